@@ -0,0 +1,1385 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.28.3
+// source: loom.proto
+
+package loom
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Bead is a work item
+type Bead struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // task, decision, epic
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`      // open, in_progress, blocked, closed
+	Priority      int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"` // 0 (critical) to 3 (low)
+	ProjectId     string                 `protobuf:"bytes,7,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	AssignedTo    string                 `protobuf:"bytes,8,opt,name=assigned_to,json=assignedTo,proto3" json:"assigned_to,omitempty"`
+	Parent        string                 `protobuf:"bytes,9,opt,name=parent,proto3" json:"parent,omitempty"`
+	Tags          []string               `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
+	BlockedBy     []string               `protobuf:"bytes,11,rep,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	Blocks        []string               `protobuf:"bytes,12,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	Context       map[string]string      `protobuf:"bytes,13,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAtMs   int64                  `protobuf:"varint,14,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	UpdatedAtMs   int64                  `protobuf:"varint,15,opt,name=updated_at_ms,json=updatedAtMs,proto3" json:"updated_at_ms,omitempty"`
+	ClosedAtMs    int64                  `protobuf:"varint,16,opt,name=closed_at_ms,json=closedAtMs,proto3" json:"closed_at_ms,omitempty"` // 0 while open
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bead) Reset() {
+	*x = Bead{}
+	mi := &file_loom_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bead) ProtoMessage() {}
+
+func (x *Bead) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bead.ProtoReflect.Descriptor instead.
+func (*Bead) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Bead) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Bead) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Bead) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Bead) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Bead) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Bead) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Bead) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Bead) GetAssignedTo() string {
+	if x != nil {
+		return x.AssignedTo
+	}
+	return ""
+}
+
+func (x *Bead) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *Bead) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Bead) GetBlockedBy() []string {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Bead) GetBlocks() []string {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *Bead) GetContext() map[string]string {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *Bead) GetCreatedAtMs() int64 {
+	if x != nil {
+		return x.CreatedAtMs
+	}
+	return 0
+}
+
+func (x *Bead) GetUpdatedAtMs() int64 {
+	if x != nil {
+		return x.UpdatedAtMs
+	}
+	return 0
+}
+
+func (x *Bead) GetClosedAtMs() int64 {
+	if x != nil {
+		return x.ClosedAtMs
+	}
+	return 0
+}
+
+// StringList wraps a list so that an empty list can be told apart from no list
+type StringList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringList) Reset() {
+	*x = StringList{}
+	mi := &file_loom_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringList) ProtoMessage() {}
+
+func (x *StringList) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringList.ProtoReflect.Descriptor instead.
+func (*StringList) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StringList) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ListBeadsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	AssignedTo    []string               `protobuf:"bytes,4,rep,name=assigned_to,json=assignedTo,proto3" json:"assigned_to,omitempty"` // any of these agents
+	Priority      *int32                 `protobuf:"varint,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBeadsRequest) Reset() {
+	*x = ListBeadsRequest{}
+	mi := &file_loom_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBeadsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBeadsRequest) ProtoMessage() {}
+
+func (x *ListBeadsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBeadsRequest.ProtoReflect.Descriptor instead.
+func (*ListBeadsRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListBeadsRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListBeadsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListBeadsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListBeadsRequest) GetAssignedTo() []string {
+	if x != nil {
+		return x.AssignedTo
+	}
+	return nil
+}
+
+func (x *ListBeadsRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+type ListBeadsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Beads         []*Bead                `protobuf:"bytes,1,rep,name=beads,proto3" json:"beads,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBeadsResponse) Reset() {
+	*x = ListBeadsResponse{}
+	mi := &file_loom_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBeadsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBeadsResponse) ProtoMessage() {}
+
+func (x *ListBeadsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBeadsResponse.ProtoReflect.Descriptor instead.
+func (*ListBeadsResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBeadsResponse) GetBeads() []*Bead {
+	if x != nil {
+		return x.Beads
+	}
+	return nil
+}
+
+type GetBeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBeadRequest) Reset() {
+	*x = GetBeadRequest{}
+	mi := &file_loom_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBeadRequest) ProtoMessage() {}
+
+func (x *GetBeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBeadRequest.ProtoReflect.Descriptor instead.
+func (*GetBeadRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetBeadRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetBeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bead          *Bead                  `protobuf:"bytes,1,opt,name=bead,proto3" json:"bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBeadResponse) Reset() {
+	*x = GetBeadResponse{}
+	mi := &file_loom_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBeadResponse) ProtoMessage() {}
+
+func (x *GetBeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBeadResponse.ProtoReflect.Descriptor instead.
+func (*GetBeadResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetBeadResponse) GetBead() *Bead {
+	if x != nil {
+		return x.Bead
+	}
+	return nil
+}
+
+type CreateBeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`                // default: task
+	Priority      *int32                 `protobuf:"varint,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"` // default: 2
+	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Context       map[string]string      `protobuf:"bytes,7,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBeadRequest) Reset() {
+	*x = CreateBeadRequest{}
+	mi := &file_loom_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBeadRequest) ProtoMessage() {}
+
+func (x *CreateBeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBeadRequest.ProtoReflect.Descriptor instead.
+func (*CreateBeadRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateBeadRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *CreateBeadRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateBeadRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateBeadRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateBeadRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *CreateBeadRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateBeadRequest) GetContext() map[string]string {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type CreateBeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bead          *Bead                  `protobuf:"bytes,1,opt,name=bead,proto3" json:"bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBeadResponse) Reset() {
+	*x = CreateBeadResponse{}
+	mi := &file_loom_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBeadResponse) ProtoMessage() {}
+
+func (x *CreateBeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBeadResponse.ProtoReflect.Descriptor instead.
+func (*CreateBeadResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateBeadResponse) GetBead() *Bead {
+	if x != nil {
+		return x.Bead
+	}
+	return nil
+}
+
+// UpdateBeadRequest changes only the fields that are set
+type UpdateBeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description   *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Status        *string                `protobuf:"bytes,4,opt,name=status,proto3,oneof" json:"status,omitempty"`
+	Priority      *int32                 `protobuf:"varint,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	AssignedTo    *string                `protobuf:"bytes,6,opt,name=assigned_to,json=assignedTo,proto3,oneof" json:"assigned_to,omitempty"`
+	Type          *string                `protobuf:"bytes,7,opt,name=type,proto3,oneof" json:"type,omitempty"`
+	Tags          *StringList            `protobuf:"bytes,8,opt,name=tags,proto3" json:"tags,omitempty"`                                                                                 // replaces the tags when set
+	Context       map[string]string      `protobuf:"bytes,9,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // merged into the existing context
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBeadRequest) Reset() {
+	*x = UpdateBeadRequest{}
+	mi := &file_loom_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBeadRequest) ProtoMessage() {}
+
+func (x *UpdateBeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBeadRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBeadRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateBeadRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetStatus() string {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *UpdateBeadRequest) GetAssignedTo() string {
+	if x != nil && x.AssignedTo != nil {
+		return *x.AssignedTo
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetType() string {
+	if x != nil && x.Type != nil {
+		return *x.Type
+	}
+	return ""
+}
+
+func (x *UpdateBeadRequest) GetTags() *StringList {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateBeadRequest) GetContext() map[string]string {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type UpdateBeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bead          *Bead                  `protobuf:"bytes,1,opt,name=bead,proto3" json:"bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBeadResponse) Reset() {
+	*x = UpdateBeadResponse{}
+	mi := &file_loom_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBeadResponse) ProtoMessage() {}
+
+func (x *UpdateBeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBeadResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBeadResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateBeadResponse) GetBead() *Bead {
+	if x != nil {
+		return x.Bead
+	}
+	return nil
+}
+
+type DeleteBeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBeadRequest) Reset() {
+	*x = DeleteBeadRequest{}
+	mi := &file_loom_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBeadRequest) ProtoMessage() {}
+
+func (x *DeleteBeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBeadRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBeadRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteBeadRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteBeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bead          *Bead                  `protobuf:"bytes,1,opt,name=bead,proto3" json:"bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBeadResponse) Reset() {
+	*x = DeleteBeadResponse{}
+	mi := &file_loom_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBeadResponse) ProtoMessage() {}
+
+func (x *DeleteBeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBeadResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBeadResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteBeadResponse) GetBead() *Bead {
+	if x != nil {
+		return x.Bead
+	}
+	return nil
+}
+
+type ClaimBeadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BeadId        string                 `protobuf:"bytes,1,opt,name=bead_id,json=beadId,proto3" json:"bead_id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimBeadRequest) Reset() {
+	*x = ClaimBeadRequest{}
+	mi := &file_loom_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimBeadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimBeadRequest) ProtoMessage() {}
+
+func (x *ClaimBeadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimBeadRequest.ProtoReflect.Descriptor instead.
+func (*ClaimBeadRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ClaimBeadRequest) GetBeadId() string {
+	if x != nil {
+		return x.BeadId
+	}
+	return ""
+}
+
+func (x *ClaimBeadRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type ClaimBeadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bead          *Bead                  `protobuf:"bytes,1,opt,name=bead,proto3" json:"bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimBeadResponse) Reset() {
+	*x = ClaimBeadResponse{}
+	mi := &file_loom_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimBeadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimBeadResponse) ProtoMessage() {}
+
+func (x *ClaimBeadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimBeadResponse.ProtoReflect.Descriptor instead.
+func (*ClaimBeadResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ClaimBeadResponse) GetBead() *Bead {
+	if x != nil {
+		return x.Bead
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_loom_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HeartbeatRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	CurrentBead   string                 `protobuf:"bytes,3,opt,name=current_bead,json=currentBead,proto3" json:"current_bead,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_loom_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *HeartbeatResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetCurrentBead() string {
+	if x != nil {
+		return x.CurrentBead
+	}
+	return ""
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"` // empty: all projects
+	Types         []string               `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`                          // empty: all event types
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_loom_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SubscribeRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// Event is an event bus message
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,4,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	TimestampMs   int64                  `protobuf:"varint,5,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	DataJson      string                 `protobuf:"bytes,6,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"` // event payload as a JSON object
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_loom_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_loom_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_loom_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Event) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Event) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *Event) GetDataJson() string {
+	if x != nil {
+		return x.DataJson
+	}
+	return ""
+}
+
+var File_loom_proto protoreflect.FileDescriptor
+
+const file_loom_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"loom.proto\x12\x04loom\"\x92\x04\n" +
+	"\x04Bead\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\a \x01(\tR\tprojectId\x12\x1f\n" +
+	"\vassigned_to\x18\b \x01(\tR\n" +
+	"assignedTo\x12\x16\n" +
+	"\x06parent\x18\t \x01(\tR\x06parent\x12\x12\n" +
+	"\x04tags\x18\n" +
+	" \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\v \x03(\tR\tblockedBy\x12\x16\n" +
+	"\x06blocks\x18\f \x03(\tR\x06blocks\x121\n" +
+	"\acontext\x18\r \x03(\v2\x17.loom.Bead.ContextEntryR\acontext\x12\"\n" +
+	"\rcreated_at_ms\x18\x0e \x01(\x03R\vcreatedAtMs\x12\"\n" +
+	"\rupdated_at_ms\x18\x0f \x01(\x03R\vupdatedAtMs\x12 \n" +
+	"\fclosed_at_ms\x18\x10 \x01(\x03R\n" +
+	"closedAtMs\x1a:\n" +
+	"\fContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"$\n" +
+	"\n" +
+	"StringList\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"\xac\x01\n" +
+	"\x10ListBeadsRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1f\n" +
+	"\vassigned_to\x18\x04 \x03(\tR\n" +
+	"assignedTo\x12\x1f\n" +
+	"\bpriority\x18\x05 \x01(\x05H\x00R\bpriority\x88\x01\x01B\v\n" +
+	"\t_priority\"5\n" +
+	"\x11ListBeadsResponse\x12 \n" +
+	"\x05beads\x18\x01 \x03(\v2\n" +
+	".loom.BeadR\x05beads\" \n" +
+	"\x0eGetBeadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x0fGetBeadResponse\x12\x1e\n" +
+	"\x04bead\x18\x01 \x01(\v2\n" +
+	".loom.BeadR\x04bead\"\xbc\x02\n" +
+	"\x11CreateBeadRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x1f\n" +
+	"\bpriority\x18\x05 \x01(\x05H\x00R\bpriority\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12>\n" +
+	"\acontext\x18\a \x03(\v2$.loom.CreateBeadRequest.ContextEntryR\acontext\x1a:\n" +
+	"\fContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_priority\"4\n" +
+	"\x12CreateBeadResponse\x12\x1e\n" +
+	"\x04bead\x18\x01 \x01(\v2\n" +
+	".loom.BeadR\x04bead\"\xcf\x03\n" +
+	"\x11UpdateBeadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x12\x1b\n" +
+	"\x06status\x18\x04 \x01(\tH\x02R\x06status\x88\x01\x01\x12\x1f\n" +
+	"\bpriority\x18\x05 \x01(\x05H\x03R\bpriority\x88\x01\x01\x12$\n" +
+	"\vassigned_to\x18\x06 \x01(\tH\x04R\n" +
+	"assignedTo\x88\x01\x01\x12\x17\n" +
+	"\x04type\x18\a \x01(\tH\x05R\x04type\x88\x01\x01\x12$\n" +
+	"\x04tags\x18\b \x01(\v2\x10.loom.StringListR\x04tags\x12>\n" +
+	"\acontext\x18\t \x03(\v2$.loom.UpdateBeadRequest.ContextEntryR\acontext\x1a:\n" +
+	"\fContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\t\n" +
+	"\a_statusB\v\n" +
+	"\t_priorityB\x0e\n" +
+	"\f_assigned_toB\a\n" +
+	"\x05_type\"4\n" +
+	"\x12UpdateBeadResponse\x12\x1e\n" +
+	"\x04bead\x18\x01 \x01(\v2\n" +
+	".loom.BeadR\x04bead\"#\n" +
+	"\x11DeleteBeadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
+	"\x12DeleteBeadResponse\x12\x1e\n" +
+	"\x04bead\x18\x01 \x01(\v2\n" +
+	".loom.BeadR\x04bead\"F\n" +
+	"\x10ClaimBeadRequest\x12\x17\n" +
+	"\abead_id\x18\x01 \x01(\tR\x06beadId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\"3\n" +
+	"\x11ClaimBeadResponse\x12\x1e\n" +
+	"\x04bead\x18\x01 \x01(\v2\n" +
+	".loom.BeadR\x04bead\"-\n" +
+	"\x10HeartbeatRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"i\n" +
+	"\x11HeartbeatResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
+	"\fcurrent_bead\x18\x03 \x01(\tR\vcurrentBead\"G\n" +
+	"\x10SubscribeRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\x12\x14\n" +
+	"\x05types\x18\x02 \x03(\tR\x05types\"\xa2\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x04 \x01(\tR\tprojectId\x12!\n" +
+	"\ftimestamp_ms\x18\x05 \x01(\x03R\vtimestampMs\x12\x1b\n" +
+	"\tdata_json\x18\x06 \x01(\tR\bdataJson2\x84\x03\n" +
+	"\vBeadService\x12<\n" +
+	"\tListBeads\x12\x16.loom.ListBeadsRequest\x1a\x17.loom.ListBeadsResponse\x126\n" +
+	"\aGetBead\x12\x14.loom.GetBeadRequest\x1a\x15.loom.GetBeadResponse\x12?\n" +
+	"\n" +
+	"CreateBead\x12\x17.loom.CreateBeadRequest\x1a\x18.loom.CreateBeadResponse\x12?\n" +
+	"\n" +
+	"UpdateBead\x12\x17.loom.UpdateBeadRequest\x1a\x18.loom.UpdateBeadResponse\x12?\n" +
+	"\n" +
+	"DeleteBead\x12\x17.loom.DeleteBeadRequest\x1a\x18.loom.DeleteBeadResponse\x12<\n" +
+	"\tClaimBead\x12\x16.loom.ClaimBeadRequest\x1a\x17.loom.ClaimBeadResponse2L\n" +
+	"\fAgentService\x12<\n" +
+	"\tHeartbeat\x12\x16.loom.HeartbeatRequest\x1a\x17.loom.HeartbeatResponse2B\n" +
+	"\fEventService\x122\n" +
+	"\tSubscribe\x12\x16.loom.SubscribeRequest\x1a\v.loom.Event0\x01B.Z,github.com/jordanhubbard/loom/api/proto/loomb\x06proto3"
+
+var (
+	file_loom_proto_rawDescOnce sync.Once
+	file_loom_proto_rawDescData []byte
+)
+
+func file_loom_proto_rawDescGZIP() []byte {
+	file_loom_proto_rawDescOnce.Do(func() {
+		file_loom_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_loom_proto_rawDesc), len(file_loom_proto_rawDesc)))
+	})
+	return file_loom_proto_rawDescData
+}
+
+var file_loom_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_loom_proto_goTypes = []any{
+	(*Bead)(nil),               // 0: loom.Bead
+	(*StringList)(nil),         // 1: loom.StringList
+	(*ListBeadsRequest)(nil),   // 2: loom.ListBeadsRequest
+	(*ListBeadsResponse)(nil),  // 3: loom.ListBeadsResponse
+	(*GetBeadRequest)(nil),     // 4: loom.GetBeadRequest
+	(*GetBeadResponse)(nil),    // 5: loom.GetBeadResponse
+	(*CreateBeadRequest)(nil),  // 6: loom.CreateBeadRequest
+	(*CreateBeadResponse)(nil), // 7: loom.CreateBeadResponse
+	(*UpdateBeadRequest)(nil),  // 8: loom.UpdateBeadRequest
+	(*UpdateBeadResponse)(nil), // 9: loom.UpdateBeadResponse
+	(*DeleteBeadRequest)(nil),  // 10: loom.DeleteBeadRequest
+	(*DeleteBeadResponse)(nil), // 11: loom.DeleteBeadResponse
+	(*ClaimBeadRequest)(nil),   // 12: loom.ClaimBeadRequest
+	(*ClaimBeadResponse)(nil),  // 13: loom.ClaimBeadResponse
+	(*HeartbeatRequest)(nil),   // 14: loom.HeartbeatRequest
+	(*HeartbeatResponse)(nil),  // 15: loom.HeartbeatResponse
+	(*SubscribeRequest)(nil),   // 16: loom.SubscribeRequest
+	(*Event)(nil),              // 17: loom.Event
+	nil,                        // 18: loom.Bead.ContextEntry
+	nil,                        // 19: loom.CreateBeadRequest.ContextEntry
+	nil,                        // 20: loom.UpdateBeadRequest.ContextEntry
+}
+var file_loom_proto_depIdxs = []int32{
+	18, // 0: loom.Bead.context:type_name -> loom.Bead.ContextEntry
+	0,  // 1: loom.ListBeadsResponse.beads:type_name -> loom.Bead
+	0,  // 2: loom.GetBeadResponse.bead:type_name -> loom.Bead
+	19, // 3: loom.CreateBeadRequest.context:type_name -> loom.CreateBeadRequest.ContextEntry
+	0,  // 4: loom.CreateBeadResponse.bead:type_name -> loom.Bead
+	1,  // 5: loom.UpdateBeadRequest.tags:type_name -> loom.StringList
+	20, // 6: loom.UpdateBeadRequest.context:type_name -> loom.UpdateBeadRequest.ContextEntry
+	0,  // 7: loom.UpdateBeadResponse.bead:type_name -> loom.Bead
+	0,  // 8: loom.DeleteBeadResponse.bead:type_name -> loom.Bead
+	0,  // 9: loom.ClaimBeadResponse.bead:type_name -> loom.Bead
+	2,  // 10: loom.BeadService.ListBeads:input_type -> loom.ListBeadsRequest
+	4,  // 11: loom.BeadService.GetBead:input_type -> loom.GetBeadRequest
+	6,  // 12: loom.BeadService.CreateBead:input_type -> loom.CreateBeadRequest
+	8,  // 13: loom.BeadService.UpdateBead:input_type -> loom.UpdateBeadRequest
+	10, // 14: loom.BeadService.DeleteBead:input_type -> loom.DeleteBeadRequest
+	12, // 15: loom.BeadService.ClaimBead:input_type -> loom.ClaimBeadRequest
+	14, // 16: loom.AgentService.Heartbeat:input_type -> loom.HeartbeatRequest
+	16, // 17: loom.EventService.Subscribe:input_type -> loom.SubscribeRequest
+	3,  // 18: loom.BeadService.ListBeads:output_type -> loom.ListBeadsResponse
+	5,  // 19: loom.BeadService.GetBead:output_type -> loom.GetBeadResponse
+	7,  // 20: loom.BeadService.CreateBead:output_type -> loom.CreateBeadResponse
+	9,  // 21: loom.BeadService.UpdateBead:output_type -> loom.UpdateBeadResponse
+	11, // 22: loom.BeadService.DeleteBead:output_type -> loom.DeleteBeadResponse
+	13, // 23: loom.BeadService.ClaimBead:output_type -> loom.ClaimBeadResponse
+	15, // 24: loom.AgentService.Heartbeat:output_type -> loom.HeartbeatResponse
+	17, // 25: loom.EventService.Subscribe:output_type -> loom.Event
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_loom_proto_init() }
+func file_loom_proto_init() {
+	if File_loom_proto != nil {
+		return
+	}
+	file_loom_proto_msgTypes[2].OneofWrappers = []any{}
+	file_loom_proto_msgTypes[6].OneofWrappers = []any{}
+	file_loom_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_loom_proto_rawDesc), len(file_loom_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_loom_proto_goTypes,
+		DependencyIndexes: file_loom_proto_depIdxs,
+		MessageInfos:      file_loom_proto_msgTypes,
+	}.Build()
+	File_loom_proto = out.File
+	file_loom_proto_goTypes = nil
+	file_loom_proto_depIdxs = nil
+}
@@ -0,0 +1,546 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             v5.28.3
+// source: loom.proto
+
+package loom
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BeadService_ListBeads_FullMethodName  = "/loom.BeadService/ListBeads"
+	BeadService_GetBead_FullMethodName    = "/loom.BeadService/GetBead"
+	BeadService_CreateBead_FullMethodName = "/loom.BeadService/CreateBead"
+	BeadService_UpdateBead_FullMethodName = "/loom.BeadService/UpdateBead"
+	BeadService_DeleteBead_FullMethodName = "/loom.BeadService/DeleteBead"
+	BeadService_ClaimBead_FullMethodName  = "/loom.BeadService/ClaimBead"
+)
+
+// BeadServiceClient is the client API for BeadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BeadService exposes bead CRUD and claiming
+type BeadServiceClient interface {
+	// ListBeads returns beads matching the given filters
+	ListBeads(ctx context.Context, in *ListBeadsRequest, opts ...grpc.CallOption) (*ListBeadsResponse, error)
+	// GetBead retrieves a specific bead by ID
+	GetBead(ctx context.Context, in *GetBeadRequest, opts ...grpc.CallOption) (*GetBeadResponse, error)
+	// CreateBead files a new bead in a project
+	CreateBead(ctx context.Context, in *CreateBeadRequest, opts ...grpc.CallOption) (*CreateBeadResponse, error)
+	// UpdateBead changes the fields that are set on the request
+	UpdateBead(ctx context.Context, in *UpdateBeadRequest, opts ...grpc.CallOption) (*UpdateBeadResponse, error)
+	// DeleteBead moves a bead to the trash
+	DeleteBead(ctx context.Context, in *DeleteBeadRequest, opts ...grpc.CallOption) (*DeleteBeadResponse, error)
+	// ClaimBead assigns a bead to an agent
+	ClaimBead(ctx context.Context, in *ClaimBeadRequest, opts ...grpc.CallOption) (*ClaimBeadResponse, error)
+}
+
+type beadServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBeadServiceClient(cc grpc.ClientConnInterface) BeadServiceClient {
+	return &beadServiceClient{cc}
+}
+
+func (c *beadServiceClient) ListBeads(ctx context.Context, in *ListBeadsRequest, opts ...grpc.CallOption) (*ListBeadsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBeadsResponse)
+	err := c.cc.Invoke(ctx, BeadService_ListBeads_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beadServiceClient) GetBead(ctx context.Context, in *GetBeadRequest, opts ...grpc.CallOption) (*GetBeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBeadResponse)
+	err := c.cc.Invoke(ctx, BeadService_GetBead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beadServiceClient) CreateBead(ctx context.Context, in *CreateBeadRequest, opts ...grpc.CallOption) (*CreateBeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateBeadResponse)
+	err := c.cc.Invoke(ctx, BeadService_CreateBead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beadServiceClient) UpdateBead(ctx context.Context, in *UpdateBeadRequest, opts ...grpc.CallOption) (*UpdateBeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateBeadResponse)
+	err := c.cc.Invoke(ctx, BeadService_UpdateBead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beadServiceClient) DeleteBead(ctx context.Context, in *DeleteBeadRequest, opts ...grpc.CallOption) (*DeleteBeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBeadResponse)
+	err := c.cc.Invoke(ctx, BeadService_DeleteBead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beadServiceClient) ClaimBead(ctx context.Context, in *ClaimBeadRequest, opts ...grpc.CallOption) (*ClaimBeadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClaimBeadResponse)
+	err := c.cc.Invoke(ctx, BeadService_ClaimBead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BeadServiceServer is the server API for BeadService service.
+// All implementations must embed UnimplementedBeadServiceServer
+// for forward compatibility.
+//
+// BeadService exposes bead CRUD and claiming
+type BeadServiceServer interface {
+	// ListBeads returns beads matching the given filters
+	ListBeads(context.Context, *ListBeadsRequest) (*ListBeadsResponse, error)
+	// GetBead retrieves a specific bead by ID
+	GetBead(context.Context, *GetBeadRequest) (*GetBeadResponse, error)
+	// CreateBead files a new bead in a project
+	CreateBead(context.Context, *CreateBeadRequest) (*CreateBeadResponse, error)
+	// UpdateBead changes the fields that are set on the request
+	UpdateBead(context.Context, *UpdateBeadRequest) (*UpdateBeadResponse, error)
+	// DeleteBead moves a bead to the trash
+	DeleteBead(context.Context, *DeleteBeadRequest) (*DeleteBeadResponse, error)
+	// ClaimBead assigns a bead to an agent
+	ClaimBead(context.Context, *ClaimBeadRequest) (*ClaimBeadResponse, error)
+	mustEmbedUnimplementedBeadServiceServer()
+}
+
+// UnimplementedBeadServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBeadServiceServer struct{}
+
+func (UnimplementedBeadServiceServer) ListBeads(context.Context, *ListBeadsRequest) (*ListBeadsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBeads not implemented")
+}
+func (UnimplementedBeadServiceServer) GetBead(context.Context, *GetBeadRequest) (*GetBeadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetBead not implemented")
+}
+func (UnimplementedBeadServiceServer) CreateBead(context.Context, *CreateBeadRequest) (*CreateBeadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateBead not implemented")
+}
+func (UnimplementedBeadServiceServer) UpdateBead(context.Context, *UpdateBeadRequest) (*UpdateBeadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateBead not implemented")
+}
+func (UnimplementedBeadServiceServer) DeleteBead(context.Context, *DeleteBeadRequest) (*DeleteBeadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteBead not implemented")
+}
+func (UnimplementedBeadServiceServer) ClaimBead(context.Context, *ClaimBeadRequest) (*ClaimBeadResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ClaimBead not implemented")
+}
+func (UnimplementedBeadServiceServer) mustEmbedUnimplementedBeadServiceServer() {}
+func (UnimplementedBeadServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBeadServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BeadServiceServer will
+// result in compilation errors.
+type UnsafeBeadServiceServer interface {
+	mustEmbedUnimplementedBeadServiceServer()
+}
+
+func RegisterBeadServiceServer(s grpc.ServiceRegistrar, srv BeadServiceServer) {
+	// If the following call panics, it indicates UnimplementedBeadServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BeadService_ServiceDesc, srv)
+}
+
+func _BeadService_ListBeads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBeadsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).ListBeads(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_ListBeads_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).ListBeads(ctx, req.(*ListBeadsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BeadService_GetBead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).GetBead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_GetBead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).GetBead(ctx, req.(*GetBeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BeadService_CreateBead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).CreateBead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_CreateBead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).CreateBead(ctx, req.(*CreateBeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BeadService_UpdateBead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateBeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).UpdateBead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_UpdateBead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).UpdateBead(ctx, req.(*UpdateBeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BeadService_DeleteBead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).DeleteBead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_DeleteBead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).DeleteBead(ctx, req.(*DeleteBeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BeadService_ClaimBead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimBeadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeadServiceServer).ClaimBead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BeadService_ClaimBead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeadServiceServer).ClaimBead(ctx, req.(*ClaimBeadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BeadService_ServiceDesc is the grpc.ServiceDesc for BeadService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BeadService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loom.BeadService",
+	HandlerType: (*BeadServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBeads",
+			Handler:    _BeadService_ListBeads_Handler,
+		},
+		{
+			MethodName: "GetBead",
+			Handler:    _BeadService_GetBead_Handler,
+		},
+		{
+			MethodName: "CreateBead",
+			Handler:    _BeadService_CreateBead_Handler,
+		},
+		{
+			MethodName: "UpdateBead",
+			Handler:    _BeadService_UpdateBead_Handler,
+		},
+		{
+			MethodName: "DeleteBead",
+			Handler:    _BeadService_DeleteBead_Handler,
+		},
+		{
+			MethodName: "ClaimBead",
+			Handler:    _BeadService_ClaimBead_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "loom.proto",
+}
+
+const (
+	AgentService_Heartbeat_FullMethodName = "/loom.AgentService/Heartbeat"
+)
+
+// AgentServiceClient is the client API for AgentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AgentService exposes agent liveness operations
+type AgentServiceClient interface {
+	// Heartbeat records that an agent is alive
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+}
+
+type agentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentServiceClient(cc grpc.ClientConnInterface) AgentServiceClient {
+	return &agentServiceClient{cc}
+}
+
+func (c *agentServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, AgentService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServiceServer is the server API for AgentService service.
+// All implementations must embed UnimplementedAgentServiceServer
+// for forward compatibility.
+//
+// AgentService exposes agent liveness operations
+type AgentServiceServer interface {
+	// Heartbeat records that an agent is alive
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	mustEmbedUnimplementedAgentServiceServer()
+}
+
+// UnimplementedAgentServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentServiceServer struct{}
+
+func (UnimplementedAgentServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
+func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAgentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentServiceServer will
+// result in compilation errors.
+type UnsafeAgentServiceServer interface {
+	mustEmbedUnimplementedAgentServiceServer()
+}
+
+func RegisterAgentServiceServer(s grpc.ServiceRegistrar, srv AgentServiceServer) {
+	// If the following call panics, it indicates UnimplementedAgentServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AgentService_ServiceDesc, srv)
+}
+
+func _AgentService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loom.AgentService",
+	HandlerType: (*AgentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Heartbeat",
+			Handler:    _AgentService_Heartbeat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "loom.proto",
+}
+
+const (
+	EventService_Subscribe_FullMethodName = "/loom.EventService/Subscribe"
+)
+
+// EventServiceClient is the client API for EventService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// EventService streams events from the event bus
+type EventServiceClient interface {
+	// Subscribe streams events until the client cancels
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type eventServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventServiceClient(cc grpc.ClientConnInterface) EventServiceClient {
+	return &eventServiceClient{cc}
+}
+
+func (c *eventServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], EventService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_SubscribeClient = grpc.ServerStreamingClient[Event]
+
+// EventServiceServer is the server API for EventService service.
+// All implementations must embed UnimplementedEventServiceServer
+// for forward compatibility.
+//
+// EventService streams events from the event bus
+type EventServiceServer interface {
+	// Subscribe streams events until the client cancels
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedEventServiceServer()
+}
+
+// UnimplementedEventServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEventServiceServer struct{}
+
+func (UnimplementedEventServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
+func (UnimplementedEventServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeEventServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventServiceServer will
+// result in compilation errors.
+type UnsafeEventServiceServer interface {
+	mustEmbedUnimplementedEventServiceServer()
+}
+
+func RegisterEventServiceServer(s grpc.ServiceRegistrar, srv EventServiceServer) {
+	// If the following call panics, it indicates UnimplementedEventServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EventService_ServiceDesc, srv)
+}
+
+func _EventService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_SubscribeServer = grpc.ServerStreamingServer[Event]
+
+// EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EventService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loom.EventService",
+	HandlerType: (*EventServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _EventService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "loom.proto",
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -33,6 +34,13 @@ func main() {
 		personaBasePath   = flag.String("persona-base-path", getEnvOrDefault("PERSONA_BASE_PATH", "/app/personas"), "Base dir for per-role persona files (multi-role mode)")
 		actionLoop        = flag.Bool("action-loop", getEnvBool("ACTION_LOOP_ENABLED", false), "Enable multi-turn action loop")
 		maxIterations     = flag.Int("max-iterations", getEnvInt("MAX_LOOP_ITERATIONS", 20), "Max action loop iterations")
+		enrollToken       = flag.String("enroll-token", os.Getenv("REMOTE_ENROLL_TOKEN"), "Remote mode: one-time enrollment token")
+		credentialFile    = flag.String("credential-file", os.Getenv("REMOTE_CREDENTIAL_FILE"), "Remote mode: where the agent credential is stored")
+		agentName         = flag.String("name", os.Getenv("AGENT_NAME"), "Remote mode: agent name (default: host name)")
+		capabilities      = flag.String("capabilities", os.Getenv("AGENT_CAPABILITIES"), "Remote mode: comma-separated capabilities, e.g. gpu,cuda")
+		tlsCert           = flag.String("tls-cert", os.Getenv("LOOM_TLS_CERT"), "mTLS: client certificate for the control plane")
+		tlsKey            = flag.String("tls-key", os.Getenv("LOOM_TLS_KEY"), "mTLS: client certificate key")
+		tlsCA             = flag.String("tls-ca", os.Getenv("LOOM_TLS_CA"), "mTLS: CA certificate the control plane's certificate is checked against")
 	)
 
 	flag.Parse()
@@ -72,6 +80,47 @@ func main() {
 	serviceID := getEnvOrDefault("SERVICE_ID", fmt.Sprintf("agent-%s", *projectID))
 	instanceID := getEnvOrDefault("INSTANCE_ID", "")
 
+	// Remote mode: the agent runs outside the control plane's network,
+	// enrolls with a token and pulls work over an outbound WebSocket.
+	if *enrollToken != "" || *credentialFile != "" {
+		log.Printf("Starting remote agent (project=%s, control plane=%s)", *projectID, *controlPlaneURL)
+		agent, err := projectagent.New(projectagent.Config{
+			ProjectID:         *projectID,
+			ControlPlaneURL:   *controlPlaneURL,
+			WorkDir:           *workDir,
+			HeartbeatInterval: *heartbeatInterval,
+			Role:              *role,
+			ProviderEndpoint:  *providerEndpoint,
+			ProviderModel:     *providerModel,
+			ProviderAPIKey:    *providerAPIKey,
+			PersonaPath:       *personaPath,
+			ActionLoopEnabled: true,
+			MaxLoopIterations: *maxIterations,
+			TLSCertFile:       *tlsCert,
+			TLSKeyFile:        *tlsKey,
+			TLSCAFile:         *tlsCA,
+		})
+		if err != nil {
+			log.Fatalf("Failed to create agent: %v", err)
+		}
+		var roles []string
+		if *role != "" {
+			roles = []string{*role}
+		}
+		err = agent.RunRemote(ctx, projectagent.RemoteConfig{
+			EnrollToken:    *enrollToken,
+			CredentialFile: *credentialFile,
+			Name:           *agentName,
+			Roles:          roles,
+			Capabilities:   splitList(*capabilities),
+		})
+		if err != nil && err != context.Canceled {
+			log.Fatalf("Remote agent error: %v", err)
+		}
+		log.Println("Agent stopped")
+		return
+	}
+
 	// Multi-role mode: AGENT_ROLE is unset → run all roles in one process.
 	// Single-role mode: AGENT_ROLE is set → backward-compatible single agent.
 	if *role == "" {
@@ -90,6 +139,9 @@ func main() {
 			PersonaBasePath:   *personaBasePath,
 			ActionLoopEnabled: *actionLoop,
 			MaxLoopIterations: *maxIterations,
+			TLSCertFile:       *tlsCert,
+			TLSKeyFile:        *tlsKey,
+			TLSCAFile:         *tlsCA,
 		})
 		if err := orch.Start(ctx); err != nil && err != context.Canceled {
 			log.Fatalf("Orchestrator error: %v", err)
@@ -121,6 +173,9 @@ func main() {
 		PersonaPath:       *personaPath,
 		ActionLoopEnabled: *actionLoop,
 		MaxLoopIterations: *maxIterations,
+		TLSCertFile:       *tlsCert,
+		TLSKeyFile:        *tlsKey,
+		TLSCAFile:         *tlsCA,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
@@ -164,6 +219,16 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	v := os.Getenv(key)
 	if v == "" {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -21,9 +22,13 @@ import (
 	"github.com/jordanhubbard/loom/internal/automerge"
 	"github.com/jordanhubbard/loom/internal/cimon"
 	internalconnectors "github.com/jordanhubbard/loom/internal/connectors"
+	"github.com/jordanhubbard/loom/internal/depbot"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/grpcapi"
 	"github.com/jordanhubbard/loom/internal/hotreload"
 	"github.com/jordanhubbard/loom/internal/keymanager"
 	"github.com/jordanhubbard/loom/internal/loom"
+	"github.com/jordanhubbard/loom/internal/report"
 	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/pkg/config"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -67,18 +72,36 @@ func main() {
 	keyStorePath := filepath.Join(".", "data", "keys", ".keys.json")
 	km := keymanager.NewKeyManager(keyStorePath)
 
-	password := loadPassword()
-	if password == "" {
-		log.Printf("Warning: No password found. Using default password. Set LOOM_PASSWORD environment variable or create .env file")
-		password = "loom-default-password"
+	backend, err := keymanager.NewBackend(&cfg.KeyStore)
+	if err != nil {
+		log.Fatalf("Invalid key store configuration: %v", err)
 	}
-
-	if err := km.Unlock(password); err != nil {
-		log.Printf("Password unlock failed: %v. Trying default password...", err)
-		if err := km.Unlock("loom-default-password"); err != nil {
-			log.Fatalf("Failed to unlock key manager with both passwords: %v", err)
+	if backend == nil {
+		unlockWithPassword(km)
+	} else {
+		err := km.UnlockWithBackend(context.Background(), backend)
+		if errors.Is(err, keymanager.ErrPasswordStore) {
+			log.Printf("Migrating key store from LOOM_PASSWORD to %s", backend.Name())
+			unlockWithPassword(km)
+			err = km.MigrateToBackend(context.Background(), backend)
+		}
+		if err != nil {
+			log.Fatalf("Failed to open key store with %s: %v", backend.Name(), err)
 		}
 	}
+	km.OnRotate(func(ev keymanager.RotationEvent) {
+		if eb := arb.GetEventBus(); eb != nil {
+			_ = eb.Publish(&eventbus.Event{
+				Type:   eventbus.EventTypeKeyStoreRotated,
+				Source: "keymanager",
+				Data: map[string]interface{}{
+					"backend": ev.Backend,
+					"keys":    ev.Keys,
+					"reason":  ev.Reason,
+				},
+			})
+		}
+	})
 
 	arb.SetKeyManager(km)
 
@@ -120,23 +143,40 @@ func main() {
 	}
 
 	go arb.StartMaintenanceLoop(runCtx)
+	km.StartAutoRotation(runCtx, cfg.KeyStore.RotationInterval)
 
 	// Task executor: direct bead-claim → ExecuteTaskWithLoop loop per project.
 	// Bypasses Temporal, NATS, and the WorkerPool for reliable execution.
 	log.Printf("Starting task executor")
 	go arb.StartTaskExecutor(runCtx)
 
-	// Self-audit loop: periodically run build/test/lint and file beads for failures.
-	// Disabled by default via env var. Set SELF_AUDIT_INTERVAL_MINUTES to enable.
-	selfAuditInterval := 0
+	// Self-audit loop: periodically run the configured checks and file beads for failures.
+	// Disabled by default. Set self_audit.interval or SELF_AUDIT_INTERVAL_MINUTES to enable.
+	selfAuditInterval := int(cfg.SelfAudit.Interval / time.Minute)
 	if interval := os.Getenv("SELF_AUDIT_INTERVAL_MINUTES"); interval != "" {
 		if n, err := fmt.Sscanf(interval, "%d", &selfAuditInterval); err == nil && n == 1 {
 			log.Printf("Self-audit enabled with %d minute interval", selfAuditInterval)
 		}
 	}
 	if selfAuditInterval > 0 {
-		selfAuditRunner := audit.NewRunner("loom", ".", selfAuditInterval, arb)
+		selfProjectID := cfg.SelfProjectID
+		if selfProjectID == "" {
+			selfProjectID = "loom"
+		}
+		selfAuditRunner := audit.NewRunner(selfProjectID, ".", selfAuditInterval, arb)
+		selfAuditRunner.SetOptions(arb.AuditOptions)
 		go selfAuditRunner.Start(runCtx)
+
+		for _, projectID := range cfg.SelfAudit.Projects {
+			p, err := arb.GetProjectManager().GetProject(projectID)
+			if err != nil || p.WorkDir == "" {
+				log.Printf("Self-audit of project %s skipped: no work directory", projectID)
+				continue
+			}
+			runner := audit.NewRunner(projectID, p.WorkDir, selfAuditInterval, arb)
+			runner.SetOptions(arb.AuditOptions)
+			go runner.Start(runCtx)
+		}
 	}
 
 	autoMergeInterval := 0
@@ -147,6 +187,7 @@ func main() {
 	}
 	if autoMergeInterval > 0 {
 		autoMergeRunner := automerge.NewRunner(arb)
+		autoMergeRunner.SetConflictBeads(arb)
 		go autoMergeRunner.Start(runCtx, time.Duration(autoMergeInterval)*time.Minute)
 	}
 
@@ -163,6 +204,34 @@ func main() {
 		go ciMonRunner.Start(runCtx)
 	}
 
+	// Dependency bot: scan manifests for updates and file beads for them.
+	// Disabled unless dependency_bot.interval is set.
+	if cfg.DepBot.Interval > 0 {
+		depBotRunner := depbot.NewRunner(arb, arb, cfg.DepBot.Interval, depbot.Options{
+			Projects:     cfg.DepBot.Projects,
+			BranchLevels: cfg.DepBot.BranchLevels,
+		})
+		depBotRunner.SetActionExecutor(arb.GetActionRouter())
+		go depBotRunner.Start(runCtx)
+	}
+
+	// Project reports: daily and weekly digests sent out on the notification
+	// channels. Disabled unless reports.periods is set.
+	if len(cfg.Reports.Periods) > 0 {
+		var periods []report.Period
+		for _, p := range cfg.Reports.Periods {
+			period, err := report.ParsePeriod(p)
+			if err != nil {
+				log.Printf("Reports: %v", err)
+				continue
+			}
+			periods = append(periods, period)
+		}
+		if len(periods) > 0 {
+			go report.NewRunner(arb, periods, cfg.Reports.Projects).Start(runCtx)
+		}
+	}
+
 	// Initialize auth manager (JWT + API key support)
 	authManager := auth.NewManager(cfg.Security.JWTSecret)
 
@@ -198,7 +267,37 @@ func main() {
 		}
 	}()
 
-	// Start gRPC ConnectorsService
+	// With agent mTLS, project agents connect to a separate TLS listener
+	// with their client certificates. It serves the same API, so other
+	// clients may use it too without a certificate.
+	var mtlsSrv *http.Server
+	if pki := arb.GetAgentPKI(); pki != nil {
+		mtls := cfg.Security.AgentMTLS
+		port := mtls.Port
+		if port == 0 {
+			port = 8443
+		}
+		names := mtls.ServerNames
+		if len(names) == 0 {
+			names = []string{"loom", "localhost"}
+		}
+		mtlsSrv = &http.Server{
+			Addr:         fmt.Sprintf(":%d", port),
+			Handler:      handler,
+			TLSConfig:    pki.ServerTLSConfig(names),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+		go func() {
+			log.Printf("Loom agent mTLS listening on %s", mtlsSrv.Addr)
+			if err := mtlsSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("mTLS server error: %v", err)
+			}
+		}()
+	}
+
+	// Start gRPC ConnectorsService and the bead, agent and event services
 	grpcPort := cfg.Server.GRPCPort
 	if grpcPort == 0 {
 		grpcPort = 9090
@@ -207,9 +306,22 @@ func main() {
 	if err != nil {
 		log.Printf("Warning: failed to start gRPC listener on :%d: %v", grpcPort, err)
 	} else {
-		grpcSrv := grpc.NewServer()
+		// With auth enabled, the bead, agent and event services take the
+		// same credentials as the HTTP API. ConnectorsService stays open so
+		// a separate connectors service can keep calling it. Maintenance
+		// mode rejects bead changes, as over HTTP.
+		var unary []grpc.UnaryServerInterceptor
+		var grpcOpts []grpc.ServerOption
+		if cfg.Security.EnableAuth {
+			unary = append(unary, authManager.UnaryServerInterceptor(grpcapi.Permission))
+			grpcOpts = append(grpcOpts, grpc.StreamInterceptor(authManager.StreamServerInterceptor(grpcapi.Permission)))
+		}
+		unary = append(unary, grpcapi.MaintenanceInterceptor(arb.ServerMode))
+		grpcOpts = append(grpcOpts, grpc.ChainUnaryInterceptor(unary...))
+		grpcSrv := grpc.NewServer(grpcOpts...)
 		pb.RegisterConnectorsServiceServer(grpcSrv, internalconnectors.NewGRPCServer(arb.GetConnectorManager()))
-		log.Printf("gRPC ConnectorsService listening on :%d", grpcPort)
+		grpcapi.Register(grpcSrv, arb)
+		log.Printf("gRPC services listening on :%d", grpcPort)
 		go func() {
 			if err := grpcSrv.Serve(grpcListener); err != nil {
 				log.Printf("gRPC server stopped: %v", err)
@@ -221,16 +333,51 @@ func main() {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	<-sigCh
+
+	// Let beads in flight finish, or checkpoint them, before stopping
+	// everything. A second signal stops waiting.
+	log.Printf("Shutting down: draining the task executor")
+	drainCtx, stopDrain := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-sigCh:
+			log.Printf("Second signal: interrupting beads in flight")
+			stopDrain()
+		case <-drainCtx.Done():
+		}
+	}()
+	arb.DrainTaskExecutor(drainCtx)
+	stopDrain()
 	cancel()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	_ = httpSrv.Shutdown(shutdownCtx)
+	if mtlsSrv != nil {
+		_ = mtlsSrv.Shutdown(shutdownCtx)
+	}
 	arb.Shutdown()
 
 }
 
+// unlockWithPassword unlocks the local key store with LOOM_PASSWORD, falling
+// back to the default password.
+func unlockWithPassword(km *keymanager.KeyManager) {
+	password := loadPassword()
+	if password == "" {
+		log.Printf("Warning: No password found. Using default password. Set LOOM_PASSWORD environment variable or create .env file")
+		password = "loom-default-password"
+	}
+
+	if err := km.Unlock(password); err != nil {
+		log.Printf("Password unlock failed: %v. Trying default password...", err)
+		if err := km.Unlock("loom-default-password"); err != nil {
+			log.Fatalf("Failed to unlock key manager with both passwords: %v", err)
+		}
+	}
+}
+
 func loadPassword() string {
 	// First, check environment variable
 	if pwd := os.Getenv("LOOM_PASSWORD"); pwd != "" {
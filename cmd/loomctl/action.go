@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newActionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "action",
+		Short: "Work with agent action envelopes",
+	}
+	cmd.AddCommand(newActionValidateCommand())
+	return cmd
+}
+
+func newActionValidateCommand() *cobra.Command {
+	var projectID, beadID, agentID string
+	cmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Dry-run an action envelope and show what it would do",
+		Long: `Validate an action envelope without executing it. The server describes
+each action's effect and summarizes the files that would be written or
+deleted, the commands that would run, and the beads that would be created or
+changed. The file (or stdin, with - or no file) may be an envelope like
+{"actions": [...]} or a raw agent response to parse. Exits non-zero when any
+action is invalid.`,
+		Args: cobra.MaximumNArgs(1),
+		Example: `  loomctl action validate proposed-fix.json --project=loom-self
+  pbpaste | loomctl action validate --project=loom-self --bead=loom-001`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var content []byte
+			var err error
+			if len(args) == 0 || args[0] == "-" {
+				content, err = io.ReadAll(os.Stdin)
+			} else {
+				content, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read envelope: %w", err)
+			}
+
+			body := map[string]interface{}{
+				"project_id": projectID,
+				"bead_id":    beadID,
+				"agent_id":   agentID,
+			}
+			var env struct {
+				Actions []json.RawMessage `json:"actions"`
+				Notes   string            `json:"notes"`
+			}
+			if json.Unmarshal(content, &env) == nil && len(env.Actions) > 0 {
+				body["actions"] = env.Actions
+				body["notes"] = env.Notes
+			} else {
+				body["response"] = string(content)
+			}
+
+			client := newClient()
+			data, err := client.post("/api/v1/actions/validate", body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+
+			var plan struct {
+				Valid bool `json:"valid"`
+			}
+			if err := json.Unmarshal(data, &plan); err == nil && !plan.Valid {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("action envelope is invalid")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project the actions would run in")
+	cmd.Flags().StringVar(&beadID, "bead", "", "Bead the actions would run for")
+	cmd.Flags().StringVar(&agentID, "agent", "", "Agent that would run the actions")
+	return cmd
+}
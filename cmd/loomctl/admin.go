@@ -0,0 +1,131 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newAdminCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Server administration",
+	}
+	cmd.AddCommand(newAdminModeCommand())
+	cmd.AddCommand(newAdminMaintenanceCommand())
+	cmd.AddCommand(newAdminMigrateCommand())
+	return cmd
+}
+
+func newAdminModeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mode",
+		Short: "Show whether the server is in normal or maintenance mode",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/admin/mode", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newAdminMaintenanceCommand() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "maintenance <on|off>",
+		Short: "Switch maintenance mode on or off",
+		Long: `Switch maintenance mode on or off. In maintenance the server rejects every
+mutating API request with 503 and stops dispatching beads; beads already
+running finish. Reads keep working. The mode outlasts a restart, so it
+can be switched on before an upgrade and off once the new version is up.`,
+		Example: `  loomctl admin maintenance on --reason "upgrading to v2"
+  loomctl admin maintenance off`,
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"on", "off"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := "normal"
+			if args[0] == "on" {
+				mode = "maintenance"
+			}
+			client := newClient()
+			data, err := client.put("/api/v1/admin/mode", map[string]interface{}{
+				"mode":   mode,
+				"reason": reason,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the server is going into maintenance, shown to rejected clients")
+	return cmd
+}
+
+func newAdminMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Review and apply database schema migrations",
+		Long: `Review and apply database schema migrations. The baseline migrations, the
+schema from before migrations were versioned, run at every startup and
+can't be rolled back. Later migrations run at startup too, unless
+database.manual_migrations is set in config.yaml; then they wait for
+'loomctl admin migrate up'.
+
+Applying or rolling back migrations requires maintenance mode. Pass
+--dry-run to see the SQL a migration would run first.`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show the schema version and which migrations are applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/admin/migrations", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(newAdminMigrateStepCommand("up", "Apply pending migrations, up to --to or all of them",
+		`  loomctl admin migrate up --dry-run
+  loomctl admin migrate up --to 42`))
+	cmd.AddCommand(newAdminMigrateStepCommand("down", "Roll back migrations after --to, or the newest one",
+		`  loomctl admin migrate down --dry-run
+  loomctl admin migrate down --to 41`))
+	return cmd
+}
+
+func newAdminMigrateStepCommand(direction, short, example string) *cobra.Command {
+	var to int
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:         direction,
+		Short:       short,
+		Example:     example,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]interface{}{"dry_run": dryRun}
+			if cmd.Flags().Changed("to") {
+				body["to"] = to
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/admin/migrations/"+direction, body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&to, "to", 0, "Target schema version")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the migrations and their SQL without running them")
+	return cmd
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newAgentTailCommand() *cobra.Command {
+	var raw bool
+	cmd := &cobra.Command{
+		Use:   "tail <agent-id>",
+		Short: "Watch what an agent is doing in real time",
+		Long: `Follow an agent's live stream: the model's output as it is generated,
+each action it runs with its result, and its status changes as it picks up
+and finishes beads. Runs until interrupted. Use --raw for one JSON event per
+line instead.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  loomctl agent tail agent-123
+  loomctl agent tail agent-123 --raw | jq 'select(.event == "actions")'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			path := "/api/v1/agents/" + url.PathEscape(args[0]) + "/stream"
+			if raw {
+				return client.readSSE(path, func(event, data string) error {
+					fmt.Printf("{\"event\":%q,\"data\":%s}\n", event, data)
+					return nil
+				})
+			}
+			t := &agentTail{out: os.Stdout}
+			return client.readSSE(path, t.handle)
+		},
+	}
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print each event as a line of JSON")
+	return cmd
+}
+
+// agentTail renders an agent stream for a terminal.
+type agentTail struct {
+	out     io.Writer
+	taskID  string
+	turn    float64
+	midLine bool
+}
+
+func (t *agentTail) handle(event, data string) error {
+	var d map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		return nil
+	}
+	switch event {
+	case "connected":
+		line := fmt.Sprintf("Watching %s (%s): %s", str(d["name"]), str(d["agent_id"]), str(d["status"]))
+		if hold := str(d["hold"]); hold != "" {
+			line += ", " + hold
+		}
+		if bead := str(d["current_bead"]); bead != "" {
+			line += " on " + bead
+		}
+		fmt.Fprintln(os.Stderr, line)
+
+	case "output":
+		taskID := str(d["task_id"])
+		turn, _ := d["turn"].(float64)
+		if taskID != t.taskID || turn != t.turn {
+			t.endLine()
+			fmt.Fprintf(t.out, "--- %s turn %.0f ---\n", str(d["bead_id"]), turn)
+			t.taskID, t.turn = taskID, turn
+		}
+		delta := str(d["delta"])
+		fmt.Fprint(t.out, delta)
+		t.midLine = delta != "" && !strings.HasSuffix(delta, "\n")
+
+	case "actions":
+		t.endLine()
+		acts, _ := d["actions"].([]interface{})
+		results, _ := d["results"].([]interface{})
+		for i, a := range acts {
+			act, _ := a.(map[string]interface{})
+			line := "> " + str(act["type"])
+			if target := actionTarget(act); target != "" {
+				line += " " + target
+			}
+			if i < len(results) {
+				res, _ := results[i].(map[string]interface{})
+				line += " [" + str(res["status"]) + "]"
+				if msg := firstLine(str(res["message"])); msg != "" {
+					line += " " + msg
+				}
+			}
+			fmt.Fprintln(t.out, line)
+		}
+
+	case "status":
+		t.endLine()
+		switch {
+		case d["new_status"] != nil:
+			line := fmt.Sprintf("* status %s -> %s", str(d["old_status"]), str(d["new_status"]))
+			if bead := str(d["bead_id"]); bead != "" {
+				line += " (" + bead + ")"
+			}
+			fmt.Fprintln(t.out, line)
+		case d["hold"] != nil:
+			hold := str(d["hold"])
+			if hold == "" {
+				hold = "resumed"
+			}
+			fmt.Fprintf(t.out, "* %s\n", hold)
+		}
+	}
+	return nil
+}
+
+func (t *agentTail) endLine() {
+	if t.midLine {
+		fmt.Fprintln(t.out)
+		t.midLine = false
+	}
+}
+
+// actionTarget picks the field that best says what an action is aimed at.
+func actionTarget(act map[string]interface{}) string {
+	for _, key := range []string{"path", "command", "query", "symbol", "branch", "commit_message", "question"} {
+		if v := str(act[key]); v != "" {
+			return truncate(firstLine(v), 80)
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return truncate(s, 120)
+}
+
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
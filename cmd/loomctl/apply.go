@@ -0,0 +1,745 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newApplyCommand() *cobra.Command {
+	var (
+		filename string
+		dryRun   bool
+		prune    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "apply -f <manifest>",
+		Short: "Reconcile projects, providers, workflows, and agents from a manifest",
+		Long: `Declaratively reconcile server state with a YAML manifest.
+
+The manifest lists projects (matched by name), providers (by id), workflows
+(by id, using the same schema as workflows/defaults), and agents (by project
+and name). apply compares each entry with the server and issues only the
+create and update calls needed to match it. Fields left out of the manifest
+are not managed.
+
+${VAR} references are replaced with environment variables, so secrets such
+as provider API keys can stay out of the file.
+
+With --prune, resources of a kind the manifest declares are deleted when the
+manifest doesn't list them. Agents are only pruned within the manifest's
+projects, and built-in global default workflows are never pruned.`,
+		Example: `  # Show what would change
+  loomctl apply -f loom.yaml --dry-run
+
+  # Apply, deleting anything the manifest no longer lists
+  loomctl apply -f loom.yaml --prune`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("--filename is required")
+			}
+			m, err := loadManifest(filename)
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			plan, err := planApply(client, m, prune)
+			if err != nil {
+				return err
+			}
+			return plan.run(client, dryRun)
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Manifest file (- for stdin)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without changing anything")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete resources of the declared kinds that the manifest doesn't list")
+	return cmd
+}
+
+// Manifest types. Optional booleans are pointers so that leaving them out
+// means "don't manage", not "false".
+
+type applyManifest struct {
+	Projects  []manifestProject  `yaml:"projects"`
+	Providers []manifestProvider `yaml:"providers"`
+	Workflows []manifestWorkflow `yaml:"workflows"`
+	Agents    []manifestAgent    `yaml:"agents"`
+}
+
+type manifestProject struct {
+	Name         string            `yaml:"name"`
+	GitRepo      string            `yaml:"git_repo"`
+	Branch       string            `yaml:"branch"`
+	BeadsPath    string            `yaml:"beads_path"`
+	GitStrategy  string            `yaml:"git_strategy"`
+	Context      map[string]string `yaml:"context"`
+	IsPerpetual  *bool             `yaml:"is_perpetual"`
+	IsSticky     *bool             `yaml:"is_sticky"`
+	UseContainer *bool             `yaml:"use_container"`
+}
+
+type manifestProvider struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Endpoint    string `yaml:"endpoint"`
+	Model       string `yaml:"model"`
+	APIKey      string `yaml:"api_key"`
+	Description string `yaml:"description"`
+}
+
+type manifestWorkflow struct {
+	ID           string         `yaml:"id" json:"id"`
+	Name         string         `yaml:"name" json:"name"`
+	Description  string         `yaml:"description" json:"description"`
+	WorkflowType string         `yaml:"workflow_type" json:"workflow_type"`
+	IsDefault    bool           `yaml:"is_default" json:"is_default"`
+	Project      string         `yaml:"project" json:"-"`
+	ProjectID    string         `yaml:"-" json:"project_id,omitempty"`
+	Nodes        []workflowNode `yaml:"nodes" json:"nodes"`
+	Edges        []workflowEdge `yaml:"edges" json:"edges"`
+}
+
+type workflowNode struct {
+	NodeKey        string            `yaml:"node_key" json:"node_key"`
+	NodeType       string            `yaml:"node_type" json:"node_type"`
+	RoleRequired   string            `yaml:"role_required" json:"role_required"`
+	PersonaHint    string            `yaml:"persona_hint" json:"persona_hint"`
+	MaxAttempts    int               `yaml:"max_attempts" json:"max_attempts"`
+	TimeoutMinutes int               `yaml:"timeout_minutes" json:"timeout_minutes"`
+	Instructions   string            `yaml:"instructions" json:"instructions"`
+	Metadata       map[string]string `yaml:"metadata" json:"metadata,omitempty"`
+}
+
+type workflowEdge struct {
+	FromNodeKey string `yaml:"from_node_key" json:"from_node_key"`
+	ToNodeKey   string `yaml:"to_node_key" json:"to_node_key"`
+	Condition   string `yaml:"condition" json:"condition"`
+	Priority    int    `yaml:"priority" json:"priority"`
+}
+
+type manifestAgent struct {
+	Name     string `yaml:"name"`
+	Persona  string `yaml:"persona"`
+	Project  string `yaml:"project"`
+	Provider string `yaml:"provider"`
+}
+
+var manifestEnvRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadManifest reads and validates a manifest, expanding ${VAR} references.
+func loadManifest(filename string) (*applyManifest, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var missing []string
+	data = manifestEnvRe.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := string(manifestEnvRe.FindSubmatch(ref)[1])
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return []byte(val)
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("manifest references unset environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	var m applyManifest
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, m.validate()
+}
+
+func (m *applyManifest) validate() error {
+	seen := map[string]bool{}
+	dup := func(kind, key string) error {
+		if seen[kind+"/"+key] {
+			return fmt.Errorf("%s %q is declared more than once", kind, key)
+		}
+		seen[kind+"/"+key] = true
+		return nil
+	}
+	for _, p := range m.Projects {
+		if p.Name == "" || p.GitRepo == "" || p.Branch == "" {
+			return fmt.Errorf("project %q: name, git_repo, and branch are required", p.Name)
+		}
+		if err := dup("project", p.Name); err != nil {
+			return err
+		}
+	}
+	for _, p := range m.Providers {
+		if p.ID == "" || p.Type == "" {
+			return fmt.Errorf("provider %q: id and type are required", p.ID)
+		}
+		if err := dup("provider", p.ID); err != nil {
+			return err
+		}
+	}
+	for _, w := range m.Workflows {
+		if w.ID == "" || w.Name == "" || w.WorkflowType == "" {
+			return fmt.Errorf("workflow %q: id, name, and workflow_type are required", w.ID)
+		}
+		if err := dup("workflow", w.ID); err != nil {
+			return err
+		}
+	}
+	for _, a := range m.Agents {
+		if a.Name == "" || a.Persona == "" || a.Project == "" {
+			return fmt.Errorf("agent %q: name, persona, and project are required", a.Name)
+		}
+		if err := dup("agent", a.Project+"/"+a.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Server state, decoded from the list endpoints.
+
+type currentProject struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	GitRepo      string            `json:"git_repo"`
+	Branch       string            `json:"branch"`
+	BeadsPath    string            `json:"beads_path"`
+	GitStrategy  string            `json:"git_strategy"`
+	Context      map[string]string `json:"context"`
+	IsPerpetual  bool              `json:"is_perpetual"`
+	IsSticky     bool              `json:"is_sticky"`
+	UseContainer bool              `json:"use_container"`
+}
+
+type currentAgent struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	PersonaName string `json:"persona_name"`
+	ProjectID   string `json:"project_id"`
+	ProviderID  string `json:"provider_id"`
+}
+
+type applyAction struct {
+	verb    string // create, update, replace, delete
+	kind    string
+	name    string
+	changes []string
+	run     func() error
+}
+
+type applyPlan struct {
+	actions   []applyAction
+	unchanged int
+}
+
+var applyVerbSymbols = map[string]string{"create": "+", "update": "~", "replace": "-/+", "delete": "-"}
+
+// run prints the plan and, unless dryRun is set, executes it in order.
+func (p *applyPlan) run(client *Client, dryRun bool) error {
+	counts := map[string]int{}
+	for _, a := range p.actions {
+		counts[a.verb]++
+		line := fmt.Sprintf("%s %s %s", applyVerbSymbols[a.verb], a.kind, a.name)
+		if len(a.changes) > 0 {
+			line += " (" + strings.Join(a.changes, ", ") + ")"
+		}
+		fmt.Println(line)
+		if dryRun {
+			continue
+		}
+		if err := a.run(); err != nil {
+			return fmt.Errorf("failed to %s %s %s: %w", a.verb, a.kind, a.name, err)
+		}
+	}
+	verb := "Applied"
+	if dryRun {
+		verb = "Plan"
+	}
+	fmt.Printf("%s: %d created, %d updated, %d replaced, %d deleted, %d unchanged\n",
+		verb, counts["create"], counts["update"], counts["replace"], counts["delete"], p.unchanged)
+	return nil
+}
+
+// planApply diffs the manifest against the server. Creates and updates run
+// in dependency order (projects and providers before workflows and agents);
+// prunes run afterwards, dependents first.
+func planApply(client *Client, m *applyManifest, prune bool) (*applyPlan, error) {
+	plan := &applyPlan{}
+	var deletes []applyAction
+
+	var projects []currentProject
+	if err := getJSON(client, "/api/v1/projects", &projects); err != nil {
+		return nil, err
+	}
+	// projectIDs maps project names to IDs, filled in as projects are created.
+	projectIDs := map[string]string{}
+	for _, p := range projects {
+		if _, dup := projectIDs[p.Name]; dup {
+			return nil, fmt.Errorf("more than one project is named %q; rename one before applying", p.Name)
+		}
+		projectIDs[p.Name] = p.ID
+	}
+	resolveProject := func(ref string) string {
+		if id, ok := projectIDs[ref]; ok {
+			return id
+		}
+		for _, p := range projects {
+			if p.ID == ref {
+				return ref
+			}
+		}
+		return ""
+	}
+
+	// Projects
+	declaredProjects := map[string]bool{}
+	for i := range m.Projects {
+		mp := m.Projects[i]
+		declaredProjects[mp.Name] = true
+		var cur *currentProject
+		for j := range projects {
+			if projects[j].Name == mp.Name {
+				cur = &projects[j]
+			}
+		}
+		if cur == nil {
+			plan.actions = append(plan.actions, applyAction{verb: "create", kind: "project", name: mp.Name, run: func() error {
+				var created currentProject
+				body := map[string]interface{}{
+					"name": mp.Name, "git_repo": mp.GitRepo, "branch": mp.Branch,
+					"beads_path": mp.BeadsPath, "context": mp.Context,
+				}
+				if mp.IsSticky != nil {
+					body["is_sticky"] = *mp.IsSticky
+				}
+				if err := postJSON(client, "/api/v1/projects", body, &created); err != nil {
+					return err
+				}
+				projectIDs[mp.Name] = created.ID
+				// Fields the create endpoint doesn't take are set with a follow-up update.
+				if updates, _ := projectUpdates(mp, &created); len(updates) > 0 {
+					_, err := client.put("/api/v1/projects/"+url.PathEscape(created.ID), updates)
+					return err
+				}
+				return nil
+			}})
+			continue
+		}
+		updates, changed := projectUpdates(mp, cur)
+		if len(changed) == 0 {
+			plan.unchanged++
+			continue
+		}
+		id := cur.ID
+		plan.actions = append(plan.actions, applyAction{verb: "update", kind: "project", name: mp.Name, changes: changed, run: func() error {
+			_, err := client.put("/api/v1/projects/"+url.PathEscape(id), updates)
+			return err
+		}})
+	}
+	if prune && len(m.Projects) > 0 {
+		for _, p := range projects {
+			if !declaredProjects[p.Name] {
+				id := p.ID
+				deletes = append(deletes, applyAction{verb: "delete", kind: "project", name: p.Name, run: func() error {
+					_, err := client.delete("/api/v1/projects/" + url.PathEscape(id))
+					return err
+				}})
+			}
+		}
+	}
+
+	// Providers
+	var providers []map[string]interface{}
+	if err := getJSON(client, "/api/v1/providers", &providers); err != nil {
+		return nil, err
+	}
+	declaredProviders := map[string]bool{}
+	for i := range m.Providers {
+		mp := m.Providers[i]
+		declaredProviders[mp.ID] = true
+		var cur map[string]interface{}
+		for _, p := range providers {
+			if stringField(p, "id") == mp.ID {
+				cur = p
+			}
+		}
+		if cur == nil {
+			plan.actions = append(plan.actions, applyAction{verb: "create", kind: "provider", name: mp.ID, run: func() error {
+				return postJSON(client, "/api/v1/providers", map[string]interface{}{
+					"id": mp.ID, "name": mp.Name, "type": mp.Type, "endpoint": mp.Endpoint,
+					"model": mp.Model, "api_key": mp.APIKey, "description": mp.Description,
+				}, nil)
+			}})
+			continue
+		}
+		changed := providerChanges(mp, cur)
+		if len(changed) == 0 {
+			plan.unchanged++
+			continue
+		}
+		plan.actions = append(plan.actions, applyAction{verb: "update", kind: "provider", name: mp.ID, changes: changed, run: func() error {
+			// PUT replaces the whole provider, so start from the server's copy.
+			body := map[string]interface{}{}
+			for k, v := range cur {
+				body[k] = v
+			}
+			setIfNotEmpty(body, "name", mp.Name)
+			setIfNotEmpty(body, "type", mp.Type)
+			setIfNotEmpty(body, "endpoint", mp.Endpoint)
+			setIfNotEmpty(body, "description", mp.Description)
+			setIfNotEmpty(body, "api_key", mp.APIKey)
+			if mp.Model != "" {
+				body["model"], body["configured_model"], body["selected_model"] = mp.Model, mp.Model, mp.Model
+			}
+			_, err := client.put("/api/v1/providers/"+url.PathEscape(mp.ID), body)
+			return err
+		}})
+	}
+	if prune && len(m.Providers) > 0 {
+		for _, p := range providers {
+			id := stringField(p, "id")
+			if !declaredProviders[id] {
+				deletes = append(deletes, applyAction{verb: "delete", kind: "provider", name: id, run: func() error {
+					_, err := client.delete("/api/v1/providers/" + url.PathEscape(id))
+					return err
+				}})
+			}
+		}
+	}
+
+	// Workflows
+	var wfList struct {
+		Workflows []manifestWorkflow `json:"workflows"`
+	}
+	if err := getJSON(client, "/api/v1/workflows", &wfList); err != nil {
+		return nil, err
+	}
+	declaredWorkflows := map[string]bool{}
+	for i := range m.Workflows {
+		mw := m.Workflows[i]
+		declaredWorkflows[mw.ID] = true
+		if mw.Project != "" && resolveProject(mw.Project) == "" && !declaredProjects[mw.Project] {
+			return nil, fmt.Errorf("workflow %s: unknown project %q", mw.ID, mw.Project)
+		}
+		var cur *manifestWorkflow
+		for j := range wfList.Workflows {
+			if wfList.Workflows[j].ID == mw.ID {
+				cur = &wfList.Workflows[j]
+			}
+		}
+		save := func(method string) func() error {
+			return func() error {
+				body := mw
+				if mw.Project != "" {
+					body.ProjectID = resolveProject(mw.Project)
+				}
+				if method == "POST" {
+					return postJSON(client, "/api/v1/workflows", body, nil)
+				}
+				_, err := client.put("/api/v1/workflows/"+url.PathEscape(mw.ID), body)
+				return err
+			}
+		}
+		if cur == nil {
+			plan.actions = append(plan.actions, applyAction{verb: "create", kind: "workflow", name: mw.ID, run: save("POST")})
+			continue
+		}
+		changed := workflowChanges(&mw, cur, resolveProject(mw.Project))
+		if len(changed) == 0 {
+			plan.unchanged++
+			continue
+		}
+		plan.actions = append(plan.actions, applyAction{verb: "update", kind: "workflow", name: mw.ID, changes: changed, run: save("PUT")})
+	}
+	if prune && len(m.Workflows) > 0 {
+		for _, w := range wfList.Workflows {
+			if declaredWorkflows[w.ID] || (w.IsDefault && w.ProjectID == "") {
+				continue
+			}
+			id := w.ID
+			deletes = append(deletes, applyAction{verb: "delete", kind: "workflow", name: id, run: func() error {
+				_, err := client.delete("/api/v1/workflows/" + url.PathEscape(id))
+				return err
+			}})
+		}
+	}
+
+	// Agents
+	var agents []currentAgent
+	if err := getJSON(client, "/api/v1/agents", &agents); err != nil {
+		return nil, err
+	}
+	declaredAgents := map[string]bool{}
+	managedProjects := map[string]bool{}
+	for i := range m.Agents {
+		ma := m.Agents[i]
+		projectID := resolveProject(ma.Project)
+		if projectID == "" && !declaredProjects[ma.Project] {
+			return nil, fmt.Errorf("agent %s: unknown project %q", ma.Name, ma.Project)
+		}
+		if ma.Provider != "" && !declaredProviders[ma.Provider] && !hasProvider(providers, ma.Provider) {
+			return nil, fmt.Errorf("agent %s: unknown provider %q", ma.Name, ma.Provider)
+		}
+		managedProjects[projectID] = true
+		declaredAgents[projectID+"/"+ma.Name] = true
+
+		var cur *currentAgent
+		for j := range agents {
+			if projectID != "" && agents[j].ProjectID == projectID && agents[j].Name == ma.Name {
+				cur = &agents[j]
+			}
+		}
+		name := ma.Project + "/" + ma.Name
+		create := func() error {
+			return postJSON(client, "/api/v1/agents", map[string]interface{}{
+				"name": ma.Name, "persona_name": ma.Persona,
+				"project_id": resolveProject(ma.Project), "provider_id": ma.Provider,
+			}, nil)
+		}
+		if cur == nil {
+			plan.actions = append(plan.actions, applyAction{verb: "create", kind: "agent", name: name, run: create})
+			continue
+		}
+		// Agents can't change persona or provider in place, so replace them.
+		var changed []string
+		if qualifyPersona(ma.Persona) != qualifyPersona(cur.PersonaName) {
+			changed = append(changed, "persona")
+		}
+		if ma.Provider != "" && ma.Provider != cur.ProviderID {
+			changed = append(changed, "provider")
+		}
+		if len(changed) == 0 {
+			plan.unchanged++
+			continue
+		}
+		id := cur.ID
+		plan.actions = append(plan.actions, applyAction{verb: "replace", kind: "agent", name: name, changes: changed, run: func() error {
+			if _, err := client.delete("/api/v1/agents/" + url.PathEscape(id)); err != nil {
+				return err
+			}
+			return create()
+		}})
+	}
+	if prune && len(m.Agents) > 0 {
+		for _, a := range agents {
+			if !managedProjects[a.ProjectID] || declaredAgents[a.ProjectID+"/"+a.Name] {
+				continue
+			}
+			id, project := a.ID, a.ProjectID
+			for _, p := range projects {
+				if p.ID == a.ProjectID {
+					project = p.Name
+				}
+			}
+			deletes = append(deletes, applyAction{verb: "delete", kind: "agent", name: project + "/" + a.Name, run: func() error {
+				_, err := client.delete("/api/v1/agents/" + url.PathEscape(id))
+				return err
+			}})
+		}
+	}
+
+	// Prune dependents first: agents, then workflows, providers, projects.
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return deleteOrder(deletes[i].kind) < deleteOrder(deletes[j].kind)
+	})
+	plan.actions = append(plan.actions, deletes...)
+	return plan, nil
+}
+
+func deleteOrder(kind string) int {
+	return map[string]int{"agent": 0, "workflow": 1, "provider": 2, "project": 3}[kind]
+}
+
+// projectUpdates returns the PUT body and changed field names needed to
+// bring cur in line with mp.
+func projectUpdates(mp manifestProject, cur *currentProject) (map[string]interface{}, []string) {
+	updates := map[string]interface{}{}
+	setString := func(field, want, have string) {
+		if want != "" && want != have {
+			updates[field] = want
+		}
+	}
+	setBool := func(field string, want *bool, have bool) {
+		if want != nil && *want != have {
+			updates[field] = *want
+		}
+	}
+	setString("git_repo", mp.GitRepo, cur.GitRepo)
+	setString("branch", mp.Branch, cur.Branch)
+	setString("beads_path", mp.BeadsPath, cur.BeadsPath)
+	setString("git_strategy", mp.GitStrategy, cur.GitStrategy)
+	setBool("is_perpetual", mp.IsPerpetual, cur.IsPerpetual)
+	setBool("is_sticky", mp.IsSticky, cur.IsSticky)
+	setBool("use_container", mp.UseContainer, cur.UseContainer)
+	if mp.Context != nil && !reflect.DeepEqual(mp.Context, nonNilMap(cur.Context)) {
+		updates["context"] = mp.Context
+	}
+	changed := make([]string, 0, len(updates))
+	for k := range updates {
+		changed = append(changed, k)
+	}
+	sort.Strings(changed)
+	return updates, changed
+}
+
+func providerChanges(mp manifestProvider, cur map[string]interface{}) []string {
+	var changed []string
+	check := func(field, want, have string) {
+		if want != "" && want != have {
+			changed = append(changed, field)
+		}
+	}
+	check("name", mp.Name, stringField(cur, "name"))
+	check("type", mp.Type, stringField(cur, "type"))
+	check("description", mp.Description, stringField(cur, "description"))
+	if mp.Endpoint != "" && normalizeEndpoint(mp.Endpoint) != normalizeEndpoint(stringField(cur, "endpoint")) {
+		changed = append(changed, "endpoint")
+	}
+	model := stringField(cur, "configured_model")
+	if model == "" {
+		model = stringField(cur, "model")
+	}
+	check("model", mp.Model, model)
+	// The server only returns API keys to some callers; compare when it does.
+	if have := stringField(cur, "api_key"); have != "" {
+		check("api_key", mp.APIKey, have)
+	}
+	return changed
+}
+
+func workflowChanges(want, have *manifestWorkflow, projectID string) []string {
+	var changed []string
+	if want.Name != have.Name {
+		changed = append(changed, "name")
+	}
+	if want.Description != have.Description {
+		changed = append(changed, "description")
+	}
+	if want.WorkflowType != have.WorkflowType {
+		changed = append(changed, "workflow_type")
+	}
+	if want.IsDefault != have.IsDefault {
+		changed = append(changed, "is_default")
+	}
+	if projectID != have.ProjectID {
+		changed = append(changed, "project")
+	}
+
+	haveNodes := map[string]workflowNode{}
+	for _, n := range have.Nodes {
+		n.Metadata = nonNilMap(n.Metadata)
+		haveNodes[n.NodeKey] = n
+	}
+	nodesChanged := len(want.Nodes) != len(haveNodes)
+	for _, n := range want.Nodes {
+		n.Metadata = nonNilMap(n.Metadata)
+		if h, ok := haveNodes[n.NodeKey]; !ok || !reflect.DeepEqual(n, h) {
+			nodesChanged = true
+		}
+	}
+	if nodesChanged {
+		changed = append(changed, "nodes")
+	}
+	if !reflect.DeepEqual(edgeSet(want.Edges), edgeSet(have.Edges)) {
+		changed = append(changed, "edges")
+	}
+	return changed
+}
+
+// edgeSet returns the distinct edges as sorted keys, so that order and
+// duplicate rows don't count as a difference.
+func edgeSet(edges []workflowEdge) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, e := range edges {
+		k := fmt.Sprintf("%s>%s:%s:%d", e.FromNodeKey, e.ToNodeKey, e.Condition, e.Priority)
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// qualifyPersona mirrors the server: bare persona names live under default/.
+func qualifyPersona(name string) string {
+	if name != "" && !strings.Contains(name, "/") {
+		return "default/" + name
+	}
+	return name
+}
+
+// normalizeEndpoint mirrors the server's /v1 suffixing of provider endpoints.
+func normalizeEndpoint(endpoint string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1")
+}
+
+func hasProvider(providers []map[string]interface{}, id string) bool {
+	for _, p := range providers {
+		if stringField(p, "id") == id {
+			return true
+		}
+	}
+	return false
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func setIfNotEmpty(m map[string]interface{}, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func getJSON(client *Client, path string, v interface{}) error {
+	data, err := client.get(path, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+func postJSON(client *Client, path string, body, v interface{}) error {
+	data, err := client.post(path, body)
+	if err != nil || v == nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func beadAttachmentsPath(beadID string) string {
+	return "/api/v1/beads/" + url.PathEscape(beadID) + "/attachments"
+}
+
+func newBeadAttachCommand() *cobra.Command {
+	var (
+		name        string
+		contentType string
+		deleteID    string
+	)
+	cmd := &cobra.Command{
+		Use:   "attach <bead-id> [file]",
+		Short: "Attach a file (log, patch, screenshot) to a bead",
+		Long: `Attach a file to a bead. Use - to read from stdin, with --name to set the
+filename. The content type is inferred from the file extension and contents
+unless --content-type is given; the server rejects types it doesn't allow.`,
+		Args: cobra.RangeArgs(1, 2),
+		Example: `  loomctl bead attach loom-001 build.log
+  loomctl bead attach loom-001 fix.patch
+  go test ./... 2>&1 | loomctl bead attach loom-001 - --name=test-output.log
+  loomctl bead attach loom-001 --delete <attachment-id>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			base := beadAttachmentsPath(args[0])
+
+			if deleteID != "" {
+				if len(args) > 1 {
+					return fmt.Errorf("--delete does not take a file")
+				}
+				if _, err := client.delete(base + "/" + url.PathEscape(deleteID)); err != nil {
+					return err
+				}
+				fmt.Printf("Deleted attachment %s\n", deleteID)
+				return nil
+			}
+
+			if len(args) < 2 {
+				return fmt.Errorf("a file to attach is required")
+			}
+			var content []byte
+			var err error
+			filename := name
+			if args[1] == "-" {
+				if filename == "" {
+					return fmt.Errorf("--name is required when reading from stdin")
+				}
+				content, err = io.ReadAll(os.Stdin)
+			} else {
+				if filename == "" {
+					filename = filepath.Base(args[1])
+				}
+				content, err = os.ReadFile(args[1])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[1], err)
+			}
+
+			data, err := client.upload(base, filename, contentType, content)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Filename to store (defaults to the file's base name)")
+	cmd.Flags().StringVar(&contentType, "content-type", "", "Content type, e.g. text/plain or image/png")
+	cmd.Flags().StringVar(&deleteID, "delete", "", "Delete the attachment with this ID instead of adding one")
+	return cmd
+}
+
+func newBeadAttachmentsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "attachments <bead-id>",
+		Short:   "List files attached to a bead",
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl bead attachments loom-001 -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get(beadAttachmentsPath(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadDownloadCommand() *cobra.Command {
+	var (
+		dest  string
+		force bool
+	)
+	cmd := &cobra.Command{
+		Use:   "download <bead-id> <attachment-id>",
+		Short: "Download a file attached to a bead",
+		Long: `Download an attachment. It is saved under its original filename in the
+current directory unless --file is given; --file=- writes to stdout.`,
+		Args: cobra.ExactArgs(2),
+		Example: `  loomctl bead download loom-001 <attachment-id>
+  loomctl bead download loom-001 <attachment-id> --file=- | less`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, header, err := client.download(beadAttachmentsPath(args[0]) + "/" + url.PathEscape(args[1]))
+			if err != nil {
+				return err
+			}
+
+			if dest == "-" {
+				_, err := os.Stdout.Write(data)
+				return err
+			}
+			if dest == "" {
+				if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+					dest = filepath.Base(params["filename"])
+				}
+				if dest == "" || dest == "." || dest == "/" {
+					dest = args[1]
+				}
+			}
+
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if !force {
+				flags |= os.O_EXCL
+			}
+			f, err := os.OpenFile(dest, flags, 0o644)
+			if err != nil {
+				if os.IsExist(err) {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", dest)
+				}
+				return err
+			}
+			if _, err := f.Write(data); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Saved %s (%d bytes)\n", dest, len(data))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dest, "file", "f", "", "Where to save the file (- for stdout)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing file")
+	return cmd
+}
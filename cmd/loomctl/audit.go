@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log of mutating API requests",
+		Long: `Query the audit log. Every POST, PUT, PATCH, and DELETE made against the
+API is recorded with the user who made it and the response status. Changes
+to beads, projects, and providers also record the resource before and
+after, with a field-level diff.`,
+	}
+	cmd.AddCommand(newAuditListCommand())
+	return cmd
+}
+
+func newAuditListCommand() *cobra.Command {
+	var (
+		since        string
+		until        string
+		actor        string
+		resourceType string
+		resourceID   string
+		method       string
+		limit        int
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List audit log entries, newest first",
+		Example: `  loomctl audit list --since 24h --actor user-admin
+  loomctl audit list --resource-type providers --since 7d
+  loomctl audit list --resource-type beads --resource-id loom-abc123`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			for name, value := range map[string]string{
+				"since":         since,
+				"until":         until,
+				"actor":         actor,
+				"resource_type": resourceType,
+				"resource_id":   resourceID,
+				"method":        method,
+			} {
+				if value != "" {
+					params.Set(name, value)
+				}
+			}
+			if limit > 0 {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get("/api/v1/audit", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Show entries since a timestamp (RFC3339) or duration (e.g. 24h, 7d)")
+	cmd.Flags().StringVar(&until, "until", "", "Show entries up to a timestamp (RFC3339)")
+	cmd.Flags().StringVar(&actor, "actor", "", "Filter by user ID or username")
+	cmd.Flags().StringVar(&resourceType, "resource-type", "", "Filter by resource type (e.g. beads, projects, providers)")
+	cmd.Flags().StringVar(&resourceID, "resource-id", "", "Filter by resource ID")
+	cmd.Flags().StringVar(&method, "method", "", "Filter by HTTP method")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 100, "Number of entries")
+	return cmd
+}
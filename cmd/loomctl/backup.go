@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newBackupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Take, verify and restore backups of the database and beads",
+		Long: `Take, verify and restore backups. A backup holds a dump of the database
+and a tarball of each project's beads directory, with a manifest of their
+SHA-256 digests. The server takes one on the schedule in config.yaml's
+backup section and keeps the newest backup.retain.`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:         "create",
+		Short:       "Take a backup now",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/admin/backups", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/admin/backups", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show <backup-id>",
+		Short: "Show a backup's manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/admin/backups/"+url.PathEscape(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:         "verify <backup-id>",
+		Short:       "Check that a backup's objects match their digests and can be read",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/admin/backups/"+url.PathEscape(args[0])+"/verify", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(newBackupRestoreCommand())
+	return cmd
+}
+
+func newBackupRestoreCommand() *cobra.Command {
+	var restoreDB, allProjects bool
+	var projects []string
+	cmd := &cobra.Command{
+		Use:   "restore <backup-id>",
+		Short: "Restore the database or projects' beads from a backup",
+		Long: `Restore the database, some projects' beads, or both from a backup. The
+backup is verified first; nothing changes if it is damaged. Restoring
+requires maintenance mode.
+
+A project's beads directory is replaced with the backed up one, reloaded
+and committed to its beads-sync branch. A PostgreSQL database is restored
+in place with pg_restore. A SQLite database can't be replaced while loom
+runs: it is written next to the live file, and the result says how to
+swap it in.`,
+		Example: `  loomctl admin maintenance on --reason "restoring backup"
+  loomctl backup restore 20261016T020000Z --project loom
+  loomctl backup restore 20261016T020000Z --database --all-projects
+  loomctl admin maintenance off`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/admin/backups/"+url.PathEscape(args[0])+"/restore", map[string]interface{}{
+				"database":     restoreDB,
+				"projects":     projects,
+				"all_projects": allProjects,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&restoreDB, "database", false, "Restore the database")
+	cmd.Flags().StringArrayVar(&projects, "project", nil, "Restore this project's beads (repeatable)")
+	cmd.Flags().BoolVar(&allProjects, "all-projects", false, "Restore the beads of every project in the backup")
+	return cmd
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newBeadTypeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "type",
+		Short: "Manage bead types",
+		Long: `Manage the registered bead types. A registered type gives new beads of
+that type a default priority, context fields they must be created with, the
+workflow the dispatcher starts for them, and an icon and color for the UI.
+Types that aren't registered are accepted as before. Without a subcommand,
+lists every registered type.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/bead-types", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.AddCommand(newBeadTypeShowCommand())
+	cmd.AddCommand(newBeadTypeSetCommand())
+	cmd.AddCommand(newBeadTypeDeleteCommand())
+	return cmd
+}
+
+func newBeadTypeShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "show <name>",
+		Short:   "Show a bead type",
+		Example: `  loomctl bead type show incident`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/bead-types/"+url.PathEscape(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadTypeSetCommand() *cobra.Command {
+	var (
+		description string
+		priority    int
+		required    []string
+		workflow    string
+		icon        string
+		color       string
+	)
+	cmd := &cobra.Command{
+		Use:   "set <name>",
+		Short: "Define a bead type, replacing an existing one (admin only)",
+		Example: `  loomctl bead type set incident --priority=0 --require=severity --require=affected_service --workflow=bug --icon=🚨 --color=#d73a49
+  loomctl bead type set spike --priority=3 --workflow=feature --description="Time-boxed investigation"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]interface{}{
+				"name":             args[0],
+				"description":      description,
+				"required_context": required,
+				"default_workflow": workflow,
+				"icon":             icon,
+				"color":            color,
+			}
+			if cmd.Flags().Changed("priority") {
+				body["default_priority"] = priority
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/bead-types", body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&description, "description", "d", "", "What the type is for")
+	cmd.Flags().IntVar(&priority, "priority", 2, "Default priority (0-3) for beads created without one")
+	cmd.Flags().StringArrayVar(&required, "require", nil, "Context field beads of this type must be created with (repeatable)")
+	cmd.Flags().StringVar(&workflow, "workflow", "", "Workflow type started for these beads, e.g. bug, feature, ui")
+	cmd.Flags().StringVar(&icon, "icon", "", "Icon shown for the type in the UI")
+	cmd.Flags().StringVar(&color, "color", "", "Color shown for the type in the UI, e.g. #d73a49")
+	return cmd
+}
+
+func newBeadTypeDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Unregister a bead type; its beads are kept (admin only)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/bead-types/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted bead type %s\n", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func projectBoardPath(projectID string) string {
+	return "/api/v1/projects/" + url.PathEscape(projectID) + "/board"
+}
+
+func newProjectBoardCommand() *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "board <project-id>",
+		Short: "Show a project's kanban board with per-column WIP limits",
+		Long: `Show a project's beads grouped into board columns. Each column lists its
+count and WIP limit; a column at its limit refuses claims and status changes
+that would move another bead into it. Projects without a custom board use
+the default Open / Blocked / In Progress / Closed columns with no limits.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  loomctl project board loom-self
+  loomctl project board loom-self --limit=0
+  loomctl project board set loom-self --column="To Do=open" --column="Doing=in_progress,blocked:3" --column="Done=closed"
+  loomctl project board reset loom-self`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if cmd.Flags().Changed("limit") {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get(projectBoardPath(args[0]), params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 0, "Beads to list per column (0 for counts only)")
+	cmd.AddCommand(newProjectBoardSetCommand())
+	cmd.AddCommand(newProjectBoardResetCommand())
+	return cmd
+}
+
+func newProjectBoardSetCommand() *cobra.Command {
+	var columns []string
+	cmd := &cobra.Command{
+		Use:   "set <project-id>",
+		Short: "Replace a project's board columns",
+		Long: `Replace a project's board columns. Each --column is NAME=STATUS[,STATUS...]
+with an optional :LIMIT suffix for the column's WIP limit. Columns appear in
+the order given, and every bead status (open, blocked, in_progress, closed)
+must belong to exactly one column.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl project board set loom-self --column="Backlog=open,blocked" --column="Doing=in_progress:2" --column="Done=closed"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(columns) == 0 {
+				return fmt.Errorf("at least one --column is required")
+			}
+			body := make([]map[string]interface{}, 0, len(columns))
+			for _, spec := range columns {
+				col, err := parseBoardColumn(spec)
+				if err != nil {
+					return err
+				}
+				body = append(body, col)
+			}
+			client := newClient()
+			data, err := client.put(projectBoardPath(args[0]), map[string]interface{}{"columns": body})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&columns, "column", nil, "Column as NAME=STATUS[,STATUS...][:LIMIT] (repeatable)")
+	return cmd
+}
+
+func newProjectBoardResetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reset <project-id>",
+		Short: "Restore a project's default board",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete(projectBoardPath(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Reset board for project %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// parseBoardColumn parses NAME=STATUS[,STATUS...][:LIMIT].
+func parseBoardColumn(spec string) (map[string]interface{}, error) {
+	name, rest, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.TrimSpace(rest) == "" {
+		return nil, fmt.Errorf("invalid column %q: want NAME=STATUS[,STATUS...][:LIMIT]", spec)
+	}
+	wipLimit := 0
+	if statuses, limit, ok := strings.Cut(rest, ":"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid WIP limit in column %q", spec)
+		}
+		rest, wipLimit = statuses, n
+	}
+	var statuses []string
+	for _, s := range strings.Split(rest, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			statuses = append(statuses, s)
+		}
+	}
+	return map[string]interface{}{
+		"name":      name,
+		"statuses":  statuses,
+		"wip_limit": wipLimit,
+	}, nil
+}
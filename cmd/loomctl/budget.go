@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newAnalyticsBudgetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Manage token and cost budgets",
+		Long: `Manage daily and monthly token/cost budgets for projects, agents, and
+providers. Usage is taken from analytics. When a budget is exhausted, loom
+stops dispatching work it covers until the period resets (midnight UTC, or
+the first of the month for monthly budgets).
+
+Without a subcommand, lists every budget with its current usage.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/budgets", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.AddCommand(newAnalyticsBudgetSetCommand())
+	cmd.AddCommand(newAnalyticsBudgetShowCommand())
+	cmd.AddCommand(newAnalyticsBudgetDeleteCommand())
+	return cmd
+}
+
+func newAnalyticsBudgetSetCommand() *cobra.Command {
+	var (
+		projectID  string
+		agentID    string
+		providerID string
+		period     string
+		tokens     int64
+		cost       float64
+	)
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a budget for a project, agent, or provider",
+		Example: `  loomctl analytics budget set --project=loom-self --tokens=2000000
+  loomctl analytics budget set --provider=tokenhub --period=monthly --cost=500`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope, scopeID := "", ""
+			for _, s := range []struct{ scope, id string }{
+				{"project", projectID}, {"agent", agentID}, {"provider", providerID},
+			} {
+				if s.id == "" {
+					continue
+				}
+				if scope != "" {
+					return fmt.Errorf("specify only one of --project, --agent, --provider")
+				}
+				scope, scopeID = s.scope, s.id
+			}
+			if scope == "" {
+				return fmt.Errorf("one of --project, --agent, --provider is required")
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/budgets", map[string]interface{}{
+				"scope":          scope,
+				"scope_id":       scopeID,
+				"period":         period,
+				"token_limit":    tokens,
+				"cost_limit_usd": cost,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID")
+	cmd.Flags().StringVar(&agentID, "agent", "", "Agent ID")
+	cmd.Flags().StringVar(&providerID, "provider", "", "Provider ID")
+	cmd.Flags().StringVar(&period, "period", "daily", "Budget period: daily or monthly")
+	cmd.Flags().Int64Var(&tokens, "tokens", 0, "Token limit per period (0 = no token limit)")
+	cmd.Flags().Float64Var(&cost, "cost", 0, "Cost limit in USD per period (0 = no cost limit)")
+	return cmd
+}
+
+func newAnalyticsBudgetShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <budget-id>",
+		Short: "Show a budget and its current usage",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/budgets/"+url.PathEscape(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newAnalyticsBudgetDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <budget-id>",
+		Short: "Delete a budget",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/budgets/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted budget %s\n", args[0])
+			return nil
+		},
+	}
+}
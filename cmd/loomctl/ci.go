@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newCICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Inspect CI on the branches agents push",
+	}
+	cmd.AddCommand(newCIStatusCommand())
+	return cmd
+}
+
+func newCIStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <bead-id>",
+		Short: "Show the CI checks on a bead's branch",
+		Long: `Show the CI checks on the branch a bead's agent pushed (the branch of its
+pull request, or bead/<id>) and their combined state: pending, passed, or
+failed. Checks come from GitHub, or from Jenkins for projects whose
+ci_provider setting is jenkins. While the bead's workflow waits at a ci node,
+only the checks the node requires decide the state.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl ci status loom-001`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/beads/"+url.PathEscape(args[0])+"/ci", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
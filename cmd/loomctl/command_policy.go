@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newCommandPolicyCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "command-policy",
+		Short: "Manage the allow and deny rules for agent shell commands",
+		Long: `Manage the rules agent shell commands are checked against before they run.
+A rule without --project applies to every project.
+
+A matching deny rule always blocks a command. If any allow rule applies to
+a project, commands there must also match one of them. Blocked commands are
+recorded as violations and, for rules created with --decision, raised as a
+decision bead that blocks the agent's bead.
+
+Patterns are regular expressions matched anywhere in the command, or with
+--syntax=glob, globs matched against the whole command. Runs of whitespace
+in the command are collapsed to one space before matching.
+
+Without a subcommand, lists the rules (for --project, the rules that apply
+to it).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			data, err := client.get("/api/v1/commands/policies", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only list rules that apply to this project")
+	cmd.AddCommand(newCommandPolicyAddCommand())
+	cmd.AddCommand(newCommandPolicyUpdateCommand())
+	cmd.AddCommand(newCommandPolicyDeleteCommand())
+	cmd.AddCommand(newCommandPolicyCheckCommand())
+	cmd.AddCommand(newCommandPolicyViolationsCommand())
+	return cmd
+}
+
+// commandPolicyFlags are the rule fields shared by add and update.
+type commandPolicyFlags struct {
+	projectID   string
+	effect      string
+	syntax      string
+	pattern     string
+	description string
+	decision    bool
+}
+
+func (f *commandPolicyFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.projectID, "project", "p", "", "Project ID (omit for all projects)")
+	cmd.Flags().StringVar(&f.effect, "effect", "deny", "allow or deny")
+	cmd.Flags().StringVar(&f.syntax, "syntax", "regex", "Pattern syntax: regex or glob")
+	cmd.Flags().StringVar(&f.pattern, "pattern", "", "Pattern to match commands against (required)")
+	cmd.Flags().StringVar(&f.description, "description", "", "Why the rule exists, shown when it blocks a command")
+	cmd.Flags().BoolVar(&f.decision, "decision", false, "Raise a decision bead when the rule blocks a command")
+}
+
+func (f *commandPolicyFlags) body() (map[string]interface{}, error) {
+	if f.pattern == "" {
+		return nil, fmt.Errorf("--pattern is required")
+	}
+	return map[string]interface{}{
+		"project_id":      f.projectID,
+		"effect":          f.effect,
+		"syntax":          f.syntax,
+		"pattern":         f.pattern,
+		"description":     f.description,
+		"create_decision": f.decision,
+	}, nil
+}
+
+func newCommandPolicyAddCommand() *cobra.Command {
+	var f commandPolicyFlags
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a command policy rule",
+		Example: `  loomctl command-policy add --pattern='rm\s+-[a-zA-Z]*r[a-zA-Z]*f?\s+/(\s|$)' --description="Deletes the root filesystem"
+  loomctl command-policy add --pattern='curl[^|]*\|\s*(ba)?sh' --decision
+  loomctl command-policy add --project=loom-self --effect=allow --syntax=glob --pattern='go test *'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body, err := f.body()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/commands/policies", body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	f.register(cmd)
+	return cmd
+}
+
+func newCommandPolicyUpdateCommand() *cobra.Command {
+	var f commandPolicyFlags
+	cmd := &cobra.Command{
+		Use:   "update <rule-id>",
+		Short: "Replace a command policy rule",
+		Long:  `Replace a rule's project, effect, pattern, and settings. Flags not given take their defaults, not the rule's current values.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body, err := f.body()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.put("/api/v1/commands/policies/"+url.PathEscape(args[0]), body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	f.register(cmd)
+	return cmd
+}
+
+func newCommandPolicyDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <rule-id>",
+		Short: "Delete a command policy rule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/commands/policies/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted command policy rule %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newCommandPolicyCheckCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:     "check <command>",
+		Short:   "Show whether a command would be allowed, without running it",
+		Example: `  loomctl command-policy check --project=loom-self "git push --force origin main"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/commands/policies/check", map[string]interface{}{
+				"project_id": projectID,
+				"command":    args[0],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID")
+	return cmd
+}
+
+func newCommandPolicyViolationsCommand() *cobra.Command {
+	var (
+		projectID string
+		limit     int
+	)
+	cmd := &cobra.Command{
+		Use:   "violations",
+		Short: "List recently blocked commands, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			if limit > 0 {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get("/api/v1/commands/policies/violations", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only list violations in this project")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum violations to list (default 100)")
+	return cmd
+}
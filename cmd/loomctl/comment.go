@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newBeadCommentCommand() *cobra.Command {
+	var (
+		message  string
+		replyTo  string
+		deleteID string
+	)
+	cmd := &cobra.Command{
+		Use:   "comment <bead-id>",
+		Short: "Comment on a bead (agents see recent comments when working it)",
+		Args:  cobra.ExactArgs(1),
+		Example: `  loomctl bead comment loom-001 -m "Use the existing retry helper, not a new one"
+  loomctl bead comment loom-001 -m "Agreed" --reply-to <comment-id>
+  loomctl bead comment loom-001 --delete <comment-id>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			base := "/api/v1/beads/" + url.PathEscape(args[0]) + "/comments"
+
+			if deleteID != "" {
+				if message != "" {
+					return fmt.Errorf("--delete cannot be combined with --message")
+				}
+				if _, err := client.delete(base + "/" + url.PathEscape(deleteID)); err != nil {
+					return err
+				}
+				fmt.Printf("Deleted comment %s\n", deleteID)
+				return nil
+			}
+
+			if message == "" {
+				return fmt.Errorf("--message is required")
+			}
+			body := map[string]interface{}{"content": message}
+			if replyTo != "" {
+				body["parent_id"] = replyTo
+			}
+			data, err := client.post(base, body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Comment text; @username mentions notify that user")
+	cmd.Flags().StringVar(&replyTo, "reply-to", "", "ID of the comment to reply to")
+	cmd.Flags().StringVar(&deleteID, "delete", "", "Delete the comment with this ID instead of adding one")
+	return cmd
+}
+
+func newBeadCommentsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "comments <bead-id>",
+		Short:   "List comments on a bead",
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl bead comments loom-001`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/beads/"+url.PathEscape(args[0])+"/comments", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
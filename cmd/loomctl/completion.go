@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the server queries made while completing, so a
+// slow or unreachable server doesn't hang the shell.
+const completionTimeout = 3 * time.Second
+
+// completionSource is a server list that IDs are completed from.
+type completionSource struct {
+	path      string
+	pageKey   string // set for paginated endpoints
+	descField string
+}
+
+var completionSources = map[string]completionSource{
+	"beads":     {path: "/api/v1/beads", pageKey: "beads", descField: "title"},
+	"projects":  {path: "/api/v1/projects", descField: "name"},
+	"agents":    {path: "/api/v1/agents", descField: "name"},
+	"providers": {path: "/api/v1/providers", descField: "name"},
+}
+
+// argCompletions maps the argument placeholders used in command Use lines
+// to the IDs they are completed with. Placeholders ending in -ids take any
+// number of IDs.
+var argCompletions = map[string]string{
+	"bead-id":     "beads",
+	"bead-ids":    "beads",
+	"project-id":  "projects",
+	"project":     "projects",
+	"agent-id":    "agents",
+	"provider-id": "providers",
+}
+
+// flagCompletions maps flag names to the IDs they are completed with.
+var flagCompletions = map[string]string{
+	"bead":     "beads",
+	"project":  "projects",
+	"agent":    "agents",
+	"provider": "providers",
+}
+
+var placeholderPattern = regexp.MustCompile(`[<\[]([^>\]]+)[>\]]`)
+
+// usePlaceholders returns the argument placeholders of a Use line, such as
+// "bead-id" for "show <bead-id>".
+func usePlaceholders(use string) []string {
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(use, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// registerDynamicCompletion walks the command tree and completes bead,
+// project, agent, and provider IDs, in positional arguments and flags, by
+// querying the server.
+func registerDynamicCompletion(cmd *cobra.Command) {
+	placeholders := usePlaceholders(cmd.Use)
+	if cmd.ValidArgsFunction == nil && len(placeholders) > 0 {
+		if completesAny(placeholders) {
+			cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				i := len(args)
+				if i >= len(placeholders) {
+					if last := placeholders[len(placeholders)-1]; strings.HasSuffix(last, "-ids") {
+						i = len(placeholders) - 1
+					} else {
+						return nil, cobra.ShellCompDirectiveNoFileComp
+					}
+				}
+				kind, ok := argCompletions[placeholders[i]]
+				if !ok {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+				return completeIDs(cmd, kind, toComplete)
+			}
+		}
+	}
+	for name, kind := range flagCompletions {
+		if cmd.LocalNonPersistentFlags().Lookup(name) == nil {
+			continue
+		}
+		_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeIDs(cmd, kind, toComplete)
+		})
+	}
+	for _, sub := range cmd.Commands() {
+		registerDynamicCompletion(sub)
+	}
+}
+
+func completesAny(placeholders []string) bool {
+	for _, p := range placeholders {
+		if _, ok := argCompletions[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// completeIDs lists IDs of kind starting with toComplete, described by
+// their title or name. Beads are limited to the --project given on the
+// command line, if any.
+func completeIDs(cmd *cobra.Command, kind, toComplete string) ([]string, cobra.ShellCompDirective) {
+	src := completionSources[kind]
+	client := newClient()
+	client.HTTP.Timeout = completionTimeout
+	client.Retries = 0
+
+	var params url.Values
+	if src.pageKey != "" {
+		params = url.Values{"limit": {"500"}}
+		if f := cmd.Flags().Lookup("project"); f != nil && f.Value.String() != "" {
+			params.Set("project_id", f.Value.String())
+		}
+	}
+	data, err := client.get(src.path, params)
+	if err != nil {
+		cobra.CompDebugln("loomctl: "+err.Error(), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if src.pageKey != "" {
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		data = page[src.pageKey]
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, item := range items {
+		id, _ := item["id"].(string)
+		if id == "" || !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		if desc, _ := item[src.descField].(string); desc != "" {
+			completions = append(completions, id+"\t"+desc)
+		} else {
+			completions = append(completions, id)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
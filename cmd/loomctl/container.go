@@ -16,21 +16,22 @@ func newContainerCommand() *cobra.Command {
 	cmd.AddCommand(newContainerLogsCommand())
 	cmd.AddCommand(newContainerRestartCommand())
 	cmd.AddCommand(newContainerStatusCommand())
+	cmd.AddCommand(newContainerRuntimeCommand())
 	return cmd
 }
 
 func newContainerListCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "list",
-		Short: "List all project containers",
+		Use:   "list [project]",
+		Short: "List project containers with their limits, health and resource usage",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Listing containers...")
 			client := newClient()
 			params := url.Values{}
 			if len(args) > 0 {
 				params.Set("project", args[0])
 			}
-			resp, err := client.get("/containers", params)
+			resp, err := client.get("/api/v1/containers", params)
 			if err != nil {
 				return fmt.Errorf("failed to list containers: %w", err)
 			}
@@ -42,16 +43,16 @@ func newContainerListCommand() *cobra.Command {
 }
 
 func newContainerLogsCommand() *cobra.Command {
-	return &cobra.Command{
+	var tail int
+	cmd := &cobra.Command{
 		Use:   "logs <project>",
-		Short: "Tail container logs",
+		Short: "Show the last lines of a project container's log",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Tailing logs for project: %s\n", args[0])
 			client := newClient()
 			params := url.Values{}
-			params.Set("project", args[0])
-			resp, err := client.get(fmt.Sprintf("/containers/%s/logs", args[0]), nil)
+			params.Set("tail", fmt.Sprint(tail))
+			resp, err := client.get(fmt.Sprintf("/api/v1/containers/%s/logs", args[0]), params)
 			if err != nil {
 				return fmt.Errorf("failed to get logs: %w", err)
 			}
@@ -60,19 +61,18 @@ func newContainerLogsCommand() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&tail, "tail", 200, "Number of log lines to show")
+	return cmd
 }
 
 func newContainerRestartCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "restart <project>",
-		Short: "Restart project container",
+		Short: "Recreate a project container, applying its current resource limits",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Restarting container for project: %s\n", args[0])
 			client := newClient()
-			params := url.Values{}
-			params.Set("project", args[0])
-			resp, err := client.post(fmt.Sprintf("/containers/%s/restart", args[0]), nil)
+			resp, err := client.post(fmt.Sprintf("/api/v1/containers/%s/restart", args[0]), nil)
 			if err != nil {
 				return fmt.Errorf("failed to restart container: %w", err)
 			}
@@ -89,11 +89,8 @@ func newContainerStatusCommand() *cobra.Command {
 		Short: "Get container status",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Getting status for project: %s\n", args[0])
 			client := newClient()
-			params := url.Values{}
-			params.Set("project", args[0])
-			resp, err := client.get(fmt.Sprintf("/containers/%s/status", args[0]), nil)
+			resp, err := client.get(fmt.Sprintf("/api/v1/containers/%s/status", args[0]), nil)
 			if err != nil {
 				return fmt.Errorf("failed to get container status: %w", err)
 			}
@@ -103,3 +100,21 @@ func newContainerStatusCommand() *cobra.Command {
 		},
 	}
 }
+
+func newContainerRuntimeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "runtime",
+		Short: "Show the container engine, whether it is rootless, and whether it enforces limits",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			resp, err := client.get("/api/v1/containers/runtime", nil)
+			if err != nil {
+				return fmt.Errorf("failed to get container runtime: %w", err)
+			}
+			outputJSON(resp)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Doctor check statuses, as the server's provider diagnostics use them.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorCheck is one line of the doctor's report. Hint says how to fix a
+// check that didn't pass.
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+// doctorReport collects the checks in the order they ran.
+type doctorReport struct {
+	Server string        `json:"server"`
+	Passed bool          `json:"passed"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+func (r *doctorReport) add(name, status, message, hint string) {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, Status: status, Message: message, Hint: hint})
+}
+
+// diagnostics is the part of GET /api/v1/system/diagnostics the doctor
+// reads.
+type diagnostics struct {
+	Version string `json:"version"`
+	Auth    struct {
+		Enabled bool   `json:"enabled"`
+		User    string `json:"user"`
+		Role    string `json:"role"`
+	} `json:"auth"`
+	Database     string `json:"database"`
+	Dependencies map[string]struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"dependencies"`
+	Projects []struct {
+		ProjectID       string   `json:"project_id"`
+		Name            string   `json:"name"`
+		Ready           bool     `json:"ready"`
+		ReadinessIssues []string `json:"readiness_issues"`
+		Container       *struct {
+			Running   bool   `json:"running"`
+			Healthy   bool   `json:"healthy"`
+			Restarts  int    `json:"restarts"`
+			LastError string `json:"last_error"`
+		} `json:"container"`
+	} `json:"projects"`
+}
+
+// dependencyHints says how to fix each dependency the server reports.
+var dependencyHints = map[string]string{
+	"database":    "Check the database settings in config.yaml and that the database server is reachable from loom.",
+	"message_bus": "Check that NATS is running and that NATS_URL points at it.",
+	"cache":       "Check the cache settings in config.yaml.",
+	"providers":   "Check the provider registry with 'loomctl provider list'.",
+}
+
+func newDoctorCommand() *cobra.Command {
+	var skipProviders bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the loom server and its environment end to end",
+		Long: `Check that the server is reachable and accepts your credentials, that its
+database and NATS connections are healthy, that each provider answers a
+test request, and that each project is ready for dispatch with a running
+container. Prints a report with a hint for each failed check and exits
+non-zero if any check fails.
+
+The report is colored on a terminal unless NO_COLOR is set. Pass
+--output json for a machine-readable report.`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{timeoutAnnotation: "2m"},
+		Example: `  loomctl doctor
+  loomctl doctor --skip-providers
+  loomctl doctor --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := runDoctor(newClient(), skipProviders)
+			if cmd.Flags().Changed("output") && outputFormat == "json" {
+				outputFormatJSON(report)
+			} else {
+				printDoctorReport(os.Stdout, report, useColor(os.Stdout))
+			}
+			if !report.Passed {
+				failed := 0
+				for _, c := range report.Checks {
+					if c.Status == doctorFail {
+						failed++
+					}
+				}
+				return &cliError{
+					Code:     "checks_failed",
+					Message:  fmt.Sprintf("%d of %d checks failed", failed, len(report.Checks)),
+					exitCode: exitError,
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&skipProviders, "skip-providers", false, "Don't send a test request to each provider")
+	return cmd
+}
+
+// runDoctor runs every check it can. Checks that depend on one that
+// failed are skipped.
+func runDoctor(client *Client, skipProviders bool) *doctorReport {
+	report := &doctorReport{Server: client.BaseURL}
+	defer func() {
+		report.Passed = true
+		for _, c := range report.Checks {
+			if c.Status == doctorFail {
+				report.Passed = false
+			}
+		}
+	}()
+
+	health, err := client.get("/api/v1/health", nil)
+	if err != nil {
+		report.add("server", doctorFail, err.Error(),
+			fmt.Sprintf("Check that loom is running at %s, or point loomctl at it with --server or LOOM_SERVER.", client.BaseURL))
+		return report
+	}
+	report.add("server", doctorPass, "reachable at "+client.BaseURL, "")
+	var h struct {
+		Mode string `json:"mode"`
+	}
+	if json.Unmarshal(health, &h) == nil && h.Mode == "maintenance" {
+		report.add("mode", doctorWarn, "in maintenance mode: writes are rejected and dispatch is paused",
+			"Switch it off with 'loomctl admin maintenance off' once maintenance is over.")
+	}
+
+	data, err := client.get("/api/v1/system/diagnostics", nil)
+	if err != nil {
+		report.add("auth", authStatus(err), err.Error(), authHint(client, err))
+		return report
+	}
+	var d diagnostics
+	if err := json.Unmarshal(data, &d); err != nil {
+		report.add("diagnostics", doctorFail, "unreadable diagnostics: "+err.Error(), "Check that loomctl and the server are the same version.")
+		return report
+	}
+	switch {
+	case !d.Auth.Enabled:
+		report.add("auth", doctorPass, "authentication is disabled on the server", "")
+	case d.Auth.Role != "":
+		report.add("auth", doctorPass, fmt.Sprintf("signed in as %s (%s)", d.Auth.User, d.Auth.Role), "")
+	default:
+		report.add("auth", doctorPass, "signed in as "+d.Auth.User, "")
+	}
+
+	names := make([]string, 0, len(d.Dependencies))
+	for name := range d.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dep := d.Dependencies[name]
+		label := name
+		switch {
+		case name == "database" && d.Database != "":
+			label = "database (" + d.Database + ")"
+		case name == "message_bus":
+			label = "nats"
+		}
+		status := doctorWarn
+		switch dep.Status {
+		case "healthy":
+			status = doctorPass
+		case "unhealthy":
+			status = doctorFail
+		}
+		hint := ""
+		if status != doctorPass {
+			hint = dependencyHints[name]
+		}
+		report.add(label, status, dep.Message, hint)
+	}
+
+	if skipProviders {
+		report.add("providers", doctorSkip, "skipped with --skip-providers", "")
+	} else {
+		checkProviders(client, report)
+	}
+
+	if len(d.Projects) == 0 {
+		report.add("projects", doctorWarn, "no projects", "Add a project from the web UI.")
+	}
+	for _, p := range d.Projects {
+		name := "project " + p.ProjectID
+		if p.Ready {
+			report.add(name, doctorPass, "ready for dispatch", "")
+		} else {
+			report.add(name, doctorFail, "not ready: "+strings.Join(p.ReadinessIssues, "; "),
+				fmt.Sprintf("Fix the issues listed, then check 'loomctl project show %s'.", p.ProjectID))
+		}
+		c := p.Container
+		switch {
+		case c == nil:
+			continue
+		case !c.Running:
+			report.add(name+" container", doctorFail, "not running"+withDetail(c.LastError),
+				fmt.Sprintf("Restart it with 'loomctl container restart %s' and check 'loomctl container logs %s'.", p.ProjectID, p.ProjectID))
+		case !c.Healthy:
+			report.add(name+" container", doctorWarn, fmt.Sprintf("failing health checks, restarted %d times", c.Restarts)+withDetail(c.LastError),
+				fmt.Sprintf("Check 'loomctl container logs %s'.", p.ProjectID))
+		default:
+			report.add(name+" container", doctorPass, "running and healthy", "")
+		}
+	}
+	return report
+}
+
+// checkProviders sends each registered provider a test request.
+func checkProviders(client *Client, report *doctorReport) {
+	data, err := client.get("/api/v1/providers", nil)
+	if err != nil {
+		report.add("providers", doctorFail, err.Error(), "")
+		return
+	}
+	var providers []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &providers); err != nil {
+		report.add("providers", doctorFail, "unreadable provider list: "+err.Error(), "")
+		return
+	}
+	if len(providers) == 0 {
+		report.add("providers", doctorFail, "no providers registered", "Register one with 'loomctl provider register'.")
+		return
+	}
+	for _, p := range providers {
+		name := "provider " + p.ID
+		hint := fmt.Sprintf("Check the provider's endpoint and API key, then rerun 'loomctl provider test %s'.", p.ID)
+		data, err := client.post("/api/v1/providers/"+url.PathEscape(p.ID)+"/test", nil)
+		if err != nil {
+			report.add(name, doctorFail, err.Error(), hint)
+			continue
+		}
+		var result struct {
+			Passed bool `json:"passed"`
+			Checks []struct {
+				Name    string `json:"name"`
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"checks"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			report.add(name, doctorFail, "unreadable test result: "+err.Error(), hint)
+			continue
+		}
+		if result.Passed {
+			report.add(name, doctorPass, fmt.Sprintf("passed %d checks", len(result.Checks)), "")
+			continue
+		}
+		var failed []string
+		for _, c := range result.Checks {
+			if c.Status == doctorFail {
+				failed = append(failed, c.Name+": "+c.Message)
+			}
+		}
+		report.add(name, doctorFail, strings.Join(failed, "; "), hint)
+	}
+}
+
+// authStatus is the auth check's status when the diagnostics request
+// failed: rejected credentials fail it, anything else is left unknown.
+func authStatus(err error) string {
+	switch httpStatus(err) {
+	case 401, 403:
+		return doctorFail
+	}
+	return doctorWarn
+}
+
+func authHint(client *Client, err error) string {
+	switch httpStatus(err) {
+	case 401:
+		if client.Token == "" && client.APIKey == "" {
+			return "The server requires authentication. Set LOOM_TOKEN or LOOM_API_KEY."
+		}
+		return "The server rejected your credentials. Check that LOOM_TOKEN or LOOM_API_KEY is current."
+	case 403:
+		return "Your account can't read server diagnostics. Check --org or ask an admin."
+	case 404:
+		return "The server predates 'loomctl doctor'; upgrade it to run the remaining checks."
+	}
+	return ""
+}
+
+// httpStatus is the status of the response an error came from, or 0.
+func httpStatus(err error) int {
+	var e *cliError
+	if errors.As(err, &e) {
+		if status, ok := e.Details["status"].(int); ok {
+			return status
+		}
+	}
+	return 0
+}
+
+// withDetail appends a detail to a message when there is one.
+func withDetail(msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return ": " + msg
+}
+
+// ANSI colors of each status.
+var doctorColors = map[string]string{
+	doctorPass: "\033[32m", // green
+	doctorWarn: "\033[33m", // yellow
+	doctorFail: "\033[31m", // red
+	doctorSkip: "\033[90m", // gray
+}
+
+var doctorMarks = map[string]string{
+	doctorPass: "✓",
+	doctorWarn: "!",
+	doctorFail: "✗",
+	doctorSkip: "-",
+}
+
+// useColor reports whether f is a terminal and NO_COLOR isn't set.
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+func printDoctorReport(w io.Writer, report *doctorReport, color bool) {
+	paint := func(status, s string) string {
+		if !color {
+			return s
+		}
+		return doctorColors[status] + s + "\033[0m"
+	}
+	width := 0
+	for _, c := range report.Checks {
+		width = max(width, len(c.Name))
+	}
+	counts := make(map[string]int)
+	for _, c := range report.Checks {
+		counts[c.Status]++
+		fmt.Fprintf(w, "%s %-*s  %s\n", paint(c.Status, doctorMarks[c.Status]), width, c.Name, c.Message)
+		if c.Hint != "" && c.Status != doctorPass {
+			fmt.Fprintf(w, "  %-*s  %s\n", width, "", paint(c.Status, "→ "+c.Hint))
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+	if counts[doctorSkip] > 0 {
+		fmt.Fprintf(w, ", %d skipped", counts[doctorSkip])
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes. Scripts can tell failure classes apart by these.
+const (
+	exitError     = 1 // the server couldn't be reached
+	exitClient    = 2 // bad usage or input, caught before reaching the server
+	exitServer4xx = 3 // the server rejected the request
+	exitServer5xx = 4 // the server failed
+	exitTimeout   = 5 // the request timed out
+)
+
+// maxErrorBody caps the response body kept in an error's details.
+const maxErrorBody = 4096
+
+// cliError is a failure with an exit code and a machine-readable form,
+// printed as JSON with --output json.
+type cliError struct {
+	Code     string                 `json:"code"`
+	Message  string                 `json:"message"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	exitCode int
+}
+
+func (e *cliError) Error() string {
+	if status, ok := e.Details["status"].(int); ok {
+		return fmt.Sprintf("server error (%d): %s", status, e.Message)
+	}
+	return e.Message
+}
+
+// newHTTPError describes a response with a 4xx or 5xx status. The server's
+// {"error": ...} message is used when there is one, and the response body
+// is kept in the details.
+func newHTTPError(req *http.Request, status int, body []byte) error {
+	e := &cliError{
+		Code:     strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		exitCode: exitServer4xx,
+		Details: map[string]interface{}{
+			"status": status,
+			"method": req.Method,
+			"path":   req.URL.Path,
+		},
+	}
+	if status >= 500 {
+		e.exitCode = exitServer5xx
+	}
+	if e.Code == "" {
+		e.Code = fmt.Sprintf("http_%d", status)
+	}
+
+	var parsed interface{}
+	if json.Unmarshal(body, &parsed) == nil {
+		e.Details["body"] = parsed
+		if m, ok := parsed.(map[string]interface{}); ok {
+			if msg, ok := m["error"].(string); ok {
+				e.Message = msg
+			}
+		}
+	} else if text := strings.TrimSpace(string(body)); text != "" {
+		if len(text) > maxErrorBody {
+			text = text[:maxErrorBody]
+		}
+		e.Details["body"] = text
+		e.Message = text
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(status)
+	}
+	return e
+}
+
+// newRequestError describes a request that got no response.
+func newRequestError(req *http.Request, err error) error {
+	e := &cliError{
+		Code:     "connection_failed",
+		Message:  fmt.Sprintf("request failed: %v", err),
+		exitCode: exitError,
+		Details: map[string]interface{}{
+			"method": req.Method,
+			"path":   req.URL.Path,
+		},
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		e.Code = "timeout"
+		e.Message = fmt.Sprintf("request timed out: %v", err)
+		e.exitCode = exitTimeout
+	}
+	return e
+}
+
+// reportError prints a command's error, as JSON with --output json, and
+// returns the exit code. Errors that didn't come from a request are client
+// errors.
+func reportError(cmd *cobra.Command, err error) int {
+	var e *cliError
+	if !errors.As(err, &e) {
+		e = &cliError{Code: "client_error", Message: err.Error(), exitCode: exitClient}
+	}
+
+	if outputFormat == "json" {
+		enc := json.NewEncoder(os.Stderr)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(e)
+		return e.exitCode
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if e.exitCode == exitClient && cmd != nil {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+	}
+	return e.exitCode
+}
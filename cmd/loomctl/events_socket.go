@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// eventSocketMessage is a message of the server's /api/v1/events/ws stream.
+type eventSocketMessage struct {
+	Kind  string `json:"kind"` // event, ready, gap or keepalive
+	Event *struct {
+		Type      string                 `json:"type"`
+		ProjectID string                 `json:"project_id"`
+		Data      map[string]interface{} `json:"data"`
+	} `json:"event"`
+	Cursor uint64 `json:"cursor"`
+}
+
+// stopFollowing wraps an error from the message handler so followEvents
+// returns it instead of reconnecting.
+type stopFollowing struct{ err error }
+
+func (s stopFollowing) Error() string { return s.err.Error() }
+
+// followEvents follows the event socket, calling fn with each message.
+// When the connection drops it reconnects with the cursor of the last
+// message, and the server replays the events missed in between. It returns
+// when fn fails or the server rejects the subscription.
+func (c *Client) followEvents(params url.Values, fn func(eventSocketMessage) error) error {
+	var cursor uint64
+	resume := false
+	backoff := time.Second
+	for {
+		q := url.Values{}
+		for k, v := range params {
+			q[k] = v
+		}
+		if resume {
+			q.Set("cursor", strconv.FormatUint(cursor, 10))
+		}
+
+		connected, err := c.readEventSocket(q, func(msg eventSocketMessage) error {
+			if msg.Cursor != 0 {
+				cursor, resume = msg.Cursor, true
+			}
+			if err := fn(msg); err != nil {
+				return stopFollowing{err}
+			}
+			return nil
+		})
+		var stop stopFollowing
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+		var cliErr *cliError
+		if errors.As(err, &cliErr) && cliErr.exitCode == exitServer4xx {
+			return err
+		}
+		if connected {
+			backoff = time.Second
+		}
+		fmt.Fprintf(os.Stderr, "--- event stream lost (%v); reconnecting in %s\n", err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// readEventSocket reads one event socket connection until it fails. It
+// reports whether the connection was established.
+func (c *Client) readEventSocket(params url.Values, fn func(eventSocketMessage) error) (bool, error) {
+	u := strings.Replace(c.BaseURL, "http", "ws", 1) + "/api/v1/events/ws"
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setAuth(req)
+
+	dialer := websocket.Dialer{HandshakeTimeout: requestTimeout, Proxy: http.ProxyFromEnvironment}
+	conn, resp, err := dialer.Dial(u, req.Header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 400 {
+			body, _ := io.ReadAll(resp.Body)
+			return false, newHTTPError(req, resp.StatusCode, body)
+		}
+		return false, newRequestError(req, err)
+	}
+	defer conn.Close()
+
+	for {
+		var msg eventSocketMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return true, err
+		}
+		if err := fn(msg); err != nil {
+			return true, err
+		}
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -46,6 +45,8 @@ The export can be used for backups, migrations, or disaster recovery.`,
 
   # Export to stdout (pipe to jq or file)
   loomctl export`,
+		// Whole-database transfers outlast the default timeout
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
 
@@ -137,7 +138,8 @@ Use --validate-only to check if the file can be imported without making changes.
 
   # Fail if any conflicts exist
   loomctl import backup.json --strategy fail-on-conflict`,
-		Args: cobra.ExactArgs(1),
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{timeoutAnnotation: "10m"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filename := args[0]
 
@@ -173,20 +175,11 @@ Use --validate-only to check if the file can be imported without making changes.
 				return fmt.Errorf("failed to create request: %w", err)
 			}
 			req.Header.Set("Content-Type", "application/json")
+			client.setAuth(req)
 
-			resp, err := client.HTTP.Do(req)
+			respBody, _, err := client.send(req)
 			if err != nil {
-				return fmt.Errorf("request failed: %w", err)
-			}
-			defer resp.Body.Close()
-
-			respBody, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("failed to read response: %w", err)
-			}
-
-			if resp.StatusCode >= 400 {
-				return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
+				return err
 			}
 
 			// Output result
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newProjectGraphCommand() *cobra.Command {
+	var (
+		target string
+		limit  int
+	)
+	cmd := &cobra.Command{
+		Use:   "graph <project-id>",
+		Short: "Analyze a project's bead dependencies: cycles, critical path, bottlenecks",
+		Long: `Analyze the blocking dependencies between a project's open beads. Shows
+every set of beads waiting on each other, the longest chain of blockers
+(ending at --target if given), and the beads the most other work waits on.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  loomctl project graph loom-self
+  loomctl project graph loom-self --target=loom-042 --limit=5`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if target != "" {
+				params.Set("target", target)
+			}
+			if cmd.Flags().Changed("limit") {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get("/api/v1/projects/"+url.PathEscape(args[0])+"/graph/analysis", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&target, "target", "", "Bead to find the critical path to")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Bottlenecks to list")
+	return cmd
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -19,6 +22,7 @@ const version = "2.0.0"
 var (
 	serverURL    string
 	outputFormat string
+	orgName      string
 )
 
 func main() {
@@ -33,12 +37,23 @@ All output is structured JSON by default (pipe through jq for human-readable for
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", getDefaultServer(), "Loom server URL")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "json", "Output format: json, table")
+	rootCmd.PersistentFlags().StringVar(&orgName, "org", getDefaultOrg(), "Organization to act in (default: LOOM_ORG, then the one saved by 'org use')")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", getDefaultTimeout(), "Timeout of each request (LOOM_TIMEOUT); some commands default to longer")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "retries", getDefaultRetries(), "Retries of idempotent requests on network errors, 429, 502, 503, and 504 (LOOM_RETRIES)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyCommandTimeout(cmd)
+	}
 
 	// Add subcommands
 	rootCmd.AddCommand(newBeadCommand())
 	rootCmd.AddCommand(newContainerCommand())
 	rootCmd.AddCommand(newWorkflowCommand())
+	rootCmd.AddCommand(newCICommand())
+	rootCmd.AddCommand(newReportCommand())
+	rootCmd.AddCommand(newMilestoneCommand())
 	rootCmd.AddCommand(newAgentCommand())
+	rootCmd.AddCommand(newRemoteAgentCommand())
+	rootCmd.AddCommand(newActionCommand())
 	rootCmd.AddCommand(newProjectCommand())
 	rootCmd.AddCommand(newLogCommand())
 	rootCmd.AddCommand(newStatusCommand())
@@ -51,10 +66,29 @@ All output is structured JSON by default (pipe through jq for human-readable for
 	rootCmd.AddCommand(newImportCommand())
 	rootCmd.AddCommand(newCreateFileCommand())
 	rootCmd.AddCommand(newProviderCommand())
-
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	rootCmd.AddCommand(newUserCommand())
+	rootCmd.AddCommand(newTokenCommand())
+	rootCmd.AddCommand(newWebhookCommand())
+	rootCmd.AddCommand(newReplCommand())
+	rootCmd.AddCommand(newApplyCommand())
+	rootCmd.AddCommand(newCommandPolicyCommand())
+	rootCmd.AddCommand(newSecretCommand())
+	rootCmd.AddCommand(newAuditCommand())
+	rootCmd.AddCommand(newOrgCommand())
+	rootCmd.AddCommand(newOrgChartCommand())
+	rootCmd.AddCommand(newSchemaCommand())
+	rootCmd.AddCommand(newDoctorCommand())
+	rootCmd.AddCommand(newAdminCommand())
+	rootCmd.AddCommand(newBackupCommand())
+
+	// Complete bead, project, agent, and provider IDs from the server
+	registerDynamicCompletion(rootCmd)
+
+	// Errors are printed by reportError, as JSON with --output json
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		os.Exit(reportError(cmd, err))
 	}
 }
 
@@ -70,12 +104,34 @@ func getDefaultServer() string {
 type Client struct {
 	BaseURL string
 	HTTP    *http.Client
+	Token   string // Bearer token (LOOM_TOKEN)
+	APIKey  string // API key (LOOM_API_KEY), used when no token is set
+	Org     string // organization requests act in (--org)
+	Retries int    // retries of idempotent requests (--retries)
 }
 
 func newClient() *Client {
 	return &Client{
 		BaseURL: serverURL,
-		HTTP:    &http.Client{Timeout: 30 * time.Second},
+		HTTP:    &http.Client{Timeout: requestTimeout, Transport: sharedTransport},
+		Token:   os.Getenv("LOOM_TOKEN"),
+		APIKey:  os.Getenv("LOOM_API_KEY"),
+		Org:     orgName,
+		Retries: maxRetries,
+	}
+}
+
+// setAuth attaches credentials when the server has authentication enabled,
+// and the organization to act in when one is selected.
+func (c *Client) setAuth(req *http.Request) {
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.APIKey != "":
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.Org != "" {
+		req.Header.Set("X-Loom-Org", c.Org)
 	}
 }
 
@@ -101,23 +157,9 @@ func (c *Client) do(method, path string, params url.Values, data interface{}) ([
 	if data != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-
-	resp, err := c.HTTP.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
-	}
-
-	return respBody, nil
+	c.setAuth(req)
+	respBody, _, err := c.send(req)
+	return respBody, err
 }
 
 func (c *Client) get(path string, params url.Values) ([]byte, error) {
@@ -132,24 +174,135 @@ func (c *Client) put(path string, data interface{}) ([]byte, error) {
 	return c.do("PUT", path, nil, data)
 }
 
+func (c *Client) patch(path string, data interface{}) ([]byte, error) {
+	return c.do("PATCH", path, nil, data)
+}
+
 func (c *Client) delete(path string) ([]byte, error) {
 	return c.do("DELETE", path, nil, nil)
 }
 
+// upload POSTs content as the "file" field of a multipart form.
+func (c *Client) upload(path, filename, contentType string, content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": filename}))
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+path, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c.setAuth(req)
+	body, _, err := c.send(req)
+	return body, err
+}
+
+// download GETs a file, returning its contents and response headers.
+func (c *Client) download(path string) ([]byte, http.Header, error) {
+	req, err := http.NewRequest(http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setAuth(req)
+	return c.send(req)
+}
+
+// send sends a request, retrying idempotent ones on transient failures,
+// and returns the response body and headers.
+func (c *Client) send(req *http.Request) ([]byte, http.Header, error) {
+	resp, err := c.doWithRetry(req)
+	if err != nil {
+		return nil, nil, newRequestError(req, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, newRequestError(req, err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, nil, newHTTPError(req, resp.StatusCode, respBody)
+	}
+	return respBody, resp.Header, nil
+}
+
 // streamSSE reads an SSE stream and prints each event's data field as JSON.
 func (c *Client) streamSSE(path string) error {
+	return c.readSSE(path, func(_, data string) error {
+		fmt.Println(data)
+		return nil
+	})
+}
+
+// readSSE reads an SSE stream and calls fn with each event's type and data.
+// The client timeout is not applied: streams stay open until the server or
+// the user closes them.
+func (c *Client) readSSE(path string, fn func(event, data string) error) error {
+	return c.doSSE(http.MethodGet, path, nil, fn)
+}
+
+// doSSE is readSSE for any method, sending data as a JSON body.
+func (c *Client) doSSE(method, path string, data interface{}, fn func(event, data string) error) error {
 	u := fmt.Sprintf("%s%s", c.BaseURL, path)
-	resp, err := c.HTTP.Get(u)
+	streamClient := *c.HTTP
+	streamClient.Timeout = 0
+
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal data: %w", err)
+		}
+		body = strings.NewReader(string(jsonData))
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setAuth(req)
+	resp, err := streamClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return newRequestError(req, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return newHTTPError(req, resp.StatusCode, body)
+	}
+
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	event := ""
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "data: ") {
-			fmt.Println(line[6:])
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = line[7:]
+		case strings.HasPrefix(line, "data: "):
+			if err := fn(event, line[6:]); err != nil {
+				return err
+			}
+		case line == "":
+			event = ""
 		}
 	}
 	return scanner.Err()
@@ -314,6 +467,7 @@ func newBeadCommand() *cobra.Command {
 		Short: "Manage beads (work items)",
 	}
 	cmd.AddCommand(newBeadListCommand())
+	cmd.AddCommand(newBeadSearchCommand())
 	cmd.AddCommand(newBeadCreateCommand())
 	cmd.AddCommand(newBeadShowCommand())
 	cmd.AddCommand(newBeadClaimCommand())
@@ -321,8 +475,26 @@ func newBeadCommand() *cobra.Command {
 	cmd.AddCommand(newBeadUpdateCommand())
 	cmd.AddCommand(newBeadBulkUpdateCommand())
 	cmd.AddCommand(newBeadDeleteCommand())
+	cmd.AddCommand(newBeadRestoreCommand())
+	cmd.AddCommand(newBeadTrashCommand())
+	cmd.AddCommand(newBeadHistoryCommand())
 	cmd.AddCommand(newBeadErrorsCommand())
+	cmd.AddCommand(newBeadLoopCommand())
 	cmd.AddCommand(newBeadUnblockCommand())
+	cmd.AddCommand(newBeadApplyPatchCommand())
+	cmd.AddCommand(newBeadRelationsCommand())
+	cmd.AddCommand(newBeadRelateCommand())
+	cmd.AddCommand(newBeadUnrelateCommand())
+	cmd.AddCommand(newBeadLinkCommand())
+	cmd.AddCommand(newBeadMoveCommand())
+	cmd.AddCommand(newBeadScheduleCommand())
+	cmd.AddCommand(newBeadCommentCommand())
+	cmd.AddCommand(newBeadCommentsCommand())
+	cmd.AddCommand(newBeadAttachCommand())
+	cmd.AddCommand(newBeadAttachmentsCommand())
+	cmd.AddCommand(newBeadDownloadCommand())
+	cmd.AddCommand(newBeadSLACommand())
+	cmd.AddCommand(newBeadTypeCommand())
 	return cmd
 }
 
@@ -334,13 +506,25 @@ func newBeadListCommand() *cobra.Command {
 		assignedTo  string
 		priority    int
 		hasPriority bool
+		overdue     bool
+		milestoneID string
+		watch       bool
+		limit       int
+		cursor      string
+		all         bool
 	)
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List beads",
 		Example: `  loomctl bead list
   loomctl bead list --status=open --project=loom
-  loomctl bead list --priority=0 --status=open`,
+  loomctl bead list --priority=0 --status=open
+  loomctl bead list --overdue
+  loomctl bead list --milestone=ms-1a2b3c4d
+  loomctl bead list --project=loom --limit=100
+  loomctl bead list --project=loom --limit=100 --cursor=<next_cursor>
+  loomctl bead list --project=loom --all
+  loomctl bead list --project=loom --watch -o table`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
 			params := url.Values{}
@@ -365,10 +549,47 @@ func newBeadListCommand() *cobra.Command {
 			if hasPriority && priority >= 0 {
 				params.Set("priority", fmt.Sprintf("%d", priority))
 			}
-			data, err := client.get("/api/v1/beads", params)
+			if overdue {
+				params.Set("overdue", "true")
+			}
+			if milestoneID != "" {
+				params.Set("milestone_id", milestoneID)
+			}
+			if watch && (limit > 0 || cursor != "") {
+				return fmt.Errorf("--watch cannot be combined with --limit or --cursor")
+			}
+
+			var (
+				data []byte
+				err  error
+			)
+			switch {
+			case all:
+				// Walk every page; --limit sets the page size.
+				if limit > 0 {
+					params.Set("limit", fmt.Sprintf("%d", limit))
+				} else {
+					params.Set("limit", "500")
+				}
+				data, err = client.getAllPages("/api/v1/beads", params, "beads")
+				params.Del("limit")
+			case limit > 0 || cursor != "":
+				if limit > 0 {
+					params.Set("limit", fmt.Sprintf("%d", limit))
+				}
+				if cursor != "" {
+					params.Set("cursor", cursor)
+				}
+				data, err = client.get("/api/v1/beads", params)
+			default:
+				data, err = client.get("/api/v1/beads", params)
+			}
 			if err != nil {
 				return err
 			}
+			if watch {
+				return watchBeads(client, data, params)
+			}
 			outputJSON(data)
 			return nil
 		},
@@ -378,6 +599,12 @@ func newBeadListCommand() *cobra.Command {
 	cmd.Flags().StringVar(&beadType, "type", "", "Filter by bead type (task, bug, feature)")
 	cmd.Flags().StringVar(&assignedTo, "assigned-to", "", "Filter by assigned agent")
 	cmd.Flags().IntVarP(&priority, "priority", "P", 0, "Filter by priority (0=P0/highest, 4=lowest)")
+	cmd.Flags().BoolVar(&overdue, "overdue", false, "Only beads still open past their due date")
+	cmd.Flags().StringVar(&milestoneID, "milestone", "", "Filter by milestone ID")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "After listing, watch for bead changes and re-render")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Page size; returns one page with next_cursor (0 = unpaginated)")
+	cmd.Flags().StringVar(&cursor, "cursor", "", "Resume from the next_cursor of a previous page")
+	cmd.Flags().BoolVar(&all, "all", false, "Fetch every page and print the combined list")
 
 	cmd.Flags().BoolVar(&hasPriority, "has-priority", false, "")
 	cmd.Flags().MarkHidden("has-priority")
@@ -389,6 +616,75 @@ func newBeadListCommand() *cobra.Command {
 	return cmd
 }
 
+func newBeadSearchCommand() *cobra.Command {
+	var (
+		projectID     string
+		status        string
+		tags          string
+		createdAfter  string
+		createdBefore string
+		updatedAfter  string
+		updatedBefore string
+		limit         int
+	)
+	cmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Full-text search across bead titles, descriptions, and context",
+		Long: `Searches every bead the server knows about. All query terms must match
+somewhere in the title, description, or context. Title matches rank highest.`,
+		Args: cobra.ArbitraryArgs,
+		Example: `  loomctl bead search "dispatch loop"
+  loomctl bead search timeout --project=loom --status=open,blocked
+  loomctl bead search --tags=infra --created-after=2026-01-01`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if q := strings.Join(args, " "); q != "" {
+				params.Set("q", q)
+			}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			if status != "" {
+				params.Set("status", status)
+			}
+			if tags != "" {
+				params.Set("tags", tags)
+			}
+			if createdAfter != "" {
+				params.Set("created_after", createdAfter)
+			}
+			if createdBefore != "" {
+				params.Set("created_before", createdBefore)
+			}
+			if updatedAfter != "" {
+				params.Set("updated_after", updatedAfter)
+			}
+			if updatedBefore != "" {
+				params.Set("updated_before", updatedBefore)
+			}
+			if limit > 0 {
+				params.Set("limit", fmt.Sprintf("%d", limit))
+			}
+			data, err := client.get("/api/v1/beads/search", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Filter by project ID")
+	cmd.Flags().StringVar(&status, "status", "", "Filter by status; comma-separated for several")
+	cmd.Flags().StringVar(&tags, "tags", "", "Comma-separated tags; bead must carry all of them")
+	cmd.Flags().StringVar(&createdAfter, "created-after", "", "Only beads created after this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&createdBefore, "created-before", "", "Only beads created before this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&updatedAfter, "updated-after", "", "Only beads updated after this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&updatedBefore, "updated-before", "", "Only beads updated before this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 50, "Maximum number of results (0 = no limit)")
+	return cmd
+}
+
 func newBeadCreateCommand() *cobra.Command {
 	var (
 		title       string
@@ -396,22 +692,47 @@ func newBeadCreateCommand() *cobra.Command {
 		priority    int
 		projectID   string
 		beadType    string
+		due         string
+		milestoneID string
+		contextKVs  []string
 	)
 	cmd := &cobra.Command{
-		Use:     "create",
-		Short:   "Create a new bead",
-		Example: `  loomctl bead create --title="Fix bug" --project=loom`,
+		Use:   "create",
+		Short: "Create a new bead",
+		Example: `  loomctl bead create --title="Fix bug" --project=loom
+  loomctl bead create --title="Ship release notes" --project=loom --due=2026-11-01
+  loomctl bead create --title="Migrate auth" --project=loom --milestone=ms-1a2b3c4d
+  loomctl bead create --title="API 502s" --project=loom --type=incident --context=severity=high`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
 			body := map[string]interface{}{
 				"title":       title,
 				"description": description,
-				"priority":    priority,
 				"project_id":  projectID,
 			}
+			if cmd.Flags().Changed("priority") {
+				body["priority"] = priority
+			}
 			if beadType != "" {
 				body["type"] = beadType
 			}
+			if due != "" {
+				body["due_date"] = due
+			}
+			if milestoneID != "" {
+				body["milestone_id"] = milestoneID
+			}
+			if len(contextKVs) > 0 {
+				beadContext := make(map[string]string, len(contextKVs))
+				for _, kv := range contextKVs {
+					key, value, ok := strings.Cut(kv, "=")
+					if !ok || key == "" {
+						return fmt.Errorf("--context must be key=value, got %q", kv)
+					}
+					beadContext[key] = value
+				}
+				body["context"] = beadContext
+			}
 			data, err := client.post("/api/v1/beads", body)
 			if err != nil {
 				return err
@@ -422,9 +743,12 @@ func newBeadCreateCommand() *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&title, "title", "t", "", "Bead title (required)")
 	cmd.Flags().StringVarP(&description, "description", "d", "", "Bead description")
-	cmd.Flags().IntVar(&priority, "priority", 2, "Priority (0=highest, 4=lowest)")
+	cmd.Flags().IntVar(&priority, "priority", 2, "Priority (0=highest, 4=lowest; default: the type's, else 2)")
 	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (required)")
 	cmd.Flags().StringVar(&beadType, "type", "task", "Bead type")
+	cmd.Flags().StringVar(&due, "due", "", "Due date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&milestoneID, "milestone", "", "Milestone ID to add the bead to")
+	cmd.Flags().StringArrayVar(&contextKVs, "context", nil, "Context field as key=value (repeatable)")
 	cmd.MarkFlagRequired("title")
 	cmd.MarkFlagRequired("project")
 	return cmd
@@ -434,6 +758,7 @@ func newBeadShowCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:     "show <bead-id>",
 		Short:   "Show bead details",
+		Long:    "Show a bead, with the tokens and cost spent working it under \"usage\".",
 		Args:    cobra.ExactArgs(1),
 		Example: `  loomctl bead show loom-001`,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -442,6 +767,16 @@ func newBeadShowCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			// Usage is best effort: without analytics the bead still shows.
+			if usage, err := client.get(fmt.Sprintf("/api/v1/beads/%s/usage", args[0]), nil); err == nil {
+				var bead map[string]interface{}
+				if json.Unmarshal(data, &bead) == nil {
+					bead["usage"] = json.RawMessage(usage)
+					if merged, err := json.Marshal(bead); err == nil {
+						data = merged
+					}
+				}
+			}
 			outputJSON(data)
 			return nil
 		},
@@ -496,9 +831,11 @@ func newBeadPokeCommand() *cobra.Command {
 
 func newBeadUpdateCommand() *cobra.Command {
 	var (
-		status   string
-		priority int
-		title    string
+		status      string
+		priority    int
+		title       string
+		due         string
+		milestoneID string
 	)
 	cmd := &cobra.Command{
 		Use:   "update <bead-id>",
@@ -516,7 +853,13 @@ func newBeadUpdateCommand() *cobra.Command {
 			if cmd.Flags().Changed("title") {
 				body["title"] = title
 			}
-			data, err := client.put(fmt.Sprintf("/api/v1/beads/%s", args[0]), body)
+			if cmd.Flags().Changed("due") {
+				body["due_date"] = due
+			}
+			if cmd.Flags().Changed("milestone") {
+				body["milestone_id"] = milestoneID
+			}
+			data, err := client.patch(fmt.Sprintf("/api/v1/beads/%s", args[0]), body)
 			if err != nil {
 				return err
 			}
@@ -527,17 +870,129 @@ func newBeadUpdateCommand() *cobra.Command {
 	cmd.Flags().StringVar(&status, "status", "", "New status")
 	cmd.Flags().IntVar(&priority, "priority", 0, "New priority")
 	cmd.Flags().StringVar(&title, "title", "", "New title")
+	cmd.Flags().StringVar(&due, "due", "", "Due date (YYYY-MM-DD or RFC 3339; empty to clear)")
+	cmd.Flags().StringVar(&milestoneID, "milestone", "", "Milestone ID (empty to take the bead out of its milestone)")
 	return cmd
 }
 
 func newBeadDeleteCommand() *cobra.Command {
-	return &cobra.Command{
+	var force bool
+	cmd := &cobra.Command{
 		Use:   "delete <bead-id>",
-		Short: "Delete a bead",
+		Short: "Move a bead to the trash",
+		Long: `Move a bead to the trash. It disappears from listings and agents stop
+seeing it, but 'loomctl bead restore' brings it back until the server's
+trash retention window (30 days by default) purges it. --force deletes it
+for good at once, whether in the trash or not, and needs an admin.`,
+		Example: `  loomctl bead delete loom-001
+  loomctl bead delete loom-001 --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			path := "/api/v1/beads/" + url.PathEscape(args[0])
+			if force {
+				path += "?force=true"
+			}
+			data, err := client.delete(path)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Delete permanently instead of moving to the trash (admin only)")
+	return cmd
+}
+
+func newBeadRestoreCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <bead-id>",
+		Short: "Restore a bead from the trash",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/"+url.PathEscape(args[0])+"/restore", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadTrashCommand() *cobra.Command {
+	var project string
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List deleted beads that can still be restored",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			var params url.Values
+			if project != "" {
+				params = url.Values{"project_id": {project}}
+			}
+			data, err := client.get("/api/v1/beads/trash", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&project, "project", "", "Only this project's deleted beads")
+	return cmd
+}
+
+func newBeadHistoryCommand() *cobra.Command {
+	var field, since string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "history <bead-id>",
+		Short: "Show every recorded change to a bead",
 		Args:  cobra.ExactArgs(1),
+		Example: `  loomctl bead history loom-001
+  loomctl bead history loom-001 --field status
+  loomctl bead history loom-001 --since 2026-01-01T00:00:00Z --limit 50`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if field != "" {
+				params.Set("field", field)
+			}
+			if since != "" {
+				params.Set("since", since)
+			}
+			if limit > 0 {
+				params.Set("limit", fmt.Sprintf("%d", limit))
+			}
+			if len(params) == 0 {
+				params = nil
+			}
+			data, err := client.get("/api/v1/beads/"+url.PathEscape(args[0])+"/history", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&field, "field", "", "Only changes to this field (e.g. status, context.pr_url)")
+	cmd.Flags().StringVar(&since, "since", "", "Only changes at or after this RFC 3339 time")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Maximum number of changes (default 500)")
+	return cmd
+}
+
+func newBeadLoopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "loop <bead-id>",
+		Short:   "Explain why a bead was flagged as stuck in a loop",
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl bead loop loom-001`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
-			data, err := client.delete(fmt.Sprintf("/api/v1/beads/%s", args[0]))
+			data, err := client.get("/api/v1/beads/"+url.PathEscape(args[0])+"/loop", nil)
 			if err != nil {
 				return err
 			}
@@ -584,6 +1039,38 @@ func newBeadErrorsCommand() *cobra.Command {
 			}
 			if ld, ok := ctx["loop_detected"]; ok && ld == "true" {
 				fmt.Printf("Loop reason:   %v\n", ctx["loop_detected_reason"])
+				if by, ok := ctx["loop_detected_by"]; ok && by != "" {
+					fmt.Printf("Loop detector: %v\n", by)
+				}
+			}
+			if rc, _ := ctx["root_cause"].(string); rc != "" {
+				fmt.Printf("\nLikely root cause (%v):\n  %s\n", ctx["root_cause_at"], rc)
+				if steps, _ := ctx["root_cause_remediation"].(string); steps != "" {
+					fmt.Println("Suggested remediation:")
+					for _, step := range strings.Split(steps, "\n") {
+						fmt.Printf("  - %s\n", step)
+					}
+				}
+			}
+			if tf, _ := ctx["test_failures"].(string); tf != "" {
+				var failures []map[string]interface{}
+				if json.Unmarshal([]byte(tf), &failures) == nil && len(failures) > 0 {
+					fmt.Printf("\nFailing tests (%v):\n", ctx["test_failures_at"])
+					for _, f := range failures {
+						name := fmt.Sprint(f["test"])
+						if pkg, _ := f["package"].(string); pkg != "" {
+							name = pkg + "." + name
+						}
+						if flaky, _ := f["flaky"].(bool); flaky {
+							name += " (known flaky)"
+						}
+						msg, _ := f["message"].(string)
+						fmt.Printf("  %s: %s\n", name, msg)
+					}
+				}
+				if filed, _ := ctx["flaky_test_beads"].(string); filed != "" {
+					fmt.Printf("Flaky test beads: %s\n", filed)
+				}
 			}
 
 			// Parse and display error_history JSON array.
@@ -613,6 +1100,53 @@ func newBeadErrorsCommand() *cobra.Command {
 	}
 }
 
+func newBeadMoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "move <bead-id> <project-id>",
+		Short: "Move a bead to another project",
+		Long: `Move a bead to another project. It gets a new ID with that project's
+prefix; its context, conversation, comments, attachments and history come
+along, and beads related to it are updated to the new ID. The bead loses its
+assignee, and a bead in progress goes back to open.`,
+		Args:    cobra.ExactArgs(2),
+		Example: `  loomctl bead move app-007 lib`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/"+url.PathEscape(args[0])+"/move", map[string]string{
+				"project_id": args[1],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadApplyPatchCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply-patch <bead-id>",
+		Short: "Apply an apply-fix bead's approved patch and run the build and tests",
+		Long: `Apply the patch from the approved code fix proposal of an apply-fix bead to
+the project's working tree. A patch that no longer applies as it is is retried
+with less context and then as a three-way merge; a conflicting merge changes
+nothing. Once applied, the project's build and tests run, and the outcome is
+recorded on the bead.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl bead apply-patch loom-042`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/"+url.PathEscape(args[0])+"/apply-patch", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
 func newBeadUnblockCommand() *cobra.Command {
 	var reason string
 	cmd := &cobra.Command{
@@ -644,32 +1178,89 @@ re-opens the bead so the task executor will pick it up again.`,
 
 func newBeadBulkUpdateCommand() *cobra.Command {
 	var (
-		status   string
-		priority int
-		title    string
+		status          string
+		priority        int
+		title           string
+		assignedTo      string
+		project         string
+		filterStatus    string
+		filterTags      string
+		query           string
+		continueOnError bool
 	)
 	cmd := &cobra.Command{
-		Use:   "bulk-update <bead-ids>",
-		Short: "Bulk update bead fields",
-		Args:  cobra.MinimumNArgs(1),
+		Use:   "bulk-update [bead-ids]",
+		Short: "Update many beads in one request",
+		Long: `Apply the same changes to the listed beads, or to the beads matching
+--project, --filter-status, --tags, and --query. The server applies the
+update all or nothing: if any bead is missing or can't be changed (for
+example, a board WIP limit), no bead is changed. With --continue-on-error
+each bead is updated on its own and failures are reported per bead.`,
+		Example: `  loomctl bead bulk-update loom-a1 loom-b2 --status closed
+  loomctl bead bulk-update --project loom --filter-status open --tags ui --priority 1`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client := newClient()
-			body := map[string]interface{}{}
+			updates := map[string]interface{}{}
 			if cmd.Flags().Changed("status") {
-				body["status"] = status
+				updates["status"] = status
 			}
 			if cmd.Flags().Changed("priority") {
-				body["priority"] = priority
+				updates["priority"] = priority
 			}
 			if cmd.Flags().Changed("title") {
-				body["title"] = title
+				updates["title"] = title
 			}
-			for _, beadID := range args {
-				data, err := client.put(fmt.Sprintf("/api/v1/beads/%s", beadID), body)
-				if err != nil {
-					return err
+			if cmd.Flags().Changed("assigned-to") {
+				updates["assigned_to"] = assignedTo
+			}
+			if len(updates) == 0 {
+				return fmt.Errorf("nothing to update: pass --status, --priority, --title, or --assigned-to")
+			}
+
+			body := map[string]interface{}{
+				"updates":           updates,
+				"continue_on_error": continueOnError,
+			}
+			filter := map[string]interface{}{}
+			if project != "" {
+				filter["project_id"] = project
+			}
+			if filterStatus != "" {
+				filter["status"] = strings.Split(filterStatus, ",")
+			}
+			if filterTags != "" {
+				filter["tags"] = strings.Split(filterTags, ",")
+			}
+			if query != "" {
+				filter["q"] = query
+			}
+			switch {
+			case len(args) > 0 && len(filter) > 0:
+				return fmt.Errorf("give bead IDs or filter flags, not both")
+			case len(args) > 0:
+				body["ids"] = args
+			case len(filter) > 0:
+				body["filter"] = filter
+			default:
+				return fmt.Errorf("give bead IDs or at least one of --project, --filter-status, --tags, --query")
+			}
+
+			client := newClient()
+			data, err := client.post("/api/v1/beads/bulk", body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+
+			var summary struct {
+				Matched int `json:"matched"`
+				Failed  int `json:"failed"`
+			}
+			if json.Unmarshal(data, &summary) == nil && summary.Failed > 0 {
+				return &cliError{
+					Code:     "partial_failure",
+					Message:  fmt.Sprintf("%d of %d beads failed to update", summary.Failed, summary.Matched),
+					exitCode: exitServer4xx,
 				}
-				outputJSON(data)
 			}
 			return nil
 		},
@@ -677,6 +1268,12 @@ func newBeadBulkUpdateCommand() *cobra.Command {
 	cmd.Flags().StringVar(&status, "status", "", "New status")
 	cmd.Flags().IntVar(&priority, "priority", 0, "New priority")
 	cmd.Flags().StringVar(&title, "title", "", "New title")
+	cmd.Flags().StringVar(&assignedTo, "assigned-to", "", "Agent to assign (empty to unassign)")
+	cmd.Flags().StringVar(&project, "project", "", "Update the beads of this project")
+	cmd.Flags().StringVar(&filterStatus, "filter-status", "", "Update beads with these statuses (comma-separated)")
+	cmd.Flags().StringVar(&filterTags, "tags", "", "Comma-separated tags; beads must carry all of them")
+	cmd.Flags().StringVar(&query, "query", "", "Update beads matching this search text")
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Update each bead on its own instead of all or none")
 	return cmd
 }
 
@@ -690,6 +1287,7 @@ func newLogCommand() *cobra.Command {
 	cmd.AddCommand(newLogRecentCommand())
 	cmd.AddCommand(newLogStreamCommand())
 	cmd.AddCommand(newLogExportCommand())
+	cmd.AddCommand(newLogPruneCommand())
 	return cmd
 }
 
@@ -799,6 +1397,40 @@ func newLogExportCommand() *cobra.Command {
 	}
 }
 
+func newLogPruneCommand() *cobra.Command {
+	var (
+		olderThan  string
+		maxEntries int
+		dryRun     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete stored log entries past the retention policy",
+		Long: `Delete stored log entries, oldest first. Without flags the server's
+configured retention policy applies. When a log archive is configured,
+entries are archived before they are deleted.`,
+		Example: `  loomctl log prune --older-than 30d --dry-run
+  loomctl log prune --max-entries 1000000`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/logs/prune", map[string]interface{}{
+				"older_than":  olderThan,
+				"max_entries": maxEntries,
+				"dry_run":     dryRun,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Prune entries older than a duration (e.g. 72h, 30d)")
+	cmd.Flags().IntVar(&maxEntries, "max-entries", 0, "Keep at most this many of the newest entries")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would be pruned without deleting anything")
+	return cmd
+}
+
 // --- Status command ---
 
 func newStatusCommand() *cobra.Command {
@@ -1019,6 +1651,11 @@ func newAnalyticsCommand() *cobra.Command {
 	cmd.AddCommand(newAnalyticsLogsCommand())
 	cmd.AddCommand(newAnalyticsExportCommand())
 	cmd.AddCommand(newAnalyticsVelocityCommand())
+	cmd.AddCommand(newAnalyticsAgentsCommand())
+	cmd.AddCommand(newAnalyticsCycleTimeCommand())
+	cmd.AddCommand(newAnalyticsBurndownCommand())
+	cmd.AddCommand(newAnalyticsForecastCommand())
+	cmd.AddCommand(newAnalyticsBudgetCommand())
 	return cmd
 }
 
@@ -1039,12 +1676,24 @@ func newAnalyticsStatsCommand() *cobra.Command {
 }
 
 func newAnalyticsCostsCommand() *cobra.Command {
-	return &cobra.Command{
+	var beadID, agentID, projectID, actionType string
+
+	cmd := &cobra.Command{
 		Use:   "costs",
-		Short: "Show cost breakdown by provider",
+		Short: "Show cost breakdown by provider, user, bead, and action",
+		Example: `  loomctl analytics costs
+  loomctl analytics costs --project loom --action edit_code`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
-			data, err := client.get("/api/v1/analytics/costs", nil)
+			params := url.Values{}
+			for name, value := range map[string]string{
+				"bead_id": beadID, "agent_id": agentID, "project_id": projectID, "action_type": actionType,
+			} {
+				if value != "" {
+					params.Set(name, value)
+				}
+			}
+			data, err := client.get("/api/v1/analytics/costs", params)
 			if err != nil {
 				return err
 			}
@@ -1052,6 +1701,12 @@ func newAnalyticsCostsCommand() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&beadID, "bead", "", "Only count usage on this bead")
+	cmd.Flags().StringVar(&agentID, "agent", "", "Only count usage by this agent")
+	cmd.Flags().StringVar(&projectID, "project", "", "Only count usage in this project")
+	cmd.Flags().StringVar(&actionType, "action", "", "Only count model calls that asked for this action")
+	return cmd
 }
 
 func newAnalyticsLogsCommand() *cobra.Command {
@@ -1129,6 +1784,7 @@ func newConfigCommand() *cobra.Command {
 	}
 	cmd.AddCommand(newConfigShowCommand())
 	cmd.AddCommand(newConfigExportCommand())
+	cmd.AddCommand(newConfigExecutorCommand())
 	return cmd
 }
 
@@ -1169,6 +1825,61 @@ func newConfigExportCommand() *cobra.Command {
 	}
 }
 
+func newConfigExecutorCommand() *cobra.Command {
+	var (
+		project            string
+		maxConcurrentBeads int
+		maxLLMCalls        int
+		claimInterval      string
+	)
+	cmd := &cobra.Command{
+		Use:   "executor",
+		Short: "Show or change task executor concurrency limits",
+		Long: `Show the task executor's concurrency limits, or those in effect for a
+project with --project. Any of --max-concurrent-beads, --max-llm-calls or
+--claim-interval changes the global limits; the change applies at once.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			body := map[string]interface{}{}
+			if cmd.Flags().Changed("max-concurrent-beads") {
+				body["max_concurrent_beads"] = maxConcurrentBeads
+			}
+			if cmd.Flags().Changed("max-llm-calls") {
+				body["max_llm_calls"] = maxLLMCalls
+			}
+			if cmd.Flags().Changed("claim-interval") {
+				body["claim_interval"] = claimInterval
+			}
+			var (
+				data []byte
+				err  error
+			)
+			if len(body) == 0 {
+				var params url.Values
+				if project != "" {
+					params = url.Values{"project_id": {project}}
+				}
+				data, err = client.get("/api/v1/config/executor", params)
+			} else {
+				if project != "" {
+					return fmt.Errorf("per-project limits are set with 'project set-config'")
+				}
+				data, err = client.put("/api/v1/config/executor", body)
+			}
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Show the limits in effect for this project")
+	cmd.Flags().IntVar(&maxConcurrentBeads, "max-concurrent-beads", 0, "Beads each project works on at once")
+	cmd.Flags().IntVar(&maxLLMCalls, "max-llm-calls", 0, "Model calls in flight across all projects")
+	cmd.Flags().StringVar(&claimInterval, "claim-interval", "", "How often idle workers look for work (e.g. 5s)")
+	return cmd
+}
+
 // --- Event commands ---
 
 func newEventCommand() *cobra.Command {
@@ -1187,10 +1898,15 @@ func newEventListCommand() *cobra.Command {
 		projectID string
 		eventType string
 		limit     int
+		cursor    string
+		all       bool
 	)
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List recent events",
+		Example: `  loomctl event list --project=loom --limit=50
+  loomctl event list --limit=50 --cursor=<next_cursor>
+  loomctl event list --type=bead.created --all`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
 			params := url.Values{}
@@ -1203,6 +1919,23 @@ func newEventListCommand() *cobra.Command {
 			if limit > 0 {
 				params.Set("limit", fmt.Sprintf("%d", limit))
 			}
+			if cursor != "" {
+				params.Set("cursor", cursor)
+			}
+			if all {
+				events, err := client.getAllPages("/api/v1/events", params, "events")
+				if err != nil {
+					return err
+				}
+				data, err := json.Marshal(map[string]interface{}{
+					"events": json.RawMessage(events),
+				})
+				if err != nil {
+					return err
+				}
+				outputJSON(data)
+				return nil
+			}
 			data, err := client.get("/api/v1/events", params)
 			if err != nil {
 				return err
@@ -1213,7 +1946,9 @@ func newEventListCommand() *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Filter by project ID")
 	cmd.Flags().StringVar(&eventType, "type", "", "Filter by event type")
-	cmd.Flags().IntVarP(&limit, "limit", "n", 100, "Number of events")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 100, "Number of events (page size with --all)")
+	cmd.Flags().StringVar(&cursor, "cursor", "", "Resume from the next_cursor of a previous page")
+	cmd.Flags().BoolVar(&all, "all", false, "Fetch every page of retained events")
 	return cmd
 }
 
@@ -1271,8 +2006,17 @@ func newWorkflowCommand() *cobra.Command {
 	}
 	cmd.AddCommand(newWorkflowListCommand())
 	cmd.AddCommand(newWorkflowShowCommand())
+	cmd.AddCommand(newWorkflowPushCommand())
+	cmd.AddCommand(newWorkflowDeleteCommand())
+	cmd.AddCommand(newWorkflowVersionsCommand())
+	cmd.AddCommand(newWorkflowGraphCommand())
 	cmd.AddCommand(newWorkflowStartCommand())
 	cmd.AddCommand(newWorkflowExecutionsCommand())
+	cmd.AddCommand(newWorkflowExecutionCommand())
+	cmd.AddCommand(newWorkflowPauseCommand())
+	cmd.AddCommand(newWorkflowResumeCommand())
+	cmd.AddCommand(newWorkflowSkipCommand())
+	cmd.AddCommand(newWorkflowRollbackCommand())
 	cmd.AddCommand(newWorkflowAnalyticsCommand())
 	return cmd
 }
@@ -1389,6 +2133,150 @@ func newWorkflowAnalyticsCommand() *cobra.Command {
 	}
 }
 
+func newAnalyticsAgentsCommand() *cobra.Command {
+	var projectID string
+	var window string
+
+	cmd := &cobra.Command{
+		Use:   "agents",
+		Short: "Show the agent performance scoreboard",
+		Long: `Rank agents by task success rate over a time window, with beads closed,
+average completion time (seconds from start to close), loop-detection
+incidents, tokens consumed, and cost. Registered agents with no activity in
+the window are listed with zero scores.`,
+		Example: `  loomctl analytics agents --project loom-self --window 7d
+  loomctl analytics agents --window 24h -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			if window != "" {
+				params.Set("window", window)
+			}
+			data, err := client.get("/api/v1/analytics/agents", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only include this project")
+	cmd.Flags().StringVarP(&window, "window", "w", "7d", "Time window (e.g., 24h, 7d, 30d)")
+
+	return cmd
+}
+
+// flowParams builds the query of the cycle-time and burndown commands.
+func flowParams(projectID, tag, milestoneID, window string) url.Values {
+	params := url.Values{}
+	for key, value := range map[string]string{"project_id": projectID, "tag": tag, "milestone_id": milestoneID, "window": window} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	return params
+}
+
+func newAnalyticsCycleTimeCommand() *cobra.Command {
+	var projectID, tag, milestoneID, window string
+
+	cmd := &cobra.Command{
+		Use:   "cycle-time",
+		Short: "Show bead cycle time, lead time, and weekly throughput",
+		Long: `Show how long beads closed in a time window took, as the mean, median,
+p75, p90, p95, and maximum in seconds: cycle time from the first move to
+in_progress (or creation, for beads never started) to closing, and lead
+time from creation to closing. Throughput counts the beads closed in each
+week (Monday to Sunday, UTC) of the window.`,
+		Example: `  loomctl analytics cycle-time --project loom-self
+  loomctl analytics cycle-time --project loom-self --tag v2 --window 90d`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/analytics/cycle-time", flowParams(projectID, tag, milestoneID, window))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only include this project")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only include beads with this tag")
+	cmd.Flags().StringVar(&milestoneID, "milestone", "", "Only include beads in this milestone")
+	cmd.Flags().StringVarP(&window, "window", "w", "30d", "Time window (e.g., 7d, 30d, 90d)")
+
+	return cmd
+}
+
+func newAnalyticsBurndownCommand() *cobra.Command {
+	var projectID, tag, milestoneID, window string
+
+	cmd := &cobra.Command{
+		Use:   "burndown",
+		Short: "Show the day-by-day burndown of a tag or milestone",
+		Long: `Show, for each day of the window, how many beads with a tag or in a
+milestone existed and how many were still open at midnight UTC, ending with
+the state now. Beads added along the way raise the total.`,
+		Example: `  loomctl analytics burndown --tag v2 --window 14d
+  loomctl analytics burndown --milestone ms-1a2b3c4d -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if tag == "" && milestoneID == "" {
+				return fmt.Errorf("--tag or --milestone is required")
+			}
+			client := newClient()
+			data, err := client.get("/api/v1/analytics/burndown", flowParams(projectID, tag, milestoneID, window))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only include this project")
+	cmd.Flags().StringVar(&tag, "tag", "", "Beads with this tag")
+	cmd.Flags().StringVar(&milestoneID, "milestone", "", "Beads in this milestone")
+	cmd.Flags().StringVarP(&window, "window", "w", "30d", "Time window (e.g., 14d, 30d)")
+
+	return cmd
+}
+
+func newAnalyticsForecastCommand() *cobra.Command {
+	var lookback string
+
+	cmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Forecast this month's spend and show usage anomalies",
+		Long: `Project this month's spend in total, per provider, and per project from the
+daily run rate over the lookback, and list the providers, projects, and
+agents whose token usage in the last hour spiked far above their baseline.`,
+		Example: `  loomctl analytics forecast
+  loomctl analytics forecast --lookback 30d`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if lookback != "" {
+				params.Set("lookback", lookback)
+			}
+			data, err := client.get("/api/v1/analytics/forecast", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&lookback, "lookback", "", "History the run rate is computed from (default 14d)")
+
+	return cmd
+}
+
 // --- Agent commands ---
 
 func newAgentCommand() *cobra.Command {
@@ -1398,6 +2286,48 @@ func newAgentCommand() *cobra.Command {
 	}
 	cmd.AddCommand(newAgentListCommand())
 	cmd.AddCommand(newAgentShowCommand())
+	cmd.AddCommand(newAgentUpdateCommand())
+	cmd.AddCommand(newAgentHoldCommand("pause", "Stop an agent taking new work, cancelling its current bead",
+		"The bead the agent is working on, if any, goes back to the open queue."))
+	cmd.AddCommand(newAgentHoldCommand("drain", "Let an agent finish its current bead, then pause it",
+		"The agent takes no new work; once its current bead is done it is paused."))
+	cmd.AddCommand(newAgentTailCommand())
+	cmd.AddCommand(newAgentHoldCommand("resume", "Let a paused or draining agent take work again", ""))
+	cmd.AddCommand(newAgentScaleCommand())
+	return cmd
+}
+
+func newAgentScaleCommand() *cobra.Command {
+	var (
+		projectID string
+		role      string
+		replicas  int
+	)
+	cmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Set how many agents fill a role in a project",
+		Long: `Scale a role to a number of agents. Each agent works one bead at a time,
+and the role's beads are sharded across them. Scaling down drains the
+agents added last: they finish their current bead, then stop.`,
+		Example: `  loomctl agent scale --project loom --role coder --replicas 3`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(fmt.Sprintf("/api/v1/projects/%s/agents/scale", projectID), map[string]interface{}{
+				"role":     role,
+				"replicas": replicas,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (required)")
+	cmd.Flags().StringVar(&role, "role", "", "Role to scale, as named in the org chart (required)")
+	cmd.Flags().IntVar(&replicas, "replicas", 1, "Number of agents")
+	cmd.MarkFlagRequired("project")
+	cmd.MarkFlagRequired("role")
 	return cmd
 }
 
@@ -1441,6 +2371,65 @@ func newAgentShowCommand() *cobra.Command {
 	}
 }
 
+func newAgentUpdateCommand() *cobra.Command {
+	var (
+		name         string
+		capabilities []string
+	)
+	cmd := &cobra.Command{
+		Use:   "update <agent-id>",
+		Short: "Update an agent's name or capability tags",
+		Long: `Update an agent. Capabilities are skill tags such as languages, frameworks,
+or domains; the dispatcher prefers agents whose capabilities match a bead's
+tags and falls back to round-robin when none do. --capabilities replaces the
+whole list, and --capabilities="" clears it.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  loomctl agent update agent-123 --capabilities=go,postgres,backend
+  loomctl agent update agent-123 --capabilities=""`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]interface{}{}
+			if name != "" {
+				body["name"] = name
+			}
+			if cmd.Flags().Changed("capabilities") {
+				body["capabilities"] = capabilities
+			}
+			if len(body) == 0 {
+				return fmt.Errorf("nothing to update: pass --name or --capabilities")
+			}
+			client := newClient()
+			data, err := client.put("/api/v1/agents/"+url.PathEscape(args[0]), body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "New agent name")
+	cmd.Flags().StringSliceVar(&capabilities, "capabilities", nil, "Comma-separated capability tags (replaces existing)")
+	return cmd
+}
+
+func newAgentHoldCommand(action, short, long string) *cobra.Command {
+	return &cobra.Command{
+		Use:     action + " <agent-id>",
+		Short:   short,
+		Long:    strings.TrimSpace(short + ". " + long),
+		Args:    cobra.ExactArgs(1),
+		Example: "  loomctl agent " + action + " agent-123",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/agents/"+url.PathEscape(args[0])+"/"+action, nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
 // --- Project commands ---
 
 func newProjectCommand() *cobra.Command {
@@ -1451,9 +2440,141 @@ func newProjectCommand() *cobra.Command {
 	cmd.AddCommand(newProjectListCommand())
 	cmd.AddCommand(newProjectShowCommand())
 	cmd.AddCommand(newProjectResetBeadsCommand())
+	cmd.AddCommand(newProjectBoardCommand())
+	cmd.AddCommand(newProjectGraphCommand())
+	cmd.AddCommand(newProjectHealthCommand())
+	cmd.AddCommand(newProjectConfigCommand())
+	cmd.AddCommand(newProjectSetConfigCommand())
+	cmd.AddCommand(newProjectUnsetConfigCommand())
+	cmd.AddCommand(newProjectArchiveCommand())
+	cmd.AddCommand(newProjectUnarchiveCommand())
 	return cmd
 }
 
+func newProjectConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config <project-id>",
+		Short: "Show a project's overrides of global settings",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get(fmt.Sprintf("/api/v1/projects/%s/config", args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newProjectSetConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-config <project-id> <key=value>...",
+		Short: "Override global settings for a project",
+		Long: `Sets per-project overrides. Keys: max_loop_iterations, readiness_mode
+(block or warn), bead_prefix, build_command, test_command, lint_command.
+Settings are validated together; nothing is set if any is invalid.`,
+		Args: cobra.MinimumNArgs(2),
+		Example: `  loomctl project set-config loom-self max_loop_iterations=40 readiness_mode=block
+  loomctl project set-config loom-self test_command="go test ./..."`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings := make(map[string]string, len(args)-1)
+			for _, arg := range args[1:] {
+				key, value, ok := strings.Cut(arg, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("expected key=value, got %q", arg)
+				}
+				settings[key] = value
+			}
+			client := newClient()
+			data, err := client.put(fmt.Sprintf("/api/v1/projects/%s/config", args[0]), map[string]interface{}{
+				"settings": settings,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newProjectUnsetConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset-config <project-id> <key>...",
+		Short: "Return project settings to the global defaults",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			for _, key := range args[1:] {
+				path := fmt.Sprintf("/api/v1/projects/%s/config?key=%s", args[0], url.QueryEscape(key))
+				if _, err := client.delete(path); err != nil {
+					return err
+				}
+				fmt.Printf("Unset %s\n", key)
+			}
+			return nil
+		},
+	}
+}
+
+func newProjectArchiveCommand() *cobra.Command {
+	var export string
+	cmd := &cobra.Command{
+		Use:   "archive <project-id>",
+		Short: "Freeze a project into cold storage",
+		Long: `Stops the project's workers and container, compresses its beads and
+conversation history into the server's archive table and hides it from
+project list. --export also saves the compressed archive (gzipped JSON)
+to a file. Undo with project unarchive.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  loomctl project archive old-site
+  loomctl project archive old-site --export old-site.json.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(fmt.Sprintf("/api/v1/projects/%s/archive", args[0]), nil)
+			if err != nil {
+				return err
+			}
+			if export != "" {
+				archive, _, err := client.download(fmt.Sprintf("/api/v1/projects/%s/archive?download=true", args[0]))
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(export, archive, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Saved %s (%d bytes)\n", export, len(archive))
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&export, "export", "", "Also save the compressed archive to this file")
+	return cmd
+}
+
+func newProjectUnarchiveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unarchive <project-id>",
+		Short: "Restore an archived project",
+		Long: `Restores the project's conversation history, reloads its beads and
+returns it to the status it had before it was archived. Its workers start
+again within 30 seconds.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(fmt.Sprintf("/api/v1/projects/%s/unarchive", args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
 func newProjectResetBeadsCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "reset-beads <project-id>",
@@ -1475,12 +2596,17 @@ recovery, or any out-of-band change to the project's beads worktree.`,
 }
 
 func newProjectListCommand() *cobra.Command {
-	return &cobra.Command{
+	var all bool
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List projects",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := newClient()
-			data, err := client.get("/api/v1/projects", nil)
+			params := url.Values{}
+			if all {
+				params.Set("include_archived", "true")
+			}
+			data, err := client.get("/api/v1/projects", params)
 			if err != nil {
 				return err
 			}
@@ -1488,6 +2614,8 @@ func newProjectListCommand() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&all, "all", false, "Include archived projects")
+	return cmd
 }
 
 func newProjectShowCommand() *cobra.Command {
@@ -1518,6 +2646,8 @@ func newProviderCommand() *cobra.Command {
 	cmd.AddCommand(newProviderShowCommand())
 	cmd.AddCommand(newProviderRegisterCommand())
 	cmd.AddCommand(newProviderDeleteCommand())
+	cmd.AddCommand(newProviderTestCommand())
+	cmd.AddCommand(newProviderPolicyCommand())
 	return cmd
 }
 
@@ -1554,6 +2684,33 @@ func newProviderShowCommand() *cobra.Command {
 	}
 }
 
+func newProviderTestCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test <provider-id>",
+		Short: "Run health diagnostics against a provider",
+		Long: `Send the health-check chat completion to a provider, list its models, and
+check that its API key is accepted. Prints a pass/fail report and exits
+non-zero if any check fails.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(fmt.Sprintf("/api/v1/providers/%s/test", args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+
+			var report struct {
+				Passed bool `json:"passed"`
+			}
+			if err := json.Unmarshal(data, &report); err == nil && !report.Passed {
+				return fmt.Errorf("provider %s failed diagnostics", args[0])
+			}
+			return nil
+		},
+	}
+}
+
 func newProviderRegisterCommand() *cobra.Command {
 	var (
 		name        string
@@ -1593,7 +2750,7 @@ func newProviderRegisterCommand() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&name, "name", "", "Display name")
-	cmd.Flags().StringVar(&provType, "type", "openai", "Provider type (openai, anthropic)")
+	cmd.Flags().StringVar(&provType, "type", "openai", "Provider type (openai, anthropic, gemini, bedrock, vllm, ollama, local, custom)")
 	cmd.Flags().StringVar(&endpoint, "endpoint", "", "API endpoint URL")
 	cmd.Flags().StringVar(&model, "model", "", "Default model ID")
 	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key")
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newMilestoneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "milestone",
+		Short: "Group beads of one or more projects under a milestone",
+		Long: `Manage milestones: named groups of beads (a release, a sprint, an epic)
+that can span several projects. Each milestone reports how many of its beads
+are open, in progress, blocked, and closed. A milestone created with
+--close-requires-beads can't be closed until every bead in it is.`,
+	}
+	cmd.AddCommand(newMilestoneListCommand())
+	cmd.AddCommand(newMilestoneCreateCommand())
+	cmd.AddCommand(newMilestoneShowCommand())
+	cmd.AddCommand(newMilestoneUpdateCommand())
+	cmd.AddCommand(newMilestoneCloseCommand())
+	cmd.AddCommand(newMilestoneReopenCommand())
+	cmd.AddCommand(newMilestoneDeleteCommand())
+	cmd.AddCommand(newMilestoneAddCommand())
+	cmd.AddCommand(newMilestoneRemoveCommand())
+	return cmd
+}
+
+func newMilestoneListCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List milestones with their progress",
+		Example: `  loomctl milestone list
+  loomctl milestone list --project=loom -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			data, err := client.get("/api/v1/milestones", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only milestones that include this project")
+	return cmd
+}
+
+func newMilestoneCreateCommand() *cobra.Command {
+	var (
+		name               string
+		description        string
+		projects           string
+		milestoneType      string
+		due                string
+		closeRequiresBeads bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a milestone",
+		Example: `  loomctl milestone create --name="v2.0" --project=loom --due=2026-12-01 --type=release
+  loomctl milestone create --name="Auth rewrite" --project=loom,loom-ui --close-requires-beads`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			body := map[string]interface{}{
+				"name":                 name,
+				"description":          description,
+				"project_ids":          strings.Split(projects, ","),
+				"close_requires_beads": closeRequiresBeads,
+			}
+			if milestoneType != "" {
+				body["type"] = milestoneType
+			}
+			if due != "" {
+				body["due_date"] = due
+			}
+			data, err := client.post("/api/v1/milestones", body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Milestone name (required)")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "Milestone description")
+	cmd.Flags().StringVarP(&projects, "project", "p", "", "Comma-separated project IDs (required)")
+	cmd.Flags().StringVar(&milestoneType, "type", "", "release, sprint_end, quarterly_review, annual_review, or custom (default custom)")
+	cmd.Flags().StringVar(&due, "due", "", "Due date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().BoolVar(&closeRequiresBeads, "close-requires-beads", false, "Refuse to close the milestone while any of its beads is open")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("project")
+	return cmd
+}
+
+func newMilestoneShowCommand() *cobra.Command {
+	var showBeads bool
+	cmd := &cobra.Command{
+		Use:   "show <milestone-id>",
+		Short: "Show a milestone and its progress",
+		Example: `  loomctl milestone show ms-1a2b3c4d
+  loomctl milestone show ms-1a2b3c4d --beads -o table`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			path := "/api/v1/milestones/" + url.PathEscape(args[0])
+			if showBeads {
+				path += "/beads"
+			}
+			data, err := client.get(path, nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&showBeads, "beads", false, "List the milestone's beads instead")
+	return cmd
+}
+
+func newMilestoneUpdateCommand() *cobra.Command {
+	var (
+		name               string
+		description        string
+		projects           string
+		milestoneType      string
+		due                string
+		closeRequiresBeads bool
+	)
+	cmd := &cobra.Command{
+		Use:   "update <milestone-id>",
+		Short: "Update milestone fields",
+		Long: `Update a milestone. --project replaces the milestone's projects; a project
+that still has beads in the milestone can't be removed.`,
+		Example: `  loomctl milestone update ms-1a2b3c4d --due=2027-01-15
+  loomctl milestone update ms-1a2b3c4d --project=loom,loom-ui,loom-docs`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]interface{}{}
+			if cmd.Flags().Changed("name") {
+				body["name"] = name
+			}
+			if cmd.Flags().Changed("description") {
+				body["description"] = description
+			}
+			if cmd.Flags().Changed("project") {
+				body["project_ids"] = strings.Split(projects, ",")
+			}
+			if cmd.Flags().Changed("type") {
+				body["type"] = milestoneType
+			}
+			if cmd.Flags().Changed("due") {
+				body["due_date"] = due
+			}
+			if cmd.Flags().Changed("close-requires-beads") {
+				body["close_requires_beads"] = closeRequiresBeads
+			}
+			if len(body) == 0 {
+				return fmt.Errorf("nothing to update: pass --name, --description, --project, --type, --due, or --close-requires-beads")
+			}
+			client := newClient()
+			data, err := client.patch("/api/v1/milestones/"+url.PathEscape(args[0]), body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "New name")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "New description")
+	cmd.Flags().StringVarP(&projects, "project", "p", "", "Comma-separated project IDs")
+	cmd.Flags().StringVar(&milestoneType, "type", "", "New type")
+	cmd.Flags().StringVar(&due, "due", "", "Due date (YYYY-MM-DD or RFC 3339; empty to clear)")
+	cmd.Flags().BoolVar(&closeRequiresBeads, "close-requires-beads", false, "Refuse to close the milestone while any of its beads is open")
+	return cmd
+}
+
+func newMilestoneCloseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "close <milestone-id>",
+		Short:   "Mark a milestone complete",
+		Example: `  loomctl milestone close ms-1a2b3c4d`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/milestones/"+url.PathEscape(args[0])+"/close", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newMilestoneReopenCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "reopen <milestone-id>",
+		Short:   "Reopen a closed milestone",
+		Example: `  loomctl milestone reopen ms-1a2b3c4d`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/milestones/"+url.PathEscape(args[0])+"/reopen", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newMilestoneDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete <milestone-id>",
+		Short:   "Delete a milestone; its beads are kept and leave the milestone",
+		Example: `  loomctl milestone delete ms-1a2b3c4d`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/milestones/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Milestone %s deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newMilestoneAddCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <milestone-id> <bead-id>...",
+		Short: "Add beads to a milestone",
+		Long: `Add beads to a milestone, moving them out of any other milestone. Every
+bead must belong to one of the milestone's projects, and the milestone must
+not be closed; otherwise no bead is changed.`,
+		Example: `  loomctl milestone add ms-1a2b3c4d loom-a1 loom-b2`,
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setBeadsMilestone(args[1:], args[0])
+		},
+	}
+}
+
+func newMilestoneRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <bead-id>...",
+		Short:   "Take beads out of their milestone",
+		Example: `  loomctl milestone remove loom-a1 loom-b2`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setBeadsMilestone(args, "")
+		},
+	}
+}
+
+// setBeadsMilestone sets the milestone of beads in one bulk update.
+func setBeadsMilestone(beadIDs []string, milestoneID string) error {
+	client := newClient()
+	data, err := client.post("/api/v1/beads/bulk", map[string]interface{}{
+		"ids":     beadIDs,
+		"updates": map[string]interface{}{"milestone_id": milestoneID},
+	})
+	if err != nil {
+		return err
+	}
+	outputJSON(data)
+	return nil
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// loomctlContext is the selection saved in ~/.loomctl_context.
+type loomctlContext struct {
+	Org string `json:"org,omitempty"`
+}
+
+func contextPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".loomctl_context"), nil
+}
+
+func loadContext() loomctlContext {
+	var ctx loomctlContext
+	path, err := contextPath()
+	if err != nil {
+		return ctx
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &ctx)
+	}
+	return ctx
+}
+
+func saveContext(ctx loomctlContext) error {
+	path, err := contextPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ctx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// getDefaultOrg returns LOOM_ORG, or the organization saved by 'org use'.
+// An empty result lets the server pick the caller's default organization.
+func getDefaultOrg() string {
+	if org := os.Getenv("LOOM_ORG"); org != "" {
+		return org
+	}
+	return loadContext().Org
+}
+
+func newOrgCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "Manage organizations, teams, and members",
+		Long: `Manage organizations. Projects, providers, agents, and budgets belong to an
+organization, and every request acts in one: the one given with --org, else
+LOOM_ORG, else the one saved with 'loomctl org use', else the server's
+default for the caller.
+
+Teams split an organization further. Members limited to a team only see the
+organization's projects that belong to the team or to no team.`,
+	}
+	cmd.AddCommand(newOrgListCommand())
+	cmd.AddCommand(newOrgCreateCommand())
+	cmd.AddCommand(newOrgDeleteCommand())
+	cmd.AddCommand(newOrgUseCommand())
+	cmd.AddCommand(newOrgCurrentCommand())
+	cmd.AddCommand(newOrgTeamCommand())
+	cmd.AddCommand(newOrgMemberCommand())
+	cmd.AddCommand(newOrgAssignCommand())
+	return cmd
+}
+
+func newOrgListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the organizations you belong to (all of them for server admins)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/orgs", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newOrgCreateCommand() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:     "create <org-id>",
+		Short:   "Create an organization (server admins only)",
+		Example: `  loomctl org create acme --name "Acme Corp"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/orgs", map[string]string{
+				"id":   args[0],
+				"name": name,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Display name (defaults to the ID)")
+	return cmd
+}
+
+func newOrgDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <org-id>",
+		Short: "Delete an organization that no longer owns any resources",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/orgs/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted organization %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newOrgUseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <org-id>",
+		Short: "Act in an organization by default, saved in ~/.loomctl_context",
+		Long: `Save the organization later commands act in. The server is asked first, so
+an organization you can't act in is never saved. Pass "" to go back to the
+server's default.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] != "" {
+				client := newClient()
+				client.Org = args[0]
+				if _, err := client.get("/api/v1/orgs/"+url.PathEscape(args[0]), nil); err != nil {
+					return err
+				}
+			}
+			ctx := loadContext()
+			ctx.Org = args[0]
+			if err := saveContext(ctx); err != nil {
+				return fmt.Errorf("failed to save context: %w", err)
+			}
+			if args[0] == "" {
+				fmt.Println("Using the server's default organization")
+			} else {
+				fmt.Printf("Using organization %s\n", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+func newOrgCurrentCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "current",
+		Short: "Show the organization commands act in",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if orgName == "" {
+				fmt.Println("(server default)")
+			} else {
+				fmt.Println(orgName)
+			}
+			return nil
+		},
+	}
+}
+
+// currentOrg returns the organization the team and member commands manage.
+func currentOrg() (string, error) {
+	if orgName == "" {
+		return "", fmt.Errorf("no organization selected: pass --org or run 'loomctl org use <org-id>'")
+	}
+	return url.PathEscape(orgName), nil
+}
+
+func newOrgTeamCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "team",
+		Short: "Manage the teams of the current organization",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List teams",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.get("/api/v1/orgs/"+org+"/teams", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "create <name>",
+		Short:   "Create a team",
+		Example: `  loomctl --org acme org team create platform`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/orgs/"+org+"/teams", map[string]string{"name": args[0]})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "delete <team-id>",
+		Short: "Delete a team; its members and projects stay in the organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			if _, err := client.delete("/api/v1/orgs/" + org + "/teams/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted team %s\n", args[0])
+			return nil
+		},
+	})
+	return cmd
+}
+
+func newOrgMemberCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "member",
+		Short: "Manage the members of the current organization",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List members",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.get("/api/v1/orgs/"+org+"/members", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	})
+
+	var team, role string
+	add := &cobra.Command{
+		Use:   "add <username|user-id>",
+		Short: "Add a user to the organization, or change their team and role",
+		Example: `  loomctl --org acme org member add alice --role admin
+  loomctl --org acme org member add bob --team team-1a2b3c4d`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.post("/api/v1/orgs/"+org+"/members", map[string]string{
+				"user_id": userID,
+				"team_id": team,
+				"role":    role,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	add.Flags().StringVar(&team, "team", "", "Limit the member to a team")
+	add.Flags().StringVar(&role, "role", "member", "Role in the organization (admin, member)")
+	cmd.AddCommand(add)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "remove <username|user-id>",
+		Short: "Remove a user from the organization",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			org, err := currentOrg()
+			if err != nil {
+				return err
+			}
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := client.delete("/api/v1/orgs/" + org + "/members/" + url.PathEscape(userID)); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s from %s\n", args[0], orgName)
+			return nil
+		},
+	})
+	return cmd
+}
+
+func newOrgAssignCommand() *cobra.Command {
+	var (
+		to   string
+		team string
+	)
+	cmd := &cobra.Command{
+		Use:   "assign <projects|providers|agents|budgets> <id>",
+		Short: "Move a resource into an organization",
+		Long: `Move a project, provider, agent, or budget from the current organization
+into another one (or a team of the current one). Agents working on a
+project always follow the project's organization.`,
+		Example: `  loomctl --org default org assign projects loom --to acme
+  loomctl --org acme org assign projects loom --team team-1a2b3c4d`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" {
+				to = orgName
+			}
+			if to == "" {
+				return fmt.Errorf("no organization to assign to: pass --to or --org")
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/orgs/"+url.PathEscape(to)+"/resources", map[string]string{
+				"resource_type": args[0],
+				"resource_id":   args[1],
+				"team_id":       team,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "Organization to move the resource to (defaults to the current one)")
+	cmd.Flags().StringVar(&team, "team", "", "Team of that organization (projects only)")
+	return cmd
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func orgChartPath(projectID string) string {
+	return fmt.Sprintf("/api/v1/projects/%s/orgchart", url.PathEscape(projectID))
+}
+
+func newOrgChartCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orgchart <project-id>",
+		Short: "Show and edit a project's org chart",
+		Long: `Show a project's org chart: its positions, who each reports to, and the
+agents filling them. Subcommands add custom roles, change reporting lines
+and limits, and remove positions. Changes are kept across restarts; new
+default positions are still added to the chart, except default roles that
+were removed on purpose.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get(orgChartPath(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.AddCommand(newOrgChartAddCommand())
+	cmd.AddCommand(newOrgChartUpdateCommand())
+	cmd.AddCommand(newOrgChartRemoveCommand())
+	cmd.AddCommand(newOrgChartResetCommand())
+	return cmd
+}
+
+func newOrgChartAddCommand() *cobra.Command {
+	var (
+		id           string
+		persona      string
+		reportsTo    string
+		maxInstances int
+		required     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "add <project-id> <role>",
+		Short: "Add a position to a project's org chart",
+		Long: `Add a position, such as a custom role. The position ID defaults to
+pos-<role> and the persona to default/<role>. An agent is created for the
+position if its persona exists.`,
+		Args: cobra.ExactArgs(2),
+		Example: `  loomctl orgchart add loom-self security-auditor --persona=default/code-reviewer --reports-to=pos-em
+  loomctl orgchart add loom-self release-manager --max-instances=1 --required`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(orgChartPath(args[0]), map[string]interface{}{
+				"id":            id,
+				"role_name":     args[1],
+				"persona_path":  persona,
+				"reports_to":    reportsTo,
+				"max_instances": maxInstances,
+				"required":      required,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&id, "id", "", "Position ID (default pos-<role>)")
+	cmd.Flags().StringVar(&persona, "persona", "", "Persona path (default default/<role>)")
+	cmd.Flags().StringVar(&reportsTo, "reports-to", "", "Position ID of the manager")
+	cmd.Flags().IntVar(&maxInstances, "max-instances", 0, "Most agents in the position (0 = unlimited)")
+	cmd.Flags().BoolVar(&required, "required", false, "The position must be filled for the project to be active")
+	return cmd
+}
+
+func newOrgChartUpdateCommand() *cobra.Command {
+	var (
+		persona      string
+		reportsTo    string
+		maxInstances int
+		required     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "update <project-id> <position-id>",
+		Short: "Change a position's persona, reporting line or limits",
+		Long: `Change a position. Only the flags given are changed; --reports-to=""
+makes the position report to no one.`,
+		Args: cobra.ExactArgs(2),
+		Example: `  loomctl orgchart update loom-self pos-qa --reports-to=pos-pm --max-instances=3
+  loomctl orgchart update loom-self pos-cfo --required=false`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update := map[string]interface{}{}
+			if cmd.Flags().Changed("persona") {
+				update["persona_path"] = persona
+			}
+			if cmd.Flags().Changed("reports-to") {
+				update["reports_to"] = reportsTo
+			}
+			if cmd.Flags().Changed("max-instances") {
+				update["max_instances"] = maxInstances
+			}
+			if cmd.Flags().Changed("required") {
+				update["required"] = required
+			}
+			if len(update) == 0 {
+				return fmt.Errorf("nothing to change; see --help for the flags")
+			}
+			client := newClient()
+			data, err := client.put(orgChartPath(args[0])+"/positions/"+url.PathEscape(args[1]), update)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&persona, "persona", "", "Persona path")
+	cmd.Flags().StringVar(&reportsTo, "reports-to", "", "Position ID of the manager")
+	cmd.Flags().IntVar(&maxInstances, "max-instances", 0, "Most agents in the position (0 = unlimited)")
+	cmd.Flags().BoolVar(&required, "required", false, "The position must be filled for the project to be active")
+	return cmd
+}
+
+func newOrgChartRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <project-id> <position-id>",
+		Short: "Remove a position from a project's org chart",
+		Long: `Remove a position. Positions that reported to it report to its manager
+instead. Agents that held it stay in the project, unassigned.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.delete(orgChartPath(args[0]) + "/positions/" + url.PathEscape(args[1]))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newOrgChartResetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reset <project-id>",
+		Short: "Discard a project's org chart changes and restore the default chart",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.delete(orgChartPath(args[0]))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// getAllPages follows next_cursor on a paginated list endpoint until the last
+// page and returns the concatenated items found under key in each response.
+func (c *Client) getAllPages(path string, params url.Values, key string) ([]byte, error) {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+
+	items := []json.RawMessage{}
+	for {
+		data, err := c.get(path, query)
+		if err != nil {
+			return nil, err
+		}
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse page: %w", err)
+		}
+		var pageItems []json.RawMessage
+		if raw, ok := page[key]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		var next string
+		if raw, ok := page["next_cursor"]; ok {
+			_ = json.Unmarshal(raw, &next)
+		}
+		if next == "" || len(pageItems) == 0 {
+			break
+		}
+		query.Set("cursor", next)
+	}
+	return json.Marshal(items)
+}
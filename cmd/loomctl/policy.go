@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newProviderPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage provider routing policies and show circuit breakers",
+		Long: `Manage provider routing policies. A policy picks the provider work is routed
+to and the order providers are failed over in. Projects without their own
+policy use the default policy; with no default, the first healthy provider
+is used.
+
+Strategies:
+  priority               Lowest priority value first, fail over in order
+  weighted_round_robin   Spread work across providers by weight
+  first_healthy          First healthy provider by ID
+
+Without a subcommand, shows every policy and each provider's circuit breaker.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/providers/policies", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.AddCommand(newProviderPolicyShowCommand())
+	cmd.AddCommand(newProviderPolicySetCommand())
+	cmd.AddCommand(newProviderPolicyDeleteCommand())
+	return cmd
+}
+
+// policyPath returns the API path for a project's policy, or the default
+// policy when projectID is empty.
+func policyPath(projectID string) string {
+	if projectID == "" {
+		projectID = "default"
+	}
+	return "/api/v1/providers/policies/" + url.PathEscape(projectID)
+}
+
+func newProviderPolicyShowCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective policy and failover order for a project",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get(policyPath(projectID), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (default policy if omitted)")
+	return cmd
+}
+
+func newProviderPolicySetCommand() *cobra.Command {
+	var (
+		projectID string
+		strategy  string
+		providers []string
+	)
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the routing policy for a project or the default policy",
+		Long: `Set a routing policy. Each --provider is id[:priority[:weight]]. When
+priority is omitted, providers are prioritized in the order given.`,
+		Example: `  loomctl provider policy set --strategy=priority --provider=tokenhub --provider=local-vllm
+  loomctl provider policy set --project=loom --strategy=weighted_round_robin \
+    --provider=tokenhub:0:3 --provider=local-vllm:0:1`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			routes := make([]map[string]interface{}, 0, len(providers))
+			for i, spec := range providers {
+				route, err := parseProviderRoute(spec, i)
+				if err != nil {
+					return err
+				}
+				routes = append(routes, route)
+			}
+			client := newClient()
+			data, err := client.put(policyPath(projectID), map[string]interface{}{
+				"strategy":  strategy,
+				"providers": routes,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (default policy if omitted)")
+	cmd.Flags().StringVar(&strategy, "strategy", "priority", "Routing strategy: priority, weighted_round_robin, first_healthy")
+	cmd.Flags().StringArrayVar(&providers, "provider", nil, "Provider route as id[:priority[:weight]] (repeatable)")
+	return cmd
+}
+
+// parseProviderRoute parses id[:priority[:weight]]; index is used as the
+// priority when none is given.
+func parseProviderRoute(spec string, index int) (map[string]interface{}, error) {
+	parts := strings.Split(spec, ":")
+	if parts[0] == "" || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid provider route %q (want id[:priority[:weight]])", spec)
+	}
+	route := map[string]interface{}{"provider_id": parts[0], "priority": index}
+	if len(parts) > 1 && parts[1] != "" {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority in %q", spec)
+		}
+		route["priority"] = n
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight in %q", spec)
+		}
+		route["weight"] = n
+	}
+	return route, nil
+}
+
+func newProviderPolicyDeleteCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Remove a project's policy (or the default policy)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete(policyPath(projectID)); err != nil {
+				return err
+			}
+			if projectID == "" {
+				fmt.Println("Deleted default routing policy")
+			} else {
+				fmt.Printf("Deleted routing policy for project %s\n", projectID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (default policy if omitted)")
+	return cmd
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newProjectHealthCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "health <project-id>",
+		Short: "Show a project's health score and what it is made of",
+		Long: `Show a project's health score, 0-100, and the components it averages:
+dispatch readiness, open P0 beads, SLA breaches, provider health, container
+health, and the error rate of the last 24 hours of model requests.
+Components loom has no data for are marked unknown and don't count.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl project health loom-self`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/projects/"+url.PathEscape(args[0])+"/health", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
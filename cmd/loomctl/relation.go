@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+const relationHelp = `Relations:
+  duplicate_of  the bead duplicates the target; the bead is closed
+  related_to    the beads are related (both ways)
+  parent        the target is the bead's parent
+  child         the target is the bead's child
+  blocked_by    the bead can't start until the target is closed
+  blocks        the target can't start until the bead is closed`
+
+func newBeadRelationsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "relations <bead-id>",
+		Short: "Show a bead's parent, children, blockers, related beads and duplicates",
+		Long: `Show everything a bead is related to. Beads with children include a
+rollup of their descendants' progress.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  loomctl bead relations loom-001`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/beads/"+url.PathEscape(args[0])+"/relations", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadRelateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "relate <bead-id> <relation> <target-bead-id>",
+		Short: "Relate a bead to another",
+		Long:  "Relate a bead to another.\n\n" + relationHelp,
+		Args:  cobra.ExactArgs(3),
+		Example: `  loomctl bead relate loom-014 duplicate_of loom-009
+  loomctl bead relate loom-020 parent loom-001
+  loomctl bead relate loom-021 related_to loom-022`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/"+url.PathEscape(args[0])+"/relations", map[string]string{
+				"relation": args[1],
+				"target":   args[2],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadUnrelateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unrelate <bead-id> <relation> <target-bead-id>",
+		Short: "Remove a relation between two beads",
+		Long: "Remove a relation between two beads. A bead closed as a duplicate\nstays closed.\n\n" +
+			relationHelp,
+		Args:    cobra.ExactArgs(3),
+		Example: `  loomctl bead unrelate loom-014 duplicate_of loom-009`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{"relation": {args[1]}, "target": {args[2]}}
+			data, err := client.do("DELETE", "/api/v1/beads/"+url.PathEscape(args[0])+"/relations", params, nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newBeadLinkCommand() *cobra.Command {
+	var relation string
+	cmd := &cobra.Command{
+		Use:   "link <bead-id> <target-bead-id>",
+		Short: "Link a bead to a bead in another project",
+		Long: `Link a bead to another, typically in another project: an app feature
+blocked by a library bug, say. Linked beads are ordinary relations, so the
+dispatcher holds a bead blocked by a bead in another project until that bead
+is closed, and each project's work graph shows the beads of other projects
+its beads are linked to.
+
+` + relationHelp,
+		Args: cobra.ExactArgs(2),
+		Example: `  loomctl bead link app-012 lib-003 --as=blocked_by
+  loomctl bead link app-012 lib-007`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/"+url.PathEscape(args[0])+"/relations", map[string]string{
+				"relation": relation,
+				"target":   args[1],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&relation, "as", "related_to", "Relation of the bead to the target")
+	return cmd
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newRemoteAgentCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote-agent",
+		Short: "Manage agents that run outside the control plane's network",
+		Long: `Manage remote agents: agents on machines the control plane cannot reach,
+such as laptops or on-prem GPU boxes. A remote agent enrolls once with a
+token from "remote-agent token create", then connects out to the control
+plane and pulls ready beads of its project.`,
+	}
+	cmd.AddCommand(newRemoteAgentTokenCommand())
+	cmd.AddCommand(newRemoteAgentListCommand())
+	cmd.AddCommand(newRemoteAgentRevokeCommand())
+	return cmd
+}
+
+func newRemoteAgentTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage remote agent enrollment tokens",
+	}
+
+	var projects, roles []string
+	var ttl string
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Issue a one-time enrollment token",
+		Long: `Issue a one-time enrollment token for agents serving the given projects,
+optionally only in the given roles. The token is shown once; pass it to the
+agent as REMOTE_ENROLL_TOKEN.`,
+		Example: `  loomctl remote-agent token create --project loom --role qa-engineer --ttl 2h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(projects) == 0 {
+				return fmt.Errorf("at least one --project is required")
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/remote-agents/tokens", map[string]interface{}{
+				"project_ids": projects,
+				"roles":       roles,
+				"ttl":         ttl,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	create.Flags().StringSliceVar(&projects, "project", nil, "Project the agent may serve (repeatable)")
+	create.Flags().StringSliceVar(&roles, "role", nil, "Role the agent may take (repeatable; default any)")
+	create.Flags().StringVar(&ttl, "ttl", "", "How long the token can be used, e.g. 2h (default 24h, at most 720h)")
+	cmd.AddCommand(create)
+	return cmd
+}
+
+func newRemoteAgentListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List remote agents with their status and the beads they hold",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/remote-agents", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newRemoteAgentRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <agent-id>",
+		Short: "Revoke a remote agent, disconnecting it and releasing its beads",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.delete("/api/v1/remote-agents/" + args[0])
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	replPrompt         = "loom> "
+	replContinuePrompt = "  ... "
+	replHistoryLimit   = 1000
+)
+
+func newReplCommand() *cobra.Command {
+	var noStream bool
+	cmd := &cobra.Command{
+		Use:   "repl",
+		Short: "Interactive CEO REPL session",
+		Long: `Open an interactive session with the CEO REPL. Each query creates a P0
+bead; its ID is printed after the response.
+
+Prefix a query with a persona to address it ("qa-engineer: what's flaky?").
+End a line with \ to continue on the next line, or wrap a block in """.
+Up/down recall history, which is kept in ~/.loomctl_history.
+Type exit, quit, or Ctrl-D to leave.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRepl(newClient(), !noStream)
+		},
+	}
+	cmd.Flags().BoolVar(&noStream, "no-stream", false, "Wait for the full response instead of streaming it")
+	return cmd
+}
+
+// replSession abstracts over an interactive terminal and piped input.
+type replSession struct {
+	readLine  func(prompt string) (string, error)
+	out       io.Writer
+	close     func()
+	isTerm    bool
+	streaming bool
+}
+
+func runRepl(client *Client, streaming bool) error {
+	sess, err := newReplSession()
+	if err != nil {
+		return err
+	}
+	defer sess.close()
+	sess.streaming = streaming
+
+	if sess.isTerm {
+		fmt.Fprintf(sess.out, "Connected to %s. Type exit or Ctrl-D to quit.\n", client.BaseURL)
+	}
+	for {
+		query, err := readReplQuery(sess)
+		if errors.Is(err, io.EOF) {
+			if sess.isTerm {
+				fmt.Fprintln(sess.out)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch strings.TrimSpace(query) {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+		if err := sendReplQuery(client, sess, query); err != nil {
+			fmt.Fprintf(sess.out, "error: %v\n", err)
+		}
+	}
+}
+
+// readReplQuery reads one query, joining continuation lines.
+func readReplQuery(sess *replSession) (string, error) {
+	line, err := sess.readLine(replPrompt)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(line) == `"""` {
+		var lines []string
+		for {
+			next, err := sess.readLine(replContinuePrompt)
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(next) == `"""` {
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, next)
+		}
+	}
+
+	var lines []string
+	for strings.HasSuffix(line, `\`) {
+		lines = append(lines, strings.TrimSuffix(line, `\`))
+		if line, err = sess.readLine(replContinuePrompt); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(append(lines, line), "\n"), nil
+}
+
+type replResult struct {
+	BeadID       string `json:"bead_id"`
+	ProviderID   string `json:"provider_id"`
+	ProviderName string `json:"provider_name"`
+	Model        string `json:"model"`
+	Response     string `json:"response"`
+	TokensUsed   int    `json:"tokens_used"`
+	LatencyMs    int64  `json:"latency_ms"`
+}
+
+func sendReplQuery(client *Client, sess *replSession, query string) error {
+	body := map[string]interface{}{"message": query}
+
+	if !sess.streaming {
+		data, err := client.post("/api/v1/repl", body)
+		if err != nil {
+			return err
+		}
+		var res replResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		fmt.Fprintln(sess.out, res.Response)
+		printReplFooter(sess.out, &res)
+		return nil
+	}
+
+	body["stream"] = true
+	var res *replResult
+	var streamErr error
+	err := client.doSSE("POST", "/api/v1/repl", body, func(event, data string) error {
+		switch event {
+		case "delta":
+			var d struct {
+				Content string `json:"content"`
+			}
+			if json.Unmarshal([]byte(data), &d) == nil {
+				fmt.Fprint(sess.out, d.Content)
+			}
+		case "result":
+			res = &replResult{}
+			if err := json.Unmarshal([]byte(data), res); err != nil {
+				return fmt.Errorf("failed to parse result: %w", err)
+			}
+		case "error":
+			var e struct {
+				Error string `json:"error"`
+			}
+			_ = json.Unmarshal([]byte(data), &e)
+			streamErr = errors.New(e.Error)
+		}
+		return nil
+	})
+	fmt.Fprintln(sess.out)
+	if err != nil {
+		return err
+	}
+	if streamErr != nil {
+		return streamErr
+	}
+	if res == nil {
+		return fmt.Errorf("stream ended without a result")
+	}
+	printReplFooter(sess.out, res)
+	return nil
+}
+
+func printReplFooter(out io.Writer, res *replResult) {
+	provider := res.ProviderName
+	if provider == "" {
+		provider = res.ProviderID
+	}
+	bead := res.BeadID
+	if bead == "" {
+		bead = "(none)"
+	}
+	fmt.Fprintf(out, "[bead %s | %s %s | %d tokens | %dms]\n", bead, provider, res.Model, res.TokensUsed, res.LatencyMs)
+}
+
+// newReplSession sets up line editing and history on a terminal, or plain
+// line reading when input is piped.
+func newReplSession() (*replSession, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &replSession{
+			readLine: func(string) (string, error) {
+				if scanner.Scan() {
+					return scanner.Text(), nil
+				}
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			},
+			out:   os.Stdout,
+			close: func() {},
+		}, nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, replPrompt)
+	if w, h, err := term.GetSize(fd); err == nil {
+		_ = t.SetSize(w, h)
+	}
+	t.History = loadReplHistory()
+
+	return &replSession{
+		readLine: func(prompt string) (string, error) {
+			t.SetPrompt(prompt)
+			line, err := t.ReadLine()
+			if errors.Is(err, term.ErrPasteIndicator) {
+				err = nil
+			}
+			return line, err
+		},
+		out:    t,
+		close:  func() { _ = term.Restore(fd, state) },
+		isTerm: true,
+	}, nil
+}
+
+// replHistory is a bounded term.History persisted to ~/.loomctl_history.
+type replHistory struct {
+	path    string
+	entries []string // oldest first
+}
+
+func loadReplHistory() *replHistory {
+	h := &replHistory{}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(home, ".loomctl_history")
+	if data, err := os.ReadFile(h.path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+	}
+	if len(h.entries) > replHistoryLimit {
+		h.entries = h.entries[len(h.entries)-replHistoryLimit:]
+	}
+	return h
+}
+
+func (h *replHistory) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > replHistoryLimit {
+		h.entries = h.entries[len(h.entries)-replHistoryLimit:]
+	}
+	if h.path == "" {
+		return
+	}
+	if f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600); err == nil {
+		fmt.Fprintln(f, entry)
+		f.Close()
+	}
+}
+
+func (h *replHistory) Len() int { return len(h.entries) }
+
+func (h *replHistory) At(idx int) string { return h.entries[len(h.entries)-1-idx] }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate and read daily and weekly project reports",
+		Long: `Generate and read project reports: beads opened and closed, velocity
+against the period before, spend, the most frequent errors, and what each
+agent did. The server generates the periods listed under reports.periods in
+its config on its own, once a day ends at midnight UTC or a week on Monday
+midnight UTC, and sends them to the users subscribed to report.generated.`,
+	}
+	cmd.AddCommand(newReportGenerateCommand())
+	cmd.AddCommand(newReportListCommand())
+	cmd.AddCommand(newReportShowCommand())
+	return cmd
+}
+
+// validReportFormat checks a --format flag of the report commands.
+func validReportFormat(format string) error {
+	switch format {
+	case "json", "markdown", "html":
+		return nil
+	default:
+		return fmt.Errorf("--format must be json, markdown or html")
+	}
+}
+
+func newReportGenerateCommand() *cobra.Command {
+	var (
+		projectID string
+		period    string
+		format    string
+	)
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a project's report for the last complete day or week",
+		Long: `Generate and store a project's report for the last complete day or week.
+The report is not sent out; the scheduled reports are.`,
+		Example: `  loomctl report generate --project=loom-self --period=week
+  loomctl report generate --project=loom-self --period=day --format=markdown`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectID == "" {
+				return fmt.Errorf("--project is required")
+			}
+			if err := validReportFormat(format); err != nil {
+				return err
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/reports", map[string]interface{}{
+				"project_id": projectID,
+				"period":     period,
+			})
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				outputJSON(data)
+				return nil
+			}
+			var rep struct {
+				Markdown string `json:"markdown"`
+				HTML     string `json:"html"`
+			}
+			if err := json.Unmarshal(data, &rep); err != nil {
+				return fmt.Errorf("failed to parse report: %w", err)
+			}
+			if format == "html" {
+				fmt.Print(rep.HTML)
+			} else {
+				fmt.Print(rep.Markdown)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (required)")
+	cmd.Flags().StringVar(&period, "period", "week", "Period: day or week")
+	cmd.Flags().StringVar(&format, "format", "json", "Print the report as json, markdown or html")
+	return cmd
+}
+
+func newReportListCommand() *cobra.Command {
+	var (
+		projectID string
+		limit     int
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List stored reports, newest first",
+		Example: `  loomctl report list
+  loomctl report list --project=loom-self --limit=10 -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			if limit > 0 {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get("/api/v1/reports", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only list this project's reports")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of reports (server default 50)")
+	return cmd
+}
+
+func newReportShowCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "show <report-id>",
+		Short: "Show a stored report",
+		Args:  cobra.ExactArgs(1),
+		Example: `  loomctl report show report-1a2b3c4d
+  loomctl report show report-1a2b3c4d --format=html > report.html`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validReportFormat(format); err != nil {
+				return err
+			}
+			client := newClient()
+			params := url.Values{}
+			if format != "json" {
+				params.Set("format", format)
+			}
+			data, err := client.get("/api/v1/reports/"+url.PathEscape(args[0]), params)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				outputJSON(data)
+			} else {
+				fmt.Print(string(data))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "json", "Print the report as json, markdown or html")
+	return cmd
+}
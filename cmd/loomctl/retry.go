@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	defaultRetries = 3
+
+	// retryBaseDelay doubles with each attempt, up to retryMaxDelay.
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+
+	// maxRetryAfter caps how long a server's Retry-After can hold a retry.
+	maxRetryAfter = 60 * time.Second
+
+	// timeoutAnnotation lets a command default to a longer timeout than
+	// defaultTimeout, as a Go duration. --timeout and LOOM_TIMEOUT win.
+	timeoutAnnotation = "loomctl/timeout"
+)
+
+var (
+	requestTimeout time.Duration
+	maxRetries     int
+)
+
+// sharedTransport is reused by every client, so bulk commands keep their
+// connections alive instead of opening one per request.
+var sharedTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 32
+	return t
+}()
+
+func getDefaultTimeout() time.Duration {
+	if v := os.Getenv("LOOM_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultTimeout
+}
+
+func getDefaultRetries() int {
+	if v := os.Getenv("LOOM_RETRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return defaultRetries
+}
+
+// applyCommandTimeout switches to the running command's own default
+// timeout unless one was given with --timeout or LOOM_TIMEOUT.
+func applyCommandTimeout(cmd *cobra.Command) error {
+	if requestTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if maxRetries < 0 {
+		return fmt.Errorf("--retries must not be negative")
+	}
+	if cmd.Flags().Changed("timeout") || os.Getenv("LOOM_TIMEOUT") != "" {
+		return nil
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if v, ok := c.Annotations[timeoutAnnotation]; ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s annotation on %s: %w", timeoutAnnotation, c.CommandPath(), err)
+			}
+			requestTimeout = d
+			return nil
+		}
+	}
+	return nil
+}
+
+// isIdempotent reports whether a request can be sent again without
+// changing its effect.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus reports whether a status is worth retrying: rate limits
+// and the transient failures of proxies and overloaded servers.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before retry number attempt (from
+// 1): the server's Retry-After if it sent one, else exponential backoff
+// with jitter.
+func retryDelay(attempt int, resp *http.Response, now time.Time) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			return min(d, maxRetryAfter)
+		}
+	}
+	d := retryBaseDelay << (attempt - 1)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	// Up to 25% jitter keeps parallel clients from retrying in lockstep.
+	return d - time.Duration(rand.Int63n(int64(d)/4+1))
+}
+
+// parseRetryAfter accepts a Retry-After of delay seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// doWithRetry sends req, retrying idempotent requests that fail with a
+// network error or a retryable status. The caller owns the returned
+// response body.
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+	retries := c.Retries
+	if !isIdempotent(req.Method) || (req.Body != nil && req.GetBody == nil) {
+		retries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := c.HTTP.Do(req)
+		if attempt >= retries {
+			return resp, err
+		}
+		if err == nil {
+			if !retryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			resp.Body.Close()
+		}
+		time.Sleep(retryDelay(attempt+1, resp, time.Now()))
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newBeadScheduleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage recurring (cron) bead definitions",
+		Long: `Manage recurring beads. Each schedule creates a bead whenever its cron
+expression fires. A firing is skipped if the bead from the previous firing is
+still open.
+
+Cron expressions use five fields (minute hour day-of-month month day-of-week)
+or one of @hourly, @daily, @weekly, @monthly, @yearly. Times are UTC.`,
+	}
+	cmd.AddCommand(newBeadScheduleCreateCommand())
+	cmd.AddCommand(newBeadScheduleListCommand())
+	cmd.AddCommand(newBeadScheduleDeleteCommand())
+	return cmd
+}
+
+func newBeadScheduleCreateCommand() *cobra.Command {
+	var (
+		name        string
+		cronExpr    string
+		title       string
+		description string
+		priority    int
+		projectID   string
+		beadType    string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Define a recurring bead",
+		Example: `  loomctl bead schedule create --cron="0 2 * * *" --project=loom \
+    --title="Run nightly dependency audit"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/beads/schedules", map[string]interface{}{
+				"name":        name,
+				"cron":        cronExpr,
+				"title":       title,
+				"description": description,
+				"priority":    priority,
+				"project_id":  projectID,
+				"type":        beadType,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Schedule name (defaults to the title)")
+	cmd.Flags().StringVar(&cronExpr, "cron", "", "Cron expression, UTC (required)")
+	cmd.Flags().StringVarP(&title, "title", "t", "", "Title of each created bead (required)")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "Description of each created bead")
+	cmd.Flags().IntVar(&priority, "priority", 2, "Priority (0=highest, 3=lowest)")
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (required)")
+	cmd.Flags().StringVar(&beadType, "type", "task", "Bead type")
+	cmd.MarkFlagRequired("cron")
+	cmd.MarkFlagRequired("title")
+	cmd.MarkFlagRequired("project")
+	return cmd
+}
+
+func newBeadScheduleListCommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recurring bead definitions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			data, err := client.get("/api/v1/beads/schedules", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Filter by project ID")
+	return cmd
+}
+
+func newBeadScheduleDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <schedule-id>",
+		Short: "Delete a recurring bead definition (created beads are kept)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/beads/schedules/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted schedule %s\n", args[0])
+			return nil
+		},
+	}
+}
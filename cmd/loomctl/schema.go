@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// commandSchema describes one command for tooling.
+type commandSchema struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Use      string          `json:"use"`
+	Short    string          `json:"short,omitempty"`
+	Long     string          `json:"long,omitempty"`
+	Example  string          `json:"example,omitempty"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Runnable bool            `json:"runnable"`
+	Args     []argSchema     `json:"args,omitempty"`
+	Flags    []flagSchema    `json:"flags,omitempty"`
+	Commands []commandSchema `json:"commands,omitempty"`
+}
+
+type argSchema struct {
+	Name      string `json:"name"`
+	Required  bool   `json:"required"`
+	Variadic  bool   `json:"variadic,omitempty"`
+	Completes string `json:"completes,omitempty"` // beads, projects, agents, or providers
+}
+
+type flagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty"`
+	Completes  string `json:"completes,omitempty"`
+}
+
+func newSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the full command tree as JSON",
+		Long: `Print every command with its arguments and flags as JSON, for tools that
+drive loomctl or generate wrappers for it. Arguments and flags that take
+bead, project, agent, or provider IDs say so in "completes".`,
+		Example: `  loomctl schema | jq '.commands[] | .name'`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(describeCommand(cmd.Root()))
+		},
+	}
+}
+
+func describeCommand(cmd *cobra.Command) commandSchema {
+	s := commandSchema{
+		Name:     cmd.Name(),
+		Path:     cmd.CommandPath(),
+		Use:      cmd.Use,
+		Short:    cmd.Short,
+		Long:     cmd.Long,
+		Example:  cmd.Example,
+		Aliases:  cmd.Aliases,
+		Runnable: cmd.Runnable(),
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(cmd.Use, -1) {
+		s.Args = append(s.Args, argSchema{
+			Name:      m[1],
+			Required:  strings.HasPrefix(m[0], "<"),
+			Variadic:  strings.HasSuffix(m[1], "-ids"),
+			Completes: argCompletions[m[1]],
+		})
+	}
+
+	describe := func(f *pflag.Flag, persistent bool) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		fs := flagSchema{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Persistent: persistent,
+		}
+		if _, ok := cmd.GetFlagCompletionFunc(f.Name); ok {
+			fs.Completes = flagCompletions[f.Name]
+		}
+		s.Flags = append(s.Flags, fs)
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) { describe(f, false) })
+	cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) { describe(f, true) })
+
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			s.Commands = append(s.Commands, describeCommand(sub))
+		}
+	}
+	return s
+}
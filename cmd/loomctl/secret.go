@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage secrets injected into agent commands",
+		Long: `Manage named secrets kept encrypted in the key store. A secret granted to a
+project is set as an environment variable, under its name, for every
+command agents run for that project, locally or in the project container.
+Its value is masked in command output and logs.
+
+Secret values are never shown after they are set.`,
+	}
+	cmd.AddCommand(newSecretListCommand())
+	cmd.AddCommand(newSecretSetCommand())
+	cmd.AddCommand(newSecretDeleteCommand())
+	cmd.AddCommand(newSecretGrantCommand())
+	cmd.AddCommand(newSecretRevokeCommand())
+	return cmd
+}
+
+func newSecretListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List secrets and the projects they are granted to",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/secrets", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newSecretSetCommand() *cobra.Command {
+	var value, description string
+	cmd := &cobra.Command{
+		Use:   "set <name>",
+		Short: "Create a secret or replace its value",
+		Long: `Create a secret or replace its value; grants are kept. The name must be a
+valid environment variable name. Without --value, the value is read from
+stdin so that it stays out of shell history; a trailing newline is dropped.`,
+		Example: `  loomctl secret set GITHUB_TOKEN --description="CI token" < token.txt
+  echo -n "$NPM_TOKEN" | loomctl secret set NPM_TOKEN`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("value") {
+				content, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read secret value: %w", err)
+				}
+				value = strings.TrimRight(string(content), "\r\n")
+			}
+			if value == "" {
+				return fmt.Errorf("secret value is empty")
+			}
+			client := newClient()
+			data, err := client.put("/api/v1/secrets/"+url.PathEscape(args[0]), map[string]interface{}{
+				"value":       value,
+				"description": description,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&value, "value", "", "Secret value (read from stdin if omitted)")
+	cmd.Flags().StringVar(&description, "description", "", "What the secret is for")
+	return cmd
+}
+
+func newSecretDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a secret and its grants",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/secrets/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted secret %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newSecretGrantCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "grant <name> <project-id>",
+		Short: "Make a secret available to a project's commands",
+		Long:  `Make a secret available to a project's commands. Granting it to "*" makes it available to every project.`,
+		Example: `  loomctl secret grant GITHUB_TOKEN loom-self
+  loomctl secret grant NPM_TOKEN '*'`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/secrets/"+url.PathEscape(args[0])+"/grants", map[string]interface{}{
+				"project_id": args[1],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newSecretRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <name> <project-id>",
+		Short: "Stop injecting a secret into a project's commands",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.delete("/api/v1/secrets/" + url.PathEscape(args[0]) + "/grants/" + url.PathEscape(args[1]))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newBeadSLACommand() *cobra.Command {
+	var projectID string
+	cmd := &cobra.Command{
+		Use:   "sla",
+		Short: "Report open beads against their SLA deadlines",
+		Long: `Report open beads covered by an SLA policy, most urgent first: breached,
+then at risk (less than 20% of the window left), then on track. Remaining
+times are in seconds and negative once a deadline has passed. Breaches
+recorded in the past week are listed at the end.
+
+Policies set how soon beads of a priority must be started (respond) and
+closed (resolve), measured from creation. See "loomctl bead sla policy".`,
+		Example: `  loomctl bead sla
+  loomctl bead sla --project=loom-self -o table`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if projectID != "" {
+				params.Set("project_id", projectID)
+			}
+			data, err := client.get("/api/v1/sla/report", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Only report beads in this project")
+	cmd.AddCommand(newBeadSLAPolicyCommand())
+	return cmd
+}
+
+func newBeadSLAPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage SLA policies",
+		Long: `Manage SLA policies. A policy without --project applies to every project
+that has no policy of its own for that priority. Without a subcommand,
+lists every policy.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/sla/policies", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.AddCommand(newBeadSLAPolicySetCommand())
+	cmd.AddCommand(newBeadSLAPolicyDeleteCommand())
+	return cmd
+}
+
+func newBeadSLAPolicySetCommand() *cobra.Command {
+	var (
+		projectID     string
+		priority      int
+		respondWithin string
+		resolveWithin string
+		autoEscalate  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the SLA for a priority, globally or for one project",
+		Example: `  loomctl bead sla policy set --priority=0 --respond-within=30m --resolve-within=24h
+  loomctl bead sla policy set --project=loom-self --priority=1 --resolve-within=72h --auto-escalate`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("priority") {
+				return fmt.Errorf("--priority is required")
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/sla/policies", map[string]interface{}{
+				"project_id":     projectID,
+				"priority":       priority,
+				"respond_within": respondWithin,
+				"resolve_within": resolveWithin,
+				"auto_escalate":  autoEscalate,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&projectID, "project", "p", "", "Project ID (omit for all projects)")
+	cmd.Flags().IntVar(&priority, "priority", 0, "Bead priority (0-3)")
+	cmd.Flags().StringVar(&respondWithin, "respond-within", "", "Time allowed before the bead is started, e.g. 30m")
+	cmd.Flags().StringVar(&resolveWithin, "resolve-within", "", "Time allowed before the bead is closed, e.g. 24h")
+	cmd.Flags().BoolVar(&autoEscalate, "auto-escalate", false, "Escalate breached beads to the CEO")
+	return cmd
+}
+
+func newBeadSLAPolicyDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <policy-id>",
+		Short: "Delete an SLA policy",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/sla/policies/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted SLA policy %s\n", args[0])
+			return nil
+		},
+	}
+}
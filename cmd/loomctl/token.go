@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens",
+		Long: `Manage named API tokens for scripts and CI. A token has a scope (read,
+write, or admin), optionally a list of projects it is limited to, and
+optionally an expiry. Pass it as LOOM_TOKEN or LOOM_API_KEY.
+
+A token never grants more than its owner's role. Tokens can only be
+created with a password login or an admin-scoped token.`,
+	}
+	cmd.AddCommand(newTokenCreateCommand())
+	cmd.AddCommand(newTokenListCommand())
+	cmd.AddCommand(newTokenRevokeCommand())
+	cmd.AddCommand(newTokenRevokedCommand())
+	return cmd
+}
+
+func newTokenCreateCommand() *cobra.Command {
+	var (
+		name     string
+		scope    string
+		projects []string
+		ttl      string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an API token",
+		Long: `Create an API token. Its value is shown once; store it right away.
+Without --ttl the token does not expire.`,
+		Example: `  loomctl token create --name ci --scope read --project loom --ttl 30d
+  loomctl token create --name deploy --scope write --ttl 720h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name == "" {
+				name = scope + " token"
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/tokens", map[string]interface{}{
+				"name":        name,
+				"scope":       scope,
+				"project_ids": projects,
+				"ttl":         ttl,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "What the token is for")
+	cmd.Flags().StringVar(&scope, "scope", "read", "Scope: read, write, or admin")
+	cmd.Flags().StringSliceVar(&projects, "project", nil, "Project the token is limited to (repeatable; default all)")
+	cmd.Flags().StringVar(&ttl, "ttl", "", "How long the token lives, e.g. 30d or 12h (default: no expiry)")
+	return cmd
+}
+
+func newTokenListCommand() *cobra.Command {
+	var all, inactive bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List your API tokens with when they were last used",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := url.Values{}
+			if all {
+				params.Set("all", "true")
+			}
+			if inactive {
+				params.Set("inactive", "true")
+			}
+			client := newClient()
+			data, err := client.get("/api/v1/tokens", params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "List every user's tokens (admin only)")
+	cmd.Flags().BoolVar(&inactive, "inactive", false, "Include revoked and expired tokens")
+	return cmd
+}
+
+func newTokenRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <token-id>",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.delete("/api/v1/tokens/" + url.PathEscape(args[0]))
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newTokenRevokedCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoked",
+		Short: "Show the revocation list (admin only)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/tokens/revoked", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users, roles, and sessions (admin only)",
+		Long: `Manage users and their roles. Roles control what each user may do:
+admin (everything), operator (day-to-day work, but no deleting providers or
+projects and no user management), and viewer (read-only).
+
+Users can be disabled, have their password reset, and have their login
+sessions listed and revoked. Commands that take a user accept a username
+or a user ID.
+
+Requests are authenticated with LOOM_TOKEN or LOOM_API_KEY.`,
+	}
+	cmd.AddCommand(newUserListCommand())
+	cmd.AddCommand(newUserCreateCommand())
+	cmd.AddCommand(newUserSetRoleCommand())
+	cmd.AddCommand(newUserRolesCommand())
+	cmd.AddCommand(newUserSetActiveCommand("disable", false))
+	cmd.AddCommand(newUserSetActiveCommand("enable", true))
+	cmd.AddCommand(newUserResetPasswordCommand())
+	cmd.AddCommand(newUserSessionsCommand())
+	cmd.AddCommand(newUserRevokeSessionsCommand())
+	cmd.AddCommand(newUserActivityCommand())
+	return cmd
+}
+
+func newUserListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/users", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newUserCreateCommand() *cobra.Command {
+	var (
+		email    string
+		role     string
+		password string
+	)
+	cmd := &cobra.Command{
+		Use:   "create <username>",
+		Short: "Create a user",
+		Long: `Create a user. Without --password a temporary password is generated
+and shown once; the user must change it after signing in.`,
+		Example: `  loomctl user create alice --role=operator --password=changeme
+  loomctl user create bob --role=viewer`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post("/api/v1/users", map[string]string{
+				"username": args[0],
+				"email":    email,
+				"role":     role,
+				"password": password,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&email, "email", "", "Email address")
+	cmd.Flags().StringVar(&role, "role", "viewer", "Role (admin, operator, viewer)")
+	cmd.Flags().StringVar(&password, "password", "", "Initial password (default: generated)")
+	return cmd
+}
+
+func newUserSetRoleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role <username|user-id> <role>",
+		Short: "Assign a role to a user",
+		Example: `  loomctl user set-role alice viewer
+  loomctl user set-role id-3f2a9c operator`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.patch(fmt.Sprintf("/api/v1/users/%s", url.PathEscape(userID)), map[string]string{
+				"role": args[1],
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newUserRolesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "roles",
+		Short: "List roles and their permissions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/auth/roles", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newUserSetActiveCommand(use string, active bool) *cobra.Command {
+	short := "Disable a user, revoking their sessions and API keys"
+	if active {
+		short = "Re-enable a disabled user"
+	}
+	return &cobra.Command{
+		Use:     use + " <username|user-id>",
+		Short:   short,
+		Example: fmt.Sprintf("  loomctl user %s alice", use),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.post(fmt.Sprintf("/api/v1/users/%s/%s", url.PathEscape(userID), use), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newUserResetPasswordCommand() *cobra.Command {
+	var password string
+	cmd := &cobra.Command{
+		Use:   "reset-password <username|user-id>",
+		Short: "Reset a user's password",
+		Long: `Set a temporary password for a user and revoke their sessions. The
+user must change the password after signing in with it. Without
+--password one is generated and shown once.`,
+		Example: `  loomctl user reset-password alice`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.post(fmt.Sprintf("/api/v1/users/%s/reset-password", url.PathEscape(userID)), map[string]string{
+				"password": password,
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&password, "password", "", "Temporary password (default: generated)")
+	return cmd
+}
+
+func newUserSessionsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "sessions <username|user-id>",
+		Short:   "List a user's active login sessions",
+		Example: `  loomctl user sessions alice`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := client.get(fmt.Sprintf("/api/v1/users/%s/sessions", url.PathEscape(userID)), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newUserRevokeSessionsCommand() *cobra.Command {
+	var session string
+	cmd := &cobra.Command{
+		Use:   "revoke-sessions <username|user-id>",
+		Short: "Revoke a user's login sessions",
+		Long:  `Revoke all of a user's login sessions, or only the one named by --session.`,
+		Example: `  loomctl user revoke-sessions alice
+  loomctl user revoke-sessions alice --session id-3f2a9c`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/api/v1/users/%s/sessions", url.PathEscape(userID))
+			if session != "" {
+				path += "/" + url.PathEscape(session)
+			}
+			data, err := client.delete(path)
+			if err != nil {
+				return err
+			}
+			if session != "" {
+				fmt.Printf("Revoked session %s\n", session)
+				return nil
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&session, "session", "", "Revoke only this session")
+	return cmd
+}
+
+func newUserActivityCommand() *cobra.Command {
+	var (
+		since string
+		limit int
+	)
+	cmd := &cobra.Command{
+		Use:     "activity <username|user-id>",
+		Short:   "Show what a user has changed",
+		Long:    `Show a user's entries in the audit log, newest first.`,
+		Example: `  loomctl user activity alice --since 7d`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			userID, err := resolveUserID(client, args[0])
+			if err != nil {
+				return err
+			}
+			params := url.Values{}
+			if since != "" {
+				params.Set("since", since)
+			}
+			if limit > 0 {
+				params.Set("limit", fmt.Sprint(limit))
+			}
+			data, err := client.get(fmt.Sprintf("/api/v1/users/%s/activity", url.PathEscape(userID)), params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only entries after this time (RFC3339) or duration ago (24h, 7d)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum entries to show")
+	return cmd
+}
+
+// resolveUserID accepts either a user ID or a username.
+func resolveUserID(client *Client, ref string) (string, error) {
+	data, err := client.get("/api/v1/users", nil)
+	if err != nil {
+		return "", err
+	}
+	var users []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(data, &users); err != nil {
+		return "", fmt.Errorf("failed to parse user list: %w", err)
+	}
+	for _, u := range users {
+		if u.ID == ref || u.Username == ref {
+			return u.ID, nil
+		}
+	}
+	return "", fmt.Errorf("user not found: %s", ref)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// watchBeads renders the initial bead list, then follows the event socket and
+// re-renders whenever a bead in (or entering) the filtered set changes.
+// Each bead event triggers a fetch of that bead so the merged view always
+// reflects server state rather than a partial event payload. Dropped
+// connections resume where they left off; if the server could not replay
+// everything that was missed, the whole list is fetched again.
+func watchBeads(client *Client, initial []byte, params url.Values) error {
+	w := &beadWatcher{client: client, params: params}
+	if err := w.load(initial); err != nil {
+		return err
+	}
+	w.render("")
+
+	streamParams := url.Values{}
+	if projectID := params.Get("project_id"); projectID != "" {
+		streamParams.Set("project_id", projectID)
+	}
+
+	subscribed := false
+	return client.followEvents(streamParams, func(msg eventSocketMessage) error {
+		switch msg.Kind {
+		case "ready":
+			// Catch beads that changed between listing and subscribing.
+			if !subscribed {
+				subscribed = true
+				return w.reload("changed while subscribing")
+			}
+		case "gap":
+			return w.reload("missed events; reloaded")
+		case "event":
+			if msg.Event == nil || !strings.HasPrefix(msg.Event.Type, "bead.") {
+				return nil
+			}
+			beadID, _ := msg.Event.Data["bead_id"].(string)
+			if beadID == "" {
+				return nil
+			}
+			if w.apply(beadID) {
+				w.render(fmt.Sprintf("%s %s", msg.Event.Type, beadID))
+			}
+		}
+		return nil
+	})
+}
+
+type beadWatcher struct {
+	client *Client
+	params url.Values
+	index  map[string]int
+	beads  []map[string]interface{}
+}
+
+// load replaces the watched list with a bead list from the server.
+func (w *beadWatcher) load(data []byte) error {
+	var list []map[string]interface{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse bead list: %w", err)
+	}
+	w.beads = list
+	w.index = make(map[string]int, len(list))
+	for i, b := range list {
+		if id, _ := b["id"].(string); id != "" {
+			w.index[id] = i
+		}
+	}
+	return nil
+}
+
+// reload fetches the whole list again and re-renders it if it changed.
+func (w *beadWatcher) reload(reason string) error {
+	data, err := w.client.get("/api/v1/beads", w.params)
+	if err != nil {
+		return err
+	}
+	before := w.snapshot()
+	if err := w.load(data); err != nil {
+		return err
+	}
+	if w.snapshot() != before {
+		w.render(reason)
+	}
+	return nil
+}
+
+// snapshot returns the watched beads in a form that does not depend on the
+// order the server listed them in.
+func (w *beadWatcher) snapshot() string {
+	byID := make(map[string]map[string]interface{}, len(w.beads))
+	for id, i := range w.index {
+		byID[id] = w.beads[i]
+	}
+	data, _ := json.Marshal(byID)
+	return string(data)
+}
+
+// apply refreshes a single bead from the server and merges it into the
+// watched list. It reports whether the rendered set changed.
+func (w *beadWatcher) apply(beadID string) bool {
+	data, err := w.client.get(fmt.Sprintf("/api/v1/beads/%s", url.PathEscape(beadID)), nil)
+	if err != nil {
+		// Bead vanished (or the server hiccupped): drop it if we had it.
+		return w.remove(beadID)
+	}
+	var bead map[string]interface{}
+	if err := json.Unmarshal(data, &bead); err != nil {
+		return false
+	}
+
+	if !beadMatchesParams(bead, w.params) {
+		return w.remove(beadID)
+	}
+	if i, ok := w.index[beadID]; ok {
+		w.beads[i] = bead
+	} else {
+		w.index[beadID] = len(w.beads)
+		w.beads = append(w.beads, bead)
+	}
+	return true
+}
+
+func (w *beadWatcher) remove(beadID string) bool {
+	i, ok := w.index[beadID]
+	if !ok {
+		return false
+	}
+	w.beads = append(w.beads[:i], w.beads[i+1:]...)
+	delete(w.index, beadID)
+	for j := i; j < len(w.beads); j++ {
+		if id, _ := w.beads[j]["id"].(string); id != "" {
+			w.index[id] = j
+		}
+	}
+	return true
+}
+
+func (w *beadWatcher) render(reason string) {
+	if reason != "" {
+		fmt.Fprintf(os.Stderr, "--- %s  %s\n", time.Now().Format("15:04:05"), reason)
+	}
+	data, err := json.Marshal(w.beads)
+	if err != nil {
+		return
+	}
+	outputJSON(data)
+}
+
+// beadMatchesParams applies the same filters the server applies to
+// GET /api/v1/beads so that beads entering or leaving the filtered set are
+// handled correctly while watching.
+func beadMatchesParams(bead map[string]interface{}, params url.Values) bool {
+	if v := params.Get("project_id"); v != "" && bead["project_id"] != v {
+		return false
+	}
+	if v := params.Get("status"); v != "" && bead["status"] != v {
+		return false
+	}
+	if v := params.Get("type"); v != "" && bead["type"] != v {
+		return false
+	}
+	if v := params.Get("assigned_to"); v != "" {
+		assigned, _ := bead["assigned_to"].(string)
+		match := false
+		for _, candidate := range strings.Split(v, ",") {
+			if strings.TrimSpace(candidate) == assigned {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newWebhookCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manage outbound event webhooks",
+		Long: `Manage outbound webhooks. Loom POSTs matching events as JSON to each
+active webhook, signed with X-Loom-Signature when a secret is set.
+
+Event types: bead.created, bead.closed, agent.stuck, provider.unhealthy,
+any other event bus type, or "*" for everything.`,
+	}
+	cmd.AddCommand(newWebhookListCommand())
+	cmd.AddCommand(newWebhookCreateCommand())
+	cmd.AddCommand(newWebhookShowCommand())
+	cmd.AddCommand(newWebhookUpdateCommand())
+	cmd.AddCommand(newWebhookDeleteCommand())
+	cmd.AddCommand(newWebhookDeliveriesCommand())
+	cmd.AddCommand(newWebhookTestCommand())
+	return cmd
+}
+
+func webhookPath(id string, rest ...string) string {
+	return "/api/v1/webhooks/" + strings.Join(append([]string{url.PathEscape(id)}, rest...), "/")
+}
+
+func splitEvents(s string) []string {
+	var events []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func newWebhookListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List webhooks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/webhooks", nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newWebhookCreateCommand() *cobra.Command {
+	var (
+		name   string
+		target string
+		events string
+		secret string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Register a webhook",
+		Example: `  loomctl webhook create --name=ops --url=https://hooks.example.com/loom \
+    --events=bead.closed,agent.stuck,provider.unhealthy --secret=s3cret`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" || events == "" {
+				return fmt.Errorf("--url and --events are required")
+			}
+			if name == "" {
+				name = target
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/webhooks", map[string]interface{}{
+				"name":        name,
+				"url":         target,
+				"secret":      secret,
+				"event_types": splitEvents(events),
+			})
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Display name (defaults to the URL)")
+	cmd.Flags().StringVar(&target, "url", "", "Endpoint URL (required)")
+	cmd.Flags().StringVar(&events, "events", "", "Comma-separated event types, or * (required)")
+	cmd.Flags().StringVar(&secret, "secret", "", "Shared secret for HMAC-SHA256 signatures")
+	return cmd
+}
+
+func newWebhookShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <webhook-id>",
+		Short: "Show a webhook",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get(webhookPath(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newWebhookUpdateCommand() *cobra.Command {
+	var (
+		name   string
+		target string
+		events string
+		secret string
+		active string
+	)
+	cmd := &cobra.Command{
+		Use:   "update <webhook-id>",
+		Short: "Update a webhook",
+		Example: `  loomctl webhook update wh-1a2b3c4d --events='*'
+  loomctl webhook update wh-1a2b3c4d --active=false`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			updates := map[string]interface{}{}
+			if cmd.Flags().Changed("name") {
+				updates["name"] = name
+			}
+			if cmd.Flags().Changed("url") {
+				updates["url"] = target
+			}
+			if cmd.Flags().Changed("events") {
+				updates["event_types"] = splitEvents(events)
+			}
+			if cmd.Flags().Changed("secret") {
+				updates["secret"] = secret
+			}
+			if cmd.Flags().Changed("active") {
+				v, err := strconv.ParseBool(active)
+				if err != nil {
+					return fmt.Errorf("invalid --active value: %s", active)
+				}
+				updates["active"] = v
+			}
+			if len(updates) == 0 {
+				return fmt.Errorf("nothing to update")
+			}
+			client := newClient()
+			data, err := client.put(webhookPath(args[0]), updates)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Display name")
+	cmd.Flags().StringVar(&target, "url", "", "Endpoint URL")
+	cmd.Flags().StringVar(&events, "events", "", "Comma-separated event types, or *")
+	cmd.Flags().StringVar(&secret, "secret", "", "Shared secret (empty disables signing)")
+	cmd.Flags().StringVar(&active, "active", "", "Enable or disable delivery (true/false)")
+	return cmd
+}
+
+func newWebhookDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <webhook-id>",
+		Short: "Delete a webhook and its delivery history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete(webhookPath(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted webhook %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newWebhookDeliveriesCommand() *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "deliveries <webhook-id>",
+		Short: "Show recent delivery attempts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			params := url.Values{}
+			if limit > 0 {
+				params.Set("limit", strconv.Itoa(limit))
+			}
+			data, err := client.get(webhookPath(args[0], "deliveries"), params)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum attempts to show")
+	return cmd
+}
+
+func newWebhookTestCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "test <webhook-id>",
+		Short: "Send a webhook.test event and show the result",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.post(webhookPath(args[0], "test"), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newWorkflowPushCommand() *cobra.Command {
+	var filename string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Create or replace a workflow from a YAML or JSON definition",
+		Long: `Create or replace a workflow from a definition in the same shape as the
+files under workflows/defaults. The server checks the graph (an entry edge,
+no dead ends, no orphaned edges) and saves each push as a new version.`,
+		Example: `  loomctl workflow push -f wf.yaml
+  loomctl workflow push -f wf.yaml --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return fmt.Errorf("--filename is required")
+			}
+			var data []byte
+			var err error
+			if filename == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(filename)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read workflow: %w", err)
+			}
+			// YAML is a superset of JSON, so either parses here.
+			var def map[string]interface{}
+			if err := yaml.Unmarshal(data, &def); err != nil {
+				return fmt.Errorf("failed to parse workflow: %w", err)
+			}
+			id, _ := def["id"].(string)
+			if id == "" {
+				return fmt.Errorf("workflow definition has no id")
+			}
+
+			var params url.Values
+			if dryRun {
+				params = url.Values{"dry_run": {"true"}}
+			}
+			client := newClient()
+			out, err := client.do("PUT", "/api/v1/workflows/"+url.PathEscape(id), params, def)
+			if err != nil {
+				return err
+			}
+			outputJSON(out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "Workflow definition file (- for stdin)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the definition without saving it")
+	return cmd
+}
+
+func newWorkflowDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <workflow-id>",
+		Short: "Delete a workflow and its executions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			if _, err := client.delete("/api/v1/workflows/" + url.PathEscape(args[0])); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted workflow %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newWorkflowVersionsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "versions <workflow-id> [version]",
+		Short: "List a workflow's saved versions, or show one",
+		Args:  cobra.RangeArgs(1, 2),
+		Example: `  loomctl workflow versions wf-bug-default
+  loomctl workflow versions wf-bug-default 2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			path := "/api/v1/workflows/" + url.PathEscape(args[0]) + "/versions"
+			if len(args) == 2 {
+				path += "/" + url.PathEscape(args[1])
+			}
+			data, err := client.get(path, nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+func newWorkflowGraphCommand() *cobra.Command {
+	var format, bead string
+	cmd := &cobra.Command{
+		Use:   "graph <workflow-id>",
+		Short: "Render a workflow as a Mermaid flowchart or Graphviz DOT",
+		Args:  cobra.ExactArgs(1),
+		Example: `  loomctl workflow graph wf-bug-default
+  loomctl workflow graph wf-bug-default --format=dot | dot -Tsvg > bug.svg
+  loomctl workflow graph wf-bug-default --bead=loom-001`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := url.Values{"format": {format}}
+			if bead != "" {
+				params.Set("bead_id", bead)
+			}
+			client := newClient()
+			data, err := client.get("/api/v1/workflows/"+url.PathEscape(args[0])+"/graph", params)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "mermaid", "Graph format: mermaid or dot")
+	cmd.Flags().StringVar(&bead, "bead", "", "Highlight where this bead's execution stands")
+	return cmd
+}
+
+func newWorkflowExecutionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "execution <execution-id>",
+		Short: "Show a workflow execution with its history and parallel branches",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+			data, err := client.get("/api/v1/workflows/executions/"+url.PathEscape(args[0]), nil)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+}
+
+// newWorkflowControlCommand builds one of the execution controls, which all
+// POST to /api/v1/workflows/executions/{id}/{action}.
+func newWorkflowControlCommand(action, short, example string, withReason, withCondition, withNode bool) *cobra.Command {
+	var reason, condition, node string
+	cmd := &cobra.Command{
+		Use:     action + " <execution-id>",
+		Short:   short,
+		Example: example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if withNode && node == "" {
+				return fmt.Errorf("--to is required")
+			}
+			body := map[string]string{}
+			if reason != "" {
+				body["reason"] = reason
+			}
+			if condition != "" {
+				body["condition"] = condition
+			}
+			if node != "" {
+				body["node_key"] = node
+			}
+			client := newClient()
+			data, err := client.post("/api/v1/workflows/executions/"+url.PathEscape(args[0])+"/"+action, body)
+			if err != nil {
+				return err
+			}
+			outputJSON(data)
+			return nil
+		},
+	}
+	if withReason {
+		cmd.Flags().StringVar(&reason, "reason", "", "Why, recorded in the execution's history")
+	}
+	if withCondition {
+		cmd.Flags().StringVar(&condition, "condition", "", "Edge to follow (default approved on approval nodes, success elsewhere)")
+	}
+	if withNode {
+		cmd.Flags().StringVar(&node, "to", "", "Node to roll back to (required)")
+	}
+	return cmd
+}
+
+func newWorkflowPauseCommand() *cobra.Command {
+	return newWorkflowControlCommand("pause", "Pause a workflow execution so it is neither dispatched nor advanced",
+		`  loomctl workflow pause wfex-1a2b3c4d --reason="waiting on legal"`, true, false, false)
+}
+
+func newWorkflowResumeCommand() *cobra.Command {
+	return newWorkflowControlCommand("resume", "Resume a paused workflow execution",
+		`  loomctl workflow resume wfex-1a2b3c4d`, false, false, false)
+}
+
+func newWorkflowSkipCommand() *cobra.Command {
+	return newWorkflowControlCommand("skip", "Force a workflow execution past its current node",
+		`  loomctl workflow skip wfex-1a2b3c4d --reason="reviewed offline"
+  loomctl workflow skip wfex-1a2b3c4d --condition=failure`, true, true, false)
+}
+
+func newWorkflowRollbackCommand() *cobra.Command {
+	return newWorkflowControlCommand("rollback", "Roll a workflow execution back to a node it has been through",
+		`  loomctl workflow rollback wfex-1a2b3c4d --to=investigate --reason="fix was wrong"`, true, false, true)
+}
@@ -258,7 +258,9 @@ Rules:
 			continue
 		}
 
-		resp, err := m.registry.SendChatCompletion(ctx, p.Config.ID, &provider.ChatCompletionRequest{
+		// The same project files always need the same setup, so the answer
+		// can come from the prompt cache.
+		resp, err := m.registry.SendChatCompletion(provider.WithPromptCache(ctx), p.Config.ID, &provider.ChatCompletionRequest{
 			Model: p.Config.Model,
 			Messages: []provider.ChatMessage{
 				{Role: "user", Content: prompt},
@@ -0,0 +1,399 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Effect classifies what an action would change.
+const (
+	EffectRead     = "read"     // reads files, git state, or beads
+	EffectWrite    = "write"    // creates or modifies files
+	EffectDelete   = "delete"   // removes or moves files
+	EffectCommand  = "command"  // runs a command, build, test, or linter
+	EffectGit      = "git"      // changes git state (commit, push, merge, ...)
+	EffectBead     = "bead"     // creates or changes beads or decisions
+	EffectMessage  = "message"  // messages another agent or comments on a PR
+	EffectWorkflow = "workflow" // hands off to a workflow tool
+	EffectNone     = "none"     // signals only
+)
+
+// PlanStep describes what one action in an envelope would do.
+type PlanStep struct {
+	Index       int          `json:"index"`
+	ActionType  string       `json:"action_type"`
+	Effect      string       `json:"effect"`
+	Description string       `json:"description"`
+	Files       []string     `json:"files,omitempty"`
+	Command     string       `json:"command,omitempty"`
+	WorkingDir  string       `json:"working_dir,omitempty"`
+	Bead        *BeadPayload `json:"bead,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// Plan is the result of a dry run: each action's intended effect, plus the
+// files, commands, and beads the envelope as a whole would touch.
+type Plan struct {
+	Valid         bool          `json:"valid"`
+	Notes         string        `json:"notes,omitempty"`
+	Steps         []PlanStep    `json:"steps"`
+	FilesWritten  []string      `json:"files_written,omitempty"`
+	FilesDeleted  []string      `json:"files_deleted,omitempty"`
+	Commands      []string      `json:"commands,omitempty"`
+	BeadsCreated  []BeadPayload `json:"beads_created,omitempty"`
+	BeadsChanged  []string      `json:"beads_changed,omitempty"`
+	GitOperations []string      `json:"git_operations,omitempty"`
+}
+
+// DryRun validates an envelope and describes what executing it would do,
+// without executing anything. Unlike Validate, an invalid action does not
+// stop the plan: it is reported on its step and the plan is marked invalid.
+func (r *Router) DryRun(env *ActionEnvelope, actx ActionContext) *Plan {
+	plan := &Plan{Valid: true, Steps: []PlanStep{}}
+	if env == nil || len(env.Actions) == 0 {
+		plan.Valid = false
+		return plan
+	}
+	plan.Notes = env.Notes
+
+	written := map[string]bool{}
+	deleted := map[string]bool{}
+	for i, action := range env.Actions {
+		var step PlanStep
+		if action.Type == "" {
+			step.Error = "missing type"
+		} else if err := validateForDryRun(action); err != nil {
+			step.Error = err.Error()
+		} else {
+			step = r.planAction(action, actx)
+		}
+		step.Index = i
+		step.ActionType = action.Type
+		if step.Error != "" {
+			plan.Valid = false
+			plan.Steps = append(plan.Steps, step)
+			continue
+		}
+
+		switch step.Effect {
+		case EffectWrite:
+			for _, f := range step.Files {
+				written[f] = true
+			}
+		case EffectDelete:
+			// Moves and renames list the source, then the target they write.
+			deleted[step.Files[0]] = true
+			if len(step.Files) > 1 {
+				written[step.Files[1]] = true
+			}
+		case EffectCommand:
+			plan.Commands = append(plan.Commands, step.Command)
+		case EffectGit:
+			plan.GitOperations = append(plan.GitOperations, step.Description)
+		case EffectBead:
+			if step.Bead != nil {
+				plan.BeadsCreated = append(plan.BeadsCreated, *step.Bead)
+			} else if action.BeadID != "" {
+				plan.BeadsChanged = append(plan.BeadsChanged, action.BeadID)
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	plan.FilesWritten = sortedKeys(written)
+	plan.FilesDeleted = sortedKeys(deleted)
+	return plan
+}
+
+// Results reports a plan as per-action results, the way Execute does for a
+// dry run: "planned" with the step's description, or "error" for an invalid
+// action.
+func (p *Plan) Results() []Result {
+	results := make([]Result, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		if step.Error != "" {
+			results = append(results, Result{ActionType: step.ActionType, Status: "error", Message: step.Error})
+			continue
+		}
+		metadata := map[string]interface{}{"effect": step.Effect}
+		if len(step.Files) > 0 {
+			metadata["files"] = step.Files
+		}
+		if step.Command != "" {
+			metadata["command"] = step.Command
+		}
+		results = append(results, Result{ActionType: step.ActionType, Status: "planned", Message: step.Description, Metadata: metadata})
+	}
+	return results
+}
+
+// planAction describes a single action. It mirrors executeAction's dispatch.
+func (r *Router) planAction(action Action, actx ActionContext) PlanStep {
+	read := func(desc string, files ...string) PlanStep {
+		return PlanStep{Effect: EffectRead, Description: desc, Files: nonEmpty(files)}
+	}
+	write := func(desc string, files ...string) PlanStep {
+		return PlanStep{Effect: EffectWrite, Description: desc, Files: nonEmpty(files)}
+	}
+	run := func(desc, command, workDir string) PlanStep {
+		if workDir == "" {
+			workDir = r.getProjectWorkDir(actx.ProjectID)
+		}
+		return PlanStep{Effect: EffectCommand, Description: desc, Command: command, WorkingDir: workDir}
+	}
+	git := func(format string, args ...interface{}) PlanStep {
+		return PlanStep{Effect: EffectGit, Description: fmt.Sprintf(format, args...)}
+	}
+	bead := func(format string, args ...interface{}) PlanStep {
+		return PlanStep{Effect: EffectBead, Description: fmt.Sprintf(format, args...)}
+	}
+	newBead := func(desc string, payload BeadPayload) PlanStep {
+		return PlanStep{Effect: EffectBead, Description: desc, Bead: &payload}
+	}
+	orDefault := func(s, def string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	}
+
+	switch action.Type {
+	case ActionAskFollowup:
+		return newBead("file a follow-up question bead", BeadPayload{Title: "Follow-up question", Description: action.Question, ProjectID: actx.ProjectID})
+
+	case ActionReadCode, ActionReadFile:
+		return read("read "+action.Path, action.Path)
+	case ActionReadTree:
+		return read("list files under "+orDefault(action.Path, "."), action.Path)
+	case ActionSearchText:
+		return read(fmt.Sprintf("search %s for %q", orDefault(action.Path, "."), action.Query))
+	case ActionFindReferences, ActionGoToDefinition, ActionFindImplementations:
+		return read(fmt.Sprintf("%s for %s in %s", strings.ReplaceAll(action.Type, "_", " "), orDefault(action.Symbol, fmt.Sprintf("%d:%d", action.Line, action.Column)), action.Path), action.Path)
+	case ActionReadBeadConversation, ActionReadBeadContext:
+		return read(strings.ReplaceAll(action.Type, "_", " ") + " of " + action.BeadID)
+	case ActionGitStatus:
+		return read("show git status")
+	case ActionGitDiff:
+		return read("show the working tree diff")
+	case ActionGitLog:
+		return read("show git log of " + orDefault(action.Branch, "the current branch"))
+	case ActionGitListBranches:
+		return read("list branches")
+	case ActionGitDiffBranches:
+		return read(fmt.Sprintf("diff %s against %s", action.SourceBranch, action.TargetBranch))
+	case ActionGitBeadCommits:
+		return read("list commits for bead " + orDefault(action.BeadID, actx.BeadID))
+	case ActionFetchPR:
+		return read(fmt.Sprintf("fetch PR #%d", action.PRNumber))
+	case ActionReviewCode:
+		return read(fmt.Sprintf("review PR #%d", action.PRNumber))
+
+	case ActionWriteFile:
+		return write(fmt.Sprintf("write %s (%d bytes)", action.Path, len(action.Content)), action.Path)
+	case ActionEditCode:
+		return write("edit "+action.Path, action.Path)
+	case ActionApplyPatch:
+		files := patchFiles(action.Patch)
+		return write("apply a patch to "+orDefault(strings.Join(files, ", "), "the project"), files...)
+	case ActionExtractMethod:
+		return write(fmt.Sprintf("extract lines %d-%d of %s into %s", action.StartLine, action.EndLine, action.Path, action.MethodName), action.Path)
+	case ActionRenameSymbol:
+		return write(fmt.Sprintf("rename %s to %s in %s", action.Symbol, action.NewName, action.Path), action.Path)
+	case ActionInlineVariable:
+		return write(fmt.Sprintf("inline %s in %s", action.VariableName, action.Path), action.Path)
+	case ActionAddLog:
+		return write(fmt.Sprintf("add a log statement at %s:%d", action.Path, action.Line), action.Path)
+	case ActionAddBreakpoint:
+		return write(fmt.Sprintf("add a breakpoint at %s:%d", action.Path, action.Line), action.Path)
+	case ActionGenerateDocs:
+		return write("generate docs for "+action.Path, action.Path)
+
+	case ActionDeleteFile:
+		return PlanStep{Effect: EffectDelete, Description: "delete " + action.Path, Files: []string{action.Path}}
+	case ActionMoveFile:
+		return PlanStep{Effect: EffectDelete, Description: fmt.Sprintf("move %s to %s", action.SourcePath, action.TargetPath),
+			Files: []string{action.SourcePath, action.TargetPath}}
+	case ActionRenameFile:
+		target := action.NewName
+		if !strings.Contains(target, "/") {
+			target = path.Join(path.Dir(action.SourcePath), target)
+		}
+		return PlanStep{Effect: EffectDelete, Description: fmt.Sprintf("rename %s to %s", action.SourcePath, action.NewName),
+			Files: []string{action.SourcePath, target}}
+
+	case ActionRunCommand:
+		return run("run `"+action.Command+"`", action.Command, action.WorkingDir)
+	case ActionRunTests:
+		desc := "run tests"
+		if action.TestPattern != "" {
+			desc += " matching " + action.TestPattern
+		}
+		return run(desc, orDefault(action.Framework, "auto-detected")+" tests", "")
+	case ActionRunLinter:
+		return run("run the linter", orDefault(action.Framework, "auto-detected")+" linter", "")
+	case ActionBuildProject:
+		return run("build the project", orDefault(action.BuildCommand, "auto-detected build"), "")
+	case ActionInstallPrerequisites:
+		cmd := action.Command
+		if cmd == "" {
+			cmd = "install " + strings.Join(action.Packages, " ")
+		}
+		return run("install prerequisites in the project container", cmd, "/")
+
+	case ActionGitCommit:
+		files := orDefault(strings.Join(action.Files, ", "), "all changes")
+		return git("commit %s after the quality gate passes (may push and open a PR)", files)
+	case ActionGitCheckpoint:
+		return git("checkpoint commit of %s", orDefault(strings.Join(action.Files, ", "), "all changes"))
+	case ActionGitPush:
+		return git("push %s", orDefault(action.Branch, "the current branch"))
+	case ActionCreatePR:
+		if action.RequestReview {
+			return git("push %s, open a pull request into %s and file a review bead", orDefault(action.Branch, "the current branch"), orDefault(action.PRBase, "main"))
+		}
+		return git("push %s and open a pull request into %s", orDefault(action.Branch, "the current branch"), orDefault(action.PRBase, "main"))
+	case ActionGitMerge:
+		return git("merge %s", action.SourceBranch)
+	case ActionGitRevert:
+		shas := action.CommitSHAs
+		if action.CommitSHA != "" {
+			shas = append([]string{action.CommitSHA}, shas...)
+		}
+		return git("revert %s", strings.Join(shas, ", "))
+	case ActionGitBranchDelete:
+		if action.DeleteRemote {
+			return git("delete branch %s locally and on the remote", action.Branch)
+		}
+		return git("delete branch %s", action.Branch)
+	case ActionGitCheckout:
+		return git("check out %s", action.Branch)
+	case ActionGitFetch:
+		return git("fetch from the remote")
+
+	case ActionCreateBead:
+		payload := *action.Bead
+		return newBead(fmt.Sprintf("create bead %q in %s", payload.Title, payload.ProjectID), payload)
+	case ActionDelegateTask:
+		return newBead(fmt.Sprintf("delegate %q to %s", action.TaskTitle, action.DelegateToRole), BeadPayload{
+			Title: action.TaskTitle, Description: action.TaskDescription, Priority: action.TaskPriority,
+			Type: "delegated", ProjectID: actx.ProjectID})
+	case ActionCloseBead:
+		return bead("close bead %s", action.BeadID)
+	case ActionEscalateCEO:
+		return bead("escalate bead %s to the CEO as a decision", action.BeadID)
+	case ActionApproveBead:
+		return bead("approve bead %s and advance its workflow", action.BeadID)
+	case ActionRejectBead:
+		return bead("reject bead %s: %s", action.BeadID, action.Reason)
+	case ActionRelateBeads:
+		return bead("mark bead %s %s %s", action.BeadID, action.Relation, action.TargetBeadID)
+
+	case ActionSendAgentMessage:
+		return PlanStep{Effect: EffectMessage, Description: fmt.Sprintf("message %s: %s",
+			orDefault(action.ToAgentID, action.ToAgentRole), orDefault(action.MessageSubject, action.MessageType))}
+	case ActionAddPRComment:
+		return PlanStep{Effect: EffectMessage, Description: fmt.Sprintf("comment on PR #%d", action.PRNumber)}
+	case ActionSubmitReview:
+		return PlanStep{Effect: EffectMessage, Description: fmt.Sprintf("submit a %s review on PR #%d", orDefault(action.ReviewEvent, "COMMENT"), action.PRNumber)}
+	case ActionRequestReview:
+		return PlanStep{Effect: EffectMessage, Description: fmt.Sprintf("request a review of PR #%d from %s", action.PRNumber, action.Reviewer)}
+
+	case ActionStartDev, ActionWhatsNext, ActionProceedToPhase, ActionConductReview, ActionResumeWorkflow:
+		return PlanStep{Effect: EffectWorkflow, Description: "hand off to the workflow tool for " + action.Type}
+
+	case ActionDone:
+		return PlanStep{Effect: EffectNone, Description: "signal that the work is done"}
+
+	default:
+		if h, ok := defaultRegistry.Get(action.Type); ok {
+			return PlanStep{Effect: h.Effect, Description: h.Description}
+		}
+		return PlanStep{Effect: EffectNone, Error: "unsupported action"}
+	}
+}
+
+// validateForDryRun applies validateAction, plus the checks the router's
+// handlers make for the actions validateAction doesn't know about (they
+// reach the router through the text and simple JSON parsers).
+func validateForDryRun(action Action) error {
+	switch action.Type {
+	case ActionFetchPR, ActionReviewCode, ActionAddPRComment, ActionSubmitReview, ActionRequestReview:
+		switch {
+		case action.PRNumber == 0:
+			return errors.New("pr_number is required")
+		case action.Type == ActionAddPRComment && action.CommentBody == "":
+			return errors.New("comment_body is required")
+		case action.Type == ActionSubmitReview && (action.ReviewEvent == "" || action.CommentBody == ""):
+			return errors.New("submit_review requires review_event and comment_body")
+		case action.Type == ActionRequestReview && action.Reviewer == "":
+			return errors.New("reviewer is required")
+		}
+	case ActionSendAgentMessage:
+		if action.ToAgentID == "" && action.ToAgentRole == "" {
+			return errors.New("either to_agent_id or to_agent_role is required")
+		}
+		if action.MessageType == "" {
+			return errors.New("message_type is required (question, delegation, notification)")
+		}
+	case ActionDelegateTask:
+		if action.DelegateToRole == "" || action.TaskTitle == "" {
+			return errors.New("delegate_task requires delegate_to_role and task_title")
+		}
+	case ActionReadBeadConversation, ActionReadBeadContext:
+		if action.BeadID == "" {
+			return errors.New("bead_id is required")
+		}
+	default:
+		return validateAction(action)
+	}
+	return nil
+}
+
+// patchFiles lists the files a unified diff touches.
+func patchFiles(patch string) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, line := range strings.Split(patch, "\n") {
+		var name string
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name = strings.TrimPrefix(line, "+++ ")
+		case strings.HasPrefix(line, "--- "):
+			name = strings.TrimPrefix(line, "--- ")
+		default:
+			continue
+		}
+		if fields := strings.Fields(name); len(fields) > 0 {
+			name = strings.TrimPrefix(strings.TrimPrefix(fields[0], "a/"), "b/")
+		}
+		if name != "" && name != "/dev/null" && !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func nonEmpty(ss []string) []string {
+	var out []string
+	for _, s := range ss {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
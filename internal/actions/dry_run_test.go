@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRouterDryRun(t *testing.T) {
+	r := &Router{}
+	env := &ActionEnvelope{
+		Notes: "fix the nil check",
+		Actions: []Action{
+			{Type: ActionReadFile, Path: "main.go"},
+			{Type: ActionWriteFile, Path: "main.go", Content: "package main\n"},
+			{Type: ActionApplyPatch, Patch: "--- a/util.go\n+++ b/util.go\n@@ -1 +1 @@\n-a\n+b\n"},
+			{Type: ActionMoveFile, SourcePath: "old.go", TargetPath: "pkg/new.go"},
+			{Type: ActionDeleteFile, Path: "dead.go"},
+			{Type: ActionRunCommand, Command: "go test ./...", WorkingDir: "/work"},
+			{Type: ActionCreateBead, Bead: &BeadPayload{Title: "Follow up", ProjectID: "p1"}},
+			{Type: ActionCloseBead, BeadID: "bd-1", Reason: "done"},
+			{Type: ActionGitCommit, CommitMessage: "fix"},
+			{Type: ActionDelegateTask, DelegateToRole: "qa", TaskTitle: "Test it"},
+		},
+	}
+
+	plan := r.DryRun(env, ActionContext{AgentID: "a1", BeadID: "bd-1", ProjectID: "p1"})
+	if !plan.Valid {
+		t.Fatalf("expected a valid plan, got %+v", plan.Steps)
+	}
+	if plan.Notes != "fix the nil check" || len(plan.Steps) != len(env.Actions) {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if want := []string{"main.go", "pkg/new.go", "util.go"}; !reflect.DeepEqual(plan.FilesWritten, want) {
+		t.Errorf("files written = %v, want %v", plan.FilesWritten, want)
+	}
+	if want := []string{"dead.go", "old.go"}; !reflect.DeepEqual(plan.FilesDeleted, want) {
+		t.Errorf("files deleted = %v, want %v", plan.FilesDeleted, want)
+	}
+	if want := []string{"go test ./..."}; !reflect.DeepEqual(plan.Commands, want) {
+		t.Errorf("commands = %v, want %v", plan.Commands, want)
+	}
+	if plan.Steps[5].WorkingDir != "/work" {
+		t.Errorf("expected working dir /work, got %q", plan.Steps[5].WorkingDir)
+	}
+	if len(plan.BeadsCreated) != 2 || plan.BeadsCreated[0].Title != "Follow up" || plan.BeadsCreated[1].Type != "delegated" {
+		t.Errorf("unexpected beads created: %+v", plan.BeadsCreated)
+	}
+	if want := []string{"bd-1"}; !reflect.DeepEqual(plan.BeadsChanged, want) {
+		t.Errorf("beads changed = %v, want %v", plan.BeadsChanged, want)
+	}
+	if len(plan.GitOperations) != 1 {
+		t.Errorf("expected one git operation, got %v", plan.GitOperations)
+	}
+	if plan.Steps[0].Effect != EffectRead {
+		t.Errorf("read_file effect = %q", plan.Steps[0].Effect)
+	}
+}
+
+func TestRouterDryRun_ReportsInvalidActions(t *testing.T) {
+	r := &Router{}
+	plan := r.DryRun(&ActionEnvelope{Actions: []Action{
+		{Type: ActionReadFile, Path: "ok.go"},
+		{Type: ActionWriteFile, Path: "missing-content.go"},
+		{Type: "launch_rockets"},
+		{Type: ActionCreateBead},
+	}}, ActionContext{})
+
+	if plan.Valid {
+		t.Fatal("expected an invalid plan")
+	}
+	if plan.Steps[0].Error != "" {
+		t.Errorf("valid step reported error: %s", plan.Steps[0].Error)
+	}
+	for _, i := range []int{1, 2, 3} {
+		if plan.Steps[i].Error == "" {
+			t.Errorf("step %d (%s) should report an error", i, plan.Steps[i].ActionType)
+		}
+	}
+	if len(plan.FilesWritten) != 0 {
+		t.Errorf("invalid steps should not contribute effects, got %v", plan.FilesWritten)
+	}
+}
+
+func TestRouterExecute_DryRunDoesNotExecute(t *testing.T) {
+	cmds := &mockCommandExecutor{}
+	closer := &mockBeadCloser{}
+	r := &Router{Commands: cmds, Closer: closer}
+
+	results, err := r.Execute(context.Background(), &ActionEnvelope{Actions: []Action{
+		{Type: ActionRunCommand, Command: "rm -rf build"},
+		{Type: ActionCloseBead, BeadID: "bd-1"},
+	}}, ActionContext{ProjectID: "p1", DryRun: true})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if cmds.lastReq.Command != "" || len(closer.closedIDs) != 0 {
+		t.Fatal("dry run executed actions")
+	}
+	if len(results) != 2 || results[0].Status != "planned" || results[0].Metadata["command"] != "rm -rf build" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
@@ -39,6 +39,9 @@ func formatSingleResult(r Result) string {
 	sb.WriteString(fmt.Sprintf("### %s — %s\n", r.ActionType, r.Status))
 
 	if r.Status == "error" {
+		if r.ActionType == ActionRunTests {
+			writeTestFailures(&sb, r)
+		}
 		sb.WriteString(fmt.Sprintf("**Error:** %s\n", r.Message))
 		// Phase 4: Specific recovery suggestions based on error type
 		writeErrorSuggestion(&sb, r)
@@ -164,6 +167,7 @@ func formatTestResult(sb *strings.Builder, r Result) {
 		sb.WriteString(fmt.Sprintf("**Tests: PASSED** (%d passed)\n", int(passed)))
 	} else {
 		sb.WriteString(fmt.Sprintf("**Tests: FAILED** (%d passed, %d failed)\n", int(passed), int(failed)))
+		writeTestFailures(sb, r)
 	}
 
 	if output != "" && !success {
@@ -178,6 +182,26 @@ func formatTestResult(sb *strings.Builder, r Result) {
 	}
 }
 
+// writeTestFailures lists the failing tests triage found in the output.
+func writeTestFailures(sb *strings.Builder, r Result) {
+	failures, _ := r.Metadata["failures"].([]TestFailure)
+	if len(failures) == 0 {
+		return
+	}
+	sb.WriteString("**Failing tests:**\n")
+	for _, f := range failures {
+		line := "- " + f.ID()
+		if f.Message != "" {
+			line += ": " + f.Message
+		}
+		if f.Flaky {
+			line += " (known flaky)"
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+}
+
 func formatLintResult(sb *strings.Builder, r Result) {
 	if r.Metadata == nil {
 		sb.WriteString(r.Message + "\n")
@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// This is the subset of JSON Schema that registered action payloads are
+// validated with: type, properties, required, enum, items, and
+// additionalProperties (boolean only). Descriptions and titles are allowed
+// and ignored.
+
+var schemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "enum": true, "items": true,
+	"additionalProperties": true, "description": true, "title": true,
+}
+
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "integer": true, "number": true, "boolean": true, "null": true,
+}
+
+// checkSchema reports schema keywords or types validateSchema doesn't
+// support, so a bad schema is caught at registration rather than silently
+// accepting everything.
+func checkSchema(schema map[string]interface{}, path string) error {
+	for key := range schema {
+		if !schemaKeywords[key] {
+			return fmt.Errorf("%s: unsupported keyword %q", path, key)
+		}
+	}
+	if t, ok := schema["type"]; ok {
+		name, _ := t.(string)
+		if !schemaTypes[name] {
+			return fmt.Errorf("%s: unsupported type %v", path, t)
+		}
+	}
+	if v, ok := schema["additionalProperties"]; ok {
+		if _, isBool := v.(bool); !isBool {
+			return fmt.Errorf("%s: additionalProperties must be a boolean", path)
+		}
+	}
+	if v, ok := schema["properties"]; ok {
+		props, isMap := v.(map[string]interface{})
+		if !isMap {
+			return fmt.Errorf("%s: properties must be an object", path)
+		}
+		for name, p := range props {
+			sub, isMap := p.(map[string]interface{})
+			if !isMap {
+				return fmt.Errorf("%s.%s: schema must be an object", path, name)
+			}
+			if err := checkSchema(sub, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if v, ok := schema["items"]; ok {
+		sub, isMap := v.(map[string]interface{})
+		if !isMap {
+			return fmt.Errorf("%s: items must be an object", path)
+		}
+		if err := checkSchema(sub, path+"[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSchema checks a decoded JSON value against a schema.
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if t, _ := schema["type"].(string); t != "" && !matchesType(t, value) {
+		return fmt.Errorf("%s must be %s %s", path, article(t), t)
+	}
+	if enum := enumValues(schema["enum"]); enum != nil {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %v", path, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s.%s is required", path, name)
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, known := props[name].(map[string]interface{})
+			if !known {
+				if allowed, set := schema["additionalProperties"].(bool); set && !allowed {
+					return fmt.Errorf("%s.%s is not allowed", path, name)
+				}
+				continue
+			}
+			if err := validateSchema(sub, v[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number", "integer":
+		var f float64
+		switch n := value.(type) {
+		case float64:
+			f = n
+		case int:
+			f = float64(n)
+		case int64:
+			f = float64(n)
+		default:
+			return false
+		}
+		return t == "number" || f == math.Trunc(f)
+	}
+	return false
+}
+
+func article(t string) string {
+	if strings.IndexAny(t[:1], "aeiou") == 0 {
+		return "an"
+	}
+	return "a"
+}
+
+func enumValues(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	strs, ok := v.([]string)
+	if !ok {
+		return nil
+	}
+	out := make([]interface{}, len(strs))
+	for i, s := range strs {
+		out[i] = s
+	}
+	return out
+}
+
+// stringList converts a decoded JSON array of strings.
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
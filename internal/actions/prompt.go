@@ -39,6 +39,7 @@ The response must be a single JSON object with this shape:
 - git_diff: Show unstaged changes
 - git_commit: Create a commit. Optional: commit_message, files
 - git_push: Push to remote. Optional: branch, set_upstream
+- create_pr: Push the branch and open a pull request. Optional: pr_title, pr_body, pr_base, branch, pr_reviewers, request_review (file a review bead), review_persona
 - git_log: View commit history. Optional: branch, max_count
 - git_fetch: Fetch from remote
 - git_checkout: Switch branches. Required: branch
@@ -52,6 +53,7 @@ The response must be a single JSON object with this shape:
 - create_bead: Create a work item. Required: bead object with title, project_id
 - close_bead: Close/complete a bead. Required: bead_id. Optional: reason
 - escalate_ceo: Escalate to CEO for decision. Required: bead_id, reason
+- relate_beads: Relate a bead to another; marking it duplicate_of closes it. Required: bead_id, relation (duplicate_of, related_to, parent, child, blocked_by, blocks), target_bead_id
 - done: Signal that work is complete — no more actions needed. Optional: reason
 
 ### Code Navigation (when LSP is available)
@@ -109,10 +111,20 @@ Example signaling completion:
 }
 `
 
+// CurrentActionPrompt returns ActionPrompt with the registered action types
+// listed after the built-in ones.
+func CurrentActionPrompt() string {
+	registered := registeredActionsPrompt()
+	if registered == "" {
+		return ActionPrompt
+	}
+	return strings.Replace(ActionPrompt, "\n## Code Change Workflow", "\n"+registered+"\n## Code Change Workflow", 1)
+}
+
 // BuildEnhancedPrompt replaces the lessons placeholder with actual lessons
 // and appends any progress context from prior dispatches.
 func BuildEnhancedPrompt(lessons string, progressContext string) string {
-	prompt := ActionPrompt
+	prompt := CurrentActionPrompt()
 
 	if lessons != "" {
 		prompt = strings.Replace(prompt, "LESSONS_PLACEHOLDER", "## Lessons Learned\n\n"+lessons, 1)
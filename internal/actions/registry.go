@@ -0,0 +1,213 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HandlerFunc executes a registered action.
+type HandlerFunc func(ctx context.Context, action Action, actx ActionContext) Result
+
+// Handler is an action type registered at startup, alongside the built-in
+// actions the router dispatches itself. Its fields are passed in the
+// action's "params" object and validated against Schema before Execute is
+// called. Registered actions are listed in the prompt sent to agents.
+type Handler struct {
+	Type string
+	// Category groups the action in the prompt, e.g. "Deployment".
+	Category string
+	// Description is the one-line summary shown to agents.
+	Description string
+	// Schema is a JSON Schema for params. It supports the type, properties,
+	// required, enum, items, and additionalProperties keywords.
+	Schema map[string]interface{}
+	// Effect classifies the action for dry runs (see EffectRead etc.);
+	// it defaults to EffectCommand.
+	Effect  string
+	Execute HandlerFunc
+}
+
+// Registry holds registered action handlers.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]*Handler
+}
+
+// NewRegistry creates an empty action registry.
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]*Handler)}
+}
+
+// defaultRegistry is the registry the router, Validate, and the action
+// prompts consult.
+var defaultRegistry = NewRegistry()
+
+// RegisterAction adds an action handler to the default registry.
+func RegisterAction(h Handler) error {
+	return defaultRegistry.Register(h)
+}
+
+// UnregisterAction removes an action handler from the default registry.
+func UnregisterAction(actionType string) {
+	defaultRegistry.Unregister(actionType)
+}
+
+// RegisteredActions lists the default registry's handlers by type.
+func RegisteredActions() []Handler {
+	return defaultRegistry.List()
+}
+
+// Register adds a handler. Built-in action types and types that are already
+// registered are rejected, as are handlers without Execute or with a schema
+// that uses unsupported keywords.
+func (r *Registry) Register(h Handler) error {
+	h.Type = strings.TrimSpace(h.Type)
+	switch {
+	case h.Type == "":
+		return errors.New("action type is required")
+	case builtinActions[h.Type]:
+		return fmt.Errorf("action type %s is built in", h.Type)
+	case h.Execute == nil:
+		return fmt.Errorf("action type %s has no Execute function", h.Type)
+	}
+	if h.Schema != nil {
+		if err := checkSchema(h.Schema, "params"); err != nil {
+			return fmt.Errorf("action type %s: invalid schema: %w", h.Type, err)
+		}
+	}
+	if h.Effect == "" {
+		h.Effect = EffectCommand
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.handlers[h.Type]; exists {
+		return fmt.Errorf("action type %s is already registered", h.Type)
+	}
+	r.handlers[h.Type] = &h
+	return nil
+}
+
+// Unregister removes a handler.
+func (r *Registry) Unregister(actionType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.handlers, actionType)
+}
+
+// Get returns the handler for an action type.
+func (r *Registry) Get(actionType string) (*Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[actionType]
+	return h, ok
+}
+
+// List returns the registered handlers sorted by category and type.
+func (r *Registry) List() []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Handler, 0, len(r.handlers))
+	for _, h := range r.handlers {
+		list = append(list, *h)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Category != list[j].Category {
+			return list[i].Category < list[j].Category
+		}
+		return list[i].Type < list[j].Type
+	})
+	return list
+}
+
+// Validate checks an action's params against the handler's schema.
+func (h *Handler) Validate(action Action) error {
+	if h.Schema == nil {
+		return nil
+	}
+	var params interface{} = action.Params
+	if action.Params == nil {
+		params = map[string]interface{}{}
+	}
+	return validateSchema(h.Schema, params, "params")
+}
+
+// registeredActionsPrompt describes the registered actions for the action
+// prompt, or returns "" when there are none.
+func registeredActionsPrompt() string {
+	handlers := RegisteredActions()
+	if len(handlers) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### Registered Actions\nThese take their fields in a \"params\" object, e.g. {\"type\": \"<action_type>\", \"params\": {...}}.\n")
+	category := ""
+	for _, h := range handlers {
+		if h.Category != category && h.Category != "" {
+			fmt.Fprintf(&sb, "\n%s:\n", h.Category)
+		}
+		category = h.Category
+		fmt.Fprintf(&sb, "- %s: %s", h.Type, h.Description)
+		if fields := schemaFieldSummary(h.Schema); fields != "" {
+			sb.WriteString(" " + fields)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// schemaFieldSummary renders a schema's properties the way the built-in
+// actions are described: "Required: a, b. Optional: c".
+func schemaFieldSummary(schema map[string]interface{}) string {
+	props, _ := schema["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		return ""
+	}
+	required := map[string]bool{}
+	for _, name := range stringList(schema["required"]) {
+		required[name] = true
+	}
+	var req, opt []string
+	for name := range props {
+		if required[name] {
+			req = append(req, name)
+		} else {
+			opt = append(opt, name)
+		}
+	}
+	sort.Strings(req)
+	sort.Strings(opt)
+	var parts []string
+	if len(req) > 0 {
+		parts = append(parts, "Required: "+strings.Join(req, ", "))
+	}
+	if len(opt) > 0 {
+		parts = append(parts, "Optional: "+strings.Join(opt, ", "))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// builtinActions are the action types the router handles itself.
+var builtinActions = map[string]bool{
+	ActionAskFollowup: true, ActionReadCode: true, ActionEditCode: true, ActionWriteFile: true,
+	ActionRunCommand: true, ActionRunTests: true, ActionRunLinter: true, ActionBuildProject: true,
+	ActionCreateBead: true, ActionCloseBead: true, ActionEscalateCEO: true, ActionReadFile: true,
+	ActionReadTree: true, ActionSearchText: true, ActionApplyPatch: true, ActionGitStatus: true,
+	ActionGitDiff: true, ActionGitCommit: true, ActionGitPush: true, ActionGitCheckpoint: true,
+	ActionCreatePR: true, ActionStartDev: true, ActionWhatsNext: true, ActionProceedToPhase: true,
+	ActionConductReview: true, ActionResumeWorkflow: true, ActionApproveBead: true, ActionRejectBead: true,
+	ActionFindReferences: true, ActionGoToDefinition: true, ActionFindImplementations: true,
+	ActionExtractMethod: true, ActionRenameSymbol: true, ActionInlineVariable: true,
+	ActionMoveFile: true, ActionDeleteFile: true, ActionRenameFile: true,
+	ActionAddLog: true, ActionAddBreakpoint: true, ActionGenerateDocs: true,
+	ActionFetchPR: true, ActionReviewCode: true, ActionAddPRComment: true, ActionSubmitReview: true,
+	ActionRequestReview: true, ActionGitMerge: true, ActionGitRevert: true, ActionGitBranchDelete: true,
+	ActionGitCheckout: true, ActionGitLog: true, ActionGitFetch: true, ActionGitListBranches: true,
+	ActionGitDiffBranches: true, ActionGitBeadCommits: true, ActionInstallPrerequisites: true,
+	ActionDone: true, ActionSendAgentMessage: true, ActionDelegateTask: true,
+	ActionReadBeadConversation: true, ActionReadBeadContext: true, ActionRelateBeads: true,
+}
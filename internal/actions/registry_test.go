@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func deployHandler(calls *[]Action) Handler {
+	return Handler{
+		Type:        "deploy_service",
+		Category:    "Deployment",
+		Description: "Deploy a service to an environment",
+		Schema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"service":     map[string]interface{}{"type": "string"},
+				"environment": map[string]interface{}{"type": "string", "enum": []string{"staging", "production"}},
+				"replicas":    map[string]interface{}{"type": "integer"},
+			},
+			"required":             []string{"service", "environment"},
+			"additionalProperties": false,
+		},
+		Execute: func(ctx context.Context, action Action, actx ActionContext) Result {
+			*calls = append(*calls, action)
+			return Result{ActionType: action.Type, Status: "executed", Message: "deployed"}
+		},
+	}
+}
+
+func TestRegisterAction_ExecuteValidateAndPrompt(t *testing.T) {
+	var calls []Action
+	if err := RegisterAction(deployHandler(&calls)); err != nil {
+		t.Fatalf("RegisterAction: %v", err)
+	}
+	defer UnregisterAction("deploy_service")
+
+	r := &Router{}
+	ok := Action{Type: "deploy_service", Params: map[string]interface{}{"service": "api", "environment": "staging", "replicas": float64(2)}}
+	bad := Action{Type: "deploy_service", Params: map[string]interface{}{"service": "api", "environment": "qa"}}
+
+	if err := Validate(&ActionEnvelope{Actions: []Action{ok}}); err != nil {
+		t.Errorf("valid registered action rejected: %v", err)
+	}
+	if err := Validate(&ActionEnvelope{Actions: []Action{bad}}); err == nil || !strings.Contains(err.Error(), "params.environment") {
+		t.Errorf("expected an environment enum error, got %v", err)
+	}
+
+	results, err := r.Execute(context.Background(), &ActionEnvelope{Actions: []Action{ok, bad}}, ActionContext{ProjectID: "p1"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if results[0].Status != "executed" || results[1].Status != "error" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected only the valid action to run, got %d calls", len(calls))
+	}
+
+	env, err := DecodeStrict([]byte(`{"actions":[{"type":"deploy_service","params":{"service":"api","environment":"production"}}]}`))
+	if err != nil || env.Actions[0].Params["service"] != "api" {
+		t.Errorf("DecodeStrict registered action: %v %+v", err, env)
+	}
+
+	prompt := CurrentActionPrompt()
+	if !strings.Contains(prompt, "- deploy_service: Deploy a service to an environment Required: environment, service. Optional: replicas") {
+		t.Errorf("registered action missing from prompt:\n%s", prompt)
+	}
+	if strings.Index(prompt, "### Registered Actions") > strings.Index(prompt, "## Code Change Workflow") {
+		t.Error("registered actions should be listed with the other action types")
+	}
+
+	plan := r.DryRun(&ActionEnvelope{Actions: []Action{ok}}, ActionContext{})
+	if !plan.Valid || plan.Steps[0].Effect != EffectCommand || len(calls) != 1 {
+		t.Errorf("unexpected dry run: %+v", plan)
+	}
+}
+
+func TestRegistry_RejectsInvalidHandlers(t *testing.T) {
+	reg := NewRegistry()
+	noop := func(ctx context.Context, action Action, actx ActionContext) Result { return Result{} }
+	tests := []struct {
+		name string
+		h    Handler
+	}{
+		{"empty type", Handler{Execute: noop}},
+		{"built in", Handler{Type: ActionWriteFile, Execute: noop}},
+		{"no execute", Handler{Type: "x"}},
+		{"bad keyword", Handler{Type: "x", Execute: noop, Schema: map[string]interface{}{"pattern": "^a"}}},
+		{"bad type", Handler{Type: "x", Execute: noop, Schema: map[string]interface{}{"type": "date"}}},
+	}
+	for _, tt := range tests {
+		if err := reg.Register(tt.h); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if err := reg.Register(Handler{Type: "x", Execute: noop}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.Register(Handler{Type: "x", Execute: noop}); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+	if h, _ := reg.Get("x"); h.Effect != EffectCommand {
+		t.Errorf("expected default effect %q, got %q", EffectCommand, h.Effect)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"count": map[string]interface{}{"type": "integer"},
+			"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	tests := []struct {
+		value   interface{}
+		wantErr string
+	}{
+		{map[string]interface{}{"count": float64(3), "tags": []interface{}{"a"}}, ""},
+		{map[string]interface{}{"extra": true}, ""},
+		{map[string]interface{}{"count": 1.5}, "params.count must be an integer"},
+		{map[string]interface{}{"tags": []interface{}{"a", 2.0}}, "params.tags[1] must be a string"},
+		{"nope", "params must be an object"},
+	}
+	for _, tt := range tests {
+		err := validateSchema(schema, tt.value, "params")
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected error %v", tt.value, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("%v: expected %q, got %v", tt.value, tt.wantErr, err)
+		}
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jordanhubbard/loom/internal/executor"
 	"github.com/jordanhubbard/loom/internal/files"
+	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type BeadCreator interface {
@@ -20,6 +25,12 @@ type BeadCloser interface {
 	CloseBead(beadID, reason string) error
 }
 
+// BeadRelater relates beads to each other: duplicates, parents and
+// children, blockers, and related beads.
+type BeadRelater interface {
+	RelateBeads(beadID, relation, targetID, actor string) error
+}
+
 type BeadEscalator interface {
 	EscalateBeadToCEO(beadID, reason, returnedTo string) (*models.DecisionBead, error)
 }
@@ -40,6 +51,24 @@ type BuildRunner interface {
 	Run(ctx context.Context, projectPath, buildTarget, buildCommand, framework string, timeoutSeconds int) (map[string]interface{}, error)
 }
 
+// ProjectSettings supplies a project's own build, test and lint commands;
+// an empty command means the project has none configured.
+type ProjectSettings interface {
+	BuildCommand(projectID string) string
+	TestCommand(projectID string) string
+	LintCommand(projectID string) string
+}
+
+// FlakyTestSettings supplies the tests a project knows to be flaky.
+type FlakyTestSettings interface {
+	FlakyTestPatterns(projectID string) []string
+	FlakyTestRetries(projectID string) (int, bool)
+}
+
+type BeadLister interface {
+	GetBeadsByProject(projectID string) ([]*models.Bead, error)
+}
+
 type ProjectGetter interface {
 	GetProject(projectID string) (*models.Project, error)
 }
@@ -80,6 +109,16 @@ type ActionLogger interface {
 	LogAction(ctx context.Context, actx ActionContext, action Action, result Result)
 }
 
+// ActionMetrics records how long each executed action took.
+type ActionMetrics interface {
+	ObserveAction(projectID, actionType, status string, duration time.Duration)
+}
+
+// OutputAttacher stores action output as a file attached to the bead.
+type OutputAttacher interface {
+	AttachOutput(ctx context.Context, beadID, agentID, filename, contentType string, data []byte) error
+}
+
 type WorkflowOperator interface {
 	AdvanceWorkflowWithCondition(beadID, agentID string, condition string, resultData map[string]string) error
 	StartDevelopment(ctx context.Context, workflow string, requireReviews bool, projectPath string) (map[string]interface{}, error)
@@ -94,6 +133,10 @@ type MessageSender interface {
 	FindAgentByRole(ctx context.Context, role string) (string, error)
 }
 
+type BeadUpdater interface {
+	UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error)
+}
+
 type BeadReader interface {
 	GetBead(beadID string) (*models.Bead, error)
 	GetBeadConversation(beadID string) ([]models.ChatMessage, error)
@@ -103,6 +146,8 @@ type ActionContext struct {
 	AgentID   string
 	BeadID    string
 	ProjectID string
+	// DryRun makes Execute describe each action instead of running it.
+	DryRun bool
 }
 
 type Result struct {
@@ -115,6 +160,7 @@ type Result struct {
 type Router struct {
 	Beads         BeadCreator
 	Closer        BeadCloser
+	Relations     BeadRelater
 	Escalator     BeadEscalator
 	Commands      CommandExecutor
 	Tests         TestRunner
@@ -123,11 +169,17 @@ type Router struct {
 	Files         FileManager
 	Git           GitOperator
 	Logger        ActionLogger
+	Metrics       ActionMetrics
+	Attachments   OutputAttacher
 	Workflow      WorkflowOperator
 	LSP           LSPOperator
 	MessageBus    MessageSender
 	BeadReader    BeadReader
+	BeadUpdater   BeadUpdater
 	Projects      ProjectGetter
+	Settings      ProjectSettings
+	FlakyTests    FlakyTestSettings
+	BeadLister    BeadLister
 	ContainerOrch ContainerOrchestrator
 	BuildEnv      *BuildEnvManager
 	BeadType      string
@@ -180,6 +232,65 @@ func (r *Router) runBuildForProject(ctx context.Context, actx ActionContext, exp
 	})
 }
 
+// runConfiguredCommand runs a project's configured test or lint command in
+// place of the test runner or linter.
+func (r *Router) runConfiguredCommand(ctx context.Context, actx ActionContext, actionType, command, what string) Result {
+	res, err := r.Commands.ExecuteCommand(ctx, executor.ExecuteCommandRequest{
+		AgentID:    actx.AgentID,
+		BeadID:     actx.BeadID,
+		ProjectID:  actx.ProjectID,
+		Command:    command,
+		WorkingDir: r.getProjectWorkDir(actx.ProjectID),
+		Timeout:    300,
+	})
+	if err != nil {
+		return Result{ActionType: actionType, Status: "error", Message: fmt.Sprintf("%s executor error: %v", what, err)}
+	}
+	metadata := map[string]interface{}{
+		"command":     command,
+		"stdout":      res.Stdout,
+		"stderr":      res.Stderr,
+		"exit_code":   res.ExitCode,
+		"duration_ms": res.Duration,
+	}
+	if !res.Success || res.ExitCode != 0 {
+		return Result{
+			ActionType: actionType,
+			Status:     "error",
+			Message:    fmt.Sprintf("%s failed (exit %d):\nstdout: %s\nstderr: %s", what, res.ExitCode, res.Stdout, res.Stderr),
+			Metadata:   metadata,
+		}
+	}
+	return Result{ActionType: actionType, Status: "executed", Message: what + " passed", Metadata: metadata}
+}
+
+// runTests runs the project's configured test command, or the test runner.
+func (r *Router) runTests(ctx context.Context, action Action, actx ActionContext) Result {
+	if r.Settings != nil && r.Commands != nil && action.TestPattern == "" {
+		if command := r.Settings.TestCommand(actx.ProjectID); command != "" {
+			return r.runConfiguredCommand(ctx, actx, action.Type, command, "tests")
+		}
+	}
+	if r.Tests == nil {
+		return Result{ActionType: action.Type, Status: "error", Message: "test runner not configured"}
+	}
+	projectPath := r.getProjectWorkDir(actx.ProjectID)
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	result, err := r.Tests.Run(ctx, projectPath, action.TestPattern, action.Framework, action.TimeoutSeconds)
+	if err != nil {
+		return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
+	}
+	return Result{
+		ActionType: action.Type,
+		Status:     "executed",
+		Message:    "tests executed",
+		Metadata:   result,
+	}
+}
+
 func (r *Router) Execute(ctx context.Context, env *ActionEnvelope, actx ActionContext) ([]Result, error) {
 	if env == nil {
 		return nil, fmt.Errorf("action envelope is nil")
@@ -190,18 +301,86 @@ func (r *Router) Execute(ctx context.Context, env *ActionEnvelope, actx ActionCo
 		ctx = WithProjectID(ctx, actx.ProjectID)
 	}
 
+	if actx.DryRun {
+		return r.DryRun(env, actx).Results(), nil
+	}
+
 	results := make([]Result, 0, len(env.Actions))
 	for _, action := range env.Actions {
-		result := r.executeAction(ctx, action, actx)
-		if r.Logger != nil {
-			r.Logger.LogAction(ctx, actx, action, result)
-		}
-		results = append(results, result)
+		results = append(results, r.executeTraced(ctx, action, actx))
 	}
 
 	return results, nil
 }
 
+// executeTraced runs one action inside an "action.<type>" span, then records
+// its metrics, log entry and output.
+func (r *Router) executeTraced(ctx context.Context, action Action, actx ActionContext) Result {
+	ctx, span := telemetry.Tracer.Start(ctx, "action."+action.Type)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("action_type", action.Type),
+		attribute.String("bead_id", actx.BeadID),
+		attribute.String("project_id", actx.ProjectID),
+		attribute.String("agent_id", actx.AgentID),
+	)
+
+	started := time.Now()
+	result := r.executeAction(ctx, action, actx)
+	if r.Metrics != nil {
+		r.Metrics.ObserveAction(actx.ProjectID, action.Type, result.Status, time.Since(started))
+	}
+	span.SetAttributes(attribute.String("status", result.Status))
+	if result.Status == "error" {
+		span.SetStatus(codes.Error, result.Message)
+	}
+	if r.Logger != nil {
+		r.Logger.LogAction(ctx, actx, action, result)
+	}
+	r.attachOutput(ctx, actx, action, result)
+	return result
+}
+
+// attachOutput saves the full output of commands, builds, tests, linters,
+// and diffs on the bead, so it survives after the agent's context is gone.
+func (r *Router) attachOutput(ctx context.Context, actx ActionContext, action Action, result Result) {
+	if r.Attachments == nil || actx.BeadID == "" {
+		return
+	}
+
+	var output, ext, contentType string
+	switch action.Type {
+	case ActionRunCommand, ActionBuildProject, ActionRunTests, ActionRunLinter:
+		ext, contentType = "log", "text/plain"
+		if raw, _ := result.Metadata["raw_output"].(string); raw != "" {
+			output = raw
+		} else {
+			stdout, _ := result.Metadata["stdout"].(string)
+			stderr, _ := result.Metadata["stderr"].(string)
+			output = stdout
+			if stderr != "" {
+				output += "\n--- stderr ---\n" + stderr
+			}
+		}
+		if output == "" && result.Status == "error" {
+			output = result.Message
+		}
+	case ActionGitDiff:
+		ext, contentType = "patch", "text/x-patch"
+		output, _ = result.Metadata["output"].(string)
+	default:
+		return
+	}
+	if strings.TrimSpace(output) == "" {
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", action.Type, time.Now().UTC().Format("20060102T150405.000Z"), ext)
+	if err := r.Attachments.AttachOutput(ctx, actx.BeadID, actx.AgentID, filename, contentType, []byte(output)); err != nil {
+		log.Printf("[Actions] Failed to attach %s output to bead %s: %v", action.Type, actx.BeadID, err)
+	}
+}
+
 func (r *Router) AutoFileParseFailure(ctx context.Context, actx ActionContext, err error, raw string) Result {
 	if r.Beads == nil {
 		return Result{ActionType: ActionCreateBead, Status: "error", Message: "bead creator not configured"}
@@ -547,37 +726,7 @@ func (r *Router) executeAction(ctx context.Context, action Action, actx ActionCo
 			Metadata:   result,
 		}
 	case ActionCreatePR:
-		if r.Git == nil {
-			return Result{ActionType: action.Type, Status: "error", Message: "git operator not configured"}
-		}
-
-		// Auto-generate title/body from bead if not provided
-		title := action.PRTitle
-		body := action.PRBody
-		if title == "" {
-			title = fmt.Sprintf("PR from bead %s", actx.BeadID)
-		}
-		if body == "" {
-			body = fmt.Sprintf("Automated pull request from bead %s\n\nAgent: %s", actx.BeadID, actx.AgentID)
-		}
-
-		// Set default base branch
-		base := action.PRBase
-		if base == "" {
-			base = "main"
-		}
-
-		result, err := r.Git.CreatePR(ctx, actx.BeadID, title, body, base, action.Branch, action.PRReviewers, false)
-		if err != nil {
-			return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
-		}
-
-		return Result{
-			ActionType: action.Type,
-			Status:     "executed",
-			Message:    fmt.Sprintf("PR created: %v", result["pr_url"]),
-			Metadata:   result,
-		}
+		return r.handleCreatePR(ctx, action, actx)
 	// Extended git operations
 	case ActionGitMerge:
 		if r.Git == nil {
@@ -748,25 +897,13 @@ func (r *Router) executeAction(ctx context.Context, action Action, actx ActionCo
 			},
 		}
 	case ActionRunTests:
-		if r.Tests == nil {
-			return Result{ActionType: action.Type, Status: "error", Message: "test runner not configured"}
-		}
-		projectPath := r.getProjectWorkDir(actx.ProjectID)
-		if projectPath == "" {
-			projectPath = "."
-		}
-
-		result, err := r.Tests.Run(ctx, projectPath, action.TestPattern, action.Framework, action.TimeoutSeconds)
-		if err != nil {
-			return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
-		}
-		return Result{
-			ActionType: action.Type,
-			Status:     "executed",
-			Message:    "tests executed",
-			Metadata:   result,
-		}
+		return r.triageTestRun(ctx, action, actx, r.runTests(ctx, action, actx))
 	case ActionRunLinter:
+		if r.Settings != nil && r.Commands != nil && len(action.Files) == 0 {
+			if command := r.Settings.LintCommand(actx.ProjectID); command != "" {
+				return r.runConfiguredCommand(ctx, actx, action.Type, command, "lint")
+			}
+		}
 		if r.Linter == nil {
 			return Result{ActionType: action.Type, Status: "error", Message: "linter not configured"}
 		}
@@ -786,6 +923,9 @@ func (r *Router) executeAction(ctx context.Context, action Action, actx ActionCo
 			Metadata:   result,
 		}
 	case ActionBuildProject:
+		if action.BuildCommand == "" && r.Settings != nil {
+			action.BuildCommand = r.Settings.BuildCommand(actx.ProjectID)
+		}
 		// Prefer the structured BuildRunner if available (returns parsed results
 		// with error_count, warnings, etc.).
 		if r.Builder != nil {
@@ -869,6 +1009,19 @@ func (r *Router) executeAction(ctx context.Context, action Action, actx ActionCo
 			Message:    "bead closed",
 			Metadata:   map[string]interface{}{"bead_id": action.BeadID},
 		}
+	case ActionRelateBeads:
+		if r.Relations == nil {
+			return Result{ActionType: action.Type, Status: "error", Message: "bead relations not configured"}
+		}
+		if err := r.Relations.RelateBeads(action.BeadID, action.Relation, action.TargetBeadID, actx.AgentID); err != nil {
+			return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
+		}
+		return Result{
+			ActionType: action.Type,
+			Status:     "executed",
+			Message:    fmt.Sprintf("bead %s marked %s %s", action.BeadID, action.Relation, action.TargetBeadID),
+			Metadata:   map[string]interface{}{"bead_id": action.BeadID, "relation": action.Relation, "target_bead_id": action.TargetBeadID},
+		}
 	case ActionEscalateCEO:
 		if r.Escalator == nil {
 			return Result{ActionType: action.Type, Status: "error", Message: "escalator not configured"}
@@ -1183,6 +1336,12 @@ func (r *Router) executeAction(ctx context.Context, action Action, actx ActionCo
 		return r.handleReadBeadContext(ctx, action, actx)
 
 	default:
+		if h, ok := defaultRegistry.Get(action.Type); ok {
+			if err := h.Validate(action); err != nil {
+				return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
+			}
+			return h.Execute(ctx, action, actx)
+		}
 		return Result{ActionType: action.Type, Status: "error", Message: "unsupported action"}
 	}
 }
@@ -1220,6 +1379,141 @@ func truncateContent(s string, maxLen int) string {
 
 // PR Review Action Handlers
 
+// defaultReviewPersona is the persona of the review bead create_pr spawns
+// when the action does not name one.
+const defaultReviewPersona = "code-reviewer"
+
+// handleCreatePR pushes the bead's branch, opens a pull request for it and
+// records the PR in the bead's context. With request_review it also files a
+// review bead, which a reviewer agent works by fetching the PR's diff and
+// submitting a review.
+func (r *Router) handleCreatePR(ctx context.Context, action Action, actx ActionContext) Result {
+	if r.Git == nil {
+		return Result{ActionType: action.Type, Status: "error", Message: "git operator not configured"}
+	}
+
+	branch := action.Branch
+	if branch == "" {
+		status, err := r.Git.GetStatus(ctx)
+		if err != nil {
+			return Result{ActionType: action.Type, Status: "error", Message: fmt.Sprintf("failed to determine current branch: %v", err)}
+		}
+		branch, _ = status["branch"].(string)
+	}
+	if branch == "" || branch == "main" || branch == "master" {
+		return Result{ActionType: action.Type, Status: "error", Message: fmt.Sprintf("create_pr needs a feature branch, not %q", branch)}
+	}
+
+	if _, err := r.Git.Push(ctx, actx.BeadID, branch, true); err != nil {
+		return Result{ActionType: action.Type, Status: "error", Message: fmt.Sprintf("failed to push %s: %v", branch, err)}
+	}
+
+	// Auto-generate title/body from bead if not provided
+	title := action.PRTitle
+	body := action.PRBody
+	if title == "" {
+		title = fmt.Sprintf("PR from bead %s", actx.BeadID)
+	}
+	if body == "" {
+		body = fmt.Sprintf("Automated pull request from bead %s\n\nAgent: %s", actx.BeadID, actx.AgentID)
+	}
+
+	// Set default base branch
+	base := action.PRBase
+	if base == "" {
+		base = "main"
+	}
+
+	result, err := r.Git.CreatePR(ctx, actx.BeadID, title, body, base, branch, action.PRReviewers, false)
+	if err != nil {
+		return Result{ActionType: action.Type, Status: "error", Message: err.Error()}
+	}
+	r.recordPR(actx.BeadID, branch, result)
+
+	message := fmt.Sprintf("PR created: %v", result["pr_url"])
+	if action.RequestReview {
+		reviewBead, err := r.createReviewBead(actx, action, title, result)
+		if err != nil {
+			message += fmt.Sprintf(" (review bead not created: %v)", err)
+		} else {
+			result["review_bead_id"] = reviewBead.ID
+			message += fmt.Sprintf(", review requested in %s", reviewBead.ID)
+		}
+	}
+
+	return Result{
+		ActionType: action.Type,
+		Status:     "executed",
+		Message:    message,
+		Metadata:   result,
+	}
+}
+
+// recordPR stores a pull request's URL, number and branch in the context of
+// the bead it was opened for.
+func (r *Router) recordPR(beadID, branch string, pr map[string]interface{}) {
+	if r.BeadUpdater == nil || beadID == "" {
+		return
+	}
+	prContext := map[string]string{"pr_branch": branch}
+	if url, ok := pr["pr_url"].(string); ok && url != "" {
+		prContext["pr_url"] = url
+	}
+	if number, ok := pr["pr_number"].(int); ok && number > 0 {
+		prContext["pr_number"] = strconv.Itoa(number)
+	}
+	if _, err := r.BeadUpdater.UpdateBead(beadID, map[string]interface{}{"context": prContext}); err != nil {
+		log.Printf("[CreatePR] Failed to record PR on bead %s: %v", beadID, err)
+	}
+}
+
+// createReviewBead files a bead asking a reviewer agent to review a pull
+// request.
+func (r *Router) createReviewBead(actx ActionContext, action Action, title string, pr map[string]interface{}) (*models.Bead, error) {
+	if r.Beads == nil {
+		return nil, fmt.Errorf("bead creator not configured")
+	}
+	number, _ := pr["pr_number"].(int)
+	if number <= 0 {
+		return nil, fmt.Errorf("PR number unknown")
+	}
+	persona := action.ReviewPersona
+	if persona == "" {
+		persona = defaultReviewPersona
+	}
+
+	description := fmt.Sprintf(`Review pull request #%d (%v), opened for bead %s.
+
+1. Fetch the PR and its diff: {"type": "fetch_pr", "pr_number": %d, "include_files": true, "include_diff": true}
+2. Check the change for correctness, tests, security and style. Use add_pr_comment for inline remarks.
+3. Submit the review with submit_review, review_event APPROVE or REQUEST_CHANGES, and a comment_body summarizing it.
+4. Close this bead.`, number, pr["pr_url"], actx.BeadID, number)
+
+	bead, err := r.Beads.CreateBead(fmt.Sprintf("Review PR #%d: %s", number, title), description, models.BeadPriorityP2, "task", actx.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if r.BeadUpdater != nil {
+		reviewContext := map[string]string{
+			"review_for":       actx.BeadID,
+			"pr_number":        strconv.Itoa(number),
+			"requires_persona": persona,
+		}
+		if url, ok := pr["pr_url"].(string); ok && url != "" {
+			reviewContext["pr_url"] = url
+		}
+		if _, err := r.BeadUpdater.UpdateBead(bead.ID, map[string]interface{}{"context": reviewContext}); err != nil {
+			log.Printf("[CreatePR] Failed to set context of review bead %s: %v", bead.ID, err)
+		}
+		if actx.BeadID != "" {
+			if _, err := r.BeadUpdater.UpdateBead(actx.BeadID, map[string]interface{}{"context": map[string]string{"pr_review_bead": bead.ID}}); err != nil {
+				log.Printf("[CreatePR] Failed to link review bead %s to %s: %v", bead.ID, actx.BeadID, err)
+			}
+		}
+	}
+	return bead, nil
+}
+
 func (r *Router) handleFetchPR(ctx context.Context, action Action, actx ActionContext) Result {
 	if action.PRNumber == 0 {
 		return Result{ActionType: action.Type, Status: "error", Message: "pr_number is required"}
@@ -1834,4 +2128,5 @@ func (r *Router) autoPushAndPR(ctx context.Context, actx ActionContext, action A
 		return
 	}
 	log.Printf("[AutoPR] Created PR for bead %s: %v", actx.BeadID, prResult)
+	r.recordPR(actx.BeadID, branch, prResult)
 }
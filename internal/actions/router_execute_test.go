@@ -5,10 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jordanhubbard/loom/internal/executor"
 	"github.com/jordanhubbard/loom/internal/files"
+	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/pkg/models"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // --- Mock types ---
@@ -26,6 +31,15 @@ func (m *mockBeadCloser) CloseBead(beadID, reason string) error {
 	return nil
 }
 
+type mockBeadRelater struct {
+	relations []string
+}
+
+func (m *mockBeadRelater) RelateBeads(beadID, relation, targetID, actor string) error {
+	m.relations = append(m.relations, beadID+" "+relation+" "+targetID+" by "+actor)
+	return nil
+}
+
 type mockBeadEscalator struct {
 	escalatedIDs []string
 	escalateErr  error
@@ -247,6 +261,27 @@ func (m *mockActionLogger) LogAction(ctx context.Context, actx ActionContext, ac
 	m.logged = append(m.logged, action)
 }
 
+type mockActionMetrics struct {
+	observed []string
+}
+
+func (m *mockActionMetrics) ObserveAction(projectID, actionType, status string, duration time.Duration) {
+	m.observed = append(m.observed, projectID+"/"+actionType+"/"+status)
+}
+
+type mockOutputAttacher struct {
+	filenames    []string
+	contentTypes []string
+	data         []string
+}
+
+func (m *mockOutputAttacher) AttachOutput(ctx context.Context, beadID, agentID, filename, contentType string, data []byte) error {
+	m.filenames = append(m.filenames, filename)
+	m.contentTypes = append(m.contentTypes, contentType)
+	m.data = append(m.data, string(data))
+	return nil
+}
+
 // --- Tests ---
 
 func TestRouter_Execute_NilEnvelope(t *testing.T) {
@@ -275,6 +310,88 @@ func TestRouter_Execute_WithLogger(t *testing.T) {
 	}
 }
 
+func TestRouter_Execute_WithMetrics(t *testing.T) {
+	metrics := &mockActionMetrics{}
+	r := &Router{Metrics: metrics}
+	env := &ActionEnvelope{
+		Actions: []Action{{Type: ActionDone, Reason: "test"}, {Type: ActionReadCode, Path: "x.go"}},
+	}
+	if _, err := r.Execute(context.Background(), env, ActionContext{ProjectID: "proj-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"proj-1/done/executed", "proj-1/read_code/error"}
+	if len(metrics.observed) != len(want) {
+		t.Fatalf("observed %v, want %v", metrics.observed, want)
+	}
+	for i := range want {
+		if metrics.observed[i] != want[i] {
+			t.Errorf("observed[%d] = %q, want %q", i, metrics.observed[i], want[i])
+		}
+	}
+}
+
+func TestRouter_Execute_Traced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevTracer := telemetry.Tracer
+	telemetry.Tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	defer func() { telemetry.Tracer = prevTracer }()
+
+	r := &Router{}
+	env := &ActionEnvelope{
+		Actions: []Action{{Type: ActionDone, Reason: "test"}, {Type: ActionReadCode, Path: "x.go"}},
+	}
+	if _, err := r.Execute(context.Background(), env, ActionContext{ProjectID: "proj-1", BeadID: "bead-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	if spans[0].Name() != "action.done" || spans[0].Status().Code != codes.Unset {
+		t.Errorf("span 0 = %s (%v), want action.done without error", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "action.read_code" || spans[1].Status().Code != codes.Error {
+		t.Errorf("span 1 = %s (%v), want action.read_code with error", spans[1].Name(), spans[1].Status())
+	}
+}
+
+func TestRouter_Execute_AttachesOutput(t *testing.T) {
+	attacher := &mockOutputAttacher{}
+	r := &Router{
+		Commands:    &mockCommandExecutor{result: &executor.ExecuteCommandResult{ID: "cmd-1", Stdout: "built", Stderr: "warning: x"}},
+		Git:         &mockGitOperator{diffOut: "diff --git a/x b/x"},
+		Attachments: attacher,
+	}
+	env := &ActionEnvelope{Actions: []Action{
+		{Type: ActionRunCommand, Command: "make"},
+		{Type: ActionGitDiff},
+		{Type: ActionDone},
+	}}
+
+	if _, err := r.Execute(context.Background(), env, ActionContext{AgentID: "a", BeadID: "b", ProjectID: "p"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attacher.filenames) != 2 {
+		t.Fatalf("expected 2 attachments, got %v", attacher.filenames)
+	}
+	if attacher.contentTypes[0] != "text/plain" || attacher.data[0] != "built\n--- stderr ---\nwarning: x" {
+		t.Errorf("unexpected command attachment: %s %q", attacher.contentTypes[0], attacher.data[0])
+	}
+	if attacher.contentTypes[1] != "text/x-patch" || attacher.data[1] != "diff --git a/x b/x" {
+		t.Errorf("unexpected diff attachment: %s %q", attacher.contentTypes[1], attacher.data[1])
+	}
+
+	// Without a bead there is nothing to attach to.
+	attacher.filenames = nil
+	if _, err := r.Execute(context.Background(), env, ActionContext{AgentID: "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attacher.filenames) != 0 {
+		t.Errorf("expected no attachments without a bead, got %v", attacher.filenames)
+	}
+}
+
 func TestRouter_Execute_WithProjectID(t *testing.T) {
 	r := &Router{}
 	env := &ActionEnvelope{
@@ -605,7 +722,7 @@ func TestRouter_CreatePR(t *testing.T) {
 }
 
 func TestRouter_CreatePR_Defaults(t *testing.T) {
-	git := &mockGitOperator{}
+	git := &mockGitOperator{result: map[string]interface{}{"branch": "agent/bead-1", "pr_url": "https://github.com/test/pr/1"}}
 	r := &Router{Git: git}
 	result := r.executeAction(context.Background(), Action{Type: ActionCreatePR}, ActionContext{BeadID: "bead-1", AgentID: "agent-1"})
 	if result.Status != "executed" {
@@ -814,6 +931,26 @@ func TestRouter_CloseBead_NoCloser(t *testing.T) {
 	}
 }
 
+func TestRouter_RelateBeads(t *testing.T) {
+	rel := &mockBeadRelater{}
+	r := &Router{Relations: rel}
+	action := Action{Type: ActionRelateBeads, BeadID: "bead-2", Relation: "duplicate_of", TargetBeadID: "bead-1"}
+	if err := validateAction(action); err != nil {
+		t.Fatalf("validateAction() error = %v", err)
+	}
+	result := r.executeAction(context.Background(), action, ActionContext{AgentID: "agent-1"})
+	if result.Status != "executed" {
+		t.Errorf("expected executed, got %s: %s", result.Status, result.Message)
+	}
+	if len(rel.relations) != 1 || rel.relations[0] != "bead-2 duplicate_of bead-1 by agent-1" {
+		t.Errorf("relations = %v", rel.relations)
+	}
+
+	if err := validateAction(Action{Type: ActionRelateBeads, BeadID: "bead-2"}); err == nil {
+		t.Error("expected validation error without relation and target")
+	}
+}
+
 func TestRouter_EscalateCEO(t *testing.T) {
 	esc := &mockBeadEscalator{}
 	r := &Router{Escalator: esc}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/jordanhubbard/loom/internal/executor"
+	"github.com/jordanhubbard/loom/pkg/models"
 )
 
 func TestHandleFetchPR_NoPRNumber(t *testing.T) {
@@ -249,3 +250,82 @@ type mockCommandExecutorFunc struct {
 func (m *mockCommandExecutorFunc) ExecuteCommand(ctx context.Context, req executor.ExecuteCommandRequest) (*executor.ExecuteCommandResult, error) {
 	return m.fn(ctx, req)
 }
+
+type recordingGit struct {
+	mockGitOperator
+	pushed []string
+}
+
+func (g *recordingGit) Push(ctx context.Context, beadID, branch string, setUpstream bool) (map[string]interface{}, error) {
+	g.pushed = append(g.pushed, branch)
+	return map[string]interface{}{"branch": branch}, nil
+}
+
+type recordingBeadUpdater struct {
+	contexts map[string]map[string]string
+}
+
+func (u *recordingBeadUpdater) UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error) {
+	if u.contexts == nil {
+		u.contexts = make(map[string]map[string]string)
+	}
+	if ctx, ok := updates["context"].(map[string]string); ok {
+		if u.contexts[beadID] == nil {
+			u.contexts[beadID] = make(map[string]string)
+		}
+		for k, v := range ctx {
+			u.contexts[beadID][k] = v
+		}
+	}
+	return &models.Bead{ID: beadID}, nil
+}
+
+func TestHandleCreatePR_PushesRecordsAndRequestsReview(t *testing.T) {
+	git := &recordingGit{mockGitOperator: mockGitOperator{result: map[string]interface{}{
+		"branch":    "agent/bead-1",
+		"pr_url":    "https://github.com/o/r/pull/7",
+		"pr_number": 7,
+	}}}
+	beads := &mockBeadCreator{}
+	updater := &recordingBeadUpdater{}
+	r := &Router{Git: git, Beads: beads, BeadUpdater: updater}
+
+	result := r.handleCreatePR(context.Background(), Action{Type: ActionCreatePR, PRTitle: "Fix", RequestReview: true},
+		ActionContext{BeadID: "bead-1", AgentID: "agent-1", ProjectID: "proj"})
+	if result.Status != "executed" {
+		t.Fatalf("expected executed, got %s: %s", result.Status, result.Message)
+	}
+	if len(git.pushed) != 1 || git.pushed[0] != "agent/bead-1" {
+		t.Errorf("expected the current branch to be pushed, got %v", git.pushed)
+	}
+	if got := updater.contexts["bead-1"]; got["pr_url"] != "https://github.com/o/r/pull/7" || got["pr_number"] != "7" {
+		t.Errorf("PR not recorded on bead: %v", got)
+	}
+
+	if len(beads.createdBeads) != 1 {
+		t.Fatalf("expected one review bead, got %d", len(beads.createdBeads))
+	}
+	review := beads.createdBeads[0]
+	if review.ProjectID != "proj" || !containsStr(review.Description, `"pr_number": 7`) {
+		t.Errorf("unexpected review bead: %+v", review)
+	}
+	reviewCtx := updater.contexts[review.ID]
+	if reviewCtx["requires_persona"] != defaultReviewPersona || reviewCtx["review_for"] != "bead-1" {
+		t.Errorf("unexpected review bead context: %v", reviewCtx)
+	}
+	if updater.contexts["bead-1"]["pr_review_bead"] != review.ID {
+		t.Errorf("review bead not linked to source bead")
+	}
+}
+
+func TestHandleCreatePR_RefusesMainBranch(t *testing.T) {
+	git := &recordingGit{mockGitOperator: mockGitOperator{result: map[string]interface{}{"branch": "main"}}}
+	r := &Router{Git: git}
+	result := r.handleCreatePR(context.Background(), Action{Type: ActionCreatePR}, ActionContext{BeadID: "bead-1"})
+	if result.Status != "error" {
+		t.Errorf("expected error, got %s", result.Status)
+	}
+	if len(git.pushed) != 0 {
+		t.Errorf("main should not be pushed, got %v", git.pushed)
+	}
+}
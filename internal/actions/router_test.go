@@ -615,3 +615,53 @@ func TestRouter_ExecuteAction_BuildProject_NoBuilder(t *testing.T) {
 		t.Errorf("Expected error message about builder, got: %s", result.Message)
 	}
 }
+
+// fakeProjectSettings configures commands for project proj-789 only.
+type fakeProjectSettings map[string]string
+
+func (f fakeProjectSettings) command(projectID, what string) string {
+	if projectID != "proj-789" {
+		return ""
+	}
+	return f[what]
+}
+
+func (f fakeProjectSettings) BuildCommand(id string) string { return f.command(id, "build") }
+func (f fakeProjectSettings) TestCommand(id string) string  { return f.command(id, "test") }
+func (f fakeProjectSettings) LintCommand(id string) string  { return f.command(id, "lint") }
+
+func TestRouter_ExecuteAction_ProjectCommands(t *testing.T) {
+	cmds := &mockCommandExecutor{}
+	var gotBuild string
+	router := &Router{
+		Commands: cmds,
+		Tests:    &mockTestRunner{},
+		Builder: &mockBuildRunner{runFunc: func(ctx context.Context, projectPath, buildTarget, buildCommand, framework string, timeoutSeconds int) (map[string]interface{}, error) {
+			gotBuild = buildCommand
+			return map[string]interface{}{"success": true}, nil
+		}},
+		Settings: fakeProjectSettings{"build": "make all", "test": "make check", "lint": "make lint"},
+	}
+	actx := ActionContext{AgentID: "agent-123", BeadID: "bead-456", ProjectID: "proj-789"}
+
+	result := router.executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if result.Status != "executed" || cmds.lastReq.Command != "make check" {
+		t.Errorf("tests: status %s, ran %q; want the configured make check", result.Status, cmds.lastReq.Command)
+	}
+	result = router.executeAction(context.Background(), Action{Type: ActionRunLinter}, actx)
+	if result.Status != "executed" || cmds.lastReq.Command != "make lint" {
+		t.Errorf("lint: status %s, ran %q; want the configured make lint", result.Status, cmds.lastReq.Command)
+	}
+	router.executeAction(context.Background(), Action{Type: ActionBuildProject}, actx)
+	if gotBuild != "make all" {
+		t.Errorf("build command = %q, want make all", gotBuild)
+	}
+
+	// A test pattern, or a project without the setting, uses the test runner.
+	cmds.lastReq.Command = ""
+	router.executeAction(context.Background(), Action{Type: ActionRunTests, TestPattern: "TestFoo"}, actx)
+	router.executeAction(context.Background(), Action{Type: ActionRunTests}, ActionContext{ProjectID: "other"})
+	if cmds.lastReq.Command != "" {
+		t.Errorf("ran %q instead of the test runner", cmds.lastReq.Command)
+	}
+}
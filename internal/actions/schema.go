@@ -37,6 +37,7 @@ const (
 	ActionResumeWorkflow = "resume_workflow"
 	ActionApproveBead    = "approve_bead"
 	ActionRejectBead     = "reject_bead"
+	ActionRelateBeads    = "relate_beads"
 
 	// Code navigation actions
 	ActionFindReferences      = "find_references"
@@ -138,6 +139,8 @@ type Action struct {
 	PRBody        string   `json:"pr_body,omitempty"`        // Pull request body
 	PRBase        string   `json:"pr_base,omitempty"`        // PR base branch (default: main)
 	PRReviewers   []string `json:"pr_reviewers,omitempty"`   // PR reviewers
+	RequestReview bool     `json:"request_review,omitempty"` // File a review bead for the new PR
+	ReviewPersona string   `json:"review_persona,omitempty"` // Persona of the review bead (default: code-reviewer)
 
 	// Extended git fields
 	SourceBranch string   `json:"source_branch,omitempty"` // Branch to merge from or diff against
@@ -211,10 +214,17 @@ type Action struct {
 	TaskPriority    int    `json:"task_priority,omitempty"`    // Priority for delegated task (0-4)
 	ParentBeadID    string `json:"parent_bead_id,omitempty"`   // Parent bead that created this delegation
 
+	// Bead relation fields
+	Relation     string `json:"relation,omitempty"`       // Relation for relate_beads (duplicate_of, related_to, parent, child, blocked_by, blocks)
+	TargetBeadID string `json:"target_bead_id,omitempty"` // Bead the relation points at
+
 	Bead *BeadPayload `json:"bead,omitempty"`
 
 	Reason     string `json:"reason,omitempty"` // Reason for bead operations or phase transitions
 	ReturnedTo string `json:"returned_to,omitempty"`
+
+	// Params carries the fields of registered (non-built-in) action types.
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 type BeadPayload struct {
@@ -431,6 +441,7 @@ func validateAction(action Action) error {
 	case ActionCreatePR:
 		// pr_title and pr_body optional (auto-generated from bead)
 		// pr_base optional (defaults to main)
+		// branch optional (uses current branch, which is pushed first)
 	case ActionGitMerge:
 		if action.SourceBranch == "" {
 			return errors.New("git_merge requires source_branch")
@@ -502,6 +513,10 @@ func validateAction(action Action) error {
 		if action.Reason == "" {
 			return errors.New("reject_bead requires reason")
 		}
+	case ActionRelateBeads:
+		if action.BeadID == "" || action.Relation == "" || action.TargetBeadID == "" {
+			return errors.New("relate_beads requires bead_id, relation and target_bead_id")
+		}
 	case ActionStartDev:
 		if action.Workflow == "" {
 			return errors.New("start_development requires workflow")
@@ -609,6 +624,9 @@ func validateAction(action Action) error {
 			return errors.New("generate_docs requires path")
 		}
 	default:
+		if h, ok := defaultRegistry.Get(action.Type); ok {
+			return h.Validate(action)
+		}
 		return fmt.Errorf("unknown action type: %s", action.Type)
 	}
 
@@ -0,0 +1,315 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Bead context keys written by test-failure triage.
+const (
+	TestFailuresKey      = "test_failures"
+	TestFailuresAtKey    = "test_failures_at"
+	FlakyTestsRetriedKey = "flaky_tests_retried"
+	FlakyTestBeadsKey    = "flaky_test_beads"
+	TestsPassedAtKey     = "tests_passed_at"
+)
+
+// defaultFlakyTestRetries is how often a run whose failures are all known
+// flaky tests is retried when the project doesn't say.
+const defaultFlakyTestRetries = 2
+
+// maxTriagedFailures caps the failures recorded on a bead and in a result.
+const maxTriagedFailures = 20
+
+// TestFailure is one failing test found in test output.
+type TestFailure struct {
+	Package string `json:"package,omitempty"`
+	Test    string `json:"test"`
+	Message string `json:"message,omitempty"`
+	// Flaky marks a test matching one of the project's flaky-test patterns.
+	Flaky bool `json:"flaky,omitempty"`
+}
+
+// ID names the test as package.Test, or just Test without a package.
+func (f TestFailure) ID() string {
+	if f.Package == "" {
+		return f.Test
+	}
+	return f.Package + "." + f.Test
+}
+
+var (
+	goFailRe      = regexp.MustCompile(`^(\s*)--- FAIL: (\S+)`)
+	goPkgFailRe   = regexp.MustCompile(`^(?:FAIL|ok)\s+(\S+)\s`)
+	pytestFailRe  = regexp.MustCompile(`^FAILED (\S+?)::(\S+)(?: - (.*))?$`)
+	jestFileRe    = regexp.MustCompile(`^FAIL\s+(\S+\.[jt]sx?)\b`)
+	jestFailRe    = regexp.MustCompile(`^\s*● (.+)$`)
+	goTestMessage = regexp.MustCompile(`^\s+\S+\.go:\d+: (.+)$`)
+)
+
+// ParseTestFailures extracts the failing tests from go test, pytest or jest
+// output. Go parent tests that fail only through their subtests are left
+// out.
+func ParseTestFailures(output string) []TestFailure {
+	var failures []TestFailure
+	var pending []int // Go failures waiting for their package's FAIL line
+	jestFile := ""
+	current := -1
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if m := goFailRe.FindStringSubmatch(line); m != nil {
+			failures = append(failures, TestFailure{Test: m[2]})
+			current = len(failures) - 1
+			pending = append(pending, current)
+			continue
+		}
+		if m := jestFileRe.FindStringSubmatch(line); m != nil {
+			jestFile, current = m[1], -1
+			continue
+		}
+		if m := goPkgFailRe.FindStringSubmatch(line); m != nil {
+			for _, i := range pending {
+				failures[i].Package = m[1]
+			}
+			pending, current = nil, -1
+			continue
+		}
+		if m := pytestFailRe.FindStringSubmatch(line); m != nil {
+			failures = append(failures, TestFailure{Package: m[1], Test: m[2], Message: strings.TrimSpace(m[3])})
+			current = -1
+			continue
+		}
+		if m := jestFailRe.FindStringSubmatch(line); m != nil && jestFile != "" {
+			failures = append(failures, TestFailure{Package: jestFile, Test: strings.TrimSpace(m[1])})
+			current = len(failures) - 1
+			continue
+		}
+		if current >= 0 && failures[current].Message == "" {
+			if m := goTestMessage.FindStringSubmatch(line); m != nil {
+				failures[current].Message = strings.TrimSpace(m[1])
+			} else if jestFile != "" && strings.TrimSpace(line) != "" {
+				failures[current].Message = strings.TrimSpace(line)
+			}
+		}
+	}
+
+	// Drop Go parents whose subtests failed.
+	leaves := failures[:0]
+	for i, f := range failures {
+		parent := false
+		for j, g := range failures {
+			if i != j && f.Package == g.Package && strings.HasPrefix(g.Test, f.Test+"/") {
+				parent = true
+				break
+			}
+		}
+		if !parent {
+			leaves = append(leaves, f)
+		}
+	}
+	return leaves
+}
+
+// matchesFlaky reports whether a failure matches one of the patterns. A
+// pattern is a glob, where * matches any run of characters, tried against
+// both the test name and package.Test.
+func matchesFlaky(f TestFailure, patterns []string) bool {
+	for _, p := range patterns {
+		re, err := regexp.Compile("^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*") + "$")
+		if err != nil {
+			continue
+		}
+		if re.MatchString(f.Test) || re.MatchString(f.ID()) {
+			return true
+		}
+	}
+	return false
+}
+
+// testsFailed reports whether a run_tests result is a test run that failed,
+// as opposed to one that passed or couldn't run at all.
+func testsFailed(res Result) bool {
+	if res.Metadata == nil {
+		return false
+	}
+	if success, ok := res.Metadata["success"].(bool); ok {
+		return !success
+	}
+	return res.Status == "error"
+}
+
+// testOutput returns the output of a run_tests result.
+func testOutput(res Result) string {
+	for _, key := range []string{"raw_output", "output"} {
+		if out, _ := res.Metadata[key].(string); out != "" {
+			return out
+		}
+	}
+	stdout, _ := res.Metadata["stdout"].(string)
+	stderr, _ := res.Metadata["stderr"].(string)
+	return stdout + "\n" + stderr
+}
+
+// triageTestRun sorts the failures of a test run. A run whose failures are
+// all known flaky tests is retried; flaky tests that fail every retry get a
+// bug bead of their own; the failures that remain are recorded on the
+// current bead and returned in the result's "failures". A full run that
+// passes is recorded on the bead as tests_passed_at.
+func (r *Router) triageTestRun(ctx context.Context, action Action, actx ActionContext, res Result) Result {
+	if !testsFailed(res) {
+		r.annotateTestPass(action, actx, res)
+		return res
+	}
+	failures := ParseTestFailures(testOutput(res))
+	if len(failures) == 0 {
+		return res
+	}
+
+	var patterns []string
+	retries := 0
+	if r.FlakyTests != nil {
+		patterns = r.FlakyTests.FlakyTestPatterns(actx.ProjectID)
+		retries = defaultFlakyTestRetries
+		if n, ok := r.FlakyTests.FlakyTestRetries(actx.ProjectID); ok {
+			retries = n
+		}
+	}
+	flaky := markFlaky(failures, patterns)
+
+	var retried []string
+	for attempt := 0; attempt < retries && flaky == len(failures); attempt++ {
+		for _, f := range failures {
+			retried = appendUnique(retried, f.ID())
+		}
+		log.Printf("[TestTriage] Retrying tests for bead %s: only flaky tests failed (%s)", actx.BeadID, strings.Join(retried, ", "))
+		res = r.runTests(ctx, action, actx)
+		if !testsFailed(res) {
+			if res.Metadata == nil {
+				res.Metadata = map[string]interface{}{}
+			}
+			res.Metadata["flaky_retried"] = retried
+			res.Message = fmt.Sprintf("%s (passed on retry %d after flaky failures: %s)", res.Message, attempt+1, strings.Join(retried, ", "))
+			r.annotateTestRun(actx, map[string]string{FlakyTestsRetriedKey: strings.Join(retried, ",")})
+			r.annotateTestPass(action, actx, res)
+			return res
+		}
+		if failures = ParseTestFailures(testOutput(res)); len(failures) == 0 {
+			return res
+		}
+		flaky = markFlaky(failures, patterns)
+	}
+
+	annotation := map[string]string{TestFailuresAtKey: time.Now().UTC().Format(time.RFC3339)}
+	if retries > 0 && flaky > 0 && flaky == len(failures) {
+		// Known flaky tests that failed every retry are broken outright;
+		// track them apart from the bead's own work.
+		var filed []string
+		for _, f := range failures {
+			if id := r.fileFlakyTestBead(actx, f, retries); id != "" {
+				filed = append(filed, id)
+			}
+		}
+		if len(filed) > 0 {
+			annotation[FlakyTestBeadsKey] = strings.Join(filed, ",")
+			res.Message = fmt.Sprintf("%s\n\nKnown flaky tests failed %d retries; tracked in %s.", res.Message, retries, strings.Join(filed, ", "))
+		}
+	}
+
+	if len(failures) > maxTriagedFailures {
+		failures = failures[:maxTriagedFailures]
+	}
+	if res.Metadata == nil {
+		res.Metadata = map[string]interface{}{}
+	}
+	res.Metadata["failures"] = failures
+	if len(retried) > 0 {
+		res.Metadata["flaky_retried"] = retried
+	}
+	if data, err := json.Marshal(failures); err == nil {
+		annotation[TestFailuresKey] = string(data)
+	}
+	r.annotateTestRun(actx, annotation)
+	return res
+}
+
+// markFlaky flags the failures matching the patterns and returns how many
+// did.
+func markFlaky(failures []TestFailure, patterns []string) int {
+	n := 0
+	for i := range failures {
+		failures[i].Flaky = matchesFlaky(failures[i], patterns)
+		if failures[i].Flaky {
+			n++
+		}
+	}
+	return n
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func (r *Router) annotateTestRun(actx ActionContext, ctxUpdates map[string]string) {
+	if r.BeadUpdater == nil || actx.BeadID == "" {
+		return
+	}
+	if _, err := r.BeadUpdater.UpdateBead(actx.BeadID, map[string]interface{}{"context": ctxUpdates}); err != nil {
+		log.Printf("[TestTriage] Failed to annotate bead %s: %v", actx.BeadID, err)
+	}
+}
+
+// annotateTestPass records a passing run on the current bead, unless it
+// only ran the tests matching a pattern or the tests couldn't run at all.
+func (r *Router) annotateTestPass(action Action, actx ActionContext, res Result) {
+	if action.TestPattern != "" || res.Metadata == nil || res.Status == "error" {
+		return
+	}
+	r.annotateTestRun(actx, map[string]string{TestsPassedAtKey: time.Now().UTC().Format(time.RFC3339)})
+}
+
+// fileFlakyTestBead files a bug bead for a flaky test that keeps failing,
+// unless one is already open, and returns its ID.
+func (r *Router) fileFlakyTestBead(actx ActionContext, f TestFailure, retries int) string {
+	if r.Beads == nil {
+		return ""
+	}
+	title := fmt.Sprintf("[flaky-test] %s keeps failing", f.ID())
+	if r.BeadLister != nil {
+		existing, err := r.BeadLister.GetBeadsByProject(actx.ProjectID)
+		if err == nil {
+			for _, b := range existing {
+				if b.Title == title && b.Status != models.BeadStatusClosed {
+					return b.ID
+				}
+			}
+		}
+	}
+
+	desc := fmt.Sprintf(`Test %s is listed as flaky for this project, but it failed all %d runs in a row while bead %s was running its tests.
+
+Package: %s
+Test: %s
+Failure: %s
+
+Find out whether the test is broken rather than flaky, and fix it or the code it covers. Remove it from the project's flaky_tests setting once it passes reliably.`,
+		f.ID(), retries+1, actx.BeadID, f.Package, f.Test, f.Message)
+	bead, err := r.Beads.CreateBead(title, desc, models.BeadPriorityP2, "bug", actx.ProjectID)
+	if err != nil {
+		log.Printf("[TestTriage] Failed to file flaky test bead for %s: %v", f.ID(), err)
+		return ""
+	}
+	return bead.ID
+}
@@ -0,0 +1,171 @@
+package actions
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const goFailureOutput = `=== RUN   TestCache
+--- FAIL: TestCache (0.00s)
+    --- FAIL: TestCache/evict (0.00s)
+        cache_test.go:42: expected 2 entries, got 3
+FAIL
+FAIL	example.com/app/cache	0.012s
+--- FAIL: TestDial (1.00s)
+    net_test.go:17: dial tcp: connection refused
+FAIL
+FAIL	example.com/app/net	1.020s
+ok  	example.com/app/util	0.003s
+`
+
+func TestParseTestFailures_Go(t *testing.T) {
+	failures := ParseTestFailures(goFailureOutput)
+	if len(failures) != 2 {
+		t.Fatalf("got %d failures, want 2: %+v", len(failures), failures)
+	}
+	if f := failures[0]; f.ID() != "example.com/app/cache.TestCache/evict" || f.Message != "expected 2 entries, got 3" {
+		t.Errorf("failures[0] = %+v", f)
+	}
+	if f := failures[1]; f.ID() != "example.com/app/net.TestDial" || f.Message != "dial tcp: connection refused" {
+		t.Errorf("failures[1] = %+v", f)
+	}
+}
+
+func TestParseTestFailures_PytestAndJest(t *testing.T) {
+	pytest := "FAILED tests/test_api.py::test_login - AssertionError: 401 != 200\n"
+	f := ParseTestFailures(pytest)
+	if len(f) != 1 || f[0].Package != "tests/test_api.py" || f[0].Test != "test_login" || f[0].Message != "AssertionError: 401 != 200" {
+		t.Errorf("pytest failures = %+v", f)
+	}
+
+	jest := "FAIL src/App.test.js (2.1 s)\n  ● App › renders header\n\n    expect(received).toBe(expected)\n"
+	f = ParseTestFailures(jest)
+	if len(f) != 1 || f[0].Package != "src/App.test.js" || f[0].Test != "App › renders header" || f[0].Message != "expect(received).toBe(expected)" {
+		t.Errorf("jest failures = %+v", f)
+	}
+}
+
+type fakeFlakySettings struct {
+	patterns []string
+	retries  int
+}
+
+func (f fakeFlakySettings) FlakyTestPatterns(string) []string { return f.patterns }
+
+func (f fakeFlakySettings) FlakyTestRetries(string) (int, bool) { return f.retries, true }
+
+// sequenceTestRunner returns the given outputs in turn; an empty output is
+// a passing run.
+type sequenceTestRunner struct {
+	outputs []string
+	runs    int
+}
+
+func (s *sequenceTestRunner) Run(context.Context, string, string, string, int) (map[string]interface{}, error) {
+	out := s.outputs[min(s.runs, len(s.outputs)-1)]
+	s.runs++
+	return map[string]interface{}{"success": out == "", "raw_output": out}, nil
+}
+
+func TestTriageTestRun_RetriesFlakyTests(t *testing.T) {
+	runner := &sequenceTestRunner{outputs: []string{goFailureOutput, ""}}
+	updater := &recordingBeadUpdater{}
+	router := &Router{
+		Tests:       runner,
+		FlakyTests:  fakeFlakySettings{patterns: []string{"TestCache/*", "*/net.TestDial"}, retries: 2},
+		BeadUpdater: updater,
+	}
+	actx := ActionContext{AgentID: "agent-1", BeadID: "bead-1", ProjectID: "proj-1"}
+
+	res := router.executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if runner.runs != 2 || testsFailed(res) {
+		t.Fatalf("runs = %d, result = %+v; want a passing retry", runner.runs, res)
+	}
+	if got := updater.contexts["bead-1"][FlakyTestsRetriedKey]; got != "example.com/app/cache.TestCache/evict,example.com/app/net.TestDial" {
+		t.Errorf("flaky_tests_retried = %q", got)
+	}
+}
+
+func TestTriageTestRun_FilesBeadForPersistentFlakyTest(t *testing.T) {
+	runner := &sequenceTestRunner{outputs: []string{goFailureOutput}}
+	beads := &mockBeadCreator{}
+	updater := &recordingBeadUpdater{}
+	router := &Router{
+		Tests:       runner,
+		Beads:       beads,
+		FlakyTests:  fakeFlakySettings{patterns: []string{"TestCache*", "TestDial"}, retries: 1},
+		BeadUpdater: updater,
+	}
+	actx := ActionContext{AgentID: "agent-1", BeadID: "bead-1", ProjectID: "proj-1"}
+
+	res := router.executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if runner.runs != 2 {
+		t.Errorf("runs = %d, want 1 retry", runner.runs)
+	}
+	if len(beads.createdBeads) != 2 || beads.createdBeads[1].Title != "[flaky-test] example.com/app/net.TestDial keeps failing" || beads.createdBeads[1].Type != "bug" {
+		t.Fatalf("created beads = %+v", beads.createdBeads)
+	}
+	if got := updater.contexts["bead-1"][FlakyTestBeadsKey]; got != "bead-child-1,bead-child-2" {
+		t.Errorf("flaky_test_beads = %q", got)
+	}
+	if !strings.Contains(res.Message, "tracked in bead-child-1, bead-child-2") {
+		t.Errorf("message = %q", res.Message)
+	}
+}
+
+func TestTriageTestRun_AnnotatesRealFailures(t *testing.T) {
+	runner := &sequenceTestRunner{outputs: []string{goFailureOutput}}
+	updater := &recordingBeadUpdater{}
+	router := &Router{
+		Tests:       runner,
+		Beads:       &mockBeadCreator{},
+		FlakyTests:  fakeFlakySettings{patterns: []string{"TestDial"}, retries: 3},
+		BeadUpdater: updater,
+	}
+	actx := ActionContext{AgentID: "agent-1", BeadID: "bead-1", ProjectID: "proj-1"}
+
+	res := router.executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if runner.runs != 1 {
+		t.Errorf("runs = %d; a real failure should not be retried", runner.runs)
+	}
+	failures, _ := res.Metadata["failures"].([]TestFailure)
+	if len(failures) != 2 || failures[0].Flaky || !failures[1].Flaky {
+		t.Errorf("failures = %+v", failures)
+	}
+	if got := updater.contexts["bead-1"][TestFailuresKey]; !strings.Contains(got, `"test":"TestCache/evict"`) {
+		t.Errorf("test_failures = %q", got)
+	}
+
+	msg := FormatResultsAsUserMessage([]Result{res})
+	if !strings.Contains(msg, "- example.com/app/net.TestDial: dial tcp: connection refused (known flaky)") {
+		t.Errorf("feedback should list the failing tests:\n%s", msg)
+	}
+}
+
+func TestTriageTestRun_PassingRunUntouched(t *testing.T) {
+	router := &Router{Tests: &sequenceTestRunner{outputs: []string{""}}, FlakyTests: fakeFlakySettings{}}
+	res := router.executeAction(context.Background(), Action{Type: ActionRunTests}, ActionContext{ProjectID: "p"})
+	if _, ok := res.Metadata["failures"]; ok || res.Message != "tests executed" {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestTriageTestRun_RecordsPass(t *testing.T) {
+	updater := &recordingBeadUpdater{}
+	router := &Router{Tests: &sequenceTestRunner{outputs: []string{"", ""}}, BeadUpdater: updater}
+	actx := ActionContext{BeadID: "bead-1", ProjectID: "p"}
+
+	router.executeAction(context.Background(), Action{Type: ActionRunTests, TestPattern: "TestCache"}, actx)
+	if _, ok := updater.contexts["bead-1"][TestsPassedAtKey]; ok {
+		t.Error("a run of only some tests was recorded as a pass")
+	}
+	(&Router{BeadUpdater: updater}).executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if _, ok := updater.contexts["bead-1"][TestsPassedAtKey]; ok {
+		t.Error("tests that couldn't run were recorded as a pass")
+	}
+	router.executeAction(context.Background(), Action{Type: ActionRunTests}, actx)
+	if updater.contexts["bead-1"][TestsPassedAtKey] == "" {
+		t.Error("passing run not recorded")
+	}
+}
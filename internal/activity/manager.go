@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/eventstore"
 )
 
 const (
@@ -19,7 +20,6 @@ const (
 // Manager handles activity feed logic
 type Manager struct {
 	db               *database.Database
-	eventBus         *eventbus.EventBus
 	subscribers      map[string]chan *Activity
 	subscribersMu    sync.RWMutex
 	eventFilterSet   map[string]bool
@@ -27,19 +27,19 @@ type Manager struct {
 	aggregationMu    sync.RWMutex
 }
 
-// NewManager creates a new activity manager
-func NewManager(db *database.Database, eventBus *eventbus.EventBus) *Manager {
+// NewManager creates a new activity manager. The feed is built from the
+// event store as a durable consumer, so events stored while loom was down
+// or before the feed caught up are recorded when it starts.
+func NewManager(db *database.Database, store *eventstore.Store) *Manager {
 	m := &Manager{
 		db:               db,
-		eventBus:         eventBus,
 		subscribers:      make(map[string]chan *Activity),
 		eventFilterSet:   buildEventFilterSet(),
 		aggregationCache: make(map[string]*Activity),
 	}
 
-	// Subscribe to EventBus
-	if eventBus != nil {
-		go m.subscribeToEvents()
+	if store != nil {
+		store.Consume("activity", m.consumeEvent)
 	}
 
 	return m
@@ -53,6 +53,7 @@ func buildEventFilterSet() map[string]bool {
 		"bead.assigned":      true,
 		"bead.status_change": true,
 		"bead.completed":     true,
+		"bead.sla_breach":    true,
 
 		// Agent events
 		"agent.spawned":       true,
@@ -73,6 +74,10 @@ func buildEventFilterSet() map[string]bool {
 		"decision.created":  true,
 		"decision.resolved": true,
 
+		// Comment events
+		"comment.created": true,
+		"mention.created": true,
+
 		// Motivation events
 		"motivation.fired":    true,
 		"motivation.enabled":  true,
@@ -82,21 +87,18 @@ func buildEventFilterSet() map[string]bool {
 		"workflow.started":   true,
 		"workflow.completed": true,
 		"workflow.failed":    true,
+
+		// Cost events
+		"cost.anomaly": true,
 	}
 }
 
-// subscribeToEvents subscribes to the event bus
-func (m *Manager) subscribeToEvents() {
-	subscriber := m.eventBus.Subscribe("activity-manager", func(event *eventbus.Event) bool {
-		// Filter events worth persisting
-		return m.eventFilterSet[string(event.Type)]
-	})
-
-	for event := range subscriber.Channel {
-		if err := m.RecordActivity(event); err != nil {
-			log.Printf("Failed to record activity: %v", err)
-		}
+// consumeEvent records the stored events worth showing in the feed
+func (m *Manager) consumeEvent(event *eventbus.Event) error {
+	if !m.eventFilterSet[string(event.Type)] {
+		return nil
 	}
+	return m.RecordActivity(event)
 }
 
 // RecordActivity processes an event and records it as an activity
@@ -113,8 +115,9 @@ func (m *Manager) RecordActivity(event *eventbus.Event) error {
 
 		// Check cache first
 		if cached, exists := m.aggregationCache[activity.AggregationKey]; exists {
-			// Check if within time window
-			if time.Since(cached.Timestamp) < aggregationWindow {
+			// Check if within time window. Compare event times rather
+			// than the clock: events replayed from the store are older.
+			if activity.Timestamp.Sub(cached.Timestamp) < aggregationWindow {
 				// Update aggregation count
 				cached.AggregationCount++
 				cached.IsAggregated = true
@@ -131,7 +134,7 @@ func (m *Manager) RecordActivity(event *eventbus.Event) error {
 		}
 
 		// Check database for recent aggregatable activity
-		since := time.Now().Add(-aggregationWindow)
+		since := activity.Timestamp.Add(-aggregationWindow)
 		existing, err := m.db.GetRecentAggregatableActivity(activity.AggregationKey, since)
 		if err != nil {
 			log.Printf("Failed to check for aggregatable activity: %v", err)
@@ -210,7 +213,7 @@ func (m *Manager) eventToActivity(event *eventbus.Event) *Activity {
 
 	// Extract resource information based on event type
 	switch event.Type {
-	case "bead.created", "bead.assigned", "bead.status_change", "bead.completed":
+	case "bead.created", "bead.assigned", "bead.status_change", "bead.completed", "bead.sla_breach":
 		activity.ResourceType = "bead"
 		if beadID, ok := event.Data["bead_id"].(string); ok {
 			activity.ResourceID = beadID
@@ -267,6 +270,20 @@ func (m *Manager) eventToActivity(event *eventbus.Event) *Activity {
 		}
 		activity.Visibility = "project"
 
+	case "comment.created", "mention.created":
+		activity.ResourceType = "bead"
+		if beadID, ok := event.Data["bead_id"].(string); ok {
+			activity.ResourceID = beadID
+			activity.BeadID = beadID
+			activity.ResourceTitle = beadID
+		}
+		if authorID, ok := event.Data["author_id"].(string); ok {
+			activity.ActorID = authorID
+			activity.ActorType = "user"
+		}
+		activity.Action = extractAction(string(event.Type))
+		activity.Visibility = "project"
+
 	case "motivation.fired", "motivation.enabled", "motivation.disabled":
 		activity.ResourceType = "motivation"
 		if motivationID, ok := event.Data["motivation_id"].(string); ok {
@@ -289,6 +306,20 @@ func (m *Manager) eventToActivity(event *eventbus.Event) *Activity {
 		}
 		activity.Visibility = "project"
 
+	case "cost.anomaly":
+		if scope, ok := event.Data["scope"].(string); ok {
+			activity.ResourceType = scope
+		}
+		if scopeID, ok := event.Data["scope_id"].(string); ok {
+			activity.ResourceID = scopeID
+			activity.ResourceTitle = scopeID
+		}
+		if providerID, ok := event.Data["provider_id"].(string); ok {
+			activity.ProviderID = providerID
+		}
+		activity.Action = extractAction(string(event.Type))
+		activity.Visibility = "global"
+
 	default:
 		// Unknown event type, skip
 		return nil
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// NormalizeCapabilities lowercases and trims capability tags, dropping empty
+// entries and duplicates while keeping the caller's order.
+func NormalizeCapabilities(capabilities []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// CapabilityScore counts how many of a bead's tags the agent lists among its
+// capabilities. Matching ignores case and surrounding whitespace.
+func CapabilityScore(a *models.Agent, tags []string) int {
+	if a == nil || len(a.Capabilities) == 0 || len(tags) == 0 {
+		return 0
+	}
+	have := make(map[string]bool, len(a.Capabilities))
+	for _, c := range a.Capabilities {
+		have[strings.ToLower(strings.TrimSpace(c))] = true
+	}
+	score := 0
+	for _, t := range NormalizeCapabilities(tags) {
+		if have[t] {
+			score++
+		}
+	}
+	return score
+}
+
+// BestCapabilityMatches returns the agents whose capabilities cover the most
+// of the given tags, or nil when none covers any.
+func BestCapabilityMatches(agents []*models.Agent, tags []string) []*models.Agent {
+	var best []*models.Agent
+	bestScore := 0
+	for _, a := range agents {
+		score := CapabilityScore(a, tags)
+		switch {
+		case score == 0 || score < bestScore:
+		case score > bestScore:
+			best, bestScore = []*models.Agent{a}, score
+		default:
+			best = append(best, a)
+		}
+	}
+	return best
+}
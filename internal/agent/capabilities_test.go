@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestCapabilityScore(t *testing.T) {
+	a := &models.Agent{ID: "a1", Capabilities: []string{"go", "postgres", "backend"}}
+
+	tests := []struct {
+		tags []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"frontend"}, 0},
+		{[]string{"Go"}, 1},
+		{[]string{"go", "postgres", "go"}, 2},
+		{[]string{" backend ", "GO", "postgres", "react"}, 3},
+	}
+	for _, tt := range tests {
+		if got := CapabilityScore(a, tt.tags); got != tt.want {
+			t.Errorf("CapabilityScore(%v) = %d, want %d", tt.tags, got, tt.want)
+		}
+	}
+	if got := CapabilityScore(&models.Agent{}, []string{"go"}); got != 0 {
+		t.Errorf("agent without capabilities scored %d", got)
+	}
+}
+
+func TestBestCapabilityMatches(t *testing.T) {
+	agents := []*models.Agent{
+		{ID: "generalist"},
+		{ID: "go", Capabilities: []string{"go"}},
+		{ID: "go-pg", Capabilities: []string{"go", "postgres"}},
+		{ID: "pg-go", Capabilities: []string{"postgres", "go", "sql"}},
+	}
+
+	best := BestCapabilityMatches(agents, []string{"go", "postgres"})
+	if len(best) != 2 || best[0].ID != "go-pg" || best[1].ID != "pg-go" {
+		t.Errorf("expected [go-pg pg-go], got %v", best)
+	}
+	if best := BestCapabilityMatches(agents, []string{"rust"}); best != nil {
+		t.Errorf("expected no match, got %v", best)
+	}
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/worker"
+)
+
+// Streamed output is published at most this often, or sooner once this
+// much text has accumulated, so that subscribers see output in near real
+// time without an event per token.
+const (
+	outputFlushInterval = 250 * time.Millisecond
+	outputFlushBytes    = 2048
+)
+
+// outputStreamer turns a task's streamed model output into transient
+// agent.output events, and its action loop iterations into transient
+// agent.iteration events.
+type outputStreamer struct {
+	eb        *eventbus.EventBus
+	agentID   string
+	projectID string
+	beadID    string
+	taskID    string
+	sessionID string
+
+	mu        sync.Mutex
+	turn      int
+	seq       int
+	buf       strings.Builder
+	lastFlush time.Time
+}
+
+func newOutputStreamer(eb *eventbus.EventBus, agentID, projectID string, task *worker.Task) *outputStreamer {
+	s := &outputStreamer{
+		eb:        eb,
+		agentID:   agentID,
+		projectID: projectID,
+		beadID:    task.BeadID,
+		taskID:    task.ID,
+		lastFlush: time.Now(),
+	}
+	if task.ConversationSession != nil {
+		s.sessionID = task.ConversationSession.SessionID
+	}
+	return s
+}
+
+// write buffers a delta, flushing first if a new turn has started.
+func (s *outputStreamer) write(turn int, delta string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if turn != s.turn {
+		s.flushLocked()
+		s.turn = turn
+	}
+	s.buf.WriteString(delta)
+	if s.buf.Len() >= outputFlushBytes || time.Since(s.lastFlush) >= outputFlushInterval {
+		s.flushLocked()
+	}
+}
+
+// flush publishes any buffered output.
+func (s *outputStreamer) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+}
+
+func (s *outputStreamer) flushLocked() {
+	s.lastFlush = time.Now()
+	if s.buf.Len() == 0 {
+		return
+	}
+	s.seq++
+	_ = s.eb.Publish(&eventbus.Event{
+		Type:      eventbus.EventTypeAgentOutput,
+		Source:    "agent-manager",
+		ProjectID: s.projectID,
+		Transient: true,
+		Data: map[string]interface{}{
+			"agent_id":   s.agentID,
+			"bead_id":    s.beadID,
+			"task_id":    s.taskID,
+			"session_id": s.sessionID,
+			"turn":       s.turn,
+			"seq":        s.seq,
+			"delta":      s.buf.String(),
+		},
+	})
+	s.buf.Reset()
+}
+
+// iteration publishes the actions an iteration ran and their results. Any
+// buffered output is flushed first so subscribers see the model's text
+// before the actions it produced.
+func (s *outputStreamer) iteration(entry worker.ActionLogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+	_ = s.eb.Publish(&eventbus.Event{
+		Type:      eventbus.EventTypeAgentIteration,
+		Source:    "agent-manager",
+		ProjectID: s.projectID,
+		Transient: true,
+		Data: map[string]interface{}{
+			"agent_id":   s.agentID,
+			"bead_id":    s.beadID,
+			"task_id":    s.taskID,
+			"session_id": s.sessionID,
+			"iteration":  entry.Iteration,
+			"actions":    entry.Actions,
+			"results":    entry.Results,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/worker"
+)
+
+func TestOutputStreamer_CoalescesAndFlushesPerTurn(t *testing.T) {
+	eb := eventbus.NewEventBus()
+	defer eb.Close()
+	sub := eb.Subscribe("test", func(e *eventbus.Event) bool {
+		return e.Type == eventbus.EventTypeAgentOutput
+	})
+
+	s := newOutputStreamer(eb, "agent-1", "proj-1", &worker.Task{ID: "task-1", BeadID: "bd-1"})
+	s.write(1, "Hel")
+	s.write(1, "lo")
+	s.write(2, "next")
+	s.flush()
+
+	var got []*eventbus.Event
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case e := <-sub.Channel:
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("expected 2 output events, got %d", len(got))
+		}
+	}
+
+	if got[0].Data["delta"] != "Hello" || got[0].Data["turn"] != 1 || got[0].Data["seq"] != 1 {
+		t.Errorf("unexpected first event: %v", got[0].Data)
+	}
+	if got[1].Data["delta"] != "next" || got[1].Data["turn"] != 2 {
+		t.Errorf("unexpected second event: %v", got[1].Data)
+	}
+	if !got[0].Transient {
+		t.Error("output events should be transient")
+	}
+	if recent := eb.GetRecentEvents(10, "", string(eventbus.EventTypeAgentOutput)); len(recent) != 0 {
+		t.Errorf("transient events should not be kept in history, got %d", len(recent))
+	}
+}
+
+func TestOutputStreamer_IterationFlushesOutputFirst(t *testing.T) {
+	eb := eventbus.NewEventBus()
+	defer eb.Close()
+	sub := eb.Subscribe("test", func(e *eventbus.Event) bool {
+		return e.Type == eventbus.EventTypeAgentOutput || e.Type == eventbus.EventTypeAgentIteration
+	})
+
+	s := newOutputStreamer(eb, "agent-1", "proj-1", &worker.Task{ID: "task-1", BeadID: "bd-1"})
+	s.write(1, "reading the file")
+	s.iteration(worker.ActionLogEntry{
+		Iteration: 1,
+		Actions:   []actions.Action{{Type: actions.ActionReadFile, Path: "main.go"}},
+		Results:   []actions.Result{{ActionType: actions.ActionReadFile, Status: "executed"}},
+	})
+
+	var got []*eventbus.Event
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case e := <-sub.Channel:
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("expected 2 events, got %d", len(got))
+		}
+	}
+
+	if got[0].Type != eventbus.EventTypeAgentOutput || got[0].Data["delta"] != "reading the file" {
+		t.Errorf("expected buffered output first, got %s %v", got[0].Type, got[0].Data)
+	}
+	it := got[1]
+	if it.Type != eventbus.EventTypeAgentIteration || it.Data["agent_id"] != "agent-1" || it.Data["iteration"] != 1 {
+		t.Errorf("unexpected iteration event: %s %v", it.Type, it.Data)
+	}
+	if acts, ok := it.Data["actions"].([]actions.Action); !ok || len(acts) != 1 || acts[0].Path != "main.go" {
+		t.Errorf("unexpected actions: %v", it.Data["actions"])
+	}
+	if !it.Transient {
+		t.Error("iteration events should be transient")
+	}
+}
@@ -32,7 +32,9 @@ type WorkerManager struct {
 	analyticsLogger   *analytics.Logger
 	actionLoopEnabled bool
 	maxLoopIterations int
+	projectMaxIter    func(projectID string, fallback int) int
 	lessonsProvider   worker.LessonsProvider
+	summarizer        worker.Summarizer
 	db                *database.Database
 	mu                sync.RWMutex
 	maxAgents         int
@@ -83,12 +85,28 @@ func (m *WorkerManager) SetMaxLoopIterations(max int) {
 	m.maxLoopIterations = max
 }
 
+// SetProjectMaxLoopIterations sets the lookup of projects' own action loop
+// limits. It is given the global limit to fall back on.
+func (m *WorkerManager) SetProjectMaxLoopIterations(lookup func(projectID string, fallback int) int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projectMaxIter = lookup
+}
+
 func (m *WorkerManager) SetLessonsProvider(lp worker.LessonsProvider) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.lessonsProvider = lp
 }
 
+// SetSummarizer sets what summarizes long conversations nearing the
+// context window.
+func (m *WorkerManager) SetSummarizer(s worker.Summarizer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summarizer = s
+}
+
 func (m *WorkerManager) SetDatabase(db *database.Database) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -227,6 +245,104 @@ func (m *WorkerManager) UpdateAgentProject(id, projectID string) error {
 	return nil
 }
 
+// UpdateAgentCapabilities replaces an agent's capability tags. Tags are
+// normalized, so an empty or blank list clears them.
+func (m *WorkerManager) UpdateAgentCapabilities(id string, capabilities []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.agents[id]
+	if !ok {
+		return fmt.Errorf("agent not found: %s", id)
+	}
+
+	agent.Capabilities = NormalizeCapabilities(capabilities)
+	agent.LastActive = time.Now()
+	m.persistAgent(agent)
+
+	return nil
+}
+
+// PauseAgent stops an agent from taking new work. A task it is running is
+// cancelled, which returns the bead to the open queue for another agent.
+func (m *WorkerManager) PauseAgent(id string) (*models.Agent, error) {
+	m.mu.Lock()
+	agent, ok := m.agents[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	if cancel, running := m.activeCancels[id]; running {
+		cancel()
+		delete(m.activeCancels, id)
+	}
+	m.setHold(agent, models.AgentHoldPaused)
+	m.mu.Unlock()
+	return agent, nil
+}
+
+// DrainAgent stops an agent from taking new work but lets it finish the bead
+// it is working on; it is paused once that bead is done. An agent with
+// nothing in flight is paused straight away.
+func (m *WorkerManager) DrainAgent(id string) (*models.Agent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	if agent.Hold == models.AgentHoldPaused {
+		return agent, nil
+	}
+	hold := models.AgentHoldDraining
+	if agent.Status != "working" {
+		hold = models.AgentHoldPaused
+	}
+	m.setHold(agent, hold)
+	return agent, nil
+}
+
+// ResumeAgent lifts a pause or drain so the dispatcher can give the agent
+// work again.
+func (m *WorkerManager) ResumeAgent(id string) (*models.Agent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	agent, ok := m.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	m.setHold(agent, "")
+	return agent, nil
+}
+
+// setHold records an operator hold and announces it. Callers hold m.mu.
+func (m *WorkerManager) setHold(agent *models.Agent, hold string) {
+	oldHold := agent.Hold
+	if oldHold == hold {
+		return
+	}
+	agent.Hold = hold
+	m.persistAgent(agent)
+	log.Printf("[WorkerManager] Agent %s hold changed from %q to %q", agent.ID, oldHold, hold)
+	if m.eventBus != nil {
+		_ = m.eventBus.PublishAgentEvent(eventbus.EventTypeAgentStatusChange, agent.ID, agent.ProjectID, map[string]interface{}{
+			"status":       agent.Status,
+			"old_hold":     oldHold,
+			"hold":         hold,
+			"current_bead": agent.CurrentBead,
+		})
+	}
+}
+
+// settleDrain pauses a draining agent once it is no longer working.
+func settleDrain(agent *models.Agent) {
+	if agent.Hold == models.AgentHoldDraining && agent.Status != "working" {
+		agent.Hold = models.AgentHoldPaused
+	}
+}
+
 // UpdateAgentProvider switches the agent to a new provider and respawns its worker
 // so that the next task uses the correct LLM endpoint.
 func (m *WorkerManager) UpdateAgentProvider(id, providerID string) error {
@@ -404,6 +520,12 @@ func (m *WorkerManager) RestoreAgentWorker(ctx context.Context, agent *models.Ag
 		if existing.Persona == nil && agent.Persona != nil {
 			existing.Persona = agent.Persona
 		}
+		if existing.Capabilities == nil {
+			existing.Capabilities = agent.Capabilities
+		}
+		if existing.Hold == "" {
+			existing.Hold = agent.Hold
+		}
 
 		// Ensure worker exists for this agent with the correct provider
 		if agent.ProviderID != "" {
@@ -455,6 +577,9 @@ func (m *WorkerManager) GetIdleAgentsByProject(projectID string) []*models.Agent
 		if a.Status != "idle" && a.Status != "paused" {
 			continue
 		}
+		if a.Hold != "" {
+			continue
+		}
 		if projectID != "" && a.ProjectID != projectID {
 			continue
 		}
@@ -547,6 +672,16 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 		_ = m.UpdateAgentStatus(agentID, "idle")
 	}()
 
+	// Stream the model's output and each loop iteration's actions to event
+	// bus subscribers (the conversation and agent stream APIs and the UI)
+	// while the task runs.
+	var streamer *outputStreamer
+	if task != nil && task.OnOutput == nil && m.eventBus != nil {
+		streamer = newOutputStreamer(m.eventBus, agentID, projectID, task)
+		task.OnOutput = streamer.write
+		defer streamer.flush()
+	}
+
 	// Ensure a worker exists for this agent; auto-spawn if the agent has a
 	// provider but no worker yet (e.g. agents created without a provider that
 	// were later auto-assigned one by the dispatcher).
@@ -590,6 +725,9 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 		if maxIter <= 0 {
 			maxIter = 15
 		}
+		if m.projectMaxIter != nil {
+			maxIter = m.projectMaxIter(task.ProjectID, maxIter)
+		}
 
 		// Determine TextMode based on model capability.
 		// Frontier models (large context, strong instruction following) use full
@@ -610,6 +748,7 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 				ProjectID: task.ProjectID,
 			},
 			LessonsProvider: m.lessonsProvider,
+			Summarizer:      m.summarizer,
 			DB:              m.db,
 			TextMode:        textMode,
 			// Update LastActive after each iteration so the stuck-agent timer
@@ -622,6 +761,16 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 				m.mu.Unlock()
 			},
 		}
+		if streamer != nil {
+			loopConfig.OnIteration = streamer.iteration
+		}
+		if al := m.analyticsLogger; al != nil {
+			loopConfig.OnUsage = func(u worker.CompletionUsage) {
+				rl := u.RequestLog("agent:"+agent.Name, agent.ProviderID, loopConfig.ActionContext)
+				rl.Metadata["task_id"] = taskID
+				_ = al.LogRequest(ctx, rl)
+			}
+		}
 
 		loopResult, loopErr := workerInstance.ExecuteTaskWithLoop(ctx, task, loopConfig)
 		if loopErr != nil {
@@ -678,17 +827,19 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 			if !result.Success {
 				statusCode = 500
 			}
+			// Tokens are logged per completion (OnUsage above), so the
+			// task record carries none to avoid counting them twice.
 			_ = al.LogRequest(ctx, &analytics.RequestLog{
 				UserID:       "agent:" + agent.Name,
 				Method:       "POST",
 				Path:         "/internal/worker/execute-loop",
 				ProviderID:   agent.ProviderID,
-				TotalTokens:  int64(result.TokensUsed),
 				LatencyMs:    elapsed.Milliseconds(),
 				StatusCode:   statusCode,
 				ErrorMessage: result.Error,
 				Metadata: map[string]string{
 					"agent_id":        agent.ID,
+					"project_id":      projectID,
 					"bead_id":         beadID,
 					"task_id":         taskID,
 					"loop_iterations": fmt.Sprintf("%d", loopResult.Iterations),
@@ -740,9 +891,10 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 				StatusCode:   500,
 				ErrorMessage: err.Error(),
 				Metadata: map[string]string{
-					"agent_id": agent.ID,
-					"bead_id":  beadID,
-					"task_id":  taskID,
+					"agent_id":   agent.ID,
+					"project_id": projectID,
+					"bead_id":    beadID,
+					"task_id":    taskID,
 				},
 			})
 		}
@@ -824,9 +976,10 @@ func (m *WorkerManager) ExecuteTask(ctx context.Context, agentID string, task *w
 			StatusCode:   statusCode,
 			ErrorMessage: result.Error,
 			Metadata: map[string]string{
-				"agent_id": agent.ID,
-				"bead_id":  beadID,
-				"task_id":  taskID,
+				"agent_id":   agent.ID,
+				"project_id": projectID,
+				"bead_id":    beadID,
+				"task_id":    taskID,
 			},
 		})
 	}
@@ -914,6 +1067,7 @@ func (m *WorkerManager) UpdateAgentStatus(id, status string) error {
 	oldStatus := agent.Status
 	agent.Status = status
 	agent.LastActive = time.Now()
+	settleDrain(agent)
 	m.persistAgent(agent)
 	if m.eventBus != nil && oldStatus != status {
 		_ = m.eventBus.PublishAgentEvent(eventbus.EventTypeAgentStatusChange, agent.ID, agent.ProjectID, map[string]interface{}{
@@ -998,7 +1152,7 @@ func (m *WorkerManager) GetIdleAgents() []*models.Agent {
 
 	agents := make([]*models.Agent, 0)
 	for _, agent := range m.agents {
-		if agent.Status == "idle" {
+		if agent.Status == "idle" && agent.Hold == "" {
 			agents = append(agents, agent)
 		}
 	}
@@ -1042,8 +1196,10 @@ func (m *WorkerManager) ResetStuckAgents(maxWorkingDuration time.Duration) int {
 					delete(m.activeCancels, agent.ID)
 				}
 
+				stuckBead := agent.CurrentBead
 				agent.Status = "idle"
 				agent.CurrentBead = ""
+				settleDrain(agent)
 
 				// Persist the change if we have a persister
 				if m.agentPersister != nil {
@@ -1057,6 +1213,11 @@ func (m *WorkerManager) ResetStuckAgents(maxWorkingDuration time.Duration) int {
 						"project_id": agent.ProjectID,
 						"reason":     "stuck_timeout",
 					}
+					_ = m.eventBus.PublishAgentEvent(eventbus.EventTypeAgentStuck, agent.ID, agent.ProjectID, map[string]interface{}{
+						"project_id":     agent.ProjectID,
+						"bead_id":        stuckBead,
+						"working_for_ms": elapsed.Milliseconds(),
+					})
 					_ = m.eventBus.PublishAgentEvent("agent.reset", agent.ID, agent.ProjectID, eventData)
 				}
 
@@ -794,3 +794,77 @@ func TestWorkerManager_ExecuteTask_ErrorCases(t *testing.T) {
 		t.Error("ExecuteTask with nonexistent agent should fail")
 	}
 }
+
+func TestWorkerManager_UpdateAgentCapabilities(t *testing.T) {
+	m := setupWorkerManager(t)
+	ctx := context.Background()
+	persona := &models.Persona{Name: "test-persona"}
+
+	agent, _ := m.CreateAgent(ctx, "test-agent", "test-persona", "proj-1", "Test", persona)
+
+	if err := m.UpdateAgentCapabilities(agent.ID, []string{" Go ", "react", "go", ""}); err != nil {
+		t.Fatalf("UpdateAgentCapabilities() error = %v", err)
+	}
+	updated, _ := m.GetAgent(agent.ID)
+	if len(updated.Capabilities) != 2 || updated.Capabilities[0] != "go" || updated.Capabilities[1] != "react" {
+		t.Errorf("agent.Capabilities = %v, want [go react]", updated.Capabilities)
+	}
+
+	if err := m.UpdateAgentCapabilities("missing", []string{"go"}); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+}
+
+func TestWorkerManager_PauseDrainResume(t *testing.T) {
+	m := setupWorkerManager(t)
+	ctx := context.Background()
+	persona := &models.Persona{Name: "test-persona"}
+
+	idle, _ := m.CreateAgent(ctx, "idle-agent", "test-persona", "proj-1", "Test", persona)
+	busy, _ := m.CreateAgent(ctx, "busy-agent", "test-persona", "proj-1", "Test", persona)
+	m.UpdateAgentStatus(idle.ID, "idle")
+	m.AssignBead(busy.ID, "bead-1")
+
+	// Pausing takes the agent out of the idle pool straight away.
+	if _, err := m.PauseAgent(idle.ID); err != nil {
+		t.Fatalf("PauseAgent() error = %v", err)
+	}
+	if got := m.GetIdleAgentsByProject("proj-1"); len(got) != 0 {
+		t.Errorf("paused agent still offered for work: %v", got)
+	}
+
+	// Draining a working agent waits for its bead, then pauses it.
+	a, err := m.DrainAgent(busy.ID)
+	if err != nil {
+		t.Fatalf("DrainAgent() error = %v", err)
+	}
+	if a.Hold != models.AgentHoldDraining {
+		t.Errorf("hold = %q, want draining", a.Hold)
+	}
+	m.UpdateAgentStatus(busy.ID, "idle")
+	if a, _ := m.GetAgent(busy.ID); a.Hold != models.AgentHoldPaused {
+		t.Errorf("hold after bead finished = %q, want paused", a.Hold)
+	}
+	if got := m.GetIdleAgentsByProject("proj-1"); len(got) != 0 {
+		t.Errorf("drained agent offered for work: %v", got)
+	}
+
+	// Draining an idle agent pauses it immediately.
+	m.ResumeAgent(idle.ID)
+	if a, _ := m.DrainAgent(idle.ID); a.Hold != models.AgentHoldPaused {
+		t.Errorf("hold after draining idle agent = %q, want paused", a.Hold)
+	}
+
+	for _, id := range []string{idle.ID, busy.ID} {
+		if _, err := m.ResumeAgent(id); err != nil {
+			t.Fatalf("ResumeAgent() error = %v", err)
+		}
+	}
+	if got := m.GetIdleAgentsByProject("proj-1"); len(got) != 2 {
+		t.Errorf("expected 2 idle agents after resume, got %d", len(got))
+	}
+
+	if _, err := m.PauseAgent("missing"); err == nil {
+		t.Error("expected error for unknown agent")
+	}
+}
@@ -0,0 +1,386 @@
+// Package agentpki issues the client certificates project agent containers
+// use to authenticate to the control plane over mutual TLS.
+//
+// The control plane keeps its own certificate authority. Each project's
+// container gets a short-lived certificate naming its project, so an agent
+// can only register, heartbeat and report results as that project.
+package agentpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File names of an issued certificate inside its directory.
+const (
+	CertFile = "tls.crt"
+	KeyFile  = "tls.key"
+	CAFile   = "ca.crt"
+)
+
+// uriScheme and uriHost form the URI SAN that names a certificate's
+// project, e.g. loom://project-agent/proj-1.
+const (
+	uriScheme = "loom"
+	uriHost   = "project-agent"
+)
+
+// DefaultTTL is how long issued certificates live when no TTL is given.
+const DefaultTTL = 24 * time.Hour
+
+// Authority is the control plane's certificate authority for project agents.
+type Authority struct {
+	cert  *x509.Certificate
+	key   *ecdsa.PrivateKey
+	caPEM []byte
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu     sync.Mutex
+	server *tls.Certificate
+}
+
+// Load reads the authority from dir, creating a new CA there on first use.
+// Issued certificates live for ttl.
+func Load(dir string, ttl time.Duration) (*Authority, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	certPath := filepath.Join(dir, "ca.crt")
+	keyPath := filepath.Join(dir, "ca.key")
+
+	certPEM, err := os.ReadFile(certPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := createCA(dir, certPath, keyPath); err != nil {
+			return nil, err
+		}
+		certPEM, err = os.ReadFile(certPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read CA key: %w", err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("load CA: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("load CA: key is not ECDSA")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse CA certificate: %w", err)
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("%s is not a CA certificate", certPath)
+	}
+	return &Authority{cert: cert, key: key, caPEM: certPEM, ttl: ttl, now: time.Now}, nil
+}
+
+func createCA(dir, certPath, keyPath string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create CA directory: %w", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Loom project agent CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("create CA certificate: %w", err)
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(keyPath, keyPEM, 0o600); err != nil {
+		return err
+	}
+	return writeFileAtomic(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+// CAPEM returns the CA certificate, PEM encoded.
+func (a *Authority) CAPEM() []byte { return a.caPEM }
+
+// TTL returns how long issued certificates live.
+func (a *Authority) TTL() time.Duration { return a.ttl }
+
+// Pool returns a pool holding only the CA certificate.
+func (a *Authority) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	return pool
+}
+
+// Issue returns a client certificate and key, PEM encoded, naming
+// projectID, and when the certificate expires.
+func (a *Authority) Issue(projectID string) (certPEM, keyPEM []byte, expires time.Time, err error) {
+	if projectID == "" {
+		return nil, nil, time.Time{}, fmt.Errorf("project ID is required")
+	}
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "project-agent:" + projectID},
+		URIs:        []*url.URL{ProjectURI(projectID)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, key, err := a.sign(tmpl)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	keyPEM, err = encodeKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), keyPEM, cert.NotAfter, nil
+}
+
+// IssueTo issues a certificate for projectID and writes it, its key and
+// the CA certificate to dir, replacing any earlier ones atomically.
+func (a *Authority) IssueTo(dir, projectID string) (time.Time, error) {
+	certPEM, keyPEM, expires, err := a.Issue(projectID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return time.Time{}, err
+	}
+	// The key goes first so an agent that sees the new certificate can
+	// always load the matching key.
+	if err := writeFileAtomic(filepath.Join(dir, KeyFile), keyPEM, 0o600); err != nil {
+		return time.Time{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, CertFile), certPEM, 0o644); err != nil {
+		return time.Time{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, CAFile), a.caPEM, 0o644); err != nil {
+		return time.Time{}, err
+	}
+	return expires, nil
+}
+
+// NeedsRotation reports whether the certificate in dir is missing, was not
+// issued by this authority for projectID, or has used up two thirds of its
+// lifetime.
+func (a *Authority) NeedsRotation(dir, projectID string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, CertFile))
+	if err != nil {
+		return true
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil || cert.CheckSignatureFrom(a.cert) != nil || ProjectID(cert) != projectID {
+		return true
+	}
+	renewAt := cert.NotBefore.Add(cert.NotAfter.Sub(cert.NotBefore) * 2 / 3)
+	return !a.now().Before(renewAt)
+}
+
+// ServerTLSConfig returns the control plane's listener config. Clients may
+// connect without a certificate; those that present one must present a
+// certificate issued by this authority. The server certificate is issued
+// for names and renewed as it nears expiry.
+func (a *Authority) ServerTLSConfig(names []string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  a.Pool(),
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return a.serverCertificate(names)
+		},
+	}
+}
+
+func (a *Authority) serverCertificate(names []string) (*tls.Certificate, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server != nil && a.now().Before(a.server.Leaf.NotAfter.Add(-a.ttl/3)) {
+		return a.server, nil
+	}
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "loom"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, name)
+		}
+	}
+	cert, key, err := a.sign(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	a.server = &tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+	return a.server, nil
+}
+
+func (a *Authority) sign(tmpl *x509.Certificate) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := a.now()
+	tmpl.SerialNumber = serial
+	tmpl.NotBefore = now.Add(-5 * time.Minute) // tolerate clock skew
+	tmpl.NotAfter = now.Add(a.ttl)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// ProjectURI returns the URI SAN naming projectID.
+func ProjectURI(projectID string) *url.URL {
+	return &url.URL{Scheme: uriScheme, Host: uriHost, Path: "/" + projectID}
+}
+
+// ProjectID returns the project a client certificate was issued for, or ""
+// when it names none. It does not verify the certificate; callers take it
+// from a chain the TLS handshake already verified.
+func ProjectID(cert *x509.Certificate) string {
+	for _, u := range cert.URIs {
+		if u.Scheme == uriScheme && u.Host == uriHost {
+			return strings.TrimPrefix(u.Path, "/")
+		}
+	}
+	return ""
+}
+
+// ClientTLSConfig returns the config a project agent dials the control
+// plane with. The certificate and key are re-read whenever the certificate
+// file changes, so rotated certificates take effect without a restart.
+func ClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("%s holds no certificates", caFile)
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.certificate(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		},
+	}, nil
+}
+
+// certReloader caches a key pair until its certificate file changes.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("stat client certificate: %w", err)
+	}
+	if r.cert != nil && info.ModTime().Equal(r.modTime) {
+		return r.cert, nil
+	}
+	pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		// Mid-rotation the files may briefly disagree; keep the old pair.
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("load client certificate: %w", err)
+	}
+	r.cert, r.modTime = &pair, info.ModTime()
+	return r.cert, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func newSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
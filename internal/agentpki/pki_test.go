@@ -0,0 +1,171 @@
+package agentpki
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestAuthority(t *testing.T) *Authority {
+	t.Helper()
+	a, err := Load(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return a
+}
+
+func TestLoadReusesCA(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Load(dir, 0)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	second, err := Load(dir, 0)
+	if err != nil {
+		t.Fatalf("Load again: %v", err)
+	}
+	if string(first.CAPEM()) != string(second.CAPEM()) {
+		t.Error("a second Load created a new CA")
+	}
+	if first.TTL() != DefaultTTL {
+		t.Errorf("TTL = %s, want %s", first.TTL(), DefaultTTL)
+	}
+}
+
+// serve starts a TLS server that echoes the project of the verified client
+// certificate, or "-" when the client sent none.
+func serve(t *testing.T, a *Authority) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 {
+			fmt.Fprint(w, "-")
+			return
+		}
+		fmt.Fprint(w, ProjectID(r.TLS.VerifiedChains[0][0]))
+	}))
+	// Wrap the listener directly: StartTLS would install its own
+	// certificate ahead of the authority's.
+	srv.Listener = tls.NewListener(srv.Listener, a.ServerTLSConfig([]string{"127.0.0.1"}))
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(client *http.Client, url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestMutualTLS(t *testing.T) {
+	a := newTestAuthority(t)
+	srv := serve(t, a)
+	url := strings.Replace(srv.URL, "http://", "https://", 1)
+
+	dir := t.TempDir()
+	if _, err := a.IssueTo(dir, "proj-1"); err != nil {
+		t.Fatalf("IssueTo: %v", err)
+	}
+	cfg, err := ClientTLSConfig(filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile), filepath.Join(dir, CAFile))
+	if err != nil {
+		t.Fatalf("ClientTLSConfig: %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	if got, err := get(client, url); err != nil || got != "proj-1" {
+		t.Fatalf("project = %q, %v; want proj-1", got, err)
+	}
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: a.Pool()}}}
+	if got, err := get(anonymous, url); err != nil || got != "-" {
+		t.Errorf("without a certificate = %q, %v; want an unverified request", got, err)
+	}
+
+	other := newTestAuthority(t)
+	otherDir := t.TempDir()
+	if _, err := other.IssueTo(otherDir, "proj-1"); err != nil {
+		t.Fatal(err)
+	}
+	forged, err := ClientTLSConfig(filepath.Join(otherDir, CertFile), filepath.Join(otherDir, KeyFile), filepath.Join(dir, CAFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: forged}}
+	if _, err := get(client, url); err == nil {
+		t.Error("a certificate from another CA was accepted")
+	}
+}
+
+func TestClientPicksUpRotatedCertificate(t *testing.T) {
+	a := newTestAuthority(t)
+	dir := t.TempDir()
+	if _, err := a.IssueTo(dir, "proj-1"); err != nil {
+		t.Fatal(err)
+	}
+	r := &certReloader{certFile: filepath.Join(dir, CertFile), keyFile: filepath.Join(dir, KeyFile)}
+	before, err := r.certificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond) // let the file's mtime move on
+	if _, err := a.IssueTo(dir, "proj-1"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := r.certificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Leaf.SerialNumber.Cmp(after.Leaf.SerialNumber) == 0 {
+		t.Error("the reloader kept serving the old certificate")
+	}
+}
+
+func TestNeedsRotation(t *testing.T) {
+	a := newTestAuthority(t)
+	dir := t.TempDir()
+	if !a.NeedsRotation(dir, "proj-1") {
+		t.Error("a missing certificate does not need rotation")
+	}
+	if _, err := a.IssueTo(dir, "proj-1"); err != nil {
+		t.Fatal(err)
+	}
+	if a.NeedsRotation(dir, "proj-1") {
+		t.Error("a fresh certificate needs rotation")
+	}
+	if !a.NeedsRotation(dir, "proj-2") {
+		t.Error("another project's certificate does not need rotation")
+	}
+	start := time.Now()
+	a.now = func() time.Time { return start.Add(45 * time.Minute) }
+	if !a.NeedsRotation(dir, "proj-1") {
+		t.Error("a certificate two thirds through its lifetime does not need rotation")
+	}
+}
+
+func TestIssue(t *testing.T) {
+	a := newTestAuthority(t)
+	certPEM, keyPEM, _, err := a.Issue("proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ProjectID(pair.Leaf); got != "proj-1" {
+		t.Errorf("ProjectID = %q, want proj-1", got)
+	}
+	if _, _, _, err := a.Issue(""); err == nil {
+		t.Error("issuing a certificate without a project succeeded")
+	}
+}
@@ -0,0 +1,175 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// loopTerminalReasons are the worker loop's terminal reasons that mean it
+// stopped because the agent was going in circles.
+var loopTerminalReasons = map[string]bool{
+	"inner_loop":        true,
+	"progress_stagnant": true,
+}
+
+// AgentScore is one agent's row in the scoreboard.
+type AgentScore struct {
+	AgentID              string  `json:"agent_id"`
+	AgentName            string  `json:"agent_name,omitempty"`
+	ProjectID            string  `json:"project_id,omitempty"`
+	Tasks                int     `json:"tasks"`
+	TasksSucceeded       int     `json:"tasks_succeeded"`
+	TasksFailed          int     `json:"tasks_failed"`
+	SuccessRate          float64 `json:"success_rate"`
+	BeadsClosed          int     `json:"beads_closed"`
+	AvgCompletionSeconds float64 `json:"avg_completion_seconds"`
+	LoopIncidents        int     `json:"loop_incidents"`
+	Tokens               int64   `json:"tokens"`
+	CostUSD              float64 `json:"cost_usd"`
+	AvgLatencyMs         float64 `json:"avg_latency_ms"`
+
+	completionTotal time.Duration
+	latencyTotal    int64
+}
+
+// AgentScoreboard summarizes agent performance over a time window.
+type AgentScoreboard struct {
+	ProjectID string        `json:"project_id,omitempty"`
+	Window    string        `json:"window"`
+	Since     time.Time     `json:"since"`
+	Agents    []*AgentScore `json:"agents"`
+}
+
+// BuildAgentScoreboard joins agent task logs, beads, and the agent roster
+// into per-agent scores. Task counts, tokens, cost, and latency come from
+// request logs carrying an agent_id, with per-completion records counting
+// toward tokens and cost only; closed beads and completion times from
+// beads closed in the window, credited to the agent in their context; and
+// loop incidents from both worker loop stops and dispatcher loop detections.
+// Registered agents with no activity are listed with zero scores. An empty
+// projectID covers every project.
+func BuildAgentScoreboard(logs []*RequestLog, beads []*models.Bead, agents []*models.Agent, projectID string, since, now time.Time) *AgentScoreboard {
+	scores := make(map[string]*AgentScore)
+	score := func(agentID string) *AgentScore {
+		s := scores[agentID]
+		if s == nil {
+			s = &AgentScore{AgentID: agentID}
+			scores[agentID] = s
+		}
+		return s
+	}
+
+	for _, a := range agents {
+		if a == nil || (projectID != "" && a.ProjectID != projectID) {
+			continue
+		}
+		s := score(a.ID)
+		s.AgentName = a.Name
+		s.ProjectID = a.ProjectID
+	}
+
+	for _, l := range logs {
+		agentID := l.Metadata["agent_id"]
+		if agentID == "" || l.Timestamp.Before(since) {
+			continue
+		}
+		if projectID != "" && l.Metadata["project_id"] != projectID {
+			continue
+		}
+		s := score(agentID)
+		if s.ProjectID == "" {
+			s.ProjectID = l.Metadata["project_id"]
+		}
+		s.Tokens += l.TotalTokens
+		s.CostUSD += l.CostUSD
+		if l.Path == CompletionPath {
+			continue
+		}
+		s.Tasks++
+		if l.StatusCode >= 200 && l.StatusCode < 400 {
+			s.TasksSucceeded++
+		} else {
+			s.TasksFailed++
+		}
+		if loopTerminalReasons[l.Metadata["terminal_reason"]] {
+			s.LoopIncidents++
+		}
+		s.latencyTotal += l.LatencyMs
+	}
+
+	for _, b := range beads {
+		if b == nil || (projectID != "" && b.ProjectID != projectID) {
+			continue
+		}
+		agentID := b.Context["agent_id"]
+		if agentID == "" {
+			continue
+		}
+		if b.Status == models.BeadStatusClosed && b.ClosedAt != nil && !b.ClosedAt.Before(since) {
+			s := score(agentID)
+			s.BeadsClosed++
+			started := b.CreatedAt
+			if b.StartedAt != nil {
+				started = *b.StartedAt
+			}
+			if d := b.ClosedAt.Sub(started); d > 0 {
+				s.completionTotal += d
+			}
+		}
+		if b.Context["loop_detected"] == "true" {
+			if at, err := time.Parse(time.RFC3339, b.Context["loop_detected_at"]); err == nil && !at.Before(since) {
+				score(agentID).LoopIncidents++
+			}
+		}
+	}
+
+	board := &AgentScoreboard{
+		ProjectID: projectID,
+		Window:    formatDuration(now.Sub(since)),
+		Since:     since,
+		Agents:    make([]*AgentScore, 0, len(scores)),
+	}
+	for _, s := range scores {
+		if s.Tasks > 0 {
+			s.SuccessRate = float64(s.TasksSucceeded) / float64(s.Tasks)
+			s.AvgLatencyMs = float64(s.latencyTotal) / float64(s.Tasks)
+		}
+		if s.BeadsClosed > 0 {
+			s.AvgCompletionSeconds = s.completionTotal.Seconds() / float64(s.BeadsClosed)
+		}
+		board.Agents = append(board.Agents, s)
+	}
+	sort.Slice(board.Agents, func(i, j int) bool {
+		a, b := board.Agents[i], board.Agents[j]
+		if a.SuccessRate != b.SuccessRate {
+			return a.SuccessRate > b.SuccessRate
+		}
+		if a.BeadsClosed != b.BeadsClosed {
+			return a.BeadsClosed > b.BeadsClosed
+		}
+		return a.AgentID < b.AgentID
+	})
+	return board
+}
+
+// ParseWindow parses a look-back window such as "24h", "90m", or "7d".
+// Days are accepted in addition to Go duration syntax.
+func ParseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid window %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestBuildAgentScoreboard(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-7 * 24 * time.Hour)
+	at := func(d time.Duration) *time.Time { t := now.Add(-d); return &t }
+
+	agentLog := func(agentID, projectID string, status int, reason string, tokens int64, age time.Duration) *RequestLog {
+		return &RequestLog{
+			Timestamp:   now.Add(-age),
+			StatusCode:  status,
+			TotalTokens: tokens,
+			CostUSD:     float64(tokens) / 1000,
+			LatencyMs:   100,
+			Metadata:    map[string]string{"agent_id": agentID, "project_id": projectID, "terminal_reason": reason},
+		}
+	}
+	logs := []*RequestLog{
+		agentLog("a1", "p1", 200, "completed", 1000, time.Hour),
+		agentLog("a1", "p1", 200, "completed", 1000, 2*time.Hour),
+		agentLog("a1", "p1", 500, "inner_loop", 500, 3*time.Hour),
+		agentLog("a2", "p1", 500, "error", 200, time.Hour),
+		agentLog("a1", "p1", 200, "completed", 9999, 30*24*time.Hour), // outside window
+		agentLog("a3", "p2", 200, "completed", 100, time.Hour),        // other project
+		{Timestamp: now, StatusCode: 200, TotalTokens: 50},            // not agent work
+		// A model call logged on its own adds tokens and cost, not a task.
+		{Timestamp: now, Path: CompletionPath, StatusCode: 200, TotalTokens: 500, CostUSD: 0.5, LatencyMs: 9000,
+			Metadata: map[string]string{"agent_id": "a1", "project_id": "p1"}},
+	}
+	beads := []*models.Bead{
+		{ID: "b1", ProjectID: "p1", Status: models.BeadStatusClosed, CreatedAt: now.Add(-10 * time.Hour),
+			StartedAt: at(4 * time.Hour), ClosedAt: at(2 * time.Hour), Context: map[string]string{"agent_id": "a1"}},
+		{ID: "b2", ProjectID: "p1", Status: models.BeadStatusClosed, CreatedAt: now.Add(-5 * time.Hour),
+			ClosedAt: at(time.Hour), Context: map[string]string{"agent_id": "a1"}},
+		{ID: "b3", ProjectID: "p1", Status: models.BeadStatusOpen, Context: map[string]string{
+			"agent_id": "a2", "loop_detected": "true", "loop_detected_at": now.Add(-time.Hour).Format(time.RFC3339)}},
+	}
+	agents := []*models.Agent{
+		{ID: "a1", Name: "Coder", ProjectID: "p1"},
+		{ID: "a4", Name: "Idle", ProjectID: "p1"},
+		{ID: "a3", Name: "Elsewhere", ProjectID: "p2"},
+	}
+
+	board := BuildAgentScoreboard(logs, beads, agents, "p1", since, now)
+	if board.Window != "7d" {
+		t.Errorf("window = %q, want 7d", board.Window)
+	}
+	if len(board.Agents) != 3 {
+		t.Fatalf("expected 3 agents, got %d", len(board.Agents))
+	}
+	byID := map[string]*AgentScore{}
+	for _, s := range board.Agents {
+		byID[s.AgentID] = s
+	}
+
+	a1 := byID["a1"]
+	if board.Agents[0] != a1 {
+		t.Errorf("expected a1 to rank first, got %s", board.Agents[0].AgentID)
+	}
+	if a1.AgentName != "Coder" || a1.Tasks != 3 || a1.TasksSucceeded != 2 || a1.TasksFailed != 1 {
+		t.Errorf("unexpected a1 task counts: %+v", a1)
+	}
+	if a1.LoopIncidents != 1 || a1.Tokens != 3000 || a1.CostUSD != 3 || a1.AvgLatencyMs != 100 {
+		t.Errorf("unexpected a1 totals: %+v", a1)
+	}
+	// b1 took 2h from start, b2 4h from creation.
+	if a1.BeadsClosed != 2 || a1.AvgCompletionSeconds != (3*time.Hour).Seconds() {
+		t.Errorf("unexpected a1 completion: closed=%d avg=%v", a1.BeadsClosed, a1.AvgCompletionSeconds)
+	}
+
+	if a2 := byID["a2"]; a2.SuccessRate != 0 || a2.TasksFailed != 1 || a2.LoopIncidents != 1 {
+		t.Errorf("unexpected a2: %+v", a2)
+	}
+	if a4 := byID["a4"]; a4 == nil || a4.Tasks != 0 {
+		t.Errorf("expected idle agent a4 with zero scores, got %+v", a4)
+	}
+	if _, ok := byID["a3"]; ok {
+		t.Error("agent from another project should be excluded")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"7d", 7 * 24 * time.Hour, true},
+		{"24h", 24 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{"0d", 0, false},
+		{"-1h", 0, false},
+		{"week", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseWindow(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseWindow(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// BeadSelector picks the beads flow metrics and burndowns are computed
+// over. Empty fields match every bead.
+type BeadSelector struct {
+	ProjectID   string `json:"project_id,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	MilestoneID string `json:"milestone_id,omitempty"`
+}
+
+// Matches reports whether a bead is selected.
+func (s BeadSelector) Matches(b *models.Bead) bool {
+	if b == nil || b.DeletedAt != nil {
+		return false
+	}
+	if s.ProjectID != "" && b.ProjectID != s.ProjectID {
+		return false
+	}
+	if s.MilestoneID != "" && b.MilestoneID != s.MilestoneID {
+		return false
+	}
+	if s.Tag != "" {
+		for _, t := range b.Tags {
+			if t == s.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// DurationStats summarizes how long a set of beads took, in seconds.
+// Percentiles use the nearest-rank method.
+type DurationStats struct {
+	Count       int     `json:"count"`
+	MeanSeconds float64 `json:"mean_seconds"`
+	P50Seconds  float64 `json:"p50_seconds"`
+	P75Seconds  float64 `json:"p75_seconds"`
+	P90Seconds  float64 `json:"p90_seconds"`
+	P95Seconds  float64 `json:"p95_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+}
+
+// newDurationStats summarizes durations; it sorts them in place.
+func newDurationStats(durations []time.Duration) DurationStats {
+	stats := DurationStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(durations)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return durations[i].Seconds()
+	}
+	stats.MeanSeconds = total.Seconds() / float64(len(durations))
+	stats.P50Seconds = rank(0.50)
+	stats.P75Seconds = rank(0.75)
+	stats.P90Seconds = rank(0.90)
+	stats.P95Seconds = rank(0.95)
+	stats.MaxSeconds = durations[len(durations)-1].Seconds()
+	return stats
+}
+
+// WeekThroughput is the number of beads closed in the week starting
+// WeekStart (Monday, UTC).
+type WeekThroughput struct {
+	WeekStart time.Time `json:"week_start"`
+	Closed    int       `json:"closed"`
+}
+
+// FlowMetrics describes how beads move from creation to closing over a
+// time window.
+type FlowMetrics struct {
+	BeadSelector
+	Window string    `json:"window"`
+	Since  time.Time `json:"since"`
+	// CycleTime runs from when work started on a bead (its first move to
+	// in_progress, or creation for beads closed without one) to closing.
+	CycleTime DurationStats `json:"cycle_time"`
+	// LeadTime runs from creation to closing.
+	LeadTime   DurationStats    `json:"lead_time"`
+	Throughput []WeekThroughput `json:"throughput"`
+	// Open is how many selected beads are not closed now.
+	Open int `json:"open"`
+}
+
+// weekStart is the Monday midnight UTC starting the week t falls in.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// closedAt returns when a bead was closed, or nil if it is not closed.
+func closedAt(b *models.Bead) *time.Time {
+	if b.Status != models.BeadStatusClosed || b.ClosedAt == nil {
+		return nil
+	}
+	return b.ClosedAt
+}
+
+// BuildFlowMetrics computes cycle time, lead time, and weekly throughput
+// from the selected beads closed between since and now. Throughput lists
+// every week of the window, including weeks nothing was closed in.
+func BuildFlowMetrics(beads []*models.Bead, sel BeadSelector, since, now time.Time) *FlowMetrics {
+	m := &FlowMetrics{
+		BeadSelector: sel,
+		Window:       formatDuration(now.Sub(since)),
+		Since:        since,
+	}
+	weeks := make(map[time.Time]int)
+	var cycle, lead []time.Duration
+	for _, b := range beads {
+		if !sel.Matches(b) {
+			continue
+		}
+		closed := closedAt(b)
+		if closed == nil {
+			m.Open++
+			continue
+		}
+		if closed.Before(since) || closed.After(now) {
+			continue
+		}
+		started := b.CreatedAt
+		if b.StartedAt != nil && b.StartedAt.After(started) {
+			started = *b.StartedAt
+		}
+		if d := closed.Sub(b.CreatedAt); d >= 0 {
+			lead = append(lead, d)
+		}
+		if d := closed.Sub(started); d >= 0 {
+			cycle = append(cycle, d)
+		}
+		weeks[weekStart(*closed)]++
+	}
+	m.CycleTime = newDurationStats(cycle)
+	m.LeadTime = newDurationStats(lead)
+	for w := weekStart(since); !w.After(now); w = w.AddDate(0, 0, 7) {
+		m.Throughput = append(m.Throughput, WeekThroughput{WeekStart: w, Closed: weeks[w]})
+	}
+	return m
+}
+
+// BurndownPoint is the state of the selected beads at the end of a day
+// (midnight UTC), or at the time of the burndown for the last point.
+type BurndownPoint struct {
+	Time      time.Time `json:"time"`
+	Total     int       `json:"total"`     // Beads created so far
+	Closed    int       `json:"closed"`    // Of those, closed so far
+	Remaining int       `json:"remaining"` // Total minus closed
+}
+
+// Burndown tracks the remaining work of a tag or milestone day by day.
+type Burndown struct {
+	BeadSelector
+	Since     time.Time       `json:"since"`
+	Total     int             `json:"total"`
+	Remaining int             `json:"remaining"`
+	Points    []BurndownPoint `json:"points"`
+}
+
+// BuildBurndown counts, for each day from since to now, how many of the
+// selected beads existed and how many of those were closed. Beads added
+// mid-way raise the total, so scope growth shows next to progress.
+func BuildBurndown(beads []*models.Bead, sel BeadSelector, since, now time.Time) *Burndown {
+	var selected []*models.Bead
+	for _, b := range beads {
+		if sel.Matches(b) {
+			selected = append(selected, b)
+		}
+	}
+	point := func(at time.Time) BurndownPoint {
+		p := BurndownPoint{Time: at}
+		for _, b := range selected {
+			if b.CreatedAt.After(at) {
+				continue
+			}
+			p.Total++
+			if closed := closedAt(b); closed != nil && !closed.After(at) {
+				p.Closed++
+			}
+		}
+		p.Remaining = p.Total - p.Closed
+		return p
+	}
+
+	bd := &Burndown{BeadSelector: sel, Since: since}
+	since = since.UTC()
+	day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	for ; day.Before(now); day = day.AddDate(0, 0, 1) {
+		bd.Points = append(bd.Points, point(day))
+	}
+	last := point(now)
+	bd.Points = append(bd.Points, last)
+	bd.Total, bd.Remaining = last.Total, last.Remaining
+	return bd
+}
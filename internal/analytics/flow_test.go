@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestBuildFlowMetrics(t *testing.T) {
+	// Wednesday noon
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-14 * 24 * time.Hour)
+	ago := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	beads := []*models.Bead{
+		// Lead 10h, cycle 4h
+		{ID: "b1", ProjectID: "p1", Tags: []string{"v2"}, Status: models.BeadStatusClosed, CreatedAt: ago(12), StartedAt: ptr(ago(6)), ClosedAt: ptr(ago(2))},
+		// Never started: cycle equals lead, 20h
+		{ID: "b2", ProjectID: "p1", Tags: []string{"v2"}, Status: models.BeadStatusClosed, CreatedAt: ago(30), ClosedAt: ptr(ago(10))},
+		// Closed last week: lead 100h, cycle 40h
+		{ID: "b3", ProjectID: "p1", Status: models.BeadStatusClosed, CreatedAt: ago(300), StartedAt: ptr(ago(240)), ClosedAt: ptr(ago(200))},
+		// Closed before the window
+		{ID: "b4", ProjectID: "p1", Status: models.BeadStatusClosed, CreatedAt: ago(1000), ClosedAt: ptr(ago(900))},
+		{ID: "b5", ProjectID: "p1", Tags: []string{"v2"}, Status: models.BeadStatusOpen, CreatedAt: ago(5)},
+		{ID: "b6", ProjectID: "p2", Status: models.BeadStatusClosed, CreatedAt: ago(5), ClosedAt: ptr(ago(1))},
+	}
+
+	m := BuildFlowMetrics(beads, BeadSelector{ProjectID: "p1"}, since, now)
+	if m.Window != "14d" || m.Open != 1 {
+		t.Errorf("window %q, open %d", m.Window, m.Open)
+	}
+	hours := func(s float64) float64 { return s / 3600 }
+	if m.LeadTime.Count != 3 || hours(m.LeadTime.P50Seconds) != 20 || hours(m.LeadTime.P95Seconds) != 100 || hours(m.LeadTime.MeanSeconds) != 130.0/3 {
+		t.Errorf("lead time = %+v", m.LeadTime)
+	}
+	if m.CycleTime.Count != 3 || hours(m.CycleTime.P50Seconds) != 20 || hours(m.CycleTime.MaxSeconds) != 40 {
+		t.Errorf("cycle time = %+v", m.CycleTime)
+	}
+	// The window starts Wednesday Feb 25: weeks of Feb 23, Mar 2 and Mar 9.
+	if len(m.Throughput) != 3 {
+		t.Fatalf("throughput = %+v", m.Throughput)
+	}
+	for i, want := range []int{0, 1, 2} {
+		if m.Throughput[i].Closed != want {
+			t.Errorf("week %s closed %d, want %d", m.Throughput[i].WeekStart.Format("Jan 2"), m.Throughput[i].Closed, want)
+		}
+	}
+	if !m.Throughput[0].WeekStart.Equal(time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("first week starts %v", m.Throughput[0].WeekStart)
+	}
+
+	tagged := BuildFlowMetrics(beads, BeadSelector{ProjectID: "p1", Tag: "v2"}, since, now)
+	if tagged.LeadTime.Count != 2 || tagged.Open != 1 {
+		t.Errorf("tagged: lead %d, open %d", tagged.LeadTime.Count, tagged.Open)
+	}
+	if empty := BuildFlowMetrics(nil, BeadSelector{}, since, now); empty.CycleTime != (DurationStats{}) {
+		t.Errorf("no beads: cycle time = %+v", empty.CycleTime)
+	}
+}
+
+func TestBuildBurndown(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	since := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	beads := []*models.Bead{
+		{ID: "b1", MilestoneID: "m1", Status: models.BeadStatusClosed, CreatedAt: at(1, 8), ClosedAt: ptr(at(2, 10))},
+		{ID: "b2", MilestoneID: "m1", Status: models.BeadStatusClosed, CreatedAt: at(1, 8), ClosedAt: ptr(at(4, 10))},
+		{ID: "b3", MilestoneID: "m1", Status: models.BeadStatusOpen, CreatedAt: at(1, 8)},
+		// Scope added on day 3
+		{ID: "b4", MilestoneID: "m1", Status: models.BeadStatusOpen, CreatedAt: at(3, 10)},
+		{ID: "b5", MilestoneID: "m2", Status: models.BeadStatusOpen, CreatedAt: at(1, 8)},
+	}
+
+	bd := BuildBurndown(beads, BeadSelector{MilestoneID: "m1"}, since, now)
+	want := []BurndownPoint{
+		{Time: at(2, 0), Total: 3, Closed: 0, Remaining: 3},
+		{Time: at(3, 0), Total: 3, Closed: 1, Remaining: 2},
+		{Time: at(4, 0), Total: 4, Closed: 1, Remaining: 3},
+		{Time: now, Total: 4, Closed: 2, Remaining: 2},
+	}
+	if len(bd.Points) != len(want) {
+		t.Fatalf("points = %+v", bd.Points)
+	}
+	for i, p := range bd.Points {
+		if p != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+	if bd.Total != 4 || bd.Remaining != 2 {
+		t.Errorf("total %d, remaining %d", bd.Total, bd.Remaining)
+	}
+}
@@ -0,0 +1,384 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+// Forecast defaults.
+const (
+	DefaultForecastLookback = 14 * 24 * time.Hour
+	DefaultAnomalyWindow    = time.Hour
+	DefaultAnomalyFactor    = 3.0
+	DefaultMinAnomalyTokens = 100000
+)
+
+// Forecast scopes. Spend is forecast for the total, each provider, and each
+// project; anomalies are detected per provider, project, and agent.
+const (
+	ForecastScopeTotal    = "total"
+	ForecastScopeProvider = "provider"
+	ForecastScopeProject  = "project"
+	ForecastScopeAgent    = "agent"
+)
+
+// ForecastOptions tunes forecasting and anomaly detection. Zero fields take
+// the defaults.
+type ForecastOptions struct {
+	// Lookback is the history the daily run rate and the anomaly baseline
+	// are computed from.
+	Lookback time.Duration
+	// AnomalyWindow is the recent window checked for spikes.
+	AnomalyWindow time.Duration
+	// AnomalyFactor is how many times its baseline a scope's token usage
+	// in the anomaly window must reach to be flagged.
+	AnomalyFactor float64
+	// MinAnomalyTokens is the least token usage in the anomaly window that
+	// is flagged, so quiet scopes don't flag every small bump.
+	MinAnomalyTokens int64
+}
+
+func (o ForecastOptions) withDefaults() ForecastOptions {
+	if o.Lookback <= 0 {
+		o.Lookback = DefaultForecastLookback
+	}
+	if o.AnomalyWindow <= 0 {
+		o.AnomalyWindow = DefaultAnomalyWindow
+	}
+	if o.AnomalyFactor <= 1 {
+		o.AnomalyFactor = DefaultAnomalyFactor
+	}
+	if o.MinAnomalyTokens <= 0 {
+		o.MinAnomalyTokens = DefaultMinAnomalyTokens
+	}
+	return o
+}
+
+// SpendForecast projects one scope's spend for the current month.
+type SpendForecast struct {
+	Scope                string  `json:"scope"`
+	ScopeID              string  `json:"scope_id,omitempty"`
+	MonthToDateUSD       float64 `json:"month_to_date_usd"`
+	MonthToDateTokens    int64   `json:"month_to_date_tokens"`
+	DailyRateUSD         float64 `json:"daily_rate_usd"`
+	DailyRateTokens      float64 `json:"daily_rate_tokens"`
+	ProjectedMonthUSD    float64 `json:"projected_month_usd"`
+	ProjectedMonthTokens int64   `json:"projected_month_tokens"`
+
+	lookbackUSD    float64
+	lookbackTokens int64
+}
+
+// Anomaly is a scope whose recent token usage spiked far above its
+// baseline, such as an agent stuck in a loop.
+type Anomaly struct {
+	Scope          string    `json:"scope"`
+	ScopeID        string    `json:"scope_id"`
+	WindowStart    time.Time `json:"window_start"`
+	WindowEnd      time.Time `json:"window_end"`
+	Tokens         int64     `json:"tokens"`
+	CostUSD        float64   `json:"cost_usd"`
+	BaselineTokens float64   `json:"baseline_tokens"`
+	Ratio          float64   `json:"ratio,omitempty"`
+	Reason         string    `json:"reason"`
+}
+
+// Forecast is the month's projected spend and the current anomalies.
+type Forecast struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	MonthStart  time.Time        `json:"month_start"`
+	MonthEnd    time.Time        `json:"month_end"`
+	Lookback    string           `json:"lookback"`
+	Total       *SpendForecast   `json:"total"`
+	Providers   []*SpendForecast `json:"providers"`
+	Projects    []*SpendForecast `json:"projects"`
+	Anomalies   []*Anomaly       `json:"anomalies"`
+}
+
+// ForecastSince returns how far back the logs BuildForecast is given must
+// reach: the start of the month or the lookback, whichever is earlier.
+func ForecastSince(now time.Time, opts ForecastOptions) time.Time {
+	opts = opts.withDefaults()
+	since := now.Add(-opts.Lookback)
+	if start := monthStart(now); start.Before(since) {
+		since = start
+	}
+	return since
+}
+
+// BuildForecast projects each scope's spend for the current (UTC) month as
+// its month-to-date spend plus its daily run rate over the lookback for
+// every remaining day, and flags the scopes whose token usage in the
+// anomaly window is AnomalyFactor times their baseline, the average usage
+// per window over the rest of the lookback. Tokens rather than cost are
+// checked so spikes on free local models are caught too. logs should
+// reach back to ForecastSince.
+func BuildForecast(logs []*RequestLog, now time.Time, opts ForecastOptions) *Forecast {
+	opts = opts.withDefaults()
+	now = now.UTC()
+	start := monthStart(now)
+	end := start.AddDate(0, 1, 0)
+	lookbackStart := now.Add(-opts.Lookback)
+	windowStart := now.Add(-opts.AnomalyWindow)
+
+	// The history actually available may be shorter than the lookback,
+	// e.g. on a new install.
+	earliest := now
+	for _, l := range logs {
+		if l.Timestamp.Before(earliest) {
+			earliest = l.Timestamp
+		}
+	}
+	if earliest.After(lookbackStart) {
+		lookbackStart = earliest
+	}
+
+	forecasts := make(map[string]*SpendForecast)
+	forecast := func(scope, id string) *SpendForecast {
+		key := scope + ":" + id
+		f := forecasts[key]
+		if f == nil {
+			f = &SpendForecast{Scope: scope, ScopeID: id}
+			forecasts[key] = f
+		}
+		return f
+	}
+	type usage struct {
+		baseline, window int64
+		windowCost       float64
+	}
+	usages := make(map[string]*usage)
+	scopes := make(map[string][2]string)
+
+	for _, l := range logs {
+		if l.Timestamp.After(now) {
+			continue
+		}
+		for _, s := range [][2]string{
+			{ForecastScopeTotal, ""},
+			{ForecastScopeProvider, l.ProviderID},
+			{ForecastScopeProject, l.Metadata["project_id"]},
+			{ForecastScopeAgent, l.Metadata["agent_id"]},
+		} {
+			if s[0] != ForecastScopeTotal && s[1] == "" {
+				continue
+			}
+			if s[0] != ForecastScopeAgent {
+				f := forecast(s[0], s[1])
+				if !l.Timestamp.Before(start) {
+					f.MonthToDateUSD += l.CostUSD
+					f.MonthToDateTokens += l.TotalTokens
+				}
+				if !l.Timestamp.Before(lookbackStart) {
+					f.lookbackUSD += l.CostUSD
+					f.lookbackTokens += l.TotalTokens
+				}
+			}
+			if s[0] == ForecastScopeTotal {
+				// A spike shows up in its provider; flagging the total
+				// as well would only repeat it.
+				continue
+			}
+			key := s[0] + ":" + s[1]
+			u := usages[key]
+			if u == nil {
+				u = &usage{}
+				usages[key] = u
+				scopes[key] = s
+			}
+			switch {
+			case !l.Timestamp.Before(windowStart):
+				u.window += l.TotalTokens
+				u.windowCost += l.CostUSD
+			case !l.Timestamp.Before(lookbackStart):
+				u.baseline += l.TotalTokens
+			}
+		}
+	}
+
+	// A history of a few minutes is averaged over at least one anomaly
+	// window rather than extrapolated as is.
+	days := max(now.Sub(lookbackStart), opts.AnomalyWindow).Hours() / 24
+	remainingDays := end.Sub(now).Hours() / 24
+	result := &Forecast{
+		GeneratedAt: now,
+		MonthStart:  start,
+		MonthEnd:    end,
+		Lookback:    formatDuration(opts.Lookback),
+		Total:       forecast(ForecastScopeTotal, ""),
+		Providers:   []*SpendForecast{},
+		Projects:    []*SpendForecast{},
+		Anomalies:   []*Anomaly{},
+	}
+	for _, f := range forecasts {
+		f.DailyRateUSD = f.lookbackUSD / days
+		f.DailyRateTokens = float64(f.lookbackTokens) / days
+		f.ProjectedMonthUSD = f.MonthToDateUSD + f.DailyRateUSD*remainingDays
+		f.ProjectedMonthTokens = f.MonthToDateTokens + int64(f.DailyRateTokens*remainingDays)
+		switch f.Scope {
+		case ForecastScopeProvider:
+			result.Providers = append(result.Providers, f)
+		case ForecastScopeProject:
+			result.Projects = append(result.Projects, f)
+		}
+	}
+	byProjection := func(list []*SpendForecast) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].ProjectedMonthUSD != list[j].ProjectedMonthUSD {
+				return list[i].ProjectedMonthUSD > list[j].ProjectedMonthUSD
+			}
+			return list[i].ScopeID < list[j].ScopeID
+		})
+	}
+	byProjection(result.Providers)
+	byProjection(result.Projects)
+
+	// The anomaly window needs a baseline before it to be compared with.
+	if baselineSpan := windowStart.Sub(lookbackStart); baselineSpan >= opts.AnomalyWindow {
+		windows := float64(baselineSpan) / float64(opts.AnomalyWindow)
+		for key, u := range usages {
+			if u.window < opts.MinAnomalyTokens {
+				continue
+			}
+			baseline := float64(u.baseline) / windows
+			if float64(u.window) < opts.AnomalyFactor*baseline {
+				continue
+			}
+			s := scopes[key]
+			a := &Anomaly{
+				Scope:          s[0],
+				ScopeID:        s[1],
+				WindowStart:    windowStart,
+				WindowEnd:      now,
+				Tokens:         u.window,
+				CostUSD:        u.windowCost,
+				BaselineTokens: baseline,
+			}
+			name := s[0] + " " + s[1]
+			if baseline > 0 {
+				a.Ratio = float64(u.window) / baseline
+				a.Reason = fmt.Sprintf("%s used %d tokens in the last %s, %.1fx its usual %.0f",
+					name, u.window, formatDuration(opts.AnomalyWindow), a.Ratio, baseline)
+			} else {
+				a.Reason = fmt.Sprintf("%s used %d tokens in the last %s with no usage before",
+					name, u.window, formatDuration(opts.AnomalyWindow))
+			}
+			result.Anomalies = append(result.Anomalies, a)
+		}
+	}
+	sort.Slice(result.Anomalies, func(i, j int) bool {
+		a, b := result.Anomalies[i], result.Anomalies[j]
+		if a.Tokens != b.Tokens {
+			return a.Tokens > b.Tokens
+		}
+		return a.Scope+a.ScopeID < b.Scope+b.ScopeID
+	})
+	return result
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// LogSource supplies request logs. Storage and *Logger satisfy it.
+type LogSource interface {
+	GetLogs(ctx context.Context, filter *LogFilter) ([]*RequestLog, error)
+}
+
+// CostWatcher forecasts spend from the request logs and reports anomalies
+// on the event bus as cost.anomaly events.
+type CostWatcher struct {
+	source   LogSource
+	eventBus *eventbus.EventBus
+	opts     ForecastOptions
+	now      func() time.Time
+
+	mu       sync.Mutex
+	reported map[string]time.Time // scope:id -> when its anomaly was reported
+}
+
+// NewCostWatcher creates a cost watcher. It returns nil without a source.
+func NewCostWatcher(source LogSource, eventBus *eventbus.EventBus, opts ForecastOptions) *CostWatcher {
+	if source == nil {
+		return nil
+	}
+	return &CostWatcher{
+		source:   source,
+		eventBus: eventBus,
+		opts:     opts.withDefaults(),
+		now:      time.Now,
+		reported: make(map[string]time.Time),
+	}
+}
+
+// Forecast builds the current forecast. A positive lookback overrides the
+// configured one.
+func (w *CostWatcher) Forecast(ctx context.Context, lookback time.Duration) (*Forecast, error) {
+	opts := w.opts
+	if lookback > 0 {
+		opts.Lookback = lookback
+	}
+	now := w.now()
+	logs, err := w.source.GetLogs(ctx, &LogFilter{StartTime: ForecastSince(now, opts), EndTime: now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load request logs: %w", err)
+	}
+	return BuildForecast(logs, now, opts), nil
+}
+
+// Check looks for anomalies and publishes the new ones. A scope is
+// reported at most once per anomaly window. It returns how many anomalies
+// were published.
+func (w *CostWatcher) Check(ctx context.Context) (int, error) {
+	f, err := w.Forecast(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, at := range w.reported {
+		if f.GeneratedAt.Sub(at) >= w.opts.AnomalyWindow {
+			delete(w.reported, key)
+		}
+	}
+	published := 0
+	for _, a := range f.Anomalies {
+		key := a.Scope + ":" + a.ScopeID
+		if _, ok := w.reported[key]; ok {
+			continue
+		}
+		w.reported[key] = f.GeneratedAt
+		published++
+		if w.eventBus == nil {
+			continue
+		}
+		event := &eventbus.Event{
+			Type:   eventbus.EventTypeCostAnomaly,
+			Source: "analytics",
+			Data: map[string]interface{}{
+				"scope":           a.Scope,
+				"scope_id":        a.ScopeID,
+				"tokens":          a.Tokens,
+				"cost_usd":        a.CostUSD,
+				"baseline_tokens": a.BaselineTokens,
+				"reason":          a.Reason,
+			},
+		}
+		switch a.Scope {
+		case ForecastScopeProject:
+			event.ProjectID = a.ScopeID
+		case ForecastScopeProvider:
+			event.Data["provider_id"] = a.ScopeID
+		case ForecastScopeAgent:
+			event.Data["agent_id"] = a.ScopeID
+		}
+		_ = w.eventBus.Publish(event)
+	}
+	return published, nil
+}
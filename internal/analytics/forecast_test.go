@@ -0,0 +1,116 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+func forecastLog(provider, project, agent string, tokens int64, cost float64, at time.Time) *RequestLog {
+	return &RequestLog{
+		Timestamp:   at,
+		ProviderID:  provider,
+		TotalTokens: tokens,
+		CostUSD:     cost,
+		Metadata:    map[string]string{"project_id": project, "agent_id": agent},
+	}
+}
+
+func TestBuildForecast(t *testing.T) {
+	now := time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC) // 10 days in, 20 to go
+	var logs []*RequestLog
+	// $1 a day on p1 via openai for the last 14 days, half of it in April.
+	for d := 1; d <= 14; d++ {
+		logs = append(logs, forecastLog("openai", "p1", "a1", 1000, 1, now.Add(-time.Duration(d)*24*time.Hour+time.Minute)))
+	}
+
+	f := BuildForecast(logs, now, ForecastOptions{})
+	if f.MonthStart != time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("month start = %v", f.MonthStart)
+	}
+	if f.Total.MonthToDateUSD != 10 {
+		t.Errorf("month to date = %v, want 10", f.Total.MonthToDateUSD)
+	}
+	if math.Abs(f.Total.DailyRateUSD-1) > 0.01 {
+		t.Errorf("daily rate = %v, want 1", f.Total.DailyRateUSD)
+	}
+	if math.Abs(f.Total.ProjectedMonthUSD-30) > 0.1 {
+		t.Errorf("projected = %v, want 30", f.Total.ProjectedMonthUSD)
+	}
+	if len(f.Providers) != 1 || f.Providers[0].ScopeID != "openai" || len(f.Projects) != 1 || f.Projects[0].ScopeID != "p1" {
+		t.Errorf("providers %+v projects %+v, want openai and p1", f.Providers, f.Projects)
+	}
+	if len(f.Anomalies) != 0 {
+		t.Errorf("steady usage flagged: %+v", f.Anomalies)
+	}
+
+	// An agent burning tokens in the last hour is flagged, along with its
+	// provider and project.
+	for i := 0; i < 20; i++ {
+		logs = append(logs, forecastLog("openai", "p1", "looper", 10000, 0, now.Add(-time.Duration(i+1)*time.Minute)))
+	}
+	f = BuildForecast(logs, now, ForecastOptions{})
+	flagged := map[string]bool{}
+	for _, a := range f.Anomalies {
+		flagged[a.Scope+":"+a.ScopeID] = true
+	}
+	for _, want := range []string{"agent:looper", "provider:openai", "project:p1"} {
+		if !flagged[want] {
+			t.Errorf("%s not flagged; anomalies %+v", want, f.Anomalies)
+		}
+	}
+	if flagged["agent:a1"] {
+		t.Error("quiet agent flagged")
+	}
+
+	// Below the minimum, a spike is ignored.
+	f = BuildForecast(logs, now, ForecastOptions{MinAnomalyTokens: 1000000})
+	if len(f.Anomalies) != 0 {
+		t.Errorf("spike below the minimum flagged: %+v", f.Anomalies)
+	}
+}
+
+type fakeLogSource []*RequestLog
+
+func (f fakeLogSource) GetLogs(context.Context, *LogFilter) ([]*RequestLog, error) {
+	return f, nil
+}
+
+func TestCostWatcherCheck(t *testing.T) {
+	now := time.Now()
+	logs := fakeLogSource{forecastLog("local", "", "", 10, 0, now.Add(-48*time.Hour))}
+	for i := 0; i < 5; i++ {
+		logs = append(logs, forecastLog("local", "", "", 50000, 0, now.Add(-time.Duration(i+1)*time.Minute)))
+	}
+	eb := eventbus.NewEventBus()
+	defer eb.Close()
+	sub := eb.Subscribe("test", func(e *eventbus.Event) bool { return e.Type == eventbus.EventTypeCostAnomaly })
+
+	w := NewCostWatcher(logs, eb, ForecastOptions{})
+	w.now = func() time.Time { return now }
+	if n, err := w.Check(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Check = %d, %v; want 1 anomaly", n, err)
+	}
+	select {
+	case e := <-sub.Channel:
+		if e.Data["provider_id"] != "local" {
+			t.Errorf("event data = %v, want provider local", e.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no cost.anomaly event")
+	}
+
+	// The same spike isn't reported again within the window.
+	if n, _ := w.Check(context.Background()); n != 0 {
+		t.Errorf("second Check = %d, want 0", n)
+	}
+	w.now = func() time.Time { return now.Add(61 * time.Minute) }
+	logs = append(logs, forecastLog("local", "", "", 250000, 0, now.Add(60*time.Minute)))
+	w.source = logs
+	if n, _ := w.Check(context.Background()); n != 1 {
+		t.Errorf("Check after the window = %d, want 1", n)
+	}
+}
@@ -26,9 +26,41 @@ type RequestLog struct {
 	ErrorMessage     string            `json:"error_message,omitempty"`
 	RequestBody      string            `json:"request_body,omitempty"`  // Redacted if privacy enabled
 	ResponseBody     string            `json:"response_body,omitempty"` // Redacted if privacy enabled
+	BeadID           string            `json:"bead_id,omitempty"`       // Bead the tokens were spent on
+	AgentID          string            `json:"agent_id,omitempty"`
+	ProjectID        string            `json:"project_id,omitempty"`
+	ActionType       string            `json:"action_type,omitempty"` // First action the response asked for
 	Metadata         map[string]string `json:"metadata,omitempty"`
 }
 
+// CompletionPath is the Path of records that log a single model call made
+// while working a bead. Task-level records use other paths.
+const CompletionPath = "/internal/worker/completion"
+
+// syncAttribution fills the attribution fields from Metadata and Metadata
+// from the fields, so readers of either see the same values.
+func (log *RequestLog) syncAttribution() {
+	for _, f := range []struct {
+		key   string
+		field *string
+	}{
+		{"bead_id", &log.BeadID},
+		{"agent_id", &log.AgentID},
+		{"project_id", &log.ProjectID},
+		{"action_type", &log.ActionType},
+	} {
+		switch {
+		case *f.field == "" && log.Metadata[f.key] != "":
+			*f.field = log.Metadata[f.key]
+		case *f.field != "" && log.Metadata[f.key] == "":
+			if log.Metadata == nil {
+				log.Metadata = make(map[string]string)
+			}
+			log.Metadata[f.key] = *f.field
+		}
+	}
+}
+
 // PrivacyConfig controls what data is logged
 type PrivacyConfig struct {
 	LogRequestBodies  bool     // Log full request bodies
@@ -60,6 +92,8 @@ func DefaultPrivacyConfig() *PrivacyConfig {
 type Logger struct {
 	storage Storage
 	privacy *PrivacyConfig
+	// pricing is USD per million tokens by model name.
+	pricing map[string]float64
 }
 
 // Storage interface for persisting logs
@@ -74,6 +108,10 @@ type Storage interface {
 type LogFilter struct {
 	UserID     string
 	ProviderID string
+	BeadID     string
+	AgentID    string
+	ProjectID  string
+	ActionType string
 	StartTime  time.Time
 	EndTime    time.Time
 	Limit      int
@@ -94,6 +132,10 @@ type LogStats struct {
 	TokensByProvider   map[string]int64   `json:"tokens_by_provider"`
 	TokensByUser       map[string]int64   `json:"tokens_by_user"`
 	LatencyByProvider  map[string]float64 `json:"latency_by_provider"`
+	CostByBead         map[string]float64 `json:"cost_by_bead"`
+	TokensByBead       map[string]int64   `json:"tokens_by_bead"`
+	CostByActionType   map[string]float64 `json:"cost_by_action_type"`
+	TokensByActionType map[string]int64   `json:"tokens_by_action_type"`
 }
 
 // NewLogger creates a new request logger
@@ -107,6 +149,12 @@ func NewLogger(storage Storage, privacy *PrivacyConfig) *Logger {
 	}
 }
 
+// SetPricing sets the USD cost per million tokens of each model, used to
+// price logged requests that don't carry a cost of their own.
+func (l *Logger) SetPricing(pricing map[string]float64) {
+	l.pricing = pricing
+}
+
 // LogRequest logs an API request with privacy controls
 func (l *Logger) LogRequest(ctx context.Context, log *RequestLog) error {
 	// Apply privacy filters
@@ -140,6 +188,11 @@ func (l *Logger) LogRequest(ctx context.Context, log *RequestLog) error {
 		log.Timestamp = time.Now()
 	}
 
+	if log.CostUSD == 0 {
+		log.CostUSD = CalculateCost(l.pricing[log.ModelName], log.TotalTokens)
+	}
+	log.syncAttribution()
+
 	return l.storage.SaveLog(ctx, log)
 }
 
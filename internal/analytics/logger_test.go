@@ -254,3 +254,39 @@ func TestDefaultPrivacyConfig(t *testing.T) {
 		t.Error("Default should have max body length")
 	}
 }
+
+func TestLogRequest_AttributionAndPricing(t *testing.T) {
+	storage := &MockStorage{}
+	logger := NewLogger(storage, nil)
+	logger.SetPricing(map[string]float64{"gpt-4o": 5})
+
+	err := logger.LogRequest(context.Background(), &RequestLog{
+		Path:        CompletionPath,
+		ModelName:   "gpt-4o",
+		TotalTokens: 2000000,
+		BeadID:      "bd-1",
+		ActionType:  "edit_code",
+		Metadata:    map[string]string{"agent_id": "agent-1"},
+	})
+	if err != nil {
+		t.Fatalf("LogRequest failed: %v", err)
+	}
+
+	saved := storage.logs[0]
+	if saved.CostUSD != 10 {
+		t.Errorf("CostUSD = %v, want 10", saved.CostUSD)
+	}
+	if saved.AgentID != "agent-1" {
+		t.Errorf("AgentID = %q, want it taken from metadata", saved.AgentID)
+	}
+	if saved.Metadata["bead_id"] != "bd-1" || saved.Metadata["action_type"] != "edit_code" {
+		t.Errorf("Metadata = %v, want bead_id and action_type mirrored", saved.Metadata)
+	}
+
+	// A cost the caller already knows is kept, and unpriced models cost nothing.
+	_ = logger.LogRequest(context.Background(), &RequestLog{ModelName: "gpt-4o", TotalTokens: 1000000, CostUSD: 1})
+	_ = logger.LogRequest(context.Background(), &RequestLog{ModelName: "local", TotalTokens: 1000000})
+	if storage.logs[1].CostUSD != 1 || storage.logs[2].CostUSD != 0 {
+		t.Errorf("costs = %v, %v; want 1, 0", storage.logs[1].CostUSD, storage.logs[2].CostUSD)
+	}
+}
@@ -67,12 +67,22 @@ func (s *DatabaseStorage) initSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_analytics_request_logs_created_at ON analytics_request_logs(created_at);
 	`
 
-	_, err := s.db.Exec(schema)
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Attribution columns were added later; older tables get them here.
+	// The errors are ignored since the columns may already exist.
+	for _, col := range []string{"bead_id", "agent_id", "project_id", "action_type"} {
+		_, _ = s.db.Exec("ALTER TABLE analytics_request_logs ADD COLUMN " + col + " TEXT")
+	}
+	_, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_analytics_request_logs_bead_id ON analytics_request_logs(bead_id)")
 	return err
 }
 
 // SaveLog persists a request log
 func (s *DatabaseStorage) SaveLog(ctx context.Context, log *RequestLog) error {
+	log.syncAttribution()
 	metadataJSON, err := json.Marshal(log.Metadata)
 	if err != nil {
 		metadataJSON = []byte("{}")
@@ -83,8 +93,8 @@ func (s *DatabaseStorage) SaveLog(ctx context.Context, log *RequestLog) error {
 			id, timestamp, user_id, method, path, provider_id, model_name,
 			prompt_tokens, completion_tokens, total_tokens, latency_ms,
 			status_code, cost_usd, error_message, request_body, response_body,
-			metadata_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			metadata_json, bead_id, agent_id, project_id, action_type
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 
 	_, err = s.db.ExecContext(ctx, query,
@@ -105,6 +115,10 @@ func (s *DatabaseStorage) SaveLog(ctx context.Context, log *RequestLog) error {
 		log.RequestBody,
 		log.ResponseBody,
 		string(metadataJSON),
+		log.BeadID,
+		log.AgentID,
+		log.ProjectID,
+		log.ActionType,
 	)
 
 	return err
@@ -117,31 +131,12 @@ func (s *DatabaseStorage) GetLogs(ctx context.Context, filter *LogFilter) ([]*Re
 			id, timestamp, user_id, method, path, provider_id, model_name,
 			prompt_tokens, completion_tokens, total_tokens, latency_ms,
 			status_code, cost_usd, error_message, request_body, response_body,
-			metadata_json
+			metadata_json, COALESCE(bead_id, ''), COALESCE(agent_id, ''),
+			COALESCE(project_id, ''), COALESCE(action_type, '')
 		FROM analytics_request_logs
 		WHERE 1=1
-	`
-	args := []interface{}{}
-
-	if filter.UserID != "" {
-		query += " AND user_id = ?"
-		args = append(args, filter.UserID)
-	}
-
-	if filter.ProviderID != "" {
-		query += " AND provider_id = ?"
-		args = append(args, filter.ProviderID)
-	}
-
-	if !filter.StartTime.IsZero() {
-		query += " AND timestamp >= ?"
-		args = append(args, filter.StartTime)
-	}
-
-	if !filter.EndTime.IsZero() {
-		query += " AND timestamp <= ?"
-		args = append(args, filter.EndTime)
-	}
+	` + buildWhereClause(filter)
+	args := buildWhereArgs(filter)
 
 	query += " ORDER BY timestamp DESC"
 
@@ -183,6 +178,10 @@ func (s *DatabaseStorage) GetLogs(ctx context.Context, filter *LogFilter) ([]*Re
 			&log.RequestBody,
 			&log.ResponseBody,
 			&metadataJSON,
+			&log.BeadID,
+			&log.AgentID,
+			&log.ProjectID,
+			&log.ActionType,
 		)
 		if err != nil {
 			return nil, err
@@ -194,6 +193,7 @@ func (s *DatabaseStorage) GetLogs(ctx context.Context, filter *LogFilter) ([]*Re
 				log.Metadata = nil
 			}
 		}
+		log.syncAttribution()
 
 		logs = append(logs, log)
 	}
@@ -212,28 +212,8 @@ func (s *DatabaseStorage) GetLogStats(ctx context.Context, filter *LogFilter) (*
 			COALESCE(SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END), 0) as error_count
 		FROM analytics_request_logs
 		WHERE 1=1
-	`
-	args := []interface{}{}
-
-	if filter.UserID != "" {
-		baseQuery += " AND user_id = ?"
-		args = append(args, filter.UserID)
-	}
-
-	if filter.ProviderID != "" {
-		baseQuery += " AND provider_id = ?"
-		args = append(args, filter.ProviderID)
-	}
-
-	if !filter.StartTime.IsZero() {
-		baseQuery += " AND timestamp >= ?"
-		args = append(args, filter.StartTime)
-	}
-
-	if !filter.EndTime.IsZero() {
-		baseQuery += " AND timestamp <= ?"
-		args = append(args, filter.EndTime)
-	}
+	` + buildWhereClause(filter)
+	args := buildWhereArgs(filter)
 
 	stats := &LogStats{
 		RequestsByUser:     make(map[string]int64),
@@ -243,6 +223,10 @@ func (s *DatabaseStorage) GetLogStats(ctx context.Context, filter *LogFilter) (*
 		TokensByProvider:   make(map[string]int64),
 		TokensByUser:       make(map[string]int64),
 		LatencyByProvider:  make(map[string]float64),
+		CostByBead:         make(map[string]float64),
+		TokensByBead:       make(map[string]int64),
+		CostByActionType:   make(map[string]float64),
+		TokensByActionType: make(map[string]int64),
 	}
 
 	var errorCount int64
@@ -314,6 +298,37 @@ func (s *DatabaseStorage) GetLogStats(ctx context.Context, filter *LogFilter) (*
 		}
 	}
 
+	// Get per-bead and per-action stats (costs, tokens)
+	for _, group := range []struct {
+		column string
+		cost   map[string]float64
+		tokens map[string]int64
+	}{
+		{"bead_id", stats.CostByBead, stats.TokensByBead},
+		{"action_type", stats.CostByActionType, stats.TokensByActionType},
+	} {
+		groupQuery := fmt.Sprintf(`
+			SELECT %[1]s, COALESCE(SUM(cost_usd), 0) as cost, COALESCE(SUM(total_tokens), 0) as tokens
+			FROM analytics_request_logs
+			WHERE 1=1 %[2]s AND %[1]s IS NOT NULL AND %[1]s != ''
+			GROUP BY %[1]s
+		`, group.column, buildWhereClause(filter))
+
+		rows, err = s.db.QueryContext(ctx, rebindQuery(groupQuery), buildWhereArgs(filter)...)
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+				var key string
+				var cost float64
+				var tokens int64
+				if err := rows.Scan(&key, &cost, &tokens); err == nil {
+					group.cost[key] = cost
+					group.tokens[key] = tokens
+				}
+			}
+		}
+	}
+
 	return stats, nil
 }
 
@@ -335,6 +350,18 @@ func buildWhereClause(filter *LogFilter) string {
 	if filter.ProviderID != "" {
 		where += " AND provider_id = ?"
 	}
+	if filter.BeadID != "" {
+		where += " AND bead_id = ?"
+	}
+	if filter.AgentID != "" {
+		where += " AND agent_id = ?"
+	}
+	if filter.ProjectID != "" {
+		where += " AND project_id = ?"
+	}
+	if filter.ActionType != "" {
+		where += " AND action_type = ?"
+	}
 	if !filter.StartTime.IsZero() {
 		where += " AND timestamp >= ?"
 	}
@@ -352,6 +379,18 @@ func buildWhereArgs(filter *LogFilter) []interface{} {
 	if filter.ProviderID != "" {
 		args = append(args, filter.ProviderID)
 	}
+	if filter.BeadID != "" {
+		args = append(args, filter.BeadID)
+	}
+	if filter.AgentID != "" {
+		args = append(args, filter.AgentID)
+	}
+	if filter.ProjectID != "" {
+		args = append(args, filter.ProjectID)
+	}
+	if filter.ActionType != "" {
+		args = append(args, filter.ActionType)
+	}
 	if !filter.StartTime.IsZero() {
 		args = append(args, filter.StartTime)
 	}
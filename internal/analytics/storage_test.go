@@ -436,3 +436,45 @@ func TestDatabaseStorage_GetLogStats_TokensByUser(t *testing.T) {
 		t.Errorf("p1 tokens = %d, want 3000", stats.TokensByProvider["p1"])
 	}
 }
+
+func TestDatabaseStorage_GetLogStats_ByBead(t *testing.T) {
+	db := newTestDB(t)
+	storage, err := NewDatabaseStorage(db)
+	if err != nil {
+		t.Fatalf("NewDatabaseStorage failed: %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	_ = storage.SaveLog(ctx, &RequestLog{ID: "b1", Timestamp: now, UserID: "agent:a", Method: "POST", Path: CompletionPath, TotalTokens: 100, CostUSD: 1, StatusCode: 200, BeadID: "bd-1", ActionType: "read_file"})
+	_ = storage.SaveLog(ctx, &RequestLog{ID: "b2", Timestamp: now, UserID: "agent:a", Method: "POST", Path: CompletionPath, TotalTokens: 300, CostUSD: 3, StatusCode: 200, BeadID: "bd-1", ActionType: "edit_code"})
+	_ = storage.SaveLog(ctx, &RequestLog{ID: "b3", Timestamp: now, UserID: "agent:a", Method: "POST", Path: CompletionPath, TotalTokens: 50, CostUSD: 0.5, StatusCode: 200, Metadata: map[string]string{"bead_id": "bd-2", "action_type": "read_file"}})
+
+	stats, err := storage.GetLogStats(ctx, &LogFilter{})
+	if err != nil {
+		t.Fatalf("GetLogStats failed: %v", err)
+	}
+	if stats.CostByBead["bd-1"] != 4 || stats.TokensByBead["bd-2"] != 50 {
+		t.Errorf("by bead = %v / %v", stats.CostByBead, stats.TokensByBead)
+	}
+	if stats.TokensByActionType["read_file"] != 150 {
+		t.Errorf("TokensByActionType[read_file] = %d, want 150", stats.TokensByActionType["read_file"])
+	}
+
+	stats, err = storage.GetLogStats(ctx, &LogFilter{BeadID: "bd-1"})
+	if err != nil {
+		t.Fatalf("GetLogStats by bead failed: %v", err)
+	}
+	if stats.TotalTokens != 400 || stats.TokensByActionType["edit_code"] != 300 {
+		t.Errorf("bd-1 stats = %d tokens, %v", stats.TotalTokens, stats.TokensByActionType)
+	}
+
+	logs, err := storage.GetLogs(ctx, &LogFilter{BeadID: "bd-2"})
+	if err != nil {
+		t.Fatalf("GetLogs by bead failed: %v", err)
+	}
+	if len(logs) != 1 || logs[0].ActionType != "read_file" {
+		t.Errorf("GetLogs(bd-2) = %+v, want b3 with its action type", logs)
+	}
+}
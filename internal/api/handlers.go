@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
@@ -93,14 +94,33 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 		} else {
 			agents = s.app.GetAgentManager().ListAgents()
 		}
+		ids := make([]string, len(agents))
+		for i, a := range agents {
+			ids[i] = a.ID
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceAgents, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if visible != nil {
+			inOrg := make([]*models.Agent, 0, len(agents))
+			for _, a := range agents {
+				if visible[a.ID] {
+					inOrg = append(inOrg, a)
+				}
+			}
+			agents = inOrg
+		}
 		s.respondJSON(w, http.StatusOK, agents)
 
 	case http.MethodPost:
 		var req struct {
-			Name        string `json:"name"`
-			PersonaName string `json:"persona_name"`
-			ProjectID   string `json:"project_id"`
-			ProviderID  string `json:"provider_id"`
+			Name         string   `json:"name"`
+			PersonaName  string   `json:"persona_name"`
+			ProjectID    string   `json:"project_id"`
+			ProviderID   string   `json:"provider_id"`
+			Capabilities []string `json:"capabilities"`
 		}
 		if err := s.parseJSON(r, &req); err != nil {
 			s.respondError(w, http.StatusBadRequest, "Invalid request body")
@@ -111,6 +131,10 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 			s.respondError(w, http.StatusBadRequest, "persona_name and project_id are required")
 			return
 		}
+		if !s.checkOrg(w, r, database.OrgResourceProjects, req.ProjectID) ||
+			!s.checkOrg(w, r, database.OrgResourceProviders, req.ProviderID) {
+			return
+		}
 
 		// Normalize persona name: prepend "default/" if not a namespaced path
 		personaName := req.PersonaName
@@ -123,6 +147,12 @@ func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
 			s.respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		if len(req.Capabilities) > 0 {
+			if err := s.app.GetAgentManager().UpdateAgentCapabilities(agent.ID, req.Capabilities); err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
 
 		s.respondJSON(w, http.StatusCreated, agent)
 
@@ -154,8 +184,9 @@ func (s *Server) handleAgent(w http.ResponseWriter, r *http.Request) {
 
 	case http.MethodPut:
 		var req struct {
-			Name    string          `json:"name"`
-			Persona *models.Persona `json:"persona"`
+			Name         string          `json:"name"`
+			Persona      *models.Persona `json:"persona"`
+			Capabilities *[]string       `json:"capabilities"`
 		}
 		if err := s.parseJSON(r, &req); err != nil {
 			s.respondError(w, http.StatusBadRequest, "Invalid request body")
@@ -191,6 +222,13 @@ func (s *Server) handleAgent(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if req.Capabilities != nil {
+			if err := s.app.GetAgentManager().UpdateAgentCapabilities(id, *req.Capabilities); err != nil {
+				s.respondError(w, http.StatusNotFound, "Agent not found")
+				return
+			}
+		}
+
 		// Write-through cache: Persist to database
 		// Note: agent is already updated in-memory since GetAgent returns a pointer to the cached object
 		if s.app.GetDatabase() != nil {
@@ -218,11 +256,44 @@ func (s *Server) handleAgentAction(w http.ResponseWriter, r *http.Request, id, a
 	switch action {
 	case "clone":
 		s.handleCloneAgent(w, r, id)
+	case "pause", "drain", "resume":
+		s.handleAgentHold(w, r, id, action)
+	case "stream":
+		s.handleAgentStream(w, r, id)
 	default:
 		s.respondError(w, http.StatusNotFound, "Unknown action")
 	}
 }
 
+// handleAgentHold handles POST /api/v1/agents/{id}/pause|drain|resume.
+// Pause stops new work and cancels the running task; drain lets the current
+// bead finish first; resume lifts either.
+func (s *Server) handleAgentHold(w http.ResponseWriter, r *http.Request, id, action string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.app.GetAgentManager()
+	var (
+		agent *models.Agent
+		err   error
+	)
+	switch action {
+	case "pause":
+		agent, err = mgr.PauseAgent(id)
+	case "drain":
+		agent, err = mgr.DrainAgent(id)
+	default:
+		agent, err = mgr.ResumeAgent(id)
+	}
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Agent not found")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, agent)
+}
+
 func (s *Server) handleCloneAgent(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodPost {
 		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -258,11 +329,39 @@ func (s *Server) handleCloneAgent(w http.ResponseWriter, r *http.Request, id str
 	s.respondJSON(w, http.StatusCreated, agent)
 }
 
-// handleProjects handles GET/POST /api/v1/projects
+// handleProjects handles GET/POST /api/v1/projects. Archived projects are
+// listed only with ?include_archived=true.
 func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		projects := s.app.GetProjectManager().ListProjects()
+		if r.URL.Query().Get("include_archived") != "true" {
+			active := projects[:0:0]
+			for _, p := range projects {
+				if p.Status != models.ProjectStatusArchived {
+					active = append(active, p)
+				}
+			}
+			projects = active
+		}
+		ids := make([]string, len(projects))
+		for i, p := range projects {
+			ids[i] = p.ID
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceProjects, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if visible != nil {
+			inOrg := projects[:0:0]
+			for _, p := range projects {
+				if visible[p.ID] {
+					inOrg = append(inOrg, p)
+				}
+			}
+			projects = inOrg
+		}
 		s.respondJSON(w, http.StatusOK, projects)
 
 	case http.MethodPost:
@@ -317,10 +416,22 @@ func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
 			s.handleProjectFiles(w, r, id, parts[2:])
 			return
 		}
+		if action == "orgchart" {
+			s.handleProjectOrgChart(w, r, id, parts[2:])
+			return
+		}
+		if action == "agents" && len(parts) > 2 && parts[2] == "scale" {
+			s.handleProjectAgentsScale(w, r, id)
+			return
+		}
 		if action == "beads" && len(parts) > 2 && parts[2] == "reset" {
 			s.handleProjectBeadsReset(w, r, id)
 			return
 		}
+		if action == "graph" && len(parts) > 2 && parts[2] == "analysis" {
+			s.handleProjectGraphAnalysis(w, r, id)
+			return
+		}
 		s.handleProjectStateEndpoints(w, r, id, action)
 		return
 	}
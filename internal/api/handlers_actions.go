@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+)
+
+// handleValidateActions handles POST /api/v1/actions/validate. It dry-runs an
+// action envelope through the action router: nothing is executed, and the
+// response is a plan of the files each action would write or delete, the
+// commands it would run, and the beads it would create or change. The
+// envelope is given either as "actions" (and "notes"), or as "response", a
+// raw model response parsed the way agent responses are.
+func (s *Server) handleValidateActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		ProjectID string           `json:"project_id"`
+		BeadID    string           `json:"bead_id"`
+		AgentID   string           `json:"agent_id"`
+		Actions   []actions.Action `json:"actions"`
+		Notes     string           `json:"notes"`
+		Response  string           `json:"response"`
+	}
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	env := &actions.ActionEnvelope{Actions: req.Actions, Notes: req.Notes}
+	if len(env.Actions) == 0 {
+		if req.Response == "" {
+			s.respondError(w, http.StatusBadRequest, "actions or response is required")
+			return
+		}
+		parsed, err := actions.ParseSimpleJSON([]byte(req.Response))
+		if err != nil {
+			if parsed, err = actions.DecodeLenient([]byte(req.Response)); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Could not parse actions: "+err.Error())
+				return
+			}
+		}
+		env = parsed
+	}
+
+	router := s.app.GetActionRouter()
+	if router == nil {
+		router = &actions.Router{}
+	}
+	plan := router.DryRun(env, actions.ActionContext{
+		AgentID:   req.AgentID,
+		BeadID:    req.BeadID,
+		ProjectID: req.ProjectID,
+		DryRun:    true,
+	})
+	s.respondJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/loom"
+)
+
+// maintenanceExemptPrefixes are mutating routes still served in
+// maintenance mode: administration such as switching it off, migrating
+// the schema or restoring a backup, signing in, and project agents reporting on the beads that
+// were already in flight.
+var maintenanceExemptPrefixes = []string{
+	"/api/v1/admin/",
+	"/api/v1/auth/",
+	"/api/v1/project-agents/",
+}
+
+// maintenanceMiddleware rejects mutating API requests with 503 while the
+// server is in maintenance mode. Reads are served as usual.
+func (s *Server) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) || !strings.HasPrefix(r.URL.Path, "/api/") || s.app == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range maintenanceExemptPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		mode := s.app.ServerMode()
+		if !mode.Maintenance() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		msg := "Server is in maintenance mode; only reads are allowed"
+		if mode.Reason != "" {
+			msg += ": " + mode.Reason
+		}
+		w.Header().Set("Retry-After", "60")
+		s.respondError(w, http.StatusServiceUnavailable, msg)
+	})
+}
+
+// serverMode is the server's mode, or normal when there is no app.
+func (s *Server) serverMode() loom.ServerMode {
+	if s.app == nil {
+		return loom.ServerMode{Mode: loom.ModeNormal}
+	}
+	return s.app.ServerMode()
+}
+
+// handleAdminMode handles GET and PUT /api/v1/admin/mode. PUT takes
+// {"mode": "normal"|"maintenance", "reason": "..."}.
+func (s *Server) handleAdminMode(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.respondJSON(w, http.StatusOK, s.serverMode())
+	case http.MethodPut:
+		var req struct {
+			Mode   string `json:"mode"`
+			Reason string `json:"reason"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.Mode != loom.ModeNormal && req.Mode != loom.ModeMaintenance {
+			s.respondError(w, http.StatusBadRequest, "mode must be \"normal\" or \"maintenance\"")
+			return
+		}
+		mode, err := s.app.SetServerMode(req.Mode, strings.TrimSpace(req.Reason), requestActor(r))
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, mode)
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// MigrationsStatus is the schema's migration state.
+type MigrationsStatus struct {
+	SchemaVersion int                        `json:"schema_version"`
+	LatestVersion int                        `json:"latest_version"`
+	Pending       int                        `json:"pending"`
+	Manual        bool                       `json:"manual"` // database.manual_migrations
+	Migrations    []database.MigrationStatus `json:"migrations"`
+}
+
+// MigrationsResult is what a migration request ran, or would run with
+// dry_run.
+type MigrationsResult struct {
+	DryRun        bool                     `json:"dry_run"`
+	Steps         []database.MigrationStep `json:"steps"`
+	SchemaVersion int                      `json:"schema_version"`
+}
+
+// handleAdminMigrations handles GET /api/v1/admin/migrations and POST
+// /api/v1/admin/migrations/{up,down}. The POSTs take {"to": version,
+// "dry_run": bool}; up defaults to the latest version and down to rolling
+// back the newest migration. Applying a migration requires maintenance
+// mode, so nothing writes while the schema changes.
+func (s *Server) handleAdminMigrations(w http.ResponseWriter, r *http.Request) {
+	direction := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/migrations"), "/")
+	switch {
+	case direction == "" && r.Method == http.MethodGet:
+	case (direction == "up" || direction == "down") && r.Method == http.MethodPost:
+	case direction == "" || direction == "up" || direction == "down":
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var req struct {
+		To     *int `json:"to"`
+		DryRun bool `json:"dry_run"`
+	}
+	if r.Method == http.MethodPost {
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.To != nil && *req.To < 0 {
+			s.respondError(w, http.StatusBadRequest, "to must not be negative")
+			return
+		}
+	}
+
+	db := s.app.GetDatabase()
+	if db == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "No database configured")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		status, err := db.MigrationStatus()
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := MigrationsStatus{
+			LatestVersion: db.LatestVersion(),
+			Manual:        s.config != nil && s.config.Database.ManualMigrations,
+			Migrations:    status,
+		}
+		for _, m := range status {
+			if m.Applied {
+				resp.SchemaVersion = max(resp.SchemaVersion, m.Version)
+			} else {
+				resp.Pending++
+			}
+		}
+		s.respondJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	to := 0
+	if req.To != nil {
+		to = *req.To
+	} else if direction == "down" {
+		current, err := db.SchemaVersion()
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		to = max(current-1, 0)
+	}
+
+	plan, migrate := db.PlanMigrateUp, db.MigrateUp
+	if direction == "down" {
+		plan, migrate = db.PlanMigrateDown, db.MigrateDown
+	}
+	steps, err := plan(to)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !req.DryRun {
+		if !s.serverMode().Maintenance() {
+			s.respondError(w, http.StatusConflict, "Switch maintenance mode on before migrating, or pass dry_run to see the plan")
+			return
+		}
+		if steps, err = migrate(to); err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	version, err := db.SchemaVersion()
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, MigrationsResult{DryRun: req.DryRun, Steps: steps, SchemaVersion: version})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAdminMode_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/mode", nil)
+	w := httptest.NewRecorder()
+	s.handleAdminMode(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestHandleAdminMode_GET(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/mode", nil)
+	w := httptest.NewRecorder()
+	s.handleAdminMode(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["mode"] != "normal" {
+		t.Errorf("mode = %v, want normal", body["mode"])
+	}
+}
+
+func TestHandleAdminMode_PUT_Invalid(t *testing.T) {
+	s := newTestServer()
+	for _, body := range []string{`not json`, `{"mode":"read-only"}`, `{}`} {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/mode", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleAdminMode(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestHandleHealth_Mode(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+	s.handleHealth(w, req)
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["mode"] != "normal" {
+		t.Errorf("mode = %v, want normal", body["mode"])
+	}
+}
+
+func TestHandleAdminMigrations_Routing(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodPost, "/api/v1/admin/migrations", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/admin/migrations/up", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/admin/migrations/sideways", "{}", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/admin/migrations/up", "not json", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/migrations/down", `{"to": -1}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		s.handleAdminMigrations(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
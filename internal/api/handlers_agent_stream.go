@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+// agentStreamEvents maps the event bus events an agent stream carries to
+// the SSE event names it sends them as.
+var agentStreamEvents = map[eventbus.EventType]string{
+	eventbus.EventTypeAgentOutput:       "output",
+	eventbus.EventTypeAgentIteration:    "actions",
+	eventbus.EventTypeAgentStatusChange: "status",
+}
+
+// handleAgentStream handles GET /api/v1/agents/{id}/stream. It streams what
+// the agent is doing as it happens: "output" events carry the model's text
+// as it is generated, "actions" events each loop iteration's actions and
+// their results, and "status" events the agent's status changes, so a
+// watcher sees when it picks up or finishes a bead.
+func (s *Server) handleAgentStream(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	agent, err := s.app.GetAgentManager().GetAgent(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Agent not found")
+		return
+	}
+
+	eventBus := s.app.GetEventBus()
+	if eventBus == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Event bus not available")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.respondError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	subscriberID := fmt.Sprintf("agent-stream-%s-%d", id, time.Now().UnixNano())
+	subscriber := eventBus.Subscribe(subscriberID, func(event *eventbus.Event) bool {
+		if _, ok := agentStreamEvents[event.Type]; !ok {
+			return false
+		}
+		agentID, _ := event.Data["agent_id"].(string)
+		return agentID == id
+	})
+	defer eventBus.Unsubscribe(subscriberID)
+
+	connected, _ := json.Marshal(map[string]interface{}{
+		"agent_id":     agent.ID,
+		"name":         agent.Name,
+		"status":       agent.Status,
+		"hold":         agent.Hold,
+		"current_bead": agent.CurrentBead,
+	})
+	fmt.Fprintf(w, "event: connected\ndata: %s\n\n", connected)
+	flusher.Flush()
+
+	ctx := r.Context()
+	keepalive := time.NewTicker(10 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-subscriber.Channel:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", agentStreamEvents[event.Type], data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
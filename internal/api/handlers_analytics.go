@@ -10,6 +10,8 @@ import (
 
 	"github.com/jordanhubbard/loom/internal/analytics"
 	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
 )
 
 // handleGetLogs handles GET /api/v1/analytics/logs
@@ -184,7 +186,11 @@ func (s *Server) handleGetCostReport(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query parameters
 	filter := &analytics.LogFilter{
-		UserID: userID, // Users can only see their own costs by default (or all if auth disabled)
+		UserID:     userID, // Users can only see their own costs by default (or all if auth disabled)
+		BeadID:     r.URL.Query().Get("bead_id"),
+		AgentID:    r.URL.Query().Get("agent_id"),
+		ProjectID:  r.URL.Query().Get("project_id"),
+		ActionType: r.URL.Query().Get("action_type"),
 	}
 
 	if startTime := r.URL.Query().Get("start_time"); startTime != "" {
@@ -231,8 +237,12 @@ func (s *Server) handleGetCostReport(w http.ResponseWriter, r *http.Request) {
 			}
 			return 0.0
 		}(),
-		"cost_by_provider": stats.CostByProvider,
-		"cost_by_user":     stats.CostByUser,
+		"cost_by_provider":      stats.CostByProvider,
+		"cost_by_user":          stats.CostByUser,
+		"cost_by_bead":          stats.CostByBead,
+		"tokens_by_bead":        stats.TokensByBead,
+		"cost_by_action_type":   stats.CostByActionType,
+		"tokens_by_action_type": stats.TokensByActionType,
 		"time_range": map[string]interface{}{
 			"start": filter.StartTime,
 			"end":   filter.EndTime,
@@ -529,3 +539,197 @@ func (s *Server) handleGetChangeVelocity(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// handleGetAgentScoreboard handles GET /api/v1/analytics/agents
+// Query params: project_id (optional), window (default 7d; e.g. 24h, 30d)
+func (s *Server) handleGetAgentScoreboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	window := 7 * 24 * time.Hour
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := analytics.ParseWindow(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	if s.analyticsLogger == nil {
+		http.Error(w, "Analytics unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-window)
+	logs, err := s.analyticsLogger.GetLogs(r.Context(), &analytics.LogFilter{StartTime: since})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	projectID := r.URL.Query().Get("project_id")
+	var beads []*models.Bead
+	if bm := s.app.GetBeadsManager(); bm != nil {
+		filters := map[string]interface{}{}
+		if projectID != "" {
+			filters["project_id"] = projectID
+		}
+		if beads, err = bm.ListBeads(filters); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	var agents []*models.Agent
+	if am := s.app.GetAgentManager(); am != nil {
+		agents = am.ListAgents()
+	}
+
+	board := analytics.BuildAgentScoreboard(logs, beads, agents, projectID, since, now)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(board); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handleGetCostForecast handles GET /api/v1/analytics/forecast
+// Query params: lookback (default 14d; e.g. 7d, 72h)
+func (s *Server) handleGetCostForecast(w http.ResponseWriter, r *http.Request) {
+	var watcher *analytics.CostWatcher
+	if s.app != nil {
+		watcher = s.app.GetCostWatcher()
+	}
+	s.costForecast(w, r, watcher)
+}
+
+func (s *Server) costForecast(w http.ResponseWriter, r *http.Request, watcher *analytics.CostWatcher) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var lookback time.Duration
+	if v := r.URL.Query().Get("lookback"); v != "" {
+		d, err := analytics.ParseWindow(v)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		lookback = d
+	}
+	if watcher == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Analytics unavailable")
+		return
+	}
+	forecast, err := watcher.Forecast(r.Context(), lookback)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, forecast)
+}
+
+// flowQuery reads the bead selector and look-back window shared by the
+// cycle-time and burndown endpoints, and lists the selected beads the
+// request's organization can see. It responds with an error and returns
+// false if the query is invalid.
+func (s *Server) flowQuery(w http.ResponseWriter, r *http.Request) (analytics.BeadSelector, time.Duration, []*models.Bead, bool) {
+	q := r.URL.Query()
+	sel := analytics.BeadSelector{
+		ProjectID:   q.Get("project_id"),
+		Tag:         q.Get("tag"),
+		MilestoneID: q.Get("milestone_id"),
+	}
+	window := 30 * 24 * time.Hour
+	if v := q.Get("window"); v != "" {
+		d, err := analytics.ParseWindow(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return sel, 0, nil, false
+		}
+		window = d
+	}
+
+	bm := s.app.GetBeadsManager()
+	if bm == nil {
+		http.Error(w, "Beads unavailable", http.StatusServiceUnavailable)
+		return sel, 0, nil, false
+	}
+	filters := map[string]interface{}{}
+	if sel.ProjectID != "" {
+		filters["project_id"] = sel.ProjectID
+	}
+	beads, err := bm.ListBeads(filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return sel, 0, nil, false
+	}
+	projectIDs := make([]string, 0, len(beads))
+	for _, b := range beads {
+		projectIDs = append(projectIDs, b.ProjectID)
+	}
+	visible, err := s.orgVisible(r, database.OrgResourceProjects, projectIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return sel, 0, nil, false
+	}
+	if visible != nil {
+		kept := beads[:0]
+		for _, b := range beads {
+			if visible[b.ProjectID] {
+				kept = append(kept, b)
+			}
+		}
+		beads = kept
+	}
+	return sel, window, beads, true
+}
+
+// handleGetCycleTime handles GET /api/v1/analytics/cycle-time
+// Query params: project_id, tag, milestone_id (all optional), window
+// (default 30d)
+func (s *Server) handleGetCycleTime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sel, window, beads, ok := s.flowQuery(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	metrics := analytics.BuildFlowMetrics(beads, sel, now.Add(-window), now)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metrics); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handleGetBurndown handles GET /api/v1/analytics/burndown
+// Query params: tag or milestone_id (one is required), project_id, window
+// (default 30d)
+func (s *Server) handleGetBurndown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Get("tag") == "" && r.URL.Query().Get("milestone_id") == "" {
+		http.Error(w, "tag or milestone_id is required", http.StatusBadRequest)
+		return
+	}
+	sel, window, beads, ok := s.flowQuery(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	burndown := analytics.BuildBurndown(beads, sel, now.Add(-window), now)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(burndown); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auditlog"
+	"github.com/jordanhubbard/loom/internal/auth"
+)
+
+// auditSkipPrefixes are mutating routes driven by machines rather than
+// people, left out of the audit log because they would drown it.
+var auditSkipPrefixes = []string{
+	"/api/v1/project-agents/",
+	"/api/v1/auth/refresh",
+}
+
+// auditMiddleware records every mutating API request in the audit log.
+// It runs inside authMiddleware so the actor headers are already set.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) || !strings.HasPrefix(r.URL.Path, "/api/") || s.app == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range auditSkipPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		auditLog := s.app.GetAuditLog()
+		if auditLog == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resourceType, resourceID := apiResource(r.URL.Path)
+		snapshot := s.auditSnapshotFunc(resourceType)
+		var before json.RawMessage
+		if snapshot != nil && resourceID != "" {
+			before = snapshot(resourceID)
+		}
+
+		// Capture the response of creates so the new resource's ID can be
+		// read from it.
+		recorder := &statusRecorder{
+			ResponseWriter: w,
+			captureBody:    snapshot != nil && resourceID == "" && r.Method == http.MethodPost,
+		}
+		next.ServeHTTP(recorder, r)
+		statusCode := recorder.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		entry := &auditlog.Entry{
+			ActorID:      auth.GetUserIDFromRequest(r),
+			ActorName:    auth.GetUsernameFromRequest(r),
+			ActorRole:    auth.GetRoleFromRequest(r),
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			StatusCode:   statusCode,
+			RemoteAddr:   r.RemoteAddr,
+		}
+		if snapshot != nil && statusCode < http.StatusBadRequest {
+			if entry.ResourceID == "" && recorder.captureBody {
+				var created struct {
+					ID string `json:"id"`
+				}
+				if json.Unmarshal(recorder.body.Bytes(), &created) == nil {
+					entry.ResourceID = created.ID
+				}
+			}
+			entry.Before = before
+			if entry.ResourceID != "" {
+				entry.After = snapshot(entry.ResourceID)
+			}
+		}
+		if err := auditLog.Record(entry); err != nil {
+			log.Printf("[Audit] Failed to record %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	})
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// apiResource splits /api/v1/{type}/{id}/... into the resource type and
+// ID. Either may be empty.
+func apiResource(path string) (resourceType, resourceID string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1/"), "/"), "/")
+	if len(parts) > 0 {
+		resourceType = parts[0]
+	}
+	if len(parts) > 1 {
+		resourceID = parts[1]
+	}
+	return resourceType, resourceID
+}
+
+// auditSnapshotFunc returns a function that loads a resource for the
+// audit log's before and after snapshots, or nil for resource types that
+// aren't snapshotted. The function returns nil if the resource doesn't
+// exist.
+func (s *Server) auditSnapshotFunc(resourceType string) func(id string) json.RawMessage {
+	var load func(id string) (interface{}, error)
+	switch resourceType {
+	case "beads":
+		load = func(id string) (interface{}, error) { return s.app.GetBeadsManager().GetBead(id) }
+	case "projects":
+		load = func(id string) (interface{}, error) { return s.app.GetProjectManager().GetProject(id) }
+	case "users":
+		if s.authManager == nil {
+			return nil
+		}
+		load = func(id string) (interface{}, error) { return s.authManager.GetUser(id) }
+	case "providers":
+		load = func(id string) (interface{}, error) {
+			providers, err := s.app.ListProviders()
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range providers {
+				if p.ID == id {
+					return p, nil
+				}
+			}
+			return nil, nil
+		}
+	default:
+		return nil
+	}
+	return func(id string) json.RawMessage {
+		v, err := load(id)
+		if err != nil || v == nil {
+			return nil
+		}
+		snapshot, err := auditlog.Snapshot(v)
+		if err != nil {
+			log.Printf("[Audit] Failed to snapshot %s %s: %v", resourceType, id, err)
+			return nil
+		}
+		return snapshot
+	}
+}
+
+// handleAudit handles GET /api/v1/audit. Entries are filtered by
+// ?actor= (user ID or name), resource_type, resource_id, method, since and
+// until (RFC3339 timestamps, or for since a duration such as 24h or 7d),
+// and limit.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	filter := auditlog.Filter{
+		Actor:        q.Get("actor"),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+		Method:       q.Get("method"),
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := parseSince(v, time.Now())
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.Since = since
+	}
+	if v := q.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "until must be an RFC3339 timestamp")
+			return
+		}
+		filter.Until = until
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			s.respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = n
+	}
+	if s.app == nil || s.app.GetAuditLog() == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "The audit log requires a database")
+		return
+	}
+
+	entries, err := s.app.GetAuditLog().List(filter)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, entries)
+}
+
+// parseSince accepts an RFC3339 timestamp or a duration before now. On top
+// of Go durations, a "d" suffix counts days.
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("since must be an RFC3339 timestamp or a duration such as 24h or 7d, got %q", v)
+}
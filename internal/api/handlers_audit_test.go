@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleAudit_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/audit", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/audit?since=yesterday", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/audit?until=24h", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/audit?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/audit?since=24h", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		s.handleAudit(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"24h", now.Add(-24 * time.Hour)},
+		{"30m", now.Add(-30 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2026-03-01T00:00:00Z", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "-1h", "soon", "1w"} {
+		if _, err := parseSince(in, now); err == nil {
+			t.Errorf("parseSince(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestAPIResource(t *testing.T) {
+	tests := []struct {
+		path, wantType, wantID string
+	}{
+		{"/api/v1/beads", "beads", ""},
+		{"/api/v1/beads/", "beads", ""},
+		{"/api/v1/beads/bd-1/comments", "beads", "bd-1"},
+		{"/api/v1/providers/p1", "providers", "p1"},
+	}
+	for _, tt := range tests {
+		gotType, gotID := apiResource(tt.path)
+		if gotType != tt.wantType || gotID != tt.wantID {
+			t.Errorf("apiResource(%q) = %q, %q; want %q, %q", tt.path, gotType, gotID, tt.wantType, tt.wantID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/backup"
+	"github.com/jordanhubbard/loom/internal/objectstore"
+)
+
+// handleAdminBackups handles the backup routes:
+//
+//	GET  /api/v1/admin/backups               list backups, newest first
+//	POST /api/v1/admin/backups               take a backup now
+//	GET  /api/v1/admin/backups/{id}          a backup's manifest
+//	POST /api/v1/admin/backups/{id}/verify   check a backup's digests and formats
+//	POST /api/v1/admin/backups/{id}/restore  restore from a backup
+//
+// Restore takes {"database": bool, "projects": [...], "all_projects": bool}
+// and requires maintenance mode, so nothing writes while it runs.
+func (s *Server) handleAdminBackups(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/backups"), "/"), "/")
+	id, action := parts[0], ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	switch {
+	case len(parts) > 2 || (action != "" && action != "verify" && action != "restore"):
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	case id == "" && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+	case id != "" && action == "" && r.Method == http.MethodGet:
+	case action != "" && r.Method == http.MethodPost:
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var opts backup.RestoreOptions
+	if action == "restore" {
+		if err := s.parseJSON(r, &opts); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if !opts.Database && !opts.AllProjects && len(opts.Projects) == 0 {
+			s.respondError(w, http.StatusBadRequest, "Select the database, projects or all_projects to restore")
+			return
+		}
+	}
+
+	var m *backup.Manager
+	if s.app != nil {
+		m = s.app.GetBackupManager()
+	}
+	if m == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Backups are not configured")
+		return
+	}
+
+	ctx := r.Context()
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		list, err := m.List(ctx)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, list)
+	case id == "":
+		man, err := m.Create(ctx)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, man)
+	case action == "":
+		man, err := m.Get(ctx, id)
+		if err != nil {
+			s.respondBackupError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, man)
+	case action == "verify":
+		v, err := m.Verify(ctx, id)
+		if err != nil {
+			s.respondBackupError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, v)
+	default:
+		if !s.serverMode().Maintenance() {
+			s.respondError(w, http.StatusConflict, "Switch maintenance mode on before restoring")
+			return
+		}
+		result, err := m.Restore(ctx, id, opts)
+		if err != nil {
+			s.respondBackupError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, result)
+	}
+}
+
+// respondBackupError reports a missing backup as 404 and anything else as
+// a server error.
+func (s *Server) respondBackupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, objectstore.ErrNotFound) {
+		s.respondError(w, http.StatusNotFound, "Backup not found")
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAdminBackups_Routing(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodDelete, "/api/v1/admin/backups", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/admin/backups/20261016T000000Z", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/admin/backups/20261016T000000Z/verify", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/admin/backups/20261016T000000Z/copy", "{}", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/admin/backups/20261016T000000Z/restore/now", "{}", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/admin/backups/20261016T000000Z/restore", "not json", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/admin/backups/20261016T000000Z/restore", "{}", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/admin/backups", "", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		s.handleAdminBackups(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
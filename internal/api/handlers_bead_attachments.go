@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/attachments"
+	"github.com/jordanhubbard/loom/internal/auth"
+)
+
+// multipartOverhead allows for multipart boundaries and headers on top of
+// the attachment size limit.
+const multipartOverhead = 64 << 10
+
+// handleBeadAttachments handles file attachments on a bead
+// GET /api/v1/beads/{id}/attachments - List attachments
+// POST /api/v1/beads/{id}/attachments - Upload (multipart "file" field, or raw body with ?filename=)
+// GET /api/v1/beads/{id}/attachments/{attachmentId} - Download
+// DELETE /api/v1/beads/{id}/attachments/{attachmentId} - Delete
+func (s *Server) handleBeadAttachments(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/beads/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "attachments" || len(parts) > 3 || (len(parts) == 3 && parts[2] == "") {
+		s.respondError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+
+	beadID := parts[0]
+	attachmentID := ""
+	if len(parts) == 3 {
+		attachmentID = parts[2]
+	}
+
+	switch {
+	case r.Method == http.MethodGet:
+	case r.Method == http.MethodPost && attachmentID == "":
+	case r.Method == http.MethodDelete && attachmentID != "":
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.app.GetAttachmentsManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead attachments are not available")
+		return
+	}
+	if _, err := s.app.GetBeadsManager().GetBead(beadID); err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+
+	switch {
+	case attachmentID == "" && r.Method == http.MethodGet:
+		list, err := mgr.List(beadID)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"bead_id":     beadID,
+			"attachments": list,
+		})
+
+	case attachmentID == "":
+		s.handleUploadAttachment(w, r, beadID, mgr)
+
+	case r.Method == http.MethodGet:
+		a, data, err := mgr.Open(attachmentID)
+		if err == nil && a.BeadID != beadID {
+			err = attachments.ErrNotFound
+		}
+		if err != nil {
+			s.respondAttachmentError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", a.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+
+	default:
+		a, err := mgr.Get(attachmentID)
+		if err == nil && a.BeadID != beadID {
+			err = attachments.ErrNotFound
+		}
+		if err == nil {
+			err = mgr.Delete(attachmentID)
+		}
+		if err != nil {
+			s.respondAttachmentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleUploadAttachment(w http.ResponseWriter, r *http.Request, beadID string, mgr *attachments.Manager) {
+	r.Body = http.MaxBytesReader(w, r.Body, mgr.MaxSize()+multipartOverhead)
+
+	req := attachments.AttachRequest{
+		BeadID:     beadID,
+		Source:     "user",
+		UploadedBy: auth.GetUserIDFromRequest(r),
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			s.respondUploadReadError(w, err, "multipart upload requires a \"file\" field")
+			return
+		}
+		defer file.Close()
+		if req.Data, err = io.ReadAll(file); err != nil {
+			s.respondUploadReadError(w, err, "Failed to read upload")
+			return
+		}
+		req.Filename = header.Filename
+		req.ContentType = header.Header.Get("Content-Type")
+	} else {
+		req.Filename = r.URL.Query().Get("filename")
+		if req.Filename == "" {
+			s.respondError(w, http.StatusBadRequest, "filename query parameter is required for raw uploads")
+			return
+		}
+		var err error
+		if req.Data, err = io.ReadAll(r.Body); err != nil {
+			s.respondUploadReadError(w, err, "Failed to read upload")
+			return
+		}
+		req.ContentType = r.Header.Get("Content-Type")
+	}
+
+	a, err := mgr.Attach(req)
+	if err != nil {
+		s.respondAttachmentError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, a)
+}
+
+func (s *Server) respondUploadReadError(w http.ResponseWriter, err error, msg string) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		s.respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Upload exceeds the %d byte limit", maxErr.Limit-multipartOverhead))
+		return
+	}
+	s.respondError(w, http.StatusBadRequest, msg)
+}
+
+func (s *Server) respondAttachmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, attachments.ErrNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, attachments.ErrTooLarge):
+		s.respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, attachments.ErrUnsupportedType):
+		s.respondError(w, http.StatusUnsupportedMediaType, err.Error())
+	case strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "empty"):
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
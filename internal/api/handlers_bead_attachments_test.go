@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleBeadAttachments_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/api/v1/beads/b-1/attachments"},
+		{http.MethodDelete, "/api/v1/beads/b-1/attachments"},
+		{http.MethodPost, "/api/v1/beads/b-1/attachments/a-1"},
+		{http.MethodPatch, "/api/v1/beads/b-1/attachments/a-1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		s.handleBead(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", tt.method, tt.path, w.Code)
+		}
+	}
+}
+
+func TestHandleBeadAttachments_InvalidPath(t *testing.T) {
+	s := newTestServer()
+	for _, path := range []string{
+		"/api/v1/beads/b-1/attachments/",
+		"/api/v1/beads/b-1/attachments/a-1/extra",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		s.handleBeadAttachments(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
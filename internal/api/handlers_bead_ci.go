@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadCI handles GET /api/v1/beads/{id}/ci: the CI checks on the
+// branch the bead's agent pushed, and their combined state.
+func (s *Server) handleBeadCI(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	bead, err := s.app.GetBeadsManager().GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+	status, err := s.app.BeadCIStatus(r.Context(), id)
+	if err != nil {
+		s.respondError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/beadhistory"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadHistory handles GET /api/v1/beads/{id}/history: every recorded
+// field change of a bead, oldest first. The history outlives the bead, so
+// it can still be read after the bead is purged.
+func (s *Server) handleBeadHistory(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := beadhistory.Filter{Field: q.Get("field")}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = since
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			s.respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	history := s.app.GetBeadHistory()
+	if history == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead history not available")
+		return
+	}
+	entries, err := history.List(id, filter)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// The bead's project decides who may read its history; for a purged
+	// bead, the project its history was recorded under.
+	projectID := ""
+	mgr := s.app.GetBeadsManager()
+	if bead, err := mgr.GetBead(id); err == nil {
+		projectID = bead.ProjectID
+	} else if bead, err := mgr.GetTrashedBead(id); err == nil {
+		projectID = bead.ProjectID
+	} else if len(entries) > 0 {
+		projectID = entries[len(entries)-1].ProjectID
+	} else {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, projectID) {
+		return
+	}
+	s.respondJSON(w, http.StatusOK, entries)
+}
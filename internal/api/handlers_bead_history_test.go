@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBeadHistory_Validation(t *testing.T) {
+	tests := []struct {
+		name, method, path string
+		want               int
+	}{
+		{"history is read-only", http.MethodPost, "/api/v1/beads/bd-1/history", http.StatusMethodNotAllowed},
+		{"bad since", http.MethodGet, "/api/v1/beads/bd-1/history?since=yesterday", http.StatusBadRequest},
+		{"bad limit", http.MethodGet, "/api/v1/beads/bd-1/history?limit=0", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			w := httptest.NewRecorder()
+			s.handleBead(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadLoop handles GET /api/v1/beads/{id}/loop: why the bead was
+// flagged as stuck in a loop, if it was, and the loop detectors and
+// thresholds that apply to it.
+func (s *Server) handleBeadLoop(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	bead, err := s.app.GetBeadsManager().GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+	report, err := s.app.GetBeadLoopReport(id)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadMove handles POST /api/v1/beads/{id}/move: {"project_id": "lib"}
+// moves the bead to another project under a new ID with that project's
+// prefix, and returns it. The caller needs access to both projects.
+func (s *Server) handleBeadMove(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req struct {
+		ProjectID string `json:"project_id"`
+	}
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ProjectID == "" {
+		s.respondError(w, http.StatusBadRequest, "project_id is required")
+		return
+	}
+
+	bead, err := s.app.GetBeadsManager().GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) ||
+		!s.checkOrg(w, r, database.OrgResourceProjects, req.ProjectID) {
+		return
+	}
+
+	moved, err := s.app.MoveBead(id, req.ProjectID, requestActor(r))
+	if err != nil {
+		s.respondError(w, beadUpdateStatus(err), err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, moved)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	loominternal "github.com/jordanhubbard/loom/internal/loom"
+)
+
+// handleBeadApplyPatch handles POST /api/v1/beads/{id}/apply-patch: applies
+// the approved proposal's patch for an apply-fix bead, with a three-way
+// merge fallback, runs the project's build and tests, and returns the
+// outcome. It changes the project's code, so it needs beads write
+// permission whatever the route requires.
+func (s *Server) handleBeadApplyPatch(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !s.requirePermission(w, r, "beads:write") {
+		return
+	}
+	bead, err := s.app.GetBeadsManager().GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+	app, err := s.app.ApplyFixPatch(r.Context(), id)
+	if errors.Is(err, loominternal.ErrFixNotApproved) {
+		s.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, app)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadRelations handles /api/v1/beads/{id}/relations:
+//
+//	GET    list the bead's parent, children, blockers, related beads and
+//	       duplicates, with a rollup of its children's progress
+//	POST   add a relation: {"relation": "duplicate_of", "target": "bd-2"}
+//	DELETE remove one: ?relation=duplicate_of&target=bd-2
+//
+// Marking a bead as a duplicate closes it.
+func (s *Server) handleBeadRelations(w http.ResponseWriter, r *http.Request, id string) {
+	var relation, target string
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Relation string `json:"relation"`
+			Target   string `json:"target"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		relation, target = req.Relation, req.Target
+	case http.MethodDelete:
+		relation, target = r.URL.Query().Get("relation"), r.URL.Query().Get("target")
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if r.Method != http.MethodGet && (relation == "" || target == "") {
+		s.respondError(w, http.StatusBadRequest, "relation and target are required")
+		return
+	}
+
+	mgr := s.app.GetBeadsManager()
+	bead, err := mgr.GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+	if target != "" {
+		other, err := mgr.GetBead(target)
+		if err != nil {
+			s.respondError(w, http.StatusNotFound, "Target bead not found")
+			return
+		}
+		if !s.checkOrg(w, r, database.OrgResourceProjects, other.ProjectID) {
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		err = s.app.RelateBeads(id, relation, target, requestActor(r))
+	case http.MethodDelete:
+		err = s.app.UnrelateBeads(id, relation, target, requestActor(r))
+	}
+	if err != nil {
+		s.respondError(w, beadUpdateStatus(err), err.Error())
+		return
+	}
+	relations, err := s.app.GetBeadRelations(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, relations)
+}
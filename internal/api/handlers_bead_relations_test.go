@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBeadRelations_Validation(t *testing.T) {
+	s := newTestServer()
+
+	w := httptest.NewRecorder()
+	s.handleBead(w, httptest.NewRequest(http.MethodPut, "/api/v1/beads/bd-1/relations", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: expected 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	body := strings.NewReader(`{"relation": "duplicate_of"}`)
+	s.handleBead(w, httptest.NewRequest(http.MethodPost, "/api/v1/beads/bd-1/relations", body))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST without target: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleBead(w, httptest.NewRequest(http.MethodDelete, "/api/v1/beads/bd-1/relations?target=bd-2", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("DELETE without relation: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/scheduler"
+)
+
+// handleBeadSchedules handles GET/POST /api/v1/beads/schedules (recurring beads)
+func (s *Server) handleBeadSchedules(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBeadScheduler()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead schedules require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		schedules, err := mgr.List(r.URL.Query().Get("project_id"))
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, schedules)
+
+	case http.MethodPost:
+		var req scheduler.CreateRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectID != "" {
+			if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+				return
+			}
+		}
+		schedule, err := mgr.Create(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, schedule)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBeadSchedule handles GET/DELETE /api/v1/beads/schedules/{id}
+func (s *Server) handleBeadSchedule(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBeadScheduler()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead schedules require a database")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/beads/schedules/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Schedule ID is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		schedule, err := mgr.Get(id)
+		if err != nil {
+			s.respondScheduleError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, schedule)
+
+	case http.MethodDelete:
+		if err := mgr.Delete(id); err != nil {
+			s.respondScheduleError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) respondScheduleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, scheduler.ErrNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/beadtype"
+)
+
+// handleBeadTypes handles GET/POST /api/v1/bead-types. POST defines a bead
+// type, replacing an existing type of the same name; RBAC limits it to
+// roles with system:write, i.e. admins.
+func (s *Server) handleBeadTypes(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBeadTypeManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead types require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		types, err := mgr.List()
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, types)
+
+	case http.MethodPost:
+		var req beadtype.SetRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		t, err := mgr.Set(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, t)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBeadType handles GET/DELETE /api/v1/bead-types/{name}. Deleting a
+// type, limited to admins like defining one, leaves its beads as they are.
+func (s *Server) handleBeadType(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBeadTypeManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Bead types require a database")
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/bead-types/")
+	if name == "" || strings.Contains(name, "/") {
+		s.respondError(w, http.StatusBadRequest, "Bead type name is required")
+		return
+	}
+
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		var t *beadtype.Type
+		if t, err = mgr.Get(name); err == nil {
+			s.respondJSON(w, http.StatusOK, t)
+			return
+		}
+	case http.MethodDelete:
+		if err = mgr.Delete(name); err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if errors.Is(err, beadtype.ErrNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
+}
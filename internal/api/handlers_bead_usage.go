@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// BeadUsage is the token usage and cost attributed to one bead.
+type BeadUsage struct {
+	BeadID             string             `json:"bead_id"`
+	Requests           int64              `json:"requests"`
+	TotalTokens        int64              `json:"total_tokens"`
+	TotalCostUSD       float64            `json:"total_cost_usd"`
+	TokensByActionType map[string]int64   `json:"tokens_by_action_type"`
+	CostByActionType   map[string]float64 `json:"cost_by_action_type"`
+	TokensByProvider   map[string]int64   `json:"tokens_by_provider"`
+	CostByProvider     map[string]float64 `json:"cost_by_provider"`
+}
+
+// handleBeadUsage handles GET /api/v1/beads/{id}/usage: the tokens and
+// cost spent working a bead, broken down by the actions the model asked
+// for and by provider.
+func (s *Server) handleBeadUsage(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	bead, err := s.app.GetBeadsManager().GetBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+	s.beadUsage(w, r, id)
+}
+
+func (s *Server) beadUsage(w http.ResponseWriter, r *http.Request, id string) {
+	if s.analyticsLogger == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Analytics unavailable")
+		return
+	}
+	stats, err := s.analyticsLogger.GetStats(r.Context(), &analytics.LogFilter{BeadID: id})
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, &BeadUsage{
+		BeadID:             id,
+		Requests:           stats.TotalRequests,
+		TotalTokens:        stats.TotalTokens,
+		TotalCostUSD:       stats.TotalCostUSD,
+		TokensByActionType: stats.TokensByActionType,
+		CostByActionType:   stats.CostByActionType,
+		TokensByProvider:   stats.TokensByProvider,
+		CostByProvider:     stats.CostByProvider,
+	})
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBeadUsage_Validation(t *testing.T) {
+	s := newTestServer()
+
+	w := httptest.NewRecorder()
+	s.handleBead(w, httptest.NewRequest(http.MethodPost, "/api/v1/beads/bd-1/usage", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.beadUsage(w, httptest.NewRequest(http.MethodGet, "/api/v1/beads/bd-1/usage", nil), "bd-1")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without analytics: expected 503, got %d", w.Code)
+	}
+}
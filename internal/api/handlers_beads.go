@@ -1,17 +1,31 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/beadtype"
+	"github.com/jordanhubbard/loom/internal/board"
+	"github.com/jordanhubbard/loom/internal/database"
 	loominternal "github.com/jordanhubbard/loom/internal/loom"
+	"github.com/jordanhubbard/loom/internal/milestone"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
+// Page sizes for cursor-paginated GET /api/v1/beads.
+const (
+	defaultBeadPageSize = 100
+	maxBeadPageSize     = 1000
+)
+
 // handleBeads handles GET/POST /api/v1/beads
 func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -38,6 +52,17 @@ func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 				filters["priority"] = models.BeadPriority(p)
 			}
 		}
+		if overdueStr := r.URL.Query().Get("overdue"); overdueStr != "" {
+			overdue, err := strconv.ParseBool(overdueStr)
+			if err != nil {
+				s.respondError(w, http.StatusBadRequest, "overdue must be true or false")
+				return
+			}
+			filters["overdue"] = overdue
+		}
+		if milestoneID := r.URL.Query().Get("milestone_id"); milestoneID != "" {
+			filters["milestone_id"] = milestoneID
+		}
 		if assignedTo != "" {
 			if strings.Contains(assignedTo, ",") {
 				parts := strings.Split(assignedTo, ",")
@@ -55,14 +80,62 @@ func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 				filters["assigned_to"] = assignedTo
 			}
 		}
+		projectIDs, err := s.orgProjectIDs(r)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if projectIDs != nil {
+			filters["project_ids"] = projectIDs
+		}
 
-		beads, err := s.app.GetBeadsManager().ListBeads(filters)
+		// Without limit/cursor the full list is returned as a bare array, as
+		// before; with either, the response is a page with a next_cursor.
+		limitStr := r.URL.Query().Get("limit")
+		cursor := r.URL.Query().Get("cursor")
+		if limitStr == "" && cursor == "" {
+			beads, err := s.app.GetBeadsManager().ListBeads(filters)
+			if err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if responses := s.beadResponses(beads); responses != nil {
+				s.respondJSON(w, http.StatusOK, responses)
+				return
+			}
+			s.respondJSON(w, http.StatusOK, beads)
+			return
+		}
+
+		limit := defaultBeadPageSize
+		if limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed <= 0 {
+				s.respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			limit = min(parsed, maxBeadPageSize)
+		}
+
+		page, next, err := s.app.GetBeadsManager().ListBeadsPage(filters, limit, cursor)
 		if err != nil {
+			if errors.Is(err, beads.ErrInvalidCursor) {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			s.respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 
-		s.respondJSON(w, http.StatusOK, beads)
+		var pageBeads interface{} = page
+		if responses := s.beadResponses(page); responses != nil {
+			pageBeads = responses
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"beads":       pageBeads,
+			"count":       len(page),
+			"next_cursor": next,
+		})
 
 	case http.MethodPost:
 		var req struct {
@@ -74,6 +147,8 @@ func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 			Parent      string            `json:"parent"`
 			Tags        []string          `json:"tags"`
 			Context     map[string]string `json:"context"`
+			DueDate     *dueDate          `json:"due_date,omitempty"`
+			MilestoneID string            `json:"milestone_id"`
 		}
 		if err := s.parseJSON(r, &req); err != nil {
 			s.respondError(w, http.StatusBadRequest, "Invalid request body")
@@ -84,20 +159,63 @@ func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 			s.respondError(w, http.StatusBadRequest, "title and project_id are required")
 			return
 		}
+		if !s.checkOrg(w, r, database.OrgResourceProjects, req.ProjectID) {
+			return
+		}
 
 		if req.Type == "" {
 			req.Type = "task"
 		}
-		priority := 2
+		priority := models.BeadPriorityP2
 		if req.Priority != nil {
-			priority = *req.Priority
+			priority = models.BeadPriority(*req.Priority)
+		}
+		if types := s.app.GetBeadTypeManager(); types != nil {
+			resolved, err := types.Resolve(req.Type, req.Priority, req.Context)
+			if err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, beadtype.ErrInvalidBead) {
+					status = http.StatusBadRequest
+				}
+				s.respondError(w, status, err.Error())
+				return
+			}
+			priority = resolved
+		}
+
+		if req.MilestoneID != "" {
+			mgr := s.app.GetMilestoneManager()
+			if mgr == nil {
+				s.respondError(w, http.StatusBadRequest, "Milestones require a database")
+				return
+			}
+			if err := mgr.CheckAssign(req.MilestoneID, req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 		}
 
-		bead, err := s.app.CreateBead(req.Title, req.Description, models.BeadPriority(priority), req.Type, req.ProjectID)
+		bead, err := s.app.CreateBead(req.Title, req.Description, priority, req.Type, req.ProjectID)
 		if err != nil {
 			s.respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		updates := make(map[string]interface{})
+		if len(req.Context) > 0 {
+			updates["context"] = req.Context
+		}
+		if req.DueDate != nil && req.DueDate.t != nil {
+			updates["due_date"] = req.DueDate.t
+		}
+		if req.MilestoneID != "" {
+			updates["milestone_id"] = req.MilestoneID
+		}
+		if len(updates) > 0 {
+			if bead, err = s.app.UpdateBead(bead.ID, updates); err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
 
 		// Wake the executor for this project in case workers are sleeping
 		s.app.WakeProject(req.ProjectID)
@@ -105,33 +223,234 @@ func (s *Server) handleBeads(w http.ResponseWriter, r *http.Request) {
 		s.respondJSON(w, http.StatusCreated, bead)
 
 	case http.MethodPatch:
-		var req struct {
-			Filter  map[string]interface{} `json:"filter"`
-			Updates map[string]interface{} `json:"updates"`
-		}
-		if err := s.parseJSON(r, &req); err != nil {
-			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		// PATCH /api/v1/beads is the bulk update under another name.
+		s.handleBeadsBulk(w, r)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBeadSearch handles GET /api/v1/beads/search
+func (s *Server) handleBeadSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	query := beads.SearchQuery{
+		Text:      q.Get("q"),
+		ProjectID: q.Get("project_id"),
+	}
+	for _, st := range splitCSV(q.Get("status")) {
+		query.Statuses = append(query.Statuses, models.BeadStatus(st))
+	}
+	query.Tags = splitCSV(q.Get("tags"))
+	projectIDs, err := s.orgProjectIDs(r)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	query.ProjectIDs = projectIDs
+
+	for param, dst := range map[string]**time.Time{
+		"created_after":  &query.CreatedAfter,
+		"created_before": &query.CreatedBefore,
+		"updated_after":  &query.UpdatedAfter,
+		"updated_before": &query.UpdatedBefore,
+	} {
+		raw := q.Get(param)
+		if raw == "" {
+			continue
+		}
+		t, err := parseSearchTime(raw)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %v", param, err))
 			return
 		}
+		*dst = &t
+	}
 
-		if len(req.Filter) == 0 || len(req.Updates) == 0 {
-			s.respondError(w, http.StatusBadRequest, "filter and updates are required")
+	if limitStr := q.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			s.respondError(w, http.StatusBadRequest, "limit must be a non-negative integer")
 			return
 		}
+		query.Limit = limit
+	}
+
+	results := s.app.GetBeadsManager().SearchBeads(query)
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"query":   query.Text,
+		"count":   len(results),
+		"results": results,
+	})
+}
+
+// parseSearchTime accepts RFC3339 timestamps or plain YYYY-MM-DD dates.
+func parseSearchTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// splitCSV splits a comma-separated query value, dropping empty entries.
+func splitCSV(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if v := strings.TrimSpace(part); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// beadPatch is the body of a bead update. Fields left out are unchanged;
+// context keys are merged into the bead's context.
+type beadPatch struct {
+	Title       *string           `json:"title"`
+	Type        *string           `json:"type"`
+	Status      *string           `json:"status"`
+	Priority    *int              `json:"priority"`
+	ProjectID   *string           `json:"project_id"`
+	AssignedTo  *string           `json:"assigned_to"`
+	Description *string           `json:"description"`
+	Parent      *string           `json:"parent"`
+	Tags        *[]string         `json:"tags"`
+	BlockedBy   *[]string         `json:"blocked_by"`
+	Blocks      *[]string         `json:"blocks"`
+	RelatedTo   *[]string         `json:"related_to"`
+	Children    *[]string         `json:"children"`
+	Context     map[string]string `json:"context"`
+	DueDate     *dueDate          `json:"due_date"`
+	MilestoneID *string           `json:"milestone_id"`
+}
+
+// dueDate is a bead due date in a request body: RFC 3339, or YYYY-MM-DD for
+// midnight UTC. An empty string clears it.
+type dueDate struct {
+	t *time.Time
+}
+
+func (d *dueDate) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	d.t = nil
+	if raw == "" {
+		return nil
+	}
+	t, err := parseSearchTime(raw)
+	if err != nil {
+		return fmt.Errorf("due_date: %w", err)
+	}
+	t = t.UTC()
+	d.t = &t
+	return nil
+}
+
+// updates returns the patch in the form the beads manager applies.
+func (p beadPatch) updates() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if p.Title != nil {
+		updates["title"] = *p.Title
+	}
+	if p.Type != nil {
+		updates["type"] = *p.Type
+	}
+	if p.Status != nil {
+		updates["status"] = models.BeadStatus(*p.Status)
+	}
+	if p.Priority != nil {
+		updates["priority"] = models.BeadPriority(*p.Priority)
+	}
+	if p.ProjectID != nil {
+		updates["project_id"] = *p.ProjectID
+	}
+	if p.AssignedTo != nil {
+		updates["assigned_to"] = *p.AssignedTo
+	}
+	if p.Description != nil {
+		updates["description"] = *p.Description
+	}
+	if p.Parent != nil {
+		updates["parent"] = *p.Parent
+	}
+	if p.Tags != nil {
+		updates["tags"] = *p.Tags
+	}
+	if p.BlockedBy != nil {
+		updates["blocked_by"] = *p.BlockedBy
+	}
+	if p.Blocks != nil {
+		updates["blocks"] = *p.Blocks
+	}
+	if p.RelatedTo != nil {
+		updates["related_to"] = *p.RelatedTo
+	}
+	if p.Children != nil {
+		updates["children"] = *p.Children
+	}
+	if p.Context != nil {
+		updates["context"] = p.Context
+	}
+	if p.DueDate != nil {
+		updates["due_date"] = p.DueDate.t
+	}
+	if p.MilestoneID != nil {
+		updates["milestone_id"] = *p.MilestoneID
+	}
+	return updates
+}
 
-		s.respondError(w, http.StatusNotImplemented, "bulk update not implemented")
+// beadActor names who made a bead change through the API, for the bead's
+// history: the user's name, else their ID, else "api" when auth is off.
+func requestActor(r *http.Request) string {
+	if name := auth.GetUsernameFromRequest(r); name != "" {
+		return name
+	}
+	if id := auth.GetUserIDFromRequest(r); id != "" {
+		return id
+	}
+	return "api"
+}
 
+// beadUpdateStatus maps a failed bead update to an HTTP status.
+func beadUpdateStatus(err error) int {
+	switch {
+	case errors.Is(err, board.ErrWIPLimit), errors.Is(err, beads.ErrDependencyCycle):
+		return http.StatusConflict
+	case errors.Is(err, milestone.ErrInvalidAssignment), errors.Is(err, beads.ErrInvalidRelation),
+		errors.Is(err, beads.ErrInvalidMove):
+		return http.StatusBadRequest
+	case errors.Is(err, beads.ErrBeadNotFound), strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
 	default:
-		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return http.StatusInternalServerError
 	}
 }
 
-// handleBead handles GET/PATCH /api/v1/beads/{id}, POST /api/v1/beads/{id}/claim, and PATCH /api/v1/beads for bulk operations
+// handleBead handles GET/PATCH/DELETE /api/v1/beads/{id} and POST /api/v1/beads/{id}/claim
 func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/beads/")
 	parts := strings.Split(path, "/")
 	id := parts[0]
 
+	// Beads of another organization's projects are reported as missing,
+	// whichever sub-resource is asked for.
+	if s.orgScope(r) != nil {
+		if bead, err := s.app.GetBeadsManager().GetBead(id); err == nil && !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+			return
+		}
+	}
+
 	// Handle /conversation endpoint
 	if len(parts) > 1 && parts[1] == "conversation" {
 		s.handleBeadConversation(w, r)
@@ -144,6 +463,60 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Handle /attachments endpoint
+	if len(parts) > 1 && parts[1] == "attachments" {
+		s.handleBeadAttachments(w, r)
+		return
+	}
+
+	// Handle /restore endpoint
+	if len(parts) > 1 && parts[1] == "restore" {
+		s.restoreBead(w, r, id)
+		return
+	}
+
+	// Handle /history endpoint
+	if len(parts) > 1 && parts[1] == "history" {
+		s.handleBeadHistory(w, r, id)
+		return
+	}
+
+	// Handle /loop endpoint
+	if len(parts) > 1 && parts[1] == "loop" {
+		s.handleBeadLoop(w, r, id)
+		return
+	}
+
+	// Handle /ci endpoint
+	if len(parts) > 1 && parts[1] == "ci" {
+		s.handleBeadCI(w, r, id)
+		return
+	}
+
+	// Handle /apply-patch endpoint
+	if len(parts) > 1 && parts[1] == "apply-patch" {
+		s.handleBeadApplyPatch(w, r, id)
+		return
+	}
+
+	// Handle /usage endpoint
+	if len(parts) > 1 && parts[1] == "usage" {
+		s.handleBeadUsage(w, r, id)
+		return
+	}
+
+	// Handle /move endpoint
+	if len(parts) > 1 && parts[1] == "move" {
+		s.handleBeadMove(w, r, id)
+		return
+	}
+
+	// Handle /relations endpoint
+	if len(parts) > 1 && parts[1] == "relations" {
+		s.handleBeadRelations(w, r, id)
+		return
+	}
+
 	// Handle /claim endpoint
 	if len(parts) > 1 && parts[1] == "claim" {
 		if r.Method != http.MethodPost {
@@ -165,7 +538,7 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := s.app.ClaimBead(id, req.AgentID); err != nil {
-			if strings.Contains(err.Error(), "already claimed") || strings.Contains(err.Error(), "already assigned") {
+			if errors.Is(err, board.ErrWIPLimit) || strings.Contains(err.Error(), "already claimed") || strings.Contains(err.Error(), "already assigned") {
 				s.respondError(w, http.StatusConflict, err.Error())
 			} else {
 				s.respondError(w, http.StatusInternalServerError, err.Error())
@@ -212,6 +585,14 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 				"loop_detected":           "false",
 				"loop_detected_reason":    "",
 				"loop_detected_at":        "",
+				"loop_detected_by":        "",
+				"loop_detected_evidence":  "",
+				"action_history":          "",
+				"progress_metrics":        "",
+				"root_cause":              "",
+				"root_cause_remediation":  "",
+				"root_cause_at":           "",
+				"root_cause_for":          "",
 				"error_history":           "[]",
 				"dispatch_count":          "0",
 				"ralph_blocked_at":        "",
@@ -221,7 +602,9 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 
 		bead, err := s.app.UpdateBead(id, updates)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
+			if errors.Is(err, board.ErrWIPLimit) {
+				s.respondError(w, http.StatusConflict, err.Error())
+			} else if strings.Contains(err.Error(), "not found") {
 				s.respondError(w, http.StatusNotFound, err.Error())
 			} else {
 				s.respondError(w, http.StatusInternalServerError, err.Error())
@@ -269,81 +652,24 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 			s.respondError(w, http.StatusNotFound, "Bead not found")
 			return
 		}
+		if responses := s.beadResponses([]*models.Bead{bead}); responses != nil {
+			s.respondJSON(w, http.StatusOK, responses[0])
+			return
+		}
 		s.respondJSON(w, http.StatusOK, bead)
 
 	case http.MethodPatch:
-		var req struct {
-			Title       *string           `json:"title"`
-			Type        *string           `json:"type"`
-			Status      *string           `json:"status"`
-			Priority    *int              `json:"priority"`
-			ProjectID   *string           `json:"project_id"`
-			AssignedTo  *string           `json:"assigned_to"`
-			Description *string           `json:"description"`
-			Parent      *string           `json:"parent"`
-			Tags        *[]string         `json:"tags"`
-			BlockedBy   *[]string         `json:"blocked_by"`
-			Blocks      *[]string         `json:"blocks"`
-			RelatedTo   *[]string         `json:"related_to"`
-			Children    *[]string         `json:"children"`
-			Context     map[string]string `json:"context"`
-		}
+		var req beadPatch
 		if err := s.parseJSON(r, &req); err != nil {
 			s.respondError(w, http.StatusBadRequest, "Invalid request body")
 			return
 		}
 
-		updates := make(map[string]interface{})
-		if req.Title != nil {
-			updates["title"] = *req.Title
-		}
-		if req.Type != nil {
-			updates["type"] = *req.Type
-		}
-		if req.Status != nil {
-			updates["status"] = models.BeadStatus(*req.Status)
-		}
-		if req.Priority != nil {
-			updates["priority"] = models.BeadPriority(*req.Priority)
-		}
-		if req.ProjectID != nil {
-			updates["project_id"] = *req.ProjectID
-		}
-		if req.AssignedTo != nil {
-			updates["assigned_to"] = *req.AssignedTo
-		}
-		if req.Description != nil {
-			updates["description"] = *req.Description
-		}
-		if req.Parent != nil {
-			updates["parent"] = *req.Parent
-		}
-		if req.Tags != nil {
-			updates["tags"] = *req.Tags
-		}
-		if req.BlockedBy != nil {
-			updates["blocked_by"] = *req.BlockedBy
-		}
-		if req.Blocks != nil {
-			updates["blocks"] = *req.Blocks
-		}
-		if req.RelatedTo != nil {
-			updates["related_to"] = *req.RelatedTo
-		}
-		if req.Children != nil {
-			updates["children"] = *req.Children
-		}
-		if req.Context != nil {
-			updates["context"] = req.Context
-		}
-
+		updates := req.updates()
+		updates[beads.UpdatedByKey] = requestActor(r)
 		bead, err := s.app.UpdateBead(id, updates)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				s.respondError(w, http.StatusNotFound, err.Error())
-			} else {
-				s.respondError(w, http.StatusInternalServerError, err.Error())
-			}
+			s.respondError(w, beadUpdateStatus(err), err.Error())
 			return
 		}
 
@@ -356,6 +682,9 @@ func (s *Server) handleBead(w http.ResponseWriter, r *http.Request) {
 
 		s.respondJSON(w, http.StatusOK, bead)
 
+	case http.MethodDelete:
+		s.deleteBead(w, r, id)
+
 	default:
 		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
 	}
@@ -382,6 +711,15 @@ func (s *Server) handleDecisions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	projectIDs, err := s.orgProjectIDs(r)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if projectIDs != nil {
+		filters["project_ids"] = projectIDs
+	}
+
 	decisions, err := s.app.GetDecisionManager().ListDecisions(filters)
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, err.Error())
@@ -397,6 +735,12 @@ func (s *Server) handleDecision(w http.ResponseWriter, r *http.Request) {
 	parts := strings.Split(path, "/")
 	id := parts[0]
 
+	if s.orgScope(r) != nil {
+		if decision, err := s.app.GetDecisionManager().GetDecision(id); err == nil && !s.checkOrg(w, r, database.OrgResourceProjects, decision.ProjectID) {
+			return
+		}
+	}
+
 	// Handle /decide endpoint
 	if len(parts) > 1 && parts[1] == "decide" {
 		if r.Method != http.MethodPost {
@@ -419,8 +763,15 @@ func (s *Server) handleDecision(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if req.DeciderID == "" {
+			req.DeciderID = "user-" + requestActor(r)
+		}
 		if err := s.app.MakeDecision(id, req.DeciderID, req.Decision, req.Rationale); err != nil {
-			s.respondError(w, http.StatusInternalServerError, err.Error())
+			status := http.StatusInternalServerError
+			if errors.Is(err, loominternal.ErrNotApprover) {
+				status = http.StatusForbidden
+			}
+			s.respondError(w, status, err.Error())
 			return
 		}
 
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// maxBulkBeads caps how many beads one bulk update may touch.
+const maxBulkBeads = 1000
+
+// Outcomes of a bead in a bulk update.
+const (
+	bulkUpdated    = "updated"
+	bulkFailed     = "failed"
+	bulkNotApplied = "not_applied" // rolled back, or never tried, because another bead failed
+)
+
+// beadBulkFilter selects the beads of a bulk update, like the search
+// endpoint's query parameters.
+type beadBulkFilter struct {
+	Query     string   `json:"q"`
+	ProjectID string   `json:"project_id"`
+	Status    []string `json:"status"`
+	Tags      []string `json:"tags"`
+}
+
+func (f *beadBulkFilter) empty() bool {
+	return f.Query == "" && f.ProjectID == "" && len(f.Status) == 0 && len(f.Tags) == 0
+}
+
+type beadBulkRequest struct {
+	IDs     []string        `json:"ids"`
+	Filter  *beadBulkFilter `json:"filter"`
+	Updates beadPatch       `json:"updates"`
+	// ContinueOnError updates each bead on its own instead of all or none.
+	ContinueOnError bool `json:"continue_on_error"`
+}
+
+type beadBulkResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type beadBulkResponse struct {
+	Error   string           `json:"error,omitempty"`
+	Matched int              `json:"matched"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Results []beadBulkResult `json:"results"`
+}
+
+// handleBeadsBulk handles POST /api/v1/beads/bulk (and PATCH /api/v1/beads):
+// one patch applied to a list of beads, or to the beads matching a filter.
+// By default the update is all or nothing; with continue_on_error each bead
+// is updated on its own. Either way the response reports every bead.
+func (s *Server) handleBeadsBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req beadBulkRequest
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	switch {
+	case len(req.IDs) > 0 && req.Filter != nil:
+		s.respondError(w, http.StatusBadRequest, "give either ids or filter, not both")
+		return
+	case len(req.IDs) == 0 && req.Filter == nil:
+		s.respondError(w, http.StatusBadRequest, "ids or filter is required")
+		return
+	case req.Filter != nil && req.Filter.empty():
+		s.respondError(w, http.StatusBadRequest, "filter must set at least one of q, project_id, status, or tags")
+		return
+	case len(req.IDs) > maxBulkBeads:
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d beads can be updated at once", maxBulkBeads))
+		return
+	}
+	updates := req.Updates.updates()
+	if len(updates) == 0 {
+		s.respondError(w, http.StatusBadRequest, "updates are required")
+		return
+	}
+	updates[beads.UpdatedByKey] = requestActor(r)
+	if req.Filter != nil && !s.checkOrg(w, r, database.OrgResourceProjects, req.Filter.ProjectID) {
+		return
+	}
+	if req.Updates.ProjectID != nil && !s.checkOrg(w, r, database.OrgResourceProjects, *req.Updates.ProjectID) {
+		return
+	}
+
+	targets, err := s.bulkTargets(r, req)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(targets) > maxBulkBeads {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("filter matches %d beads; at most %d can be updated at once", len(targets), maxBulkBeads))
+		return
+	}
+
+	resp := beadBulkResponse{Matched: len(targets), Results: make([]beadBulkResult, len(targets))}
+	var found []string
+	for i, t := range targets {
+		resp.Results[i] = beadBulkResult{ID: t.id, Status: bulkNotApplied}
+		if t.bead == nil {
+			resp.Results[i].Status = bulkFailed
+			resp.Results[i].Error = "bead not found"
+			resp.Failed++
+			continue
+		}
+		found = append(found, t.id)
+	}
+
+	var updated []*models.Bead
+	if req.ContinueOnError {
+		for i, t := range targets {
+			if t.bead == nil {
+				continue
+			}
+			bead, err := s.app.UpdateBead(t.id, updates)
+			if err != nil {
+				resp.Results[i].Status = bulkFailed
+				resp.Results[i].Error = err.Error()
+				resp.Failed++
+				continue
+			}
+			resp.Results[i].Status = bulkUpdated
+			updated = append(updated, bead)
+		}
+	} else {
+		status := http.StatusNotFound
+		if resp.Failed == 0 {
+			updated, err = s.app.UpdateBeads(found, updates)
+			var bulkErr *beads.BulkUpdateError
+			if errors.As(err, &bulkErr) {
+				for i := range resp.Results {
+					if resp.Results[i].ID == bulkErr.BeadID {
+						resp.Results[i].Status = bulkFailed
+						resp.Results[i].Error = bulkErr.Err.Error()
+					}
+				}
+				resp.Failed++
+				status = beadUpdateStatus(bulkErr.Err)
+			} else if err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		if resp.Failed > 0 {
+			resp.Error = fmt.Sprintf("bulk update aborted: %d of %d beads failed; no beads were changed", resp.Failed, resp.Matched)
+			s.respondJSON(w, status, resp)
+			return
+		}
+		for i := range resp.Results {
+			resp.Results[i].Status = bulkUpdated
+		}
+	}
+	resp.Updated = len(updated)
+
+	// As for a single update, moving beads to in_progress wakes their
+	// projects' executors.
+	if req.Updates.Status != nil && models.BeadStatus(*req.Updates.Status) == models.BeadStatusInProgress {
+		woken := make(map[string]bool)
+		for _, bead := range updated {
+			if !woken[bead.ProjectID] {
+				woken[bead.ProjectID] = true
+				s.app.WakeProject(bead.ProjectID)
+			}
+		}
+	}
+
+	s.respondJSON(w, http.StatusOK, resp)
+}
+
+type bulkTarget struct {
+	id   string
+	bead *models.Bead // nil when missing or outside the request's organization
+}
+
+// bulkTargets resolves the beads a bulk update applies to, each once.
+func (s *Server) bulkTargets(r *http.Request, req beadBulkRequest) ([]bulkTarget, error) {
+	mgr := s.app.GetBeadsManager()
+	var targets []bulkTarget
+	if req.Filter != nil {
+		query := beads.SearchQuery{
+			Text:      req.Filter.Query,
+			ProjectID: req.Filter.ProjectID,
+			Tags:      req.Filter.Tags,
+		}
+		for _, st := range req.Filter.Status {
+			query.Statuses = append(query.Statuses, models.BeadStatus(st))
+		}
+		for _, result := range mgr.SearchBeads(query) {
+			targets = append(targets, bulkTarget{id: result.Bead.ID, bead: result.Bead})
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, id := range req.IDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			bead, err := mgr.GetBead(id)
+			if err != nil {
+				bead = nil
+			}
+			targets = append(targets, bulkTarget{id: id, bead: bead})
+		}
+	}
+
+	// Beads of another organization's projects are treated as missing.
+	var projectIDs []string
+	for _, t := range targets {
+		if t.bead != nil && t.bead.ProjectID != "" {
+			projectIDs = append(projectIDs, t.bead.ProjectID)
+		}
+	}
+	visible, err := s.orgVisible(r, database.OrgResourceProjects, projectIDs)
+	if err != nil || visible == nil {
+		return targets, err
+	}
+	kept := targets[:0]
+	for _, t := range targets {
+		if t.bead != nil && t.bead.ProjectID != "" && !visible[t.bead.ProjectID] {
+			if req.Filter != nil {
+				continue
+			}
+			t.bead = nil
+		}
+		kept = append(kept, t)
+	}
+	return kept, nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestHandleBeadsBulk_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, body string
+		want         int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "{", http.StatusBadRequest},
+		{http.MethodPost, `{"updates":{"status":"closed"}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"ids":["b-1"],"filter":{"project_id":"p1"},"updates":{"status":"closed"}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"filter":{},"updates":{"status":"closed"}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"ids":["b-1"]}`, http.StatusBadRequest},
+		{http.MethodPost, `{"ids":["b-1"],"updates":{}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"ids":["b-1"],"updates":{"unknown":1}}`, http.StatusBadRequest},
+		{http.MethodPost, `{"ids":` + bulkIDs(maxBulkBeads+1) + `,"updates":{"priority":1}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/v1/beads/bulk", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		s.handleBeadsBulk(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %.60s: expected %d, got %d", tt.method, tt.body, tt.want, w.Code)
+		}
+	}
+}
+
+func bulkIDs(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = `"b"`
+	}
+	return "[" + strings.Join(ids, ",") + "]"
+}
+
+func TestBeadPatch_Updates(t *testing.T) {
+	status := "in_progress"
+	priority := 1
+	tags := []string{"ui"}
+	p := beadPatch{Status: &status, Priority: &priority, Tags: &tags}
+
+	updates := p.updates()
+	if len(updates) != 3 {
+		t.Fatalf("expected 3 updates, got %v", updates)
+	}
+	if updates["status"] != models.BeadStatusInProgress {
+		t.Errorf("status = %v", updates["status"])
+	}
+	if updates["priority"] != models.BeadPriority(1) {
+		t.Errorf("priority = %v", updates["priority"])
+	}
+	if len(beadPatch{}.updates()) != 0 {
+		t.Error("empty patch should have no updates")
+	}
+}
+
+func TestBeadPatch_DueDate(t *testing.T) {
+	var p beadPatch
+	if err := json.Unmarshal([]byte(`{"due_date":"2026-11-01"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	due, ok := p.updates()["due_date"].(*time.Time)
+	if !ok || due == nil || !due.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("due_date = %v, want 2026-11-01T00:00:00Z", p.updates()["due_date"])
+	}
+
+	p = beadPatch{}
+	if err := json.Unmarshal([]byte(`{"due_date":""}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if due, ok := p.updates()["due_date"].(*time.Time); !ok || due != nil {
+		t.Errorf("empty due_date should clear it, got %v", p.updates()["due_date"])
+	}
+
+	if err := json.Unmarshal([]byte(`{"due_date":"next week"}`), &p); err == nil {
+		t.Error("unparseable due_date should fail")
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBeadTrash handles GET /api/v1/beads/trash: deleted beads that can
+// still be restored, optionally for one project.
+func (s *Server) handleBeadTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	trashed := s.app.GetBeadsManager().ListTrash(r.URL.Query().Get("project_id"))
+	projectIDs := make([]string, 0, len(trashed))
+	for _, bead := range trashed {
+		projectIDs = append(projectIDs, bead.ProjectID)
+	}
+	visible, err := s.orgVisible(r, database.OrgResourceProjects, projectIDs)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if visible != nil {
+		kept := trashed[:0]
+		for _, bead := range trashed {
+			if visible[bead.ProjectID] {
+				kept = append(kept, bead)
+			}
+		}
+		trashed = kept
+	}
+	s.respondJSON(w, http.StatusOK, trashed)
+}
+
+// deleteBead handles DELETE /api/v1/beads/{id}. The bead moves to the
+// trash, from which it can be restored until the retention window purges
+// it. With ?force=true an admin deletes it for good, from the trash or not.
+func (s *Server) deleteBead(w http.ResponseWriter, r *http.Request, id string) {
+	force := false
+	if v := r.URL.Query().Get("force"); v != "" {
+		var err error
+		if force, err = strconv.ParseBool(v); err != nil {
+			s.respondError(w, http.StatusBadRequest, "force must be true or false")
+			return
+		}
+	}
+	if force && s.config.Security.EnableAuth && auth.GetRoleFromRequest(r) != "admin" {
+		s.respondError(w, http.StatusForbidden, "Forbidden: admin access required to force-delete")
+		return
+	}
+
+	mgr := s.app.GetBeadsManager()
+	bead, err := mgr.GetTrashedBead(id)
+	if err != nil {
+		bead, err = mgr.GetBead(id)
+	}
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+
+	if force {
+		if err := mgr.PurgeBead(id); err != nil {
+			s.respondError(w, beadUpdateStatus(err), err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]string{"id": id, "status": "purged"})
+		return
+	}
+
+	if bead.DeletedAt != nil {
+		s.respondError(w, http.StatusConflict, "Bead is already in the trash; restore it or force-delete it")
+		return
+	}
+	trashed, err := mgr.TrashBead(id, requestActor(r))
+	if err != nil {
+		s.respondError(w, beadUpdateStatus(err), err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, trashed)
+}
+
+// restoreBead handles POST /api/v1/beads/{id}/restore.
+func (s *Server) restoreBead(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.app.GetBeadsManager()
+	bead, err := mgr.GetTrashedBead(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not in trash")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, bead.ProjectID) {
+		return
+	}
+
+	restored, err := mgr.RestoreBead(id, requestActor(r))
+	if err != nil {
+		if errors.Is(err, beads.ErrBeadNotFound) {
+			s.respondError(w, http.StatusNotFound, err.Error())
+		} else {
+			s.respondError(w, http.StatusConflict, err.Error())
+		}
+		return
+	}
+	s.respondJSON(w, http.StatusOK, restored)
+}
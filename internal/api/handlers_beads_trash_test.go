@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBeadTrash_Validation(t *testing.T) {
+	tests := []struct {
+		name         string
+		method, path string
+		role         string
+		auth         bool
+		want         int
+	}{
+		{"trash is read-only", http.MethodPost, "/api/v1/beads/trash", "", false, http.StatusMethodNotAllowed},
+		{"restore needs POST", http.MethodGet, "/api/v1/beads/bd-1/restore", "", false, http.StatusMethodNotAllowed},
+		{"bad force value", http.MethodDelete, "/api/v1/beads/bd-1?force=maybe", "admin", true, http.StatusBadRequest},
+		{"force needs admin", http.MethodDelete, "/api/v1/beads/bd-1?force=true", "user", true, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			s.config.Security.EnableAuth = tt.auth
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Role", tt.role)
+			w := httptest.NewRecorder()
+			if tt.path == "/api/v1/beads/trash" {
+				s.handleBeadTrash(w, req)
+			} else {
+				s.handleBead(w, req)
+			}
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
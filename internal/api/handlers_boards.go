@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/board"
+)
+
+// handleProjectBoard handles a project's kanban board
+// GET /api/v1/projects/{id}/board - Columns with counts and beads (?limit= per column, 0 for counts only)
+// PUT /api/v1/projects/{id}/board - Replace the columns ({"columns": [{"name","statuses","wip_limit"}]})
+// DELETE /api/v1/projects/{id}/board - Return to the default board
+func (s *Server) handleProjectBoard(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	mgr := s.app.GetBoardManager()
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := board.DefaultCardLimit
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			parsed, err := strconv.Atoi(limitStr)
+			if err != nil || parsed < 0 {
+				s.respondError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+				return
+			}
+			limit = min(parsed, maxBeadPageSize)
+		}
+		view, err := mgr.View(id, limit)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, view)
+
+	case http.MethodPut:
+		var req struct {
+			Columns []board.Column `json:"columns"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		b, err := mgr.Set(id, req.Columns, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, b)
+
+	case http.MethodDelete:
+		if err := mgr.Reset(id); err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
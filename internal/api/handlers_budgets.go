@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/budget"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleBudgets handles GET/POST /api/v1/budgets. POST sets the limits for a
+// scope and period, replacing an existing budget for the same pair.
+func (s *Server) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBudgetManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Budgets require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		statuses, err := mgr.List(r.Context())
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ids := make([]string, len(statuses))
+		for i, st := range statuses {
+			ids[i] = st.ID
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceBudgets, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if visible != nil {
+			inOrg := statuses[:0:0]
+			for _, st := range statuses {
+				if visible[st.ID] {
+					inOrg = append(inOrg, st)
+				}
+			}
+			statuses = inOrg
+		}
+		s.respondJSON(w, http.StatusOK, statuses)
+
+	case http.MethodPost:
+		var req budget.SetRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if msg := s.unknownBudgetScope(req); msg != "" {
+			s.respondError(w, http.StatusBadRequest, msg)
+			return
+		}
+		if resourceType, ok := budgetScopeResources[req.Scope]; ok && !s.checkOrg(w, r, resourceType, req.ScopeID) {
+			return
+		}
+		b, err := mgr.Set(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		st, err := mgr.Get(r.Context(), b.ID)
+		if err != nil {
+			s.respondJSON(w, http.StatusOK, b)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, st)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleBudget handles GET/DELETE /api/v1/budgets/{id}
+func (s *Server) handleBudget(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetBudgetManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Budgets require a database")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/budgets/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Budget ID is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		st, err := mgr.Get(r.Context(), id)
+		if err != nil {
+			s.respondBudgetError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, st)
+
+	case http.MethodDelete:
+		if err := mgr.Delete(id); err != nil {
+			s.respondBudgetError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// budgetScopeResources maps budget scopes to the organization resource
+// type of their target.
+var budgetScopeResources = map[string]string{
+	budget.ScopeProject:  database.OrgResourceProjects,
+	budget.ScopeAgent:    database.OrgResourceAgents,
+	budget.ScopeProvider: database.OrgResourceProviders,
+}
+
+// unknownBudgetScope returns an error message if the budget names a
+// project, agent, or provider that does not exist.
+func (s *Server) unknownBudgetScope(req budget.SetRequest) string {
+	if req.ScopeID == "" {
+		return ""
+	}
+	switch req.Scope {
+	case budget.ScopeProject:
+		if _, err := s.app.GetProjectManager().GetProject(req.ScopeID); err != nil {
+			return "Unknown project: " + req.ScopeID
+		}
+	case budget.ScopeAgent:
+		if _, err := s.app.GetAgentManager().GetAgent(req.ScopeID); err != nil {
+			return "Unknown agent: " + req.ScopeID
+		}
+	case budget.ScopeProvider:
+		if _, err := s.app.GetProviderRegistry().Get(req.ScopeID); err != nil {
+			return "Unknown provider: " + req.ScopeID
+		}
+	}
+	return ""
+}
+
+func (s *Server) respondBudgetError(w http.ResponseWriter, err error) {
+	if errors.Is(err, budget.ErrNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jordanhubbard/loom/internal/auth"
 	"github.com/jordanhubbard/loom/internal/cache"
+	"github.com/jordanhubbard/loom/internal/provider"
 )
 
 // handleGetCacheStats handles GET /api/v1/cache/stats
@@ -23,13 +24,29 @@ func (s *Server) handleGetCacheStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var promptCache *provider.PromptCache
+	if s.app != nil {
+		promptCache = s.app.GetProviderRegistry().PromptCache()
+	}
+	s.cacheStats(w, r, promptCache)
+}
+
+// cacheStats writes the response cache's stats, with the prompt cache's
+// under "prompts" when prompt caching is on.
+func (s *Server) cacheStats(w http.ResponseWriter, r *http.Request, promptCache *provider.PromptCache) {
 	// Get cache stats
 	if s.cache == nil {
 		http.Error(w, "Cache not initialized", http.StatusInternalServerError)
 		return
 	}
 
-	stats := s.cache.GetStats(r.Context())
+	stats := struct {
+		*cache.Stats
+		Prompts *cache.Stats `json:"prompts,omitempty"`
+	}{Stats: s.cache.GetStats(r.Context())}
+	if promptCache != nil {
+		stats.Prompts = promptCache.Stats(r.Context())
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(stats); err != nil {
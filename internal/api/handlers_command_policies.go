@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/cmdpolicy"
+)
+
+// handleCommandPolicies handles GET/POST /api/v1/commands/policies. GET
+// lists every rule, or with ?project_id= the rules that apply to that
+// project.
+func (s *Server) handleCommandPolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	mgr := s.app.GetCommandPolicy()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Command policies require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := mgr.List(r.URL.Query().Get("project_id"))
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, rules)
+
+	case http.MethodPost:
+		var req cmdpolicy.RuleRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectID != "" {
+			if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+				return
+			}
+		}
+		rule, err := mgr.Create(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, rule)
+	}
+}
+
+// handleCommandPolicy handles GET/PUT/DELETE /api/v1/commands/policies/{id},
+// plus POST /api/v1/commands/policies/check and
+// GET /api/v1/commands/policies/violations.
+func (s *Server) handleCommandPolicy(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/commands/policies/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Rule ID is required")
+		return
+	}
+	switch id {
+	case "check":
+		s.handleCommandPolicyCheck(w, r)
+		return
+	case "violations":
+		s.handleCommandPolicyViolations(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	mgr := s.app.GetCommandPolicy()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Command policies require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rule, err := mgr.Get(id)
+		if err != nil {
+			s.respondCommandPolicyError(w, err, http.StatusInternalServerError)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, rule)
+
+	case http.MethodPut:
+		var req cmdpolicy.RuleRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectID != "" {
+			if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+				return
+			}
+		}
+		rule, err := mgr.Update(id, req)
+		if err != nil {
+			s.respondCommandPolicyError(w, err, http.StatusBadRequest)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, rule)
+
+	case http.MethodDelete:
+		if err := mgr.Delete(id); err != nil {
+			s.respondCommandPolicyError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// respondCommandPolicyError responds 404 for unknown rules and with status
+// otherwise.
+func (s *Server) respondCommandPolicyError(w http.ResponseWriter, err error, status int) {
+	if errors.Is(err, cmdpolicy.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	s.respondError(w, status, err.Error())
+}
+
+// handleCommandPolicyCheck handles POST /api/v1/commands/policies/check. It
+// reports whether a command would be allowed in a project without recording
+// anything.
+func (s *Server) handleCommandPolicyCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req struct {
+		ProjectID string `json:"project_id"`
+		Command   string `json:"command"`
+	}
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Command) == "" {
+		s.respondError(w, http.StatusBadRequest, "command is required")
+		return
+	}
+	mgr := s.app.GetCommandPolicy()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Command policies require a database")
+		return
+	}
+	verdict, err := mgr.Check(req.ProjectID, req.Command)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, verdict)
+}
+
+// handleCommandPolicyViolations handles
+// GET /api/v1/commands/policies/violations?project_id=&limit=
+func (s *Server) handleCommandPolicyViolations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			s.respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	mgr := s.app.GetCommandPolicy()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Command policies require a database")
+		return
+	}
+	violations, err := mgr.Violations(r.URL.Query().Get("project_id"), limit)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, violations)
+}
@@ -5,65 +5,99 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/jordanhubbard/loom/internal/comments"
 )
 
 // handleBeadComments handles comment operations for a specific bead
 // GET /api/v1/beads/{id}/comments - Get all comments
 // POST /api/v1/beads/{id}/comments - Create comment
+// DELETE /api/v1/beads/{id}/comments/{commentId} - Delete comment
 func (s *Server) handleBeadComments(w http.ResponseWriter, r *http.Request) {
-	commentsMgr := s.app.GetCommentsManager()
-	if commentsMgr == nil {
-		s.respondError(w, http.StatusServiceUnavailable, "Comments manager not available")
-		return
-	}
-
 	// Extract bead ID from path
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/beads/")
 	parts := strings.Split(path, "/")
-	if len(parts) < 2 || parts[1] != "comments" {
+	if len(parts) < 2 || parts[1] != "comments" || len(parts) > 3 {
 		s.respondError(w, http.StatusBadRequest, "Invalid path")
 		return
 	}
 
 	beadID := parts[0]
+	commentID := ""
+	if len(parts) == 3 {
+		commentID = parts[2]
+	}
+
+	switch {
+	case r.Method == http.MethodGet && commentID == "":
+	case r.Method == http.MethodPost && commentID == "":
+	case r.Method == http.MethodDelete && commentID != "":
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	commentsMgr := s.app.GetCommentsManager()
+	if commentsMgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Comments manager not available")
+		return
+	}
+	if _, err := s.app.GetBeadsManager().GetBead(beadID); err != nil {
+		s.respondError(w, http.StatusNotFound, "Bead not found")
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
 		s.handleGetComments(w, r, beadID, commentsMgr)
 	case http.MethodPost:
 		s.handleCreateComment(w, r, beadID, commentsMgr)
-	default:
-		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	case http.MethodDelete:
+		s.handleDeleteBeadComment(w, r, beadID, commentID, commentsMgr)
 	}
 }
 
 // handleGetComments retrieves all comments for a bead
-func (s *Server) handleGetComments(w http.ResponseWriter, r *http.Request, beadID string, commentsMgr interface{}) {
-	// Type assert to access GetComments method
-	type CommentsGetter interface {
-		GetComments(beadID string) (interface{}, error)
-	}
-
-	getter, ok := commentsMgr.(CommentsGetter)
-	if !ok {
-		s.respondError(w, http.StatusInternalServerError, "Invalid comments manager")
-		return
-	}
-
-	comments, err := getter.GetComments(beadID)
+func (s *Server) handleGetComments(w http.ResponseWriter, r *http.Request, beadID string, commentsMgr *comments.Manager) {
+	thread, err := commentsMgr.GetComments(beadID)
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get comments: %v", err))
 		return
 	}
+	if thread == nil {
+		thread = []*comments.Comment{}
+	}
 
 	s.respondJSON(w, http.StatusOK, map[string]interface{}{
 		"bead_id":  beadID,
-		"comments": comments,
+		"comments": thread,
 	})
 }
 
+// handleDeleteBeadComment deletes a comment on a bead. Authors can delete
+// their own comments; admins can delete any.
+func (s *Server) handleDeleteBeadComment(w http.ResponseWriter, r *http.Request, beadID, commentID string, commentsMgr *comments.Manager) {
+	user := s.getUserFromContext(r)
+	if user == nil {
+		s.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	comment, err := commentsMgr.GetComment(commentID)
+	if err != nil || comment.BeadID != beadID {
+		s.respondError(w, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	authorID := user.ID
+	if user.Role == "admin" {
+		authorID = comment.AuthorID
+	}
+	s.handleDeleteComment(w, r, commentID, authorID, commentsMgr)
+}
+
 // handleCreateComment creates a new comment
-func (s *Server) handleCreateComment(w http.ResponseWriter, r *http.Request, beadID string, commentsMgr interface{}) {
+func (s *Server) handleCreateComment(w http.ResponseWriter, r *http.Request, beadID string, commentsMgr *comments.Manager) {
 	// Get user from context
 	user := s.getUserFromContext(r)
 	if user == nil {
@@ -87,18 +121,15 @@ func (s *Server) handleCreateComment(w http.ResponseWriter, r *http.Request, bea
 		return
 	}
 
-	// Type assert to access CreateComment method
-	type CommentCreator interface {
-		CreateComment(beadID, authorID, authorUsername, content, parentID string) (interface{}, error)
-	}
-
-	creator, ok := commentsMgr.(CommentCreator)
-	if !ok {
-		s.respondError(w, http.StatusInternalServerError, "Invalid comments manager")
-		return
+	if req.ParentID != "" {
+		parent, err := commentsMgr.GetComment(req.ParentID)
+		if err != nil || parent.BeadID != beadID {
+			s.respondError(w, http.StatusBadRequest, "parent_id is not a comment on this bead")
+			return
+		}
 	}
 
-	comment, err := creator.CreateComment(beadID, user.ID, user.Username, req.Content, req.ParentID)
+	comment, err := commentsMgr.CreateComment(beadID, user.ID, user.Username, req.Content, req.ParentID)
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create comment: %v", err))
 		return
@@ -142,7 +173,7 @@ func (s *Server) handleComment(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleUpdateComment updates a comment
-func (s *Server) handleUpdateComment(w http.ResponseWriter, r *http.Request, commentID, userID string, commentsMgr interface{}) {
+func (s *Server) handleUpdateComment(w http.ResponseWriter, r *http.Request, commentID, userID string, commentsMgr *comments.Manager) {
 	// Parse request body
 	var req struct {
 		Content string `json:"content"`
@@ -158,18 +189,7 @@ func (s *Server) handleUpdateComment(w http.ResponseWriter, r *http.Request, com
 		return
 	}
 
-	// Type assert to access UpdateComment method
-	type CommentUpdater interface {
-		UpdateComment(commentID, authorID, content string) error
-	}
-
-	updater, ok := commentsMgr.(CommentUpdater)
-	if !ok {
-		s.respondError(w, http.StatusInternalServerError, "Invalid comments manager")
-		return
-	}
-
-	if err := updater.UpdateComment(commentID, userID, req.Content); err != nil {
+	if err := commentsMgr.UpdateComment(commentID, userID, req.Content); err != nil {
 		if strings.Contains(err.Error(), "unauthorized") {
 			s.respondError(w, http.StatusForbidden, err.Error())
 			return
@@ -188,19 +208,8 @@ func (s *Server) handleUpdateComment(w http.ResponseWriter, r *http.Request, com
 }
 
 // handleDeleteComment deletes a comment
-func (s *Server) handleDeleteComment(w http.ResponseWriter, r *http.Request, commentID, userID string, commentsMgr interface{}) {
-	// Type assert to access DeleteComment method
-	type CommentDeleter interface {
-		DeleteComment(commentID, authorID string) error
-	}
-
-	deleter, ok := commentsMgr.(CommentDeleter)
-	if !ok {
-		s.respondError(w, http.StatusInternalServerError, "Invalid comments manager")
-		return
-	}
-
-	if err := deleter.DeleteComment(commentID, userID); err != nil {
+func (s *Server) handleDeleteComment(w http.ResponseWriter, r *http.Request, commentID, userID string, commentsMgr *comments.Manager) {
+	if err := commentsMgr.DeleteComment(commentID, userID); err != nil {
 		if strings.Contains(err.Error(), "unauthorized") {
 			s.respondError(w, http.StatusForbidden, err.Error())
 			return
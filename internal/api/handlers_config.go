@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/jordanhubbard/loom/internal/eventbus"
 	loompkg "github.com/jordanhubbard/loom/internal/loom"
+	"github.com/jordanhubbard/loom/internal/taskexecutor"
 )
 
 // handleConfig handles GET/PUT /api/v1/config (JSON).
@@ -40,6 +43,48 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleExecutorConfig reads and changes the task executor's concurrency
+// limits at runtime. GET /api/v1/config/executor returns the global limits,
+// or with ?project_id= those in effect for the project. PUT changes the
+// global limits with {"max_concurrent_beads": 5, "max_llm_calls": 3,
+// "claim_interval": "5s"}; fields left out keep their value. Projects set
+// their own through the project config endpoint.
+func (s *Server) handleExecutorConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		limits, err := s.app.GetExecutorLimits(r.URL.Query().Get("project_id"))
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				s.respondError(w, http.StatusNotFound, err.Error())
+			} else {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		s.respondJSON(w, http.StatusOK, limits)
+
+	case http.MethodPut:
+		var limits taskexecutor.Limits
+		if err := s.parseJSON(r, &limits); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		limits, err := s.app.SetExecutorLimits(limits)
+		if err != nil {
+			if errors.Is(err, taskexecutor.ErrInvalidLimits) {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+			} else {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		s.respondJSON(w, http.StatusOK, limits)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
 // handleConfigExportYAML handles GET /api/v1/config/export.yaml.
 func (s *Server) handleConfigExportYAML(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// containerRestartTimeout bounds a manual container restart, which waits
+// for the recreated container's agent to report healthy.
+const containerRestartTimeout = 3 * time.Minute
+
+// handleContainers handles GET /api/v1/containers, listing the project
+// containers with their limits, health and resource usage.
+func (s *Server) handleContainers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	orch := s.app.GetContainerOrchestrator()
+	if orch == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "container orchestrator not available")
+		return
+	}
+
+	infos := orch.ListContainers(r.Context())
+	if projectID := r.URL.Query().Get("project"); projectID != "" {
+		filtered := infos[:0]
+		for _, info := range infos {
+			if info.ProjectID == projectID {
+				filtered = append(filtered, info)
+			}
+		}
+		infos = filtered
+	}
+	s.respondJSON(w, http.StatusOK, infos)
+}
+
+// handleContainer handles the container engine and per-project container
+// routes:
+//
+//	GET  /api/v1/containers/runtime
+//	GET  /api/v1/containers/{project}/status
+//	GET  /api/v1/containers/{project}/logs?tail=N
+//	POST /api/v1/containers/{project}/restart
+func (s *Server) handleContainer(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/containers/"), "/"), "/")
+	if len(parts) == 1 && parts[0] == "runtime" {
+		s.handleContainerRuntime(w, r)
+		return
+	}
+	if len(parts) != 2 || parts[0] == "" {
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	projectID, action := parts[0], parts[1]
+
+	orch := s.app.GetContainerOrchestrator()
+	if orch == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "container orchestrator not available")
+		return
+	}
+
+	switch action {
+	case "status":
+		if r.Method != http.MethodGet {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		info, err := orch.ContainerStatus(r.Context(), projectID)
+		if err != nil {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, info)
+
+	case "logs":
+		if r.Method != http.MethodGet {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		tail := 200
+		if v := r.URL.Query().Get("tail"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				s.respondError(w, http.StatusBadRequest, "tail must be a positive integer")
+				return
+			}
+			tail = n
+		}
+		logs, err := orch.ProjectContainerLogs(r.Context(), projectID, tail)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"project_id": projectID,
+			"tail":       tail,
+			"logs":       logs,
+		})
+
+	case "restart":
+		if r.Method != http.MethodPost {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		project, err := s.app.GetProjectManager().GetProject(projectID)
+		if err != nil {
+			s.respondError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), containerRestartTimeout)
+		defer cancel()
+		if err := orch.RestartProjectContainer(ctx, project); err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		info, _ := orch.ContainerStatus(r.Context(), projectID)
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"status":    "restarted",
+			"container": info,
+		})
+
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// handleContainerRuntime reports the container engine project containers
+// run on and what it was found able to do at startup.
+func (s *Server) handleContainerRuntime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	orch := s.app.GetContainerOrchestrator()
+	if orch == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "container orchestrator not available")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, orch.Runtime())
+}
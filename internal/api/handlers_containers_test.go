@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleContainers_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/containers", nil)
+	w := httptest.NewRecorder()
+	s.handleContainers(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestHandleContainer_UnknownPath(t *testing.T) {
+	s := newTestServer()
+	for _, path := range []string{"/api/v1/containers/", "/api/v1/containers/proj-1", "/api/v1/containers/proj-1/status/extra"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		s.handleContainer(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestHandleContainerRuntime_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/containers/runtime", nil)
+	w := httptest.NewRecorder()
+	s.handleContainer(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
 )
 
 // handleConversation handles operations on a specific conversation session
@@ -14,6 +16,7 @@ import (
 // DELETE /api/v1/conversations/{id} - Delete session
 // POST /api/v1/conversations/{id}/reset - Reset conversation history
 // POST /api/v1/conversations/{id}/inject - Inject a message into the conversation
+// GET /api/v1/conversations/{id}/stream - Stream the agent's output (SSE)
 func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
 	db := s.app.GetDatabase()
 	if db == nil {
@@ -51,6 +54,16 @@ func (s *Server) handleConversation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check for stream endpoint
+	if len(parts) == 2 && parts[1] == "stream" {
+		if r.Method != http.MethodGet {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		s.handleConversationStream(w, r, sessionID, db)
+		return
+	}
+
 	// Handle main conversation operations
 	switch r.Method {
 	case http.MethodGet:
@@ -77,6 +90,79 @@ func (s *Server) handleGetConversation(w http.ResponseWriter, r *http.Request, s
 	s.respondJSON(w, http.StatusOK, session)
 }
 
+// handleConversationStream streams the output of the agent working in a
+// conversation as the model generates it. Each "output" event carries a
+// piece of text and the turn it belongs to; persisted messages are still
+// read with GET /api/v1/conversations/{id}.
+func (s *Server) handleConversationStream(w http.ResponseWriter, r *http.Request, sessionID string, db *database.Database) {
+	if _, err := db.GetConversationContext(sessionID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			s.respondError(w, http.StatusNotFound, fmt.Sprintf("Conversation session not found: %s", sessionID))
+			return
+		}
+		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get conversation: %v", err))
+		return
+	}
+
+	eventBus := s.app.GetEventBus()
+	if eventBus == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Event bus not available")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.respondError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	subscriberID := fmt.Sprintf("conversation-stream-%s-%d", sessionID, time.Now().UnixNano())
+	subscriber := eventBus.Subscribe(subscriberID, func(event *eventbus.Event) bool {
+		if event.Type != eventbus.EventTypeAgentOutput {
+			return false
+		}
+		id, _ := event.Data["session_id"].(string)
+		return id == sessionID
+	})
+	defer eventBus.Unsubscribe(subscriberID)
+
+	fmt.Fprintf(w, "event: connected\ndata: {\"session_id\": %q}\n\n", sessionID)
+	flusher.Flush()
+
+	ctx := r.Context()
+	keepalive := time.NewTicker(10 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-subscriber.Channel:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: output\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 // handleDeleteConversation deletes a conversation session
 func (s *Server) handleDeleteConversation(w http.ResponseWriter, r *http.Request, sessionID string, db *database.Database) {
 	err := db.DeleteConversationContext(sessionID)
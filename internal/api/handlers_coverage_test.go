@@ -1822,3 +1822,23 @@ func TestMotivationResponse_JSON(t *testing.T) {
 		t.Error("expected m1 in JSON")
 	}
 }
+
+func TestHandleGetCacheStats_PromptCache(t *testing.T) {
+	s := newTestServerWithCache()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/cache/stats", nil)
+	w := httptest.NewRecorder()
+	s.cacheStats(w, req, provider.NewPromptCache(time.Hour, 10))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var stats struct {
+		Hits    int64        `json:"hits"`
+		Prompts *cache.Stats `json:"prompts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Prompts == nil {
+		t.Errorf("expected prompt cache stats, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/containers"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Diagnostics is what the server knows about its own environment, for
+// `loomctl doctor`.
+type Diagnostics struct {
+	Status       string               `json:"status"` // "healthy", "degraded", "unhealthy"
+	Timestamp    time.Time            `json:"timestamp"`
+	Version      string               `json:"version,omitempty"`
+	Uptime       int64                `json:"uptime_seconds"`
+	Auth         DiagnosticsAuth      `json:"auth"`
+	Database     string               `json:"database,omitempty"` // "postgres" or "sqlite"
+	Dependencies map[string]DepHealth `json:"dependencies"`
+	Projects     []ProjectDiagnostics `json:"projects"`
+}
+
+// DiagnosticsAuth says whether authentication is enabled and who the
+// request was made as.
+type DiagnosticsAuth struct {
+	Enabled bool   `json:"enabled"`
+	User    string `json:"user,omitempty"`
+	Role    string `json:"role,omitempty"`
+}
+
+// ProjectDiagnostics is a project's dispatch readiness and container.
+// Container is nil when the project doesn't run in one.
+type ProjectDiagnostics struct {
+	ProjectID       string                    `json:"project_id"`
+	Name            string                    `json:"name"`
+	Ready           bool                      `json:"ready"`
+	ReadinessIssues []string                  `json:"readiness_issues,omitempty"`
+	Container       *containers.ContainerInfo `json:"container"`
+}
+
+// handleSystemDiagnostics handles GET /api/v1/system/diagnostics. It
+// reports the database, NATS and other dependencies along with each
+// active project's readiness and container.
+func (s *Server) handleSystemDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	d := Diagnostics{
+		Status:       "healthy",
+		Timestamp:    time.Now(),
+		Version:      getVersion(),
+		Uptime:       int64(time.Since(startTime).Seconds()),
+		Dependencies: s.checkDependencies(ctx),
+		Projects:     []ProjectDiagnostics{},
+		Auth: DiagnosticsAuth{
+			Enabled: s.config != nil && s.config.Security.EnableAuth,
+			User:    auth.GetUsernameFromRequest(r),
+			Role:    auth.GetRoleFromRequest(r),
+		},
+	}
+	for _, health := range d.Dependencies {
+		if health.Status == "unhealthy" {
+			d.Status = "unhealthy"
+			break
+		} else if health.Status == "degraded" {
+			d.Status = "degraded"
+		}
+	}
+	if db := s.app.GetDatabase(); db != nil {
+		d.Database = db.Type()
+	}
+
+	projects := s.app.GetProjectManager().ListProjects()
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	visible, err := s.orgVisible(r, database.OrgResourceProjects, ids)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	orch := s.app.GetContainerOrchestrator()
+	for _, p := range projects {
+		if p.Status == models.ProjectStatusArchived || (visible != nil && !visible[p.ID]) {
+			continue
+		}
+		pd := ProjectDiagnostics{ProjectID: p.ID, Name: p.Name}
+		pd.Ready, pd.ReadinessIssues = s.app.CheckProjectReadiness(ctx, p.ID)
+		if orch != nil {
+			if info, err := orch.ContainerStatus(ctx, p.ID); err == nil {
+				pd.Container = info
+			}
+		}
+		d.Projects = append(d.Projects, pd)
+	}
+
+	s.respondJSON(w, http.StatusOK, d)
+}
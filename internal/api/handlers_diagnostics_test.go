@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSystemDiagnostics_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+
+	w := httptest.NewRecorder()
+	s.handleSystemDiagnostics(w, httptest.NewRequest(http.MethodPost, "/api/v1/system/diagnostics", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", w.Code)
+	}
+}
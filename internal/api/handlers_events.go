@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/eventstore"
 )
 
 // handleEventStream handles SSE endpoint for real-time event updates
@@ -107,7 +109,7 @@ func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleGetEvents handles GET requests for recent events
-// GET /api/v1/events?project_id=xxx&type=xxx&limit=100
+// GET /api/v1/events?project_id=xxx&type=xxx&limit=100&cursor=xxx
 func (s *Server) handleGetEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -132,13 +134,83 @@ func (s *Server) handleGetEvents(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	events := eventBus.GetRecentEvents(limit, projectID, eventType)
+	var before uint64
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		parsed, err := strconv.ParseUint(c, 10, 64)
+		if err != nil || parsed == 0 {
+			s.respondError(w, http.StatusBadRequest, "invalid pagination cursor")
+			return
+		}
+		before = parsed
+	}
+
+	events, next := eventBus.GetEventsPage(limit, projectID, eventType, before)
+	nextCursor := ""
+	if next != 0 {
+		nextCursor = strconv.FormatUint(next, 10)
+	}
 	s.respondJSON(w, http.StatusOK, map[string]interface{}{
-		"events": events,
-		"count":  len(events),
+		"events":      events,
+		"count":       len(events),
+		"next_cursor": nextCursor,
 	})
 }
 
+// handleEventReplay returns stored events after a point, oldest first, so
+// consumers can catch up on what they missed, even across restarts. since
+// is either the cursor of a previous page (the seq of the last event seen)
+// or an RFC 3339 time; without it replay starts at the oldest event kept.
+// GET /api/v1/events/replay?since=N&project_id=xxx&types=a,b&limit=500
+func (s *Server) handleEventReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	store := s.app.GetEventStore()
+	if store == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Event store not available")
+		return
+	}
+	s.replayEvents(w, r, store)
+}
+
+// replayEvents serves one replay request from store.
+func (s *Server) replayEvents(w http.ResponseWriter, r *http.Request, store *eventstore.Store) {
+	q := r.URL.Query()
+	query := eventstore.ReplayQuery{ProjectID: q.Get("project_id")}
+	if since := q.Get("since"); since != "" {
+		if seq, err := strconv.ParseInt(since, 10, 64); err == nil && seq >= 0 {
+			query.Since = seq
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			query.SinceTime = t
+		} else {
+			s.respondError(w, http.StatusBadRequest, "since must be a cursor or an RFC 3339 time")
+			return
+		}
+	}
+	for _, t := range strings.Split(q.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			query.Types = append(query.Types, t)
+		}
+	}
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			s.respondError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := store.Replay(query)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, page)
+}
+
 // handleGetEventStats returns statistics about events
 // GET /api/v1/events/stats
 func (s *Server) handleGetEventStats(w http.ResponseWriter, r *http.Request) {
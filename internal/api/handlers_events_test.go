@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/eventstore"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestReplayEvents(t *testing.T) {
+	db := dbtest.New(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, typ := range []string{"bead.created", "bead.status_change", "bead.created"} {
+		if err := db.AppendEvent(&database.StoredEvent{ID: "ev", Type: typ, ProjectID: "p1", CreatedAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+	store := eventstore.NewStore(db, nil, config.EventsConfig{})
+	s := newTestServer()
+
+	tests := []struct {
+		query string
+		code  int
+		seqs  []int64
+	}{
+		{"", http.StatusOK, []int64{1, 2, 3}},
+		{"since=1", http.StatusOK, []int64{2, 3}},
+		{"since=" + base.Add(30*time.Minute).Format(time.RFC3339), http.StatusOK, []int64{2, 3}},
+		{"types=bead.created&project_id=p1", http.StatusOK, []int64{1, 3}},
+		{"project_id=p2", http.StatusOK, []int64{}},
+		{"since=yesterday", http.StatusBadRequest, nil},
+		{"limit=0", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.replayEvents(w, httptest.NewRequest(http.MethodGet, "/api/v1/events/replay?"+tt.query, nil), store)
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			var page eventstore.ReplayPage
+			if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(page.Events) != len(tt.seqs) {
+				t.Fatalf("got %d events, want %v", len(page.Events), tt.seqs)
+			}
+			for i, ev := range page.Events {
+				if ev.Seq != tt.seqs[i] {
+					t.Errorf("event %d seq = %d, want %d", i, ev.Seq, tt.seqs[i])
+				}
+			}
+			if page.Cursor != 3 {
+				t.Errorf("cursor = %d, want 3", page.Cursor)
+			}
+		})
+	}
+}
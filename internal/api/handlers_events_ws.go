@@ -0,0 +1,201 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+// eventSocketKeepalive is how often an idle event socket gets a keepalive
+// carrying the current cursor.
+const eventSocketKeepalive = 15 * time.Second
+
+// eventSocketUpgrader upgrades event stream connections. The default origin
+// check keeps other sites' pages from reading the stream.
+var eventSocketUpgrader = websocket.Upgrader{}
+
+// Kinds of EventSocketMessage.
+const (
+	EventSocketEvent     = "event"     // Event holds an event; its seq is the new cursor
+	EventSocketReady     = "ready"     // Replay is done; live events follow
+	EventSocketGap       = "gap"       // Events were lost and can't be replayed; refetch state
+	EventSocketKeepalive = "keepalive" // Nothing new; Cursor is the current cursor
+)
+
+// EventSocketMessage is a message the server sends on /api/v1/events/ws.
+type EventSocketMessage struct {
+	Kind     string          `json:"kind"`
+	Event    *eventbus.Event `json:"event,omitempty"`
+	Cursor   uint64          `json:"cursor,omitempty"`
+	Replayed int             `json:"replayed,omitempty"`
+}
+
+// handleEventSocket streams events over a WebSocket. Unlike the SSE stream,
+// a client that reconnects with the cursor of the last event (or keepalive)
+// it saw gets the events it missed replayed from the event history first.
+// A caller in an organization only gets the events of its projects.
+// Browsers, which can't send an Authorization header here, pass their
+// token as ?token=.
+// GET /api/v1/events/ws?project_id=xxx&types=a,b&cursor=N&token=xxx
+func (s *Server) handleEventSocket(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	eventBus := s.app.GetEventBus()
+	if eventBus == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Event bus not available")
+		return
+	}
+	orgProjects, err := s.orgProjectIDs(r)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.streamEventSocket(w, r, eventBus, orgProjects)
+}
+
+// streamEventSocket serves one event socket from eventBus. When
+// orgProjects isn't nil, only events of those projects are sent.
+func (s *Server) streamEventSocket(w http.ResponseWriter, r *http.Request, eventBus *eventbus.EventBus, orgProjects []string) {
+	q := r.URL.Query()
+	projectID := q.Get("project_id")
+	types := make(map[string]bool)
+	for _, t := range strings.Split(q.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+	replay := q.Has("cursor")
+	var cursor uint64
+	if replay {
+		parsed, err := strconv.ParseUint(q.Get("cursor"), 10, 64)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		cursor = parsed
+	}
+	var inOrg map[string]bool
+	if orgProjects != nil {
+		inOrg = make(map[string]bool, len(orgProjects))
+		for _, id := range orgProjects {
+			inOrg[id] = true
+		}
+	}
+	filter := func(event *eventbus.Event) bool {
+		if projectID != "" && event.ProjectID != projectID {
+			return false
+		}
+		if inOrg != nil && !inOrg[event.ProjectID] {
+			return false
+		}
+		return len(types) == 0 || types[string(event.Type)]
+	}
+
+	conn, err := eventSocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	send := func(msg *EventSocketMessage) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(msg)
+	}
+
+	// Subscribe before reading the history so nothing published in between
+	// is lost. The subscription only wakes the loop below: history events
+	// are read from the ring buffer by cursor, which also recovers events
+	// the bus dropped because this client fell behind.
+	subscriberID := "ws-" + uuid.New().String()
+	sub := eventBus.Subscribe(subscriberID, filter)
+	defer eventBus.Unsubscribe(subscriberID)
+
+	// flush sends the events after the cursor and advances it.
+	flush := func() (int, error) {
+		events, latest, gap := eventBus.EventsSince(cursor, filter)
+		if gap {
+			if err := send(&EventSocketMessage{Kind: EventSocketGap, Cursor: latest}); err != nil {
+				return 0, err
+			}
+		}
+		for _, ev := range events {
+			if err := send(&EventSocketMessage{Kind: EventSocketEvent, Event: ev, Cursor: ev.Seq}); err != nil {
+				return 0, err
+			}
+		}
+		cursor = latest
+		return len(events), nil
+	}
+
+	// Without a cursor the client only wants what happens from now on.
+	replayed := 0
+	if replay {
+		if replayed, err = flush(); err != nil {
+			return
+		}
+	} else {
+		cursor = eventBus.LatestSeq()
+	}
+	if err := send(&EventSocketMessage{Kind: EventSocketReady, Cursor: cursor, Replayed: replayed}); err != nil {
+		return
+	}
+
+	// The client doesn't send anything; reading notices when it goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepalive := time.NewTicker(eventSocketKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			if ev.Seq == 0 {
+				// Transient events aren't in the history; pass them through.
+				if err := send(&EventSocketMessage{Kind: EventSocketEvent, Event: ev}); err != nil {
+					return
+				}
+				continue
+			}
+			if ev.Seq <= cursor {
+				continue
+			}
+			if _, err := flush(); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := flush(); err != nil {
+				return
+			}
+			if err := send(&EventSocketMessage{Kind: EventSocketKeepalive, Cursor: cursor}); err != nil {
+				return
+			}
+		}
+	}
+}
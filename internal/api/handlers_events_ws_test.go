@@ -0,0 +1,195 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func dialEventSocket(t *testing.T, bus *eventbus.EventBus, query string) *websocket.Conn {
+	t.Helper()
+	return dialOrgEventSocket(t, bus, query, nil)
+}
+
+// dialOrgEventSocket dials an event socket scoped to orgProjects.
+func dialOrgEventSocket(t *testing.T, bus *eventbus.EventBus, query string, orgProjects []string) *websocket.Conn {
+	t.Helper()
+	s := newTestServer()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.streamEventSocket(w, r, bus, orgProjects)
+	}))
+	t.Cleanup(ts.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/events/ws?"+query, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readEventSocket(t *testing.T, conn *websocket.Conn) EventSocketMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg EventSocketMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+// publishAndWait publishes events and waits until the bus has stored them.
+func publishAndWait(t *testing.T, bus *eventbus.EventBus, events ...*eventbus.Event) {
+	t.Helper()
+	want := bus.LatestSeq() + uint64(len(events))
+	for _, ev := range events {
+		if err := bus.Publish(ev); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for bus.LatestSeq() < want {
+		if time.Now().After(deadline) {
+			t.Fatal("events were not distributed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventSocket_ReplaysFromCursor(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	defer bus.Close()
+
+	publishAndWait(t, bus,
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom", Data: map[string]interface{}{"bead_id": "bd-1"}},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "other"},
+		&eventbus.Event{Type: eventbus.EventTypeAgentHeartbeat, ProjectID: "loom"},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom", Data: map[string]interface{}{"bead_id": "bd-2"}},
+	)
+
+	// The client last saw event 1; it should get bd-2 only.
+	conn := dialEventSocket(t, bus, "project_id=loom&types=bead.created&cursor=1")
+	msg := readEventSocket(t, conn)
+	if msg.Kind != EventSocketEvent || msg.Cursor != 4 || msg.Event.Data["bead_id"] != "bd-2" {
+		t.Fatalf("replayed = %+v, want bd-2 at cursor 4", msg)
+	}
+	msg = readEventSocket(t, conn)
+	if msg.Kind != EventSocketReady || msg.Cursor != 4 || msg.Replayed != 1 {
+		t.Fatalf("ready = %+v, want cursor 4 with 1 replayed", msg)
+	}
+
+	// Live events follow, filtered the same way.
+	publishAndWait(t, bus,
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "other"},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom", Data: map[string]interface{}{"bead_id": "bd-3"}},
+	)
+	msg = readEventSocket(t, conn)
+	if msg.Kind != EventSocketEvent || msg.Cursor != 6 || msg.Event.Data["bead_id"] != "bd-3" {
+		t.Fatalf("live = %+v, want bd-3 at cursor 6", msg)
+	}
+}
+
+func TestEventSocket_NoCursorStartsLive(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	defer bus.Close()
+	publishAndWait(t, bus, &eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom"})
+
+	conn := dialEventSocket(t, bus, "")
+	msg := readEventSocket(t, conn)
+	if msg.Kind != EventSocketReady || msg.Cursor != 1 || msg.Replayed != 0 {
+		t.Fatalf("ready = %+v, want cursor 1 with nothing replayed", msg)
+	}
+}
+
+func TestEventSocket_CursorFromBeforeRestart(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	defer bus.Close()
+	publishAndWait(t, bus, &eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom"})
+
+	// A cursor ahead of the bus means the server restarted: the client is
+	// told it missed events, then gets everything still in the history.
+	conn := dialEventSocket(t, bus, "cursor=500")
+	if msg := readEventSocket(t, conn); msg.Kind != EventSocketGap {
+		t.Fatalf("first message = %+v, want gap", msg)
+	}
+	if msg := readEventSocket(t, conn); msg.Kind != EventSocketEvent || msg.Cursor != 1 {
+		t.Fatalf("second message = %+v, want event 1", msg)
+	}
+	if msg := readEventSocket(t, conn); msg.Kind != EventSocketReady || msg.Replayed != 1 {
+		t.Fatalf("third message = %+v, want ready", msg)
+	}
+}
+
+func TestEventSocket_InvalidCursor(t *testing.T) {
+	s := newTestServer()
+	bus := eventbus.NewEventBus()
+	defer bus.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/ws?cursor=abc", nil)
+	w := httptest.NewRecorder()
+	s.streamEventSocket(w, req, bus, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestEventSocket_OrgScoped(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	defer bus.Close()
+
+	publishAndWait(t, bus,
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "rival"},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom"},
+	)
+
+	conn := dialOrgEventSocket(t, bus, "cursor=0", []string{"loom"})
+	msg := readEventSocket(t, conn)
+	if msg.Kind != EventSocketEvent || msg.Event.ProjectID != "loom" {
+		t.Fatalf("replayed = %+v, want only the loom event", msg)
+	}
+	if msg = readEventSocket(t, conn); msg.Kind != EventSocketReady || msg.Replayed != 1 {
+		t.Fatalf("ready = %+v, want 1 replayed", msg)
+	}
+}
+
+func TestEventSocket_RequiresAuth(t *testing.T) {
+	am := auth.NewManager("test-secret")
+	login, err := am.Login("admin", "admin")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	s := &Server{
+		config:         &config.Config{Security: config.SecurityConfig{EnableAuth: true}},
+		authManager:    am,
+		apiFailureLast: make(map[string]time.Time),
+	}
+	var query string
+	handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/events/ws?cursor=0", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status = %d, want 401", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/events/ws?cursor=0&token="+login.Token, nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("token in query: status = %d, want 204", w.Code)
+	}
+	if query != "cursor=0" {
+		t.Errorf("query = %q, want the token dropped", query)
+	}
+}
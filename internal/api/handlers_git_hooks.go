@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// gitHookRefreshTimeout bounds one project refresh started by the git hook.
+const gitHookRefreshTimeout = 5 * time.Minute
+
+// gitHookPayload holds the fields of a push or pull request event that
+// identify the repository. GitHub and Gitea send repository; GitLab sends
+// project and, for merge requests, object_attributes.
+type gitHookPayload struct {
+	Ref        string `json:"ref"`
+	Action     string `json:"action"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Project *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		GitHTTPURL        string `json:"git_http_url"`
+		GitSSHURL         string `json:"git_ssh_url"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes *struct {
+		Action string `json:"action"`
+	} `json:"object_attributes"`
+}
+
+// repos returns every name and URL the payload gives its repository.
+func (p *gitHookPayload) repos() []string {
+	var repos []string
+	if r := p.Repository; r != nil {
+		repos = append(repos, r.FullName, r.CloneURL, r.SSHURL, r.HTMLURL)
+	}
+	if r := p.Project; r != nil {
+		repos = append(repos, r.PathWithNamespace, r.GitHTTPURL, r.GitSSHURL, r.WebURL)
+	}
+	return repos
+}
+
+// gitHookPRActions are the pull request actions that change what a
+// project's clone or beads should look like. Other actions (labels,
+// assignees, comments) are acknowledged and ignored.
+var gitHookPRActions = map[string]bool{
+	// GitHub and Gitea
+	"opened": true, "reopened": true, "synchronize": true, "synchronized": true, "closed": true,
+	// GitLab
+	"open": true, "reopen": true, "update": true, "merge": true, "close": true,
+}
+
+// gitHookEvent returns the event a git host says it is sending, normalized
+// to push, pull_request or the host's own name for anything else.
+func gitHookEvent(r *http.Request) string {
+	for _, h := range []string{"X-GitHub-Event", "X-Gitea-Event", "X-Gogs-Event"} {
+		if e := r.Header.Get(h); e != "" {
+			return e
+		}
+	}
+	switch e := r.Header.Get("X-Gitlab-Event"); e {
+	case "Push Hook":
+		return "push"
+	case "Merge Request Hook":
+		return "pull_request"
+	default:
+		return e
+	}
+}
+
+// verifyGitHook checks a hook request against the shared secret: an HMAC
+// signature from GitHub or Gitea, or GitLab's plain token.
+func verifyGitHook(r *http.Request, body []byte, secret string) bool {
+	if sig := r.Header.Get("X-Hub-Signature-256"); sig != "" {
+		return verifyGitHubSignature(body, sig, secret)
+	}
+	if sig := r.Header.Get("X-Gitea-Signature"); sig != "" {
+		return verifyGitHubSignature(body, sig, secret)
+	}
+	if token := r.Header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+// handleGitHook handles POST /api/v1/hooks/git, the receiver for push and
+// pull request events from a git host. Each project whose repository the
+// event names is refreshed in the background: its clone is pulled, its
+// beads reloaded from beads-sync, its readiness re-checked and its task
+// executor woken.
+func (s *Server) handleGitHook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	secret := ""
+	if s.config != nil {
+		secret = s.config.Security.GitHookSecret
+	}
+	if secret == "" {
+		s.respondError(w, http.StatusServiceUnavailable, "Git hook secret not configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 25<<20))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	if !verifyGitHook(r, body, secret) {
+		s.respondError(w, http.StatusUnauthorized, "Invalid hook signature")
+		return
+	}
+
+	event := gitHookEvent(r)
+	if event == "" {
+		s.respondError(w, http.StatusBadRequest, "Missing event header")
+		return
+	}
+
+	var payload gitHookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	action := payload.Action
+	if payload.ObjectAttributes != nil {
+		action = payload.ObjectAttributes.Action
+	}
+	if event != "push" && (event != "pull_request" || !gitHookPRActions[action]) {
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "ignored", "event": event})
+		return
+	}
+
+	if s.app == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Loom not initialized")
+		return
+	}
+	projects := s.app.ProjectsForRepository(payload.repos()...)
+	projectIDs := make([]string, 0, len(projects))
+	for _, p := range projects {
+		projectIDs = append(projectIDs, p.ID)
+		go func(projectID string) {
+			ctx, cancel := context.WithTimeout(context.Background(), gitHookRefreshTimeout)
+			defer cancel()
+			if err := s.app.RefreshProjectFromRemote(ctx, projectID); err != nil {
+				log.Printf("[GitHook] Failed to refresh project %s after %s event: %v", projectID, event, err)
+			}
+		}(p.ID)
+	}
+
+	s.respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"status":   "accepted",
+		"event":    event,
+		"ref":      payload.Ref,
+		"projects": projectIDs,
+	})
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func newGitHookTestServer(secret string) *Server {
+	return NewServer(nil, nil, nil, &config.Config{Security: config.SecurityConfig{GitHookSecret: secret}})
+}
+
+func TestGitHook_RequiresSecret(t *testing.T) {
+	s := newGitHookTestServer("")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/hooks/git", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+	s.handleGitHook(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a configured secret, got %d", w.Code)
+	}
+}
+
+func TestGitHook_RejectsBadSignature(t *testing.T) {
+	s := newGitHookTestServer("hook-secret")
+	body := []byte(`{"ref":"refs/heads/main"}`)
+
+	for name, set := range map[string]func(*http.Request){
+		"none":   func(r *http.Request) {},
+		"github": func(r *http.Request) { r.Header.Set("X-Hub-Signature-256", generateSignature(body, "wrong")) },
+		"gitlab": func(r *http.Request) { r.Header.Set("X-Gitlab-Token", "wrong") },
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/hooks/git", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		set(req)
+		w := httptest.NewRecorder()
+		s.handleGitHook(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestGitHook_IgnoresIrrelevantEvents(t *testing.T) {
+	s := newGitHookTestServer("hook-secret")
+	cases := []struct {
+		event, body string
+	}{
+		{"ping", `{"zen":"hi"}`},
+		{"pull_request", `{"action":"labeled","repository":{"full_name":"owner/repo"}}`},
+	}
+	for _, tc := range cases {
+		body := []byte(tc.body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/hooks/git", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", tc.event)
+		req.Header.Set("X-Hub-Signature-256", generateSignature(body, "hook-secret"))
+		w := httptest.NewRecorder()
+		s.handleGitHook(w, req)
+		if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"ignored"`)) {
+			t.Errorf("%s: expected ignored, got %d %s", tc.event, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGitHookEvent_GitLab(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/hooks/git", nil)
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	if got := gitHookEvent(req); got != "pull_request" {
+		t.Errorf("gitHookEvent = %q, want pull_request", got)
+	}
+	req.Header.Set("X-Gitlab-Token", "hook-secret")
+	if !verifyGitHook(req, nil, "hook-secret") {
+		t.Error("GitLab token not accepted")
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleProjectGraphAnalysis handles GET /api/v1/projects/{id}/graph/analysis:
+// the dependency cycles among the project's open beads, the critical path
+// to ?target= (or the longest one in the project), and the ?limit= beads
+// blocking the most downstream work.
+func (s *Server) handleProjectGraphAnalysis(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := beads.DefaultBottleneckLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			s.respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, id) {
+		return
+	}
+	analysis, err := s.app.GetBeadsManager().AnalyzeWorkGraph(id, r.URL.Query().Get("target"), limit)
+	if errors.Is(err, beads.ErrBeadNotFound) {
+		s.respondError(w, http.StatusNotFound, "Target bead not found in project")
+		return
+	} else if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, analysis)
+}
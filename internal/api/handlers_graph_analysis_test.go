@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProjectGraphAnalysis_Validation(t *testing.T) {
+	s := newTestServer()
+
+	w := httptest.NewRecorder()
+	s.handleProject(w, httptest.NewRequest(http.MethodPost, "/api/v1/projects/p1/graph/analysis", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleProject(w, httptest.NewRequest(http.MethodGet, "/api/v1/projects/p1/graph/analysis?limit=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("limit=0: expected 400, got %d", w.Code)
+	}
+}
@@ -297,3 +297,91 @@ func getLogMeta(meta map[string]interface{}, key string) string {
 	}
 	return ""
 }
+
+// handleLoggingConfig reads and changes the log levels at runtime.
+// GET /api/v1/config/logging returns the levels in effect; PUT replaces them
+// with {"default": "info", "subsystems": {"dispatcher": "debug"}}. Changes
+// last until the server restarts.
+func (s *Server) handleLoggingConfig(w http.ResponseWriter, r *http.Request) {
+	if s.logManager == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Log manager not available")
+		return
+	}
+	s.loggingConfig(w, r, s.logManager.Levels())
+}
+
+func (s *Server) loggingConfig(w http.ResponseWriter, r *http.Request, levels *logging.Levels) {
+	switch r.Method {
+	case http.MethodGet:
+		s.respondJSON(w, http.StatusOK, levels.Config())
+	case http.MethodPut:
+		var cfg logging.LevelConfig
+		if err := s.parseJSON(r, &cfg); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := levels.Apply(cfg); err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, levels.Config())
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// LogPruneRequest is the body of POST /api/v1/logs/prune. Empty fields fall
+// back to the configured retention policy.
+type LogPruneRequest struct {
+	OlderThan  string `json:"older_than,omitempty"` // duration such as 720h or 30d
+	MaxEntries int    `json:"max_entries,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+}
+
+// HandleLogsPrune prunes stored log entries, archiving them first when an
+// archive is configured.
+// POST /api/v1/logs/prune
+func (s *Server) HandleLogsPrune(w http.ResponseWriter, r *http.Request) {
+	if s.logManager == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Log manager not available")
+		return
+	}
+	s.pruneLogs(w, r, s.logManager)
+}
+
+func (s *Server) pruneLogs(w http.ResponseWriter, r *http.Request, m *logging.Manager) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req LogPruneRequest
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	policy := m.Retention()
+	if req.OlderThan != "" {
+		now := time.Now()
+		cutoff, err := parseSince(req.OlderThan, now)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, strings.Replace(err.Error(), "since", "older_than", 1))
+			return
+		}
+		policy.MaxAge = now.Sub(cutoff)
+	}
+	if req.MaxEntries < 0 {
+		s.respondError(w, http.StatusBadRequest, "max_entries must not be negative")
+		return
+	}
+	if req.MaxEntries > 0 {
+		policy.MaxEntries = req.MaxEntries
+	}
+
+	result, err := m.Prune(r.Context(), policy, req.DryRun)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, result)
+}
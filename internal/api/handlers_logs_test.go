@@ -0,0 +1,128 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/logging"
+)
+
+func TestLoggingConfig(t *testing.T) {
+	levels := logging.NewLevels()
+	s := newTestServer()
+
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.loggingConfig(w, httptest.NewRequest(http.MethodPut, "/api/v1/config/logging", strings.NewReader(body)), levels)
+		return w
+	}
+
+	w := put(`{"default": "warn", "subsystems": {"Dispatcher": "debug"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d: %s", w.Code, w.Body.String())
+	}
+	if !levels.Enabled("dispatcher", slog.LevelDebug) {
+		t.Error("dispatcher debug should be enabled")
+	}
+	if levels.Enabled("taskexecutor", slog.LevelInfo) {
+		t.Error("taskexecutor info should be below the warn default")
+	}
+
+	if w := put(`{"subsystems": {"dispatcher": "loud"}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid level: status = %d, want 400", w.Code)
+	}
+	if !levels.Enabled("dispatcher", slog.LevelDebug) {
+		t.Error("a rejected update must not change the levels")
+	}
+
+	w = httptest.NewRecorder()
+	s.loggingConfig(w, httptest.NewRequest(http.MethodGet, "/api/v1/config/logging", nil), levels)
+	var got logging.LevelConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Default != "warn" || got.Subsystems["dispatcher"] != "debug" {
+		t.Errorf("GET = %+v, want default warn and dispatcher debug", got)
+	}
+}
+
+func TestPruneLogs(t *testing.T) {
+	db := dbtest.New(t)
+	m := logging.NewManager(db.DB())
+	dir := t.TempDir()
+	m.SetRetention(logging.RetentionPolicy{Archiver: &logging.FileArchiver{Dir: dir}})
+
+	now := time.Now()
+	for i, age := range []time.Duration{90 * 24 * time.Hour, 45 * 24 * time.Hour, time.Hour, time.Minute} {
+		if _, err := db.DB().Exec(`INSERT INTO logs (id, timestamp, level, source, message) VALUES ($1, $2, 'info', 'test', 'hello')`,
+			fmt.Sprintf("log-%d", i), now.Add(-age)); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	s := newTestServer()
+	prune := func(body string) (int, logging.PruneResult) {
+		w := httptest.NewRecorder()
+		s.pruneLogs(w, httptest.NewRequest(http.MethodPost, "/api/v1/logs/prune", strings.NewReader(body)), m)
+		var res logging.PruneResult
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		return w.Code, res
+	}
+
+	if code, _ := prune(`{"older_than": "soon"}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid older_than: status = %d, want 400", code)
+	}
+
+	code, res := prune(`{"older_than": "30d", "dry_run": true}`)
+	if code != http.StatusOK || res.Matched != 2 || res.Deleted != 0 {
+		t.Fatalf("dry run = %d %+v, want 2 matched and nothing deleted", code, res)
+	}
+
+	code, res = prune(`{"older_than": "30d"}`)
+	if code != http.StatusOK || res.Deleted != 2 || len(res.Archives) != 1 {
+		t.Fatalf("prune = %d %+v, want 2 deleted into 1 archive", code, res)
+	}
+	f, err := os.Open(filepath.Join(dir, filepath.Base(res.Archives[0])))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	dec := json.NewDecoder(zr)
+	var archived []string
+	for dec.More() {
+		var entry logging.LogEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("decode archive: %v", err)
+		}
+		archived = append(archived, entry.ID)
+	}
+	if strings.Join(archived, ",") != "log-0,log-1" {
+		t.Errorf("archived %v, want log-0,log-1 oldest first", archived)
+	}
+
+	// Size-based retention keeps only the newest entry.
+	code, res = prune(`{"max_entries": 1}`)
+	if code != http.StatusOK || res.Deleted != 1 {
+		t.Fatalf("max_entries prune = %d %+v, want 1 deleted", code, res)
+	}
+	var left int
+	if err := db.DB().QueryRow(`SELECT COUNT(*) FROM logs`).Scan(&left); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if left != 1 {
+		t.Errorf("%d entries left, want 1", left)
+	}
+}
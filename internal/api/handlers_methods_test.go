@@ -132,6 +132,26 @@ func TestHandleGetBatchingRecommendations_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestHandleGetAgentScoreboard(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, url string
+		want        int
+	}{
+		{http.MethodPost, "/api/v1/analytics/agents", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/analytics/agents?window=fortnight", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/analytics/agents?window=7d", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, nil)
+		w := httptest.NewRecorder()
+		s.handleGetAgentScoreboard(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.url, tt.want, w.Code)
+		}
+	}
+}
+
 func TestHandleExportStats_MethodNotAllowed(t *testing.T) {
 	s := newTestServer()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/analytics/export-stats", nil)
@@ -350,7 +370,7 @@ func TestHandleCacheRecommendations_MethodNotAllowed(t *testing.T) {
 
 func TestHandleWorkflows_MethodNotAllowed(t *testing.T) {
 	s := newTestServer()
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/workflows", nil)
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/workflows", nil)
 	w := httptest.NewRecorder()
 	s.handleWorkflows(w, req)
 	if w.Code != http.StatusMethodNotAllowed {
@@ -358,6 +378,91 @@ func TestHandleWorkflows_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestHandleWorkflow_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/workflows/wf-1", nil)
+	w := httptest.NewRecorder()
+	s.handleWorkflow(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflows_InvalidDefinition(t *testing.T) {
+	s := newTestServer()
+	body := strings.NewReader(`{"id":"wf-1","name":"X","workflow_type":"bug","nodes":[{"node_key":"a"}],"edges":[{"to_node_key":"b","condition":"success"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/workflows", body)
+	w := httptest.NewRecorder()
+	s.handleWorkflows(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflow_PutIDMismatch(t *testing.T) {
+	s := newTestServer()
+	body := strings.NewReader(`{"id":"wf-2","name":"X","workflow_type":"bug"}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/workflows/wf-1", body)
+	w := httptest.NewRecorder()
+	s.handleWorkflow(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflow_SaveValidation(t *testing.T) {
+	tests := []struct {
+		name, method, path, body string
+	}{
+		{"malformed yaml", http.MethodPut, "/api/v1/workflows/wf-1", "id: [wf-1"},
+		{"dead end in yaml", http.MethodPut, "/api/v1/workflows/wf-1", "name: X\nworkflow_type: bug\nnodes:\n  - node_key: a\n    node_type: task\nedges:\n  - to_node_key: a\n    condition: success\n"},
+		{"bad dry_run", http.MethodPost, "/api/v1/workflows?dry_run=maybe", `{"id":"wf-1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			if tt.method == http.MethodPost {
+				s.handleWorkflows(w, req)
+			} else {
+				s.handleWorkflow(w, req)
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleWorkflowVersions_Validation(t *testing.T) {
+	s := newTestServer()
+	w := httptest.NewRecorder()
+	s.handleWorkflow(w, httptest.NewRequest(http.MethodPost, "/api/v1/workflows/wf-1/versions", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.handleWorkflow(w, httptest.NewRequest(http.MethodGet, "/api/v1/workflows/wf-1/versions/latest", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflowGraph_Validation(t *testing.T) {
+	s := newTestServer()
+	w := httptest.NewRecorder()
+	s.handleWorkflow(w, httptest.NewRequest(http.MethodPost, "/api/v1/workflows/wf-1/graph", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.handleWorkflow(w, httptest.NewRequest(http.MethodGet, "/api/v1/workflows/wf-1/graph?format=svg", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
 func TestHandleWorkflowExecutions_MethodNotAllowed(t *testing.T) {
 	s := newTestServer()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/workflows/executions", nil)
@@ -368,6 +473,28 @@ func TestHandleWorkflowExecutions_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestHandleWorkflowExecution_Validation(t *testing.T) {
+	s := newTestServer()
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/api/v1/workflows/executions/wfex-1", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/workflows/executions/wfex-1/pause", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/workflows/executions/wfex-1/restart", "", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/workflows/executions/", "", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/workflows/executions/wfex-1/skip", "{", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/workflows/executions/wfex-1/rollback", `{"reason":"x"}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		s.handleWorkflowExecution(w, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+		if w.Code != c.want {
+			t.Errorf("%s %s %q: expected %d, got %d", c.method, c.path, c.body, c.want, w.Code)
+		}
+	}
+}
+
 func TestHandleWorkflowAnalytics_MethodNotAllowed(t *testing.T) {
 	s := newTestServer()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/workflows/analytics", nil)
@@ -744,6 +871,115 @@ func TestHandleProjectStateEndpoints_UnknownAction(t *testing.T) {
 	}
 }
 
+func TestHandleProjectBoard_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		req := httptest.NewRequest(method, "/api/v1/projects/p1/board", nil)
+		w := httptest.NewRecorder()
+		s.handleProject(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestHandleProjectArchive_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	for _, tc := range []struct{ method, action string }{
+		{http.MethodDelete, "archive"},
+		{http.MethodGet, "unarchive"},
+	} {
+		req := httptest.NewRequest(tc.method, "/api/v1/projects/p1/"+tc.action, nil)
+		w := httptest.NewRecorder()
+		s.handleProject(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", tc.method, tc.action, w.Code)
+		}
+	}
+}
+
+func TestHandleProjectConfig_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		req := httptest.NewRequest(method, "/api/v1/projects/p1/config", nil)
+		w := httptest.NewRecorder()
+		s.handleProject(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestHandleAgentHold_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	for _, action := range []string{"pause", "drain", "resume"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/agents/a1/"+action, nil)
+		w := httptest.NewRecorder()
+		s.handleAgent(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", action, w.Code)
+		}
+	}
+}
+
+func TestHandleValidateActions(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, body string
+		want         int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, `{}`, http.StatusBadRequest},
+		{http.MethodPost, `{"response": "I think we should refactor"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/v1/actions/validate", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		s.handleValidateActions(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.body, tt.want, w.Code)
+		}
+	}
+}
+
+func TestHandleCommandPolicies_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPatch, "/api/v1/commands/policies", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/commands/policies/", "", http.StatusBadRequest},
+		{http.MethodPatch, "/api/v1/commands/policies/cmdpol-1", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/commands/policies/check", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/commands/policies/check", `{"project_id": "loom"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/commands/policies/violations", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/commands/policies/violations?limit=none", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		if tt.path == "/api/v1/commands/policies" {
+			s.handleCommandPolicies(w, req)
+		} else {
+			s.handleCommandPolicy(w, req)
+		}
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestHandleAgentStream_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agents/a1/stream", nil)
+	w := httptest.NewRecorder()
+	s.handleAgent(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
 func TestHandleBootstrapProject_MethodNotAllowed(t *testing.T) {
 	s := newTestServer()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/bootstrap", nil)
@@ -1439,11 +1675,34 @@ func TestHandleProject_PutInvalidBody(t *testing.T) {
 // ============================================================
 
 func TestHandleBeadComments_MethodNotAllowed(t *testing.T) {
-	// s.app is nil so s.app.GetCommentsManager() will panic
-	// but the handler checks for commentsMgr first
-	// Since s.app is nil we can't call this without panic
-	// This test is intentionally skipped
-	t.Skip("requires app instance")
+	s := newTestServer()
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/api/v1/beads/b-1/comments"},
+		{http.MethodDelete, "/api/v1/beads/b-1/comments"},
+		{http.MethodGet, "/api/v1/beads/b-1/comments/c-1"},
+		{http.MethodPost, "/api/v1/beads/b-1/comments/c-1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		s.handleBeadComments(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", tt.method, tt.path, w.Code)
+		}
+	}
+}
+
+func TestHandleBeadComments_InvalidPath(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/beads/b-1/comments/c-1/extra", nil)
+	w := httptest.NewRecorder()
+	s.handleBeadComments(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
 }
 
 // ============================================================
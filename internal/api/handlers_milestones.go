@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/milestone"
+)
+
+// handleMilestones handles GET/POST /api/v1/milestones. GET lists the
+// milestones of ?project_id=, or all of them, with their progress.
+func (s *Server) handleMilestones(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetMilestoneManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Milestones require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := mgr.List(r.URL.Query().Get("project_id"))
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		var ids []string
+		for _, ms := range list {
+			ids = append(ids, ms.ProjectIDs...)
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceProjects, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out := make([]*milestone.Milestone, 0, len(list))
+		for _, ms := range list {
+			if visible == nil || allVisible(visible, ms.ProjectIDs) {
+				out = append(out, ms)
+			}
+		}
+		s.respondJSON(w, http.StatusOK, out)
+
+	case http.MethodPost:
+		var req milestone.CreateRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if !s.checkMilestoneProjects(w, r, req.ProjectIDs) {
+			return
+		}
+		ms, err := mgr.Create(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, ms)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleMilestone handles GET/PATCH/DELETE /api/v1/milestones/{id},
+// GET /api/v1/milestones/{id}/beads, and POST /api/v1/milestones/{id}/close
+// and /reopen. Deleting a milestone takes its beads out of it.
+func (s *Server) handleMilestone(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetMilestoneManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Milestones require a database")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/milestones/"), "/")
+	id := parts[0]
+	if id == "" || len(parts) > 2 {
+		s.respondError(w, http.StatusBadRequest, "Milestone ID is required")
+		return
+	}
+
+	ms, err := mgr.Get(id)
+	if err != nil {
+		s.respondMilestoneError(w, err)
+		return
+	}
+	for _, projectID := range ms.ProjectIDs {
+		if !s.checkOrg(w, r, database.OrgResourceProjects, projectID) {
+			return
+		}
+	}
+
+	if len(parts) == 2 {
+		switch {
+		case parts[1] == "beads" && r.Method == http.MethodGet:
+			beads, err := mgr.Beads(id)
+			if err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.respondJSON(w, http.StatusOK, beads)
+		case parts[1] == "close" && r.Method == http.MethodPost:
+			ms, err := mgr.Close(id)
+			s.respondMilestone(w, ms, err)
+		case parts[1] == "reopen" && r.Method == http.MethodPost:
+			ms, err := mgr.Reopen(id)
+			s.respondMilestone(w, ms, err)
+		case parts[1] == "beads" || parts[1] == "close" || parts[1] == "reopen":
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		default:
+			s.respondError(w, http.StatusNotFound, "Not found")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.respondJSON(w, http.StatusOK, ms)
+
+	case http.MethodPatch:
+		var req milestone.UpdateRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectIDs != nil && !s.checkMilestoneProjects(w, r, *req.ProjectIDs) {
+			return
+		}
+		ms, err := mgr.Update(id, req)
+		s.respondMilestone(w, ms, err)
+
+	case http.MethodDelete:
+		if err := mgr.Delete(id); err != nil {
+			s.respondMilestoneError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// checkMilestoneProjects responds with an error and returns false unless
+// every project exists and is in the request's organization.
+func (s *Server) checkMilestoneProjects(w http.ResponseWriter, r *http.Request, projectIDs []string) bool {
+	for _, projectID := range projectIDs {
+		if !s.checkOrg(w, r, database.OrgResourceProjects, projectID) {
+			return false
+		}
+		if _, err := s.app.GetProjectManager().GetProject(projectID); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Unknown project: "+projectID)
+			return false
+		}
+	}
+	return true
+}
+
+func allVisible(visible map[string]bool, ids []string) bool {
+	for _, id := range ids {
+		if !visible[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) respondMilestone(w http.ResponseWriter, ms *milestone.Milestone, err error) {
+	if err != nil {
+		s.respondMilestoneError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, ms)
+}
+
+func (s *Server) respondMilestoneError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, milestone.ErrNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, milestone.ErrBeadsOpen):
+		s.respondError(w, http.StatusConflict, err.Error())
+	case strings.Contains(err.Error(), "failed to"):
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+	default:
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
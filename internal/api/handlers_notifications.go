@@ -259,8 +259,14 @@ func (s *Server) handleNotificationPreferences(w http.ResponseWriter, r *http.Re
 		s.respondJSON(w, http.StatusOK, prefs)
 
 	case http.MethodPatch:
-		// Parse request body
-		var updates notifications.NotificationPreferences
+		// Parse request body. The switches are pointers so that leaving
+		// one out of the request leaves it as it is.
+		var updates struct {
+			notifications.NotificationPreferences
+			EnableInApp   *bool `json:"enable_in_app"`
+			EnableEmail   *bool `json:"enable_email"`
+			EnableWebhook *bool `json:"enable_webhook"`
+		}
 		if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
 			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 			return
@@ -274,19 +280,27 @@ func (s *Server) handleNotificationPreferences(w http.ResponseWriter, r *http.Re
 		}
 
 		// Apply updates (only update fields that are present in request)
-		// This is a simplified approach - in production you'd want to use JSON Patch or similar
-		if updates.EnableInApp != prefs.EnableInApp {
-			prefs.EnableInApp = updates.EnableInApp
+		if updates.EnableInApp != nil {
+			prefs.EnableInApp = *updates.EnableInApp
 		}
-		if updates.EnableEmail != prefs.EnableEmail {
-			prefs.EnableEmail = updates.EnableEmail
+		if updates.EnableEmail != nil {
+			prefs.EnableEmail = *updates.EnableEmail
 		}
-		if updates.EnableWebhook != prefs.EnableWebhook {
-			prefs.EnableWebhook = updates.EnableWebhook
+		if updates.EnableWebhook != nil {
+			prefs.EnableWebhook = *updates.EnableWebhook
 		}
 		if len(updates.SubscribedEvents) > 0 {
 			prefs.SubscribedEvents = updates.SubscribedEvents
 		}
+		if updates.EmailEvents != nil {
+			prefs.EmailEvents = updates.EmailEvents
+		}
+		switch updates.DigestMode {
+		case "", notifications.DigestRealtime, notifications.DigestHourly, notifications.DigestDaily:
+		default:
+			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid digest_mode %q: must be realtime, hourly, or daily", updates.DigestMode))
+			return
+		}
 		if updates.DigestMode != "" {
 			prefs.DigestMode = updates.DigestMode
 		}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/oidc"
+)
+
+// oidcStateCookie binds a sign-in to the browser that started it, so a
+// callback URL from someone else's sign-in can't log this browser in.
+const oidcStateCookie = "loom_oidc_state"
+
+// oidcStateTTL matches how long the provider waits for a sign-in.
+const oidcStateTTL = 10 * time.Minute
+
+// setOIDCStateCookie sets the state cookie, or clears it when state is "".
+func (s *Server) setOIDCStateCookie(w http.ResponseWriter, r *http.Request, state string) {
+	maxAge := int(oidcStateTTL.Seconds())
+	if state == "" {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     s.basePath() + "/api/v1/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// handleOIDCConfig handles GET /api/v1/auth/oidc/config, which tells the
+// login page whether to offer single sign-on.
+func (s *Server) handleOIDCConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.oidc == nil {
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{"enabled": false})
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{"enabled": true, "name": s.oidc.Name()})
+}
+
+// handleOIDCLogin handles GET /api/v1/auth/oidc/login?redirect=/path and
+// sends the browser to the identity provider.
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.oidc == nil {
+		s.respondError(w, http.StatusNotFound, "Single sign-on is not configured")
+		return
+	}
+	redirect := r.URL.Query().Get("redirect")
+	// Only return to pages of this server.
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, `\`) {
+		redirect = s.basePath() + "/"
+	}
+	target, state, err := s.oidc.AuthCodeURL(r.Context(), redirect)
+	if err != nil {
+		log.Printf("[OIDC] %v", err)
+		s.respondError(w, http.StatusBadGateway, "Identity provider is unavailable")
+		return
+	}
+	s.setOIDCStateCookie(w, r, state)
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleOIDCCallback handles GET /api/v1/auth/oidc/callback, where the
+// identity provider sends the browser back. The sign-in must have been
+// started by this browser, as its state cookie shows. It signs the user in,
+// provisioning them on first sign-in, and returns the browser to the page
+// it started from with the session token in the URL fragment.
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.oidc == nil || s.authManager == nil {
+		s.respondError(w, http.StatusNotFound, "Single sign-on is not configured")
+		return
+	}
+	q := r.URL.Query()
+	cookie, err := r.Cookie(oidcStateCookie)
+	s.setOIDCStateCookie(w, r, "")
+	if err != nil || q.Get("state") == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(q.Get("state"))) != 1 {
+		s.respondError(w, http.StatusBadRequest, "Sign-in was not started from this browser; start again")
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		s.respondError(w, http.StatusUnauthorized, "Sign-in failed: "+strings.TrimSpace(e+" "+q.Get("error_description")))
+		return
+	}
+
+	id, err := s.oidc.Exchange(r.Context(), q.Get("code"), q.Get("state"))
+	if errors.Is(err, oidc.ErrNotAllowed) {
+		log.Printf("[OIDC] %v", err)
+		s.respondError(w, http.StatusForbidden, "You are not allowed to sign in to Loom")
+		return
+	}
+	if err != nil {
+		log.Printf("[OIDC] %v", err)
+		s.respondError(w, http.StatusUnauthorized, "Sign-in failed")
+		return
+	}
+	resp, err := s.authManager.LoginExternal(id.ExternalID(), id.Username, id.Email, id.Role)
+	if err != nil {
+		log.Printf("[OIDC] %s: %v", id.Username, err)
+		s.respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	log.Printf("[OIDC] %s signed in as %s", id.Username, id.Role)
+	http.Redirect(w, r, id.Redirect+"#token="+url.QueryEscape(resp.Token), http.StatusFound)
+}
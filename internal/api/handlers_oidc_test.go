@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/oidc"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestHandleOIDC_NotConfigured(t *testing.T) {
+	s := newTestServer()
+
+	w := httptest.NewRecorder()
+	s.handleOIDCConfig(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/config", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"enabled":false`) {
+		t.Errorf("config: status = %d, body %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleOIDCLogin(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("login: status = %d, want 404", w.Code)
+	}
+}
+
+func TestHandleOIDCCallback_RequiresStateCookie(t *testing.T) {
+	s := newTestServer()
+	s.authManager = auth.NewManager("secret")
+	p, err := oidc.New(config.OIDCConfig{IssuerURL: "https://idp.example.com", ClientID: "loom", RedirectURL: "https://loom.example.com/api/v1/auth/oidc/callback"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.oidc = p
+
+	for name, cookie := range map[string]string{"no cookie": "", "other sign-in": "abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=c&state=xyz", nil)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		s.handleOIDCCallback(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/orgs"
+)
+
+// orgReservedIDs are paths under an org-scoped collection that name an
+// endpoint rather than a resource.
+var orgReservedIDs = map[string]bool{
+	"projects/bootstrap": true,
+	"projects/git":       true,
+	"providers/policies": true,
+}
+
+// orgManager returns the organization manager, or nil when organizations
+// aren't available.
+func (s *Server) orgManager() *orgs.Manager {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.GetOrgManager()
+}
+
+// orgScope returns the database scope of the organization the request acts
+// in, or nil when requests aren't scoped.
+func (s *Server) orgScope(r *http.Request) *database.OrgScope {
+	orgID := auth.GetOrgIDFromRequest(r)
+	mgr := s.orgManager()
+	if orgID == "" || mgr == nil {
+		return nil
+	}
+	return mgr.Scope(orgID, auth.GetTeamIDFromRequest(r))
+}
+
+// checkOrg responds 404 and returns false if the resource belongs to
+// another organization than the request's. Resources outside the caller's
+// organization are reported as missing rather than forbidden so their
+// existence isn't revealed.
+func (s *Server) checkOrg(w http.ResponseWriter, r *http.Request, resourceType, id string) bool {
+	scope := s.orgScope(r)
+	if scope == nil || id == "" {
+		return true
+	}
+	if err := scope.Check(resourceType, id); err != nil {
+		if errors.Is(err, database.ErrCrossOrg) {
+			s.respondError(w, http.StatusNotFound, "Not found: "+id)
+		} else {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+// orgVisible returns which of ids the request's organization can see, or
+// nil when requests aren't scoped.
+func (s *Server) orgVisible(r *http.Request, resourceType string, ids []string) (map[string]bool, error) {
+	scope := s.orgScope(r)
+	if scope == nil {
+		return nil, nil
+	}
+	return scope.Visible(resourceType, ids)
+}
+
+// orgProjectIDs returns the IDs of the projects the request's organization
+// can see, for filtering beads and decisions by project, or nil when
+// requests aren't scoped.
+func (s *Server) orgProjectIDs(r *http.Request) ([]string, error) {
+	if s.orgScope(r) == nil {
+		return nil, nil
+	}
+	projects := s.app.GetProjectManager().ListProjects()
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	visible, err := s.orgVisible(r, database.OrgResourceProjects, ids)
+	if err != nil || visible == nil {
+		return nil, err
+	}
+	inOrg := make([]string, 0, len(visible))
+	for _, id := range ids {
+		if visible[id] {
+			inOrg = append(inOrg, id)
+		}
+	}
+	return inOrg, nil
+}
+
+func isOrgResource(resourceType string) bool {
+	for _, t := range orgs.ResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// orgScopeMiddleware keeps requests inside their organization: requests
+// for a project, provider, agent, or budget of another organization are
+// answered 404, as are requests naming another organization's project in
+// ?project_id=. Resources created through the API are assigned to the
+// request's organization (and for projects, its team) before the response
+// reaches the client.
+func (s *Server) orgScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := s.orgScope(r)
+		if scope == nil || !strings.HasPrefix(r.URL.Path, "/api/v1/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if projectID := r.URL.Query().Get("project_id"); projectID != "" {
+			if !s.checkOrg(w, r, database.OrgResourceProjects, projectID) {
+				return
+			}
+		}
+
+		resourceType, id := apiResource(r.URL.Path)
+		if !isOrgResource(resourceType) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		idField := "id"
+		if orgReservedIDs[resourceType+"/"+id] {
+			if r.URL.Path != "/api/v1/projects/bootstrap" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			idField = "project_id"
+			id = ""
+		}
+		if id != "" {
+			if s.checkOrg(w, r, resourceType, id) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Hold the response to a create until the new resource belongs to
+		// the organization, so the client can't use it before then.
+		buffered := &bufferedResponse{ResponseWriter: w}
+		next.ServeHTTP(buffered, r)
+		if buffered.statusCode >= 200 && buffered.statusCode < 300 {
+			var created map[string]interface{}
+			if json.Unmarshal(buffered.body.Bytes(), &created) == nil {
+				if createdID, _ := created[idField].(string); createdID != "" {
+					teamID := ""
+					if resourceType == database.OrgResourceProjects {
+						teamID = scope.TeamID
+					}
+					if err := s.orgManager().Assign(scope.OrgID, resourceType, createdID, teamID); err != nil {
+						log.Printf("[Orgs] Failed to assign %s %s to %s: %v", resourceType, createdID, scope.OrgID, err)
+					}
+				}
+			}
+		}
+		buffered.flush()
+	})
+}
+
+// bufferedResponse holds a response until flush is called.
+type bufferedResponse struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.statusCode == 0 {
+		b.statusCode = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.statusCode == 0 {
+		b.statusCode = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush() {
+	if b.statusCode == 0 {
+		b.statusCode = http.StatusOK
+	}
+	b.ResponseWriter.WriteHeader(b.statusCode)
+	_, _ = b.ResponseWriter.Write(b.body.Bytes())
+}
+
+// handleOrgs handles GET/POST /api/v1/orgs. Server admins see every
+// organization and are the only ones who can create one; other users see
+// the organizations they belong to.
+func (s *Server) handleOrgs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		mgr := s.requireOrgManager(w)
+		if mgr == nil {
+			return
+		}
+		userID := auth.GetUserIDFromRequest(r)
+		if auth.GetRoleFromRequest(r) == orgs.ServerAdminRole {
+			userID = ""
+		}
+		list, err := mgr.ListOrgs(userID)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, list)
+
+	case http.MethodPost:
+		var req struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ID == "" {
+			s.respondError(w, http.StatusBadRequest, "id is required")
+			return
+		}
+		if auth.GetRoleFromRequest(r) != orgs.ServerAdminRole {
+			s.respondError(w, http.StatusForbidden, "Only server admins can create organizations")
+			return
+		}
+		mgr := s.requireOrgManager(w)
+		if mgr == nil {
+			return
+		}
+		org, err := mgr.CreateOrg(req.ID, req.Name, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, org)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleOrg handles an organization and its teams, members, and resources:
+//
+//	GET/DELETE /api/v1/orgs/{id}
+//	GET/POST   /api/v1/orgs/{id}/teams
+//	DELETE     /api/v1/orgs/{id}/teams/{team_id}
+//	GET/POST   /api/v1/orgs/{id}/members
+//	DELETE     /api/v1/orgs/{id}/members/{user_id}
+//	POST       /api/v1/orgs/{id}/resources
+//
+// Members can read an organization; its admins and server admins can
+// change it.
+func (s *Server) handleOrg(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/orgs/"), "/"), "/")
+	orgID := parts[0]
+	if orgID == "" || len(parts) > 3 {
+		s.respondError(w, http.StatusBadRequest, "Organization ID is required")
+		return
+	}
+	sub, subID := "", ""
+	if len(parts) > 1 {
+		sub = parts[1]
+	}
+	if len(parts) > 2 {
+		subID = parts[2]
+	}
+
+	var allowed []string
+	switch {
+	case sub == "" && subID == "":
+		allowed = []string{http.MethodGet, http.MethodDelete}
+	case (sub == "teams" || sub == "members") && subID == "":
+		allowed = []string{http.MethodGet, http.MethodPost}
+	case sub == "teams" || sub == "members":
+		allowed = []string{http.MethodDelete}
+	case sub == "resources" && subID == "":
+		allowed = []string{http.MethodPost}
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	methodAllowed := false
+	for _, m := range allowed {
+		methodAllowed = methodAllowed || r.Method == m
+	}
+	if !methodAllowed {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.requireOrgManager(w)
+	if mgr == nil {
+		return
+	}
+	userID, role := auth.GetUserIDFromRequest(r), auth.GetRoleFromRequest(r)
+	if _, err := mgr.GetOrg(orgID); err != nil {
+		s.respondOrgError(w, err)
+		return
+	}
+	if r.Method == http.MethodGet {
+		// Reading takes membership, or acting in the organization, which
+		// users without any membership do in the default organization.
+		if role != orgs.ServerAdminRole && auth.GetOrgIDFromRequest(r) != orgID {
+			if _, err := mgr.GetMember(orgID, userID); err != nil {
+				s.respondOrgError(w, err)
+				return
+			}
+		}
+	} else if !mgr.CanAdmin(orgID, userID, role) {
+		s.respondError(w, http.StatusForbidden, "Only organization admins can change "+orgID)
+		return
+	}
+
+	switch sub {
+	case "":
+		if r.Method == http.MethodGet {
+			org, err := mgr.GetOrg(orgID)
+			if err != nil {
+				s.respondOrgError(w, err)
+				return
+			}
+			s.respondJSON(w, http.StatusOK, org)
+			return
+		}
+		if role != orgs.ServerAdminRole {
+			s.respondError(w, http.StatusForbidden, "Only server admins can delete organizations")
+			return
+		}
+		if err := mgr.DeleteOrg(orgID); err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case "teams":
+		s.handleOrgTeams(w, r, mgr, orgID, subID)
+
+	case "members":
+		s.handleOrgMembers(w, r, mgr, orgID, subID)
+
+	case "resources":
+		var req struct {
+			ResourceType string `json:"resource_type"`
+			ResourceID   string `json:"resource_id"`
+			TeamID       string `json:"team_id"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if !isOrgResource(req.ResourceType) || req.ResourceID == "" {
+			s.respondError(w, http.StatusBadRequest, "resource_type (projects, providers, agents, or budgets) and resource_id are required")
+			return
+		}
+		// Other than server admins, callers can only move resources out
+		// of the organization they act in.
+		if role != orgs.ServerAdminRole && !s.checkOrg(w, r, req.ResourceType, req.ResourceID) {
+			return
+		}
+		if err := mgr.Assign(orgID, req.ResourceType, req.ResourceID, req.TeamID); err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, req)
+	}
+}
+
+func (s *Server) handleOrgTeams(w http.ResponseWriter, r *http.Request, mgr *orgs.Manager, orgID, teamID string) {
+	switch r.Method {
+	case http.MethodGet:
+		teams, err := mgr.ListTeams(orgID)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, teams)
+
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		team, err := mgr.CreateTeam(orgID, req.Name)
+		if err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, team)
+
+	case http.MethodDelete:
+		if err := mgr.DeleteTeam(orgID, teamID); err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleOrgMembers(w http.ResponseWriter, r *http.Request, mgr *orgs.Manager, orgID, userID string) {
+	switch r.Method {
+	case http.MethodGet:
+		members, err := mgr.ListMembers(orgID)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, members)
+
+	case http.MethodPost:
+		var req struct {
+			UserID string `json:"user_id"`
+			TeamID string `json:"team_id"`
+			Role   string `json:"role"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		member, err := mgr.SetMember(orgID, req.UserID, req.TeamID, req.Role)
+		if err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, member)
+
+	case http.MethodDelete:
+		if err := mgr.RemoveMember(orgID, userID); err != nil {
+			s.respondOrgError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) requireOrgManager(w http.ResponseWriter) *orgs.Manager {
+	mgr := s.orgManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Organizations require a database")
+	}
+	return mgr
+}
+
+// respondOrgError maps organization errors to status codes. Anything that
+// isn't a missing resource or membership is a bad request.
+func (s *Server) respondOrgError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, orgs.ErrNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, orgs.ErrNotMember):
+		s.respondError(w, http.StatusForbidden, err.Error())
+	default:
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleOrgs_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, path, body, role string
+		want                     int
+	}{
+		{http.MethodPut, "/api/v1/orgs", "", "admin", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/orgs", "{", "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/orgs", `{"name":"Acme"}`, "admin", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/orgs", `{"id":"acme"}`, "user", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/orgs", `{"id":"acme"}`, "admin", http.StatusServiceUnavailable},
+		{http.MethodGet, "/api/v1/orgs", "", "user", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("X-Role", tt.role)
+		w := httptest.NewRecorder()
+		s.handleOrgs(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s %s: expected %d, got %d", tt.method, tt.path, tt.body, tt.want, w.Code)
+		}
+	}
+}
+
+func TestHandleOrg_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/orgs/", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/orgs/acme/unknown", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/orgs/acme/teams/t1/x", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/orgs/acme", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/api/v1/orgs/acme/teams", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/orgs/acme/members/user-1", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/orgs/acme/resources", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/orgs/acme/members", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		s.handleOrg(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestOrgScopeMiddleware_Unscoped(t *testing.T) {
+	s := newTestServer()
+	called := false
+	handler := s.orgScopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/projects", nil)
+	req.Header.Set("X-Org-ID", "acme")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if !called || w.Code != http.StatusCreated {
+		t.Errorf("without an org manager requests should pass through, got called=%v code=%d", called, w.Code)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/jordanhubbard/loom/internal/agentpki"
 )
 
 // handleProjectAgentRegister handles POST /api/v1/project-agents/register
@@ -29,6 +31,9 @@ func (s *Server) handleProjectAgentRegister(w http.ResponseWriter, r *http.Reque
 		s.respondError(w, http.StatusBadRequest, "project_id and agent_url are required")
 		return
 	}
+	if !s.checkAgentCertificate(w, r, payload.ProjectID) {
+		return
+	}
 
 	orch := s.app.GetContainerOrchestrator()
 	if orch == nil {
@@ -48,6 +53,9 @@ func (s *Server) handleProjectAgentHeartbeat(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if !s.checkAgentCertificate(w, r, extractProjectAgentID(r.URL.Path)) {
+		return
+	}
 	// Just acknowledge - we don't track heartbeat timestamps yet
 	s.respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
@@ -58,6 +66,10 @@ func (s *Server) handleProjectAgentResults(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	projectID := extractProjectAgentID(r.URL.Path)
+	if !s.checkAgentCertificate(w, r, projectID) {
+		return
+	}
 	// Results logged for now; future: route to waiting callers
 	var result map[string]interface{}
 	if err := s.parseJSON(r, &result); err != nil {
@@ -65,7 +77,6 @@ func (s *Server) handleProjectAgentResults(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	projectID := extractProjectAgentID(r.URL.Path)
 	log.Printf("[API] Task result received from project agent %s: %v", projectID, result)
 	s.respondJSON(w, http.StatusOK, map[string]string{"status": "received"})
 }
@@ -87,6 +98,25 @@ func (s *Server) handleContainerAgents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// checkAgentCertificate enforces agent mTLS: with it enabled, a project
+// agent call must come over TLS with a verified client certificate issued
+// for projectID. It writes the error response and returns false otherwise.
+func (s *Server) checkAgentCertificate(w http.ResponseWriter, r *http.Request, projectID string) bool {
+	if s.config == nil || !s.config.Security.AgentMTLS.Enabled {
+		return true
+	}
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		s.respondError(w, http.StatusUnauthorized, "project agent client certificate required")
+		return false
+	}
+	if certProject := agentpki.ProjectID(r.TLS.VerifiedChains[0][0]); certProject != projectID {
+		log.Printf("[API] Project agent certificate for %q used for project %q", certProject, projectID)
+		s.respondError(w, http.StatusForbidden, "client certificate was not issued for this project")
+		return false
+	}
+	return true
+}
+
 func extractProjectAgentID(urlPath string) string {
 	// /api/v1/project-agents/{id}/heartbeat → id
 	path := strings.TrimPrefix(urlPath, "/api/v1/project-agents/")
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/agentpki"
+)
+
+func agentCertificate(t *testing.T, a *agentpki.Authority, projectID string) *x509.Certificate {
+	t.Helper()
+	certPEM, keyPEM, _, err := a.Issue(projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair.Leaf
+}
+
+func TestProjectAgentHeartbeatRequiresCertificate(t *testing.T) {
+	s := newTestServer()
+	s.config.Security.AgentMTLS.Enabled = true
+	a, err := agentpki.Load(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want int
+	}{
+		{"no certificate", nil, http.StatusUnauthorized},
+		{"other project", agentCertificate(t, a, "proj-2"), http.StatusForbidden},
+		{"own project", agentCertificate(t, a, "proj-1"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/project-agents/proj-1/heartbeat", nil)
+			if tt.cert != nil {
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tt.cert}}}
+			}
+			w := httptest.NewRecorder()
+			s.handleContainerAgents(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	s.config.Security.AgentMTLS.Enabled = false
+	w := httptest.NewRecorder()
+	s.handleContainerAgents(w, httptest.NewRequest(http.MethodPost, "/api/v1/project-agents/proj-1/heartbeat", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("without mTLS status = %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// projectArchiveSummary describes an archive without its data.
+func projectArchiveSummary(a *database.ProjectArchive) map[string]interface{} {
+	return map[string]interface{}{
+		"project_id":         a.ProjectID,
+		"prior_status":       a.PriorStatus,
+		"archived_by":        a.ArchivedBy,
+		"archived_at":        a.ArchivedAt,
+		"bead_count":         a.BeadCount,
+		"conversation_count": a.ConversationCount,
+		"size_bytes":         len(a.Data),
+	}
+}
+
+// handleProjectArchive handles /api/v1/projects/{id}/archive.
+// POST archives the project; GET describes its archive, or with
+// ?download=true returns the compressed archive itself.
+func (s *Server) handleProjectArchive(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodPost:
+		archive, err := s.app.ArchiveProject(r.Context(), id, requestActor(r))
+		if err != nil {
+			s.respondError(w, projectArchiveErrorStatus(err), err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, projectArchiveSummary(archive))
+
+	case http.MethodGet:
+		archive, err := s.app.GetProjectArchive(id)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if archive == nil {
+			s.respondError(w, http.StatusNotFound, "Project is not archived")
+			return
+		}
+		if r.URL.Query().Get("download") == "true" {
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+"-archive.json.gz"))
+			_, _ = w.Write(archive.Data)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, projectArchiveSummary(archive))
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleProjectUnarchive handles POST /api/v1/projects/{id}/unarchive
+func (s *Server) handleProjectUnarchive(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if err := s.app.UnarchiveProject(r.Context(), id); err != nil {
+		s.respondError(w, projectArchiveErrorStatus(err), err.Error())
+		return
+	}
+	project, err := s.app.GetProjectManager().GetProject(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, project)
+}
+
+func projectArchiveErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "perpetual"), strings.Contains(msg, "archived"):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/jordanhubbard/loom/internal/projectconfig"
+)
+
+// handleProjectConfig handles a project's overrides of global settings
+// GET /api/v1/projects/{id}/config - The project's settings and the keys it may set
+// PUT /api/v1/projects/{id}/config - Set settings ({"settings": {"key": "value"}})
+// DELETE /api/v1/projects/{id}/config?key= - Return a setting to the global default
+func (s *Server) handleProjectConfig(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	mgr := s.app.GetProjectConfig()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Project config requires a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := mgr.List(id)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"project_id": id,
+			"settings":   settings,
+			"keys":       projectconfig.Keys(),
+		})
+
+	case http.MethodPut:
+		var req struct {
+			Settings map[string]string `json:"settings"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if len(req.Settings) == 0 {
+			s.respondError(w, http.StatusBadRequest, "settings is required")
+			return
+		}
+		keys := make([]string, 0, len(req.Settings))
+		for key, value := range req.Settings {
+			if _, err := projectconfig.Validate(key, value); err != nil {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		updated := make([]*projectconfig.Setting, 0, len(keys))
+		for _, key := range keys {
+			setting, err := s.app.SetProjectConfig(id, key, req.Settings[key], requestActor(r))
+			if err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			updated = append(updated, setting)
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"project_id": id,
+			"settings":   updated,
+		})
+
+	case http.MethodDelete:
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			s.respondError(w, http.StatusBadRequest, "key is required")
+			return
+		}
+		if err := s.app.UnsetProjectConfig(id, key); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, projectconfig.ErrInvalid) {
+				status = http.StatusBadRequest
+			}
+			s.respondError(w, status, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
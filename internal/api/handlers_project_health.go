@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// handleProjectHealth handles GET /api/v1/projects/{id}/health: the
+// project's health score and the components it is made of.
+func (s *Server) handleProjectHealth(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, id) {
+		return
+	}
+	report, err := s.app.ProjectHealth(r.Context(), id)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, report)
+}
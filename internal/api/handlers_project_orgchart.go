@@ -0,0 +1,125 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/orgchart"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// handleProjectOrgChart handles editing a project's org chart
+// GET /api/v1/projects/{id}/orgchart - The project's org chart
+// POST /api/v1/projects/{id}/orgchart - Add a position
+// DELETE /api/v1/projects/{id}/orgchart - Reset the chart to the default template
+// PUT /api/v1/projects/{id}/orgchart/positions/{positionId} - Change a position
+// DELETE /api/v1/projects/{id}/orgchart/positions/{positionId} - Remove a position
+func (s *Server) handleProjectOrgChart(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	if len(rest) > 0 && rest[len(rest)-1] == "" {
+		rest = rest[:len(rest)-1]
+	}
+	var positionID string
+	switch {
+	case len(rest) == 0:
+		switch r.Method {
+		case http.MethodGet, http.MethodPost, http.MethodDelete:
+		default:
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+	case len(rest) == 2 && rest[0] == "positions":
+		positionID = rest[1]
+		if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+
+	var (
+		chart *models.OrgChart
+		err   error
+	)
+	status := http.StatusOK
+	switch {
+	case positionID == "" && r.Method == http.MethodGet:
+		chart, err = s.app.GetProjectOrgChart(r.Context(), id)
+
+	case positionID == "" && r.Method == http.MethodPost:
+		var pos models.Position
+		if err := s.parseJSON(r, &pos); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		pos.AgentIDs = nil
+		chart, err = s.app.AddOrgChartPosition(r.Context(), id, pos, requestActor(r))
+		status = http.StatusCreated
+
+	case positionID == "" && r.Method == http.MethodDelete:
+		chart, err = s.app.ResetOrgChart(r.Context(), id)
+
+	case r.Method == http.MethodPut:
+		var update orgchart.PositionUpdate
+		if err := s.parseJSON(r, &update); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		chart, err = s.app.UpdateOrgChartPosition(r.Context(), id, positionID, update, requestActor(r))
+
+	default:
+		chart, err = s.app.RemoveOrgChartPosition(r.Context(), id, positionID, requestActor(r))
+	}
+	if err != nil {
+		s.respondError(w, orgChartErrorStatus(err), err.Error())
+		return
+	}
+	s.respondJSON(w, status, chart)
+}
+
+// handleProjectAgentsScale handles POST /api/v1/projects/{id}/agents/scale,
+// setting how many agents fill a role: {"role": "...", "replicas": N}.
+func (s *Server) handleProjectAgentsScale(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if _, err := s.app.GetProjectManager().GetProject(id); err != nil {
+		s.respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	var req struct {
+		Role     string `json:"role"`
+		Replicas int    `json:"replicas"`
+	}
+	if err := s.parseJSON(r, &req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	scale, err := s.app.ScaleRole(r.Context(), id, req.Role, req.Replicas, requestActor(r))
+	if err != nil {
+		s.respondError(w, orgChartErrorStatus(err), err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, scale)
+}
+
+func orgChartErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, orgchart.ErrInvalidPosition):
+		return http.StatusBadRequest
+	case strings.Contains(err.Error(), "already exists"):
+		return http.StatusConflict
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -30,6 +30,16 @@ func (s *Server) handleProjectStateEndpoints(w http.ResponseWriter, r *http.Requ
 		s.handleProjectGitHub(w, r, id)
 	case "memory":
 		s.handleProjectMemory(w, r, id)
+	case "board":
+		s.handleProjectBoard(w, r, id)
+	case "health":
+		s.handleProjectHealth(w, r, id)
+	case "config":
+		s.handleProjectConfig(w, r, id)
+	case "archive":
+		s.handleProjectArchive(w, r, id)
+	case "unarchive":
+		s.handleProjectUnarchive(w, r, id)
 	default:
 		s.respondError(w, http.StatusNotFound, "Unknown action")
 	}
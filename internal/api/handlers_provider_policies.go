@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+)
+
+// defaultPolicyKey names the default routing policy in URLs.
+const defaultPolicyKey = "default"
+
+// handleProviderPolicies handles GET /api/v1/providers/policies
+func (s *Server) handleProviderPolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	registry := s.app.GetProviderRegistry()
+	def, projects := registry.Policies()
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"default":  def,
+		"projects": projects,
+		"circuits": registry.CircuitStatuses(),
+	})
+}
+
+// handleProviderPolicy handles GET/PUT/DELETE /api/v1/providers/policies/{project_id}.
+// The project ID "default" addresses the policy used by projects without one.
+func (s *Server) handleProviderPolicy(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/api/v1/providers/policies/")
+	if key == "" || strings.Contains(key, "/") {
+		s.respondError(w, http.StatusBadRequest, "Project ID is required")
+		return
+	}
+	projectID := key
+	if key == defaultPolicyKey {
+		projectID = ""
+	}
+	registry := s.app.GetProviderRegistry()
+
+	switch r.Method {
+	case http.MethodGet:
+		def, projects := registry.Policies()
+		_, own := projects[projectID]
+		if projectID == "" {
+			own = def != nil
+		}
+		candidates := []string{}
+		for _, p := range registry.Candidates(projectID) {
+			candidates = append(candidates, p.Config.ID)
+		}
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{
+			"project_id": key,
+			"policy":     registry.Policy(projectID),
+			"inherited":  !own,
+			"candidates": candidates,
+		})
+
+	case http.MethodPut:
+		var policy provider.RoutingPolicy
+		if err := s.parseJSON(r, &policy); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if projectID != "" {
+			if _, err := s.app.GetProjectManager().GetProject(projectID); err != nil {
+				s.respondError(w, http.StatusNotFound, "Project not found: "+projectID)
+				return
+			}
+		}
+		for _, route := range policy.Providers {
+			if _, err := registry.Get(route.ProviderID); err != nil {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if err := s.app.SetProviderPolicy(projectID, &policy); err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, registry.Policy(projectID))
+
+	case http.MethodDelete:
+		if err := s.app.DeleteProviderPolicy(projectID); err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
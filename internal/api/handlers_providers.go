@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/jordanhubbard/loom/internal/database"
 	internalmodels "github.com/jordanhubbard/loom/internal/models"
 )
 
@@ -33,6 +35,24 @@ func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
 			s.respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		ids := make([]string, len(providers))
+		for i, p := range providers {
+			ids[i] = p.ID
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceProviders, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if visible != nil {
+			inOrg := providers[:0:0]
+			for _, p := range providers {
+				if visible[p.ID] {
+					inOrg = append(inOrg, p)
+				}
+			}
+			providers = inOrg
+		}
 		s.respondJSON(w, http.StatusOK, providers)
 
 	case http.MethodPost:
@@ -81,7 +101,8 @@ func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleProvider handles GET/DELETE /api/v1/providers/{id} and GET /api/v1/providers/{id}/models
+// handleProvider handles GET/DELETE /api/v1/providers/{id}, GET /api/v1/providers/{id}/models,
+// and POST /api/v1/providers/{id}/test
 func (s *Server) handleProvider(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/providers/")
 	parts := strings.Split(path, "/")
@@ -110,6 +131,26 @@ func (s *Server) handleProvider(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) > 1 && parts[1] == "test" {
+		if r.Method != http.MethodPost {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if s.app == nil {
+			s.respondError(w, http.StatusServiceUnavailable, "Application not initialized")
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+		defer cancel()
+		report, err := s.app.TestProvider(ctx, providerID)
+		if err != nil {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, report)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		if s.app == nil {
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/remoteagent"
+	"github.com/jordanhubbard/loom/pkg/messages"
+)
+
+// remoteAgentMaxMessage bounds one message of the remote agent protocol;
+// results carry the agent's output.
+const remoteAgentMaxMessage = 1 << 20
+
+// remoteAgentUpgrader upgrades remote agent connections. Agents are not
+// browsers and authenticate with their credential, so the origin is not
+// checked.
+var remoteAgentUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// CreateRemoteAgentTokenRequest is the body of POST
+// /api/v1/remote-agents/tokens.
+type CreateRemoteAgentTokenRequest struct {
+	ProjectIDs []string `json:"project_ids"`
+	Roles      []string `json:"roles,omitempty"`
+	TTL        string   `json:"ttl,omitempty"` // e.g. "24h"; default 24h
+}
+
+// handleRemoteAgents handles GET /api/v1/remote-agents, listing remote
+// agents with their liveness and the beads they hold.
+func (s *Server) handleRemoteAgents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	mgr := s.app.GetRemoteAgents()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Remote agents require a database")
+		return
+	}
+	agents, err := mgr.List()
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, agents)
+}
+
+// handleRemoteAgent handles the remote agent routes below
+// /api/v1/remote-agents/:
+//
+//	POST   /api/v1/remote-agents/tokens  issue an enrollment token
+//	POST   /api/v1/remote-agents/enroll  trade a token for a credential
+//	GET    /api/v1/remote-agents/connect WebSocket the agent works over
+//	DELETE /api/v1/remote-agents/{id}    revoke an agent
+//
+// enroll and connect authenticate with the token and credential rather
+// than a user session.
+func (s *Server) handleRemoteAgent(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/remote-agents/"), "/")
+	if rest == "" || strings.Contains(rest, "/") {
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	method := http.MethodDelete
+	switch rest {
+	case "tokens", "enroll":
+		method = http.MethodPost
+	case "connect":
+		method = http.MethodGet
+	}
+	if r.Method != method {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.app.GetRemoteAgents()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Remote agents require a database")
+		return
+	}
+
+	switch rest {
+	case "tokens":
+		s.createRemoteAgentToken(w, r, mgr)
+	case "enroll":
+		s.enrollRemoteAgent(w, r, mgr)
+	case "connect":
+		s.connectRemoteAgent(w, r, mgr)
+	default:
+		if err := mgr.Revoke(rest); err != nil {
+			if errors.Is(err, remoteagent.ErrNotFound) {
+				s.respondError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "revoked", "id": rest})
+	}
+}
+
+func (s *Server) createRemoteAgentToken(w http.ResponseWriter, r *http.Request, mgr *remoteagent.Manager) {
+	var req CreateRemoteAgentTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid ttl: "+err.Error())
+			return
+		}
+		ttl = d
+	}
+	for _, id := range req.ProjectIDs {
+		if _, err := s.app.GetProjectManager().GetProject(strings.TrimSpace(id)); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Project not found: "+id)
+			return
+		}
+	}
+
+	token, err := mgr.CreateToken(req.ProjectIDs, req.Roles, ttl, auth.GetUsernameFromRequest(r))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, token)
+}
+
+func (s *Server) enrollRemoteAgent(w http.ResponseWriter, r *http.Request, mgr *remoteagent.Manager) {
+	var req remoteagent.EnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	agent, credential, err := mgr.Enroll(req)
+	switch {
+	case errors.Is(err, remoteagent.ErrInvalidToken):
+		s.respondError(w, http.StatusUnauthorized, err.Error())
+		return
+	case errors.Is(err, remoteagent.ErrForbidden):
+		s.respondError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"agent":      agent,
+		"credential": credential,
+	})
+}
+
+// connectRemoteAgent serves the WebSocket a remote agent works over. It
+// welcomes the agent, then answers its heartbeats, work requests and
+// results until the agent goes away, falls silent or is revoked.
+func (s *Server) connectRemoteAgent(w http.ResponseWriter, r *http.Request, mgr *remoteagent.Manager) {
+	credential := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	ra, err := mgr.Authenticate(credential)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, remoteagent.ErrUnauthorized) {
+			status = http.StatusUnauthorized
+		}
+		s.respondError(w, status, err.Error())
+		return
+	}
+
+	conn, err := remoteAgentUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[RemoteAgent] Upgrade for %s failed: %v", ra.ID, err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(remoteAgentMaxMessage)
+
+	var writeMu sync.Mutex
+	send := func(msg *messages.RemoteMessage) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		msg.AgentID = ra.ID
+		_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(msg)
+	}
+
+	mgr.Connect(ra.ID, func() { conn.Close() })
+	defer mgr.Disconnect(ra.ID)
+
+	welcome := messages.NewRemoteMessage(messages.RemoteWelcome)
+	welcome.HeartbeatSeconds = int(remoteagent.HeartbeatInterval / time.Second)
+	if err := send(welcome); err != nil {
+		return
+	}
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(remoteagent.LivenessTimeout))
+		var msg messages.RemoteMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("[RemoteAgent] Connection of %s lost: %v", ra.ID, err)
+			}
+			return
+		}
+		if reply := s.handleRemoteAgentMessage(mgr, ra, &msg); reply != nil {
+			if err := send(reply); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleRemoteAgentMessage handles one message from a remote agent and
+// returns the reply, if any.
+func (s *Server) handleRemoteAgentMessage(mgr *remoteagent.Manager, ra *database.RemoteAgent, msg *messages.RemoteMessage) *messages.RemoteMessage {
+	switch msg.Type {
+	case messages.RemoteHeartbeat:
+		mgr.Heartbeat(ra.ID, msg.Status)
+		return nil
+
+	case messages.RemoteRequestWork:
+		task, err := mgr.NextTask(ra)
+		if err != nil {
+			reply := messages.NewRemoteMessage(messages.RemoteError)
+			reply.Error = err.Error()
+			return reply
+		}
+		if task == nil {
+			reply := messages.NewRemoteMessage(messages.RemoteNoWork)
+			reply.RetryAfterSeconds = int(remoteagent.IdleRetryInterval / time.Second)
+			return reply
+		}
+		reply := messages.NewRemoteMessage(messages.RemoteTask)
+		reply.BeadID = task.BeadID
+		reply.Task = task
+		return reply
+
+	case messages.RemoteResult:
+		if msg.Result == nil {
+			reply := messages.NewRemoteMessage(messages.RemoteError)
+			reply.Error = "result message without a result"
+			return reply
+		}
+		if err := mgr.Complete(ra.ID, msg.Result); err != nil {
+			reply := messages.NewRemoteMessage(messages.RemoteError)
+			reply.BeadID = msg.Result.BeadID
+			reply.Error = err.Error()
+			return reply
+		}
+		reply := messages.NewRemoteMessage(messages.RemoteAck)
+		reply.BeadID = msg.Result.BeadID
+		return reply
+
+	default:
+		reply := messages.NewRemoteMessage(messages.RemoteError)
+		reply.Error = "unknown message type " + msg.Type
+		return reply
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/remoteagent"
+	"github.com/jordanhubbard/loom/pkg/messages"
+)
+
+func TestHandleRemoteAgents_MethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/remote-agents", nil)
+	w := httptest.NewRecorder()
+	s.handleRemoteAgents(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
+
+func TestHandleRemoteAgent_Routes(t *testing.T) {
+	s := newTestServer()
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/remote-agents/", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/remote-agents/ra-1/extra", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/remote-agents/tokens", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/remote-agents/enroll", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/remote-agents/connect", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/remote-agents/ra-1", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		s.handleRemoteAgent(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, w.Code)
+		}
+	}
+}
+
+func TestHandleRemoteAgentMessage(t *testing.T) {
+	db := dbtest.New(t)
+	mgr := remoteagent.NewManager(db, nil)
+	ra := &database.RemoteAgent{ID: "ra-1"}
+	s := newTestServer()
+
+	if reply := s.handleRemoteAgentMessage(mgr, ra, messages.NewRemoteMessage(messages.RemoteHeartbeat)); reply != nil {
+		t.Errorf("heartbeat: expected no reply, got %s", reply.Type)
+	}
+	if reply := s.handleRemoteAgentMessage(mgr, ra, messages.NewRemoteMessage(messages.RemoteResult)); reply == nil || reply.Type != messages.RemoteError {
+		t.Errorf("result without a result: expected an error reply, got %+v", reply)
+	}
+	if reply := s.handleRemoteAgentMessage(mgr, ra, messages.NewRemoteMessage("remote.bogus")); reply == nil || reply.Type != messages.RemoteError {
+		t.Errorf("unknown type: expected an error reply, got %+v", reply)
+	}
+}
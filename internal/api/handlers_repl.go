@@ -2,11 +2,15 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
 
-// handleRepl handles POST /api/v1/repl for CEO REPL queries.
+// handleRepl handles POST /api/v1/repl for CEO REPL queries. With
+// "stream": true the response is a Server-Sent Events stream of "delta"
+// events followed by a "result" (or "error") event.
 func (s *Server) handleRepl(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -16,6 +20,7 @@ func (s *Server) handleRepl(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Message    string `json:"message"`
 		TimeoutSec int    `json:"timeout_sec"`
+		Stream     bool   `json:"stream"`
 	}
 	if err := s.parseJSON(r, &req); err != nil {
 		s.respondError(w, http.StatusBadRequest, "Invalid request body")
@@ -30,6 +35,12 @@ func (s *Server) handleRepl(w http.ResponseWriter, r *http.Request) {
 	if req.TimeoutSec > 0 {
 		timeout = time.Duration(req.TimeoutSec) * time.Second
 	}
+
+	if req.Stream {
+		s.streamRepl(w, r, req.Message, timeout)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
@@ -41,3 +52,33 @@ func (s *Server) handleRepl(w http.ResponseWriter, r *http.Request) {
 
 	s.respondJSON(w, http.StatusOK, result)
 }
+
+func (s *Server) streamRepl(w http.ResponseWriter, r *http.Request, message string, timeout time.Duration) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.respondError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	send := func(event string, v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, err := s.app.RunReplQueryStream(ctx, message, func(delta string) {
+		send("delta", map[string]string{"content": delta})
+	})
+	if err != nil {
+		send("error", map[string]string{"error": err.Error()})
+		return
+	}
+	send("result", result)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/report"
+)
+
+// handleReports handles GET/POST /api/v1/reports. GET lists stored reports,
+// newest first, optionally for one project; POST generates a project's
+// report for the last complete day or week.
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	if s.app.GetDatabase() == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Reports require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := 50
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				s.respondError(w, http.StatusBadRequest, "Invalid limit")
+				return
+			}
+			limit = n
+		}
+		reports, err := s.app.ListReports(r.URL.Query().Get("project_id"), limit)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ids := make([]string, len(reports))
+		for i, rep := range reports {
+			ids[i] = rep.ProjectID
+		}
+		visible, err := s.orgVisible(r, database.OrgResourceProjects, ids)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out := make([]*database.Report, 0, len(reports))
+		for _, rep := range reports {
+			if visible == nil || visible[rep.ProjectID] {
+				out = append(out, rep)
+			}
+		}
+		s.respondJSON(w, http.StatusOK, out)
+
+	case http.MethodPost:
+		var req struct {
+			ProjectID string `json:"project_id"`
+			Period    string `json:"period"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectID == "" {
+			s.respondError(w, http.StatusBadRequest, "project_id is required")
+			return
+		}
+		if req.Period == "" {
+			req.Period = string(report.PeriodWeek)
+		}
+		period, err := report.ParsePeriod(req.Period)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !s.checkOrg(w, r, database.OrgResourceProjects, req.ProjectID) {
+			return
+		}
+		if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+			return
+		}
+		rep, err := s.app.GenerateReport(r.Context(), req.ProjectID, period, period.LastEnd(time.Now()))
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, rep)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleReport handles GET /api/v1/reports/{id}. ?format=markdown or
+// ?format=html returns the rendered report instead of JSON.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.app.GetDatabase() == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Reports require a database")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/reports/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Report ID is required")
+		return
+	}
+
+	rep, err := s.app.GetReport(id)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rep == nil {
+		s.respondError(w, http.StatusNotFound, "Report not found: "+id)
+		return
+	}
+	if !s.checkOrg(w, r, database.OrgResourceProjects, rep.ProjectID) {
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		s.respondJSON(w, http.StatusOK, rep)
+	case "markdown", "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(rep.Markdown))
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(rep.HTML))
+	default:
+		s.respondError(w, http.StatusBadRequest, "format must be json, markdown or html")
+	}
+}
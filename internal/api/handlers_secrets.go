@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/keymanager"
+)
+
+// handleSecrets handles GET /api/v1/secrets, listing secrets and the
+// projects they are granted to. Values are never returned.
+func (s *Server) handleSecrets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.keyManager == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Key manager not available")
+		return
+	}
+	secrets, err := s.keyManager.ListSecrets()
+	if err != nil {
+		s.respondError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, secrets)
+}
+
+// handleSecret handles PUT/DELETE /api/v1/secrets/{name},
+// POST /api/v1/secrets/{name}/grants, and
+// DELETE /api/v1/secrets/{name}/grants/{project_id}.
+func (s *Server) handleSecret(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/secrets/"), "/")
+	name := parts[0]
+	if name == "" {
+		s.respondError(w, http.StatusBadRequest, "Secret name is required")
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+	case len(parts) == 2 && parts[1] == "grants":
+		if r.Method != http.MethodPost {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+	case len(parts) == 3 && parts[1] == "grants" && parts[2] != "":
+		if r.Method != http.MethodDelete {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if s.keyManager == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Key manager not available")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		var req struct {
+			Description string `json:"description"`
+			Value       string `json:"value"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		secret, err := s.keyManager.SetSecret(name, req.Description, req.Value)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, secret)
+
+	case len(parts) == 1:
+		if err := s.keyManager.DeleteSecret(name); err != nil {
+			s.respondSecretError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case len(parts) == 2:
+		var req struct {
+			ProjectID string `json:"project_id"`
+		}
+		if err := s.parseJSON(r, &req); err != nil || req.ProjectID == "" {
+			s.respondError(w, http.StatusBadRequest, "project_id is required")
+			return
+		}
+		if req.ProjectID != keymanager.AllProjects {
+			if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+				return
+			}
+		}
+		secret, err := s.keyManager.GrantSecret(name, req.ProjectID)
+		if err != nil {
+			s.respondSecretError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, secret)
+
+	default:
+		secret, err := s.keyManager.RevokeSecret(name, parts[2])
+		if err != nil {
+			s.respondSecretError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, secret)
+	}
+}
+
+// respondSecretError responds 404 for unknown secrets and 503 otherwise,
+// since the remaining failures come from a locked or unwritable key store.
+func (s *Server) respondSecretError(w http.ResponseWriter, err error) {
+	status := http.StatusServiceUnavailable
+	if errors.Is(err, keymanager.ErrSecretNotFound) {
+		status = http.StatusNotFound
+	}
+	s.respondError(w, status, err.Error())
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/keymanager"
+)
+
+func TestHandleSecret_Validation(t *testing.T) {
+	s := newTestServer()
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/secrets", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/secrets/", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/secrets/TOKEN", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/secrets/TOKEN/grants", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/secrets/TOKEN/grants/loom", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/v1/secrets/TOKEN/other", http.StatusNotFound},
+		{http.MethodPut, "/api/v1/secrets/TOKEN", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		if tt.path == "/api/v1/secrets" {
+			s.handleSecrets(w, req)
+		} else {
+			s.handleSecret(w, req)
+		}
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestHandleSecret_SetListDelete(t *testing.T) {
+	s := newTestServer()
+	s.keyManager = keymanager.NewKeyManager(filepath.Join(t.TempDir(), "keys.json"))
+	if err := s.keyManager.Unlock("test-password"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/secrets/GITHUB_TOKEN", strings.NewReader(`{"value": "ghp_secret", "description": "CI"}`))
+	w := httptest.NewRecorder()
+	s.handleSecret(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.handleSecrets(w, httptest.NewRequest(http.MethodGet, "/api/v1/secrets", nil))
+	if strings.Contains(w.Body.String(), "ghp_secret") {
+		t.Fatal("secret value returned by list")
+	}
+	var list []keymanager.Secret
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].Name != "GITHUB_TOKEN" {
+		t.Fatalf("list = %s (%v)", w.Body.String(), err)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/secrets/bad-name", strings.NewReader(`{"value": "x"}`))
+	w = httptest.NewRecorder()
+	s.handleSecret(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("PUT bad name: expected 400, got %d", w.Code)
+	}
+
+	for _, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		s.handleSecret(w, httptest.NewRequest(http.MethodDelete, "/api/v1/secrets/GITHUB_TOKEN", nil))
+		if w.Code != want {
+			t.Errorf("DELETE: expected %d, got %d", want, w.Code)
+		}
+	}
+}
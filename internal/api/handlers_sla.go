@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/sla"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// beadResponse is a bead with its SLA standing, when a policy covers it,
+// and its effective priority, when its project ages priorities.
+type beadResponse struct {
+	*models.Bead
+	SLA               *sla.BeadStatus      `json:"sla,omitempty"`
+	EffectivePriority *models.BeadPriority `json:"effective_priority,omitempty"`
+}
+
+// handleSLAPolicies handles GET/POST /api/v1/sla/policies. POST sets the
+// deadlines for a project and priority, replacing an existing policy for
+// the same pair.
+func (s *Server) handleSLAPolicies(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetSLAManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "SLA policies require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		policies, err := mgr.List()
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, policies)
+
+	case http.MethodPost:
+		var req sla.SetRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.ProjectID != "" {
+			if _, err := s.app.GetProjectManager().GetProject(req.ProjectID); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Unknown project: "+req.ProjectID)
+				return
+			}
+		}
+		p, err := mgr.Set(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, p)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleSLAPolicy handles DELETE /api/v1/sla/policies/{id}
+func (s *Server) handleSLAPolicy(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/sla/policies/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Policy ID is required")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	mgr := s.app.GetSLAManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "SLA policies require a database")
+		return
+	}
+	if err := mgr.Delete(id); err != nil {
+		if errors.Is(err, sla.ErrNotFound) {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSLAReport handles GET /api/v1/sla/report?project_id=
+func (s *Server) handleSLAReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	mgr := s.app.GetSLAManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "SLA policies require a database")
+		return
+	}
+	report, err := mgr.Report(r.URL.Query().Get("project_id"))
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, report)
+}
+
+// beadResponses pairs beads with their SLA standing and, where their
+// project ages priorities, their effective priority. With neither an SLA
+// manager whose policies can be read nor aging, it returns nil and callers
+// respond with the beads as they are.
+func (s *Server) beadResponses(beads []*models.Bead) []beadResponse {
+	var statuses map[string]*sla.BeadStatus
+	decorated := false
+	if mgr := s.app.GetSLAManager(); mgr != nil {
+		if st, err := mgr.Statuses(beads); err == nil {
+			statuses, decorated = st, true
+		}
+	}
+	bm := s.app.GetBeadsManager()
+	out := make([]beadResponse, len(beads))
+	for i, b := range beads {
+		out[i] = beadResponse{Bead: b, SLA: statuses[b.ID]}
+		if bm != nil && bm.PriorityAging(b.ProjectID) > 0 {
+			effective := bm.EffectivePriority(b)
+			out[i].EffectivePriority = &effective
+			decorated = true
+		}
+	}
+	if !decorated {
+		return nil
+	}
+	return out
+}
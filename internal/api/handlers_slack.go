@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/slack"
+)
+
+// handleSlackInteraction receives button clicks from Slack decision messages
+// and resolves the decision through MakeDecision.
+// POST /api/v1/webhooks/slack
+func (s *Server) handleSlackInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Graceful degradation: return 404 when integration is disabled.
+	if s.config == nil || !s.config.Slack.Enabled {
+		s.respondError(w, http.StatusNotFound, "Slack integration is not enabled")
+		return
+	}
+
+	// Read body (needed for signature verification before parsing).
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	if err := slack.VerifySignature(s.config.Slack.SigningSecret, r.Header, body, time.Now()); err != nil {
+		s.respondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid form body")
+		return
+	}
+	var payload slack.InteractionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid interaction payload")
+		return
+	}
+
+	// Slack expects a 200 for every interaction; the outcome is reflected
+	// back into the channel by the bridge when the decision resolves.
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 {
+		s.respondJSON(w, http.StatusOK, map[string]interface{}{"status": "ignored"})
+		return
+	}
+	result := s.processSlackDecision(&payload)
+	if result["status"] == "error" {
+		log.Printf("[Slack] Decision action from %s failed: %v", payload.User.ID, result["error"])
+	}
+	s.respondJSON(w, http.StatusOK, result)
+}
+
+// processSlackDecision maps an approve/deny button click to MakeDecision.
+func (s *Server) processSlackDecision(payload *slack.InteractionPayload) map[string]interface{} {
+	action := payload.Actions[0]
+	decisionID := action.Value
+
+	var decisionText, verb string
+	switch action.ActionID {
+	case slack.ActionApprove:
+		decisionText, verb = "approve", "Approved"
+	case slack.ActionDeny:
+		decisionText, verb = "deny", "Denied"
+	default:
+		return map[string]interface{}{"status": "ignored", "action_id": action.ActionID}
+	}
+
+	if s.app == nil {
+		return map[string]interface{}{"status": "error", "error": "loom not initialized"}
+	}
+	if dm := s.app.GetDecisionManager(); dm != nil {
+		if d, err := dm.GetDecision(decisionID); err == nil && d != nil && d.DecidedAt != nil {
+			return map[string]interface{}{
+				"status":      "already_decided",
+				"decision_id": decisionID,
+				"decision":    d.Decision,
+			}
+		}
+	}
+
+	who := payload.User.Username
+	if who == "" {
+		who = payload.User.ID
+	}
+	deciderID := "user-slack-" + payload.User.ID
+	rationale := verb + " via Slack by @" + who
+
+	if err := s.app.MakeDecision(decisionID, deciderID, decisionText, rationale); err != nil {
+		return map[string]interface{}{
+			"status":      "error",
+			"decision_id": decisionID,
+			"error":       err.Error(),
+		}
+	}
+
+	return map[string]interface{}{
+		"status":      "resolved",
+		"decision_id": decisionID,
+		"decision":    decisionText,
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/slack"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func slackRequest(t *testing.T, secret string, ts time.Time, payload string) *http.Request {
+	t.Helper()
+	body := []byte(url.Values{"payload": {payload}}.Encode())
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/slack", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", slack.Sign(secret, timestamp, body))
+	return req
+}
+
+func TestHandleSlackInteraction_Disabled(t *testing.T) {
+	s := &Server{config: &config.Config{}}
+	w := httptest.NewRecorder()
+	s.handleSlackInteraction(w, slackRequest(t, "secret", time.Now(), `{}`))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when disabled, got %d", w.Code)
+	}
+}
+
+func TestHandleSlackInteraction_Signature(t *testing.T) {
+	s := &Server{config: &config.Config{
+		Slack: config.SlackConfig{Enabled: true, SigningSecret: "signing-secret"},
+	}}
+	payload := `{"type":"block_actions","user":{"id":"U1"},"actions":[{"action_id":"other","value":"bd-1"}]}`
+
+	cases := []struct {
+		name   string
+		secret string
+		ts     time.Time
+		want   int
+	}{
+		{"wrong secret", "nope", time.Now(), http.StatusUnauthorized},
+		{"replayed", "signing-secret", time.Now().Add(-time.Hour), http.StatusUnauthorized},
+		{"valid", "signing-secret", time.Now(), http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.handleSlackInteraction(w, slackRequest(t, tc.secret, tc.ts, payload))
+			if w.Code != tc.want {
+				t.Errorf("got %d, want %d: %s", w.Code, tc.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleSlackInteraction_ApproveWithoutLoom(t *testing.T) {
+	s := &Server{config: &config.Config{
+		Slack: config.SlackConfig{Enabled: true, SigningSecret: "signing-secret"},
+	}}
+	payload := `{"type":"block_actions","user":{"id":"U1","username":"ceo"},"actions":[{"action_id":"` +
+		slack.ActionApprove + `","value":"bd-dec-1"}]}`
+
+	w := httptest.NewRecorder()
+	s.handleSlackInteraction(w, slackRequest(t, "signing-secret", time.Now(), payload))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var result map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result["status"] != "error" {
+		t.Errorf("expected error status without loom, got %v", result)
+	}
+}
@@ -263,7 +263,7 @@ func (s *Server) handleChatCompletion(w http.ResponseWriter, r *http.Request) {
 }
 
 func appendActionPrompt(messages []provider.ChatMessage) []provider.ChatMessage {
-	prompt := strings.TrimSpace(actions.ActionPrompt)
+	prompt := strings.TrimSpace(actions.CurrentActionPrompt())
 	if prompt == "" {
 		return messages
 	}
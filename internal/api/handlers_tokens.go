@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// tokenStore keeps the auth manager's API tokens in the database.
+type tokenStore struct {
+	db *database.Database
+}
+
+func (s tokenStore) CreateToken(t *auth.APIToken) error {
+	return s.db.CreateAPIToken(&database.APIToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		UserID:     t.UserID,
+		TokenHash:  t.Hash,
+		Prefix:     t.Prefix,
+		Scope:      t.Scope,
+		ProjectIDs: t.ProjectIDs,
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+	})
+}
+
+func (s tokenStore) ListTokens() ([]*auth.APIToken, error) {
+	stored, err := s.db.ListAPITokens()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*auth.APIToken, len(stored))
+	for i, t := range stored {
+		tokens[i] = &auth.APIToken{
+			ID:         t.ID,
+			Name:       t.Name,
+			UserID:     t.UserID,
+			Prefix:     t.Prefix,
+			Hash:       t.TokenHash,
+			Scope:      t.Scope,
+			ProjectIDs: t.ProjectIDs,
+			CreatedAt:  t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			LastUsedAt: t.LastUsedAt,
+			RevokedAt:  t.RevokedAt,
+			RevokedBy:  t.RevokedBy,
+		}
+	}
+	return tokens, nil
+}
+
+func (s tokenStore) TouchToken(id string, usedAt time.Time) error {
+	return s.db.TouchAPIToken(id, usedAt)
+}
+
+func (s tokenStore) RevokeToken(id string, revokedAt time.Time, revokedBy string) error {
+	return s.db.RevokeAPIToken(id, revokedAt, revokedBy)
+}
+
+// requireTokenManager responds 503 and returns nil when there is no auth
+// manager to keep tokens in.
+func (s *Server) requireTokenManager(w http.ResponseWriter) *auth.Manager {
+	if s.authManager == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Authentication is not configured")
+		return nil
+	}
+	return s.authManager
+}
+
+// canManageTokens reports whether the caller may create and revoke API
+// tokens. Requests made with an API token need an admin-scoped one, so a
+// token can't be used to mint a broader one.
+func canManageTokens(r *http.Request) bool {
+	id, scope := auth.GetTokenFromRequest(r)
+	return id == "" || scope == auth.ScopeAdmin
+}
+
+// handleTokens handles GET/POST /api/v1/tokens. GET lists the caller's
+// tokens; ?all=true lists every user's (admins only) and ?inactive=true
+// includes revoked and expired ones. POST creates a token, whose value is
+// only returned in that response.
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	mgr := s.requireTokenManager(w)
+	if mgr == nil {
+		return
+	}
+	userID := auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		s.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		owner := userID
+		if r.URL.Query().Get("all") == "true" {
+			if auth.GetRoleFromRequest(r) != "admin" {
+				s.respondError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+			owner = ""
+		}
+		s.respondJSON(w, http.StatusOK, mgr.ListTokens(owner, r.URL.Query().Get("inactive") == "true"))
+
+	case http.MethodPost:
+		if !canManageTokens(r) {
+			s.respondError(w, http.StatusForbidden, "API tokens can only be created with an admin-scoped token")
+			return
+		}
+		var req auth.CreateTokenRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.Scope == "" {
+			req.Scope = auth.ScopeRead
+		}
+		if s.app != nil && s.app.GetProjectManager() != nil {
+			for _, projectID := range req.ProjectIDs {
+				if _, err := s.app.GetProjectManager().GetProject(projectID); err != nil {
+					s.respondError(w, http.StatusBadRequest, "Unknown project: "+projectID)
+					return
+				}
+			}
+		}
+		resp, err := mgr.CreateToken(userID, req)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, resp)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleToken handles the tokens below /api/v1/tokens/:
+//
+//	GET    /api/v1/tokens/revoked  the revocation list (admins only)
+//	GET    /api/v1/tokens/{id}
+//	DELETE /api/v1/tokens/{id}     revokes the token
+//
+// Users see and revoke their own tokens; admins any token.
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	mgr := s.requireTokenManager(w)
+	if mgr == nil {
+		return
+	}
+	userID := auth.GetUserIDFromRequest(r)
+	if userID == "" {
+		s.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/tokens/"), "/")
+	if id == "" || strings.Contains(id, "/") {
+		s.respondError(w, http.StatusBadRequest, "Token ID is required")
+		return
+	}
+	isAdmin := auth.GetRoleFromRequest(r) == "admin"
+
+	if id == "revoked" {
+		if r.Method != http.MethodGet {
+			s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if !isAdmin {
+			s.respondError(w, http.StatusForbidden, "Admin access required")
+			return
+		}
+		s.respondJSON(w, http.StatusOK, mgr.RevokedTokens())
+		return
+	}
+
+	owner := userID
+	if isAdmin {
+		owner = ""
+	}
+	switch r.Method {
+	case http.MethodGet:
+		t, err := mgr.GetToken(id, owner)
+		if err != nil {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, t)
+
+	case http.MethodDelete:
+		// A token may always revoke itself, whatever its scope.
+		if tokenID, _ := auth.GetTokenFromRequest(r); !canManageTokens(r) && tokenID != id {
+			s.respondError(w, http.StatusForbidden, "API tokens can only be revoked with an admin-scoped token")
+			return
+		}
+		if err := mgr.RevokeToken(id, owner, userID); err != nil {
+			status := http.StatusNotFound
+			if strings.Contains(err.Error(), "already revoked") {
+				status = http.StatusConflict
+			} else if !strings.Contains(err.Error(), "not found") {
+				status = http.StatusInternalServerError
+			}
+			s.respondError(w, status, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// tokenProjectMiddleware keeps requests made with a project-limited API
+// token inside those projects. Such a token can reach a project, its
+// beads, and any route given one of its projects in ?project_id= or a
+// JSON body's project_id, and manage tokens; everything else is forbidden.
+func (s *Server) tokenProjectMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projects := auth.GetTokenProjectsFromRequest(r)
+		if len(projects) == 0 || !strings.HasPrefix(r.URL.Path, "/api/v1/") || strings.HasPrefix(r.URL.Path, "/api/v1/tokens") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed := func(projectID string) bool {
+			for _, p := range projects {
+				if p == projectID {
+					return true
+				}
+			}
+			return false
+		}
+		forbid := func() {
+			s.respondError(w, http.StatusForbidden, "API token is limited to projects "+strings.Join(projects, ", "))
+		}
+
+		scoped := false
+		if projectID := r.URL.Query().Get("project_id"); projectID != "" {
+			if !allowed(projectID) {
+				forbid()
+				return
+			}
+			scoped = true
+		}
+		if r.Body != nil && (r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				s.respondError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			var payload struct {
+				ProjectID *string `json:"project_id"`
+			}
+			if json.Unmarshal(body, &payload) == nil && payload.ProjectID != nil {
+				if !allowed(*payload.ProjectID) {
+					forbid()
+					return
+				}
+				scoped = true
+			}
+		}
+
+		resourceType, id := apiResource(r.URL.Path)
+		switch {
+		case resourceType == "projects" && id != "" && !orgReservedIDs["projects/"+id]:
+			scoped = allowed(id)
+		case resourceType == "beads" && id != "" && s.app != nil && s.app.GetBeadsManager() != nil:
+			bead, err := s.app.GetBeadsManager().GetBead(id)
+			if err == nil && bead != nil {
+				scoped = allowed(bead.ProjectID)
+			}
+		}
+		if !scoped {
+			forbid()
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+)
+
+func TestTokenStore_RoundTrip(t *testing.T) {
+	db := dbtest.New(t)
+
+	m := auth.NewManager("secret")
+	if err := m.SetTokenStore(tokenStore{db: db}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := m.CreateToken("user-admin", auth.CreateTokenRequest{Name: "ci", Scope: auth.ScopeWrite, ProjectIDs: []string{"loom"}, TTL: "7d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RevokeToken(created.ID, "", "user-admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := auth.NewManager("secret")
+	if err := reloaded.SetTokenStore(tokenStore{db: db}); err != nil {
+		t.Fatal(err)
+	}
+	revoked := reloaded.RevokedTokens()
+	if len(revoked) != 1 || revoked[0].ID != created.ID || revoked[0].RevokedBy != "user-admin" {
+		t.Fatalf("RevokedTokens() after reload = %+v", revoked)
+	}
+	if got := revoked[0]; got.Scope != auth.ScopeWrite || len(got.ProjectIDs) != 1 || got.ExpiresAt == nil {
+		t.Errorf("token not stored faithfully: %+v", got)
+	}
+}
+
+func TestHandleTokens_CreateAndRevoke(t *testing.T) {
+	s := newTestServer()
+	s.authManager = auth.NewManager("secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tokens", strings.NewReader(`{"name":"ci","scope":"read","ttl":"30d"}`))
+	req.Header.Set("X-User-ID", "user-admin")
+	w := httptest.NewRecorder()
+	s.handleTokens(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", w.Code, w.Body)
+	}
+	var created auth.CreateTokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Token == "" {
+		t.Fatalf("create response %s: %v", w.Body, err)
+	}
+
+	// The new read token can't create further tokens.
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/tokens", strings.NewReader(`{"name":"more","scope":"read"}`))
+	req.Header.Set("X-User-ID", "user-admin")
+	req.Header.Set("X-Token-ID", created.ID)
+	req.Header.Set("X-Token-Scope", auth.ScopeRead)
+	w = httptest.NewRecorder()
+	s.handleTokens(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("create with a read token: status = %d, want 403", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/tokens/"+created.ID, nil)
+	req.Header.Set("X-User-ID", "someone-else")
+	w = httptest.NewRecorder()
+	s.handleToken(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("revoking another user's token: status = %d, want 404", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/tokens/"+created.ID, nil)
+	req.Header.Set("X-User-ID", "user-admin")
+	w = httptest.NewRecorder()
+	s.handleToken(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("revoke: status = %d, body %s", w.Code, w.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/tokens/revoked", nil)
+	req.Header.Set("X-User-ID", "user-admin")
+	req.Header.Set("X-Role", "admin")
+	w = httptest.NewRecorder()
+	s.handleToken(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), created.ID) {
+		t.Errorf("revocation list: status = %d, body %s", w.Code, w.Body)
+	}
+}
+
+func TestTokenProjectMiddleware(t *testing.T) {
+	s := newTestServer()
+	handler := s.tokenProjectMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name, method, path, body string
+		projects                 string
+		want                     int
+	}{
+		{"unlimited token", http.MethodGet, "/api/v1/providers", "", "", http.StatusOK},
+		{"own project", http.MethodGet, "/api/v1/projects/loom", "", "loom", http.StatusOK},
+		{"other project", http.MethodGet, "/api/v1/projects/other", "", "loom", http.StatusForbidden},
+		{"project list", http.MethodGet, "/api/v1/projects", "", "loom", http.StatusForbidden},
+		{"own project_id", http.MethodGet, "/api/v1/beads?project_id=loom", "", "loom", http.StatusOK},
+		{"other project_id", http.MethodGet, "/api/v1/beads?project_id=other", "", "loom", http.StatusForbidden},
+		{"create in own project", http.MethodPost, "/api/v1/beads", `{"title":"x","project_id":"loom"}`, "loom", http.StatusOK},
+		{"create in other project", http.MethodPost, "/api/v1/beads", `{"title":"x","project_id":"other"}`, "loom", http.StatusForbidden},
+		{"unscoped route", http.MethodGet, "/api/v1/providers", "", "loom", http.StatusForbidden},
+		{"token management", http.MethodGet, "/api/v1/tokens", "", "loom", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.projects != "" {
+				req.Header.Set("X-Token-Projects", tt.projects)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// userStore keeps the auth manager's users in the database.
+type userStore struct {
+	db *database.Database
+}
+
+func toAuthUser(u *database.User) *auth.User {
+	return &auth.User{
+		ID:                    u.ID,
+		Username:              u.Username,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		IsActive:              u.IsActive,
+		CreatedAt:             u.CreatedAt,
+		UpdatedAt:             u.UpdatedAt,
+		PasswordResetRequired: u.PasswordResetRequired,
+		LastLoginAt:           u.LastLoginAt,
+		SessionsRevokedAt:     u.SessionsRevokedAt,
+		ExternalID:            u.ExternalID,
+	}
+}
+
+func (s userStore) LoadUsers() ([]*auth.User, map[string]string, error) {
+	stored, err := s.db.ListAllUsers()
+	if err != nil {
+		return nil, nil, err
+	}
+	users := make([]*auth.User, len(stored))
+	passwords := make(map[string]string)
+	for i, u := range stored {
+		users[i] = toAuthUser(u)
+		if u.PasswordHash != "" {
+			passwords[u.ID] = u.PasswordHash
+		}
+	}
+	return users, passwords, nil
+}
+
+func (s userStore) GetUserByExternalID(externalID string) (*auth.User, error) {
+	u, err := s.db.GetUserByExternalID(externalID)
+	if err != nil || u == nil {
+		return nil, err
+	}
+	return toAuthUser(u), nil
+}
+
+func (s userStore) SaveUser(u *auth.User) error {
+	return s.db.SaveUser(&database.User{
+		ID:                    u.ID,
+		Username:              u.Username,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		IsActive:              u.IsActive,
+		PasswordResetRequired: u.PasswordResetRequired,
+		LastLoginAt:           u.LastLoginAt,
+		SessionsRevokedAt:     u.SessionsRevokedAt,
+		ExternalID:            u.ExternalID,
+		CreatedAt:             u.CreatedAt,
+		UpdatedAt:             u.UpdatedAt,
+	})
+}
+
+func (s userStore) SetPassword(userID, hash string) error {
+	return s.db.SetUserPassword(userID, hash)
+}
+
+func (s userStore) RevokeSession(t *auth.Token) error {
+	return s.db.RevokeSession(&database.RevokedSession{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: *t.RevokedAt,
+	})
+}
+
+func (s userStore) LoadRevokedSessions() ([]*auth.Token, error) {
+	stored, err := s.db.ListRevokedSessions(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*auth.Token, len(stored))
+	for i, r := range stored {
+		revokedAt := r.RevokedAt
+		sessions[i] = &auth.Token{ID: r.ID, UserID: r.UserID, ExpiresAt: r.ExpiresAt, RevokedAt: &revokedAt}
+	}
+	return sessions, nil
+}
+
+// createdUser is a new user, with the temporary password generated for
+// them when none was given.
+type createdUser struct {
+	*auth.User
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+}
+
+// requireUserAdmin responds and returns nil unless the caller is an admin
+// and there is an auth manager holding users.
+func (s *Server) requireUserAdmin(w http.ResponseWriter, r *http.Request) *auth.Manager {
+	mgr := s.requireTokenManager(w)
+	if mgr == nil {
+		return nil
+	}
+	if auth.GetRoleFromRequest(r) != "admin" {
+		s.respondError(w, http.StatusForbidden, "Admin access required")
+		return nil
+	}
+	return mgr
+}
+
+// handleUsers handles GET/POST /api/v1/users (admins only). POST creates
+// a user; without a password one is generated, returned once, and must be
+// changed at first sign-in.
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	mgr := s.requireUserAdmin(w, r)
+	if mgr == nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		users := mgr.ListUsers()
+		if users == nil {
+			users = []*auth.User{}
+		}
+		s.respondJSON(w, http.StatusOK, users)
+
+	case http.MethodPost:
+		var req struct {
+			Username string `json:"username"`
+			Email    string `json:"email"`
+			Role     string `json:"role"`
+			Password string `json:"password"`
+		}
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		req.Username = strings.TrimSpace(req.Username)
+		if req.Username == "" {
+			s.respondError(w, http.StatusBadRequest, "username is required")
+			return
+		}
+		if req.Role == "" {
+			req.Role = "viewer"
+		}
+		generate := req.Password == ""
+		if generate {
+			req.Password = auth.GeneratePassword()
+		}
+		user, err := mgr.CreateUser(req.Username, req.Email, req.Role, req.Password)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp := createdUser{User: user}
+		if generate {
+			if resp.TemporaryPassword, err = mgr.ResetPassword(user.ID, req.Password); err != nil {
+				s.respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		s.respondJSON(w, http.StatusCreated, resp)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleUser handles a user below /api/v1/users/ (admins only):
+//
+//	GET    /api/v1/users/{id}
+//	PATCH  /api/v1/users/{id}                   {"role": "..."}
+//	POST   /api/v1/users/{id}/disable
+//	POST   /api/v1/users/{id}/enable
+//	POST   /api/v1/users/{id}/reset-password    {"password": "..."}, optional
+//	GET    /api/v1/users/{id}/sessions
+//	DELETE /api/v1/users/{id}/sessions          revokes them all
+//	DELETE /api/v1/users/{id}/sessions/{sid}
+//	GET    /api/v1/users/{id}/activity          the user's audit log entries
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	mgr := s.requireUserAdmin(w, r)
+	if mgr == nil {
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/users/"), "/"), "/")
+	id := parts[0]
+	if id == "" || len(parts) > 3 {
+		s.respondError(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+	user, err := mgr.GetUser(id)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	self := id == auth.GetUserIDFromRequest(r)
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	if len(parts) == 3 && action != "sessions" {
+		s.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		s.respondJSON(w, http.StatusOK, user)
+
+	case action == "" && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := s.parseJSON(r, &req); err != nil || req.Role == "" {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body: role is required")
+			return
+		}
+		if self && req.Role != "admin" {
+			s.respondError(w, http.StatusBadRequest, "Admins cannot demote themselves")
+			return
+		}
+		user, err := mgr.SetUserRole(id, req.Role)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, user)
+
+	case (action == "disable" || action == "enable") && r.Method == http.MethodPost:
+		if self && action == "disable" {
+			s.respondError(w, http.StatusBadRequest, "Admins cannot disable themselves")
+			return
+		}
+		user, err := mgr.SetUserActive(id, action == "enable")
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, user)
+
+	case action == "reset-password" && r.Method == http.MethodPost:
+		var req struct {
+			Password string `json:"password"`
+		}
+		if r.ContentLength != 0 {
+			if err := s.parseJSON(r, &req); err != nil {
+				s.respondError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+		}
+		password, err := mgr.ResetPassword(id, req.Password)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]string{"temporary_password": password})
+
+	case action == "sessions" && len(parts) == 2 && r.Method == http.MethodGet:
+		s.respondJSON(w, http.StatusOK, mgr.ListSessions(id))
+
+	case action == "sessions" && len(parts) == 2 && r.Method == http.MethodDelete:
+		n, err := mgr.RevokeSessions(id)
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]int{"revoked": n})
+
+	case action == "sessions" && len(parts) == 3 && r.Method == http.MethodDelete:
+		if err := mgr.RevokeSession(id, parts[2]); err != nil {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case action == "activity" && r.Method == http.MethodGet:
+		// The user's history is their audit log entries.
+		q := r.URL.Query()
+		q.Set("actor", id)
+		activity := r.Clone(r.Context())
+		activity.URL.RawQuery = q.Encode()
+		s.handleAudit(w, activity)
+
+	case action == "" || action == "disable" || action == "enable" || action == "reset-password" ||
+		action == "sessions" || action == "activity":
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+
+	default:
+		s.respondError(w, http.StatusNotFound, "Not found")
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+)
+
+func TestUserStore_RoundTrip(t *testing.T) {
+	db := dbtest.New(t)
+
+	m := auth.NewManager("secret")
+	if err := m.SetUserStore(userStore{db: db}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := m.LoginExternal("https://idp.example.com#00u1", "ada", "ada@example.com", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	carol, err := m.CreateUser("carol", "", "operator", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SetUserActive(carol.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := m.ValidateToken(first.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RevokeSession(first.User.ID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := auth.NewManager("secret")
+	if err := reloaded.SetUserStore(userStore{db: db}); err != nil {
+		t.Fatal(err)
+	}
+	again, err := reloaded.LoginExternal("https://idp.example.com#00u1", "ada", "ada@corp.example.com", "operator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.User.ID != first.User.ID {
+		t.Errorf("user ID changed across restarts: %s -> %s", first.User.ID, again.User.ID)
+	}
+	stored, err := db.GetUserByUsername("ada")
+	if err != nil || stored == nil {
+		t.Fatalf("GetUserByUsername() = %v, %v", stored, err)
+	}
+	if stored.Role != "operator" || stored.Email != "ada@corp.example.com" || stored.LastLoginAt == nil {
+		t.Errorf("stored user = %+v", stored)
+	}
+	if _, err := reloaded.ValidateToken(first.Token); err == nil {
+		t.Error("revoked session valid after reload")
+	}
+	if got, err := reloaded.GetUser(carol.ID); err != nil || got.IsActive {
+		t.Errorf("disabled user after reload = %+v, %v", got, err)
+	}
+	// The default admin keeps its password though the stored row has none.
+	if _, err := reloaded.Login("admin", "admin"); err != nil {
+		t.Errorf("default admin can't sign in after reload: %v", err)
+	}
+}
+
+func TestHandleUsers_Admin(t *testing.T) {
+	s := newTestServer()
+	s.authManager = auth.NewManager("secret")
+	call := func(handler http.HandlerFunc, method, path, body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User-ID", "user-admin")
+		req.Header.Set("X-Role", role)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	if w := call(s.handleUsers, http.MethodGet, "/api/v1/users", "", "operator"); w.Code != http.StatusForbidden {
+		t.Errorf("operator listing users: status = %d, want 403", w.Code)
+	}
+
+	w := call(s.handleUsers, http.MethodPost, "/api/v1/users", `{"username":"dana","role":"operator"}`, "admin")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", w.Code, w.Body)
+	}
+	var created struct {
+		ID                    string `json:"id"`
+		TemporaryPassword     string `json:"temporary_password"`
+		PasswordResetRequired bool   `json:"password_reset_required"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.TemporaryPassword == "" || !created.PasswordResetRequired {
+		t.Errorf("user created without a password = %s", w.Body)
+	}
+	if _, err := s.authManager.Login("dana", created.TemporaryPassword); err != nil {
+		t.Errorf("temporary password doesn't work: %v", err)
+	}
+
+	if w := call(s.handleUser, http.MethodGet, "/api/v1/users/"+created.ID+"/sessions", "", "admin"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), created.ID) {
+		t.Errorf("sessions: status = %d, body %s", w.Code, w.Body)
+	}
+	if w := call(s.handleUser, http.MethodPost, "/api/v1/users/"+created.ID+"/disable", "", "admin"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"is_active":false`) {
+		t.Errorf("disable: status = %d, body %s", w.Code, w.Body)
+	}
+	if w := call(s.handleUser, http.MethodGet, "/api/v1/users/"+created.ID+"/sessions", "", "admin"); strings.Contains(w.Body.String(), created.ID) {
+		t.Errorf("sessions after disabling = %s, want none", w.Body)
+	}
+	if w := call(s.handleUser, http.MethodPost, "/api/v1/users/user-admin/disable", "", "admin"); w.Code != http.StatusBadRequest {
+		t.Errorf("admin disabling themselves: status = %d, want 400", w.Code)
+	}
+	if w := call(s.handleUser, http.MethodPost, "/api/v1/users/"+created.ID+"/reset-password", `{"password":"temp-pass"}`, "admin"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "temp-pass") {
+		t.Errorf("reset-password: status = %d, body %s", w.Code, w.Body)
+	}
+	if w := call(s.handleUser, http.MethodGet, "/api/v1/users/nobody", "", "admin"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/webhooks"
+)
+
+// handleWebhooks handles GET/POST /api/v1/webhooks (outbound event webhooks)
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetWebhooksManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Webhooks require a database")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := mgr.List()
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, hooks)
+
+	case http.MethodPost:
+		var req webhooks.CreateRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		hook, err := mgr.Create(req, auth.GetUserIDFromRequest(r))
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusCreated, hook)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleWebhook handles /api/v1/webhooks/{id}, /api/v1/webhooks/{id}/deliveries,
+// and /api/v1/webhooks/{id}/test
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	mgr := s.app.GetWebhooksManager()
+	if mgr == nil {
+		s.respondError(w, http.StatusServiceUnavailable, "Webhooks require a database")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+	id := parts[0]
+	if id == "" {
+		s.respondError(w, http.StatusBadRequest, "Webhook ID is required")
+		return
+	}
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "deliveries" && r.Method == http.MethodGet:
+		limit := 50
+		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+			limit = min(l, 500)
+		}
+		deliveries, err := mgr.Deliveries(id, limit)
+		if err != nil {
+			s.respondWebhookError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, deliveries)
+
+	case action == "test" && r.Method == http.MethodPost:
+		delivery, err := mgr.Test(r.Context(), id)
+		if err != nil {
+			s.respondWebhookError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, delivery)
+
+	case action != "":
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+
+	case r.Method == http.MethodGet:
+		hook, err := mgr.Get(id)
+		if err != nil {
+			s.respondWebhookError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, hook)
+
+	case r.Method == http.MethodPatch || r.Method == http.MethodPut:
+		var req webhooks.UpdateRequest
+		if err := s.parseJSON(r, &req); err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		hook, err := mgr.Update(id, req)
+		if err != nil {
+			s.respondWebhookError(w, err)
+			return
+		}
+		s.respondJSON(w, http.StatusOK, hook)
+
+	case r.Method == http.MethodDelete:
+		if err := mgr.Delete(id); err != nil {
+			s.respondWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) respondWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, webhooks.ErrNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.respondError(w, http.StatusBadRequest, err.Error())
+}
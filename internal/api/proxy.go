@@ -0,0 +1,182 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// trustedProxies matches the addresses of reverse proxies whose forwarded
+// headers are believed.
+type trustedProxies []netip.Prefix
+
+// parseTrustedProxies parses server.trusted_proxies, skipping and logging
+// entries that are neither an address nor a CIDR range.
+func parseTrustedProxies(entries []string) trustedProxies {
+	var proxies trustedProxies
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			proxies = append(proxies, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			log.Printf("[API] Ignoring trusted proxy %q: not an address or CIDR range", entry)
+		}
+	}
+	return proxies
+}
+
+func (p trustedProxies) contains(ip string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedClient is the client address in an X-Forwarded-For chain: the
+// last one that isn't a trusted proxy, since anything before it could
+// have been sent by the client itself.
+func (p trustedProxies) forwardedClient(chain string) string {
+	hops := strings.Split(chain, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop != "" && !p.contains(hop) {
+			return hop
+		}
+	}
+	return strings.TrimSpace(hops[0])
+}
+
+// proxyMiddleware makes requests that came through a trusted reverse
+// proxy look as they did to the proxy: RemoteAddr becomes the client's
+// address, for the audit log, request logs and rate limits, and
+// X-Forwarded-Proto the scheme the client used. Forwarded headers from
+// anyone else are dropped. With security.require_https, plain HTTP
+// requests are redirected to HTTPS, health probes aside.
+func (s *Server) proxyMiddleware(next http.Handler) http.Handler {
+	proxies := parseTrustedProxies(s.config.Server.TrustedProxies)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		if len(proxies) > 0 && proxies.contains(clientIP(r)) {
+			if chain := strings.Join(r.Header.Values("X-Forwarded-For"), ","); chain != "" {
+				if ip := proxies.forwardedClient(chain); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+			if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "http" || proto == "https" {
+				scheme = proto
+			}
+		} else {
+			r.Header.Del("X-Forwarded-For")
+		}
+		r.Header.Set("X-Forwarded-Proto", scheme)
+
+		if s.config.Security.RequireHTTPS && scheme != "https" && !isHealthPath(strings.TrimPrefix(r.URL.Path, s.basePath())) {
+			http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isHealthPath reports whether path is a health probe, which works over
+// plain HTTP and without credentials.
+func isHealthPath(path string) bool {
+	switch path {
+	case "/health", "/health/live", "/health/ready", "/api/v1/health":
+		return true
+	}
+	return false
+}
+
+// basePath is server.base_path cleaned up: "" or a prefix such as "/loom"
+// with a leading slash and no trailing one.
+func (s *Server) basePath() string {
+	if s.config == nil {
+		return ""
+	}
+	base := strings.Trim(strings.TrimSpace(s.config.Server.BasePath), "/")
+	if base == "" {
+		return ""
+	}
+	return "/" + base
+}
+
+// basePathMiddleware strips server.base_path from requests under it.
+// Requests without it are served as they are, for proxies that strip the
+// prefix themselves and for probes that go straight to the server.
+func (s *Server) basePathMiddleware(next http.Handler) http.Handler {
+	base := s.basePath()
+	if base == "" {
+		return next
+	}
+	stripped := http.StripPrefix(base, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == base:
+			http.Redirect(w, r, base+"/", http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, base+"/"):
+			stripped.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// servePage serves an HTML page of the web UI. Under a base path, its
+// root-relative links to /static/ and /api/ get the prefix, and
+// base-path.js is loaded first to prefix the API calls the scripts make.
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request, file string) {
+	base := s.basePath()
+	if base == "" {
+		http.ServeFile(w, r, file)
+		return
+	}
+	page, err := os.ReadFile(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	html := string(page)
+	for _, dir := range []string{"static", "api"} {
+		html = strings.ReplaceAll(html, `"/`+dir+`/`, `"`+base+`/`+dir+`/`)
+	}
+	shim := `<script>window.LOOM_BASE_PATH = "` + base + `";</script>` +
+		`<script src="` + base + `/static/js/base-path.js"></script>`
+	if i := strings.Index(html, "<head>"); i >= 0 {
+		html = html[:i+len("<head>")] + shim + html[i+len("<head>"):]
+	} else {
+		html = shim + html
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(html))
+}
+
+// pageServer serves the web UI's static files, passing HTML pages through
+// servePage.
+func (s *Server) pageServer(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.FromSlash(strings.TrimPrefix(r.URL.Path, "/"))
+		if s.basePath() != "" && strings.HasSuffix(name, ".html") && filepath.IsLocal(name) {
+			s.servePage(w, r, filepath.Join(dir, name))
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
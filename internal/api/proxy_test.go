@@ -0,0 +1,183 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestProxyMiddleware_TrustedProxy(t *testing.T) {
+	s := newTestServer()
+	s.config.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "bogus"}
+	var gotAddr, gotProto string
+	handler := s.proxyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAddr, gotProto = r.RemoteAddr, r.Header.Get("X-Forwarded-Proto")
+	}))
+
+	tests := []struct {
+		name, remote, fwd, proto string
+		wantAddr, wantProto      string
+	}{
+		{"trusted", "10.1.2.3:4000", "203.0.113.9", "https", "203.0.113.9", "https"},
+		{"proxy chain", "10.1.2.3:4000", "198.51.100.1, 203.0.113.9, 192.168.1.1", "https", "203.0.113.9", "https"},
+		{"untrusted", "203.0.113.50:4000", "1.2.3.4", "https", "203.0.113.50:4000", "http"},
+		{"no forwarded headers", "10.1.2.3:4000", "", "", "10.1.2.3:4000", "http"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/beads", nil)
+		req.RemoteAddr = tt.remote
+		if tt.fwd != "" {
+			req.Header.Set("X-Forwarded-For", tt.fwd)
+		}
+		if tt.proto != "" {
+			req.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if gotAddr != tt.wantAddr || gotProto != tt.wantProto {
+			t.Errorf("%s: RemoteAddr %q, scheme %q; want %q, %q", tt.name, gotAddr, gotProto, tt.wantAddr, tt.wantProto)
+		}
+	}
+}
+
+func TestProxyMiddleware_RequireHTTPS(t *testing.T) {
+	s := newTestServer()
+	s.config.Security.RequireHTTPS = true
+	s.config.Server.TrustedProxies = []string{"10.0.0.1"}
+	handler := s.proxyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://loom.example.com/api/v1/beads?x=1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "https://loom.example.com/api/v1/beads?x=1" {
+		t.Errorf("plain HTTP: %d to %q, want 308 to HTTPS", w.Code, w.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/beads", nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("HTTPS through a trusted proxy: %d, want 200", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health/live", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("health probe over plain HTTP: %d, want 200", w.Code)
+	}
+}
+
+func TestBasePathMiddleware(t *testing.T) {
+	s := newTestServer()
+	s.config.Server.BasePath = "loom/"
+	var gotPath string
+	handler := s.basePathMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+
+	for path, want := range map[string]string{
+		"/loom/api/v1/beads": "/api/v1/beads",
+		"/loom/":             "/",
+		"/api/v1/beads":      "/api/v1/beads",
+		"/health":            "/health",
+		"/loomy/x":           "/loomy/x",
+	} {
+		gotPath = ""
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if gotPath != want {
+			t.Errorf("%s: handler saw %q, want %q", path, gotPath, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loom", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/loom/" {
+		t.Errorf("/loom: %d to %q, want a redirect to /loom/", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestServePage_BasePath(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "index.html")
+	if err := os.WriteFile(page, []byte(`<html><head><link href="/static/css/a.css"></head><a href="/api/v1/health">x</a></html>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer()
+	s.config.Server.BasePath = "/loom"
+	w := httptest.NewRecorder()
+	s.servePage(w, httptest.NewRequest(http.MethodGet, "/", nil), page)
+	body := w.Body.String()
+	for _, want := range []string{
+		`<head><script>window.LOOM_BASE_PATH = "/loom";</script><script src="/loom/static/js/base-path.js"></script>`,
+		`href="/loom/static/css/a.css"`,
+		`href="/loom/api/v1/health"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page lacks %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestCORSMiddleware_ServerConfig(t *testing.T) {
+	s := newTestServer()
+	s.config.Security.AllowedOrigins = []string{"http://legacy.com"}
+	s.config.Server.CORS = config.CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedHeaders:   []string{"X-Trace-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handler := s.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for origin, allowed := range map[string]bool{
+		"https://app.example.com": true,
+		"https://example.com":     false,
+		"http://app.example.com":  false,
+		"http://legacy.com":       false,
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/beads", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if allowed && (got != origin || w.Header().Get("Access-Control-Allow-Credentials") != "true") {
+			t.Errorf("%s: allow origin %q, want it echoed with credentials", origin, got)
+		}
+		if !allowed && got != "" {
+			t.Errorf("%s: allow origin %q, want none", origin, got)
+		}
+		if h := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(h, "X-Trace-ID") {
+			t.Errorf("allow headers %q lack the configured one", h)
+		}
+		if w.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("max age %q, want 600", w.Header().Get("Access-Control-Max-Age"))
+		}
+	}
+}
+
+func TestCORSMiddleware_NoCredentialsForAnyOrigin(t *testing.T) {
+	s := newTestServer()
+	s.config.Server.CORS = config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	handler := s.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/beads", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("allow origin %q, want *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("allow credentials %q for any origin, want none", got)
+	}
+}
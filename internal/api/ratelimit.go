@@ -0,0 +1,202 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// rateLimitIdle is how long a bucket goes unused before it is dropped. A
+// dropped bucket comes back full, which any bucket idle this long is.
+const rateLimitIdle = 10 * time.Minute
+
+// rateLimiter keeps a token bucket per key.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateCheck is one limit a request is counted against.
+type rateCheck struct {
+	limit string // "ip", "token" or "route_group", for metrics and errors
+	group string
+	key   string
+	rate  config.RateLimit
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+// burst is the size of a limit's bucket.
+func burst(limit config.RateLimit) float64 {
+	if limit.Burst > 0 {
+		return float64(limit.Burst)
+	}
+	return math.Max(1, math.Ceil(limit.Rate))
+}
+
+// allow takes a token from the bucket of every check, or from none of them
+// when one is empty. It returns the first empty one and how long until it
+// has a token again.
+func (l *rateLimiter) allow(checks []rateCheck) (*rateCheck, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	buckets := make([]*tokenBucket, len(checks))
+	for i, c := range checks {
+		b, ok := l.buckets[c.key]
+		if !ok {
+			b = &tokenBucket{tokens: burst(c.rate), last: now}
+			l.buckets[c.key] = b
+		}
+		b.tokens = math.Min(burst(c.rate), b.tokens+now.Sub(b.last).Seconds()*c.rate.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			return &checks[i], time.Duration((1 - b.tokens) / c.rate.Rate * float64(time.Second))
+		}
+		buckets[i] = b
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return nil, 0
+}
+
+// sweep drops idle buckets, at most once a minute, so the limiter doesn't
+// grow with every client it has seen.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > rateLimitIdle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ipRateLimitMiddleware enforces the per-IP limit of security.rate_limits.
+// It runs before authMiddleware, so clients that fail to authenticate, and
+// clients guessing passwords or keys, are limited too; no admin bypasses
+// it, since nobody is known to be an admin yet.
+func (s *Server) ipRateLimitMiddleware(next http.Handler) http.Handler {
+	if s.config == nil || !s.config.Security.RateLimits.Enabled || s.config.Security.RateLimits.PerIP.Rate <= 0 {
+		return next
+	}
+	rate := s.config.Security.RateLimits.PerIP
+	limiter := newRateLimiter()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		denied, wait := limiter.allow([]rateCheck{{limit: "ip", key: "ip:" + clientIP(r), rate: rate}})
+		if denied != nil {
+			s.respondRateLimited(w, denied, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitMiddleware enforces the per-token and route group limits of
+// security.rate_limits. It runs once authMiddleware has identified the
+// caller, and admins skip these limits with admin_bypass.
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	if s.config == nil || !s.config.Security.RateLimits.Enabled {
+		return next
+	}
+	cfg := s.config.Security.RateLimits
+	limiter := newRateLimiter()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || (cfg.AdminBypass && r.Header.Get("X-Role") == "admin") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		caller := "ip:" + clientIP(r)
+		var checks []rateCheck
+		if user := r.Header.Get("X-User-ID"); user != "" {
+			caller = "user:" + user
+			if cfg.PerToken.Rate > 0 {
+				// Each API token has a bucket of its own; sessions and API
+				// keys share their user's.
+				key := caller
+				if tokenID, _ := auth.GetTokenFromRequest(r); tokenID != "" {
+					key = "token:" + tokenID
+				}
+				checks = append(checks, rateCheck{limit: "token", key: key, rate: cfg.PerToken})
+			}
+		}
+		if group := routeGroupOf(r.URL.Path); group != "" {
+			if limit := cfg.RouteGroups[group]; limit.Rate > 0 {
+				checks = append(checks, rateCheck{limit: "route_group", group: group, key: "group:" + group + ":" + caller, rate: limit})
+			}
+		}
+
+		denied, wait := limiter.allow(checks)
+		if denied != nil {
+			s.respondRateLimited(w, denied, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// respondRateLimited answers 429 for the limit a request ran into, with
+// a Retry-After of the seconds until it allows another one.
+func (s *Server) respondRateLimited(w http.ResponseWriter, denied *rateCheck, wait time.Duration) {
+	if s.metrics != nil {
+		s.metrics.RecordRateLimited(denied.limit, denied.group)
+	}
+	retry := max(1, int(math.Ceil(wait.Seconds())))
+	what := "per-" + denied.limit
+	if denied.group != "" {
+		what = denied.group + " route group"
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	s.respondError(w, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded (%s); retry in %ds", what, retry))
+}
+
+// routeGroupOf is the name of the RBAC route group a path belongs to, or
+// "" when it belongs to none.
+func routeGroupOf(path string) string {
+	for _, g := range routeGroups {
+		if path != g.prefix && !strings.HasPrefix(path, g.prefix+"/") {
+			continue
+		}
+		if g.resource != "" {
+			return g.resource
+		}
+		resource, _, _ := strings.Cut(g.permission, ":")
+		return resource
+	}
+	return ""
+}
+
+// clientIP is the address a request came from. proxyMiddleware has
+// already replaced a trusted proxy's address with its client's.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestRateLimiter_BurstAndRefill(t *testing.T) {
+	l := newRateLimiter()
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	checks := []rateCheck{{limit: "token", key: "token:u", rate: config.RateLimit{Rate: 2, Burst: 3}}}
+
+	for i := 0; i < 3; i++ {
+		if denied, _ := l.allow(checks); denied != nil {
+			t.Fatalf("request %d of the burst was denied", i+1)
+		}
+	}
+	denied, wait := l.allow(checks)
+	if denied == nil {
+		t.Fatal("request past the burst was allowed")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %v, want 500ms at 2 requests per second", wait)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if denied, _ := l.allow(checks); denied != nil {
+		t.Error("request after the bucket refilled was denied")
+	}
+}
+
+func TestRateLimiter_AllOrNothing(t *testing.T) {
+	l := newRateLimiter()
+	l.now = func() time.Time { return time.Unix(0, 0) }
+	ip := rateCheck{limit: "ip", key: "ip:a", rate: config.RateLimit{Rate: 1, Burst: 5}}
+	group := rateCheck{limit: "route_group", group: "beads", key: "group:beads:ip:a", rate: config.RateLimit{Rate: 1, Burst: 1}}
+
+	if denied, _ := l.allow([]rateCheck{ip, group}); denied != nil {
+		t.Fatal("first request was denied")
+	}
+	for i := 0; i < 3; i++ {
+		if denied, _ := l.allow([]rateCheck{ip, group}); denied == nil || denied.group != "beads" {
+			t.Fatalf("denied = %+v, want the beads route group", denied)
+		}
+	}
+	// The denied requests didn't use up the per-IP bucket.
+	for i := 0; i < 4; i++ {
+		if denied, _ := l.allow([]rateCheck{ip}); denied != nil {
+			t.Fatalf("per-IP request %d was denied", i+1)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := newTestServer()
+	s.config.Security.RateLimits = config.RateLimitConfig{
+		Enabled:     true,
+		PerIP:       config.RateLimit{Rate: 1, Burst: 3},
+		RouteGroups: map[string]config.RateLimit{"providers": {Rate: 0.1, Burst: 1}},
+		AdminBypass: true,
+	}
+	handler := s.ipRateLimitMiddleware(s.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	do := func(path, addr, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = addr
+		if role != "" {
+			req.Header.Set("X-Role", role)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("/api/v1/providers", "10.0.0.1:1000", ""); w.Code != http.StatusOK {
+		t.Fatalf("first request: %d", w.Code)
+	}
+	w := do("/api/v1/providers", "10.0.0.1:1001", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "10" {
+		t.Fatalf("second providers request: %d, Retry-After %q; want 429 after 10s", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := do("/api/v1/beads", "10.0.0.1:1002", ""); w.Code != http.StatusOK {
+		t.Errorf("request outside the limited group: %d", w.Code)
+	}
+	if w := do("/api/v1/beads", "10.0.0.1:1003", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("request past the per-IP burst: %d, want 429", w.Code)
+	}
+	if w := do("/api/v1/beads", "10.0.0.2:1000", ""); w.Code != http.StatusOK {
+		t.Errorf("request from another address: %d", w.Code)
+	}
+	for i := 0; i < 3; i++ {
+		if w := do("/api/v1/providers", "10.0.0.3:1000", "admin"); w.Code != http.StatusOK {
+			t.Fatalf("admin request %d: %d, want the admin bypass", i+1, w.Code)
+		}
+	}
+	// The per-IP limit is checked before the caller is known, so claiming
+	// to be an admin doesn't get past it.
+	if w := do("/api/v1/providers", "10.0.0.3:1001", "admin"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("admin request past the per-IP burst: %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimitMiddleware_PerToken(t *testing.T) {
+	s := newTestServer()
+	s.config.Security.RateLimits = config.RateLimitConfig{
+		Enabled:  true,
+		PerToken: config.RateLimit{Rate: 0.1, Burst: 1},
+	}
+	handler := s.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(user, tokenID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/beads", nil)
+		req.Header.Set("X-User-ID", user)
+		if tokenID != "" {
+			req.Header.Set("X-Token-ID", tokenID)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Two tokens of the same user each get their own bucket.
+	if code := do("user-1", "tok-a"); code != http.StatusOK {
+		t.Fatalf("first request with token a: %d", code)
+	}
+	if code := do("user-1", "tok-b"); code != http.StatusOK {
+		t.Errorf("first request with token b: %d, want its own bucket", code)
+	}
+	if code := do("user-1", "tok-a"); code != http.StatusTooManyRequests {
+		t.Errorf("second request with token a: %d, want 429", code)
+	}
+
+	// A session is limited per user, apart from the user's tokens.
+	if code := do("user-1", ""); code != http.StatusOK {
+		t.Errorf("first session request: %d", code)
+	}
+	if code := do("user-1", ""); code != http.StatusTooManyRequests {
+		t.Errorf("second session request: %d, want 429", code)
+	}
+}
+
+func TestRouteGroupOf(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v1/beads/bd-1":              "beads",
+		"/api/v1/repl":                    "repl",
+		"/api/v1/commands/policies/check": "agents",
+		"/api/v1/health":                  "",
+		"/api/v1/providersx":              "",
+		"/api/v1/admin/backups/x/restore": "system",
+	} {
+		if got := routeGroupOf(path); got != want {
+			t.Errorf("routeGroupOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for addr, want := range map[string]string{"10.0.0.9:5555": "10.0.0.9", "[::1]:80": "::1", "192.168.1.7": "192.168.1.7"} {
+		req.RemoteAddr = addr
+		if got := clientIP(req); got != want {
+			t.Errorf("clientIP(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+)
+
+// routeGroup maps a family of API routes to the resource whose permissions
+// guard it. The action is derived from the HTTP method unless permission
+// pins a single permission for the whole group. writeResource, when set,
+// guards the methods that change something instead of resource, for
+// routes everyone may read but few may change.
+type routeGroup struct {
+	prefix        string
+	resource      string
+	permission    string
+	writeResource string
+}
+
+// routeGroups lists the route groups RBAC enforces, registered alongside the
+// routes in SetupRoutes. A prefix matches itself and everything below it.
+// Routes not listed here only require authentication.
+var routeGroups = []routeGroup{
+	{prefix: "/api/v1/auth/users", resource: "users"},
+	{prefix: "/api/v1/users", resource: "users"},
+
+	{prefix: "/api/v1/beads", resource: "beads"},
+	{prefix: "/api/v1/comments", resource: "beads"},
+	{prefix: "/api/v1/work-graph", resource: "beads"},
+	{prefix: "/api/v1/work", resource: "beads"},
+	{prefix: "/api/v1/file-locks", resource: "beads"},
+	{prefix: "/api/v1/sla/report", resource: "beads"},
+	{prefix: "/api/v1/milestones", resource: "beads"},
+	{prefix: "/api/v1/bead-types", resource: "beads", writeResource: "system"},
+
+	{prefix: "/api/v1/decisions", resource: "decisions"},
+
+	{prefix: "/api/v1/agents", resource: "agents"},
+	{prefix: "/api/v1/remote-agents", resource: "agents"},
+	{prefix: "/api/v1/personas", resource: "agents"},
+	{prefix: "/api/v1/org-charts", resource: "agents"},
+	{prefix: "/api/v1/prompts", resource: "agents"},
+	{prefix: "/api/v1/commands/policies/check", permission: "agents:read"},
+	{prefix: "/api/v1/commands/policies", resource: "system"},
+	{prefix: "/api/v1/commands", resource: "agents"},
+	{prefix: "/api/v1/actions/validate", permission: "agents:read"},
+
+	{prefix: "/api/v1/providers", resource: "providers"},
+	{prefix: "/api/v1/models", resource: "providers"},
+	{prefix: "/api/v1/optimizations", resource: "providers"},
+
+	{prefix: "/api/v1/projects", resource: "projects"},
+	{prefix: "/api/v1/containers", resource: "projects"},
+
+	{prefix: "/api/v1/workflows", resource: "workflows"},
+
+	{prefix: "/api/v1/logs/prune", permission: "system:write"},
+	{prefix: "/api/v1/logs", resource: "logs"},
+	{prefix: "/api/v1/events", resource: "logs"},
+	{prefix: "/api/v1/activity-feed", resource: "logs"},
+	{prefix: "/api/v1/analytics", resource: "logs"},
+	{prefix: "/api/v1/reports", resource: "logs"},
+	{prefix: "/api/v1/conversations", resource: "logs"},
+	{prefix: "/api/v1/patterns", resource: "logs"},
+	{prefix: "/api/v1/notifications", resource: "logs"},
+
+	{prefix: "/api/v1/repl", permission: "repl:use"},
+
+	{prefix: "/api/v1/config", resource: "system"},
+	{prefix: "/api/v1/cache", resource: "system"},
+	{prefix: "/api/v1/debug", resource: "system"},
+	{prefix: "/api/v1/export", resource: "system"},
+	{prefix: "/api/v1/import", resource: "system"},
+	{prefix: "/api/v1/connectors", resource: "system"},
+	{prefix: "/api/v1/federation", resource: "system"},
+	{prefix: "/api/v1/openclaw", resource: "system"},
+	{prefix: "/api/v1/system", resource: "system"},
+	{prefix: "/api/v1/budgets", resource: "system"},
+	{prefix: "/api/v1/sla/policies", resource: "system"},
+	{prefix: "/api/v1/webhooks", resource: "system"},
+	{prefix: "/api/v1/secrets", resource: "system"},
+	{prefix: "/api/v1/audit", resource: "system"},
+	{prefix: "/api/v1/admin", resource: "system"},
+}
+
+// requiredPermission returns the permission a request needs, e.g.
+// "providers:delete" for DELETE /api/v1/providers/{id}, or "" when the route
+// is not part of an RBAC route group.
+func requiredPermission(r *http.Request) string {
+	for _, g := range routeGroups {
+		if r.URL.Path != g.prefix && !strings.HasPrefix(r.URL.Path, g.prefix+"/") {
+			continue
+		}
+		if g.permission != "" {
+			return g.permission
+		}
+		action := methodAction(r.Method)
+		if g.writeResource != "" && action != "read" {
+			return g.writeResource + ":" + action
+		}
+		return g.resource + ":" + action
+	}
+	return ""
+}
+
+// requirePermission responds 403 and returns false unless the caller's role
+// grants permission, for handlers whose effect goes beyond what their route
+// group's permission says. Without authentication every caller may.
+func (s *Server) requirePermission(w http.ResponseWriter, r *http.Request, permission string) bool {
+	if !s.config.Security.EnableAuth || s.authManager == nil {
+		return true
+	}
+	if !s.authManager.RoleAllows(auth.GetRoleFromRequest(r), permission) {
+		s.respondError(w, http.StatusForbidden, "Forbidden: "+permission+" permission required")
+		return false
+	}
+	return true
+}
+
+// methodAction maps an HTTP method to a permission action.
+func methodAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "write"
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestRequiredPermission(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/beads", "beads:read"},
+		{http.MethodPost, "/api/v1/beads/bd-1/claim", "beads:write"},
+		{http.MethodGet, "/api/v1/work-graph", "beads:read"},
+		{http.MethodDelete, "/api/v1/providers/tokenhub", "providers:delete"},
+		{http.MethodDelete, "/api/v1/projects/loom", "projects:delete"},
+		{http.MethodGet, "/api/v1/logs/recent", "logs:read"},
+		{http.MethodPost, "/api/v1/logs/prune", "system:write"},
+		{http.MethodPost, "/api/v1/repl", "repl:use"},
+		{http.MethodPatch, "/api/v1/auth/users/id-1", "users:write"},
+		{http.MethodGet, "/api/v1/auth/me", ""},
+		{http.MethodGet, "/api/v1/beadsearch", ""},
+		{http.MethodPost, "/api/v1/commands/policies/check", "agents:read"},
+		{http.MethodDelete, "/api/v1/commands/policies/cmdpol-1", "system:delete"},
+		{http.MethodPost, "/api/v1/commands/policies", "system:write"},
+		{http.MethodPut, "/api/v1/secrets/GITHUB_TOKEN", "system:write"},
+		{http.MethodGet, "/api/v1/audit", "system:read"},
+		{http.MethodPut, "/api/v1/admin/mode", "system:write"},
+		{http.MethodGet, "/api/v1/milestones", "beads:read"},
+		{http.MethodGet, "/api/v1/bead-types", "beads:read"},
+		{http.MethodPost, "/api/v1/bead-types", "system:write"},
+		{http.MethodDelete, "/api/v1/bead-types/incident", "system:delete"},
+		{http.MethodPatch, "/api/v1/milestones/ms-1", "beads:write"},
+		{http.MethodGet, "/api/v1/reports/rpt-1/runs", "logs:read"},
+		{http.MethodPost, "/api/v1/reports", "logs:write"},
+		{http.MethodDelete, "/api/v1/reports/rpt-1", "logs:delete"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := requiredPermission(req); got != tt.want {
+			t.Errorf("requiredPermission(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAuthMiddleware_RBAC(t *testing.T) {
+	am := auth.NewManager("test-secret")
+	s := &Server{
+		config:         &config.Config{Security: config.SecurityConfig{EnableAuth: true}},
+		authManager:    am,
+		apiFailureLast: make(map[string]time.Time),
+	}
+	handler := s.authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tokenFor := func(role string) string {
+		user, err := am.CreateUser("u-"+role, "", role, "password")
+		if err != nil {
+			t.Fatalf("CreateUser(%s) failed: %v", role, err)
+		}
+		token, err := am.GenerateToken(user)
+		if err != nil {
+			t.Fatalf("GenerateToken(%s) failed: %v", role, err)
+		}
+		return token
+	}
+	viewer := tokenFor("viewer")
+	operator := tokenFor("operator")
+	admin := tokenFor("admin")
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		want   int
+	}{
+		{"viewer reads beads", viewer, http.MethodGet, "/api/v1/beads", http.StatusOK},
+		{"viewer reads logs", viewer, http.MethodGet, "/api/v1/logs/recent", http.StatusOK},
+		{"viewer cannot create beads", viewer, http.MethodPost, "/api/v1/beads", http.StatusForbidden},
+		{"viewer cannot delete providers", viewer, http.MethodDelete, "/api/v1/providers/p1", http.StatusForbidden},
+		{"operator updates beads", operator, http.MethodPatch, "/api/v1/beads/bd-1", http.StatusOK},
+		{"operator cannot delete projects", operator, http.MethodDelete, "/api/v1/projects/loom", http.StatusForbidden},
+		{"operator cannot assign roles", operator, http.MethodPatch, "/api/v1/auth/users/id-1", http.StatusForbidden},
+		{"admin deletes providers", admin, http.MethodDelete, "/api/v1/providers/p1", http.StatusOK},
+		{"unauthenticated", "", http.MethodGet, "/api/v1/beads", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
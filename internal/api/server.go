@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,6 +24,7 @@ import (
 	"github.com/jordanhubbard/loom/internal/logging"
 	"github.com/jordanhubbard/loom/internal/loom"
 	"github.com/jordanhubbard/loom/internal/metrics"
+	"github.com/jordanhubbard/loom/internal/oidc"
 	"github.com/jordanhubbard/loom/pkg/config"
 	"github.com/jordanhubbard/loom/pkg/models"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -73,6 +75,7 @@ var standardDebugPatterns = []debugPattern{
 	{"POST", regexp.MustCompile(`^/api/v1/agents/[^/]+/start$`), "agent_event", "agent started"},
 	{"POST", regexp.MustCompile(`^/api/v1/agents/[^/]+/stop$`), "agent_event", "agent stopped"},
 	{"POST", regexp.MustCompile(`^/api/v1/agents/[^/]+/pause$`), "agent_event", "agent paused"},
+	{"POST", regexp.MustCompile(`^/api/v1/agents/[^/]+/drain$`), "agent_event", "agent draining"},
 	{"POST", regexp.MustCompile(`^/api/v1/agents/[^/]+/resume$`), "agent_event", "agent resumed"},
 	// Project lifecycle
 	{"POST", regexp.MustCompile(`^/api/v1/projects$`), "project_event", "project created"},
@@ -80,6 +83,15 @@ var standardDebugPatterns = []debugPattern{
 	{"DELETE", regexp.MustCompile(`^/api/v1/projects/[^/]+$`), "project_event", "project deleted"},
 	{"PUT", regexp.MustCompile(`^/api/v1/projects/[^/]+$`), "project_event", "project updated"},
 	{"POST", regexp.MustCompile(`^/api/v1/projects/[^/]+/close$`), "project_event", "project closed"},
+	{"POST", regexp.MustCompile(`^/api/v1/projects/[^/]+/archive$`), "project_event", "project archived"},
+	{"POST", regexp.MustCompile(`^/api/v1/projects/[^/]+/unarchive$`), "project_event", "project unarchived"},
+	{"PUT", regexp.MustCompile(`^/api/v1/projects/[^/]+/config$`), "project_event", "project config updated"},
+	{"DELETE", regexp.MustCompile(`^/api/v1/projects/[^/]+/config$`), "project_event", "project config updated"},
+	{"POST", regexp.MustCompile(`^/api/v1/projects/[^/]+/orgchart$`), "project_event", "org chart position added"},
+	{"DELETE", regexp.MustCompile(`^/api/v1/projects/[^/]+/orgchart$`), "project_event", "org chart reset"},
+	{"PUT", regexp.MustCompile(`^/api/v1/projects/[^/]+/orgchart/positions/[^/]+$`), "project_event", "org chart position updated"},
+	{"DELETE", regexp.MustCompile(`^/api/v1/projects/[^/]+/orgchart/positions/[^/]+$`), "project_event", "org chart position removed"},
+	{"POST", regexp.MustCompile(`^/api/v1/projects/[^/]+/agents/scale$`), "project_event", "role scaled"},
 }
 
 // streamingPrefixes are path prefixes whose responses are SSE/chunked streams
@@ -155,6 +167,9 @@ type Server struct {
 	apiFailureMu    sync.Mutex
 	apiFailureLast  map[string]time.Time
 
+	// oidc is the single sign-on provider, nil unless security.oidc is enabled.
+	oidc *oidc.Provider
+
 	// ConnectorService provides location-transparent access to connectors.
 	// Uses gRPC remote service when CONNECTORS_SERVICE_ADDR is set,
 	// otherwise falls back to the in-process manager.
@@ -180,10 +195,11 @@ func NewServer(arb *loom.Loom, km *keymanager.KeyManager, am *auth.Manager, cfg
 		}
 	}
 
-	// Initialize logging manager
+	// Share the app's logging manager so the log API sees (and the level
+	// config endpoint controls) the same entries the app records.
 	var logMgr *logging.Manager
-	if arb != nil && arb.GetDatabase() != nil {
-		logMgr = logging.NewManager(arb.GetDatabase().DB())
+	if arb != nil {
+		logMgr = arb.GetLogManager()
 	}
 
 	// Initialize cache with config
@@ -247,6 +263,36 @@ func NewServer(arb *loom.Loom, km *keymanager.KeyManager, am *auth.Manager, cfg
 		log.Printf("[API] Using local connectors service")
 	}
 
+	// Scope authenticated requests to an organization
+	if am != nil && arb != nil && arb.GetOrgManager() != nil {
+		am.SetOrgResolver(arb.GetOrgManager().Resolve)
+	}
+
+	// Keep users, including those single sign-on provisions, across restarts
+	if am != nil && arb != nil && arb.GetDatabase() != nil {
+		if err := am.SetUserStore(userStore{db: arb.GetDatabase()}); err != nil {
+			log.Printf("[API] Users will not persist: %v", err)
+		}
+	}
+
+	// Keep API tokens across restarts
+	if am != nil && arb != nil && arb.GetDatabase() != nil {
+		if err := am.SetTokenStore(tokenStore{db: arb.GetDatabase()}); err != nil {
+			log.Printf("[API] API tokens will not persist: %v", err)
+		}
+	}
+
+	// Sign users in through the identity provider
+	var oidcProvider *oidc.Provider
+	if cfg != nil && cfg.Security.OIDC.Enabled {
+		p, err := oidc.New(cfg.Security.OIDC, nil)
+		if err != nil {
+			log.Printf("[API] Single sign-on disabled: %v", err)
+		} else {
+			oidcProvider = p
+		}
+	}
+
 	return &Server{
 		app:              arb,
 		keyManager:       km,
@@ -259,6 +305,7 @@ func NewServer(arb *loom.Loom, km *keymanager.KeyManager, am *auth.Manager, cfg
 		connectorService: connSvc,
 		metrics:          promMetrics,
 		apiFailureLast:   make(map[string]time.Time),
+		oidc:             oidcProvider,
 	}
 }
 
@@ -268,13 +315,12 @@ func (s *Server) SetupRoutes() http.Handler {
 
 	// Serve static files
 	if s.config.WebUI.Enabled {
-		fs := http.FileServer(http.Dir(s.config.WebUI.StaticPath))
-		mux.Handle("/static/", http.StripPrefix("/static/", fs))
+		mux.Handle("/static/", http.StripPrefix("/static/", s.pageServer(s.config.WebUI.StaticPath)))
 
 		// Serve index.html at root
 		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == "/" {
-				http.ServeFile(w, r, s.config.WebUI.StaticPath+"/index.html")
+				s.servePage(w, r, s.config.WebUI.StaticPath+"/index.html")
 			} else {
 				http.NotFound(w, r)
 			}
@@ -299,6 +345,9 @@ func (s *Server) SetupRoutes() http.Handler {
 	authHandlers := auth.NewHandlers(s.authManager)
 	mux.HandleFunc("/api/v1/auth/login", authHandlers.HandleLogin)
 	mux.HandleFunc("/api/v1/auth/refresh", authHandlers.HandleRefreshToken)
+	mux.HandleFunc("/api/v1/auth/oidc/config", s.handleOIDCConfig)
+	mux.HandleFunc("/api/v1/auth/oidc/login", s.handleOIDCLogin)
+	mux.HandleFunc("/api/v1/auth/oidc/callback", s.handleOIDCCallback)
 	mux.HandleFunc("/api/v1/auth/change-password", authHandlers.HandleChangePassword)
 	mux.HandleFunc("/api/v1/auth/api-keys", authHandlers.HandleAPIKeys)
 	mux.HandleFunc("/api/v1/auth/api-keys/", authHandlers.HandleAPIKeyByID)
@@ -313,6 +362,16 @@ func (s *Server) SetupRoutes() http.Handler {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	mux.HandleFunc("/api/v1/auth/users/", authHandlers.HandleUserByID)
+	mux.HandleFunc("/api/v1/auth/roles", authHandlers.HandleListRoles)
+
+	// User administration
+	mux.HandleFunc("/api/v1/users", s.handleUsers)
+	mux.HandleFunc("/api/v1/users/", s.handleUser)
+
+	// API tokens with scopes, expiry, and revocation
+	mux.HandleFunc("/api/v1/tokens", s.handleTokens)
+	mux.HandleFunc("/api/v1/tokens/", s.handleToken)
 
 	// Personas
 	mux.HandleFunc("/api/v1/personas", s.handlePersonas)
@@ -332,6 +391,11 @@ func (s *Server) SetupRoutes() http.Handler {
 
 	// Beads
 	mux.HandleFunc("/api/v1/beads", s.handleBeads)
+	mux.HandleFunc("/api/v1/beads/search", s.handleBeadSearch)
+	mux.HandleFunc("/api/v1/beads/bulk", s.handleBeadsBulk)
+	mux.HandleFunc("/api/v1/beads/trash", s.handleBeadTrash)
+	mux.HandleFunc("/api/v1/beads/schedules", s.handleBeadSchedules)
+	mux.HandleFunc("/api/v1/beads/schedules/", s.handleBeadSchedule)
 	mux.HandleFunc("/api/v1/beads/", s.handleBead)
 
 	// Connectors
@@ -365,6 +429,8 @@ func (s *Server) SetupRoutes() http.Handler {
 
 	// Providers
 	mux.HandleFunc("/api/v1/providers", s.handleProviders)
+	mux.HandleFunc("/api/v1/providers/policies", s.handleProviderPolicies)
+	mux.HandleFunc("/api/v1/providers/policies/", s.handleProviderPolicy)
 	mux.HandleFunc("/api/v1/providers/", s.handleProvider)
 
 	// Models
@@ -373,6 +439,12 @@ func (s *Server) SetupRoutes() http.Handler {
 	// System
 	mux.HandleFunc("/api/v1/system/status", s.handleSystemStatus)
 	mux.HandleFunc("/api/v1/system/state", s.handleSystemState)
+	mux.HandleFunc("/api/v1/system/diagnostics", s.handleSystemDiagnostics)
+	mux.HandleFunc("/api/v1/admin/mode", s.handleAdminMode)
+	mux.HandleFunc("/api/v1/admin/migrations", s.handleAdminMigrations)
+	mux.HandleFunc("/api/v1/admin/migrations/", s.handleAdminMigrations)
+	mux.HandleFunc("/api/v1/admin/backups", s.handleAdminBackups)
+	mux.HandleFunc("/api/v1/admin/backups/", s.handleAdminBackups)
 
 	// Work (non-bead prompts)
 	mux.HandleFunc("/api/v1/work", s.handleWork)
@@ -380,8 +452,13 @@ func (s *Server) SetupRoutes() http.Handler {
 	// CEO REPL
 	mux.HandleFunc("/api/v1/repl", s.handleRepl)
 
+	// Action envelope dry runs
+	mux.HandleFunc("/api/v1/actions/validate", s.handleValidateActions)
+
 	// Shell command execution
 	mux.HandleFunc("/api/v1/commands/execute", s.HandleExecuteCommand)
+	mux.HandleFunc("/api/v1/commands/policies", s.handleCommandPolicies)
+	mux.HandleFunc("/api/v1/commands/policies/", s.handleCommandPolicy)
 	mux.HandleFunc("/api/v1/commands", s.HandleGetCommandLogs)
 	mux.HandleFunc("/api/v1/commands/", s.HandleGetCommandLogs)
 
@@ -392,6 +469,7 @@ func (s *Server) SetupRoutes() http.Handler {
 	mux.HandleFunc("/api/v1/logs/recent", s.HandleLogsRecent)
 	mux.HandleFunc("/api/v1/logs/stream", s.HandleLogsStream)
 	mux.HandleFunc("/api/v1/logs/export", s.HandleLogsExport)
+	mux.HandleFunc("/api/v1/logs/prune", s.HandleLogsPrune)
 
 	// Chat completions (with streaming support)
 	mux.HandleFunc("/api/v1/chat/completions/stream", s.handleStreamChatCompletion)
@@ -414,6 +492,31 @@ func (s *Server) SetupRoutes() http.Handler {
 	mux.HandleFunc("/api/v1/analytics/costs", s.handleGetCostReport)
 	mux.HandleFunc("/api/v1/analytics/batching", s.handleGetBatchingRecommendations)
 	mux.HandleFunc("/api/v1/analytics/change-velocity", s.handleGetChangeVelocity)
+	mux.HandleFunc("/api/v1/analytics/agents", s.handleGetAgentScoreboard)
+	mux.HandleFunc("/api/v1/analytics/cycle-time", s.handleGetCycleTime)
+	mux.HandleFunc("/api/v1/analytics/burndown", s.handleGetBurndown)
+	mux.HandleFunc("/api/v1/analytics/forecast", s.handleGetCostForecast)
+
+	// Token and cost budgets
+	mux.HandleFunc("/api/v1/budgets", s.handleBudgets)
+	mux.HandleFunc("/api/v1/budgets/", s.handleBudget)
+
+	// Bead SLA policies and report
+	mux.HandleFunc("/api/v1/sla/policies", s.handleSLAPolicies)
+	mux.HandleFunc("/api/v1/sla/policies/", s.handleSLAPolicy)
+	mux.HandleFunc("/api/v1/sla/report", s.handleSLAReport)
+
+	// Daily and weekly project reports
+	mux.HandleFunc("/api/v1/reports", s.handleReports)
+	mux.HandleFunc("/api/v1/reports/", s.handleReport)
+
+	// Admin-defined bead types
+	mux.HandleFunc("/api/v1/bead-types", s.handleBeadTypes)
+	mux.HandleFunc("/api/v1/bead-types/", s.handleBeadType)
+
+	// Milestones grouping beads across projects
+	mux.HandleFunc("/api/v1/milestones", s.handleMilestones)
+	mux.HandleFunc("/api/v1/milestones/", s.handleMilestone)
 
 	// Debug endpoints
 	mux.HandleFunc("/api/v1/debug/capture-ui", s.handleCaptureUI)
@@ -447,12 +550,16 @@ func (s *Server) SetupRoutes() http.Handler {
 
 	// Configuration
 	mux.HandleFunc("/api/v1/config/debug", s.handleDebugConfig)
+	mux.HandleFunc("/api/v1/config/logging", s.handleLoggingConfig)
+	mux.HandleFunc("/api/v1/config/executor", s.handleExecutorConfig)
 	mux.HandleFunc("/api/v1/config", s.handleConfig)
 	mux.HandleFunc("/api/v1/config/export.yaml", s.handleConfigExportYAML)
 	mux.HandleFunc("/api/v1/config/import.yaml", s.handleConfigImportYAML)
 
 	// Events (real-time updates and event bus)
 	mux.HandleFunc("/api/v1/events/stream", s.handleEventStream)
+	mux.HandleFunc("/api/v1/events/ws", s.handleEventSocket)
+	mux.HandleFunc("/api/v1/events/replay", s.handleEventReplay)
 	mux.HandleFunc("/api/v1/events/stats", s.handleGetEventStats)
 	mux.HandleFunc("/api/v1/events", s.handleGetEvents) // GET for history
 	// POST /api/v1/events for publishing is available but should be restricted
@@ -481,13 +588,31 @@ func (s *Server) SetupRoutes() http.Handler {
 	mux.HandleFunc("/api/v1/workflows/start", s.handleWorkflowStart)
 	mux.HandleFunc("/api/v1/workflows/", s.handleWorkflow)
 	mux.HandleFunc("/api/v1/workflows/executions", s.handleWorkflowExecutions)
+	mux.HandleFunc("/api/v1/workflows/executions/", s.handleWorkflowExecution)
 	mux.HandleFunc("/api/v1/workflows/analytics", s.handleWorkflowAnalytics)
 	mux.HandleFunc("/api/v1/beads/workflow", s.handleBeadWorkflow)
 
 	// Webhooks (external event integration)
+	// Outbound event webhooks (exact inbound routes below take precedence)
+	mux.HandleFunc("/api/v1/webhooks", s.handleWebhooks)
+	mux.HandleFunc("/api/v1/webhooks/", s.handleWebhook)
+
 	mux.HandleFunc("/api/v1/webhooks/github", s.handleGitHubWebhook)
 	mux.HandleFunc("/api/v1/webhooks/openclaw", s.handleOpenClawWebhook)
+	mux.HandleFunc("/api/v1/webhooks/slack", s.handleSlackInteraction)
 	mux.HandleFunc("/api/v1/webhooks/status", s.handleWebhookStatus)
+	mux.HandleFunc("/api/v1/hooks/git", s.handleGitHook)
+
+	// Project secrets injected into agent commands
+	mux.HandleFunc("/api/v1/secrets", s.handleSecrets)
+	mux.HandleFunc("/api/v1/secrets/", s.handleSecret)
+
+	// Audit log of mutating API requests
+	mux.HandleFunc("/api/v1/audit", s.handleAudit)
+
+	// Organizations, teams, and members
+	mux.HandleFunc("/api/v1/orgs", s.handleOrgs)
+	mux.HandleFunc("/api/v1/orgs/", s.handleOrg)
 
 	// OpenClaw messaging gateway
 	mux.HandleFunc("/api/v1/openclaw/status", s.handleOpenClawStatus)
@@ -496,14 +621,33 @@ func (s *Server) SetupRoutes() http.Handler {
 	mux.HandleFunc("/api/v1/export", s.handleExport)
 	mux.HandleFunc("/api/v1/import", s.handleImport)
 
+	// Project containers: limits, health, usage, logs and restarts
+	mux.HandleFunc("/api/v1/containers", s.handleContainers)
+	mux.HandleFunc("/api/v1/containers/", s.handleContainer)
+
+	// Remote agents: enrollment tokens, enrollment and the work connection
+	mux.HandleFunc("/api/v1/remote-agents", s.handleRemoteAgents)
+	mux.HandleFunc("/api/v1/remote-agents/", s.handleRemoteAgent)
+
 	// Project agent registration (called by containers on startup)
 	mux.HandleFunc("/api/v1/project-agents/", s.handleContainerAgents)
 	mux.HandleFunc("/api/v1/project-agents/register", s.handleContainerAgents)
 
-	// Apply middleware
+	// Apply middleware. Per-route-group RBAC (see routeGroups) is enforced
+	// by authMiddleware once the caller is authenticated.
 	handler := s.loggingMiddleware(mux)
 	handler = s.corsMiddleware(handler)
+	handler = s.orgScopeMiddleware(handler)
+	handler = s.tokenProjectMiddleware(handler)
+	handler = s.auditMiddleware(handler)
+	handler = s.maintenanceMiddleware(handler)
+	handler = s.rateLimitMiddleware(handler)
 	handler = s.authMiddleware(handler)
+	handler = s.ipRateLimitMiddleware(handler)
+	// Outermost, so everything above sees the client as the reverse proxy
+	// saw it and paths without server.base_path.
+	handler = s.basePathMiddleware(handler)
+	handler = s.proxyMiddleware(handler)
 
 	return handler
 }
@@ -519,6 +663,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"status":         "ok",
 		"version":        getVersion(),
 		"uptime_seconds": int64(time.Since(startTime).Seconds()),
+		"mode":           s.serverMode().Mode,
 	})
 }
 
@@ -797,22 +942,41 @@ func (s *Server) defaultProjectID() string {
 	return ""
 }
 
-// corsMiddleware handles CORS headers
+// corsMiddleware handles CORS headers. Origins come from server.cors, or
+// security.allowed_origins when it lists none.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	cors := s.config.Server.CORS
+	origins := cors.AllowedOrigins
+	if len(origins) == 0 {
+		origins = s.config.Security.AllowedOrigins
+	}
+	headers := strings.Join(append([]string{"Content-Type", "X-API-Key", "Authorization", auth.OrgRequestHeader}, cors.AllowedHeaders...), ", ")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
-		if len(s.config.Security.AllowedOrigins) > 0 {
-			origin := r.Header.Get("Origin")
-			for _, allowedOrigin := range s.config.Security.AllowedOrigins {
-				if allowedOrigin == "*" || allowedOrigin == origin {
-					w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-					break
-				}
+		origin := r.Header.Get("Origin")
+		for _, allowedOrigin := range origins {
+			if !originAllowed(allowedOrigin, origin) {
+				continue
+			}
+			// Credentials are only shared with listed origins, never
+			// with "*", which the config refuses to combine with them.
+			if allowedOrigin == "*" {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				break
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			if cors.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
 			}
+			break
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", headers)
+		if cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+		}
 
 		// Handle preflight
 		if r.Method == http.MethodOptions {
@@ -824,9 +988,38 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// originAllowed reports whether an allowed_origins entry admits origin.
+// The entry may be "*", an exact origin, or one with a wildcard subdomain
+// such as "https://*.example.com", which doesn't match the bare domain.
+func originAllowed(allowed, origin string) bool {
+	if allowed == "*" {
+		return true
+	}
+	if origin == "" {
+		return false
+	}
+	scheme, host, ok := strings.Cut(allowed, "://*.")
+	if !ok {
+		return strings.EqualFold(allowed, origin)
+	}
+	rest, ok := strings.CutPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://")
+	return ok && strings.HasSuffix(rest, "."+strings.ToLower(host)) && !strings.Contains(strings.TrimSuffix(rest, "."+strings.ToLower(host)), "/")
+}
+
 // authMiddleware handles authentication
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only authentication sets the caller and the organization a
+		// request acts in
+		r.Header.Del("X-User-ID")
+		r.Header.Del("X-Username")
+		r.Header.Del("X-Role")
+		r.Header.Del("X-Org-ID")
+		r.Header.Del("X-Team-ID")
+		r.Header.Del("X-Token-ID")
+		r.Header.Del("X-Token-Scope")
+		r.Header.Del("X-Token-Projects")
+
 		// Skip auth for health check endpoints (all variants for monitoring/probes)
 		if r.URL.Path == "/api/v1/health" ||
 			r.URL.Path == "/health" ||
@@ -834,6 +1027,7 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			r.URL.Path == "/health/ready" ||
 			r.URL.Path == "/api/v1/auth/login" ||
 			r.URL.Path == "/api/v1/auth/refresh" ||
+			strings.HasPrefix(r.URL.Path, "/api/v1/auth/oidc/") ||
 			r.URL.Path == "/api/v1/config/debug" ||
 			r.URL.Path == "/" ||
 			r.URL.Path == "/api/openapi.yaml" ||
@@ -842,24 +1036,50 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			r.URL.Path == "/api/v1/chat/completions" ||
 			r.URL.Path == "/api/v1/pair" ||
 			r.URL.Path == "/api/v1/webhooks/openclaw" ||
+			r.URL.Path == "/api/v1/webhooks/slack" ||
+			r.URL.Path == "/api/v1/hooks/git" ||
 			strings.HasPrefix(r.URL.Path, "/api/v1/project-agents/") ||
+			r.URL.Path == "/api/v1/remote-agents/enroll" ||
+			r.URL.Path == "/api/v1/remote-agents/connect" ||
 			strings.HasPrefix(r.URL.Path, "/static/") ||
 			strings.HasPrefix(r.URL.Path, "/api/v1/motivations/") {
 			next.ServeHTTP(w, r)
 			return
 		}
 
+		// Browsers can't set headers on a WebSocket handshake, so the event
+		// socket also takes its token as ?token=. It's dropped from the URL
+		// so it isn't logged.
+		if r.URL.Path == "/api/v1/events/ws" {
+			q := r.URL.Query()
+			if token := q.Get("token"); token != "" {
+				if r.Header.Get("Authorization") == "" {
+					r.Header.Set("Authorization", "Bearer "+token)
+				}
+				q.Del("token")
+				r.URL.RawQuery = q.Encode()
+			}
+		}
+
 		// Skip auth if disabled — treat all requests as admin
 		if !s.config.Security.EnableAuth || s.authManager == nil {
 			r.Header.Set("X-User-ID", "admin")
 			r.Header.Set("X-Username", "admin")
 			r.Header.Set("X-Role", "admin")
+			if s.app != nil && s.app.GetOrgManager() != nil {
+				orgID, _, err := s.app.GetOrgManager().Resolve("admin", "admin", r.Header.Get(auth.OrgRequestHeader))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusForbidden)
+					return
+				}
+				r.Header.Set("X-Org-ID", orgID)
+			}
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		// Apply JWT/API key auth
-		s.authManager.Middleware("")(next).ServeHTTP(w, r)
+		// Apply JWT/API key auth and the route group's role permissions
+		s.authManager.RouteMiddleware(requiredPermission)(next).ServeHTTP(w, r)
 	})
 }
 
@@ -2,16 +2,24 @@ package api
 
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/jordanhubbard/loom/internal/workflow"
 )
 
-// handleWorkflows handles GET /api/v1/workflows - list all workflows
+// handleWorkflows handles GET /api/v1/workflows - list all workflows, and
+// POST /api/v1/workflows - create a workflow from a definition
 func (s *Server) handleWorkflows(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		s.saveWorkflow(w, r, "")
+		return
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -43,22 +51,47 @@ func (s *Server) handleWorkflows(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleWorkflow handles GET /api/v1/workflows/{id} - get workflow details
+// handleWorkflow handles GET /api/v1/workflows/{id} - get workflow details,
+// PUT - replace the workflow's definition, and DELETE - remove it,
+// GET /api/v1/workflows/{id}/versions[/{version}] - its saved versions, and
+// GET /api/v1/workflows/{id}/graph - its rendered graph
 func (s *Server) handleWorkflow(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Extract workflow ID from path
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/workflows/")
-	workflowID := strings.Split(path, "/")[0]
+	parts := strings.Split(path, "/")
+	workflowID := parts[0]
 
 	if workflowID == "" {
 		http.Error(w, "Workflow ID required", http.StatusBadRequest)
 		return
 	}
 
+	if len(parts) > 1 && parts[1] == "versions" {
+		version := ""
+		if len(parts) > 2 {
+			version = parts[2]
+		}
+		s.handleWorkflowVersions(w, r, workflowID, version)
+		return
+	}
+	if len(parts) > 1 && parts[1] == "graph" {
+		s.handleWorkflowGraph(w, r, workflowID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		s.saveWorkflow(w, r, workflowID)
+		return
+	case http.MethodDelete:
+		s.deleteWorkflow(w, workflowID)
+		return
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
 	// Get workflow engine
 	engine := s.app.GetWorkflowEngine()
 	if engine == nil {
@@ -87,6 +120,244 @@ func (s *Server) handleWorkflow(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// maxWorkflowDefinitionSize caps the body of a workflow save.
+const maxWorkflowDefinitionSize = 1 << 20
+
+// saveWorkflow creates a workflow (POST, id empty) or replaces the one with
+// the given id (PUT). The body is a workflow definition in YAML or JSON, in
+// the same shape as the files under workflows/defaults. Every save is a new
+// version. With ?dry_run=true the definition is only validated.
+func (s *Server) saveWorkflow(w http.ResponseWriter, r *http.Request, id string) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWorkflowDefinitionSize))
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	def, err := workflow.ParseWorkflowDefinition(body)
+	if err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id != "" {
+		if def.ID != "" && def.ID != id {
+			http.Error(w, "id in body does not match path", http.StatusBadRequest)
+			return
+		}
+		def.ID = id
+	}
+	wf, err := workflow.NewWorkflowFromDefinition(def)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	db := s.app.GetDatabase()
+	if db == nil {
+		http.Error(w, "Database not available", http.StatusServiceUnavailable)
+		return
+	}
+	if wf.ProjectID != "" {
+		if _, err := s.app.GetProjectManager().GetProject(wf.ProjectID); err != nil {
+			http.Error(w, "Project not found: "+wf.ProjectID, http.StatusBadRequest)
+			return
+		}
+	}
+
+	status := http.StatusOK
+	existing, err := db.GetWorkflow(wf.ID)
+	switch {
+	case err == nil && id == "":
+		http.Error(w, "Workflow already exists: "+wf.ID, http.StatusConflict)
+		return
+	case err == nil:
+		wf.CreatedAt = existing.CreatedAt
+		wf.Version = existing.Version
+	case strings.Contains(err.Error(), "not found"):
+		status = http.StatusCreated
+	default:
+		http.Error(w, "Failed to get workflow: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if dryRun {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid":    true,
+			"created":  status == http.StatusCreated,
+			"workflow": wf,
+		}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := db.ReplaceWorkflow(wf, requestActor(r)); err != nil {
+		http.Error(w, "Failed to save workflow: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(wf); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// deleteWorkflow removes a workflow along with its executions.
+func (s *Server) deleteWorkflow(w http.ResponseWriter, id string) {
+	db := s.app.GetDatabase()
+	if db == nil {
+		http.Error(w, "Database not available", http.StatusServiceUnavailable)
+		return
+	}
+	if err := db.DeleteWorkflow(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Workflow not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to delete workflow: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleWorkflowVersions handles GET /api/v1/workflows/{id}/versions - the
+// saved versions of a workflow, newest first - and
+// GET /api/v1/workflows/{id}/versions/{version} - one of them.
+func (s *Server) handleWorkflowVersions(w http.ResponseWriter, r *http.Request, id, version string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	n := 0
+	if version != "" {
+		var err error
+		if n, err = strconv.Atoi(version); err != nil || n < 1 {
+			http.Error(w, "version must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	db := s.app.GetDatabase()
+	if db == nil {
+		http.Error(w, "Database not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var result interface{}
+	if n > 0 {
+		v, err := db.GetWorkflowVersion(id, n)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				http.Error(w, "Workflow version not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Failed to get workflow version: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		result = v
+	} else {
+		if _, err := db.GetWorkflow(id); err != nil {
+			http.Error(w, "Workflow not found", http.StatusNotFound)
+			return
+		}
+		versions, err := db.ListWorkflowVersions(id)
+		if err != nil {
+			http.Error(w, "Failed to list workflow versions: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = map[string]interface{}{
+			"versions": versions,
+			"count":    len(versions),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// handleWorkflowGraph handles GET /api/v1/workflows/{id}/graph - the
+// workflow's nodes and edges as Graphviz DOT or a Mermaid flowchart
+// (?format=dot|mermaid, default mermaid). With ?bead_id= the node that
+// bead's execution stands at is highlighted.
+func (s *Server) handleWorkflowGraph(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = workflow.GraphFormatMermaid
+	}
+	if format != workflow.GraphFormatDOT && format != workflow.GraphFormatMermaid {
+		http.Error(w, "format must be dot or mermaid", http.StatusBadRequest)
+		return
+	}
+
+	engine := s.app.GetWorkflowEngine()
+	if engine == nil {
+		http.Error(w, "Workflow engine not available", http.StatusServiceUnavailable)
+		return
+	}
+	wf, err := engine.GetDatabase().GetWorkflow(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Workflow not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get workflow: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var pos *workflow.GraphPosition
+	if beadID := r.URL.Query().Get("bead_id"); beadID != "" {
+		execution, err := engine.GetDatabase().GetWorkflowExecutionByBeadID(beadID)
+		if err != nil {
+			http.Error(w, "Failed to get execution: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if execution == nil {
+			http.Error(w, "No workflow execution found for bead "+beadID, http.StatusNotFound)
+			return
+		}
+		if execution.WorkflowID != wf.ID {
+			http.Error(w, "Bead "+beadID+" is running workflow "+execution.WorkflowID, http.StatusConflict)
+			return
+		}
+		pos = &workflow.GraphPosition{NodeKey: execution.CurrentNodeKey, Status: execution.Status}
+		if execution.Status == workflow.ExecutionStatusBlocked {
+			branches, _ := engine.JoinBranches(execution)
+			for _, b := range branches {
+				if b.Status == workflow.BranchStatusRunning {
+					pos.Branches = append(pos.Branches, b.NodeKey)
+				}
+			}
+		}
+	}
+
+	graph, err := workflow.RenderGraph(wf, format, pos)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format == workflow.GraphFormatDOT {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	_, _ = io.WriteString(w, graph)
+}
+
 // handleWorkflowExecutions handles GET /api/v1/workflows/executions - list workflow executions
 func (s *Server) handleWorkflowExecutions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -148,6 +419,111 @@ func (s *Server) handleWorkflowExecutions(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// ExecutionControlRequest is the body of a pause, resume, skip, or rollback
+// of a workflow execution. Condition applies to skip, NodeKey to rollback.
+type ExecutionControlRequest struct {
+	Reason    string `json:"reason"`
+	Condition string `json:"condition"`
+	NodeKey   string `json:"node_key"`
+}
+
+// handleWorkflowExecution handles GET /api/v1/workflows/executions/{id} - an
+// execution with its history and parallel branches, and POST /api/v1/workflows/executions/{id}/{action}
+// where action is pause, resume, skip, or rollback
+func (s *Server) handleWorkflowExecution(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/workflows/executions/")
+	parts := strings.Split(path, "/")
+	executionID := parts[0]
+	if executionID == "" {
+		http.Error(w, "Execution ID required", http.StatusBadRequest)
+		return
+	}
+
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	case "pause", "resume", "skip", "rollback":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+	default:
+		http.Error(w, "Unknown execution action: "+action, http.StatusNotFound)
+		return
+	}
+
+	var req ExecutionControlRequest
+	if action != "" && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if action == "rollback" && req.NodeKey == "" {
+		http.Error(w, "node_key is required", http.StatusBadRequest)
+		return
+	}
+
+	engine := s.app.GetWorkflowEngine()
+	if engine == nil {
+		http.Error(w, "Workflow engine not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var execution *workflow.WorkflowExecution
+	var err error
+	actor := requestActor(r)
+	switch action {
+	case "":
+		execution, err = engine.GetDatabase().GetWorkflowExecution(executionID)
+	case "pause":
+		execution, err = engine.PauseExecution(executionID, actor, req.Reason)
+	case "resume":
+		execution, err = engine.ResumeExecution(executionID, actor)
+	case "skip":
+		execution, err = engine.SkipNode(executionID, workflow.EdgeCondition(req.Condition), actor, req.Reason)
+	case "rollback":
+		execution, err = engine.RollbackExecution(executionID, req.NodeKey, actor, req.Reason)
+	}
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			http.Error(w, "Execution not found", http.StatusNotFound)
+		case strings.HasPrefix(err.Error(), "failed to"):
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			// The execution is not in a state that allows the action.
+			http.Error(w, err.Error(), http.StatusConflict)
+		}
+		return
+	}
+
+	history, err := engine.GetDatabase().ListWorkflowHistory(execution.ID)
+	if err != nil {
+		history = nil // Continue without history
+	}
+	branches, err := engine.GetDatabase().ListWorkflowBranches(execution.ID)
+	if err != nil {
+		branches = nil // Continue without branches
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"execution": execution,
+		"history":   history,
+		"branches":  branches,
+	}); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 // handleBeadWorkflow handles GET /api/v1/beads/workflow?bead_id={id} - get workflow for a bead
 func (s *Server) handleBeadWorkflow(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -198,6 +574,12 @@ func (s *Server) handleBeadWorkflow(w http.ResponseWriter, r *http.Request) {
 		history = nil // Continue without history
 	}
 
+	// Get the child beads of parallel branches, if any
+	branches, err := engine.GetDatabase().ListWorkflowBranches(execution.ID)
+	if err != nil {
+		branches = nil // Continue without branches
+	}
+
 	// Get current node if any
 	var currentNode *workflow.WorkflowNode
 	if execution.CurrentNodeKey != "" {
@@ -214,6 +596,7 @@ func (s *Server) handleBeadWorkflow(w http.ResponseWriter, r *http.Request) {
 		"execution":    execution,
 		"current_node": currentNode,
 		"history":      history,
+		"branches":     branches,
 	}); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
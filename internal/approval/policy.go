@@ -0,0 +1,452 @@
+// Package approval decides which code fix proposals are approved without
+// waiting for a person. A proposal's patch is rated low, medium or high
+// risk from the paths it touches and its size, and the proposal is approved
+// by the first of the admin's rules it satisfies. The decision names that
+// rule, so every auto-approval can be traced back to the rule allowing it.
+package approval
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// Risk levels, lowest first. None, as a project's threshold, approves
+// nothing.
+const (
+	RiskNone   = "none"
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+var riskRank = map[string]int{RiskNone: 0, RiskLow: 1, RiskMedium: 2, RiskHigh: 3}
+
+// DefaultHighRiskPaths are the paths a high-risk fix touches when the
+// policy doesn't say.
+var DefaultHighRiskPaths = []string{
+	"*migrations/*", "*.sql",
+	"*auth/*", "*secret*", "*credential*", "*.pem", "*.key", ".env*",
+	".github/workflows/*", "Dockerfile*", "docker-compose*",
+}
+
+// Defaults bounding a low-risk patch.
+const (
+	defaultLowRiskMaxFiles = 1
+	defaultLowRiskMaxLines = 50
+)
+
+// DefaultRule approves low-risk proposals when the policy has no rules.
+var DefaultRule = config.AutoApprovalRule{Name: "default", MaxRisk: RiskLow}
+
+// Proposal is a code fix proposal as the policy sees it.
+type Proposal struct {
+	ProjectID   string
+	Description string
+	// Files and DiffLines describe the patch in the description; Files is
+	// empty when there is none.
+	Files     []string
+	DiffLines int
+	// TestsPassing reports whether the proposing agent's last test run
+	// passed.
+	TestsPassing bool
+}
+
+// NewProposal returns the proposal described by description, with its
+// patch parsed.
+func NewProposal(projectID, description string, testsPassing bool) *Proposal {
+	files, lines := ParsePatch(description)
+	return &Proposal{
+		ProjectID:    projectID,
+		Description:  description,
+		Files:        files,
+		DiffLines:    lines,
+		TestsPassing: testsPassing,
+	}
+}
+
+// Decision is the policy's verdict on a proposal.
+type Decision struct {
+	Risk        string   `json:"risk"`
+	RiskReasons []string `json:"risk_reasons"`
+	Approved    bool     `json:"approved"`
+	// Rule names the rule that approved the proposal.
+	Rule string `json:"rule,omitempty"`
+	// Rejections say why each rule that applies to the project didn't.
+	Rejections []string `json:"rejections,omitempty"`
+}
+
+// Policy evaluates proposals against an auto-approval config.
+type Policy struct {
+	highRisk        []glob
+	lowRiskMaxFiles int
+	lowRiskMaxLines int
+	rules           []config.AutoApprovalRule
+}
+
+// New returns the policy cfg describes.
+func New(cfg config.AutoApprovalConfig) *Policy {
+	p := &Policy{
+		lowRiskMaxFiles: cfg.LowRiskMaxFiles,
+		lowRiskMaxLines: cfg.LowRiskMaxLines,
+		rules:           cfg.Rules,
+	}
+	if p.lowRiskMaxFiles <= 0 {
+		p.lowRiskMaxFiles = defaultLowRiskMaxFiles
+	}
+	if p.lowRiskMaxLines <= 0 {
+		p.lowRiskMaxLines = defaultLowRiskMaxLines
+	}
+	if len(p.rules) == 0 {
+		p.rules = []config.AutoApprovalRule{DefaultRule}
+	}
+	highRisk := cfg.HighRiskPaths
+	if len(highRisk) == 0 {
+		highRisk = DefaultHighRiskPaths
+	}
+	p.highRisk = compileGlobs(highRisk)
+	return p
+}
+
+// Validate checks the rules of cfg.
+func Validate(cfg config.AutoApprovalConfig) error {
+	names := make(map[string]bool)
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("auto-approval rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate auto-approval rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if _, ok := riskRank[strings.ToLower(rule.MaxRisk)]; rule.MaxRisk != "" && !ok {
+			return fmt.Errorf("auto-approval rule %q: max_risk must be none, low, medium or high", rule.Name)
+		}
+	}
+	return nil
+}
+
+// Assess rates a proposal's risk. A patch touching a high-risk path is high
+// risk; one larger than a low-risk patch, or a proposal without a patch,
+// is medium risk. The proposer's own assessment in a "Risk Level:" line
+// can raise the rating but not lower it.
+func (p *Policy) Assess(prop *Proposal) (string, []string) {
+	risk := RiskLow
+	var reasons []string
+	raise := func(level, reason string) {
+		if riskRank[level] > riskRank[risk] {
+			risk = level
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if len(prop.Files) == 0 {
+		raise(RiskMedium, "no patch found")
+	}
+	for _, file := range prop.Files {
+		if g := matchGlobs(p.highRisk, file); g != "" {
+			raise(RiskHigh, fmt.Sprintf("touches high-risk path %s (%s)", file, g))
+		}
+	}
+	if len(prop.Files) > p.lowRiskMaxFiles {
+		raise(RiskMedium, fmt.Sprintf("changes %d files", len(prop.Files)))
+	}
+	if prop.DiffLines > p.lowRiskMaxLines {
+		raise(RiskMedium, fmt.Sprintf("changes %d lines", prop.DiffLines))
+	}
+	if self := selfAssessedRisk(prop.Description); riskRank[self] > riskRank[RiskLow] {
+		raise(self, "proposal self-assessed as "+self+" risk")
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, fmt.Sprintf("changes %d lines in %s", prop.DiffLines, strings.Join(prop.Files, ", ")))
+	}
+	return risk, reasons
+}
+
+// Evaluate decides whether a proposal is approved. maxRisk is the
+// project's threshold, capping the risk any rule approves; "" leaves the
+// rules' own.
+func (p *Policy) Evaluate(prop *Proposal, maxRisk string) *Decision {
+	d := &Decision{}
+	d.Risk, d.RiskReasons = p.Assess(prop)
+	for _, rule := range p.rules {
+		if !appliesTo(rule, prop.ProjectID) {
+			continue
+		}
+		if reason := p.check(rule, prop, d.Risk, maxRisk); reason != "" {
+			d.Rejections = append(d.Rejections, rule.Name+": "+reason)
+			continue
+		}
+		d.Approved = true
+		d.Rule = rule.Name
+		break
+	}
+	return d
+}
+
+// check returns why rule doesn't approve prop, or "" if it does.
+func (p *Policy) check(rule config.AutoApprovalRule, prop *Proposal, risk, maxRisk string) string {
+	limit := strings.ToLower(rule.MaxRisk)
+	if limit == "" {
+		limit = RiskLow
+	}
+	if projectLimit := strings.ToLower(maxRisk); projectLimit != "" && riskRank[projectLimit] < riskRank[limit] {
+		limit = projectLimit
+	}
+	if riskRank[risk] > riskRank[limit] {
+		return fmt.Sprintf("%s risk exceeds %s", risk, limit)
+	}
+
+	constrained := rule.MaxDiffLines > 0 || rule.MaxFiles > 0 || len(rule.AllowedPaths) > 0
+	if constrained && len(prop.Files) == 0 {
+		return "no patch found"
+	}
+	if rule.MaxDiffLines > 0 && prop.DiffLines > rule.MaxDiffLines {
+		return fmt.Sprintf("%d changed lines exceed %d", prop.DiffLines, rule.MaxDiffLines)
+	}
+	if rule.MaxFiles > 0 && len(prop.Files) > rule.MaxFiles {
+		return fmt.Sprintf("%d changed files exceed %d", len(prop.Files), rule.MaxFiles)
+	}
+	forbidden := compileGlobs(rule.ForbiddenPaths)
+	allowed := compileGlobs(rule.AllowedPaths)
+	for _, file := range prop.Files {
+		if g := matchGlobs(forbidden, file); g != "" {
+			return fmt.Sprintf("%s is forbidden by %s", file, g)
+		}
+		if len(allowed) > 0 && matchGlobs(allowed, file) == "" {
+			return fmt.Sprintf("%s is outside the allowed paths", file)
+		}
+	}
+	if rule.RequireTestsPassing && !prop.TestsPassing {
+		return "tests have not passed"
+	}
+	return ""
+}
+
+func appliesTo(rule config.AutoApprovalRule, projectID string) bool {
+	if len(rule.Projects) == 0 {
+		return true
+	}
+	for _, id := range rule.Projects {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// selfAssessedRisk returns the risk level the proposal states for itself,
+// or "".
+func selfAssessedRisk(description string) string {
+	for _, line := range strings.Split(description, "\n") {
+		cleaned := strings.ToLower(strings.Trim(strings.TrimSpace(line), "*#_-` "))
+		for _, prefix := range []string{"risk level:", "risk:"} {
+			rest, ok := strings.CutPrefix(cleaned, prefix)
+			if !ok {
+				continue
+			}
+			rest = strings.Trim(rest, "*_` ")
+			for _, level := range []string{RiskHigh, RiskMedium, RiskLow} {
+				if strings.HasPrefix(rest, level) {
+					return level
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ParsePatch returns the files a unified diff in the description changes,
+// deleted files included, and how many lines it adds or removes.
+func ParsePatch(description string) ([]string, int) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if i := strings.IndexByte(name, '\t'); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" || name == "/dev/null" {
+			return
+		}
+		if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+			name = name[2:]
+		}
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+
+	lines := 0
+	inHunk := false
+	oldName := ""
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+			if fields := strings.Fields(line); len(fields) == 4 {
+				add(fields[3])
+			}
+		case strings.HasPrefix(line, "--- "):
+			inHunk = false
+			oldName = line[4:]
+		case strings.HasPrefix(line, "+++ "):
+			inHunk = false
+			newName := line[4:]
+			if i := strings.IndexByte(newName, '\t'); i >= 0 {
+				newName = newName[:i]
+			}
+			// A deleted file is only named on the old side.
+			if strings.TrimSpace(newName) == "/dev/null" {
+				add(oldName)
+			} else {
+				add(newName)
+			}
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case line == "```":
+			inHunk = false
+		case inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")):
+			lines++
+		}
+	}
+	return files, lines
+}
+
+// ExtractPatch returns the unified diff in a proposal's description: the
+// first fenced block holding one, or else the diff lines written straight
+// into the text. It returns "" when the description has no patch.
+func ExtractPatch(description string) string {
+	var block, outside []string
+	inFence := false
+	for _, line := range strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "```") {
+			if inFence && isPatch(block) {
+				return joinPatch(block)
+			}
+			inFence, block = !inFence, nil
+			continue
+		}
+		if inFence {
+			block = append(block, line)
+		} else {
+			outside = append(outside, line)
+		}
+	}
+	return joinPatch(unfencedPatch(outside))
+}
+
+// isPatch reports whether lines hold a file header and a hunk.
+func isPatch(lines []string) bool {
+	header, hunk := false, false
+	for _, line := range lines {
+		header = header || strings.HasPrefix(line, "+++ ")
+		hunk = hunk || strings.HasPrefix(line, "@@")
+	}
+	return header && hunk
+}
+
+// diffLinePrefixes start the lines a unified diff is made of.
+var diffLinePrefixes = []string{
+	"diff ", "index ", "--- ", "+++ ", "@@", " ", "+", "-", "\\",
+	"new file", "deleted file", "old mode", "new mode", "similarity", "rename ",
+}
+
+// unfencedPatch returns the run of diff lines starting at the first file
+// header in lines.
+func unfencedPatch(lines []string) []string {
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") ||
+			(strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	end := start
+	for end < len(lines) && isDiffLine(lines[end]) {
+		end++
+	}
+	if !isPatch(lines[start:end]) {
+		return nil
+	}
+	return lines[start:end]
+}
+
+func isDiffLine(line string) bool {
+	for _, prefix := range diffLinePrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPatch joins patch lines, dropping trailing blank ones, and ends the
+// patch with a newline as git apply expects.
+func joinPatch(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// glob is a compiled path glob.
+type glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// compileGlob translates a path glob into an anchored regular expression:
+// * matches any run of characters, including /, and ? any one character.
+// A glob without a / is also tried against the path's base name.
+func compileGlob(pattern string) glob {
+	var sb strings.Builder
+	sb.WriteString(`^`)
+	if !strings.Contains(pattern, "/") {
+		sb.WriteString(`(?:.*/)?`)
+	}
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			sb.WriteString(`.*`)
+		case '?':
+			sb.WriteString(`.`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString(`$`)
+	return glob{pattern: pattern, re: regexp.MustCompile(sb.String())}
+}
+
+func compileGlobs(patterns []string) []glob {
+	globs := make([]glob, len(patterns))
+	for i, pattern := range patterns {
+		globs[i] = compileGlob(pattern)
+	}
+	return globs
+}
+
+// matchGlobs returns the first of the globs matching file, or "".
+func matchGlobs(globs []glob, file string) string {
+	file = path.Clean(file)
+	for _, g := range globs {
+		if g.re.MatchString(file) {
+			return g.pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package approval
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// proposal returns a description with a patch changing lines lines in
+// each of files.
+func proposal(risk string, lines int, files ...string) string {
+	var sb strings.Builder
+	sb.WriteString("## Code Fix Proposal\n\n**Original Bug:** bd-1\n\n### Changes Required\n\n```diff\n")
+	for _, f := range files {
+		sb.WriteString("diff --git a/" + f + " b/" + f + "\n--- a/" + f + "\n+++ b/" + f + "\n@@ -1,3 +1,3 @@\n context\n")
+		for i := 0; i < lines; i++ {
+			sb.WriteString("-old\n")
+		}
+	}
+	sb.WriteString("```\n")
+	if risk != "" {
+		sb.WriteString("\n### Risk Assessment\n\n**Risk Level:** " + risk + "\n")
+	}
+	return sb.String()
+}
+
+func TestParsePatch(t *testing.T) {
+	files, lines := ParsePatch(proposal("", 3, "web/static/js/app.js", "internal/api/server.go"))
+	if !reflect.DeepEqual(files, []string{"web/static/js/app.js", "internal/api/server.go"}) || lines != 6 {
+		t.Errorf("ParsePatch() = %v, %d", files, lines)
+	}
+
+	// A patch without git headers, and a new file.
+	files, lines = ParsePatch("--- /dev/null\n+++ b/docs/new.md\t2026-01-01\n@@ -0,0 +1,2 @@\n+# New\n+text\n")
+	if !reflect.DeepEqual(files, []string{"docs/new.md"}) || lines != 2 {
+		t.Errorf("ParsePatch() = %v, %d", files, lines)
+	}
+
+	// A deleted file, alone and alongside an edit.
+	deletion := "--- a/internal/auth/keys.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-package auth\n-var keys = 1\n"
+	files, lines = ParsePatch(deletion)
+	if !reflect.DeepEqual(files, []string{"internal/auth/keys.go"}) || lines != 2 {
+		t.Errorf("ParsePatch() of a deletion = %v, %d", files, lines)
+	}
+	files, _ = ParsePatch("--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-teh\n+the\n" + deletion)
+	if !reflect.DeepEqual(files, []string{"README.md", "internal/auth/keys.go"}) {
+		t.Errorf("ParsePatch() of an edit and a deletion = %v", files)
+	}
+
+	// Only one side prefix is stripped.
+	if files, _ := ParsePatch("--- a/b/c.go\n+++ b/b/c.go\n@@ -1 +1 @@\n-x\n+y\n"); !reflect.DeepEqual(files, []string{"b/c.go"}) {
+		t.Errorf("ParsePatch() = %v, want [b/c.go]", files)
+	}
+
+	if files, _ := ParsePatch("Fix the typo in the README."); len(files) != 0 {
+		t.Errorf("ParsePatch() without a patch = %v", files)
+	}
+}
+
+func TestExtractPatch(t *testing.T) {
+	const diff = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n context\n-old\n+new\n"
+	tests := []struct {
+		name string
+		desc string
+		want string
+	}{
+		{"fenced", "## Fix\n\n```diff\n" + diff + "```\n\nRisk Level: Low\n", diff},
+		{"after another block", "```go\nfunc a() {}\n```\n\n```\n" + diff + "\n```\n", diff},
+		{"unfenced", "### Changes\n\n" + diff + "\nThis fixes the bug.\n", diff},
+		{"rule is not a header", "Fix\n---\nnothing to apply\n", ""},
+		{"no patch", "Fix the typo in the README.", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractPatch(tt.desc); got != tt.want {
+				t.Errorf("ExtractPatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Assess(t *testing.T) {
+	p := New(config.AutoApprovalConfig{})
+	tests := []struct {
+		name string
+		desc string
+		want string
+	}{
+		{"small single file", proposal("", 2, "web/static/js/app.js"), RiskLow},
+		{"no patch", "Fix the typo in the README.", RiskMedium},
+		{"several files", proposal("", 2, "a.go", "b.go"), RiskMedium},
+		{"large diff", proposal("", 80, "a.go"), RiskMedium},
+		{"migration", proposal("", 2, "internal/database/migrations/042.sql"), RiskHigh},
+		{"auth code", proposal("", 2, "internal/auth/manager.go"), RiskHigh},
+		{"env file", proposal("", 1, "deploy/.env.production"), RiskHigh},
+		{"self-assessed high", proposal("High", 1, "README.md"), RiskHigh},
+		{"self-assessed low doesn't lower", proposal("Low", 1, "internal/auth/jwt.go"), RiskHigh},
+		// The keyword heuristics called these high risk; the paths don't.
+		{"keyword in text", "Fix token refresh display\n" + proposal("", 1, "web/static/js/tokens.js"), RiskLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prop := NewProposal("loom", tt.desc, false)
+			if risk, reasons := p.Assess(prop); risk != tt.want {
+				t.Errorf("Assess() = %s (%v), want %s", risk, reasons, tt.want)
+			}
+		})
+	}
+
+	custom := New(config.AutoApprovalConfig{HighRiskPaths: []string{"billing/*"}, LowRiskMaxFiles: 3})
+	if risk, _ := custom.Assess(NewProposal("loom", proposal("", 1, "internal/auth/a.go", "b.go"), false)); risk != RiskLow {
+		t.Errorf("custom policy: risk = %s, want low", risk)
+	}
+	if risk, _ := custom.Assess(NewProposal("loom", proposal("", 1, "billing/invoice.go"), false)); risk != RiskHigh {
+		t.Errorf("custom policy: risk = %s, want high", risk)
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	p := New(config.AutoApprovalConfig{
+		LowRiskMaxFiles: 5,
+		LowRiskMaxLines: 200,
+		Rules: []config.AutoApprovalRule{
+			{
+				Name:                "web",
+				Projects:            []string{"loom"},
+				MaxRisk:             RiskMedium,
+				MaxDiffLines:        20,
+				AllowedPaths:        []string{"web/*"},
+				ForbiddenPaths:      []string{"*.min.js"},
+				RequireTestsPassing: true,
+			},
+			{Name: "tiny", MaxRisk: RiskLow, MaxFiles: 1, MaxDiffLines: 5},
+		},
+	})
+
+	tests := []struct {
+		name         string
+		project      string
+		desc         string
+		testsPassing bool
+		maxRisk      string
+		wantRule     string
+		wantReject   string
+	}{
+		{"web fix", "loom", proposal("", 10, "web/static/js/app.js"), true, "", "web", ""},
+		{"web fix, medium", "loom", proposal("Medium", 10, "web/static/css/app.css"), true, "", "web", ""},
+		{"tests not passing", "loom", proposal("", 3, "web/static/js/app.js"), false, "", "tiny", "web: tests have not passed"},
+		{"outside allowed paths", "loom", proposal("", 10, "internal/api/server.go"), true, "", "", "web: internal/api/server.go is outside the allowed paths"},
+		{"forbidden path", "loom", proposal("", 10, "web/static/js/vendor.min.js"), true, "", "", "web: web/static/js/vendor.min.js is forbidden by *.min.js"},
+		{"other project", "app", proposal("", 10, "web/static/js/app.js"), true, "", "", "tiny: 10 changed lines exceed 5"},
+		{"project threshold", "loom", proposal("Medium", 1, "web/a.js"), true, RiskLow, "", "web: medium risk exceeds low"},
+		{"project off", "loom", proposal("", 1, "web/a.js"), true, RiskNone, "", "tiny: low risk exceeds none"},
+		{"no patch", "loom", "Fix the typo.", true, "", "", "web: no patch found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := p.Evaluate(NewProposal(tt.project, tt.desc, tt.testsPassing), tt.maxRisk)
+			if d.Rule != tt.wantRule || d.Approved != (tt.wantRule != "") {
+				t.Errorf("Evaluate() = %+v, want rule %q", d, tt.wantRule)
+			}
+			if tt.wantReject != "" && !strings.Contains(strings.Join(d.Rejections, "\n"), tt.wantReject) {
+				t.Errorf("rejections = %q, want %q", d.Rejections, tt.wantReject)
+			}
+		})
+	}
+}
+
+func TestPolicy_DefaultRule(t *testing.T) {
+	p := New(config.AutoApprovalConfig{})
+	if d := p.Evaluate(NewProposal("loom", proposal("", 2, "README.md"), false), ""); !d.Approved || d.Rule != DefaultRule.Name {
+		t.Errorf("low-risk fix = %+v, want approved by the default rule", d)
+	}
+	if d := p.Evaluate(NewProposal("loom", proposal("", 2, "a.go", "b.go"), false), ""); d.Approved {
+		t.Errorf("medium-risk fix approved: %+v", d)
+	}
+	deletesAuth := "```diff\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-teh\n+the\n" +
+		"--- a/internal/auth/keys.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package auth\n```\n"
+	if d := p.Evaluate(NewProposal("loom", deletesAuth, false), ""); d.Approved || d.Risk != RiskHigh {
+		t.Errorf("fix deleting auth code = %+v, want high risk and not approved", d)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(config.AutoApprovalConfig{Rules: []config.AutoApprovalRule{{Name: "a"}, {Name: "b", MaxRisk: "Medium"}}}); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	bad := []config.AutoApprovalConfig{
+		{Rules: []config.AutoApprovalRule{{MaxRisk: RiskLow}}},
+		{Rules: []config.AutoApprovalRule{{Name: "a"}, {Name: "a"}}},
+		{Rules: []config.AutoApprovalRule{{Name: "a", MaxRisk: "severe"}}},
+	}
+	for _, cfg := range bad {
+		if err := Validate(cfg); err == nil {
+			t.Errorf("Validate(%+v) accepted", cfg.Rules)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+const (
+	defaultDir     = "./data/attachments"
+	defaultMaxSize = 10 << 20
+)
+
+// DefaultAllowedTypes are accepted when no allowed_types are configured:
+// logs and other text, diffs, JSON, and common image formats. HTML and SVG
+// are deliberately absent since browsers execute script in them.
+var DefaultAllowedTypes = []string{
+	"text/plain",
+	"text/markdown",
+	"text/csv",
+	"text/x-diff",
+	"text/x-patch",
+	"application/json",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+}
+
+// extensionTypes maps file extensions to content types when the uploader
+// doesn't send one. It is consulted before the system MIME table, which
+// varies between hosts.
+var extensionTypes = map[string]string{
+	".txt":   "text/plain",
+	".log":   "text/plain",
+	".out":   "text/plain",
+	".md":    "text/markdown",
+	".csv":   "text/csv",
+	".diff":  "text/x-diff",
+	".patch": "text/x-patch",
+	".json":  "application/json",
+	".png":   "image/png",
+	".jpg":   "image/jpeg",
+	".jpeg":  "image/jpeg",
+	".gif":   "image/gif",
+	".webp":  "image/webp",
+}
+
+var (
+	// ErrNotFound is returned when an attachment ID does not exist.
+	ErrNotFound = errors.New("attachment not found")
+	// ErrTooLarge is returned when a file exceeds the configured size limit.
+	ErrTooLarge = errors.New("attachment exceeds size limit")
+	// ErrUnsupportedType is returned when a file's content type is not
+	// allowed, or its contents don't match the type it claims.
+	ErrUnsupportedType = errors.New("unsupported attachment content type")
+)
+
+// Attachment is a file attached to a bead.
+type Attachment struct {
+	ID          string    `json:"id"`
+	BeadID      string    `json:"bead_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256"`
+	Source      string    `json:"source"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachRequest describes a new attachment. ContentType may be empty, in
+// which case it is inferred from the filename and contents. Source is
+// "user" (the default) or "agent".
+type AttachRequest struct {
+	BeadID      string
+	Filename    string
+	ContentType string
+	Source      string
+	UploadedBy  string
+	Data        []byte
+}
+
+// Manager validates attachments and keeps their metadata in the database
+// and their contents in a Store.
+type Manager struct {
+	db      *database.Database
+	store   Store
+	maxSize int64
+	allowed []string
+}
+
+// NewManager builds a manager from configuration. It returns nil without a
+// database, since attachment metadata has nowhere else to live.
+func NewManager(db *database.Database, cfg config.AttachmentsConfig) (*Manager, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	var store Store
+	var err error
+	switch cfg.Storage {
+	case "", "disk":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultDir
+		}
+		store, err = NewDiskStore(dir)
+	case "postgres":
+		store, err = NewLargeObjectStore(db)
+	default:
+		err = fmt.Errorf("unknown attachment storage %q (want disk or postgres)", cfg.Storage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerWithStore(db, store, cfg.MaxSizeBytes, cfg.AllowedTypes), nil
+}
+
+// NewManagerWithStore builds a manager around an existing store. A zero
+// maxSize or empty allowed list selects the defaults.
+func NewManagerWithStore(db *database.Database, store Store, maxSize int64, allowed []string) *Manager {
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedTypes
+	}
+	return &Manager{db: db, store: store, maxSize: maxSize, allowed: allowed}
+}
+
+// MaxSize returns the per-file size limit in bytes.
+func (m *Manager) MaxSize() int64 {
+	return m.maxSize
+}
+
+// Attach validates and stores a new attachment.
+func (m *Manager) Attach(req AttachRequest) (*Attachment, error) {
+	if req.BeadID == "" {
+		return nil, fmt.Errorf("bead_id is required")
+	}
+	filename := sanitizeFilename(req.Filename)
+	if filename == "" {
+		return nil, fmt.Errorf("filename is required")
+	}
+	if len(req.Data) == 0 {
+		return nil, fmt.Errorf("attachment is empty")
+	}
+	if int64(len(req.Data)) > m.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrTooLarge, len(req.Data), m.maxSize)
+	}
+	contentType, err := m.resolveContentType(filename, req.ContentType, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	source := req.Source
+	if source == "" {
+		source = "user"
+	}
+
+	sum := sha256.Sum256(req.Data)
+	a := &database.BeadAttachment{
+		ID:          uuid.New().String(),
+		BeadID:      req.BeadID,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   int64(len(req.Data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Storage:     m.store.Name(),
+		Source:      source,
+		UploadedBy:  req.UploadedBy,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if a.StorageRef, err = m.store.Put(a.ID, req.Data); err != nil {
+		return nil, err
+	}
+	if err := m.db.CreateBeadAttachment(a); err != nil {
+		if delErr := m.store.Delete(a.StorageRef); delErr != nil {
+			log.Printf("[Attachments] Failed to remove orphaned contents of %s: %v", a.ID, delErr)
+		}
+		return nil, err
+	}
+	return fromDB(a), nil
+}
+
+// AttachOutput records an agent action's output on a bead. Output over the
+// size limit keeps its tail, where build and test failures usually are.
+func (m *Manager) AttachOutput(ctx context.Context, beadID, agentID, filename, contentType string, data []byte) error {
+	if int64(len(data)) > m.maxSize {
+		marker := []byte(fmt.Sprintf("[truncated: first %d bytes omitted]\n", int64(len(data))-m.maxSize))
+		keep := m.maxSize - int64(len(marker))
+		if keep < 0 {
+			keep = 0
+		}
+		data = append(marker, data[int64(len(data))-keep:]...)
+	}
+	_, err := m.Attach(AttachRequest{
+		BeadID:      beadID,
+		Filename:    filename,
+		ContentType: contentType,
+		Source:      "agent",
+		UploadedBy:  agentID,
+		Data:        data,
+	})
+	return err
+}
+
+// List returns a bead's attachments, oldest first.
+func (m *Manager) List(beadID string) ([]*Attachment, error) {
+	rows, err := m.db.ListBeadAttachments(beadID)
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]*Attachment, 0, len(rows))
+	for _, a := range rows {
+		attachments = append(attachments, fromDB(a))
+	}
+	return attachments, nil
+}
+
+// Get returns an attachment's metadata.
+func (m *Manager) Get(id string) (*Attachment, error) {
+	a, err := m.db.GetBeadAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	return fromDB(a), nil
+}
+
+// Open returns an attachment's metadata and contents.
+func (m *Manager) Open(id string) (*Attachment, []byte, error) {
+	a, err := m.db.GetBeadAttachment(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a == nil {
+		return nil, nil, ErrNotFound
+	}
+	if a.Storage != m.store.Name() {
+		return nil, nil, fmt.Errorf("attachment %s is in %s storage, but %s storage is configured", id, a.Storage, m.store.Name())
+	}
+	data, err := m.store.Get(a.StorageRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromDB(a), data, nil
+}
+
+// Delete removes an attachment's metadata and contents.
+func (m *Manager) Delete(id string) error {
+	a, err := m.db.GetBeadAttachment(id)
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return ErrNotFound
+	}
+	if err := m.db.DeleteBeadAttachment(id); err != nil {
+		return err
+	}
+	if a.Storage == m.store.Name() {
+		if err := m.store.Delete(a.StorageRef); err != nil {
+			log.Printf("[Attachments] Failed to remove contents of %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// resolveContentType determines the content type from the declared type,
+// the filename, or the contents, in that order, then checks it against the
+// allowed list and verifies the contents plausibly match it.
+func (m *Manager) resolveContentType(filename, declared string, data []byte) (string, error) {
+	contentType := ""
+	if declared != "" {
+		mediaType, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedType, declared)
+		}
+		// Generic binary is what clients send when they don't know; infer instead.
+		if mediaType != "application/octet-stream" {
+			contentType = mediaType
+		}
+	}
+	if contentType == "" {
+		ext := strings.ToLower(filepath.Ext(filename))
+		if t, ok := extensionTypes[ext]; ok {
+			contentType = t
+		} else if t := mime.TypeByExtension(ext); t != "" {
+			contentType, _, _ = mime.ParseMediaType(t)
+		}
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if contentType == "" {
+		contentType = sniffed
+	}
+
+	if !typeAllowed(contentType, m.allowed) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		if sniffed != contentType {
+			return "", fmt.Errorf("%w: declared %s but contents look like %s", ErrUnsupportedType, contentType, sniffed)
+		}
+	case isTextType(contentType):
+		if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+			return "", fmt.Errorf("%w: declared %s but contents are not UTF-8 text", ErrUnsupportedType, contentType)
+		}
+	}
+	return contentType, nil
+}
+
+func typeAllowed(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") || contentType == "application/json"
+}
+
+// sanitizeFilename keeps the base name and drops characters that would
+// break a Content-Disposition header.
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" {
+		return ""
+	}
+	return strings.TrimSpace(name)
+}
+
+func fromDB(a *database.BeadAttachment) *Attachment {
+	return &Attachment{
+		ID:          a.ID,
+		BeadID:      a.BeadID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		SHA256:      a.SHA256,
+		Source:      a.Source,
+		UploadedBy:  a.UploadedBy,
+		CreatedAt:   a.CreatedAt,
+	}
+}
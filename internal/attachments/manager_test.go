@@ -0,0 +1,151 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// pngHeader is enough of a PNG for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestManager(t *testing.T, maxSize int64) *Manager {
+	t.Helper()
+	db := dbtest.New(t)
+	m, err := NewManager(db, config.AttachmentsConfig{Dir: t.TempDir(), MaxSizeBytes: maxSize})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	return m
+}
+
+func TestManager_AttachOpenDelete(t *testing.T) {
+	m := newTestManager(t, 0)
+
+	a, err := m.Attach(AttachRequest{
+		BeadID:     "bd-1",
+		Filename:   "../../build.log",
+		Data:       []byte("ok\nFAIL: TestThing\n"),
+		UploadedBy: "alice",
+	})
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if a.Filename != "build.log" || a.ContentType != "text/plain" || a.Source != "user" || a.SizeBytes != 19 {
+		t.Fatalf("unexpected attachment: %+v", a)
+	}
+
+	list, err := m.List("bd-1")
+	if err != nil || len(list) != 1 || list[0].ID != a.ID {
+		t.Fatalf("List = %v, %v", list, err)
+	}
+
+	got, data, err := m.Open(a.ID)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got.SHA256 != a.SHA256 || string(data) != "ok\nFAIL: TestThing\n" {
+		t.Fatalf("Open returned %+v %q", got, data)
+	}
+
+	if err := m.Delete(a.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, _, err := m.Open(a.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open after delete: expected ErrNotFound, got %v", err)
+	}
+	if err := m.Delete(a.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManager_AttachValidation(t *testing.T) {
+	m := newTestManager(t, 64)
+
+	tests := []struct {
+		name string
+		req  AttachRequest
+		want error
+	}{
+		{"too large", AttachRequest{BeadID: "bd-1", Filename: "big.log", Data: bytes.Repeat([]byte("x"), 65)}, ErrTooLarge},
+		{"disallowed type", AttachRequest{BeadID: "bd-1", Filename: "page.html", ContentType: "text/html", Data: []byte("<p>hi</p>")}, ErrUnsupportedType},
+		{"image mismatch", AttachRequest{BeadID: "bd-1", Filename: "shot.png", Data: []byte("<script>alert(1)</script>")}, ErrUnsupportedType},
+		{"binary text", AttachRequest{BeadID: "bd-1", Filename: "out.log", Data: []byte{0x00, 0xff, 0xfe}}, ErrUnsupportedType},
+		{"empty", AttachRequest{BeadID: "bd-1", Filename: "empty.log"}, nil},
+		{"no filename", AttachRequest{BeadID: "bd-1", Data: []byte("x")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.Attach(tt.req)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	a, err := m.Attach(AttachRequest{BeadID: "bd-1", Filename: "shot", ContentType: "application/octet-stream", Data: pngHeader})
+	if err != nil {
+		t.Fatalf("sniffed PNG rejected: %v", err)
+	}
+	if a.ContentType != "image/png" {
+		t.Fatalf("expected image/png, got %s", a.ContentType)
+	}
+}
+
+func TestManager_AttachOutputKeepsTail(t *testing.T) {
+	m := newTestManager(t, 100)
+
+	output := strings.Repeat("passing line\n", 20) + "FAIL: the interesting part\n"
+	if err := m.AttachOutput(context.Background(), "bd-1", "agent-1", "run_tests.log", "text/plain", []byte(output)); err != nil {
+		t.Fatalf("AttachOutput failed: %v", err)
+	}
+
+	list, _ := m.List("bd-1")
+	if len(list) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(list))
+	}
+	if list[0].Source != "agent" || list[0].UploadedBy != "agent-1" || list[0].SizeBytes != 100 {
+		t.Fatalf("unexpected attachment: %+v", list[0])
+	}
+	_, data, _ := m.Open(list[0].ID)
+	if !strings.HasPrefix(string(data), "[truncated: first ") || !strings.HasSuffix(string(data), "FAIL: the interesting part\n") {
+		t.Fatalf("unexpected contents: %q", data)
+	}
+}
+
+func TestNewManager_Storage(t *testing.T) {
+	db := dbtest.New(t)
+
+	if _, err := NewManager(db, config.AttachmentsConfig{Storage: "postgres"}); err == nil {
+		t.Fatal("expected postgres storage to be rejected on SQLite")
+	}
+	if _, err := NewManager(db, config.AttachmentsConfig{Storage: "s3"}); err == nil {
+		t.Fatal("expected unknown storage to be rejected")
+	}
+	if m, err := NewManager(nil, config.AttachmentsConfig{}); m != nil || err != nil {
+		t.Fatalf("expected nil manager without a database, got %v, %v", m, err)
+	}
+}
+
+func TestDiskStore_RejectsEscapingRefs(t *testing.T) {
+	s, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore failed: %v", err)
+	}
+	for _, ref := range []string{"../etc/passwd", "/etc/passwd", "."} {
+		if _, err := s.Get(ref); err == nil {
+			t.Errorf("Get(%q): expected error", ref)
+		}
+	}
+	if _, err := s.Put("../x", []byte("x")); err == nil {
+		t.Error("Put with path in id: expected error")
+	}
+}
@@ -0,0 +1,136 @@
+package attachments
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// Store holds attachment contents. Put returns a reference that Get and
+// Delete accept; the reference is recorded alongside the metadata.
+type Store interface {
+	Name() string
+	Put(id string, data []byte) (string, error)
+	Get(ref string) ([]byte, error)
+	Delete(ref string) error
+}
+
+// DiskStore keeps each attachment in its own file under a directory,
+// sharded by the first two characters of the attachment ID.
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore creates the directory if needed.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) Name() string { return "disk" }
+
+func (s *DiskStore) Put(id string, data []byte) (string, error) {
+	if len(id) < 2 || strings.ContainsAny(id, `/\.`) {
+		return "", fmt.Errorf("invalid attachment id: %q", id)
+	}
+	ref := filepath.Join(id[:2], id)
+	path := filepath.Join(s.dir, ref)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return "", fmt.Errorf("failed to write attachment: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return filepath.ToSlash(ref), nil
+}
+
+func (s *DiskStore) Get(ref string) ([]byte, error) {
+	path, err := s.path(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return data, nil
+}
+
+func (s *DiskStore) Delete(ref string) error {
+	path, err := s.path(ref)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
+
+// path resolves a reference, refusing anything that escapes the directory.
+func (s *DiskStore) path(ref string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(ref))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("invalid attachment reference: %q", ref)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// LargeObjectStore keeps attachments as PostgreSQL large objects, so they
+// are covered by database backups and shared between replicas.
+type LargeObjectStore struct {
+	db *database.Database
+}
+
+// NewLargeObjectStore requires a PostgreSQL database.
+func NewLargeObjectStore(db *database.Database) (*LargeObjectStore, error) {
+	if db == nil || db.Dialect() != database.DialectPostgres {
+		return nil, fmt.Errorf("postgres attachment storage requires a PostgreSQL database")
+	}
+	return &LargeObjectStore{db: db}, nil
+}
+
+func (s *LargeObjectStore) Name() string { return "postgres" }
+
+func (s *LargeObjectStore) Put(id string, data []byte) (string, error) {
+	oid, err := s.db.CreateLargeObject(data)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(oid), 10), nil
+}
+
+func (s *LargeObjectStore) Get(ref string) ([]byte, error) {
+	oid, err := parseOID(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.ReadLargeObject(oid)
+}
+
+func (s *LargeObjectStore) Delete(ref string) error {
+	oid, err := parseOID(ref)
+	if err != nil {
+		return err
+	}
+	return s.db.UnlinkLargeObject(oid)
+}
+
+func parseOID(ref string) (uint32, error) {
+	oid, err := strconv.ParseUint(ref, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid large object reference: %q", ref)
+	}
+	return uint32(oid), nil
+}
@@ -2,6 +2,9 @@ package audit
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
 	"os"
 	"os/exec"
 	"strconv"
@@ -26,6 +29,7 @@ func NewSelfAuditActivity(projectPath string) *SelfAuditActivity {
 type SelfAuditInput struct {
 	ProjectID   string
 	ProjectPath string
+	Options     Options
 }
 
 type SelfAuditOutput struct {
@@ -43,21 +47,24 @@ func (a *SelfAuditActivity) RunSelfAudit(ctx context.Context, input SelfAuditInp
 		projectPath = "."
 	}
 
-	var allFindings []Finding
-
-	buildOut, buildErr := a.runCommand(ctx, "go", []string{"build", "./..."}, projectPath, 5*time.Minute)
-	if buildErr != nil {
-		allFindings = append(allFindings, a.parser.ParseGoBuild(buildOut)...)
+	names := input.Options.Checks
+	if names == nil {
+		names = DefaultChecks
 	}
 
-	testOut, testErr := a.runCommand(ctx, "go", []string{"test", "-short", "./..."}, projectPath, 10*time.Minute)
-	if testErr != nil {
-		allFindings = append(allFindings, a.parser.ParseGoTest(testOut)...)
-	}
-
-	lintOut, lintErr := a.runCommand(ctx, "golangci-lint", []string{"run", "--timeout=5m"}, projectPath, 10*time.Minute)
-	if lintErr != nil && strings.TrimSpace(lintOut) != "" {
-		allFindings = append(allFindings, a.parser.ParseGoLint(lintOut)...)
+	var allFindings []Finding
+	for _, name := range names {
+		check, ok := lookupCheck(name)
+		if !ok {
+			log.Printf("[SelfAudit] Unknown check %q skipped", name)
+			continue
+		}
+		findings, err := check.Run(ctx, projectPath, input.Options)
+		if err != nil {
+			log.Printf("[SelfAudit] Check %s skipped: %v", name, err)
+			continue
+		}
+		allFindings = append(allFindings, findings...)
 	}
 
 	result := a.parser.NewResult(allFindings)
@@ -69,7 +76,10 @@ func (a *SelfAuditActivity) RunSelfAudit(ctx context.Context, input SelfAuditInp
 	}, nil
 }
 
-func (a *SelfAuditActivity) runCommand(ctx context.Context, name string, args []string, dir string, timeout time.Duration) (string, error) {
+func runCommand(ctx context.Context, name string, args []string, dir string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = dir
 	cmd.Env = append(os.Environ(), "CI=true")
@@ -94,10 +104,16 @@ func (a *SelfAuditActivity) FileBeadsForFindings(ctx context.Context, beadCreato
 		return nil, err
 	}
 
+	// A finding already has a bead while one with its title, or with its
+	// fingerprint in the description, is still unresolved.
 	existingTitles := make(map[string]bool)
+	existingFingerprints := make(map[string]bool)
 	for _, b := range existingBeads {
-		if b.Status == "open" || b.Status == "in_progress" {
+		if b.Status == "open" || b.Status == "in_progress" || b.Status == "blocked" {
 			existingTitles[b.Title] = true
+			if fp := descriptionFingerprint(b.Description); fp != "" {
+				existingFingerprints[fp] = true
+			}
 		}
 	}
 
@@ -105,7 +121,8 @@ func (a *SelfAuditActivity) FileBeadsForFindings(ctx context.Context, beadCreato
 
 	for _, f := range findings {
 		title := a.findingToTitle(f)
-		if existingTitles[title] {
+		fp := fingerprint(f)
+		if existingTitles[title] || existingFingerprints[fp] {
 			continue
 		}
 
@@ -122,6 +139,7 @@ func (a *SelfAuditActivity) FileBeadsForFindings(ctx context.Context, beadCreato
 
 		newBeadIDs = append(newBeadIDs, bead.ID)
 		existingTitles[title] = true
+		existingFingerprints[fp] = true
 	}
 
 	return newBeadIDs, nil
@@ -139,6 +157,14 @@ func (a *SelfAuditActivity) findingToTitle(f Finding) string {
 			return prefix + " Lint: " + f.Rule + " - " + truncateTitle(f.Message)
 		}
 		return prefix + " Lint warning: " + truncateTitle(f.Message)
+	case FindingTypeVetIssue:
+		return prefix + " Vet: " + truncateTitle(f.Message)
+	case FindingTypeStaticAnalysis:
+		return prefix + " Staticcheck: " + f.Rule + " - " + truncateTitle(f.Message)
+	case FindingTypeVulnerability:
+		return prefix + " Vulnerability: " + f.Rule + " - " + truncateTitle(f.Message)
+	case FindingTypeCoverage:
+		return prefix + " Coverage: " + truncateTitle(f.Message)
 	default:
 		return prefix + " " + truncateTitle(f.Message)
 	}
@@ -161,9 +187,33 @@ func (a *SelfAuditActivity) findingToDescription(f Finding) string {
 	if f.Rule != "" {
 		b.WriteString("\nRule: " + f.Rule + "\n")
 	}
+	if f.Detail != "" {
+		b.WriteString("\n" + f.Detail + "\n")
+	}
+	b.WriteString("\n" + fingerprintPrefix + fingerprint(f) + "\n")
 	return b.String()
 }
 
+const fingerprintPrefix = "Fingerprint: "
+
+// fingerprint identifies a finding across audits. It leaves out the line
+// number so a finding keeps its bead when code above it moves.
+func fingerprint(f Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.Source, string(f.Type), f.File, f.Rule, f.Message}, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// descriptionFingerprint returns the fingerprint recorded in an audit
+// bead's description.
+func descriptionFingerprint(desc string) string {
+	for _, line := range strings.Split(desc, "\n") {
+		if fp, ok := strings.CutPrefix(line, fingerprintPrefix); ok {
+			return strings.TrimSpace(fp)
+		}
+	}
+	return ""
+}
+
 func truncateTitle(s string) string {
 	if len(s) <= 80 {
 		return s
@@ -107,6 +107,32 @@ func TestFileBeadsForFindings_SkipsClosedDuplicates(t *testing.T) {
 	}
 }
 
+func TestFileBeadsForFindings_FingerprintDeduplication(t *testing.T) {
+	activity := NewSelfAuditActivity(".")
+	old := Finding{
+		Type: FindingTypeVetIssue, Severity: SeverityWarning, Source: "go vet",
+		File: "internal/foo.go", Line: 10, Message: "unreachable code",
+	}
+	mock := &mockBeadCreator{
+		existingBeads: []*models.Bead{
+			// Renamed by someone triaging it, so only the fingerprint matches.
+			{ID: "bd-vet", Title: "Remove dead code in foo", Description: activity.findingToDescription(old), Status: "blocked"},
+		},
+	}
+
+	moved := old
+	moved.Line = 14
+	other := old
+	other.File = "internal/bar.go"
+	ids, err := activity.FileBeadsForFindings(context.Background(), mock, []Finding{moved, other, other}, "loom")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 1 || mock.created[0].Title != activity.findingToTitle(other) {
+		t.Errorf("expected one bead for bar.go, got %d: %+v", len(ids), mock.created)
+	}
+}
+
 func TestFileBeadsForFindings_ListError(t *testing.T) {
 	mock := &mockBeadCreator{listErr: errors.New("db down")}
 	activity := NewSelfAuditActivity(".")
@@ -166,6 +192,10 @@ func TestFindingToTitle_Types(t *testing.T) {
 		{Finding{Type: FindingTypeTestFailure, Message: "bad"}, "[auto-audit] Test failure:"},
 		{Finding{Type: FindingTypeLintError, Message: "bad"}, "[auto-audit] Lint warning:"},
 		{Finding{Type: FindingTypeLintError, Message: "bad", Rule: "errcheck"}, "[auto-audit] Lint: errcheck"},
+		{Finding{Type: FindingTypeVetIssue, Message: "bad"}, "[auto-audit] Vet:"},
+		{Finding{Type: FindingTypeStaticAnalysis, Message: "bad", Rule: "SA1019"}, "[auto-audit] Staticcheck: SA1019"},
+		{Finding{Type: FindingTypeVulnerability, Message: "bad", Rule: "GO-2024-0001"}, "[auto-audit] Vulnerability: GO-2024-0001"},
+		{Finding{Type: FindingTypeCoverage, Message: "bad"}, "[auto-audit] Coverage:"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,343 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in checks.
+const (
+	CheckBuild       = "build"
+	CheckTest        = "test"
+	CheckLint        = "lint"
+	CheckVet         = "vet"
+	CheckStaticcheck = "staticcheck"
+	CheckVulns       = "govulncheck"
+	CheckCoverage    = "coverage"
+)
+
+// DefaultChecks are the checks run when none are configured.
+var DefaultChecks = []string{CheckBuild, CheckTest, CheckLint}
+
+// ErrToolMissing is returned by a check whose tool is not installed.
+var ErrToolMissing = errors.New("audit tool not installed")
+
+// Options choose what a self-audit runs.
+type Options struct {
+	// Checks names the checks to run, in order; nil runs DefaultChecks.
+	Checks []string
+	// CoverageThreshold is the statement coverage, in percent, below which
+	// the coverage check files a finding. 0 turns the check off.
+	CoverageThreshold float64
+}
+
+// Check is one kind of audit run against a project's tree. Run returns
+// what it found; a check that passes returns no findings.
+type Check interface {
+	Name() string
+	Run(ctx context.Context, projectPath string, opts Options) ([]Finding, error)
+}
+
+// CheckRegistry holds checks added alongside the built-in ones.
+type CheckRegistry struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewCheckRegistry creates an empty check registry.
+func NewCheckRegistry() *CheckRegistry {
+	return &CheckRegistry{checks: make(map[string]Check)}
+}
+
+// defaultChecks is the registry self-audits consult.
+var defaultChecks = NewCheckRegistry()
+
+// RegisterCheck adds a check to the default registry.
+func RegisterCheck(c Check) error {
+	return defaultChecks.Register(c)
+}
+
+// UnregisterCheck removes a check from the default registry.
+func UnregisterCheck(name string) {
+	defaultChecks.Unregister(name)
+}
+
+// Checks lists the names of the built-in checks followed by the registered
+// ones.
+func Checks() []string {
+	names := make([]string, 0, len(builtinChecks))
+	for _, c := range builtinChecks {
+		names = append(names, c.Name())
+	}
+	for _, c := range defaultChecks.List() {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Register adds a check. Built-in names and names that are already
+// registered are rejected.
+func (r *CheckRegistry) Register(c Check) error {
+	if c == nil {
+		return errors.New("check is nil")
+	}
+	name := strings.TrimSpace(c.Name())
+	if name == "" {
+		return errors.New("check name is required")
+	}
+	if _, ok := builtinCheck(name); ok {
+		return fmt.Errorf("audit check %s is built in", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; ok {
+		return fmt.Errorf("audit check %s is already registered", name)
+	}
+	r.checks[name] = c
+	return nil
+}
+
+// Unregister removes a check.
+func (r *CheckRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Get returns a registered check.
+func (r *CheckRegistry) Get(name string) (Check, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.checks[name]
+	return c, ok
+}
+
+// List returns the registered checks sorted by name.
+func (r *CheckRegistry) List() []Check {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Check, 0, len(r.checks))
+	for _, c := range r.checks {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+// lookupCheck finds a built-in or registered check by name.
+func lookupCheck(name string) (Check, bool) {
+	if c, ok := builtinCheck(name); ok {
+		return c, true
+	}
+	return defaultChecks.Get(name)
+}
+
+func builtinCheck(name string) (Check, bool) {
+	for _, c := range builtinChecks {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// commandCheck runs a command in the project tree and parses its output.
+// Most tools report problems through their exit status, so their output is
+// only parsed when the command fails; always parses it regardless.
+type commandCheck struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+	always  bool
+	parse   func(output string, opts Options) []Finding
+}
+
+func (c commandCheck) Name() string { return c.name }
+
+func (c commandCheck) Run(ctx context.Context, projectPath string, opts Options) ([]Finding, error) {
+	if c.name == CheckCoverage && opts.CoverageThreshold <= 0 {
+		return nil, nil
+	}
+	output, err := runCommand(ctx, c.command, c.args, projectPath, c.timeout)
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrToolMissing, c.command)
+	}
+	if err == nil && !c.always {
+		return nil, nil
+	}
+	return c.parse(output, opts), nil
+}
+
+var checkParser = NewParser()
+
+func ignoreOptions(parse func(string) []Finding) func(string, Options) []Finding {
+	return func(output string, _ Options) []Finding { return parse(output) }
+}
+
+var builtinChecks = []Check{
+	commandCheck{name: CheckBuild, command: "go", args: []string{"build", "./..."}, timeout: 5 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseGoBuild)},
+	commandCheck{name: CheckTest, command: "go", args: []string{"test", "-short", "./..."}, timeout: 10 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseGoTest)},
+	commandCheck{name: CheckLint, command: "golangci-lint", args: []string{"run", "--timeout=5m"}, timeout: 10 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseGoLint)},
+	commandCheck{name: CheckVet, command: "go", args: []string{"vet", "./..."}, timeout: 5 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseGoVet)},
+	commandCheck{name: CheckStaticcheck, command: "staticcheck", args: []string{"./..."}, timeout: 10 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseStaticcheck)},
+	commandCheck{name: CheckVulns, command: "govulncheck", args: []string{"./..."}, timeout: 10 * time.Minute,
+		parse: ignoreOptions(checkParser.ParseGovulncheck)},
+	commandCheck{name: CheckCoverage, command: "go", args: []string{"test", "-short", "-cover", "./..."}, timeout: 10 * time.Minute, always: true,
+		parse: func(output string, opts Options) []Finding {
+			return checkParser.ParseCoverage(output, opts.CoverageThreshold)
+		}},
+}
+
+// ParseGoVet parses `go vet` output
+func (p *Parser) ParseGoVet(output string) []Finding {
+	var findings []Finding
+	re := regexp.MustCompile(`^(?:vet: )?([^:\s]+\.go):(\d+):(\d+)?:?\s*(.+)`)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := re.FindStringSubmatch(line); m != nil {
+			findings = append(findings, Finding{
+				Type:     FindingTypeVetIssue,
+				Severity: SeverityWarning,
+				Source:   "go vet",
+				File:     m[1],
+				Line:     atoiSafe(m[2]),
+				Column:   atoiSafe(m[3]),
+				Message:  strings.TrimSpace(m[4]),
+			})
+		}
+	}
+	return findings
+}
+
+// ParseStaticcheck parses staticcheck output
+func (p *Parser) ParseStaticcheck(output string) []Finding {
+	var findings []Finding
+	// Pattern: path/to/file.go:line:col: message (SA1019)
+	re := regexp.MustCompile(`^([^:\s]+\.go):(\d+):(\d+)?:?\s*(.+?)\s+\(([A-Z]+\d+)\)$`)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if m := re.FindStringSubmatch(line); m != nil {
+			findings = append(findings, Finding{
+				Type:     FindingTypeStaticAnalysis,
+				Severity: SeverityWarning,
+				Source:   "staticcheck",
+				File:     m[1],
+				Line:     atoiSafe(m[2]),
+				Column:   atoiSafe(m[3]),
+				Message:  strings.TrimSpace(m[4]),
+				Rule:     m[5],
+			})
+		}
+	}
+	return findings
+}
+
+// ParseGovulncheck parses govulncheck's text output: one finding per
+// vulnerability the code calls.
+func (p *Parser) ParseGovulncheck(output string) []Finding {
+	var findings []Finding
+	idRe := regexp.MustCompile(`^Vulnerability #\d+: (\S+)`)
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		m := idRe.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if m == nil {
+			continue
+		}
+		f := Finding{
+			Type:     FindingTypeVulnerability,
+			Severity: SeverityError,
+			Source:   "govulncheck",
+			Rule:     m[1],
+		}
+		var details []string
+		for i+1 < len(lines) {
+			next := strings.TrimSpace(lines[i+1])
+			if idRe.MatchString(next) {
+				break
+			}
+			i++
+			switch {
+			case next == "":
+			case f.Message == "":
+				f.Message = next
+			case strings.HasPrefix(next, "Module:") || strings.HasPrefix(next, "Found in:") ||
+				strings.HasPrefix(next, "Fixed in:") || strings.HasPrefix(next, "More info:"):
+				details = append(details, next)
+			}
+		}
+		if f.Message == "" {
+			f.Message = m[1]
+		}
+		f.Detail = strings.Join(details, "\n")
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// ParseCoverage parses `go test -cover` output and reports one finding when
+// the average statement coverage of the packages with tests is below
+// threshold percent.
+func (p *Parser) ParseCoverage(output string, threshold float64) []Finding {
+	re := regexp.MustCompile(`^ok\s+(\S+)\s+.*coverage: ([\d.]+)% of statements`)
+	type pkgCoverage struct {
+		pkg string
+		pct float64
+	}
+	var pkgs []pkgCoverage
+	total := 0.0
+	for _, line := range strings.Split(output, "\n") {
+		m := re.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		pct, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		pkgs = append(pkgs, pkgCoverage{m[1], pct})
+		total += pct
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+	avg := total / float64(len(pkgs))
+	if avg >= threshold {
+		return nil
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].pct < pkgs[j].pct })
+	var b strings.Builder
+	fmt.Fprintf(&b, "Average statement coverage is %.1f%% across %d packages. Least covered:\n", avg, len(pkgs))
+	for i, pc := range pkgs {
+		if i == 10 {
+			break
+		}
+		fmt.Fprintf(&b, "  %5.1f%%  %s\n", pc.pct, pc.pkg)
+	}
+	return []Finding{{
+		Type:     FindingTypeCoverage,
+		Severity: SeverityWarning,
+		Source:   "go test -cover",
+		// The message names only the threshold, so the finding keeps its
+		// title, and its bead, while the coverage itself moves.
+		Message: fmt.Sprintf("test coverage below %g%%", threshold),
+		Detail:  strings.TrimRight(b.String(), "\n"),
+	}}
+}
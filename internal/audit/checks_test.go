@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_ParseGoVet(t *testing.T) {
+	output := `# github.com/example/app/internal/foo
+internal/foo/foo.go:12:2: unreachable code
+vet: internal/foo/bar.go:7:5: fmt.Sprintf format %d has arg s of wrong type string`
+
+	findings := NewParser().ParseGoVet(output)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d: %+v", len(findings), findings)
+	}
+	f := findings[1]
+	if f.Type != FindingTypeVetIssue || f.Source != "go vet" || f.File != "internal/foo/bar.go" || f.Line != 7 {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.HasPrefix(f.Message, "fmt.Sprintf format %d") {
+		t.Errorf("message = %q", f.Message)
+	}
+}
+
+func TestParser_ParseStaticcheck(t *testing.T) {
+	output := `internal/foo/foo.go:30:6: func unused is unused (U1000)
+internal/foo/foo.go:41:2: io/ioutil has been deprecated since Go 1.19 (SA1019)`
+
+	findings := NewParser().ParseStaticcheck(output)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	if f := findings[1]; f.Rule != "SA1019" || f.Line != 41 || f.Message != "io/ioutil has been deprecated since Go 1.19" {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestParser_ParseGovulncheck(t *testing.T) {
+	output := `=== Symbol Results ===
+
+Vulnerability #1: GO-2024-2687
+    HTTP/2 CONTINUATION flood in net/http
+  More info: https://pkg.go.dev/vuln/GO-2024-2687
+  Standard library
+    Found in: net/http@go1.21.0
+    Fixed in: net/http@go1.21.9
+
+Vulnerability #2: GO-2023-2402
+    Man-in-the-middle attacker can compromise integrity of secure channel
+  Module: golang.org/x/crypto
+    Found in: golang.org/x/crypto@v0.14.0
+
+Your code is affected by 2 vulnerabilities.`
+
+	findings := NewParser().ParseGovulncheck(output)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Rule != "GO-2024-2687" || f.Message != "HTTP/2 CONTINUATION flood in net/http" || f.Severity != SeverityError {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Detail, "Fixed in: net/http@go1.21.9") {
+		t.Errorf("detail = %q", f.Detail)
+	}
+	if !strings.Contains(findings[1].Detail, "Module: golang.org/x/crypto") {
+		t.Errorf("detail = %q", findings[1].Detail)
+	}
+}
+
+func TestParser_ParseCoverage(t *testing.T) {
+	output := `ok  	github.com/example/app/a	0.01s	coverage: 80.0% of statements
+ok  	github.com/example/app/b	0.02s	coverage: 20.0% of statements
+?   	github.com/example/app/c	[no test files]`
+
+	p := NewParser()
+	if findings := p.ParseCoverage(output, 50); len(findings) != 0 {
+		t.Errorf("average of 50%% flagged at threshold 50: %+v", findings)
+	}
+	findings := p.ParseCoverage(output, 60)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Message != "test coverage below 60%" {
+		t.Errorf("message = %q", f.Message)
+	}
+	if !strings.Contains(f.Detail, "50.0%") || strings.Index(f.Detail, "app/b") > strings.Index(f.Detail, "app/a") {
+		t.Errorf("detail should give the average and list the least covered first: %q", f.Detail)
+	}
+}
+
+type stubCheck struct {
+	name     string
+	findings []Finding
+	err      error
+}
+
+func (c stubCheck) Name() string { return c.name }
+
+func (c stubCheck) Run(context.Context, string, Options) ([]Finding, error) {
+	return c.findings, c.err
+}
+
+func TestRegisterCheck(t *testing.T) {
+	if err := RegisterCheck(stubCheck{name: CheckVet}); err == nil {
+		t.Error("registering a built-in name should fail")
+	}
+	if err := RegisterCheck(stubCheck{name: "license", findings: []Finding{{Type: FindingTypeWarning, Message: "no license"}}}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterCheck("license")
+	if err := RegisterCheck(stubCheck{name: "license"}); err == nil {
+		t.Error("registering a name twice should fail")
+	}
+	if err := RegisterCheck(stubCheck{name: "tools", err: ErrToolMissing}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterCheck("tools")
+
+	names := Checks()
+	if len(names) != 9 || names[7] != "license" {
+		t.Errorf("Checks() = %v", names)
+	}
+
+	out, err := NewSelfAuditActivity(".").RunSelfAudit(context.Background(), SelfAuditInput{
+		Options: Options{Checks: []string{"license", "tools", "nonexistent"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Result.Findings) != 1 || out.Result.Findings[0].Message != "no license" {
+		t.Errorf("findings = %+v", out.Result.Findings)
+	}
+}
+
+func TestCoverageCheckOffWithoutThreshold(t *testing.T) {
+	c, ok := lookupCheck(CheckCoverage)
+	if !ok {
+		t.Fatal("coverage check not found")
+	}
+	findings, err := c.Run(context.Background(), t.TempDir(), Options{})
+	if err != nil || findings != nil {
+		t.Errorf("Run() = %v, %v; want nothing without a threshold", findings, err)
+	}
+}
+
+func TestCommandCheck_ToolMissing(t *testing.T) {
+	c := commandCheck{name: "missing", command: "loom-no-such-tool", timeout: time.Second,
+		parse: ignoreOptions(NewParser().ParseGoLint)}
+	if _, err := c.Run(context.Background(), t.TempDir(), Options{}); !errors.Is(err, ErrToolMissing) {
+		t.Errorf("err = %v, want ErrToolMissing", err)
+	}
+}
@@ -14,7 +14,8 @@ type Finding struct {
 	Line     int         `json:"line"`
 	Column   int         `json:"column,omitempty"`
 	Message  string      `json:"message"`
-	Rule     string      `json:"rule,omitempty"` // for linter rules
+	Rule     string      `json:"rule,omitempty"`   // for linter rules
+	Detail   string      `json:"detail,omitempty"` // extra context for the bead description
 }
 
 // FindingType categorizes the type of finding
@@ -27,6 +28,14 @@ const (
 	FindingTypeWarning     FindingType = "warning"
 )
 
+// Finding types of the optional checks.
+const (
+	FindingTypeVetIssue       FindingType = "vet_issue"
+	FindingTypeStaticAnalysis FindingType = "static_analysis"
+	FindingTypeVulnerability  FindingType = "vulnerability"
+	FindingTypeCoverage       FindingType = "coverage"
+)
+
 // Severity indicates how serious the finding is
 type Severity string
 
@@ -223,6 +232,12 @@ func (p *Parser) Parse(source, output string) []Finding {
 		return p.ParseGoTest(output)
 	case "golangci-lint":
 		return p.ParseGoLint(output)
+	case "go vet":
+		return p.ParseGoVet(output)
+	case "staticcheck":
+		return p.ParseStaticcheck(output)
+	case "govulncheck":
+		return p.ParseGovulncheck(output)
 	default:
 		return nil
 	}
@@ -233,6 +248,7 @@ func (p *Parser) NewResult(findings []Finding) *Result {
 	buildErrors := 0
 	testFailures := 0
 	lintErrors := 0
+	other := 0
 
 	for _, f := range findings {
 		switch f.Type {
@@ -242,6 +258,8 @@ func (p *Parser) NewResult(findings []Finding) *Result {
 			testFailures++
 		case FindingTypeLintError:
 			lintErrors++
+		default:
+			other++
 		}
 	}
 
@@ -255,6 +273,9 @@ func (p *Parser) NewResult(findings []Finding) *Result {
 	if lintErrors > 0 {
 		summary = summary + fmt.Sprintf(", %d lint errors", lintErrors)
 	}
+	if other > 0 {
+		summary = summary + fmt.Sprintf(", %d other findings", other)
+	}
 	if len(findings) == 0 {
 		summary = "All checks passed"
 	}
@@ -12,6 +12,7 @@ type Runner struct {
 	intervalMinutes int
 	activity        *SelfAuditActivity
 	beadCreator     BeadCreator
+	options         func(projectID string) Options
 	stopCh          chan struct{}
 }
 
@@ -29,6 +30,12 @@ func NewRunner(projectID, projectPath string, intervalMinutes int, beadCreator B
 	}
 }
 
+// SetOptions sets the lookup that chooses the checks each audit runs.
+// Without one, audits run DefaultChecks.
+func (r *Runner) SetOptions(lookup func(projectID string) Options) {
+	r.options = lookup
+}
+
 func (r *Runner) Start(ctx context.Context) {
 	log.Printf("[SelfAudit] Starting for project %s, interval %dm", r.projectID, r.intervalMinutes)
 
@@ -54,10 +61,14 @@ func (r *Runner) Stop() {
 func (r *Runner) runAudit(ctx context.Context) {
 	log.Printf("[SelfAudit] Running audit for project %s", r.projectID)
 
-	output, err := r.activity.RunSelfAudit(ctx, SelfAuditInput{
+	input := SelfAuditInput{
 		ProjectID:   r.projectID,
 		ProjectPath: r.projectPath,
-	})
+	}
+	if r.options != nil {
+		input.Options = r.options(r.projectID)
+	}
+	output, err := r.activity.RunSelfAudit(ctx, input)
 
 	if err != nil {
 		log.Printf("[SelfAudit] Audit failed: %v", err)
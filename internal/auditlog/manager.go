@@ -0,0 +1,280 @@
+// Package auditlog keeps an append-only record of the mutating API
+// requests made against loom: who made each one, what it touched, and how
+// it turned out. For beads, projects, and providers it also keeps the
+// resource as it was before and after the request, with a field-level
+// diff. Entries are never updated; the retention policy is the only thing
+// that removes them.
+//
+// This is unrelated to internal/audit, which audits loom's own builds.
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// DefaultRetention is how long entries are kept when the config doesn't
+// say.
+const DefaultRetention = 90 * 24 * time.Hour
+
+// defaultListLimit is how many entries List returns when no limit is given.
+const defaultListLimit = 100
+
+// redacted replaces the values of sensitive fields in snapshots.
+const redacted = "[redacted]"
+
+// sensitiveFields are snapshot field names whose values are never stored.
+// A field also matches when its name ends in "_" followed by one of these.
+var sensitiveFields = []string{"api_key", "password", "secret", "token", "private_key", "credentials"}
+
+// Entry is one audited request.
+type Entry struct {
+	ID           string            `json:"id"`
+	ActorID      string            `json:"actor_id"`
+	ActorName    string            `json:"actor_name,omitempty"`
+	ActorRole    string            `json:"actor_role,omitempty"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	ResourceType string            `json:"resource_type,omitempty"`
+	ResourceID   string            `json:"resource_id,omitempty"`
+	StatusCode   int               `json:"status_code"`
+	RemoteAddr   string            `json:"remote_addr,omitempty"`
+	Before       json.RawMessage   `json:"before,omitempty"`
+	After        json.RawMessage   `json:"after,omitempty"`
+	Diff         map[string]Change `json:"diff,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// Change is a field's value before and after a request. A nil side means
+// the field was absent.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Filter selects entries. Actor matches an actor's ID or name.
+type Filter struct {
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	Method       string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+// Manager records and queries the audit log.
+type Manager struct {
+	db        *database.Database
+	retention config.AuditConfig
+	now       func() time.Time
+}
+
+// NewManager creates an audit log manager. It returns nil without a
+// database.
+func NewManager(db *database.Database, cfg config.AuditConfig) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db, retention: cfg, now: time.Now}
+}
+
+// Record appends an entry, computing its diff when both snapshots are set.
+func (m *Manager) Record(e *Entry) error {
+	e.ID = "audit-" + uuid.New().String()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = m.now().UTC()
+	}
+	if len(e.Before) > 0 && len(e.After) > 0 {
+		diff, err := Diff(e.Before, e.After)
+		if err != nil {
+			return err
+		}
+		e.Diff = diff
+	}
+
+	row := &database.AuditLogEntry{
+		ID:           e.ID,
+		ActorID:      e.ActorID,
+		ActorName:    e.ActorName,
+		ActorRole:    e.ActorRole,
+		Method:       e.Method,
+		Path:         e.Path,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		StatusCode:   e.StatusCode,
+		RemoteAddr:   e.RemoteAddr,
+		Before:       string(e.Before),
+		After:        string(e.After),
+		CreatedAt:    e.CreatedAt,
+	}
+	if len(e.Diff) > 0 {
+		diff, err := json.Marshal(e.Diff)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit diff: %w", err)
+		}
+		row.Diff = string(diff)
+	}
+	return m.db.CreateAuditLogEntry(row)
+}
+
+// List returns matching entries, newest first.
+func (m *Manager) List(f Filter) ([]*Entry, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultListLimit
+	}
+	rows, err := m.db.ListAuditLogEntries(database.AuditLogFilter{
+		Actor:        f.Actor,
+		ResourceType: f.ResourceType,
+		ResourceID:   f.ResourceID,
+		Method:       strings.ToUpper(f.Method),
+		Since:        f.Since,
+		Until:        f.Until,
+		Limit:        f.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(rows))
+	for _, row := range rows {
+		e := &Entry{
+			ID:           row.ID,
+			ActorID:      row.ActorID,
+			ActorName:    row.ActorName,
+			ActorRole:    row.ActorRole,
+			Method:       row.Method,
+			Path:         row.Path,
+			ResourceType: row.ResourceType,
+			ResourceID:   row.ResourceID,
+			StatusCode:   row.StatusCode,
+			RemoteAddr:   row.RemoteAddr,
+			CreatedAt:    row.CreatedAt,
+		}
+		if row.Before != "" {
+			e.Before = json.RawMessage(row.Before)
+		}
+		if row.After != "" {
+			e.After = json.RawMessage(row.After)
+		}
+		if row.Diff != "" {
+			if err := json.Unmarshal([]byte(row.Diff), &e.Diff); err != nil {
+				return nil, fmt.Errorf("failed to decode audit diff for %s: %w", row.ID, err)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Prune removes entries older than their resource type's retention and
+// returns how many were removed.
+func (m *Manager) Prune() (int64, error) {
+	now := m.now().UTC()
+	var total int64
+	overridden := make([]string, 0, len(m.retention.ResourceRetention))
+	for resourceType := range m.retention.ResourceRetention {
+		overridden = append(overridden, resourceType)
+	}
+	sort.Strings(overridden)
+
+	for _, resourceType := range overridden {
+		keep := m.retention.ResourceRetention[resourceType]
+		if keep < 0 {
+			continue
+		}
+		n, err := m.db.DeleteAuditLogEntriesBefore(now.Add(-keep), []string{resourceType}, false)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+
+	keep := m.retention.Retention
+	if keep == 0 {
+		keep = DefaultRetention
+	}
+	if keep < 0 {
+		return total, nil
+	}
+	n, err := m.db.DeleteAuditLogEntriesBefore(now.Add(-keep), overridden, true)
+	return total + n, err
+}
+
+// Snapshot encodes v for an entry's Before or After, replacing the values
+// of sensitive fields such as API keys. It returns nil for a nil v.
+func Snapshot(v interface{}) (json.RawMessage, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+	return json.Marshal(redact(decoded))
+}
+
+// Diff compares two JSON objects field by field and returns the fields
+// that differ.
+func Diff(before, after json.RawMessage) (map[string]Change, error) {
+	var b, a map[string]interface{}
+	if err := json.Unmarshal(before, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+	if err := json.Unmarshal(after, &a); err != nil {
+		return nil, fmt.Errorf("failed to decode audit snapshot: %w", err)
+	}
+	diff := make(map[string]Change)
+	for field, bv := range b {
+		if av, ok := a[field]; !ok || !reflect.DeepEqual(bv, av) {
+			diff[field] = Change{Before: bv, After: a[field]}
+		}
+	}
+	for field, av := range a {
+		if _, ok := b[field]; !ok {
+			diff[field] = Change{After: av}
+		}
+	}
+	return diff, nil
+}
+
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for field, value := range t {
+			if isSensitive(field) {
+				if value != nil && value != "" {
+					t[field] = redacted
+				}
+				continue
+			}
+			t[field] = redact(value)
+		}
+	case []interface{}:
+		for i, value := range t {
+			t[i] = redact(value)
+		}
+	}
+	return v
+}
+
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, name := range sensitiveFields {
+		if field == name || strings.HasSuffix(field, "_"+name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func newTestManager(t *testing.T, cfg config.AuditConfig) *Manager {
+	t.Helper()
+	return NewManager(dbtest.New(t), cfg)
+}
+
+func TestRecordAndList(t *testing.T) {
+	m := newTestManager(t, config.AuditConfig{})
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	before, _ := Snapshot(map[string]interface{}{"title": "Old", "priority": 2, "api_key": "sk-123"})
+	after, _ := Snapshot(map[string]interface{}{"title": "New", "priority": 2, "api_key": "sk-456", "tags": []string{"x"}})
+	entries := []*Entry{
+		{ActorID: "user-admin", ActorName: "admin", Method: "PATCH", Path: "/api/v1/beads/bd-1", ResourceType: "beads", ResourceID: "bd-1",
+			StatusCode: 200, Before: before, After: after, CreatedAt: base},
+		{ActorID: "user-ops", ActorName: "ops", Method: "DELETE", Path: "/api/v1/providers/p1", ResourceType: "providers", ResourceID: "p1",
+			StatusCode: 204, Before: before, CreatedAt: base.Add(time.Hour)},
+	}
+	for _, e := range entries {
+		if err := m.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	all, err := m.List(Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 || all[0].Method != "DELETE" {
+		t.Fatalf("List = %+v, want newest first", all)
+	}
+
+	got, err := m.List(Filter{Actor: "admin"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("List(actor=admin) = %v, %v", got, err)
+	}
+	diff := got[0].Diff
+	if len(diff) != 2 || diff["title"].Before != "Old" || diff["title"].After != "New" {
+		t.Errorf("diff = %+v, want title and tags", diff)
+	}
+	if _, ok := diff["api_key"]; ok {
+		t.Error("redacted api_key should not appear in the diff")
+	}
+	if strings.Contains(string(got[0].Before), "sk-123") {
+		t.Errorf("before snapshot leaked the api key: %s", got[0].Before)
+	}
+
+	if got, _ := m.List(Filter{Since: base.Add(30 * time.Minute)}); len(got) != 1 || got[0].ResourceID != "p1" {
+		t.Errorf("List(since) = %+v", got)
+	}
+	if got, _ := m.List(Filter{ResourceType: "beads", Method: "patch"}); len(got) != 1 {
+		t.Errorf("List(resource_type, method) = %+v", got)
+	}
+	if got, _ := m.List(Filter{Limit: 1}); len(got) != 1 {
+		t.Errorf("List(limit=1) returned %d entries", len(got))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	m := newTestManager(t, config.AuditConfig{
+		Retention:         24 * time.Hour,
+		ResourceRetention: map[string]time.Duration{"providers": 7 * 24 * time.Hour, "secrets": -1},
+	})
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	old := now.Add(-48 * time.Hour)
+	ancient := now.Add(-30 * 24 * time.Hour)
+	for _, e := range []*Entry{
+		{Method: "POST", Path: "/api/v1/beads", ResourceType: "beads", StatusCode: 201, CreatedAt: old},
+		{Method: "POST", Path: "/api/v1/beads", ResourceType: "beads", StatusCode: 201, CreatedAt: now},
+		{Method: "PUT", Path: "/api/v1/providers/p1", ResourceType: "providers", StatusCode: 200, CreatedAt: old},
+		{Method: "PUT", Path: "/api/v1/providers/p1", ResourceType: "providers", StatusCode: 200, CreatedAt: ancient},
+		{Method: "PUT", Path: "/api/v1/secrets/X", ResourceType: "secrets", StatusCode: 200, CreatedAt: ancient},
+	} {
+		if err := m.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	n, err := m.Prune()
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Prune removed %d entries, want 2", n)
+	}
+	left, _ := m.List(Filter{})
+	if len(left) != 3 {
+		t.Fatalf("%d entries left, want 3", len(left))
+	}
+	for _, e := range left {
+		if e.ResourceType == "beads" && e.CreatedAt.Before(now) {
+			t.Error("old beads entry should have been pruned")
+		}
+		if e.ResourceType == "providers" && e.CreatedAt.Equal(ancient) {
+			t.Error("ancient providers entry should have been pruned")
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	type provider struct {
+		ID        string            `json:"id"`
+		APIKey    string            `json:"api_key"`
+		MaxTokens int               `json:"max_tokens"`
+		Git       map[string]string `json:"git"`
+	}
+	var nilProvider *provider
+	if got, err := Snapshot(nilProvider); err != nil || got != nil {
+		t.Errorf("Snapshot(nil) = %s, %v", got, err)
+	}
+
+	got, err := Snapshot(&provider{ID: "p1", APIKey: "sk-1", MaxTokens: 10, Git: map[string]string{"auth_token": "ghp"}})
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded["api_key"] != redacted || decoded["max_tokens"] != float64(10) {
+		t.Errorf("snapshot = %s", got)
+	}
+	if decoded["git"].(map[string]interface{})["auth_token"] != redacted {
+		t.Errorf("nested token not redacted: %s", got)
+	}
+}
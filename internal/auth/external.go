@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// LoginExternal signs in a user an external identity provider has already
+// authenticated. Users are matched on externalID, the provider's stable
+// identifier for them, never on username: a provider account named like a
+// local user doesn't get that user's access. A user seen for the first
+// time is created with username, which must not be taken; a returning
+// user's email and role are updated to what the provider reports. Such
+// users have no password, so they can't use the password login.
+func (m *Manager) LoginExternal(externalID, username, email, role string) (*LoginResponse, error) {
+	if externalID == "" {
+		return nil, fmt.Errorf("external ID is required")
+	}
+	if username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+	if _, exists := m.roles[role]; !exists {
+		return nil, fmt.Errorf("unknown role: %s", role)
+	}
+
+	m.usersMu.Lock()
+	var user *User
+	for _, u := range m.users {
+		if u.ExternalID == externalID {
+			user = u
+			break
+		}
+	}
+	if user == nil && m.userStore != nil {
+		stored, err := m.userStore.GetUserByExternalID(externalID)
+		if err != nil {
+			m.usersMu.Unlock()
+			return nil, fmt.Errorf("failed to look up user: %w", err)
+		}
+		if stored != nil {
+			user = stored
+			m.users[user.ID] = user
+		}
+	}
+
+	now := time.Now()
+	provisioned := user == nil
+	if provisioned {
+		for _, u := range m.users {
+			if u.Username == username {
+				m.usersMu.Unlock()
+				return nil, fmt.Errorf("username %s is taken by another account", username)
+			}
+		}
+		user = &User{
+			ID:         generateRandomID(),
+			Username:   username,
+			ExternalID: externalID,
+			IsActive:   true,
+			CreatedAt:  now,
+		}
+	}
+	if !user.IsActive {
+		m.usersMu.Unlock()
+		return nil, fmt.Errorf("user %s is disabled", user.Username)
+	}
+	if user.Email != email || user.Role != role {
+		user.Email = email
+		user.Role = role
+		user.UpdatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	user.LastLoginAt = &now
+	if m.userStore != nil {
+		if err := m.userStore.SaveUser(user); err != nil {
+			m.usersMu.Unlock()
+			return nil, fmt.Errorf("failed to save user: %w", err)
+		}
+	}
+	m.users[user.ID] = user
+	copied := *user
+	m.usersMu.Unlock()
+	if provisioned {
+		log.Printf("Provisioned user %s with role %s", username, role)
+	}
+
+	token, err := m.GenerateToken(&copied)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{
+		Token:     token,
+		ExpiresIn: int64(m.tokenTTL.Seconds()),
+		User:      copied,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import "testing"
+
+func TestManager_LoginExternal(t *testing.T) {
+	store := newMemUserStore()
+	m := NewManager("secret")
+	if err := m.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := m.LoginExternal("https://idp.example.com#00u1", "ada", "ada@example.com", "user")
+	if err != nil {
+		t.Fatalf("LoginExternal() error = %v", err)
+	}
+	if first.Token == "" || first.User.Role != "user" {
+		t.Errorf("first sign-in = %+v", first)
+	}
+	if _, ok := store.users[first.User.ID]; !ok {
+		t.Error("provisioned user not stored")
+	}
+	if _, err := m.Login("ada", ""); err == nil {
+		t.Error("provisioned user signed in without a password")
+	}
+
+	// After a restart the user keeps their ID and gets their new role.
+	m2 := NewManager("secret")
+	if err := m2.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+	// Users are matched on their external ID, so a renamed account is
+	// still the same user.
+	again, err := m2.LoginExternal("https://idp.example.com#00u1", "ada.l", "ada@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.User.ID != first.User.ID || again.User.Role != "admin" || again.User.Username != "ada" {
+		t.Errorf("returning user = %+v, want ID %s with role admin", again.User, first.User.ID)
+	}
+
+	// A provider account named like a local user doesn't get its access.
+	if _, err := m2.LoginExternal("https://idp.example.com#00u2", "admin", "", "viewer"); err == nil {
+		t.Error("provider account signed in as the local admin")
+	}
+	if _, err := m2.LoginExternal("https://idp.example.com#00u3", "ada", "", "user"); err == nil {
+		t.Error("second provider account signed in as ada")
+	}
+
+	if _, err := m2.LoginExternal("https://idp.example.com#00u4", "bob", "", "root"); err == nil {
+		t.Error("unknown role accepted")
+	}
+}
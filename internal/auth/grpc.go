@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Identity is the authenticated caller of a gRPC method.
+type Identity struct {
+	UserID   string
+	Username string
+	Role     string
+	OrgID    string
+	TeamID   string
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the caller the gRPC interceptors authenticated,
+// or nil when the method was called without authentication.
+func IdentityFromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
+
+// UnaryServerInterceptor authenticates unary gRPC calls the same way
+// RouteMiddleware authenticates HTTP requests: a bearer token in the
+// "authorization" metadata or an API key in "x-api-key". permissionFor
+// returns the permission a method needs; methods it does not know (ok is
+// false) are passed through without authentication.
+func (m *Manager) UnaryServerInterceptor(permissionFor func(fullMethod string) (permission string, ok bool)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permission, ok := permissionFor(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		id, err := m.authenticateGRPC(ctx, permission)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, identityKey{}, id), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func (m *Manager) StreamServerInterceptor(permissionFor func(fullMethod string) (permission string, ok bool)) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		permission, ok := permissionFor(info.FullMethod)
+		if !ok {
+			return handler(srv, ss)
+		}
+		id, err := m.authenticateGRPC(ss.Context(), permission)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), identityKey{}, id)})
+	}
+}
+
+// identityStream carries the authenticated identity in the stream context.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context { return s.ctx }
+
+// authenticateGRPC resolves the caller from the incoming metadata and checks
+// it holds permission. An empty permission only requires authentication.
+func (m *Manager) authenticateGRPC(ctx context.Context, permission string) (*Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	requested := first(strings.ToLower(OrgRequestHeader))
+
+	var id *Identity
+	if value := apiTokenValue(first("authorization"), first("x-api-key")); value != "" {
+		t, user, err := m.authenticateToken(value, permission)
+		if errors.Is(err, errTokenForbidden) {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid API token: %v", err)
+		}
+		// gRPC methods aren't scoped to a project, so a token limited to
+		// projects can't call them.
+		if len(t.ProjectIDs) > 0 {
+			return nil, status.Error(codes.PermissionDenied, "API token is limited to projects")
+		}
+		id = &Identity{UserID: user.ID, Username: user.Username, Role: TokenRole(t.Scope, user.Role)}
+	} else if authHeader := first("authorization"); authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+		claims, err := m.ValidateToken(parts[1])
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+		}
+		if permission != "" && !m.HasPermission(claims, permission) {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+		id = &Identity{UserID: claims.UserID, Username: claims.Username, Role: claims.Role}
+	} else {
+		apiKey := first("x-api-key")
+		if apiKey == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		}
+		key, err := m.lookupAPIKey(apiKey)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		permissions := key.Permissions
+
+		// Keys created without explicit permissions act with the
+		// permissions of the owner's role.
+		user, _ := m.GetUser(key.UserID)
+		if len(permissions) == 0 && user != nil {
+			permissions = m.roles[user.Role].Permissions
+		}
+		if permission != "" && !PermissionAllows(permissions, permission) {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+
+		// A key limited to an organization can't be used in another.
+		if key.OrgID != "" {
+			if requested != "" && requested != key.OrgID {
+				return nil, status.Error(codes.PermissionDenied, "API key is limited to organization "+key.OrgID)
+			}
+			requested = key.OrgID
+		}
+		id = &Identity{UserID: key.UserID}
+		if user != nil {
+			id.Username, id.Role = user.Username, user.Role
+		}
+	}
+
+	orgID, teamID, err := m.ResolveOrg(id.UserID, id.Role, requested)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	id.OrgID, id.TeamID = orgID, teamID
+	return id, nil
+}
+
+// apiTokenValue returns the API token presented as a bearer token or API
+// key, or "" when neither is one.
+func apiTokenValue(authorization, apiKey string) string {
+	if v, ok := strings.CutPrefix(authorization, "Bearer "); ok && strings.HasPrefix(v, TokenPrefix) {
+		return v
+	}
+	if authorization == "" && strings.HasPrefix(apiKey, TokenPrefix) {
+		return apiKey
+	}
+	return ""
+}
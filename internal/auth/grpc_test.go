@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func grpcPermissions(fullMethod string) (string, bool) {
+	switch fullMethod {
+	case "/test.Service/Read":
+		return "beads:read", true
+	case "/test.Service/Delete":
+		return "beads:delete", true
+	}
+	return "", false
+}
+
+func callUnary(m *Manager, ctx context.Context, method string) (*Identity, error) {
+	var got *Identity
+	_, err := m.UnaryServerInterceptor(grpcPermissions)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = IdentityFromContext(ctx)
+			return nil, nil
+		})
+	return got, err
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := NewManager("test-secret")
+	login, err := m.Login("admin", "admin")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	viewer, err := m.CreateUser("viewer", "viewer@loom.local", "viewer", "password")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	key, err := m.CreateAPIKey(viewer.ID, CreateAPIKeyRequest{Name: "ro"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	withMD := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		code     codes.Code
+		wantUser string
+	}{
+		{"unlisted method passes through", context.Background(), "/connectors.ConnectorsService/ListConnectors", codes.OK, ""},
+		{"missing credentials", context.Background(), "/test.Service/Read", codes.Unauthenticated, ""},
+		{"malformed authorization", withMD("authorization", login.Token), "/test.Service/Read", codes.Unauthenticated, ""},
+		{"invalid token", withMD("authorization", "Bearer nope"), "/test.Service/Read", codes.Unauthenticated, ""},
+		{"bearer token", withMD("authorization", "Bearer "+login.Token), "/test.Service/Delete", codes.OK, "user-admin"},
+		{"invalid API key", withMD("x-api-key", "nope"), "/test.Service/Read", codes.Unauthenticated, ""},
+		{"API key with role permissions", withMD("x-api-key", key.Key), "/test.Service/Read", codes.OK, viewer.ID},
+		{"API key lacking permission", withMD("x-api-key", key.Key), "/test.Service/Delete", codes.PermissionDenied, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := callUnary(m, tt.ctx, tt.method)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %v, want %v (err %v)", code, tt.code, err)
+			}
+			if tt.wantUser == "" {
+				if err == nil && id != nil {
+					t.Errorf("identity = %+v, want none", id)
+				}
+				return
+			}
+			if id == nil || id.UserID != tt.wantUser {
+				t.Errorf("identity = %+v, want user %s", id, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_APIKeyOrg(t *testing.T) {
+	m := NewManager("test-secret")
+	key, err := m.CreateAPIKey("user-admin", CreateAPIKeyRequest{Name: "scoped", OrgID: "org-a"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	m.SetOrgResolver(func(userID, role, requested string) (string, string, error) {
+		return requested, "", nil
+	})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key.Key, "x-loom-org", "org-b"))
+	if _, err := callUnary(m, ctx, "/test.Service/Read"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("other org: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key.Key))
+	id, err := callUnary(m, ctx, "/test.Service/Read")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id.OrgID != "org-a" {
+		t.Errorf("OrgID = %q, want org-a", id.OrgID)
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	m := NewManager("test-secret")
+	login, err := m.Login("admin", "admin")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	interceptor := m.StreamServerInterceptor(grpcPermissions)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Read", IsServerStream: true}
+
+	err = interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, func(interface{}, grpc.ServerStream) error {
+		t.Fatal("handler called without credentials")
+		return nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+login.Token))
+	var got *Identity
+	err = interceptor(nil, &fakeServerStream{ctx: ctx}, info, func(_ interface{}, ss grpc.ServerStream) error {
+		got = IdentityFromContext(ss.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || got.Username != "admin" {
+		t.Errorf("identity = %+v, want admin", got)
+	}
+}
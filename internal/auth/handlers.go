@@ -3,6 +3,7 @@ package auth
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 // Handlers provides HTTP handlers for auth operations
@@ -94,6 +95,9 @@ func (h *Handlers) HandleAPIKeys(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		if req.OrgID == "" {
+			req.OrgID = GetOrgIDFromRequest(r)
+		}
 		resp, err := h.manager.CreateAPIKey(userID, req)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -226,6 +230,79 @@ func (h *Handlers) HandleListUsers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleUserByID handles GET and PATCH /auth/users/{id} (admin only).
+// PATCH accepts {"role": "..."} to assign a role.
+func (h *Handlers) HandleUserByID(w http.ResponseWriter, r *http.Request) {
+	// Check admin permission
+	role := GetRoleFromRequest(r)
+	if role != "admin" {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+
+	// Extract user ID from path: /api/v1/auth/users/{id}
+	userID := strings.TrimPrefix(r.URL.Path, "/api/v1/auth/users/")
+	if userID == "" || strings.Contains(userID, "/") {
+		http.Error(w, "Missing user ID", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		user *User
+		err  error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		user, err = h.manager.GetUser(userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	case http.MethodPatch, http.MethodPut:
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Role == "" {
+			http.Error(w, "Invalid request body: role is required", http.StatusBadRequest)
+			return
+		}
+		if userID == GetUserIDFromRequest(r) && req.Role != "admin" {
+			http.Error(w, "Admins cannot demote themselves", http.StatusBadRequest)
+			return
+		}
+		user, err = h.manager.SetUserRole(userID, req.Role)
+		if err != nil {
+			status := http.StatusBadRequest
+			if err.Error() == "user not found" {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// HandleListRoles handles GET /auth/roles
+func (h *Handlers) HandleListRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.manager.ListRoles()); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 // HandleHealthCheck handles GET /health (no auth required)
 func (h *Handlers) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
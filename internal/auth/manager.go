@@ -4,7 +4,9 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,12 +16,43 @@ import (
 // Manager handles authentication and authorization
 type Manager struct {
 	jwtSecret string
+	usersMu   sync.RWMutex       // guards users and passwords
 	users     map[string]*User   // userID -> User
-	tokens    map[string]*Token  // tokenID -> Token
+	tokens    map[string]*Token  // tokenID -> Token (login sessions)
 	apiKeys   map[string]*APIKey // keyID -> APIKey
 	passwords map[string]string  // userID -> password hash
 	roles     map[string]Role    // roleName -> Role
 	tokenTTL  time.Duration
+
+	sessionsMu sync.Mutex // guards tokens
+
+	tokensMu   sync.Mutex
+	apiTokens  map[string]*APIToken // tokenID -> APIToken
+	tokenStore TokenStore
+
+	userStore UserStore
+
+	orgResolver OrgResolver
+}
+
+// OrgResolver decides which organization, and team within it, a user acts
+// in. requested is the organization the caller asked for, or "" for the
+// user's default. It returns an error if the user may not act there.
+type OrgResolver func(userID, role, requested string) (orgID, teamID string, err error)
+
+// SetOrgResolver enables organization scoping: authenticated requests get
+// the organization they act in, and API keys are limited to one.
+func (m *Manager) SetOrgResolver(resolver OrgResolver) {
+	m.orgResolver = resolver
+}
+
+// ResolveOrg returns the organization and team a user acts in, or empty
+// strings when organization scoping is not enabled.
+func (m *Manager) ResolveOrg(userID, role, requested string) (string, string, error) {
+	if m.orgResolver == nil {
+		return "", "", nil
+	}
+	return m.orgResolver(userID, role, requested)
 }
 
 // NewManager creates a new auth manager
@@ -38,6 +71,7 @@ func NewManager(jwtSecret string) *Manager {
 		passwords: make(map[string]string),
 		roles:     make(map[string]Role),
 		tokenTTL:  24 * time.Hour,
+		apiTokens: make(map[string]*APIToken),
 	}
 
 	// Initialize predefined roles
@@ -67,6 +101,7 @@ func NewManager(jwtSecret string) *Manager {
 // Login authenticates a user and returns a token
 func (m *Manager) Login(username, password string) (*LoginResponse, error) {
 	// Find user by username
+	m.usersMu.RLock()
 	var user *User
 	for _, u := range m.users {
 		if u.Username == username && u.IsActive {
@@ -74,13 +109,14 @@ func (m *Manager) Login(username, password string) (*LoginResponse, error) {
 			break
 		}
 	}
-
-	if user == nil {
-		return nil, fmt.Errorf("invalid username or password")
+	var passwordHash string
+	exists := false
+	if user != nil {
+		passwordHash, exists = m.passwords[user.ID]
 	}
+	m.usersMu.RUnlock()
 
 	// Verify password
-	passwordHash, exists := m.passwords[user.ID]
 	if !exists {
 		return nil, fmt.Errorf("invalid username or password")
 	}
@@ -89,8 +125,15 @@ func (m *Manager) Login(username, password string) (*LoginResponse, error) {
 		return nil, fmt.Errorf("invalid username or password")
 	}
 
+	m.usersMu.Lock()
+	now := time.Now()
+	user.LastLoginAt = &now
+	m.persistUser(user)
+	copied := *user
+	m.usersMu.Unlock()
+
 	// Generate JWT token
-	token, err := m.GenerateToken(user)
+	token, err := m.GenerateToken(&copied)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +141,7 @@ func (m *Manager) Login(username, password string) (*LoginResponse, error) {
 	return &LoginResponse{
 		Token:     token,
 		ExpiresIn: int64(m.tokenTTL.Seconds()),
-		User:      *user,
+		User:      copied,
 	}, nil
 }
 
@@ -112,6 +155,7 @@ func (m *Manager) GenerateToken(user *User) (string, error) {
 
 	now := time.Now()
 	expiresAt := now.Add(m.tokenTTL)
+	tokenID := generateRandomID()
 
 	claims := &Claims{
 		UserID:      user.ID,
@@ -123,6 +167,7 @@ func (m *Manager) GenerateToken(user *User) (string, error) {
 			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "loom",
 			Subject:   user.ID,
+			ID:        tokenID,
 		},
 	}
 
@@ -132,8 +177,14 @@ func (m *Manager) GenerateToken(user *User) (string, error) {
 		return "", err
 	}
 
-	// Store token for revocation
-	tokenID := generateRandomID()
+	// Store token for revocation, dropping sessions that have expired
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	for id, t := range m.tokens {
+		if now.After(t.ExpiresAt) {
+			delete(m.tokens, id)
+		}
+	}
 	m.tokens[tokenID] = &Token{
 		ID:        tokenID,
 		UserID:    user.ID,
@@ -168,16 +219,26 @@ func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 		}
 	}
 
+	if err := m.checkSession(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
 // CreateAPIKey creates a new API key for a user
 func (m *Manager) CreateAPIKey(userID string, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
-	user, exists := m.users[userID]
+	user, exists := m.lookupUser(userID)
 	if !exists {
 		return nil, fmt.Errorf("user not found")
 	}
 
+	if req.OrgID != "" {
+		if _, _, err := m.ResolveOrg(userID, user.Role, req.OrgID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate API key
 	keyID := generateRandomID()
 	keyValue := generateRandomSecret(32)
@@ -199,6 +260,7 @@ func (m *Manager) CreateAPIKey(userID string, req CreateAPIKeyRequest) (*CreateA
 		KeyPrefix:   keyPrefix,
 		KeyHash:     string(keyHash),
 		Permissions: req.Permissions,
+		OrgID:       req.OrgID,
 		IsActive:    true,
 		ExpiresAt:   expiresAtValue,
 		CreatedAt:   time.Now(),
@@ -212,6 +274,7 @@ func (m *Manager) CreateAPIKey(userID string, req CreateAPIKeyRequest) (*CreateA
 		ID:        keyID,
 		Name:      req.Name,
 		Key:       keyValue, // Only returned once!
+		OrgID:     req.OrgID,
 		ExpiresAt: expiresAt,
 	}, nil
 }
@@ -239,6 +302,15 @@ func (m *Manager) RevokeAPIKey(keyID, userID string) error {
 
 // ValidateAPIKey validates an API key and returns the user and permissions
 func (m *Manager) ValidateAPIKey(keyValue string) (string, []string, error) {
+	apiKey, err := m.lookupAPIKey(keyValue)
+	if err != nil {
+		return "", nil, err
+	}
+	return apiKey.UserID, apiKey.Permissions, nil
+}
+
+// lookupAPIKey returns the active, unexpired key matching keyValue
+func (m *Manager) lookupAPIKey(keyValue string) (*APIKey, error) {
 	// Find API key by hashing the provided value
 	for _, apiKey := range m.apiKeys {
 		if !apiKey.IsActive {
@@ -258,14 +330,16 @@ func (m *Manager) ValidateAPIKey(keyValue string) (string, []string, error) {
 		// Update last used
 		apiKey.LastUsed = time.Now()
 
-		return apiKey.UserID, apiKey.Permissions, nil
+		return apiKey, nil
 	}
 
-	return "", nil, fmt.Errorf("invalid API key")
+	return nil, fmt.Errorf("invalid API key")
 }
 
 // ChangePassword changes a user's password
 func (m *Manager) ChangePassword(userID, oldPassword, newPassword string) error {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
 	user, exists := m.users[userID]
 	if !exists {
 		return fmt.Errorf("user not found")
@@ -287,8 +361,15 @@ func (m *Manager) ChangePassword(userID, oldPassword, newPassword string) error
 		return err
 	}
 
+	if m.userStore != nil {
+		if err := m.userStore.SetPassword(userID, string(newHash)); err != nil {
+			return fmt.Errorf("failed to save password: %w", err)
+		}
+	}
 	m.passwords[userID] = string(newHash)
+	user.PasswordResetRequired = false
 	user.UpdatedAt = time.Now()
+	m.persistUser(user)
 
 	log.Printf("Password changed for user %s", user.Username)
 	return nil
@@ -296,6 +377,9 @@ func (m *Manager) ChangePassword(userID, oldPassword, newPassword string) error
 
 // CreateUser creates a new user
 func (m *Manager) CreateUser(username, email, role, password string) (*User, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+
 	// Check if username already exists
 	for _, u := range m.users {
 		if u.Username == username {
@@ -321,6 +405,14 @@ func (m *Manager) CreateUser(username, email, role, password string) (*User, err
 
 	// Hash password
 	passwordHash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if m.userStore != nil {
+		if err := m.userStore.SaveUser(user); err != nil {
+			return nil, fmt.Errorf("failed to save user: %w", err)
+		}
+		if err := m.userStore.SetPassword(userID, string(passwordHash)); err != nil {
+			return nil, fmt.Errorf("failed to save password: %w", err)
+		}
+	}
 	m.passwords[userID] = string(passwordHash)
 
 	m.users[userID] = user
@@ -331,25 +423,81 @@ func (m *Manager) CreateUser(username, email, role, password string) (*User, err
 
 // GetUser retrieves a user by ID
 func (m *Manager) GetUser(userID string) (*User, error) {
-	user, exists := m.users[userID]
+	user, exists := m.lookupUser(userID)
 	if !exists {
 		return nil, fmt.Errorf("user not found")
 	}
 	return user, nil
 }
 
-// ListUsers lists all users
+// ListUsers lists all users, sorted by username
 func (m *Manager) ListUsers() []*User {
+	m.usersMu.RLock()
 	var users []*User
 	for _, u := range m.users {
 		users = append(users, u)
 	}
+	m.usersMu.RUnlock()
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
 	return users
 }
 
+// SetUserRole assigns a role to a user. Tokens issued before the change keep
+// their old permissions until they expire or are refreshed.
+func (m *Manager) SetUserRole(userID, role string) (*User, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+	user, exists := m.users[userID]
+	if !exists {
+		return nil, fmt.Errorf("user not found")
+	}
+	if _, exists := m.roles[role]; !exists {
+		return nil, fmt.Errorf("unknown role: %s", role)
+	}
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	m.persistUser(user)
+
+	log.Printf("Assigned role %s to user %s", role, user.Username)
+	return user, nil
+}
+
+// lookupUser returns the user with userID.
+func (m *Manager) lookupUser(userID string) (*User, bool) {
+	m.usersMu.RLock()
+	defer m.usersMu.RUnlock()
+	user, exists := m.users[userID]
+	return user, exists
+}
+
+// ListRoles returns all known roles, sorted by name
+func (m *Manager) ListRoles() []Role {
+	roles := make([]Role, 0, len(m.roles))
+	for _, r := range m.roles {
+		roles = append(roles, r)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles
+}
+
 // HasPermission checks if a user has a permission
 func (m *Manager) HasPermission(claims *Claims, permission string) bool {
-	for _, p := range claims.Permissions {
+	return PermissionAllows(claims.Permissions, permission)
+}
+
+// RoleAllows reports whether role grants permission.
+func (m *Manager) RoleAllows(role, permission string) bool {
+	r, exists := m.roles[role]
+	return exists && PermissionAllows(r.Permissions, permission)
+}
+
+// PermissionAllows reports whether any of the granted permissions covers the
+// requested one. Grants may use "*" for the resource ("*:read") or the
+// action ("agents:*"); "*:*" covers everything.
+func PermissionAllows(granted []string, permission string) bool {
+	resource, action, _ := strings.Cut(permission, ":")
+	for _, p := range granted {
 		// Check for exact match
 		if p == permission {
 			return true
@@ -358,14 +506,9 @@ func (m *Manager) HasPermission(claims *Claims, permission string) bool {
 		if p == "*:*" {
 			return true
 		}
-		// Check for resource wildcard (e.g., "agents:*")
-		// Extract resource part (before :) and check if permission matches with wildcard
-		parts := strings.Split(permission, ":")
-		if len(parts) == 2 {
-			resourceWildcard := parts[0] + ":*"
-			if p == resourceWildcard {
-				return true
-			}
+		// Check for resource ("agents:*") and action ("*:read") wildcards
+		if p == resource+":*" || p == "*:"+action {
+			return true
 		}
 	}
 	return false
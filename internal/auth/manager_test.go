@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -484,6 +487,12 @@ func TestManager_HasPermission(t *testing.T) {
 		{"viewer has agents:read", "viewer", "agents:read", true},
 		{"viewer cannot write", "viewer", "agents:write", false},
 		{"viewer cannot delete", "viewer", "agents:delete", false},
+		{"viewer reads logs", "viewer", "logs:read", true},
+		{"operator reads anything", "operator", "system:read", true},
+		{"operator writes beads", "operator", "beads:write", true},
+		{"operator cannot delete providers", "operator", "providers:delete", false},
+		{"operator cannot delete projects", "operator", "projects:delete", false},
+		{"operator cannot assign roles", "operator", "users:write", false},
 	}
 
 	for _, tt := range tests {
@@ -508,6 +517,57 @@ func TestManager_HasPermission(t *testing.T) {
 	}
 }
 
+func TestManager_SetUserRole(t *testing.T) {
+	m := NewManager("test-secret")
+	user, _ := m.CreateUser("ops", "ops@example.com", "viewer", "password")
+
+	updated, err := m.SetUserRole(user.ID, "operator")
+	if err != nil {
+		t.Fatalf("SetUserRole failed: %v", err)
+	}
+	if updated.Role != "operator" {
+		t.Errorf("Role = %q, want operator", updated.Role)
+	}
+
+	// New tokens carry the new role's permissions.
+	token, _ := m.GenerateToken(updated)
+	claims, err := m.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if !m.HasPermission(claims, "beads:write") {
+		t.Error("expected operator token to allow beads:write")
+	}
+
+	if _, err := m.SetUserRole(user.ID, "superuser"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+	if _, err := m.SetUserRole("nobody", "viewer"); err == nil {
+		t.Error("expected error for unknown user")
+	}
+}
+
+func TestPermissionAllows(t *testing.T) {
+	tests := []struct {
+		granted    []string
+		permission string
+		want       bool
+	}{
+		{[]string{"beads:read"}, "beads:read", true},
+		{[]string{"beads:read"}, "beads:write", false},
+		{[]string{"beads:*"}, "beads:delete", true},
+		{[]string{"*:read"}, "providers:read", true},
+		{[]string{"*:read"}, "providers:delete", false},
+		{[]string{"*:*"}, "system:admin", true},
+		{nil, "beads:read", false},
+	}
+	for _, tt := range tests {
+		if got := PermissionAllows(tt.granted, tt.permission); got != tt.want {
+			t.Errorf("PermissionAllows(%v, %q) = %v, want %v", tt.granted, tt.permission, got, tt.want)
+		}
+	}
+}
+
 func TestGenerateRandomID(t *testing.T) {
 	id := generateRandomID()
 
@@ -555,7 +615,7 @@ func TestManager_TokenTTL(t *testing.T) {
 func TestManager_PreDefinedRoles(t *testing.T) {
 	m := NewManager("test-secret")
 
-	expectedRoles := []string{"admin", "user", "viewer", "service"}
+	expectedRoles := []string{"admin", "operator", "user", "viewer", "service"}
 
 	for _, roleName := range expectedRoles {
 		role, exists := m.roles[roleName]
@@ -893,3 +953,52 @@ func TestManager_CreateAPIKeyWithCustomPermissions(t *testing.T) {
 		}
 	}
 }
+
+func TestManager_APIKeyOrgScope(t *testing.T) {
+	m := NewManager("test-secret")
+	m.SetOrgResolver(func(userID, role, requested string) (string, string, error) {
+		if requested == "" {
+			return "default", "", nil
+		}
+		if requested != "acme" && requested != "globex" {
+			return "", "", errors.New("no such organization")
+		}
+		return requested, "", nil
+	})
+
+	if _, err := m.CreateAPIKey("user-admin", CreateAPIKeyRequest{Name: "bad", OrgID: "nope"}); err == nil {
+		t.Error("CreateAPIKey with an unknown organization should fail")
+	}
+	resp, err := m.CreateAPIKey("user-admin", CreateAPIKeyRequest{Name: "acme-key", OrgID: "acme"})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if resp.OrgID != "acme" {
+		t.Errorf("OrgID = %q, want acme", resp.OrgID)
+	}
+
+	var gotOrg string
+	handler := m.RouteMiddleware(func(*http.Request) string { return "" })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOrg = GetOrgIDFromRequest(r)
+	}))
+	tests := []struct {
+		requested string
+		want      int
+		wantOrg   string
+	}{
+		{"", http.StatusOK, "acme"},
+		{"acme", http.StatusOK, "acme"},
+		{"globex", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		gotOrg = ""
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)
+		req.Header.Set("X-API-Key", resp.Key)
+		req.Header.Set(OrgRequestHeader, tt.requested)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want || gotOrg != tt.wantOrg {
+			t.Errorf("requested %q: got %d in %q, want %d in %q", tt.requested, w.Code, gotOrg, tt.want, tt.wantOrg)
+		}
+	}
+}
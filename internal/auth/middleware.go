@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,8 +9,17 @@ import (
 
 // Middleware wraps an HTTP handler with authentication
 func (m *Manager) Middleware(requiredPermission string) func(http.Handler) http.Handler {
+	return m.RouteMiddleware(func(*http.Request) string { return requiredPermission })
+}
+
+// RouteMiddleware wraps an HTTP handler with authentication and checks the
+// permission permissionFor returns for each request. An empty permission
+// only requires the caller to be authenticated.
+func (m *Manager) RouteMiddleware(permissionFor func(*http.Request) string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requiredPermission := permissionFor(r)
+
 			// Get token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -19,31 +29,62 @@ func (m *Manager) Middleware(requiredPermission string) func(http.Handler) http.
 					http.Error(w, "Missing authorization header", http.StatusUnauthorized)
 					return
 				}
+				if strings.HasPrefix(apiKey, TokenPrefix) {
+					m.serveToken(w, r, next, apiKey, requiredPermission)
+					return
+				}
 
 				// Validate API key
-				userID, permissions, err := m.ValidateAPIKey(apiKey)
+				key, err := m.lookupAPIKey(apiKey)
 				if err != nil {
 					http.Error(w, "Invalid API key", http.StatusUnauthorized)
 					return
 				}
+				userID, permissions := key.UserID, key.Permissions
+
+				// Keys created without explicit permissions act with the
+				// permissions of the owner's role.
+				user, _ := m.GetUser(userID)
+				if user != nil && !user.IsActive {
+					http.Error(w, "API key owner is not active", http.StatusUnauthorized)
+					return
+				}
+				if len(permissions) == 0 && user != nil {
+					permissions = m.roles[user.Role].Permissions
+				}
 
 				// Check permission
-				if requiredPermission != "" {
-					hasPermission := false
-					for _, p := range permissions {
-						if p == requiredPermission || p == "*:*" {
-							hasPermission = true
-							break
-						}
-					}
-					if !hasPermission {
-						http.Error(w, "Insufficient permissions", http.StatusForbidden)
+				if requiredPermission != "" && !PermissionAllows(permissions, requiredPermission) {
+					http.Error(w, "Insufficient permissions", http.StatusForbidden)
+					return
+				}
+
+				// A key limited to an organization can't be used in another.
+				requested := r.Header.Get(OrgRequestHeader)
+				if key.OrgID != "" {
+					if requested != "" && requested != key.OrgID {
+						http.Error(w, "API key is limited to organization "+key.OrgID, http.StatusForbidden)
 						return
 					}
+					requested = key.OrgID
+				}
+				role := ""
+				if user != nil {
+					role = user.Role
+				}
+				if !m.setOrgHeaders(w, r, userID, role, requested) {
+					return
 				}
 
-				// Store userID in context
+				// Store identity for downstream handlers, overwriting anything
+				// the client sent in these headers.
 				r.Header.Set("X-User-ID", userID)
+				r.Header.Del("X-Username")
+				r.Header.Del("X-Role")
+				if user != nil {
+					r.Header.Set("X-Username", user.Username)
+					r.Header.Set("X-Role", user.Role)
+				}
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -56,6 +97,10 @@ func (m *Manager) Middleware(requiredPermission string) func(http.Handler) http.
 			}
 
 			tokenString := parts[1]
+			if strings.HasPrefix(tokenString, TokenPrefix) {
+				m.serveToken(w, r, next, tokenString, requiredPermission)
+				return
+			}
 
 			// Validate token
 			claims, err := m.ValidateToken(tokenString)
@@ -64,12 +109,23 @@ func (m *Manager) Middleware(requiredPermission string) func(http.Handler) http.
 				return
 			}
 
+			// Until a reset password is changed, the session can only be
+			// used to change it.
+			if m.PasswordResetPending(claims.UserID) && !passwordChangePaths[r.URL.Path] {
+				http.Error(w, "Password change required", http.StatusForbidden)
+				return
+			}
+
 			// Check permission
 			if requiredPermission != "" && !m.HasPermission(claims, requiredPermission) {
 				http.Error(w, "Insufficient permissions", http.StatusForbidden)
 				return
 			}
 
+			if !m.setOrgHeaders(w, r, claims.UserID, claims.Role, r.Header.Get(OrgRequestHeader)) {
+				return
+			}
+
 			// Store claims in header for downstream handlers
 			r.Header.Set("X-User-ID", claims.UserID)
 			r.Header.Set("X-Username", claims.Username)
@@ -80,6 +136,69 @@ func (m *Manager) Middleware(requiredPermission string) func(http.Handler) http.
 	}
 }
 
+// passwordChangePaths are the routes a user whose password was reset may
+// call before changing it.
+var passwordChangePaths = map[string]bool{
+	"/api/v1/auth/change-password": true,
+	"/api/v1/auth/me":              true,
+}
+
+// serveToken authenticates a request made with an API token and passes it
+// on to next. Besides the caller, it records the token's ID, scope, and the
+// projects it is limited to for downstream handlers. On routes that need
+// no particular permission, a read token can still only read.
+func (m *Manager) serveToken(w http.ResponseWriter, r *http.Request, next http.Handler, value, requiredPermission string) {
+	t, user, err := m.authenticateToken(value, requiredPermission)
+	if err == nil && t.Scope == ScopeRead && !readOnlyMethods[r.Method] {
+		err = errTokenForbidden
+	}
+	if errors.Is(err, errTokenForbidden) {
+		http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invalid API token: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	role := TokenRole(t.Scope, user.Role)
+	if !m.setOrgHeaders(w, r, user.ID, role, r.Header.Get(OrgRequestHeader)) {
+		return
+	}
+
+	r.Header.Set("X-User-ID", user.ID)
+	r.Header.Set("X-Username", user.Username)
+	r.Header.Set("X-Role", role)
+	r.Header.Set("X-Token-ID", t.ID)
+	r.Header.Set("X-Token-Scope", t.Scope)
+	r.Header.Set("X-Token-Projects", strings.Join(t.ProjectIDs, ","))
+	next.ServeHTTP(w, r)
+}
+
+// readOnlyMethods are the HTTP methods a read token may use.
+var readOnlyMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// OrgRequestHeader is the header clients use to pick the organization a
+// request acts in.
+const OrgRequestHeader = "X-Loom-Org"
+
+// setOrgHeaders resolves the organization the request acts in and stores
+// it for downstream handlers. It responds 403 and returns false if the
+// caller may not act in the requested organization.
+func (m *Manager) setOrgHeaders(w http.ResponseWriter, r *http.Request, userID, role, requested string) bool {
+	orgID, teamID, err := m.ResolveOrg(userID, role, requested)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	r.Header.Set("X-Org-ID", orgID)
+	r.Header.Set("X-Team-ID", teamID)
+	return true
+}
+
 // OptionalAuth wraps a handler with optional authentication
 // (no error if auth fails, but stores claims if successful)
 func (m *Manager) OptionalAuth() func(http.Handler) http.Handler {
@@ -128,3 +247,29 @@ func GetUsernameFromRequest(r *http.Request) string {
 func GetRoleFromRequest(r *http.Request) string {
 	return r.Header.Get("X-Role")
 }
+
+// GetTokenFromRequest returns the ID and scope of the API token a request
+// was made with, or empty strings when it wasn't made with one.
+func GetTokenFromRequest(r *http.Request) (id, scope string) {
+	return r.Header.Get("X-Token-ID"), r.Header.Get("X-Token-Scope")
+}
+
+// GetTokenProjectsFromRequest returns the projects the request's API token
+// is limited to, or nil when it isn't limited.
+func GetTokenProjectsFromRequest(r *http.Request) []string {
+	if v := r.Header.Get("X-Token-Projects"); v != "" {
+		return strings.Split(v, ",")
+	}
+	return nil
+}
+
+// GetOrgIDFromRequest extracts the organization the request acts in. It is
+// empty when organization scoping is not enabled.
+func GetOrgIDFromRequest(r *http.Request) string {
+	return r.Header.Get("X-Org-ID")
+}
+
+// GetTeamIDFromRequest extracts the team the request is limited to, if any
+func GetTeamIDFromRequest(r *http.Request) string {
+	return r.Header.Get("X-Team-ID")
+}
@@ -11,20 +11,31 @@ type User struct {
 	ID        string    `json:"id"`
 	Username  string    `json:"username"`
 	Email     string    `json:"email,omitempty"`
-	Role      string    `json:"role"` // admin, user, viewer, service
+	Role      string    `json:"role"` // admin, operator, user, viewer, service
 	IsActive  bool      `json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// PasswordResetRequired is set when an admin resets the user's
+	// password; the user must change it before doing anything else.
+	PasswordResetRequired bool       `json:"password_reset_required,omitempty"`
+	LastLoginAt           *time.Time `json:"last_login_at,omitempty"`
+	// SessionsRevokedAt invalidates every session issued before it.
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"`
+	// ExternalID identifies a user provisioned through single sign-on at
+	// their identity provider.
+	ExternalID string `json:"external_id,omitempty"`
 }
 
-// Token represents an authentication token
+// Token represents an authentication token: a login session
 type Token struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Token     string    `json:"-"` // Never send to client
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
-	LastUsed  time.Time `json:"last_used,omitempty"`
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Token     string     `json:"-"` // Never send to client
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastUsed  time.Time  `json:"last_used,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
 }
 
 // APIKey represents a service account API key
@@ -35,6 +46,7 @@ type APIKey struct {
 	KeyPrefix   string    `json:"key_prefix"` // First 8 chars for display
 	KeyHash     string    `json:"-"`          // Never send to client
 	Permissions []string  `json:"permissions"`
+	OrgID       string    `json:"org_id,omitempty"` // Organization the key is limited to
 	IsActive    bool      `json:"is_active"`
 	ExpiresAt   time.Time `json:"expires_at,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -43,7 +55,7 @@ type APIKey struct {
 
 // Role defines permissions for users
 type Role struct {
-	Name        string   `json:"name"` // admin, user, viewer, service
+	Name        string   `json:"name"` // admin, operator, user, viewer, service
 	Description string   `json:"description"`
 	Permissions []string `json:"permissions"`
 }
@@ -113,6 +125,7 @@ type CreateAPIKeyRequest struct {
 	Name        string   `json:"name"`
 	Permissions []string `json:"permissions"`
 	ExpiresIn   int64    `json:"expires_in,omitempty"` // seconds, 0 = no expiry
+	OrgID       string   `json:"org_id,omitempty"`     // defaults to the organization of the request
 }
 
 // CreateAPIKeyResponse returns the new API key (only shown once)
@@ -120,6 +133,7 @@ type CreateAPIKeyResponse struct {
 	ID        string     `json:"id"`
 	Name      string     `json:"name"`
 	Key       string     `json:"key"` // Full key - only shown once!
+	OrgID     string     `json:"org_id,omitempty"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
@@ -138,6 +152,22 @@ var PreDefinedRoles = map[string]Role{
 			"*:*", // All permissions
 		},
 	},
+	"operator": {
+		Name:        "operator",
+		Description: "Runs day-to-day work; cannot delete providers or projects, change system settings, or manage users",
+		Permissions: []string{
+			"*:read",
+			"agents:write",
+			"beads:write",
+			"beads:delete",
+			"providers:write",
+			"projects:write",
+			"decisions:write",
+			"workflows:write",
+			"logs:write",
+			"repl:use",
+		},
+	},
 	"user": {
 		Name:        "user",
 		Description: "Read and write access to most resources",
@@ -152,6 +182,9 @@ var PreDefinedRoles = map[string]Role{
 			"projects:write",
 			"decisions:read",
 			"decisions:write",
+			"workflows:read",
+			"workflows:write",
+			"logs:read",
 			"repl:use",
 		},
 	},
@@ -164,6 +197,8 @@ var PreDefinedRoles = map[string]Role{
 			"providers:read",
 			"projects:read",
 			"decisions:read",
+			"workflows:read",
+			"logs:read",
 		},
 	},
 	"service": {
@@ -208,8 +243,23 @@ var PreDefinedPermissions = []Permission{
 	{Name: "decisions:delete", Resource: "decisions", Action: "delete", Description: "Delete decisions"},
 	{Name: "decisions:admin", Resource: "decisions", Action: "admin", Description: "Admin access to decisions"},
 
+	// Workflows
+	{Name: "workflows:read", Resource: "workflows", Action: "read", Description: "Read workflows and executions"},
+	{Name: "workflows:write", Resource: "workflows", Action: "write", Description: "Create/start workflows"},
+	{Name: "workflows:delete", Resource: "workflows", Action: "delete", Description: "Delete workflows"},
+
+	// Logs, events, and analytics
+	{Name: "logs:read", Resource: "logs", Action: "read", Description: "Read logs, events, and analytics"},
+	{Name: "logs:write", Resource: "logs", Action: "write", Description: "Acknowledge notifications and annotate logs"},
+
+	// Users
+	{Name: "users:read", Resource: "users", Action: "read", Description: "List users"},
+	{Name: "users:write", Resource: "users", Action: "write", Description: "Create users and assign roles"},
+
 	// System
 	{Name: "repl:use", Resource: "repl", Action: "write", Description: "Use CEO REPL"},
+	{Name: "system:read", Resource: "system", Action: "read", Description: "Read configuration and system state"},
+	{Name: "system:write", Resource: "system", Action: "write", Description: "Change configuration, import and export data"},
 	{Name: "system:admin", Resource: "system", Action: "admin", Description: "Full system administration"},
 
 	// Catch-all
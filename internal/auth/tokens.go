@@ -0,0 +1,360 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokenPrefix starts every API token value, so tokens can be told apart
+// from JWTs and API keys wherever they are presented.
+const TokenPrefix = "loom_"
+
+// Token scopes. A token acts with the permissions of its scope, limited to
+// those of its owner's role.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// scopePermissions are the permissions each scope grants.
+var scopePermissions = map[string][]string{
+	ScopeRead:  {"*:read"},
+	ScopeWrite: {"*:read", "*:write", "*:delete", "repl:use"},
+	ScopeAdmin: {"*:*"},
+}
+
+// lastUsedInterval is how often a token's last use is written to the
+// store; uses in between only update it in memory.
+const lastUsedInterval = time.Minute
+
+// APIToken is a named API token with a scope, an optional expiry, and an
+// optional list of projects it is limited to.
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id"`
+	Prefix     string     `json:"prefix"` // First characters of the value, for display
+	Hash       string     `json:"-"`      // SHA-256 of the value; never sent to clients
+	Scope      string     `json:"scope"`
+	ProjectIDs []string   `json:"project_ids,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+
+	lastStored time.Time // when LastUsedAt was last written to the store
+}
+
+// Active reports whether the token can still be used at now.
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// AllowsProject reports whether the token may be used for projectID.
+func (t *APIToken) AllowsProject(projectID string) bool {
+	if len(t.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range t.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTokenRequest represents an API token creation request
+type CreateTokenRequest struct {
+	Name       string   `json:"name"`
+	Scope      string   `json:"scope"`                 // read, write, or admin
+	ProjectIDs []string `json:"project_ids,omitempty"` // empty = every project
+	TTL        string   `json:"ttl,omitempty"`         // e.g. 720h or 30d; empty = no expiry
+}
+
+// CreateTokenResponse returns the new token (its value is only shown once)
+type CreateTokenResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// TokenStore persists API tokens. Without one, tokens only live as long
+// as the process.
+type TokenStore interface {
+	CreateToken(t *APIToken) error
+	ListTokens() ([]*APIToken, error)
+	TouchToken(id string, usedAt time.Time) error
+	RevokeToken(id string, revokedAt time.Time, revokedBy string) error
+}
+
+// SetTokenStore persists API tokens in store and loads the tokens it
+// already holds.
+func (m *Manager) SetTokenStore(store TokenStore) error {
+	tokens, err := store.ListTokens()
+	if err != nil {
+		return fmt.Errorf("failed to load API tokens: %w", err)
+	}
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	m.tokenStore = store
+	for _, t := range tokens {
+		m.apiTokens[t.ID] = t
+	}
+	return nil
+}
+
+// ParseTTL parses a token lifetime. On top of Go durations, a "d" suffix
+// counts days. An empty TTL means the token does not expire.
+func ParseTTL(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("ttl must be a positive duration such as 720h or 30d, got %q", v)
+}
+
+// CreateToken creates an API token for a user. Only admins can create admin
+// tokens; other scopes are narrowed to the user's role when the token is
+// used.
+func (m *Manager) CreateToken(userID string, req CreateTokenRequest) (*CreateTokenResponse, error) {
+	user, exists := m.lookupUser(userID)
+	if !exists {
+		return nil, fmt.Errorf("user not found")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if _, ok := scopePermissions[req.Scope]; !ok {
+		return nil, fmt.Errorf("unknown scope %q: use read, write, or admin", req.Scope)
+	}
+	if req.Scope == ScopeAdmin && !PermissionAllows(m.roles[user.Role].Permissions, "*:*") {
+		return nil, fmt.Errorf("only admins can create admin tokens")
+	}
+	ttl, err := ParseTTL(req.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	value := TokenPrefix + generateRandomSecret(32)
+	now := time.Now().UTC()
+	t := &APIToken{
+		ID:         generateRandomID(),
+		Name:       req.Name,
+		UserID:     userID,
+		Prefix:     value[:len(TokenPrefix)+8],
+		Hash:       hashToken(value),
+		Scope:      req.Scope,
+		ProjectIDs: req.ProjectIDs,
+		CreatedAt:  now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		t.ExpiresAt = &expires
+	}
+
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	if m.tokenStore != nil {
+		if err := m.tokenStore.CreateToken(t); err != nil {
+			return nil, err
+		}
+	}
+	m.apiTokens[t.ID] = t
+
+	log.Printf("Created %s API token %s for user %s", t.Scope, t.Prefix, user.Username)
+	return &CreateTokenResponse{APIToken: *t, Token: value}, nil
+}
+
+// ListTokens returns a user's API tokens, or every user's when userID is
+// empty, oldest first. Revoked and expired tokens are only included with
+// includeInactive.
+func (m *Manager) ListTokens(userID string, includeInactive bool) []*APIToken {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	now := time.Now()
+	tokens := []*APIToken{}
+	for _, t := range m.apiTokens {
+		if userID != "" && t.UserID != userID {
+			continue
+		}
+		if !includeInactive && !t.Active(now) {
+			continue
+		}
+		copied := *t
+		tokens = append(tokens, &copied)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens
+}
+
+// RevokedTokens returns the revocation list: every revoked token, most
+// recently revoked first.
+func (m *Manager) RevokedTokens() []*APIToken {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	tokens := []*APIToken{}
+	for _, t := range m.apiTokens {
+		if t.RevokedAt != nil {
+			copied := *t
+			tokens = append(tokens, &copied)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].RevokedAt.After(*tokens[j].RevokedAt) })
+	return tokens
+}
+
+// GetToken returns an API token by ID. Unless userID is empty, the token
+// must belong to that user.
+func (m *Manager) GetToken(id, userID string) (*APIToken, error) {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	t, exists := m.apiTokens[id]
+	if !exists || (userID != "" && t.UserID != userID) {
+		return nil, fmt.Errorf("API token not found")
+	}
+	copied := *t
+	return &copied, nil
+}
+
+// RevokeToken revokes an API token on behalf of revokedBy. Unless userID
+// is empty, the token must belong to that user.
+func (m *Manager) RevokeToken(id, userID, revokedBy string) error {
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	t, exists := m.apiTokens[id]
+	if !exists || (userID != "" && t.UserID != userID) {
+		return fmt.Errorf("API token not found")
+	}
+	if t.RevokedAt != nil {
+		return fmt.Errorf("API token already revoked")
+	}
+	now := time.Now().UTC()
+	if m.tokenStore != nil {
+		if err := m.tokenStore.RevokeToken(id, now, revokedBy); err != nil {
+			return err
+		}
+	}
+	t.RevokedAt = &now
+	t.RevokedBy = revokedBy
+
+	log.Printf("Revoked API token %s", t.Prefix)
+	return nil
+}
+
+// lookupToken returns the active token with value and records its use.
+func (m *Manager) lookupToken(value string) (*APIToken, error) {
+	hash := hashToken(value)
+	now := time.Now().UTC()
+
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	for _, t := range m.apiTokens {
+		if t.Hash != hash {
+			continue
+		}
+		if t.RevokedAt != nil {
+			return nil, fmt.Errorf("API token revoked")
+		}
+		if !t.Active(now) {
+			return nil, fmt.Errorf("API token expired")
+		}
+		t.LastUsedAt = &now
+		if m.tokenStore != nil && now.Sub(t.lastStored) >= lastUsedInterval {
+			t.lastStored = now
+			if err := m.tokenStore.TouchToken(t.ID, now); err != nil {
+				log.Printf("Failed to record use of API token %s: %v", t.Prefix, err)
+			}
+		}
+		copied := *t
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("invalid API token")
+}
+
+// tokenPermissions returns what a token may do: its scope's permissions
+// that its owner's role also holds.
+func (m *Manager) tokenPermissions(t *APIToken, user *User) []string {
+	if user == nil || !user.IsActive {
+		return nil
+	}
+	role := m.roles[user.Role].Permissions
+	var permissions []string
+	for _, p := range scopePermissions[t.Scope] {
+		if PermissionAllows(role, p) {
+			permissions = append(permissions, p)
+			continue
+		}
+		// A wildcard scope permission the role only partly holds is
+		// narrowed to the role's own permissions it covers.
+		for _, r := range role {
+			if PermissionAllows([]string{p}, r) {
+				permissions = append(permissions, r)
+			}
+		}
+	}
+	return permissions
+}
+
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// errTokenForbidden is returned by authenticateToken when a valid token
+// lacks the permission asked for.
+var errTokenForbidden = errors.New("insufficient permissions")
+
+// authenticateToken resolves an API token value to the token and its
+// owner and checks it holds permission. An empty permission only requires
+// a valid token.
+func (m *Manager) authenticateToken(value, permission string) (*APIToken, *User, error) {
+	t, err := m.lookupToken(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := m.GetUser(t.UserID)
+	if err != nil || !user.IsActive {
+		return nil, nil, fmt.Errorf("API token owner is not active")
+	}
+	if permission != "" && !PermissionAllows(m.tokenPermissions(t, user), permission) {
+		return nil, nil, errTokenForbidden
+	}
+	return t, user, nil
+}
+
+// TokenRole is the role a request made with a token of scope acts as, for
+// handlers that check roles rather than permissions: the owner's role for
+// admin tokens, and at most operator or viewer for write and read tokens.
+func TokenRole(scope, ownerRole string) string {
+	switch scope {
+	case ScopeAdmin:
+		return ownerRole
+	case ScopeWrite:
+		if ownerRole == "admin" {
+			return "operator"
+		}
+		return ownerRole
+	default:
+		return "viewer"
+	}
+}
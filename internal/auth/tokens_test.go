@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// memTokenStore is an in-memory TokenStore.
+type memTokenStore struct {
+	tokens  map[string]*APIToken
+	touched int
+}
+
+func (s *memTokenStore) CreateToken(t *APIToken) error {
+	copied := *t
+	s.tokens[t.ID] = &copied
+	return nil
+}
+
+func (s *memTokenStore) ListTokens() ([]*APIToken, error) {
+	var tokens []*APIToken
+	for _, t := range s.tokens {
+		copied := *t
+		tokens = append(tokens, &copied)
+	}
+	return tokens, nil
+}
+
+func (s *memTokenStore) TouchToken(id string, usedAt time.Time) error {
+	s.touched++
+	s.tokens[id].LastUsedAt = &usedAt
+	return nil
+}
+
+func (s *memTokenStore) RevokeToken(id string, revokedAt time.Time, revokedBy string) error {
+	s.tokens[id].RevokedAt = &revokedAt
+	s.tokens[id].RevokedBy = revokedBy
+	return nil
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTTL(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTTL(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestManager_CreateToken(t *testing.T) {
+	m := NewManager("secret")
+	viewer, err := m.CreateUser("viewer", "", "viewer", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.CreateToken(viewer.ID, CreateTokenRequest{Name: "ci", Scope: ScopeAdmin}); err == nil {
+		t.Error("viewer created an admin token")
+	}
+	if _, err := m.CreateToken(viewer.ID, CreateTokenRequest{Name: "ci", Scope: "root"}); err == nil {
+		t.Error("unknown scope accepted")
+	}
+
+	resp, err := m.CreateToken("user-admin", CreateTokenRequest{Name: "ci", Scope: ScopeRead, TTL: "30d"})
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if len(resp.Token) <= len(TokenPrefix) || resp.Token[:len(TokenPrefix)] != TokenPrefix {
+		t.Errorf("token %q lacks the %q prefix", resp.Token, TokenPrefix)
+	}
+	if resp.ExpiresAt == nil || time.Until(*resp.ExpiresAt) < 29*24*time.Hour {
+		t.Errorf("ExpiresAt = %v, want about 30 days from now", resp.ExpiresAt)
+	}
+	if resp.Hash == resp.Token {
+		t.Error("token stored in the clear")
+	}
+}
+
+func TestTokenMiddleware_Scopes(t *testing.T) {
+	m := NewManager("secret")
+	read, _ := m.CreateToken("user-admin", CreateTokenRequest{Name: "read", Scope: ScopeRead})
+	write, _ := m.CreateToken("user-admin", CreateTokenRequest{Name: "write", Scope: ScopeWrite})
+
+	var gotRole string
+	handler := m.Middleware("beads:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRole = GetRoleFromRequest(r)
+	}))
+	call := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/beads", nil)
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := call("X-API-Key", read.Token); code != http.StatusForbidden {
+		t.Errorf("read token writing: status = %d, want 403", code)
+	}
+	if code := call("Authorization", "Bearer "+write.Token); code != http.StatusOK {
+		t.Errorf("write token writing: status = %d, want 200", code)
+	}
+	// An admin's write token must not pass handlers' admin role checks.
+	if gotRole != "operator" {
+		t.Errorf("role = %q, want operator", gotRole)
+	}
+
+	if err := m.RevokeToken(write.ID, "", "user-admin"); err != nil {
+		t.Fatal(err)
+	}
+	if code := call("X-API-Key", write.Token); code != http.StatusUnauthorized {
+		t.Errorf("revoked token: status = %d, want 401", code)
+	}
+	if revoked := m.RevokedTokens(); len(revoked) != 1 || revoked[0].ID != write.ID {
+		t.Errorf("RevokedTokens() = %v", revoked)
+	}
+}
+
+func TestTokenMiddleware_ReadTokenOnUnguardedRoute(t *testing.T) {
+	m := NewManager("secret")
+	read, _ := m.CreateToken("user-admin", CreateTokenRequest{Name: "read", Scope: ScopeRead})
+
+	// A route outside the RBAC groups only requires authentication.
+	handler := m.Middleware("")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for method, want := range map[string]int{
+		http.MethodGet:    http.StatusOK,
+		http.MethodHead:   http.StatusOK,
+		http.MethodPost:   http.StatusForbidden,
+		http.MethodPut:    http.StatusForbidden,
+		http.MethodDelete: http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(method, "/api/v1/beadsearch", nil)
+		req.Header.Set("Authorization", "Bearer "+read.Token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("read token %s: status = %d, want %d", method, w.Code, want)
+		}
+	}
+}
+
+func TestTokenPermissions_LimitedByRole(t *testing.T) {
+	m := NewManager("secret")
+	viewer, _ := m.CreateUser("viewer", "", "viewer", "pw")
+	resp, err := m.CreateToken(viewer.ID, CreateTokenRequest{Name: "w", Scope: ScopeWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.authenticateToken(resp.Token, "beads:write"); err != errTokenForbidden {
+		t.Errorf("viewer's write token writing: err = %v, want forbidden", err)
+	}
+	if _, _, err := m.authenticateToken(resp.Token, "beads:read"); err != nil {
+		t.Errorf("viewer's write token reading: err = %v", err)
+	}
+}
+
+func TestManager_TokenStore(t *testing.T) {
+	store := &memTokenStore{tokens: map[string]*APIToken{}}
+	m := NewManager("secret")
+	if err := m.SetTokenStore(store); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := m.CreateToken("user-admin", CreateTokenRequest{Name: "ci", Scope: ScopeRead, ProjectIDs: []string{"loom"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := m.lookupToken(resp.Token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.touched != 1 {
+		t.Errorf("last use stored %d times, want once", store.touched)
+	}
+
+	// A new manager on the same store knows the token.
+	m2 := NewManager("secret")
+	if err := m2.SetTokenStore(store); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := m2.lookupToken(resp.Token)
+	if err != nil {
+		t.Fatalf("token not loaded from store: %v", err)
+	}
+	if !tok.AllowsProject("loom") || tok.AllowsProject("other") {
+		t.Errorf("ProjectIDs = %v, want [loom]", tok.ProjectIDs)
+	}
+}
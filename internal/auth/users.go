@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserStore persists users, so they, their passwords, and revoked
+// sessions survive restarts and users provisioned through single sign-on
+// keep the same ID.
+type UserStore interface {
+	// LoadUsers returns every stored user and, by user ID, the password
+	// hashes of those that have one.
+	LoadUsers() ([]*User, map[string]string, error)
+	// GetUserByExternalID returns nil, nil when there is no such user.
+	GetUserByExternalID(externalID string) (*User, error)
+	// SaveUser creates or updates a user, leaving the password alone.
+	SaveUser(user *User) error
+	SetPassword(userID, hash string) error
+	// RevokeSession records that a login session was revoked.
+	RevokeSession(session *Token) error
+	// LoadRevokedSessions returns the revoked sessions that haven't
+	// expired.
+	LoadRevokedSessions() ([]*Token, error)
+}
+
+// SetUserStore persists users in store and loads the users and revoked
+// sessions it already holds. A stored user without a password keeps the
+// in-memory one, so the default admin's password works until it is changed.
+func (m *Manager) SetUserStore(store UserStore) error {
+	users, passwords, err := store.LoadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+	revoked, err := store.LoadRevokedSessions()
+	if err != nil {
+		return fmt.Errorf("failed to load revoked sessions: %w", err)
+	}
+
+	m.usersMu.Lock()
+	m.userStore = store
+	for _, u := range users {
+		m.users[u.ID] = u
+		if hash := passwords[u.ID]; hash != "" {
+			m.passwords[u.ID] = hash
+		}
+	}
+	m.usersMu.Unlock()
+
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	for _, t := range revoked {
+		m.tokens[t.ID] = t
+	}
+	return nil
+}
+
+// persistUser saves user to the user store, if there is one. Failures are
+// logged: the change still applies until the next restart. The caller
+// holds usersMu.
+func (m *Manager) persistUser(user *User) {
+	if m.userStore == nil {
+		return
+	}
+	if err := m.userStore.SaveUser(user); err != nil {
+		log.Printf("Failed to save user %s: %v", user.Username, err)
+	}
+}
+
+// SetUserActive enables or disables a user. A disabled user can't sign in,
+// their sessions are revoked, and their API keys and tokens stop working.
+func (m *Manager) SetUserActive(userID string, active bool) (*User, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+	user, exists := m.users[userID]
+	if !exists {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.IsActive == active {
+		return user, nil
+	}
+	user.IsActive = active
+	user.UpdatedAt = time.Now()
+	if !active {
+		m.revokeSessions(user)
+	}
+	m.persistUser(user)
+
+	if active {
+		log.Printf("Enabled user %s", user.Username)
+	} else {
+		log.Printf("Disabled user %s", user.Username)
+	}
+	return user, nil
+}
+
+// ResetPassword sets a temporary password for a user and revokes their
+// sessions. The user must change the password after signing in with it.
+// An empty password generates one. It returns the temporary password.
+func (m *Manager) ResetPassword(userID, password string) (string, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+	user, exists := m.users[userID]
+	if !exists {
+		return "", fmt.Errorf("user not found")
+	}
+	if password == "" {
+		password = GeneratePassword()
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	if m.userStore != nil {
+		if err := m.userStore.SetPassword(userID, string(hash)); err != nil {
+			return "", fmt.Errorf("failed to save password: %w", err)
+		}
+	}
+	m.passwords[userID] = string(hash)
+	user.PasswordResetRequired = true
+	user.UpdatedAt = time.Now()
+	m.revokeSessions(user)
+	m.persistUser(user)
+
+	log.Printf("Password reset for user %s", user.Username)
+	return password, nil
+}
+
+// GeneratePassword returns a random temporary password.
+func GeneratePassword() string {
+	return generateRandomSecret(12)
+}
+
+// PasswordResetPending reports whether a user must change their password
+// before doing anything else.
+func (m *Manager) PasswordResetPending(userID string) bool {
+	m.usersMu.RLock()
+	defer m.usersMu.RUnlock()
+	user, exists := m.users[userID]
+	return exists && user.PasswordResetRequired
+}
+
+// ListSessions returns a user's login sessions that have neither expired
+// nor been revoked, newest first.
+func (m *Manager) ListSessions(userID string) []*Token {
+	now := time.Now()
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	sessions := []*Token{}
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.RevokedAt == nil && now.Before(t.ExpiresAt) {
+			copied := *t
+			sessions = append(sessions, &copied)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return sessions
+}
+
+// RevokeSession ends one of a user's login sessions. The revocation is
+// stored, so the session stays revoked after a restart.
+func (m *Manager) RevokeSession(userID, sessionID string) error {
+	store := m.store()
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	t, exists := m.tokens[sessionID]
+	if !exists || t.UserID != userID || t.RevokedAt != nil {
+		return fmt.Errorf("session not found")
+	}
+	now := time.Now()
+	revoked := *t
+	revoked.RevokedAt = &now
+	if store != nil {
+		if err := store.RevokeSession(&revoked); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	t.RevokedAt = &now
+	return nil
+}
+
+// store returns the user store, or nil if there is none.
+func (m *Manager) store() UserStore {
+	m.usersMu.RLock()
+	defer m.usersMu.RUnlock()
+	return m.userStore
+}
+
+// RevokeSessions ends all of a user's login sessions, including ones
+// issued before a restart, and returns how many were active.
+func (m *Manager) RevokeSessions(userID string) (int, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+	user, exists := m.users[userID]
+	if !exists {
+		return 0, fmt.Errorf("user not found")
+	}
+	n := m.revokeSessions(user)
+	m.persistUser(user)
+	log.Printf("Revoked %d sessions of user %s", n, user.Username)
+	return n, nil
+}
+
+// revokeSessions marks the user's sessions revoked and records the time,
+// so tokens this manager never saw are rejected too. The caller holds
+// usersMu and persists the user.
+func (m *Manager) revokeSessions(user *User) int {
+	now := time.Now()
+	user.SessionsRevokedAt = &now
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	n := 0
+	for _, t := range m.tokens {
+		if t.UserID == user.ID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			if now.Before(t.ExpiresAt) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// checkSession rejects tokens of revoked sessions and of disabled users,
+// and records the session's use.
+func (m *Manager) checkSession(claims *Claims) error {
+	m.usersMu.RLock()
+	var active bool
+	var revokedAt *time.Time
+	user, exists := m.users[claims.UserID]
+	if exists {
+		active, revokedAt = user.IsActive, user.SessionsRevokedAt
+	}
+	m.usersMu.RUnlock()
+	if exists {
+		if !active {
+			return fmt.Errorf("user is disabled")
+		}
+		// JWT times have one-second resolution.
+		if revokedAt != nil && claims.IssuedAt != nil &&
+			claims.IssuedAt.Time.Before(revokedAt.Truncate(time.Second)) {
+			return fmt.Errorf("session revoked")
+		}
+	}
+	if claims.ID == "" {
+		return nil
+	}
+	m.sessionsMu.Lock()
+	defer m.sessionsMu.Unlock()
+	if t, exists := m.tokens[claims.ID]; exists {
+		if t.RevokedAt != nil {
+			return fmt.Errorf("session revoked")
+		}
+		t.LastUsed = time.Now()
+	}
+	return nil
+}
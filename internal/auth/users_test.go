@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// memUserStore is an in-memory UserStore.
+type memUserStore struct {
+	users     map[string]User
+	passwords map[string]string
+	revoked   map[string]Token
+}
+
+func newMemUserStore() *memUserStore {
+	return &memUserStore{users: map[string]User{}, passwords: map[string]string{}, revoked: map[string]Token{}}
+}
+
+func (s *memUserStore) LoadUsers() ([]*User, map[string]string, error) {
+	var users []*User
+	for _, u := range s.users {
+		copied := u
+		users = append(users, &copied)
+	}
+	return users, s.passwords, nil
+}
+
+func (s *memUserStore) GetUserByExternalID(externalID string) (*User, error) {
+	for _, u := range s.users {
+		if u.ExternalID == externalID {
+			copied := u
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memUserStore) SaveUser(u *User) error {
+	s.users[u.ID] = *u
+	return nil
+}
+
+func (s *memUserStore) SetPassword(userID, hash string) error {
+	s.passwords[userID] = hash
+	return nil
+}
+
+func (s *memUserStore) RevokeSession(session *Token) error {
+	s.revoked[session.ID] = *session
+	return nil
+}
+
+func (s *memUserStore) LoadRevokedSessions() ([]*Token, error) {
+	var sessions []*Token
+	for _, t := range s.revoked {
+		copied := t
+		sessions = append(sessions, &copied)
+	}
+	return sessions, nil
+}
+
+func TestManager_SetUserActive(t *testing.T) {
+	m := NewManager("secret")
+	user, _ := m.CreateUser("alice", "", "operator", "pw")
+	resp, err := m.Login("alice", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := m.CreateAPIKey(user.ID, CreateAPIKeyRequest{Name: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.SetUserActive(user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ValidateToken(resp.Token); err == nil {
+		t.Error("disabled user's session still valid")
+	}
+	if _, err := m.Login("alice", "pw"); err == nil {
+		t.Error("disabled user signed in")
+	}
+	handler := m.Middleware("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/beads", nil)
+	req.Header.Set("X-API-Key", key.Key)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("disabled user's API key: status = %d, want 401", w.Code)
+	}
+
+	// Re-enabling lets the user sign in again, but not resume old sessions.
+	if _, err := m.SetUserActive(user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ValidateToken(resp.Token); err == nil {
+		t.Error("session revoked by disabling came back")
+	}
+	if _, err := m.Login("alice", "pw"); err != nil {
+		t.Errorf("re-enabled user can't sign in: %v", err)
+	}
+}
+
+func TestManager_ResetPassword(t *testing.T) {
+	store := newMemUserStore()
+	m := NewManager("secret")
+	if err := m.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+	user, _ := m.CreateUser("bob", "", "viewer", "old")
+
+	temp, err := m.ResetPassword(user.ID, "")
+	if err != nil || temp == "" {
+		t.Fatalf("ResetPassword() = %q, %v", temp, err)
+	}
+	resp, err := m.Login("bob", temp)
+	if err != nil {
+		t.Fatalf("login with temporary password: %v", err)
+	}
+	if !resp.User.PasswordResetRequired {
+		t.Error("login response doesn't ask for a password change")
+	}
+
+	handler := m.Middleware("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	call := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+resp.Token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := call("/api/v1/beads"); code != http.StatusForbidden {
+		t.Errorf("before changing the password: status = %d, want 403", code)
+	}
+	if code := call("/api/v1/auth/change-password"); code != http.StatusOK {
+		t.Errorf("change-password: status = %d, want 200", code)
+	}
+
+	if err := m.ChangePassword(user.ID, temp, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if code := call("/api/v1/beads"); code != http.StatusOK {
+		t.Errorf("after changing the password: status = %d, want 200", code)
+	}
+
+	// The new password survives a restart.
+	m2 := NewManager("secret")
+	if err := m2.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m2.Login("bob", "new"); err != nil {
+		t.Errorf("stored password not loaded: %v", err)
+	}
+}
+
+func TestManager_Sessions(t *testing.T) {
+	store := newMemUserStore()
+	m := NewManager("secret")
+	if err := m.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := m.Login("admin", "admin")
+	second, _ := m.Login("admin", "admin")
+
+	sessions := m.ListSessions("user-admin")
+	if len(sessions) != 2 {
+		t.Fatalf("ListSessions() returned %d sessions, want 2", len(sessions))
+	}
+	claims, err := m.ValidateToken(first.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RevokeSession("user-admin", claims.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ValidateToken(first.Token); err == nil {
+		t.Error("revoked session still valid")
+	}
+	if _, err := m.ValidateToken(second.Token); err != nil {
+		t.Errorf("other session revoked too: %v", err)
+	}
+
+	// The revocation holds after a restart.
+	restarted := NewManager("secret")
+	if err := restarted.SetUserStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restarted.ValidateToken(first.Token); err == nil {
+		t.Error("revoked session valid again after a restart")
+	}
+	if _, err := restarted.ValidateToken(second.Token); err != nil {
+		t.Errorf("other session revoked after a restart: %v", err)
+	}
+
+	if n, err := m.RevokeSessions("user-admin"); err != nil || n != 1 {
+		t.Errorf("RevokeSessions() = %d, %v; want 1", n, err)
+	}
+	if _, err := m.ValidateToken(second.Token); err == nil {
+		t.Error("session still valid after revoking all")
+	}
+	if len(m.ListSessions("user-admin")) != 0 {
+		t.Error("revoked sessions still listed")
+	}
+}
@@ -0,0 +1,259 @@
+package automerge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/github"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// BeadWriter files merge conflict beads and finds the ones already filed.
+type BeadWriter interface {
+	CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error)
+	GetBeadsByProject(projectID string) ([]*models.Bead, error)
+	UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error)
+}
+
+// Conflict is a file that both a PR's branch and its base branch changed,
+// with the conflicting hunks as git marks them.
+type Conflict struct {
+	Path  string
+	Hunks []string
+}
+
+// ConflictInspector lists the conflicts merging head into base would hit.
+type ConflictInspector func(ctx context.Context, workDir, base, head string) ([]Conflict, error)
+
+const (
+	// conflictPersona is the persona conflict beads are routed to; its
+	// agents write code.
+	conflictPersona = "engineering-manager"
+	// maxConflictAttempts is how many conflict beads are filed for one PR
+	// before the runner gives up and leaves it to a human.
+	maxConflictAttempts = 3
+	// maxConflictFiles and maxHunkBytes bound the bead description.
+	maxConflictFiles = 20
+	maxHunkBytes     = 4000
+)
+
+// SetConflictBeads enables conflict handling: when an agent PR cannot be
+// merged because of conflicts, the runner files a bead asking a coder agent
+// to resolve them, and retries the merge once that bead is closed.
+func (r *Runner) SetConflictBeads(beads BeadWriter) {
+	r.beads = beads
+}
+
+// conflictBeadTitlePrefix is the title prefix of the conflict beads of a PR.
+func conflictBeadTitlePrefix(prNumber int) string {
+	return fmt.Sprintf("[merge-conflict] PR #%d:", prNumber)
+}
+
+// conflictBeads returns the conflict beads filed for a PR.
+func conflictBeads(all []*models.Bead, prNumber int) []*models.Bead {
+	prefix := conflictBeadTitlePrefix(prNumber)
+	var beads []*models.Bead
+	for _, b := range all {
+		if strings.HasPrefix(b.Title, prefix) {
+			beads = append(beads, b)
+		}
+	}
+	return beads
+}
+
+// handleConflict moves a conflicting PR along: it files a conflict bead if
+// none is open, and once the last one is closed retries the merge before
+// filing another. It reports whether the PR was merged.
+func (r *Runner) handleConflict(ctx context.Context, client PRClient, projectID, workDir string, pr github.PullRequest, existing []*models.Bead) bool {
+	filed := conflictBeads(existing, pr.Number)
+	var last *models.Bead
+	for _, b := range filed {
+		if b.Status != models.BeadStatusClosed {
+			return false // a coder is on it
+		}
+		if last == nil || b.CreatedAt.After(last.CreatedAt) {
+			last = b
+		}
+	}
+
+	if last != nil && last.Context["merge_retried"] == "" {
+		log.Printf("[AutoMerge] Conflict bead %s closed, retrying merge of PR #%d for project %s", last.ID, pr.Number, projectID)
+		err := client.MergePR(ctx, pr.Number, r.mergeMethod)
+		outcome := "merged"
+		if err != nil {
+			outcome = "failed"
+		}
+		if _, uerr := r.beads.UpdateBead(last.ID, map[string]interface{}{
+			"context": map[string]string{"merge_retried": outcome},
+		}); uerr != nil {
+			log.Printf("[AutoMerge] Failed to record merge retry on bead %s: %v", last.ID, uerr)
+		}
+		if err == nil {
+			return true
+		}
+		log.Printf("[AutoMerge] PR #%d still does not merge: %v", pr.Number, err)
+	}
+
+	if len(filed) >= maxConflictAttempts {
+		if last != nil && last.Context["merge_retried"] != "given_up" {
+			log.Printf("[AutoMerge] PR #%d for project %s still conflicts after %d conflict beads; leaving it for a human",
+				pr.Number, projectID, len(filed))
+			_, _ = r.beads.UpdateBead(last.ID, map[string]interface{}{
+				"context": map[string]string{"merge_retried": "given_up"},
+			})
+		}
+		return false
+	}
+
+	r.fileConflictBead(ctx, projectID, workDir, pr, len(filed)+1)
+	return false
+}
+
+// fileConflictBead files a high-priority bead asking a coder agent to
+// resolve a PR's merge conflicts.
+func (r *Runner) fileConflictBead(ctx context.Context, projectID, workDir string, pr github.PullRequest, attempt int) {
+	base := pr.BaseRef
+	if base == "" {
+		base = "main"
+	}
+	conflicts, err := r.inspectConflicts(ctx, workDir, base, pr.HeadRef)
+	if err != nil {
+		log.Printf("[AutoMerge] Could not inspect conflicts of PR #%d: %v", pr.Number, err)
+	}
+
+	title := fmt.Sprintf("%s resolve merge conflict in %s", conflictBeadTitlePrefix(pr.Number), pr.HeadRef)
+	bead, err := r.beads.CreateBead(title, conflictDescription(pr, base, conflicts, err), models.BeadPriorityP1, "bug", projectID)
+	if err != nil {
+		log.Printf("[AutoMerge] Failed to file conflict bead for PR #%d: %v", pr.Number, err)
+		return
+	}
+	if _, err := r.beads.UpdateBead(bead.ID, map[string]interface{}{
+		"context": map[string]string{
+			"requires_persona":       conflictPersona,
+			"merge_conflict_pr":      strconv.Itoa(pr.Number),
+			"merge_conflict_attempt": strconv.Itoa(attempt),
+			"pr_url":                 pr.URL,
+			"pr_branch":              pr.HeadRef,
+			"base_branch":            base,
+		},
+	}); err != nil {
+		log.Printf("[AutoMerge] Failed to set context of conflict bead %s: %v", bead.ID, err)
+	}
+	log.Printf("[AutoMerge] Filed conflict bead %s for PR #%d (%d files, attempt %d)", bead.ID, pr.Number, len(conflicts), attempt)
+}
+
+// conflictDescription produces the body of a conflict bead.
+func conflictDescription(pr github.PullRequest, base string, conflicts []Conflict, inspectErr error) string {
+	var sb strings.Builder
+	sb.WriteString("## Merge Conflict\n\n")
+	sb.WriteString(fmt.Sprintf("Pull request #%d (%s) cannot be merged into `%s` because of conflicts.\n\n", pr.Number, pr.Title, base))
+	if pr.URL != "" {
+		sb.WriteString(fmt.Sprintf("**PR**: %s\n", pr.URL))
+	}
+	sb.WriteString(fmt.Sprintf("**Branch**: %s\n**Base**: %s\n\n", pr.HeadRef, base))
+
+	switch {
+	case inspectErr != nil:
+		sb.WriteString(fmt.Sprintf("The conflicting files could not be determined (%v); merge locally to see them.\n\n", inspectErr))
+	case len(conflicts) > 0:
+		sb.WriteString("### Conflicting Files\n\n")
+		for i, c := range conflicts {
+			if i == maxConflictFiles {
+				sb.WriteString(fmt.Sprintf("...and %d more files.\n\n", len(conflicts)-i))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("#### %s\n\n```\n", c.Path))
+			written := 0
+			for _, h := range c.Hunks {
+				if written+len(h) > maxHunkBytes {
+					sb.WriteString("... (truncated)\n")
+					break
+				}
+				sb.WriteString(h)
+				written += len(h)
+			}
+			sb.WriteString("```\n\n")
+		}
+	}
+
+	sb.WriteString("### Task\n\n")
+	sb.WriteString(fmt.Sprintf("1. Check out `%s` and merge `origin/%s` into it.\n", pr.HeadRef, base))
+	sb.WriteString("2. Resolve each conflict, keeping the intent of both sides.\n")
+	sb.WriteString("3. Build and run the tests, then commit and push the branch.\n")
+	sb.WriteString("4. Close this bead. The merge is retried automatically once it is closed.\n")
+	return sb.String()
+}
+
+// gitConflicts is the default ConflictInspector. It fetches both branches
+// and asks git merge-tree for the result of merging them, without touching
+// the working tree.
+func gitConflicts(ctx context.Context, workDir, base, head string) ([]Conflict, error) {
+	fetch := exec.CommandContext(ctx, "git", "fetch", "origin",
+		fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", base, base),
+		fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", head, head))
+	fetch.Dir = workDir
+	if out, err := fetch.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git fetch failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	mergeTree := exec.CommandContext(ctx, "git", "merge-tree", "--write-tree", "--name-only", "--no-messages",
+		"origin/"+base, "origin/"+head)
+	mergeTree.Dir = workDir
+	out, err := mergeTree.Output()
+	if err == nil {
+		return nil, nil // merges cleanly
+	}
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		return nil, fmt.Errorf("git merge-tree failed: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	tree := lines[0]
+	var conflicts []Conflict
+	for _, path := range lines[1:] {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		show := exec.CommandContext(ctx, "git", "show", tree+":"+path)
+		show.Dir = workDir
+		content, err := show.Output()
+		if err != nil {
+			// Deleted on one side: there is no merged file to show.
+			conflicts = append(conflicts, Conflict{Path: path})
+			continue
+		}
+		conflicts = append(conflicts, Conflict{Path: path, Hunks: conflictHunks(string(content))})
+	}
+	return conflicts, nil
+}
+
+// conflictHunks extracts the regions between git's conflict markers.
+func conflictHunks(content string) []string {
+	var hunks []string
+	var cur strings.Builder
+	in := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "<<<<<<< ") {
+			in = true
+			cur.Reset()
+		}
+		if in {
+			cur.WriteString(line)
+			cur.WriteByte('\n')
+		}
+		if in && strings.HasPrefix(line, ">>>>>>> ") {
+			hunks = append(hunks, cur.String())
+			in = false
+		}
+	}
+	return hunks
+}
@@ -0,0 +1,188 @@
+package automerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/github"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type mockBeadWriter struct {
+	beads []*models.Bead
+}
+
+func (m *mockBeadWriter) CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error) {
+	b := &models.Bead{
+		ID:          fmt.Sprintf("bead-%d", len(m.beads)+1),
+		Title:       title,
+		Description: description,
+		Priority:    priority,
+		ProjectID:   projectID,
+		Status:      models.BeadStatusOpen,
+		Context:     map[string]string{},
+		CreatedAt:   time.Now().Add(time.Duration(len(m.beads)) * time.Second),
+	}
+	m.beads = append(m.beads, b)
+	return b, nil
+}
+
+func (m *mockBeadWriter) GetBeadsByProject(projectID string) ([]*models.Bead, error) {
+	return m.beads, nil
+}
+
+func (m *mockBeadWriter) UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error) {
+	for _, b := range m.beads {
+		if b.ID == beadID {
+			for k, v := range updates["context"].(map[string]string) {
+				b.Context[k] = v
+			}
+			return b, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func newConflictRunner(client PRClient, beads *mockBeadWriter) *Runner {
+	r := NewRunner(&mockProjectResolver{projects: map[string]string{"proj1": "/tmp/proj1"}})
+	r.clientFactory = func(_ string) PRClient { return client }
+	r.SetConflictBeads(beads)
+	r.inspectConflicts = func(_ context.Context, _, base, head string) ([]Conflict, error) {
+		return []Conflict{{Path: "main.go", Hunks: []string{"<<<<<<< " + base + "\nx\n=======\ny\n>>>>>>> " + head + "\n"}}}, nil
+	}
+	return r
+}
+
+func TestSweep_FilesConflictBeadOnce(t *testing.T) {
+	client := &mockPRClient{
+		prs:      []github.PullRequest{{Number: 7, HeadRef: "agent/bead-1", BaseRef: "main", Mergeable: "CONFLICTING"}},
+		mergeErr: map[int]error{7: errors.New("conflict")},
+	}
+	beads := &mockBeadWriter{}
+	r := newConflictRunner(client, beads)
+
+	r.sweep(context.Background())
+	r.sweep(context.Background())
+
+	if len(beads.beads) != 1 {
+		t.Fatalf("expected one conflict bead, got %d", len(beads.beads))
+	}
+	b := beads.beads[0]
+	if b.Priority != models.BeadPriorityP1 || !strings.Contains(b.Description, "main.go") || !strings.Contains(b.Description, "=======") {
+		t.Errorf("unexpected conflict bead: %+v", b)
+	}
+	if b.Context["requires_persona"] != conflictPersona || b.Context["merge_conflict_pr"] != "7" {
+		t.Errorf("unexpected context: %v", b.Context)
+	}
+	if len(client.getMerged()) != 0 {
+		t.Error("conflicting PR should not be merged while its bead is open")
+	}
+}
+
+func TestSweep_RetriesMergeWhenConflictBeadCloses(t *testing.T) {
+	client := &mockPRClient{prs: []github.PullRequest{{Number: 7, HeadRef: "agent/bead-1", Mergeable: "CONFLICTING"}}}
+	beads := &mockBeadWriter{}
+	r := newConflictRunner(client, beads)
+
+	r.sweep(context.Background())
+	beads.beads[0].Status = models.BeadStatusClosed
+	r.sweep(context.Background())
+
+	if merged := client.getMerged(); len(merged) != 1 || merged[0] != 7 {
+		t.Fatalf("expected PR #7 merged on retry, got %v", merged)
+	}
+	if beads.beads[0].Context["merge_retried"] != "merged" {
+		t.Errorf("retry not recorded: %v", beads.beads[0].Context)
+	}
+}
+
+func TestSweep_GivesUpAfterMaxConflictAttempts(t *testing.T) {
+	client := &mockPRClient{
+		prs:      []github.PullRequest{{Number: 7, HeadRef: "agent/bead-1", Mergeable: "CONFLICTING"}},
+		mergeErr: map[int]error{7: errors.New("conflict")},
+	}
+	beads := &mockBeadWriter{}
+	r := newConflictRunner(client, beads)
+
+	for i := 0; i < maxConflictAttempts+2; i++ {
+		r.sweep(context.Background())
+		for _, b := range beads.beads {
+			b.Status = models.BeadStatusClosed
+		}
+	}
+
+	if len(beads.beads) != maxConflictAttempts {
+		t.Fatalf("expected %d conflict beads, got %d", maxConflictAttempts, len(beads.beads))
+	}
+	if last := beads.beads[len(beads.beads)-1]; last.Context["merge_retried"] != "given_up" {
+		t.Errorf("expected last bead marked given_up, got %v", last.Context)
+	}
+}
+
+func TestConflictHunks(t *testing.T) {
+	content := "a\n<<<<<<< ours\nx\n=======\ny\n>>>>>>> theirs\nb\n<<<<<<< ours\n1\n=======\n2\n>>>>>>> theirs\n"
+	hunks := conflictHunks(content)
+	if len(hunks) != 2 || !strings.HasPrefix(hunks[0], "<<<<<<< ours\nx\n") || strings.Contains(hunks[0], "b\n") {
+		t.Errorf("unexpected hunks: %q", hunks)
+	}
+}
+
+func TestGitConflicts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	origin := filepath.Join(dir, "origin.git")
+	work := filepath.Join(dir, "work")
+	run := func(cwd string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = cwd
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		t.Helper()
+		cmd := exec.Command("sh", "-c", "printf '"+content+"' > f.txt")
+		cmd.Dir = work
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(dir, "init", "-q", "--bare", origin)
+	run(dir, "init", "-q", "-b", "main", work)
+	run(work, "remote", "add", "origin", origin)
+	write("base\\n")
+	run(work, "add", ".")
+	run(work, "commit", "-q", "-m", "base")
+	run(work, "checkout", "-q", "-b", "agent/x")
+	write("theirs\\n")
+	run(work, "commit", "-q", "-am", "theirs")
+	run(work, "checkout", "-q", "main")
+	write("ours\\n")
+	run(work, "commit", "-q", "-am", "ours")
+	run(work, "push", "-q", "origin", "main", "agent/x")
+
+	conflicts, err := gitConflicts(context.Background(), work, "main", "agent/x")
+	if err != nil {
+		if strings.Contains(err.Error(), "merge-tree") {
+			t.Skipf("git merge-tree --write-tree unsupported: %v", err)
+		}
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != "f.txt" || len(conflicts[0].Hunks) != 1 {
+		t.Fatalf("unexpected conflicts: %+v", conflicts)
+	}
+	if !strings.Contains(conflicts[0].Hunks[0], "ours") || !strings.Contains(conflicts[0].Hunks[0], "theirs") {
+		t.Errorf("unexpected hunk: %q", conflicts[0].Hunks[0])
+	}
+}
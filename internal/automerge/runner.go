@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/jordanhubbard/loom/internal/github"
+	"github.com/jordanhubbard/loom/pkg/models"
 )
 
 // ProjectResolver maps project IDs to their working directories.
@@ -31,21 +32,26 @@ func defaultClientFactory(workDir string) PRClient {
 
 // Runner periodically sweeps open PRs across all projects and auto-merges
 // those that meet readiness criteria: approved (or no review policy),
-// mergeable, not draft, and from an agent branch prefix.
+// mergeable, not draft, and from an agent branch prefix. With conflict
+// beads enabled, agent PRs that conflict with their base get a bead asking
+// a coder agent to resolve them.
 type Runner struct {
-	projects      ProjectResolver
-	clientFactory PRClientFactory
-	mergeMethod   string
-	stopCh        chan struct{}
+	projects         ProjectResolver
+	clientFactory    PRClientFactory
+	beads            BeadWriter
+	inspectConflicts ConflictInspector
+	mergeMethod      string
+	stopCh           chan struct{}
 }
 
 // NewRunner creates an auto-merge runner with the default GitHub client factory.
 func NewRunner(projects ProjectResolver) *Runner {
 	return &Runner{
-		projects:      projects,
-		clientFactory: defaultClientFactory,
-		mergeMethod:   "squash",
-		stopCh:        make(chan struct{}),
+		projects:         projects,
+		clientFactory:    defaultClientFactory,
+		inspectConflicts: gitConflicts,
+		mergeMethod:      "squash",
+		stopCh:           make(chan struct{}),
 	}
 }
 
@@ -100,7 +106,24 @@ func (r *Runner) mergeReadyPRs(ctx context.Context, projectID, workDir string) (
 	}
 
 	merged := 0
+	var projectBeads []*models.Bead
+	var beadsErr error
+	beadsLoaded := false
 	for _, pr := range prs {
+		if r.beads != nil && isConflicting(pr) {
+			if !beadsLoaded {
+				projectBeads, beadsErr = r.beads.GetBeadsByProject(projectID)
+				if beadsErr != nil {
+					log.Printf("[AutoMerge] Failed to list beads for project %s: %v", projectID, beadsErr)
+				}
+				beadsLoaded = true
+			}
+			// Without the bead list a conflict bead could be filed twice.
+			if beadsErr == nil && r.handleConflict(ctx, client, projectID, workDir, pr, projectBeads) {
+				merged++
+			}
+			continue
+		}
 		if !isAutoMergeable(pr) {
 			continue
 		}
@@ -142,6 +165,12 @@ func isAutoMergeable(pr github.PullRequest) bool {
 	return true
 }
 
+// isConflicting returns true if a PR is an agent PR, other than a draft,
+// that cannot be merged because of conflicts with its base.
+func isConflicting(pr github.PullRequest) bool {
+	return !pr.IsDraft && pr.Mergeable == "CONFLICTING" && isAgentBranch(pr.HeadRef)
+}
+
 // isAgentBranch returns true if the branch name has an agent-created prefix.
 func isAgentBranch(branch string) bool {
 	prefixes := []string{"loom/", "agent/", "auto/", "fix/", "feat/"}
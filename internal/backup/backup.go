@@ -0,0 +1,722 @@
+// Package backup snapshots the database and each project's beads worktree
+// to object storage on a schedule, and verifies and restores them. A
+// backup is a set of objects under its ID: the database dump, a tarball
+// of each project's beads directory, and a manifest listing them with
+// their SHA-256 digests. The manifest is written last, so a backup
+// without one is incomplete.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/logging"
+	"github.com/jordanhubbard/loom/internal/objectstore"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+const (
+	// DefaultInterval is how often a backup is taken when the config
+	// doesn't say.
+	DefaultInterval = 24 * time.Hour
+	// DefaultRetain is how many backups are kept when the config doesn't
+	// say.
+	DefaultRetain = 7
+)
+
+// lastRunKey is the config_kv key holding when the last scheduled backup
+// ran.
+const lastRunKey = "backup:last_run"
+
+const manifestName = "manifest.json"
+
+// Object formats.
+const (
+	FormatPgDump = "pg_dump" // pg_dump custom format
+	FormatSQLite = "sqlite"  // gzipped SQLite database file
+	FormatTarGz  = "tar.gz"  // gzipped tarball of a directory
+)
+
+// Object is one object of a backup.
+type Object struct {
+	Key    string `json:"key"`
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ProjectBackup is the backup of a project's beads directory.
+type ProjectBackup struct {
+	ProjectID string `json:"project_id"`
+	Files     int    `json:"files"`
+	Object
+}
+
+// Manifest describes a backup.
+type Manifest struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Database  *Object         `json:"database,omitempty"`
+	Projects  []ProjectBackup `json:"projects"`
+	// Errors lists what couldn't be backed up. The rest of the backup is
+	// still good.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Check is the verification of one object.
+type Check struct {
+	Key   string `json:"key"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Verification is the result of verifying a backup.
+type Verification struct {
+	ID     string  `json:"id"`
+	OK     bool    `json:"ok"`
+	Checks []Check `json:"checks"`
+}
+
+// RestoreOptions selects what to restore from a backup.
+type RestoreOptions struct {
+	Database    bool     `json:"database"`
+	Projects    []string `json:"projects,omitempty"`
+	AllProjects bool     `json:"all_projects"`
+}
+
+// RestoreResult is what a restore did.
+type RestoreResult struct {
+	ID       string   `json:"id"`
+	Database bool     `json:"database"`
+	Projects []string `json:"projects"`
+	// Notes says what is left for the operator to do, if anything.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Project is a project whose beads are backed up.
+type Project struct {
+	ID       string
+	BeadsDir string
+}
+
+// Manager takes, verifies and restores backups. One runs at a time.
+type Manager struct {
+	db       *database.Database
+	store    objectstore.Store
+	interval time.Duration
+	retain   int
+	projects func() []Project
+	// restored is called after a project's beads directory is restored.
+	restored func(projectID, backupID string) error
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// NewManager creates a manager for cfg that backs up db and the beads
+// directories projects lists. It returns nil when backups have no
+// destination.
+func NewManager(db *database.Database, cfg config.BackupConfig, projects func() []Project) (*Manager, error) {
+	store, err := objectstore.New(cfg.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("backup destination: %w", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	return newManager(db, store, cfg, projects), nil
+}
+
+func newManager(db *database.Database, store objectstore.Store, cfg config.BackupConfig, projects func() []Project) *Manager {
+	m := &Manager{
+		db:       db,
+		store:    store,
+		interval: cfg.Interval,
+		retain:   cfg.Retain,
+		projects: projects,
+		now:      time.Now,
+	}
+	if m.interval <= 0 {
+		m.interval = DefaultInterval
+	}
+	if m.retain <= 0 {
+		m.retain = DefaultRetain
+	}
+	return m
+}
+
+// SetProjectRestoredHook installs what runs after a project's beads
+// directory is restored, e.g. to reload and commit the beads.
+func (m *Manager) SetProjectRestoredHook(fn func(projectID, backupID string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restored = fn
+}
+
+func logger() *slog.Logger { return logging.For("backup") }
+
+// Run takes a backup every interval until ctx is done. A backup that is
+// overdue, e.g. because loom was down, is taken right away.
+func (m *Manager) Run(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	for {
+		wait := time.Duration(0)
+		if last, ok := m.lastRun(); ok {
+			wait = time.Until(last.Add(m.interval))
+		}
+		timer := time.NewTimer(max(wait, 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := m.Create(ctx); err != nil && ctx.Err() == nil {
+			logger().Error("scheduled backup failed", "error", err)
+		}
+		// Record the attempt even when it failed, so a broken backup
+		// isn't retried in a tight loop.
+		if m.db != nil {
+			_ = m.db.SetConfigValue(lastRunKey, m.now().UTC().Format(time.RFC3339Nano))
+		}
+	}
+}
+
+func (m *Manager) lastRun() (time.Time, bool) {
+	if m.db == nil {
+		return time.Time{}, false
+	}
+	raw, ok, err := m.db.GetConfigValue(lastRunKey)
+	if err != nil || !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	return t, err == nil
+}
+
+// Create takes a backup now. What can't be backed up is listed in the
+// manifest's Errors; Create fails only when nothing could be.
+func (m *Manager) Create(ctx context.Context) (*Manifest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now().UTC()
+	man := &Manifest{ID: now.Format("20060102T150405Z"), CreatedAt: now, Projects: []ProjectBackup{}}
+
+	if m.db != nil {
+		data, format, err := dumpDatabase(ctx, m.db)
+		if err == nil {
+			var obj Object
+			obj, err = m.put(ctx, man.ID, "database."+format, format, data)
+			man.Database = &obj
+		}
+		if err != nil {
+			man.Database = nil
+			man.Errors = append(man.Errors, "database: "+err.Error())
+		}
+	}
+
+	for _, p := range m.projects() {
+		data, files, err := archiveDir(p.BeadsDir)
+		if err == nil {
+			var obj Object
+			obj, err = m.put(ctx, man.ID, "beads/"+p.ID+".tar.gz", FormatTarGz, data)
+			man.Projects = append(man.Projects, ProjectBackup{ProjectID: p.ID, Files: files, Object: obj})
+		}
+		if err != nil {
+			man.Errors = append(man.Errors, "project "+p.ID+": "+err.Error())
+		}
+	}
+
+	if man.Database == nil && len(man.Projects) == 0 {
+		if len(man.Errors) == 0 {
+			return nil, errors.New("nothing to back up")
+		}
+		return nil, fmt.Errorf("backup failed: %s", strings.Join(man.Errors, "; "))
+	}
+	raw, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.store.Put(ctx, man.ID+"/"+manifestName, raw, "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	logger().Info("backup taken", "backup_id", man.ID, "projects", len(man.Projects), "errors", len(man.Errors))
+
+	if err := m.prune(ctx); err != nil {
+		logger().Warn("failed to delete old backups", "error", err)
+	}
+	return man, nil
+}
+
+// put stores one object of a backup and describes it.
+func (m *Manager) put(ctx context.Context, id, name, format string, data []byte) (Object, error) {
+	obj := Object{Key: id + "/" + name, Format: format, Size: int64(len(data)), SHA256: digest(data)}
+	if _, err := m.store.Put(ctx, obj.Key, data, "application/octet-stream"); err != nil {
+		return Object{}, err
+	}
+	return obj, nil
+}
+
+// List returns the complete backups, newest first.
+func (m *Manager) List(ctx context.Context) ([]Manifest, error) {
+	ids, err := m.ids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifests := []Manifest{}
+	for _, id := range ids {
+		man, err := m.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, *man)
+	}
+	return manifests, nil
+}
+
+// ids returns the IDs of the complete backups, newest first.
+func (m *Manager) ids(ctx context.Context) ([]string, error) {
+	keys, err := m.store.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var ids []string
+	for _, key := range keys {
+		if id, ok := strings.CutSuffix(key, "/"+manifestName); ok && !strings.Contains(id, "/") {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// Get returns the manifest of a backup.
+func (m *Manager) Get(ctx context.Context, id string) (*Manifest, error) {
+	if id == "" || strings.ContainsAny(id, "/\\") {
+		return nil, fmt.Errorf("invalid backup ID %q", id)
+	}
+	raw, err := m.store.Get(ctx, id+"/"+manifestName)
+	if err != nil {
+		return nil, err
+	}
+	var man Manifest
+	if err := json.Unmarshal(raw, &man); err != nil {
+		return nil, fmt.Errorf("backup %s has an unreadable manifest: %w", id, err)
+	}
+	return &man, nil
+}
+
+// prune deletes the backups past the newest m.retain.
+func (m *Manager) prune(ctx context.Context) error {
+	ids, err := m.ids(ctx)
+	if err != nil || len(ids) <= m.retain {
+		return err
+	}
+	keys, err := m.store.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, id := range ids[m.retain:] {
+		for _, key := range keys {
+			// The manifest goes last, so an interrupted prune leaves an
+			// incomplete backup rather than a listed one missing objects.
+			if strings.HasPrefix(key, id+"/") && key != id+"/"+manifestName {
+				if err := m.store.Delete(ctx, key); err != nil {
+					return err
+				}
+			}
+		}
+		if err := m.store.Delete(ctx, id+"/"+manifestName); err != nil {
+			return err
+		}
+		logger().Info("deleted old backup", "backup_id", id)
+	}
+	return nil
+}
+
+// Verify checks that every object of a backup is present, matches the
+// digest in the manifest, and can be read in its format.
+func (m *Manager) Verify(ctx context.Context, id string) (*Verification, error) {
+	man, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	v := &Verification{ID: id, OK: true, Checks: []Check{}}
+	for _, obj := range man.objects() {
+		check := Check{Key: obj.Key, OK: true}
+		if _, err := m.fetch(ctx, obj); err != nil {
+			check.OK = false
+			check.Error = err.Error()
+			v.OK = false
+		}
+		v.Checks = append(v.Checks, check)
+	}
+	return v, nil
+}
+
+func (man *Manifest) objects() []Object {
+	var objects []Object
+	if man.Database != nil {
+		objects = append(objects, *man.Database)
+	}
+	for _, p := range man.Projects {
+		objects = append(objects, p.Object)
+	}
+	return objects
+}
+
+// fetch downloads an object and checks it against its manifest entry.
+func (m *Manager) fetch(ctx context.Context, obj Object) ([]byte, error) {
+	data, err := m.store.Get(ctx, obj.Key)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != obj.Size {
+		return nil, fmt.Errorf("size is %d bytes, want %d", len(data), obj.Size)
+	}
+	if got := digest(data); got != obj.SHA256 {
+		return nil, fmt.Errorf("sha256 is %s, want %s", got, obj.SHA256)
+	}
+	if err := checkFormat(obj.Format, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Restore restores what opts selects from a backup. Every selected object
+// is verified before anything is touched. A project's beads directory is
+// replaced whole; a PostgreSQL database is restored with pg_restore, while
+// a SQLite database is staged next to the live file for the operator to
+// swap in with loom stopped.
+func (m *Manager) Restore(ctx context.Context, id string, opts RestoreOptions) (*RestoreResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	man, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Database && !opts.AllProjects && len(opts.Projects) == 0 {
+		return nil, errors.New("nothing to restore: select the database or projects")
+	}
+	if opts.Database && man.Database == nil {
+		return nil, fmt.Errorf("backup %s has no database", id)
+	}
+	if opts.Database && m.db == nil {
+		return nil, errors.New("no database configured")
+	}
+
+	var selected []ProjectBackup
+	for _, p := range man.Projects {
+		if opts.AllProjects || slices.Contains(opts.Projects, p.ProjectID) {
+			selected = append(selected, p)
+		}
+	}
+	for _, pid := range opts.Projects {
+		if !slices.ContainsFunc(selected, func(p ProjectBackup) bool { return p.ProjectID == pid }) {
+			return nil, fmt.Errorf("backup %s has no beads for project %s", id, pid)
+		}
+	}
+	dirs := make(map[string]string)
+	for _, p := range m.projects() {
+		dirs[p.ID] = p.BeadsDir
+	}
+
+	// Verify everything first, so a bad backup changes nothing.
+	data := make(map[string][]byte)
+	for _, p := range selected {
+		if dirs[p.ProjectID] == "" {
+			return nil, fmt.Errorf("project %s no longer exists", p.ProjectID)
+		}
+		if data[p.Key], err = m.fetch(ctx, p.Object); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Key, err)
+		}
+	}
+	if opts.Database {
+		if data[man.Database.Key], err = m.fetch(ctx, *man.Database); err != nil {
+			return nil, fmt.Errorf("%s: %w", man.Database.Key, err)
+		}
+	}
+
+	result := &RestoreResult{ID: id, Projects: []string{}}
+	for _, p := range selected {
+		if err := extractInto(dirs[p.ProjectID], data[p.Key]); err != nil {
+			return result, fmt.Errorf("failed to restore project %s: %w", p.ProjectID, err)
+		}
+		result.Projects = append(result.Projects, p.ProjectID)
+		logger().Info("restored beads", "backup_id", id, "project_id", p.ProjectID)
+		if m.restored != nil {
+			if err := m.restored(p.ProjectID, id); err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("project %s: restored but not reloaded: %v", p.ProjectID, err))
+			}
+		}
+	}
+	if opts.Database {
+		note, err := restoreDatabase(ctx, m.db, man.Database.Format, data[man.Database.Key])
+		if err != nil {
+			return result, fmt.Errorf("failed to restore database: %w", err)
+		}
+		result.Database = true
+		if note != "" {
+			result.Notes = append(result.Notes, note)
+		}
+		logger().Info("restored database", "backup_id", id)
+	}
+	return result, nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// dumpDatabase dumps db and returns the dump and its format.
+func dumpDatabase(ctx context.Context, db *database.Database) ([]byte, string, error) {
+	if db.Dialect() == database.DialectPostgres {
+		cmd := exec.CommandContext(ctx, "pg_dump", "--format=custom", "--no-owner", "--no-privileges", "--dbname="+db.DataSource())
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, "", fmt.Errorf("pg_dump failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return out, FormatPgDump, nil
+	}
+
+	// VACUUM INTO writes a consistent copy without blocking writers for
+	// long.
+	dir, err := os.MkdirTemp("", "loom-backup-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "loom.db")
+	if _, err := db.DB().ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return nil, "", fmt.Errorf("failed to copy SQLite database: %w", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), FormatSQLite, nil
+}
+
+// restoreDatabase restores a dump into db. It returns a note when the
+// operator has to finish the restore.
+func restoreDatabase(ctx context.Context, db *database.Database, format string, data []byte) (string, error) {
+	switch format {
+	case FormatPgDump:
+		if db.Dialect() != database.DialectPostgres {
+			return "", errors.New("the backup is of a PostgreSQL database, but loom uses SQLite")
+		}
+		cmd := exec.CommandContext(ctx, "pg_restore", "--clean", "--if-exists", "--no-owner", "--no-privileges",
+			"--single-transaction", "--dbname="+db.DataSource())
+		cmd.Stdin = bytes.NewReader(data)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("pg_restore failed: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return "", nil
+	case FormatSQLite:
+		if db.Dialect() != database.DialectSQLite {
+			return "", errors.New("the backup is of a SQLite database, but loom uses PostgreSQL")
+		}
+		path := db.DataSource()
+		if path == "" || path == ":memory:" {
+			return "", errors.New("the database is in memory")
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return "", err
+		}
+		staged := path + ".restore"
+		if err := os.WriteFile(staged, raw, 0o600); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("SQLite can't be restored while loom runs: stop loom, replace %s with %s, and start it again", path, staged), nil
+	}
+	return "", fmt.Errorf("unknown database format %q", format)
+}
+
+// checkFormat checks that data can be read as format.
+func checkFormat(format string, data []byte) error {
+	switch format {
+	case FormatPgDump:
+		if !bytes.HasPrefix(data, []byte("PGDMP")) {
+			return errors.New("not a pg_dump archive")
+		}
+	case FormatSQLite:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("not gzipped: %w", err)
+		}
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return fmt.Errorf("corrupt gzip: %w", err)
+		}
+		if !bytes.HasPrefix(raw, []byte("SQLite format 3\x00")) {
+			return errors.New("not a SQLite database")
+		}
+	case FormatTarGz:
+		return walkArchive(data, func(*tar.Header, io.Reader) error { return nil })
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	return nil
+}
+
+// archiveDir returns a gzipped tarball of the regular files under dir and
+// how many there are.
+func archiveDir(dir string) ([]byte, int, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, 0, fmt.Errorf("beads directory: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	files := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Name:    filepath.ToSlash(rel),
+			Mode:    int64(info.Mode().Perm()),
+			Size:    int64(len(content)),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+		files++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), files, nil
+}
+
+// walkArchive calls fn for each file in a gzipped tarball, refusing paths
+// that would escape the directory it is extracted into.
+func walkArchive(data []byte, fn func(*tar.Header, io.Reader) error) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not gzipped: %w", err)
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("corrupt archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(filepath.FromSlash(hdr.Name)) {
+			return fmt.Errorf("unexpected entry %q in archive", hdr.Name)
+		}
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractInto replaces dir with the contents of a gzipped tarball. The
+// archive is extracted next to dir first and swapped in, so a failed
+// restore leaves dir as it was.
+func extractInto(dir string, data []byte) error {
+	staging := dir + ".restore"
+	previous := dir + ".pre-restore"
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	err := walkArchive(data, func(hdr *tar.Header, r io.Reader) error {
+		path := filepath.Join(staging, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, content, fs.FileMode(hdr.Mode).Perm()|0o600)
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		if err := os.Rename(dir, previous); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		_ = os.Rename(previous, dir)
+		return err
+	}
+	return os.RemoveAll(previous)
+}
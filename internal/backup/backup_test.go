@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/objectstore"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func newTestManager(t *testing.T, retain int) (*Manager, string) {
+	t.Helper()
+	db := dbtest.New(t)
+	beads := filepath.Join(t.TempDir(), ".beads")
+	writeFile(t, filepath.Join(beads, "issues.jsonl"), `{"id":"bead-1"}`)
+	writeFile(t, filepath.Join(beads, "config", "beads.yaml"), "prefix: bd\n")
+	projects := func() []Project { return []Project{{ID: "proj-1", BeadsDir: beads}} }
+	store := &objectstore.FileStore{Dir: t.TempDir()}
+	return newManager(db, store, config.BackupConfig{Retain: retain}, projects), beads
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewManagerWithoutDestination(t *testing.T) {
+	if m, err := NewManager(nil, config.BackupConfig{}, nil); m != nil || err != nil {
+		t.Fatalf("NewManager = %v, %v; want nil, nil", m, err)
+	}
+}
+
+func TestCreateVerifyAndRestore(t *testing.T) {
+	m, beads := newTestManager(t, 0)
+	ctx := context.Background()
+
+	man, err := m.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(man.Errors) > 0 {
+		t.Fatalf("backup errors: %v", man.Errors)
+	}
+	if man.Database == nil || man.Database.Format != FormatSQLite {
+		t.Fatalf("database = %+v, want a SQLite dump", man.Database)
+	}
+	if len(man.Projects) != 1 || man.Projects[0].Files != 2 {
+		t.Fatalf("projects = %+v, want proj-1 with 2 files", man.Projects)
+	}
+
+	v, err := m.Verify(ctx, man.ID)
+	if err != nil || !v.OK {
+		t.Fatalf("Verify = %+v, %v; want ok", v, err)
+	}
+
+	writeFile(t, filepath.Join(beads, "issues.jsonl"), `{"id":"bead-2"}`)
+	writeFile(t, filepath.Join(beads, "stray.jsonl"), "")
+	var hooked []string
+	m.SetProjectRestoredHook(func(projectID, backupID string) error {
+		hooked = append(hooked, projectID+"@"+backupID)
+		return nil
+	})
+	result, err := m.Restore(ctx, man.ID, RestoreOptions{Projects: []string{"proj-1"}})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(result.Projects) != 1 || result.Database {
+		t.Errorf("result = %+v, want only proj-1 restored", result)
+	}
+	if got, _ := os.ReadFile(filepath.Join(beads, "issues.jsonl")); string(got) != `{"id":"bead-1"}` {
+		t.Errorf("issues.jsonl = %q, want the backed up content", got)
+	}
+	if _, err := os.Stat(filepath.Join(beads, "stray.jsonl")); !os.IsNotExist(err) {
+		t.Error("a file added after the backup survived the restore")
+	}
+	if len(hooked) != 1 || hooked[0] != "proj-1@"+man.ID {
+		t.Errorf("hook calls = %v", hooked)
+	}
+
+	if _, err := m.Restore(ctx, man.ID, RestoreOptions{Projects: []string{"nope"}}); err == nil {
+		t.Error("restoring a project not in the backup succeeded")
+	}
+	if _, err := m.Restore(ctx, man.ID, RestoreOptions{}); err == nil {
+		t.Error("restoring nothing succeeded")
+	}
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	m, beads := newTestManager(t, 0)
+	ctx := context.Background()
+	man, err := m.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	key := man.Projects[0].Key
+	if _, err := m.store.Put(ctx, key, []byte("not a tarball"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := m.Verify(ctx, man.ID)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if v.OK {
+		t.Fatal("Verify passed a corrupted backup")
+	}
+	for _, c := range v.Checks {
+		if c.Key == key && (c.OK || !strings.Contains(c.Error, "size")) {
+			t.Errorf("check = %+v, want a size mismatch", c)
+		}
+	}
+
+	writeFile(t, filepath.Join(beads, "issues.jsonl"), "changed")
+	if _, err := m.Restore(ctx, man.ID, RestoreOptions{AllProjects: true}); err == nil {
+		t.Fatal("restoring a corrupted backup succeeded")
+	}
+	if got, _ := os.ReadFile(filepath.Join(beads, "issues.jsonl")); string(got) != "changed" {
+		t.Error("a failed restore changed the beads directory")
+	}
+}
+
+func TestCreatePrunesOldBackups(t *testing.T) {
+	m, _ := newTestManager(t, 2)
+	ctx := context.Background()
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i := range 4 {
+		m.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		man, err := m.Create(ctx)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		ids = append(ids, man.ID)
+	}
+
+	list, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != ids[3] || list[1].ID != ids[2] {
+		t.Fatalf("backups = %v, want the newest two, newest first", list)
+	}
+	keys, err := m.store.List(ctx, ids[0]+"/")
+	if err != nil || len(keys) != 0 {
+		t.Errorf("objects of a pruned backup = %v, %v; want none", keys, err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package beadhistory keeps the change log of every bead: each changed
+// field with its old and new value, who changed it, and when. Changes are
+// reported by the beads manager, whoever makes them; entries are never
+// updated or removed.
+package beadhistory
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// defaultListLimit is how many entries List returns when no limit is given.
+const defaultListLimit = 500
+
+// Entry is one changed field of a bead.
+type Entry struct {
+	ID        string    `json:"id"`
+	BeadID    string    `json:"bead_id"`
+	ProjectID string    `json:"project_id,omitempty"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Actor     string    `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Filter selects a bead's entries. Field matches one field, such as
+// "status" or "context.pr_url".
+type Filter struct {
+	Field string
+	Since time.Time
+	Limit int
+}
+
+// Manager records and queries bead history.
+type Manager struct {
+	db *database.Database
+}
+
+// NewManager creates a bead history manager. It returns nil without a
+// database.
+func NewManager(db *database.Database) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db}
+}
+
+// Record stores a change set from the beads manager. It is installed with
+// beads.Manager.SetHistoryRecorder, so failures are logged, not returned:
+// a bead change is never undone for want of its history.
+func (m *Manager) Record(changes []beads.FieldChange) {
+	entries := make([]*database.BeadHistoryEntry, 0, len(changes))
+	for _, c := range changes {
+		entries = append(entries, &database.BeadHistoryEntry{
+			ID:        "bh-" + uuid.New().String(),
+			BeadID:    c.BeadID,
+			ProjectID: c.ProjectID,
+			Field:     c.Field,
+			OldValue:  c.OldValue,
+			NewValue:  c.NewValue,
+			Actor:     c.Actor,
+			ChangedAt: c.ChangedAt.UTC(),
+		})
+	}
+	if err := m.db.CreateBeadHistoryEntries(entries); err != nil {
+		log.Printf("[BeadHistory] %v", err)
+	}
+}
+
+// List returns a bead's history, oldest first.
+func (m *Manager) List(beadID string, f Filter) ([]*Entry, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultListLimit
+	}
+	rows, err := m.db.ListBeadHistory(database.BeadHistoryFilter{
+		BeadID: beadID,
+		Field:  f.Field,
+		Since:  f.Since,
+		Limit:  f.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, &Entry{
+			ID:        r.ID,
+			BeadID:    r.BeadID,
+			ProjectID: r.ProjectID,
+			Field:     r.Field,
+			OldValue:  r.OldValue,
+			NewValue:  r.NewValue,
+			Actor:     r.Actor,
+			ChangedAt: r.ChangedAt,
+		})
+	}
+	return entries, nil
+}
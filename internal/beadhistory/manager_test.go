@@ -0,0 +1,93 @@
+package beadhistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	return NewManager(dbtest.New(t))
+}
+
+func TestRecordsBeadChanges(t *testing.T) {
+	m := newTestManager(t)
+	bm := beads.NewManager("")
+	bm.SetProjectBeadsPath("project1", t.TempDir())
+	bm.SetHistoryRecorder(m.Record)
+
+	bead, _ := bm.CreateBead("Fix login", "", models.BeadPriorityP2, "task", "project1")
+	if err := bm.UpdateBead(bead.ID, map[string]interface{}{
+		"priority":         models.BeadPriorityP0,
+		"context":          map[string]string{"pr_url": "https://example.com/pr/1"},
+		beads.UpdatedByKey: "alice",
+	}); err != nil {
+		t.Fatalf("UpdateBead: %v", err)
+	}
+	if err := bm.ClaimBead(bead.ID, "agent-1"); err != nil {
+		t.Fatalf("ClaimBead: %v", err)
+	}
+	// An update that changes nothing leaves no entries.
+	_ = bm.UpdateBead(bead.ID, map[string]interface{}{"priority": models.BeadPriorityP0})
+
+	entries, err := m.List(bead.ID, Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []struct{ field, old, new, actor string }{
+		{"priority", "2", "0", "alice"},
+		{"context.pr_url", "", "https://example.com/pr/1", "alice"},
+		{"status", "open", "in_progress", "agent-1"},
+		{"assigned_to", "", "agent-1", "agent-1"},
+	}
+	got := make(map[string]*Entry)
+	for _, e := range entries {
+		got[e.Field] = e
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for _, w := range want {
+		e := got[w.field]
+		if e == nil || e.OldValue != w.old || e.NewValue != w.new || e.Actor != w.actor || e.ProjectID != "project1" {
+			t.Errorf("%s entry = %+v, want %s -> %s by %s", w.field, e, w.old, w.new, w.actor)
+		}
+	}
+
+	status, _ := m.List(bead.ID, Filter{Field: "status"})
+	if len(status) != 1 {
+		t.Errorf("field filter returned %d entries, want 1", len(status))
+	}
+	if later, _ := m.List(bead.ID, Filter{Since: time.Now().Add(time.Hour)}); len(later) != 0 {
+		t.Errorf("since filter returned %d entries, want 0", len(later))
+	}
+	if none, _ := m.List("other", Filter{}); len(none) != 0 {
+		t.Errorf("other bead has %d entries", len(none))
+	}
+}
+
+func TestRecordsTrashAndRestore(t *testing.T) {
+	m := newTestManager(t)
+	bm := beads.NewManager("")
+	bm.SetProjectBeadsPath("project1", t.TempDir())
+	bm.SetHistoryRecorder(m.Record)
+
+	bead, _ := bm.CreateBead("Doomed", "", models.BeadPriorityP2, "task", "project1")
+	_, _ = bm.TrashBead(bead.ID, "bob")
+	_, _ = bm.RestoreBead(bead.ID, "carol")
+
+	entries, _ := m.List(bead.ID, Filter{Field: "deleted_at"})
+	if len(entries) != 2 {
+		t.Fatalf("got %d deleted_at entries, want 2", len(entries))
+	}
+	if entries[0].Actor != "bob" || entries[0].NewValue == "" {
+		t.Errorf("trash entry = %+v", entries[0])
+	}
+	if entries[1].Actor != "carol" || entries[1].NewValue != "" {
+		t.Errorf("restore entry = %+v", entries[1])
+	}
+}
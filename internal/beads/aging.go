@@ -0,0 +1,57 @@
+package beads
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// SetPriorityAging installs the lookup of how long a project's beads wait
+// before their priority is raised a level; 0 turns aging off. Without one,
+// beads are ordered by their own priority.
+func (m *Manager) SetPriorityAging(lookup func(projectID string) time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.priorityAging = lookup
+}
+
+// PriorityAging returns the aging interval in effect for a project, or 0
+// when its beads don't age.
+func (m *Manager) PriorityAging(projectID string) time.Duration {
+	m.mu.RLock()
+	lookup := m.priorityAging
+	m.mu.RUnlock()
+	if lookup == nil {
+		return 0
+	}
+	return lookup(projectID)
+}
+
+// EffectivePriority returns the priority a bead is worked at: its own,
+// raised by how long it has waited when its project ages priorities.
+func (m *Manager) EffectivePriority(b *models.Bead) models.BeadPriority {
+	return b.EffectivePriority(m.PriorityAging(b.ProjectID), time.Now())
+}
+
+// SortByPriority orders beads by priority, most urgent first, and beads of
+// the same priority by most recently updated. priority gives each bead's
+// priority; nil uses the beads' own. Nil beads go last.
+func SortByPriority(list []*models.Bead, priority func(*models.Bead) models.BeadPriority) {
+	if priority == nil {
+		priority = func(b *models.Bead) models.BeadPriority { return b.Priority }
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i] == nil {
+			return false
+		}
+		if list[j] == nil {
+			return true
+		}
+		pi, pj := priority(list[i]), priority(list[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return list[i].UpdatedAt.After(list[j].UpdatedAt)
+	})
+}
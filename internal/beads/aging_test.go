@@ -0,0 +1,70 @@
+package beads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestBead_EffectivePriority(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		priority models.BeadPriority
+		waited   time.Duration
+		interval time.Duration
+		want     models.BeadPriority
+	}{
+		{models.BeadPriorityP3, 72 * time.Hour, 0, models.BeadPriorityP3},
+		{models.BeadPriorityP3, 23 * time.Hour, 24 * time.Hour, models.BeadPriorityP3},
+		{models.BeadPriorityP3, 25 * time.Hour, 24 * time.Hour, models.BeadPriorityP2},
+		{4, 49 * time.Hour, 24 * time.Hour, models.BeadPriorityP2},
+		{models.BeadPriorityP2, 240 * time.Hour, 24 * time.Hour, models.BeadPriorityP0},
+		{models.BeadPriorityP0, 240 * time.Hour, 24 * time.Hour, models.BeadPriorityP0},
+	}
+	for _, tc := range tests {
+		b := &models.Bead{Priority: tc.priority, CreatedAt: now.Add(-tc.waited)}
+		if got := b.EffectivePriority(tc.interval, now); got != tc.want {
+			t.Errorf("P%d waiting %s, aging %s: got P%d, want P%d", tc.priority, tc.waited, tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestManager_GetReadyBeadsAgesPriority(t *testing.T) {
+	manager := newTestManager(t)
+	manager.SetProjectBeadsPath("project1", t.TempDir())
+	manager.SetProjectBeadsPath("project2", t.TempDir())
+
+	old, _ := manager.CreateBead("Old chore", "", models.BeadPriorityP3, "task", "project1")
+	old.CreatedAt = time.Now().Add(-72 * time.Hour)
+	urgent, _ := manager.CreateBead("Urgent", "", models.BeadPriorityP1, "task", "project1")
+	otherOld, _ := manager.CreateBead("Old chore elsewhere", "", models.BeadPriorityP3, "task", "project2")
+	otherOld.CreatedAt = time.Now().Add(-72 * time.Hour)
+	otherUrgent, _ := manager.CreateBead("Urgent elsewhere", "", models.BeadPriorityP1, "task", "project2")
+
+	ready, err := manager.GetReadyBeads("project1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ready) != 2 || ready[0].ID != urgent.ID {
+		t.Fatalf("without aging, want %s first, got %v", urgent.ID, ready)
+	}
+
+	manager.SetPriorityAging(func(projectID string) time.Duration {
+		if projectID == "project1" {
+			return 24 * time.Hour
+		}
+		return 0
+	})
+	ready, _ = manager.GetReadyBeads("project1")
+	if ready[0].ID != old.ID {
+		t.Errorf("with aging, want the aged %s first, got %s", old.ID, ready[0].ID)
+	}
+	if got := manager.EffectivePriority(old); got != models.BeadPriorityP0 {
+		t.Errorf("EffectivePriority(old) = P%d, want P0", got)
+	}
+	ready, _ = manager.GetReadyBeads("project2")
+	if ready[0].ID != otherUrgent.ID {
+		t.Errorf("project without aging: want %s first, got %s", otherUrgent.ID, ready[0].ID)
+	}
+}
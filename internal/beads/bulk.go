@@ -0,0 +1,90 @@
+package beads
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// BulkUpdateError reports the bead that stopped an all-or-nothing bulk
+// update. Beads updated before it have been put back as they were.
+type BulkUpdateError struct {
+	BeadID string
+	Err    error
+}
+
+func (e *BulkUpdateError) Error() string {
+	return fmt.Sprintf("bead %s: %v", e.BeadID, e.Err)
+}
+
+func (e *BulkUpdateError) Unwrap() error { return e.Err }
+
+// UpdateBeads applies the same updates to every listed bead, all or
+// nothing. Each bead passes the transition check in turn, so a WIP limit
+// counts the beads moved before it. If any bead is missing or fails, the
+// beads already updated are restored and a *BulkUpdateError is returned.
+func (m *Manager) UpdateBeads(ids []string, updates map[string]interface{}) error {
+	snapshots := make([]models.Bead, 0, len(ids))
+	m.mu.RLock()
+	for _, id := range ids {
+		bead, ok := m.beads[id]
+		if !ok {
+			m.mu.RUnlock()
+			return &BulkUpdateError{BeadID: id, Err: ErrBeadNotFound}
+		}
+		snapshots = append(snapshots, snapshotBead(bead))
+	}
+	m.mu.RUnlock()
+
+	status, hasStatus := updates["status"].(models.BeadStatus)
+	for i, id := range ids {
+		var err error
+		if hasStatus {
+			err = m.CheckTransition(id, status)
+		}
+		if err == nil {
+			err = m.UpdateBead(id, updates)
+		}
+		if err != nil {
+			m.restoreBeads(snapshots[:i], actorOf(updates))
+			return &BulkUpdateError{BeadID: id, Err: err}
+		}
+	}
+	return nil
+}
+
+// snapshotBead copies a bead deeply enough to undo UpdateBead, which
+// replaces slices and timestamps but merges into the context map.
+func snapshotBead(bead *models.Bead) models.Bead {
+	snap := *bead
+	if bead.Context != nil {
+		snap.Context = make(map[string]string, len(bead.Context))
+		for k, v := range bead.Context {
+			snap.Context[k] = v
+		}
+	}
+	return snap
+}
+
+func (m *Manager) restoreBeads(snapshots []models.Bead, actor string) {
+	for i := range snapshots {
+		snap := snapshots[i]
+		m.mu.Lock()
+		bead, ok := m.beads[snap.ID]
+		var before models.Bead
+		if ok {
+			before = snapshotBead(bead)
+			*bead = snap
+		}
+		m.mu.Unlock()
+		if !ok {
+			continue
+		}
+		m.recordChanges(&before, &snap, actor)
+		if err := m.SaveBeadToGit(context.Background(), bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save restored bead to git: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package beads
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_UpdateBeads(t *testing.T) {
+	manager := newTestManager(t)
+
+	a, _ := manager.CreateBead("A", "", models.BeadPriorityP2, "task", "project1")
+	b, _ := manager.CreateBead("B", "", models.BeadPriorityP2, "task", "project1")
+
+	err := manager.UpdateBeads([]string{a.ID, b.ID}, map[string]interface{}{
+		"priority": models.BeadPriorityP0,
+		"context":  map[string]string{"sprint": "7"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateBeads() error = %v", err)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		bead, _ := manager.GetBead(id)
+		if bead.Priority != models.BeadPriorityP0 || bead.Context["sprint"] != "7" {
+			t.Errorf("bead %s not updated: %+v", id, bead)
+		}
+	}
+}
+
+// TestManager_UpdateBeads_RollsBack tests that a bead failing its
+// transition check undoes the beads updated before it
+func TestManager_UpdateBeads_RollsBack(t *testing.T) {
+	manager := newTestManager(t)
+
+	a, _ := manager.CreateBead("A", "", models.BeadPriorityP2, "task", "project1")
+	b, _ := manager.CreateBead("B", "", models.BeadPriorityP2, "task", "project1")
+	_ = manager.UpdateBead(a.ID, map[string]interface{}{"context": map[string]string{"k": "old"}})
+
+	// Allow one bead in progress, like a WIP limit of 1.
+	errFull := errors.New("column full")
+	manager.SetTransitionCheck(func(bead *models.Bead, to models.BeadStatus) error {
+		beads, _ := manager.ListBeads(map[string]interface{}{"status": models.BeadStatusInProgress})
+		if to == models.BeadStatusInProgress && len(beads) >= 1 {
+			return errFull
+		}
+		return nil
+	})
+
+	err := manager.UpdateBeads([]string{a.ID, b.ID}, map[string]interface{}{
+		"status":  models.BeadStatusInProgress,
+		"context": map[string]string{"k": "new", "added": "x"},
+	})
+	var bulkErr *BulkUpdateError
+	if !errors.As(err, &bulkErr) || bulkErr.BeadID != b.ID || !errors.Is(err, errFull) {
+		t.Fatalf("UpdateBeads() error = %v, want failure on %s", err, b.ID)
+	}
+
+	restored, _ := manager.GetBead(a.ID)
+	if restored.Status != models.BeadStatusOpen || restored.StartedAt != nil {
+		t.Errorf("bead %s not rolled back: status %s", a.ID, restored.Status)
+	}
+	if restored.Context["k"] != "old" || restored.Context["added"] != "" {
+		t.Errorf("context not rolled back: %v", restored.Context)
+	}
+}
+
+func TestManager_UpdateBeads_NotFound(t *testing.T) {
+	manager := newTestManager(t)
+
+	a, _ := manager.CreateBead("A", "", models.BeadPriorityP2, "task", "project1")
+
+	err := manager.UpdateBeads([]string{a.ID, "missing"}, map[string]interface{}{"title": "changed"})
+	if !errors.Is(err, ErrBeadNotFound) {
+		t.Fatalf("UpdateBeads() error = %v, want %v", err, ErrBeadNotFound)
+	}
+	if bead, _ := manager.GetBead(a.ID); bead.Title != "A" {
+		t.Errorf("title = %q, want unchanged", bead.Title)
+	}
+}
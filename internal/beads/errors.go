@@ -7,4 +7,6 @@ import "errors"
 var (
 	ErrBeadNotFound       = errors.New("bead not found")
 	ErrBeadAlreadyClaimed = errors.New("bead already claimed")
+	ErrInvalidCursor      = errors.New("invalid pagination cursor")
+	ErrInvalidMove        = errors.New("invalid move")
 )
@@ -0,0 +1,291 @@
+package beads
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ErrDependencyCycle is returned when blocking one bead on another would
+// make a bead wait on itself.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// DefaultBottleneckLimit is how many bottlenecks an analysis lists unless
+// asked for another number.
+const DefaultBottleneckLimit = 10
+
+// GraphAnalysis describes the blocking dependencies between the open beads
+// of a project. Closed beads block nothing and are left out.
+type GraphAnalysis struct {
+	ProjectID string `json:"project_id"`
+	OpenBeads int    `json:"open_beads"`
+	// Cycles lists each set of beads that wait on each other, which none
+	// of them can get out of without a dependency being removed.
+	Cycles       [][]string    `json:"cycles"`
+	CriticalPath *CriticalPath `json:"critical_path"`
+	Bottlenecks  []Bottleneck  `json:"bottlenecks"`
+}
+
+// CriticalPath is the longest chain of open blockers ending at Target, in
+// the order the beads have to be closed. Without a target it is the
+// longest chain in the project.
+type CriticalPath struct {
+	Target string   `json:"target"`
+	Beads  []string `json:"beads"`
+	Length int      `json:"length"`
+}
+
+// Bottleneck is an open bead that other open beads wait on.
+type Bottleneck struct {
+	BeadID string            `json:"bead_id"`
+	Title  string            `json:"title"`
+	Status models.BeadStatus `json:"status"`
+	// Blocking counts the beads blocked on this one directly, Downstream
+	// those waiting on it directly or through other beads.
+	Blocking   int `json:"blocking"`
+	Downstream int `json:"downstream"`
+}
+
+// AnalyzeWorkGraph finds the dependency cycles, critical path, and the
+// limit beads blocking the most downstream work in a project. target,
+// when set, is the bead to find the critical path to.
+func (m *Manager) AnalyzeWorkGraph(projectID, target string, limit int) (*GraphAnalysis, error) {
+	graph, err := m.GetWorkGraph(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if target != "" {
+		if _, ok := graph.Beads[target]; !ok {
+			return nil, fmt.Errorf("bead not found %s: %w", target, ErrBeadNotFound)
+		}
+	}
+	a := AnalyzeGraph(graph, target, limit)
+	a.ProjectID = projectID
+	return a, nil
+}
+
+// AnalyzeGraph analyzes the "blocks" edges of a work graph; see
+// AnalyzeWorkGraph.
+func AnalyzeGraph(graph *models.WorkGraph, target string, limit int) *GraphAnalysis {
+	open := make(map[string]*models.Bead)
+	for id, bead := range graph.Beads {
+		if bead.Status != models.BeadStatusClosed {
+			open[id] = bead
+		}
+	}
+	// blockers[a] lists the beads a waits on; dependents is the reverse.
+	blockers := make(map[string][]string)
+	dependents := make(map[string][]string)
+	for _, e := range graph.Edges {
+		if e.Relationship != "blocks" || open[e.From] == nil || open[e.To] == nil {
+			continue
+		}
+		blockers[e.From] = append(blockers[e.From], e.To)
+		dependents[e.To] = append(dependents[e.To], e.From)
+	}
+	ids := make([]string, 0, len(open))
+	for id := range open {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		sort.Strings(blockers[id])
+		sort.Strings(dependents[id])
+	}
+
+	a := &GraphAnalysis{
+		OpenBeads:   len(open),
+		Cycles:      findCycles(ids, blockers),
+		Bottlenecks: []Bottleneck{},
+	}
+	a.CriticalPath = criticalPath(ids, blockers, target, open[target] != nil)
+
+	for _, id := range ids {
+		downstream := len(reachable(id, dependents)) - 1
+		if downstream == 0 {
+			continue
+		}
+		a.Bottlenecks = append(a.Bottlenecks, Bottleneck{
+			BeadID:     id,
+			Title:      open[id].Title,
+			Status:     open[id].Status,
+			Blocking:   len(dependents[id]),
+			Downstream: downstream,
+		})
+	}
+	sort.SliceStable(a.Bottlenecks, func(i, j int) bool {
+		bi, bj := a.Bottlenecks[i], a.Bottlenecks[j]
+		if bi.Downstream != bj.Downstream {
+			return bi.Downstream > bj.Downstream
+		}
+		return bi.Blocking > bj.Blocking
+	})
+	if limit <= 0 {
+		limit = DefaultBottleneckLimit
+	}
+	if len(a.Bottlenecks) > limit {
+		a.Bottlenecks = a.Bottlenecks[:limit]
+	}
+	return a
+}
+
+// findCycles returns the strongly connected components of more than one
+// bead (Tarjan's algorithm), each sorted, ordered by their first bead.
+func findCycles(ids []string, edges map[string][]string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := [][]string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, next := range edges[id] {
+			if _, seen := index[next]; !seen {
+				visit(next)
+				low[id] = min(low[id], low[next])
+			} else if onStack[next] {
+				low[id] = min(low[id], index[next])
+			}
+		}
+		if low[id] != index[id] {
+			return
+		}
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == id {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, id := range ids {
+		if _, seen := index[id]; !seen {
+			visit(id)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// criticalPath returns the longest chain of blockers ending at target, or
+// the longest chain overall when target is empty. Edges back into the
+// chain being built are skipped, so cycles don't make it infinite. An
+// unknown or closed target has an empty path.
+func criticalPath(ids []string, blockers map[string][]string, target string, targetOpen bool) *CriticalPath {
+	path := &CriticalPath{Target: target, Beads: []string{}}
+	if target != "" && !targetOpen {
+		return path
+	}
+	chains := make(map[string][]string)
+	visiting := make(map[string]bool)
+	var chain func(id string) []string
+	chain = func(id string) []string {
+		if c, ok := chains[id]; ok {
+			return c
+		}
+		visiting[id] = true
+		var longest []string
+		for _, blocker := range blockers[id] {
+			if visiting[blocker] {
+				continue
+			}
+			if c := chain(blocker); len(c) > len(longest) {
+				longest = c
+			}
+		}
+		visiting[id] = false
+		c := append(append([]string(nil), longest...), id)
+		chains[id] = c
+		return c
+	}
+
+	if target != "" {
+		path.Beads = chain(target)
+	} else {
+		for _, id := range ids {
+			if c := chain(id); len(c) > len(path.Beads) {
+				path.Beads = c
+			}
+		}
+	}
+	path.Length = len(path.Beads)
+	return path
+}
+
+// reachable returns the beads reachable from id over edges, id included.
+func reachable(id string, edges map[string][]string) map[string]bool {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, to := range edges[next] {
+			if !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return seen
+}
+
+// checkBlocker refuses to block beadID on blockerID when blockerID
+// already waits on beadID, directly or through other beads. Must be
+// called with m.mu held.
+func (m *Manager) checkBlocker(beadID, blockerID string) error {
+	if beadID == blockerID {
+		return fmt.Errorf("%w: %s can't block itself", ErrDependencyCycle, beadID)
+	}
+	// Search the beads blockerID waits on for beadID, remembering how each
+	// was reached to report the chain.
+	from := map[string]string{blockerID: ""}
+	queue := []string{blockerID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		bead, ok := m.beads[id]
+		if !ok {
+			continue
+		}
+		for _, next := range bead.BlockedBy {
+			if _, seen := from[next]; seen {
+				continue
+			}
+			from[next] = id
+			if next != beadID {
+				queue = append(queue, next)
+				continue
+			}
+			chain := []string{beadID}
+			for at := id; at != ""; at = from[at] {
+				chain = append(chain, at)
+			}
+			return fmt.Errorf("%w: %s already waits on %s (%s)", ErrDependencyCycle,
+				blockerID, beadID, strings.Join(reversed(chain), " -> "))
+		}
+	}
+	return nil
+}
+
+func reversed(ids []string) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[len(ids)-1-i] = id
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package beads
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_RefusesDependencyCycles(t *testing.T) {
+	manager := newTestManager(t)
+
+	a, _ := manager.CreateBead("A", "", models.BeadPriorityP2, "task", "project1")
+	b, _ := manager.CreateBead("B", "", models.BeadPriorityP2, "task", "project1")
+	c, _ := manager.CreateBead("C", "", models.BeadPriorityP2, "task", "project1")
+
+	// c waits on b, which waits on a.
+	if err := manager.AddRelation(b.ID, RelationBlockedBy, a.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation() error = %v", err)
+	}
+	if err := manager.AddDependency(c.ID, b.ID, "blocks"); err != nil {
+		t.Fatalf("AddDependency() error = %v", err)
+	}
+
+	if err := manager.AddRelation(a.ID, RelationBlockedBy, c.ID, "tester"); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("AddRelation() error = %v, want ErrDependencyCycle", err)
+	}
+	if err := manager.AddDependency(a.ID, c.ID, "blocks"); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("AddDependency() error = %v, want ErrDependencyCycle", err)
+	}
+	err := manager.UpdateBead(a.ID, map[string]interface{}{"blocked_by": []string{b.ID}})
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("UpdateBead() error = %v, want ErrDependencyCycle", err)
+	}
+	bead, _ := manager.GetBead(a.ID)
+	if len(bead.BlockedBy) != 0 {
+		t.Errorf("BlockedBy = %v after refused updates", bead.BlockedBy)
+	}
+}
+
+func TestAnalyzeGraph(t *testing.T) {
+	bead := func(id string, status models.BeadStatus) *models.Bead {
+		return &models.Bead{ID: id, Title: "Bead " + id, Status: status}
+	}
+	blocks := func(from, to string) models.Edge {
+		return models.Edge{From: from, To: to, Relationship: "blocks"}
+	}
+	graph := &models.WorkGraph{
+		Beads: map[string]*models.Bead{
+			"a":    bead("a", models.BeadStatusOpen),
+			"b":    bead("b", models.BeadStatusInProgress),
+			"c":    bead("c", models.BeadStatusOpen),
+			"d":    bead("d", models.BeadStatusOpen),
+			"e":    bead("e", models.BeadStatusOpen),
+			"done": bead("done", models.BeadStatusClosed),
+			"x":    bead("x", models.BeadStatusOpen),
+			"y":    bead("y", models.BeadStatusOpen),
+		},
+		// d waits on c, c on b, b on a; e waits on a and on a closed bead;
+		// x and y wait on each other.
+		Edges: []models.Edge{
+			blocks("b", "a"), blocks("c", "b"), blocks("d", "c"),
+			blocks("e", "a"), blocks("e", "done"),
+			blocks("x", "y"), blocks("y", "x"),
+			{From: "e", To: "d", Relationship: "related"},
+		},
+	}
+
+	a := AnalyzeGraph(graph, "", 0)
+	if a.OpenBeads != 7 {
+		t.Errorf("OpenBeads = %d, want 7", a.OpenBeads)
+	}
+	if want := [][]string{{"x", "y"}}; !reflect.DeepEqual(a.Cycles, want) {
+		t.Errorf("Cycles = %v, want %v", a.Cycles, want)
+	}
+	if want := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(a.CriticalPath.Beads, want) {
+		t.Errorf("CriticalPath = %v, want %v", a.CriticalPath.Beads, want)
+	}
+	if len(a.Bottlenecks) == 0 || a.Bottlenecks[0].BeadID != "a" ||
+		a.Bottlenecks[0].Downstream != 4 || a.Bottlenecks[0].Blocking != 2 {
+		t.Errorf("top bottleneck = %+v, want a blocking 2 with 4 downstream", a.Bottlenecks)
+	}
+
+	a = AnalyzeGraph(graph, "e", 1)
+	if want := []string{"a", "e"}; !reflect.DeepEqual(a.CriticalPath.Beads, want) {
+		t.Errorf("CriticalPath(e) = %v, want %v", a.CriticalPath.Beads, want)
+	}
+	if len(a.Bottlenecks) != 1 {
+		t.Errorf("Bottlenecks = %d, want the limit of 1", len(a.Bottlenecks))
+	}
+
+	if a = AnalyzeGraph(graph, "done", 0); a.CriticalPath.Length != 0 {
+		t.Errorf("CriticalPath(done) = %v, want empty", a.CriticalPath.Beads)
+	}
+}
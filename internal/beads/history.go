@@ -0,0 +1,133 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// UpdatedByKey is the updates key naming who made a change, recorded in
+// the bead's history. Updates without it are attributed to SystemActor.
+const UpdatedByKey = "updated_by"
+
+// SystemActor is the actor of changes made by loom itself.
+const SystemActor = "system"
+
+// maxHistoryValue caps the length of a value kept in a bead's history;
+// context values such as error histories can grow large.
+const maxHistoryValue = 2000
+
+// FieldChange is one changed field of a bead. Context keys are recorded as
+// "context.<key>"; list fields as comma-separated values.
+type FieldChange struct {
+	BeadID    string    `json:"bead_id"`
+	ProjectID string    `json:"project_id"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Actor     string    `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SetHistoryRecorder installs the function every bead change is passed to.
+// It is called outside the manager's locks, once per change set.
+func (m *Manager) SetHistoryRecorder(record func([]FieldChange)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.historyRecorder = record
+}
+
+// recordChanges passes the differences between two states of a bead to
+// the history recorder, if one is installed.
+func (m *Manager) recordChanges(before, after *models.Bead, actor string) {
+	m.mu.RLock()
+	record := m.historyRecorder
+	m.mu.RUnlock()
+	if record == nil {
+		return
+	}
+	if actor == "" {
+		actor = SystemActor
+	}
+	if changes := diffBead(before, after, actor, time.Now()); len(changes) > 0 {
+		record(changes)
+	}
+}
+
+// actorOf returns who made an update, from its UpdatedByKey.
+func actorOf(updates map[string]interface{}) string {
+	if actor, ok := updates[UpdatedByKey].(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+func diffBead(before, after *models.Bead, actor string, at time.Time) []FieldChange {
+	var changes []FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue == newValue {
+			return
+		}
+		changes = append(changes, FieldChange{
+			BeadID:    after.ID,
+			ProjectID: after.ProjectID,
+			Field:     field,
+			OldValue:  truncateHistoryValue(oldValue),
+			NewValue:  truncateHistoryValue(newValue),
+			Actor:     actor,
+			ChangedAt: at,
+		})
+	}
+
+	add("status", string(before.Status), string(after.Status))
+	add("priority", fmt.Sprint(int(before.Priority)), fmt.Sprint(int(after.Priority)))
+	add("assigned_to", before.AssignedTo, after.AssignedTo)
+	add("title", before.Title, after.Title)
+	add("type", before.Type, after.Type)
+	add("project_id", before.ProjectID, after.ProjectID)
+	add("description", before.Description, after.Description)
+	add("parent", before.Parent, after.Parent)
+	add("duplicate_of", before.DuplicateOf, after.DuplicateOf)
+	add("milestone_id", before.MilestoneID, after.MilestoneID)
+	add("tags", strings.Join(before.Tags, ","), strings.Join(after.Tags, ","))
+	add("blocked_by", strings.Join(before.BlockedBy, ","), strings.Join(after.BlockedBy, ","))
+	add("blocks", strings.Join(before.Blocks, ","), strings.Join(after.Blocks, ","))
+	add("related_to", strings.Join(before.RelatedTo, ","), strings.Join(after.RelatedTo, ","))
+	add("children", strings.Join(before.Children, ","), strings.Join(after.Children, ","))
+	add("due_date", formatHistoryTime(before.DueDate), formatHistoryTime(after.DueDate))
+	add("deleted_at", formatHistoryTime(before.DeletedAt), formatHistoryTime(after.DeletedAt))
+
+	keys := make(map[string]bool)
+	for k := range before.Context {
+		keys[k] = true
+	}
+	for k := range after.Context {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		add("context."+k, before.Context[k], after.Context[k])
+	}
+	return changes
+}
+
+func formatHistoryTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func truncateHistoryValue(v string) string {
+	if len(v) <= maxHistoryValue {
+		return v
+	}
+	return strings.ToValidUTF8(v[:maxHistoryValue], "") + "…"
+}
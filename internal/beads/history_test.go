@@ -0,0 +1,56 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestDiffBead(t *testing.T) {
+	before := &models.Bead{
+		ID:        "bd-1",
+		ProjectID: "p1",
+		Status:    models.BeadStatusOpen,
+		Tags:      []string{"ui"},
+		Context:   map[string]string{"keep": "1", "drop": "x"},
+	}
+	after := &models.Bead{
+		ID:        "bd-1",
+		ProjectID: "p1",
+		Status:    models.BeadStatusBlocked,
+		Tags:      []string{"ui", "p0"},
+		Context:   map[string]string{"keep": "1", "add": strings.Repeat("y", maxHistoryValue+10)},
+	}
+
+	changes := diffBead(before, after, "alice", time.Now())
+	got := make(map[string]FieldChange)
+	for _, c := range changes {
+		got[c.Field] = c
+	}
+	if len(changes) != 4 {
+		t.Fatalf("got %d changes, want 4: %+v", len(changes), changes)
+	}
+	if c := got["status"]; c.OldValue != "open" || c.NewValue != "blocked" || c.Actor != "alice" {
+		t.Errorf("status change = %+v", c)
+	}
+	if c := got["tags"]; c.OldValue != "ui" || c.NewValue != "ui,p0" {
+		t.Errorf("tags change = %+v", c)
+	}
+	if c := got["context.drop"]; c.OldValue != "x" || c.NewValue != "" {
+		t.Errorf("removed context key = %+v", c)
+	}
+	if c := got["context.add"]; len(c.NewValue) > maxHistoryValue+len("…") {
+		t.Errorf("long value not truncated: %d bytes", len(c.NewValue))
+	}
+}
+
+func TestActorOf(t *testing.T) {
+	if got := actorOf(map[string]interface{}{UpdatedByKey: "alice"}); got != "alice" {
+		t.Errorf("actorOf = %q, want alice", got)
+	}
+	if got := actorOf(map[string]interface{}{}); got != SystemActor {
+		t.Errorf("actorOf = %q, want %q", got, SystemActor)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ type Manager struct {
 	backend           string // "sqlite", "dolt", or "yaml"
 	mu                sync.RWMutex
 	beads             map[string]*models.Bead
+	trash             map[string]*models.Bead // soft-deleted beads, kept until purged
 	beadFiles         map[string]string
 	workGraph         *models.WorkGraph
 	nextID            int               // For generating IDs when bd CLI is not available
@@ -37,6 +39,19 @@ type Manager struct {
 	gitConfigs map[string]*GitConfig  // Project ID -> git configuration
 	gitMu      sync.Mutex             // Protects gitLocks map
 	gitLocks   map[string]*sync.Mutex // Per-project mutex to serialize git operations
+
+	// Board WIP limits: checked before a bead is claimed; transitionMu
+	// serializes check-and-claim so concurrent claims can't overshoot.
+	transitionCheck func(b *models.Bead, to models.BeadStatus) error
+	transitionMu    sync.Mutex
+
+	historyRecorder func([]FieldChange)
+
+	// recordMover re-keys the records kept elsewhere for a moved bead.
+	recordMover func(oldID, newID, projectID string) error
+
+	// priorityAging gives a project's aging interval for GetReadyBeads.
+	priorityAging func(projectID string) time.Duration
 }
 
 // GitConfig stores git storage configuration for a project
@@ -55,6 +70,7 @@ func NewManager(bdPath string) *Manager {
 		beadsPath: ".beads",
 		backend:   "sqlite", // Default to sqlite for simpler setup
 		beads:     make(map[string]*models.Bead),
+		trash:     make(map[string]*models.Bead),
 		beadFiles: make(map[string]string),
 		workGraph: &models.WorkGraph{
 			Beads:     make(map[string]*models.Bead),
@@ -127,6 +143,29 @@ func (m *Manager) SetBackend(backend string) {
 	m.backend = backend
 }
 
+// SetTransitionCheck installs the check run before a bead is claimed and
+// by CheckTransition. It returns an error when the bead must not move to
+// the given status, e.g. because a board column is at its WIP limit.
+func (m *Manager) SetTransitionCheck(check func(b *models.Bead, to models.BeadStatus) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transitionCheck = check
+}
+
+// CheckTransition runs the transition check, if one is installed, for
+// moving a bead to a new status. Moves to the bead's current status are
+// always allowed.
+func (m *Manager) CheckTransition(beadID string, to models.BeadStatus) error {
+	m.mu.RLock()
+	check := m.transitionCheck
+	bead, ok := m.beads[beadID]
+	m.mu.RUnlock()
+	if check == nil || !ok || bead.Status == to {
+		return nil
+	}
+	return check(bead, to)
+}
+
 // buildBDCommand constructs a bd command with the correct --db flag for sqlite backend
 func (m *Manager) buildBDCommand(args ...string) *exec.Cmd {
 	// For sqlite backend, explicitly pass --db flag to avoid dolt auto-discovery
@@ -151,12 +190,19 @@ func (m *Manager) ClearProjectBeads(projectID string) {
 			delete(m.workGraph.Beads, id)
 		}
 	}
+	for id, b := range m.trash {
+		if b.ProjectID == projectID {
+			delete(m.trash, id)
+			delete(m.beadFiles, id)
+		}
+	}
 }
 
 func (m *Manager) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.beads = make(map[string]*models.Bead)
+	m.trash = make(map[string]*models.Bead)
 	m.beadFiles = make(map[string]string)
 	m.workGraph = &models.WorkGraph{Beads: make(map[string]*models.Bead), Edges: []models.Edge{}, UpdatedAt: time.Now()}
 	m.nextID = 1
@@ -297,26 +343,7 @@ func (m *Manager) CreateBead(title, description string, priority models.BeadPrio
 
 	// Fallback to filesystem-based bead creation
 	if beadID == "" {
-		// Get or initialize project-specific counter
-		nextID := m.projectNextIDs[projectID]
-		if nextID == 0 {
-			nextID = 1
-		}
-
-		// Generate a new ID with project prefix
-		beadID = fmt.Sprintf("%s-%03d", prefix, nextID)
-		nextID++
-
-		// Check for existing beads to avoid ID collision
-		for {
-			if _, exists := m.beads[beadID]; !exists {
-				break
-			}
-			beadID = fmt.Sprintf("%s-%03d", prefix, nextID)
-			nextID++
-		}
-
-		m.projectNextIDs[projectID] = nextID
+		beadID = m.nextBeadID(projectID)
 	}
 
 	// Create internal bead representation
@@ -350,6 +377,39 @@ func (m *Manager) CreateBead(title, description string, priority models.BeadPrio
 	return bead, nil
 }
 
+// nextBeadID allocates the next free ID with the project's prefix. Must be
+// called with m.mu held.
+func (m *Manager) nextBeadID(projectID string) string {
+	prefix := "bd"
+	if p, ok := m.projectPrefixes[projectID]; ok && p != "" {
+		prefix = p
+	}
+
+	// Get or initialize project-specific counter
+	nextID := m.projectNextIDs[projectID]
+	if nextID == 0 {
+		nextID = 1
+	}
+
+	// Generate a new ID with project prefix
+	beadID := fmt.Sprintf("%s-%03d", prefix, nextID)
+	nextID++
+
+	// Check for existing beads, including trashed ones, to avoid ID collision
+	for {
+		_, exists := m.beads[beadID]
+		_, trashed := m.trash[beadID]
+		if !exists && !trashed {
+			break
+		}
+		beadID = fmt.Sprintf("%s-%03d", prefix, nextID)
+		nextID++
+	}
+
+	m.projectNextIDs[projectID] = nextID
+	return beadID
+}
+
 // GetBead retrieves a bead by ID
 func (m *Manager) GetBead(id string) (*models.Bead, error) {
 	m.mu.RLock()
@@ -394,6 +454,16 @@ func (m *Manager) UpdateBead(id string, updates map[string]interface{}) error {
 		return fmt.Errorf("bead not found %s: %w", id, ErrBeadNotFound)
 	}
 
+	if blockedBy, ok := updates["blocked_by"].([]string); ok {
+		for _, blocker := range blockedBy {
+			if err := m.checkBlocker(id, blocker); err != nil {
+				m.mu.Unlock()
+				return err
+			}
+		}
+	}
+
+	before := snapshotBead(bead)
 	previousAssigned := bead.AssignedTo
 	assignedUpdated := false
 
@@ -408,6 +478,10 @@ func (m *Manager) UpdateBead(id string, updates map[string]interface{}) error {
 		if status != models.BeadStatusClosed {
 			bead.ClosedAt = nil
 		}
+		if status == models.BeadStatusInProgress && bead.StartedAt == nil {
+			now := time.Now()
+			bead.StartedAt = &now
+		}
 		// When resetting to open, clear any stale assignment unless explicitly overridden
 		if status == models.BeadStatusOpen {
 			if _, hasAssigned := updates["assigned_to"]; !hasAssigned {
@@ -434,9 +508,16 @@ func (m *Manager) UpdateBead(id string, updates map[string]interface{}) error {
 	if description, ok := updates["description"].(string); ok {
 		bead.Description = description
 	}
+	// A nil *time.Time clears the due date.
+	if dueDate, ok := updates["due_date"].(*time.Time); ok {
+		bead.DueDate = dueDate
+	}
 	if parent, ok := updates["parent"].(string); ok {
 		bead.Parent = parent
 	}
+	if milestoneID, ok := updates["milestone_id"].(string); ok {
+		bead.MilestoneID = milestoneID
+	}
 	if tags, ok := updates["tags"].([]string); ok {
 		bead.Tags = tags
 	}
@@ -473,10 +554,14 @@ func (m *Manager) UpdateBead(id string, updates map[string]interface{}) error {
 		})
 	}
 
+	after := snapshotBead(bead)
+
 	// Release lock before expensive I/O operations
 	// SaveBeadToGit has its own locking for safe concurrent access
 	m.mu.Unlock()
 
+	m.recordChanges(&before, &after, actorOf(updates))
+
 	// Save to filesystem and git (without holding the main lock)
 	if err := m.SaveBeadToGit(context.Background(), bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to save bead to git: %v\n", err)
@@ -487,6 +572,16 @@ func (m *Manager) UpdateBead(id string, updates map[string]interface{}) error {
 
 // ClaimBead assigns a bead to an agent
 func (m *Manager) ClaimBead(beadID, agentID string) error {
+	m.transitionMu.Lock()
+	defer m.transitionMu.Unlock()
+	if err := m.CheckTransition(beadID, models.BeadStatusInProgress); err != nil {
+		observability.Error("bead.claim", map[string]interface{}{
+			"agent_id": agentID,
+			"bead_id":  beadID,
+		}, err)
+		return err
+	}
+
 	// Update in-memory state with write lock
 	m.mu.Lock()
 
@@ -513,9 +608,14 @@ func (m *Manager) ClaimBead(beadID, agentID string) error {
 		return err
 	}
 
+	before := snapshotBead(bead)
 	bead.AssignedTo = agentID
 	bead.Status = models.BeadStatusInProgress
 	bead.UpdatedAt = time.Now()
+	if bead.StartedAt == nil {
+		now := bead.UpdatedAt
+		bead.StartedAt = &now
+	}
 
 	observability.Info("bead.claim", map[string]interface{}{
 		"agent_id":   agentID,
@@ -523,10 +623,13 @@ func (m *Manager) ClaimBead(beadID, agentID string) error {
 		"project_id": bead.ProjectID,
 		"status":     "claimed",
 	})
+	after := snapshotBead(bead)
 
 	// Release lock before I/O operations
 	m.mu.Unlock()
 
+	m.recordChanges(&before, &after, agentID)
+
 	if err := m.SaveBeadToFilesystem(bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to save bead to filesystem: %v\n", err)
 	}
@@ -546,10 +649,12 @@ func (m *Manager) ReassignBead(beadID, newAgentID, previousAgentID string) error
 		return fmt.Errorf("bead not found %s: %w", beadID, ErrBeadNotFound)
 	}
 
+	before := snapshotBead(bead)
 	oldAgent := bead.AssignedTo
 	bead.AssignedTo = newAgentID
 	bead.Status = models.BeadStatusInProgress
 	bead.UpdatedAt = time.Now()
+	after := snapshotBead(bead)
 
 	observability.Info("bead.reassign", map[string]interface{}{
 		"bead_id":      bead.ID,
@@ -561,6 +666,8 @@ func (m *Manager) ReassignBead(beadID, newAgentID, previousAgentID string) error
 
 	m.mu.Unlock()
 
+	m.recordChanges(&before, &after, SystemActor)
+
 	if err := m.SaveBeadToFilesystem(bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to save bead to filesystem: %v\n", err)
 	}
@@ -586,6 +693,9 @@ func (m *Manager) AddDependency(childID, parentID, relationship string) error {
 	// Update bead relationships
 	switch relationship {
 	case "blocks":
+		if err := m.checkBlocker(childID, parentID); err != nil {
+			return err
+		}
 		child.BlockedBy = append(child.BlockedBy, parentID)
 		parent.Blocks = append(parent.Blocks, childID)
 		if child.Status == models.BeadStatusInProgress {
@@ -601,21 +711,17 @@ func (m *Manager) AddDependency(childID, parentID, relationship string) error {
 		return fmt.Errorf("unknown relationship: %s", relationship)
 	}
 
-	// Update work graph
-	m.workGraph.Edges = append(m.workGraph.Edges, models.Edge{
-		From:         childID,
-		To:           parentID,
-		Relationship: relationship,
-	})
 	m.workGraph.UpdatedAt = time.Now()
 
 	return nil
 }
 
-// GetReadyBeads returns beads with no open blockers
+// GetReadyBeads returns beads with no open blockers, most urgent first by
+// effective priority, so beads that have waited long enough are worked
+// ahead of newer ones of higher priority.
 func (m *Manager) GetReadyBeads(projectID string) ([]*models.Bead, error) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	aging := m.priorityAging
 
 	ready := make([]*models.Bead, 0)
 
@@ -644,7 +750,21 @@ func (m *Manager) GetReadyBeads(projectID string) ([]*models.Bead, error) {
 			ready = append(ready, bead)
 		}
 	}
+	m.mu.RUnlock()
 
+	now := time.Now()
+	intervals := make(map[string]time.Duration)
+	SortByPriority(ready, func(b *models.Bead) models.BeadPriority {
+		if aging == nil {
+			return b.Priority
+		}
+		interval, ok := intervals[b.ProjectID]
+		if !ok {
+			interval = aging(b.ProjectID)
+			intervals[b.ProjectID] = interval
+		}
+		return b.EffectivePriority(interval, now)
+	})
 	return ready, nil
 }
 
@@ -677,37 +797,82 @@ func (m *Manager) UnblockBead(beadID, blockerID string) error {
 	return nil
 }
 
-// GetWorkGraph returns the current work graph
+// GetWorkGraph returns the beads of a project, or of every project for an
+// empty projectID, with the edges between them derived from their
+// relations and a rollup of each bead that has children. A project's graph
+// also holds the beads of other projects its beads are related to, so
+// cross-project blockers show in it.
 func (m *Manager) GetWorkGraph(projectID string) (*models.WorkGraph, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if projectID == "" {
-		return m.workGraph, nil
-	}
-
-	// Filter by project
-	filteredGraph := &models.WorkGraph{
+	graph := &models.WorkGraph{
 		Beads:     make(map[string]*models.Bead),
 		Edges:     []models.Edge{},
+		Rollups:   make(map[string]models.ChildRollup),
 		UpdatedAt: m.workGraph.UpdatedAt,
 	}
-
+	ids := make([]string, 0, len(m.workGraph.Beads))
 	for id, bead := range m.workGraph.Beads {
-		if bead.ProjectID == projectID {
-			filteredGraph.Beads[id] = bead
+		if projectID == "" || bead.ProjectID == projectID {
+			graph.Beads[id] = bead
+			ids = append(ids, id)
 		}
 	}
-
-	for _, edge := range m.workGraph.Edges {
-		if _, ok := filteredGraph.Beads[edge.From]; ok {
-			if _, ok := filteredGraph.Beads[edge.To]; ok {
-				filteredGraph.Edges = append(filteredGraph.Edges, edge)
+	if projectID != "" {
+		for _, id := range ids {
+			for _, other := range relatedIDs(graph.Beads[id]) {
+				if bead, ok := m.workGraph.Beads[other]; ok && graph.Beads[other] == nil {
+					graph.Beads[other] = bead
+					ids = append(ids, other)
+				}
 			}
 		}
 	}
+	sort.Strings(ids)
 
-	return filteredGraph, nil
+	// Both beads of a relation record it; add each edge once.
+	seen := make(map[models.Edge]bool)
+	edge := func(from, to, relationship string) {
+		e := models.Edge{From: from, To: to, Relationship: relationship}
+		if relationship == "related" && to < from {
+			e.From, e.To = to, from
+		}
+		if _, ok := graph.Beads[e.From]; !ok || seen[e] {
+			return
+		}
+		if _, ok := graph.Beads[e.To]; !ok {
+			return
+		}
+		seen[e] = true
+		graph.Edges = append(graph.Edges, e)
+	}
+	for _, id := range ids {
+		bead := graph.Beads[id]
+		for _, blocker := range bead.BlockedBy {
+			edge(id, blocker, "blocks")
+		}
+		for _, blocked := range bead.Blocks {
+			edge(blocked, id, "blocks")
+		}
+		if bead.Parent != "" {
+			edge(id, bead.Parent, "parent")
+		}
+		for _, child := range bead.Children {
+			edge(child, id, "parent")
+		}
+		for _, other := range bead.RelatedTo {
+			edge(id, other, "related")
+		}
+		if bead.DuplicateOf != "" {
+			edge(id, bead.DuplicateOf, "duplicate")
+		}
+		if len(bead.Children) > 0 {
+			graph.Rollups[id] = m.rollup(bead)
+		}
+	}
+
+	return graph, nil
 }
 
 // Helper functions
@@ -803,6 +968,14 @@ func (m *Manager) matchesFilters(bead *models.Bead, filters map[string]interface
 		}
 	}
 
+	// project_ids restricts the list to a set of projects, e.g. those of
+	// the caller's organization; an empty set matches nothing.
+	if projectIDs, ok := filters["project_ids"].([]string); ok {
+		if !hasID(projectIDs, bead.ProjectID) {
+			return false
+		}
+	}
+
 	if status, ok := filters["status"].(models.BeadStatus); ok {
 		if bead.Status != status {
 			return false
@@ -815,6 +988,18 @@ func (m *Manager) matchesFilters(bead *models.Bead, filters map[string]interface
 		}
 	}
 
+	if milestoneID, ok := filters["milestone_id"].(string); ok {
+		if bead.MilestoneID != milestoneID {
+			return false
+		}
+	}
+
+	if overdue, ok := filters["overdue"].(bool); ok {
+		if bead.IsOverdue(time.Now()) != overdue {
+			return false
+		}
+	}
+
 	if assignedTo, ok := filters["assigned_to"]; ok {
 		switch value := assignedTo.(type) {
 		case string:
@@ -895,9 +1080,13 @@ func (m *Manager) LoadBeadsFromFilesystem(projectID, beadsPath string) error {
 		if bead.ProjectID == "" && projectID != "" {
 			bead.ProjectID = projectID
 		}
+		m.beadFiles[bead.ID] = beadPath
+		if bead.DeletedAt != nil {
+			m.trash[bead.ID] = &bead
+			continue
+		}
 		m.beads[bead.ID] = &bead
 		m.workGraph.Beads[bead.ID] = &bead
-		m.beadFiles[bead.ID] = beadPath
 		loadedCount++
 	}
 
@@ -1152,6 +1341,46 @@ func (m *Manager) SaveBeadToGit(ctx context.Context, bead *models.Bead, beadsPat
 		m.mu.Unlock()
 	}
 
+	// Commit with descriptive message
+	message := fmt.Sprintf("Update bead %s: %s\n\nStatus: %s\nAgent: %s\nPriority: %v",
+		bead.ID, bead.Title, bead.Status, bead.AssignedTo, bead.Priority)
+	return m.commitBeadChange(gitConfig, beadsWorktree, []string{"add", beadFile}, message)
+}
+
+// CommitBeadsDir commits and pushes every change under the project's beads
+// directory, e.g. after it was restored from a backup. It does nothing when
+// the project doesn't keep its beads in git.
+func (m *Manager) CommitBeadsDir(projectID, message string) error {
+	m.mu.RLock()
+	gitConfig, ok := m.gitConfigs[projectID]
+	m.mu.RUnlock()
+	if !ok || gitConfig == nil || !gitConfig.UseGitStorage || gitConfig.WorktreeManager == nil {
+		return nil
+	}
+	type pathGetter interface {
+		GetWorktreePath(string, string) string
+	}
+	wt, ok := gitConfig.WorktreeManager.(pathGetter)
+	if !ok {
+		return fmt.Errorf("worktree manager does not support GetWorktreePath")
+	}
+	beadsWorktree := wt.GetWorktreePath(projectID, "beads")
+	beadsPath := m.GetProjectBeadsPath(projectID)
+	rel, err := filepath.Rel(beadsWorktree, beadsPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("beads directory %s is outside the beads worktree", beadsPath)
+	}
+
+	gitLock := m.projectGitLock(projectID)
+	gitLock.Lock()
+	defer gitLock.Unlock()
+	return m.commitBeadChange(gitConfig, beadsWorktree, []string{"add", "-A", "--", rel}, message)
+}
+
+// commitBeadChange stages a change in the beads worktree with the given
+// git arguments, commits it, and pushes, rebasing on push conflicts.
+// Callers hold the project's git lock.
+func (m *Manager) commitBeadChange(gitConfig *GitConfig, beadsWorktree string, stage []string, message string) error {
 	// Auto-recover from a stuck rebase before doing anything else.
 	rebaseMerge := filepath.Join(beadsWorktree, ".git", "rebase-merge")
 	rebaseApply := filepath.Join(beadsWorktree, ".git", "rebase-apply")
@@ -1167,16 +1396,13 @@ func (m *Manager) SaveBeadToGit(ctx context.Context, bead *models.Bead, beadsPat
 		_ = abort.Run()
 	}
 
-	// Stage the bead file
-	addCmd := exec.Command("git", "add", beadFile)
-	addCmd.Dir = beadsWorktree
-	if output, err := addCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git add failed: %s - %w", output, err)
+	// Stage the change
+	stageCmd := exec.Command("git", stage...)
+	stageCmd.Dir = beadsWorktree
+	if output, err := stageCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s - %w", stage[0], output, err)
 	}
 
-	// Commit with descriptive message
-	message := fmt.Sprintf("Update bead %s: %s\n\nStatus: %s\nAgent: %s\nPriority: %v",
-		bead.ID, bead.Title, bead.Status, bead.AssignedTo, bead.Priority)
 	commitCmd := exec.Command("git", "commit", "-m", message)
 	commitCmd.Dir = beadsWorktree
 	if output, err := commitCmd.CombinedOutput(); err != nil {
@@ -2,6 +2,7 @@ package beads
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,6 +12,15 @@ import (
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
+// newTestManager returns a manager that saves beads under a temporary
+// directory rather than the package directory.
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager := NewManager("")
+	manager.SetBeadsPath(t.TempDir())
+	return manager
+}
+
 // TestNewManager tests manager creation
 func TestNewManager(t *testing.T) {
 	manager := NewManager("")
@@ -50,7 +60,7 @@ func TestManager_SetBeadsPath(t *testing.T) {
 
 // TestManager_Reset tests resetting the manager state
 func TestManager_Reset(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	// Add some data
 	bead := &models.Bead{
@@ -79,7 +89,7 @@ func TestManager_Reset(t *testing.T) {
 
 // TestManager_SetProjectPrefix tests setting project prefix
 func TestManager_SetProjectPrefix(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	projectID := "test-project"
 	prefix := "tp"
@@ -99,7 +109,7 @@ func TestManager_SetProjectPrefix(t *testing.T) {
 
 // TestManager_GetProjectPrefix tests getting project prefix with defaults
 func TestManager_GetProjectPrefix(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	// Default prefix for unknown project
 	prefix := manager.GetProjectPrefix("unknown-project")
@@ -117,7 +127,7 @@ func TestManager_GetProjectPrefix(t *testing.T) {
 
 // TestManager_CreateBead tests bead creation without bd CLI
 func TestManager_CreateBead(t *testing.T) {
-	manager := NewManager("") // No bd CLI path
+	manager := newTestManager(t) // No bd CLI path
 
 	title := "Test Bead"
 	description := "Test Description"
@@ -171,7 +181,7 @@ func TestManager_CreateBead(t *testing.T) {
 
 // TestManager_CreateBead_WithPrefix tests bead creation with custom prefix
 func TestManager_CreateBead_WithPrefix(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 	projectID := "loom"
 
 	manager.SetProjectPrefix(projectID, "ac")
@@ -189,7 +199,7 @@ func TestManager_CreateBead_WithPrefix(t *testing.T) {
 
 // TestManager_GetBead tests getting a bead by ID
 func TestManager_GetBead(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	// Create a bead
 	bead, err := manager.CreateBead("Test", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -210,7 +220,7 @@ func TestManager_GetBead(t *testing.T) {
 
 // TestManager_ListBeads tests listing beads
 func TestManager_ListBeads(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	// Create several beads
 	bead1, _ := manager.CreateBead("Bead 1", "Desc 1", models.BeadPriorityP1, "task", "project1")
@@ -269,12 +279,25 @@ func TestManager_ListBeads(t *testing.T) {
 		t.Error("Expected to find bead1 and bead2 in project1 beads")
 	}
 
-	_ = bead3 // Silence unused warning
+	// List beads of a set of projects; an empty set matches nothing
+	filters = map[string]interface{}{
+		"project_ids": []string{"project2", "project3"},
+	}
+	inProjects, err := manager.ListBeads(filters)
+	if err != nil {
+		t.Fatalf("ListBeads(project_ids) error = %v", err)
+	}
+	if len(inProjects) != 1 || inProjects[0].ID != bead3.ID {
+		t.Errorf("ListBeads(project_ids=[project2 project3]) = %v, want only %s", inProjects, bead3.ID)
+	}
+	if none, _ := manager.ListBeads(map[string]interface{}{"project_ids": []string{}}); len(none) != 0 {
+		t.Errorf("ListBeads(project_ids=[]) returned %d beads, want 0", len(none))
+	}
 }
 
 // TestManager_UpdateBead tests updating a bead
 func TestManager_UpdateBead(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead, err := manager.CreateBead("Original", "Desc", models.BeadPriorityP3, "task", "project1")
 	if err != nil {
@@ -315,7 +338,7 @@ func TestManager_UpdateBead(t *testing.T) {
 
 // TestManager_UpdateBead_StatusClosed tests closing a bead
 func TestManager_UpdateBead_StatusClosed(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead, _ := manager.CreateBead("Test", "Desc", models.BeadPriorityP2, "task", "project1")
 
@@ -356,7 +379,7 @@ func TestManager_UpdateBead_StatusClosed(t *testing.T) {
 
 // TestManager_ClaimBead tests claiming a bead
 func TestManager_ClaimBead(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead, _ := manager.CreateBead("Test", "Desc", models.BeadPriorityP2, "task", "project1")
 
@@ -378,7 +401,7 @@ func TestManager_ClaimBead(t *testing.T) {
 
 // TestManager_ClaimBead_AlreadyClaimed tests claiming an already claimed bead
 func TestManager_ClaimBead_AlreadyClaimed(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead, _ := manager.CreateBead("Test", "Desc", models.BeadPriorityP2, "task", "project1")
 
@@ -398,9 +421,36 @@ func TestManager_ClaimBead_AlreadyClaimed(t *testing.T) {
 	}
 }
 
+// TestManager_ClaimBead_TransitionCheck tests that a failing transition
+// check (e.g. a board WIP limit) stops a claim
+func TestManager_ClaimBead_TransitionCheck(t *testing.T) {
+	manager := newTestManager(t)
+
+	bead, _ := manager.CreateBead("Test", "Desc", models.BeadPriorityP2, "task", "project1")
+
+	errFull := errors.New("column full")
+	manager.SetTransitionCheck(func(b *models.Bead, to models.BeadStatus) error {
+		if to == models.BeadStatusInProgress {
+			return errFull
+		}
+		return nil
+	})
+
+	if err := manager.ClaimBead(bead.ID, "agent-1"); !errors.Is(err, errFull) {
+		t.Fatalf("ClaimBead() error = %v, want %v", err, errFull)
+	}
+	claimed, _ := manager.GetBead(bead.ID)
+	if claimed.AssignedTo != "" || claimed.Status != models.BeadStatusOpen {
+		t.Errorf("bead changed despite failed check: %+v", claimed)
+	}
+	if err := manager.CheckTransition(bead.ID, models.BeadStatusOpen); err != nil {
+		t.Errorf("CheckTransition to current status: %v", err)
+	}
+}
+
 // TestManager_ClaimBead_NotFound tests claiming a non-existent bead
 func TestManager_ClaimBead_NotFound(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	err := manager.ClaimBead("nonexistent", "agent-1")
 	if err == nil {
@@ -410,7 +460,7 @@ func TestManager_ClaimBead_NotFound(t *testing.T) {
 
 // TestManager_AddDependency tests adding dependencies between beads
 func TestManager_AddDependency(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead1, _ := manager.CreateBead("Bead 1", "Desc", models.BeadPriorityP2, "task", "project1")
 	bead2, _ := manager.CreateBead("Bead 2", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -433,14 +483,15 @@ func TestManager_AddDependency(t *testing.T) {
 	}
 
 	// Verify edge in work graph
-	if len(manager.workGraph.Edges) != 1 {
-		t.Errorf("workGraph.Edges length = %d, want 1", len(manager.workGraph.Edges))
+	graph, _ := manager.GetWorkGraph("")
+	if len(graph.Edges) != 1 {
+		t.Errorf("work graph edges = %d, want 1", len(graph.Edges))
 	}
 }
 
 // TestManager_AddDependency_Parent tests parent-child relationship
 func TestManager_AddDependency_Parent(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	parent, _ := manager.CreateBead("Parent", "Desc", models.BeadPriorityP2, "task", "project1")
 	child, _ := manager.CreateBead("Child", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -463,7 +514,7 @@ func TestManager_AddDependency_Parent(t *testing.T) {
 
 // TestManager_AddDependency_Related tests related relationship
 func TestManager_AddDependency_Related(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead1, _ := manager.CreateBead("Bead 1", "Desc", models.BeadPriorityP2, "task", "project1")
 	bead2, _ := manager.CreateBead("Bead 2", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -486,7 +537,7 @@ func TestManager_AddDependency_Related(t *testing.T) {
 
 // TestManager_AddDependency_InvalidRelationship tests invalid relationship type
 func TestManager_AddDependency_InvalidRelationship(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead1, _ := manager.CreateBead("Bead 1", "Desc", models.BeadPriorityP2, "task", "project1")
 	bead2, _ := manager.CreateBead("Bead 2", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -499,7 +550,7 @@ func TestManager_AddDependency_InvalidRelationship(t *testing.T) {
 
 // TestManager_GetReadyBeads tests getting beads with no blockers
 func TestManager_GetReadyBeads(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead1, _ := manager.CreateBead("Bead 1", "Desc", models.BeadPriorityP2, "task", "project1")
 	bead2, _ := manager.CreateBead("Bead 2", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -534,7 +585,7 @@ func TestManager_GetReadyBeads(t *testing.T) {
 
 // TestManager_UnblockBead tests unblocking a bead
 func TestManager_UnblockBead(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	blocker, _ := manager.CreateBead("Blocker", "Desc", models.BeadPriorityP2, "task", "project1")
 	blocked, _ := manager.CreateBead("Blocked", "Desc", models.BeadPriorityP2, "task", "project1")
@@ -573,10 +624,11 @@ func TestManager_UnblockBead(t *testing.T) {
 
 // TestManager_GetWorkGraph tests getting the work graph
 func TestManager_GetWorkGraph(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead1, _ := manager.CreateBead("Bead 1", "Desc", models.BeadPriorityP2, "task", "project1")
 	bead2, _ := manager.CreateBead("Bead 2", "Desc", models.BeadPriorityP2, "task", "project2")
+	bead3, _ := manager.CreateBead("Bead 3", "Desc", models.BeadPriorityP2, "task", "project2")
 
 	manager.AddDependency(bead2.ID, bead1.ID, "related")
 
@@ -586,8 +638,8 @@ func TestManager_GetWorkGraph(t *testing.T) {
 		t.Fatalf("GetWorkGraph() error = %v", err)
 	}
 
-	if len(graph.Beads) != 2 {
-		t.Errorf("Graph beads count = %d, want 2", len(graph.Beads))
+	if len(graph.Beads) != 3 {
+		t.Errorf("Graph beads count = %d, want 3", len(graph.Beads))
 	}
 
 	if len(graph.Edges) != 1 {
@@ -600,13 +652,20 @@ func TestManager_GetWorkGraph(t *testing.T) {
 		t.Fatalf("GetWorkGraph(project1) error = %v", err)
 	}
 
-	if len(graph1.Beads) != 1 {
-		t.Errorf("Project1 graph beads count = %d, want 1", len(graph1.Beads))
+	// bead2 is in project1's graph as a bead related to one of its own.
+	if len(graph1.Beads) != 2 {
+		t.Errorf("Project1 graph beads count = %d, want 2", len(graph1.Beads))
 	}
 
 	if _, ok := graph1.Beads[bead1.ID]; !ok {
 		t.Error("Expected bead1 in project1 graph")
 	}
+	if _, ok := graph1.Beads[bead3.ID]; ok {
+		t.Error("Unrelated project2 bead in project1 graph")
+	}
+	if len(graph1.Edges) != 1 {
+		t.Errorf("Project1 graph edges count = %d, want the cross-project edge", len(graph1.Edges))
+	}
 }
 
 // Helper function tests
@@ -706,7 +765,7 @@ func TestBeadsRootDir(t *testing.T) {
 
 // TestExtractBeadID tests extracting bead IDs from output
 func TestExtractBeadID(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	tests := []struct {
 		name   string
@@ -752,7 +811,7 @@ func TestExtractBeadID(t *testing.T) {
 
 // TestExtractBeadIDWithPrefix tests extracting bead IDs with specific prefix
 func TestExtractBeadIDWithPrefix(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	tests := []struct {
 		name   string
@@ -792,14 +851,15 @@ func TestExtractBeadIDWithPrefix(t *testing.T) {
 
 // TestMatchesFilters tests filter matching logic
 func TestMatchesFilters(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	bead := &models.Bead{
-		ID:         "bd-001",
-		ProjectID:  "project1",
-		Status:     models.BeadStatusOpen,
-		Type:       "task",
-		AssignedTo: "agent-1",
+		ID:          "bd-001",
+		ProjectID:   "project1",
+		Status:      models.BeadStatusOpen,
+		Type:        "task",
+		AssignedTo:  "agent-1",
+		MilestoneID: "ms-1",
 	}
 
 	tests := []struct {
@@ -861,6 +921,34 @@ func TestMatchesFilters(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "Matching milestone_id",
+			filters: map[string]interface{}{
+				"milestone_id": "ms-1",
+			},
+			want: true,
+		},
+		{
+			name: "Non-matching milestone_id",
+			filters: map[string]interface{}{
+				"milestone_id": "ms-2",
+			},
+			want: false,
+		},
+		{
+			name: "Overdue filter on a bead without a due date",
+			filters: map[string]interface{}{
+				"overdue": true,
+			},
+			want: false,
+		},
+		{
+			name: "Not-overdue filter on a bead without a due date",
+			filters: map[string]interface{}{
+				"overdue": false,
+			},
+			want: true,
+		},
 		{
 			name: "Multiple matching filters",
 			filters: map[string]interface{}{
@@ -966,7 +1054,7 @@ func TestManager_LoadProjectPrefixFromConfig(t *testing.T) {
 		t.Fatalf("WriteFile() error = %v", err)
 	}
 
-	manager := NewManager("")
+	manager := newTestManager(t)
 	err := manager.LoadProjectPrefixFromConfig("test-project", beadsPath)
 	if err != nil {
 		t.Fatalf("LoadProjectPrefixFromConfig() error = %v", err)
@@ -980,7 +1068,7 @@ func TestManager_LoadProjectPrefixFromConfig(t *testing.T) {
 
 // TestManager_SyncFederation tests federation sync
 func TestManager_SyncFederation(t *testing.T) {
-	manager := NewManager("")
+	manager := newTestManager(t)
 
 	cfg := &config.BeadsFederationConfig{
 		Enabled: false,
@@ -0,0 +1,149 @@
+package beads
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// MovedFromKey is the context key recording the ID a moved bead had in
+// its previous project.
+const MovedFromKey = "moved_from"
+
+// SetRecordMover installs the function MoveBead calls to re-key the
+// records kept elsewhere for a bead, such as its conversations, under its
+// new ID. It runs before the bead itself moves, and when it fails the bead
+// stays where it was.
+func (m *Manager) SetRecordMover(move func(oldID, newID, projectID string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordMover = move
+}
+
+// MoveBead moves a bead to another project, under a new ID with that
+// project's prefix. Its relations come along: every bead related to it,
+// in either project, refers to the new ID. The bead keeps its context,
+// noting its old ID under moved_from, but not its assignee, since agents
+// work within a project; a bead in progress goes back to open. Records
+// kept elsewhere move first, through the record mover.
+func (m *Manager) MoveBead(id, projectID, actor string) (*models.Bead, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("%w: a target project is required", ErrInvalidMove)
+	}
+
+	m.mu.Lock()
+	bead, ok := m.beads[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("bead not found %s: %w", id, ErrBeadNotFound)
+	}
+	if bead.ProjectID == projectID {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is already in project %s", ErrInvalidMove, id, projectID)
+	}
+	before := snapshotBead(bead)
+	oldPath := m.beadFiles[id]
+
+	now := time.Now()
+	moved := snapshotBead(bead)
+	moved.ID = m.nextBeadID(projectID)
+	moved.ProjectID = projectID
+	moved.AssignedTo = ""
+	if moved.Status == models.BeadStatusInProgress {
+		moved.Status = models.BeadStatusOpen
+	}
+	if moved.Context == nil {
+		moved.Context = make(map[string]string)
+	}
+	moved.Context[MovedFromKey] = id
+	moved.UpdatedAt = now
+
+	if m.recordMover != nil {
+		if err := m.recordMover(id, moved.ID, projectID); err != nil {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("failed to move records of bead %s: %w", id, err)
+		}
+	}
+
+	delete(m.beads, id)
+	delete(m.workGraph.Beads, id)
+	delete(m.beadFiles, id)
+	m.beads[moved.ID] = &moved
+	m.workGraph.Beads[moved.ID] = &moved
+
+	touched := make(map[string]*models.Bead)
+	befores := make(map[string]models.Bead)
+	for otherID, other := range m.beads {
+		snap := snapshotBead(other)
+		if renameRelated(other, id, moved.ID) {
+			other.UpdatedAt = now
+			touched[otherID] = other
+			befores[otherID] = snap
+		}
+	}
+	m.workGraph.UpdatedAt = now
+	after := snapshotBead(&moved)
+	afters := make([]models.Bead, 0, len(touched))
+	for _, other := range touched {
+		afters = append(afters, snapshotBead(other))
+	}
+	m.mu.Unlock()
+
+	m.recordChanges(&before, &after, actor)
+	for i := range afters {
+		b := befores[afters[i].ID]
+		m.recordChanges(&b, &afters[i], actor)
+	}
+
+	if err := m.removeBeadFile(&before, oldPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove moved bead file: %v\n", err)
+	}
+	for _, b := range append([]*models.Bead{&moved}, beadsOf(touched)...) {
+		if err := m.SaveBeadToGit(context.Background(), b, m.GetProjectBeadsPath(b.ProjectID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save bead to git: %v\n", err)
+		}
+	}
+	return &moved, nil
+}
+
+// renameRelated points b's relations to oldID at newID, and reports
+// whether there were any.
+func renameRelated(b *models.Bead, oldID, newID string) bool {
+	changed := false
+	rename := func(ids []string) []string {
+		if !hasID(ids, oldID) {
+			return ids
+		}
+		changed = true
+		out := make([]string, len(ids))
+		for i, v := range ids {
+			if v == oldID {
+				v = newID
+			}
+			out[i] = v
+		}
+		return out
+	}
+	b.BlockedBy = rename(b.BlockedBy)
+	b.Blocks = rename(b.Blocks)
+	b.Children = rename(b.Children)
+	b.RelatedTo = rename(b.RelatedTo)
+	if b.Parent == oldID {
+		b.Parent, changed = newID, true
+	}
+	if b.DuplicateOf == oldID {
+		b.DuplicateOf, changed = newID, true
+	}
+	return changed
+}
+
+func beadsOf(m map[string]*models.Bead) []*models.Bead {
+	out := make([]*models.Bead, 0, len(m))
+	for _, b := range m {
+		out = append(out, b)
+	}
+	return out
+}
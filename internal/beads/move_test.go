@@ -0,0 +1,100 @@
+package beads
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_MoveBead(t *testing.T) {
+	manager := NewManager("")
+	manager.SetProjectBeadsPath("app", t.TempDir())
+	manager.SetProjectBeadsPath("lib", t.TempDir())
+	manager.SetProjectPrefix("app", "app")
+	manager.SetProjectPrefix("lib", "lib")
+
+	bug, _ := manager.CreateBead("Parser drops escapes", "", models.BeadPriorityP1, "bug", "app")
+	feature, _ := manager.CreateBead("Import CSV", "", models.BeadPriorityP2, "task", "app")
+	existing, _ := manager.CreateBead("Lib task", "", models.BeadPriorityP2, "task", "lib")
+	if err := manager.AddRelation(feature.ID, RelationBlockedBy, bug.ID, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	_ = manager.UpdateBead(bug.ID, map[string]interface{}{
+		"status":      models.BeadStatusInProgress,
+		"assigned_to": "agent-app",
+		"context":     map[string]string{"root_cause": "escape handling in lib"},
+	})
+	oldFile := manager.beadFiles[bug.ID]
+
+	moved, err := manager.MoveBead(bug.ID, "lib", "tester")
+	if err != nil {
+		t.Fatalf("MoveBead() error = %v", err)
+	}
+	if moved.ID == bug.ID || moved.ID == existing.ID || moved.ProjectID != "lib" || moved.ID[:4] != "lib-" {
+		t.Errorf("moved = %s in %s", moved.ID, moved.ProjectID)
+	}
+	if moved.Status != models.BeadStatusOpen || moved.AssignedTo != "" {
+		t.Errorf("moved bead is %s, assigned to %q", moved.Status, moved.AssignedTo)
+	}
+	if moved.Context["root_cause"] != "escape handling in lib" || moved.Context[MovedFromKey] != bug.ID {
+		t.Errorf("context = %v", moved.Context)
+	}
+	if _, err := manager.GetBead(bug.ID); err == nil {
+		t.Error("old ID still resolves")
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("old file left behind: %v", err)
+	}
+
+	// The feature now waits on the bead in the other project.
+	rel, _ := manager.GetRelations(feature.ID)
+	if len(rel.BlockedBy) != 1 || rel.BlockedBy[0] != moved.ID {
+		t.Errorf("feature blocked by %v, want [%s]", rel.BlockedBy, moved.ID)
+	}
+	if ready, _ := manager.GetReadyBeads("app"); len(ready) != 0 {
+		t.Errorf("ready in app = %d beads, want none while the lib bug is open", len(ready))
+	}
+	_ = manager.UpdateBead(moved.ID, map[string]interface{}{"status": models.BeadStatusClosed})
+	if ready, _ := manager.GetReadyBeads("app"); len(ready) != 1 || ready[0].ID != feature.ID {
+		t.Errorf("ready in app = %v, want the feature", ready)
+	}
+
+	if _, err := manager.MoveBead(moved.ID, "lib", "tester"); !errors.Is(err, ErrInvalidMove) {
+		t.Errorf("move to the same project error = %v, want ErrInvalidMove", err)
+	}
+	if _, err := manager.MoveBead("missing", "lib", "tester"); !errors.Is(err, ErrBeadNotFound) {
+		t.Errorf("missing bead error = %v, want ErrBeadNotFound", err)
+	}
+}
+
+func TestManager_MoveBead_RecordMoverFails(t *testing.T) {
+	manager := NewManager("")
+	manager.SetProjectBeadsPath("app", t.TempDir())
+	manager.SetProjectBeadsPath("lib", t.TempDir())
+	bead, _ := manager.CreateBead("Parser drops escapes", "", models.BeadPriorityP1, "bug", "app")
+
+	var movedFrom, movedTo string
+	manager.SetRecordMover(func(oldID, newID, projectID string) error {
+		movedFrom, movedTo = oldID, newID
+		return errors.New("database unavailable")
+	})
+	if _, err := manager.MoveBead(bead.ID, "lib", "tester"); err == nil {
+		t.Fatal("MoveBead() succeeded although its records couldn't move")
+	}
+	if movedFrom != bead.ID || movedTo == "" || movedTo == bead.ID {
+		t.Errorf("record mover called with %q -> %q", movedFrom, movedTo)
+	}
+	if got, err := manager.GetBead(bead.ID); err != nil || got.ProjectID != "app" {
+		t.Errorf("bead after the failed move = %+v, %v; want it still in app", got, err)
+	}
+	if _, err := manager.GetBead(movedTo); err == nil {
+		t.Errorf("new ID %s resolves after the failed move", movedTo)
+	}
+
+	manager.SetRecordMover(func(oldID, newID, projectID string) error { return nil })
+	if moved, err := manager.MoveBead(bead.ID, "lib", "tester"); err != nil || moved.ProjectID != "lib" {
+		t.Errorf("MoveBead() = %v, %v once the records move", moved, err)
+	}
+}
@@ -0,0 +1,88 @@
+package beads
+
+import (
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ListBeadsPage returns up to limit beads matching filters, ordered by
+// creation time (oldest first) and then ID. cursor is the value returned as
+// next from a previous call; an empty cursor starts from the beginning. next
+// is empty once the last page has been returned.
+//
+// The cursor encodes the position of the last bead on the page rather than
+// an offset, so beads created or closed between requests do not cause the
+// next page to skip or repeat entries.
+func (m *Manager) ListBeadsPage(filters map[string]interface{}, limit int, cursor string) (page []*models.Bead, next string, err error) {
+	var after *beadCursor
+	if cursor != "" {
+		c, err := decodeBeadCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	beads, err := m.ListBeads(filters)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(beads, func(i, j int) bool {
+		return beadCursorOf(beads[i]).less(beadCursorOf(beads[j]))
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(beads), func(i int) bool {
+			return after.less(beadCursorOf(beads[i]))
+		})
+	}
+	beads = beads[start:]
+
+	if limit <= 0 || limit >= len(beads) {
+		return beads, "", nil
+	}
+	page = beads[:limit]
+	return page, beadCursorOf(page[len(page)-1]).encode(), nil
+}
+
+type beadCursor struct {
+	createdAt int64
+	id        string
+}
+
+func beadCursorOf(b *models.Bead) beadCursor {
+	return beadCursor{createdAt: b.CreatedAt.UnixNano(), id: b.ID}
+}
+
+func (c beadCursor) less(o beadCursor) bool {
+	if c.createdAt != o.createdAt {
+		return c.createdAt < o.createdAt
+	}
+	return c.id < o.id
+}
+
+func (c beadCursor) encode() string {
+	raw := strconv.FormatInt(c.createdAt, 10) + ":" + c.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeBeadCursor(s string) (beadCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return beadCursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return beadCursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return beadCursor{}, ErrInvalidCursor
+	}
+	return beadCursor{createdAt: nanos, id: id}, nil
+}
@@ -0,0 +1,72 @@
+package beads
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_ListBeadsPage(t *testing.T) {
+	manager := newTestManager(t)
+
+	want := make(map[string]bool)
+	for i := 0; i < 7; i++ {
+		b, err := manager.CreateBead("Bead", "", models.BeadPriorityP2, "task", "loom")
+		if err != nil {
+			t.Fatalf("CreateBead failed: %v", err)
+		}
+		want[b.ID] = true
+	}
+	_, _ = manager.CreateBead("Elsewhere", "", models.BeadPriorityP2, "task", "other")
+
+	filters := map[string]interface{}{"project_id": "loom"}
+	seen := make(map[string]bool)
+	cursor := ""
+	pages := 0
+	for {
+		page, next, err := manager.ListBeadsPage(filters, 3, cursor)
+		if err != nil {
+			t.Fatalf("ListBeadsPage failed: %v", err)
+		}
+		pages++
+		for _, b := range page {
+			if seen[b.ID] {
+				t.Errorf("bead %s returned twice", b.ID)
+			}
+			seen[b.ID] = true
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+
+		// A bead created mid-iteration sorts after the cursor and must not
+		// shift the remaining pages.
+		if pages == 1 {
+			b, _ := manager.CreateBead("Late", "", models.BeadPriorityP2, "task", "loom")
+			want[b.ID] = true
+		}
+	}
+
+	if pages != 3 {
+		t.Errorf("got %d pages, want 3", pages)
+	}
+	if len(seen) != len(want) {
+		t.Errorf("saw %d beads, want %d", len(seen), len(want))
+	}
+	for id := range want {
+		if !seen[id] {
+			t.Errorf("bead %s never returned", id)
+		}
+	}
+
+	all, next, err := manager.ListBeadsPage(filters, 0, "")
+	if err != nil || next != "" || len(all) != len(want) {
+		t.Errorf("ListBeadsPage(limit=0) = (%d beads, %q, %v), want all %d beads", len(all), next, err, len(want))
+	}
+
+	if _, _, err := manager.ListBeadsPage(filters, 3, "not a cursor!"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
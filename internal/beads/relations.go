@@ -0,0 +1,343 @@
+package beads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Relation types between two beads. "A <relation> B" reads as "A is
+// blocked by B", "A's parent is B", "A is a duplicate of B", and so on.
+// Every relation is stored on both beads: blocked_by pairs with blocks,
+// parent with children, related_to with itself, and duplicate_of with the
+// original's list of duplicates, which is derived rather than stored.
+const (
+	RelationBlockedBy   = "blocked_by"
+	RelationBlocks      = "blocks"
+	RelationParent      = "parent"
+	RelationChild       = "child"
+	RelationRelatedTo   = "related_to"
+	RelationDuplicateOf = "duplicate_of"
+)
+
+// ErrInvalidRelation is returned for relations that can't be added or
+// removed: unknown types, a bead related to itself, parent cycles, and
+// relations that don't exist.
+var ErrInvalidRelation = errors.New("invalid relation")
+
+// Relations lists everything a bead is related to. Rollup summarizes the
+// bead's children and their children, when it has any.
+type Relations struct {
+	BeadID      string              `json:"bead_id"`
+	Parent      string              `json:"parent,omitempty"`
+	Children    []string            `json:"children"`
+	BlockedBy   []string            `json:"blocked_by"`
+	Blocks      []string            `json:"blocks"`
+	RelatedTo   []string            `json:"related_to"`
+	DuplicateOf string              `json:"duplicate_of,omitempty"`
+	Duplicates  []string            `json:"duplicates"`
+	Rollup      *models.ChildRollup `json:"rollup,omitempty"`
+}
+
+// AddRelation relates a bead to another. Marking a bead the parent of
+// another moves it out from under its previous parent, and a duplicate of
+// a duplicate is recorded against the original. Closing duplicates is left
+// to the caller.
+func (m *Manager) AddRelation(beadID, relation, targetID, actor string) error {
+	return m.changeRelation(beadID, relation, targetID, actor, true)
+}
+
+// RemoveRelation removes a relation added with AddRelation.
+func (m *Manager) RemoveRelation(beadID, relation, targetID, actor string) error {
+	return m.changeRelation(beadID, relation, targetID, actor, false)
+}
+
+func (m *Manager) changeRelation(beadID, relation, targetID, actor string, add bool) error {
+	// Child and blocks are parent and blocked_by seen from the other bead.
+	from, to := beadID, targetID
+	switch relation {
+	case RelationChild:
+		from, to, relation = targetID, beadID, RelationParent
+	case RelationBlocks:
+		from, to, relation = targetID, beadID, RelationBlockedBy
+	case RelationBlockedBy, RelationParent, RelationRelatedTo, RelationDuplicateOf:
+	default:
+		return fmt.Errorf("%w: unknown relation %q", ErrInvalidRelation, relation)
+	}
+	if from == to {
+		return fmt.Errorf("%w: a bead can't be related to itself", ErrInvalidRelation)
+	}
+
+	m.mu.Lock()
+	a, ok := m.beads[from]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("bead not found %s: %w", from, ErrBeadNotFound)
+	}
+	b, ok := m.beads[to]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("bead not found %s: %w", to, ErrBeadNotFound)
+	}
+	touched := map[string]*models.Bead{a.ID: a, b.ID: b}
+	before := map[string]models.Bead{a.ID: snapshotBead(a), b.ID: snapshotBead(b)}
+
+	var err error
+	if add {
+		err = m.link(a, b, relation, touched, before)
+	} else {
+		err = m.unlink(a, b, relation)
+	}
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	now := time.Now()
+	afters := make([]models.Bead, 0, len(touched))
+	for _, bead := range touched {
+		bead.UpdatedAt = now
+		afters = append(afters, snapshotBead(bead))
+	}
+	m.workGraph.UpdatedAt = now
+	m.mu.Unlock()
+
+	for i := range afters {
+		b := before[afters[i].ID]
+		m.recordChanges(&b, &afters[i], actor)
+	}
+	for _, bead := range touched {
+		if err := m.SaveBeadToGit(context.Background(), bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save bead to git: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// link adds a relation from a to b. Beads other than a and b that change
+// are added to touched, with their prior state in before. Must be called
+// with m.mu held.
+func (m *Manager) link(a, b *models.Bead, relation string, touched map[string]*models.Bead, before map[string]models.Bead) error {
+	switch relation {
+	case RelationBlockedBy:
+		if err := m.checkBlocker(a.ID, b.ID); err != nil {
+			return err
+		}
+		a.BlockedBy = withID(a.BlockedBy, b.ID)
+		b.Blocks = withID(b.Blocks, a.ID)
+		if a.Status == models.BeadStatusInProgress && b.Status != models.BeadStatusClosed {
+			a.Status = models.BeadStatusBlocked
+		}
+	case RelationParent:
+		for id := b.ID; id != ""; {
+			if id == a.ID {
+				return fmt.Errorf("%w: %s is already under %s", ErrInvalidRelation, b.ID, a.ID)
+			}
+			up, ok := m.beads[id]
+			if !ok {
+				break
+			}
+			id = up.Parent
+		}
+		if old, ok := m.beads[a.Parent]; ok && old.ID != b.ID {
+			if _, seen := touched[old.ID]; !seen {
+				before[old.ID] = snapshotBead(old)
+				touched[old.ID] = old
+			}
+			old.Children = withoutID(old.Children, a.ID)
+		}
+		a.Parent = b.ID
+		b.Children = withID(b.Children, a.ID)
+	case RelationRelatedTo:
+		a.RelatedTo = withID(a.RelatedTo, b.ID)
+		b.RelatedTo = withID(b.RelatedTo, a.ID)
+	case RelationDuplicateOf:
+		original := b
+		for seen := map[string]bool{}; original.DuplicateOf != "" && !seen[original.ID]; {
+			seen[original.ID] = true
+			next, ok := m.beads[original.DuplicateOf]
+			if !ok {
+				break
+			}
+			original = next
+		}
+		if original.ID == a.ID {
+			return fmt.Errorf("%w: %s is a duplicate of %s", ErrInvalidRelation, b.ID, a.ID)
+		}
+		a.DuplicateOf = original.ID
+	}
+	return nil
+}
+
+// unlink removes a relation from a to b. Must be called with m.mu held.
+func (m *Manager) unlink(a, b *models.Bead, relation string) error {
+	missing := fmt.Errorf("%w: %s is not %s %s", ErrInvalidRelation, a.ID, relation, b.ID)
+	switch relation {
+	case RelationBlockedBy:
+		if !hasID(a.BlockedBy, b.ID) {
+			return missing
+		}
+		a.BlockedBy = withoutID(a.BlockedBy, b.ID)
+		b.Blocks = withoutID(b.Blocks, a.ID)
+		if a.Status == models.BeadStatusBlocked && !m.hasOpenBlocker(a) {
+			a.Status = models.BeadStatusOpen
+		}
+	case RelationParent:
+		if a.Parent != b.ID {
+			return missing
+		}
+		a.Parent = ""
+		b.Children = withoutID(b.Children, a.ID)
+	case RelationRelatedTo:
+		if !hasID(a.RelatedTo, b.ID) {
+			return missing
+		}
+		a.RelatedTo = withoutID(a.RelatedTo, b.ID)
+		b.RelatedTo = withoutID(b.RelatedTo, a.ID)
+	case RelationDuplicateOf:
+		if a.DuplicateOf != b.ID {
+			return missing
+		}
+		a.DuplicateOf = ""
+	}
+	return nil
+}
+
+// hasOpenBlocker reports whether any bead blocking b is not closed.
+// Blockers not in the cache are treated as closed. Must be called with
+// m.mu held.
+func (m *Manager) hasOpenBlocker(b *models.Bead) bool {
+	for _, id := range b.BlockedBy {
+		if blocker, ok := m.beads[id]; ok && blocker.Status != models.BeadStatusClosed {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRelations returns everything a bead is related to.
+func (m *Manager) GetRelations(beadID string) (*Relations, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bead, ok := m.beads[beadID]
+	if !ok {
+		return nil, fmt.Errorf("bead not found %s: %w", beadID, ErrBeadNotFound)
+	}
+	rel := &Relations{
+		BeadID:      bead.ID,
+		Parent:      bead.Parent,
+		Children:    nonNil(bead.Children),
+		BlockedBy:   nonNil(bead.BlockedBy),
+		Blocks:      nonNil(bead.Blocks),
+		RelatedTo:   nonNil(bead.RelatedTo),
+		DuplicateOf: bead.DuplicateOf,
+		Duplicates:  []string{},
+	}
+	for id, other := range m.beads {
+		if other.DuplicateOf == bead.ID {
+			rel.Duplicates = append(rel.Duplicates, id)
+		}
+	}
+	sort.Strings(rel.Duplicates)
+	if len(bead.Children) > 0 {
+		rollup := m.rollup(bead)
+		rel.Rollup = &rollup
+	}
+	return rel, nil
+}
+
+// rollup counts the descendants of a bead by status. Children not in the
+// cache are counted as closed, like blockers; trashed ones are skipped.
+// Must be called with m.mu held.
+func (m *Manager) rollup(bead *models.Bead) models.ChildRollup {
+	var r models.ChildRollup
+	seen := map[string]bool{bead.ID: true}
+	queue := append([]string(nil), bead.Children...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, trashed := m.trash[id]; trashed {
+			continue
+		}
+		r.Total++
+		child, ok := m.beads[id]
+		if !ok {
+			r.Closed++
+			continue
+		}
+		switch child.Status {
+		case models.BeadStatusClosed:
+			r.Closed++
+		case models.BeadStatusInProgress:
+			r.InProgress++
+		case models.BeadStatusBlocked:
+			r.Blocked++
+		default:
+			r.Open++
+		}
+		queue = append(queue, child.Children...)
+	}
+	if r.Total > 0 {
+		r.PercentComplete = float64(r.Closed) / float64(r.Total) * 100
+	}
+	return r
+}
+
+// relatedIDs returns the IDs of every bead b is related to.
+func relatedIDs(b *models.Bead) []string {
+	ids := make([]string, 0, len(b.BlockedBy)+len(b.Blocks)+len(b.Children)+len(b.RelatedTo)+2)
+	ids = append(append(append(append(ids, b.BlockedBy...), b.Blocks...), b.Children...), b.RelatedTo...)
+	if b.Parent != "" {
+		ids = append(ids, b.Parent)
+	}
+	if b.DuplicateOf != "" {
+		ids = append(ids, b.DuplicateOf)
+	}
+	return ids
+}
+
+// hasID reports whether ids contains id.
+func hasID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// withID returns a copy of ids with id appended, unless already present.
+func withID(ids []string, id string) []string {
+	if hasID(ids, id) {
+		return ids
+	}
+	out := make([]string, 0, len(ids)+1)
+	return append(append(out, ids...), id)
+}
+
+// withoutID returns a copy of ids without id.
+func withoutID(ids []string, id string) []string {
+	out := make([]string, 0, len(ids))
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
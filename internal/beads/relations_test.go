@@ -0,0 +1,124 @@
+package beads
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_AddRelation(t *testing.T) {
+	manager := newTestManager(t)
+
+	a, _ := manager.CreateBead("A", "", models.BeadPriorityP2, "task", "project1")
+	b, _ := manager.CreateBead("B", "", models.BeadPriorityP2, "task", "project1")
+
+	if err := manager.AddRelation(a.ID, RelationRelatedTo, b.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(related_to) error = %v", err)
+	}
+	rel, _ := manager.GetRelations(b.ID)
+	if len(rel.RelatedTo) != 1 || rel.RelatedTo[0] != a.ID {
+		t.Errorf("related_to is not symmetric: %+v", rel)
+	}
+
+	// "blocks" is stored as blocked_by on the other bead.
+	if err := manager.AddRelation(a.ID, RelationBlocks, b.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(blocks) error = %v", err)
+	}
+	rel, _ = manager.GetRelations(b.ID)
+	if len(rel.BlockedBy) != 1 || rel.BlockedBy[0] != a.ID {
+		t.Errorf("BlockedBy = %v, want [%s]", rel.BlockedBy, a.ID)
+	}
+	if err := manager.RemoveRelation(b.ID, RelationBlockedBy, a.ID, "tester"); err != nil {
+		t.Fatalf("RemoveRelation(blocked_by) error = %v", err)
+	}
+	if err := manager.RemoveRelation(b.ID, RelationBlockedBy, a.ID, "tester"); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("removing a missing relation error = %v, want ErrInvalidRelation", err)
+	}
+
+	if err := manager.AddRelation(a.ID, RelationRelatedTo, a.ID, "tester"); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("self relation error = %v, want ErrInvalidRelation", err)
+	}
+	if err := manager.AddRelation(a.ID, "mentions", b.ID, "tester"); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("unknown relation error = %v, want ErrInvalidRelation", err)
+	}
+	if err := manager.AddRelation(a.ID, RelationParent, "missing", "tester"); !errors.Is(err, ErrBeadNotFound) {
+		t.Errorf("missing target error = %v, want ErrBeadNotFound", err)
+	}
+}
+
+func TestManager_AddRelation_Parent(t *testing.T) {
+	manager := newTestManager(t)
+
+	epic, _ := manager.CreateBead("Epic", "", models.BeadPriorityP1, "epic", "project1")
+	other, _ := manager.CreateBead("Other epic", "", models.BeadPriorityP1, "epic", "project1")
+	task, _ := manager.CreateBead("Task", "", models.BeadPriorityP2, "task", "project1")
+	sub, _ := manager.CreateBead("Subtask", "", models.BeadPriorityP2, "task", "project1")
+
+	if err := manager.AddRelation(task.ID, RelationParent, epic.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(parent) error = %v", err)
+	}
+	if err := manager.AddRelation(task.ID, RelationChild, sub.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(child) error = %v", err)
+	}
+	if err := manager.AddRelation(epic.ID, RelationParent, sub.ID, "tester"); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("parent cycle error = %v, want ErrInvalidRelation", err)
+	}
+
+	_ = manager.UpdateBead(sub.ID, map[string]interface{}{"status": models.BeadStatusClosed})
+	_ = manager.UpdateBead(task.ID, map[string]interface{}{"status": models.BeadStatusInProgress})
+
+	rel, _ := manager.GetRelations(epic.ID)
+	want := models.ChildRollup{Total: 2, InProgress: 1, Closed: 1, PercentComplete: 50}
+	if rel.Rollup == nil || *rel.Rollup != want {
+		t.Errorf("Rollup = %+v, want %+v", rel.Rollup, want)
+	}
+
+	// Moving the task to another epic takes it out of the first one.
+	if err := manager.AddRelation(task.ID, RelationParent, other.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(parent) error = %v", err)
+	}
+	rel, _ = manager.GetRelations(epic.ID)
+	if len(rel.Children) != 0 || rel.Rollup != nil {
+		t.Errorf("old parent still has children: %+v", rel)
+	}
+}
+
+func TestManager_AddRelation_DuplicateOf(t *testing.T) {
+	manager := newTestManager(t)
+
+	original, _ := manager.CreateBead("Login fails", "", models.BeadPriorityP1, "bug", "project1")
+	dup, _ := manager.CreateBead("Can't log in", "", models.BeadPriorityP1, "bug", "project1")
+	dup2, _ := manager.CreateBead("Login broken", "", models.BeadPriorityP1, "bug", "project1")
+
+	if err := manager.AddRelation(dup.ID, RelationDuplicateOf, original.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(duplicate_of) error = %v", err)
+	}
+	// A duplicate of a duplicate points at the original.
+	if err := manager.AddRelation(dup2.ID, RelationDuplicateOf, dup.ID, "tester"); err != nil {
+		t.Fatalf("AddRelation(duplicate_of) error = %v", err)
+	}
+	bead, _ := manager.GetBead(dup2.ID)
+	if bead.DuplicateOf != original.ID {
+		t.Errorf("DuplicateOf = %q, want %q", bead.DuplicateOf, original.ID)
+	}
+	if err := manager.AddRelation(original.ID, RelationDuplicateOf, dup2.ID, "tester"); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("duplicate cycle error = %v, want ErrInvalidRelation", err)
+	}
+
+	rel, _ := manager.GetRelations(original.ID)
+	if len(rel.Duplicates) != 2 {
+		t.Errorf("Duplicates = %v, want 2", rel.Duplicates)
+	}
+
+	graph, _ := manager.GetWorkGraph("project1")
+	duplicates := 0
+	for _, e := range graph.Edges {
+		if e.Relationship == "duplicate" && e.To == original.ID {
+			duplicates++
+		}
+	}
+	if duplicates != 2 {
+		t.Errorf("duplicate edges = %d, want 2 in %+v", duplicates, graph.Edges)
+	}
+}
@@ -0,0 +1,146 @@
+package beads
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// SearchQuery describes a full-text bead search with optional filters.
+// Text is split on whitespace; every term must appear (case-insensitive)
+// in the bead's title, description, or context keys/values.
+type SearchQuery struct {
+	Text          string
+	ProjectID     string
+	ProjectIDs    []string // when non-nil, bead must belong to one of these projects
+	Statuses      []models.BeadStatus
+	Tags          []string // bead must carry every listed tag
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Limit         int
+}
+
+// SearchResult is a bead matched by SearchBeads along with its relevance score.
+type SearchResult struct {
+	Bead  *models.Bead `json:"bead"`
+	Score int          `json:"score"`
+}
+
+// Search scoring weights: a term in the title is worth more than one buried
+// in a description or in agent-written context.
+const (
+	searchWeightTitle       = 10
+	searchWeightDescription = 3
+	searchWeightContext     = 1
+)
+
+// SearchBeads returns beads matching the query, ordered by relevance and then
+// by most recent update. An empty Text matches every bead that passes the filters.
+func (m *Manager) SearchBeads(q SearchQuery) []SearchResult {
+	terms := strings.Fields(strings.ToLower(q.Text))
+
+	m.mu.RLock()
+	results := make([]SearchResult, 0)
+	for _, bead := range m.beads {
+		if !matchesSearchFilters(bead, q) {
+			continue
+		}
+		score, ok := scoreBead(bead, terms)
+		if !ok {
+			continue
+		}
+		results = append(results, SearchResult{Bead: bead, Score: score})
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Bead.UpdatedAt.After(results[j].Bead.UpdatedAt)
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+func matchesSearchFilters(bead *models.Bead, q SearchQuery) bool {
+	if q.ProjectID != "" && bead.ProjectID != q.ProjectID {
+		return false
+	}
+	if q.ProjectIDs != nil && !hasID(q.ProjectIDs, bead.ProjectID) {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		match := false
+		for _, s := range q.Statuses {
+			if bead.Status == s {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	for _, want := range q.Tags {
+		found := false
+		for _, tag := range bead.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.CreatedAfter != nil && bead.CreatedAt.Before(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && bead.CreatedAt.After(*q.CreatedBefore) {
+		return false
+	}
+	if q.UpdatedAfter != nil && bead.UpdatedAt.Before(*q.UpdatedAfter) {
+		return false
+	}
+	if q.UpdatedBefore != nil && bead.UpdatedAt.After(*q.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// scoreBead reports whether every term appears somewhere in the bead, and
+// if so how strongly it matched.
+func scoreBead(bead *models.Bead, terms []string) (int, bool) {
+	if len(terms) == 0 {
+		return 0, true
+	}
+
+	title := strings.ToLower(bead.Title)
+	description := strings.ToLower(bead.Description)
+
+	total := 0
+	for _, term := range terms {
+		score := 0
+		score += strings.Count(title, term) * searchWeightTitle
+		score += strings.Count(description, term) * searchWeightDescription
+		for k, v := range bead.Context {
+			if strings.Contains(strings.ToLower(k), term) {
+				score += searchWeightContext
+			}
+			score += strings.Count(strings.ToLower(v), term) * searchWeightContext
+		}
+		if score == 0 {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
@@ -0,0 +1,62 @@
+package beads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_SearchBeads(t *testing.T) {
+	manager := newTestManager(t)
+
+	b1, _ := manager.CreateBead("Fix dispatch loop", "The dispatcher spins on blocked beads", models.BeadPriorityP1, "bug", "loom")
+	b2, _ := manager.CreateBead("Document provider setup", "Explain how dispatch picks a provider", models.BeadPriorityP2, "task", "loom")
+	b3, _ := manager.CreateBead("Unrelated", "Nothing to see", models.BeadPriorityP3, "task", "other")
+	_ = manager.UpdateBead(b3.ID, map[string]interface{}{
+		"context": map[string]string{"last_run_error": "dispatch timeout"},
+		"tags":    []string{"infra"},
+	})
+
+	results := manager.SearchBeads(SearchQuery{Text: "dispatch"})
+	if len(results) != 3 {
+		t.Fatalf("SearchBeads(dispatch) returned %d results, want 3", len(results))
+	}
+	if results[0].Bead.ID != b1.ID {
+		t.Errorf("top result = %s, want title match %s", results[0].Bead.ID, b1.ID)
+	}
+
+	results = manager.SearchBeads(SearchQuery{Text: "dispatch provider"})
+	if len(results) != 1 || results[0].Bead.ID != b2.ID {
+		t.Errorf("SearchBeads(dispatch provider) = %v, want only %s", results, b2.ID)
+	}
+
+	results = manager.SearchBeads(SearchQuery{Text: "dispatch", ProjectID: "other", Tags: []string{"INFRA"}})
+	if len(results) != 1 || results[0].Bead.ID != b3.ID {
+		t.Errorf("SearchBeads with project+tag filter = %v, want only %s", results, b3.ID)
+	}
+
+	results = manager.SearchBeads(SearchQuery{Text: "dispatch", ProjectIDs: []string{"other"}})
+	if len(results) != 1 || results[0].Bead.ID != b3.ID {
+		t.Errorf("SearchBeads restricted to project other = %v, want only %s", results, b3.ID)
+	}
+	if results = manager.SearchBeads(SearchQuery{ProjectIDs: []string{}}); len(results) != 0 {
+		t.Errorf("SearchBeads with an empty project set returned %d results, want 0", len(results))
+	}
+
+	results = manager.SearchBeads(SearchQuery{Statuses: []models.BeadStatus{models.BeadStatusClosed}})
+	if len(results) != 0 {
+		t.Errorf("SearchBeads(status=closed) returned %d results, want 0", len(results))
+	}
+
+	future := time.Now().Add(time.Hour)
+	results = manager.SearchBeads(SearchQuery{CreatedAfter: &future})
+	if len(results) != 0 {
+		t.Errorf("SearchBeads(created_after=future) returned %d results, want 0", len(results))
+	}
+
+	results = manager.SearchBeads(SearchQuery{Limit: 2})
+	if len(results) != 2 {
+		t.Errorf("SearchBeads(limit=2) returned %d results, want 2", len(results))
+	}
+}
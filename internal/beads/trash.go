@@ -0,0 +1,196 @@
+package beads
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// DefaultTrashRetention is how long deleted beads stay in the trash when
+// the config doesn't say.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashBead soft-deletes a bead: it leaves every listing, search, and the
+// work graph, but is kept, with its file, until restored or purged.
+func (m *Manager) TrashBead(id, deletedBy string) (*models.Bead, error) {
+	m.mu.Lock()
+	bead, ok := m.beads[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("bead not found %s: %w", id, ErrBeadNotFound)
+	}
+	before := snapshotBead(bead)
+	now := time.Now()
+	bead.DeletedAt = &now
+	bead.DeletedBy = deletedBy
+	bead.UpdatedAt = now
+	delete(m.beads, id)
+	delete(m.workGraph.Beads, id)
+	m.trash[id] = bead
+	m.workGraph.UpdatedAt = now
+	after := snapshotBead(bead)
+	m.mu.Unlock()
+
+	m.recordChanges(&before, &after, deletedBy)
+
+	if err := m.SaveBeadToGit(context.Background(), bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save bead to git: %v\n", err)
+	}
+	return bead, nil
+}
+
+// RestoreBead takes a bead out of the trash, unchanged apart from its
+// deletion marks.
+func (m *Manager) RestoreBead(id, restoredBy string) (*models.Bead, error) {
+	m.mu.Lock()
+	bead, ok := m.trash[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("bead not in trash %s: %w", id, ErrBeadNotFound)
+	}
+	if _, exists := m.beads[id]; exists {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("bead %s already exists", id)
+	}
+	before := snapshotBead(bead)
+	bead.DeletedAt = nil
+	bead.DeletedBy = ""
+	bead.UpdatedAt = time.Now()
+	delete(m.trash, id)
+	m.beads[id] = bead
+	m.workGraph.Beads[id] = bead
+	m.workGraph.UpdatedAt = time.Now()
+	after := snapshotBead(bead)
+	m.mu.Unlock()
+
+	m.recordChanges(&before, &after, restoredBy)
+
+	if err := m.SaveBeadToGit(context.Background(), bead, m.GetProjectBeadsPath(bead.ProjectID)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save bead to git: %v\n", err)
+	}
+	return bead, nil
+}
+
+// ListTrash returns the deleted beads, most recently deleted first,
+// optionally limited to a project.
+func (m *Manager) ListTrash(projectID string) []*models.Bead {
+	m.mu.RLock()
+	trashed := make([]*models.Bead, 0, len(m.trash))
+	for _, bead := range m.trash {
+		if projectID == "" || bead.ProjectID == projectID {
+			trashed = append(trashed, bead)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(*trashed[j].DeletedAt)
+	})
+	return trashed
+}
+
+// GetTrashedBead returns a bead from the trash.
+func (m *Manager) GetTrashedBead(id string) (*models.Bead, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bead, ok := m.trash[id]
+	if !ok {
+		return nil, fmt.Errorf("bead not in trash %s: %w", id, ErrBeadNotFound)
+	}
+	return bead, nil
+}
+
+// PurgeBead deletes a bead for good, whether in the trash or not, along
+// with its file.
+func (m *Manager) PurgeBead(id string) error {
+	m.mu.Lock()
+	bead, ok := m.trash[id]
+	if ok {
+		delete(m.trash, id)
+	} else if bead, ok = m.beads[id]; ok {
+		delete(m.beads, id)
+		delete(m.workGraph.Beads, id)
+		m.workGraph.UpdatedAt = time.Now()
+	}
+	path := m.beadFiles[id]
+	delete(m.beadFiles, id)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("bead not found %s: %w", id, ErrBeadNotFound)
+	}
+
+	if err := m.removeBeadFile(bead, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove bead file: %v\n", err)
+	}
+	return nil
+}
+
+// PurgeTrash purges the beads deleted before cutoff and returns how many
+// there were.
+func (m *Manager) PurgeTrash(cutoff time.Time) int {
+	m.mu.RLock()
+	var expired []string
+	for id, bead := range m.trash {
+		if bead.DeletedAt.Before(cutoff) {
+			expired = append(expired, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	purged := 0
+	for _, id := range expired {
+		if m.PurgeBead(id) == nil {
+			purged++
+		}
+	}
+	return purged
+}
+
+// removeBeadFile deletes a purged bead's file, and with git storage
+// commits the deletion.
+func (m *Manager) removeBeadFile(bead *models.Bead, path string) error {
+	if path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	m.mu.RLock()
+	gitConfig, ok := m.gitConfigs[bead.ProjectID]
+	m.mu.RUnlock()
+	if !ok || gitConfig == nil || !gitConfig.UseGitStorage || gitConfig.WorktreeManager == nil || path == "" {
+		return nil
+	}
+
+	gitLock := m.projectGitLock(bead.ProjectID)
+	gitLock.Lock()
+	defer gitLock.Unlock()
+
+	type pathGetter interface {
+		GetWorktreePath(string, string) string
+	}
+	wt, ok := gitConfig.WorktreeManager.(pathGetter)
+	if !ok {
+		return fmt.Errorf("worktree manager does not support GetWorktreePath")
+	}
+	beadsWorktree := wt.GetWorktreePath(bead.ProjectID, "beads")
+
+	// As in SaveBeadToGit, a file outside the beads worktree has its copy
+	// at .beads/beads/ inside it.
+	beadFile, err := filepath.Rel(beadsWorktree, path)
+	if err != nil || strings.HasPrefix(beadFile, "..") {
+		beadFile = filepath.Join(".beads", "beads", filepath.Base(path))
+		if err := os.Remove(filepath.Join(beadsWorktree, beadFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("Delete bead %s: %s", bead.ID, bead.Title)
+	return m.commitBeadChange(gitConfig, beadsWorktree, []string{"rm", "--cached", "--ignore-unmatch", "-q", "--", beadFile}, message)
+}
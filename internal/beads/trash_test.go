@@ -0,0 +1,121 @@
+package beads
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestManager_TrashAndRestore(t *testing.T) {
+	manager := newTestManager(t)
+	manager.SetProjectBeadsPath("project1", t.TempDir())
+
+	bead, _ := manager.CreateBead("Doomed", "", models.BeadPriorityP2, "task", "project1")
+	if _, err := manager.TrashBead(bead.ID, "user-1"); err != nil {
+		t.Fatalf("TrashBead() error = %v", err)
+	}
+
+	if list, _ := manager.ListBeads(nil); len(list) != 0 {
+		t.Errorf("trashed bead still listed: %v", list)
+	}
+	if err := manager.UpdateBead(bead.ID, map[string]interface{}{"title": "x"}); !errors.Is(err, ErrBeadNotFound) {
+		t.Errorf("UpdateBead() on trashed bead error = %v, want %v", err, ErrBeadNotFound)
+	}
+	trash := manager.ListTrash("project1")
+	if len(trash) != 1 || trash[0].DeletedBy != "user-1" || trash[0].DeletedAt == nil {
+		t.Fatalf("ListTrash() = %+v", trash)
+	}
+	if len(manager.ListTrash("other")) != 0 {
+		t.Error("ListTrash() should filter by project")
+	}
+
+	// A new bead must not take the trashed bead's ID.
+	other, _ := manager.CreateBead("Other", "", models.BeadPriorityP2, "task", "project1")
+	if other.ID == bead.ID {
+		t.Fatalf("new bead reused trashed ID %s", bead.ID)
+	}
+
+	restored, err := manager.RestoreBead(bead.ID, "")
+	if err != nil {
+		t.Fatalf("RestoreBead() error = %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Title != "Doomed" {
+		t.Errorf("restored bead = %+v", restored)
+	}
+	if _, err := manager.GetBead(bead.ID); err != nil {
+		t.Errorf("GetBead() after restore: %v", err)
+	}
+	if _, err := manager.RestoreBead(bead.ID, ""); !errors.Is(err, ErrBeadNotFound) {
+		t.Errorf("second RestoreBead() error = %v, want %v", err, ErrBeadNotFound)
+	}
+}
+
+func TestManager_TrashSurvivesReload(t *testing.T) {
+	dir := t.TempDir()
+	manager := newTestManager(t)
+	manager.SetBackend("yaml")
+	manager.SetProjectBeadsPath("project1", dir)
+
+	bead, _ := manager.CreateBead("Doomed", "", models.BeadPriorityP2, "task", "project1")
+	_, _ = manager.TrashBead(bead.ID, "")
+
+	reloaded := newTestManager(t)
+	reloaded.SetBackend("yaml")
+	if err := reloaded.LoadBeadsFromFilesystem("project1", dir); err != nil {
+		t.Fatalf("LoadBeadsFromFilesystem() error = %v", err)
+	}
+	if _, err := reloaded.GetTrashedBead(bead.ID); err != nil {
+		t.Errorf("trashed bead not in trash after reload: %v", err)
+	}
+	if list, _ := reloaded.ListBeads(nil); len(list) != 0 {
+		t.Errorf("trashed bead listed after reload: %v", list)
+	}
+}
+
+func TestManager_PurgeTrash(t *testing.T) {
+	manager := newTestManager(t)
+	manager.SetProjectBeadsPath("project1", t.TempDir())
+
+	old, _ := manager.CreateBead("Old", "", models.BeadPriorityP2, "task", "project1")
+	recent, _ := manager.CreateBead("Recent", "", models.BeadPriorityP2, "task", "project1")
+	_, _ = manager.TrashBead(old.ID, "")
+	_, _ = manager.TrashBead(recent.ID, "")
+	past := time.Now().Add(-48 * time.Hour)
+	manager.trash[old.ID].DeletedAt = &past
+
+	manager.mu.RLock()
+	oldFile := manager.beadFiles[old.ID]
+	manager.mu.RUnlock()
+
+	if n := manager.PurgeTrash(time.Now().Add(-24 * time.Hour)); n != 1 {
+		t.Fatalf("PurgeTrash() = %d, want 1", n)
+	}
+	if _, err := manager.GetTrashedBead(old.ID); err == nil {
+		t.Error("expired bead still in trash")
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Errorf("purged bead file still exists: %v", err)
+	}
+	if _, err := manager.GetTrashedBead(recent.ID); err != nil {
+		t.Errorf("recent bead purged early: %v", err)
+	}
+}
+
+func TestManager_PurgeBead_Live(t *testing.T) {
+	manager := newTestManager(t)
+	manager.SetProjectBeadsPath("project1", t.TempDir())
+
+	bead, _ := manager.CreateBead("Live", "", models.BeadPriorityP2, "task", "project1")
+	if err := manager.PurgeBead(bead.ID); err != nil {
+		t.Fatalf("PurgeBead() error = %v", err)
+	}
+	if _, err := manager.RestoreBead(bead.ID, ""); err == nil {
+		t.Error("purged bead should not be restorable")
+	}
+	if err := manager.PurgeBead(bead.ID); !errors.Is(err, ErrBeadNotFound) {
+		t.Errorf("second PurgeBead() error = %v, want %v", err, ErrBeadNotFound)
+	}
+}
@@ -0,0 +1,235 @@
+// Package beadtype is the registry of admin-defined bead types (incident,
+// spike, chore, ...). A registered type gives its beads a default priority,
+// context fields that must be filled in when one is created, the workflow
+// the dispatcher starts for it, and an icon and color for the UI. Types
+// that aren't registered are accepted as free strings, as before.
+package beadtype
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+var (
+	// ErrNotFound is returned when a bead type is not registered.
+	ErrNotFound = errors.New("bead type not found")
+	// ErrInvalidBead is returned when a new bead does not meet its type's
+	// requirements.
+	ErrInvalidBead = errors.New("invalid bead")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Type is a registered bead type.
+type Type struct {
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	DefaultPriority *int      `json:"default_priority,omitempty"`
+	RequiredContext []string  `json:"required_context"`
+	DefaultWorkflow string    `json:"default_workflow,omitempty"`
+	Icon            string    `json:"icon,omitempty"`
+	Color           string    `json:"color,omitempty"`
+	CreatedBy       string    `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// SetRequest is the body accepted when defining a bead type. DefaultWorkflow
+// is a workflow type such as "bug", "feature", or "ui".
+type SetRequest struct {
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	DefaultPriority *int     `json:"default_priority"`
+	RequiredContext []string `json:"required_context"`
+	DefaultWorkflow string   `json:"default_workflow"`
+	Icon            string   `json:"icon"`
+	Color           string   `json:"color"`
+}
+
+// Manager persists bead types. Types are cached in memory since they are
+// looked up for every bead created and dispatched.
+type Manager struct {
+	db  *database.Database
+	now func() time.Time
+
+	mu    sync.RWMutex
+	types map[string]*Type // nil until loaded
+}
+
+// NewManager creates a bead type manager. Returns nil when db is nil.
+func NewManager(db *database.Database) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db, now: time.Now}
+}
+
+// Set defines a bead type, replacing an existing type of the same name.
+func (m *Manager) Set(req SetRequest, createdBy string) (*Type, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid name %q: use up to 32 lowercase letters, digits, '-' or '_', starting with a letter", req.Name)
+	}
+	if p := req.DefaultPriority; p != nil && (*p < int(models.BeadPriorityP0) || *p > int(models.BeadPriorityP3)) {
+		return nil, fmt.Errorf("default_priority must be between 0 and 3")
+	}
+	var required []string
+	seen := make(map[string]bool)
+	for _, key := range req.RequiredContext {
+		key = strings.TrimSpace(key)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			required = append(required, key)
+		}
+	}
+
+	now := m.now().UTC()
+	row := &database.BeadType{
+		Name:            name,
+		Description:     req.Description,
+		DefaultPriority: req.DefaultPriority,
+		RequiredContext: required,
+		DefaultWorkflow: strings.TrimSpace(req.DefaultWorkflow),
+		Icon:            req.Icon,
+		Color:           req.Color,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := m.db.UpsertBeadType(row); err != nil {
+		return nil, err
+	}
+	m.invalidate()
+	return m.Get(name)
+}
+
+// Get returns a registered bead type.
+func (m *Manager) Get(name string) (*Type, error) {
+	types, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return t, nil
+}
+
+// List returns the registered bead types, ordered by name.
+func (m *Manager) List() ([]*Type, error) {
+	types, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Type, 0, len(types))
+	for _, t := range types {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Delete unregisters a bead type. Existing beads of the type are kept.
+func (m *Manager) Delete(name string) error {
+	if _, err := m.Get(name); err != nil {
+		return err
+	}
+	if err := m.db.DeleteBeadType(name); err != nil {
+		return err
+	}
+	m.invalidate()
+	return nil
+}
+
+// Resolve checks a new bead of beadType against its type and returns the
+// priority to create it with: priority when given, else the type's default,
+// else P2. It returns an ErrInvalidBead error when context is missing a
+// required field. Unregistered types are not checked.
+func (m *Manager) Resolve(beadType string, priority *int, context map[string]string) (models.BeadPriority, error) {
+	resolved := models.BeadPriorityP2
+	if priority != nil {
+		resolved = models.BeadPriority(*priority)
+	}
+	t, err := m.Get(beadType)
+	if errors.Is(err, ErrNotFound) {
+		return resolved, nil
+	}
+	if err != nil {
+		return resolved, err
+	}
+	if priority == nil && t.DefaultPriority != nil {
+		resolved = models.BeadPriority(*t.DefaultPriority)
+	}
+	var missing []string
+	for _, key := range t.RequiredContext {
+		if strings.TrimSpace(context[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return resolved, fmt.Errorf("%w: %s beads require context %s", ErrInvalidBead, t.Name, strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+// Workflow returns the default workflow type of a bead type, or "" when
+// the type is not registered or has none.
+func (m *Manager) Workflow(beadType string) string {
+	t, err := m.Get(beadType)
+	if err != nil {
+		return ""
+	}
+	return t.DefaultWorkflow
+}
+
+func (m *Manager) load() (map[string]*Type, error) {
+	m.mu.RLock()
+	types := m.types
+	m.mu.RUnlock()
+	if types != nil {
+		return types, nil
+	}
+
+	rows, err := m.db.ListBeadTypes()
+	if err != nil {
+		return nil, err
+	}
+	types = make(map[string]*Type, len(rows))
+	for _, row := range rows {
+		required := row.RequiredContext
+		if required == nil {
+			required = []string{}
+		}
+		types[row.Name] = &Type{
+			Name:            row.Name,
+			Description:     row.Description,
+			DefaultPriority: row.DefaultPriority,
+			RequiredContext: required,
+			DefaultWorkflow: row.DefaultWorkflow,
+			Icon:            row.Icon,
+			Color:           row.Color,
+			CreatedBy:       row.CreatedBy,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+		}
+	}
+	m.mu.Lock()
+	m.types = types
+	m.mu.Unlock()
+	return types, nil
+}
+
+func (m *Manager) invalidate() {
+	m.mu.Lock()
+	m.types = nil
+	m.mu.Unlock()
+}
@@ -0,0 +1,112 @@
+package beadtype
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	db := dbtest.New(t)
+	m := NewManager(db)
+	m.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	return m
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestManager_SetValidatesAndReplaces(t *testing.T) {
+	m := newTestManager(t)
+
+	bad := []SetRequest{
+		{},
+		{Name: "1st"},
+		{Name: "has space"},
+		{Name: "incident", DefaultPriority: intPtr(4)},
+		{Name: "incident", DefaultPriority: intPtr(-1)},
+	}
+	for _, req := range bad {
+		if _, err := m.Set(req, "admin"); err == nil {
+			t.Errorf("Set(%+v): expected error", req)
+		}
+	}
+
+	typ, err := m.Set(SetRequest{
+		Name:            " Incident ",
+		DefaultPriority: intPtr(0),
+		RequiredContext: []string{"severity", " ", "severity", "service"},
+		DefaultWorkflow: "bug",
+		Icon:            "🚨",
+	}, "admin")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if typ.Name != "incident" || *typ.DefaultPriority != 0 || len(typ.RequiredContext) != 2 || typ.Icon != "🚨" || typ.CreatedBy != "admin" {
+		t.Fatalf("unexpected type: %+v", typ)
+	}
+
+	if _, err := m.Set(SetRequest{Name: "incident", DefaultWorkflow: "ui"}, "other"); err != nil {
+		t.Fatalf("Set replace failed: %v", err)
+	}
+	typ, err = m.Get("incident")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if typ.DefaultPriority != nil || len(typ.RequiredContext) != 0 || typ.DefaultWorkflow != "ui" || typ.CreatedBy != "admin" {
+		t.Errorf("replaced type = %+v", typ)
+	}
+	if m.Workflow("incident") != "ui" || m.Workflow("task") != "" {
+		t.Errorf("Workflow: got %q and %q", m.Workflow("incident"), m.Workflow("task"))
+	}
+
+	if err := m.Delete("incident"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := m.Get("incident"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after delete: got %v, want ErrNotFound", err)
+	}
+	if err := m.Delete("incident"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete twice: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_Resolve(t *testing.T) {
+	m := newTestManager(t)
+	if _, err := m.Set(SetRequest{Name: "incident", DefaultPriority: intPtr(0), RequiredContext: []string{"severity"}}, "admin"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		beadType string
+		priority *int
+		context  map[string]string
+		want     models.BeadPriority
+		wantErr  bool
+	}{
+		{"unregistered default", "task", nil, nil, models.BeadPriorityP2, false},
+		{"unregistered explicit", "task", intPtr(1), nil, models.BeadPriorityP1, false},
+		{"type default", "incident", nil, map[string]string{"severity": "high"}, models.BeadPriorityP0, false},
+		{"explicit beats default", "incident", intPtr(3), map[string]string{"severity": "high"}, models.BeadPriorityP3, false},
+		{"missing context", "incident", nil, nil, models.BeadPriorityP0, true},
+		{"blank context", "incident", nil, map[string]string{"severity": " "}, models.BeadPriorityP0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Resolve(tt.beadType, tt.priority, tt.context)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidBead) {
+				t.Errorf("Resolve error = %v, want ErrInvalidBead", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve priority = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
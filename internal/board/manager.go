@@ -0,0 +1,312 @@
+// Package board lays a project's beads out as a kanban board: named columns
+// mapped to bead statuses, each with an optional work-in-progress limit that
+// is enforced when a bead moves into the column.
+package board
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ErrWIPLimit is returned when a bead would move into a column that is
+// already at its WIP limit.
+var ErrWIPLimit = errors.New("WIP limit reached")
+
+// DefaultCardLimit is the number of beads listed per column when a view
+// doesn't ask for a specific number.
+const DefaultCardLimit = 50
+
+var beadStatuses = []models.BeadStatus{
+	models.BeadStatusOpen,
+	models.BeadStatusBlocked,
+	models.BeadStatusInProgress,
+	models.BeadStatusClosed,
+}
+
+// BeadSource supplies the beads laid out on a board. *beads.Manager
+// satisfies it.
+type BeadSource interface {
+	ListBeads(filters map[string]interface{}) ([]*models.Bead, error)
+}
+
+// Column is one board column. Every bead status appears in exactly one
+// column. A zero WIPLimit is not enforced.
+type Column struct {
+	Name     string              `json:"name"`
+	Statuses []models.BeadStatus `json:"statuses"`
+	WIPLimit int                 `json:"wip_limit,omitempty"`
+}
+
+// Board is a project's column layout. Default is set when the project has
+// not configured one.
+type Board struct {
+	ProjectID string     `json:"project_id"`
+	Columns   []Column   `json:"columns"`
+	Default   bool       `json:"default"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Card is the summary of a bead shown in a column.
+type Card struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Type       string    `json:"type"`
+	Status     string    `json:"status"`
+	Priority   int       `json:"priority"`
+	AssignedTo string    `json:"assigned_to,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ColumnView is a column with the beads currently in it. Count is the total;
+// Beads may be truncated.
+type ColumnView struct {
+	Column
+	Count     int     `json:"count"`
+	AtLimit   bool    `json:"at_limit"`
+	OverLimit bool    `json:"over_limit"`
+	Beads     []*Card `json:"beads"`
+}
+
+// View is a board with its beads.
+type View struct {
+	ProjectID string        `json:"project_id"`
+	Default   bool          `json:"default"`
+	Columns   []*ColumnView `json:"columns"`
+}
+
+// DefaultColumns is the board used by projects that haven't configured one:
+// a column per status and no WIP limits.
+func DefaultColumns() []Column {
+	return []Column{
+		{Name: "Open", Statuses: []models.BeadStatus{models.BeadStatusOpen}},
+		{Name: "Blocked", Statuses: []models.BeadStatus{models.BeadStatusBlocked}},
+		{Name: "In Progress", Statuses: []models.BeadStatus{models.BeadStatusInProgress}},
+		{Name: "Closed", Statuses: []models.BeadStatus{models.BeadStatusClosed}},
+	}
+}
+
+// Manager stores board layouts and checks bead transitions against them.
+type Manager struct {
+	db    *database.Database
+	beads BeadSource
+}
+
+// NewManager creates a board manager. Returns nil when beads is nil. Without
+// a database every project uses the default board.
+func NewManager(db *database.Database, beads BeadSource) *Manager {
+	if beads == nil {
+		return nil
+	}
+	return &Manager{db: db, beads: beads}
+}
+
+// Get returns a project's board, or the default board if it has none.
+func (m *Manager) Get(projectID string) (*Board, error) {
+	if m.db != nil {
+		row, err := m.db.GetProjectBoard(projectID)
+		if err != nil {
+			return nil, err
+		}
+		if row != nil {
+			b := &Board{ProjectID: projectID, UpdatedBy: row.UpdatedBy}
+			updatedAt := row.UpdatedAt
+			b.UpdatedAt = &updatedAt
+			for _, c := range row.Columns {
+				col := Column{Name: c.Name, WIPLimit: c.WIPLimit}
+				for _, st := range c.Statuses {
+					col.Statuses = append(col.Statuses, models.BeadStatus(st))
+				}
+				b.Columns = append(b.Columns, col)
+			}
+			return b, nil
+		}
+	}
+	return &Board{ProjectID: projectID, Columns: DefaultColumns(), Default: true}, nil
+}
+
+// Set validates and stores a project's board.
+func (m *Manager) Set(projectID string, columns []Column, updatedBy string) (*Board, error) {
+	if m.db == nil {
+		return nil, fmt.Errorf("custom boards require a database")
+	}
+	if err := Validate(columns); err != nil {
+		return nil, err
+	}
+	row := &database.ProjectBoard{
+		ProjectID: projectID,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, c := range columns {
+		col := database.BoardColumn{Name: strings.TrimSpace(c.Name), WIPLimit: c.WIPLimit}
+		for _, st := range c.Statuses {
+			col.Statuses = append(col.Statuses, string(st))
+		}
+		row.Columns = append(row.Columns, col)
+	}
+	if err := m.db.UpsertProjectBoard(row); err != nil {
+		return nil, err
+	}
+	return m.Get(projectID)
+}
+
+// Reset returns a project to the default board.
+func (m *Manager) Reset(projectID string) error {
+	if m.db == nil {
+		return nil
+	}
+	return m.db.DeleteProjectBoard(projectID)
+}
+
+// Validate checks that columns have unique names, non-negative limits, and
+// between them cover every bead status exactly once.
+func Validate(columns []Column) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	names := make(map[string]bool)
+	seen := make(map[models.BeadStatus]string)
+	for i, c := range columns {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			return fmt.Errorf("column %d: name is required", i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("duplicate column name %q", name)
+		}
+		names[strings.ToLower(name)] = true
+		if c.WIPLimit < 0 {
+			return fmt.Errorf("column %q: wip_limit must not be negative", name)
+		}
+		if len(c.Statuses) == 0 {
+			return fmt.Errorf("column %q: at least one status is required", name)
+		}
+		for _, st := range c.Statuses {
+			if !validStatus(st) {
+				return fmt.Errorf("column %q: unknown status %q", name, st)
+			}
+			if prev, ok := seen[st]; ok {
+				return fmt.Errorf("status %q is in both %q and %q", st, prev, name)
+			}
+			seen[st] = name
+		}
+	}
+	for _, st := range beadStatuses {
+		if _, ok := seen[st]; !ok {
+			return fmt.Errorf("status %q is not in any column", st)
+		}
+	}
+	return nil
+}
+
+// View lays out a project's beads on its board. limit caps the beads listed
+// per column, highest priority first; 0 lists none and only counts them.
+func (m *Manager) View(projectID string, limit int) (*View, error) {
+	b, err := m.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	beads, err := m.beads.ListBeads(map[string]interface{}{"project_id": projectID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(beads, func(i, j int) bool {
+		if beads[i].Priority != beads[j].Priority {
+			return beads[i].Priority < beads[j].Priority
+		}
+		return beads[i].UpdatedAt.After(beads[j].UpdatedAt)
+	})
+
+	view := &View{ProjectID: projectID, Default: b.Default}
+	byStatus := make(map[models.BeadStatus]*ColumnView)
+	for _, c := range b.Columns {
+		cv := &ColumnView{Column: c, Beads: []*Card{}}
+		for _, st := range c.Statuses {
+			byStatus[st] = cv
+		}
+		view.Columns = append(view.Columns, cv)
+	}
+	for _, bead := range beads {
+		cv := byStatus[bead.Status]
+		if cv == nil {
+			continue
+		}
+		cv.Count++
+		if len(cv.Beads) < limit {
+			cv.Beads = append(cv.Beads, &Card{
+				ID:         bead.ID,
+				Title:      bead.Title,
+				Type:       bead.Type,
+				Status:     string(bead.Status),
+				Priority:   int(bead.Priority),
+				AssignedTo: bead.AssignedTo,
+				UpdatedAt:  bead.UpdatedAt,
+			})
+		}
+	}
+	for _, cv := range view.Columns {
+		cv.AtLimit = cv.WIPLimit > 0 && cv.Count >= cv.WIPLimit
+		cv.OverLimit = cv.WIPLimit > 0 && cv.Count > cv.WIPLimit
+	}
+	return view, nil
+}
+
+// CheckTransition returns an error wrapping ErrWIPLimit if moving the bead
+// to the given status would take it into another column that is already at
+// its WIP limit. Moves within a column are always allowed.
+func (m *Manager) CheckTransition(bead *models.Bead, to models.BeadStatus) error {
+	if m == nil || bead == nil {
+		return nil
+	}
+	b, err := m.Get(bead.ProjectID)
+	if err != nil {
+		return err
+	}
+	target := columnFor(b.Columns, to)
+	if target == nil || target.WIPLimit == 0 || target == columnFor(b.Columns, bead.Status) {
+		return nil
+	}
+
+	beads, err := m.beads.ListBeads(map[string]interface{}{"project_id": bead.ProjectID})
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, other := range beads {
+		if other.ID != bead.ID && columnFor(b.Columns, other.Status) == target {
+			count++
+		}
+	}
+	if count >= target.WIPLimit {
+		return fmt.Errorf("%w: column %q of project %s already holds %d of %d beads",
+			ErrWIPLimit, target.Name, bead.ProjectID, count, target.WIPLimit)
+	}
+	return nil
+}
+
+func columnFor(columns []Column, status models.BeadStatus) *Column {
+	for i := range columns {
+		for _, st := range columns[i].Statuses {
+			if st == status {
+				return &columns[i]
+			}
+		}
+	}
+	return nil
+}
+
+func validStatus(status models.BeadStatus) bool {
+	for _, st := range beadStatuses {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package board
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type fakeBeads struct {
+	beads []*models.Bead
+}
+
+func (f *fakeBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	var out []*models.Bead
+	for _, b := range f.beads {
+		if pid, ok := filters["project_id"].(string); ok && b.ProjectID != pid {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func newTestManager(t *testing.T, beads *fakeBeads) *Manager {
+	t.Helper()
+	return NewManager(dbtest.New(t), beads)
+}
+
+func wipBoard(limit int) []Column {
+	return []Column{
+		{Name: "Backlog", Statuses: []models.BeadStatus{models.BeadStatusOpen, models.BeadStatusBlocked}},
+		{Name: "Doing", Statuses: []models.BeadStatus{models.BeadStatusInProgress}, WIPLimit: limit},
+		{Name: "Done", Statuses: []models.BeadStatus{models.BeadStatusClosed}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(DefaultColumns()); err != nil {
+		t.Fatalf("default board invalid: %v", err)
+	}
+	if err := Validate(wipBoard(2)); err != nil {
+		t.Fatalf("valid board rejected: %v", err)
+	}
+
+	bad := map[string][]Column{
+		"empty":          nil,
+		"missing status": wipBoard(2)[:2],
+		"duplicate name": append(wipBoard(2), Column{Name: "doing", Statuses: []models.BeadStatus{"open"}}),
+		"status twice":   append(wipBoard(2)[:2], Column{Name: "Done", Statuses: []models.BeadStatus{"closed", "open"}}),
+		"unknown status": append(wipBoard(2), Column{Name: "Review", Statuses: []models.BeadStatus{"review"}}),
+		"negative limit": wipBoard(-1),
+	}
+	for name, cols := range bad {
+		if err := Validate(cols); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestManager_SetGetReset(t *testing.T) {
+	m := newTestManager(t, &fakeBeads{})
+
+	b, err := m.Get("loom")
+	if err != nil || !b.Default || len(b.Columns) != 4 {
+		t.Fatalf("expected default board, got %+v, %v", b, err)
+	}
+
+	if _, err := m.Set("loom", wipBoard(2), "admin"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	b, _ = m.Get("loom")
+	if b.Default || len(b.Columns) != 3 || b.Columns[1].WIPLimit != 2 || b.UpdatedBy != "admin" {
+		t.Fatalf("unexpected board: %+v", b)
+	}
+
+	if err := m.Reset("loom"); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if b, _ := m.Get("loom"); !b.Default {
+		t.Fatal("expected default board after reset")
+	}
+}
+
+func TestManager_CheckTransitionAndView(t *testing.T) {
+	beads := &fakeBeads{beads: []*models.Bead{
+		{ID: "a", ProjectID: "loom", Status: models.BeadStatusInProgress, Priority: 1},
+		{ID: "b", ProjectID: "loom", Status: models.BeadStatusInProgress, Priority: 0},
+		{ID: "c", ProjectID: "loom", Status: models.BeadStatusOpen},
+		{ID: "d", ProjectID: "loom", Status: models.BeadStatusBlocked},
+		{ID: "e", ProjectID: "web", Status: models.BeadStatusOpen},
+	}}
+	m := newTestManager(t, beads)
+
+	// No limits on the default board.
+	if err := m.CheckTransition(beads.beads[2], models.BeadStatusInProgress); err != nil {
+		t.Fatalf("default board blocked transition: %v", err)
+	}
+
+	if _, err := m.Set("loom", wipBoard(2), "admin"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := m.CheckTransition(beads.beads[2], models.BeadStatusInProgress); !errors.Is(err, ErrWIPLimit) {
+		t.Fatalf("expected ErrWIPLimit, got %v", err)
+	}
+	// Moves within a column, out of a full column, and in other projects are fine.
+	if err := m.CheckTransition(beads.beads[2], models.BeadStatusBlocked); err != nil {
+		t.Fatalf("move within column blocked: %v", err)
+	}
+	if err := m.CheckTransition(beads.beads[0], models.BeadStatusClosed); err != nil {
+		t.Fatalf("move out of full column blocked: %v", err)
+	}
+	if err := m.CheckTransition(beads.beads[4], models.BeadStatusInProgress); err != nil {
+		t.Fatalf("other project blocked: %v", err)
+	}
+
+	view, err := m.View("loom", 1)
+	if err != nil {
+		t.Fatalf("View failed: %v", err)
+	}
+	doing := view.Columns[1]
+	if doing.Count != 2 || !doing.AtLimit || doing.OverLimit || len(doing.Beads) != 1 || doing.Beads[0].ID != "b" {
+		t.Fatalf("unexpected Doing column: %+v", doing)
+	}
+	if backlog := view.Columns[0]; backlog.Count != 2 {
+		t.Fatalf("expected 2 beads in Backlog, got %d", backlog.Count)
+	}
+}
@@ -0,0 +1,375 @@
+// Package budget enforces daily and monthly token/cost limits on projects,
+// agents, and providers using the consumption recorded by analytics.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/metrics"
+)
+
+// ErrNotFound is returned when a budget ID does not exist.
+var ErrNotFound = errors.New("budget not found")
+
+// Budget scopes.
+const (
+	ScopeProject  = "project"
+	ScopeAgent    = "agent"
+	ScopeProvider = "provider"
+)
+
+// Budget periods. Periods start at midnight UTC.
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+// defaultUsageTTL bounds how stale usage figures may be. Dispatch checks
+// budgets on every pass, so usage is recomputed at most this often.
+const defaultUsageTTL = 30 * time.Second
+
+// UsageSource supplies the request logs consumption is computed from.
+// analytics.Storage and *analytics.Logger satisfy it.
+type UsageSource interface {
+	GetLogs(ctx context.Context, filter *analytics.LogFilter) ([]*analytics.RequestLog, error)
+}
+
+// Budget is a token and/or cost limit. A zero limit is not enforced.
+type Budget struct {
+	ID           string    `json:"id"`
+	Scope        string    `json:"scope"`
+	ScopeID      string    `json:"scope_id"`
+	Period       string    `json:"period"`
+	TokenLimit   int64     `json:"token_limit,omitempty"`
+	CostLimitUSD float64   `json:"cost_limit_usd,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Status is a budget together with its consumption in the current period.
+type Status struct {
+	*Budget
+	TokensUsed  int64     `json:"tokens_used"`
+	CostUsedUSD float64   `json:"cost_used_usd"`
+	PeriodStart time.Time `json:"period_start"`
+	ResetsAt    time.Time `json:"resets_at"`
+	Exhausted   bool      `json:"exhausted"`
+}
+
+// SetRequest is the body accepted when setting a budget.
+type SetRequest struct {
+	Scope        string  `json:"scope"`
+	ScopeID      string  `json:"scope_id"`
+	Period       string  `json:"period"`
+	TokenLimit   int64   `json:"token_limit"`
+	CostLimitUSD float64 `json:"cost_limit_usd"`
+}
+
+type usage struct {
+	tokens int64
+	cost   float64
+}
+
+// periodUsage is consumption since the start of one period, keyed by scope
+// and then scope ID.
+type periodUsage struct {
+	start      time.Time
+	computedAt time.Time
+	byScope    map[string]map[string]usage
+}
+
+// Manager persists budgets and checks them against recorded usage.
+type Manager struct {
+	db       *database.Database
+	source   UsageSource
+	usageTTL time.Duration
+	now      func() time.Time
+	metrics  *metrics.Metrics
+
+	mu    sync.Mutex
+	cache map[string]*periodUsage // period -> usage
+}
+
+// NewManager creates a budget manager. Returns nil when db or source is nil,
+// in which case no budgets are enforced.
+func NewManager(db *database.Database, source UsageSource) *Manager {
+	if db == nil || source == nil {
+		return nil
+	}
+	return &Manager{
+		db:       db,
+		source:   source,
+		usageTTL: defaultUsageTTL,
+		now:      time.Now,
+		cache:    make(map[string]*periodUsage),
+	}
+}
+
+// SetMetrics sets where the consumption of budgets is reported each time
+// they are checked.
+func (m *Manager) SetMetrics(mt *metrics.Metrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = mt
+}
+
+// Set creates the budget for a scope and period, or replaces the limits of
+// the existing one.
+func (m *Manager) Set(req SetRequest, createdBy string) (*Budget, error) {
+	switch req.Scope {
+	case ScopeProject, ScopeAgent, ScopeProvider:
+	default:
+		return nil, fmt.Errorf("scope must be one of project, agent, provider")
+	}
+	req.ScopeID = strings.TrimSpace(req.ScopeID)
+	if req.ScopeID == "" {
+		return nil, fmt.Errorf("scope_id is required")
+	}
+	if req.Period == "" {
+		req.Period = PeriodDaily
+	}
+	if req.Period != PeriodDaily && req.Period != PeriodMonthly {
+		return nil, fmt.Errorf("period must be daily or monthly")
+	}
+	if req.TokenLimit < 0 || req.CostLimitUSD < 0 {
+		return nil, fmt.Errorf("limits must not be negative")
+	}
+	if req.TokenLimit == 0 && req.CostLimitUSD == 0 {
+		return nil, fmt.Errorf("token_limit or cost_limit_usd is required")
+	}
+
+	now := m.now().UTC()
+	existing, err := m.db.FindBudget(req.Scope, req.ScopeID, req.Period)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.TokenLimit = req.TokenLimit
+		existing.CostLimitUSD = req.CostLimitUSD
+		existing.UpdatedAt = now
+		if err := m.db.UpdateBudget(existing); err != nil {
+			return nil, err
+		}
+		return toBudget(existing), nil
+	}
+
+	b := &database.Budget{
+		ID:           "budget-" + uuid.New().String()[:8],
+		Scope:        req.Scope,
+		ScopeID:      req.ScopeID,
+		Period:       req.Period,
+		TokenLimit:   req.TokenLimit,
+		CostLimitUSD: req.CostLimitUSD,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := m.db.CreateBudget(b); err != nil {
+		return nil, err
+	}
+	return toBudget(b), nil
+}
+
+// Get returns a budget and its current consumption.
+func (m *Manager) Get(ctx context.Context, id string) (*Status, error) {
+	b, err := m.db.GetBudget(id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return m.status(ctx, toBudget(b))
+}
+
+// List returns every budget and its current consumption.
+func (m *Manager) List(ctx context.Context) ([]*Status, error) {
+	rows, err := m.db.ListBudgets()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]*Status, 0, len(rows))
+	for _, b := range rows {
+		st, err := m.status(ctx, toBudget(b))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// Delete removes a budget.
+func (m *Manager) Delete(id string) error {
+	b, err := m.db.GetBudget(id)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return m.db.DeleteBudget(id)
+}
+
+// Check reports whether work for the given project, agent, and provider may
+// be dispatched. Empty IDs are not checked. When a budget is exhausted it
+// returns false and a reason naming it. Errors reading budgets or usage
+// fail open so that an analytics outage does not stop all work.
+func (m *Manager) Check(projectID, agentID, providerID string) (bool, string) {
+	if m == nil {
+		return true, ""
+	}
+	budgets, err := m.db.ListBudgets()
+	if err != nil || len(budgets) == 0 {
+		return true, ""
+	}
+	ids := map[string]string{
+		ScopeProject:  projectID,
+		ScopeAgent:    agentID,
+		ScopeProvider: providerID,
+	}
+	ctx := context.Background()
+	for _, row := range budgets {
+		if id := ids[row.Scope]; id == "" || id != row.ScopeID {
+			continue
+		}
+		st, err := m.status(ctx, toBudget(row))
+		if err != nil {
+			continue
+		}
+		if st.Exhausted {
+			return false, st.reason()
+		}
+	}
+	return true, ""
+}
+
+func (st *Status) reason() string {
+	if st.TokenLimit > 0 && st.TokensUsed >= st.TokenLimit {
+		return fmt.Sprintf("%s %s %s token budget exhausted (%d/%d tokens)",
+			st.Scope, st.ScopeID, st.Period, st.TokensUsed, st.TokenLimit)
+	}
+	return fmt.Sprintf("%s %s %s cost budget exhausted ($%.2f/$%.2f)",
+		st.Scope, st.ScopeID, st.Period, st.CostUsedUSD, st.CostLimitUSD)
+}
+
+// utilization is the fraction consumed of the budget's tightest limit.
+func (st *Status) utilization() float64 {
+	var u float64
+	if st.TokenLimit > 0 {
+		u = float64(st.TokensUsed) / float64(st.TokenLimit)
+	}
+	if st.CostLimitUSD > 0 {
+		u = max(u, st.CostUsedUSD/st.CostLimitUSD)
+	}
+	return u
+}
+
+func (m *Manager) status(ctx context.Context, b *Budget) (*Status, error) {
+	pu, err := m.usage(ctx, b.Period)
+	if err != nil {
+		return nil, err
+	}
+	used := pu.byScope[b.Scope][b.ScopeID]
+	st := &Status{
+		Budget:      b,
+		TokensUsed:  used.tokens,
+		CostUsedUSD: used.cost,
+		PeriodStart: pu.start,
+		ResetsAt:    periodEnd(b.Period, pu.start),
+	}
+	st.Exhausted = (b.TokenLimit > 0 && used.tokens >= b.TokenLimit) ||
+		(b.CostLimitUSD > 0 && used.cost >= b.CostLimitUSD)
+
+	m.mu.Lock()
+	mt := m.metrics
+	m.mu.Unlock()
+	if mt != nil {
+		mt.RecordBudgetUsage(b.Scope, b.ScopeID, b.Period, st.TokensUsed, st.CostUsedUSD, st.utilization())
+	}
+	return st, nil
+}
+
+// usage returns consumption for the current period, recomputing it from the
+// request logs when the cached figures are older than usageTTL or belong to
+// a previous period.
+func (m *Manager) usage(ctx context.Context, period string) (*periodUsage, error) {
+	now := m.now().UTC()
+	start := periodStart(period, now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pu := m.cache[period]; pu != nil && pu.start.Equal(start) && now.Sub(pu.computedAt) < m.usageTTL {
+		return pu, nil
+	}
+
+	logs, err := m.source.GetLogs(ctx, &analytics.LogFilter{StartTime: start})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+	pu := &periodUsage{
+		start:      start,
+		computedAt: now,
+		byScope: map[string]map[string]usage{
+			ScopeProject:  {},
+			ScopeAgent:    {},
+			ScopeProvider: {},
+		},
+	}
+	for _, l := range logs {
+		if l.Timestamp.Before(start) {
+			continue
+		}
+		add := func(scope, id string) {
+			if id == "" {
+				return
+			}
+			u := pu.byScope[scope][id]
+			u.tokens += l.TotalTokens
+			u.cost += l.CostUSD
+			pu.byScope[scope][id] = u
+		}
+		add(ScopeProject, l.Metadata["project_id"])
+		add(ScopeAgent, l.Metadata["agent_id"])
+		add(ScopeProvider, l.ProviderID)
+	}
+	m.cache[period] = pu
+	return pu, nil
+}
+
+func periodStart(period string, now time.Time) time.Time {
+	if period == PeriodMonthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func periodEnd(period string, start time.Time) time.Time {
+	if period == PeriodMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func toBudget(b *database.Budget) *Budget {
+	return &Budget{
+		ID:           b.ID,
+		Scope:        b.Scope,
+		ScopeID:      b.ScopeID,
+		Period:       b.Period,
+		TokenLimit:   b.TokenLimit,
+		CostLimitUSD: b.CostLimitUSD,
+		CreatedBy:    b.CreatedBy,
+		CreatedAt:    b.CreatedAt,
+		UpdatedAt:    b.UpdatedAt,
+	}
+}
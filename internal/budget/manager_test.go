@@ -0,0 +1,170 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/metrics"
+)
+
+type fakeUsage struct {
+	logs  []*analytics.RequestLog
+	calls int
+}
+
+func (f *fakeUsage) GetLogs(ctx context.Context, filter *analytics.LogFilter) ([]*analytics.RequestLog, error) {
+	f.calls++
+	var out []*analytics.RequestLog
+	for _, l := range f.logs {
+		if !l.Timestamp.Before(filter.StartTime) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func newTestManager(t *testing.T, usage *fakeUsage, clock *time.Time) *Manager {
+	t.Helper()
+	db := dbtest.New(t)
+	m := NewManager(db, usage)
+	m.now = func() time.Time { return *clock }
+	return m
+}
+
+func usageLog(ts time.Time, project, agent, provider string, tokens int64, cost float64) *analytics.RequestLog {
+	return &analytics.RequestLog{
+		Timestamp:   ts,
+		ProviderID:  provider,
+		TotalTokens: tokens,
+		CostUSD:     cost,
+		Metadata:    map[string]string{"project_id": project, "agent_id": agent},
+	}
+}
+
+func TestManager_CheckEnforcesLimits(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	usage := &fakeUsage{logs: []*analytics.RequestLog{
+		usageLog(clock.Add(-2*time.Hour), "loom", "agent-1", "tokenhub", 6000, 0.50),
+		usageLog(clock.Add(-1*time.Hour), "loom", "agent-2", "tokenhub", 5000, 0.40),
+		// Yesterday: counts toward the month but not the day.
+		usageLog(clock.Add(-24*time.Hour), "loom", "agent-1", "tokenhub", 50000, 4.00),
+	}}
+	m := newTestManager(t, usage, &clock)
+
+	if ok, _ := m.Check("loom", "agent-1", "tokenhub"); !ok {
+		t.Fatal("expected dispatch allowed with no budgets")
+	}
+
+	if _, err := m.Set(SetRequest{Scope: ScopeProject, ScopeID: "loom", Period: PeriodDaily, TokenLimit: 20000}, "admin"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ok, reason := m.Check("loom", "", ""); !ok {
+		t.Fatalf("11000/20000 daily tokens should be allowed: %s", reason)
+	}
+
+	if _, err := m.Set(SetRequest{Scope: ScopeAgent, ScopeID: "agent-1", Period: PeriodMonthly, CostLimitUSD: 4.00}, "admin"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	ok, reason := m.Check("loom", "agent-1", "tokenhub")
+	if ok || reason == "" {
+		t.Fatal("agent-1 spent $4.50 this month and should be blocked")
+	}
+	if ok, _ := m.Check("loom", "agent-2", "tokenhub"); !ok {
+		t.Error("agent-2 has no budget and should be allowed")
+	}
+
+	// Setting the same scope and period replaces the limits.
+	b, err := m.Set(SetRequest{Scope: ScopeAgent, ScopeID: "agent-1", Period: PeriodMonthly, CostLimitUSD: 10}, "admin")
+	if err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ok, _ := m.Check("loom", "agent-1", "tokenhub"); !ok {
+		t.Error("raised agent-1 limit should allow dispatch")
+	}
+	statuses, err := m.List(context.Background())
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("expected 2 budgets, got %d (%v)", len(statuses), err)
+	}
+
+	st, err := m.Get(context.Background(), b.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if st.TokensUsed != 56000 || st.ResetsAt != time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("unexpected status: %+v", st)
+	}
+
+	if err := m.Delete(b.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := m.Get(context.Background(), b.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestManager_UsageCachedWithinTTL(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	usage := &fakeUsage{}
+	m := newTestManager(t, usage, &clock)
+	if _, err := m.Set(SetRequest{Scope: ScopeProvider, ScopeID: "tokenhub", TokenLimit: 1000}, ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	m.Check("", "", "tokenhub")
+	m.Check("", "", "tokenhub")
+	if usage.calls != 1 {
+		t.Errorf("expected usage read once within the TTL, got %d", usage.calls)
+	}
+
+	usage.logs = append(usage.logs, usageLog(clock, "loom", "agent-1", "tokenhub", 1000, 0))
+	clock = clock.Add(defaultUsageTTL)
+	if ok, _ := m.Check("", "", "tokenhub"); ok {
+		t.Error("expected provider budget exhausted after the cache expired")
+	}
+}
+
+func TestManager_SetValidation(t *testing.T) {
+	clock := time.Now()
+	m := newTestManager(t, &fakeUsage{}, &clock)
+
+	cases := []SetRequest{
+		{Scope: "team", ScopeID: "x", TokenLimit: 1},
+		{Scope: ScopeProject, TokenLimit: 1},
+		{Scope: ScopeProject, ScopeID: "loom", Period: "weekly", TokenLimit: 1},
+		{Scope: ScopeProject, ScopeID: "loom"},
+		{Scope: ScopeProject, ScopeID: "loom", TokenLimit: -1},
+	}
+	for _, req := range cases {
+		if _, err := m.Set(req, ""); err == nil {
+			t.Errorf("expected error for %+v", req)
+		}
+	}
+}
+
+func TestManager_ReportsUsageMetrics(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	usage := &fakeUsage{logs: []*analytics.RequestLog{
+		usageLog(clock.Add(-time.Hour), "metrics-project", "agent-1", "tokenhub", 5000, 3.00),
+	}}
+	m := newTestManager(t, usage, &clock)
+	mt := metrics.NewMetrics()
+	m.SetMetrics(mt)
+
+	if _, err := m.Set(SetRequest{Scope: ScopeProject, ScopeID: "metrics-project", Period: PeriodDaily, TokenLimit: 20000, CostLimitUSD: 4.00}, "admin"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	m.Check("metrics-project", "", "")
+
+	if got := testutil.ToFloat64(mt.BudgetTokensUsed.WithLabelValues(ScopeProject, "metrics-project", PeriodDaily)); got != 5000 {
+		t.Errorf("tokens used = %v, want 5000", got)
+	}
+	if got := testutil.ToFloat64(mt.BudgetUtilization.WithLabelValues(ScopeProject, "metrics-project", PeriodDaily)); got != 0.75 {
+		t.Errorf("utilization = %v, want 0.75 (the cost limit is the tighter one)", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Package ci reads the CI checks on the branches agents push, from GitHub
+// (check runs and commit statuses, which covers GitHub Actions and any CI
+// reporting back to GitHub) or from a Jenkins multibranch job, and combines
+// them into one state a workflow can wait on.
+package ci
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// State is the combined state of the checks on a branch.
+type State string
+
+const (
+	StatePending State = "pending" // Checks still running, or none reported yet
+	StatePassed  State = "passed"  // Every check passed
+	StateFailed  State = "failed"  // At least one check failed
+)
+
+// Check is one CI check on a branch. Status is queued, in_progress, or
+// completed; Conclusion is set once it has completed.
+type Check struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// Passed reports whether the check completed successfully.
+func (c Check) Passed() bool {
+	return c.Status == "completed" && passingConclusions[c.Conclusion]
+}
+
+// Failed reports whether the check completed unsuccessfully.
+func (c Check) Failed() bool {
+	return c.Status == "completed" && !passingConclusions[c.Conclusion]
+}
+
+var passingConclusions = map[string]bool{"success": true, "neutral": true, "skipped": true}
+
+// Status is the CI of a branch.
+type Status struct {
+	Provider string  `json:"provider"`
+	Branch   string  `json:"branch"`
+	State    State   `json:"state"`
+	Summary  string  `json:"summary"`
+	URL      string  `json:"url,omitempty"` // first failing check, if any
+	Checks   []Check `json:"checks"`
+	// Required names the checks the state is decided by; empty means all.
+	Required []string `json:"required,omitempty"`
+}
+
+// Provider reads the checks on a branch from a CI system.
+type Provider interface {
+	Name() string
+	Checks(ctx context.Context, branch string) ([]Check, error)
+}
+
+// Get reads the checks on a branch and combines them.
+func Get(ctx context.Context, p Provider, branch string, required []string) (*Status, error) {
+	checks, err := p.Checks(ctx, branch)
+	if err != nil {
+		return nil, fmt.Errorf("%s checks on %s: %w", p.Name(), branch, err)
+	}
+	s := Combine(checks, required)
+	s.Provider = p.Name()
+	s.Branch = branch
+	return s, nil
+}
+
+// Combine decides the state of a branch from its checks. When required is
+// not empty, only the checks it names count, and a required check that has
+// not been reported yet keeps the branch pending. Any failed check fails
+// the branch; otherwise it is pending until every check has passed. A
+// branch with no checks at all is pending.
+func Combine(checks []Check, required []string) *Status {
+	counted := checks
+	var missing []string
+	if len(required) > 0 {
+		byName := make(map[string]Check, len(checks))
+		for _, c := range checks {
+			byName[c.Name] = c
+		}
+		counted = nil
+		for _, name := range required {
+			if c, ok := byName[name]; ok {
+				counted = append(counted, c)
+			} else {
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	var passed, failed, running []string
+	url := ""
+	for _, c := range counted {
+		switch {
+		case c.Passed():
+			passed = append(passed, c.Name)
+		case c.Failed():
+			failed = append(failed, c.Name)
+			if url == "" {
+				url = c.URL
+			}
+		default:
+			running = append(running, c.Name)
+		}
+	}
+
+	s := &Status{Checks: checks, Required: required, URL: url}
+	switch {
+	case len(failed) > 0:
+		s.State = StateFailed
+	case len(running) > 0 || len(missing) > 0 || len(counted) == 0:
+		s.State = StatePending
+	default:
+		s.State = StatePassed
+	}
+	s.Summary = summarize(passed, failed, running, missing)
+	return s
+}
+
+func summarize(passed, failed, running, missing []string) string {
+	var parts []string
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		parts = append(parts, fmt.Sprintf("%d failed (%s)", len(failed), strings.Join(failed, ", ")))
+	}
+	if len(running) > 0 {
+		parts = append(parts, fmt.Sprintf("%d running", len(running)))
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		parts = append(parts, fmt.Sprintf("%d not reported (%s)", len(missing), strings.Join(missing, ", ")))
+	}
+	if len(passed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d passed", len(passed)))
+	}
+	if len(parts) == 0 {
+		return "no checks reported"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,76 @@
+package ci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+	pass := Check{Name: "build", Status: "completed", Conclusion: "success"}
+	skip := Check{Name: "docs", Status: "completed", Conclusion: "skipped"}
+	fail := Check{Name: "lint", Status: "completed", Conclusion: "failure", URL: "https://ci/lint"}
+	running := Check{Name: "test", Status: "in_progress"}
+
+	for _, tc := range []struct {
+		name     string
+		checks   []Check
+		required []string
+		want     State
+		summary  string
+	}{
+		{"none", nil, nil, StatePending, "no checks reported"},
+		{"all passed", []Check{pass, skip}, nil, StatePassed, "2 passed"},
+		{"one running", []Check{pass, running}, nil, StatePending, "1 running, 1 passed"},
+		{"one failed", []Check{pass, running, fail}, nil, StateFailed, "1 failed (lint), 1 running, 1 passed"},
+		{"failure not required", []Check{pass, fail}, []string{"build"}, StatePassed, "1 passed"},
+		{"required not reported", []Check{pass}, []string{"build", "test"}, StatePending, "1 not reported (test), 1 passed"},
+	} {
+		s := Combine(tc.checks, tc.required)
+		if s.State != tc.want || s.Summary != tc.summary {
+			t.Errorf("%s: got %s %q, want %s %q", tc.name, s.State, s.Summary, tc.want, tc.summary)
+		}
+	}
+	if s := Combine([]Check{fail}, nil); s.URL != "https://ci/lint" {
+		t.Errorf("URL = %q, want the failing check's", s.URL)
+	}
+}
+
+func TestJenkins(t *testing.T) {
+	builds := map[string]string{
+		"/job/team/job/loom/job/bead%252Floom-1/lastBuild/api/json": `{"fullDisplayName":"loom » bead/loom-1 #3","building":false,"result":"UNSTABLE","url":"https://jenkins/b/3"}`,
+		"/job/team/job/loom/job/bead%252Floom-2/lastBuild/api/json": `{"fullDisplayName":"loom » bead/loom-2 #1","building":true,"result":null}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "loom" || token != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := builds[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	j := NewJenkins(srv.URL+"/", "/team/loom", "loom", "secret")
+
+	s, err := Get(context.Background(), j, "bead/loom-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.State != StateFailed || s.URL != "https://jenkins/b/3" || s.Provider != "jenkins" {
+		t.Errorf("unstable build: %+v", s)
+	}
+	if s, err = Get(context.Background(), j, "bead/loom-2", nil); err != nil || s.State != StatePending {
+		t.Errorf("running build: %+v, %v", s, err)
+	}
+	if s, err = Get(context.Background(), j, "bead/loom-3", nil); err != nil || s.State != StatePending || len(s.Checks) != 0 {
+		t.Errorf("unbuilt branch: %+v, %v", s, err)
+	}
+	if _, err := Get(context.Background(), NewJenkins(srv.URL, "team/loom", "", ""), "bead/loom-1", nil); err == nil {
+		t.Error("unauthorized request did not fail")
+	}
+}
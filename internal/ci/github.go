@@ -0,0 +1,33 @@
+package ci
+
+import (
+	"context"
+
+	"github.com/jordanhubbard/loom/internal/github"
+)
+
+// GitHub reads check runs and commit statuses through the gh CLI, from the
+// repository of a project's workspace.
+type GitHub struct {
+	client *github.Client
+}
+
+// NewGitHub returns a provider for the repository checked out in workDir.
+// An empty token uses gh's stored credentials.
+func NewGitHub(workDir, token string) *GitHub {
+	return &GitHub{client: github.NewClient(workDir, token)}
+}
+
+func (g *GitHub) Name() string { return "github" }
+
+func (g *GitHub) Checks(ctx context.Context, branch string) ([]Check, error) {
+	runs, err := g.client.ListCheckRuns(ctx, branch)
+	if err != nil {
+		return nil, err
+	}
+	checks := make([]Check, 0, len(runs))
+	for _, r := range runs {
+		checks = append(checks, Check(r))
+	}
+	return checks, nil
+}
@@ -0,0 +1,98 @@
+package ci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Jenkins reads the last build of a branch of a Jenkins multibranch job.
+type Jenkins struct {
+	baseURL string
+	job     string
+	user    string
+	token   string
+	client  *http.Client
+}
+
+// NewJenkins returns a provider for job, a multibranch job path such as
+// "team/loom", on the Jenkins at baseURL. user and token, an API token,
+// may be empty for anonymous read access.
+func NewJenkins(baseURL, job, user, token string) *Jenkins {
+	return &Jenkins{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		job:     strings.Trim(job, "/"),
+		user:    user,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (j *Jenkins) Name() string { return "jenkins" }
+
+// Checks reports the branch's last build as its one check. A branch Jenkins
+// has not built yet has no checks.
+func (j *Jenkins) Checks(ctx context.Context, branch string) ([]Check, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.buildURL(branch), nil)
+	if err != nil {
+		return nil, err
+	}
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.token)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("jenkins returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var build struct {
+		FullDisplayName string `json:"fullDisplayName"`
+		Building        bool   `json:"building"`
+		Result          string `json:"result"`
+		URL             string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+		return nil, fmt.Errorf("parse jenkins build: %w", err)
+	}
+	check := Check{Name: build.FullDisplayName, Status: "in_progress", URL: build.URL}
+	if check.Name == "" {
+		check.Name = j.job
+	}
+	if !build.Building && build.Result != "" {
+		check.Status = "completed"
+		switch build.Result {
+		case "SUCCESS":
+			check.Conclusion = "success"
+		case "ABORTED", "NOT_BUILT":
+			check.Conclusion = "cancelled"
+		default: // FAILURE, UNSTABLE
+			check.Conclusion = "failure"
+		}
+	}
+	return []Check{check}, nil
+}
+
+// buildURL is the API URL of the last build of a branch. Multibranch jobs
+// name a branch's job after the branch, URL-encoded, so a branch with a
+// slash is encoded twice in the path.
+func (j *Jenkins) buildURL(branch string) string {
+	var path strings.Builder
+	for _, part := range strings.Split(j.job, "/") {
+		path.WriteString("/job/" + url.PathEscape(part))
+	}
+	path.WriteString("/job/" + url.PathEscape(url.PathEscape(branch)))
+	return j.baseURL + path.String() + "/lastBuild/api/json?tree=fullDisplayName,building,result,url"
+}
@@ -0,0 +1,464 @@
+// Package cmdpolicy holds the allow and deny rules agent shell commands are
+// checked against before the shell executor runs them. Rules match commands
+// by regular expression or glob and apply to one project or to all of them.
+// Blocked commands are recorded as violations and, when the rule says so,
+// raised as a decision bead that blocks the agent's bead until someone
+// looks at it.
+package cmdpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ErrNotFound is returned when a rule ID does not exist.
+var ErrNotFound = errors.New("command policy rule not found")
+
+// Rule effects.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// Pattern syntaxes.
+const (
+	SyntaxRegex = "regex" // matches anywhere in the command
+	SyntaxGlob  = "glob"  // must match the whole command; * also matches /
+)
+
+// defaultViolationLimit is how many violations Violations returns when no
+// limit is given.
+const defaultViolationLimit = 100
+
+// DecisionCreator raises a blocked command as a decision bead.
+// *loom.Loom satisfies it.
+type DecisionCreator interface {
+	CreateDecisionBead(question, parentBeadID, requesterID string, options []string, recommendation string, priority models.BeadPriority, projectID string) (*models.DecisionBead, error)
+}
+
+// Rule allows or denies commands matching Pattern.
+type Rule struct {
+	ID             string    `json:"id"`
+	ProjectID      string    `json:"project_id,omitempty"`
+	Effect         string    `json:"effect"`
+	Syntax         string    `json:"syntax"`
+	Pattern        string    `json:"pattern"`
+	Description    string    `json:"description,omitempty"`
+	CreateDecision bool      `json:"create_decision"`
+	CreatedBy      string    `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// RuleRequest creates or replaces a rule. Syntax defaults to regex.
+type RuleRequest struct {
+	ProjectID      string `json:"project_id"`
+	Effect         string `json:"effect"`
+	Syntax         string `json:"syntax"`
+	Pattern        string `json:"pattern"`
+	Description    string `json:"description"`
+	CreateDecision bool   `json:"create_decision"`
+}
+
+// Verdict is the outcome of checking a command. Rule is the deny rule that
+// blocked it, or the allow rule that let it through when the project has an
+// allowlist.
+type Verdict struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Rule    *Rule  `json:"rule,omitempty"`
+}
+
+// Violation is a command the policy blocked.
+type Violation struct {
+	ID         string    `json:"id"`
+	RuleID     string    `json:"rule_id,omitempty"`
+	ProjectID  string    `json:"project_id,omitempty"`
+	AgentID    string    `json:"agent_id,omitempty"`
+	BeadID     string    `json:"bead_id,omitempty"`
+	Command    string    `json:"command"`
+	Reason     string    `json:"reason"`
+	DecisionID string    `json:"decision_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BlockedError is returned by CheckCommand for a command the policy blocks.
+type BlockedError struct {
+	Verdict *Verdict
+}
+
+func (e *BlockedError) Error() string {
+	return "blocked by command policy: " + e.Verdict.Reason
+}
+
+// Manager stores rules and checks commands against them.
+type Manager struct {
+	db        *database.Database
+	decisions DecisionCreator
+	eventBus  *eventbus.EventBus
+	now       func() time.Time
+
+	mu       sync.Mutex
+	compiled map[string]*regexp.Regexp // keyed by syntax and pattern
+}
+
+// NewManager creates a command policy manager. It returns nil without a
+// database.
+func NewManager(db *database.Database, decisions DecisionCreator, eventBus *eventbus.EventBus) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{
+		db:        db,
+		decisions: decisions,
+		eventBus:  eventBus,
+		now:       time.Now,
+		compiled:  make(map[string]*regexp.Regexp),
+	}
+}
+
+// Create adds a rule.
+func (m *Manager) Create(req RuleRequest, createdBy string) (*Rule, error) {
+	if err := m.validate(&req); err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	r := &database.CommandPolicyRule{
+		ID:             "cmdpol-" + uuid.New().String()[:8],
+		ProjectID:      req.ProjectID,
+		Effect:         req.Effect,
+		Syntax:         req.Syntax,
+		Pattern:        req.Pattern,
+		Description:    req.Description,
+		CreateDecision: req.CreateDecision,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := m.db.CreateCommandPolicyRule(r); err != nil {
+		return nil, err
+	}
+	return toRule(r), nil
+}
+
+// Update replaces a rule's project, match, and settings.
+func (m *Manager) Update(id string, req RuleRequest) (*Rule, error) {
+	r, err := m.db.GetCommandPolicyRule(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err := m.validate(&req); err != nil {
+		return nil, err
+	}
+	r.ProjectID = req.ProjectID
+	r.Effect = req.Effect
+	r.Syntax = req.Syntax
+	r.Pattern = req.Pattern
+	r.Description = req.Description
+	r.CreateDecision = req.CreateDecision
+	r.UpdatedAt = m.now().UTC()
+	if err := m.db.UpdateCommandPolicyRule(r); err != nil {
+		return nil, err
+	}
+	return toRule(r), nil
+}
+
+// Get returns a rule.
+func (m *Manager) Get(id string) (*Rule, error) {
+	r, err := m.db.GetCommandPolicyRule(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return toRule(r), nil
+}
+
+// List returns the rules that apply to a project, or every rule when
+// projectID is empty.
+func (m *Manager) List(projectID string) ([]*Rule, error) {
+	rows, err := m.db.ListCommandPolicyRules(projectID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*Rule, 0, len(rows))
+	for _, r := range rows {
+		rules = append(rules, toRule(r))
+	}
+	return rules, nil
+}
+
+// Delete removes a rule.
+func (m *Manager) Delete(id string) error {
+	if _, err := m.Get(id); err != nil {
+		return err
+	}
+	return m.db.DeleteCommandPolicyRule(id)
+}
+
+// Violations returns the most recent blocked commands, optionally for one
+// project.
+func (m *Manager) Violations(projectID string, limit int) ([]*Violation, error) {
+	if limit <= 0 {
+		limit = defaultViolationLimit
+	}
+	rows, err := m.db.ListCommandPolicyViolations(projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Violation, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, &Violation{
+			ID:         v.ID,
+			RuleID:     v.RuleID,
+			ProjectID:  v.ProjectID,
+			AgentID:    v.AgentID,
+			BeadID:     v.BeadID,
+			Command:    v.Command,
+			Reason:     v.Reason,
+			DecisionID: v.DecisionID,
+			CreatedAt:  v.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// Check evaluates a command against the rules for a project without
+// recording anything. A matching deny rule blocks the command. Otherwise,
+// if any allow rule applies to the project, the command must match one.
+func (m *Manager) Check(projectID, command string) (*Verdict, error) {
+	rules, err := m.List(projectID)
+	if err != nil {
+		return nil, err
+	}
+	normalized := normalize(command)
+
+	var allowRules int
+	var allowedBy *Rule
+	for _, r := range rules {
+		re, err := m.pattern(r.Syntax, r.Pattern)
+		if err != nil {
+			log.Printf("[CommandPolicy] Skipping rule %s: %v", r.ID, err)
+			continue
+		}
+		matched := re.MatchString(normalized)
+		switch r.Effect {
+		case EffectDeny:
+			if matched {
+				return &Verdict{Allowed: false, Reason: denyReason(r), Rule: r}, nil
+			}
+		case EffectAllow:
+			allowRules++
+			if matched && allowedBy == nil {
+				allowedBy = r
+			}
+		}
+	}
+	if allowRules > 0 && allowedBy == nil {
+		return &Verdict{Allowed: false, Reason: "command matches no allow rule for this project"}, nil
+	}
+	return &Verdict{Allowed: true, Rule: allowedBy}, nil
+}
+
+// CheckCommand checks a command an agent is about to run. A blocked command
+// is recorded as a violation, published as a command.blocked event, and,
+// if its rule asks for one, raised as a decision bead blocking the agent's
+// bead. It returns a *BlockedError for blocked commands. If the rules
+// can't be read the command is blocked too, since the policy can't vouch
+// for it.
+func (m *Manager) CheckCommand(ctx context.Context, projectID, agentID, beadID, command string) error {
+	verdict, err := m.Check(projectID, command)
+	if err != nil {
+		return fmt.Errorf("command policy unavailable: %w", err)
+	}
+	if verdict.Allowed {
+		return nil
+	}
+
+	v := &database.CommandPolicyViolation{
+		ID:        "cmdviol-" + uuid.New().String()[:8],
+		ProjectID: projectID,
+		AgentID:   agentID,
+		BeadID:    beadID,
+		Command:   command,
+		Reason:    verdict.Reason,
+		CreatedAt: m.now().UTC(),
+	}
+	if verdict.Rule != nil {
+		v.RuleID = verdict.Rule.ID
+		if verdict.Rule.CreateDecision {
+			v.DecisionID = m.raiseDecision(verdict, projectID, agentID, beadID, command)
+		}
+	}
+	if err := m.db.CreateCommandPolicyViolation(v); err != nil {
+		log.Printf("[CommandPolicy] %v", err)
+	}
+	log.Printf("[CommandPolicy] Blocked command for agent=%s bead=%s: %s (%s)", agentID, beadID, command, verdict.Reason)
+
+	if m.eventBus != nil {
+		_ = m.eventBus.Publish(&eventbus.Event{
+			Type:      eventbus.EventTypeCommandBlocked,
+			Source:    "command-policy",
+			ProjectID: projectID,
+			Data: map[string]interface{}{
+				"violation_id": v.ID,
+				"rule_id":      v.RuleID,
+				"agent_id":     agentID,
+				"bead_id":      beadID,
+				"command":      command,
+				"reason":       verdict.Reason,
+				"decision_id":  v.DecisionID,
+			},
+		})
+	}
+	return &BlockedError{Verdict: verdict}
+}
+
+// raiseDecision creates a decision bead for a blocked command, unless the
+// rule already raised one for the same bead, and returns its ID.
+func (m *Manager) raiseDecision(verdict *Verdict, projectID, agentID, beadID, command string) string {
+	if m.decisions == nil {
+		return ""
+	}
+	if beadID != "" {
+		existing, err := m.db.FindCommandPolicyDecision(verdict.Rule.ID, beadID)
+		if err != nil {
+			log.Printf("[CommandPolicy] %v", err)
+		}
+		if existing != "" {
+			return existing
+		}
+	}
+	who := agentID
+	if who == "" {
+		who = "An agent"
+	}
+	question := fmt.Sprintf("%s tried to run a command blocked by command policy rule %s (%s):\n\n%s\n\nShould this be allowed? Allowing it means changing or removing the rule.",
+		who, verdict.Rule.ID, verdict.Reason, command)
+	decision, err := m.decisions.CreateDecisionBead(question, beadID, "system",
+		[]string{"Keep blocking", "Change the rule to allow it"}, "Keep blocking", models.BeadPriorityP1, projectID)
+	if err != nil {
+		log.Printf("[CommandPolicy] Failed to create decision for blocked command: %v", err)
+		return ""
+	}
+	return decision.ID
+}
+
+func (m *Manager) validate(req *RuleRequest) error {
+	req.ProjectID = strings.TrimSpace(req.ProjectID)
+	req.Effect = strings.ToLower(strings.TrimSpace(req.Effect))
+	req.Syntax = strings.ToLower(strings.TrimSpace(req.Syntax))
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Syntax == "" {
+		req.Syntax = SyntaxRegex
+	}
+	if req.Effect != EffectAllow && req.Effect != EffectDeny {
+		return fmt.Errorf("effect must be %q or %q", EffectAllow, EffectDeny)
+	}
+	if req.Syntax != SyntaxRegex && req.Syntax != SyntaxGlob {
+		return fmt.Errorf("syntax must be %q or %q", SyntaxRegex, SyntaxGlob)
+	}
+	if strings.TrimSpace(req.Pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := m.pattern(req.Syntax, req.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	return nil
+}
+
+// pattern compiles a rule's pattern, caching the result.
+func (m *Manager) pattern(syntax, pattern string) (*regexp.Regexp, error) {
+	key := syntax + "\x00" + pattern
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if re, ok := m.compiled[key]; ok {
+		return re, nil
+	}
+	expr := pattern
+	if syntax == SyntaxGlob {
+		expr = globToRegexp(normalize(pattern))
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	m.compiled[key] = re
+	return re, nil
+}
+
+// globToRegexp translates a glob into an anchored regular expression: *
+// matches any run of characters, ? any one character, and [...] a
+// character class.
+func globToRegexp(glob string) string {
+	runes := []rune(glob)
+	var sb strings.Builder
+	sb.WriteString(`^`)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			sb.WriteString(`.*`)
+		case '?':
+			sb.WriteString(`.`)
+		case '[':
+			end := strings.IndexRune(string(runes[i+1:]), ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := []rune(string(runes[i+1:])[:end])
+			if len(class) > 0 && class[0] == '!' {
+				class[0] = '^'
+			}
+			sb.WriteString("[" + strings.ReplaceAll(string(class), `\`, `\\`) + "]")
+			i += len(class) + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString(`$`)
+	return sb.String()
+}
+
+// normalize collapses runs of whitespace so that rules don't have to allow
+// for extra spaces.
+func normalize(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
+
+func denyReason(r *Rule) string {
+	if r.Description != "" {
+		return fmt.Sprintf("denied by rule %s: %s", r.ID, r.Description)
+	}
+	return fmt.Sprintf("denied by rule %s (%s %s)", r.ID, r.Syntax, r.Pattern)
+}
+
+func toRule(r *database.CommandPolicyRule) *Rule {
+	return &Rule{
+		ID:             r.ID,
+		ProjectID:      r.ProjectID,
+		Effect:         r.Effect,
+		Syntax:         r.Syntax,
+		Pattern:        r.Pattern,
+		Description:    r.Description,
+		CreateDecision: r.CreateDecision,
+		CreatedBy:      r.CreatedBy,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
@@ -3,6 +3,7 @@ package comments
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -81,7 +82,7 @@ func (m *Manager) CreateComment(beadID, authorID, authorUsername, content, paren
 	mentions := m.parseMentions(content)
 	comment.Mentions = mentions
 
-	if err := m.processMentions(comment.ID, mentions); err != nil {
+	if err := m.processMentions(comment, mentions); err != nil {
 		// Log error but don't fail comment creation
 		fmt.Printf("Failed to process mentions: %v\n", err)
 	}
@@ -142,6 +143,29 @@ func (m *Manager) GetComments(beadID string) ([]*Comment, error) {
 	return topLevel, nil
 }
 
+// GetComment retrieves a single comment without its replies
+func (m *Manager) GetComment(commentID string) (*Comment, error) {
+	dbComment, err := m.db.GetComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if dbComment.Deleted {
+		return nil, fmt.Errorf("comment not found: %s", commentID)
+	}
+	return &Comment{
+		ID:             dbComment.ID,
+		BeadID:         dbComment.BeadID,
+		ParentID:       dbComment.ParentID,
+		AuthorID:       dbComment.AuthorID,
+		AuthorUsername: dbComment.AuthorUsername,
+		Content:        dbComment.Content,
+		CreatedAt:      dbComment.CreatedAt,
+		UpdatedAt:      dbComment.UpdatedAt,
+		Edited:         dbComment.Edited,
+		Mentions:       m.parseMentions(dbComment.Content),
+	}, nil
+}
+
 // UpdateComment updates a comment's content
 func (m *Manager) UpdateComment(commentID, authorID, content string) error {
 	// Verify ownership
@@ -232,7 +256,7 @@ func (m *Manager) parseMentions(content string) []string {
 }
 
 // processMentions creates mention records and notifications
-func (m *Manager) processMentions(commentID string, mentions []string) error {
+func (m *Manager) processMentions(comment *Comment, mentions []string) error {
 	if len(mentions) == 0 {
 		return nil
 	}
@@ -260,7 +284,7 @@ func (m *Manager) processMentions(commentID string, mentions []string) error {
 		// Create mention record
 		mention := &database.CommentMention{
 			ID:                uuid.New().String(),
-			CommentID:         commentID,
+			CommentID:         comment.ID,
 			MentionedUserID:   userID,
 			MentionedUsername: username,
 			CreatedAt:         time.Now(),
@@ -279,10 +303,13 @@ func (m *Manager) processMentions(commentID string, mentions []string) error {
 				Timestamp: time.Now(),
 				Source:    "comments",
 				Data: map[string]interface{}{
-					"mention_id":   mention.ID,
-					"comment_id":   commentID,
-					"mentioned_id": userID,
-					"username":     username,
+					"mention_id":      mention.ID,
+					"comment_id":      comment.ID,
+					"bead_id":         comment.BeadID,
+					"author_id":       comment.AuthorID,
+					"author_username": comment.AuthorUsername,
+					"mentioned_id":    userID,
+					"username":        username,
 				},
 			}
 			_ = m.eventBus.Publish(event)
@@ -317,3 +344,37 @@ func (m *Manager) publishCommentEvent(eventType string, comment *Comment) {
 
 	_ = m.eventBus.Publish(event)
 }
+
+// Limits on how much of a bead's comment thread goes into an agent's context.
+const (
+	promptMaxComments     = 10
+	promptMaxCommentChars = 1000
+)
+
+// PromptSection renders the most recent comments on a bead as a section of
+// an agent's task context, so guidance left by humans reaches the agent
+// working the bead. It returns "" when there are no comments.
+func PromptSection(db *database.Database, beadID string) string {
+	if db == nil || beadID == "" {
+		return ""
+	}
+	dbComments, err := db.GetCommentsByBeadID(beadID)
+	if err != nil || len(dbComments) == 0 {
+		return ""
+	}
+	if len(dbComments) > promptMaxComments {
+		dbComments = dbComments[len(dbComments)-promptMaxComments:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n## Comments on This Bead\n\n")
+	sb.WriteString("Guidance left by people on this bead, oldest first. Follow it where it applies.\n\n")
+	for _, c := range dbComments {
+		content := strings.TrimSpace(c.Content)
+		if len(content) > promptMaxCommentChars {
+			content = content[:promptMaxCommentChars] + "..."
+		}
+		fmt.Fprintf(&sb, "- %s (%s): %s\n", c.AuthorUsername, c.CreatedAt.UTC().Format("2006-01-02 15:04"), content)
+	}
+	return sb.String()
+}
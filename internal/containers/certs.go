@@ -0,0 +1,65 @@
+package containers
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/agentpki"
+)
+
+// SetAgentPKI enables mTLS for project agents: each container is issued a
+// client certificate for its project at spawn, mounted at /etc/loom/tls.
+func (o *Orchestrator) SetAgentPKI(pki *agentpki.Authority) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pki = pki
+}
+
+// certDir returns the host directory holding a project's agent certificate.
+func (o *Orchestrator) certDir(projectID string) string {
+	return filepath.Join(o.projectsRoot, projectID, "tls")
+}
+
+// StartCertRotation re-issues agent certificates nearing expiry, checking
+// at interval until ctx is cancelled. The directory is mounted into the
+// container, so agents pick up the new certificate without a restart.
+func (o *Orchestrator) StartCertRotation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.rotateCerts()
+		}
+	}
+}
+
+func (o *Orchestrator) rotateCerts() {
+	o.mu.RLock()
+	pki := o.pki
+	projectIDs := make([]string, 0, len(o.projectAgents))
+	for id := range o.projectAgents {
+		projectIDs = append(projectIDs, id)
+	}
+	o.mu.RUnlock()
+	if pki == nil {
+		return
+	}
+
+	for _, id := range projectIDs {
+		dir := o.certDir(id)
+		if !pki.NeedsRotation(dir, id) {
+			continue
+		}
+		expires, err := pki.IssueTo(dir, id)
+		if err != nil {
+			log.Printf("[Containers] Failed to rotate agent certificate for project %s: %v", id, err)
+			continue
+		}
+		log.Printf("[Containers] Rotated agent certificate for project %s (expires %s)", id, expires.Format(time.RFC3339))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/sandbox"
 	"github.com/jordanhubbard/loom/pkg/messages"
 )
 
@@ -234,14 +235,43 @@ func (c *ProjectAgentClient) ExecuteTask(ctx context.Context, req interface{}) e
 	return nil
 }
 
+// ExecOptions are the optional settings for Exec.
+type ExecOptions struct {
+	Timeout int
+	// Limits, when set, has the project agent run the command under these
+	// resource limits.
+	Limits *sandbox.Limits
+	// Env is added to the command's environment.
+	Env map[string]string
+}
+
 // ExecSync executes a command synchronously in the container via the /exec endpoint
 // and returns stdout, stderr, exit code, and duration directly.
 func (c *ProjectAgentClient) ExecSync(ctx context.Context, command, workingDir string, timeout int) (*ExecResult, error) {
+	return c.Exec(ctx, command, workingDir, ExecOptions{Timeout: timeout})
+}
+
+// ExecSandboxed executes a command like ExecSync, with the project agent
+// running it under the given resource limits.
+func (c *ProjectAgentClient) ExecSandboxed(ctx context.Context, command, workingDir string, limits sandbox.Limits) (*ExecResult, error) {
+	return c.Exec(ctx, command, workingDir, ExecOptions{Timeout: limits.TimeoutSeconds, Limits: &limits})
+}
+
+// Exec executes a command synchronously like ExecSync, with resource limits
+// and extra environment variables from opts.
+func (c *ProjectAgentClient) Exec(ctx context.Context, command, workingDir string, opts ExecOptions) (*ExecResult, error) {
+	timeout := opts.Timeout
 	payload := map[string]interface{}{
 		"command":     command,
 		"working_dir": workingDir,
 		"timeout":     timeout,
 	}
+	if opts.Limits != nil {
+		payload["limits"] = opts.Limits
+	}
+	if len(opts.Env) > 0 {
+		payload["env"] = opts.Env
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -268,11 +298,12 @@ func (c *ProjectAgentClient) ExecSync(ctx context.Context, command, workingDir s
 	}
 
 	var result struct {
-		Stdout     string `json:"stdout"`
-		Stderr     string `json:"stderr"`
-		ExitCode   int    `json:"exit_code"`
-		DurationMs int64  `json:"duration_ms"`
-		Success    bool   `json:"success"`
+		Stdout     string          `json:"stdout"`
+		Stderr     string          `json:"stderr"`
+		ExitCode   int             `json:"exit_code"`
+		DurationMs int64           `json:"duration_ms"`
+		Success    bool            `json:"success"`
+		Sandbox    *sandbox.Report `json:"sandbox"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode exec response: %w", err)
@@ -283,6 +314,7 @@ func (c *ProjectAgentClient) ExecSync(ctx context.Context, command, workingDir s
 		Stderr:     result.Stderr,
 		ExitCode:   result.ExitCode,
 		DurationMs: result.DurationMs,
+		Sandbox:    result.Sandbox,
 	}, nil
 }
 
@@ -0,0 +1,340 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/metrics"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Restart policy of the health monitor. A container that fails
+// unhealthyThreshold probes in a row is restarted; each further restart
+// waits twice as long as the one before, from restartBackoffBase up to
+// restartBackoffMax. A container that stays healthy for restartBackoffReset
+// after a restart starts over at restartBackoffBase.
+const (
+	unhealthyThreshold  = 3
+	restartBackoffBase  = 10 * time.Second
+	restartBackoffMax   = 10 * time.Minute
+	restartBackoffReset = 10 * time.Minute
+	healthProbeTimeout  = 5 * time.Second
+)
+
+// ResourceLimits caps what a project container may use. Empty fields leave
+// Docker's default, which is unlimited.
+type ResourceLimits struct {
+	CPUs   string `json:"cpus,omitempty"`   // e.g. "2" or "0.5"
+	Memory string `json:"memory,omitempty"` // e.g. "4g" or "512m"
+}
+
+// ResourceUsage is a container's resource usage as docker stats reports it.
+type ResourceUsage struct {
+	CPUPercent    string `json:"cpu_percent"`
+	MemoryUsage   string `json:"memory_usage"`
+	MemoryPercent string `json:"memory_percent"`
+	PIDs          string `json:"pids"`
+}
+
+// ContainerInfo describes a project container: its limits, its health as
+// the monitor last saw it, and its current resource usage.
+type ContainerInfo struct {
+	ProjectID           string         `json:"project_id"`
+	Name                string         `json:"name"`
+	Running             bool           `json:"running"`
+	Healthy             bool           `json:"healthy"`
+	ConsecutiveFailures int            `json:"consecutive_failures,omitempty"`
+	Restarts            int            `json:"restarts"`
+	LastRestartAt       *time.Time     `json:"last_restart_at,omitempty"`
+	LastError           string         `json:"last_error,omitempty"`
+	Limits              ResourceLimits `json:"limits"`
+	Usage               *ResourceUsage `json:"usage,omitempty"`
+}
+
+// healthState is what the health monitor knows about one container.
+type healthState struct {
+	healthy       bool
+	failures      int // consecutive failed probes
+	restarts      int // restarts since the orchestrator started
+	attempts      int // restarts counted towards the backoff
+	lastRestartAt time.Time
+	nextRestartAt time.Time
+	lastError     string
+}
+
+// containerName returns the name of a project's container.
+func containerName(projectID string) string {
+	return fmt.Sprintf("loom-project-%s", projectID)
+}
+
+// SetResourceLimits sets the lookup of a project's container resource
+// limits. Limits apply when the container is next created or restarted.
+func (o *Orchestrator) SetResourceLimits(lookup func(projectID string) ResourceLimits) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.limits = lookup
+}
+
+// SetMetrics sets where the health monitor reports container health and
+// restarts.
+func (o *Orchestrator) SetMetrics(m *metrics.Metrics) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.metrics = m
+}
+
+func (o *Orchestrator) resourceLimits(projectID string) ResourceLimits {
+	if o.limits == nil {
+		return ResourceLimits{}
+	}
+	return o.limits(projectID)
+}
+
+// StartHealthMonitor probes every project container at interval until ctx
+// is cancelled, restarting containers that stay unhealthy.
+func (o *Orchestrator) StartHealthMonitor(ctx context.Context, interval time.Duration) {
+	log.Printf("[Containers] Health monitor started with %s interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.probeAll(ctx)
+		}
+	}
+}
+
+func (o *Orchestrator) probeAll(ctx context.Context) {
+	o.mu.RLock()
+	agents := make(map[string]*ProjectAgentClient, len(o.projectAgents))
+	for id, agent := range o.projectAgents {
+		agents[id] = agent
+	}
+	m := o.metrics
+	o.mu.RUnlock()
+
+	for projectID, agent := range agents {
+		probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+		err := agent.Health(probeCtx)
+		cancel()
+		restart := o.recordProbe(projectID, err, time.Now())
+		if m != nil {
+			m.RecordContainerProbe(projectID, err == nil, restart)
+		}
+		if restart {
+			o.restartUnhealthy(ctx, projectID)
+		}
+	}
+}
+
+// recordProbe records the outcome of a health probe and reports whether
+// the container is due a restart.
+func (o *Orchestrator) recordProbe(projectID string, probeErr error, now time.Time) bool {
+	o.healthMu.Lock()
+	defer o.healthMu.Unlock()
+
+	st := o.health[projectID]
+	if st == nil {
+		st = &healthState{}
+		o.health[projectID] = st
+	}
+
+	if probeErr == nil {
+		st.healthy = true
+		st.failures = 0
+		st.lastError = ""
+		if st.attempts > 0 && now.Sub(st.lastRestartAt) >= restartBackoffReset {
+			st.attempts = 0
+		}
+		return false
+	}
+
+	st.healthy = false
+	st.failures++
+	st.lastError = probeErr.Error()
+	if st.failures < unhealthyThreshold || now.Before(st.nextRestartAt) {
+		return false
+	}
+
+	delay := restartBackoffBase << st.attempts
+	if delay > restartBackoffMax || delay <= 0 {
+		delay = restartBackoffMax
+	}
+	st.attempts++
+	st.restarts++
+	st.lastRestartAt = now
+	st.nextRestartAt = now.Add(delay)
+	log.Printf("[Containers] Project %s container unhealthy after %d probes (%s); restarting (restart %d, next no sooner than %s)",
+		projectID, st.failures, st.lastError, st.restarts, delay)
+	return true
+}
+
+// restartUnhealthy restarts a container the health monitor gave up on.
+func (o *Orchestrator) restartUnhealthy(ctx context.Context, projectID string) {
+	if err := o.restartContainer(ctx, projectID); err != nil {
+		log.Printf("[Containers] Restart of project %s container failed: %v", projectID, err)
+		o.healthMu.Lock()
+		if st := o.health[projectID]; st != nil {
+			st.lastError = err.Error()
+		}
+		o.healthMu.Unlock()
+	}
+}
+
+// restartContainer restarts a project's container in place and waits for
+// its agent to answer again.
+func (o *Orchestrator) restartContainer(ctx context.Context, projectID string) error {
+	rt := o.Runtime()
+	cmd := rt.command(ctx, "restart", containerName(projectID))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s restart failed: %s - %w", rt.Name, strings.TrimSpace(string(output)), err)
+	}
+	return o.waitForHealth(ctx, projectID, 60*time.Second)
+}
+
+// RestartProjectContainer recreates a project's container on request. The
+// compose file is regenerated, so limits changed since the container was
+// created take effect.
+func (o *Orchestrator) RestartProjectContainer(ctx context.Context, project *models.Project) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.generateComposeFile(project); err != nil {
+		return fmt.Errorf("failed to generate compose file: %w", err)
+	}
+	cmd, err := o.runtime.composeCommand(ctx, o.composeFile, "up", "-d", "--force-recreate", containerName(project.ID))
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s up failed: %s - %w", o.runtime.Name, strings.TrimSpace(string(output)), err)
+	}
+	if err := o.waitForHealth(ctx, project.ID, 60*time.Second); err != nil {
+		return fmt.Errorf("container failed to become healthy: %w", err)
+	}
+	if _, ok := o.projectAgents[project.ID]; !ok {
+		agent := NewProjectAgentClient(fmt.Sprintf("http://%s:8090", containerName(project.ID)), project.ID)
+		if o.messageBus != nil {
+			agent.SetMessageBus(o.messageBus)
+		}
+		o.projectAgents[project.ID] = agent
+	}
+
+	o.healthMu.Lock()
+	if st := o.health[project.ID]; st != nil {
+		st.healthy, st.failures, st.lastError = true, 0, ""
+	}
+	o.healthMu.Unlock()
+	log.Printf("[Containers] Recreated project %s container", project.ID)
+	return nil
+}
+
+// ProjectContainerLogs returns the last lines of a project container's log.
+func (o *Orchestrator) ProjectContainerLogs(ctx context.Context, projectID string, tail int) (string, error) {
+	rt := o.Runtime()
+	cmd := rt.command(ctx, "logs", "--tail", fmt.Sprint(tail), containerName(projectID))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s logs failed: %s - %w", rt.Name, strings.TrimSpace(string(output)), err)
+	}
+	return string(output), nil
+}
+
+// ListContainers describes the project containers the orchestrator knows
+// of, ordered by project ID, with their current resource usage.
+func (o *Orchestrator) ListContainers(ctx context.Context) []ContainerInfo {
+	o.mu.RLock()
+	projectIDs := make([]string, 0, len(o.projectAgents))
+	for id := range o.projectAgents {
+		projectIDs = append(projectIDs, id)
+	}
+	o.mu.RUnlock()
+	sort.Strings(projectIDs)
+
+	usage, err := containerUsage(ctx, o.Runtime(), projectIDs)
+	if err != nil {
+		log.Printf("[Containers] Failed to read container usage: %v", err)
+	}
+
+	infos := make([]ContainerInfo, 0, len(projectIDs))
+	for _, id := range projectIDs {
+		info := ContainerInfo{
+			ProjectID: id,
+			Name:      containerName(id),
+			Healthy:   true,
+			Limits:    o.resourceLimits(id),
+		}
+		if u, ok := usage[id]; ok {
+			info.Running = true
+			info.Usage = u
+		}
+		o.healthMu.Lock()
+		if st := o.health[id]; st != nil {
+			info.Healthy = st.healthy
+			info.ConsecutiveFailures = st.failures
+			info.Restarts = st.restarts
+			info.LastError = st.lastError
+			if !st.lastRestartAt.IsZero() {
+				t := st.lastRestartAt
+				info.LastRestartAt = &t
+			}
+		}
+		o.healthMu.Unlock()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ContainerStatus describes one project's container.
+func (o *Orchestrator) ContainerStatus(ctx context.Context, projectID string) (*ContainerInfo, error) {
+	for _, info := range o.ListContainers(ctx) {
+		if info.ProjectID == projectID {
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("no container for project %s", projectID)
+}
+
+// containerUsage reads the resource usage of running project containers,
+// keyed by project ID.
+func containerUsage(ctx context.Context, rt *Runtime, projectIDs []string) (map[string]*ResourceUsage, error) {
+	usage := make(map[string]*ResourceUsage)
+	if len(projectIDs) == 0 {
+		return usage, nil
+	}
+	// Docker and Podman name the process count differently.
+	pids := "{{.PIDs}}"
+	if rt.Name == "podman" {
+		pids = "{{.PIDS}}"
+	}
+	args := []string{"stats", "--no-stream", "--format", "{{.Name}}\t{{.CPUPerc}}\t{{.MemUsage}}\t{{.MemPerc}}\t" + pids}
+	for _, id := range projectIDs {
+		args = append(args, containerName(id))
+	}
+	// stats fails outright if any container is missing, but still prints
+	// the ones it found.
+	output, err := rt.command(ctx, args...).Output()
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			continue
+		}
+		id := strings.TrimPrefix(fields[0], "loom-project-")
+		usage[id] = &ResourceUsage{
+			CPUPercent:    fields[1],
+			MemoryUsage:   fields[2],
+			MemoryPercent: fields[3],
+			PIDs:          fields[4],
+		}
+	}
+	if err != nil && len(usage) == 0 {
+		return usage, err
+	}
+	return usage, nil
+}
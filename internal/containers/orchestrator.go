@@ -6,13 +6,15 @@ import (
 	"log"
 	"math/rand"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"text/template"
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/agentpki"
+	"github.com/jordanhubbard/loom/internal/metrics"
+	"github.com/jordanhubbard/loom/internal/sandbox"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
@@ -22,6 +24,7 @@ type ExecResult struct {
 	Stderr     string
 	ExitCode   int
 	DurationMs int64
+	Sandbox    *sandbox.Report // Set when the project agent applied resource limits
 }
 
 // AgentClient interface for executing tasks in project containers
@@ -41,6 +44,13 @@ type Orchestrator struct {
 	controlPlaneURL string
 	natsURL         string     // NATS URL injected into project containers
 	messageBus      MessageBus // NATS message bus for async task publishing
+	limits          func(projectID string) ResourceLimits
+	runtime         *Runtime
+	metrics         *metrics.Metrics
+	pki             *agentpki.Authority // issues agent client certificates; nil without mTLS
+
+	healthMu sync.Mutex
+	health   map[string]*healthState // project ID -> health monitor state
 }
 
 // shortID returns a 6-character random alphanumeric string for container instance IDs.
@@ -62,6 +72,8 @@ func NewOrchestrator(projectsRoot, controlPlaneURL string) (*Orchestrator, error
 		composeFile:     composeFile,
 		projectAgents:   make(map[string]*ProjectAgentClient),
 		controlPlaneURL: controlPlaneURL,
+		health:          make(map[string]*healthState),
+		runtime:         defaultRuntime(),
 	}, nil
 }
 
@@ -97,6 +109,12 @@ func (o *Orchestrator) EnsureProjectContainer(ctx context.Context, project *mode
 		delete(o.projectAgents, project.ID)
 	}
 
+	if o.pki != nil {
+		if _, err := o.pki.IssueTo(o.certDir(project.ID), project.ID); err != nil {
+			return fmt.Errorf("failed to issue agent certificate: %w", err)
+		}
+	}
+
 	// Generate docker-compose.yml for this project
 	if err := o.generateComposeFile(project); err != nil {
 		return fmt.Errorf("failed to generate compose file: %w", err)
@@ -108,7 +126,7 @@ func (o *Orchestrator) EnsureProjectContainer(ctx context.Context, project *mode
 	}
 
 	// Wait for container to be healthy
-	if err := o.waitForHealth(ctx, project, 60*time.Second); err != nil {
+	if err := o.waitForHealth(ctx, project.ID, 60*time.Second); err != nil {
 		return fmt.Errorf("container failed to become healthy: %w", err)
 	}
 
@@ -148,13 +166,19 @@ func (o *Orchestrator) StopProjectContainer(ctx context.Context, projectID strin
 
 	serviceName := fmt.Sprintf("loom-project-%s", projectID)
 
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", o.composeFile, "stop", serviceName)
+	cmd, err := o.runtime.composeCommand(ctx, o.composeFile, "stop", serviceName)
+	if err != nil {
+		return err
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to stop container: %s - %w", output, err)
 	}
 
 	delete(o.projectAgents, projectID)
+	o.healthMu.Lock()
+	delete(o.health, projectID)
+	o.healthMu.Unlock()
 	log.Printf("[Containers] Stopped project %s container", projectID)
 	return nil
 }
@@ -185,13 +209,28 @@ services:
       - NATS_URL={{.NatsURL}}
       - SERVICE_ID={{.ServiceID}}
       - INSTANCE_ID={{.InstanceID}}
+{{- if .TLSDir}}
+      - LOOM_TLS_CERT=/etc/loom/tls/tls.crt
+      - LOOM_TLS_KEY=/etc/loom/tls/tls.key
+      - LOOM_TLS_CA=/etc/loom/tls/ca.crt
+{{- end}}
     volumes:
       - loom-project-{{.ProjectID}}-workspace:/workspace
       - loom-project-{{.ProjectID}}-history:/root/.loom-history
       - {{.ProjectsRoot}}/{{.ProjectID}}/keys:/root/.ssh:ro
+{{- if .TLSDir}}
+      - {{.TLSDir}}:/etc/loom/tls:ro
+{{- end}}
     networks:
       - loom_loom-network
     restart: unless-stopped
+{{- if .CPUs}}
+    cpus: "{{.CPUs}}"
+{{- end}}
+{{- if .Memory}}
+    mem_limit: {{.Memory}}
+    memswap_limit: {{.Memory}}
+{{- end}}
     cap_add:
       - SYS_ADMIN
     security_opt:
@@ -274,6 +313,14 @@ volumes:
 		"ServiceID":       serviceID,
 		"InstanceID":      instanceID,
 	}
+	if o.pki != nil {
+		data["TLSDir"] = o.certDir(project.ID)
+	}
+	if o.runtime.Limits {
+		limits := o.resourceLimits(project.ID)
+		data["CPUs"] = limits.CPUs
+		data["Memory"] = limits.Memory
+	}
 
 	f, err := os.Create(o.composeFile)
 	if err != nil {
@@ -361,18 +408,24 @@ func (o *Orchestrator) startContainer(ctx context.Context, project *models.Proje
 	serviceName := fmt.Sprintf("loom-project-%s", project.ID)
 
 	// Build the container image first
-	buildCmd := exec.CommandContext(ctx, "docker", "compose", "-f", o.composeFile, "build", serviceName)
+	buildCmd, err := o.runtime.composeCommand(ctx, o.composeFile, "build", serviceName)
+	if err != nil {
+		return err
+	}
 	buildCmd.Stdout = os.Stdout
 	buildCmd.Stderr = os.Stderr
 	if err := buildCmd.Run(); err != nil {
-		return fmt.Errorf("docker build failed: %w", err)
+		return fmt.Errorf("%s build failed: %w", o.runtime.Name, err)
 	}
 
 	// Start the container
-	startCmd := exec.CommandContext(ctx, "docker", "compose", "-f", o.composeFile, "up", "-d", serviceName)
+	startCmd, err := o.runtime.composeCommand(ctx, o.composeFile, "up", "-d", serviceName)
+	if err != nil {
+		return err
+	}
 	output, err := startCmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("docker up failed: %s - %w", output, err)
+		return fmt.Errorf("%s up failed: %s - %w", o.runtime.Name, output, err)
 	}
 
 	log.Printf("[Containers] Started container for project %s", project.ID)
@@ -380,9 +433,9 @@ func (o *Orchestrator) startContainer(ctx context.Context, project *models.Proje
 }
 
 // waitForHealth waits for a container to become healthy
-func (o *Orchestrator) waitForHealth(ctx context.Context, project *models.Project, timeout time.Duration) error {
-	agentURL := fmt.Sprintf("http://loom-project-%s:8090", project.ID)
-	agent := NewProjectAgentClient(agentURL, project.ID)
+func (o *Orchestrator) waitForHealth(ctx context.Context, projectID string, timeout time.Duration) error {
+	agentURL := fmt.Sprintf("http://loom-project-%s:8090", projectID)
+	agent := NewProjectAgentClient(agentURL, projectID)
 
 	deadline := time.Now().Add(timeout)
 	ticker := time.NewTicker(2 * time.Second)
@@ -400,14 +453,14 @@ func (o *Orchestrator) waitForHealth(ctx context.Context, project *models.Projec
 			if err := agent.Health(ctx); err == nil {
 				return nil
 			}
-			log.Printf("[Containers] Waiting for project %s container to be healthy...", project.ID)
+			log.Printf("[Containers] Waiting for project %s container to be healthy...", projectID)
 		}
 	}
 }
 
 // ListRunningContainers returns list of running project containers
 func (o *Orchestrator) ListRunningContainers(ctx context.Context) ([]string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "ps", "--filter", "name=loom-project-", "--format", "{{.Names}}")
+	cmd := o.Runtime().command(ctx, "ps", "--filter", "name=loom-project-", "--format", "{{.Names}}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, err
@@ -451,10 +504,11 @@ func (o *Orchestrator) SnapshotContainer(ctx context.Context, projectID string)
 	containerName := fmt.Sprintf("loom-project-%s", projectID)
 	imageName := fmt.Sprintf("loom-project:%s", projectID)
 
-	cmd := exec.CommandContext(ctx, "docker", "commit", containerName, imageName)
+	rt := o.Runtime()
+	cmd := rt.command(ctx, "commit", containerName, imageName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("docker commit failed for %s: %s - %w", projectID, string(output), err)
+		return fmt.Errorf("%s commit failed for %s: %s - %w", rt.Name, projectID, string(output), err)
 	}
 
 	log.Printf("[Containers] Snapshot saved for project %s -> %s", projectID, imageName)
@@ -478,13 +532,19 @@ func (o *Orchestrator) StopAll(ctx context.Context) error {
 		return nil // No compose file, nothing to stop
 	}
 
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", o.composeFile, "down")
+	cmd, err := o.runtime.composeCommand(ctx, o.composeFile, "down")
+	if err != nil {
+		return err
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to stop containers: %s - %w", output, err)
 	}
 
 	o.projectAgents = make(map[string]*ProjectAgentClient)
+	o.healthMu.Lock()
+	o.health = make(map[string]*healthState)
+	o.healthMu.Unlock()
 	log.Println("[Containers] Stopped all project containers")
 	return nil
 }
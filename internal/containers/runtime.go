@@ -0,0 +1,232 @@
+package containers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Runtime is the container engine the orchestrator drives, through the
+// docker or podman CLI, and what it was found able to do at startup.
+type Runtime struct {
+	Name     string   `json:"name"`              // "docker" or "podman"
+	Binary   string   `json:"binary"`            // CLI path
+	Socket   string   `json:"socket,omitempty"`  // API socket the CLI is pointed at; "" for its default
+	Version  string   `json:"version,omitempty"` // engine version
+	Compose  []string `json:"compose,omitempty"` // command that runs compose files, e.g. docker compose or podman-compose
+	Rootless bool     `json:"rootless"`
+	// Limits reports whether the engine can enforce CPU and memory limits.
+	// Rootless engines need the cpu and memory cgroup controllers delegated
+	// to the user, which only cgroup v2 allows.
+	Limits bool `json:"limits"`
+}
+
+// defaultRuntime is the runtime used until one is detected: the docker CLI
+// with its own defaults, as before runtimes were configurable.
+func defaultRuntime() *Runtime {
+	return &Runtime{Name: "docker", Binary: "docker", Compose: []string{"docker", "compose"}, Limits: true}
+}
+
+// DetectRuntime finds a usable container engine. name is "docker",
+// "podman", or "auto" (or "") to try Docker and then Podman, Podman first
+// when socket looks like a Podman socket. socket, a path or unix:// URL,
+// points the CLI at an engine other than its default; any engine serving
+// the Docker API can be used with the docker CLI this way.
+func DetectRuntime(ctx context.Context, name, socket string) (*Runtime, error) {
+	socket = normalizeSocket(socket)
+
+	var candidates []string
+	switch name {
+	case "", "auto":
+		candidates = []string{"docker", "podman"}
+		if strings.Contains(socket, "podman") {
+			candidates = []string{"podman", "docker"}
+		}
+	case "docker", "podman":
+		candidates = []string{name}
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q (want auto, docker, or podman)", name)
+	}
+
+	var errs []string
+	for _, c := range candidates {
+		rt, err := probeRuntime(ctx, c, socket)
+		if err == nil {
+			return rt, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", c, err))
+	}
+	return nil, fmt.Errorf("no usable container runtime (%s)", strings.Join(errs, "; "))
+}
+
+// normalizeSocket turns a bare socket path into a unix:// URL.
+func normalizeSocket(socket string) string {
+	socket = strings.TrimSpace(socket)
+	if strings.HasPrefix(socket, "/") {
+		return "unix://" + socket
+	}
+	return socket
+}
+
+// probeRuntime asks one engine about itself and works out how to run
+// compose files with it.
+func probeRuntime(ctx context.Context, name, socket string) (*Runtime, error) {
+	binary, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("CLI not found")
+	}
+	rt := &Runtime{Name: name, Binary: binary, Socket: socket}
+
+	output, err := rt.command(ctx, "info", "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("engine not reachable: %w", err)
+	}
+	if name == "podman" {
+		err = rt.parsePodmanInfo(output)
+	} else {
+		err = rt.parseDockerInfo(output)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unreadable engine info: %w", err)
+	}
+
+	rt.Compose = rt.findCompose(ctx)
+	return rt, nil
+}
+
+func (rt *Runtime) parseDockerInfo(output []byte) error {
+	var info struct {
+		ServerVersion   string   `json:"ServerVersion"`
+		SecurityOptions []string `json:"SecurityOptions"`
+		MemoryLimit     bool     `json:"MemoryLimit"`
+		CPUCfsQuota     bool     `json:"CpuCfsQuota"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return err
+	}
+	rt.Version = info.ServerVersion
+	for _, opt := range info.SecurityOptions {
+		if strings.Contains(opt, "name=rootless") {
+			rt.Rootless = true
+		}
+	}
+	rt.Limits = info.MemoryLimit && info.CPUCfsQuota
+	return nil
+}
+
+func (rt *Runtime) parsePodmanInfo(output []byte) error {
+	var info struct {
+		Host struct {
+			CgroupVersion     string   `json:"cgroupVersion"`
+			CgroupControllers []string `json:"cgroupControllers"`
+			Security          struct {
+				Rootless bool `json:"rootless"`
+			} `json:"security"`
+		} `json:"host"`
+		Version struct {
+			Version string `json:"Version"`
+		} `json:"version"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return err
+	}
+	rt.Version = info.Version.Version
+	rt.Rootless = info.Host.Security.Rootless
+	if !rt.Rootless {
+		rt.Limits = true
+		return nil
+	}
+	var cpu, memory bool
+	for _, c := range info.Host.CgroupControllers {
+		cpu = cpu || c == "cpu"
+		memory = memory || c == "memory"
+	}
+	rt.Limits = info.Host.CgroupVersion == "v2" && cpu && memory
+	return nil
+}
+
+// findCompose returns the command that runs compose files with this
+// engine: its compose subcommand if it has one, else the standalone tool.
+func (rt *Runtime) findCompose(ctx context.Context) []string {
+	if rt.command(ctx, "compose", "version").Run() == nil {
+		return []string{rt.Binary, "compose"}
+	}
+	standalone := "docker-compose"
+	if rt.Name == "podman" {
+		standalone = "podman-compose"
+	}
+	if path, err := exec.LookPath(standalone); err == nil {
+		return []string{path}
+	}
+	return nil
+}
+
+// env returns the environment of the runtime's commands, pointing the CLI
+// and compose at the configured socket.
+func (rt *Runtime) env() []string {
+	if rt.Socket == "" {
+		return nil // inherit
+	}
+	env := append(os.Environ(), "DOCKER_HOST="+rt.Socket)
+	if rt.Name == "podman" {
+		env = append(env, "CONTAINER_HOST="+rt.Socket)
+	}
+	return env
+}
+
+// command builds a command of the runtime's CLI.
+func (rt *Runtime) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, rt.Binary, args...)
+	cmd.Env = rt.env()
+	return cmd
+}
+
+// composeCommand builds a compose command for composeFile.
+func (rt *Runtime) composeCommand(ctx context.Context, composeFile string, args ...string) (*exec.Cmd, error) {
+	if len(rt.Compose) == 0 {
+		return nil, fmt.Errorf("%s has no compose support; install the compose plugin or %s-compose", rt.Name, rt.Name)
+	}
+	full := append(append(append([]string{}, rt.Compose[1:]...), "-f", composeFile), args...)
+	cmd := exec.CommandContext(ctx, rt.Compose[0], full...)
+	cmd.Env = rt.env()
+	return cmd, nil
+}
+
+// String describes the runtime for logs.
+func (rt *Runtime) String() string {
+	mode := "rootful"
+	if rt.Rootless {
+		mode = "rootless"
+	}
+	compose := "none"
+	if len(rt.Compose) > 0 {
+		compose = strings.Join(rt.Compose, " ")
+	}
+	s := fmt.Sprintf("%s %s (%s, compose: %s, resource limits: %t)", rt.Name, rt.Version, mode, compose, rt.Limits)
+	if rt.Socket != "" {
+		s += " at " + rt.Socket
+	}
+	return s
+}
+
+// SetRuntime sets the container engine the orchestrator drives.
+func (o *Orchestrator) SetRuntime(rt *Runtime) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runtime = rt
+	log.Printf("[Containers] Using %s", rt)
+	if !rt.Limits {
+		log.Printf("[Containers] Warning: %s cannot enforce CPU and memory limits here; container_cpus and container_memory are ignored", rt.Name)
+	}
+}
+
+// Runtime returns the container engine the orchestrator drives.
+func (o *Orchestrator) Runtime() *Runtime {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.runtime
+}
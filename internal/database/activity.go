@@ -293,6 +293,7 @@ type Notification struct {
 	Status       string
 	Priority     string
 	MetadataJSON string
+	EmailStatus  string
 	CreatedAt    time.Time
 	ReadAt       *time.Time
 	ArchivedAt   *time.Time
@@ -303,8 +304,8 @@ func (d *Database) CreateNotification(notification *Notification) error {
 	query := `
 		INSERT INTO notifications (
 			id, user_id, activity_id, event_type, title, message, link,
-			status, priority, metadata_json, created_at, read_at, archived_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			status, priority, metadata_json, email_status, created_at, read_at, archived_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := d.db.Exec(rebind(query),
@@ -318,6 +319,7 @@ func (d *Database) CreateNotification(notification *Notification) error {
 		notification.Status,
 		notification.Priority,
 		sqlNullString(notification.MetadataJSON),
+		notification.EmailStatus,
 		notification.CreatedAt,
 		sqlNullTime(notification.ReadAt),
 		sqlNullTime(notification.ArchivedAt),
@@ -435,6 +437,58 @@ func (d *Database) MarkAllNotificationsRead(userID string) error {
 	return nil
 }
 
+// ListQueuedNotificationEmails returns the notifications waiting to be
+// emailed, grouped by user, oldest first.
+func (d *Database) ListQueuedNotificationEmails() ([]*Notification, error) {
+	query := `
+		SELECT id, user_id, event_type, title, message, link,
+			   priority, metadata_json, created_at
+		FROM notifications
+		WHERE email_status = 'queued'
+		ORDER BY user_id, created_at ASC
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued notification emails: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*Notification
+	for rows.Next() {
+		notification := &Notification{EmailStatus: "queued"}
+		var link, metadataJSON sql.NullString
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.EventType,
+			&notification.Title,
+			&notification.Message,
+			&link,
+			&notification.Priority,
+			&metadataJSON,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notification.Link = link.String
+		notification.MetadataJSON = metadataJSON.String
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// SetNotificationEmailStatus records how the email of each notification
+// went.
+func (d *Database) SetNotificationEmailStatus(ids []string, status string) error {
+	for _, id := range ids {
+		if _, err := d.db.Exec(rebind(`UPDATE notifications SET email_status = ? WHERE id = ?`), status, id); err != nil {
+			return fmt.Errorf("failed to set notification email status: %w", err)
+		}
+	}
+	return nil
+}
+
 // NotificationPreferences represents user notification preferences
 type NotificationPreferences struct {
 	ID                   string
@@ -443,6 +497,7 @@ type NotificationPreferences struct {
 	EnableEmail          bool
 	EnableWebhook        bool
 	SubscribedEventsJSON string
+	EmailEventsJSON      string
 	DigestMode           string
 	QuietHoursStart      string
 	QuietHoursEnd        string
@@ -455,14 +510,14 @@ type NotificationPreferences struct {
 func (d *Database) GetNotificationPreferences(userID string) (*NotificationPreferences, error) {
 	query := `
 		SELECT id, user_id, enable_in_app, enable_email, enable_webhook,
-			   subscribed_events_json, digest_mode, quiet_hours_start,
+			   subscribed_events_json, email_events_json, digest_mode, quiet_hours_start,
 			   quiet_hours_end, project_filters_json, min_priority, updated_at
 		FROM notification_preferences
 		WHERE user_id = ?
 	`
 
 	prefs := &NotificationPreferences{}
-	var subscribedEvents, quietStart, quietEnd, projectFilters sql.NullString
+	var subscribedEvents, emailEvents, quietStart, quietEnd, projectFilters sql.NullString
 
 	err := d.db.QueryRow(rebind(query), userID).Scan(
 		&prefs.ID,
@@ -471,6 +526,7 @@ func (d *Database) GetNotificationPreferences(userID string) (*NotificationPrefe
 		&prefs.EnableEmail,
 		&prefs.EnableWebhook,
 		&subscribedEvents,
+		&emailEvents,
 		&prefs.DigestMode,
 		&quietStart,
 		&quietEnd,
@@ -487,6 +543,7 @@ func (d *Database) GetNotificationPreferences(userID string) (*NotificationPrefe
 	}
 
 	prefs.SubscribedEventsJSON = subscribedEvents.String
+	prefs.EmailEventsJSON = emailEvents.String
 	prefs.QuietHoursStart = quietStart.String
 	prefs.QuietHoursEnd = quietEnd.String
 	prefs.ProjectFiltersJSON = projectFilters.String
@@ -499,14 +556,15 @@ func (d *Database) UpsertNotificationPreferences(prefs *NotificationPreferences)
 	query := `
 		INSERT INTO notification_preferences (
 			id, user_id, enable_in_app, enable_email, enable_webhook,
-			subscribed_events_json, digest_mode, quiet_hours_start,
+			subscribed_events_json, email_events_json, digest_mode, quiet_hours_start,
 			quiet_hours_end, project_filters_json, min_priority, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(user_id) DO UPDATE SET
 			enable_in_app = excluded.enable_in_app,
 			enable_email = excluded.enable_email,
 			enable_webhook = excluded.enable_webhook,
 			subscribed_events_json = excluded.subscribed_events_json,
+			email_events_json = excluded.email_events_json,
 			digest_mode = excluded.digest_mode,
 			quiet_hours_start = excluded.quiet_hours_start,
 			quiet_hours_end = excluded.quiet_hours_end,
@@ -522,6 +580,7 @@ func (d *Database) UpsertNotificationPreferences(prefs *NotificationPreferences)
 		prefs.EnableEmail,
 		prefs.EnableWebhook,
 		sqlNullString(prefs.SubscribedEventsJSON),
+		sqlNullString(prefs.EmailEventsJSON),
 		prefs.DigestMode,
 		sqlNullString(prefs.QuietHoursStart),
 		sqlNullString(prefs.QuietHoursEnd),
@@ -591,6 +650,171 @@ func (d *Database) ListUsers() ([]struct {
 	return users, nil
 }
 
+// User is a row of the users table.
+type User struct {
+	ID                    string
+	Username              string
+	Email                 string
+	Role                  string
+	IsActive              bool
+	PasswordResetRequired bool
+	LastLoginAt           *time.Time
+	SessionsRevokedAt     *time.Time
+	ExternalID            string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	// PasswordHash is read but never written by SaveUser; see
+	// SetUserPassword.
+	PasswordHash string
+}
+
+const userColumns = `id, username, email, role, is_active, password_reset_required,
+	last_login_at, sessions_revoked_at, created_at, updated_at, password_hash, external_id`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*User, error) {
+	var u User
+	var email, hash, externalID sql.NullString
+	var lastLogin, revoked sql.NullTime
+	if err := row.Scan(&u.ID, &u.Username, &email, &u.Role, &u.IsActive, &u.PasswordResetRequired,
+		&lastLogin, &revoked, &u.CreatedAt, &u.UpdatedAt, &hash, &externalID); err != nil {
+		return nil, err
+	}
+	u.Email = email.String
+	u.PasswordHash = hash.String
+	u.ExternalID = externalID.String
+	if lastLogin.Valid {
+		u.LastLoginAt = &lastLogin.Time
+	}
+	if revoked.Valid {
+		u.SessionsRevokedAt = &revoked.Time
+	}
+	return &u, nil
+}
+
+// ListAllUsers returns every user, disabled ones included.
+func (d *Database) ListAllUsers() ([]*User, error) {
+	rows, err := d.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// GetUserByUsername returns the user with username, or nil if there is none.
+func (d *Database) GetUserByUsername(username string) (*User, error) {
+	u, err := scanUser(d.db.QueryRow(rebind(`SELECT `+userColumns+` FROM users WHERE username = ?`), username))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// GetUserByExternalID returns the user provisioned through single sign-on
+// with externalID, or nil if there is none.
+func (d *Database) GetUserByExternalID(externalID string) (*User, error) {
+	u, err := scanUser(d.db.QueryRow(rebind(`SELECT `+userColumns+` FROM users WHERE external_id = ?`), externalID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// SaveUser creates or updates a user. The password hash is left alone.
+func (d *Database) SaveUser(u *User) error {
+	_, err := d.db.Exec(rebind(`
+		INSERT INTO users (id, username, email, role, is_active, password_reset_required,
+			last_login_at, sessions_revoked_at, external_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			username = excluded.username,
+			email = excluded.email,
+			role = excluded.role,
+			is_active = excluded.is_active,
+			password_reset_required = excluded.password_reset_required,
+			last_login_at = excluded.last_login_at,
+			sessions_revoked_at = excluded.sessions_revoked_at,
+			external_id = excluded.external_id,
+			updated_at = excluded.updated_at
+	`), u.ID, u.Username, sqlNullString(u.Email), u.Role, u.IsActive, u.PasswordResetRequired,
+		sqlNullTime(u.LastLoginAt), sqlNullTime(u.SessionsRevokedAt), sqlNullString(u.ExternalID), u.CreatedAt, u.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+	return nil
+}
+
+// SetUserPassword stores a user's password hash.
+func (d *Database) SetUserPassword(id, hash string) error {
+	res, err := d.db.Exec(rebind(`UPDATE users SET password_hash = ? WHERE id = ?`), hash, id)
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("user not found: %s", id)
+	}
+	return nil
+}
+
+// RevokedSession is a login session revoked before it expired.
+type RevokedSession struct {
+	ID        string
+	UserID    string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// RevokeSession records a revoked login session and drops the records of
+// sessions that have since expired.
+func (d *Database) RevokeSession(s *RevokedSession) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM revoked_sessions WHERE expires_at < ?`), s.RevokedAt); err != nil {
+		return fmt.Errorf("failed to prune revoked sessions: %w", err)
+	}
+	_, err := d.db.Exec(rebind(`
+		INSERT INTO revoked_sessions (id, user_id, expires_at, revoked_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO NOTHING
+	`), s.ID, s.UserID, s.ExpiresAt, s.RevokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// ListRevokedSessions returns the revoked sessions that expire after now.
+func (d *Database) ListRevokedSessions(now time.Time) ([]*RevokedSession, error) {
+	rows, err := d.db.Query(rebind(`SELECT id, user_id, expires_at, revoked_at FROM revoked_sessions WHERE expires_at > ?`), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revoked sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*RevokedSession
+	for rows.Next() {
+		s := &RevokedSession{}
+		if err := rows.Scan(&s.ID, &s.UserID, &s.ExpiresAt, &s.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revoked session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
 // Helper functions
 func sqlNullString(s string) sql.NullString {
 	if s == "" {
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// APIToken is a named API token. Only the SHA-256 hash of its value is
+// stored. A token with project IDs can only be used for those projects.
+type APIToken struct {
+	ID         string
+	Name       string
+	UserID     string
+	TokenHash  string
+	Prefix     string
+	Scope      string
+	ProjectIDs []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	RevokedBy  string
+}
+
+// CreateAPIToken stores a new API token
+func (d *Database) CreateAPIToken(t *APIToken) error {
+	projects, err := marshalStrings(t.ProjectIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project IDs: %w", err)
+	}
+	query := `
+		INSERT INTO api_tokens (id, name, user_id, token_hash, prefix, scope, project_ids, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := d.db.Exec(rebind(query), t.ID, t.Name, t.UserID, t.TokenHash, t.Prefix, t.Scope, projects, t.CreatedAt, sqlNullTime(t.ExpiresAt)); err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	return nil
+}
+
+const apiTokenColumns = `id, name, user_id, token_hash, prefix, scope, project_ids, created_at, expires_at, last_used_at, revoked_at, revoked_by`
+
+func scanAPIToken(scan func(dest ...interface{}) error) (*APIToken, error) {
+	t := &APIToken{}
+	var projects, revokedBy sql.NullString
+	var expires, lastUsed, revoked sql.NullTime
+	if err := scan(&t.ID, &t.Name, &t.UserID, &t.TokenHash, &t.Prefix, &t.Scope, &projects, &t.CreatedAt, &expires, &lastUsed, &revoked, &revokedBy); err != nil {
+		return nil, err
+	}
+	t.ProjectIDs = unmarshalStrings(projects)
+	t.RevokedBy = revokedBy.String
+	if expires.Valid {
+		t.ExpiresAt = &expires.Time
+	}
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		t.RevokedAt = &revoked.Time
+	}
+	return t, nil
+}
+
+// ListAPITokens returns all API tokens, revoked ones included, oldest first
+func (d *Database) ListAPITokens() ([]*APIToken, error) {
+	rows, err := d.db.Query(`SELECT ` + apiTokenColumns + ` FROM api_tokens ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// TouchAPIToken records when an API token was last used
+func (d *Database) TouchAPIToken(id string, usedAt time.Time) error {
+	if _, err := d.db.Exec(rebind(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`), usedAt, id); err != nil {
+		return fmt.Errorf("failed to update API token: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIToken revokes an API token
+func (d *Database) RevokeAPIToken(id string, revokedAt time.Time, revokedBy string) error {
+	result, err := d.db.Exec(rebind(`UPDATE api_tokens SET revoked_at = ?, revoked_by = ? WHERE id = ? AND revoked_at IS NULL`), revokedAt, sqlNullString(revokedBy), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("API token not found or already revoked: %s", id)
+	}
+	return nil
+}
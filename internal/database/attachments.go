@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BeadAttachment is the metadata for a file attached to a bead. StorageRef
+// identifies the contents within Storage ("disk" or "postgres").
+type BeadAttachment struct {
+	ID          string
+	BeadID      string
+	Filename    string
+	ContentType string
+	SizeBytes   int64
+	SHA256      string
+	Storage     string
+	StorageRef  string
+	Source      string
+	UploadedBy  string
+	CreatedAt   time.Time
+}
+
+const beadAttachmentColumns = `
+	id, bead_id, filename, content_type, size_bytes, sha256, storage, storage_ref,
+	source, uploaded_by, created_at
+`
+
+// CreateBeadAttachment inserts attachment metadata
+func (d *Database) CreateBeadAttachment(a *BeadAttachment) error {
+	query := `INSERT INTO bead_attachments (` + beadAttachmentColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		a.ID, a.BeadID, a.Filename, a.ContentType, a.SizeBytes, a.SHA256, a.Storage, a.StorageRef,
+		a.Source, sqlNullString(a.UploadedBy), a.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bead attachment: %w", err)
+	}
+	return nil
+}
+
+// GetBeadAttachment retrieves attachment metadata by ID. It returns nil if none exists.
+func (d *Database) GetBeadAttachment(id string) (*BeadAttachment, error) {
+	query := `SELECT ` + beadAttachmentColumns + ` FROM bead_attachments WHERE id = ?`
+	a, err := scanBeadAttachment(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bead attachment: %w", err)
+	}
+	return a, nil
+}
+
+// ListBeadAttachments returns a bead's attachments, oldest first
+func (d *Database) ListBeadAttachments(beadID string) ([]*BeadAttachment, error) {
+	query := `SELECT ` + beadAttachmentColumns + ` FROM bead_attachments
+		WHERE bead_id = ? ORDER BY created_at ASC`
+	rows, err := d.db.Query(rebind(query), beadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bead attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*BeadAttachment
+	for rows.Next() {
+		a, err := scanBeadAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bead attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// DeleteBeadAttachment removes attachment metadata. The caller is
+// responsible for removing the stored contents.
+func (d *Database) DeleteBeadAttachment(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM bead_attachments WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete bead attachment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("bead attachment not found: %s", id)
+	}
+	return nil
+}
+
+func scanBeadAttachment(row rowScanner) (*BeadAttachment, error) {
+	a := &BeadAttachment{}
+	var uploadedBy sql.NullString
+	if err := row.Scan(
+		&a.ID, &a.BeadID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.SHA256, &a.Storage, &a.StorageRef,
+		&a.Source, &uploadedBy, &a.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	a.UploadedBy = uploadedBy.String
+	return a, nil
+}
+
+// CreateLargeObject stores data as a PostgreSQL large object and returns its OID.
+func (d *Database) CreateLargeObject(data []byte) (uint32, error) {
+	if d.dialect != DialectPostgres {
+		return 0, fmt.Errorf("large objects require PostgreSQL (database is %s)", d.dialect)
+	}
+	var oid uint32
+	if err := d.db.QueryRow(`SELECT lo_from_bytea(0, $1)`, data).Scan(&oid); err != nil {
+		return 0, fmt.Errorf("failed to create large object: %w", err)
+	}
+	return oid, nil
+}
+
+// ReadLargeObject returns the contents of a PostgreSQL large object.
+func (d *Database) ReadLargeObject(oid uint32) ([]byte, error) {
+	if d.dialect != DialectPostgres {
+		return nil, fmt.Errorf("large objects require PostgreSQL (database is %s)", d.dialect)
+	}
+	var data []byte
+	if err := d.db.QueryRow(`SELECT lo_get($1)`, oid).Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to read large object %d: %w", oid, err)
+	}
+	return data, nil
+}
+
+// UnlinkLargeObject deletes a PostgreSQL large object.
+func (d *Database) UnlinkLargeObject(oid uint32) error {
+	if d.dialect != DialectPostgres {
+		return fmt.Errorf("large objects require PostgreSQL (database is %s)", d.dialect)
+	}
+	if _, err := d.db.Exec(`SELECT lo_unlink($1)`, oid); err != nil {
+		return fmt.Errorf("failed to unlink large object %d: %w", oid, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditLogEntry records one mutating API request. Before, After, and Diff
+// hold JSON and are only set for resources the audit log snapshots.
+type AuditLogEntry struct {
+	ID           string
+	ActorID      string
+	ActorName    string
+	ActorRole    string
+	Method       string
+	Path         string
+	ResourceType string
+	ResourceID   string
+	StatusCode   int
+	RemoteAddr   string
+	Before       string
+	After        string
+	Diff         string
+	CreatedAt    time.Time
+}
+
+// AuditLogFilter selects audit log entries. Zero fields match everything.
+// Actor matches either the actor's ID or name.
+type AuditLogFilter struct {
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	Method       string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+const auditLogColumns = `
+	id, actor_id, actor_name, actor_role, method, path, resource_type, resource_id,
+	status_code, remote_addr, before_json, after_json, diff_json, created_at
+`
+
+// CreateAuditLogEntry appends an entry to the audit log
+func (d *Database) CreateAuditLogEntry(e *AuditLogEntry) error {
+	query := `INSERT INTO audit_log (` + auditLogColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		e.ID, e.ActorID, sqlNullString(e.ActorName), sqlNullString(e.ActorRole), e.Method, e.Path,
+		e.ResourceType, e.ResourceID, e.StatusCode, sqlNullString(e.RemoteAddr),
+		sqlNullString(e.Before), sqlNullString(e.After), sqlNullString(e.Diff), e.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditLogEntries returns matching entries, newest first
+func (d *Database) ListAuditLogEntries(f AuditLogFilter) ([]*AuditLogEntry, error) {
+	query := `SELECT ` + auditLogColumns + ` FROM audit_log`
+	var where []string
+	var args []interface{}
+	if f.Actor != "" {
+		where = append(where, "(actor_id = ? OR actor_name = ?)")
+		args = append(args, f.Actor, f.Actor)
+	}
+	if f.ResourceType != "" {
+		where = append(where, "resource_type = ?")
+		args = append(args, f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		where = append(where, "resource_id = ?")
+		args = append(args, f.ResourceID)
+	}
+	if f.Method != "" {
+		where = append(where, "method = ?")
+		args = append(args, f.Method)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, f.Since)
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "created_at <= ?")
+		args = append(args, f.Until)
+	}
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if f.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		e := &AuditLogEntry{}
+		var actorName, actorRole, remoteAddr, before, after, diff sql.NullString
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &actorName, &actorRole, &e.Method, &e.Path, &e.ResourceType, &e.ResourceID,
+			&e.StatusCode, &remoteAddr, &before, &after, &diff, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		e.ActorName = actorName.String
+		e.ActorRole = actorRole.String
+		e.RemoteAddr = remoteAddr.String
+		e.Before = before.String
+		e.After = after.String
+		e.Diff = diff.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteAuditLogEntriesBefore removes entries older than before. With
+// exclude false it only touches the given resource types; with exclude
+// true it touches every other type.
+func (d *Database) DeleteAuditLogEntriesBefore(before time.Time, resourceTypes []string, exclude bool) (int64, error) {
+	query := `DELETE FROM audit_log WHERE created_at < ?`
+	args := []interface{}{before}
+	if len(resourceTypes) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(resourceTypes)), ", ")
+		if exclude {
+			query += ` AND resource_type NOT IN (` + placeholders + `)`
+		} else {
+			query += ` AND resource_type IN (` + placeholders + `)`
+		}
+		for _, t := range resourceTypes {
+			args = append(args, t)
+		}
+	} else if !exclude {
+		return 0, nil
+	}
+
+	result, err := d.db.Exec(rebind(query), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit log: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n, nil
+}
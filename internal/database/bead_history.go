@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BeadHistoryEntry records one changed field of a bead
+type BeadHistoryEntry struct {
+	ID        string
+	BeadID    string
+	ProjectID string
+	Field     string
+	OldValue  string
+	NewValue  string
+	Actor     string
+	ChangedAt time.Time
+}
+
+// BeadHistoryFilter selects a bead's history entries. Zero fields match
+// everything.
+type BeadHistoryFilter struct {
+	BeadID string
+	Field  string
+	Since  time.Time
+	Limit  int
+}
+
+const beadHistoryColumns = `id, bead_id, project_id, field, old_value, new_value, actor, changed_at`
+
+// CreateBeadHistoryEntries appends entries to the bead history in one
+// transaction
+func (d *Database) CreateBeadHistoryEntries(entries []*BeadHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record bead history: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := rebind(`INSERT INTO bead_history (` + beadHistoryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	for _, e := range entries {
+		if _, err := tx.Exec(query,
+			e.ID, e.BeadID, e.ProjectID, e.Field,
+			sqlNullString(e.OldValue), sqlNullString(e.NewValue), e.Actor, e.ChangedAt,
+		); err != nil {
+			return fmt.Errorf("failed to record bead history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record bead history: %w", err)
+	}
+	return nil
+}
+
+// ListBeadHistory returns matching entries, oldest first
+func (d *Database) ListBeadHistory(f BeadHistoryFilter) ([]*BeadHistoryEntry, error) {
+	query := `SELECT ` + beadHistoryColumns + ` FROM bead_history`
+	var where []string
+	var args []interface{}
+	if f.BeadID != "" {
+		where = append(where, "bead_id = ?")
+		args = append(args, f.BeadID)
+	}
+	if f.Field != "" {
+		where = append(where, "field = ?")
+		args = append(args, f.Field)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "changed_at >= ?")
+		args = append(args, f.Since)
+	}
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY changed_at, id`
+	if f.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, f.Limit)
+	}
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bead history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*BeadHistoryEntry
+	for rows.Next() {
+		e := &BeadHistoryEntry{}
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&e.ID, &e.BeadID, &e.ProjectID, &e.Field, &oldValue, &newValue, &e.Actor, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan bead history entry: %w", err)
+		}
+		e.OldValue = oldValue.String
+		e.NewValue = newValue.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
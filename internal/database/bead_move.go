@@ -0,0 +1,64 @@
+package database
+
+import "fmt"
+
+// beadRecordTables lists the tables keyed by bead ID that follow a bead
+// moved to another project. Those marked moveProject also move to the new
+// project; the others record what happened in the old one, e.g. history
+// and logs, and keep it.
+var beadRecordTables = []struct {
+	table       string
+	moveProject bool
+}{
+	{"conversation_contexts", true},
+	{"workflow_executions", true},
+	{"workflow_branches", false},
+	{"bead_comments", false},
+	{"bead_attachments", false},
+	{"bead_history", false},
+	{"sla_breaches", false},
+	{"command_logs", false},
+	{"analytics_request_logs", false},
+}
+
+// MoveBeadRecords re-keys a moved bead's records under its new ID, in one
+// transaction: its conversations, workflow execution and branches,
+// comments, attachments, history, SLA breaches, and its command and LLM
+// request logs, which its usage is counted from. Its conversations and
+// workflow execution also move to projectID. Tables that don't exist,
+// such as the request logs before analytics first starts, are skipped.
+func (d *Database) MoveBeadRecords(oldID, newID, projectID string) error {
+	exists := make(map[string]bool, len(beadRecordTables))
+	for _, t := range beadRecordTables {
+		ok, err := d.tableExists(t.table)
+		if err != nil {
+			return fmt.Errorf("failed to move bead records in %s: %w", t.table, err)
+		}
+		exists[t.table] = ok
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to move bead records: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range beadRecordTables {
+		if !exists[t.table] {
+			continue
+		}
+		query := `UPDATE ` + t.table + ` SET bead_id = ? WHERE bead_id = ?`
+		args := []interface{}{newID, oldID}
+		if t.moveProject {
+			query = `UPDATE ` + t.table + ` SET bead_id = ?, project_id = ? WHERE bead_id = ?`
+			args = []interface{}{newID, projectID, oldID}
+		}
+		if _, err := tx.Exec(rebind(query), args...); err != nil {
+			return fmt.Errorf("failed to move bead records in %s: %w", t.table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to move bead records: %w", err)
+	}
+	return nil
+}
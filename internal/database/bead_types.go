@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BeadType is an admin-defined bead type. A nil DefaultPriority leaves the
+// priority to the creator.
+type BeadType struct {
+	Name            string
+	Description     string
+	DefaultPriority *int
+	RequiredContext []string
+	DefaultWorkflow string
+	Icon            string
+	Color           string
+	CreatedBy       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// UpsertBeadType stores a bead type, replacing any existing one of the same
+// name but keeping its creator and creation time.
+func (d *Database) UpsertBeadType(t *BeadType) error {
+	required, err := json.Marshal(t.RequiredContext)
+	if err != nil {
+		return fmt.Errorf("failed to marshal required context: %w", err)
+	}
+	var priority sql.NullInt64
+	if t.DefaultPriority != nil {
+		priority = sql.NullInt64{Int64: int64(*t.DefaultPriority), Valid: true}
+	}
+	query := `
+		INSERT INTO bead_types (name, description, default_priority, required_context, default_workflow, icon, color, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE
+		SET description = excluded.description, default_priority = excluded.default_priority,
+			required_context = excluded.required_context, default_workflow = excluded.default_workflow,
+			icon = excluded.icon, color = excluded.color, updated_at = excluded.updated_at
+	`
+	_, err = d.db.Exec(rebind(query), t.Name, sqlNullString(t.Description), priority, string(required),
+		sqlNullString(t.DefaultWorkflow), sqlNullString(t.Icon), sqlNullString(t.Color), sqlNullString(t.CreatedBy),
+		t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save bead type: %w", err)
+	}
+	return nil
+}
+
+// GetBeadType retrieves a bead type by name. It returns nil if there is
+// none.
+func (d *Database) GetBeadType(name string) (*BeadType, error) {
+	query := `
+		SELECT name, description, default_priority, required_context, default_workflow, icon, color, created_by, created_at, updated_at
+		FROM bead_types WHERE name = ?
+	`
+	t, err := scanBeadType(d.db.QueryRow(rebind(query), name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bead type: %w", err)
+	}
+	return t, nil
+}
+
+// ListBeadTypes returns every bead type, ordered by name.
+func (d *Database) ListBeadTypes() ([]*BeadType, error) {
+	query := `
+		SELECT name, description, default_priority, required_context, default_workflow, icon, color, created_by, created_at, updated_at
+		FROM bead_types ORDER BY name
+	`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bead types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []*BeadType
+	for rows.Next() {
+		t, err := scanBeadType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bead type: %w", err)
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// DeleteBeadType removes a bead type. It is not an error if there is none.
+func (d *Database) DeleteBeadType(name string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM bead_types WHERE name = ?`), name); err != nil {
+		return fmt.Errorf("failed to delete bead type: %w", err)
+	}
+	return nil
+}
+
+func scanBeadType(row rowScanner) (*BeadType, error) {
+	t := &BeadType{}
+	var description, workflow, icon, color, createdBy sql.NullString
+	var priority sql.NullInt64
+	var required string
+	err := row.Scan(&t.Name, &description, &priority, &required, &workflow, &icon, &color, &createdBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(required), &t.RequiredContext); err != nil {
+		return nil, fmt.Errorf("failed to parse required context: %w", err)
+	}
+	if priority.Valid {
+		p := int(priority.Int64)
+		t.DefaultPriority = &p
+	}
+	t.Description = description.String
+	t.DefaultWorkflow = workflow.String
+	t.Icon = icon.String
+	t.Color = color.String
+	t.CreatedBy = createdBy.String
+	return t, nil
+}
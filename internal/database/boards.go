@@ -0,0 +1,72 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BoardColumn is one column of a project board. Statuses lists the bead
+// statuses shown in the column; a zero WIPLimit is not enforced.
+type BoardColumn struct {
+	Name     string   `json:"name"`
+	Statuses []string `json:"statuses"`
+	WIPLimit int      `json:"wip_limit,omitempty"`
+}
+
+// ProjectBoard is a project's board layout
+type ProjectBoard struct {
+	ProjectID string
+	Columns   []BoardColumn
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// UpsertProjectBoard stores a project's board, replacing any existing one
+func (d *Database) UpsertProjectBoard(b *ProjectBoard) error {
+	columns, err := json.Marshal(b.Columns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal board columns: %w", err)
+	}
+	query := `
+		INSERT INTO project_boards (project_id, columns, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE
+		SET columns = excluded.columns, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`
+	if _, err := d.db.Exec(rebind(query), b.ProjectID, string(columns), sqlNullString(b.UpdatedBy), b.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save project board: %w", err)
+	}
+	return nil
+}
+
+// GetProjectBoard retrieves a project's board. It returns nil if the project
+// has none.
+func (d *Database) GetProjectBoard(projectID string) (*ProjectBoard, error) {
+	query := `SELECT project_id, columns, updated_by, updated_at FROM project_boards WHERE project_id = ?`
+	b := &ProjectBoard{}
+	var columns string
+	var updatedBy sql.NullString
+	err := d.db.QueryRow(rebind(query), projectID).Scan(&b.ProjectID, &columns, &updatedBy, &b.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project board: %w", err)
+	}
+	if err := json.Unmarshal([]byte(columns), &b.Columns); err != nil {
+		return nil, fmt.Errorf("failed to parse board columns: %w", err)
+	}
+	b.UpdatedBy = updatedBy.String
+	return b, nil
+}
+
+// DeleteProjectBoard removes a project's board. It is not an error if the
+// project has none.
+func (d *Database) DeleteProjectBoard(projectID string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM project_boards WHERE project_id = ?`), projectID); err != nil {
+		return fmt.Errorf("failed to delete project board: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Budget is a daily or monthly token/cost limit for a project, agent, or provider
+type Budget struct {
+	ID           string
+	Scope        string
+	ScopeID      string
+	Period       string
+	TokenLimit   int64
+	CostLimitUSD float64
+	CreatedBy    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+const budgetColumns = `
+	id, scope, scope_id, period, token_limit, cost_limit_usd, created_by, created_at, updated_at
+`
+
+// CreateBudget inserts a new budget
+func (d *Database) CreateBudget(b *Budget) error {
+	query := `INSERT INTO budgets (` + budgetColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		b.ID, b.Scope, b.ScopeID, b.Period, b.TokenLimit, b.CostLimitUSD,
+		sqlNullString(b.CreatedBy), b.CreatedAt, b.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", err)
+	}
+	return nil
+}
+
+// UpdateBudget updates a budget's limits
+func (d *Database) UpdateBudget(b *Budget) error {
+	query := `UPDATE budgets SET token_limit = ?, cost_limit_usd = ?, updated_at = ? WHERE id = ?`
+	result, err := d.db.Exec(rebind(query), b.TokenLimit, b.CostLimitUSD, b.UpdatedAt, b.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("budget not found: %s", b.ID)
+	}
+	return nil
+}
+
+// GetBudget retrieves a budget by ID. It returns nil if none exists.
+func (d *Database) GetBudget(id string) (*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE id = ?`
+	b, err := scanBudget(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return b, nil
+}
+
+// FindBudget retrieves the budget for a scope and period. It returns nil if
+// none exists.
+func (d *Database) FindBudget(scope, scopeID, period string) (*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets WHERE scope = ? AND scope_id = ? AND period = ?`
+	b, err := scanBudget(d.db.QueryRow(rebind(query), scope, scopeID, period))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return b, nil
+}
+
+// ListBudgets returns all budgets ordered by scope
+func (d *Database) ListBudgets() ([]*Budget, error) {
+	query := `SELECT ` + budgetColumns + ` FROM budgets ORDER BY scope, scope_id, period`
+	rows, err := d.db.Query(rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var budgets []*Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// DeleteBudget removes a budget
+func (d *Database) DeleteBudget(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM budgets WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("budget not found: %s", id)
+	}
+	return nil
+}
+
+func scanBudget(row rowScanner) (*Budget, error) {
+	b := &Budget{}
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&b.ID, &b.Scope, &b.ScopeID, &b.Period, &b.TokenLimit, &b.CostLimitUSD,
+		&createdBy, &b.CreatedAt, &b.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	b.CreatedBy = createdBy.String
+	return b, nil
+}
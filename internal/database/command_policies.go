@@ -0,0 +1,206 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CommandPolicyRule allows or denies agent shell commands matching a
+// pattern. An empty ProjectID applies the rule to every project.
+type CommandPolicyRule struct {
+	ID             string
+	ProjectID      string
+	Effect         string
+	Syntax         string
+	Pattern        string
+	Description    string
+	CreateDecision bool
+	CreatedBy      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CommandPolicyViolation records a command the policy blocked. RuleID is
+// empty when the command matched no allow rule.
+type CommandPolicyViolation struct {
+	ID         string
+	RuleID     string
+	ProjectID  string
+	AgentID    string
+	BeadID     string
+	Command    string
+	Reason     string
+	DecisionID string
+	CreatedAt  time.Time
+}
+
+const commandPolicyRuleColumns = `
+	id, project_id, effect, syntax, pattern, description, create_decision,
+	created_by, created_at, updated_at
+`
+
+const commandPolicyViolationColumns = `
+	id, rule_id, project_id, agent_id, bead_id, command, reason, decision_id, created_at
+`
+
+// CreateCommandPolicyRule inserts a new rule
+func (d *Database) CreateCommandPolicyRule(r *CommandPolicyRule) error {
+	query := `INSERT INTO command_policy_rules (` + commandPolicyRuleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		r.ID, r.ProjectID, r.Effect, r.Syntax, r.Pattern, sqlNullString(r.Description), r.CreateDecision,
+		sqlNullString(r.CreatedBy), r.CreatedAt, r.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create command policy rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateCommandPolicyRule replaces a rule's project, match, and settings
+func (d *Database) UpdateCommandPolicyRule(r *CommandPolicyRule) error {
+	query := `UPDATE command_policy_rules
+		SET project_id = ?, effect = ?, syntax = ?, pattern = ?, description = ?, create_decision = ?, updated_at = ?
+		WHERE id = ?`
+	result, err := d.db.Exec(rebind(query),
+		r.ProjectID, r.Effect, r.Syntax, r.Pattern, sqlNullString(r.Description), r.CreateDecision, r.UpdatedAt, r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update command policy rule: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("command policy rule not found: %s", r.ID)
+	}
+	return nil
+}
+
+// GetCommandPolicyRule retrieves a rule by ID. It returns nil if none exists.
+func (d *Database) GetCommandPolicyRule(id string) (*CommandPolicyRule, error) {
+	query := `SELECT ` + commandPolicyRuleColumns + ` FROM command_policy_rules WHERE id = ?`
+	r, err := scanCommandPolicyRule(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get command policy rule: %w", err)
+	}
+	return r, nil
+}
+
+// ListCommandPolicyRules returns the rules for a project, including the
+// rules for every project, oldest first. An empty projectID returns all
+// rules.
+func (d *Database) ListCommandPolicyRules(projectID string) ([]*CommandPolicyRule, error) {
+	query := `SELECT ` + commandPolicyRuleColumns + ` FROM command_policy_rules`
+	var args []interface{}
+	if projectID != "" {
+		query += ` WHERE project_id = '' OR project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY created_at, id`
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list command policy rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*CommandPolicyRule
+	for rows.Next() {
+		r, err := scanCommandPolicyRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan command policy rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteCommandPolicyRule removes a rule. Violations already recorded are kept.
+func (d *Database) DeleteCommandPolicyRule(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM command_policy_rules WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete command policy rule: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("command policy rule not found: %s", id)
+	}
+	return nil
+}
+
+// CreateCommandPolicyViolation records a blocked command
+func (d *Database) CreateCommandPolicyViolation(v *CommandPolicyViolation) error {
+	query := `INSERT INTO command_policy_violations (` + commandPolicyViolationColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		v.ID, v.RuleID, v.ProjectID, sqlNullString(v.AgentID), sqlNullString(v.BeadID),
+		v.Command, v.Reason, sqlNullString(v.DecisionID), v.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record command policy violation: %w", err)
+	}
+	return nil
+}
+
+// FindCommandPolicyDecision returns the decision already raised for a
+// rule's violations on a bead, or "" if there is none.
+func (d *Database) FindCommandPolicyDecision(ruleID, beadID string) (string, error) {
+	query := `SELECT decision_id FROM command_policy_violations
+		WHERE rule_id = ? AND bead_id = ? AND decision_id IS NOT NULL AND decision_id <> ''
+		ORDER BY created_at DESC LIMIT 1`
+	var decisionID string
+	err := d.db.QueryRow(rebind(query), ruleID, beadID).Scan(&decisionID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find command policy decision: %w", err)
+	}
+	return decisionID, nil
+}
+
+// ListCommandPolicyViolations returns the most recent violations, newest
+// first, optionally filtered by project
+func (d *Database) ListCommandPolicyViolations(projectID string, limit int) ([]*CommandPolicyViolation, error) {
+	query := `SELECT ` + commandPolicyViolationColumns + ` FROM command_policy_violations`
+	var args []interface{}
+	if projectID != "" {
+		query += ` WHERE project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list command policy violations: %w", err)
+	}
+	defer rows.Close()
+
+	var violations []*CommandPolicyViolation
+	for rows.Next() {
+		v := &CommandPolicyViolation{}
+		var agentID, beadID, decisionID sql.NullString
+		if err := rows.Scan(&v.ID, &v.RuleID, &v.ProjectID, &agentID, &beadID, &v.Command, &v.Reason, &decisionID, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan command policy violation: %w", err)
+		}
+		v.AgentID = agentID.String
+		v.BeadID = beadID.String
+		v.DecisionID = decisionID.String
+		violations = append(violations, v)
+	}
+	return violations, rows.Err()
+}
+
+func scanCommandPolicyRule(row rowScanner) (*CommandPolicyRule, error) {
+	r := &CommandPolicyRule{}
+	var description, createdBy sql.NullString
+	if err := row.Scan(
+		&r.ID, &r.ProjectID, &r.Effect, &r.Syntax, &r.Pattern, &description, &r.CreateDecision,
+		&createdBy, &r.CreatedAt, &r.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	r.Description = description.String
+	r.CreatedBy = createdBy.String
+	return r, nil
+}
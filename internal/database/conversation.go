@@ -8,6 +8,44 @@ import (
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
+const conversationColumns = `
+	session_id, bead_id, project_id, messages,
+	created_at, updated_at, expires_at, token_count, metadata, loop_state
+`
+
+// scanConversationContext reads a conversationColumns row.
+func scanConversationContext(row interface{ Scan(...interface{}) error }) (*models.ConversationContext, error) {
+	ctx := &models.ConversationContext{}
+	var messagesJSON, metadataJSON []byte
+	var loopStateJSON sql.NullString
+	if err := row.Scan(
+		&ctx.SessionID,
+		&ctx.BeadID,
+		&ctx.ProjectID,
+		&messagesJSON,
+		&ctx.CreatedAt,
+		&ctx.UpdatedAt,
+		&ctx.ExpiresAt,
+		&ctx.TokenCount,
+		&metadataJSON,
+		&loopStateJSON,
+	); err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSON fields
+	if err := ctx.SetMessagesFromJSON(messagesJSON); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
+	}
+	if err := ctx.SetMetadataFromJSON(metadataJSON); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	if err := ctx.SetLoopStateFromJSON([]byte(loopStateJSON.String)); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal loop state: %w", err)
+	}
+	return ctx, nil
+}
+
 // CreateConversationContext inserts a new conversation context
 func (d *Database) CreateConversationContext(ctx *models.ConversationContext) error {
 	messagesJSON, err := ctx.MessagesJSON()
@@ -20,11 +58,14 @@ func (d *Database) CreateConversationContext(ctx *models.ConversationContext) er
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	loopStateJSON, err := ctx.LoopStateJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal loop state: %w", err)
+	}
+
 	query := `
-		INSERT INTO conversation_contexts (
-			session_id, bead_id, project_id, messages,
-			created_at, updated_at, expires_at, token_count, metadata
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO conversation_contexts (` + conversationColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = d.db.Exec(rebind(query),
@@ -37,6 +78,7 @@ func (d *Database) CreateConversationContext(ctx *models.ConversationContext) er
 		ctx.ExpiresAt,
 		ctx.TokenCount,
 		metadataJSON,
+		loopStateJSON,
 	)
 
 	if err != nil {
@@ -48,86 +90,38 @@ func (d *Database) CreateConversationContext(ctx *models.ConversationContext) er
 // GetConversationContext retrieves a conversation context by session ID
 func (d *Database) GetConversationContext(sessionID string) (*models.ConversationContext, error) {
 	query := `
-		SELECT session_id, bead_id, project_id, messages,
-			   created_at, updated_at, expires_at, token_count, metadata
+		SELECT ` + conversationColumns + `
 		FROM conversation_contexts
 		WHERE session_id = ?
 	`
 
-	ctx := &models.ConversationContext{}
-	var messagesJSON, metadataJSON []byte
-
-	err := d.db.QueryRow(rebind(query), sessionID).Scan(
-		&ctx.SessionID,
-		&ctx.BeadID,
-		&ctx.ProjectID,
-		&messagesJSON,
-		&ctx.CreatedAt,
-		&ctx.UpdatedAt,
-		&ctx.ExpiresAt,
-		&ctx.TokenCount,
-		&metadataJSON,
-	)
-
+	ctx, err := scanConversationContext(d.db.QueryRow(rebind(query), sessionID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("conversation context not found: %s", sessionID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation context: %w", err)
 	}
-
-	// Unmarshal JSON fields
-	if err := ctx.SetMessagesFromJSON(messagesJSON); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
-	}
-	if err := ctx.SetMetadataFromJSON(metadataJSON); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-	}
-
 	return ctx, nil
 }
 
 // GetConversationContextByBeadID retrieves the conversation context for a specific bead
 func (d *Database) GetConversationContextByBeadID(beadID string) (*models.ConversationContext, error) {
 	query := `
-		SELECT session_id, bead_id, project_id, messages,
-			   created_at, updated_at, expires_at, token_count, metadata
+		SELECT ` + conversationColumns + `
 		FROM conversation_contexts
 		WHERE bead_id = ?
 		ORDER BY updated_at DESC
 		LIMIT 1
 	`
 
-	ctx := &models.ConversationContext{}
-	var messagesJSON, metadataJSON []byte
-
-	err := d.db.QueryRow(rebind(query), beadID).Scan(
-		&ctx.SessionID,
-		&ctx.BeadID,
-		&ctx.ProjectID,
-		&messagesJSON,
-		&ctx.CreatedAt,
-		&ctx.UpdatedAt,
-		&ctx.ExpiresAt,
-		&ctx.TokenCount,
-		&metadataJSON,
-	)
-
+	ctx, err := scanConversationContext(d.db.QueryRow(rebind(query), beadID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("conversation context not found for bead: %s", beadID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get conversation context: %w", err)
 	}
-
-	// Unmarshal JSON fields
-	if err := ctx.SetMessagesFromJSON(messagesJSON); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
-	}
-	if err := ctx.SetMetadataFromJSON(metadataJSON); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-	}
-
 	return ctx, nil
 }
 
@@ -163,9 +157,16 @@ func (d *Database) UpdateConversationContext(ctx *models.ConversationContext) er
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	loopStateJSON, err := ctx.LoopStateJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal loop state: %w", err)
+	}
+
+	// One statement, so the messages and the loop state saved with them
+	// are always from the same point in the loop.
 	query := `
 		UPDATE conversation_contexts
-		SET messages = ?, updated_at = ?, token_count = ?, metadata = ?
+		SET messages = ?, updated_at = ?, token_count = ?, metadata = ?, loop_state = ?
 		WHERE session_id = ?
 	`
 
@@ -174,6 +175,7 @@ func (d *Database) UpdateConversationContext(ctx *models.ConversationContext) er
 		ctx.UpdatedAt,
 		ctx.TokenCount,
 		metadataJSON,
+		loopStateJSON,
 		ctx.SessionID,
 	)
 
@@ -241,8 +243,7 @@ func (d *Database) DeleteExpiredConversationContexts() (int64, error) {
 // ListConversationContextsByProject retrieves all conversation contexts for a project
 func (d *Database) ListConversationContextsByProject(projectID string, limit int) ([]*models.ConversationContext, error) {
 	query := `
-		SELECT session_id, bead_id, project_id, messages,
-			   created_at, updated_at, expires_at, token_count, metadata
+		SELECT ` + conversationColumns + `
 		FROM conversation_contexts
 		WHERE project_id = ?
 		ORDER BY updated_at DESC
@@ -257,33 +258,10 @@ func (d *Database) ListConversationContextsByProject(projectID string, limit int
 
 	var contexts []*models.ConversationContext
 	for rows.Next() {
-		ctx := &models.ConversationContext{}
-		var messagesJSON, metadataJSON []byte
-
-		err := rows.Scan(
-			&ctx.SessionID,
-			&ctx.BeadID,
-			&ctx.ProjectID,
-			&messagesJSON,
-			&ctx.CreatedAt,
-			&ctx.UpdatedAt,
-			&ctx.ExpiresAt,
-			&ctx.TokenCount,
-			&metadataJSON,
-		)
-
+		ctx, err := scanConversationContext(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan conversation context: %w", err)
 		}
-
-		// Unmarshal JSON fields
-		if err := ctx.SetMessagesFromJSON(messagesJSON); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal messages: %w", err)
-		}
-		if err := ctx.SetMetadataFromJSON(metadataJSON); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
-		}
-
 		contexts = append(contexts, ctx)
 	}
 
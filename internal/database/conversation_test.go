@@ -1,6 +1,7 @@
 package database
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -258,3 +259,102 @@ func TestResetConversationMessages_NoSystemMessage(t *testing.T) {
 		t.Errorf("Expected token count 0 after reset, got %d", retrieved.TokenCount)
 	}
 }
+
+func TestConversationContext_LoopState(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite(:memory:) failed: %v", err)
+	}
+	defer db.Close()
+
+	ctx := models.NewConversationContext("session-loop", "bead-loop", "proj-1", 24*time.Hour)
+	if err := db.CreateConversationContext(ctx); err != nil {
+		t.Fatalf("CreateConversationContext() error = %v", err)
+	}
+	got, err := db.GetConversationContextByBeadID("bead-loop")
+	if err != nil {
+		t.Fatalf("GetConversationContextByBeadID() error = %v", err)
+	}
+	if got.LoopState != nil {
+		t.Errorf("LoopState = %+v before any checkpoint, want nil", got.LoopState)
+	}
+
+	ctx.AddMessage("assistant", `{"action": "read", "path": "main.go"}`, 10)
+	ctx.LoopState = &models.LoopState{
+		Iteration:    4,
+		TokensUsed:   1200,
+		ActionLog:    []byte(`[{"iteration":4}]`),
+		FilesChanged: []string{"main.go"},
+		ActionHashes: map[string]int{"abc": 2},
+	}
+	if err := db.UpdateConversationContext(ctx); err != nil {
+		t.Fatalf("UpdateConversationContext() error = %v", err)
+	}
+	got, err = db.GetConversationContextByBeadID("bead-loop")
+	if err != nil {
+		t.Fatalf("GetConversationContextByBeadID() error = %v", err)
+	}
+	if got.LoopState == nil || got.LoopState.Iteration != 4 || got.LoopState.TokensUsed != 1200 ||
+		len(got.LoopState.FilesChanged) != 1 || got.LoopState.ActionHashes["abc"] != 2 ||
+		string(got.LoopState.ActionLog) != `[{"iteration":4}]` {
+		t.Errorf("LoopState = %+v, want the checkpoint saved", got.LoopState)
+	}
+	if len(got.Messages) != 1 {
+		t.Errorf("Messages = %d, want 1 saved with the checkpoint", len(got.Messages))
+	}
+
+	ctx.LoopState = nil
+	if err := db.UpdateConversationContext(ctx); err != nil {
+		t.Fatalf("UpdateConversationContext() error = %v", err)
+	}
+	if got, _ = db.GetConversationContext("session-loop"); got.LoopState != nil {
+		t.Errorf("LoopState = %+v after clearing, want nil", got.LoopState)
+	}
+}
+
+func TestMoveBeadRecords(t *testing.T) {
+	db, err := NewSQLite(filepath.Join(t.TempDir(), "loom.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	conv := models.NewConversationContext("session-move", "app-007", "app", 24*time.Hour)
+	if err := db.CreateConversationContext(conv); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateComment(makeTestComment("comment-move", "app-007")); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if _, err := db.DB().Exec(`INSERT INTO sla_breaches (bead_id, kind, policy_id, project_id, due_at, breached_at) VALUES (?, 'resolution', 'p1', 'app', ?, ?)`, "app-007", now, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB().Exec(`INSERT INTO command_logs (id, agent_id, bead_id, project_id, command, working_dir, exit_code, duration_ms, started_at, completed_at) VALUES ('cmd-1', 'agent-1', ?, 'app', 'make', '/src', 0, 1, ?, ?)`, "app-007", now, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// The analytics request logs don't exist yet and are skipped.
+	if err := db.MoveBeadRecords("app-007", "lib-012", "lib"); err != nil {
+		t.Fatalf("MoveBeadRecords() error = %v", err)
+	}
+	got, err := db.GetConversationContextByBeadID("lib-012")
+	if err != nil {
+		t.Fatalf("conversation not moved: %v", err)
+	}
+	if got.SessionID != "session-move" || got.ProjectID != "lib" {
+		t.Errorf("conversation = %s in %s", got.SessionID, got.ProjectID)
+	}
+	if comments, _ := db.GetCommentsByBeadID("lib-012"); len(comments) != 1 {
+		t.Errorf("comments under the new ID = %d, want 1", len(comments))
+	}
+	if comments, _ := db.GetCommentsByBeadID("app-007"); len(comments) != 0 {
+		t.Errorf("comments left under the old ID = %d", len(comments))
+	}
+	for _, table := range []string{"sla_breaches", "command_logs"} {
+		var n int
+		if err := db.DB().QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE bead_id = ? AND project_id = 'app'`, "lib-012").Scan(&n); err != nil || n != 1 {
+			t.Errorf("%s under the new ID in the old project = %d (%v), want 1", table, n, err)
+		}
+	}
+}
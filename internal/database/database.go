@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	internalmodels "github.com/jordanhubbard/loom/internal/models"
@@ -12,20 +14,34 @@ import (
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
-// Database represents the loom database (PostgreSQL only)
+// Database represents the loom database. PostgreSQL is the primary backend;
+// SQLite is supported for single-node deployments and local development.
 type Database struct {
 	db         *sql.DB
+	dialect    Dialect
 	supportsHA bool
+	// pgvector is set when the vector extension is installed and memory
+	// documents can be searched in the database.
+	pgvector bool
+	// manualMigrations is set by the ManualMigrations option.
+	manualMigrations bool
+	// source is the connection string or file the database was opened
+	// with; see DataSource.
+	source string
 }
 
-// New creates a new database instance and initializes the schema
-// NewFromEnv creates a PostgreSQL database instance from environment variables.
-func NewFromEnv() (*Database, error) {
-	return NewPostgreSQL()
+// NewFromEnv creates a database instance from environment variables.
+// DB_TYPE=sqlite selects SQLite (file from SQLITE_PATH); anything else
+// selects PostgreSQL.
+func NewFromEnv(opts ...Option) (*Database, error) {
+	if os.Getenv("DB_TYPE") == string(DialectSQLite) {
+		return NewSQLite(os.Getenv("SQLITE_PATH"), opts...)
+	}
+	return NewPostgreSQL(opts...)
 }
 
 // NewPostgreSQL creates a PostgreSQL database instance from environment variables
-func NewPostgreSQL() (*Database, error) {
+func NewPostgreSQL(opts ...Option) (*Database, error) {
 	host := os.Getenv("POSTGRES_HOST")
 	if host == "" {
 		host = "localhost"
@@ -82,84 +98,70 @@ func NewPostgreSQL() (*Database, error) {
 
 	d := &Database{
 		db:         db,
+		dialect:    DialectPostgres,
 		supportsHA: true,
+		source:     connStr,
 	}
-
-	// Initialize schema
-	if err := d.initSchemaPostgres(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize PostgreSQL schema: %w", err)
-	}
-
-	// Run migrations
-	if err := d.migrateProviderOwnership(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider ownership: %w", err)
-	}
-
-	if err := d.migrateProviderRouting(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider routing: %w", err)
+	for _, opt := range opts {
+		opt(d)
 	}
 
-	if err := d.migrateProviderScoring(); err != nil {
+	if err := d.migrate(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider scoring: %w", err)
+		return nil, err
 	}
 
-	if err := d.migrateMotivations(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate motivations: %w", err)
-	}
+	return d, nil
+}
 
-	if err := d.migrateWorkflows(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate workflows: %w", err)
-	}
+// memoryDBSeq keeps concurrently opened in-memory databases apart.
+var memoryDBSeq atomic.Int64
 
-	if err := d.migrateActivity(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate activity: %w", err)
-	}
+// DefaultSQLitePath is where the SQLite database lives when no path is configured.
+const DefaultSQLitePath = "./data/loom.db"
 
-	if err := d.migrateComments(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate comments: %w", err)
+// NewSQLite opens (or creates) a SQLite database file and runs migrations.
+// SQLite has no cross-process locking story, so distributed locks and
+// instance registration (SupportsHA) are disabled.
+func NewSQLite(path string, opts ...Option) (*Database, error) {
+	if path == "" {
+		path = DefaultSQLitePath
 	}
-
-	if err := d.migrateConversations(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate conversations: %w", err)
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+		}
 	}
 
-	if err := migratePatterns(d.db); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate patterns: %w", err)
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	if path == ":memory:" {
+		// A plain in-memory database exists per connection. Name it and
+		// share the cache so every pooled connection sees the same data.
+		dsn = fmt.Sprintf("file:loom-mem-%d?mode=memory&cache=shared&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+			memoryDBSeq.Add(1))
 	}
-
-	if err := d.migrateCredentials(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate credentials: %w", err)
+	db, err := sql.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
 	}
 
-	if err := d.migrateLessons(); err != nil {
+	if err := db.Ping(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to migrate lessons: %w", err)
+		return nil, fmt.Errorf("failed to ping SQLite database: %w", err)
 	}
 
-	if err := d.migrateRequestLogs(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate request logs: %w", err)
+	d := &Database{
+		db:      db,
+		dialect: DialectSQLite,
+		source:  path,
 	}
-
-	if err := d.migrateProviderAPIKey(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider api key: %w", err)
+	for _, opt := range opts {
+		opt(d)
 	}
 
-	if err := d.migrateProjectMemory(); err != nil {
+	if err := d.migrate(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to migrate project memory: %w", err)
+		return nil, err
 	}
 
 	return d, nil
@@ -167,8 +169,7 @@ func NewPostgreSQL() (*Database, error) {
 
 // migrateRequestLogs adds columns to request_logs that the analytics package expects.
 func (d *Database) migrateRequestLogs() error {
-	_, err := d.db.Exec(`ALTER TABLE request_logs ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`)
-	if err != nil {
+	if err := d.addColumnIfMissing("request_logs", "created_at", "TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP"); err != nil {
 		return fmt.Errorf("migrateRequestLogs: %w", err)
 	}
 	return nil
@@ -184,9 +185,20 @@ func (d *Database) DB() *sql.DB {
 	return d.db
 }
 
-// Type returns the database type
+// Type returns the database type ("postgres" or "sqlite")
 func (d *Database) Type() string {
-	return "postgres"
+	return string(d.dialect)
+}
+
+// Dialect returns the SQL dialect of the underlying backend.
+func (d *Database) Dialect() Dialect {
+	return d.dialect
+}
+
+// DataSource returns the PostgreSQL connection string or the SQLite file
+// the database was opened with, for tools such as pg_dump.
+func (d *Database) DataSource() string {
+	return d.source
 }
 
 // SupportsHA returns whether the database supports HA features
@@ -387,8 +399,8 @@ func (d *Database) UpsertAgent(agent *models.Agent) error {
 	}
 
 	query := `
-		INSERT INTO agents (id, name, role, persona_name, provider_id, status, current_bead, project_id, started_at, last_active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO agents (id, name, role, persona_name, provider_id, status, current_bead, project_id, capabilities, hold, started_at, last_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			role = excluded.role,
@@ -397,6 +409,8 @@ func (d *Database) UpsertAgent(agent *models.Agent) error {
 			status = excluded.status,
 			current_bead = excluded.current_bead,
 			project_id = excluded.project_id,
+			capabilities = excluded.capabilities,
+			hold = excluded.hold,
 			last_active = excluded.last_active
 	`
 
@@ -411,6 +425,14 @@ func (d *Database) UpsertAgent(agent *models.Agent) error {
 	if agent.ProjectID != "" {
 		projectID = agent.ProjectID
 	}
+	var capabilities interface{}
+	if len(agent.Capabilities) > 0 {
+		b, err := json.Marshal(agent.Capabilities)
+		if err != nil {
+			return fmt.Errorf("failed to marshal agent capabilities: %w", err)
+		}
+		capabilities = string(b)
+	}
 
 	_, err := d.db.Exec(rebind(query),
 		agent.ID,
@@ -421,6 +443,8 @@ func (d *Database) UpsertAgent(agent *models.Agent) error {
 		agent.Status,
 		currentBead,
 		projectID,
+		capabilities,
+		sqlNullString(agent.Hold),
 		agent.StartedAt,
 		agent.LastActive,
 	)
@@ -432,7 +456,7 @@ func (d *Database) UpsertAgent(agent *models.Agent) error {
 
 func (d *Database) ListAgents() ([]*models.Agent, error) {
 	query := `
-		SELECT id, name, role, persona_name, provider_id, status, current_bead, project_id, started_at, last_active
+		SELECT id, name, role, persona_name, provider_id, status, current_bead, project_id, capabilities, hold, started_at, last_active
 		FROM agents
 		ORDER BY started_at DESC
 	`
@@ -445,7 +469,7 @@ func (d *Database) ListAgents() ([]*models.Agent, error) {
 	var agents []*models.Agent
 	for rows.Next() {
 		a := &models.Agent{}
-		var providerID, currentBead, projectID sql.NullString
+		var providerID, currentBead, projectID, capabilities, hold sql.NullString
 		err := rows.Scan(
 			&a.ID,
 			&a.Name,
@@ -455,6 +479,8 @@ func (d *Database) ListAgents() ([]*models.Agent, error) {
 			&a.Status,
 			&currentBead,
 			&projectID,
+			&capabilities,
+			&hold,
 			&a.StartedAt,
 			&a.LastActive,
 		)
@@ -471,6 +497,10 @@ func (d *Database) ListAgents() ([]*models.Agent, error) {
 		if projectID.Valid {
 			a.ProjectID = projectID.String
 		}
+		if capabilities.Valid && capabilities.String != "" {
+			_ = json.Unmarshal([]byte(capabilities.String), &a.Capabilities)
+		}
+		a.Hold = hold.String
 		agents = append(agents, a)
 	}
 	return agents, nil
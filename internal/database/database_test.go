@@ -2262,6 +2262,41 @@ func TestMarkAllNotificationsRead(t *testing.T) {
 	}
 }
 
+func TestNotificationEmailQueue(t *testing.T) {
+	db := newTestDB(t)
+	ensureUserExists(t, db, "user-eq", "user_eq")
+	base := time.Now().Add(-time.Hour)
+	for i, status := range []string{"queued", "", "queued"} {
+		n := &Notification{
+			ID: "notif-eq-" + string(rune('0'+i)), UserID: "user-eq", EventType: "e",
+			Title: "T", Message: "M", Status: "unread", Priority: "normal",
+			EmailStatus: status, CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := db.CreateNotification(n); err != nil {
+			t.Fatalf("CreateNotification failed: %v", err)
+		}
+	}
+
+	queued, err := db.ListQueuedNotificationEmails()
+	if err != nil {
+		t.Fatalf("ListQueuedNotificationEmails failed: %v", err)
+	}
+	if len(queued) != 2 || queued[0].ID != "notif-eq-0" || queued[1].ID != "notif-eq-2" {
+		t.Fatalf("queued = %v, want notif-eq-0 and notif-eq-2 oldest first", queued)
+	}
+
+	if err := db.SetNotificationEmailStatus([]string{"notif-eq-0"}, "sent"); err != nil {
+		t.Fatalf("SetNotificationEmailStatus failed: %v", err)
+	}
+	queued, err = db.ListQueuedNotificationEmails()
+	if err != nil {
+		t.Fatalf("ListQueuedNotificationEmails failed: %v", err)
+	}
+	if len(queued) != 1 || queued[0].ID != "notif-eq-2" {
+		t.Errorf("queued after sending = %v, want notif-eq-2", queued)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 11. Notification Preferences
 // ---------------------------------------------------------------------------
@@ -2424,6 +2459,58 @@ func TestWorkflow_CRUD(t *testing.T) {
 	}
 }
 
+func TestWorkflow_ReplaceVersions(t *testing.T) {
+	db := newTestDB(t)
+
+	newWorkflow := func(name string) *workflow.Workflow {
+		wf, err := workflow.NewWorkflowFromDefinition(&workflow.WorkflowDefinition{
+			ID: "wf-v", Name: name, WorkflowType: "custom",
+			Nodes: []workflow.WorkflowNodeDefinition{{NodeKey: "a", NodeType: "task"}},
+			Edges: []workflow.WorkflowEdgeDefinition{
+				{ToNodeKey: "a", Condition: "success"},
+				{FromNodeKey: "a", Condition: "success"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewWorkflowFromDefinition: %v", err)
+		}
+		return wf
+	}
+
+	for i, name := range []string{"First", "Second"} {
+		wf := newWorkflow(name)
+		if err := db.ReplaceWorkflow(wf, "alice"); err != nil {
+			t.Fatalf("ReplaceWorkflow: %v", err)
+		}
+		if wf.Version != i+1 {
+			t.Errorf("Version = %d, want %d", wf.Version, i+1)
+		}
+	}
+
+	got, err := db.GetWorkflow("wf-v")
+	if err != nil {
+		t.Fatalf("GetWorkflow: %v", err)
+	}
+	if got.Version != 2 || got.Name != "Second" || len(got.Edges) != 2 {
+		t.Errorf("got version %d %q with %d edges, want version 2 Second with 2", got.Version, got.Name, len(got.Edges))
+	}
+
+	versions, err := db.ListWorkflowVersions("wf-v")
+	if err != nil {
+		t.Fatalf("ListWorkflowVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Definition.Name != "First" || versions[1].SavedBy != "alice" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	if _, err := workflow.NewWorkflowFromDefinition(&versions[1].Definition); err != nil {
+		t.Errorf("saved definition does not validate: %v", err)
+	}
+
+	if _, err := db.GetWorkflowVersion("wf-v", 3); err == nil {
+		t.Error("expected error for missing version")
+	}
+}
+
 func TestUpsertWorkflow_Nil(t *testing.T) {
 	db := newTestDB(t)
 	err := db.UpsertWorkflow(nil)
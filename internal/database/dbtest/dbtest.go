@@ -0,0 +1,20 @@
+// Package dbtest provides databases for tests.
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// New returns an in-memory SQLite database with the schema applied. It is
+// closed when the test ends.
+func New(t testing.TB) *database.Database {
+	t.Helper()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
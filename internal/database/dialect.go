@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sqlite "modernc.org/sqlite"
+)
+
+// Dialect identifies the SQL backend a Database talks to.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// sqliteDriverName is the database/sql driver that wraps modernc's SQLite
+// driver and translates the PostgreSQL dialect used throughout this package.
+const sqliteDriverName = "loom-sqlite"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{base: &sqlite.Driver{}})
+}
+
+// Queries in this package (and in packages that use DB() directly) are
+// written for PostgreSQL. Rather than fork every statement, the SQLite driver
+// rewrites the handful of constructs the two dialects disagree on.
+var sqliteRewrites = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// $1 → ?1: SQLite numbered parameters bind by index, like PostgreSQL's.
+	{regexp.MustCompile(`\$(\d+)`), `?$1`},
+	{regexp.MustCompile(`(?i)\bNOW\(\)`), `CURRENT_TIMESTAMP`},
+	{regexp.MustCompile(`(?i)\b(?:BIG)?SERIAL\s+PRIMARY\s+KEY`), `INTEGER PRIMARY KEY AUTOINCREMENT`},
+	{regexp.MustCompile(`(?i)\bJSONB\b`), `TEXT`},
+	{regexp.MustCompile(`(?i)\bBYTEA\b`), `BLOB`},
+	{regexp.MustCompile(`(?i)\bTIMESTAMPTZ\b`), `TIMESTAMP`},
+	{regexp.MustCompile(`(?i)\bTEXT\[\]`), `TEXT`},
+	{regexp.MustCompile(`(?i)\bADD\s+COLUMN\s+IF\s+NOT\s+EXISTS\b`), `ADD COLUMN`},
+}
+
+var truncateRe = regexp.MustCompile(`(?is)^\s*TRUNCATE\s+(?:TABLE\s+)?(.+?)(?:\s+(?:CASCADE|RESTRICT))?\s*;?\s*$`)
+
+// translateSQLite rewrites a PostgreSQL statement into its SQLite equivalent.
+func translateSQLite(query string) string {
+	if m := truncateRe.FindStringSubmatch(query); m != nil {
+		var stmts []string
+		for _, table := range strings.Split(m[1], ",") {
+			if table = strings.TrimSpace(table); table != "" {
+				stmts = append(stmts, "DELETE FROM "+table)
+			}
+		}
+		return strings.Join(stmts, "; ")
+	}
+	for _, rw := range sqliteRewrites {
+		query = rw.re.ReplaceAllString(query, rw.repl)
+	}
+	return query
+}
+
+// sqliteDriver wraps the SQLite driver so every statement passes through
+// translateSQLite before it reaches the engine.
+type sqliteDriver struct {
+	base driver.Driver
+}
+
+func (d *sqliteDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{Conn: c}, nil
+}
+
+type sqliteConn struct {
+	driver.Conn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(translateSQLite(query))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, translateSQLite(query))
+	}
+	return c.Prepare(query)
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, translateSQLite(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, translateSQLite(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *sqliteConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *sqliteConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column unless the table already has it.
+// PostgreSQL supports ADD COLUMN IF NOT EXISTS natively; SQLite does not,
+// so there the column list is checked first.
+func (d *Database) addColumnIfMissing(table, column, definition string) error {
+	if d.dialect != DialectSQLite {
+		_, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", table, column, definition))
+		return err
+	}
+
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if strings.EqualFold(name, column) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// tableExists reports whether the database has the table. Most tables are
+// created by migrations, but some belong to packages that create them when
+// they first start, e.g. the analytics request logs.
+func (d *Database) tableExists(table string) (bool, error) {
+	query := `SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1 AND table_schema = current_schema()`
+	if d.dialect == DialectSQLite {
+		query = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	}
+	var n int
+	if err := d.db.QueryRow(query, table).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// StoredEvent is an event bus event persisted in the event store
+type StoredEvent struct {
+	Seq       int64
+	ID        string
+	Type      string
+	Source    string
+	ProjectID string
+	DataJSON  string
+	CreatedAt time.Time
+}
+
+// StoredEventFilter selects stored events. Since and SinceTime are
+// exclusive lower bounds; Types matches any of the given types.
+type StoredEventFilter struct {
+	Since     int64
+	SinceTime time.Time
+	ProjectID string
+	Types     []string
+	Limit     int
+}
+
+// AppendEvent stores an event and sets its Seq
+func (d *Database) AppendEvent(e *StoredEvent) error {
+	query := `
+		INSERT INTO events (id, type, source, project_id, data_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING seq
+	`
+	err := d.db.QueryRow(rebind(query), e.ID, e.Type, e.Source, sqlNullString(e.ProjectID), sqlNullString(e.DataJSON), e.CreatedAt).Scan(&e.Seq)
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns matching events, oldest first
+func (d *Database) ListEvents(f StoredEventFilter) ([]*StoredEvent, error) {
+	query := `
+		SELECT seq, id, type, source, project_id, data_json, created_at
+		FROM events
+		WHERE seq > ?
+	`
+	args := []interface{}{f.Since}
+	if !f.SinceTime.IsZero() {
+		query += " AND created_at > ?"
+		args = append(args, f.SinceTime)
+	}
+	if f.ProjectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, f.ProjectID)
+	}
+	if len(f.Types) > 0 {
+		placeholders := ""
+		for i, t := range f.Types {
+			if i > 0 {
+				placeholders += ", "
+			}
+			placeholders += "?"
+			args = append(args, t)
+		}
+		query += fmt.Sprintf(" AND type IN (%s)", placeholders)
+	}
+	query += " ORDER BY seq"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*StoredEvent
+	for rows.Next() {
+		e := &StoredEvent{}
+		var projectID, dataJSON sql.NullString
+		if err := rows.Scan(&e.Seq, &e.ID, &e.Type, &e.Source, &projectID, &dataJSON, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.ProjectID = projectID.String
+		e.DataJSON = dataJSON.String
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// EventSeqRange returns the oldest and newest seq in the store, both zero
+// when it is empty
+func (d *Database) EventSeqRange() (oldest, newest int64, err error) {
+	var lo, hi sql.NullInt64
+	if err := d.db.QueryRow(`SELECT MIN(seq), MAX(seq) FROM events`).Scan(&lo, &hi); err != nil {
+		return 0, 0, fmt.Errorf("failed to get event range: %w", err)
+	}
+	return lo.Int64, hi.Int64, nil
+}
+
+// DeleteEventsBefore removes events created before the given time
+func (d *Database) DeleteEventsBefore(before time.Time) (int64, error) {
+	result, err := d.db.Exec(rebind(`DELETE FROM events WHERE created_at < ?`), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n, nil
+}
+
+// GetEventCursor returns the seq a consumer has processed up to, or zero
+// if it has never run
+func (d *Database) GetEventCursor(consumer string) (int64, error) {
+	var seq int64
+	err := d.db.QueryRow(rebind(`SELECT seq FROM event_cursors WHERE consumer = ?`), consumer).Scan(&seq)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event cursor: %w", err)
+	}
+	return seq, nil
+}
+
+// SetEventCursor records the seq a consumer has processed up to
+func (d *Database) SetEventCursor(consumer string, seq int64) error {
+	query := `
+		INSERT INTO event_cursors (consumer, seq, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(consumer) DO UPDATE SET
+			seq = excluded.seq,
+			updated_at = excluded.updated_at
+	`
+	if _, err := d.db.Exec(rebind(query), consumer, seq, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to set event cursor: %w", err)
+	}
+	return nil
+}
@@ -34,15 +34,7 @@ func (d *Database) migrateLessons() error {
 	}
 
 	// Add embedding column if it doesn't exist (migration)
-	query := `ALTER TABLE lessons ADD COLUMN embedding BYTEA`
-	_, err := d.db.Exec(query)
-	if err != nil {
-		// Column already exists — ignore the error
-		if !isAlterColumnExistsError(err) {
-			return err
-		}
-	}
-	return nil
+	return d.addColumnIfMissing("lessons", "embedding", "BYTEA")
 }
 
 // isAlterColumnExistsError checks if an ALTER TABLE error is "column already exists".
@@ -125,6 +117,38 @@ func (d *Database) GetLessonsForProject(projectID string, limit int, maxChars in
 	return lessons, rows.Err()
 }
 
+// ListLessonsSince returns lessons of all projects created after since,
+// oldest first, up to limit.
+func (d *Database) ListLessonsSince(since time.Time, limit int) ([]*models.Lesson, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	rows, err := d.db.Query(rebind(`
+		SELECT id, project_id, category, title, detail,
+			COALESCE(source_bead_id, ''), COALESCE(source_agent_id, ''), relevance_score, created_at
+		FROM lessons
+		WHERE created_at > ?
+		ORDER BY created_at ASC
+		LIMIT ?`),
+		since.Round(0), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lessons []*models.Lesson
+	for rows.Next() {
+		l := &models.Lesson{}
+		if err := rows.Scan(&l.ID, &l.ProjectID, &l.Category, &l.Title, &l.Detail,
+			&l.SourceBeadID, &l.SourceAgentID, &l.RelevanceScore, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, rows.Err()
+}
+
 // StoreLessonWithEmbedding inserts a lesson along with its vector embedding.
 func (d *Database) StoreLessonWithEmbedding(lesson *models.Lesson, embedding []float32) error {
 	if lesson == nil {
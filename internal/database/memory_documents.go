@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/memory"
+)
+
+// maxMemoryCandidates bounds the documents scored in process when the
+// database can't search by similarity itself.
+const maxMemoryCandidates = 5000
+
+// UpsertDocument stores a memory document and its embedding, replacing any
+// document with the same ID.
+func (d *Database) UpsertDocument(ctx context.Context, doc *memory.Document, embedding []float32) error {
+	if doc.UpdatedAt.IsZero() {
+		doc.UpdatedAt = time.Now().UTC()
+	}
+	args := []interface{}{
+		doc.ID, doc.ProjectID, string(doc.Kind), doc.SourceID, doc.Title, doc.Content,
+		memory.EncodeEmbedding(embedding), doc.UpdatedAt.Round(0),
+	}
+	if d.pgvector {
+		var vec interface{}
+		if len(embedding) > 0 {
+			vec = vectorLiteral(embedding)
+		}
+		_, err := d.db.ExecContext(ctx, `
+			INSERT INTO memory_documents (id, project_id, kind, source_id, title, content, embedding, updated_at, embedding_vec)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::vector)
+			ON CONFLICT (id) DO UPDATE SET
+				project_id = EXCLUDED.project_id, kind = EXCLUDED.kind, source_id = EXCLUDED.source_id,
+				title = EXCLUDED.title, content = EXCLUDED.content, embedding = EXCLUDED.embedding,
+				updated_at = EXCLUDED.updated_at, embedding_vec = EXCLUDED.embedding_vec`,
+			append(args, vec)...)
+		return err
+	}
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO memory_documents (id, project_id, kind, source_id, title, content, embedding, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			project_id = EXCLUDED.project_id, kind = EXCLUDED.kind, source_id = EXCLUDED.source_id,
+			title = EXCLUDED.title, content = EXCLUDED.content, embedding = EXCLUDED.embedding,
+			updated_at = EXCLUDED.updated_at`,
+		args...)
+	return err
+}
+
+// DeleteDocuments removes a project's memory documents of a kind that came
+// from sourceID.
+func (d *Database) DeleteDocuments(ctx context.Context, projectID string, kind memory.DocumentKind, sourceID string) error {
+	_, err := d.db.ExecContext(ctx, rebind(`
+		DELETE FROM memory_documents WHERE project_id = ? AND kind = ? AND source_id = ?`),
+		projectID, string(kind), sourceID)
+	return err
+}
+
+// SearchDocuments returns up to topK of a project's memory documents ranked
+// by cosine similarity to embedding. With pgvector the ranking is done by
+// the database; otherwise the project's most recent documents are scored
+// in process.
+func (d *Database) SearchDocuments(ctx context.Context, projectID string, embedding []float32, topK int) ([]*memory.Document, error) {
+	if topK <= 0 {
+		topK = memory.DefaultTopK
+	}
+	if d.pgvector {
+		return d.searchDocumentsPgvector(ctx, projectID, embedding, topK)
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, project_id, kind, source_id, title, content, updated_at, embedding
+		FROM memory_documents
+		WHERE project_id = $1
+		ORDER BY updated_at DESC
+		LIMIT $2`,
+		projectID, maxMemoryCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []*memory.Document
+	for rows.Next() {
+		var embBytes []byte
+		doc, err := scanMemoryDocument(rows, &embBytes)
+		if err != nil {
+			return nil, err
+		}
+		// Documents embedded by a different model have a different
+		// dimension and can't be compared.
+		stored := memory.DecodeEmbedding(embBytes)
+		if len(stored) != len(embedding) {
+			continue
+		}
+		doc.Score = memory.CosineSimilarity(embedding, stored)
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].Score > docs[j].Score })
+	if len(docs) > topK {
+		docs = docs[:topK]
+	}
+	return docs, nil
+}
+
+func (d *Database) searchDocumentsPgvector(ctx context.Context, projectID string, embedding []float32, topK int) ([]*memory.Document, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, project_id, kind, source_id, title, content, updated_at,
+			1 - (embedding_vec <=> $2::vector) AS score
+		FROM memory_documents
+		WHERE project_id = $1 AND embedding_vec IS NOT NULL AND vector_dims(embedding_vec) = $3
+		ORDER BY embedding_vec <=> $2::vector
+		LIMIT $4`,
+		projectID, vectorLiteral(embedding), len(embedding), topK)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []*memory.Document
+	for rows.Next() {
+		var score float64
+		doc, err := scanMemoryDocument(rows, &score)
+		if err != nil {
+			return nil, err
+		}
+		doc.Score = float32(score)
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// scanMemoryDocument scans a memory_documents row followed by one extra
+// column into extra.
+func scanMemoryDocument(rows *sql.Rows, extra interface{}) (*memory.Document, error) {
+	doc := &memory.Document{}
+	var kind string
+	err := rows.Scan(&doc.ID, &doc.ProjectID, &kind, &doc.SourceID, &doc.Title, &doc.Content, &doc.UpdatedAt, extra)
+	if err != nil {
+		return nil, err
+	}
+	doc.Kind = memory.DocumentKind(kind)
+	return doc, nil
+}
+
+// vectorLiteral formats an embedding in pgvector's text form, [x,y,...].
+func vectorLiteral(vec []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, v := range vec {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
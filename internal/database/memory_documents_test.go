@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/memory"
+)
+
+func TestMemoryDocuments_RetrieveSQLite(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	r := memory.NewRetriever(db, memory.NewHashEmbedder())
+
+	err = r.Index(ctx,
+		&memory.Document{ID: "bead:1", ProjectID: "p1", Kind: memory.DocKindBead, Title: "Fix flaky login test",
+			Content: "The login test races the session cache; run it with -count=1 and wait for the cache warmup."},
+		&memory.Document{ID: "lesson:1", ProjectID: "p1", Kind: memory.DocKindLesson, Title: "convention: commit messages",
+			Content: "Commit subjects are imperative and prefixed with the package name."},
+		&memory.Document{ID: "bead:2", ProjectID: "p2", Kind: memory.DocKindBead, Title: "Fix flaky login test",
+			Content: "Another project's login test."},
+	)
+	if err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if err := r.IndexFile(ctx, "p1", "README.md", "# Building\n\nRun make build.\n\n# Testing\n\nRun make test."); err != nil {
+		t.Fatalf("IndexFile: %v", err)
+	}
+
+	docs, err := r.Retrieve(ctx, "p1", "the login test is flaky again, session cache", 2)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(docs) != 2 || docs[0].ID != "bead:1" {
+		t.Fatalf("Retrieve = %+v, want bead:1 first of 2", docs)
+	}
+	for _, d := range docs {
+		if d.ProjectID != "p1" {
+			t.Errorf("retrieved %s from project %s", d.ID, d.ProjectID)
+		}
+	}
+
+	// Reindexing a file replaces its excerpts.
+	if err := r.IndexFile(ctx, "p1", "README.md", ""); err != nil {
+		t.Fatalf("IndexFile: %v", err)
+	}
+	docs, err = r.Retrieve(ctx, "p1", "make build", 10)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	for _, d := range docs {
+		if d.Kind == memory.DocKindFile {
+			t.Errorf("stale file excerpt %s still retrieved", d.ID)
+		}
+	}
+	if len(docs) != 2 {
+		t.Errorf("Retrieve = %d docs, want the 2 remaining in p1", len(docs))
+	}
+}
@@ -0,0 +1,429 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Migration is one versioned change to the schema.
+//
+// The baseline migrations are the schema loom had before migrations were
+// versioned: idempotent Go code that runs on every startup, as it always
+// has, and can't be rolled back. Every schema change after them is a
+// versioned migration in versionedMigrations, with the SQL that applies it
+// and the SQL that rolls it back, so operators can review both before
+// running them.
+type Migration struct {
+	Version int
+	Name    string
+	// Up and Down are the statements that apply and roll back the
+	// migration, written for PostgreSQL like the rest of the schema. A
+	// migration without Down can't be rolled back.
+	Up   []string
+	Down []string
+	// apply runs a baseline migration.
+	apply func() error
+}
+
+// Baseline reports whether m is a baseline migration.
+func (m Migration) Baseline() bool {
+	return m.apply != nil
+}
+
+// Reversible reports whether m can be rolled back.
+func (m Migration) Reversible() bool {
+	return !m.Baseline() && len(m.Down) > 0
+}
+
+// versionedMigrations are the schema changes made since the baseline, in
+// order. Number a new one after the last, give it Up and Down statements,
+// and never change one that has shipped.
+var versionedMigrations = []Migration{
+	{
+		Version: 41,
+		Name:    "api tokens",
+		Up: []string{`
+			CREATE TABLE IF NOT EXISTS api_tokens (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				user_id TEXT NOT NULL,
+				token_hash TEXT NOT NULL UNIQUE,
+				prefix TEXT NOT NULL,
+				scope TEXT NOT NULL,
+				project_ids TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP,
+				last_used_at TIMESTAMP,
+				revoked_at TIMESTAMP,
+				revoked_by TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens(user_id)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_api_tokens_user`,
+			`DROP TABLE IF EXISTS api_tokens`,
+		},
+	},
+	{
+		Version: 42,
+		Name:    "user management",
+		Up: []string{
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT`,
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT false`,
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP`,
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMP`,
+		},
+		Down: []string{
+			`ALTER TABLE users DROP COLUMN sessions_revoked_at`,
+			`ALTER TABLE users DROP COLUMN last_login_at`,
+			`ALTER TABLE users DROP COLUMN password_reset_required`,
+			`ALTER TABLE users DROP COLUMN password_hash`,
+		},
+	},
+	{
+		Version: 43,
+		Name:    "external identities",
+		Up: []string{
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS external_id TEXT`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users(external_id)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_users_external_id`,
+			`ALTER TABLE users DROP COLUMN external_id`,
+		},
+	},
+	{
+		Version: 44,
+		Name:    "revoked sessions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS revoked_sessions (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				expires_at TIMESTAMP NOT NULL,
+				revoked_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_revoked_sessions_expires ON revoked_sessions(expires_at)`,
+		},
+		Down: []string{
+			`DROP INDEX IF EXISTS idx_revoked_sessions_expires`,
+			`DROP TABLE IF EXISTS revoked_sessions`,
+		},
+	},
+}
+
+// MigrationStatus is a migration and whether it has been applied.
+type MigrationStatus struct {
+	Version    int        `json:"version"`
+	Name       string     `json:"name"`
+	Baseline   bool       `json:"baseline"`
+	Reversible bool       `json:"reversible"`
+	Applied    bool       `json:"applied"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+}
+
+// MigrationStep is one migration a plan runs, in the direction it runs.
+// Statements is empty for baseline migrations, which aren't SQL.
+type MigrationStep struct {
+	Version    int      `json:"version"`
+	Name       string   `json:"name"`
+	Direction  string   `json:"direction"` // "up" or "down"
+	Statements []string `json:"statements,omitempty"`
+}
+
+// Option configures a Database when it is opened.
+type Option func(*Database)
+
+// ManualMigrations leaves versioned migrations for an operator to apply
+// with MigrateUp; opening the database only runs the baseline.
+func ManualMigrations() Option {
+	return func(d *Database) {
+		d.manualMigrations = true
+	}
+}
+
+// baseline lists the baseline migrations in the order they run. It is
+// closed: new schema changes go in versionedMigrations.
+func (d *Database) baseline() []Migration {
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		// The schema is written in PostgreSQL syntax; the SQLite driver
+		// translates the few types that differ (SERIAL, JSONB, TEXT[]).
+		{"base schema", d.initSchemaPostgres},
+		{"provider ownership", d.migrateProviderOwnership},
+		{"provider routing", d.migrateProviderRouting},
+		{"provider scoring", d.migrateProviderScoring},
+		{"motivations", d.migrateMotivations},
+		{"workflows", d.migrateWorkflows},
+		{"activity", d.migrateActivity},
+		{"comments", d.migrateComments},
+		{"conversations", d.migrateConversations},
+		{"patterns", func() error { return migratePatterns(d.db) }},
+		{"credentials", d.migrateCredentials},
+		{"lessons", d.migrateLessons},
+		{"request logs", d.migrateRequestLogs},
+		{"provider api key", d.migrateProviderAPIKey},
+		{"project memory", d.migrateProjectMemory},
+		{"webhooks", d.migrateWebhooks},
+		{"bead schedules", d.migrateBeadSchedules},
+		{"provider policies", d.migrateProviderPolicies},
+		{"budgets", d.migrateBudgets},
+		{"attachments", d.migrateAttachments},
+		{"sla", d.migrateSLA},
+		{"boards", d.migrateBoards},
+		{"agent capabilities", d.migrateAgentCapabilities},
+		{"agent hold", d.migrateAgentHold},
+		{"command policies", d.migrateCommandPolicies},
+		{"audit log", d.migrateAuditLog},
+		{"organizations", d.migrateOrganizations},
+		{"bead history", d.migrateBeadHistory},
+		{"workflow versions", d.migrateWorkflowVersions},
+		{"workflow branches", d.migrateWorkflowBranches},
+		{"project archives", d.migrateProjectArchives},
+		{"project config", d.migrateProjectConfig},
+		{"remote agents", d.migrateRemoteAgents},
+		{"events", d.migrateEvents},
+		{"memory documents", d.migrateMemoryDocuments},
+		{"org chart layouts", d.migrateOrgChartLayouts},
+		{"notification email", d.migrateNotificationEmail},
+		{"reports", d.migrateReports},
+		{"milestones", d.migrateMilestones},
+		{"bead types", d.migrateBeadTypes},
+	}
+	migrations := make([]Migration, len(steps))
+	for i, step := range steps {
+		migrations[i] = Migration{Version: i + 1, Name: step.name, apply: step.fn}
+	}
+	return migrations
+}
+
+// Migrations lists every migration, baseline first, in version order.
+func (d *Database) Migrations() []Migration {
+	return append(d.baseline(), versionedMigrations...)
+}
+
+// migrate brings the schema up to date when the database is opened. The
+// baseline always runs; versioned migrations wait for MigrateUp when the
+// database was opened with ManualMigrations.
+func (d *Database) migrate() error {
+	if err := d.ensureMigrationsTable(); err != nil {
+		return err
+	}
+	baseline := d.baseline()
+	for _, m := range baseline {
+		if err := m.apply(); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", m.Name, err)
+		}
+		if err := d.recordMigration(d.db, m); err != nil {
+			return err
+		}
+	}
+	if d.manualMigrations {
+		return nil
+	}
+	_, err := d.MigrateUp(0)
+	return err
+}
+
+func (d *Database) ensureMigrationsTable() error {
+	_, err := d.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// execer is what running a migration needs from a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (d *Database) recordMigration(db execer, m Migration) error {
+	query := `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?) ON CONFLICT(version) DO NOTHING`
+	if _, err := db.Exec(rebind(query), m.Version, m.Name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// appliedMigrations maps the version of each applied migration to when
+// it was applied.
+func (d *Database) appliedMigrations() (map[int]time.Time, error) {
+	rows, err := d.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// MigrationStatus lists every migration and whether it has been applied.
+func (d *Database) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var status []MigrationStatus
+	for _, m := range d.Migrations() {
+		s := MigrationStatus{Version: m.Version, Name: m.Name, Baseline: m.Baseline(), Reversible: m.Reversible()}
+		if at, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &at
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+// PendingMigrations lists the versioned migrations not yet applied.
+func (d *Database) PendingMigrations() ([]Migration, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range versionedMigrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// SchemaVersion is the version of the newest applied migration.
+func (d *Database) SchemaVersion() (int, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// LatestVersion is the version of the last migration.
+func (d *Database) LatestVersion() int {
+	all := d.Migrations()
+	return all[len(all)-1].Version
+}
+
+// PlanMigrateUp lists the migrations MigrateUp(to) would apply.
+func (d *Database) PlanMigrateUp(to int) ([]MigrationStep, error) {
+	if to == 0 {
+		to = d.LatestVersion()
+	}
+	if to < 0 || to > d.LatestVersion() {
+		return nil, fmt.Errorf("no migration %d: the latest is %d", to, d.LatestVersion())
+	}
+	pending, err := d.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+	steps := []MigrationStep{}
+	for _, m := range pending {
+		if m.Version <= to {
+			steps = append(steps, MigrationStep{Version: m.Version, Name: m.Name, Direction: "up", Statements: m.Up})
+		}
+	}
+	return steps, nil
+}
+
+// PlanMigrateDown lists the migrations MigrateDown(to) would roll back,
+// newest first. It fails if any of them can't be rolled back.
+func (d *Database) PlanMigrateDown(to int) ([]MigrationStep, error) {
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	all := d.Migrations()
+	sort.Slice(all, func(i, j int) bool { return all[i].Version > all[j].Version })
+	steps := []MigrationStep{}
+	for _, m := range all {
+		if m.Version <= to {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if !m.Reversible() {
+			return nil, fmt.Errorf("migration %d (%s) can't be rolled back", m.Version, m.Name)
+		}
+		steps = append(steps, MigrationStep{Version: m.Version, Name: m.Name, Direction: "down", Statements: m.Down})
+	}
+	return steps, nil
+}
+
+// MigrateUp applies the pending versioned migrations up to and including
+// version to, or all of them when to is 0, and returns what it applied.
+// Each migration runs in its own transaction.
+func (d *Database) MigrateUp(to int) ([]MigrationStep, error) {
+	steps, err := d.PlanMigrateUp(to)
+	if err != nil {
+		return nil, err
+	}
+	return d.runSteps(steps)
+}
+
+// MigrateDown rolls back the applied migrations after version to, newest
+// first, and returns what it rolled back.
+func (d *Database) MigrateDown(to int) ([]MigrationStep, error) {
+	steps, err := d.PlanMigrateDown(to)
+	if err != nil {
+		return nil, err
+	}
+	return d.runSteps(steps)
+}
+
+func (d *Database) runSteps(steps []MigrationStep) ([]MigrationStep, error) {
+	done := []MigrationStep{}
+	for _, step := range steps {
+		if err := d.runStep(step); err != nil {
+			return done, fmt.Errorf("migration %d (%s) %s failed: %w", step.Version, step.Name, step.Direction, err)
+		}
+		log.Printf("[Database] Migrated %s: %d %s", step.Direction, step.Version, step.Name)
+		done = append(done, step)
+	}
+	return done, nil
+}
+
+func (d *Database) runStep(step MigrationStep) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, stmt := range step.Statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if step.Direction == "up" {
+		err = d.recordMigration(tx, Migration{Version: step.Version, Name: step.Name})
+	} else {
+		_, err = tx.Exec(rebind(`DELETE FROM schema_migrations WHERE version = ?`), step.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
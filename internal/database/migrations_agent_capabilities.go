@@ -0,0 +1,12 @@
+package database
+
+import "fmt"
+
+// migrateAgentCapabilities adds a capabilities column to the agents table,
+// holding the agent's skill tags as a JSON array.
+func (d *Database) migrateAgentCapabilities() error {
+	if err := d.addColumnIfMissing("agents", "capabilities", "TEXT"); err != nil {
+		return fmt.Errorf("migrateAgentCapabilities: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package database
+
+import "fmt"
+
+// migrateAgentHold adds a hold column to the agents table so operator
+// pauses and drains survive restarts.
+func (d *Database) migrateAgentHold() error {
+	if err := d.addColumnIfMissing("agents", "hold", "TEXT"); err != nil {
+		return fmt.Errorf("migrateAgentHold: %w", err)
+	}
+	return nil
+}
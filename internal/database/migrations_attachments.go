@@ -0,0 +1,34 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateAttachments creates the bead attachment metadata table. File
+// contents live in the configured attachment store, not in this table.
+func (d *Database) migrateAttachments() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS bead_attachments (
+		id TEXT PRIMARY KEY,
+		bead_id TEXT NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size_bytes BIGINT NOT NULL,
+		sha256 TEXT NOT NULL,
+		storage TEXT NOT NULL,
+		storage_ref TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT 'user',
+		uploaded_by TEXT,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bead_attachments_bead ON bead_attachments(bead_id, created_at);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Bead attachment table migrated successfully")
+	return nil
+}
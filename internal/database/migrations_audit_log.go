@@ -0,0 +1,39 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateAuditLog creates the API audit log table. Rows are only ever
+// inserted, and removed by retention.
+func (d *Database) migrateAuditLog() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		actor_id TEXT NOT NULL DEFAULT '',
+		actor_name TEXT,
+		actor_role TEXT,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		resource_type TEXT NOT NULL DEFAULT '',
+		resource_id TEXT NOT NULL DEFAULT '',
+		status_code INTEGER NOT NULL,
+		remote_addr TEXT,
+		before_json TEXT,
+		after_json TEXT,
+		diff_json TEXT,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Audit log table migrated successfully")
+	return nil
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateBeadHistory creates the per-bead change log. Rows are only ever
+// inserted.
+func (d *Database) migrateBeadHistory() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS bead_history (
+		id TEXT PRIMARY KEY,
+		bead_id TEXT NOT NULL,
+		project_id TEXT NOT NULL DEFAULT '',
+		field TEXT NOT NULL,
+		old_value TEXT,
+		new_value TEXT,
+		actor TEXT NOT NULL,
+		changed_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bead_history_bead ON bead_history(bead_id, changed_at);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Bead history table migrated successfully")
+	return nil
+}
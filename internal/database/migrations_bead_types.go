@@ -0,0 +1,30 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateBeadTypes creates the table of admin-defined bead types
+func (d *Database) migrateBeadTypes() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS bead_types (
+		name TEXT PRIMARY KEY,
+		description TEXT,
+		default_priority INTEGER,
+		required_context TEXT NOT NULL DEFAULT '[]',
+		default_workflow TEXT,
+		icon TEXT,
+		color TEXT,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Bead types table migrated successfully")
+	return nil
+}
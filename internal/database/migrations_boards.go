@@ -0,0 +1,24 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateBoards creates the per-project board configuration table
+func (d *Database) migrateBoards() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS project_boards (
+		project_id TEXT PRIMARY KEY,
+		columns TEXT NOT NULL DEFAULT '[]',
+		updated_by TEXT,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Board tables migrated successfully")
+	return nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateBudgets creates the table of token and cost budgets
+func (d *Database) migrateBudgets() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS budgets (
+		id TEXT PRIMARY KEY,
+		scope TEXT NOT NULL,
+		scope_id TEXT NOT NULL,
+		period TEXT NOT NULL,
+		token_limit BIGINT NOT NULL DEFAULT 0,
+		cost_limit_usd REAL NOT NULL DEFAULT 0,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (scope, scope_id, period)
+	);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Budget table migrated successfully")
+	return nil
+}
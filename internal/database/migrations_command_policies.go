@@ -0,0 +1,52 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateCommandPolicies creates the command policy rule and violation tables
+func (d *Database) migrateCommandPolicies() error {
+	rulesSchema := `
+	CREATE TABLE IF NOT EXISTS command_policy_rules (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL DEFAULT '',
+		effect TEXT NOT NULL,
+		syntax TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		description TEXT,
+		create_decision BOOLEAN NOT NULL DEFAULT false,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_command_policy_rules_project ON command_policy_rules(project_id);
+	`
+
+	if _, err := d.db.Exec(rulesSchema); err != nil {
+		return err
+	}
+
+	violationsSchema := `
+	CREATE TABLE IF NOT EXISTS command_policy_violations (
+		id TEXT PRIMARY KEY,
+		rule_id TEXT NOT NULL DEFAULT '',
+		project_id TEXT NOT NULL DEFAULT '',
+		agent_id TEXT,
+		bead_id TEXT,
+		command TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		decision_id TEXT,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_command_policy_violations_created ON command_policy_violations(created_at);
+	`
+
+	if _, err := d.db.Exec(violationsSchema); err != nil {
+		return err
+	}
+
+	log.Println("Command policy tables migrated successfully")
+	return nil
+}
@@ -33,6 +33,10 @@ func (d *Database) migrateConversations() error {
 	if _, err := d.db.Exec(conversationSchema); err != nil {
 		return err
 	}
+	// loop_state is where an unfinished action loop is checkpointed.
+	if err := d.addColumnIfMissing("conversation_contexts", "loop_state", "TEXT"); err != nil {
+		return err
+	}
 
 	log.Println("Conversation contexts table migrated successfully")
 	return nil
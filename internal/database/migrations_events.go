@@ -0,0 +1,37 @@
+package database
+
+import "log"
+
+// migrateEvents creates the durable event store and the cursors of its
+// consumers. seq is the store's own sequence, independent of the in-memory
+// event bus's, so it survives restarts and is what replay clients resume
+// from.
+func (d *Database) migrateEvents() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS events (
+		seq BIGSERIAL PRIMARY KEY,
+		id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		project_id TEXT,
+		data_json TEXT,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_events_created ON events(created_at);
+	CREATE INDEX IF NOT EXISTS idx_events_project ON events(project_id, seq);
+	CREATE INDEX IF NOT EXISTS idx_events_type ON events(type, seq);
+
+	CREATE TABLE IF NOT EXISTS event_cursors (
+		consumer TEXT PRIMARY KEY,
+		seq BIGINT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Event store tables migrated successfully")
+	return nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import "log"
+
+// migrateMemoryDocuments creates the table agent memory is retrieved from:
+// embedded closed-bead summaries, lessons and project file excerpts. On
+// PostgreSQL with the pgvector extension the embeddings are also kept in a
+// vector column so similarity search runs in the database.
+func (d *Database) migrateMemoryDocuments() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS memory_documents (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		source_id TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		content TEXT NOT NULL,
+		embedding BYTEA,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_memory_documents_project ON memory_documents(project_id);
+	CREATE INDEX IF NOT EXISTS idx_memory_documents_source ON memory_documents(project_id, kind, source_id);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	if d.dialect == DialectPostgres {
+		if _, err := d.db.Exec(`CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+			log.Printf("pgvector is not available, memory search runs in process: %v", err)
+		} else if err := d.addColumnIfMissing("memory_documents", "embedding_vec", "vector"); err != nil {
+			log.Printf("Failed to add memory_documents.embedding_vec, memory search runs in process: %v", err)
+		} else {
+			d.pgvector = true
+		}
+	}
+
+	log.Println("Memory documents table migrated successfully")
+	return nil
+}
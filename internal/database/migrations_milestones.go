@@ -0,0 +1,19 @@
+package database
+
+import "fmt"
+
+// migrateMilestones extends the milestones table so milestones can group
+// beads across several projects and hold off closing until their beads
+// are closed.
+func (d *Database) migrateMilestones() error {
+	for _, col := range []struct{ name, definition string }{
+		{"project_ids_json", "TEXT"},
+		{"close_requires_beads", "BOOLEAN NOT NULL DEFAULT false"},
+		{"created_by", "TEXT"},
+	} {
+		if err := d.addColumnIfMissing("milestones", col.name, col.definition); err != nil {
+			return fmt.Errorf("migrateMilestones: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package database
+
+import "fmt"
+
+// migrateNotificationEmail adds the columns the email notification channel
+// needs: which event types a user has emailed, and whether a notification
+// is waiting to be emailed.
+func (d *Database) migrateNotificationEmail() error {
+	if err := d.addColumnIfMissing("notification_preferences", "email_events_json", "TEXT"); err != nil {
+		return fmt.Errorf("migrateNotificationEmail: %w", err)
+	}
+	if err := d.addColumnIfMissing("notifications", "email_status", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("migrateNotificationEmail: %w", err)
+	}
+	return nil
+}
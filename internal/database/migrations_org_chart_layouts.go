@@ -0,0 +1,24 @@
+package database
+
+import "log"
+
+// migrateOrgChartLayouts creates the table customized project org charts
+// are kept in, so positions added, removed or changed through the API
+// survive a restart.
+func (d *Database) migrateOrgChartLayouts() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS org_chart_layouts (
+		project_id TEXT PRIMARY KEY,
+		positions JSONB NOT NULL,
+		removed_roles JSONB NOT NULL,
+		updated_by TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Org chart layouts table migrated successfully")
+	return nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"log"
+	"time"
+)
+
+// migrateOrganizations creates the organization, team, and membership
+// tables, adds the owning organization to projects, providers, agents,
+// and budgets, and creates the default organization that existing rows
+// belong to.
+func (d *Database) migrateOrganizations() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS organizations (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS teams (
+		id TEXT PRIMARY KEY,
+		org_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE (org_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS org_members (
+		org_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		team_id TEXT NOT NULL DEFAULT '',
+		role TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (org_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_org_members_user ON org_members(user_id);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"projects", "providers", "agents", "budgets"} {
+		if err := d.addColumnIfMissing(table, "org_id", "TEXT NOT NULL DEFAULT '"+DefaultOrgID+"'"); err != nil {
+			return err
+		}
+	}
+	if err := d.addColumnIfMissing("projects", "team_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO organizations (id, name, created_at) VALUES (?, ?, ?) ON CONFLICT(id) DO NOTHING`
+	if _, err := d.db.Exec(rebind(query), DefaultOrgID, "Default", time.Now().UTC()); err != nil {
+		return err
+	}
+
+	log.Println("Organization tables migrated successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import "log"
+
+// migrateProjectArchives creates the cold storage table for archived
+// projects: one compressed snapshot of each project's beads and
+// conversation history.
+func (d *Database) migrateProjectArchives() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS project_archives (
+		project_id TEXT PRIMARY KEY,
+		prior_status TEXT NOT NULL,
+		archived_by TEXT NOT NULL DEFAULT '',
+		archived_at TIMESTAMP NOT NULL,
+		bead_count INTEGER NOT NULL DEFAULT 0,
+		conversation_count INTEGER NOT NULL DEFAULT 0,
+		data BYTEA NOT NULL
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Project archives table migrated successfully")
+	return nil
+}
@@ -0,0 +1,24 @@
+package database
+
+import "log"
+
+// migrateProjectConfig creates the table of per-project overrides of global
+// settings, one row per project and key.
+func (d *Database) migrateProjectConfig() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS project_config (
+		project_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		updated_by TEXT,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (project_id, key)
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Project config table migrated successfully")
+	return nil
+}
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateProviderPolicies creates the provider routing policy table. The
+// default policy is stored under the empty project ID.
+func (d *Database) migrateProviderPolicies() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS provider_policies (
+		project_id TEXT PRIMARY KEY,
+		policy_json TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Provider policy table migrated successfully")
+	return nil
+}
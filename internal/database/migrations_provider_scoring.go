@@ -3,16 +3,16 @@ package database
 import "fmt"
 
 // Migration to add dynamic scoring columns to providers table.
-// Uses ADD COLUMN IF NOT EXISTS so it is safe to run on databases that already
+// Uses addColumnIfMissing so it is safe to run on databases that already
 // have these columns from a previous deployment.
 func (d *Database) migrateProviderScoring() error {
-	alterations := []string{
-		`ALTER TABLE providers ADD COLUMN IF NOT EXISTS model_params_b REAL`,
-		`ALTER TABLE providers ADD COLUMN IF NOT EXISTS capability_score REAL`,
-		`ALTER TABLE providers ADD COLUMN IF NOT EXISTS avg_latency_ms INTEGER`,
+	columns := []struct{ name, def string }{
+		{"model_params_b", "REAL"},
+		{"capability_score", "REAL"},
+		{"avg_latency_ms", "INTEGER"},
 	}
-	for _, sql := range alterations {
-		if _, err := d.db.Exec(sql); err != nil {
+	for _, col := range columns {
+		if err := d.addColumnIfMissing("providers", col.name, col.def); err != nil {
 			return fmt.Errorf("migrateProviderScoring: %w", err)
 		}
 	}
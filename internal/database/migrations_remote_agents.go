@@ -0,0 +1,41 @@
+package database
+
+import "log"
+
+// migrateRemoteAgents creates the tables of remote agent enrollment tokens
+// and of the remote agents enrolled with them. Tokens and credentials are
+// stored as SHA-256 hashes; project IDs, roles and capabilities as JSON
+// arrays.
+func (d *Database) migrateRemoteAgents() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS remote_agent_tokens (
+		id TEXT PRIMARY KEY,
+		token_hash TEXT NOT NULL UNIQUE,
+		project_ids TEXT NOT NULL,
+		roles TEXT,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		used_at TIMESTAMP,
+		used_by TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS remote_agents (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		project_ids TEXT NOT NULL,
+		roles TEXT,
+		capabilities TEXT,
+		credential_hash TEXT NOT NULL,
+		enrolled_at TIMESTAMP NOT NULL,
+		last_seen_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Remote agents tables migrated successfully")
+	return nil
+}
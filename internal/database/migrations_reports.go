@@ -0,0 +1,26 @@
+package database
+
+import "log"
+
+// migrateReports creates the table generated project reports are kept in.
+func (d *Database) migrateReports() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS reports (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		period TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		data_json TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_reports_project ON reports(project_id, period_end DESC);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Reports table migrated successfully")
+	return nil
+}
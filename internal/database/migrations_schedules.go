@@ -0,0 +1,37 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateBeadSchedules creates the table of recurring bead definitions
+func (d *Database) migrateBeadSchedules() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS bead_schedules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		cron_expr TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		priority INTEGER NOT NULL DEFAULT 2,
+		bead_type TEXT NOT NULL DEFAULT 'task',
+		enabled BOOLEAN NOT NULL DEFAULT true,
+		next_fire_at TIMESTAMP,
+		last_fired_at TIMESTAMP,
+		last_bead_id TEXT,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_bead_schedules_next_fire ON bead_schedules(enabled, next_fire_at);
+	`
+
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Bead schedule table migrated successfully")
+	return nil
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateSLA creates the SLA policy and breach tables
+func (d *Database) migrateSLA() error {
+	policiesSchema := `
+	CREATE TABLE IF NOT EXISTS sla_policies (
+		id TEXT PRIMARY KEY,
+		project_id TEXT NOT NULL DEFAULT '',
+		priority INTEGER NOT NULL,
+		respond_seconds BIGINT NOT NULL DEFAULT 0,
+		resolve_seconds BIGINT NOT NULL DEFAULT 0,
+		auto_escalate BOOLEAN NOT NULL DEFAULT false,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		UNIQUE (project_id, priority)
+	);
+	`
+
+	if _, err := d.db.Exec(policiesSchema); err != nil {
+		return err
+	}
+
+	breachesSchema := `
+	CREATE TABLE IF NOT EXISTS sla_breaches (
+		bead_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		policy_id TEXT NOT NULL,
+		project_id TEXT NOT NULL DEFAULT '',
+		due_at TIMESTAMP NOT NULL,
+		breached_at TIMESTAMP NOT NULL,
+		escalation_id TEXT,
+		PRIMARY KEY (bead_id, kind)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_sla_breaches_breached_at ON sla_breaches(breached_at);
+	`
+
+	if _, err := d.db.Exec(breachesSchema); err != nil {
+		return err
+	}
+
+	log.Println("SLA tables migrated successfully")
+	return nil
+}
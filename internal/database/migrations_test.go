@@ -0,0 +1,122 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+// withMigrations replaces the versioned migrations for one test.
+func withMigrations(t *testing.T, migrations []Migration) {
+	t.Helper()
+	saved := versionedMigrations
+	versionedMigrations = migrations
+	t.Cleanup(func() { versionedMigrations = saved })
+}
+
+func TestVersionedMigrations_Numbering(t *testing.T) {
+	d := &Database{}
+	for i, m := range d.Migrations() {
+		if m.Version != i+1 {
+			t.Fatalf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if !m.Baseline() && len(m.Up) == 0 {
+			t.Errorf("migration %d (%s) has no Up statements", m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrations_UpDown(t *testing.T) {
+	base := len((&Database{}).baseline())
+	withMigrations(t, []Migration{
+		{
+			Version: base + 1,
+			Name:    "widgets",
+			Up:      []string{`CREATE TABLE widgets (id TEXT PRIMARY KEY)`},
+			Down:    []string{`DROP TABLE widgets`},
+		},
+		{
+			Version: base + 2,
+			Name:    "widget color",
+			Up:      []string{`ALTER TABLE widgets ADD COLUMN color TEXT`},
+			Down:    []string{`ALTER TABLE widgets DROP COLUMN color`},
+		},
+	})
+
+	db, err := NewSQLite(":memory:", ManualMigrations())
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil || version != base {
+		t.Fatalf("SchemaVersion() = %d, %v; want the baseline %d", version, err, base)
+	}
+	pending, err := db.PendingMigrations()
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("PendingMigrations() = %v, %v; want 2", pending, err)
+	}
+
+	plan, err := db.PlanMigrateUp(base + 1)
+	if err != nil || len(plan) != 1 || plan[0].Name != "widgets" {
+		t.Fatalf("PlanMigrateUp(%d) = %+v, %v", base+1, plan, err)
+	}
+	if _, err := db.DB().Exec(`SELECT id FROM widgets`); err == nil {
+		t.Fatal("planning applied the migration")
+	}
+
+	applied, err := db.MigrateUp(0)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("MigrateUp(0) = %+v, %v", applied, err)
+	}
+	if _, err := db.DB().Exec(`INSERT INTO widgets (id, color) VALUES ('w1', 'red')`); err != nil {
+		t.Fatalf("schema not migrated: %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != base+2 {
+		t.Errorf("SchemaVersion() = %d after up, want %d", version, base+2)
+	}
+
+	rolledBack, err := db.MigrateDown(base)
+	if err != nil || len(rolledBack) != 2 || rolledBack[0].Version != base+2 {
+		t.Fatalf("MigrateDown(%d) = %+v, %v; want newest first", base, rolledBack, err)
+	}
+	if _, err := db.DB().Exec(`SELECT id FROM widgets`); err == nil {
+		t.Error("widgets survived the rollback")
+	}
+
+	if _, err := db.PlanMigrateDown(base - 1); err == nil || !strings.Contains(err.Error(), "can't be rolled back") {
+		t.Errorf("rolling back the baseline: err = %v", err)
+	}
+	if _, err := db.PlanMigrateUp(base + 3); err == nil {
+		t.Error("planning up to an unknown version succeeded")
+	}
+}
+
+func TestMigrations_AppliedOnOpen(t *testing.T) {
+	base := len((&Database{}).baseline())
+	withMigrations(t, []Migration{{
+		Version: base + 1,
+		Name:    "widgets",
+		Up:      []string{`CREATE TABLE widgets (id TEXT PRIMARY KEY)`},
+		Down:    []string{`DROP TABLE widgets`},
+	}})
+
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+
+	status, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	for _, s := range status {
+		if !s.Applied || s.AppliedAt == nil {
+			t.Errorf("migration %d (%s) not applied on open", s.Version, s.Name)
+		}
+	}
+	if last := status[len(status)-1]; !last.Reversible || last.Baseline {
+		t.Errorf("last migration = %+v, want a reversible versioned migration", last)
+	}
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"log"
+)
+
+// migrateWebhooks creates the outbound webhook and delivery history tables
+func (d *Database) migrateWebhooks() error {
+	webhooksSchema := `
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL DEFAULT '',
+		event_types TEXT NOT NULL DEFAULT '[]',
+		active BOOLEAN NOT NULL DEFAULT true,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	`
+
+	if _, err := d.db.Exec(webhooksSchema); err != nil {
+		return err
+	}
+
+	deliveriesSchema := `
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		attempt INTEGER NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		success BOOLEAN NOT NULL DEFAULT false,
+		error TEXT,
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+	`
+
+	if _, err := d.db.Exec(deliveriesSchema); err != nil {
+		return err
+	}
+
+	log.Println("Webhook tables migrated successfully")
+	return nil
+}
@@ -0,0 +1,29 @@
+package database
+
+import "log"
+
+// migrateWorkflowBranches tracks the child beads running the branches of
+// parallel workflow nodes.
+func (d *Database) migrateWorkflowBranches() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS workflow_branches (
+		execution_id TEXT NOT NULL,
+		node_key TEXT NOT NULL,
+		join_node_key TEXT NOT NULL,
+		bead_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (execution_id, node_key),
+		FOREIGN KEY (execution_id) REFERENCES workflow_executions(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_workflow_branches_status ON workflow_branches(status);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Workflow branches table migrated successfully")
+	return nil
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"fmt"
+	"log"
+)
+
+// migrateWorkflowVersions numbers workflow definitions and keeps every
+// version saved through the API.
+func (d *Database) migrateWorkflowVersions() error {
+	if err := d.addColumnIfMissing("workflows", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return fmt.Errorf("migrateWorkflowVersions: %w", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS workflow_versions (
+		workflow_id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		definition TEXT NOT NULL,
+		saved_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (workflow_id, version),
+		FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+	);
+	`
+	if _, err := d.db.Exec(schema); err != nil {
+		return err
+	}
+
+	log.Println("Workflow versions table migrated successfully")
+	return nil
+}
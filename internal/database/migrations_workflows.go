@@ -133,6 +133,10 @@ func (d *Database) migrateWorkflows() error {
 	if _, err := d.db.Exec(historySchema); err != nil {
 		return err
 	}
+	// Rolling an execution back restores the cycle count it had at a node.
+	if err := d.addColumnIfMissing("workflow_execution_history", "cycle_count", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
 
 	log.Println("Workflow tables migrated successfully")
 	return nil
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Milestone groups beads of one or more projects. The first of ProjectIDs
+// is stored as the milestone's project; all of them in project_ids_json.
+// The due_date column predates optional due dates: a milestone without one
+// is stored with the zero time.
+type Milestone struct {
+	ID                 string
+	ProjectIDs         []string
+	Name               string
+	Description        string
+	Type               string
+	Status             string
+	DueDate            *time.Time
+	CompletedAt        *time.Time
+	CloseRequiresBeads bool
+	CreatedBy          string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+const milestoneColumns = `
+	id, project_id, project_ids_json, name, description, type, status, due_date,
+	completed_at, close_requires_beads, created_by, created_at, updated_at
+`
+
+// milestoneRow returns the column values of a milestone, in
+// milestoneColumns order.
+func milestoneRow(m *Milestone) ([]interface{}, error) {
+	if len(m.ProjectIDs) == 0 {
+		return nil, fmt.Errorf("milestone %s has no project", m.ID)
+	}
+	projectIDs, err := json.Marshal(m.ProjectIDs)
+	if err != nil {
+		return nil, err
+	}
+	var due time.Time
+	if m.DueDate != nil {
+		due = m.DueDate.UTC()
+	}
+	return []interface{}{
+		m.ID, m.ProjectIDs[0], string(projectIDs), m.Name, sqlNullString(m.Description), m.Type, m.Status, due,
+		sqlNullTime(m.CompletedAt), m.CloseRequiresBeads, sqlNullString(m.CreatedBy), m.CreatedAt, m.UpdatedAt,
+	}, nil
+}
+
+// CreateMilestone inserts a milestone.
+func (d *Database) CreateMilestone(m *Milestone) error {
+	values, err := milestoneRow(m)
+	if err != nil {
+		return fmt.Errorf("failed to create milestone: %w", err)
+	}
+	query := `INSERT INTO milestones (` + milestoneColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := d.db.Exec(rebind(query), values...); err != nil {
+		return fmt.Errorf("failed to create milestone: %w", err)
+	}
+	return nil
+}
+
+// UpdateMilestone saves every field of a milestone but its creation.
+func (d *Database) UpdateMilestone(m *Milestone) error {
+	values, err := milestoneRow(m)
+	if err != nil {
+		return fmt.Errorf("failed to update milestone: %w", err)
+	}
+	query := `UPDATE milestones SET
+		project_id = ?, project_ids_json = ?, name = ?, description = ?, type = ?, status = ?, due_date = ?,
+		completed_at = ?, close_requires_beads = ?, updated_at = ?
+		WHERE id = ?`
+	args := append(values[1:10], m.UpdatedAt, m.ID)
+	result, err := d.db.Exec(rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to update milestone: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("milestone not found: %s", m.ID)
+	}
+	return nil
+}
+
+// GetMilestone returns a milestone, or nil if there is none with the ID.
+func (d *Database) GetMilestone(id string) (*Milestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM milestones WHERE id = ?`
+	m, err := scanMilestone(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+	return m, nil
+}
+
+// ListMilestones returns the milestones that include a project, or every
+// milestone for an empty projectID, soonest due first and those without a
+// due date last.
+func (d *Database) ListMilestones(projectID string) ([]*Milestone, error) {
+	query := `SELECT ` + milestoneColumns + ` FROM milestones ORDER BY due_date, created_at`
+	rows, err := d.db.Query(rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var milestones, undated []*Milestone
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		if projectID != "" && !m.HasProject(projectID) {
+			continue
+		}
+		if m.DueDate == nil {
+			undated = append(undated, m)
+		} else {
+			milestones = append(milestones, m)
+		}
+	}
+	return append(milestones, undated...), rows.Err()
+}
+
+// DeleteMilestone removes a milestone.
+func (d *Database) DeleteMilestone(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM milestones WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("milestone not found: %s", id)
+	}
+	return nil
+}
+
+// HasProject reports whether the milestone includes a project.
+func (m *Milestone) HasProject(projectID string) bool {
+	for _, id := range m.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+func scanMilestone(row rowScanner) (*Milestone, error) {
+	m := &Milestone{}
+	var (
+		projectID                          string
+		projectIDs, description, createdBy sql.NullString
+		due                                time.Time
+		completedAt                        sql.NullTime
+	)
+	if err := row.Scan(
+		&m.ID, &projectID, &projectIDs, &m.Name, &description, &m.Type, &m.Status, &due,
+		&completedAt, &m.CloseRequiresBeads, &createdBy, &m.CreatedAt, &m.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if projectIDs.String != "" {
+		if err := json.Unmarshal([]byte(projectIDs.String), &m.ProjectIDs); err != nil {
+			return nil, fmt.Errorf("milestone %s: bad project_ids_json: %w", m.ID, err)
+		}
+	}
+	if len(m.ProjectIDs) == 0 {
+		m.ProjectIDs = []string{projectID}
+	}
+	m.Description = description.String
+	m.CreatedBy = createdBy.String
+	if !due.IsZero() {
+		due = due.UTC()
+		m.DueDate = &due
+	}
+	if completedAt.Valid {
+		t := completedAt.Time
+		m.CompletedAt = &t
+	}
+	return m, nil
+}
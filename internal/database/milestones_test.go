@@ -0,0 +1,69 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestMilestonesRoundTrip(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+	for _, id := range []string{"p1", "p2"} {
+		if err := db.UpsertProject(&models.Project{ID: id, Name: id, GitRepo: ".", Branch: "main", BeadsPath: ".beads"}); err != nil {
+			t.Fatalf("UpsertProject: %v", err)
+		}
+	}
+
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, m := range []*Milestone{
+		{ID: "ms-1", ProjectIDs: []string{"p1", "p2"}, Name: "v2", Type: "release", Status: "planned", DueDate: &due, CloseRequiresBeads: true, CreatedAt: now, UpdatedAt: now},
+		{ID: "ms-2", ProjectIDs: []string{"p2"}, Name: "Cleanup", Type: "custom", Status: "planned", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := db.CreateMilestone(m); err != nil {
+			t.Fatalf("CreateMilestone %s: %v", m.ID, err)
+		}
+	}
+
+	m, err := db.GetMilestone("ms-1")
+	if err != nil || m == nil {
+		t.Fatalf("GetMilestone = %v, %v", m, err)
+	}
+	if len(m.ProjectIDs) != 2 || m.DueDate == nil || !m.DueDate.Equal(due) || !m.CloseRequiresBeads {
+		t.Errorf("milestone = %+v", m)
+	}
+	if m, _ := db.GetMilestone("ms-2"); m == nil || m.DueDate != nil {
+		t.Errorf("undated milestone = %+v", m)
+	}
+	if m, err := db.GetMilestone("missing"); err != nil || m != nil {
+		t.Errorf("missing milestone = %v, %v", m, err)
+	}
+
+	if list, _ := db.ListMilestones("p2"); len(list) != 2 || list[0].ID != "ms-1" {
+		t.Errorf("p2 milestones = %v", list)
+	}
+	if list, _ := db.ListMilestones("p1"); len(list) != 1 {
+		t.Errorf("p1 milestones = %v", list)
+	}
+
+	m.Status = "complete"
+	m.CompletedAt = &now
+	m.ProjectIDs = []string{"p2"}
+	if err := db.UpdateMilestone(m); err != nil {
+		t.Fatalf("UpdateMilestone: %v", err)
+	}
+	if m, _ := db.GetMilestone("ms-1"); m.Status != "complete" || m.CompletedAt == nil || m.HasProject("p1") {
+		t.Errorf("updated milestone = %+v", m)
+	}
+	if err := db.DeleteMilestone("ms-1"); err != nil {
+		t.Fatalf("DeleteMilestone: %v", err)
+	}
+	if err := db.DeleteMilestone("ms-1"); err == nil {
+		t.Error("deleted a missing milestone")
+	}
+}
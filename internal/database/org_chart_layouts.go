@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// OrgChartLayout is a project's customized org chart: its positions, without
+// agent assignments, and the default roles removed from it.
+type OrgChartLayout struct {
+	ProjectID    string
+	Positions    []models.Position
+	RemovedRoles []string
+	UpdatedBy    string
+	UpdatedAt    time.Time
+}
+
+// SaveOrgChartLayout stores a project's org chart layout, replacing any
+// saved before.
+func (d *Database) SaveOrgChartLayout(l *OrgChartLayout) error {
+	positions := make([]models.Position, len(l.Positions))
+	for i, p := range l.Positions {
+		p.AgentIDs = nil
+		positions[i] = p
+	}
+	posJSON, err := json.Marshal(positions)
+	if err != nil {
+		return fmt.Errorf("failed to encode org chart positions: %w", err)
+	}
+	removed := l.RemovedRoles
+	if removed == nil {
+		removed = []string{}
+	}
+	removedJSON, err := json.Marshal(removed)
+	if err != nil {
+		return fmt.Errorf("failed to encode removed roles: %w", err)
+	}
+	if l.UpdatedAt.IsZero() {
+		l.UpdatedAt = time.Now().UTC()
+	}
+	_, err = d.db.Exec(rebind(`
+		INSERT INTO org_chart_layouts (project_id, positions, removed_roles, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE
+		SET positions = excluded.positions, removed_roles = excluded.removed_roles,
+			updated_by = excluded.updated_by, updated_at = excluded.updated_at`),
+		l.ProjectID, string(posJSON), string(removedJSON), l.UpdatedBy, l.UpdatedAt.Round(0))
+	if err != nil {
+		return fmt.Errorf("failed to save org chart layout: %w", err)
+	}
+	return nil
+}
+
+// GetOrgChartLayout returns a project's saved org chart layout, or nil if
+// the project uses the default chart.
+func (d *Database) GetOrgChartLayout(projectID string) (*OrgChartLayout, error) {
+	l := &OrgChartLayout{ProjectID: projectID}
+	var posJSON, removedJSON string
+	err := d.db.QueryRow(rebind(`
+		SELECT positions, removed_roles, updated_by, updated_at
+		FROM org_chart_layouts WHERE project_id = ?`), projectID,
+	).Scan(&posJSON, &removedJSON, &l.UpdatedBy, &l.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get org chart layout: %w", err)
+	}
+	if err := json.Unmarshal([]byte(posJSON), &l.Positions); err != nil {
+		return nil, fmt.Errorf("failed to decode org chart positions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(removedJSON), &l.RemovedRoles); err != nil {
+		return nil, fmt.Errorf("failed to decode removed roles: %w", err)
+	}
+	return l, nil
+}
+
+// DeleteOrgChartLayout returns a project to the default org chart. It is
+// not an error if the project has no saved layout.
+func (d *Database) DeleteOrgChartLayout(projectID string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM org_chart_layouts WHERE project_id = ?`), projectID); err != nil {
+		return fmt.Errorf("failed to delete org chart layout: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestOrgChartLayoutRoundTrip(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+
+	if l, err := db.GetOrgChartLayout("p1"); err != nil || l != nil {
+		t.Fatalf("GetOrgChartLayout before saving = %v, %v; want nil, nil", l, err)
+	}
+	err = db.SaveOrgChartLayout(&OrgChartLayout{
+		ProjectID: "p1",
+		Positions: []models.Position{
+			{ID: "pos-lead", RoleName: "lead", PersonaPath: "custom/lead", MaxInstances: 2, AgentIDs: []string{"agent-1"}},
+		},
+		RemovedRoles: []string{"cfo"},
+		UpdatedBy:    "admin",
+	})
+	if err != nil {
+		t.Fatalf("SaveOrgChartLayout: %v", err)
+	}
+	l, err := db.GetOrgChartLayout("p1")
+	if err != nil || l == nil {
+		t.Fatalf("GetOrgChartLayout = %v, %v", l, err)
+	}
+	if len(l.Positions) != 1 || l.Positions[0].RoleName != "lead" || l.Positions[0].MaxInstances != 2 || len(l.Positions[0].AgentIDs) != 0 {
+		t.Errorf("positions = %+v, want the lead position without agents", l.Positions)
+	}
+	if len(l.RemovedRoles) != 1 || l.RemovedRoles[0] != "cfo" || l.UpdatedBy != "admin" {
+		t.Errorf("layout = %+v", l)
+	}
+
+	if err := db.DeleteOrgChartLayout("p1"); err != nil {
+		t.Fatalf("DeleteOrgChartLayout: %v", err)
+	}
+	if l, _ := db.GetOrgChartLayout("p1"); l != nil {
+		t.Error("layout still there after delete")
+	}
+}
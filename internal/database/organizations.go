@@ -0,0 +1,400 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultOrgID is the organization that resources created before
+// organizations existed, and resources created without one, belong to.
+const DefaultOrgID = "default"
+
+// ErrCrossOrg is returned by OrgScope.Check for a resource that belongs to
+// another organization, or to a team outside the scope.
+var ErrCrossOrg = errors.New("resource belongs to another organization")
+
+// Organization groups the projects, providers, agents, and budgets of one
+// tenant.
+type Organization struct {
+	ID        string
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Team is a group of an organization's members. Projects assigned to a
+// team are only visible to members of that team and to members of the
+// whole organization.
+type Team struct {
+	ID        string
+	OrgID     string
+	Name      string
+	CreatedAt time.Time
+}
+
+// OrgMember makes a user a member of an organization. An empty TeamID
+// gives access to the whole organization.
+type OrgMember struct {
+	OrgID     string
+	UserID    string
+	TeamID    string
+	Role      string
+	CreatedAt time.Time
+}
+
+// Org-scoped resource types.
+const (
+	OrgResourceProjects  = "projects"
+	OrgResourceProviders = "providers"
+	OrgResourceAgents    = "agents"
+	OrgResourceBudgets   = "budgets"
+)
+
+// orgOwnerQueries look up a resource's organization and team.
+var orgOwnerQueries = map[string]string{
+	OrgResourceProjects:  `SELECT org_id, team_id FROM projects WHERE id = ?`,
+	OrgResourceProviders: `SELECT org_id, '' FROM providers WHERE id = ?`,
+	OrgResourceBudgets:   `SELECT org_id, '' FROM budgets WHERE id = ?`,
+	// An agent working on a project belongs to the project's organization.
+	OrgResourceAgents: `SELECT COALESCE(p.org_id, a.org_id), COALESCE(p.team_id, '')
+		FROM agents a LEFT JOIN projects p ON p.id = a.project_id WHERE a.id = ?`,
+}
+
+// orgOwnersQueries list the organization and team of every resource of a
+// type.
+var orgOwnersQueries = map[string]string{
+	OrgResourceProjects:  `SELECT id, org_id, team_id FROM projects`,
+	OrgResourceProviders: `SELECT id, org_id, '' FROM providers`,
+	OrgResourceBudgets:   `SELECT id, org_id, '' FROM budgets`,
+	OrgResourceAgents: `SELECT a.id, COALESCE(p.org_id, a.org_id), COALESCE(p.team_id, '')
+		FROM agents a LEFT JOIN projects p ON p.id = a.project_id`,
+}
+
+// CreateOrganization inserts a new organization
+func (d *Database) CreateOrganization(o *Organization) error {
+	query := `INSERT INTO organizations (id, name, created_by, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := d.db.Exec(rebind(query), o.ID, o.Name, sqlNullString(o.CreatedBy), o.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	return nil
+}
+
+// GetOrganization retrieves an organization by ID. It returns nil if none
+// exists.
+func (d *Database) GetOrganization(id string) (*Organization, error) {
+	o := &Organization{}
+	var createdBy sql.NullString
+	err := d.db.QueryRow(rebind(`SELECT id, name, created_by, created_at FROM organizations WHERE id = ?`), id).
+		Scan(&o.ID, &o.Name, &createdBy, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	o.CreatedBy = createdBy.String
+	return o, nil
+}
+
+// ListOrganizations returns every organization, or with a userID, the
+// organizations the user is a member of, ordered by ID
+func (d *Database) ListOrganizations(userID string) ([]*Organization, error) {
+	query := `SELECT id, name, created_by, created_at FROM organizations`
+	var args []interface{}
+	if userID != "" {
+		query += ` WHERE id IN (SELECT org_id FROM org_members WHERE user_id = ?)`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY id`
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []*Organization
+	for rows.Next() {
+		o := &Organization{}
+		var createdBy sql.NullString
+		if err := rows.Scan(&o.ID, &o.Name, &createdBy, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		o.CreatedBy = createdBy.String
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+// DeleteOrganization removes an organization with its teams and
+// memberships. It fails while any resource still belongs to it.
+func (d *Database) DeleteOrganization(id string) error {
+	for _, table := range []string{"projects", "providers", "agents", "budgets"} {
+		var n int
+		if err := d.db.QueryRow(rebind(`SELECT COUNT(*) FROM `+table+` WHERE org_id = ?`), id).Scan(&n); err != nil {
+			return fmt.Errorf("failed to count organization %s: %w", table, err)
+		}
+		if n > 0 {
+			return fmt.Errorf("organization %s still owns %d %s", id, n, table)
+		}
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	result, err := tx.Exec(rebind(`DELETE FROM organizations WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("organization not found: %s", id)
+	}
+	for _, query := range []string{`DELETE FROM teams WHERE org_id = ?`, `DELETE FROM org_members WHERE org_id = ?`} {
+		if _, err := tx.Exec(rebind(query), id); err != nil {
+			return fmt.Errorf("failed to delete organization: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CreateTeam inserts a new team
+func (d *Database) CreateTeam(t *Team) error {
+	query := `INSERT INTO teams (id, org_id, name, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := d.db.Exec(rebind(query), t.ID, t.OrgID, t.Name, t.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+	return nil
+}
+
+// GetTeam retrieves a team of an organization. It returns nil if none
+// exists.
+func (d *Database) GetTeam(orgID, id string) (*Team, error) {
+	t := &Team{}
+	err := d.db.QueryRow(rebind(`SELECT id, org_id, name, created_at FROM teams WHERE org_id = ? AND id = ?`), orgID, id).
+		Scan(&t.ID, &t.OrgID, &t.Name, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return t, nil
+}
+
+// ListTeams returns an organization's teams, ordered by name
+func (d *Database) ListTeams(orgID string) ([]*Team, error) {
+	rows, err := d.db.Query(rebind(`SELECT id, org_id, name, created_at FROM teams WHERE org_id = ? ORDER BY name`), orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []*Team
+	for rows.Next() {
+		t := &Team{}
+		if err := rows.Scan(&t.ID, &t.OrgID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// DeleteTeam removes a team. Its members keep their organization
+// membership with access to the whole organization, and its projects
+// become visible to every member.
+func (d *Database) DeleteTeam(orgID, id string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	result, err := tx.Exec(rebind(`DELETE FROM teams WHERE org_id = ? AND id = ?`), orgID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("team not found: %s", id)
+	}
+	if _, err := tx.Exec(rebind(`UPDATE org_members SET team_id = '' WHERE org_id = ? AND team_id = ?`), orgID, id); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	if _, err := tx.Exec(rebind(`UPDATE projects SET team_id = '' WHERE org_id = ? AND team_id = ?`), orgID, id); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	return tx.Commit()
+}
+
+// UpsertOrgMember adds a user to an organization or changes their team
+// and role
+func (d *Database) UpsertOrgMember(m *OrgMember) error {
+	query := `INSERT INTO org_members (org_id, user_id, team_id, role, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(org_id, user_id) DO UPDATE SET team_id = excluded.team_id, role = excluded.role`
+	if _, err := d.db.Exec(rebind(query), m.OrgID, m.UserID, m.TeamID, m.Role, m.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save organization member: %w", err)
+	}
+	return nil
+}
+
+// GetOrgMember retrieves a user's membership in an organization. It
+// returns nil if the user is not a member.
+func (d *Database) GetOrgMember(orgID, userID string) (*OrgMember, error) {
+	m := &OrgMember{}
+	query := `SELECT org_id, user_id, team_id, role, created_at FROM org_members WHERE org_id = ? AND user_id = ?`
+	err := d.db.QueryRow(rebind(query), orgID, userID).Scan(&m.OrgID, &m.UserID, &m.TeamID, &m.Role, &m.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return m, nil
+}
+
+// ListOrgMembers returns an organization's members, or with an empty
+// orgID, every membership of userID, ordered by organization and user
+func (d *Database) ListOrgMembers(orgID, userID string) ([]*OrgMember, error) {
+	query := `SELECT org_id, user_id, team_id, role, created_at FROM org_members`
+	var args []interface{}
+	if orgID != "" {
+		query += ` WHERE org_id = ?`
+		args = append(args, orgID)
+	} else {
+		query += ` WHERE user_id = ?`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY org_id, user_id`
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*OrgMember
+	for rows.Next() {
+		m := &OrgMember{}
+		if err := rows.Scan(&m.OrgID, &m.UserID, &m.TeamID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// DeleteOrgMember removes a user from an organization
+func (d *Database) DeleteOrgMember(orgID, userID string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM org_members WHERE org_id = ? AND user_id = ?`), orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("organization member not found: %s", userID)
+	}
+	return nil
+}
+
+// SetResourceOrg moves a resource to an organization and, for projects, a
+// team ("" for none)
+func (d *Database) SetResourceOrg(resourceType, id, orgID, teamID string) error {
+	if _, ok := orgOwnerQueries[resourceType]; !ok {
+		return fmt.Errorf("unknown organization resource type: %s", resourceType)
+	}
+	query := `UPDATE ` + resourceType + ` SET org_id = ? WHERE id = ?`
+	args := []interface{}{orgID, id}
+	if resourceType == OrgResourceProjects {
+		query = `UPDATE projects SET org_id = ?, team_id = ? WHERE id = ?`
+		args = []interface{}{orgID, teamID, id}
+	}
+	result, err := d.db.Exec(rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to assign %s to organization: %w", resourceType, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s not found: %s", resourceType, id)
+	}
+	return nil
+}
+
+// OrgScope restricts resource lookups to one organization and, optionally,
+// one of its teams. Every org-scoped API request is checked through one.
+type OrgScope struct {
+	db     *Database
+	OrgID  string
+	TeamID string
+}
+
+// Scope returns an OrgScope for an organization. An empty teamID covers
+// every team.
+func (d *Database) Scope(orgID, teamID string) *OrgScope {
+	return &OrgScope{db: d, OrgID: orgID, TeamID: teamID}
+}
+
+// Check returns ErrCrossOrg if the resource is outside the scope.
+// Resources that aren't stored in the database, such as ones that don't
+// exist, are treated as belonging to the default organization.
+func (s *OrgScope) Check(resourceType, id string) error {
+	query, ok := orgOwnerQueries[resourceType]
+	if !ok {
+		return fmt.Errorf("unknown organization resource type: %s", resourceType)
+	}
+	orgID, teamID := DefaultOrgID, ""
+	err := s.db.db.QueryRow(rebind(query), id).Scan(&orgID, &teamID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to look up %s organization: %w", resourceType, err)
+	}
+	if !s.allows(orgID, teamID) {
+		return ErrCrossOrg
+	}
+	return nil
+}
+
+// Visible returns which of ids are inside the scope, with the same rules
+// as Check.
+func (s *OrgScope) Visible(resourceType string, ids []string) (map[string]bool, error) {
+	query, ok := orgOwnersQueries[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unknown organization resource type: %s", resourceType)
+	}
+	rows, err := s.db.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s organizations: %w", resourceType, err)
+	}
+	defer rows.Close()
+
+	type owner struct{ orgID, teamID string }
+	owners := make(map[string]owner)
+	for rows.Next() {
+		var id string
+		var o owner
+		if err := rows.Scan(&id, &o.orgID, &o.teamID); err != nil {
+			return nil, fmt.Errorf("failed to scan %s organization: %w", resourceType, err)
+		}
+		owners[id] = o
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	visible := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		o, found := owners[id]
+		if !found {
+			o = owner{orgID: DefaultOrgID}
+		}
+		if s.allows(o.orgID, o.teamID) {
+			visible[id] = true
+		}
+	}
+	return visible, nil
+}
+
+// allows reports whether a resource owned by orgID and teamID is in scope.
+// A scope limited to a team still sees resources without a team.
+func (s *OrgScope) allows(orgID, teamID string) bool {
+	return orgID == s.OrgID && (s.TeamID == "" || teamID == "" || teamID == s.TeamID)
+}
@@ -25,7 +25,7 @@ func rebind(query string) string {
 }
 
 // NewPostgres creates a PostgreSQL database connection.
-func NewPostgres(dsn string) (*Database, error) {
+func NewPostgres(dsn string, opts ...Option) (*Database, error) {
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open postgres: %w", err)
@@ -44,24 +44,17 @@ func NewPostgres(dsn string) (*Database, error) {
 
 	d := &Database{
 		db:         db,
+		dialect:    DialectPostgres,
 		supportsHA: true,
+		source:     dsn,
 	}
-
-	// Initialize schema
-	if err := d.initSchemaPostgres(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
-
-	// Run migrations
-	if err := d.migrateProviderOwnership(); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider ownership: %w", err)
+	for _, opt := range opts {
+		opt(d)
 	}
 
-	if err := d.migrateProviderRouting(); err != nil {
+	if err := d.migrate(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to migrate provider routing: %w", err)
+		return nil, err
 	}
 
 	return d, nil
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ProjectArchive is the cold storage of an archived project. Data is a
+// gzip-compressed snapshot of its beads and conversation history; Counts
+// describe it without unpacking it.
+type ProjectArchive struct {
+	ProjectID         string
+	PriorStatus       string
+	ArchivedBy        string
+	ArchivedAt        time.Time
+	BeadCount         int
+	ConversationCount int
+	Data              []byte
+}
+
+// CreateProjectArchive stores a project's archive and deletes its
+// conversation contexts, which the archive now holds, in one transaction.
+func (d *Database) CreateProjectArchive(a *ProjectArchive) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(rebind(`
+		INSERT INTO project_archives (
+			project_id, prior_status, archived_by, archived_at,
+			bead_count, conversation_count, data
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		a.ProjectID, a.PriorStatus, a.ArchivedBy, a.ArchivedAt,
+		a.BeadCount, a.ConversationCount, a.Data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+	if _, err := tx.Exec(rebind(`DELETE FROM conversation_contexts WHERE project_id = ?`), a.ProjectID); err != nil {
+		return fmt.Errorf("failed to archive project conversations: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+	return nil
+}
+
+// GetProjectArchive retrieves a project's archive. It returns nil if the
+// project is not archived.
+func (d *Database) GetProjectArchive(projectID string) (*ProjectArchive, error) {
+	a := &ProjectArchive{}
+	err := d.db.QueryRow(rebind(`
+		SELECT project_id, prior_status, archived_by, archived_at,
+			bead_count, conversation_count, data
+		FROM project_archives WHERE project_id = ?`), projectID,
+	).Scan(&a.ProjectID, &a.PriorStatus, &a.ArchivedBy, &a.ArchivedAt,
+		&a.BeadCount, &a.ConversationCount, &a.Data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project archive: %w", err)
+	}
+	return a, nil
+}
+
+// DeleteProjectArchive removes a project's archive once it is restored.
+func (d *Database) DeleteProjectArchive(projectID string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM project_archives WHERE project_id = ?`), projectID); err != nil {
+		return fmt.Errorf("failed to delete project archive: %w", err)
+	}
+	return nil
+}
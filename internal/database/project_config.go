@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ProjectConfigEntry overrides one setting for one project
+type ProjectConfigEntry struct {
+	ProjectID string
+	Key       string
+	Value     string
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// UpsertProjectConfig stores a project setting, replacing any existing value
+func (d *Database) UpsertProjectConfig(e *ProjectConfigEntry) error {
+	query := `
+		INSERT INTO project_config (project_id, key, value, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (project_id, key) DO UPDATE
+		SET value = excluded.value, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`
+	if _, err := d.db.Exec(rebind(query), e.ProjectID, e.Key, e.Value, sqlNullString(e.UpdatedBy), e.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save project config: %w", err)
+	}
+	return nil
+}
+
+// ListProjectConfig returns a project's settings, ordered by key
+func (d *Database) ListProjectConfig(projectID string) ([]*ProjectConfigEntry, error) {
+	query := `SELECT project_id, key, value, updated_by, updated_at FROM project_config WHERE project_id = ? ORDER BY key`
+	rows, err := d.db.Query(rebind(query), projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project config: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*ProjectConfigEntry
+	for rows.Next() {
+		e := &ProjectConfigEntry{}
+		var updatedBy sql.NullString
+		if err := rows.Scan(&e.ProjectID, &e.Key, &e.Value, &updatedBy, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project config: %w", err)
+		}
+		e.UpdatedBy = updatedBy.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// DeleteProjectConfig removes a project setting. It is not an error if the
+// project has not set it.
+func (d *Database) DeleteProjectConfig(projectID, key string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM project_config WHERE project_id = ? AND key = ?`), projectID, key); err != nil {
+		return fmt.Errorf("failed to delete project config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// UpsertProviderPolicy stores the JSON routing policy for a project, or the
+// default policy when projectID is empty
+func (d *Database) UpsertProviderPolicy(projectID, policyJSON string) error {
+	query := `
+		INSERT INTO provider_policies (project_id, policy_json, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (project_id) DO UPDATE SET policy_json = excluded.policy_json, updated_at = excluded.updated_at
+	`
+	if _, err := d.db.Exec(rebind(query), projectID, policyJSON, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save provider policy: %w", err)
+	}
+	return nil
+}
+
+// ListProviderPolicies returns every stored routing policy keyed by project ID
+func (d *Database) ListProviderPolicies() (map[string]string, error) {
+	rows, err := d.db.Query(`SELECT project_id, policy_json FROM provider_policies`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make(map[string]string)
+	for rows.Next() {
+		var projectID, policyJSON string
+		if err := rows.Scan(&projectID, &policyJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan provider policy: %w", err)
+		}
+		policies[projectID] = policyJSON
+	}
+	return policies, rows.Err()
+}
+
+// DeleteProviderPolicy removes a project's routing policy
+func (d *Database) DeleteProviderPolicy(projectID string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM provider_policies WHERE project_id = ?`), projectID); err != nil {
+		return fmt.Errorf("failed to delete provider policy: %w", err)
+	}
+	return nil
+}
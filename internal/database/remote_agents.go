@@ -0,0 +1,205 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RemoteAgentToken is a one-time enrollment token for a remote agent. It
+// limits which projects, and optionally which roles, the agent may serve.
+type RemoteAgentToken struct {
+	ID         string
+	TokenHash  string
+	ProjectIDs []string
+	Roles      []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	UsedBy     string
+}
+
+// RemoteAgent is an agent running outside the control plane's reach that
+// enrolled with a token and pulls its work
+type RemoteAgent struct {
+	ID             string
+	Name           string
+	ProjectIDs     []string
+	Roles          []string
+	Capabilities   []string
+	CredentialHash string
+	EnrolledAt     time.Time
+	LastSeenAt     *time.Time
+	RevokedAt      *time.Time
+}
+
+func marshalStrings(v []string) (string, error) {
+	if v == nil {
+		v = []string{}
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func unmarshalStrings(s sql.NullString) []string {
+	var v []string
+	if s.Valid && s.String != "" {
+		_ = json.Unmarshal([]byte(s.String), &v)
+	}
+	return v
+}
+
+// CreateRemoteAgentToken stores a new enrollment token
+func (d *Database) CreateRemoteAgentToken(t *RemoteAgentToken) error {
+	projects, err := marshalStrings(t.ProjectIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project IDs: %w", err)
+	}
+	roles, err := marshalStrings(t.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal roles: %w", err)
+	}
+	query := `
+		INSERT INTO remote_agent_tokens (id, token_hash, project_ids, roles, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := d.db.Exec(rebind(query), t.ID, t.TokenHash, projects, roles, sqlNullString(t.CreatedBy), t.CreatedAt, t.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to create remote agent token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeRemoteAgentToken marks an unused, unexpired token as used by
+// usedBy and returns it. It returns nil if no such token exists, so a
+// token can only ever be consumed once.
+func (d *Database) ConsumeRemoteAgentToken(tokenHash, usedBy string, now time.Time) (*RemoteAgentToken, error) {
+	result, err := d.db.Exec(rebind(`
+		UPDATE remote_agent_tokens SET used_at = ?, used_by = ?
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+	`), now, usedBy, tokenHash, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume remote agent token: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+
+	t := &RemoteAgentToken{}
+	var projects, roles, createdBy, usedByCol sql.NullString
+	var usedAt sql.NullTime
+	err = d.db.QueryRow(rebind(`
+		SELECT id, token_hash, project_ids, roles, created_by, created_at, expires_at, used_at, used_by
+		FROM remote_agent_tokens WHERE token_hash = ?
+	`), tokenHash).Scan(&t.ID, &t.TokenHash, &projects, &roles, &createdBy, &t.CreatedAt, &t.ExpiresAt, &usedAt, &usedByCol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remote agent token: %w", err)
+	}
+	t.ProjectIDs = unmarshalStrings(projects)
+	t.Roles = unmarshalStrings(roles)
+	t.CreatedBy = createdBy.String
+	t.UsedBy = usedByCol.String
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	return t, nil
+}
+
+// CreateRemoteAgent stores a newly enrolled remote agent
+func (d *Database) CreateRemoteAgent(a *RemoteAgent) error {
+	projects, err := marshalStrings(a.ProjectIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project IDs: %w", err)
+	}
+	roles, err := marshalStrings(a.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal roles: %w", err)
+	}
+	capabilities, err := marshalStrings(a.Capabilities)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capabilities: %w", err)
+	}
+	query := `
+		INSERT INTO remote_agents (id, name, project_ids, roles, capabilities, credential_hash, enrolled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := d.db.Exec(rebind(query), a.ID, a.Name, projects, roles, capabilities, a.CredentialHash, a.EnrolledAt); err != nil {
+		return fmt.Errorf("failed to create remote agent: %w", err)
+	}
+	return nil
+}
+
+const remoteAgentColumns = `id, name, project_ids, roles, capabilities, credential_hash, enrolled_at, last_seen_at, revoked_at`
+
+func scanRemoteAgent(scan func(dest ...interface{}) error) (*RemoteAgent, error) {
+	a := &RemoteAgent{}
+	var projects, roles, capabilities sql.NullString
+	var lastSeen, revoked sql.NullTime
+	if err := scan(&a.ID, &a.Name, &projects, &roles, &capabilities, &a.CredentialHash, &a.EnrolledAt, &lastSeen, &revoked); err != nil {
+		return nil, err
+	}
+	a.ProjectIDs = unmarshalStrings(projects)
+	a.Roles = unmarshalStrings(roles)
+	a.Capabilities = unmarshalStrings(capabilities)
+	if lastSeen.Valid {
+		a.LastSeenAt = &lastSeen.Time
+	}
+	if revoked.Valid {
+		a.RevokedAt = &revoked.Time
+	}
+	return a, nil
+}
+
+// GetRemoteAgent returns a remote agent, or nil if there is none with id
+func (d *Database) GetRemoteAgent(id string) (*RemoteAgent, error) {
+	row := d.db.QueryRow(rebind(`SELECT `+remoteAgentColumns+` FROM remote_agents WHERE id = ?`), id)
+	a, err := scanRemoteAgent(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote agent: %w", err)
+	}
+	return a, nil
+}
+
+// ListRemoteAgents returns all remote agents, revoked ones included, in
+// enrollment order
+func (d *Database) ListRemoteAgents() ([]*RemoteAgent, error) {
+	rows, err := d.db.Query(`SELECT ` + remoteAgentColumns + ` FROM remote_agents ORDER BY enrolled_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*RemoteAgent
+	for rows.Next() {
+		a, err := scanRemoteAgent(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan remote agent: %w", err)
+		}
+		agents = append(agents, a)
+	}
+	return agents, rows.Err()
+}
+
+// TouchRemoteAgent records when a remote agent was last heard from
+func (d *Database) TouchRemoteAgent(id string, seenAt time.Time) error {
+	if _, err := d.db.Exec(rebind(`UPDATE remote_agents SET last_seen_at = ? WHERE id = ?`), seenAt, id); err != nil {
+		return fmt.Errorf("failed to update remote agent: %w", err)
+	}
+	return nil
+}
+
+// RevokeRemoteAgent revokes a remote agent's credential
+func (d *Database) RevokeRemoteAgent(id string, revokedAt time.Time) error {
+	result, err := d.db.Exec(rebind(`UPDATE remote_agents SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`), revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke remote agent: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("remote agent not found or already revoked: %s", id)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Report is a generated project report. DataJSON holds the report itself,
+// rendered markdown and HTML included.
+type Report struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"project_id"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	DataJSON    string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateReport stores a generated report.
+func (d *Database) CreateReport(r *Report) error {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	_, err := d.db.Exec(rebind(`
+		INSERT INTO reports (id, project_id, period, period_start, period_end, data_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		r.ID, r.ProjectID, r.Period, r.PeriodStart.UTC(), r.PeriodEnd.UTC(), r.DataJSON, r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns a stored report, or nil if there is none with the ID.
+func (d *Database) GetReport(id string) (*Report, error) {
+	r := &Report{}
+	err := d.db.QueryRow(rebind(`
+		SELECT id, project_id, period, period_start, period_end, data_json, created_at
+		FROM reports WHERE id = ?`), id,
+	).Scan(&r.ID, &r.ProjectID, &r.Period, &r.PeriodStart, &r.PeriodEnd, &r.DataJSON, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	return r, nil
+}
+
+// ListReports returns a project's reports, newest first, without their
+// data. An empty projectID lists every project's.
+func (d *Database) ListReports(projectID string, limit int) ([]*Report, error) {
+	query := `SELECT id, project_id, period, period_start, period_end, created_at FROM reports`
+	var args []interface{}
+	if projectID != "" {
+		query += ` WHERE project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY period_end DESC, created_at DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*Report
+	for rows.Next() {
+		r := &Report{}
+		if err := rows.Scan(&r.ID, &r.ProjectID, &r.Period, &r.PeriodStart, &r.PeriodEnd, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// HasReport reports whether a project's report for the period ending at
+// end was already stored.
+func (d *Database) HasReport(projectID, period string, end time.Time) (bool, error) {
+	var count int
+	err := d.db.QueryRow(rebind(`
+		SELECT COUNT(*) FROM reports WHERE project_id = ? AND period = ? AND period_end = ?`),
+		projectID, period, end.UTC(),
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up report: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportsRoundTrip(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite: %v", err)
+	}
+	defer db.Close()
+
+	end := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	for i, r := range []*Report{
+		{ID: "rep-1", ProjectID: "p1", Period: "week", PeriodStart: end.AddDate(0, 0, -14), PeriodEnd: end.AddDate(0, 0, -7), DataJSON: `{"n":1}`},
+		{ID: "rep-2", ProjectID: "p1", Period: "week", PeriodStart: end.AddDate(0, 0, -7), PeriodEnd: end, DataJSON: `{"n":2}`},
+		{ID: "rep-3", ProjectID: "p2", Period: "day", PeriodStart: end.AddDate(0, 0, -1), PeriodEnd: end, DataJSON: `{"n":3}`},
+	} {
+		if err := db.CreateReport(r); err != nil {
+			t.Fatalf("CreateReport %d: %v", i, err)
+		}
+	}
+
+	r, err := db.GetReport("rep-2")
+	if err != nil || r == nil || r.DataJSON != `{"n":2}` || !r.PeriodEnd.Equal(end) {
+		t.Fatalf("GetReport = %+v, %v", r, err)
+	}
+	if r, err := db.GetReport("missing"); err != nil || r != nil {
+		t.Errorf("GetReport(missing) = %+v, %v; want nil, nil", r, err)
+	}
+
+	list, err := db.ListReports("p1", 0)
+	if err != nil || len(list) != 2 || list[0].ID != "rep-2" || list[0].DataJSON != "" {
+		t.Errorf("ListReports(p1) = %+v, %v; want rep-2 then rep-1 without data", list, err)
+	}
+	if list, err := db.ListReports("", 1); err != nil || len(list) != 1 {
+		t.Errorf("ListReports limited to 1 = %d reports, %v", len(list), err)
+	}
+
+	if ok, err := db.HasReport("p1", "week", end); err != nil || !ok {
+		t.Errorf("HasReport(p1, week, end) = %v, %v; want true", ok, err)
+	}
+	if ok, err := db.HasReport("p1", "day", end); err != nil || ok {
+		t.Errorf("HasReport(p1, day, end) = %v, %v; want false", ok, err)
+	}
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BeadSchedule is a recurring bead definition fired on a cron expression
+type BeadSchedule struct {
+	ID          string
+	Name        string
+	ProjectID   string
+	CronExpr    string
+	Title       string
+	Description string
+	Priority    int
+	BeadType    string
+	Enabled     bool
+	NextFireAt  *time.Time
+	LastFiredAt *time.Time
+	LastBeadID  string
+	CreatedBy   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const beadScheduleColumns = `
+	id, name, project_id, cron_expr, title, description, priority, bead_type,
+	enabled, next_fire_at, last_fired_at, last_bead_id, created_by, created_at, updated_at
+`
+
+// CreateBeadSchedule inserts a new bead schedule
+func (d *Database) CreateBeadSchedule(s *BeadSchedule) error {
+	query := `INSERT INTO bead_schedules (` + beadScheduleColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		s.ID, s.Name, s.ProjectID, s.CronExpr, s.Title, s.Description, s.Priority, s.BeadType,
+		s.Enabled, sqlNullTime(s.NextFireAt), sqlNullTime(s.LastFiredAt), sqlNullString(s.LastBeadID),
+		sqlNullString(s.CreatedBy), s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create bead schedule: %w", err)
+	}
+	return nil
+}
+
+// UpdateBeadSchedule updates a schedule's definition and firing state
+func (d *Database) UpdateBeadSchedule(s *BeadSchedule) error {
+	query := `
+		UPDATE bead_schedules
+		SET name = ?, project_id = ?, cron_expr = ?, title = ?, description = ?, priority = ?,
+			bead_type = ?, enabled = ?, next_fire_at = ?, last_fired_at = ?, last_bead_id = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+	result, err := d.db.Exec(rebind(query),
+		s.Name, s.ProjectID, s.CronExpr, s.Title, s.Description, s.Priority,
+		s.BeadType, s.Enabled, sqlNullTime(s.NextFireAt), sqlNullTime(s.LastFiredAt), sqlNullString(s.LastBeadID),
+		s.UpdatedAt, s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update bead schedule: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("bead schedule not found: %s", s.ID)
+	}
+	return nil
+}
+
+// GetBeadSchedule retrieves a schedule by ID. It returns nil if none exists.
+func (d *Database) GetBeadSchedule(id string) (*BeadSchedule, error) {
+	query := `SELECT ` + beadScheduleColumns + ` FROM bead_schedules WHERE id = ?`
+	s, err := scanBeadSchedule(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bead schedule: %w", err)
+	}
+	return s, nil
+}
+
+// ListBeadSchedules returns schedules, optionally filtered by project, oldest first
+func (d *Database) ListBeadSchedules(projectID string) ([]*BeadSchedule, error) {
+	query := `SELECT ` + beadScheduleColumns + ` FROM bead_schedules`
+	var args []interface{}
+	if projectID != "" {
+		query += ` WHERE project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY created_at ASC`
+	return d.queryBeadSchedules(query, args...)
+}
+
+// ListDueBeadSchedules returns enabled schedules whose next fire time is at
+// or before now. The comparison is done in Go because SQLite stores
+// timestamps as text.
+func (d *Database) ListDueBeadSchedules(now time.Time) ([]*BeadSchedule, error) {
+	query := `SELECT ` + beadScheduleColumns + ` FROM bead_schedules
+		WHERE enabled = ? AND next_fire_at IS NOT NULL`
+	schedules, err := d.queryBeadSchedules(query, true)
+	if err != nil {
+		return nil, err
+	}
+	due := schedules[:0]
+	for _, s := range schedules {
+		if !s.NextFireAt.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+// DeleteBeadSchedule removes a schedule. Beads it already created are kept.
+func (d *Database) DeleteBeadSchedule(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM bead_schedules WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete bead schedule: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("bead schedule not found: %s", id)
+	}
+	return nil
+}
+
+func (d *Database) queryBeadSchedules(query string, args ...interface{}) ([]*BeadSchedule, error) {
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bead schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*BeadSchedule
+	for rows.Next() {
+		s, err := scanBeadSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bead schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func scanBeadSchedule(row rowScanner) (*BeadSchedule, error) {
+	s := &BeadSchedule{}
+	var nextFireAt, lastFiredAt sql.NullTime
+	var lastBeadID, createdBy sql.NullString
+	if err := row.Scan(
+		&s.ID, &s.Name, &s.ProjectID, &s.CronExpr, &s.Title, &s.Description, &s.Priority, &s.BeadType,
+		&s.Enabled, &nextFireAt, &lastFiredAt, &lastBeadID, &createdBy, &s.CreatedAt, &s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if nextFireAt.Valid {
+		s.NextFireAt = &nextFireAt.Time
+	}
+	if lastFiredAt.Valid {
+		s.LastFiredAt = &lastFiredAt.Time
+	}
+	s.LastBeadID = lastBeadID.String
+	s.CreatedBy = createdBy.String
+	return s, nil
+}
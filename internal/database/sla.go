@@ -0,0 +1,196 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SLAPolicy sets how quickly beads of one priority must be started and
+// closed. An empty ProjectID applies to every project without its own policy.
+type SLAPolicy struct {
+	ID             string
+	ProjectID      string
+	Priority       int
+	RespondSeconds int64
+	ResolveSeconds int64
+	AutoEscalate   bool
+	CreatedBy      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// SLABreach records that a bead missed a deadline. Kind is "response" or
+// "resolution"; each is recorded at most once per bead.
+type SLABreach struct {
+	BeadID       string
+	Kind         string
+	PolicyID     string
+	ProjectID    string
+	DueAt        time.Time
+	BreachedAt   time.Time
+	EscalationID string
+}
+
+const slaPolicyColumns = `
+	id, project_id, priority, respond_seconds, resolve_seconds, auto_escalate,
+	created_by, created_at, updated_at
+`
+
+const slaBreachColumns = `
+	bead_id, kind, policy_id, project_id, due_at, breached_at, escalation_id
+`
+
+// CreateSLAPolicy inserts a new SLA policy
+func (d *Database) CreateSLAPolicy(p *SLAPolicy) error {
+	query := `INSERT INTO sla_policies (` + slaPolicyColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := d.db.Exec(rebind(query),
+		p.ID, p.ProjectID, p.Priority, p.RespondSeconds, p.ResolveSeconds, p.AutoEscalate,
+		sqlNullString(p.CreatedBy), p.CreatedAt, p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SLA policy: %w", err)
+	}
+	return nil
+}
+
+// UpdateSLAPolicy updates a policy's deadlines and escalation setting
+func (d *Database) UpdateSLAPolicy(p *SLAPolicy) error {
+	query := `UPDATE sla_policies
+		SET respond_seconds = ?, resolve_seconds = ?, auto_escalate = ?, updated_at = ?
+		WHERE id = ?`
+	result, err := d.db.Exec(rebind(query), p.RespondSeconds, p.ResolveSeconds, p.AutoEscalate, p.UpdatedAt, p.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update SLA policy: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("SLA policy not found: %s", p.ID)
+	}
+	return nil
+}
+
+// GetSLAPolicy retrieves a policy by ID. It returns nil if none exists.
+func (d *Database) GetSLAPolicy(id string) (*SLAPolicy, error) {
+	query := `SELECT ` + slaPolicyColumns + ` FROM sla_policies WHERE id = ?`
+	p, err := scanSLAPolicy(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+	return p, nil
+}
+
+// FindSLAPolicy retrieves the policy for a project and priority. It returns
+// nil if none exists.
+func (d *Database) FindSLAPolicy(projectID string, priority int) (*SLAPolicy, error) {
+	query := `SELECT ` + slaPolicyColumns + ` FROM sla_policies WHERE project_id = ? AND priority = ?`
+	p, err := scanSLAPolicy(d.db.QueryRow(rebind(query), projectID, priority))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLA policy: %w", err)
+	}
+	return p, nil
+}
+
+// ListSLAPolicies returns all policies ordered by project and priority
+func (d *Database) ListSLAPolicies() ([]*SLAPolicy, error) {
+	query := `SELECT ` + slaPolicyColumns + ` FROM sla_policies ORDER BY project_id, priority`
+	rows, err := d.db.Query(rebind(query))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLA policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*SLAPolicy
+	for rows.Next() {
+		p, err := scanSLAPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan SLA policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// DeleteSLAPolicy removes a policy. Breaches already recorded are kept.
+func (d *Database) DeleteSLAPolicy(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM sla_policies WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete SLA policy: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("SLA policy not found: %s", id)
+	}
+	return nil
+}
+
+// CreateSLABreach records a breach. It reports false, without error, if the
+// bead already has a breach of the same kind.
+func (d *Database) CreateSLABreach(b *SLABreach) (bool, error) {
+	query := `INSERT INTO sla_breaches (` + slaBreachColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (bead_id, kind) DO NOTHING`
+	result, err := d.db.Exec(rebind(query),
+		b.BeadID, b.Kind, b.PolicyID, b.ProjectID, b.DueAt, b.BreachedAt, sqlNullString(b.EscalationID),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record SLA breach: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// SetSLABreachEscalation records the decision a breach was escalated to
+func (d *Database) SetSLABreachEscalation(beadID, kind, escalationID string) error {
+	query := `UPDATE sla_breaches SET escalation_id = ? WHERE bead_id = ? AND kind = ?`
+	if _, err := d.db.Exec(rebind(query), escalationID, beadID, kind); err != nil {
+		return fmt.Errorf("failed to update SLA breach: %w", err)
+	}
+	return nil
+}
+
+// ListSLABreaches returns breaches recorded at or after since, optionally
+// filtered by project, newest first
+func (d *Database) ListSLABreaches(projectID string, since time.Time) ([]*SLABreach, error) {
+	query := `SELECT ` + slaBreachColumns + ` FROM sla_breaches WHERE breached_at >= ?`
+	args := []interface{}{since}
+	if projectID != "" {
+		query += ` AND project_id = ?`
+		args = append(args, projectID)
+	}
+	query += ` ORDER BY breached_at DESC`
+
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SLA breaches: %w", err)
+	}
+	defer rows.Close()
+
+	var breaches []*SLABreach
+	for rows.Next() {
+		b := &SLABreach{}
+		var escalationID sql.NullString
+		if err := rows.Scan(&b.BeadID, &b.Kind, &b.PolicyID, &b.ProjectID, &b.DueAt, &b.BreachedAt, &escalationID); err != nil {
+			return nil, fmt.Errorf("failed to scan SLA breach: %w", err)
+		}
+		b.EscalationID = escalationID.String
+		breaches = append(breaches, b)
+	}
+	return breaches, rows.Err()
+}
+
+func scanSLAPolicy(row rowScanner) (*SLAPolicy, error) {
+	p := &SLAPolicy{}
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&p.ID, &p.ProjectID, &p.Priority, &p.RespondSeconds, &p.ResolveSeconds, &p.AutoEscalate,
+		&createdBy, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	p.CreatedBy = createdBy.String
+	return p, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	internalmodels "github.com/jordanhubbard/loom/internal/models"
+)
+
+func TestTranslateSQLite(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"placeholders", "SELECT * FROM t WHERE a = $1 AND b = $2", "SELECT * FROM t WHERE a = ?1 AND b = ?2"},
+		{"reordered placeholders", "UPDATE t SET a = $2 WHERE id = $1", "UPDATE t SET a = ?2 WHERE id = ?1"},
+		{"now", "INSERT INTO t (ts) VALUES (NOW())", "INSERT INTO t (ts) VALUES (CURRENT_TIMESTAMP)"},
+		{"serial", "id SERIAL PRIMARY KEY,", "id INTEGER PRIMARY KEY AUTOINCREMENT,"},
+		{"jsonb", "metadata JSONB", "metadata TEXT"},
+		{"bytea", "ADD COLUMN embedding BYTEA", "ADD COLUMN embedding BLOB"},
+		{"text array", "tags TEXT[]", "tags TEXT"},
+		{"truncate", "TRUNCATE \"a\", \"b\" CASCADE", "DELETE FROM \"a\"; DELETE FROM \"b\""},
+		{"truncate table", "TRUNCATE TABLE logs", "DELETE FROM logs"},
+		{"passthrough", "SELECT value FROM config_kv WHERE key = ?", "SELECT value FROM config_kv WHERE key = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translateSQLite(tt.in); got != tt.want {
+				t.Errorf("translateSQLite(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "loom.db")
+
+	db, err := NewSQLite(path)
+	if err != nil {
+		t.Fatalf("NewSQLite failed: %v", err)
+	}
+	if db.Type() != "sqlite" {
+		t.Errorf("Type() = %q, want %q", db.Type(), "sqlite")
+	}
+	if db.SupportsHA() {
+		t.Error("SupportsHA() should be false for SQLite")
+	}
+
+	if err := db.SetConfigValue("theme", "dark"); err != nil {
+		t.Fatalf("SetConfigValue failed: %v", err)
+	}
+	if err := db.SetConfigValue("theme", "light"); err != nil {
+		t.Fatalf("SetConfigValue (upsert) failed: %v", err)
+	}
+	if err := db.UpsertProvider(&internalmodels.Provider{
+		ID: "tokenhub", Name: "TokenHub", Type: "openai", Endpoint: "http://localhost:8090/v1", Status: "healthy",
+	}); err != nil {
+		t.Fatalf("UpsertProvider failed: %v", err)
+	}
+	db.Close()
+
+	// Reopening runs every migration again; they must be idempotent.
+	db, err = NewSQLite(path)
+	if err != nil {
+		t.Fatalf("NewSQLite (reopen) failed: %v", err)
+	}
+	defer db.Close()
+
+	val, found, err := db.GetConfigValue("theme")
+	if err != nil || !found || val != "light" {
+		t.Errorf("GetConfigValue = (%q, %v, %v), want (\"light\", true, nil)", val, found, err)
+	}
+	p, err := db.GetProvider("tokenhub")
+	if err != nil {
+		t.Fatalf("GetProvider failed: %v", err)
+	}
+	if p.Endpoint != "http://localhost:8090/v1" {
+		t.Errorf("provider endpoint = %q", p.Endpoint)
+	}
+}
+
+func TestNewSQLite_InMemory(t *testing.T) {
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLite(:memory:) failed: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SetConfigValue("k", "v"); err != nil {
+		t.Fatalf("SetConfigValue failed: %v", err)
+	}
+	if _, err := db.DB().Exec("TRUNCATE config_kv"); err != nil {
+		t.Fatalf("TRUNCATE failed: %v", err)
+	}
+	if _, found, _ := db.GetConfigValue("k"); found {
+		t.Error("expected config_kv to be empty after TRUNCATE")
+	}
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Webhook is an outbound HTTP endpoint subscribed to event types
+type Webhook struct {
+	ID         string
+	Name       string
+	URL        string
+	Secret     string
+	EventTypes []string
+	Active     bool
+	CreatedBy  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID         string
+	WebhookID  string
+	EventID    string
+	EventType  string
+	Attempt    int
+	StatusCode int
+	Success    bool
+	Error      string
+	DurationMs int64
+	CreatedAt  time.Time
+}
+
+// CreateWebhook inserts a new webhook
+func (d *Database) CreateWebhook(w *Webhook) error {
+	eventTypes, err := json.Marshal(w.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event types: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (
+			id, name, url, secret, event_types, active, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = d.db.Exec(rebind(query),
+		w.ID, w.Name, w.URL, w.Secret, string(eventTypes), w.Active,
+		sqlNullString(w.CreatedBy), w.CreatedAt, w.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhook updates a webhook's mutable fields
+func (d *Database) UpdateWebhook(w *Webhook) error {
+	eventTypes, err := json.Marshal(w.EventTypes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event types: %w", err)
+	}
+
+	query := `
+		UPDATE webhooks
+		SET name = ?, url = ?, secret = ?, event_types = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := d.db.Exec(rebind(query),
+		w.Name, w.URL, w.Secret, string(eventTypes), w.Active, w.UpdatedAt, w.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook not found: %s", w.ID)
+	}
+	return nil
+}
+
+// GetWebhook retrieves a webhook by ID. It returns nil if none exists.
+func (d *Database) GetWebhook(id string) (*Webhook, error) {
+	query := `
+		SELECT id, name, url, secret, event_types, active, created_by, created_at, updated_at
+		FROM webhooks
+		WHERE id = ?
+	`
+	w, err := scanWebhook(d.db.QueryRow(rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return w, nil
+}
+
+// ListWebhooks returns all webhooks, oldest first
+func (d *Database) ListWebhooks() ([]*Webhook, error) {
+	query := `
+		SELECT id, name, url, secret, event_types, active, created_by, created_at, updated_at
+		FROM webhooks
+		ORDER BY created_at ASC
+	`
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook removes a webhook and its delivery history
+func (d *Database) DeleteWebhook(id string) error {
+	if _, err := d.db.Exec(rebind(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`), id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	result, err := d.db.Exec(rebind(`DELETE FROM webhooks WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook not found: %s", id)
+	}
+	return nil
+}
+
+// CreateWebhookDelivery records a delivery attempt
+func (d *Database) CreateWebhookDelivery(del *WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, webhook_id, event_id, event_type, attempt, status_code,
+			success, error, duration_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := d.db.Exec(rebind(query),
+		del.ID, del.WebhookID, del.EventID, del.EventType, del.Attempt, del.StatusCode,
+		del.Success, sqlNullString(del.Error), del.DurationMs, del.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the most recent delivery attempts for a
+// webhook, newest first
+func (d *Database) ListWebhookDeliveries(webhookID string, limit int) ([]*WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := `
+		SELECT id, webhook_id, event_id, event_type, attempt, status_code,
+			   success, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+	rows, err := d.db.Query(rebind(query), webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		del := &WebhookDelivery{}
+		var errMsg sql.NullString
+		if err := rows.Scan(
+			&del.ID, &del.WebhookID, &del.EventID, &del.EventType, &del.Attempt, &del.StatusCode,
+			&del.Success, &errMsg, &del.DurationMs, &del.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		del.Error = errMsg.String
+		deliveries = append(deliveries, del)
+	}
+	return deliveries, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row rowScanner) (*Webhook, error) {
+	w := &Webhook{}
+	var eventTypes string
+	var createdBy sql.NullString
+	if err := row.Scan(
+		&w.ID, &w.Name, &w.URL, &w.Secret, &eventTypes, &w.Active,
+		&createdBy, &w.CreatedAt, &w.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	w.CreatedBy = createdBy.String
+	if eventTypes != "" {
+		if err := json.Unmarshal([]byte(eventTypes), &w.EventTypes); err != nil {
+			return nil, fmt.Errorf("invalid event types for webhook %s: %w", w.ID, err)
+		}
+	}
+	return w, nil
+}
@@ -52,7 +52,7 @@ func (d *Database) UpsertWorkflow(wf *workflow.Workflow) error {
 // GetWorkflow retrieves a workflow by ID
 func (d *Database) GetWorkflow(id string) (*workflow.Workflow, error) {
 	query := `
-		SELECT id, name, description, workflow_type, is_default, project_id, created_at, updated_at
+		SELECT id, name, description, workflow_type, is_default, project_id, version, created_at, updated_at
 		FROM workflows
 		WHERE id = ?
 	`
@@ -66,6 +66,7 @@ func (d *Database) GetWorkflow(id string) (*workflow.Workflow, error) {
 		&wf.WorkflowType,
 		&wf.IsDefault,
 		&projectID,
+		&wf.Version,
 		&wf.CreatedAt,
 		&wf.UpdatedAt,
 	)
@@ -100,7 +101,7 @@ func (d *Database) GetWorkflow(id string) (*workflow.Workflow, error) {
 // ListWorkflows retrieves workflows, optionally filtered by type or project
 func (d *Database) ListWorkflows(workflowType, projectID string) ([]*workflow.Workflow, error) {
 	query := `
-		SELECT id, name, description, workflow_type, is_default, project_id, created_at, updated_at
+		SELECT id, name, description, workflow_type, is_default, project_id, version, created_at, updated_at
 		FROM workflows
 		WHERE 1=1
 	`
@@ -135,6 +136,7 @@ func (d *Database) ListWorkflows(workflowType, projectID string) ([]*workflow.Wo
 			&wf.WorkflowType,
 			&wf.IsDefault,
 			&projID,
+			&wf.Version,
 			&wf.CreatedAt,
 			&wf.UpdatedAt,
 		)
@@ -163,6 +165,125 @@ func (d *Database) ListWorkflows(workflowType, projectID string) ([]*workflow.Wo
 	return workflows, nil
 }
 
+// ReplaceWorkflow upserts a workflow and replaces its nodes and edges with
+// the ones on wf. Running executions keep their position, since they track
+// the current node by key. Each replacement is a new version: wf.Version is
+// set to it and the definition is kept in workflow_versions.
+func (d *Database) ReplaceWorkflow(wf *workflow.Workflow, savedBy string) error {
+	var current int
+	err := d.db.QueryRow(rebind(`SELECT version FROM workflows WHERE id = ?`), wf.ID).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get workflow version: %w", err)
+	}
+	wf.Version = current + 1
+
+	if err := d.UpsertWorkflow(wf); err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(rebind(`UPDATE workflows SET version = ? WHERE id = ?`), wf.Version, wf.ID); err != nil {
+		return fmt.Errorf("failed to set workflow version: %w", err)
+	}
+	if _, err := d.db.Exec(rebind(`DELETE FROM workflow_edges WHERE workflow_id = ?`), wf.ID); err != nil {
+		return fmt.Errorf("failed to clear workflow edges: %w", err)
+	}
+	if _, err := d.db.Exec(rebind(`DELETE FROM workflow_nodes WHERE workflow_id = ?`), wf.ID); err != nil {
+		return fmt.Errorf("failed to clear workflow nodes: %w", err)
+	}
+	for i := range wf.Nodes {
+		if err := d.UpsertWorkflowNode(&wf.Nodes[i]); err != nil {
+			return fmt.Errorf("failed to save node %s: %w", wf.Nodes[i].NodeKey, err)
+		}
+	}
+	for i := range wf.Edges {
+		if err := d.UpsertWorkflowEdge(&wf.Edges[i]); err != nil {
+			return fmt.Errorf("failed to save edge: %w", err)
+		}
+	}
+
+	definition, err := json.Marshal(workflow.DefinitionFromWorkflow(wf))
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow definition: %w", err)
+	}
+	_, err = d.db.Exec(rebind(`
+		INSERT INTO workflow_versions (workflow_id, version, definition, saved_by, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`), wf.ID, wf.Version, string(definition), savedBy, wf.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save workflow version: %w", err)
+	}
+	return nil
+}
+
+// ListWorkflowVersions returns the saved versions of a workflow, newest
+// first.
+func (d *Database) ListWorkflowVersions(workflowID string) ([]*workflow.WorkflowVersion, error) {
+	rows, err := d.db.Query(rebind(`
+		SELECT workflow_id, version, definition, saved_by, created_at
+		FROM workflow_versions
+		WHERE workflow_id = ?
+		ORDER BY version DESC
+	`), workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*workflow.WorkflowVersion
+	for rows.Next() {
+		v, err := scanWorkflowVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetWorkflowVersion returns one saved version of a workflow.
+func (d *Database) GetWorkflowVersion(workflowID string, version int) (*workflow.WorkflowVersion, error) {
+	row := d.db.QueryRow(rebind(`
+		SELECT workflow_id, version, definition, saved_by, created_at
+		FROM workflow_versions
+		WHERE workflow_id = ? AND version = ?
+	`), workflowID, version)
+	v, err := scanWorkflowVersion(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("workflow version not found: %s v%d", workflowID, version)
+	}
+	return v, err
+}
+
+func scanWorkflowVersion(row rowScanner) (*workflow.WorkflowVersion, error) {
+	v := &workflow.WorkflowVersion{}
+	var definition string
+	var savedBy sql.NullString
+	if err := row.Scan(&v.WorkflowID, &v.Version, &definition, &savedBy, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	v.SavedBy = savedBy.String
+	if err := json.Unmarshal([]byte(definition), &v.Definition); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workflow definition: %w", err)
+	}
+	return v, nil
+}
+
+// DeleteWorkflow deletes a workflow. Its nodes, edges, and executions are
+// removed by cascade.
+func (d *Database) DeleteWorkflow(id string) error {
+	result, err := d.db.Exec(rebind(`DELETE FROM workflows WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("workflow not found: %s", id)
+	}
+	return nil
+}
+
 // UpsertWorkflowNode inserts or updates a workflow node
 func (d *Database) UpsertWorkflowNode(node *workflow.WorkflowNode) error {
 	if node == nil {
@@ -480,11 +601,62 @@ func (d *Database) GetWorkflowExecutionByBeadID(beadID string) (*workflow.Workfl
 	return exec, nil
 }
 
+// ListWorkflowExecutionsByStatus returns the executions with a status,
+// oldest first.
+func (d *Database) ListWorkflowExecutionsByStatus(status workflow.ExecutionStatus) ([]*workflow.WorkflowExecution, error) {
+	query := `
+		SELECT id, workflow_id, bead_id, project_id, current_node_key, status, cycle_count, node_attempt_count, started_at, completed_at, escalated_at, last_node_at
+		FROM workflow_executions
+		WHERE status = ?
+		ORDER BY started_at ASC
+	`
+	rows, err := d.db.Query(rebind(query), string(status))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*workflow.WorkflowExecution
+	for rows.Next() {
+		exec := &workflow.WorkflowExecution{}
+		var currentNodeKey sql.NullString
+		var completedAt, escalatedAt sql.NullTime
+		if err := rows.Scan(
+			&exec.ID,
+			&exec.WorkflowID,
+			&exec.BeadID,
+			&exec.ProjectID,
+			&currentNodeKey,
+			&exec.Status,
+			&exec.CycleCount,
+			&exec.NodeAttemptCount,
+			&exec.StartedAt,
+			&completedAt,
+			&escalatedAt,
+			&exec.LastNodeAt,
+		); err != nil {
+			return nil, err
+		}
+		if currentNodeKey.Valid {
+			exec.CurrentNodeKey = currentNodeKey.String
+		}
+		if completedAt.Valid {
+			exec.CompletedAt = &completedAt.Time
+		}
+		if escalatedAt.Valid {
+			exec.EscalatedAt = &escalatedAt.Time
+		}
+		executions = append(executions, exec)
+	}
+	return executions, rows.Err()
+}
+
 // DeleteWorkflowExecutionByBeadID removes workflow executions for a bead,
 // allowing a fresh workflow to be started (e.g., on redispatch).
 func (d *Database) DeleteWorkflowExecutionByBeadID(beadID string) error {
-	// Delete history first (foreign key)
+	// Delete history and branches first (foreign key)
 	_, _ = d.db.Exec(rebind("DELETE FROM workflow_execution_history WHERE execution_id IN (SELECT id FROM workflow_executions WHERE bead_id = ?)"), beadID)
+	_, _ = d.db.Exec(rebind("DELETE FROM workflow_branches WHERE execution_id IN (SELECT id FROM workflow_executions WHERE bead_id = ?)"), beadID)
 	_, err := d.db.Exec(rebind("DELETE FROM workflow_executions WHERE bead_id = ?"), beadID)
 	return err
 }
@@ -499,8 +671,8 @@ func (d *Database) InsertWorkflowHistory(history *workflow.WorkflowExecutionHist
 	}
 
 	query := `
-		INSERT INTO workflow_execution_history (id, execution_id, node_key, agent_id, condition, result_data, attempt_number, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO workflow_execution_history (id, execution_id, node_key, agent_id, condition, result_data, attempt_number, cycle_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := d.db.Exec(rebind(query),
@@ -511,6 +683,7 @@ func (d *Database) InsertWorkflowHistory(history *workflow.WorkflowExecutionHist
 		string(history.Condition),
 		history.ResultData,
 		history.AttemptNumber,
+		history.CycleCount,
 		history.CreatedAt,
 	)
 	return err
@@ -519,7 +692,7 @@ func (d *Database) InsertWorkflowHistory(history *workflow.WorkflowExecutionHist
 // ListWorkflowHistory retrieves history entries for a workflow execution
 func (d *Database) ListWorkflowHistory(executionID string) ([]*workflow.WorkflowExecutionHistory, error) {
 	query := `
-		SELECT id, execution_id, node_key, agent_id, condition, result_data, attempt_number, created_at
+		SELECT id, execution_id, node_key, agent_id, condition, result_data, attempt_number, cycle_count, created_at
 		FROM workflow_execution_history
 		WHERE execution_id = ?
 		ORDER BY created_at ASC
@@ -543,6 +716,7 @@ func (d *Database) ListWorkflowHistory(executionID string) ([]*workflow.Workflow
 			&h.Condition,
 			&resultData,
 			&h.AttemptNumber,
+			&h.CycleCount,
 			&h.CreatedAt,
 		)
 		if err != nil {
@@ -558,3 +732,73 @@ func (d *Database) ListWorkflowHistory(executionID string) ([]*workflow.Workflow
 
 	return history, nil
 }
+
+// UpsertWorkflowBranch records the child bead of a branch of a parallel
+// node, replacing any earlier run of the same branch.
+func (d *Database) UpsertWorkflowBranch(branch *workflow.WorkflowBranch) error {
+	if branch == nil {
+		return fmt.Errorf("workflow branch cannot be nil")
+	}
+	now := time.Now()
+	if branch.CreatedAt.IsZero() {
+		branch.CreatedAt = now
+	}
+	branch.UpdatedAt = now
+
+	query := `
+		INSERT INTO workflow_branches (execution_id, node_key, join_node_key, bead_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(execution_id, node_key) DO UPDATE SET
+			join_node_key = excluded.join_node_key,
+			bead_id = excluded.bead_id,
+			status = excluded.status,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+	`
+	_, err := d.db.Exec(rebind(query),
+		branch.ExecutionID,
+		branch.NodeKey,
+		branch.JoinNodeKey,
+		branch.BeadID,
+		string(branch.Status),
+		branch.CreatedAt,
+		branch.UpdatedAt,
+	)
+	return err
+}
+
+// ListWorkflowBranches returns the branches of an execution's parallel
+// nodes, oldest first.
+func (d *Database) ListWorkflowBranches(executionID string) ([]*workflow.WorkflowBranch, error) {
+	return d.queryWorkflowBranches(`WHERE execution_id = ?`, executionID)
+}
+
+// ListRunningWorkflowBranches returns the branches, across all executions,
+// whose child beads have not finished.
+func (d *Database) ListRunningWorkflowBranches() ([]*workflow.WorkflowBranch, error) {
+	return d.queryWorkflowBranches(`WHERE status = ?`, string(workflow.BranchStatusRunning))
+}
+
+func (d *Database) queryWorkflowBranches(where string, args ...interface{}) ([]*workflow.WorkflowBranch, error) {
+	query := `
+		SELECT execution_id, node_key, join_node_key, bead_id, status, created_at, updated_at
+		FROM workflow_branches
+		` + where + `
+		ORDER BY created_at ASC, node_key ASC
+	`
+	rows, err := d.db.Query(rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var branches []*workflow.WorkflowBranch
+	for rows.Next() {
+		b := &workflow.WorkflowBranch{}
+		if err := rows.Scan(&b.ExecutionID, &b.NodeKey, &b.JoinNodeKey, &b.BeadID, &b.Status, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		branches = append(branches, b)
+	}
+	return branches, rows.Err()
+}
@@ -280,6 +280,19 @@ func (m *Manager) matchesFilters(decision *models.DecisionBead, filters map[stri
 		}
 	}
 
+	if projectIDs, ok := filters["project_ids"].([]string); ok {
+		found := false
+		for _, id := range projectIDs {
+			if decision.ProjectID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
 	if requesterID, ok := filters["requester_id"].(string); ok {
 		if decision.RequesterID != requesterID {
 			return false
@@ -1128,6 +1128,25 @@ func TestMatchesFilters_EdgeCases(t *testing.T) {
 		}
 	})
 
+	t.Run("project_ids filter keeps decisions of the listed projects", func(t *testing.T) {
+		m := createTestManager()
+		_, _ = m.CreateDecision("Q1?", "", "r1", nil, "", models.BeadPriorityP2, "p1")
+		_, _ = m.CreateDecision("Q2?", "", "r1", nil, "", models.BeadPriorityP2, "p2")
+
+		results, err := m.ListDecisions(map[string]interface{}{"project_ids": []string{"p2", "p3"}})
+		if err != nil {
+			t.Fatalf("ListDecisions() error = %v", err)
+		}
+		if len(results) != 1 || results[0].ProjectID != "p2" {
+			t.Errorf("ListDecisions(project_ids=[p2 p3]) = %v, want the p2 decision", results)
+		}
+
+		results, _ = m.ListDecisions(map[string]interface{}{"project_ids": []string{}})
+		if len(results) != 0 {
+			t.Errorf("ListDecisions(project_ids=[]) count = %d, want 0", len(results))
+		}
+	})
+
 	t.Run("all four filters applied simultaneously", func(t *testing.T) {
 		m := createTestManager()
 		d, _ := m.CreateDecision("Q?", "", "r1", nil, "", models.BeadPriorityP2, "p1")
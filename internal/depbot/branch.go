@@ -0,0 +1,133 @@
+package depbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// openBranch applies a group of updates in the project's work tree and
+// commits them through the action router, which puts the commit on the
+// bead's branch, runs the quality gate, pushes and opens a pull request.
+// It only starts on a clean checkout of the default branch, and puts the
+// work tree back on that branch when it is done.
+func (r *Runner) openBranch(ctx context.Context, proj *models.Project, beadID, level string, updates []Update) error {
+	actx := actions.ActionContext{AgentID: agentID, BeadID: beadID, ProjectID: proj.ID}
+	base := proj.DefaultBranch
+	if base == "" {
+		base = "main"
+	}
+
+	status, err := r.run(ctx, actx, actions.Action{Type: actions.ActionGitStatus})
+	if err != nil {
+		return err
+	}
+	out, _ := status.Metadata["output"].(string)
+	branch, clean := parseStatus(out)
+	if branch != base || !clean {
+		return fmt.Errorf("work tree is not a clean checkout of %s", base)
+	}
+
+	steps := updateActions(updates)
+	title := fmt.Sprintf("Update %s dependencies", level)
+	steps = append(steps, actions.Action{
+		Type:          actions.ActionGitCommit,
+		CommitMessage: commitMessage(title, beadID, updates),
+		PRTitle:       fmt.Sprintf("[bead/%s] %s", beadID, title),
+		PRBody:        buildDescription(level, updates),
+	})
+	for _, step := range steps {
+		if _, err := r.run(ctx, actx, step); err != nil {
+			// Drop the partial update so agents find the tree as it was.
+			_, _ = r.run(ctx, actx, actions.Action{Type: actions.ActionRunCommand, Command: "git reset --hard -q", Reason: "discard failed dependency update"})
+			_, _ = r.run(ctx, actx, actions.Action{Type: actions.ActionGitCheckout, Branch: base})
+			return fmt.Errorf("%s: %w", step.Type, err)
+		}
+	}
+	_, err = r.run(ctx, actx, actions.Action{Type: actions.ActionGitCheckout, Branch: base})
+	return err
+}
+
+// run executes one action and returns its result, or an error if it
+// failed.
+func (r *Runner) run(ctx context.Context, actx actions.ActionContext, action actions.Action) (actions.Result, error) {
+	results, err := r.executor.Execute(ctx, &actions.ActionEnvelope{Actions: []actions.Action{action}}, actx)
+	if err != nil {
+		return actions.Result{}, err
+	}
+	if len(results) == 0 {
+		return actions.Result{}, fmt.Errorf("no result")
+	}
+	res := results[0]
+	if res.Status == "error" {
+		return res, fmt.Errorf("%s", res.Message)
+	}
+	if code, ok := res.Metadata["exit_code"].(int); ok && code != 0 {
+		stderr, _ := res.Metadata["stderr"].(string)
+		return res, fmt.Errorf("%s exited %d: %s", action.Command, code, strings.TrimSpace(stderr))
+	}
+	return res, nil
+}
+
+// parseStatus reads the branch and whether the tree is clean from the
+// output of git status -sb.
+func parseStatus(out string) (branch string, clean bool) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "## ") {
+		return "", false
+	}
+	branch = strings.TrimPrefix(lines[0], "## ")
+	if i := strings.Index(branch, "..."); i >= 0 {
+		branch = branch[:i]
+	}
+	if i := strings.Index(branch, " "); i >= 0 {
+		branch = branch[:i]
+	}
+	return branch, len(lines) == 1
+}
+
+// updateActions returns the actions that apply the updates: the package
+// manager for Go and npm, and an edit of requirements.txt for Python.
+func updateActions(updates []Update) []actions.Action {
+	var steps []actions.Action
+	goUpdated := false
+	for _, u := range updates {
+		switch u.Ecosystem {
+		case EcosystemGo:
+			steps = append(steps, actions.Action{Type: actions.ActionRunCommand,
+				Command: fmt.Sprintf("go get %s@%s", u.Name, u.Latest), Reason: "dependency update"})
+			goUpdated = true
+		case EcosystemNPM:
+			flag := "--save"
+			if u.Dev {
+				flag = "--save-dev"
+			}
+			steps = append(steps, actions.Action{Type: actions.ActionRunCommand,
+				Command: fmt.Sprintf("npm install %s %s@%s", flag, u.Name, u.Latest), Reason: "dependency update"})
+		case EcosystemPyPI:
+			i := strings.LastIndex(u.line, u.Version)
+			if i < 0 {
+				continue
+			}
+			steps = append(steps, actions.Action{Type: actions.ActionEditCode, Path: Requirements,
+				OldText: u.line, NewText: u.line[:i] + u.Latest + u.line[i+len(u.Version):]})
+		}
+	}
+	if goUpdated {
+		steps = append(steps, actions.Action{Type: actions.ActionRunCommand, Command: "go mod tidy", Reason: "dependency update"})
+	}
+	return steps
+}
+
+func commitMessage(title, beadID string, updates []Update) string {
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+	for _, u := range updates {
+		sb.WriteString(fmt.Sprintf("- %s %s -> %s\n", u.Name, u.Version, u.Latest))
+	}
+	sb.WriteString("\nBead: " + beadID + "\n")
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package depbot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.22
+
+require github.com/pkg/errors v0.9.1
+
+require (
+	github.com/google/uuid v1.3.0
+	golang.org/x/sys v0.10.0 // indirect
+	example.com/forked v1.0.0
+)
+
+replace example.com/forked => ../forked
+`
+
+const testPackageJSON = `{
+  "dependencies": {"react": "^18.2.0", "local": "file:../local"},
+  "devDependencies": {"typescript": "~5.1.3"}
+}`
+
+const testRequirements = `# pinned
+requests==2.28.0
+django[argon2] >= 4.1  # web
+flask
+-r other.txt
+`
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{GoMod: testGoMod, PackageJSON: testPackageJSON, Requirements: testRequirements} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deps, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range deps {
+		got = append(got, fmt.Sprintf("%s:%s@%s", d.Ecosystem, d.Name, d.Version))
+	}
+	want := "go:github.com/pkg/errors@v0.9.1 go:github.com/google/uuid@v1.3.0 npm:react@18.2.0 npm:typescript@5.1.3 pypi:requests@2.28.0 pypi:django@4.1"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Scan() = %v\nwant %s", got, want)
+	}
+	if !deps[3].Dev || deps[3].Spec != "~5.1.3" {
+		t.Errorf("typescript = %+v, want a dev dependency with spec ~5.1.3", deps[3])
+	}
+}
+
+func TestUpdateLevel(t *testing.T) {
+	for _, tc := range []struct{ current, latest, want string }{
+		{"v1.2.3", "v2.0.0", LevelMajor},
+		{"1.2.3", "1.3.0", LevelMinor},
+		{"1.2", "1.2.1", LevelPatch},
+		{"v1.2.3", "v1.2.3", ""},
+		{"v2.0.0", "v1.9.9", ""},
+		{"1.2.3", "1.3.0rc1", ""},
+		{"v0.0.0-20230101000000-abcdef123456", "v0.1.0", ""},
+		{"v3.0.0+incompatible", "v3.1.0+incompatible", LevelMinor},
+	} {
+		if got := UpdateLevel(tc.current, tc.latest); got != tc.want {
+			t.Errorf("UpdateLevel(%s, %s) = %q, want %q", tc.current, tc.latest, got, tc.want)
+		}
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	if got := escapeModulePath("github.com/BurntSushi/toml"); got != "github.com/!burnt!sushi/toml" {
+		t.Errorf("escapeModulePath = %q", got)
+	}
+}
+
+type fakeSource map[string]string
+
+func (s fakeSource) Latest(_ context.Context, dep Dependency) (string, error) {
+	if v, ok := s[dep.Name]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("not found")
+}
+
+type fakeProjects struct{ proj *models.Project }
+
+func (f fakeProjects) ListProjectIDs() []string { return []string{f.proj.ID} }
+
+func (f fakeProjects) GetProject(string) (*models.Project, error) { return f.proj, nil }
+
+type fakeBeads struct {
+	existing []*models.Bead
+	created  []*models.Bead
+}
+
+func (f *fakeBeads) CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error) {
+	b := &models.Bead{ID: fmt.Sprintf("bd-%d", len(f.created)+1), Title: title, Description: description, Priority: priority, ProjectID: projectID}
+	f.created = append(f.created, b)
+	return b, nil
+}
+
+func (f *fakeBeads) GetBeadsByProject(string) ([]*models.Bead, error) { return f.existing, nil }
+
+type fakeExecutor struct {
+	status string
+	fail   string
+	ran    []actions.Action
+}
+
+func (f *fakeExecutor) Execute(_ context.Context, env *actions.ActionEnvelope, _ actions.ActionContext) ([]actions.Result, error) {
+	a := env.Actions[0]
+	f.ran = append(f.ran, a)
+	res := actions.Result{ActionType: a.Type, Status: "executed"}
+	switch {
+	case a.Type == actions.ActionGitStatus:
+		res.Metadata = map[string]interface{}{"output": f.status}
+	case f.fail != "" && a.Type == f.fail:
+		res.Status, res.Message = "error", "quality gate failed"
+	}
+	return []actions.Result{res}, nil
+}
+
+func newTestRunner(t *testing.T, existing ...*models.Bead) (*Runner, *fakeBeads, *fakeExecutor) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, GoMod), []byte(testGoMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, Requirements), []byte(testRequirements), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	beads := &fakeBeads{existing: existing}
+	exec := &fakeExecutor{status: "## main...origin/main"}
+	r := NewRunner(fakeProjects{&models.Project{ID: "app", WorkDir: dir}}, beads, 0, Options{BranchLevels: []string{LevelPatch}})
+	r.SetVersionSource(fakeSource{
+		"github.com/pkg/errors":  "v1.0.0",
+		"github.com/google/uuid": "v1.3.1",
+		"requests":               "2.28.2",
+		"django":                 "4.1",
+		"golang.org/x/sys":       "v0.20.0",
+		"example.com/forked":     "v2.0.0",
+	})
+	r.SetActionExecutor(exec)
+	return r, beads, exec
+}
+
+func TestRunner_FilesBeadPerLevel(t *testing.T) {
+	r, beads, exec := newTestRunner(t, &models.Bead{Title: beadTitle(LevelMajor), Status: models.BeadStatusOpen})
+	r.sweep(context.Background())
+
+	if len(beads.created) != 1 {
+		t.Fatalf("created %d beads, want only the patch bead: %+v", len(beads.created), beads.created)
+	}
+	b := beads.created[0]
+	if b.Title != "[deps] Patch dependency updates" || b.Priority != models.BeadPriorityP3 {
+		t.Errorf("bead = %q P%d", b.Title, b.Priority)
+	}
+	if !strings.Contains(b.Description, "| go.mod | github.com/google/uuid | v1.3.0 | v1.3.1 |") ||
+		!strings.Contains(b.Description, "| requirements.txt | requests | 2.28.0 | 2.28.2 |") {
+		t.Errorf("description = %s", b.Description)
+	}
+
+	var ran []string
+	for _, a := range exec.ran {
+		ran = append(ran, a.Type+" "+a.Command+a.NewText)
+	}
+	want := []string{
+		"git_status ",
+		"run_command go get github.com/google/uuid@v1.3.1",
+		"edit_code requests==2.28.2",
+		"run_command go mod tidy",
+		"git_commit ",
+		"git_checkout ",
+	}
+	if strings.Join(ran, "|") != strings.Join(want, "|") {
+		t.Errorf("actions = %q\nwant %q", ran, want)
+	}
+	if commit := exec.ran[4]; !strings.HasPrefix(commit.PRTitle, "[bead/bd-1] Update patch") {
+		t.Errorf("PR title = %q", commit.PRTitle)
+	}
+}
+
+func TestRunner_BranchNeedsCleanDefaultBranch(t *testing.T) {
+	r, _, exec := newTestRunner(t)
+	exec.status = "## main\n M go.mod"
+	r.sweep(context.Background())
+	if len(exec.ran) != 1 {
+		t.Errorf("ran %d actions on a dirty tree, want only git_status", len(exec.ran))
+	}
+}
+
+func TestRunner_FailedUpdateIsRolledBack(t *testing.T) {
+	r, _, exec := newTestRunner(t)
+	exec.fail = actions.ActionGitCommit
+	r.sweep(context.Background())
+	n := len(exec.ran)
+	if n < 2 || exec.ran[n-2].Command != "git reset --hard -q" || exec.ran[n-1].Type != actions.ActionGitCheckout || exec.ran[n-1].Branch != "main" {
+		t.Errorf("last actions = %+v, want a reset and a checkout of main", exec.ran[max(0, n-2):])
+	}
+}
@@ -0,0 +1,185 @@
+// Package depbot periodically scans projects' dependency manifests (go.mod,
+// package.json, requirements.txt) for available updates, files a bead per
+// project and update level, and can open update branches through the
+// action router.
+package depbot
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystems.
+const (
+	EcosystemGo   = "go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "pypi"
+)
+
+// Manifest file names.
+const (
+	GoMod        = "go.mod"
+	PackageJSON  = "package.json"
+	Requirements = "requirements.txt"
+)
+
+// Dependency is a direct dependency declared in a manifest.
+type Dependency struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+	Manifest  string `json:"manifest"`
+	// Dev marks an npm devDependency.
+	Dev bool `json:"dev,omitempty"`
+	// Spec is the version as written, e.g. "^1.2.0" or "==1.2.0".
+	Spec string `json:"spec,omitempty"`
+	// line is the requirement as written in requirements.txt.
+	line string
+}
+
+// Scan reads the manifests at the root of workDir and returns their direct
+// dependencies. Missing manifests are skipped.
+func Scan(workDir string) ([]Dependency, error) {
+	var deps []Dependency
+	for _, m := range []struct {
+		file  string
+		parse func([]byte) ([]Dependency, error)
+	}{
+		{GoMod, ParseGoMod},
+		{PackageJSON, ParsePackageJSON},
+		{Requirements, ParseRequirements},
+	} {
+		data, err := os.ReadFile(filepath.Join(workDir, m.file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found, err := m.parse(data)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, found...)
+	}
+	return deps, nil
+}
+
+// ParseGoMod returns the modules a go.mod requires directly. Indirect
+// requirements and modules that are replaced are left out.
+func ParseGoMod(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	replaced := make(map[string]bool)
+	inRequire, inReplace := false, false
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == ")":
+			inRequire, inReplace = false, false
+			continue
+		case line == "require (":
+			inRequire = true
+			continue
+		case line == "replace (":
+			inReplace = true
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case strings.HasPrefix(line, "replace "):
+			if f := strings.Fields(strings.TrimPrefix(line, "replace ")); len(f) > 0 {
+				replaced[f[0]] = true
+			}
+			continue
+		case inReplace:
+			if f := strings.Fields(line); len(f) > 0 {
+				replaced[f[0]] = true
+			}
+			continue
+		case !inRequire:
+			continue
+		}
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) < 2 || strings.HasPrefix(f[0], "//") {
+			continue
+		}
+		deps = append(deps, Dependency{Name: f[0], Version: f[1], Spec: f[1], Ecosystem: EcosystemGo, Manifest: GoMod})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	kept := deps[:0]
+	for _, d := range deps {
+		if !replaced[d.Name] {
+			kept = append(kept, d)
+		}
+	}
+	return kept, nil
+}
+
+// ParsePackageJSON returns a package.json's dependencies and
+// devDependencies. Versions that aren't a plain or ^/~ ranged version, such
+// as git URLs or workspace references, are left out.
+func ParsePackageJSON(data []byte) ([]Dependency, error) {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	add := func(m map[string]string, dev bool) {
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			spec := strings.TrimSpace(m[name])
+			version := strings.TrimLeft(spec, "^~=v")
+			if _, ok := parseVersion(version); !ok {
+				continue
+			}
+			deps = append(deps, Dependency{Name: name, Version: version, Spec: spec, Ecosystem: EcosystemNPM, Manifest: PackageJSON, Dev: dev})
+		}
+	}
+	add(pkg.Dependencies, false)
+	add(pkg.DevDependencies, true)
+	return deps, nil
+}
+
+// requirementPattern matches a pinned requirement such as requests==2.31.0
+// or django[argon2]>=4.2.
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*(==|>=|~=)\s*([0-9][0-9A-Za-z.]*)`)
+
+// ParseRequirements returns the requirements in a requirements.txt that
+// name a version with ==, >= or ~=. Unversioned requirements, options and
+// includes are left out.
+func ParseRequirements(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		m := requirementPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		deps = append(deps, Dependency{Name: m[1], Version: m[3], Spec: m[2] + m[3], Ecosystem: EcosystemPyPI, Manifest: Requirements, line: m[0]})
+	}
+	return deps, scanner.Err()
+}
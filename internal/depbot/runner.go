@@ -0,0 +1,250 @@
+package depbot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// agentID is the actor recorded for the bot's beads and actions.
+const agentID = "depbot"
+
+// ProjectResolver provides the projects the runner scans.
+type ProjectResolver interface {
+	ListProjectIDs() []string
+	GetProject(projectID string) (*models.Project, error)
+}
+
+// BeadWriter provides bead read/write operations.
+type BeadWriter interface {
+	CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error)
+	GetBeadsByProject(projectID string) ([]*models.Bead, error)
+}
+
+// ActionExecutor runs actions on a project; *actions.Router implements it.
+type ActionExecutor interface {
+	Execute(ctx context.Context, env *actions.ActionEnvelope, actx actions.ActionContext) ([]actions.Result, error)
+}
+
+// Options choose what the runner does.
+type Options struct {
+	// Projects limits the scan to these project IDs; empty scans all.
+	Projects []string
+	// BranchLevels are the update levels, e.g. patch and minor, for which
+	// the runner applies the updates itself and opens a pull request.
+	// Updates of other levels are left to agents.
+	BranchLevels []string
+}
+
+// Runner periodically scans projects for dependency updates and files a
+// bead per project and update level that doesn't already have one open.
+type Runner struct {
+	projects ProjectResolver
+	beads    BeadWriter
+	source   VersionSource
+	executor ActionExecutor
+	opts     Options
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewRunner creates a dependency runner that looks versions up in the
+// public registries. interval defaults to 24 hours if <= 0.
+func NewRunner(projects ProjectResolver, beads BeadWriter, interval time.Duration, opts Options) *Runner {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return &Runner{
+		projects: projects,
+		beads:    beads,
+		source:   NewRegistrySource(),
+		opts:     opts,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// SetVersionSource replaces the registry lookups.
+func (r *Runner) SetVersionSource(s VersionSource) {
+	r.source = s
+}
+
+// SetActionExecutor sets the action router used to open update branches.
+// Without one, or without Options.BranchLevels, the runner only files
+// beads.
+func (r *Runner) SetActionExecutor(e ActionExecutor) {
+	r.executor = e
+}
+
+// Start runs the scan at the configured interval until the context is
+// cancelled or Stop is called.
+func (r *Runner) Start(ctx context.Context) {
+	log.Printf("[DepBot] Starting with %s interval", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.sweep(ctx)
+		}
+	}
+}
+
+// Stop signals the runner to exit its loop.
+func (r *Runner) Stop() {
+	close(r.stopCh)
+}
+
+func (r *Runner) sweep(ctx context.Context) {
+	projectIDs := r.opts.Projects
+	if len(projectIDs) == 0 {
+		projectIDs = r.projects.ListProjectIDs()
+	}
+	for _, pid := range projectIDs {
+		proj, err := r.projects.GetProject(pid)
+		if err != nil || proj == nil || proj.WorkDir == "" {
+			continue
+		}
+		if err := r.ScanProject(ctx, proj); err != nil {
+			log.Printf("[DepBot] project %s: %v", pid, err)
+		}
+	}
+}
+
+// FindUpdates returns the available updates of a project's dependencies.
+// Dependencies whose latest release can't be looked up are skipped.
+func (r *Runner) FindUpdates(ctx context.Context, workDir string) ([]Update, error) {
+	deps, err := Scan(workDir)
+	if err != nil {
+		return nil, err
+	}
+	var updates []Update
+	for _, dep := range deps {
+		latest, err := r.source.Latest(ctx, dep)
+		if err != nil {
+			log.Printf("[DepBot] %s %s: %v", dep.Ecosystem, dep.Name, err)
+			continue
+		}
+		if level := UpdateLevel(dep.Version, latest); level != "" {
+			updates = append(updates, Update{Dependency: dep, Latest: latest, Level: level})
+		}
+	}
+	return updates, nil
+}
+
+// ScanProject files a bead for each update level with available updates
+// that has no open bead yet, and opens update branches for the levels in
+// Options.BranchLevels.
+func (r *Runner) ScanProject(ctx context.Context, proj *models.Project) error {
+	updates, err := r.FindUpdates(ctx, proj.WorkDir)
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	existing, err := r.beads.GetBeadsByProject(proj.ID)
+	if err != nil {
+		return fmt.Errorf("list beads: %w", err)
+	}
+	openTitles := make(map[string]bool, len(existing))
+	for _, b := range existing {
+		if b.Status == models.BeadStatusOpen || b.Status == models.BeadStatusInProgress || b.Status == models.BeadStatusBlocked {
+			openTitles[b.Title] = true
+		}
+	}
+
+	byLevel := make(map[string][]Update)
+	for _, u := range updates {
+		byLevel[u.Level] = append(byLevel[u.Level], u)
+	}
+	for _, level := range Levels {
+		group := byLevel[level]
+		if len(group) == 0 {
+			continue
+		}
+		title := beadTitle(level)
+		if openTitles[title] {
+			continue
+		}
+		bead, err := r.beads.CreateBead(title, buildDescription(level, group), levelPriority(level), "task", proj.ID)
+		if err != nil {
+			log.Printf("[DepBot] Failed to file %s updates for %s: %v", level, proj.ID, err)
+			continue
+		}
+		log.Printf("[DepBot] Filed bead %s for %d %s updates in %s", bead.ID, len(group), level, proj.ID)
+
+		if r.executor != nil && containsLevel(r.opts.BranchLevels, level) {
+			if err := r.openBranch(ctx, proj, bead.ID, level, group); err != nil {
+				log.Printf("[DepBot] Update branch for %s not opened: %v", bead.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func beadTitle(level string) string {
+	return fmt.Sprintf("[deps] %s dependency updates", strings.ToUpper(level[:1])+level[1:])
+}
+
+// levelPriority files major updates, which may need code changes, above
+// minor and patch ones.
+func levelPriority(level string) models.BeadPriority {
+	if level == LevelMajor {
+		return models.BeadPriorityP2
+	}
+	return models.BeadPriorityP3
+}
+
+func containsLevel(levels []string, level string) bool {
+	for _, l := range levels {
+		if strings.EqualFold(l, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDescription produces the bead body for a group of updates.
+func buildDescription(level string, updates []Update) string {
+	sort.Slice(updates, func(i, j int) bool {
+		if updates[i].Manifest != updates[j].Manifest {
+			return updates[i].Manifest < updates[j].Manifest
+		}
+		return updates[i].Name < updates[j].Name
+	})
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %d %s dependency updates\n\n", len(updates), level))
+	sb.WriteString("| Manifest | Dependency | Current | Latest |\n|---|---|---|---|\n")
+	for _, u := range updates {
+		name := u.Name
+		if u.Dev {
+			name += " (dev)"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", u.Manifest, name, u.Version, u.Latest))
+	}
+	sb.WriteString("\n---\n\n")
+	sb.WriteString("Steps:\n")
+	sb.WriteString("1. Update each dependency to its latest version and refresh the lock files\n")
+	if level == LevelMajor {
+		sb.WriteString("2. Read each dependency's release notes and adapt the code to its breaking changes\n")
+	} else {
+		sb.WriteString("2. Fix anything the updates break\n")
+	}
+	sb.WriteString("3. Build and run the tests, then open a pull request\n")
+	sb.WriteString("4. Close this bead once the updates are merged\n\n")
+	sb.WriteString("*Auto-filed by the dependency bot. A new bead will be filed for updates still pending after this one is closed.*\n")
+	return sb.String()
+}
@@ -0,0 +1,157 @@
+package depbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Update levels, from the most to the least disruptive.
+const (
+	LevelMajor = "major"
+	LevelMinor = "minor"
+	LevelPatch = "patch"
+)
+
+// Levels lists the update levels in order.
+var Levels = []string{LevelMajor, LevelMinor, LevelPatch}
+
+// version is a parsed release version. Pre-releases are not parsed.
+type version struct {
+	major, minor, patch int
+}
+
+// parseVersion parses versions such as v1.2.3, 1.2 or 4, ignoring Go
+// +incompatible suffixes. Pre-release and pseudo-versions don't parse.
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSuffix(s, "+incompatible"), "v")
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return version{}, false
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return version{}, false
+		}
+		n[i] = v
+	}
+	return version{n[0], n[1], n[2]}, true
+}
+
+// UpdateLevel returns how far latest is ahead of current: major, minor or
+// patch, or "" when it isn't ahead or either version doesn't parse.
+func UpdateLevel(current, latest string) string {
+	c, ok1 := parseVersion(current)
+	l, ok2 := parseVersion(latest)
+	switch {
+	case !ok1 || !ok2:
+		return ""
+	case l.major != c.major:
+		if l.major > c.major {
+			return LevelMajor
+		}
+	case l.minor != c.minor:
+		if l.minor > c.minor {
+			return LevelMinor
+		}
+	case l.patch > c.patch:
+		return LevelPatch
+	}
+	return ""
+}
+
+// Update is an available update of a dependency.
+type Update struct {
+	Dependency
+	Latest string `json:"latest"`
+	Level  string `json:"level"`
+}
+
+// VersionSource finds the latest release of a dependency.
+type VersionSource interface {
+	Latest(ctx context.Context, dep Dependency) (string, error)
+}
+
+// RegistrySource looks up latest releases in the public package
+// registries: the Go module proxy, the npm registry and PyPI.
+type RegistrySource struct {
+	GoProxy string
+	NPM     string
+	PyPI    string
+	Client  *http.Client
+}
+
+// NewRegistrySource returns a RegistrySource using the public registries.
+func NewRegistrySource() *RegistrySource {
+	return &RegistrySource{
+		GoProxy: "https://proxy.golang.org",
+		NPM:     "https://registry.npmjs.org",
+		PyPI:    "https://pypi.org/pypi",
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Latest returns the latest release of dep in its ecosystem's registry.
+func (s *RegistrySource) Latest(ctx context.Context, dep Dependency) (string, error) {
+	switch dep.Ecosystem {
+	case EcosystemGo:
+		var info struct {
+			Version string `json:"Version"`
+		}
+		err := s.getJSON(ctx, s.GoProxy+"/"+escapeModulePath(dep.Name)+"/@latest", &info)
+		return info.Version, err
+	case EcosystemNPM:
+		var info struct {
+			Version string `json:"version"`
+		}
+		err := s.getJSON(ctx, s.NPM+"/"+strings.Replace(dep.Name, "/", "%2F", 1)+"/latest", &info)
+		return info.Version, err
+	case EcosystemPyPI:
+		var info struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		err := s.getJSON(ctx, s.PyPI+"/"+url.PathEscape(dep.Name)+"/json", &info)
+		return info.Info.Version, err
+	}
+	return "", fmt.Errorf("unknown ecosystem %q", dep.Ecosystem)
+}
+
+func (s *RegistrySource) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// escapeModulePath applies the module proxy's case encoding: each upper
+// case letter becomes '!' and its lower case form.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
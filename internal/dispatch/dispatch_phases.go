@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/agent"
+	"github.com/jordanhubbard/loom/internal/beads"
 	"github.com/jordanhubbard/loom/internal/observability"
 	"github.com/jordanhubbard/loom/internal/worker"
 	"github.com/jordanhubbard/loom/internal/workflow"
@@ -24,20 +25,10 @@ type candidateSelection struct {
 }
 
 // sortReadyBeads sorts beads by priority (ascending) then by recency (descending).
+// priority gives each bead's effective priority; nil uses the beads' own.
 // Nil beads are pushed to the end.
-func sortReadyBeads(ready []*models.Bead) {
-	sort.SliceStable(ready, func(i, j int) bool {
-		if ready[i] == nil {
-			return false
-		}
-		if ready[j] == nil {
-			return true
-		}
-		if ready[i].Priority != ready[j].Priority {
-			return ready[i].Priority < ready[j].Priority
-		}
-		return ready[i].UpdatedAt.After(ready[j].UpdatedAt)
-	})
+func sortReadyBeads(ready []*models.Bead, priority func(*models.Bead) models.BeadPriority) {
+	beads.SortByPriority(ready, priority)
 }
 
 // filterIdleAgents takes a list of idle agents and returns only those with
@@ -50,11 +41,9 @@ func (d *Dispatcher) filterIdleAgents(idleAgents []*models.Agent) []*models.Agen
 			continue
 		}
 		needsProvider := candidateAgent.ProviderID == "" ||
-			!d.providers.IsActive(candidateAgent.ProviderID)
+			!d.providers.Available(candidateAgent.ProviderID)
 		if needsProvider {
-			activeProviders := d.providers.ListActive()
-			if len(activeProviders) > 0 {
-				best := activeProviders[0]
+			if best := d.providers.SelectProvider(candidateAgent.ProjectID); best != nil {
 				prev := candidateAgent.ProviderID
 				candidateAgent.ProviderID = best.Config.ID
 				if prev != "" {
@@ -77,6 +66,47 @@ func (d *Dispatcher) filterIdleAgents(idleAgents []*models.Agent) []*models.Agen
 	return filtered
 }
 
+// checkBudget runs the budget check, if one is installed.
+func (d *Dispatcher) checkBudget(projectID, agentID, providerID string) (bool, string) {
+	d.mu.RLock()
+	check := d.budgetCheck
+	d.mu.RUnlock()
+	if check == nil {
+		return true, ""
+	}
+	return check(projectID, agentID, providerID)
+}
+
+// wipLimitReached reports whether claiming the bead would take its board
+// column past its WIP limit. Beads already in progress are not checked.
+func (d *Dispatcher) wipLimitReached(b *models.Bead) bool {
+	d.mu.RLock()
+	check := d.wipCheck
+	d.mu.RUnlock()
+	if check == nil || b.Status == models.BeadStatusInProgress {
+		return false
+	}
+	if err := check(b, models.BeadStatusInProgress); err != nil {
+		log.Printf("[Dispatcher] Not claiming bead %s: %v", b.ID, err)
+		return true
+	}
+	return false
+}
+
+// filterAgentsByBudget drops agents that have exhausted their own budget so
+// that other agents can pick up the work.
+func (d *Dispatcher) filterAgentsByBudget(agents []*models.Agent) []*models.Agent {
+	filtered := agents[:0]
+	for _, a := range agents {
+		if ok, reason := d.checkBudget("", a.ID, ""); !ok {
+			log.Printf("[Dispatcher] Skipping agent %s: %s", a.Name, reason)
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	return filtered
+}
+
 // buildAgentMaps builds ID-keyed maps for idle agents and all project agents.
 func (d *Dispatcher) buildAgentMaps(projectID string, idleAgents []*models.Agent) (idleByID, allByID map[string]*models.Agent) {
 	idleByID = make(map[string]*models.Agent, len(idleAgents))
@@ -117,6 +147,19 @@ func beadSkipCheck(b *models.Bead, maxHops int) (skip bool, reason string) {
 		case "completed":
 			return true, "terminal_completed"
 		}
+		// An operator has paused the bead's workflow execution, or it is
+		// waiting for parallel branches to finish, for a person to approve
+		// it, or for CI on its branch.
+		switch b.Context["workflow_status"] {
+		case string(workflow.ExecutionStatusPaused):
+			return true, "workflow_paused"
+		case string(workflow.ExecutionStatusBlocked):
+			return true, "workflow_blocked"
+		case string(workflow.ExecutionStatusAwaitingApproval):
+			return true, "workflow_awaiting_approval"
+		case string(workflow.ExecutionStatusAwaitingCI):
+			return true, "workflow_awaiting_ci"
+		}
 	}
 
 	// Skip beads that recently failed (cooldown)
@@ -249,8 +292,10 @@ func matchAssignedAgent(b *models.Bead, idleByID, allByID map[string]*models.Age
 	return idle, false, ""
 }
 
-// matchAgentForBead picks the best idle agent for a bead, considering persona
-// hints and preferring the engineering-manager role as a default.
+// matchAgentForBead picks the best idle agent for a bead. An explicit persona
+// hint wins; otherwise agents whose capabilities cover the most bead tags are
+// preferred, then engineering managers, then any project-compatible agent.
+// Ties are broken round-robin.
 func (d *Dispatcher) matchAgentForBead(b *models.Bead, idleAgents []*models.Agent) *models.Agent {
 	// Try persona-based routing first
 	personaHint := d.personaMatcher.ExtractPersonaHint(b)
@@ -265,23 +310,38 @@ func (d *Dispatcher) matchAgentForBead(b *models.Bead, idleAgents []*models.Agen
 			b.ID, personaHint)
 	}
 
-	// Prefer engineering manager, fallback to any project-compatible agent
-	var matchedAgent, fallbackAgent *models.Agent
+	var compatible, managers []*models.Agent
 	for _, a := range idleAgents {
 		if a.ProjectID == b.ProjectID || a.ProjectID == "" || b.ProjectID == "" {
-			if fallbackAgent == nil {
-				fallbackAgent = a
-			}
+			compatible = append(compatible, a)
 			if rolesMatch(a.Role, "engineering-manager") {
-				matchedAgent = a
-				break
+				managers = append(managers, a)
 			}
 		}
 	}
-	if matchedAgent == nil {
-		matchedAgent = fallbackAgent
+
+	if skilled := agent.BestCapabilityMatches(compatible, b.Tags); len(skilled) > 0 {
+		matchedAgent := d.nextAgent(skilled)
+		log.Printf("[Dispatcher] Matched bead %s to agent %s via capabilities (tags %v)",
+			b.ID, matchedAgent.Name, b.Tags)
+		return matchedAgent
+	}
+	if len(managers) > 0 {
+		return d.nextAgent(managers)
 	}
-	return matchedAgent
+	return d.nextAgent(compatible)
+}
+
+// nextAgent returns the next of several equally suitable agents in
+// round-robin order, or nil if there are none.
+func (d *Dispatcher) nextAgent(agents []*models.Agent) *models.Agent {
+	if len(agents) == 0 {
+		return nil
+	}
+	if len(agents) == 1 {
+		return agents[0]
+	}
+	return agents[(d.agentCursor.Add(1)-1)%uint64(len(agents))]
 }
 
 // selectCandidate iterates through ready beads and picks the first one that
@@ -339,6 +399,11 @@ func (d *Dispatcher) selectCandidate(
 			continue
 		}
 
+		if d.wipLimitReached(b) {
+			skippedReasons["wip_limit"]++
+			continue
+		}
+
 		// Enable auto-redispatch for open/in-progress beads
 		if b.Status == models.BeadStatusOpen || b.Status == models.BeadStatusInProgress {
 			if b.Context == nil {
@@ -468,15 +533,14 @@ func (d *Dispatcher) selectCandidate(
 	return candidateSelection{SkippedReasons: skippedReasons}
 }
 
-// selectProviderForTask chooses a provider based on task complexity using
-// round-robin across healthy providers. Returns the selected provider ID
-// or empty string if none available.
+// selectProviderForTask chooses a provider according to the bead's project
+// routing policy, skipping providers whose circuit breaker is open. Returns
+// the selected provider ID or empty string if none available.
 func (d *Dispatcher) selectProviderForTask(candidate *models.Bead, ag *models.Agent) string {
-	activeProviders := d.providers.ListActive()
-	if len(activeProviders) == 0 {
-		return ""
+	if p := d.providers.SelectProvider(candidate.ProjectID); p != nil {
+		return p.Config.ID
 	}
-	return activeProviders[0].Config.ID
+	return ""
 }
 
 // claimAndAssign claims the bead for the agent, increments the dispatch count,
@@ -668,6 +732,7 @@ func (d *Dispatcher) processTaskError(candidate *models.Bead, ag *models.Agent,
 	if loopDetected {
 		ctxUpdates["loop_detected_reason"] = loopReason
 		ctxUpdates["loop_detected_at"] = time.Now().UTC().Format(time.RFC3339)
+		ctxUpdates[LoopDetectedByKey] = detectorDispatchAlternation
 	}
 	updates := map[string]interface{}{"context": ctxUpdates}
 	if loopDetected {
@@ -716,6 +781,7 @@ func (d *Dispatcher) processTaskSuccess(candidate *models.Bead, ag *models.Agent
 	if loopDetected {
 		ctxUpdates["loop_detected_reason"] = loopReason
 		ctxUpdates["loop_detected_at"] = time.Now().UTC().Format(time.RFC3339)
+		ctxUpdates[LoopDetectedByKey] = detectorDispatchAlternation
 	}
 
 	updates := map[string]interface{}{"context": ctxUpdates}
@@ -834,8 +900,9 @@ func (d *Dispatcher) applyLoopMetadata(ctxUpdates map[string]string, candidate *
 }
 
 // filterBeadsByReadiness applies project readiness checks to a list of beads.
-// In block mode, only beads from ready projects are returned.
-// In warn mode, all beads pass through (readiness is just logged).
+// Beads of projects in block mode are returned only if their project is
+// ready; in warn mode all beads pass through (readiness is just logged).
+// mode applies to projects that do not set their own.
 func (d *Dispatcher) filterBeadsByReadiness(
 	ctx context.Context,
 	ready []*models.Bead,
@@ -847,36 +914,21 @@ func (d *Dispatcher) filterBeadsByReadiness(
 	}
 
 	projectReadiness := make(map[string]bool)
-
-	if mode == ReadinessBlock {
-		filtered := make([]*models.Bead, 0, len(ready))
-		for _, bead := range ready {
-			if bead == nil {
-				filtered = append(filtered, bead)
-				continue
-			}
-			if _, ok := projectReadiness[bead.ProjectID]; !ok {
-				okReady, _ := readinessCheck(ctx, bead.ProjectID)
-				projectReadiness[bead.ProjectID] = okReady
-			}
-			if projectReadiness[bead.ProjectID] {
-				filtered = append(filtered, bead)
-			}
-		}
-		return filtered
-	}
-
-	// Warn mode: just evaluate readiness (for logging), return all beads
+	filtered := make([]*models.Bead, 0, len(ready))
 	for _, bead := range ready {
 		if bead == nil {
+			filtered = append(filtered, bead)
 			continue
 		}
 		if _, ok := projectReadiness[bead.ProjectID]; !ok {
 			okReady, _ := readinessCheck(ctx, bead.ProjectID)
 			projectReadiness[bead.ProjectID] = okReady
 		}
+		if projectReadiness[bead.ProjectID] || d.readinessModeFor(bead.ProjectID, mode) != ReadinessBlock {
+			filtered = append(filtered, bead)
+		}
 	}
-	return ready
+	return filtered
 }
 
 // advanceWorkflowOnFailure reports a task failure to the workflow engine.
@@ -1009,7 +1061,7 @@ func (d *Dispatcher) checkProjectReadiness(ctx context.Context, projectID string
 	}
 
 	readyOK, issues := readinessCheck(ctx, projectID)
-	if !readyOK && readinessMode == ReadinessBlock {
+	if !readyOK && d.readinessModeFor(projectID, readinessMode) == ReadinessBlock {
 		reason := "project readiness failed"
 		if len(issues) > 0 {
 			reason = fmt.Sprintf("project readiness failed: %s", strings.Join(issues, "; "))
@@ -2,6 +2,7 @@ package dispatch
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -17,7 +18,7 @@ func TestSortReadyBeads_ByPriority(t *testing.T) {
 		{ID: "p0", Priority: 0, UpdatedAt: time.Now()},
 		{ID: "p1", Priority: 1, UpdatedAt: time.Now()},
 	}
-	sortReadyBeads(beads)
+	sortReadyBeads(beads, nil)
 	if beads[0].ID != "p0" || beads[1].ID != "p1" || beads[2].ID != "p2" {
 		t.Errorf("Expected [p0, p1, p2], got [%s, %s, %s]", beads[0].ID, beads[1].ID, beads[2].ID)
 	}
@@ -29,12 +30,26 @@ func TestSortReadyBeads_SamePriorityByRecency(t *testing.T) {
 		{ID: "old", Priority: 1, UpdatedAt: now.Add(-1 * time.Hour)},
 		{ID: "new", Priority: 1, UpdatedAt: now},
 	}
-	sortReadyBeads(beads)
+	sortReadyBeads(beads, nil)
 	if beads[0].ID != "new" {
 		t.Errorf("Expected 'new' first (more recent), got %s", beads[0].ID)
 	}
 }
 
+func TestSortReadyBeads_EffectivePriority(t *testing.T) {
+	now := time.Now()
+	beads := []*models.Bead{
+		{ID: "urgent", Priority: 1, CreatedAt: now, UpdatedAt: now},
+		{ID: "aged", Priority: 3, CreatedAt: now.Add(-72 * time.Hour), UpdatedAt: now.Add(-72 * time.Hour)},
+	}
+	sortReadyBeads(beads, func(b *models.Bead) models.BeadPriority {
+		return b.EffectivePriority(24*time.Hour, now)
+	})
+	if beads[0].ID != "aged" {
+		t.Errorf("Expected the aged bead first, got %s", beads[0].ID)
+	}
+}
+
 func TestSortReadyBeads_NilsToEnd(t *testing.T) {
 	now := time.Now()
 	beads := []*models.Bead{
@@ -43,7 +58,7 @@ func TestSortReadyBeads_NilsToEnd(t *testing.T) {
 		nil,
 		{ID: "b", Priority: 0, UpdatedAt: now},
 	}
-	sortReadyBeads(beads)
+	sortReadyBeads(beads, nil)
 	if beads[0].ID != "b" {
 		t.Errorf("Expected 'b' (priority 0) first, got %v", beads[0])
 	}
@@ -56,13 +71,13 @@ func TestSortReadyBeads_NilsToEnd(t *testing.T) {
 }
 
 func TestSortReadyBeads_Empty(t *testing.T) {
-	sortReadyBeads(nil)
-	sortReadyBeads([]*models.Bead{})
+	sortReadyBeads(nil, nil)
+	sortReadyBeads([]*models.Bead{}, nil)
 }
 
 func TestSortReadyBeads_AllNil(t *testing.T) {
 	beads := []*models.Bead{nil, nil, nil}
-	sortReadyBeads(beads)
+	sortReadyBeads(beads, nil)
 	for i, b := range beads {
 		if b != nil {
 			t.Errorf("Expected nil at index %d", i)
@@ -72,7 +87,7 @@ func TestSortReadyBeads_AllNil(t *testing.T) {
 
 func TestSortReadyBeads_SingleElement(t *testing.T) {
 	beads := []*models.Bead{{ID: "only", Priority: 1, UpdatedAt: time.Now()}}
-	sortReadyBeads(beads)
+	sortReadyBeads(beads, nil)
 	if beads[0].ID != "only" {
 		t.Errorf("Expected 'only', got %s", beads[0].ID)
 	}
@@ -109,6 +124,30 @@ func TestBeadSkipCheck_CooldownAfterFailure(t *testing.T) {
 	}
 }
 
+func TestBeadSkipCheck_WorkflowPaused(t *testing.T) {
+	b := &models.Bead{
+		ID:      "b1",
+		Type:    "task",
+		Context: map[string]string{"workflow_status": "paused"},
+	}
+	skip, reason := beadSkipCheck(b, 20)
+	if !skip || reason != "workflow_paused" {
+		t.Errorf("Expected (true, workflow_paused), got (%v, %s)", skip, reason)
+	}
+
+	b.Context["workflow_status"] = "blocked"
+	skip, reason = beadSkipCheck(b, 20)
+	if !skip || reason != "workflow_blocked" {
+		t.Errorf("Expected (true, workflow_blocked), got (%v, %s)", skip, reason)
+	}
+
+	b.Context["workflow_status"] = "awaiting_approval"
+	skip, reason = beadSkipCheck(b, 20)
+	if !skip || reason != "workflow_awaiting_approval" {
+		t.Errorf("Expected (true, workflow_awaiting_approval), got (%v, %s)", skip, reason)
+	}
+}
+
 func TestBeadSkipCheck_CooldownExpired(t *testing.T) {
 	b := &models.Bead{
 		ID:   "b1",
@@ -321,6 +360,54 @@ func TestMatchAgentForBead_PrefersEngineeringManager(t *testing.T) {
 	}
 }
 
+func TestMatchAgentForBead_PrefersCapabilities(t *testing.T) {
+	d := &Dispatcher{personaMatcher: NewPersonaMatcher()}
+	b := &models.Bead{ID: "b1", ProjectID: "proj-1", Tags: []string{"Go", "postgres"}}
+	agents := []*models.Agent{
+		{ID: "a1", Role: "Engineering Manager", ProjectID: "proj-1"},
+		{ID: "a2", Role: "Frontend Engineer", ProjectID: "proj-1", Capabilities: []string{"react", "go"}},
+		{ID: "a3", Role: "Backend Engineer", ProjectID: "proj-1", Capabilities: []string{"go", "postgres"}},
+		{ID: "a4", Role: "Backend Engineer", ProjectID: "proj-2", Capabilities: []string{"go", "postgres"}},
+	}
+
+	ag := d.matchAgentForBead(b, agents)
+	if ag == nil || ag.ID != "a3" {
+		t.Errorf("Expected best-skilled agent a3, got %v", ag)
+	}
+
+	// No capability overlap falls back to the engineering manager.
+	b.Tags = []string{"rust"}
+	ag = d.matchAgentForBead(b, agents)
+	if ag == nil || ag.ID != "a1" {
+		t.Errorf("Expected fallback to engineering manager a1, got %v", ag)
+	}
+}
+
+func TestMatchAgentForBead_RoundRobin(t *testing.T) {
+	d := &Dispatcher{personaMatcher: NewPersonaMatcher()}
+	b := &models.Bead{ID: "b1", ProjectID: "proj-1"}
+	agents := []*models.Agent{
+		{ID: "a1", Role: "Backend Engineer", ProjectID: "proj-1"},
+		{ID: "a2", Role: "Backend Engineer", ProjectID: "proj-1"},
+		{ID: "a3", Role: "Backend Engineer", ProjectID: "proj-1"},
+	}
+
+	seen := map[string]int{}
+	for i := 0; i < 6; i++ {
+		ag := d.matchAgentForBead(b, agents)
+		if ag == nil {
+			t.Fatal("Expected an agent")
+		}
+		seen[ag.ID]++
+	}
+	for _, a := range agents {
+		if seen[a.ID] != 2 {
+			t.Errorf("Expected each agent picked twice, got %v", seen)
+			break
+		}
+	}
+}
+
 func TestMatchAgentForBead_FallbackToAny(t *testing.T) {
 	d := &Dispatcher{personaMatcher: NewPersonaMatcher()}
 	b := &models.Bead{ID: "b1", ProjectID: "proj-1"}
@@ -484,6 +571,36 @@ func TestFilterBeadsByReadiness_BlockMode_CachesPerProject(t *testing.T) {
 	}
 }
 
+func TestFilterBeadsByReadiness_ProjectMode(t *testing.T) {
+	d := &Dispatcher{}
+	d.SetProjectReadinessMode(func(pid string) string {
+		if pid == "strict" {
+			return "block"
+		}
+		return ""
+	})
+	beads := []*models.Bead{
+		{ID: "b1", ProjectID: "strict"},
+		{ID: "b2", ProjectID: "relaxed"},
+	}
+	check := func(ctx context.Context, pid string) (bool, []string) {
+		return false, []string{"not ready"}
+	}
+	result := d.filterBeadsByReadiness(context.Background(), beads, check, ReadinessWarn)
+	if len(result) != 1 || result[0].ID != "b2" {
+		t.Errorf("Expected only the warn-mode project's bead, got %v", result)
+	}
+
+	d.readinessCheck = check
+	d.readinessMode = ReadinessWarn
+	if blocked, _ := d.checkProjectReadiness(context.Background(), "strict"); !blocked {
+		t.Error("Expected the block-mode project to be blocked")
+	}
+	if blocked, _ := d.checkProjectReadiness(context.Background(), "relaxed"); blocked {
+		t.Error("Expected the warn-mode project not to be blocked")
+	}
+}
+
 // --- checkProjectReadiness ---
 
 func TestCheckProjectReadiness_NoCheck(t *testing.T) {
@@ -1109,3 +1226,45 @@ func TestProcessTaskSuccess_CompletedTakesPrecedenceOverLoopDetected(t *testing.
 		t.Errorf("completed: expected status=closed even if loopDetected would be true, got %v", updates["status"])
 	}
 }
+
+// --- filterAgentsByBudget ---
+
+func TestFilterAgentsByBudget(t *testing.T) {
+	d := &Dispatcher{}
+	agents := []*models.Agent{{ID: "a1", Name: "one"}, {ID: "a2", Name: "two"}}
+
+	if got := d.filterAgentsByBudget(agents); len(got) != 2 {
+		t.Fatalf("expected all agents without a budget check, got %d", len(got))
+	}
+
+	d.SetBudgetCheck(func(projectID, agentID, providerID string) (bool, string) {
+		if agentID == "a1" {
+			return false, "agent a1 daily token budget exhausted"
+		}
+		return true, ""
+	})
+	got := d.filterAgentsByBudget([]*models.Agent{{ID: "a1", Name: "one"}, {ID: "a2", Name: "two"}})
+	if len(got) != 1 || got[0].ID != "a2" {
+		t.Errorf("expected only a2, got %+v", got)
+	}
+}
+
+// --- wipLimitReached ---
+
+func TestWIPLimitReached(t *testing.T) {
+	d := &Dispatcher{}
+	open := &models.Bead{ID: "b1", Status: models.BeadStatusOpen}
+	if d.wipLimitReached(open) {
+		t.Fatal("expected no limit without a WIP check")
+	}
+
+	d.SetWIPCheck(func(b *models.Bead, to models.BeadStatus) error {
+		return errors.New("column In Progress is full")
+	})
+	if !d.wipLimitReached(open) {
+		t.Error("expected open bead to be held back")
+	}
+	if d.wipLimitReached(&models.Bead{ID: "b2", Status: models.BeadStatusInProgress}) {
+		t.Error("in-progress bead should not be checked")
+	}
+}
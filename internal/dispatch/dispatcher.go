@@ -9,16 +9,19 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jordanhubbard/loom/internal/agent"
 	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/comments"
 	"github.com/jordanhubbard/loom/internal/containers"
 	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/internal/eventbus"
 	"github.com/jordanhubbard/loom/internal/gitops"
 	"github.com/jordanhubbard/loom/internal/memory"
+	"github.com/jordanhubbard/loom/internal/metrics"
 	"github.com/jordanhubbard/loom/internal/project"
 	"github.com/jordanhubbard/loom/internal/provider"
 	"github.com/jordanhubbard/loom/internal/swarm"
@@ -29,6 +32,7 @@ import (
 	"github.com/jordanhubbard/loom/pkg/models"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type StatusState string
@@ -79,9 +83,18 @@ type Dispatcher struct {
 	autoBugRouter   *AutoBugRouter
 	readinessCheck  func(context.Context, string) (bool, []string)
 	readinessMode   ReadinessMode
+	projectMode     func(projectID string) string
+	budgetCheck     func(projectID, agentID, providerID string) (bool, string)
+	wipCheck        func(b *models.Bead, to models.BeadStatus) error
+	typeWorkflow    func(beadType string) string
 	escalator       Escalator
 	maxDispatchHops int
 	loopDetector    *LoopDetector
+	metrics         *metrics.Metrics
+
+	// agentCursor rotates through equally suitable idle agents so work is
+	// spread round-robin rather than always landing on the first one.
+	agentCursor atomic.Uint64
 
 	// Commit serialization (Gap #2)
 	commitLock        sync.RWMutex       // Global commit lock
@@ -337,12 +350,47 @@ func (d *Dispatcher) SetMaxDispatchHops(maxHops int) {
 	d.maxDispatchHops = maxHops
 }
 
+// SetLoopThresholds installs the lookup of a project's loop detection
+// thresholds. Set it before dispatching starts.
+func (d *Dispatcher) SetLoopThresholds(lookup func(projectID string) LoopThresholds) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loopDetector.SetThresholdLookup(lookup)
+}
+
 func (d *Dispatcher) SetReadinessCheck(check func(context.Context, string) (bool, []string)) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.readinessCheck = check
 }
 
+// SetBudgetCheck installs the check that pauses dispatch when a project,
+// agent, or provider has exhausted its token or cost budget. The check
+// returns false and a reason when work must not be dispatched.
+func (d *Dispatcher) SetBudgetCheck(check func(projectID, agentID, providerID string) (bool, string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.budgetCheck = check
+}
+
+// SetWIPCheck installs the check that keeps beads in a project's board
+// column from exceeding its WIP limit. The check returns an error when a
+// bead must not move to the given status.
+func (d *Dispatcher) SetWIPCheck(check func(b *models.Bead, to models.BeadStatus) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.wipCheck = check
+}
+
+// SetTypeWorkflow installs the lookup of a bead type's default workflow
+// type. A bead whose type has one starts that workflow instead of the one
+// guessed from its title and tags.
+func (d *Dispatcher) SetTypeWorkflow(lookup func(beadType string) string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.typeWorkflow = lookup
+}
+
 func (d *Dispatcher) SetReadinessMode(mode ReadinessMode) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -352,6 +400,48 @@ func (d *Dispatcher) SetReadinessMode(mode ReadinessMode) {
 	d.readinessMode = mode
 }
 
+// SetProjectReadinessMode sets the lookup of projects' own readiness modes.
+// A project whose lookup returns neither block nor warn uses the global
+// mode.
+func (d *Dispatcher) SetProjectReadinessMode(lookup func(projectID string) string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.projectMode = lookup
+}
+
+// readinessModeFor returns a project's readiness mode: its own if it sets
+// one, mode otherwise.
+func (d *Dispatcher) readinessModeFor(projectID string, mode ReadinessMode) ReadinessMode {
+	d.mu.RLock()
+	lookup := d.projectMode
+	d.mu.RUnlock()
+	if lookup == nil || projectID == "" {
+		return mode
+	}
+	switch own := ReadinessMode(lookup(projectID)); own {
+	case ReadinessBlock, ReadinessWarn:
+		return own
+	}
+	return mode
+}
+
+// SetMetrics sets where the outcome of each dispatch pass is counted.
+func (d *Dispatcher) SetMetrics(m *metrics.Metrics) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.metrics = m
+}
+
+// recordDecision counts the outcome of a DispatchOnce pass.
+func (d *Dispatcher) recordDecision(projectID, decision string) {
+	d.mu.RLock()
+	m := d.metrics
+	d.mu.RUnlock()
+	if m != nil {
+		m.RecordDispatchDecision(projectID, decision)
+	}
+}
+
 // SetLifecycleContext sets the dispatcher's lifecycle context for graceful shutdown.
 // Task goroutines derive their context from this, enabling cancellation propagation
 // when Loom is shutting down.
@@ -464,6 +554,20 @@ func (d *Dispatcher) releaseCommitLock() {
 	d.commitLock.Unlock()
 }
 
+// recordTraceID stores the ID of the trace a bead execution runs under in
+// the bead context, so the trace can be looked up from the bead.
+func (d *Dispatcher) recordTraceID(ctx context.Context, beadID string) {
+	traceID := telemetry.TraceID(ctx)
+	if traceID == "" || d.beads == nil {
+		return
+	}
+	if err := d.beads.UpdateBead(beadID, map[string]interface{}{
+		"context": map[string]string{"trace_id": traceID},
+	}); err != nil {
+		log.Printf("[Dispatcher] Failed to record trace ID for bead %s: %v", beadID, err)
+	}
+}
+
 // DispatchOnce finds at most one ready bead and asks an idle agent to work on it.
 func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*DispatchResult, error) {
 	ctx, span := telemetry.Tracer.Start(ctx, "dispatch.DispatchOnce")
@@ -480,18 +584,21 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 		log.Printf("[Dispatcher] Parked - no active providers")
 		d.setStatus(StatusParked, "no active providers registered")
 		span.SetStatus(codes.Error, "no active providers")
+		d.recordDecision(projectID, "no_providers")
 		return &DispatchResult{Dispatched: false, ProjectID: projectID}, nil
 	}
 
 	ready, err := d.beads.GetReadyBeads(projectID)
 	if err != nil {
 		d.setStatus(StatusParked, "failed to list ready beads")
+		d.recordDecision(projectID, "error")
 		return nil, err
 	}
 
 	// Project-level readiness gate
 	blocked, earlyResult := d.checkProjectReadiness(ctx, projectID)
 	if blocked {
+		d.recordDecision(projectID, "readiness_blocked")
 		return earlyResult, nil
 	}
 
@@ -501,16 +608,25 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	readinessMode := d.readinessMode
 	d.mu.RUnlock()
 	ready = d.filterBeadsByReadiness(ctx, ready, readinessCheck, readinessMode)
-	if readinessMode == ReadinessBlock && len(ready) == 0 {
+	if d.readinessModeFor(projectID, readinessMode) == ReadinessBlock && len(ready) == 0 {
 		d.setStatus(StatusParked, "project readiness failed")
+		d.recordDecision(projectID, "readiness_blocked")
 		return &DispatchResult{Dispatched: false, ProjectID: projectID}, nil
 	}
 
+	if projectID != "" {
+		if ok, reason := d.checkBudget(projectID, "", ""); !ok {
+			d.setStatus(StatusParked, reason)
+			d.recordDecision(projectID, "budget_exhausted")
+			return &DispatchResult{Dispatched: false, ProjectID: projectID, Error: reason}, nil
+		}
+	}
+
 	log.Printf("[Dispatcher] GetReadyBeads returned %d beads for project %s", len(ready), projectID)
 
-	sortReadyBeads(ready)
+	sortReadyBeads(ready, d.beads.EffectivePriority)
 
-	idleAgents := d.filterIdleAgents(d.agents.GetIdleAgentsByProject(projectID))
+	idleAgents := d.filterAgentsByBudget(d.filterIdleAgents(d.agents.GetIdleAgentsByProject(projectID)))
 	idleByID, allByID := d.buildAgentMaps(projectID, idleAgents)
 
 	sel := d.selectCandidate(ctx, ready, idleAgents, idleByID, allByID)
@@ -525,6 +641,7 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 		reasonsJSON, _ := json.Marshal(sel.SkippedReasons)
 		log.Printf("[Dispatcher] No dispatchable beads found (ready: %d, idle agents: %d, skipped: %s)", len(ready), len(idleAgents), string(reasonsJSON))
 		d.setStatus(StatusParked, "no dispatchable beads")
+		d.recordDecision(projectID, "no_candidate")
 		return &DispatchResult{Dispatched: false, ProjectID: projectID}, nil
 	}
 
@@ -536,6 +653,7 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	if _, alreadyRunning := d.inflight[candidate.ID]; alreadyRunning {
 		d.inflightMu.Unlock()
 		log.Printf("[Dispatcher] Bead %s lost inflight race, skipping duplicate dispatch", candidate.ID)
+		d.recordDecision(projectID, "inflight_race")
 		return &DispatchResult{Dispatched: false, ProjectID: projectID}, nil
 	}
 	d.inflight[candidate.ID] = struct{}{}
@@ -554,6 +672,7 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	if ag == nil {
 		releaseInflight()
 		d.setStatus(StatusParked, "no idle agents with active providers")
+		d.recordDecision(selectedProjectID, "no_agent")
 		return &DispatchResult{Dispatched: false, ProjectID: selectedProjectID}, nil
 	}
 
@@ -561,8 +680,16 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	if providerID == "" {
 		releaseInflight()
 		d.setStatus(StatusParked, "no active providers available")
+		d.recordDecision(selectedProjectID, "no_providers")
 		return &DispatchResult{Dispatched: false, ProjectID: selectedProjectID, AgentID: ag.ID}, nil
 	}
+	if ok, reason := d.checkBudget(selectedProjectID, ag.ID, providerID); !ok {
+		releaseInflight()
+		log.Printf("[Dispatcher] Not dispatching bead %s: %s", candidate.ID, reason)
+		d.setStatus(StatusParked, reason)
+		d.recordDecision(selectedProjectID, "budget_exhausted")
+		return &DispatchResult{Dispatched: false, ProjectID: selectedProjectID, AgentID: ag.ID, Error: reason}, nil
+	}
 
 	// Apply the selected provider to the agent so the worker uses the correct endpoint.
 	// The dispatcher selects the best provider each dispatch, but the agent/worker
@@ -578,6 +705,7 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	if err := d.claimAndAssign(candidate, ag, selectedProjectID); err != nil {
 		releaseInflight()
 		log.Printf("[Dispatcher] Skipping bead %s (claim failed for agent %s, project=%s): %v", candidate.ID, ag.ID, selectedProjectID, err)
+		d.recordDecision(selectedProjectID, "claim_failed")
 		return &DispatchResult{Dispatched: false, ProjectID: projectID}, nil
 	}
 
@@ -600,13 +728,14 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	task := &worker.Task{
 		ID:                  fmt.Sprintf("task-%s-%d", candidate.ID, time.Now().UnixNano()),
 		Description:         buildBeadDescription(candidate),
-		Context:             buildBeadContext(candidate, proj),
+		Context:             buildBeadContext(candidate, proj) + comments.PromptSection(d.db, candidate.ID),
 		BeadID:              candidate.ID,
 		ProjectID:           selectedProjectID,
 		ConversationSession: conversationSession,
 	}
 
 	d.setStatus(StatusActive, fmt.Sprintf("dispatching %s", candidate.ID))
+	d.recordDecision(selectedProjectID, "dispatched")
 	dispatchResult := &DispatchResult{Dispatched: true, ProjectID: selectedProjectID, BeadID: candidate.ID, AgentID: ag.ID, ProviderID: ag.ProviderID}
 
 	// NATS-only and swarm routing removed: the TaskExecutor handles all bead
@@ -634,6 +763,10 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 		}
 		taskCtx, cancel := context.WithTimeout(baseCtx, timeout)
 		defer cancel()
+		// The lifecycle context carries no span; continue the dispatch trace
+		// so the whole execution shows up as one trace.
+		taskCtx = trace.ContextWithSpanContext(taskCtx, span.SpanContext())
+		d.recordTraceID(taskCtx, candidate.ID)
 
 		if d.workflowEngine != nil {
 			execution, err := d.workflowEngine.GetDatabase().GetWorkflowExecutionByBeadID(candidate.ID)
@@ -671,6 +804,10 @@ func (d *Dispatcher) DispatchOnce(ctx context.Context, projectID string) (*Dispa
 	return dispatchResult, nil
 }
 
+// detectorDispatchAlternation names the check buildDispatchHistory makes
+// when it records a loop.
+const detectorDispatchAlternation = "dispatch_alternation"
+
 func buildDispatchHistory(bead *models.Bead, agentID string) (historyJSON string, loopDetected bool, loopReason string) {
 	history := make([]string, 0)
 	if bead != nil && bead.Context != nil {
@@ -925,7 +1062,16 @@ func (d *Dispatcher) ensureBeadHasWorkflow(ctx context.Context, bead *models.Bea
 		isSelfImprovement = true
 	}
 
-	if isSelfImprovement {
+	d.mu.RLock()
+	typeWorkflow := d.typeWorkflow
+	d.mu.RUnlock()
+	if typeWorkflow != nil {
+		workflowType = typeWorkflow(bead.Type)
+	}
+
+	if workflowType != "" {
+		log.Printf("[Workflow] Matched bead %s to %s workflow (type %s)", bead.ID, workflowType, bead.Type)
+	} else if isSelfImprovement {
 		workflowType = "self-improvement"
 		log.Printf("[Workflow] Matched bead %s to self-improvement workflow (tags: %v)", bead.ID, bead.Tags)
 	} else if strings.Contains(title, "feature") || strings.Contains(title, "enhancement") {
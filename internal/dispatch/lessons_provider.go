@@ -16,8 +16,10 @@ import (
 // LessonsProvider retrieves and records lessons from the database.
 // It implements the worker.LessonsProvider interface.
 type LessonsProvider struct {
-	db       *database.Database
-	embedder memory.Embedder
+	db        *database.Database
+	embedder  memory.Embedder
+	retriever *memory.Retriever
+	topK      int
 }
 
 // NewLessonsProvider creates a new LessonsProvider backed by the given database.
@@ -39,6 +41,16 @@ func (lp *LessonsProvider) SetEmbedder(e memory.Embedder) {
 	}
 }
 
+// SetRetriever makes GetRelevantLessons draw on the project's indexed
+// history (closed beads, lessons and file excerpts) rather than lessons
+// alone. A positive topK overrides the caller's.
+func (lp *LessonsProvider) SetRetriever(r *memory.Retriever, topK int) {
+	if lp != nil && r != nil {
+		lp.retriever = r
+		lp.topK = topK
+	}
+}
+
 // GetLessonsForPrompt retrieves lessons for a project and formats them as markdown
 // suitable for injection into the system prompt.
 func (lp *LessonsProvider) GetLessonsForPrompt(projectID string) string {
@@ -83,12 +95,25 @@ func (lp *LessonsProvider) GetRelevantLessons(projectID, taskContext string, top
 		return lp.GetLessonsForPrompt(projectID)
 	}
 
+	if lp.topK > 0 {
+		topK = lp.topK
+	}
 	if topK <= 0 {
 		topK = 5
 	}
 
-	// Embed the task context
 	ctx := context.Background()
+	if lp.retriever != nil {
+		docs, err := lp.retriever.Retrieve(ctx, projectID, taskContext, topK)
+		if err == nil && len(docs) > 0 {
+			return memory.FormatDocuments(docs, 4000)
+		}
+		if err != nil {
+			log.Printf("[LessonsProvider] Memory retrieval failed, falling back to lessons: %v", err)
+		}
+	}
+
+	// Embed the task context
 	embeddings, err := lp.embedder.Embed(ctx, []string{taskContext})
 	if err != nil {
 		log.Printf("[LessonsProvider] Embedding failed, falling back to recency: %v", err)
@@ -159,6 +184,7 @@ func (lp *LessonsProvider) RecordLesson(projectID, category, title, detail, bead
 				return err
 			}
 			log.Printf("[LessonsProvider] Recorded lesson with embedding: [%s] %s", category, title)
+			lp.indexLesson(ctx, lesson)
 			return nil
 		}
 		// Embedding failed — fall through to store without embedding
@@ -170,5 +196,17 @@ func (lp *LessonsProvider) RecordLesson(projectID, category, title, detail, bead
 	}
 
 	log.Printf("[LessonsProvider] Recorded lesson: [%s] %s", category, title)
+	lp.indexLesson(context.Background(), lesson)
 	return nil
 }
+
+// indexLesson adds a new lesson to the retrieval index right away rather
+// than at the next indexing sweep.
+func (lp *LessonsProvider) indexLesson(ctx context.Context, lesson *models.Lesson) {
+	if lp.retriever == nil {
+		return
+	}
+	if err := lp.retriever.Index(ctx, memory.LessonDocument(lesson)); err != nil {
+		log.Printf("[LessonsProvider] Failed to index lesson %s: %v", lesson.ID, err)
+	}
+}
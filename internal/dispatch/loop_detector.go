@@ -8,6 +8,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/worker"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
@@ -30,9 +32,12 @@ type ProgressMetrics struct {
 	LastProgress     time.Time `json:"last_progress"`
 }
 
-// LoopDetector detects stuck loops vs. productive investigation
+// LoopDetector detects stuck loops vs. productive investigation. It runs
+// the built-in detectors, then any registered with RegisterDetector, and
+// the first to flag a bead decides why.
 type LoopDetector struct {
 	repeatThreshold int // Number of identical action sequences before flagging as loop
+	thresholds      func(projectID string) LoopThresholds
 }
 
 // NewLoopDetector creates a new loop detector with default settings
@@ -50,6 +55,22 @@ func (ld *LoopDetector) SetRepeatThreshold(threshold int) {
 	ld.repeatThreshold = threshold
 }
 
+// SetThresholdLookup installs the lookup of a project's loop thresholds.
+// Counts it leaves zero keep their defaults.
+func (ld *LoopDetector) SetThresholdLookup(lookup func(projectID string) LoopThresholds) {
+	ld.thresholds = lookup
+}
+
+// ThresholdsFor returns the loop thresholds in effect for a project.
+func (ld *LoopDetector) ThresholdsFor(projectID string) LoopThresholds {
+	t := DefaultLoopThresholds()
+	t.RepeatedActions = ld.repeatThreshold
+	if ld.thresholds != nil {
+		t = t.Merge(ld.thresholds(projectID))
+	}
+	return t
+}
+
 // RecordAction adds an action to the bead's dispatch history
 func (ld *LoopDetector) RecordAction(bead *models.Bead, action ActionRecord) error {
 	if bead.Context == nil {
@@ -87,42 +108,67 @@ func (ld *LoopDetector) RecordAction(bead *models.Bead, action ActionRecord) err
 	return nil
 }
 
+// RecordActionLog adds the actions of an agent's action loop, with their
+// results, to the bead's dispatch history.
+func (ld *LoopDetector) RecordActionLog(bead *models.Bead, agentID string, entries []worker.ActionLogEntry) error {
+	for _, entry := range entries {
+		for i, action := range entry.Actions {
+			var result actions.Result
+			if i < len(entry.Results) {
+				result = entry.Results[i]
+			}
+			if err := ld.RecordAction(bead, NewActionRecord(agentID, action, result, entry.Timestamp)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // IsStuckInLoop checks if the bead is stuck in a non-productive loop
 func (ld *LoopDetector) IsStuckInLoop(bead *models.Bead) (bool, string) {
-	// CRITICAL: Check for repeated infrastructure errors FIRST
-	// These are hard failures that will never succeed
-	if stuck, reason := ld.checkRepeatedErrors(bead); stuck {
-		return true, reason
+	if f := ld.Check(bead); f != nil {
+		return true, f.Reason
 	}
+	return false, ""
+}
 
+// Check runs the detectors enabled for the bead's project and returns the
+// first finding, or nil when the bead is not stuck. Repeated infrastructure
+// errors are checked first: they are hard failures that will never succeed.
+func (ld *LoopDetector) Check(bead *models.Bead) *LoopFinding {
+	t := ld.ThresholdsFor(bead.ProjectID)
 	history, err := ld.getActionHistory(bead)
-	if err != nil || len(history) < ld.repeatThreshold*2 {
-		// Not enough history to detect a loop
-		return false, ""
-	}
-
-	// Check for progress in recent history
-	if ld.hasRecentProgress(bead) {
-		// Making progress, not stuck
-		return false, ""
+	if err != nil {
+		log.Printf("[LoopDetector] Failed to parse action history for bead %s: %v", bead.ID, err)
+		history = nil
 	}
-
-	// Look for repeated action patterns
-	pattern, count := ld.findRepeatedPattern(history)
-	if count >= ld.repeatThreshold {
-		reason := fmt.Sprintf("Repeated action pattern %d times without progress: %s", count, pattern)
-		return true, reason
+	for _, d := range ld.detectors() {
+		if !t.Enabled(d.Name()) {
+			continue
+		}
+		if f := d.Detect(bead, history, t); f != nil {
+			if f.Detector == "" {
+				f.Detector = d.Name()
+			}
+			return f
+		}
 	}
-
-	return false, ""
+	return nil
 }
 
 // checkRepeatedErrors detects repeated infrastructure errors
-// This catches authentication failures, provider errors, and other hard failures
-func (ld *LoopDetector) checkRepeatedErrors(bead *models.Bead) (bool, string) {
+// This catches authentication failures, provider errors, and other hard failures.
+// threshold is how many identical, provider or rate limit errors flag the bead.
+func (ld *LoopDetector) checkRepeatedErrors(bead *models.Bead, threshold int) (bool, string) {
 	if bead.Context == nil {
 		return false, ""
 	}
+	// Credentials don't fix themselves, so give up on them sooner.
+	authThreshold := 3
+	if threshold < authThreshold {
+		authThreshold = threshold
+	}
 
 	// Get dispatch count
 	dispatchCount := 0
@@ -130,8 +176,8 @@ func (ld *LoopDetector) checkRepeatedErrors(bead *models.Bead) (bool, string) {
 		fmt.Sscanf(countStr, "%d", &dispatchCount)
 	}
 
-	// Need at least 5 attempts to detect error pattern
-	if dispatchCount < 5 {
+	// Need at least threshold attempts to detect error pattern
+	if dispatchCount < threshold {
 		return false, ""
 	}
 
@@ -160,7 +206,7 @@ func (ld *LoopDetector) checkRepeatedErrors(bead *models.Bead) (bool, string) {
 	ld.saveErrorHistory(bead, errorHistory)
 
 	// Check for repeated error patterns
-	if len(errorHistory) < 5 {
+	if len(errorHistory) < threshold {
 		return false, ""
 	}
 
@@ -210,19 +256,19 @@ func (ld *LoopDetector) checkRepeatedErrors(bead *models.Bead) (bool, string) {
 	}
 
 	// Hard failure conditions
-	if authErrors >= 3 {
+	if authErrors >= authThreshold {
 		return true, fmt.Sprintf("Repeated authentication errors (%d attempts) - provider credentials invalid or missing", authErrors)
 	}
 
-	if providerErrors >= 5 {
+	if providerErrors >= threshold {
 		return true, fmt.Sprintf("Repeated provider errors (%d attempts) - provider unavailable or unhealthy", providerErrors)
 	}
 
-	if rateLimitErrors >= 5 {
+	if rateLimitErrors >= threshold {
 		return true, fmt.Sprintf("Repeated rate limit errors (%d attempts) - exhausted provider quota. Consider increasing the budget or resetting the counter.", rateLimitErrors)
 	}
 
-	if sameErrorCount >= 5 {
+	if sameErrorCount >= threshold {
 		return true, fmt.Sprintf("Identical error repeated %d times - error pattern: %.100s", sameErrorCount, lastErrorPattern)
 	}
 
@@ -325,9 +371,9 @@ func (ld *LoopDetector) generateProgressKey(action ActionRecord) string {
 	return hex.EncodeToString(hash[:8]) // Use first 8 bytes (16 hex chars)
 }
 
-// findRepeatedPattern looks for repeated action sequences
-func (ld *LoopDetector) findRepeatedPattern(history []ActionRecord) (string, int) {
-	if len(history) < ld.repeatThreshold {
+// findRepeatedPattern looks for runs of at least threshold identical actions
+func findRepeatedPattern(history []ActionRecord, threshold int) (string, int) {
+	if len(history) < threshold {
 		return "", 0
 	}
 
@@ -346,7 +392,7 @@ func (ld *LoopDetector) findRepeatedPattern(history []ActionRecord) (string, int
 		if action.ProgressKey == lastKey {
 			consecutiveCount++
 		} else {
-			if consecutiveCount >= ld.repeatThreshold {
+			if consecutiveCount >= threshold {
 				patternCounts[lastKey] = consecutiveCount
 			}
 			lastKey = action.ProgressKey
@@ -355,7 +401,7 @@ func (ld *LoopDetector) findRepeatedPattern(history []ActionRecord) (string, int
 	}
 
 	// Check last sequence
-	if consecutiveCount >= ld.repeatThreshold {
+	if consecutiveCount >= threshold {
 		patternCounts[lastKey] = consecutiveCount
 	}
 
@@ -417,13 +463,13 @@ func (ld *LoopDetector) updateProgressMetrics(bead *models.Bead, action ActionRe
 	// LastProgress — repeating them endlessly is the definition of being stuck.
 	progressMade := false
 	switch action.ActionType {
-	case "read_file", "glob", "grep", "search_text", "read_tree":
+	case "read_file", "read_code", "glob", "grep", "search_text", "read_tree":
 		// Read-only: track for stats but do NOT update LastProgress
 		metrics.FilesRead++
-	case "edit_file", "write_file", "create_file":
+	case "edit_file", "edit_code", "write_file", "create_file", "apply_patch":
 		metrics.FilesModified++
 		progressMade = true
-	case "run_tests", "test":
+	case "run_tests", "test", "build_project":
 		metrics.TestsRun++
 		progressMade = true
 	case "bash", "execute", "run_command":
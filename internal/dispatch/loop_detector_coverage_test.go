@@ -526,7 +526,7 @@ func TestFindRepeatedPattern_LongHistory(t *testing.T) {
 		history[i] = ActionRecord{ProgressKey: key}
 	}
 
-	pattern, count := ld.findRepeatedPattern(history)
+	pattern, count := findRepeatedPattern(history, ld.repeatThreshold)
 	// Should detect the repeated pattern in last 15 entries
 	if count < ld.repeatThreshold {
 		t.Errorf("Expected count >= %d, got %d (pattern: %s)", ld.repeatThreshold, count, pattern)
@@ -548,7 +548,7 @@ func TestFindRepeatedPattern_MultiplePatterns(t *testing.T) {
 		{ProgressKey: "key2"},
 	}
 
-	pattern, count := ld.findRepeatedPattern(history)
+	pattern, count := findRepeatedPattern(history, ld.repeatThreshold)
 	// Should return the highest count pattern
 	if count != 5 {
 		t.Errorf("Expected count 5 for key2, got %d (pattern: %s)", count, pattern)
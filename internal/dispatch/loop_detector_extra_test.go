@@ -336,7 +336,7 @@ func TestFindRepeatedPattern(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pattern, count := ld.findRepeatedPattern(tt.history)
+			pattern, count := findRepeatedPattern(tt.history, ld.repeatThreshold)
 			if tt.hasPattern {
 				if count < ld.repeatThreshold {
 					t.Errorf("Expected count >= %d, got %d", ld.repeatThreshold, count)
@@ -0,0 +1,462 @@
+package dispatch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Built-in loop detectors, in the order they run.
+const (
+	DetectorRepeatedErrors   = "repeated_errors"
+	DetectorRepeatedActions  = "repeated_actions"
+	DetectorNoProgressEdits  = "no_progress_edits"
+	DetectorBuildOscillation = "build_oscillation"
+)
+
+var builtinDetectors = []string{
+	DetectorRepeatedErrors,
+	DetectorRepeatedActions,
+	DetectorNoProgressEdits,
+	DetectorBuildOscillation,
+}
+
+// Bead context keys recording why a bead was flagged as looping, next to
+// loop_detected, loop_detected_reason and loop_detected_at.
+const (
+	LoopDetectedByKey       = "loop_detected_by"
+	LoopDetectedEvidenceKey = "loop_detected_evidence"
+)
+
+// LoopThresholds tune the loop detectors. A zero count uses the default.
+type LoopThresholds struct {
+	// RepeatedErrors is how many identical run errors flag a bead.
+	RepeatedErrors int `json:"repeated_errors,omitempty"`
+	// RepeatedActions is how many identical actions in a row, without
+	// progress, flag a bead.
+	RepeatedActions int `json:"repeated_actions,omitempty"`
+	// NoProgressEdits is how many edits that repeat or undo an earlier edit
+	// flag a bead.
+	NoProgressEdits int `json:"no_progress_edits,omitempty"`
+	// BuildOscillations is how many times a build or test may flip between
+	// passing and failing before a bead that ends failing is flagged.
+	BuildOscillations int `json:"build_oscillations,omitempty"`
+	// Disabled names detectors that are not run.
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// DefaultLoopThresholds returns the thresholds used when nothing is
+// configured.
+func DefaultLoopThresholds() LoopThresholds {
+	return LoopThresholds{
+		RepeatedErrors:    5,
+		RepeatedActions:   3,
+		NoProgressEdits:   3,
+		BuildOscillations: 4,
+	}
+}
+
+// Merge returns t with the counts and disabled detectors set in o
+// replacing its own.
+func (t LoopThresholds) Merge(o LoopThresholds) LoopThresholds {
+	if o.RepeatedErrors > 0 {
+		t.RepeatedErrors = o.RepeatedErrors
+	}
+	if o.RepeatedActions > 0 {
+		t.RepeatedActions = o.RepeatedActions
+	}
+	if o.NoProgressEdits > 0 {
+		t.NoProgressEdits = o.NoProgressEdits
+	}
+	if o.BuildOscillations > 0 {
+		t.BuildOscillations = o.BuildOscillations
+	}
+	if o.Disabled != nil {
+		t.Disabled = o.Disabled
+	}
+	return t
+}
+
+// Enabled reports whether the named detector runs.
+func (t LoopThresholds) Enabled(name string) bool {
+	for _, d := range t.Disabled {
+		if d == name {
+			return false
+		}
+	}
+	return true
+}
+
+// LoopFinding explains why a bead was flagged as stuck in a loop.
+type LoopFinding struct {
+	Detector string   `json:"detector"`
+	Reason   string   `json:"reason"`
+	Evidence []string `json:"evidence,omitempty"`
+}
+
+// Context returns the bead context updates that record the finding.
+func (f *LoopFinding) Context() map[string]string {
+	evidence, _ := json.Marshal(f.Evidence)
+	return map[string]string{
+		"loop_detected":         "true",
+		"loop_detected_reason":  f.Reason,
+		"loop_detected_at":      time.Now().UTC().Format(time.RFC3339),
+		LoopDetectedByKey:       f.Detector,
+		LoopDetectedEvidenceKey: string(evidence),
+	}
+}
+
+// Detector decides whether a bead is stuck in a loop. Detect is given the
+// bead, its recorded action history, oldest first, and the thresholds in
+// effect for its project, and returns nil when the bead looks healthy.
+type Detector interface {
+	Name() string
+	Detect(bead *models.Bead, history []ActionRecord, t LoopThresholds) *LoopFinding
+}
+
+// DetectorRegistry holds loop detectors added alongside the built-in ones.
+type DetectorRegistry struct {
+	mu        sync.RWMutex
+	detectors map[string]Detector
+}
+
+// NewDetectorRegistry creates an empty detector registry.
+func NewDetectorRegistry() *DetectorRegistry {
+	return &DetectorRegistry{detectors: make(map[string]Detector)}
+}
+
+// defaultDetectors is the registry every LoopDetector consults.
+var defaultDetectors = NewDetectorRegistry()
+
+// RegisterDetector adds a loop detector to the default registry. It runs
+// after the built-in detectors.
+func RegisterDetector(d Detector) error {
+	return defaultDetectors.Register(d)
+}
+
+// UnregisterDetector removes a loop detector from the default registry.
+func UnregisterDetector(name string) {
+	defaultDetectors.Unregister(name)
+}
+
+// LoopDetectors lists the names of the built-in detectors, in the order
+// they run, followed by the registered ones.
+func LoopDetectors() []string {
+	names := append([]string(nil), builtinDetectors...)
+	for _, d := range defaultDetectors.List() {
+		names = append(names, d.Name())
+	}
+	return names
+}
+
+// Register adds a detector. Built-in names and names that are already
+// registered are rejected.
+func (r *DetectorRegistry) Register(d Detector) error {
+	if d == nil {
+		return errors.New("detector is nil")
+	}
+	name := strings.TrimSpace(d.Name())
+	switch {
+	case name == "":
+		return errors.New("detector name is required")
+	case isBuiltinDetector(name):
+		return fmt.Errorf("loop detector %s is built in", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.detectors[name]; ok {
+		return fmt.Errorf("loop detector %s is already registered", name)
+	}
+	r.detectors[name] = d
+	return nil
+}
+
+// Unregister removes a detector.
+func (r *DetectorRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.detectors, name)
+}
+
+// List returns the registered detectors sorted by name.
+func (r *DetectorRegistry) List() []Detector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Detector, 0, len(r.detectors))
+	for _, d := range r.detectors {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
+
+func isBuiltinDetector(name string) bool {
+	for _, b := range builtinDetectors {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// detectorFunc adapts a function to Detector.
+type detectorFunc struct {
+	name   string
+	detect func(*models.Bead, []ActionRecord, LoopThresholds) *LoopFinding
+}
+
+func (d detectorFunc) Name() string { return d.name }
+
+func (d detectorFunc) Detect(bead *models.Bead, history []ActionRecord, t LoopThresholds) *LoopFinding {
+	return d.detect(bead, history, t)
+}
+
+// detectors returns the built-in detectors followed by the registered ones.
+func (ld *LoopDetector) detectors() []Detector {
+	list := []Detector{
+		detectorFunc{DetectorRepeatedErrors, ld.detectRepeatedErrors},
+		detectorFunc{DetectorRepeatedActions, ld.detectRepeatedActions},
+		detectorFunc{DetectorNoProgressEdits, detectNoProgressEdits},
+		detectorFunc{DetectorBuildOscillation, detectBuildOscillation},
+	}
+	return append(list, defaultDetectors.List()...)
+}
+
+func (ld *LoopDetector) detectRepeatedErrors(bead *models.Bead, _ []ActionRecord, t LoopThresholds) *LoopFinding {
+	stuck, reason := ld.checkRepeatedErrors(bead, t.RepeatedErrors)
+	if !stuck {
+		return nil
+	}
+	var evidence []string
+	history := ld.getErrorHistory(bead)
+	if len(history) > 5 {
+		history = history[len(history)-5:]
+	}
+	for _, e := range history {
+		evidence = append(evidence, fmt.Sprintf("dispatch %d: %.200s", e.Dispatch, e.Error))
+	}
+	return &LoopFinding{Reason: reason, Evidence: evidence}
+}
+
+func (ld *LoopDetector) detectRepeatedActions(bead *models.Bead, history []ActionRecord, t LoopThresholds) *LoopFinding {
+	if len(history) < t.RepeatedActions*2 || ld.hasRecentProgress(bead) {
+		return nil
+	}
+	pattern, count := findRepeatedPattern(history, t.RepeatedActions)
+	if count < t.RepeatedActions {
+		return nil
+	}
+	var evidence []string
+	for _, a := range history {
+		if a.ProgressKey == pattern {
+			evidence = append(evidence, describeAction(a))
+		}
+	}
+	if len(evidence) > count {
+		evidence = evidence[len(evidence)-count:]
+	}
+	return &LoopFinding{
+		Reason:   fmt.Sprintf("Repeated action pattern %d times without progress: %s", count, pattern),
+		Evidence: evidence,
+	}
+}
+
+// detectNoProgressEdits flags a bead whose recent edits keep making a
+// change it already made, or undoing one, so the files go round in
+// circles.
+func detectNoProgressEdits(_ *models.Bead, history []ActionRecord, t LoopThresholds) *LoopFinding {
+	recent := history
+	if len(recent) > 30 {
+		recent = recent[len(recent)-30:]
+	}
+	seen := make(map[string]bool)
+	var evidence []string
+	for _, a := range recent {
+		if !isEditAction(a.ActionType) || a.ResultHash == "" {
+			continue
+		}
+		if seen[a.ResultHash] {
+			evidence = append(evidence, describeAction(a))
+		}
+		seen[a.ResultHash] = true
+		if undo, _ := a.ActionData["undo_hash"].(string); undo != "" {
+			seen[undo] = true
+		}
+	}
+	if len(evidence) < t.NoProgressEdits {
+		return nil
+	}
+	return &LoopFinding{
+		Reason:   fmt.Sprintf("%d edits repeated or undid an earlier edit without changing the outcome", len(evidence)),
+		Evidence: evidence,
+	}
+}
+
+// detectBuildOscillation flags a bead whose builds or tests keep flipping
+// between passing and failing and are failing now.
+func detectBuildOscillation(_ *models.Bead, history []ActionRecord, t LoopThresholds) *LoopFinding {
+	outcomes := make(map[string][]bool)
+	var order []string
+	for _, a := range history {
+		if !isBuildAction(a.ActionType) {
+			continue
+		}
+		success, ok := a.ActionData["success"].(bool)
+		if !ok {
+			continue
+		}
+		if _, ok := outcomes[a.ActionType]; !ok {
+			order = append(order, a.ActionType)
+		}
+		outcomes[a.ActionType] = append(outcomes[a.ActionType], success)
+	}
+	for _, actionType := range order {
+		runs := outcomes[actionType]
+		flips := 0
+		for i := 1; i < len(runs); i++ {
+			if runs[i] != runs[i-1] {
+				flips++
+			}
+		}
+		if flips < t.BuildOscillations || runs[len(runs)-1] {
+			continue
+		}
+		results := make([]string, len(runs))
+		for i, ok := range runs {
+			results[i] = "fail"
+			if ok {
+				results[i] = "pass"
+			}
+		}
+		return &LoopFinding{
+			Reason:   fmt.Sprintf("%s flipped between passing and failing %d times and is failing", actionType, flips),
+			Evidence: []string{actionType + ": " + strings.Join(results, ", ")},
+		}
+	}
+	return nil
+}
+
+func isEditAction(actionType string) bool {
+	switch actionType {
+	case actions.ActionEditCode, actions.ActionWriteFile, actions.ActionApplyPatch, "edit_file", "create_file":
+		return true
+	}
+	return false
+}
+
+func isBuildAction(actionType string) bool {
+	switch actionType {
+	case actions.ActionBuildProject, actions.ActionRunTests, "test":
+		return true
+	}
+	return false
+}
+
+// describeAction summarizes an action record for loop evidence.
+func describeAction(a ActionRecord) string {
+	if path, ok := a.ActionData["file_path"].(string); ok && path != "" {
+		return a.ActionType + " " + path
+	}
+	if command, ok := a.ActionData["command"].(string); ok && command != "" {
+		return a.ActionType + " " + command
+	}
+	return a.ActionType
+}
+
+// NewActionRecord builds the loop detector's record of an action an agent
+// ran and its result. Edits are hashed so that repeating or undoing one
+// can be recognised; builds and tests record whether they passed.
+func NewActionRecord(agentID string, action actions.Action, result actions.Result, at time.Time) ActionRecord {
+	data := map[string]interface{}{}
+	if action.Path != "" {
+		data["file_path"] = action.Path
+	}
+	if action.Command != "" {
+		data["command"] = action.Command
+	}
+	record := ActionRecord{Timestamp: at, AgentID: agentID, ActionType: action.Type, ActionData: data}
+
+	if result.Status == "error" {
+		data["error"] = true
+	}
+	switch {
+	case isEditAction(action.Type) && result.Status != "error":
+		switch {
+		case action.OldText != "" || action.NewText != "":
+			record.ResultHash = hashParts(action.Path, action.OldText, action.NewText)
+			data["undo_hash"] = hashParts(action.Path, action.NewText, action.OldText)
+		case action.Content != "":
+			record.ResultHash = hashParts(action.Path, action.Content)
+		case action.Patch != "":
+			record.ResultHash = hashParts(action.Path, action.Patch)
+		}
+	case isBuildAction(action.Type):
+		data["success"] = result.Status != "error" && result.Metadata["success"] != false
+	}
+	return record
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// LoopReport describes a bead's loop detection state: whether it was
+// flagged and why, and the detectors and thresholds that apply to it.
+type LoopReport struct {
+	BeadID       string          `json:"bead_id"`
+	LoopDetected bool            `json:"loop_detected"`
+	Detector     string          `json:"detector,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+	DetectedAt   string          `json:"detected_at,omitempty"`
+	Evidence     []string        `json:"evidence,omitempty"`
+	Detectors    []DetectorState `json:"detectors"`
+	Thresholds   LoopThresholds  `json:"thresholds"`
+	Progress     string          `json:"progress"`
+	Actions      int             `json:"actions_recorded"`
+}
+
+// DetectorState is one detector and whether it runs for a bead.
+type DetectorState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Report describes the bead's recorded loop detection state. It does not
+// run the detectors.
+func (ld *LoopDetector) Report(bead *models.Bead) *LoopReport {
+	t := ld.ThresholdsFor(bead.ProjectID)
+	history, _ := ld.getActionHistory(bead)
+	report := &LoopReport{
+		BeadID:     bead.ID,
+		Thresholds: t,
+		Progress:   ld.GetProgressSummary(bead),
+		Actions:    len(history),
+	}
+	for _, name := range LoopDetectors() {
+		report.Detectors = append(report.Detectors, DetectorState{Name: name, Enabled: t.Enabled(name)})
+	}
+	if bead.Context != nil && bead.Context["loop_detected"] == "true" {
+		report.LoopDetected = true
+		report.Detector = bead.Context[LoopDetectedByKey]
+		report.Reason = bead.Context["loop_detected_reason"]
+		report.DetectedAt = bead.Context["loop_detected_at"]
+		if raw := bead.Context[LoopDetectedEvidenceKey]; raw != "" {
+			_ = json.Unmarshal([]byte(raw), &report.Evidence)
+		}
+	}
+	return report
+}
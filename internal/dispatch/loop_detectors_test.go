@@ -0,0 +1,147 @@
+package dispatch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/worker"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func loopEntry(acts []actions.Action, results []actions.Result) worker.ActionLogEntry {
+	return worker.ActionLogEntry{Actions: acts, Results: results, Timestamp: time.Now()}
+}
+
+func TestLoopDetector_NoProgressEdits(t *testing.T) {
+	ld := NewLoopDetector()
+	bead := &models.Bead{ID: "b-1", ProjectID: "p"}
+	forward := actions.Action{Type: actions.ActionEditCode, Path: "main.go", OldText: "a", NewText: "b"}
+	back := actions.Action{Type: actions.ActionEditCode, Path: "main.go", OldText: "b", NewText: "a"}
+	ok := actions.Result{Status: "executed"}
+
+	var entries []worker.ActionLogEntry
+	for i := 0; i < 2; i++ {
+		entries = append(entries, loopEntry([]actions.Action{forward, back}, []actions.Result{ok, ok}))
+	}
+	if err := ld.RecordActionLog(bead, "agent-1", entries); err != nil {
+		t.Fatal(err)
+	}
+	f := ld.Check(bead)
+	if f == nil || f.Detector != DetectorNoProgressEdits {
+		t.Fatalf("Check() = %+v, want a no_progress_edits finding", f)
+	}
+	if len(f.Evidence) != 3 || f.Evidence[0] != "edit_code main.go" {
+		t.Errorf("evidence = %v", f.Evidence)
+	}
+
+	ld.SetThresholdLookup(func(string) LoopThresholds { return LoopThresholds{NoProgressEdits: 4} })
+	if f := ld.Check(bead); f != nil {
+		t.Errorf("with a threshold of 4, Check() = %+v, want nil", f)
+	}
+	ld.SetThresholdLookup(func(string) LoopThresholds {
+		return LoopThresholds{Disabled: []string{DetectorNoProgressEdits}}
+	})
+	if f := ld.Check(bead); f != nil {
+		t.Errorf("with the detector disabled, Check() = %+v, want nil", f)
+	}
+}
+
+func TestLoopDetector_BuildOscillation(t *testing.T) {
+	ld := NewLoopDetector()
+	bead := &models.Bead{ID: "b-2", ProjectID: "p"}
+	test := actions.Action{Type: actions.ActionRunTests}
+	pass := actions.Result{Status: "executed", Metadata: map[string]interface{}{"success": true}}
+	fail := actions.Result{Status: "executed", Metadata: map[string]interface{}{"success": false}}
+
+	var entries []worker.ActionLogEntry
+	for _, r := range []actions.Result{fail, pass, fail, pass} {
+		entries = append(entries, loopEntry([]actions.Action{test}, []actions.Result{r}))
+	}
+	_ = ld.RecordActionLog(bead, "agent-1", entries)
+	if f := ld.Check(bead); f != nil {
+		t.Fatalf("passing tests flagged: %+v", f)
+	}
+
+	_ = ld.RecordActionLog(bead, "agent-1", []worker.ActionLogEntry{loopEntry([]actions.Action{test}, []actions.Result{fail})})
+	f := ld.Check(bead)
+	if f == nil || f.Detector != DetectorBuildOscillation {
+		t.Fatalf("Check() = %+v, want a build_oscillation finding", f)
+	}
+	if want := "run_tests: fail, pass, fail, pass, fail"; len(f.Evidence) != 1 || f.Evidence[0] != want {
+		t.Errorf("evidence = %v, want %q", f.Evidence, want)
+	}
+}
+
+type stubDetector struct{ name string }
+
+func (d stubDetector) Name() string { return d.name }
+
+func (d stubDetector) Detect(bead *models.Bead, _ []ActionRecord, _ LoopThresholds) *LoopFinding {
+	if bead.Context["stub"] == "stuck" {
+		return &LoopFinding{Reason: "stub says stuck"}
+	}
+	return nil
+}
+
+func TestRegisterDetector(t *testing.T) {
+	if err := RegisterDetector(stubDetector{DetectorRepeatedErrors}); err == nil {
+		t.Error("registering a built-in name should fail")
+	}
+	if err := RegisterDetector(stubDetector{"stub"}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterDetector("stub")
+	if err := RegisterDetector(stubDetector{"stub"}); err == nil {
+		t.Error("registering a name twice should fail")
+	}
+	if names := LoopDetectors(); len(names) != 5 || names[4] != "stub" {
+		t.Errorf("LoopDetectors() = %v", names)
+	}
+
+	ld := NewLoopDetector()
+	bead := &models.Bead{ID: "b-3", Context: map[string]string{"stub": "stuck"}}
+	f := ld.Check(bead)
+	if f == nil || f.Detector != "stub" || f.Reason != "stub says stuck" {
+		t.Fatalf("Check() = %+v, want the stub's finding", f)
+	}
+
+	for k, v := range f.Context() {
+		bead.Context[k] = v
+	}
+	report := ld.Report(bead)
+	if !report.LoopDetected || report.Detector != "stub" || report.Thresholds.RepeatedActions != 3 {
+		t.Errorf("Report() = %+v", report)
+	}
+	if len(report.Detectors) != 5 || !report.Detectors[4].Enabled {
+		t.Errorf("report detectors = %+v", report.Detectors)
+	}
+}
+
+func TestLoopDetector_RepeatedErrorsThreshold(t *testing.T) {
+	ld := NewLoopDetector()
+	ld.SetThresholdLookup(func(projectID string) LoopThresholds {
+		if projectID == "strict" {
+			return LoopThresholds{RepeatedErrors: 2}
+		}
+		return LoopThresholds{}
+	})
+	for _, tc := range []struct {
+		project string
+		want    bool
+	}{{"strict", true}, {"lenient", false}} {
+		bead := &models.Bead{ID: "b-4", ProjectID: tc.project, Context: map[string]string{
+			"dispatch_count": "2",
+			"last_run_error": "boom",
+			"error_history":  `[{"error":"boom","dispatch":1}]`,
+		}}
+		f := ld.Check(bead)
+		if (f != nil) != tc.want {
+			t.Errorf("%s: Check() = %+v, want flagged %v", tc.project, f, tc.want)
+		}
+		if f != nil && (f.Detector != DetectorRepeatedErrors || !strings.Contains(f.Reason, "Identical error")) {
+			t.Errorf("%s: finding = %+v", tc.project, f)
+		}
+	}
+}
@@ -157,7 +157,7 @@ func TestLoopDetector_SaveErrorHistory_ExistingContext(t *testing.T) {
 func TestLoopDetector_CheckRepeatedErrors_NilContext(t *testing.T) {
 	ld := NewLoopDetector()
 	bead := &models.Bead{}
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if stuck {
 		t.Error("nil context should not be stuck")
 	}
@@ -169,7 +169,7 @@ func TestLoopDetector_CheckRepeatedErrors_LowDispatchCount(t *testing.T) {
 		"dispatch_count": "3",
 		"last_run_error": "some error",
 	}}
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if stuck {
 		t.Error("low dispatch count should not be stuck")
 	}
@@ -180,7 +180,7 @@ func TestLoopDetector_CheckRepeatedErrors_NoLastError(t *testing.T) {
 	bead := &models.Bead{Context: map[string]string{
 		"dispatch_count": "10",
 	}}
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if stuck {
 		t.Error("no last error should not be stuck")
 	}
@@ -206,7 +206,7 @@ func TestLoopDetector_CheckRepeatedErrors_AuthErrors(t *testing.T) {
 		"error_history":  string(histJSON),
 	}}
 
-	stuck, reason := ld.checkRepeatedErrors(bead)
+	stuck, reason := ld.checkRepeatedErrors(bead, 5)
 	if !stuck {
 		t.Error("repeated auth errors should be detected as stuck")
 	}
@@ -234,7 +234,7 @@ func TestLoopDetector_CheckRepeatedErrors_ProviderErrors(t *testing.T) {
 		"error_history":  string(histJSON),
 	}}
 
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if !stuck {
 		t.Error("repeated provider errors should be detected as stuck")
 	}
@@ -259,7 +259,7 @@ func TestLoopDetector_CheckRepeatedErrors_RateLimitErrors(t *testing.T) {
 		"error_history":  string(histJSON),
 	}}
 
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if !stuck {
 		t.Error("repeated rate limit errors should be detected as stuck")
 	}
@@ -284,7 +284,7 @@ func TestLoopDetector_CheckRepeatedErrors_SameError(t *testing.T) {
 		"error_history":  string(histJSON),
 	}}
 
-	stuck, _ := ld.checkRepeatedErrors(bead)
+	stuck, _ := ld.checkRepeatedErrors(bead, 5)
 	if !stuck {
 		t.Error("identical repeated errors should be detected as stuck")
 	}
@@ -16,19 +16,27 @@ const (
 	EventTypeAgentHeartbeat     EventType = "agent.heartbeat"
 	EventTypeAgentCompleted     EventType = "agent.completed"
 	EventTypeAgentIteration     EventType = "agent.iteration"
+	EventTypeAgentStuck         EventType = "agent.stuck"
+	EventTypeAgentOutput        EventType = "agent.output"
+	EventTypeCommandBlocked     EventType = "command.blocked"
 	EventTypeBeadCreated        EventType = "bead.created"
 	EventTypeBeadAssigned       EventType = "bead.assigned"
 	EventTypeBeadStatusChange   EventType = "bead.status_change"
 	EventTypeBeadCompleted      EventType = "bead.completed"
+	EventTypeBeadSLABreach      EventType = "bead.sla_breach"
+	EventTypeBeadOverdue        EventType = "bead.overdue"
 	EventTypeDecisionCreated    EventType = "decision.created"
 	EventTypeDecisionResolved   EventType = "decision.resolved"
 	EventTypeProviderRegistered EventType = "provider.registered"
 	EventTypeProviderDeleted    EventType = "provider.deleted"
 	EventTypeProviderUpdated    EventType = "provider.updated"
+	EventTypeProviderUnhealthy  EventType = "provider.unhealthy"
+	EventTypeCostAnomaly        EventType = "cost.anomaly"
 	EventTypeProjectCreated     EventType = "project.created"
 	EventTypeProjectUpdated     EventType = "project.updated"
 	EventTypeProjectDeleted     EventType = "project.deleted"
 	EventTypeConfigUpdated      EventType = "config.updated"
+	EventTypeKeyStoreRotated    EventType = "keystore.rotated"
 	EventTypeLogMessage         EventType = "log.message"
 	EventTypeWorkflowStarted    EventType = "workflow.started"
 	EventTypeWorkflowCompleted  EventType = "workflow.completed"
@@ -56,6 +64,14 @@ type Event struct {
 	Source    string                 `json:"source"` // Component that generated the event
 	Data      map[string]interface{} `json:"data"`   // Event payload
 	ProjectID string                 `json:"project_id,omitempty"`
+	// Seq numbers the events kept in the recent-events history, in the order
+	// they were distributed; clients use it as a cursor to resume a stream.
+	// It is 0 for transient events.
+	Seq uint64 `json:"seq,omitempty"`
+	// Transient events (e.g. streamed model output) are delivered to
+	// subscribers but not kept in the recent-events history, so that they
+	// cannot crowd out everything else.
+	Transient bool `json:"-"`
 }
 
 // Subscriber represents an event subscriber
@@ -75,8 +91,10 @@ type EventBus struct {
 
 	// Ring buffer for recent event history (ephemeral, lost on restart)
 	recentEvents []*Event
+	recentSeqs   []uint64 // Sequence number of the event in the same slot
 	recentIdx    int
 	recentCount  int
+	seq          uint64
 }
 
 // NewEventBus creates a new event bus
@@ -89,6 +107,7 @@ func NewEventBus() *EventBus {
 		cancel:       cancel,
 		buffer:       make(chan *Event, 1000),
 		recentEvents: make([]*Event, 1000),
+		recentSeqs:   make([]uint64, 1000),
 	}
 
 	// Start event processing goroutine
@@ -173,13 +192,18 @@ func (eb *EventBus) processEvents() {
 // distributeEvent sends event to all matching subscribers
 func (eb *EventBus) distributeEvent(event *Event) {
 	// Store in ring buffer for history queries
-	eb.mu.Lock()
-	eb.recentEvents[eb.recentIdx] = event
-	eb.recentIdx = (eb.recentIdx + 1) % len(eb.recentEvents)
-	if eb.recentCount < len(eb.recentEvents) {
-		eb.recentCount++
+	if !event.Transient {
+		eb.mu.Lock()
+		eb.seq++
+		event.Seq = eb.seq
+		eb.recentEvents[eb.recentIdx] = event
+		eb.recentSeqs[eb.recentIdx] = eb.seq
+		eb.recentIdx = (eb.recentIdx + 1) % len(eb.recentEvents)
+		if eb.recentCount < len(eb.recentEvents) {
+			eb.recentCount++
+		}
+		eb.mu.Unlock()
 	}
-	eb.mu.Unlock()
 
 	eb.mu.RLock()
 	subs := make([]*Subscriber, 0, len(eb.subscribers))
@@ -213,6 +237,15 @@ func (eb *EventBus) SubscriberCount() int {
 // GetRecentEvents returns recent events from the ring buffer, filtered by optional projectID and eventType.
 // Results are returned newest-first, up to limit.
 func (eb *EventBus) GetRecentEvents(limit int, projectID, eventType string) []*Event {
+	events, _ := eb.GetEventsPage(limit, projectID, eventType, 0)
+	return events
+}
+
+// GetEventsPage returns up to limit events older than the event with sequence
+// number before (0 starts from the newest event), newest-first. next is the
+// sequence number to pass as before to fetch the following page, or 0 when no
+// older matching events remain in the ring buffer.
+func (eb *EventBus) GetEventsPage(limit int, projectID, eventType string, before uint64) (events []*Event, next uint64) {
 	eb.mu.RLock()
 	defer eb.mu.RUnlock()
 
@@ -221,22 +254,72 @@ func (eb *EventBus) GetRecentEvents(limit int, projectID, eventType string) []*E
 	}
 
 	result := make([]*Event, 0, limit)
+	var lastSeq uint64
 	// Walk backwards from most recent
-	for i := 0; i < eb.recentCount && len(result) < limit; i++ {
+	for i := 0; i < eb.recentCount; i++ {
 		idx := (eb.recentIdx - 1 - i + len(eb.recentEvents)) % len(eb.recentEvents)
 		ev := eb.recentEvents[idx]
 		if ev == nil {
 			continue
 		}
+		if before != 0 && eb.recentSeqs[idx] >= before {
+			continue
+		}
 		if projectID != "" && ev.ProjectID != projectID {
 			continue
 		}
 		if eventType != "" && string(ev.Type) != eventType {
 			continue
 		}
+		if len(result) == limit {
+			// At least one more matching event exists beyond this page.
+			return result, lastSeq
+		}
 		result = append(result, ev)
+		lastSeq = eb.recentSeqs[idx]
+	}
+	return result, 0
+}
+
+// LatestSeq returns the sequence number of the newest event in the ring
+// buffer, the cursor of a stream that starts now.
+func (eb *EventBus) LatestSeq() uint64 {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+	return eb.seq
+}
+
+// EventsSince returns the events in the ring buffer with a sequence number
+// after the cursor after that match filter, oldest-first, for replaying a
+// stream a client lost. latest is the sequence number of the newest event,
+// the cursor to resume from once these are delivered. gap reports whether
+// events after the cursor have already left the ring buffer (or the cursor
+// is from before a restart), so the client missed events it can't replay.
+func (eb *EventBus) EventsSince(after uint64, filter func(*Event) bool) (events []*Event, latest uint64, gap bool) {
+	eb.mu.RLock()
+	defer eb.mu.RUnlock()
+
+	if after > eb.seq {
+		// The sequence restarted with the process; replay all we have.
+		after, gap = 0, eb.seq > 0
+	}
+	oldest := eb.seq - uint64(eb.recentCount) + 1
+	if after+1 < oldest {
+		gap = true
+	}
+
+	for i := eb.recentCount - 1; i >= 0; i-- {
+		idx := (eb.recentIdx - 1 - i + len(eb.recentEvents)) % len(eb.recentEvents)
+		ev := eb.recentEvents[idx]
+		if ev == nil || eb.recentSeqs[idx] <= after {
+			continue
+		}
+		if filter != nil && !filter(ev) {
+			continue
+		}
+		events = append(events, ev)
 	}
-	return result
+	return events, eb.seq, gap
 }
 
 // Close shuts down the event bus
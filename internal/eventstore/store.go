@@ -0,0 +1,314 @@
+// Package eventstore persists the event bus to the database. The bus only
+// keeps its last 1000 events in memory, so everything published before a
+// restart is otherwise gone. The store gives each stored event a durable
+// sequence number that replay clients resume from, prunes events past the
+// retention window, and feeds durable consumers (such as the activity
+// feed) that pick up where they left off after a restart.
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// DefaultRetention is how long events are kept when the config doesn't
+// say.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// Replay page sizes.
+const (
+	defaultReplayLimit = 500
+	maxReplayLimit     = 1000
+)
+
+// consumerBatch is how many stored events a consumer is handed per query.
+const consumerBatch = 500
+
+// pollInterval is how often Run looks for events it wasn't woken up for,
+// e.g. because its subscription fell behind and the bus dropped them.
+const pollInterval = 5 * time.Second
+
+// Event is a stored event. Seq is its position in the store; unlike the
+// event bus's sequence it survives restarts.
+type Event struct {
+	Seq       int64                  `json:"seq"`
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Source    string                 `json:"source"`
+	ProjectID string                 `json:"project_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// ReplayQuery selects the events to replay. Since is the Seq of the last
+// event the client saw; SinceTime, if set, skips events up to that time.
+type ReplayQuery struct {
+	Since     int64
+	SinceTime time.Time
+	ProjectID string
+	Types     []string
+	Limit     int
+}
+
+// ReplayPage is a page of replayed events. Cursor is the Since of the next
+// page, and More reports whether there is one. Truncated reports that
+// events after Since were already pruned, so the client missed some.
+type ReplayPage struct {
+	Events    []*Event `json:"events"`
+	Cursor    int64    `json:"cursor"`
+	More      bool     `json:"more"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// consumer is a durable consumer registered with Consume.
+type consumer struct {
+	name   string
+	fn     func(*eventbus.Event) error
+	cursor int64
+	loaded bool
+}
+
+// Store persists event bus events and replays them.
+type Store struct {
+	db        *database.Database
+	eventBus  *eventbus.EventBus
+	retention config.EventsConfig
+	now       func() time.Time
+
+	mu        sync.Mutex
+	consumers []*consumer
+}
+
+// NewStore creates an event store. It returns nil without a database.
+func NewStore(db *database.Database, eventBus *eventbus.EventBus, cfg config.EventsConfig) *Store {
+	if db == nil {
+		return nil
+	}
+	return &Store{db: db, eventBus: eventBus, retention: cfg, now: time.Now}
+}
+
+// Consume registers a durable consumer. fn is called with every stored
+// event, in order, starting after the last event it was called with
+// before, even across restarts. Errors from fn are logged and the event is
+// skipped. Consumers are only called from Run.
+func (s *Store) Consume(name string, fn func(*eventbus.Event) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumers = append(s.consumers, &consumer{name: name, fn: fn})
+}
+
+// Run stores the bus's events and feeds the consumers until ctx is done.
+// It starts with the bus's whole history, so events published before Run
+// are stored too.
+func (s *Store) Run(ctx context.Context) {
+	if s.eventBus == nil {
+		return
+	}
+	persistent := func(event *eventbus.Event) bool { return !event.Transient }
+
+	// The subscription only wakes the loop up: events are read from the
+	// bus's history by sequence, which also recovers any the bus dropped
+	// because the store fell behind.
+	sub := s.eventBus.Subscribe("event-store", persistent)
+	defer s.eventBus.Unsubscribe("event-store")
+
+	var cursor uint64
+	flush := func() {
+		events, latest, gap := s.eventBus.EventsSince(cursor, persistent)
+		if gap {
+			log.Printf("[EventStore] Events were lost before they could be stored")
+		}
+		for _, ev := range events {
+			if err := s.append(ev); err != nil {
+				log.Printf("[EventStore] %v", err)
+				return
+			}
+			cursor = ev.Seq
+		}
+		cursor = latest
+	}
+
+	flush()
+	s.deliver()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Channel:
+			if !ok {
+				return
+			}
+			if ev.Seq <= cursor {
+				continue
+			}
+		case <-ticker.C:
+		}
+		flush()
+		s.deliver()
+	}
+}
+
+// append stores one bus event.
+func (s *Store) append(ev *eventbus.Event) error {
+	row := &database.StoredEvent{
+		ID:        ev.ID,
+		Type:      string(ev.Type),
+		Source:    ev.Source,
+		ProjectID: ev.ProjectID,
+		CreatedAt: ev.Timestamp.UTC(),
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = s.now().UTC()
+	}
+	if len(ev.Data) > 0 {
+		data, err := json.Marshal(ev.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", ev.ID, err)
+		}
+		row.DataJSON = string(data)
+	}
+	return s.db.AppendEvent(row)
+}
+
+// deliver hands each consumer the stored events after its cursor.
+func (s *Store) deliver() {
+	s.mu.Lock()
+	consumers := append([]*consumer(nil), s.consumers...)
+	s.mu.Unlock()
+
+	for _, c := range consumers {
+		if err := s.deliverTo(c); err != nil {
+			log.Printf("[EventStore] Consumer %s: %v", c.name, err)
+		}
+	}
+}
+
+func (s *Store) deliverTo(c *consumer) error {
+	if !c.loaded {
+		cursor, err := s.db.GetEventCursor(c.name)
+		if err != nil {
+			return err
+		}
+		c.cursor, c.loaded = cursor, true
+	}
+	for {
+		rows, err := s.db.ListEvents(database.StoredEventFilter{Since: c.cursor, Limit: consumerBatch})
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			if err := c.fn(toEvent(row).busEvent()); err != nil {
+				log.Printf("[EventStore] Consumer %s failed on event %d: %v", c.name, row.Seq, err)
+			}
+		}
+		c.cursor = rows[len(rows)-1].Seq
+		if err := s.db.SetEventCursor(c.name, c.cursor); err != nil {
+			return err
+		}
+		if len(rows) < consumerBatch {
+			return nil
+		}
+	}
+}
+
+// Replay returns the stored events after q.Since that match q, oldest
+// first.
+func (s *Store) Replay(q ReplayQuery) (*ReplayPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = defaultReplayLimit
+	}
+	if q.Limit > maxReplayLimit {
+		q.Limit = maxReplayLimit
+	}
+
+	// Read the range first: every event up to newest is either returned
+	// below or doesn't match, so a short page can advance the cursor to it.
+	oldest, newest, err := s.db.EventSeqRange()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.ListEvents(database.StoredEventFilter{
+		Since:     q.Since,
+		SinceTime: q.SinceTime,
+		ProjectID: q.ProjectID,
+		Types:     q.Types,
+		Limit:     q.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ReplayPage{
+		Events:    make([]*Event, 0, len(rows)),
+		Cursor:    q.Since,
+		More:      len(rows) == q.Limit,
+		Truncated: q.Since > 0 && oldest > 0 && q.Since+1 < oldest,
+	}
+	for _, row := range rows {
+		page.Events = append(page.Events, toEvent(row))
+		page.Cursor = row.Seq
+	}
+	if !page.More && newest > page.Cursor {
+		page.Cursor = newest
+	}
+	return page, nil
+}
+
+// Prune removes events older than the retention window and returns how
+// many were removed.
+func (s *Store) Prune() (int64, error) {
+	keep := s.retention.Retention
+	if keep == 0 {
+		keep = DefaultRetention
+	}
+	if keep < 0 {
+		return 0, nil
+	}
+	return s.db.DeleteEventsBefore(s.now().UTC().Add(-keep))
+}
+
+func toEvent(row *database.StoredEvent) *Event {
+	e := &Event{
+		Seq:       row.Seq,
+		ID:        row.ID,
+		Type:      row.Type,
+		Source:    row.Source,
+		ProjectID: row.ProjectID,
+		Timestamp: row.CreatedAt,
+	}
+	if row.DataJSON != "" {
+		if err := json.Unmarshal([]byte(row.DataJSON), &e.Data); err != nil {
+			log.Printf("[EventStore] Failed to decode data of event %d: %v", row.Seq, err)
+		}
+	}
+	if e.Data == nil {
+		e.Data = map[string]interface{}{}
+	}
+	return e
+}
+
+// busEvent converts a stored event back to the bus's form for consumers.
+func (e *Event) busEvent() *eventbus.Event {
+	return &eventbus.Event{
+		ID:        e.ID,
+		Type:      eventbus.EventType(e.Type),
+		Timestamp: e.Timestamp,
+		Source:    e.Source,
+		Data:      e.Data,
+		ProjectID: e.ProjectID,
+	}
+}
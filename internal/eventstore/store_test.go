@@ -0,0 +1,203 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// runStore runs s until the test ends.
+func runStore(t *testing.T, s *Store) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitStored waits until the store holds n events.
+func waitStored(t *testing.T, db *database.Database, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, newest, err := db.EventSeqRange()
+		if err != nil {
+			t.Fatalf("EventSeqRange: %v", err)
+		}
+		if newest >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("store holds %d events, want %d", newest, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func publish(t *testing.T, bus *eventbus.EventBus, events ...*eventbus.Event) {
+	t.Helper()
+	for _, ev := range events {
+		if err := bus.Publish(ev); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+}
+
+func TestRunStoresAndReplays(t *testing.T) {
+	db := dbtest.New(t)
+	bus := eventbus.NewEventBus()
+
+	// Published before the store runs: picked up from the bus's history.
+	publish(t, bus, &eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "p1", Data: map[string]interface{}{"bead_id": "bd-1"}})
+
+	s := NewStore(db, bus, config.EventsConfig{})
+	runStore(t, s)
+	publish(t, bus,
+		&eventbus.Event{Type: eventbus.EventTypeLogMessage, Transient: true},
+		&eventbus.Event{Type: eventbus.EventTypeBeadStatusChange, ProjectID: "p2"},
+		&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "p2"},
+	)
+	waitStored(t, db, 3)
+
+	page, err := s.Replay(ReplayQuery{})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(page.Events) != 3 || page.More || page.Cursor != 3 {
+		t.Fatalf("Replay = %d events, more %v, cursor %d; want 3, false, 3", len(page.Events), page.More, page.Cursor)
+	}
+	if first := page.Events[0]; first.Type != string(eventbus.EventTypeBeadCreated) || first.Data["bead_id"] != "bd-1" {
+		t.Errorf("first event = %+v", first)
+	}
+
+	page, err = s.Replay(ReplayQuery{Since: 1, ProjectID: "p2", Types: []string{string(eventbus.EventTypeBeadCreated)}})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].Seq != 3 || page.Cursor != 3 {
+		t.Fatalf("filtered Replay = %+v", page)
+	}
+
+	page, err = s.Replay(ReplayQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(page.Events) != 2 || !page.More || page.Cursor != 2 {
+		t.Fatalf("limited Replay = %d events, more %v, cursor %d; want 2, true, 2", len(page.Events), page.More, page.Cursor)
+	}
+}
+
+func TestConsumerResumesAfterRestart(t *testing.T) {
+	db := dbtest.New(t)
+	for i := 1; i <= 3; i++ {
+		if err := db.AppendEvent(&database.StoredEvent{ID: fmt.Sprintf("ev-%d", i), Type: "bead.created", CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	consume := func(ev *eventbus.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, ev.ID)
+		return nil
+	}
+	waitSeen := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			got := len(seen)
+			mu.Unlock()
+			if got >= n {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("consumer saw %d events, want %d", got, n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	bus := eventbus.NewEventBus()
+	first := NewStore(db, bus, config.EventsConfig{})
+	first.Consume("test", consume)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first.Run(ctx)
+	}()
+	waitSeen(3)
+	cancel()
+	<-done
+
+	if err := db.AppendEvent(&database.StoredEvent{ID: "ev-4", Type: "bead.created", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+	second := NewStore(db, eventbus.NewEventBus(), config.EventsConfig{})
+	second.Consume("test", consume)
+	runStore(t, second)
+	waitSeen(4)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 4 || seen[3] != "ev-4" {
+		t.Errorf("consumer saw %v, want ev-1..ev-4 once each", seen)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db := dbtest.New(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 10 * 24 * time.Hour, time.Hour} {
+		if err := db.AppendEvent(&database.StoredEvent{ID: fmt.Sprintf("ev-%d", i), Type: "bead.created", CreatedAt: now.Add(-age)}); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	s := NewStore(db, nil, config.EventsConfig{})
+	s.now = func() time.Time { return now }
+	if n, err := s.Prune(); err != nil || n != 1 {
+		t.Fatalf("Prune() = %d, %v; want 1 with the default retention", n, err)
+	}
+
+	page, err := s.Replay(ReplayQuery{Since: 0})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(page.Events) != 2 || page.Truncated {
+		t.Fatalf("Replay from the start = %+v", page)
+	}
+	if page, err = s.Replay(ReplayQuery{Since: 1}); err != nil || page.Truncated {
+		t.Fatalf("Replay(since=1) = %+v, %v; nothing after 1 was pruned", page, err)
+	}
+
+	s.retention.Retention = 24 * time.Hour
+	if n, err := s.Prune(); err != nil || n != 1 {
+		t.Fatalf("Prune() = %d, %v; want 1", n, err)
+	}
+	if page, err = s.Replay(ReplayQuery{Since: 1}); err != nil || !page.Truncated {
+		t.Fatalf("Replay(since=1) = %+v, %v; want truncated", page, err)
+	}
+
+	s.retention.Retention = -1
+	s.now = func() time.Time { return now.Add(365 * 24 * time.Hour) }
+	if n, err := s.Prune(); err != nil || n != 0 {
+		t.Errorf("Prune() with negative retention = %d, %v; want 0", n, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -14,6 +15,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jordanhubbard/loom/internal/containers"
+	"github.com/jordanhubbard/loom/internal/keymanager"
+	"github.com/jordanhubbard/loom/internal/sandbox"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
@@ -77,12 +80,27 @@ type ProjectGetter interface {
 // It should ensure the container's build environment is initialised.
 type EnvReadyFunc func(ctx context.Context, projectID string, agent *containers.ProjectAgentClient)
 
+// CommandChecker vets a command before it runs. A non-nil error blocks the
+// command and is returned to the caller as is.
+type CommandChecker interface {
+	CheckCommand(ctx context.Context, projectID, agentID, beadID, command string) error
+}
+
+// SecretSource supplies the secrets granted to a project, keyed by
+// environment variable name. *keymanager.KeyManager satisfies it.
+type SecretSource interface {
+	SecretsForProject(projectID string) (map[string]string, error)
+}
+
 // ShellExecutor provides shell command execution with persistent logging
 type ShellExecutor struct {
 	db            *sql.DB
 	containerOrch *containers.Orchestrator
 	projectGetter ProjectGetter
 	envReadyHook  EnvReadyFunc
+	sandbox       *sandbox.Sandbox
+	policy        CommandChecker
+	secrets       SecretSource
 }
 
 // NewShellExecutor creates a new shell executor
@@ -105,6 +123,58 @@ func (e *ShellExecutor) SetEnvReadyHook(fn EnvReadyFunc) {
 	e.envReadyHook = fn
 }
 
+// SetCommandPolicy checks every command against a policy before it runs,
+// whether locally or in a project container.
+func (e *ShellExecutor) SetCommandPolicy(policy CommandChecker) {
+	e.policy = policy
+}
+
+// SetSecrets injects the secrets granted to each project into the
+// environment of its commands. Their values are masked in command output
+// and logs.
+func (e *ShellExecutor) SetSecrets(secrets SecretSource) {
+	e.secrets = secrets
+}
+
+// projectSecrets returns the secrets granted to a project. A key store
+// that can't be read leaves commands without secrets rather than failing
+// them.
+func (e *ShellExecutor) projectSecrets(projectID string) map[string]string {
+	if e.secrets == nil || projectID == "" {
+		return nil
+	}
+	secrets, err := e.secrets.SecretsForProject(projectID)
+	if err != nil {
+		log.Printf("[ShellExecutor] Warning: Failed to load secrets for project %s: %v", projectID, err)
+		return nil
+	}
+	return secrets
+}
+
+// SetSandbox runs commands under the resource limits of each project's
+// sandbox profile. Without a sandbox, commands only get a timeout.
+func (e *ShellExecutor) SetSandbox(sb *sandbox.Sandbox, projGetter ProjectGetter) {
+	e.sandbox = sb
+	if e.projectGetter == nil {
+		e.projectGetter = projGetter
+	}
+}
+
+// sandboxLimits returns the sandbox profile and limits for a request's
+// project, with the timeout resolved against the profile's.
+func (e *ShellExecutor) sandboxLimits(project *models.Project, timeout int) (string, sandbox.Limits) {
+	if e.sandbox == nil {
+		return "", sandbox.Limits{TimeoutSeconds: (sandbox.Limits{}).Timeout(timeout)}
+	}
+	var projectContext map[string]string
+	if project != nil {
+		projectContext = project.Context
+	}
+	profile, limits := e.sandbox.Resolve(projectContext)
+	limits.TimeoutSeconds = limits.Timeout(timeout)
+	return profile, limits
+}
+
 // validateCommand checks if a command is allowed and returns the parsed command parts
 func validateCommand(command string) ([]string, bool, error) {
 	// Empty command check
@@ -114,7 +184,7 @@ func validateCommand(command string) ([]string, bool, error) {
 	}
 
 	// Check for shell metacharacters that require shell interpretation
-	shellMetachars := []string{"|", "&&", "||", ";", ">", "<", "&", "`", "$(", "\"", "'", "\\"}
+	shellMetachars := []string{"|", "&&", "||", ";", ">", "<", "&", "`", "$", "\"", "'", "\\"}
 	requiresShell := false
 	for _, meta := range shellMetachars {
 		if strings.Contains(command, meta) {
@@ -175,6 +245,9 @@ type ExecuteCommandResult struct {
 	CompletedAt time.Time `json:"completed_at"`
 	Success     bool      `json:"success"`
 	Error       string    `json:"error,omitempty"`
+	// Sandbox reports the limits profile the command ran under and which
+	// limit, if any, stopped it.
+	Sandbox *sandbox.Report `json:"sandbox,omitempty"`
 }
 
 // ExecuteCommand executes a shell command and logs it to the database
@@ -183,14 +256,24 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 		return nil, fmt.Errorf("command is required")
 	}
 
-	// Check if project uses containers and route accordingly
-	if e.containerOrch != nil && e.projectGetter != nil && req.ProjectID != "" {
-		project, err := e.projectGetter.GetProject(req.ProjectID)
-		if err == nil && project != nil && project.UseContainer {
-			log.Printf("[ShellExecutor] Routing command to container for project %s", req.ProjectID)
-			return e.executeInContainer(ctx, req)
+	var project *models.Project
+	if e.projectGetter != nil && req.ProjectID != "" {
+		project, _ = e.projectGetter.GetProject(req.ProjectID)
+	}
+	profile, limits := e.sandboxLimits(project, req.Timeout)
+
+	if e.policy != nil {
+		if err := e.policy.CheckCommand(ctx, req.ProjectID, req.AgentID, req.BeadID, req.Command); err != nil {
+			return nil, err
 		}
 	}
+	secrets := e.projectSecrets(req.ProjectID)
+
+	// Check if project uses containers and route accordingly
+	if e.containerOrch != nil && project != nil && project.UseContainer {
+		log.Printf("[ShellExecutor] Routing command to container for project %s", req.ProjectID)
+		return e.executeInContainer(ctx, req, profile, limits, secrets)
+	}
 
 	// Validate command against allowlist
 	parts, requiresShell, err := validateCommand(req.Command)
@@ -198,11 +281,7 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 		return nil, fmt.Errorf("command validation failed: %w", err)
 	}
 
-	// Set default timeout if not specified
-	timeout := req.Timeout
-	if timeout <= 0 {
-		timeout = 300 // 5 minutes default
-	}
+	timeout := limits.TimeoutSeconds
 
 	// Set default working directory
 	workingDir := req.WorkingDir
@@ -216,7 +295,7 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 		AgentID:    req.AgentID,
 		BeadID:     req.BeadID,
 		ProjectID:  req.ProjectID,
-		Command:    req.Command,
+		Command:    keymanager.Mask(req.Command, secrets),
 		WorkingDir: workingDir,
 		Context:    req.Context,
 		StartedAt:  time.Now(),
@@ -228,7 +307,7 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 	defer cancel()
 
 	// Execute command
-	log.Printf("[ShellExecutor] Executing command for agent=%s bead=%s: %s", req.AgentID, req.BeadID, req.Command)
+	log.Printf("[ShellExecutor] Executing command for agent=%s bead=%s: %s", req.AgentID, req.BeadID, cmdLog.Command)
 
 	var cmd *exec.Cmd
 	if requiresShell {
@@ -241,19 +320,37 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 		cmd = exec.CommandContext(cmdCtx, parts[0], parts[1:]...)
 	}
 	cmd.Dir = workingDir
+	if len(secrets) > 0 {
+		cmd.Env = os.Environ()
+		for name, value := range secrets {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	startTime := time.Now()
-	err = cmd.Run()
+	var report *sandbox.Report
+	if e.sandbox != nil {
+		var r sandbox.Report
+		r, err = e.sandbox.Run(cmdCtx, cmd, limits)
+		r.Profile = profile
+		report = &r
+	} else {
+		err = cmd.Run()
+	}
 	endTime := time.Now()
 	duration := endTime.Sub(startTime).Milliseconds()
+	if report != nil && report.Exceeded != "" {
+		stderr.WriteString("\n[loom] " + report.String() + "\n")
+		log.Printf("[ShellExecutor] Command %s", report)
+	}
 
 	// Update command log with results
-	cmdLog.Stdout = stdout.String()
-	cmdLog.Stderr = stderr.String()
+	cmdLog.Stdout = keymanager.Mask(stdout.String(), secrets)
+	cmdLog.Stderr = keymanager.Mask(stderr.String(), secrets)
 	cmdLog.CompletedAt = endTime
 	cmdLog.Duration = duration
 
@@ -292,7 +389,7 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 	// Build result
 	result := &ExecuteCommandResult{
 		ID:          cmdLog.ID,
-		Command:     req.Command,
+		Command:     cmdLog.Command,
 		ExitCode:    cmdLog.ExitCode,
 		Stdout:      cmdLog.Stdout,
 		Stderr:      cmdLog.Stderr,
@@ -300,10 +397,14 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 		StartedAt:   startTime,
 		CompletedAt: endTime,
 		Success:     cmdLog.ExitCode == 0,
+		Sandbox:     report,
 	}
 
 	if err != nil {
-		result.Error = err.Error()
+		result.Error = keymanager.Mask(err.Error(), secrets)
+	}
+	if report != nil && report.Exceeded != "" {
+		result.Error = report.String()
 	}
 
 	log.Printf("[ShellExecutor] Command completed: exit_code=%d duration=%dms", cmdLog.ExitCode, duration)
@@ -311,8 +412,10 @@ func (e *ShellExecutor) ExecuteCommand(ctx context.Context, req ExecuteCommandRe
 	return result, nil
 }
 
-// executeInContainer routes command execution to a project container synchronously.
-func (e *ShellExecutor) executeInContainer(ctx context.Context, req ExecuteCommandRequest) (*ExecuteCommandResult, error) {
+// executeInContainer routes command execution to a project container
+// synchronously. The project agent applies the sandbox limits inside the
+// container.
+func (e *ShellExecutor) executeInContainer(ctx context.Context, req ExecuteCommandRequest, profile string, limits sandbox.Limits, secrets map[string]string) (*ExecuteCommandResult, error) {
 	// Get container agent client for this project
 	agentInterface, err := e.containerOrch.GetAgent(req.ProjectID)
 	if err != nil {
@@ -327,11 +430,7 @@ func (e *ShellExecutor) executeInContainer(ctx context.Context, req ExecuteComma
 		}
 	}
 
-	// Set default timeout if not specified
-	timeout := req.Timeout
-	if timeout <= 0 {
-		timeout = 300 // 5 minutes default
-	}
+	timeout := limits.TimeoutSeconds
 
 	// Container working directory is always /workspace — the loom server's
 	// internal paths (e.g. /app/data/projects/X/main) don't exist inside
@@ -344,25 +443,37 @@ func (e *ShellExecutor) executeInContainer(ctx context.Context, req ExecuteComma
 
 	taskID := fmt.Sprintf("cmd-%s", uuid.New().String()[:8])
 	startTime := time.Now()
-	log.Printf("[ShellExecutor] Executing command in container for project %s: %s", req.ProjectID, req.Command)
+	command := keymanager.Mask(req.Command, secrets)
+	log.Printf("[ShellExecutor] Executing command in container for project %s: %s", req.ProjectID, command)
 
 	// Use ExecSync for synchronous execution with full output capture
-	execResult, execErr := agentInterface.ExecSync(ctx, req.Command, workingDir, timeout)
+	var execResult *containers.ExecResult
+	var execErr error
+	if pac, ok := agentInterface.(*containers.ProjectAgentClient); ok {
+		opts := containers.ExecOptions{Timeout: timeout, Env: secrets}
+		if e.sandbox != nil {
+			opts.Limits = &limits
+		}
+		execResult, execErr = pac.Exec(ctx, req.Command, workingDir, opts)
+	} else {
+		execResult, execErr = agentInterface.ExecSync(ctx, req.Command, workingDir, timeout)
+	}
 	endTime := time.Now()
 	duration := endTime.Sub(startTime).Milliseconds()
 
 	if execErr != nil {
+		errText := keymanager.Mask(execErr.Error(), secrets)
 		result := &ExecuteCommandResult{
 			ID:          taskID,
-			Command:     req.Command,
+			Command:     command,
 			ExitCode:    -1,
 			Stdout:      "",
-			Stderr:      execErr.Error(),
+			Stderr:      errText,
 			Duration:    duration,
 			StartedAt:   startTime,
 			CompletedAt: endTime,
 			Success:     false,
-			Error:       execErr.Error(),
+			Error:       errText,
 		}
 		e.logCommandToDB(req, result)
 		return result, fmt.Errorf("container execution failed: %w", execErr)
@@ -370,18 +481,25 @@ func (e *ShellExecutor) executeInContainer(ctx context.Context, req ExecuteComma
 
 	result := &ExecuteCommandResult{
 		ID:          taskID,
-		Command:     req.Command,
+		Command:     command,
 		ExitCode:    execResult.ExitCode,
-		Stdout:      execResult.Stdout,
-		Stderr:      execResult.Stderr,
+		Stdout:      keymanager.Mask(execResult.Stdout, secrets),
+		Stderr:      keymanager.Mask(execResult.Stderr, secrets),
 		Duration:    duration,
 		StartedAt:   startTime,
 		CompletedAt: endTime,
 		Success:     execResult.ExitCode == 0,
+		Sandbox:     execResult.Sandbox,
 	}
 	if execResult.ExitCode != 0 {
 		result.Error = fmt.Sprintf("exit code %d", execResult.ExitCode)
 	}
+	if result.Sandbox != nil {
+		result.Sandbox.Profile = profile
+		if result.Sandbox.Exceeded != "" {
+			result.Error = result.Sandbox.String()
+		}
+	}
 
 	e.logCommandToDB(req, result)
 	log.Printf("[ShellExecutor] Container command exit=%d duration=%dms", execResult.ExitCode, duration)
@@ -407,7 +525,7 @@ func (e *ShellExecutor) logCommandToDB(req ExecuteCommandRequest, result *Execut
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := e.db.Exec(insertQuery,
-		result.ID, req.AgentID, req.BeadID, req.ProjectID, req.Command,
+		result.ID, req.AgentID, req.BeadID, req.ProjectID, result.Command,
 		req.WorkingDir, result.ExitCode, result.Stdout, result.Stderr, result.Duration,
 		result.StartedAt, result.CompletedAt, contextJSON, time.Now(),
 	)
@@ -0,0 +1,306 @@
+// Package export ships operational data to object storage on a schedule,
+// so long-term reporting can run against the exported objects instead of
+// the operational database. Each configured export writes its datasets as
+// gzipped JSON lines to its own destination. Incremental datasets export
+// what was recorded since the previous run; snapshot datasets are exported
+// whole every run.
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/logging"
+	"github.com/jordanhubbard/loom/internal/objectstore"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// DefaultInterval is how often an export runs when the config doesn't say.
+const DefaultInterval = 24 * time.Hour
+
+// maxRecordsPerObject caps the records in one exported object; larger runs
+// are split into numbered parts.
+const maxRecordsPerObject = 50000
+
+// cursorKeyPrefix prefixes the config_kv keys export progress is kept in.
+const cursorKeyPrefix = "export:"
+
+// Dataset is something that can be exported.
+type Dataset struct {
+	Name string
+	// Snapshot datasets are exported whole on every run. Otherwise Read
+	// only yields the records stored in [from, to).
+	Snapshot bool
+	// Read calls emit for each record to export.
+	Read func(ctx context.Context, from, to time.Time, emit func(record interface{}) error) error
+}
+
+// TableDataset exports the rows of a table by a timestamp column. Each
+// row becomes a JSON object keyed by column name.
+func TableDataset(db *sql.DB, name, table, timeColumn string) Dataset {
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s >= $1 AND %s < $2 ORDER BY %s", table, timeColumn, timeColumn, timeColumn)
+	return Dataset{
+		Name: name,
+		Read: func(ctx context.Context, from, to time.Time, emit func(interface{}) error) error {
+			rows, err := db.QueryContext(ctx, query, from, to)
+			if err != nil {
+				return fmt.Errorf("failed to query %s: %w", table, err)
+			}
+			defer rows.Close()
+			columns, err := rows.Columns()
+			if err != nil {
+				return err
+			}
+			for rows.Next() {
+				values := make([]interface{}, len(columns))
+				ptrs := make([]interface{}, len(columns))
+				for i := range values {
+					ptrs[i] = &values[i]
+				}
+				if err := rows.Scan(ptrs...); err != nil {
+					return fmt.Errorf("failed to scan %s: %w", table, err)
+				}
+				record := make(map[string]interface{}, len(columns))
+				for i, col := range columns {
+					if b, ok := values[i].([]byte); ok {
+						record[col] = string(b)
+					} else {
+						record[col] = values[i]
+					}
+				}
+				if err := emit(record); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		},
+	}
+}
+
+// job is a configured export.
+type job struct {
+	name     string
+	interval time.Duration
+	datasets []Dataset
+	store    objectstore.Store
+}
+
+// Manager runs the configured exports.
+type Manager struct {
+	db   *database.Database
+	jobs []*job
+	now  func() time.Time
+}
+
+// NewManager creates a manager for the exports in cfgs, drawing on the
+// datasets available by name. It returns nil when there is no database or
+// nothing to export, and an error when an export is misconfigured.
+func NewManager(db *database.Database, cfgs []config.ExportConfig, available []Dataset) (*Manager, error) {
+	if db == nil || len(cfgs) == 0 {
+		return nil, nil
+	}
+	byName := make(map[string]Dataset, len(available))
+	for _, ds := range available {
+		byName[ds.Name] = ds
+	}
+	m := &Manager{db: db, now: time.Now}
+	seen := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("export name is required")
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("duplicate export %q", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		switch strings.ToLower(cfg.Format) {
+		case "", "jsonl":
+		case "parquet":
+			return nil, fmt.Errorf("export %q: parquet is not supported yet, use jsonl", cfg.Name)
+		default:
+			return nil, fmt.Errorf("export %q: unknown format %q", cfg.Name, cfg.Format)
+		}
+		if len(cfg.Datasets) == 0 {
+			return nil, fmt.Errorf("export %q: no datasets", cfg.Name)
+		}
+		j := &job{name: cfg.Name, interval: cfg.Interval}
+		if j.interval <= 0 {
+			j.interval = DefaultInterval
+		}
+		for _, name := range cfg.Datasets {
+			ds, ok := byName[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("export %q: unknown dataset %q", cfg.Name, name)
+			}
+			j.datasets = append(j.datasets, ds)
+		}
+		store, err := objectstore.New(cfg.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("export %q: %w", cfg.Name, err)
+		}
+		if store == nil {
+			return nil, fmt.Errorf("export %q: destination target is required", cfg.Name)
+		}
+		j.store = store
+		m.jobs = append(m.jobs, j)
+	}
+	return m, nil
+}
+
+func logger() *slog.Logger { return logging.For("export") }
+
+// Run runs each export on its schedule until ctx is done. An export that
+// is overdue, e.g. because loom was down, runs right away.
+func (m *Manager) Run(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, j := range m.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			m.schedule(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+func (m *Manager) schedule(ctx context.Context, j *job) {
+	for {
+		wait := time.Duration(0)
+		if last, ok := m.lastRun(j); ok {
+			wait = time.Until(last.Add(j.interval))
+		}
+		timer := time.NewTimer(max(wait, 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := m.RunExport(ctx, j.name); err != nil && ctx.Err() == nil {
+			logger().Error("export failed", "export", j.name, "error", err)
+			// Don't retry in a tight loop; try again next interval.
+			_ = m.db.SetConfigValue(m.key(j, "last_run"), m.now().UTC().Format(time.RFC3339Nano))
+		}
+	}
+}
+
+// RunExport runs the named export now.
+func (m *Manager) RunExport(ctx context.Context, name string) error {
+	for _, j := range m.jobs {
+		if j.name == name {
+			return m.run(ctx, j)
+		}
+	}
+	return fmt.Errorf("unknown export %q", name)
+}
+
+func (m *Manager) run(ctx context.Context, j *job) error {
+	to := m.now().Round(0)
+	for _, ds := range j.datasets {
+		var from time.Time
+		if !ds.Snapshot {
+			from = m.cursor(j, ds)
+		}
+		objects, records, err := m.exportDataset(ctx, j, ds, from, to)
+		if err != nil {
+			return fmt.Errorf("dataset %s: %w", ds.Name, err)
+		}
+		if !ds.Snapshot {
+			if err := m.db.SetConfigValue(m.key(j, ds.Name), to.UTC().Format(time.RFC3339Nano)); err != nil {
+				return err
+			}
+		}
+		logger().Info("dataset exported", "export", j.name, "dataset", ds.Name, "records", records, "objects", objects)
+	}
+	return m.db.SetConfigValue(m.key(j, "last_run"), to.UTC().Format(time.RFC3339Nano))
+}
+
+// exportDataset writes the records of ds in [from, to) as one or more
+// objects named after to, and returns how many objects and records it
+// wrote. Nothing is written when there are no records.
+func (m *Manager) exportDataset(ctx context.Context, j *job, ds Dataset, from, to time.Time) (int, int, error) {
+	var (
+		buf     bytes.Buffer
+		zw      *gzip.Writer
+		enc     *json.Encoder
+		pending int
+		objects int
+		records int
+	)
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		objects++
+		key := fmt.Sprintf("%s/%s/%s-%s-%04d.jsonl.gz", ds.Name, to.UTC().Format("2006/01/02"),
+			ds.Name, to.UTC().Format("20060102T150405Z"), objects)
+		if _, err := j.store.Put(ctx, key, buf.Bytes(), "application/gzip"); err != nil {
+			return err
+		}
+		buf.Reset()
+		pending = 0
+		return nil
+	}
+	err := ds.Read(ctx, from, to, func(record interface{}) error {
+		if pending == 0 {
+			zw = gzip.NewWriter(&buf)
+			enc = json.NewEncoder(zw)
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		pending++
+		records++
+		if pending >= maxRecordsPerObject {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	return objects, records, err
+}
+
+func (m *Manager) key(j *job, name string) string {
+	return cursorKeyPrefix + j.name + ":" + name
+}
+
+// cursor returns where the next export of ds starts: the end of the
+// previous one, or the zero time the first time.
+func (m *Manager) cursor(j *job, ds Dataset) time.Time {
+	t, _ := m.readTime(m.key(j, ds.Name))
+	return t
+}
+
+func (m *Manager) lastRun(j *job) (time.Time, bool) {
+	return m.readTime(m.key(j, "last_run"))
+}
+
+func (m *Manager) readTime(key string) (time.Time, bool) {
+	raw, ok, err := m.db.GetConfigValue(key)
+	if err != nil || !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// Stored timestamps compare in local time on SQLite.
+	return t.Local(), true
+}
@@ -48,6 +48,10 @@ type SearchMatch struct {
 type PatchResult struct {
 	Applied bool   `json:"applied"`
 	Output  string `json:"output,omitempty"`
+	// Strategy, Files and Conflicts are set by ApplyPatchWithFallback.
+	Strategy  string   `json:"strategy,omitempty"`
+	Files     []string `json:"files,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty"`
 }
 
 type WriteResult struct {
@@ -291,25 +295,27 @@ func extractPatchFiles(patch string) ([]string, error) {
 	return files, nil
 }
 
-func (m *Manager) ApplyPatch(ctx context.Context, projectID, patch string) (*PatchResult, error) {
+// preparePatch checks a patch is safe to apply to the project and returns
+// the project's work directory and the files the patch touches.
+func (m *Manager) preparePatch(projectID, patch string) (string, []string, error) {
 	if strings.TrimSpace(patch) == "" {
-		return nil, fmt.Errorf("patch is required")
+		return "", nil, fmt.Errorf("patch is required")
 	}
 
 	// Validate patch size (prevent DoS)
 	if len(patch) > 10*1024*1024 { // 10MB limit
-		return nil, fmt.Errorf("patch too large (max 10MB)")
+		return "", nil, fmt.Errorf("patch too large (max 10MB)")
 	}
 
 	workDir, err := m.resolveWorkDir(projectID)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	// Extract and validate all files in the patch
 	files, err := extractPatchFiles(patch)
 	if err != nil {
-		return nil, fmt.Errorf("invalid patch format: %w", err)
+		return "", nil, fmt.Errorf("invalid patch format: %w", err)
 	}
 
 	// Validate each file path
@@ -317,12 +323,12 @@ func (m *Manager) ApplyPatch(ctx context.Context, projectID, patch string) (*Pat
 		// Use safeJoin to validate path is within project
 		fullPath, err := safeJoin(workDir, file)
 		if err != nil {
-			return nil, fmt.Errorf("patch modifies unauthorized file: %s (%w)", file, err)
+			return "", nil, fmt.Errorf("patch modifies unauthorized file: %s (%w)", file, err)
 		}
 
 		// Check if path is blocked (e.g., .git, .env)
 		if isBlockedPath(fullPath) {
-			return nil, fmt.Errorf("patch modifies blocked file: %s", file)
+			return "", nil, fmt.Errorf("patch modifies blocked file: %s", file)
 		}
 
 		// Additional sensitive file checks
@@ -330,10 +336,18 @@ func (m *Manager) ApplyPatch(ctx context.Context, projectID, patch string) (*Pat
 		sensitivePatterns := []string{".env", "secret", "password", "key", "token", "credentials"}
 		for _, pattern := range sensitivePatterns {
 			if strings.Contains(lowercaseFile, pattern) {
-				return nil, fmt.Errorf("patch modifies potentially sensitive file: %s", file)
+				return "", nil, fmt.Errorf("patch modifies potentially sensitive file: %s", file)
 			}
 		}
 	}
+	return workDir, files, nil
+}
+
+func (m *Manager) ApplyPatch(ctx context.Context, projectID, patch string) (*PatchResult, error) {
+	workDir, _, err := m.preparePatch(projectID, patch)
+	if err != nil {
+		return nil, err
+	}
 
 	// First, check if patch is valid without applying it
 	checkCmd := exec.CommandContext(ctx, "git", "apply", "--check", "--whitespace=nowarn", "-")
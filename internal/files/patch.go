@@ -0,0 +1,175 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Strategies ApplyPatchWithFallback applies a patch with, in the order it
+// tries them.
+const (
+	// StrategyApply applies the patch as it is.
+	StrategyApply = "apply"
+	// StrategyFuzzy needs only one line of context to match and ignores
+	// whitespace changes, for patches whose surroundings have moved on.
+	StrategyFuzzy = "fuzzy"
+	// StrategyThreeWay merges the patch from the file versions it was made
+	// against, when the repository has them.
+	StrategyThreeWay = "3way"
+)
+
+// ErrPatchConflict is returned when a patch applies with none of the
+// strategies; the result's Conflicts name the files a three-way merge
+// conflicted in.
+var ErrPatchConflict = errors.New("patch does not apply")
+
+// ApplyPatchWithFallback applies a patch to the project atomically: every
+// file it touches changes, or none does. A patch that doesn't apply as it
+// is, is retried with less context and then as a three-way merge, and a
+// merge with conflicts is rolled back. The git index is left as it is.
+func (m *Manager) ApplyPatchWithFallback(ctx context.Context, projectID, patch string) (*PatchResult, error) {
+	workDir, files, err := m.preparePatch(projectID, patch)
+	if err != nil {
+		return nil, err
+	}
+	res := &PatchResult{Files: files}
+
+	// git apply is all-or-nothing, so a failed attempt leaves no trace.
+	var outputs []string
+	attempts := []struct {
+		strategy string
+		args     []string
+	}{
+		{StrategyApply, []string{"--recount"}},
+		{StrategyFuzzy, []string{"--recount", "-C1", "--ignore-whitespace"}},
+	}
+	for _, attempt := range attempts {
+		out, err := gitApply(ctx, workDir, patch, attempt.args...)
+		if err == nil {
+			res.Applied, res.Strategy, res.Output = true, attempt.strategy, out
+			return res, nil
+		}
+		outputs = append(outputs, attempt.strategy+": "+out)
+	}
+
+	out, conflicts, err := applyThreeWay(ctx, workDir, patch, files)
+	if err == nil {
+		res.Applied, res.Strategy, res.Output = true, StrategyThreeWay, out
+		return res, nil
+	}
+	outputs = append(outputs, StrategyThreeWay+": "+out)
+	res.Conflicts = conflicts
+	res.Output = strings.Join(outputs, "\n")
+	return res, fmt.Errorf("%w: %v", ErrPatchConflict, err)
+}
+
+// applyThreeWay applies a patch with git apply --3way, rolling the work
+// tree back if any file conflicts. It returns git's output and the
+// conflicting files. The merge runs against a scratch index holding the
+// work tree's files, so local changes take part in it and the real index
+// is never touched.
+func applyThreeWay(ctx context.Context, workDir, patch string, files []string) (string, []string, error) {
+	scratch, err := os.MkdirTemp("", "loom-patch-index-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(scratch)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(scratch, "index")}
+
+	if out, err := runGit(ctx, workDir, env, "", "read-tree", "HEAD"); err != nil {
+		return out, nil, err
+	}
+	var present []string
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(workDir, f)); err == nil {
+			present = append(present, f)
+		}
+	}
+	if len(present) > 0 {
+		if out, err := runGit(ctx, workDir, env, "", append([]string{"add", "-f", "--"}, present...)...); err != nil {
+			return out, nil, err
+		}
+	}
+
+	snap := snapshotFiles(workDir, files)
+	out, err := runGit(ctx, workDir, env, patch, "apply", "--whitespace=nowarn", "--3way", "-")
+	if err == nil {
+		return out, nil, nil
+	}
+
+	var conflicts []string
+	if unmerged, uerr := runGit(ctx, workDir, env, "", "diff", "--name-only", "--diff-filter=U"); uerr == nil && unmerged != "" {
+		conflicts = strings.Split(unmerged, "\n")
+	}
+	snap.restore()
+	return out, conflicts, err
+}
+
+// fileSnapshot holds files' contents to restore them, nil for files that
+// didn't exist.
+type fileSnapshot struct {
+	dir   string
+	files map[string]*savedFile
+}
+
+type savedFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+func snapshotFiles(dir string, files []string) *fileSnapshot {
+	snap := &fileSnapshot{dir: dir, files: make(map[string]*savedFile)}
+	for _, f := range files {
+		path := filepath.Join(dir, f)
+		info, err := os.Stat(path)
+		if err != nil {
+			snap.files[f] = nil
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		snap.files[f] = &savedFile{data: data, mode: info.Mode().Perm()}
+	}
+	return snap
+}
+
+func (s *fileSnapshot) restore() {
+	for f, saved := range s.files {
+		path := filepath.Join(s.dir, f)
+		if saved == nil {
+			_ = os.Remove(path)
+			continue
+		}
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, saved.data, saved.mode)
+	}
+}
+
+// gitApply runs git apply on a patch in dir and returns its output.
+func gitApply(ctx context.Context, dir, patch string, args ...string) (string, error) {
+	return runGit(ctx, dir, nil, patch, append(append([]string{"apply", "--whitespace=nowarn"}, args...), "-")...)
+}
+
+// runGit runs git in dir with extra environment and stdin, and returns its
+// combined output.
+func runGit(ctx context.Context, dir string, env []string, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = strings.NewReader(stdin)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
+}
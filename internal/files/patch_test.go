@@ -0,0 +1,126 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository with app.go committed and returns its
+// directory.
+func gitRepo(t *testing.T, content string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "app.go"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+// diffOf returns the patch turning app.go in dir into content, leaving the
+// file as it was.
+func diffOf(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "app.go")
+	before, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("git", "-C", dir, "diff").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(path, before, 0644)
+	return string(out)
+}
+
+func readApp(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "app.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const appSource = "package app\n\nfunc A() int {\n\treturn 1\n}\n\nfunc B() int {\n\treturn 2\n}\n\nfunc C() int {\n\treturn 3\n}\n"
+
+func TestApplyPatchWithFallback_Clean(t *testing.T) {
+	dir := gitRepo(t, appSource)
+	patch := diffOf(t, dir, strings.Replace(appSource, "return 2", "return 20", 1))
+
+	res, err := NewManager(staticResolver{dir: dir}).ApplyPatchWithFallback(context.Background(), "p", patch)
+	if err != nil {
+		t.Fatalf("ApplyPatchWithFallback() error = %v (%+v)", err, res)
+	}
+	if !res.Applied || res.Strategy != StrategyApply || len(res.Files) != 1 || res.Files[0] != "app.go" {
+		t.Errorf("result = %+v", res)
+	}
+	if !strings.Contains(readApp(t, dir), "return 20") {
+		t.Error("patch not applied")
+	}
+}
+
+func TestApplyPatchWithFallback_ThreeWay(t *testing.T) {
+	dir := gitRepo(t, appSource)
+	patch := diffOf(t, dir, strings.Replace(strings.Replace(appSource, "return 1", "return 10", 1), "func C() int", "func C() (c int)", 1))
+	// A line inside the patch's hunk has changed since the patch was made.
+	drifted := strings.Replace(appSource, "return 2", "return 20", 1)
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(drifted), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewManager(staticResolver{dir: dir}).ApplyPatchWithFallback(context.Background(), "p", patch)
+	if err != nil {
+		t.Fatalf("ApplyPatchWithFallback() error = %v (%+v)", err, res)
+	}
+	if !res.Applied || res.Strategy != StrategyThreeWay {
+		t.Errorf("result = %+v, want %s", res, StrategyThreeWay)
+	}
+	got := readApp(t, dir)
+	if !strings.Contains(got, "return 10") || !strings.Contains(got, "return 20") || !strings.Contains(got, "func C() (c int)") {
+		t.Errorf("merged file =\n%s", got)
+	}
+	if staged, _ := exec.Command("git", "-C", dir, "diff", "--cached", "--name-only").Output(); len(staged) != 0 {
+		t.Errorf("index changed: %s", staged)
+	}
+}
+
+func TestApplyPatchWithFallback_ConflictRollsBack(t *testing.T) {
+	dir := gitRepo(t, appSource)
+	patch := diffOf(t, dir, strings.Replace(appSource, "return 2", "return 20", 1)+"\nfunc D() int {\n\treturn 4\n}\n")
+	conflicting := strings.Replace(appSource, "return 2", "return 200", 1)
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte(conflicting), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewManager(staticResolver{dir: dir}).ApplyPatchWithFallback(context.Background(), "p", patch)
+	if !errors.Is(err, ErrPatchConflict) {
+		t.Fatalf("ApplyPatchWithFallback() error = %v, want ErrPatchConflict", err)
+	}
+	if res.Applied || len(res.Conflicts) != 1 || res.Conflicts[0] != "app.go" {
+		t.Errorf("result = %+v", res)
+	}
+	if got := readApp(t, dir); got != conflicting {
+		t.Errorf("file not rolled back:\n%s", got)
+	}
+	if out, _ := exec.Command("git", "-C", dir, "ls-files", "--unmerged").Output(); len(out) != 0 {
+		t.Errorf("index left unmerged: %s", out)
+	}
+}
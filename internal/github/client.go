@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
 )
@@ -391,3 +392,56 @@ func (c *Client) ListFailedWorkflowRuns(ctx context.Context, workflow string) ([
 	}
 	return runs, nil
 }
+
+// ListCheckRuns returns the check runs and commit statuses reported on the
+// head of ref, a branch, tag, or commit SHA.
+func (c *Client) ListCheckRuns(ctx context.Context, ref string) ([]CheckRun, error) {
+	ref = url.PathEscape(ref)
+	out, err := c.gh(ctx, "api", "repos/{owner}/{repo}/commits/"+ref+"/check-runs?per_page=100")
+	if err != nil {
+		return nil, err
+	}
+	var runs struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+		} `json:"check_runs"`
+	}
+	if err := json.Unmarshal(out, &runs); err != nil {
+		return nil, fmt.Errorf("parse check runs: %w", err)
+	}
+	checks := make([]CheckRun, 0, len(runs.CheckRuns))
+	for _, r := range runs.CheckRuns {
+		checks = append(checks, CheckRun{Name: r.Name, Status: r.Status, Conclusion: r.Conclusion, URL: r.HTMLURL})
+	}
+
+	out, err = c.gh(ctx, "api", "repos/{owner}/{repo}/commits/"+ref+"/status")
+	if err != nil {
+		return nil, err
+	}
+	var combined struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			State     string `json:"state"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := json.Unmarshal(out, &combined); err != nil {
+		return nil, fmt.Errorf("parse commit status: %w", err)
+	}
+	for _, s := range combined.Statuses {
+		check := CheckRun{Name: s.Context, Status: "completed", URL: s.TargetURL}
+		switch s.State {
+		case "pending":
+			check.Status = "in_progress"
+		case "success":
+			check.Conclusion = "success"
+		default: // failure, error
+			check.Conclusion = "failure"
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
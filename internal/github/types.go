@@ -23,6 +23,17 @@ type StatusCheck struct {
 	Conclusion string `json:"conclusion"`
 }
 
+// CheckRun is a CI check reported on a commit, either a check run (GitHub
+// Actions and other GitHub Apps) or a commit status (Jenkins and other
+// external CI). Status is queued, in_progress, or completed; Conclusion is
+// set once it has completed.
+type CheckRun struct {
+	Name       string
+	Status     string
+	Conclusion string
+	URL        string
+}
+
 // CreateIssueRequest holds parameters for creating a GitHub issue.
 type CreateIssueRequest struct {
 	Title  string
@@ -0,0 +1,574 @@
+// Package grpcapi provides the gRPC BeadService, AgentService and
+// EventService: the core bead and agent operations of the HTTP API for
+// clients that prefer gRPC.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/jordanhubbard/loom/api/proto/loom"
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/board"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/loom"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// methodPermissions maps each method to the permission it needs, matching
+// the permissions of the equivalent HTTP routes.
+var methodPermissions = map[string]string{
+	pb.BeadService_ListBeads_FullMethodName:  "beads:read",
+	pb.BeadService_GetBead_FullMethodName:    "beads:read",
+	pb.BeadService_CreateBead_FullMethodName: "beads:write",
+	pb.BeadService_UpdateBead_FullMethodName: "beads:write",
+	pb.BeadService_DeleteBead_FullMethodName: "beads:delete",
+	pb.BeadService_ClaimBead_FullMethodName:  "beads:write",
+	pb.AgentService_Heartbeat_FullMethodName: "agents:write",
+	pb.EventService_Subscribe_FullMethodName: "logs:read",
+}
+
+// Permission returns the permission a method of these services needs, for
+// auth.Manager's interceptors. ok is false for methods of other services.
+func Permission(fullMethod string) (permission string, ok bool) {
+	permission, ok = methodPermissions[fullMethod]
+	return permission, ok
+}
+
+// maintenanceMethods are the methods that change beads. Maintenance mode
+// rejects them as it rejects the HTTP API's writes; heartbeats go on.
+var maintenanceMethods = map[string]bool{
+	pb.BeadService_CreateBead_FullMethodName: true,
+	pb.BeadService_UpdateBead_FullMethodName: true,
+	pb.BeadService_DeleteBead_FullMethodName: true,
+	pb.BeadService_ClaimBead_FullMethodName:  true,
+}
+
+// MaintenanceInterceptor rejects calls that change beads with Unavailable
+// while mode reports the server in maintenance mode. Reads are served as
+// usual.
+func MaintenanceInterceptor(mode func() loom.ServerMode) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !maintenanceMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		m := mode()
+		if !m.Maintenance() {
+			return handler(ctx, req)
+		}
+		msg := "server is in maintenance mode; only reads are allowed"
+		if m.Reason != "" {
+			msg += ": " + m.Reason
+		}
+		return nil, status.Error(codes.Unavailable, msg)
+	}
+}
+
+// Beads is the part of the control plane BeadService uses.
+type Beads interface {
+	ListBeads(filters map[string]interface{}) ([]*models.Bead, error)
+	GetBead(id string) (*models.Bead, error)
+	CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error)
+	UpdateBead(id string, updates map[string]interface{}) (*models.Bead, error)
+	TrashBead(id, deletedBy string) (*models.Bead, error)
+	ClaimBead(beadID, agentID string) error
+	WakeProject(projectID string)
+}
+
+// Agents is the part of the agent manager AgentService uses.
+type Agents interface {
+	UpdateHeartbeat(id string) error
+	GetAgent(id string) (*models.Agent, error)
+}
+
+// Events is the part of the event bus EventService uses.
+type Events interface {
+	Subscribe(subscriberID string, filter func(*eventbus.Event) bool) *eventbus.Subscriber
+	Unsubscribe(subscriberID string)
+}
+
+// Orgs is the part of the control plane that scopes calls to the caller's
+// organization, as the HTTP API does.
+type Orgs interface {
+	// OrgProjectIDs returns the IDs of the projects orgID, within teamID
+	// when it is set, can see, or nil when organizations aren't enabled.
+	OrgProjectIDs(orgID, teamID string) ([]string, error)
+}
+
+// loomBeads adapts *loom.Loom to Beads; listing and trashing live on its
+// beads manager.
+type loomBeads struct {
+	*loom.Loom
+}
+
+func (l loomBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	return l.GetBeadsManager().ListBeads(filters)
+}
+
+func (l loomBeads) TrashBead(id, deletedBy string) (*models.Bead, error) {
+	return l.GetBeadsManager().TrashBead(id, deletedBy)
+}
+
+// loomOrgs adapts *loom.Loom to Orgs.
+type loomOrgs struct {
+	*loom.Loom
+}
+
+func (l loomOrgs) OrgProjectIDs(orgID, teamID string) ([]string, error) {
+	mgr := l.GetOrgManager()
+	if mgr == nil {
+		return nil, nil
+	}
+	projects := l.GetProjectManager().ListProjects()
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	visible, err := mgr.Scope(orgID, teamID).Visible(database.OrgResourceProjects, ids)
+	if err != nil {
+		return nil, err
+	}
+	inOrg := make([]string, 0, len(visible))
+	for _, id := range ids {
+		if visible[id] {
+			inOrg = append(inOrg, id)
+		}
+	}
+	return inOrg, nil
+}
+
+// Register registers the BeadService, AgentService and EventService backed
+// by app on s.
+func Register(s grpc.ServiceRegistrar, app *loom.Loom) {
+	var agents Agents
+	if m := app.GetAgentManager(); m != nil {
+		agents = m
+	}
+	var events Events
+	if eb := app.GetEventBus(); eb != nil {
+		events = eb
+	}
+	orgs := loomOrgs{app}
+	pb.RegisterBeadServiceServer(s, NewBeadServer(loomBeads{app}, orgs))
+	pb.RegisterAgentServiceServer(s, NewAgentServer(agents))
+	pb.RegisterEventServiceServer(s, NewEventServer(events, orgs))
+}
+
+// actor names who made a bead change for the bead's history: the caller's
+// name, else their ID, else "grpc" when auth is off.
+func actor(ctx context.Context) string {
+	if id := auth.IdentityFromContext(ctx); id != nil {
+		if id.Username != "" {
+			return id.Username
+		}
+		return id.UserID
+	}
+	return "grpc"
+}
+
+// orgProjects returns the projects the caller's organization can see, or
+// nil when the call isn't scoped to an organization.
+func orgProjects(ctx context.Context, orgs Orgs) (map[string]bool, error) {
+	id := auth.IdentityFromContext(ctx)
+	if orgs == nil || id == nil || id.OrgID == "" {
+		return nil, nil
+	}
+	ids, err := orgs.OrgProjectIDs(id.OrgID, id.TeamID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if ids == nil {
+		return nil, nil
+	}
+	visible := make(map[string]bool, len(ids))
+	for _, p := range ids {
+		visible[p] = true
+	}
+	return visible, nil
+}
+
+// beadError maps a failed bead operation to a gRPC status.
+func beadError(err error) error {
+	switch {
+	case errors.Is(err, beads.ErrBeadNotFound), strings.Contains(err.Error(), "not found"):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, board.ErrWIPLimit), errors.Is(err, beads.ErrBeadAlreadyClaimed),
+		strings.Contains(err.Error(), "already claimed"), strings.Contains(err.Error(), "already assigned"):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// BeadServer implements pb.BeadServiceServer.
+type BeadServer struct {
+	pb.UnimplementedBeadServiceServer
+	beads Beads
+	orgs  Orgs
+}
+
+// NewBeadServer creates a BeadService server. Calls made in an
+// organization only see the beads of its projects; o may be nil when
+// organizations aren't enabled.
+func NewBeadServer(b Beads, o Orgs) *BeadServer {
+	return &BeadServer{beads: b, orgs: o}
+}
+
+// checkBead returns NotFound for a bead outside the caller's organization,
+// so its existence isn't revealed.
+func (s *BeadServer) checkBead(ctx context.Context, id string) error {
+	visible, err := orgProjects(ctx, s.orgs)
+	if err != nil || visible == nil {
+		return err
+	}
+	b, err := s.beads.GetBead(id)
+	if err != nil {
+		return beadError(err)
+	}
+	if !visible[b.ProjectID] {
+		return status.Error(codes.NotFound, "bead not found: "+id)
+	}
+	return nil
+}
+
+// checkProject returns NotFound for a project outside the caller's
+// organization.
+func (s *BeadServer) checkProject(ctx context.Context, projectID string) error {
+	visible, err := orgProjects(ctx, s.orgs)
+	if err != nil || visible == nil {
+		return err
+	}
+	if !visible[projectID] {
+		return status.Error(codes.NotFound, "project not found: "+projectID)
+	}
+	return nil
+}
+
+// ListBeads returns beads matching the request's filters.
+func (s *BeadServer) ListBeads(ctx context.Context, req *pb.ListBeadsRequest) (*pb.ListBeadsResponse, error) {
+	filters := make(map[string]interface{})
+	if req.ProjectId != "" {
+		if err := s.checkProject(ctx, req.ProjectId); err != nil {
+			return nil, err
+		}
+		filters["project_id"] = req.ProjectId
+	} else {
+		visible, err := orgProjects(ctx, s.orgs)
+		if err != nil {
+			return nil, err
+		}
+		if visible != nil {
+			ids := make([]string, 0, len(visible))
+			for id := range visible {
+				ids = append(ids, id)
+			}
+			filters["project_ids"] = ids
+		}
+	}
+	if req.Status != "" {
+		filters["status"] = models.BeadStatus(req.Status)
+	}
+	if req.Type != "" {
+		filters["type"] = req.Type
+	}
+	if req.Priority != nil {
+		filters["priority"] = models.BeadPriority(req.GetPriority())
+	}
+	switch len(req.AssignedTo) {
+	case 0:
+	case 1:
+		filters["assigned_to"] = req.AssignedTo[0]
+	default:
+		filters["assigned_to"] = req.AssignedTo
+	}
+
+	list, err := s.beads.ListBeads(filters)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := make([]*pb.Bead, 0, len(list))
+	for _, b := range list {
+		out = append(out, beadToProto(b))
+	}
+	return &pb.ListBeadsResponse{Beads: out}, nil
+}
+
+// GetBead retrieves a bead by ID.
+func (s *BeadServer) GetBead(ctx context.Context, req *pb.GetBeadRequest) (*pb.GetBeadResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.checkBead(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	b, err := s.beads.GetBead(req.Id)
+	if err != nil {
+		return nil, beadError(err)
+	}
+	return &pb.GetBeadResponse{Bead: beadToProto(b)}, nil
+}
+
+// CreateBead files a new bead and wakes the project's executor.
+func (s *BeadServer) CreateBead(ctx context.Context, req *pb.CreateBeadRequest) (*pb.CreateBeadResponse, error) {
+	if req.Title == "" || req.ProjectId == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and project_id are required")
+	}
+	if err := s.checkProject(ctx, req.ProjectId); err != nil {
+		return nil, err
+	}
+	beadType := req.Type
+	if beadType == "" {
+		beadType = "task"
+	}
+	priority := models.BeadPriorityP2
+	if req.Priority != nil {
+		priority = models.BeadPriority(req.GetPriority())
+	}
+
+	b, err := s.beads.CreateBead(req.Title, req.Description, priority, beadType, req.ProjectId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if len(req.Tags) > 0 || len(req.Context) > 0 {
+		updates := map[string]interface{}{beads.UpdatedByKey: actor(ctx)}
+		if len(req.Tags) > 0 {
+			updates["tags"] = req.Tags
+		}
+		if len(req.Context) > 0 {
+			updates["context"] = req.Context
+		}
+		if b, err = s.beads.UpdateBead(b.ID, updates); err != nil {
+			return nil, beadError(err)
+		}
+	}
+
+	s.beads.WakeProject(req.ProjectId)
+	return &pb.CreateBeadResponse{Bead: beadToProto(b)}, nil
+}
+
+// UpdateBead applies the fields set on the request.
+func (s *BeadServer) UpdateBead(ctx context.Context, req *pb.UpdateBeadRequest) (*pb.UpdateBeadResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.checkBead(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{beads.UpdatedByKey: actor(ctx)}
+	if req.Title != nil {
+		updates["title"] = req.GetTitle()
+	}
+	if req.Description != nil {
+		updates["description"] = req.GetDescription()
+	}
+	if req.Status != nil {
+		updates["status"] = models.BeadStatus(req.GetStatus())
+	}
+	if req.Priority != nil {
+		updates["priority"] = models.BeadPriority(req.GetPriority())
+	}
+	if req.AssignedTo != nil {
+		updates["assigned_to"] = req.GetAssignedTo()
+	}
+	if req.Type != nil {
+		updates["type"] = req.GetType()
+	}
+	if req.Tags != nil {
+		updates["tags"] = append([]string{}, req.Tags.Values...)
+	}
+	if req.Context != nil {
+		updates["context"] = req.Context
+	}
+
+	b, err := s.beads.UpdateBead(req.Id, updates)
+	if err != nil {
+		return nil, beadError(err)
+	}
+	// As over HTTP, moving a bead to in_progress wakes the executor so a
+	// worker picks it up without waiting for the next poll.
+	if req.Status != nil && models.BeadStatus(req.GetStatus()) == models.BeadStatusInProgress {
+		s.beads.WakeProject(b.ProjectID)
+	}
+	return &pb.UpdateBeadResponse{Bead: beadToProto(b)}, nil
+}
+
+// DeleteBead moves a bead to the trash.
+func (s *BeadServer) DeleteBead(ctx context.Context, req *pb.DeleteBeadRequest) (*pb.DeleteBeadResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.checkBead(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	b, err := s.beads.TrashBead(req.Id, actor(ctx))
+	if err != nil {
+		return nil, beadError(err)
+	}
+	return &pb.DeleteBeadResponse{Bead: beadToProto(b)}, nil
+}
+
+// ClaimBead assigns a bead to an agent.
+func (s *BeadServer) ClaimBead(ctx context.Context, req *pb.ClaimBeadRequest) (*pb.ClaimBeadResponse, error) {
+	if req.BeadId == "" || req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "bead_id and agent_id are required")
+	}
+	if err := s.checkBead(ctx, req.BeadId); err != nil {
+		return nil, err
+	}
+	if err := s.beads.ClaimBead(req.BeadId, req.AgentId); err != nil {
+		return nil, beadError(err)
+	}
+	b, err := s.beads.GetBead(req.BeadId)
+	if err != nil {
+		return nil, beadError(err)
+	}
+	return &pb.ClaimBeadResponse{Bead: beadToProto(b)}, nil
+}
+
+// AgentServer implements pb.AgentServiceServer.
+type AgentServer struct {
+	pb.UnimplementedAgentServiceServer
+	agents Agents
+}
+
+// NewAgentServer creates an AgentService server.
+func NewAgentServer(a Agents) *AgentServer {
+	return &AgentServer{agents: a}
+}
+
+// Heartbeat records that an agent is alive and returns its current state.
+func (s *AgentServer) Heartbeat(_ context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if s.agents == nil {
+		return nil, status.Error(codes.Unavailable, "agent manager not available")
+	}
+	if err := s.agents.UpdateHeartbeat(req.AgentId); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	a, err := s.agents.GetAgent(req.AgentId)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &pb.HeartbeatResponse{AgentId: a.ID, Status: a.Status, CurrentBead: a.CurrentBead}, nil
+}
+
+// EventServer implements pb.EventServiceServer.
+type EventServer struct {
+	pb.UnimplementedEventServiceServer
+	events Events
+	orgs   Orgs
+}
+
+// NewEventServer creates an EventService server. Subscribers in an
+// organization only get the events of its projects; o may be nil when
+// organizations aren't enabled.
+func NewEventServer(e Events, o Orgs) *EventServer {
+	return &EventServer{events: e, orgs: o}
+}
+
+var subscriberSeq atomic.Uint64
+
+// Subscribe streams events matching the request until the client cancels.
+// The projects of the caller's organization are looked up once, when the
+// subscription starts.
+func (s *EventServer) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	if s.events == nil {
+		return status.Error(codes.Unavailable, "event bus not available")
+	}
+	visible, err := orgProjects(stream.Context(), s.orgs)
+	if err != nil {
+		return err
+	}
+	if visible != nil && req.ProjectId != "" && !visible[req.ProjectId] {
+		return status.Error(codes.NotFound, "project not found: "+req.ProjectId)
+	}
+	types := make(map[string]bool, len(req.Types))
+	for _, t := range req.Types {
+		types[t] = true
+	}
+	subscriberID := fmt.Sprintf("grpc-%d", subscriberSeq.Add(1))
+	sub := s.events.Subscribe(subscriberID, func(event *eventbus.Event) bool {
+		if req.ProjectId != "" && event.ProjectID != req.ProjectId {
+			return false
+		}
+		if visible != nil && !visible[event.ProjectID] {
+			return false
+		}
+		return len(types) == 0 || types[string(event.Type)]
+	})
+	defer s.events.Unsubscribe(subscriberID)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Channel:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(eventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func beadToProto(b *models.Bead) *pb.Bead {
+	out := &pb.Bead{
+		Id:          b.ID,
+		Type:        b.Type,
+		Title:       b.Title,
+		Description: b.Description,
+		Status:      string(b.Status),
+		Priority:    int32(b.Priority),
+		ProjectId:   b.ProjectID,
+		AssignedTo:  b.AssignedTo,
+		Parent:      b.Parent,
+		Tags:        b.Tags,
+		BlockedBy:   b.BlockedBy,
+		Blocks:      b.Blocks,
+		Context:     b.Context,
+		CreatedAtMs: unixMilli(b.CreatedAt),
+		UpdatedAtMs: unixMilli(b.UpdatedAt),
+	}
+	if b.ClosedAt != nil {
+		out.ClosedAtMs = unixMilli(*b.ClosedAt)
+	}
+	return out
+}
+
+func eventToProto(e *eventbus.Event) *pb.Event {
+	data, err := json.Marshal(e.Data)
+	if err != nil || e.Data == nil {
+		data = []byte("{}")
+	}
+	return &pb.Event{
+		Id:          e.ID,
+		Type:        string(e.Type),
+		Source:      e.Source,
+		ProjectId:   e.ProjectID,
+		TimestampMs: unixMilli(e.Timestamp),
+		DataJson:    string(data),
+	}
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
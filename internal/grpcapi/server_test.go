@@ -0,0 +1,514 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/jordanhubbard/loom/api/proto/loom"
+	"github.com/jordanhubbard/loom/internal/auth"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/loom"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type fakeBeads struct {
+	mu      sync.Mutex
+	beads   map[string]*models.Bead
+	updates []map[string]interface{}
+	woken   []string
+	filters map[string]interface{}
+}
+
+func newFakeBeads() *fakeBeads {
+	return &fakeBeads{beads: map[string]*models.Bead{}}
+}
+
+func (f *fakeBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filters = filters
+	var out []*models.Bead
+	for _, b := range f.beads {
+		if p, ok := filters["project_id"]; ok && b.ProjectID != p {
+			continue
+		}
+		if ids, ok := filters["project_ids"].([]string); ok && !slices.Contains(ids, b.ProjectID) {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (f *fakeBeads) GetBead(id string) (*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.beads[id]
+	if !ok {
+		return nil, fmt.Errorf("bead not found %s: %w", id, beads.ErrBeadNotFound)
+	}
+	return b, nil
+}
+
+func (f *fakeBeads) CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b := &models.Bead{
+		ID:          fmt.Sprintf("bd-%d", len(f.beads)+1),
+		Title:       title,
+		Description: description,
+		Priority:    priority,
+		Type:        beadType,
+		ProjectID:   projectID,
+		Status:      models.BeadStatusOpen,
+		CreatedAt:   time.Now(),
+	}
+	f.beads[b.ID] = b
+	return b, nil
+}
+
+func (f *fakeBeads) UpdateBead(id string, updates map[string]interface{}) (*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.beads[id]
+	if !ok {
+		return nil, fmt.Errorf("bead not found %s: %w", id, beads.ErrBeadNotFound)
+	}
+	f.updates = append(f.updates, updates)
+	if v, ok := updates["status"].(models.BeadStatus); ok {
+		b.Status = v
+	}
+	if v, ok := updates["title"].(string); ok {
+		b.Title = v
+	}
+	if v, ok := updates["tags"].([]string); ok {
+		b.Tags = v
+	}
+	return b, nil
+}
+
+func (f *fakeBeads) TrashBead(id, deletedBy string) (*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.beads[id]
+	if !ok {
+		return nil, fmt.Errorf("bead not found %s: %w", id, beads.ErrBeadNotFound)
+	}
+	now := time.Now()
+	b.DeletedAt, b.DeletedBy = &now, deletedBy
+	return b, nil
+}
+
+func (f *fakeBeads) ClaimBead(beadID, agentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.beads[beadID]
+	if !ok {
+		return fmt.Errorf("bead not found %s: %w", beadID, beads.ErrBeadNotFound)
+	}
+	if b.AssignedTo != "" {
+		return fmt.Errorf("failed to claim bead: %w", beads.ErrBeadAlreadyClaimed)
+	}
+	b.AssignedTo, b.Status = agentID, models.BeadStatusInProgress
+	return nil
+}
+
+func (f *fakeBeads) WakeProject(projectID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.woken = append(f.woken, projectID)
+}
+
+type fakeAgents struct {
+	agents map[string]*models.Agent
+	beats  int
+}
+
+func (f *fakeAgents) UpdateHeartbeat(id string) error {
+	if _, ok := f.agents[id]; !ok {
+		return fmt.Errorf("agent not found: %s", id)
+	}
+	f.beats++
+	return nil
+}
+
+func (f *fakeAgents) GetAgent(id string) (*models.Agent, error) {
+	a, ok := f.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent not found: %s", id)
+	}
+	return a, nil
+}
+
+// fakeOrgs maps organizations to the projects they own.
+type fakeOrgs map[string][]string
+
+func (f fakeOrgs) OrgProjectIDs(orgID, teamID string) ([]string, error) {
+	return append([]string{}, f[orgID]...), nil
+}
+
+// dial serves the services over an in-memory listener, behind authMgr's
+// interceptors when it is not nil.
+func dial(t *testing.T, b Beads, a Agents, e Events, authMgr *auth.Manager) *grpc.ClientConn {
+	return dialOrgs(t, b, a, e, authMgr, nil)
+}
+
+// dialOrgs is dial with calls scoped to organizations by o.
+func dialOrgs(t *testing.T, b Beads, a Agents, e Events, authMgr *auth.Manager, o Orgs) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	var opts []grpc.ServerOption
+	if authMgr != nil {
+		opts = append(opts,
+			grpc.UnaryInterceptor(authMgr.UnaryServerInterceptor(Permission)),
+			grpc.StreamInterceptor(authMgr.StreamServerInterceptor(Permission)))
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterBeadServiceServer(srv, NewBeadServer(b, o))
+	pb.RegisterAgentServiceServer(srv, NewAgentServer(a))
+	pb.RegisterEventServiceServer(srv, NewEventServer(e, o))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestBeadService(t *testing.T) {
+	store := newFakeBeads()
+	client := pb.NewBeadServiceClient(dial(t, store, nil, nil, nil))
+	ctx := context.Background()
+
+	if _, err := client.CreateBead(ctx, &pb.CreateBeadRequest{Title: "no project"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("create without project: code = %v, want InvalidArgument", status.Code(err))
+	}
+
+	created, err := client.CreateBead(ctx, &pb.CreateBeadRequest{
+		ProjectId: "loom",
+		Title:     "Fix the build",
+		Tags:      []string{"ci"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBead: %v", err)
+	}
+	b := created.Bead
+	if b.Type != "task" || b.Priority != 2 || b.Status != "open" {
+		t.Errorf("defaults: type=%q priority=%d status=%q", b.Type, b.Priority, b.Status)
+	}
+	if len(b.Tags) != 1 || b.Tags[0] != "ci" {
+		t.Errorf("tags = %v, want [ci]", b.Tags)
+	}
+	if b.CreatedAtMs == 0 {
+		t.Error("created_at_ms not set")
+	}
+	if len(store.woken) != 1 || store.woken[0] != "loom" {
+		t.Errorf("woken = %v, want [loom]", store.woken)
+	}
+	if got := store.updates[0][beads.UpdatedByKey]; got != "grpc" {
+		t.Errorf("actor = %v, want grpc", got)
+	}
+
+	got, err := client.GetBead(ctx, &pb.GetBeadRequest{Id: b.Id})
+	if err != nil || got.Bead.Title != "Fix the build" {
+		t.Fatalf("GetBead = %v, %v", got, err)
+	}
+	if _, err := client.GetBead(ctx, &pb.GetBeadRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("missing bead: code = %v, want NotFound", status.Code(err))
+	}
+
+	list, err := client.ListBeads(ctx, &pb.ListBeadsRequest{ProjectId: "loom", AssignedTo: []string{"a", "b"}, Priority: proto32(1)})
+	if err != nil || len(list.Beads) != 1 {
+		t.Fatalf("ListBeads = %v, %v", list, err)
+	}
+	if store.filters["priority"] != models.BeadPriorityP1 {
+		t.Errorf("priority filter = %v", store.filters["priority"])
+	}
+	if v, ok := store.filters["assigned_to"].([]string); !ok || len(v) != 2 {
+		t.Errorf("assigned_to filter = %v", store.filters["assigned_to"])
+	}
+
+	title, st := "Fix the CI build", "in_progress"
+	updated, err := client.UpdateBead(ctx, &pb.UpdateBeadRequest{Id: b.Id, Title: &title, Status: &st, Tags: &pb.StringList{}})
+	if err != nil {
+		t.Fatalf("UpdateBead: %v", err)
+	}
+	if updated.Bead.Title != title || updated.Bead.Status != st || len(updated.Bead.Tags) != 0 {
+		t.Errorf("updated bead = %+v", updated.Bead)
+	}
+	last := store.updates[len(store.updates)-1]
+	if _, ok := last["description"]; ok {
+		t.Error("unset description was sent as an update")
+	}
+	if len(store.woken) != 2 {
+		t.Errorf("moving to in_progress should wake the project, woken = %v", store.woken)
+	}
+
+	claimed, err := client.ClaimBead(ctx, &pb.ClaimBeadRequest{BeadId: b.Id, AgentId: "agent-1"})
+	if err != nil || claimed.Bead.AssignedTo != "agent-1" {
+		t.Fatalf("ClaimBead = %v, %v", claimed, err)
+	}
+	if _, err := client.ClaimBead(ctx, &pb.ClaimBeadRequest{BeadId: b.Id, AgentId: "agent-2"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("second claim: code = %v, want FailedPrecondition", status.Code(err))
+	}
+
+	if _, err := client.DeleteBead(ctx, &pb.DeleteBeadRequest{Id: b.Id}); err != nil {
+		t.Fatalf("DeleteBead: %v", err)
+	}
+	if store.beads[b.Id].DeletedBy != "grpc" {
+		t.Errorf("deleted_by = %q, want grpc", store.beads[b.Id].DeletedBy)
+	}
+}
+
+func proto32(v int32) *int32 { return &v }
+
+func TestAgentService_Heartbeat(t *testing.T) {
+	agents := &fakeAgents{agents: map[string]*models.Agent{
+		"agent-1": {ID: "agent-1", Status: "working", CurrentBead: "bd-1"},
+	}}
+	client := pb.NewAgentServiceClient(dial(t, newFakeBeads(), agents, nil, nil))
+
+	resp, err := client.Heartbeat(context.Background(), &pb.HeartbeatRequest{AgentId: "agent-1"})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if resp.Status != "working" || resp.CurrentBead != "bd-1" || agents.beats != 1 {
+		t.Errorf("Heartbeat = %+v (beats %d)", resp, agents.beats)
+	}
+	if _, err := client.Heartbeat(context.Background(), &pb.HeartbeatRequest{AgentId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown agent: code = %v, want NotFound", status.Code(err))
+	}
+}
+
+func TestEventService_Subscribe(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	client := pb.NewEventServiceClient(dial(t, newFakeBeads(), nil, bus, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Subscribe(ctx, &pb.SubscribeRequest{ProjectId: "loom", Types: []string{string(eventbus.EventTypeBeadCreated)}})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	// The subscription is set up once the server handles the call; keep
+	// publishing until the wanted event arrives.
+	go func() {
+		for ctx.Err() == nil {
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "other"})
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeAgentHeartbeat, ProjectID: "loom"})
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom", Data: map[string]interface{}{"bead_id": "bd-1"}})
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if ev.Type != string(eventbus.EventTypeBeadCreated) || ev.ProjectId != "loom" {
+		t.Errorf("event = %+v, want bead.created in loom", ev)
+	}
+	if ev.DataJson != `{"bead_id":"bd-1"}` {
+		t.Errorf("data_json = %s", ev.DataJson)
+	}
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	authMgr := auth.NewManager("test-secret")
+	viewer, err := authMgr.CreateUser("viewer", "viewer@loom.local", "viewer", "password")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	login, err := authMgr.Login(viewer.Username, "password")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	store := newFakeBeads()
+	_, _ = store.CreateBead("existing", "", models.BeadPriorityP2, "task", "loom")
+	conn := dial(t, store, nil, eventbus.NewEventBus(), authMgr)
+	beadClient := pb.NewBeadServiceClient(conn)
+
+	if _, err := beadClient.GetBead(context.Background(), &pb.GetBeadRequest{Id: "bd-1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous call: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+login.Token)
+	if _, err := beadClient.GetBead(ctx, &pb.GetBeadRequest{Id: "bd-1"}); err != nil {
+		t.Fatalf("viewer read: %v", err)
+	}
+	if _, err := beadClient.DeleteBead(ctx, &pb.DeleteBeadRequest{Id: "bd-1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("viewer delete: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	stream, err := pb.NewEventServiceClient(conn).Subscribe(context.Background(), &pb.SubscribeRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous subscribe: code = %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+func TestAuthInterceptors_Actor(t *testing.T) {
+	authMgr := auth.NewManager("test-secret")
+	login, err := authMgr.Login("admin", "admin")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	store := newFakeBeads()
+	_, _ = store.CreateBead("existing", "", models.BeadPriorityP2, "task", "loom")
+	client := pb.NewBeadServiceClient(dial(t, store, nil, nil, authMgr))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+login.Token)
+	if _, err := client.DeleteBead(ctx, &pb.DeleteBeadRequest{Id: "bd-1"}); err != nil {
+		t.Fatalf("DeleteBead: %v", err)
+	}
+	if store.beads["bd-1"].DeletedBy != "admin" {
+		t.Errorf("deleted_by = %q, want admin", store.beads["bd-1"].DeletedBy)
+	}
+}
+
+func TestOrgScoping(t *testing.T) {
+	authMgr := auth.NewManager("test-secret")
+	authMgr.SetOrgResolver(func(userID, role, requested string) (string, string, error) {
+		return requested, "", nil
+	})
+	login, err := authMgr.Login("admin", "admin")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	store := newFakeBeads()
+	own, _ := store.CreateBead("ours", "", models.BeadPriorityP2, "task", "loom")
+	other, _ := store.CreateBead("theirs", "", models.BeadPriorityP2, "task", "rival")
+	bus := eventbus.NewEventBus()
+	conn := dialOrgs(t, store, nil, bus, authMgr, fakeOrgs{"acme": {"loom"}, "globex": {"rival"}})
+	client := pb.NewBeadServiceClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+login.Token, strings.ToLower(auth.OrgRequestHeader), "acme")
+
+	list, err := client.ListBeads(ctx, &pb.ListBeadsRequest{})
+	if err != nil || len(list.Beads) != 1 || list.Beads[0].Id != own.ID {
+		t.Fatalf("ListBeads = %v, %v, want only %s", list, err, own.ID)
+	}
+	if _, err := client.ListBeads(ctx, &pb.ListBeadsRequest{ProjectId: "rival"}); status.Code(err) != codes.NotFound {
+		t.Errorf("list other org's project: code = %v, want NotFound", status.Code(err))
+	}
+	if _, err := client.GetBead(ctx, &pb.GetBeadRequest{Id: own.ID}); err != nil {
+		t.Errorf("GetBead in org: %v", err)
+	}
+
+	title := "taken over"
+	for name, call := range map[string]func() error{
+		"get": func() error {
+			_, err := client.GetBead(ctx, &pb.GetBeadRequest{Id: other.ID})
+			return err
+		},
+		"update": func() error {
+			_, err := client.UpdateBead(ctx, &pb.UpdateBeadRequest{Id: other.ID, Title: &title})
+			return err
+		},
+		"claim": func() error {
+			_, err := client.ClaimBead(ctx, &pb.ClaimBeadRequest{BeadId: other.ID, AgentId: "agent-1"})
+			return err
+		},
+		"delete": func() error {
+			_, err := client.DeleteBead(ctx, &pb.DeleteBeadRequest{Id: other.ID})
+			return err
+		},
+		"create": func() error {
+			_, err := client.CreateBead(ctx, &pb.CreateBeadRequest{Title: "planted", ProjectId: "rival"})
+			return err
+		},
+	} {
+		if err := call(); status.Code(err) != codes.NotFound {
+			t.Errorf("%s in another org: code = %v, want NotFound", name, status.Code(err))
+		}
+	}
+	if b := store.beads[other.ID]; b.Title != "theirs" || b.AssignedTo != "" || b.DeletedAt != nil {
+		t.Errorf("other org's bead changed: %+v", b)
+	}
+	if len(store.beads) != 2 {
+		t.Errorf("beads = %d, want 2", len(store.beads))
+	}
+
+	sctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	stream, err := pb.NewEventServiceClient(conn).Subscribe(sctx, &pb.SubscribeRequest{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	go func() {
+		for sctx.Err() == nil {
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "rival"})
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeBeadCreated})
+			_ = bus.Publish(&eventbus.Event{Type: eventbus.EventTypeBeadCreated, ProjectID: "loom"})
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if ev.ProjectId != "loom" {
+			t.Fatalf("event of project %q streamed to acme", ev.ProjectId)
+		}
+	}
+}
+
+func TestMaintenanceInterceptor(t *testing.T) {
+	mode := loom.ServerMode{Mode: loom.ModeMaintenance, Reason: "upgrading"}
+	intercept := MaintenanceInterceptor(func() loom.ServerMode { return mode })
+	call := func(method string) error {
+		_, err := intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+
+	for _, method := range []string{
+		pb.BeadService_CreateBead_FullMethodName,
+		pb.BeadService_UpdateBead_FullMethodName,
+		pb.BeadService_DeleteBead_FullMethodName,
+		pb.BeadService_ClaimBead_FullMethodName,
+	} {
+		err := call(method)
+		if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), "upgrading") {
+			t.Errorf("%s in maintenance: %v, want Unavailable", method, err)
+		}
+	}
+	for _, method := range []string{
+		pb.BeadService_ListBeads_FullMethodName,
+		pb.BeadService_GetBead_FullMethodName,
+		pb.AgentService_Heartbeat_FullMethodName,
+	} {
+		if err := call(method); err != nil {
+			t.Errorf("%s in maintenance: %v, want served", method, err)
+		}
+	}
+
+	mode = loom.ServerMode{Mode: loom.ModeNormal}
+	if err := call(pb.BeadService_CreateBead_FullMethodName); err != nil {
+		t.Errorf("CreateBead in normal mode: %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package keymanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// KeyStoreBackend keeps the data key that encrypts the key store's entries
+// in an external key service, so the store can be opened without a shared
+// master password. The data key is stored in .keys.json only in wrapped
+// (encrypted) form, and every open asks the backend to unwrap it.
+type KeyStoreBackend interface {
+	// Name identifies the backend in the key store file, e.g. "vault".
+	Name() string
+	// Wrap encrypts a data key.
+	Wrap(ctx context.Context, dataKey []byte) (string, error)
+	// Unwrap decrypts a data key returned by Wrap.
+	Unwrap(ctx context.Context, wrapped string) ([]byte, error)
+}
+
+// Backend names.
+const (
+	BackendLocal  = "local"
+	BackendVault  = "vault"
+	BackendAWSKMS = "aws-kms"
+)
+
+// ErrPasswordStore is returned by UnlockWithBackend for a key store that
+// is still encrypted with a password. Unlock it with the password, then
+// call MigrateToBackend.
+var ErrPasswordStore = errors.New("key store is encrypted with a password")
+
+// RotationEvent describes a completed data key rotation.
+type RotationEvent struct {
+	Backend   string    `json:"backend"`
+	Keys      int       `json:"keys"`
+	Reason    string    `json:"reason"` // "rotation" or "migration"
+	RotatedAt time.Time `json:"rotated_at"`
+}
+
+// RotationHook is called after the data key is rotated or the store is
+// migrated to a backend.
+type RotationHook func(RotationEvent)
+
+// NewBackend returns the backend the config selects, or nil for the local
+// password-protected store.
+func NewBackend(cfg *config.KeyStoreConfig) (KeyStoreBackend, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return nil, nil
+	case BackendVault:
+		return NewVaultBackend(cfg.Vault)
+	case BackendAWSKMS:
+		return NewKMSBackend(cfg.KMS)
+	default:
+		return nil, fmt.Errorf("unknown key store backend %q (use %s, %s, or %s)", cfg.Backend, BackendLocal, BackendVault, BackendAWSKMS)
+	}
+}
+
+// UnlockWithBackend opens the key store with a data key kept by backend,
+// creating the store if it doesn't exist. It returns ErrPasswordStore if
+// the store still uses a password.
+func (km *KeyManager) UnlockWithBackend(ctx context.Context, backend KeyStoreBackend) error {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if err := km.loadStore(); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to unlock key store: %w", err)
+		}
+		dataKey, err := newDataKey()
+		if err != nil {
+			return err
+		}
+		wrapped, err := backend.Wrap(ctx, dataKey)
+		if err != nil {
+			return fmt.Errorf("failed to wrap data key with %s: %w", backend.Name(), err)
+		}
+		km.store = &KeyStore{
+			Version:    "1.0",
+			Backend:    backend.Name(),
+			WrappedKey: wrapped,
+			Keys:       make(map[string]*KeyEntry),
+		}
+		if err := km.saveStore(); err != nil {
+			return fmt.Errorf("failed to initialize key store: %w", err)
+		}
+		km.open(backend, dataKey)
+		return nil
+	}
+
+	switch km.store.Backend {
+	case "":
+		return ErrPasswordStore
+	case backend.Name():
+	default:
+		return fmt.Errorf("key store is protected by the %s backend, not %s", km.store.Backend, backend.Name())
+	}
+	dataKey, err := backend.Unwrap(ctx, km.store.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("failed to unwrap data key with %s: %w", backend.Name(), err)
+	}
+	km.open(backend, dataKey)
+	return nil
+}
+
+// MigrateToBackend re-encrypts an unlocked key store under a new data key
+// kept by backend. The previous file is kept next to the store with a
+// .pre-migration suffix until an operator removes it.
+func (km *KeyManager) MigrateToBackend(ctx context.Context, backend KeyStoreBackend) error {
+	km.mu.Lock()
+	if !km.unlocked {
+		km.mu.Unlock()
+		return errors.New("key store is locked")
+	}
+
+	if previous, err := os.ReadFile(km.storePath); err == nil {
+		if err := os.WriteFile(km.storePath+".pre-migration", previous, 0600); err != nil {
+			km.mu.Unlock()
+			return fmt.Errorf("failed to back up key store: %w", err)
+		}
+	}
+	n, err := km.replaceDataKey(ctx, backend)
+	km.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	km.notify(RotationEvent{Backend: backend.Name(), Keys: n, Reason: "migration", RotatedAt: time.Now()})
+	return nil
+}
+
+// RotateDataKey re-encrypts every entry under a new data key and has the
+// backend wrap it, which also picks up any rotation of the backend's own
+// master key. Rotation needs a backend; a password store is rotated by
+// changing its password.
+func (km *KeyManager) RotateDataKey(ctx context.Context) error {
+	km.mu.Lock()
+	if !km.unlocked {
+		km.mu.Unlock()
+		return errors.New("key store is locked")
+	}
+	if km.backend == nil {
+		km.mu.Unlock()
+		return errors.New("data key rotation requires a key store backend")
+	}
+	backend := km.backend
+	n, err := km.replaceDataKey(ctx, backend)
+	km.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	km.notify(RotationEvent{Backend: backend.Name(), Keys: n, Reason: "rotation", RotatedAt: time.Now()})
+	return nil
+}
+
+// OnRotate registers a hook run after every rotation and migration, e.g.
+// to record an audit event or to tell replicas to reopen the store.
+func (km *KeyManager) OnRotate(hook RotationHook) {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.hooks = append(km.hooks, hook)
+}
+
+// StartAutoRotation rotates the data key every interval until ctx is done.
+func (km *KeyManager) StartAutoRotation(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := km.RotateDataKey(ctx); err != nil {
+					log.Printf("[KeyManager] Data key rotation failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// BackendName returns the backend protecting the key store, or "local" for
+// a password.
+func (km *KeyManager) BackendName() string {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if km.backend == nil {
+		return BackendLocal
+	}
+	return km.backend.Name()
+}
+
+// replaceDataKey re-encrypts every entry under a new data key wrapped by
+// backend and saves the store. The caller holds the write lock. Nothing
+// changes if any step fails.
+func (km *KeyManager) replaceDataKey(ctx context.Context, backend KeyStoreBackend) (int, error) {
+	dataKey, err := newDataKey()
+	if err != nil {
+		return 0, err
+	}
+	reencrypted := make(map[string]string, len(km.store.Keys))
+	for id, entry := range km.store.Keys {
+		encryptedData, err := base64.StdEncoding.DecodeString(entry.EncryptedData)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode key %s: %w", id, err)
+		}
+		plaintext, err := km.decrypt(encryptedData)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt key %s: %w", id, err)
+		}
+		ciphertext, err := encryptWith(dataKey, plaintext)
+		if err != nil {
+			return 0, fmt.Errorf("failed to re-encrypt key %s: %w", id, err)
+		}
+		reencrypted[id] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	wrapped, err := backend.Wrap(ctx, dataKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to wrap data key with %s: %w", backend.Name(), err)
+	}
+
+	previous := *km.store
+	previousData := make(map[string]string, len(km.store.Keys))
+	for id, entry := range km.store.Keys {
+		previousData[id] = entry.EncryptedData
+		entry.EncryptedData = reencrypted[id]
+	}
+	km.store.Backend = backend.Name()
+	km.store.WrappedKey = wrapped
+	km.store.PasswordSalt = ""
+	km.store.PasswordVerify = ""
+	if err := km.saveStore(); err != nil {
+		for id, entry := range km.store.Keys {
+			entry.EncryptedData = previousData[id]
+		}
+		km.store.Backend = previous.Backend
+		km.store.WrappedKey = previous.WrappedKey
+		km.store.PasswordSalt = previous.PasswordSalt
+		km.store.PasswordVerify = previous.PasswordVerify
+		return 0, fmt.Errorf("failed to save key store: %w", err)
+	}
+	km.open(backend, dataKey)
+	return len(reencrypted), nil
+}
+
+// open marks the store unlocked with a backend's data key. The caller
+// holds the write lock.
+func (km *KeyManager) open(backend KeyStoreBackend, dataKey []byte) {
+	for i := range km.password {
+		km.password[i] = 0
+	}
+	km.password = dataKey
+	km.backend = backend
+	km.unlocked = true
+}
+
+func (km *KeyManager) notify(event RotationEvent) {
+	km.mu.RLock()
+	hooks := append([]RotationHook(nil), km.hooks...)
+	km.mu.RUnlock()
+	log.Printf("[KeyManager] Data key %s complete: %d keys now protected by %s", event.Reason, event.Keys, event.Backend)
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
+
+func newDataKey() ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,165 @@
+package keymanager
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBackend wraps data keys by prefixing them with its master key version.
+type fakeBackend struct {
+	name    string
+	version int
+	wraps   int
+}
+
+func (f *fakeBackend) Name() string { return f.name }
+
+func (f *fakeBackend) Wrap(ctx context.Context, dataKey []byte) (string, error) {
+	f.wraps++
+	return "v" + string(rune('0'+f.version)) + ":" + base64.StdEncoding.EncodeToString(dataKey), nil
+}
+
+func (f *fakeBackend) Unwrap(ctx context.Context, wrapped string) ([]byte, error) {
+	i := strings.Index(wrapped, ":")
+	if i < 0 {
+		return nil, errors.New("bad ciphertext")
+	}
+	return base64.StdEncoding.DecodeString(wrapped[i+1:])
+}
+
+func TestUnlockWithBackend_NewStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "keys.json")
+	backend := &fakeBackend{name: "fake", version: 1}
+
+	km := NewKeyManager(storePath)
+	if err := km.UnlockWithBackend(context.Background(), backend); err != nil {
+		t.Fatalf("UnlockWithBackend: %v", err)
+	}
+	if err := km.StoreKey("k1", "Key", "", "value-1"); err != nil {
+		t.Fatalf("StoreKey: %v", err)
+	}
+	if km.BackendName() != "fake" {
+		t.Errorf("BackendName = %q, want fake", km.BackendName())
+	}
+
+	km2 := NewKeyManager(storePath)
+	if err := km2.Unlock("any-password"); err == nil {
+		t.Error("password unlock of a backend store succeeded, want error")
+	}
+	if err := km2.UnlockWithBackend(context.Background(), &fakeBackend{name: "other"}); err == nil {
+		t.Error("unlock with a different backend succeeded, want error")
+	}
+	if err := km2.UnlockWithBackend(context.Background(), backend); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, err := km2.GetKey("k1"); err != nil || got != "value-1" {
+		t.Errorf("GetKey after reopen = %q, %v", got, err)
+	}
+	if err := km2.ChangePassword("a", "b"); err == nil {
+		t.Error("ChangePassword on a backend store succeeded, want error")
+	}
+}
+
+func TestMigrateToBackend(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "keys.json")
+	km := NewKeyManager(storePath)
+	if err := km.Unlock("old-password"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := km.StoreKey("provider-1", "Provider", "", "sk-provider"); err != nil {
+		t.Fatalf("StoreKey: %v", err)
+	}
+	if _, err := km.SetSecret("GITHUB_TOKEN", "", "ghp_token"); err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	km.Lock()
+
+	backend := &fakeBackend{name: "fake", version: 1}
+	km = NewKeyManager(storePath)
+	if err := km.UnlockWithBackend(context.Background(), backend); !errors.Is(err, ErrPasswordStore) {
+		t.Fatalf("UnlockWithBackend = %v, want ErrPasswordStore", err)
+	}
+	if err := km.Unlock("old-password"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	var events []RotationEvent
+	km.OnRotate(func(ev RotationEvent) { events = append(events, ev) })
+	if err := km.MigrateToBackend(context.Background(), backend); err != nil {
+		t.Fatalf("MigrateToBackend: %v", err)
+	}
+	if len(events) != 1 || events[0].Reason != "migration" || events[0].Keys != 2 {
+		t.Errorf("events = %+v, want one migration of 2 keys", events)
+	}
+	if _, err := os.Stat(storePath + ".pre-migration"); err != nil {
+		t.Errorf("no backup of the password store: %v", err)
+	}
+
+	km = NewKeyManager(storePath)
+	if err := km.Unlock("old-password"); err == nil {
+		t.Error("old password still unlocks the migrated store")
+	}
+	if err := km.UnlockWithBackend(context.Background(), backend); err != nil {
+		t.Fatalf("UnlockWithBackend after migration: %v", err)
+	}
+	if got, _ := km.GetKey("provider-1"); got != "sk-provider" {
+		t.Errorf("provider key = %q after migration", got)
+	}
+	if got, _ := km.SecretsForProject(""); len(got) != 0 {
+		t.Errorf("ungranted secret returned: %v", got)
+	}
+}
+
+func TestRotateDataKey(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "keys.json")
+	backend := &fakeBackend{name: "fake", version: 1}
+	km := NewKeyManager(storePath)
+
+	if err := km.RotateDataKey(context.Background()); err == nil {
+		t.Error("RotateDataKey on a locked store succeeded, want error")
+	}
+	if err := km.UnlockWithBackend(context.Background(), backend); err != nil {
+		t.Fatalf("UnlockWithBackend: %v", err)
+	}
+	if err := km.StoreKey("k1", "Key", "", "value-1"); err != nil {
+		t.Fatalf("StoreKey: %v", err)
+	}
+	before, _ := os.ReadFile(storePath)
+
+	var events []RotationEvent
+	km.OnRotate(func(ev RotationEvent) { events = append(events, ev) })
+	backend.version = 2
+	if err := km.RotateDataKey(context.Background()); err != nil {
+		t.Fatalf("RotateDataKey: %v", err)
+	}
+	after, _ := os.ReadFile(storePath)
+	if string(before) == string(after) {
+		t.Error("store unchanged by rotation")
+	}
+	if !strings.Contains(string(after), `"wrapped_key": "v2:`) {
+		t.Error("data key not rewrapped with the backend's new master key version")
+	}
+	if len(events) != 1 || events[0].Reason != "rotation" || events[0].Keys != 1 {
+		t.Errorf("events = %+v", events)
+	}
+
+	km = NewKeyManager(storePath)
+	if err := km.UnlockWithBackend(context.Background(), backend); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got, err := km.GetKey("k1"); err != nil || got != "value-1" {
+		t.Errorf("GetKey after rotation = %q, %v", got, err)
+	}
+
+	local := NewKeyManager(filepath.Join(t.TempDir(), "local.json"))
+	if err := local.Unlock("password"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := local.RotateDataKey(context.Background()); err == nil {
+		t.Error("RotateDataKey on a password store succeeded, want error")
+	}
+}
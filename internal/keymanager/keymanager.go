@@ -23,26 +23,31 @@ type KeyEntry struct {
 	ID            string    `json:"id"`
 	Name          string    `json:"name"`
 	Description   string    `json:"description"`
-	EncryptedData string    `json:"encrypted_data"` // Base64 encoded encrypted key
+	EncryptedData string    `json:"encrypted_data"`     // Base64 encoded encrypted key
+	Projects      []string  `json:"projects,omitempty"` // Projects a secret is granted to
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // KeyStore represents the encrypted key storage
 type KeyStore struct {
-	Version        string               `json:"version"`         // Schema version
-	PasswordSalt   string               `json:"password_salt"`   // Unencrypted salt for password validation
-	PasswordVerify string               `json:"password_verify"` // Hash to verify password correctness
+	Version        string               `json:"version"`               // Schema version
+	PasswordSalt   string               `json:"password_salt"`         // Unencrypted salt for password validation
+	PasswordVerify string               `json:"password_verify"`       // Hash to verify password correctness
+	Backend        string               `json:"backend,omitempty"`     // Backend holding the data key; empty for a password
+	WrappedKey     string               `json:"wrapped_key,omitempty"` // Data key encrypted by the backend
 	Keys           map[string]*KeyEntry `json:"keys"`
 }
 
 // KeyManager manages secure storage and retrieval of provider credentials
 type KeyManager struct {
 	storePath string
-	password  []byte
+	password  []byte // the master password, or the data key with a backend
 	store     *KeyStore
 	mu        sync.RWMutex
 	unlocked  bool
+	backend   KeyStoreBackend
+	hooks     []RotationHook
 }
 
 const (
@@ -91,6 +96,11 @@ func (km *KeyManager) Unlock(password string) error {
 		}
 	}
 
+	if km.store.Backend != "" {
+		km.password = nil
+		return fmt.Errorf("key store is protected by the %s backend, not a password", km.store.Backend)
+	}
+
 	// Verify password if store already exists
 	if km.store.PasswordVerify != "" {
 		if err := km.verifyPassword(password); err != nil {
@@ -247,6 +257,7 @@ func (km *KeyManager) ListKeys() ([]*KeyEntry, error) {
 			ID:          entry.ID,
 			Name:        entry.Name,
 			Description: entry.Description,
+			Projects:    entry.Projects,
 			CreatedAt:   entry.CreatedAt,
 			UpdatedAt:   entry.UpdatedAt,
 		})
@@ -263,6 +274,9 @@ func (km *KeyManager) ChangePassword(oldPassword, newPassword string) error {
 	if !km.unlocked {
 		return errors.New("key store is locked")
 	}
+	if km.backend != nil {
+		return fmt.Errorf("key store is protected by the %s backend; rotate its data key instead", km.backend.Name())
+	}
 
 	// Verify old password
 	if err := km.verifyPassword(oldPassword); err != nil {
@@ -329,6 +343,11 @@ func (km *KeyManager) Lock() {
 
 // encrypt encrypts data using AES-GCM
 func (km *KeyManager) encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWith(km.password, plaintext)
+}
+
+// encryptWith encrypts data using AES-GCM with a key derived from secret
+func encryptWith(secret, plaintext []byte) ([]byte, error) {
 	// Generate salt
 	salt := make([]byte, saltSize)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
@@ -336,7 +355,7 @@ func (km *KeyManager) encrypt(plaintext []byte) ([]byte, error) {
 	}
 
 	// Derive key from password
-	key := pbkdf2.Key(km.password, salt, iterations, keySize, sha256.New)
+	key := pbkdf2.Key(secret, salt, iterations, keySize, sha256.New)
 
 	// Create cipher
 	block, err := aes.NewCipher(key)
@@ -369,6 +388,11 @@ func (km *KeyManager) encrypt(plaintext []byte) ([]byte, error) {
 
 // decrypt decrypts data using AES-GCM
 func (km *KeyManager) decrypt(data []byte) ([]byte, error) {
+	return decryptWith(km.password, data)
+}
+
+// decryptWith decrypts data using AES-GCM with a key derived from secret
+func decryptWith(secret, data []byte) ([]byte, error) {
 	if len(data) < saltSize {
 		return nil, errors.New("invalid encrypted data")
 	}
@@ -378,7 +402,7 @@ func (km *KeyManager) decrypt(data []byte) ([]byte, error) {
 	data = data[saltSize:]
 
 	// Derive key from password
-	key := pbkdf2.Key(km.password, salt, iterations, keySize, sha256.New)
+	key := pbkdf2.Key(secret, salt, iterations, keySize, sha256.New)
 
 	// Create cipher
 	block, err := aes.NewCipher(key)
@@ -0,0 +1,89 @@
+package keymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// TestSignV4 checks the signer against the get-vanilla case of the AWS
+// Signature Version 4 test suite.
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+}
+
+func TestKMSBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/kms/aws4_request") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			http.Error(w, `{"__type":"UnrecognizedClientException"}`, http.StatusBadRequest)
+			return
+		}
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["KeyId"] != "alias/loom" {
+			http.Error(w, `{"__type":"NotFoundException"}`, http.StatusBadRequest)
+			return
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			json.NewEncoder(w).Encode(map[string]string{"CiphertextBlob": "kms-" + req["Plaintext"]})
+		case "TrentService.Decrypt":
+			json.NewEncoder(w).Encode(map[string]string{"Plaintext": strings.TrimPrefix(req["CiphertextBlob"], "kms-")})
+		default:
+			http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	if _, err := NewKMSBackend(config.KMSKeyStoreConfig{}); err == nil {
+		t.Error("NewKMSBackend without a key ID succeeded, want error")
+	}
+	b, err := NewKMSBackend(config.KMSKeyStoreConfig{KeyID: "alias/loom", Endpoint: srv.URL})
+	if err != nil {
+		t.Fatalf("NewKMSBackend: %v", err)
+	}
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := b.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	got, err := b.Unwrap(context.Background(), wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap: %v", err)
+	}
+	if !bytes.Equal(got, dataKey) {
+		t.Errorf("Unwrap = %q, want %q", got, dataKey)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	if b, err := NewBackend(&config.KeyStoreConfig{}); b != nil || err != nil {
+		t.Errorf("NewBackend(default) = %v, %v; want nil, nil", b, err)
+	}
+	if _, err := NewBackend(&config.KeyStoreConfig{Backend: "hsm"}); err == nil {
+		t.Error("NewBackend(hsm) succeeded, want error")
+	}
+}
@@ -0,0 +1,254 @@
+package keymanager
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Secrets are named credentials granted to projects and injected into the
+// environment of the commands agents run for them, so that API keys don't
+// have to live in config files or persona prompts. They are stored
+// encrypted alongside the other keys, under IDs with the secretPrefix.
+
+// ErrSecretNotFound is returned when a secret name does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// AllProjects grants a secret to every project.
+const AllProjects = "*"
+
+const secretPrefix = "secret/"
+
+// secretNamePattern matches names usable as environment variables.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedSecretNames would break commands if a secret replaced them.
+var reservedSecretNames = map[string]bool{
+	"PATH": true, "HOME": true, "USER": true, "SHELL": true, "PWD": true,
+}
+
+// Secret describes a named secret without its value.
+type Secret struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Projects    []string  `json:"projects"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ValidateSecretName checks that a name can be used as an environment
+// variable.
+func ValidateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, and underscores, not starting with a digit", name)
+	}
+	if reservedSecretNames[strings.ToUpper(name)] {
+		return fmt.Errorf("secret name %q is reserved", name)
+	}
+	return nil
+}
+
+// SetSecret stores a secret's value, creating it or replacing the value of
+// an existing secret. Grants are kept.
+func (km *KeyManager) SetSecret(name, description, value string) (*Secret, error) {
+	if err := ValidateSecretName(name); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, errors.New("secret value is required")
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	if !km.unlocked {
+		return nil, errors.New("key store is locked")
+	}
+
+	encryptedData, err := km.encrypt([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	now := time.Now()
+	entry, exists := km.store.Keys[secretPrefix+name]
+	if !exists {
+		entry = &KeyEntry{ID: secretPrefix + name, Name: name, CreatedAt: now}
+		km.store.Keys[entry.ID] = entry
+	}
+	if description != "" || !exists {
+		entry.Description = description
+	}
+	entry.EncryptedData = base64.StdEncoding.EncodeToString(encryptedData)
+	entry.UpdatedAt = now
+
+	if err := km.saveStore(); err != nil {
+		return nil, fmt.Errorf("failed to save key store: %w", err)
+	}
+	return toSecret(entry), nil
+}
+
+// ListSecrets returns every secret, sorted by name, without values.
+func (km *KeyManager) ListSecrets() ([]*Secret, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if !km.unlocked {
+		return nil, errors.New("key store is locked")
+	}
+
+	secrets := []*Secret{}
+	for id, entry := range km.store.Keys {
+		if strings.HasPrefix(id, secretPrefix) {
+			secrets = append(secrets, toSecret(entry))
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+// DeleteSecret removes a secret and its grants.
+func (km *KeyManager) DeleteSecret(name string) error {
+	return km.updateSecret(name, func(entry *KeyEntry) bool {
+		delete(km.store.Keys, entry.ID)
+		return true
+	})
+}
+
+// GrantSecret makes a secret available to a project's commands. Granting
+// AllProjects makes it available everywhere.
+func (km *KeyManager) GrantSecret(name, projectID string) (*Secret, error) {
+	if projectID == "" {
+		return nil, errors.New("project is required")
+	}
+	var secret *Secret
+	err := km.updateSecret(name, func(entry *KeyEntry) bool {
+		defer func() { secret = toSecret(entry) }()
+		for _, p := range entry.Projects {
+			if p == projectID {
+				return false
+			}
+		}
+		entry.Projects = append(entry.Projects, projectID)
+		sort.Strings(entry.Projects)
+		entry.UpdatedAt = time.Now()
+		return true
+	})
+	return secret, err
+}
+
+// RevokeSecret removes a project's grant. Revoking AllProjects leaves the
+// grants to individual projects in place.
+func (km *KeyManager) RevokeSecret(name, projectID string) (*Secret, error) {
+	var secret *Secret
+	err := km.updateSecret(name, func(entry *KeyEntry) bool {
+		defer func() { secret = toSecret(entry) }()
+		for i, p := range entry.Projects {
+			if p == projectID {
+				entry.Projects = append(entry.Projects[:i:i], entry.Projects[i+1:]...)
+				entry.UpdatedAt = time.Now()
+				return true
+			}
+		}
+		return false
+	})
+	return secret, err
+}
+
+// SecretsForProject decrypts the secrets granted to a project, keyed by
+// name.
+func (km *KeyManager) SecretsForProject(projectID string) (map[string]string, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	if !km.unlocked {
+		return nil, errors.New("key store is locked")
+	}
+
+	secrets := make(map[string]string)
+	for id, entry := range km.store.Keys {
+		if !strings.HasPrefix(id, secretPrefix) || !grantedTo(entry, projectID) {
+			continue
+		}
+		encryptedData, err := base64.StdEncoding.DecodeString(entry.EncryptedData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secret %s: %w", entry.Name, err)
+		}
+		value, err := km.decrypt(encryptedData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", entry.Name, err)
+		}
+		secrets[entry.Name] = string(value)
+	}
+	return secrets, nil
+}
+
+// updateSecret applies fn to a secret under the write lock and saves the
+// store if fn reports a change.
+func (km *KeyManager) updateSecret(name string, fn func(entry *KeyEntry) bool) error {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	if !km.unlocked {
+		return errors.New("key store is locked")
+	}
+
+	entry, exists := km.store.Keys[secretPrefix+name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if !fn(entry) {
+		return nil
+	}
+	if err := km.saveStore(); err != nil {
+		return fmt.Errorf("failed to save key store: %w", err)
+	}
+	return nil
+}
+
+func grantedTo(entry *KeyEntry, projectID string) bool {
+	for _, p := range entry.Projects {
+		if p == AllProjects || (projectID != "" && p == projectID) {
+			return true
+		}
+	}
+	return false
+}
+
+func toSecret(entry *KeyEntry) *Secret {
+	projects := append([]string{}, entry.Projects...)
+	return &Secret{
+		Name:        entry.Name,
+		Description: entry.Description,
+		Projects:    projects,
+		CreatedAt:   entry.CreatedAt,
+		UpdatedAt:   entry.UpdatedAt,
+	}
+}
+
+// minMaskLength is the shortest secret value Mask replaces. Shorter values
+// would mangle unrelated output and are too short to be worth protecting.
+const minMaskLength = 4
+
+// Mask replaces every occurrence of a secret's value in text with
+// [masked:NAME], longest values first.
+func Mask(text string, secrets map[string]string) string {
+	if len(secrets) == 0 || text == "" {
+		return text
+	}
+	names := make([]string, 0, len(secrets))
+	for name, value := range secrets {
+		if len(value) >= minMaskLength {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(secrets[names[i]]) != len(secrets[names[j]]) {
+			return len(secrets[names[i]]) > len(secrets[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		text = strings.ReplaceAll(text, secrets[name], "[masked:"+name+"]")
+	}
+	return text
+}
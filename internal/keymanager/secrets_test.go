@@ -0,0 +1,112 @@
+package keymanager
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newUnlocked(t *testing.T, storePath string) *KeyManager {
+	t.Helper()
+	km := NewKeyManager(storePath)
+	if err := km.Unlock("test-password-123"); err != nil {
+		t.Fatalf("Failed to unlock key manager: %v", err)
+	}
+	return km
+}
+
+func TestSecrets(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "keys.json")
+	km := newUnlocked(t, storePath)
+
+	for _, name := range []string{"", "1PASSWORD", "GITHUB-TOKEN", "PATH"} {
+		if _, err := km.SetSecret(name, "", "value"); err == nil {
+			t.Errorf("SetSecret(%q) succeeded, want error", name)
+		}
+	}
+	if _, err := km.SetSecret("GITHUB_TOKEN", "", ""); err == nil {
+		t.Error("SetSecret with an empty value succeeded, want error")
+	}
+
+	if _, err := km.SetSecret("GITHUB_TOKEN", "CI token", "ghp_one"); err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	if _, err := km.SetSecret("NPM_TOKEN", "", "npm_two"); err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	if _, err := km.GrantSecret("GITHUB_TOKEN", "loom"); err != nil {
+		t.Fatalf("GrantSecret: %v", err)
+	}
+	if _, err := km.GrantSecret("NPM_TOKEN", AllProjects); err != nil {
+		t.Fatalf("GrantSecret: %v", err)
+	}
+	if _, err := km.GrantSecret("MISSING", "loom"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GrantSecret(missing) = %v, want ErrSecretNotFound", err)
+	}
+
+	// Replacing a value keeps the description and grants.
+	secret, err := km.SetSecret("GITHUB_TOKEN", "", "ghp_rotated")
+	if err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	if secret.Description != "CI token" || len(secret.Projects) != 1 {
+		t.Errorf("secret = %+v, want description and grant kept", secret)
+	}
+
+	// Secrets survive a restart.
+	km = newUnlocked(t, storePath)
+	got, err := km.SecretsForProject("loom")
+	if err != nil {
+		t.Fatalf("SecretsForProject: %v", err)
+	}
+	if len(got) != 2 || got["GITHUB_TOKEN"] != "ghp_rotated" || got["NPM_TOKEN"] != "npm_two" {
+		t.Errorf("SecretsForProject(loom) = %v", got)
+	}
+	other, _ := km.SecretsForProject("other")
+	if len(other) != 1 || other["NPM_TOKEN"] != "npm_two" {
+		t.Errorf("SecretsForProject(other) = %v, want only NPM_TOKEN", other)
+	}
+
+	if _, err := km.RevokeSecret("GITHUB_TOKEN", "loom"); err != nil {
+		t.Fatalf("RevokeSecret: %v", err)
+	}
+	if got, _ := km.SecretsForProject("loom"); len(got) != 1 {
+		t.Errorf("after revoke, SecretsForProject(loom) = %v", got)
+	}
+
+	list, err := km.ListSecrets()
+	if err != nil {
+		t.Fatalf("ListSecrets: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "GITHUB_TOKEN" || list[1].Name != "NPM_TOKEN" {
+		t.Errorf("ListSecrets = %+v", list)
+	}
+
+	if err := km.DeleteSecret("NPM_TOKEN"); err != nil {
+		t.Fatalf("DeleteSecret: %v", err)
+	}
+	if err := km.DeleteSecret("NPM_TOKEN"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("second DeleteSecret = %v, want ErrSecretNotFound", err)
+	}
+
+	km.Lock()
+	if _, err := km.SecretsForProject("loom"); err == nil {
+		t.Error("SecretsForProject on a locked store succeeded, want error")
+	}
+}
+
+func TestMask(t *testing.T) {
+	secrets := map[string]string{
+		"TOKEN":      "abc123",
+		"LONG_TOKEN": "abc123xyz",
+		"PIN":        "42",
+	}
+	got := Mask("t=abc123 long=abc123xyz pin=42", secrets)
+	want := "t=[masked:TOKEN] long=[masked:LONG_TOKEN] pin=42"
+	if got != want {
+		t.Errorf("Mask = %q, want %q", got, want)
+	}
+	if got := Mask("nothing here", nil); got != "nothing here" {
+		t.Errorf("Mask with no secrets = %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package keymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// VaultBackend wraps the data key with a HashiCorp Vault transit key.
+// Rotating the transit key in Vault takes effect for the key store at its
+// next data key rotation.
+type VaultBackend struct {
+	address   string
+	token     string
+	namespace string
+	mount     string
+	keyName   string
+	client    *http.Client
+}
+
+// NewVaultBackend creates a Vault transit backend. The address and token
+// default to VAULT_ADDR and VAULT_TOKEN, the mount to "transit", and the
+// key name to "loom".
+func NewVaultBackend(cfg config.VaultKeyStoreConfig) (*VaultBackend, error) {
+	b := &VaultBackend{
+		address:   strings.TrimRight(firstNonEmpty(cfg.Address, os.Getenv("VAULT_ADDR")), "/"),
+		token:     firstNonEmpty(cfg.Token, os.Getenv("VAULT_TOKEN")),
+		namespace: firstNonEmpty(cfg.Namespace, os.Getenv("VAULT_NAMESPACE")),
+		mount:     strings.Trim(firstNonEmpty(cfg.Mount, "transit"), "/"),
+		keyName:   firstNonEmpty(cfg.KeyName, "loom"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if b.address == "" {
+		return nil, errors.New("vault key store: address is required (key_store.vault.address or VAULT_ADDR)")
+	}
+	if b.token == "" {
+		return nil, errors.New("vault key store: token is required (key_store.vault.token or VAULT_TOKEN)")
+	}
+	return b, nil
+}
+
+// Name implements KeyStoreBackend.
+func (b *VaultBackend) Name() string { return BackendVault }
+
+// Wrap implements KeyStoreBackend.
+func (b *VaultBackend) Wrap(ctx context.Context, dataKey []byte) (string, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	err := b.post(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Ciphertext == "" {
+		return "", errors.New("vault returned no ciphertext")
+	}
+	return resp.Data.Ciphertext, nil
+}
+
+// Unwrap implements KeyStoreBackend.
+func (b *VaultBackend) Unwrap(ctx context.Context, wrapped string) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := b.post(ctx, "decrypt", map[string]string{"ciphertext": wrapped}, &resp); err != nil {
+		return nil, err
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vault plaintext: %w", err)
+	}
+	return dataKey, nil
+}
+
+// post calls a transit operation, e.g. POST /v1/transit/encrypt/loom.
+func (b *VaultBackend) post(ctx context.Context, operation string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", b.address, b.mount, operation, url.PathEscape(b.keyName))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", b.token)
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault %s request failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to decode vault %s response: %w", operation, err)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
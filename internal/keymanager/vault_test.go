@@ -0,0 +1,64 @@
+package keymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestVaultBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/v1/secret-transit/encrypt/loom-keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + req["plaintext"]}})
+		case "/v1/secret-transit/decrypt/loom-keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(req["ciphertext"], "vault:v1:")}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := NewVaultBackend(config.VaultKeyStoreConfig{Address: srv.URL}); err == nil {
+		t.Error("NewVaultBackend without a token succeeded, want error")
+	}
+
+	b, err := NewVaultBackend(config.VaultKeyStoreConfig{
+		Address: srv.URL + "/", Token: "s.token", Namespace: "team", Mount: "/secret-transit/", KeyName: "loom-keys",
+	})
+	if err != nil {
+		t.Fatalf("NewVaultBackend: %v", err)
+	}
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := b.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if !strings.HasPrefix(wrapped, "vault:v1:") {
+		t.Errorf("wrapped = %q", wrapped)
+	}
+	got, err := b.Unwrap(context.Background(), wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap: %v", err)
+	}
+	if !bytes.Equal(got, dataKey) {
+		t.Errorf("Unwrap = %q, want %q", got, dataKey)
+	}
+
+	b.token = "wrong"
+	if _, err := b.Wrap(context.Background(), dataKey); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Wrap with a bad token = %v, want a 403 error", err)
+	}
+}
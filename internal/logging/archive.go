@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+
+	"github.com/jordanhubbard/loom/internal/objectstore"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// DefaultArchiveDir is where the file archiver writes when no dir is set.
+const DefaultArchiveDir = "./data/log-archive"
+
+// Archiver stores a batch of log entries before they are pruned.
+type Archiver interface {
+	// Archive stores data (gzipped JSON lines) under name and returns
+	// where it went.
+	Archive(ctx context.Context, name string, data []byte) (string, error)
+}
+
+// NewArchiver creates the archiver cfg describes, or nil when archiving is
+// off.
+func NewArchiver(cfg config.ObjectStoreConfig) (Archiver, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultArchiveDir
+	}
+	store, err := objectstore.New(cfg)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	return &StoreArchiver{Store: store}, nil
+}
+
+// encodeArchive encodes entries as gzipped JSON lines, one LogEntry per line.
+func encodeArchive(entries []LogEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// StoreArchiver writes archives to an object store.
+type StoreArchiver struct {
+	Store objectstore.Store
+}
+
+// Archive implements Archiver.
+func (a *StoreArchiver) Archive(ctx context.Context, name string, data []byte) (string, error) {
+	return a.Store.Put(ctx, name, data, "application/gzip")
+}
+
+// FileArchiver writes archives to a local directory.
+type FileArchiver struct {
+	Dir string
+}
+
+// Archive implements Archiver.
+func (a *FileArchiver) Archive(ctx context.Context, name string, data []byte) (string, error) {
+	return (&objectstore.FileStore{Dir: a.Dir}).Put(ctx, name, data, "application/gzip")
+}
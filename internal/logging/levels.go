@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// LevelConfig is the set of log levels in effect: a default level and
+// overrides for individual subsystems (log sources such as "dispatcher").
+type LevelConfig struct {
+	Default    string            `json:"default"`
+	Subsystems map[string]string `json:"subsystems"`
+}
+
+// Levels holds the minimum log level of each subsystem. It is safe for
+// concurrent use and can be changed while the server runs.
+type Levels struct {
+	mu         sync.RWMutex
+	def        slog.Level
+	subsystems map[string]slog.Level
+}
+
+// NewLevels creates a level set that logs info and above everywhere.
+func NewLevels() *Levels {
+	return &Levels{def: slog.LevelInfo, subsystems: make(map[string]slog.Level)}
+}
+
+// ParseLevel parses debug, info, warn or error, ignoring case.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: want debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// levelName is the LogEntry level string of a slog level.
+func levelName(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarn
+	default:
+		return LogLevelError
+	}
+}
+
+// Enabled reports whether a subsystem logs at the given level.
+func (l *Levels) Enabled(subsystem string, level slog.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	min, ok := l.subsystems[subsystem]
+	if !ok {
+		min = l.def
+	}
+	return level >= min
+}
+
+// Apply replaces the level set with cfg. An empty default keeps the current
+// one; an empty subsystem level removes that subsystem's override. Nothing
+// changes when any level is invalid.
+func (l *Levels) Apply(cfg LevelConfig) error {
+	def := l.defaultLevel()
+	if cfg.Default != "" {
+		parsed, err := ParseLevel(cfg.Default)
+		if err != nil {
+			return err
+		}
+		def = parsed
+	}
+	subsystems := make(map[string]slog.Level, len(cfg.Subsystems))
+	for name, level := range cfg.Subsystems {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || level == "" {
+			continue
+		}
+		parsed, err := ParseLevel(level)
+		if err != nil {
+			return fmt.Errorf("subsystem %s: %w", name, err)
+		}
+		subsystems[name] = parsed
+	}
+
+	l.mu.Lock()
+	l.def = def
+	l.subsystems = subsystems
+	l.mu.Unlock()
+	return nil
+}
+
+// Config returns the levels in effect.
+func (l *Levels) Config() LevelConfig {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	cfg := LevelConfig{Default: levelName(l.def), Subsystems: make(map[string]string, len(l.subsystems))}
+	for name, level := range l.subsystems {
+		cfg.Subsystems[name] = levelName(level)
+	}
+	return cfg
+}
+
+func (l *Levels) defaultLevel() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.def
+}
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -37,10 +39,15 @@ type LogEntry struct {
 
 // Manager handles log collection, buffering, and persistence
 type Manager struct {
-	mu       sync.RWMutex
-	buffer   *ring.Ring
-	db       *sql.DB
-	handlers []func(LogEntry)
+	mu        sync.RWMutex
+	buffer    *ring.Ring
+	db        *sql.DB
+	handlers  []func(LogEntry)
+	levels    *Levels
+	retention RetentionPolicy
+
+	outMu sync.Mutex
+	out   io.Writer
 }
 
 // NewManager creates a new logging manager
@@ -49,6 +56,7 @@ func NewManager(db *sql.DB) *Manager {
 		buffer:   ring.New(MaxBufferSize),
 		db:       db,
 		handlers: make([]func(LogEntry), 0),
+		levels:   NewLevels(),
 	}
 
 	// Initialize database schema
@@ -116,12 +124,37 @@ func (m *Manager) initSchema() error {
 	return nil
 }
 
-// Log adds a log entry to the buffer and optionally persists it
+// Levels returns the per-subsystem log levels, which can be changed at runtime.
+func (m *Manager) Levels() *Levels {
+	return m.levels
+}
+
+// SetOutput makes every recorded entry also be written to w as one JSON
+// line in the LogEntry format the log query API returns. A nil w stops it.
+func (m *Manager) SetOutput(w io.Writer) {
+	m.outMu.Lock()
+	defer m.outMu.Unlock()
+	m.out = w
+}
+
+// Log adds a log entry to the buffer and optionally persists it. Entries
+// below the source's level are dropped; an unknown level counts as info.
 func (m *Manager) Log(level, source, message string, metadata map[string]interface{}) {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		parsed = slog.LevelInfo
+	}
+	m.log(parsed, source, message, metadata, time.Now())
+}
+
+func (m *Manager) log(level slog.Level, source, message string, metadata map[string]interface{}, ts time.Time) {
+	if !m.levels.Enabled(source, level) {
+		return
+	}
 	entry := LogEntry{
-		ID:        fmt.Sprintf("log-%d", time.Now().UnixNano()),
-		Timestamp: time.Now(),
-		Level:     level,
+		ID:        fmt.Sprintf("log-%d", ts.UnixNano()),
+		Timestamp: ts,
+		Level:     levelName(level),
 		Source:    source,
 		Message:   message,
 		Metadata:  metadata,
@@ -137,10 +170,26 @@ func (m *Manager) Log(level, source, message string, metadata map[string]interfa
 		go handler(entry)
 	}
 
+	m.writeOutput(entry)
+
 	// Persist to database asynchronously
 	go m.persistLog(entry)
 }
 
+// writeOutput writes entry to the output set with SetOutput, if any.
+func (m *Manager) writeOutput(entry LogEntry) {
+	m.outMu.Lock()
+	defer m.outMu.Unlock()
+	if m.out == nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_, _ = m.out.Write(append(data, '\n'))
+}
+
 // persistLog saves a log entry to the database
 func (m *Manager) persistLog(entry LogEntry) {
 	if m.db == nil {
@@ -176,7 +225,7 @@ func (m *Manager) persistLog(entry LogEntry) {
 	_, err := m.db.Exec(rebindQuery(`
 		INSERT INTO logs (id, timestamp, level, source, message, metadata_json, agent_id, bead_id, project_id, provider_id)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`), entry.ID, entry.Timestamp, entry.Level, entry.Source, entry.Message, metadataJSON, agentID, beadID, projectID, providerID)
+	`), entry.ID, entry.Timestamp.Round(0), entry.Level, entry.Source, entry.Message, metadataJSON, agentID, beadID, projectID, providerID)
 
 	if err != nil {
 		log.Printf("Failed to persist log entry: %v", err)
@@ -396,9 +445,14 @@ func (w *logInterceptWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-// InstallLogInterceptor redirects Go's standard log package through this manager.
+// InstallLogInterceptor makes this manager the default slog handler and
+// redirects Go's standard log package through it, so both are filtered by
+// the same levels and recorded in the same format.
 // Call this once at startup after creating the manager.
 func (m *Manager) InstallLogInterceptor() {
+	slog.SetDefault(slog.New(m.Handler()))
+	// slog.SetDefault also routes the log package into the handler; replace
+	// that with the interceptor, which parses levels and sources.
 	log.SetOutput(&logInterceptWriter{manager: m})
 	log.SetFlags(0) // We handle timestamps ourselves
 }
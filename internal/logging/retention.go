@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// DefaultRetention is how long stored log entries are kept when no
+// retention is configured.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// pruneBatchSize is how many entries are archived and deleted at a time.
+const pruneBatchSize = 5000
+
+// RetentionPolicy decides which stored log entries are pruned: those older
+// than MaxAge and those beyond the newest MaxEntries. A zero field doesn't
+// prune anything. Pruned entries go to Archiver first, when set.
+type RetentionPolicy struct {
+	MaxAge     time.Duration
+	MaxEntries int
+	Archiver   Archiver
+}
+
+// RetentionFromConfig builds the retention policy of cfg. The age limit
+// defaults to DefaultRetention; a negative retention turns it off.
+func RetentionFromConfig(cfg config.LoggingConfig) (RetentionPolicy, error) {
+	policy := RetentionPolicy{MaxAge: cfg.Retention, MaxEntries: cfg.MaxEntries}
+	if policy.MaxAge == 0 {
+		policy.MaxAge = DefaultRetention
+	} else if policy.MaxAge < 0 {
+		policy.MaxAge = 0
+	}
+	archiver, err := NewArchiver(cfg.Archive)
+	if err != nil {
+		return policy, err
+	}
+	policy.Archiver = archiver
+	return policy, nil
+}
+
+// PruneResult reports what a prune did, or would do on a dry run.
+type PruneResult struct {
+	DryRun   bool       `json:"dry_run"`
+	Matched  int        `json:"matched"`
+	Deleted  int        `json:"deleted"`
+	Oldest   *time.Time `json:"oldest,omitempty"`
+	Newest   *time.Time `json:"newest,omitempty"`
+	Archives []string   `json:"archives,omitempty"`
+}
+
+// SetRetention sets the policy EnforceRetention applies.
+func (m *Manager) SetRetention(policy RetentionPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = policy
+}
+
+// Retention returns the policy EnforceRetention applies.
+func (m *Manager) Retention() RetentionPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retention
+}
+
+// EnforceRetention prunes the stored entries the retention policy no longer
+// keeps.
+func (m *Manager) EnforceRetention(ctx context.Context) (*PruneResult, error) {
+	return m.Prune(ctx, m.Retention(), false)
+}
+
+// Prune deletes the stored entries policy doesn't keep, oldest first,
+// archiving each batch before deleting it. If archiving fails, the batch is
+// kept and Prune stops. A dry run only reports what would be pruned.
+func (m *Manager) Prune(ctx context.Context, policy RetentionPolicy, dryRun bool) (*PruneResult, error) {
+	result := &PruneResult{DryRun: dryRun}
+	if m.db == nil {
+		return result, nil
+	}
+	// Both limits prune a run of the oldest entries, so the policy comes
+	// down to how many of the oldest entries go.
+	count, err := m.pruneCount(ctx, policy)
+	if err != nil || count == 0 {
+		return result, err
+	}
+
+	if dryRun {
+		result.Matched = count
+		var oldest, newest time.Time
+		query := rebindQuery("SELECT timestamp FROM logs ORDER BY timestamp ASC, id ASC LIMIT 1 OFFSET ?")
+		if err := m.db.QueryRowContext(ctx, query, 0).Scan(&oldest); err != nil {
+			return nil, fmt.Errorf("failed to query logs: %w", err)
+		}
+		if err := m.db.QueryRowContext(ctx, query, count-1).Scan(&newest); err != nil {
+			return nil, fmt.Errorf("failed to query logs: %w", err)
+		}
+		result.Oldest, result.Newest = &oldest, &newest
+		return result, nil
+	}
+
+	query := rebindQuery(`SELECT id, timestamp, level, source, message, metadata_json FROM logs
+		ORDER BY timestamp ASC, id ASC LIMIT ?`)
+	for result.Matched < count {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		rows, err := m.db.QueryContext(ctx, query, min(pruneBatchSize, count-result.Matched))
+		if err != nil {
+			return result, fmt.Errorf("failed to query logs: %w", err)
+		}
+		batch, err := scanEntries(rows)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		result.Matched += len(batch)
+		if result.Oldest == nil {
+			result.Oldest = &batch[0].Timestamp
+		}
+		result.Newest = &batch[len(batch)-1].Timestamp
+
+		if policy.Archiver != nil {
+			data, err := encodeArchive(batch)
+			if err != nil {
+				return result, fmt.Errorf("failed to encode log archive: %w", err)
+			}
+			name := fmt.Sprintf("logs-%s-%s.jsonl.gz",
+				batch[0].Timestamp.UTC().Format("20060102T150405.000000000Z"),
+				batch[len(batch)-1].Timestamp.UTC().Format("20060102T150405.000000000Z"))
+			location, err := policy.Archiver.Archive(ctx, name, data)
+			if err != nil {
+				return result, fmt.Errorf("failed to archive logs: %w", err)
+			}
+			result.Archives = append(result.Archives, location)
+		}
+
+		ids := make([]interface{}, len(batch))
+		for i, entry := range batch {
+			ids[i] = entry.ID
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		res, err := m.db.ExecContext(ctx, rebindQuery("DELETE FROM logs WHERE id IN ("+placeholders+")"), ids...)
+		if err != nil {
+			return result, fmt.Errorf("failed to delete logs: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		result.Deleted += int(n)
+	}
+	return result, nil
+}
+
+// pruneCount returns how many of the oldest stored entries policy prunes.
+func (m *Manager) pruneCount(ctx context.Context, policy RetentionPolicy) (int, error) {
+	count := 0
+	if policy.MaxAge > 0 {
+		cutoff := time.Now().Add(-policy.MaxAge).Round(0)
+		if err := m.db.QueryRowContext(ctx, rebindQuery("SELECT COUNT(*) FROM logs WHERE timestamp < ?"), cutoff).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count logs: %w", err)
+		}
+	}
+	if policy.MaxEntries > 0 {
+		var total int
+		if err := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM logs").Scan(&total); err != nil {
+			return 0, fmt.Errorf("failed to count logs: %w", err)
+		}
+		count = max(count, total-policy.MaxEntries)
+	}
+	return count, nil
+}
+
+// scanEntries reads and closes rows of id, timestamp, level, source,
+// message and metadata_json.
+func scanEntries(rows *sql.Rows) ([]LogEntry, error) {
+	defer rows.Close()
+	logs := make([]LogEntry, 0)
+	for rows.Next() {
+		var entry LogEntry
+		var metadataJSON *string
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.Level, &entry.Source, &entry.Message, &metadataJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan log entry: %w", err)
+		}
+		if metadataJSON != nil && *metadataJSON != "" {
+			_ = json.Unmarshal([]byte(*metadataJSON), &entry.Metadata)
+		}
+		logs = append(logs, entry)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/telemetry"
+)
+
+// For returns the logger of a subsystem. Its records carry the subsystem as
+// their source, which is what per-subsystem levels and the log query API's
+// source filter match on. Entity IDs go in as attributes:
+//
+//	logging.For("dispatcher").Info("bead dispatched", "bead_id", id, "agent_id", agentID)
+func For(subsystem string) *slog.Logger {
+	return slog.Default().With("source", subsystem)
+}
+
+// Handler is a slog.Handler that records into a Manager, so slog records
+// are buffered, persisted, streamed and filtered by level exactly like
+// entries logged through the Manager directly. Attributes become the
+// entry's metadata; a "source" attribute sets its source.
+type Handler struct {
+	manager *Manager
+	source  string
+	attrs   map[string]interface{}
+	group   string
+}
+
+// Handler returns a slog handler that logs into m.
+func (m *Manager) Handler() *Handler {
+	return &Handler{manager: m, source: "system"}
+}
+
+// Logger returns the logger of a subsystem that logs into m.
+func (m *Manager) Logger(subsystem string) *slog.Logger {
+	return slog.New(m.Handler()).With("source", subsystem)
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.manager.levels.Enabled(h.source, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	source := h.source
+	metadata := make(map[string]interface{}, len(h.attrs)+r.NumAttrs()+1)
+	for k, v := range h.attrs {
+		metadata[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group == "" && a.Key == "source" {
+			source = a.Value.String()
+			return true
+		}
+		addAttr(metadata, h.group, a)
+		return true
+	})
+	if traceID := telemetry.TraceID(ctx); traceID != "" {
+		if _, ok := metadata["trace_id"]; !ok {
+			metadata["trace_id"] = traceID
+		}
+	}
+
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	h.manager.log(r.Level, source, r.Message, metadata, ts)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := h.clone()
+	for _, a := range attrs {
+		if h.group == "" && a.Key == "source" {
+			h2.source = a.Value.String()
+			continue
+		}
+		addAttr(h2.attrs, h.group, a)
+	}
+	return h2
+}
+
+// WithGroup implements slog.Handler. Grouped attributes are flattened into
+// dotted metadata keys.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.group = h.group + name + "."
+	return h2
+}
+
+func (h *Handler) clone() *Handler {
+	attrs := make(map[string]interface{}, len(h.attrs))
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+	return &Handler{manager: h.manager, source: h.source, attrs: attrs, group: h.group}
+}
+
+// addAttr adds a to metadata under prefix, flattening groups.
+func addAttr(metadata map[string]interface{}, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(metadata, p, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	switch val := v.Any().(type) {
+	case error:
+		metadata[prefix+a.Key] = val.Error()
+	case time.Duration:
+		metadata[prefix+a.Key] = val.String()
+	default:
+		metadata[prefix+a.Key] = val
+	}
+}
@@ -0,0 +1,90 @@
+package loom
+
+import (
+	"log"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/approval"
+	"github.com/jordanhubbard/loom/internal/auditlog"
+	"github.com/jordanhubbard/loom/pkg/config"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Context keys recording the auto-approval policy's decision on a code fix
+// proposal, besides its risk.
+const (
+	autoApprovalRuleKey       = "auto_approval_rule"
+	autoApprovalRejectionsKey = "auto_approval_rejections"
+)
+
+// autoApprovalMethod is the method of the audit log entries recording
+// auto-approvals.
+const autoApprovalMethod = "AUTO_APPROVE"
+
+// evaluateFixApproval runs a code fix proposal through the auto-approval
+// policy. With the policy misconfigured, proposals are only assessed.
+func (a *Loom) evaluateFixApproval(bead *models.Bead) *approval.Decision {
+	policy, maxRisk := a.fixPolicy, a.projectConfig.AutoApproveMaxRisk(bead.ProjectID)
+	if policy == nil {
+		policy, maxRisk = approval.New(config.AutoApprovalConfig{}), approval.RiskNone
+	}
+	return policy.Evaluate(approval.NewProposal(bead.ProjectID, bead.Description, a.fixTestsPassing(bead)), maxRisk)
+}
+
+// fixTestsPassing reports whether the proposing agent's last test run
+// passed, on the proposal or on the bug it fixes.
+func (a *Loom) fixTestsPassing(bead *models.Bead) bool {
+	if testsPassed(bead.Context) {
+		return true
+	}
+	bugID := extractOriginalBugID(bead.Description)
+	if bugID == "" || a.beadsManager == nil {
+		return false
+	}
+	bug, err := a.beadsManager.GetBead(bugID)
+	return err == nil && bug != nil && testsPassed(bug.Context)
+}
+
+// testsPassed reports whether a bead's last test run passed. Both times
+// are RFC 3339 in UTC, so they compare as strings.
+func testsPassed(ctx map[string]string) bool {
+	passed := ctx[actions.TestsPassedAtKey]
+	return passed != "" && passed > ctx[actions.TestFailuresAtKey]
+}
+
+// recordFixApproval records the policy's decision on the proposal's bead
+// and, for an approval, in the audit log.
+func (a *Loom) recordFixApproval(bead *models.Bead, d *approval.Decision) {
+	if err := a.beadsManager.UpdateBead(bead.ID, map[string]interface{}{
+		"context": map[string]string{
+			fixRiskKey:                d.Risk,
+			fixRiskReasonsKey:         strings.Join(d.RiskReasons, "; "),
+			autoApprovalRuleKey:       d.Rule,
+			autoApprovalRejectionsKey: strings.Join(d.Rejections, "; "),
+		},
+	}); err != nil {
+		log.Printf("[AutoApproval] Failed to record decision on %s: %v", bead.ID, err)
+	}
+
+	if !d.Approved || a.auditLog == nil {
+		return
+	}
+	after, err := auditlog.Snapshot(d)
+	if err != nil {
+		log.Printf("[AutoApproval] Failed to snapshot decision on %s: %v", bead.ID, err)
+		return
+	}
+	if err := a.auditLog.Record(&auditlog.Entry{
+		ActorID:      "system",
+		ActorName:    "auto-approval",
+		Method:       autoApprovalMethod,
+		Path:         "/api/v1/beads/" + bead.ID,
+		ResourceType: "beads",
+		ResourceID:   bead.ID,
+		StatusCode:   200,
+		After:        after,
+	}); err != nil {
+		log.Printf("[AutoApproval] Failed to audit approval of %s: %v", bead.ID, err)
+	}
+}
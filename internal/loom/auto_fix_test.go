@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/approval"
+	"github.com/jordanhubbard/loom/pkg/config"
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
@@ -108,33 +111,6 @@ func TestCreateApplyFixBead(t *testing.T) {
 	}
 }
 
-func TestAssessFixRisk(t *testing.T) {
-	tests := []struct {
-		name     string
-		desc     string
-		wantRisk string
-	}{
-		{"low risk typo", "Fix typo in variable name\nRisk Level: Low\nSingle file change", "low"},
-		{"low risk css", "CSS style fix for button color\ncosmetic change only", "low"},
-		{"medium risk multi-file", "Refactor across multiple files\nArchitecture change", "medium"},
-		{"high risk security", "Fix authentication bypass in token validation", "high"},
-		{"high risk destructive", "Delete all stale records using rm -rf /tmp/cache", "high"},
-		{"self-assessed medium", "Simple fix\nRisk Level: Medium\nOne change", "medium"},
-		{"self-assessed high", "Simple fix\nRisk Level: High\nDangerous", "high"},
-		{"no indicators", "Generic fix with no special patterns", "low"},
-		{"missing import", "Fix missing import statement", "low"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			risk, reasons := assessFixRisk(tt.desc)
-			if risk != tt.wantRisk {
-				t.Errorf("assessFixRisk() risk = %s, want %s (reasons: %v)", risk, tt.wantRisk, reasons)
-			}
-		})
-	}
-}
-
 func TestApplyFixTriggerConditions(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -199,3 +175,33 @@ func TestApplyFixTriggerConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateFixApproval(t *testing.T) {
+	bead := &models.Bead{
+		ID:          "dc-approval-002",
+		ProjectID:   "loom",
+		Description: "## Code Fix Proposal\n\n```diff\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-teh\n+the\n```\n",
+		Context: map[string]string{
+			actions.TestFailuresAtKey: "2026-10-01T10:00:00Z",
+			actions.TestsPassedAtKey:  "2026-10-01T10:05:00Z",
+		},
+	}
+	cfg := config.AutoApprovalConfig{Rules: []config.AutoApprovalRule{{Name: "docs", AllowedPaths: []string{"*.md"}, RequireTestsPassing: true}}}
+	a := &Loom{fixPolicy: approval.New(cfg)}
+
+	if d := a.evaluateFixApproval(bead); !d.Approved || d.Rule != "docs" || d.Risk != approval.RiskLow {
+		t.Errorf("evaluateFixApproval() = %+v, want approved by docs", d)
+	}
+
+	bead.Context[actions.TestFailuresAtKey] = "2026-10-01T10:10:00Z"
+	if d := a.evaluateFixApproval(bead); d.Approved {
+		t.Errorf("approved after the tests failed again: %+v", d)
+	}
+
+	// A misconfigured policy approves nothing.
+	a.fixPolicy = nil
+	bead.Context[actions.TestsPassedAtKey] = "2026-10-01T10:15:00Z"
+	if d := a.evaluateFixApproval(bead); d.Approved || d.Risk != approval.RiskLow {
+		t.Errorf("without a policy: %+v", d)
+	}
+}
@@ -0,0 +1,44 @@
+package loom
+
+import (
+	"fmt"
+
+	"github.com/jordanhubbard/loom/internal/backup"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// GetBackupManager returns the backup manager, or nil when backups have
+// no destination.
+func (a *Loom) GetBackupManager() *backup.Manager {
+	return a.backupManager
+}
+
+// backupProjects lists the beads directories of the projects that aren't
+// archived.
+func (a *Loom) backupProjects() []backup.Project {
+	var projects []backup.Project
+	for _, p := range a.projectManager.ListProjects() {
+		if p.Status == models.ProjectStatusArchived {
+			continue
+		}
+		if dir := a.beadsManager.GetProjectBeadsPath(p.ID); dir != "" {
+			projects = append(projects, backup.Project{ID: p.ID, BeadsDir: dir})
+		}
+	}
+	return projects
+}
+
+// reloadRestoredBeads reloads a project's beads after its beads directory
+// was restored from a backup, and commits the restored files to the
+// beads-sync branch.
+func (a *Loom) reloadRestoredBeads(projectID, backupID string) error {
+	dir := a.beadsManager.GetProjectBeadsPath(projectID)
+	a.beadsManager.ClearProjectBeads(projectID)
+	if err := a.beadsManager.LoadBeadsFromFilesystem(projectID, dir); err != nil {
+		return fmt.Errorf("failed to reload beads: %w", err)
+	}
+	if err := a.beadsManager.CommitBeadsDir(projectID, "Restore beads from backup "+backupID); err != nil {
+		return fmt.Errorf("failed to commit restored beads: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package loom
+
+import (
+	"context"
+	"log"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/pkg/connectors"
+)
+
+// registerConnectorActions registers the agent actions provided by
+// connectors that implement connectors.ActionProvider. An action that fails
+// to register (a clash with a built-in or another connector's action, or a
+// bad schema) is logged and skipped.
+func registerConnectorActions(mgr *connectors.Manager) {
+	for _, c := range mgr.ListConnectors() {
+		provider, ok := c.(connectors.ActionProvider)
+		if !ok {
+			continue
+		}
+		for _, spec := range provider.Actions() {
+			if err := actions.RegisterAction(connectorActionHandler(c.Name(), spec)); err != nil {
+				log.Printf("Warning: connector %s: failed to register action %s: %v", c.ID(), spec.Type, err)
+				continue
+			}
+			log.Printf("Registered action %s from connector %s", spec.Type, c.ID())
+		}
+	}
+}
+
+func connectorActionHandler(connectorName string, spec connectors.ActionSpec) actions.Handler {
+	effect := actions.EffectCommand
+	if spec.ReadOnly {
+		effect = actions.EffectRead
+	}
+	h := actions.Handler{
+		Type:        spec.Type,
+		Category:    connectorName,
+		Description: spec.Description,
+		Schema:      spec.Schema,
+		Effect:      effect,
+	}
+	if spec.Execute == nil {
+		return h // rejected by RegisterAction
+	}
+	h.Execute = func(ctx context.Context, action actions.Action, actx actions.ActionContext) actions.Result {
+		msg, output, err := spec.Execute(ctx, action.Params, connectors.ActionScope{
+			AgentID:   actx.AgentID,
+			BeadID:    actx.BeadID,
+			ProjectID: actx.ProjectID,
+		})
+		if err != nil {
+			return actions.Result{ActionType: action.Type, Status: "error", Message: err.Error()}
+		}
+		return actions.Result{ActionType: action.Type, Status: "executed", Message: msg, Metadata: output}
+	}
+	return h
+}
@@ -0,0 +1,69 @@
+package loom
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// overdueContextKey records the due date a bead was last handled as overdue
+// for, so each deadline is acted on once. Moving the due date arms it again.
+const overdueContextKey = "overdue_for"
+
+// checkOverdueBeads acts on beads that have passed their due date: each is
+// raised one priority level, a bead.overdue event is published, and, with
+// beads.escalate_overdue set, it is escalated to the CEO. It returns the
+// number of beads newly found overdue.
+func (a *Loom) checkOverdueBeads() (int, error) {
+	if a.beadsManager == nil {
+		return 0, nil
+	}
+	overdue, err := a.beadsManager.ListBeads(map[string]interface{}{"overdue": true})
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, b := range overdue {
+		due := b.DueDate.UTC().Format(time.RFC3339)
+		if b.Context[overdueContextKey] == due {
+			continue
+		}
+		previous := b.Priority
+		priority := previous
+		if priority > models.BeadPriorityP0 {
+			priority--
+		}
+		if _, err := a.UpdateBead(b.ID, map[string]interface{}{
+			"priority": priority,
+			"context":  map[string]string{overdueContextKey: due},
+		}); err != nil {
+			log.Printf("[Deadlines] Failed to raise overdue bead %s: %v", b.ID, err)
+			continue
+		}
+		handled++
+
+		reason := fmt.Sprintf("bead %s (%s) is past its due date %s", b.ID, b.Title, due)
+		log.Printf("[Deadlines] %s; priority P%d -> P%d", reason, previous, priority)
+
+		if a.eventBus != nil {
+			_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadOverdue, b.ID, b.ProjectID, map[string]interface{}{
+				"due_at":            due,
+				"priority":          int(priority),
+				"previous_priority": int(previous),
+				"title":             b.Title,
+				"reason":            reason,
+			})
+		}
+
+		if a.config.Beads.EscalateOverdue && b.Context["escalated_to_ceo_decision_id"] == "" {
+			if _, err := a.EscalateBeadToCEO(b.ID, reason, ""); err != nil {
+				log.Printf("[Deadlines] Failed to escalate %s: %v", b.ID, err)
+			}
+		}
+	}
+	return handled, nil
+}
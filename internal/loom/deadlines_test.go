@@ -0,0 +1,87 @@
+package loom
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestLoom_CheckOverdueBeads(t *testing.T) {
+	l, tmpDir := testLoom(t, func(cfg *config.Config) {
+		cfg.Beads.EscalateOverdue = true
+	})
+	defer os.RemoveAll(tmpDir)
+
+	proj, err := l.CreateProject("deadlines", ".", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreateProject() error = %v", err)
+	}
+	late, _ := l.CreateBead("Late", "", models.BeadPriorityP2, "task", proj.ID)
+	onTime, _ := l.CreateBead("On time", "", models.BeadPriorityP2, "task", proj.ID)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	if _, err := l.UpdateBead(late.ID, map[string]interface{}{"due_date": &past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.UpdateBead(onTime.ID, map[string]interface{}{"due_date": &future}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := l.checkOverdueBeads()
+	if err != nil || n != 1 {
+		t.Fatalf("checkOverdueBeads() = %d, %v; want 1", n, err)
+	}
+	got, _ := l.beadsManager.GetBead(late.ID)
+	if got.Context["escalated_to_ceo_decision_id"] == "" {
+		t.Error("overdue bead was not escalated to the CEO")
+	}
+	if got.Context[overdueContextKey] == "" {
+		t.Error("overdue bead was not marked")
+	}
+	if got, _ := l.beadsManager.GetBead(onTime.ID); got.Priority != models.BeadPriorityP2 {
+		t.Errorf("bead not yet due was raised to P%d", got.Priority)
+	}
+
+	// Each deadline is handled once.
+	if n, _ := l.checkOverdueBeads(); n != 0 {
+		t.Errorf("second check handled %d beads, want 0", n)
+	}
+}
+
+func TestLoom_CheckOverdueBeadsRaisesPriority(t *testing.T) {
+	l, tmpDir := testLoom(t)
+	defer os.RemoveAll(tmpDir)
+
+	proj, err := l.CreateProject("deadlines-raise", ".", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreateProject() error = %v", err)
+	}
+	bead, _ := l.CreateBead("Late", "", models.BeadPriorityP3, "task", proj.ID)
+	past := time.Now().Add(-time.Hour)
+	if _, err := l.UpdateBead(bead.ID, map[string]interface{}{"due_date": &past}); err != nil {
+		t.Fatal(err)
+	}
+	if overdue, _ := l.beadsManager.ListBeads(map[string]interface{}{"project_id": proj.ID, "overdue": true}); len(overdue) != 1 {
+		t.Fatalf("overdue filter returned %d beads, want 1", len(overdue))
+	}
+
+	if _, err := l.checkOverdueBeads(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := l.beadsManager.GetBead(bead.ID)
+	if got.Priority != models.BeadPriorityP2 || got.Context["escalated_to_ceo_decision_id"] != "" {
+		t.Errorf("priority P%d, escalation %q; want P2 and no escalation", got.Priority, got.Context["escalated_to_ceo_decision_id"])
+	}
+
+	// Moving the deadline arms it again.
+	earlier := past.Add(-time.Hour)
+	if _, err := l.UpdateBead(bead.ID, map[string]interface{}{"due_date": &earlier}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := l.checkOverdueBeads(); n != 1 {
+		t.Errorf("check after a new due date handled %d beads, want 1", n)
+	}
+}
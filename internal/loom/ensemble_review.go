@@ -0,0 +1,206 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Context keys recording a code fix proposal's assessments on its
+// decision bead: the keyword heuristics' and the reviewing model's.
+const (
+	fixRiskKey            = "fix_risk"
+	fixRiskReasonsKey     = "fix_risk_reasons"
+	ensembleReviewerKey   = "ensemble_reviewer"
+	ensembleModelKey      = "ensemble_model"
+	ensembleVerdictKey    = "ensemble_verdict"
+	ensembleAssessmentKey = "ensemble_assessment"
+)
+
+// ensembleVetoReason starts the blocked reason of an apply-fix bead whose
+// fix the ensemble review didn't approve.
+const ensembleVetoReason = "ensemble review verdict"
+
+// Verdicts a reviewing model gives a code fix.
+const (
+	fixVerdictApprove = "approve"
+	fixVerdictReject  = "reject"
+	fixVerdictUnclear = "unclear"
+	fixVerdictError   = "error"
+)
+
+const fixReviewPrompt = `You are an independent code reviewer. Another model proposed the code fix below and it is about to be applied to the repository without further human review.
+
+Check that the patch fixes the stated problem, is confined to it, and does not introduce security issues, data loss or regressions. A keyword heuristic assessed the fix as %s risk (%s).
+
+Start your reply with a line reading exactly "VERDICT: APPROVE" or "VERDICT: REJECT", then explain your assessment in a few short paragraphs.`
+
+// fixReview is a reviewing model's assessment of a code fix proposal.
+type fixReview struct {
+	Reviewer   string
+	Model      string
+	Verdict    string
+	Assessment string
+}
+
+// context returns the bead context recording r.
+func (r *fixReview) context() map[string]string {
+	return map[string]string{
+		ensembleReviewerKey:   r.Reviewer,
+		ensembleModelKey:      r.Model,
+		ensembleVerdictKey:    r.Verdict,
+		ensembleAssessmentKey: r.Assessment,
+	}
+}
+
+// fixReviewFromContext returns the review recorded in a bead's context, or
+// nil if there is none.
+func fixReviewFromContext(ctx map[string]string) *fixReview {
+	if ctx[ensembleVerdictKey] == "" {
+		return nil
+	}
+	return &fixReview{
+		Reviewer:   ctx[ensembleReviewerKey],
+		Model:      ctx[ensembleModelKey],
+		Verdict:    ctx[ensembleVerdictKey],
+		Assessment: ctx[ensembleAssessmentKey],
+	}
+}
+
+// needsEnsembleReview reports whether fixes assessed at risk are sent for
+// a second opinion.
+func (a *Loom) needsEnsembleReview(risk string) bool {
+	cfg := a.config.AutoFix.EnsembleReview
+	if !cfg.Enabled {
+		return false
+	}
+	levels := cfg.RiskLevels
+	if len(levels) == 0 {
+		levels = []string{"high"}
+	}
+	for _, level := range levels {
+		if strings.EqualFold(strings.TrimSpace(level), risk) {
+			return true
+		}
+	}
+	return false
+}
+
+// fixReviewer picks the provider reviewing bead's proposal: the configured
+// one, or else an active provider other than the proposing agent's.
+func (a *Loom) fixReviewer(bead *models.Bead) (*provider.RegisteredProvider, error) {
+	if a.providerRegistry == nil {
+		return nil, fmt.Errorf("no provider registry")
+	}
+	if id := a.config.AutoFix.EnsembleReview.Provider; id != "" {
+		return a.providerRegistry.Get(id)
+	}
+
+	proposer := ""
+	if a.agentManager != nil && bead.Context["agent_id"] != "" {
+		if ag, err := a.agentManager.GetAgent(bead.Context["agent_id"]); err == nil && ag != nil {
+			proposer = ag.ProviderID
+		}
+	}
+	var fallback *provider.RegisteredProvider
+	for _, p := range a.providerRegistry.ListActive() {
+		if p.Config.ID != proposer {
+			return p, nil
+		}
+		fallback = p
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("no active providers")
+	}
+	// Only the proposer's provider is up; a configured model still makes
+	// the review independent.
+	return fallback, nil
+}
+
+// ensembleReviewFix asks a second model to review bead's proposed fix and
+// records its verdict on the bead. A review that can't be had is recorded
+// with the error verdict, which blocks the fix like a rejection.
+func (a *Loom) ensembleReviewFix(bead *models.Bead, risk string, reasons []string) *fixReview {
+	cfg := a.config.AutoFix.EnsembleReview
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	review := &fixReview{Model: cfg.Model}
+	reviewer, err := a.fixReviewer(bead)
+	if err == nil {
+		review.Reviewer = reviewer.Config.ID
+		if review.Model == "" {
+			review.Model = reviewer.Config.Model
+		}
+		err = reviewFix(ctx, reviewer.Protocol, review, bead.Description, risk, reasons)
+	}
+	if err != nil {
+		log.Printf("[AutoFix] Ensemble review of %s failed: %v", bead.ID, err)
+		review.Verdict = fixVerdictError
+		review.Assessment = "Review failed: " + err.Error()
+	}
+	log.Printf("[AutoFix] Ensemble review of %s by %s (%s): %s", bead.ID, review.Reviewer, review.Model, review.Verdict)
+
+	if err := a.beadsManager.UpdateBead(bead.ID, map[string]interface{}{"context": review.context()}); err != nil {
+		log.Printf("[AutoFix] Failed to record ensemble review on %s: %v", bead.ID, err)
+	}
+	return review
+}
+
+// reviewFix has protocol review proposal and fills in review's verdict and
+// assessment.
+func reviewFix(ctx context.Context, protocol provider.Protocol, review *fixReview, proposal, risk string, reasons []string) error {
+	if protocol == nil {
+		return fmt.Errorf("provider %s has no protocol configured", review.Reviewer)
+	}
+	resp, err := protocol.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model: review.Model,
+		Messages: []provider.ChatMessage{
+			{Role: "system", Content: fmt.Sprintf(fixReviewPrompt, risk, strings.Join(reasons, "; "))},
+			{Role: "user", Content: proposal},
+		},
+		Temperature: 0.1,
+		MaxTokens:   1000,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Choices) == 0 {
+		return fmt.Errorf("empty response")
+	}
+	review.Verdict, review.Assessment = parseFixReview(resp.Choices[0].Message.Content)
+	return nil
+}
+
+// parseFixReview splits a reviewer's reply into its verdict and the
+// assessment following it. A reply without a recognisable verdict is
+// unclear.
+func parseFixReview(content string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	for i, line := range lines {
+		cleaned := strings.ToLower(strings.Trim(strings.TrimSpace(line), "*#_` "))
+		rest, ok := strings.CutPrefix(cleaned, "verdict:")
+		if !ok {
+			continue
+		}
+		verdict := fixVerdictUnclear
+		switch rest = strings.Trim(rest, "*_` ."); {
+		case strings.HasPrefix(rest, "approve"):
+			verdict = fixVerdictApprove
+		case strings.HasPrefix(rest, "reject"):
+			verdict = fixVerdictReject
+		}
+		assessment := append(append([]string{}, lines[:i]...), lines[i+1:]...)
+		return verdict, strings.TrimSpace(strings.Join(assessment, "\n"))
+	}
+	return fixVerdictUnclear, strings.TrimSpace(content)
+}
@@ -0,0 +1,90 @@
+package loom
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// cannedProtocol answers every chat completion with reply.
+type cannedProtocol struct {
+	reply string
+	got   *provider.ChatCompletionRequest
+}
+
+func (p *cannedProtocol) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.got = req
+	resp := &provider.ChatCompletionResponse{}
+	resp.Choices = append(resp.Choices, struct {
+		Index   int                  `json:"index"`
+		Message provider.ChatMessage `json:"message"`
+		Finish  string               `json:"finish_reason"`
+	}{Message: provider.ChatMessage{Role: "assistant", Content: p.reply}})
+	return resp, nil
+}
+
+func (p *cannedProtocol) GetModels(ctx context.Context) ([]provider.Model, error) {
+	return nil, nil
+}
+
+func TestParseFixReview(t *testing.T) {
+	tests := []struct {
+		reply      string
+		verdict    string
+		assessment string
+	}{
+		{"VERDICT: APPROVE\nThe patch is minimal.", fixVerdictApprove, "The patch is minimal."},
+		{"**Verdict: Reject**\n\nIt drops the auth check.", fixVerdictReject, "It drops the auth check."},
+		{"Looked it over.\nverdict: approved.", fixVerdictApprove, "Looked it over."},
+		{"VERDICT: maybe\nNot sure.", fixVerdictUnclear, "Not sure."},
+		{"Seems fine to me.", fixVerdictUnclear, "Seems fine to me."},
+	}
+	for _, tt := range tests {
+		verdict, assessment := parseFixReview(tt.reply)
+		if verdict != tt.verdict || assessment != tt.assessment {
+			t.Errorf("parseFixReview(%q) = %q, %q; want %q, %q", tt.reply, verdict, assessment, tt.verdict, tt.assessment)
+		}
+	}
+}
+
+func TestReviewFix(t *testing.T) {
+	protocol := &cannedProtocol{reply: "VERDICT: REJECT\nThe token is logged."}
+	review := &fixReview{Reviewer: "second", Model: "reviewer-model"}
+	err := reviewFix(context.Background(), protocol, review, "**Original Bug:** bd-1\nFix token refresh", "high",
+		[]string{"contains security/destructive keyword: token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Verdict != fixVerdictReject || review.Assessment != "The token is logged." {
+		t.Errorf("review = %+v", review)
+	}
+	if protocol.got.Model != "reviewer-model" || !strings.Contains(protocol.got.Messages[0].Content, "high risk") ||
+		!strings.Contains(protocol.got.Messages[1].Content, "Fix token refresh") {
+		t.Errorf("request = %+v", protocol.got)
+	}
+
+	got := fixReviewFromContext(review.context())
+	if got == nil || *got != *review {
+		t.Errorf("fixReviewFromContext() = %+v, want %+v", got, review)
+	}
+}
+
+func TestNeedsEnsembleReview(t *testing.T) {
+	a := &Loom{config: &config.Config{}}
+	if a.needsEnsembleReview("high") {
+		t.Error("review needed while disabled")
+	}
+
+	a.config.AutoFix.EnsembleReview.Enabled = true
+	if !a.needsEnsembleReview("high") || a.needsEnsembleReview("medium") {
+		t.Error("default risk levels are not just high")
+	}
+
+	a.config.AutoFix.EnsembleReview.RiskLevels = []string{"Medium", "low"}
+	if a.needsEnsembleReview("high") || !a.needsEnsembleReview("medium") || !a.needsEnsembleReview("low") {
+		t.Error("configured risk levels not honored")
+	}
+}
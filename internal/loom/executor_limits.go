@@ -0,0 +1,88 @@
+package loom
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/jordanhubbard/loom/internal/taskexecutor"
+)
+
+// executorLimitsKey is the config_kv key holding the executor limits last
+// set through the API. They take precedence over config.yaml.
+const executorLimitsKey = "executor:limits"
+
+// executorLimits returns the global executor limits: the defaults, then
+// config.yaml, then what was last set through the API.
+func (a *Loom) executorLimits() taskexecutor.Limits {
+	limits := taskexecutor.Limits{
+		MaxConcurrentBeads: a.config.Executor.MaxConcurrentBeads,
+		MaxLLMCalls:        a.config.Executor.MaxLLMCalls,
+		ClaimInterval:      a.config.Executor.ClaimInterval,
+	}
+	if limits.Validate() != nil {
+		log.Printf("[Executor] Ignoring out-of-range executor limits in config: %+v", a.config.Executor)
+		limits = taskexecutor.Limits{}
+	}
+	limits = limits.Merge(taskexecutor.DefaultLimits())
+	if a.database != nil {
+		if raw, ok, err := a.database.GetConfigValue(executorLimitsKey); err == nil && ok {
+			var stored taskexecutor.Limits
+			if err := json.Unmarshal([]byte(raw), &stored); err == nil && stored.Validate() == nil {
+				limits = stored.Merge(limits)
+			}
+		}
+	}
+	return limits
+}
+
+// projectExecutorLimits returns the limits a project sets for itself.
+func (a *Loom) projectExecutorLimits(projectID string) taskexecutor.Limits {
+	return taskexecutor.Limits{
+		MaxConcurrentBeads: a.projectConfig.MaxConcurrentBeads(projectID),
+		MaxLLMCalls:        a.projectConfig.MaxLLMCalls(projectID),
+		ClaimInterval:      a.projectConfig.ClaimInterval(projectID),
+	}
+}
+
+// GetExecutorLimits returns the task executor's global limits, or those in
+// effect for projectID when it is set.
+func (a *Loom) GetExecutorLimits(projectID string) (taskexecutor.Limits, error) {
+	if projectID == "" {
+		return a.executorLimits(), nil
+	}
+	if _, err := a.projectManager.GetProject(projectID); err != nil {
+		return taskexecutor.Limits{}, err
+	}
+	if a.taskExecutor != nil {
+		return a.taskExecutor.ProjectLimits(projectID), nil
+	}
+	own := a.projectExecutorLimits(projectID)
+	limits := own.Merge(a.executorLimits())
+	limits.MaxLLMCalls = own.MaxLLMCalls
+	return limits, nil
+}
+
+// SetExecutorLimits changes the task executor's global limits; zero fields
+// are left as they are. The change applies at once and is saved, so it
+// outlasts a restart.
+func (a *Loom) SetExecutorLimits(limits taskexecutor.Limits) (taskexecutor.Limits, error) {
+	if err := limits.Validate(); err != nil {
+		return taskexecutor.Limits{}, err
+	}
+	merged := limits.Merge(a.executorLimits())
+	if a.database != nil {
+		raw, err := json.Marshal(merged)
+		if err != nil {
+			return taskexecutor.Limits{}, err
+		}
+		if err := a.database.SetConfigValue(executorLimitsKey, string(raw)); err != nil {
+			return taskexecutor.Limits{}, err
+		}
+	}
+	if a.taskExecutor != nil {
+		if err := a.taskExecutor.SetLimits(merged); err != nil {
+			return taskexecutor.Limits{}, err
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,63 @@
+package loom
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/taskexecutor"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestLoom_ExecutorLimits(t *testing.T) {
+	l, tmpDir := testLoom(t, func(cfg *config.Config) {
+		cfg.Executor.MaxConcurrentBeads = 8
+	})
+	defer os.RemoveAll(tmpDir)
+
+	got, err := l.GetExecutorLimits("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := taskexecutor.Limits{MaxConcurrentBeads: 8, MaxLLMCalls: 3, ClaimInterval: 5 * time.Second}
+	if got != want {
+		t.Errorf("limits = %+v, want config over defaults %+v", got, want)
+	}
+
+	l.taskExecutor = taskexecutor.New(nil, nil, nil, nil, nil)
+
+	got, err = l.SetExecutorLimits(taskexecutor.Limits{MaxLLMCalls: 6, ClaimInterval: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = taskexecutor.Limits{MaxConcurrentBeads: 8, MaxLLMCalls: 6, ClaimInterval: 2 * time.Second}
+	if got != want || l.taskExecutor.Limits() != want {
+		t.Errorf("after set: returned %+v, executor has %+v, want %+v", got, l.taskExecutor.Limits(), want)
+	}
+
+	if _, err := l.SetExecutorLimits(taskexecutor.Limits{MaxConcurrentBeads: 1000}); !errors.Is(err, taskexecutor.ErrInvalidLimits) {
+		t.Errorf("out-of-range limit: err = %v, want ErrInvalidLimits", err)
+	}
+	if _, err := l.GetExecutorLimits("no-such-project"); err == nil {
+		t.Error("GetExecutorLimits for a missing project should fail")
+	}
+}
+
+func TestExecutorLimitsJSON(t *testing.T) {
+	var limits taskexecutor.Limits
+	if err := json.Unmarshal([]byte(`{"max_llm_calls": 4, "claim_interval": "1m30s"}`), &limits); err != nil {
+		t.Fatal(err)
+	}
+	if limits.MaxLLMCalls != 4 || limits.ClaimInterval != 90*time.Second || limits.MaxConcurrentBeads != 0 {
+		t.Errorf("decoded %+v", limits)
+	}
+	raw, _ := json.Marshal(limits)
+	if string(raw) != `{"max_llm_calls":4,"claim_interval":"1m30s"}` {
+		t.Errorf("encoded %s", raw)
+	}
+	if err := json.Unmarshal([]byte(`{"claim_interval": "soon"}`), &limits); err == nil {
+		t.Error("bad duration should not decode")
+	}
+}
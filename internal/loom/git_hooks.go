@@ -0,0 +1,106 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ProjectsForRepository returns the projects whose repository is one of
+// repos, given as clone URLs or owner/name paths. Archived projects are
+// left out.
+func (a *Loom) ProjectsForRepository(repos ...string) []*models.Project {
+	want := make(map[string]struct{}, len(repos))
+	for _, r := range repos {
+		if slug := repoSlug(r); slug != "" {
+			want[slug] = struct{}{}
+		}
+	}
+	if len(want) == 0 {
+		return nil
+	}
+
+	var matched []*models.Project
+	for _, p := range a.projectManager.ListProjects() {
+		if p.Status == models.ProjectStatusArchived {
+			continue
+		}
+		_, byRepo := want[repoSlug(p.GitRepo)]
+		_, byGitHub := want[repoSlug(p.GitHubRepo)]
+		if byRepo || byGitHub {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// repoSlug reduces a repository URL or path to its lower-cased owner/name,
+// so that https, ssh and scp-style URLs of one repository compare equal.
+func repoSlug(repo string) string {
+	s := strings.ToLower(strings.TrimSpace(repo))
+	if s == "" || s == "." {
+		return ""
+	}
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	} else if at := strings.Index(s, "@"); at >= 0 {
+		s = strings.Replace(s[at+1:], ":", "/", 1)
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
+
+// RefreshProjectFromRemote brings a project up to date after its remote
+// changed: it pulls the project's clone, reloads its beads from the
+// beads-sync branch, re-runs the readiness check and wakes the project's
+// task executor so agents pick up the new state. Refreshes of one project
+// run one at a time.
+func (a *Loom) RefreshProjectFromRemote(ctx context.Context, projectID string) error {
+	lock, _ := a.remoteRefreshLocks.LoadOrStore(projectID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	p, err := a.projectManager.GetProject(projectID)
+	if err != nil {
+		return err
+	}
+	if p.Status == models.ProjectStatusArchived {
+		return fmt.Errorf("project is archived: %s", projectID)
+	}
+
+	if p.GitRepo != "" && p.GitRepo != "." && a.gitopsManager != nil {
+		if err := a.gitopsManager.PullProject(ctx, p); err != nil {
+			return fmt.Errorf("failed to pull project %s: %w", projectID, err)
+		}
+		if err := a.projectManager.UpdateProject(projectID, map[string]interface{}{
+			"last_sync_at":     p.LastSyncAt,
+			"last_commit_hash": p.LastCommitHash,
+		}); err != nil {
+			log.Printf("[GitHook] Warning: failed to record sync of project %s: %v", projectID, err)
+		}
+		a.PersistProject(projectID)
+	}
+
+	if _, err := a.ReloadProjectBeads(ctx, projectID); err != nil {
+		log.Printf("[GitHook] Warning: failed to reload beads for project %s: %v", projectID, err)
+	}
+
+	a.readinessMu.Lock()
+	delete(a.readinessCache, projectID)
+	a.readinessMu.Unlock()
+	if ready, issues := a.CheckProjectReadiness(ctx, projectID); !ready {
+		log.Printf("[GitHook] Project %s not ready after refresh: %v", projectID, issues)
+	}
+
+	a.WakeProject(projectID)
+	log.Printf("[GitHook] Refreshed project %s at %s", projectID, p.LastCommitHash)
+	return nil
+}
@@ -0,0 +1,22 @@
+package loom
+
+import "testing"
+
+func TestRepoSlug(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/Owner/Repo.git":      "owner/repo",
+		"https://github.com/owner/repo":          "owner/repo",
+		"git@github.com:owner/repo.git":          "owner/repo",
+		"ssh://git@gitea.example.com/owner/repo": "owner/repo",
+		"owner/repo":                             "owner/repo",
+		"https://gitlab.com/group/sub/repo.git":  "sub/repo",
+		".":                                      "",
+		"":                                       "",
+		"repo":                                   "",
+	}
+	for in, want := range cases {
+		if got := repoSlug(in); got != want {
+			t.Errorf("repoSlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
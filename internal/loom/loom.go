@@ -17,15 +17,27 @@ import (
 	"github.com/jordanhubbard/loom/internal/actions"
 	"github.com/jordanhubbard/loom/internal/activity"
 	"github.com/jordanhubbard/loom/internal/agent"
+	"github.com/jordanhubbard/loom/internal/agentpki"
 	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/approval"
+	"github.com/jordanhubbard/loom/internal/attachments"
+	"github.com/jordanhubbard/loom/internal/auditlog"
+	"github.com/jordanhubbard/loom/internal/backup"
+	"github.com/jordanhubbard/loom/internal/beadhistory"
 	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/beadtype"
+	"github.com/jordanhubbard/loom/internal/board"
+	"github.com/jordanhubbard/loom/internal/budget"
+	"github.com/jordanhubbard/loom/internal/cmdpolicy"
 	"github.com/jordanhubbard/loom/internal/comments"
 	"github.com/jordanhubbard/loom/internal/containers"
 	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/internal/decision"
 	"github.com/jordanhubbard/loom/internal/dispatch"
 	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/eventstore"
 	"github.com/jordanhubbard/loom/internal/executor"
+	"github.com/jordanhubbard/loom/internal/export"
 	"github.com/jordanhubbard/loom/internal/files"
 	"github.com/jordanhubbard/loom/internal/gitops"
 	"github.com/jordanhubbard/loom/internal/keymanager"
@@ -33,6 +45,7 @@ import (
 	"github.com/jordanhubbard/loom/internal/memory"
 	"github.com/jordanhubbard/loom/internal/messagebus"
 	"github.com/jordanhubbard/loom/internal/metrics"
+	"github.com/jordanhubbard/loom/internal/milestone"
 	"github.com/jordanhubbard/loom/internal/modelcatalog"
 	internalmodels "github.com/jordanhubbard/loom/internal/models"
 	"github.com/jordanhubbard/loom/internal/motivation"
@@ -41,13 +54,22 @@ import (
 	"github.com/jordanhubbard/loom/internal/openclaw"
 	"github.com/jordanhubbard/loom/internal/orchestrator"
 	"github.com/jordanhubbard/loom/internal/orgchart"
+	"github.com/jordanhubbard/loom/internal/orgs"
 	"github.com/jordanhubbard/loom/internal/patterns"
 	"github.com/jordanhubbard/loom/internal/persona"
 	"github.com/jordanhubbard/loom/internal/project"
+	"github.com/jordanhubbard/loom/internal/projectconfig"
 	"github.com/jordanhubbard/loom/internal/provider"
 	"github.com/jordanhubbard/loom/internal/ralph"
+	"github.com/jordanhubbard/loom/internal/remoteagent"
+	"github.com/jordanhubbard/loom/internal/sandbox"
+	"github.com/jordanhubbard/loom/internal/scheduler"
+	"github.com/jordanhubbard/loom/internal/sla"
+	"github.com/jordanhubbard/loom/internal/slack"
 	"github.com/jordanhubbard/loom/internal/swarm"
 	"github.com/jordanhubbard/loom/internal/taskexecutor"
+	"github.com/jordanhubbard/loom/internal/webhooks"
+	"github.com/jordanhubbard/loom/internal/worker"
 	"github.com/jordanhubbard/loom/internal/workflow"
 	"github.com/jordanhubbard/loom/pkg/config"
 	"github.com/jordanhubbard/loom/pkg/connectors"
@@ -77,6 +99,9 @@ type Loom struct {
 	database              *database.Database
 	dispatcher            *dispatch.Dispatcher
 	eventBus              *eventbus.EventBus
+	eventStore            *eventstore.Store
+	exportManager         *export.Manager
+	backupManager         *backup.Manager
 	modelCatalog          *modelcatalog.Catalog
 	gitopsManager         *gitops.Manager
 	shellExecutor         *executor.ShellExecutor
@@ -84,6 +109,24 @@ type Loom struct {
 	activityManager       *activity.Manager
 	notificationManager   *notifications.Manager
 	commentsManager       *comments.Manager
+	attachmentsManager    *attachments.Manager
+	webhooksManager       *webhooks.Manager
+	beadScheduler         *scheduler.Manager
+	budgetManager         *budget.Manager
+	costWatcher           *analytics.CostWatcher
+	analyticsLogger       *analytics.Logger
+	slaManager            *sla.Manager
+	milestoneManager      *milestone.Manager
+	beadTypeManager       *beadtype.Manager
+	commandPolicy         *cmdpolicy.Manager
+	auditLog              *auditlog.Manager
+	beadHistory           *beadhistory.Manager
+	orgManager            *orgs.Manager
+	boardManager          *board.Manager
+	projectConfig         *projectconfig.Manager
+	fixPolicy             *approval.Policy
+	fileManager           *files.Manager
+	remoteAgents          *remoteagent.Manager
 	motivationRegistry    *motivation.Registry
 	motivationEngine      *motivation.Engine
 	idleDetector          *motivation.IdleDetector
@@ -94,18 +137,25 @@ type Loom struct {
 	doltCoordinator       *beads.DoltCoordinator
 	openclawClient        *openclaw.Client
 	openclawBridge        *openclaw.Bridge
+	slackBridge           *slack.Bridge
 	containerOrchestrator *containers.Orchestrator
+	agentPKI              *agentpki.Authority
 	connectorManager      *connectors.Manager
 	memoryManager         *memory.MemoryManager
+	retriever             *memory.Retriever
+	summarizer            worker.Summarizer
 	messageBus            interface{}
 	bridge                *messagebus.BridgedMessageBus
 	pdaOrchestrator       *orchestrator.PDAOrchestrator
 	swarmManager          *swarm.Manager
 	swarmFederation       *swarm.Federation
 	taskExecutor          *taskexecutor.Executor
+	serverModeMu          sync.Mutex
+	serverMode            *ServerMode // loaded by ServerMode
 	readinessMu           sync.Mutex
 	readinessCache        map[string]projectReadinessState
 	readinessFailures     map[string]time.Time
+	remoteRefreshLocks    sync.Map // project ID -> *sync.Mutex
 	shutdownOnce          sync.Once
 	startedAt             time.Time
 }
@@ -118,6 +168,16 @@ func New(cfg *config.Config) (*Loom, error) {
 	}
 
 	providerRegistry := provider.NewRegistry()
+	if pc := cfg.Cache.Prompts; pc.Enabled {
+		ttl, maxSize := pc.TTL, pc.MaxSize
+		if ttl <= 0 {
+			ttl = time.Hour
+		}
+		if maxSize <= 0 {
+			maxSize = 1000
+		}
+		providerRegistry.SetPromptCache(provider.NewPromptCache(ttl, maxSize))
+	}
 
 	// Initialize NATS message bus if configured
 	var messageBus interface{}
@@ -150,26 +210,54 @@ func New(cfg *config.Config) (*Loom, error) {
 		}
 	}
 
-	// Initialize PostgreSQL database.
-	// Config DSN takes priority; otherwise fall back to environment variables (POSTGRES_HOST, etc.)
+	// Initialize the database.
+	// Config DSN takes priority (PostgreSQL). Otherwise DB_TYPE (env) or
+	// database.type (config) selects the backend: "sqlite" opens the file at
+	// SQLITE_PATH / database.path, anything else uses PostgreSQL from
+	// environment variables (POSTGRES_HOST, etc.).
 	// An empty database Type means "no database" (skip initialization).
 	var db *database.Database
+	var dbOpts []database.Option
+	if cfg.Database.ManualMigrations {
+		dbOpts = append(dbOpts, database.ManualMigrations())
+	}
+	dbType := cfg.Database.Type
+	if env := os.Getenv("DB_TYPE"); env != "" && dbType != "" {
+		dbType = env
+	}
 	if cfg.Database.DSN != "" {
 		var err error
-		db, err = database.NewPostgres(cfg.Database.DSN)
+		db, err = database.NewPostgres(cfg.Database.DSN, dbOpts...)
 		if err != nil {
 			log.Printf("Warning: failed to initialize postgres: %v (running without persistence)", err)
 		}
 		log.Printf("Initialized postgres database from config DSN")
-	} else if cfg.Database.Type != "" {
+	} else if dbType == string(database.DialectSQLite) {
+		sqlitePath := os.Getenv("SQLITE_PATH")
+		if sqlitePath == "" {
+			sqlitePath = cfg.Database.Path
+		}
 		var err error
-		db, err = database.NewFromEnv()
+		db, err = database.NewSQLite(sqlitePath, dbOpts...)
+		if err != nil {
+			log.Printf("Warning: failed to initialize sqlite: %v (running without persistence)", err)
+		} else {
+			log.Printf("Initialized sqlite database at %s", sqlitePath)
+		}
+	} else if dbType != "" {
+		var err error
+		db, err = database.NewPostgreSQL(dbOpts...)
 		if err != nil {
 			log.Printf("Warning: failed to initialize database: %v (running without persistence)", err)
 		} else {
 			log.Printf("Initialized postgres database from environment")
 		}
 	}
+	if db != nil && cfg.Database.ManualMigrations {
+		if pending, err := db.PendingMigrations(); err == nil && len(pending) > 0 {
+			log.Printf("Warning: %d schema migrations pending; review them with 'loomctl admin migrate status' and apply them with 'loomctl admin migrate up'", len(pending))
+		}
+	}
 
 	// Initialize model catalog from config or use defaults.
 	// Priority: 1) config.yaml preferred_models, 2) database override, 3) hardcoded defaults
@@ -245,6 +333,7 @@ func New(cfg *config.Config) (*Loom, error) {
 	var logMgr *logging.Manager
 	if db != nil {
 		logMgr = logging.NewManager(db.DB())
+		configureLogging(logMgr, cfg.Logging)
 		logMgr.InstallLogInterceptor()
 	}
 
@@ -252,36 +341,74 @@ func New(cfg *config.Config) (*Loom, error) {
 	motivationRegistry := motivation.NewRegistry(motivation.DefaultConfig())
 	idleDetector := motivation.NewIdleDetector(motivation.DefaultIdleConfig())
 
-	// Initialize workflow engine (if database is available)
+	beadsMgr := beads.NewManager(cfg.Beads.BDPath)
+	beadsMgr.SetBackend(cfg.Beads.Backend)
+
+	// Initialize workflow engine (if database is available). It shares the
+	// beads manager, so the workflow context it writes is what the
+	// dispatcher reads.
 	var workflowEngine *workflow.Engine
 	if db != nil {
-		beadsMgr := beads.NewManager(cfg.Beads.BDPath)
 		workflowEngine = workflow.NewEngine(db, beadsMgr)
 	}
 
+	// Durable event store; started in Initialize, pruned by the
+	// maintenance loop.
+	eventStore := eventstore.NewStore(db, eb, cfg.Events)
+
 	// Initialize activity, notification, and comments managers
 	var activityMgr *activity.Manager
 	var notificationMgr *notifications.Manager
 	var commentsMgr *comments.Manager
 	if db != nil {
-		activityMgr = activity.NewManager(db, eb)
+		activityMgr = activity.NewManager(db, eventStore)
 		notificationMgr = notifications.NewManager(db, activityMgr)
 		commentsMgr = comments.NewManager(db, notificationMgr, eb)
+		if email := cfg.Notifications.Email; email.Enabled {
+			password := email.Password
+			if password == "" {
+				password = os.Getenv("SMTP_PASSWORD")
+			}
+			notificationMgr.SetMailer(notifications.NewSMTPMailer(email.SMTPHost, email.SMTPPort, email.Username, password, email.From), email.BaseURL)
+		}
+	}
+
+	// Files attached to beads: uploads from people and outputs of agent actions.
+	attachmentsMgr, err := attachments.NewManager(db, cfg.Beads.Attachments)
+	if err != nil {
+		log.Printf("Warning: bead attachments disabled: %v", err)
 	}
 
+	// Outbound webhooks deliver event bus events to registered endpoints.
+	webhooksMgr := webhooks.NewManager(db, eb)
+
 	// Initialize pattern manager and analytics logger if database is available
 	var patternMgr *patterns.Manager
+	var budgetMgr *budget.Manager
+	var costWatcher *analytics.CostWatcher
+	var analyticsLogger *analytics.Logger
 	if db != nil {
 		analyticsStorage, err := analytics.NewDatabaseStorage(db.DB())
 		if err != nil {
 			log.Printf("Warning: failed to initialize analytics storage: %v", err)
 		} else if analyticsStorage != nil {
 			patternMgr = patterns.NewManager(analyticsStorage, nil)
+			budgetMgr = budget.NewManager(db, analyticsStorage)
+			costWatcher = analytics.NewCostWatcher(analyticsStorage, eb, analytics.ForecastOptions{})
 			// Wire analytics logger to WorkerManager so LLM completions are logged
-			agentMgr.SetAnalyticsLogger(analytics.NewLogger(analyticsStorage, analytics.DefaultPrivacyConfig()))
+			analyticsLogger = analytics.NewLogger(analyticsStorage, analytics.DefaultPrivacyConfig())
+			analyticsLogger.SetPricing(cfg.Models.Pricing)
+			agentMgr.SetAnalyticsLogger(analyticsLogger)
 		}
 	}
 
+	// Scheduled exports ship analytics, logs and bead snapshots to object
+	// storage for long-term reporting.
+	exportMgr, exportErr := export.NewManager(db, cfg.Exports, exportDatasets(db, beadsMgr))
+	if exportErr != nil {
+		log.Printf("[Loom] Exports misconfigured, exports disabled: %v", exportErr)
+	}
+
 	// Initialize Dolt coordinator for multi-reader/multi-writer bead management
 	// DISABLED: Let bd CLI manage Dolt in embedded mode to avoid lock conflicts
 	var doltCoord *beads.DoltCoordinator
@@ -297,17 +424,56 @@ func New(cfg *config.Config) (*Loom, error) {
 	ocClient := openclaw.NewClient(&cfg.OpenClaw)
 	ocBridge := openclaw.NewBridge(ocClient, eb, &cfg.OpenClaw)
 
+	// Initialize Slack bridge for decision beads and escalations (nil when disabled).
+	slackBridge := slack.NewBridge(slack.NewClient(&cfg.Slack), eb)
+
 	// Initialize container orchestrator for per-project containers
 	// Control plane URL for project agents to communicate back
 	// Use container name "loom" as hostname (Docker network DNS resolution)
-	controlPlaneURL := "http://loom:8081" // Port 8081 is the internal port
+	controlPlaneHost := "loom"
 	if host := os.Getenv("CONTROL_PLANE_HOST"); host != "" {
-		controlPlaneURL = fmt.Sprintf("http://%s:8081", host)
+		controlPlaneHost = host
+	}
+	controlPlaneURL := fmt.Sprintf("http://%s:8081", controlPlaneHost) // Port 8081 is the internal port
+	// With agent mTLS, containers reach the control plane on its TLS
+	// listener with a client certificate issued at spawn.
+	var agentPKI *agentpki.Authority
+	if mtls := cfg.Security.AgentMTLS; mtls.Enabled {
+		caDir := mtls.CADir
+		if caDir == "" {
+			caDir = "/app/data/pki"
+		}
+		agentPKI, err = agentpki.Load(caDir, mtls.CertTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load project agent CA: %w", err)
+		}
+		port := mtls.Port
+		if port == 0 {
+			port = 8443
+		}
+		controlPlaneURL = fmt.Sprintf("https://%s:%d", controlPlaneHost, port)
 	}
 	containerOrch, err := containers.NewOrchestrator(projectKeyDir, controlPlaneURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize container orchestrator: %w", err)
 	}
+	if agentPKI != nil {
+		containerOrch.SetAgentPKI(agentPKI)
+	}
+	runtimeName, runtimeSocket := cfg.Containers.Runtime, cfg.Containers.Socket
+	if env := os.Getenv("LOOM_CONTAINER_RUNTIME"); env != "" {
+		runtimeName = env
+	}
+	if env := os.Getenv("LOOM_CONTAINER_SOCKET"); env != "" {
+		runtimeSocket = env
+	}
+	detectCtx, cancelDetect := context.WithTimeout(context.Background(), 30*time.Second)
+	if rt, err := containers.DetectRuntime(detectCtx, runtimeName, runtimeSocket); err != nil {
+		log.Printf("Warning: %v; falling back to the docker CLI", err)
+	} else {
+		containerOrch.SetRuntime(rt)
+	}
+	cancelDetect()
 
 	// Initialize connector manager for external service integrations
 	connectorsConfigPath := filepath.Join("/app/data", "connectors.yaml")
@@ -318,9 +484,6 @@ func New(cfg *config.Config) (*Loom, error) {
 	// Start health monitoring for all connectors
 	connectorMgr.StartHealthMonitoring(30 * time.Second)
 
-	beadsMgr := beads.NewManager(cfg.Beads.BDPath)
-	beadsMgr.SetBackend(cfg.Beads.Backend)
-
 	arb := &Loom{
 		config:                cfg,
 		startedAt:             time.Now().UTC(),
@@ -334,6 +497,8 @@ func New(cfg *config.Config) (*Loom, error) {
 		providerRegistry:      providerRegistry,
 		database:              db,
 		eventBus:              eb,
+		eventStore:            eventStore,
+		exportManager:         exportMgr,
 		modelCatalog:          modelCatalog,
 		gitopsManager:         gitopsMgr,
 		shellExecutor:         shellExec,
@@ -341,6 +506,11 @@ func New(cfg *config.Config) (*Loom, error) {
 		activityManager:       activityMgr,
 		notificationManager:   notificationMgr,
 		commentsManager:       commentsMgr,
+		attachmentsManager:    attachmentsMgr,
+		webhooksManager:       webhooksMgr,
+		budgetManager:         budgetMgr,
+		costWatcher:           costWatcher,
+		analyticsLogger:       analyticsLogger,
 		motivationRegistry:    motivationRegistry,
 		idleDetector:          idleDetector,
 		workflowEngine:        workflowEngine,
@@ -349,7 +519,9 @@ func New(cfg *config.Config) (*Loom, error) {
 		doltCoordinator:       doltCoord,
 		openclawClient:        ocClient,
 		openclawBridge:        ocBridge,
+		slackBridge:           slackBridge,
 		containerOrchestrator: containerOrch,
+		agentPKI:              agentPKI,
 		connectorManager:      connectorMgr,
 		messageBus:            messageBus,
 		bridge:                bridge,
@@ -360,12 +532,14 @@ func New(cfg *config.Config) (*Loom, error) {
 		buildEnv.SetOnReady(containerOrch.SnapshotAfterSetup)
 	}
 
+	arb.fileManager = files.NewManager(gitopsMgr)
 	actionRouter := &actions.Router{
 		Beads:         arb,
 		Closer:        arb,
+		Relations:     arb,
 		Escalator:     arb,
 		Commands:      arb,
-		Files:         files.NewManager(gitopsMgr),
+		Files:         arb.fileManager,
 		Git:           actions.NewProjectGitRouter(gitopsMgr),
 		Logger:        arb,
 		Workflow:      arb,
@@ -374,30 +548,158 @@ func New(cfg *config.Config) (*Loom, error) {
 		BuildEnv:      buildEnv,
 		BeadType:      "task",
 		BeadReader:    arb,
+		BeadUpdater:   arb,
+		BeadLister:    arb,
 		DefaultP0:     true,
 	}
+	if attachmentsMgr != nil {
+		actionRouter.Attachments = attachmentsMgr
+	}
+	actionRouter.Metrics = arb.metrics
+	// Per-project overrides of the loop limit, readiness mode, bead prefix
+	// and build/test/lint commands.
+	arb.projectConfig = projectconfig.NewManager(db)
+	if arb.projectConfig != nil {
+		actionRouter.Settings = arb.projectConfig
+		actionRouter.FlakyTests = arb.projectConfig
+		agentMgr.SetProjectMaxLoopIterations(arb.projectConfig.MaxLoopIterations)
+		if containerOrch != nil {
+			pc := arb.projectConfig
+			containerOrch.SetResourceLimits(func(projectID string) containers.ResourceLimits {
+				return containers.ResourceLimits{CPUs: pc.ContainerCPUs(projectID), Memory: pc.ContainerMemory(projectID)}
+			})
+		}
+	}
+	// Rules deciding which code fix proposals are approved without a
+	// person.
+	if err := approval.Validate(cfg.AutoFix.Approval); err != nil {
+		log.Printf("[Loom] Auto-approval policy misconfigured, code fixes need manual approval: %v", err)
+	} else {
+		arb.fixPolicy = approval.New(cfg.AutoFix.Approval)
+	}
+	// Agents outside the control plane's reach enroll with a token and
+	// pull ready beads over a WebSocket.
+	arb.remoteAgents = remoteagent.NewManager(db, beadsMgr)
+	if arb.remoteAgents != nil {
+		arb.remoteAgents.SetPaused(arb.ServerMode().Maintenance())
+	}
+	// Scheduled backups of the database and each project's beads.
+	backupMgr, backupErr := backup.NewManager(db, cfg.Backup, arb.backupProjects)
+	if backupErr != nil {
+		log.Printf("[Loom] Backups misconfigured, backups disabled: %v", backupErr)
+	}
+	if backupMgr != nil {
+		backupMgr.SetProjectRestoredHook(arb.reloadRestoredBeads)
+		arb.backupManager = backupMgr
+	}
 	arb.actionRouter = actionRouter
 	agentMgr.SetActionRouter(actionRouter)
+	registerConnectorActions(connectorMgr)
 
 	// Enable multi-turn action loop
 	agentMgr.SetActionLoopEnabled(true)
 	agentMgr.SetMaxLoopIterations(100) // Increased to 100 to allow full development cycle (explore + plan + edit + build + test + commit)
 	if db != nil {
 		agentMgr.SetDatabase(db)
+		if cfg.Memory.Enabled {
+			arb.retriever = newMemoryRetriever(db, cfg.Memory)
+		}
 		lessonsProvider := dispatch.NewLessonsProvider(db)
 		if lessonsProvider != nil {
+			lessonsProvider.SetRetriever(arb.retriever, cfg.Memory.TopK)
 			agentMgr.SetLessonsProvider(lessonsProvider)
 		}
 		arb.memoryManager = memory.NewMemoryManager(db)
 	}
+	if cfg.Agents.SummaryProvider != "" {
+		arb.summarizer = worker.NewRegistrySummarizer(providerRegistry, cfg.Agents.SummaryProvider, cfg.Agents.SummaryModel)
+		agentMgr.SetSummarizer(arb.summarizer)
+	}
+
+	// Recurring (cron) bead definitions; started in Initialize.
+	arb.beadScheduler = scheduler.NewManager(db, arb)
+
+	// Bead SLA policies; checked by the maintenance loop.
+	arb.slaManager = sla.NewManager(db, arb.beadsManager, arb, eb)
+
+	// Milestones grouping beads across projects.
+	arb.milestoneManager = milestone.NewManager(db, arb.beadsManager)
+
+	// Admin-defined bead types; checked when beads are created through the
+	// API, and their default workflows are started by the dispatcher.
+	arb.beadTypeManager = beadtype.NewManager(db)
+
+	// Command policy; checked by the shell executor before every command.
+	arb.commandPolicy = cmdpolicy.NewManager(db, arb, eb)
+	if shellExec != nil && arb.commandPolicy != nil {
+		shellExec.SetCommandPolicy(arb.commandPolicy)
+	}
+
+	// Audit log of mutating API requests; pruned by the maintenance loop.
+	arb.auditLog = auditlog.NewManager(db, cfg.Audit)
+
+	// Per-bead change log and status transition metrics, fed by every
+	// change the beads manager makes.
+	arb.beadHistory = beadhistory.NewManager(db)
+	arb.beadsManager.SetHistoryRecorder(func(changes []beads.FieldChange) {
+		for _, c := range changes {
+			if c.Field == "status" {
+				arb.metrics.RecordBeadTransition(c.ProjectID, c.OldValue, c.NewValue)
+			}
+		}
+		if arb.beadHistory != nil {
+			arb.beadHistory.Record(changes)
+		}
+	})
+
+	// Branches of parallel workflow nodes run as child beads; their joins
+	// are checked by the maintenance loop, as is the CI ci nodes wait for.
+	// Human approval gates wait on decision beads.
+	if workflowEngine != nil {
+		workflowEngine.SetBranchBeads(&workflowBranchBeads{beads: arb.beadsManager})
+		workflowEngine.SetApprovalGates(&workflowApprovalGates{loom: arb})
+		workflowEngine.SetCIChecks(&workflowCIChecks{loom: arb})
+	}
+
+	// Organizations; requests are scoped to one by the API server.
+	arb.orgManager = orgs.NewManager(db)
+
+	// Project boards; their WIP limits are checked whenever a bead is claimed.
+	arb.boardManager = board.NewManager(db, arb.beadsManager)
+	arb.beadsManager.SetTransitionCheck(arb.boardManager.CheckTransition)
+
+	// Ready beads gain priority the longer they wait, so low-priority work
+	// is not starved by a steady stream of urgent beads.
+	arb.beadsManager.SetPriorityAging(arb.priorityAging)
+
+	// A moved bead's records follow it to its new ID.
+	if db != nil {
+		arb.beadsManager.SetRecordMover(db.MoveBeadRecords)
+	}
 
 	arb.dispatcher = dispatch.NewDispatcher(arb.beadsManager, arb.projectManager, arb.agentManager, arb.providerRegistry, eb)
 	arb.readinessCache = make(map[string]projectReadinessState)
 	arb.readinessFailures = make(map[string]time.Time)
 	arb.dispatcher.SetReadinessCheck(arb.CheckProjectReadiness)
 	arb.dispatcher.SetReadinessMode(dispatch.ReadinessMode(cfg.Readiness.Mode))
+	if arb.projectConfig != nil {
+		arb.dispatcher.SetProjectReadinessMode(arb.projectConfig.ReadinessMode)
+	}
 	arb.dispatcher.SetMaxDispatchHops(cfg.Dispatch.MaxHops)
+	arb.dispatcher.SetLoopThresholds(arb.loopThresholds)
 	arb.dispatcher.SetEscalator(arb)
+	if budgetMgr != nil {
+		arb.dispatcher.SetBudgetCheck(budgetMgr.Check)
+		budgetMgr.SetMetrics(arb.metrics)
+	}
+	arb.dispatcher.SetMetrics(arb.metrics)
+	if containerOrch != nil {
+		containerOrch.SetMetrics(arb.metrics)
+	}
+	arb.dispatcher.SetWIPCheck(arb.boardManager.CheckTransition)
+	if arb.beadTypeManager != nil {
+		arb.dispatcher.SetTypeWorkflow(arb.beadTypeManager.Workflow)
+	}
 	// Enable conversation context support for multi-turn conversations
 	if db != nil {
 		arb.dispatcher.SetDatabase(db)
@@ -417,6 +719,11 @@ func New(cfg *config.Config) (*Loom, error) {
 	worktreeManager := gitops.NewGitWorktreeManager(projectKeyDir)
 	arb.dispatcher.SetWorktreeManager(worktreeManager)
 
+	// Run agent commands under their project's sandbox limits
+	if shellExec != nil {
+		shellExec.SetSandbox(sandbox.New(&cfg.Sandbox), arb.projectManager)
+	}
+
 	// Wire container orchestrator for per-project isolation
 	if containerOrch != nil {
 		arb.dispatcher.SetContainerOrchestrator(containerOrch)
@@ -810,6 +1117,15 @@ func (a *Loom) Initialize(ctx context.Context) error {
 		if p.BeadPrefix != "" {
 			a.beadsManager.SetProjectPrefix(p.ID, p.BeadPrefix)
 		}
+		if prefix := a.projectConfig.BeadPrefix(p.ID); prefix != "" {
+			a.beadsManager.SetProjectPrefix(p.ID, prefix)
+		}
+		// Archived projects stay frozen: no beads in memory, no container.
+		if p.Status == models.ProjectStatusArchived {
+			log.Printf("[Loom] Project %s is archived, not loading its beads", p.ID)
+			continue
+		}
+
 		// Load historical beads from main worktree first (baseline).
 		// These may not yet be on the beads-sync branch.
 		mainWorktree := wtManager.GetWorktreePath(p.ID, "main")
@@ -945,6 +1261,7 @@ func (a *Loom) Initialize(ctx context.Context) error {
 				LastHeartbeatLatencyMs: p.LastHeartbeatLatencyMs,
 			})
 		}
+		a.loadProviderPolicies()
 
 		// Count providers ready for dispatch; re-probe any that aren't healthy.
 		// checkProviderHealthAndActivate is normally called when a provider is first
@@ -1040,9 +1357,41 @@ func (a *Loom) Initialize(ctx context.Context) error {
 		}
 	}
 
+	// Probe project containers and restart the ones that stay unhealthy.
+	if a.containerOrchestrator != nil {
+		go a.containerOrchestrator.StartHealthMonitor(ctx, 30*time.Second)
+		if a.agentPKI != nil {
+			go a.containerOrchestrator.StartCertRotation(ctx, time.Minute)
+		}
+	}
+
+	// Persist events and feed the activity feed from the store.
+	if a.eventStore != nil {
+		go a.eventStore.Run(ctx)
+	}
+
+	// Run the scheduled object-storage exports.
+	if a.exportManager != nil {
+		go a.exportManager.Run(ctx)
+	}
+
+	// Take scheduled backups.
+	if a.backupManager != nil {
+		go a.backupManager.Run(ctx)
+	}
+
+	// Hand the beads of remote agents that stopped sending heartbeats back
+	// to the ready queue.
+	if a.remoteAgents != nil {
+		go a.remoteAgents.StartReaper(ctx, remoteagent.HeartbeatInterval)
+	}
+
 	// Start the Ralph Loop — a plain goroutine ticker that runs maintenance
 	// every 10 seconds (resets stuck agents, auto-blocks looped beads, etc.).
 	ralphActs := ralph.New(a.database, a.dispatcher, a.beadsManager, a.agentManager)
+	if a.config.Agents.SummaryProvider != "" {
+		ralphActs.SetRootCauseAnalyzer(ralph.NewRegistryCompleter(a.providerRegistry, a.config.Agents.SummaryProvider, a.config.Agents.SummaryModel))
+	}
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
@@ -1065,6 +1414,9 @@ func (a *Loom) Initialize(ctx context.Context) error {
 	// Kick-start work on all open beads across registered projects.
 	a.kickstartOpenBeads(ctx)
 
+	// Materialize scheduled beads as their cron expressions come due.
+	a.beadScheduler.Start(ctx)
+
 	// Register default motivations for all agent roles
 	if a.motivationRegistry != nil {
 		if err := motivation.RegisterDefaults(a.motivationRegistry); err != nil {
@@ -1291,6 +1643,15 @@ func (a *Loom) Shutdown() {
 		if a.openclawBridge != nil {
 			a.openclawBridge.Close()
 		}
+		if a.slackBridge != nil {
+			a.slackBridge.Close()
+		}
+		if a.beadScheduler != nil {
+			a.beadScheduler.Close()
+		}
+		if a.webhooksManager != nil {
+			a.webhooksManager.Close()
+		}
 		if a.doltCoordinator != nil {
 			a.doltCoordinator.Shutdown()
 		}
@@ -1312,6 +1673,11 @@ func (a *Loom) GetEventBus() *eventbus.EventBus {
 	return a.eventBus
 }
 
+// GetEventStore returns the durable event store (nil without a database).
+func (a *Loom) GetEventStore() *eventstore.Store {
+	return a.eventStore
+}
+
 // GetDatabase returns the database instance
 func (a *Loom) GetDatabase() *database.Database {
 	return a.database
@@ -1399,6 +1765,10 @@ func (a *Loom) SetKeyManager(km *keymanager.KeyManager) {
 	if a.gitopsManager != nil {
 		a.gitopsManager.SetKeyManager(km)
 	}
+	// and into the shell executor, which injects project secrets
+	if a.shellExecutor != nil && km != nil {
+		a.shellExecutor.SetSecrets(km)
+	}
 }
 
 // GetKeyManager returns the key manager
@@ -1525,6 +1895,69 @@ func (a *Loom) GetCommentsManager() *comments.Manager {
 	return a.commentsManager
 }
 
+// GetAttachmentsManager returns the bead attachments manager (nil without a database).
+func (a *Loom) GetAttachmentsManager() *attachments.Manager {
+	return a.attachmentsManager
+}
+
+// configureLogging applies the configured log levels, retention and output
+// to m.
+func configureLogging(m *logging.Manager, cfg config.LoggingConfig) {
+	if err := m.Levels().Apply(logging.LevelConfig{Default: cfg.Level, Subsystems: cfg.Subsystems}); err != nil {
+		log.Printf("[Loom] Invalid logging config, using defaults: %v", err)
+	}
+	// A broken archive target turns pruning off rather than deleting
+	// entries that were meant to be archived.
+	if policy, err := logging.RetentionFromConfig(cfg); err != nil {
+		log.Printf("[Loom] Log archive misconfigured, log pruning disabled: %v", err)
+	} else {
+		m.SetRetention(policy)
+	}
+	switch strings.ToLower(cfg.Output) {
+	case "", "stderr":
+		m.SetOutput(os.Stderr)
+	case "stdout":
+		m.SetOutput(os.Stdout)
+	case "none":
+		m.SetOutput(nil)
+	default:
+		log.Printf("[Loom] Unknown logging output %q, using stderr", cfg.Output)
+		m.SetOutput(os.Stderr)
+	}
+}
+
+// exportDatasets returns the datasets scheduled exports can ship.
+func exportDatasets(db *database.Database, beadsMgr *beads.Manager) []export.Dataset {
+	if db == nil {
+		return nil
+	}
+	return []export.Dataset{
+		export.TableDataset(db.DB(), "analytics", "analytics_request_logs", "timestamp"),
+		export.TableDataset(db.DB(), "logs", "logs", "timestamp"),
+		{
+			Name:     "beads",
+			Snapshot: true,
+			Read: func(_ context.Context, _, _ time.Time, emit func(interface{}) error) error {
+				list, err := beadsMgr.ListBeads(nil)
+				if err != nil {
+					return err
+				}
+				for _, b := range list {
+					if err := emit(b); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// GetExportManager returns the scheduled export manager
+func (a *Loom) GetExportManager() *export.Manager {
+	return a.exportManager
+}
+
 // GetLogManager returns the log manager
 func (a *Loom) GetLogManager() *logging.Manager {
 	return a.logManager
@@ -1550,6 +1983,77 @@ func (a *Loom) GetOpenClawClient() *openclaw.Client {
 	return a.openclawClient
 }
 
+// GetBeadScheduler returns the recurring bead scheduler (nil without a database).
+func (a *Loom) GetBeadScheduler() *scheduler.Manager {
+	return a.beadScheduler
+}
+
+// GetCostWatcher returns the spend forecaster and anomaly detector (nil
+// without a database).
+func (a *Loom) GetCostWatcher() *analytics.CostWatcher {
+	return a.costWatcher
+}
+
+// GetBudgetManager returns the token/cost budget manager (nil without a database).
+func (a *Loom) GetBudgetManager() *budget.Manager {
+	return a.budgetManager
+}
+
+// GetCommandPolicy returns the command policy manager (nil without a database).
+func (a *Loom) GetCommandPolicy() *cmdpolicy.Manager {
+	return a.commandPolicy
+}
+
+// GetAuditLog returns the API audit log (nil without a database).
+func (a *Loom) GetAuditLog() *auditlog.Manager {
+	return a.auditLog
+}
+
+// GetBeadHistory returns the per-bead change log (nil without a database).
+func (a *Loom) GetBeadHistory() *beadhistory.Manager {
+	return a.beadHistory
+}
+
+// GetOrgManager returns the organization manager (nil without a database).
+func (a *Loom) GetOrgManager() *orgs.Manager {
+	return a.orgManager
+}
+
+// GetSLAManager returns the bead SLA policy manager (nil without a database).
+func (a *Loom) GetSLAManager() *sla.Manager {
+	return a.slaManager
+}
+
+// GetMilestoneManager returns the milestone manager (nil without a database).
+func (a *Loom) GetMilestoneManager() *milestone.Manager {
+	return a.milestoneManager
+}
+
+// GetBeadTypeManager returns the bead type registry (nil without a database).
+func (a *Loom) GetBeadTypeManager() *beadtype.Manager {
+	return a.beadTypeManager
+}
+
+// GetProjectConfig returns the per-project config manager
+func (a *Loom) GetProjectConfig() *projectconfig.Manager {
+	return a.projectConfig
+}
+
+// GetRemoteAgents returns the remote agent manager
+func (a *Loom) GetRemoteAgents() *remoteagent.Manager {
+	return a.remoteAgents
+}
+
+// GetBoardManager returns the project board manager
+func (a *Loom) GetBoardManager() *board.Manager {
+	return a.boardManager
+}
+
+// GetWebhooksManager returns the outbound webhooks manager (nil without a database).
+func (a *Loom) GetWebhooksManager() *webhooks.Manager {
+	return a.webhooksManager
+}
+
 // GetOpenClawBridge returns the OpenClaw EventBus bridge (nil when disabled).
 func (a *Loom) GetOpenClawBridge() *openclaw.Bridge {
 	return a.openclawBridge
@@ -1560,6 +2064,12 @@ func (a *Loom) GetContainerOrchestrator() *containers.Orchestrator {
 	return a.containerOrchestrator
 }
 
+// GetAgentPKI returns the project agent certificate authority, or nil when
+// agent mTLS is disabled.
+func (a *Loom) GetAgentPKI() *agentpki.Authority {
+	return a.agentPKI
+}
+
 // AdvanceWorkflowWithCondition advances a bead's workflow with a specific condition
 func (a *Loom) AdvanceWorkflowWithCondition(beadID, agentID string, condition string, resultData map[string]string) error {
 	if a.workflowEngine == nil {
@@ -1672,7 +2182,11 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 		return err
 	}
 
-	// Create or get the org chart for this project
+	// Restore a customized org chart saved through the API, then create or
+	// get the org chart for this project
+	if _, err := a.orgChartManager.GetByProject(projectID); err != nil {
+		a.loadOrgChartLayout(projectID, project.Name)
+	}
 	chart, err := a.orgChartManager.CreateForProject(projectID, project.Name)
 	if err != nil {
 		return err
@@ -1681,12 +2195,16 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 	// Backfill any positions from the default template that are missing from
 	// the existing project chart. This ensures that new personas added to
 	// DefaultOrgChartPositions() are automatically propagated to all existing
-	// projects without requiring a fresh project creation.
+	// projects without requiring a fresh project creation. Default roles the
+	// project removed on purpose stay removed.
 	defaultPositions := models.DefaultOrgChartPositions()
 	existingRoles := make(map[string]struct{})
 	for _, p := range chart.Positions {
 		existingRoles[p.RoleName] = struct{}{}
 	}
+	for _, role := range chart.RemovedRoles {
+		existingRoles[role] = struct{}{}
+	}
 	for _, tmplPos := range defaultPositions {
 		if _, alreadyExists := existingRoles[tmplPos.RoleName]; !alreadyExists {
 			newPos := models.Position{
@@ -1698,6 +2216,9 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 				ReportsTo:    tmplPos.ReportsTo,
 				AgentIDs:     []string{},
 			}
+			if chart.GetPositionByID(newPos.ReportsTo) == nil {
+				newPos.ReportsTo = ""
+			}
 			if addErr := a.orgChartManager.AddPosition(projectID, newPos); addErr == nil {
 				log.Printf("[OrgChart] Backfilled missing position %q for project %s", tmplPos.RoleName, projectID)
 			}
@@ -1706,7 +2227,16 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 		}
 	}
 
+	// The corp profile's allowed roles only limit the default roles; custom
+	// positions were added on purpose and are always staffed.
 	allowedRoles := a.allowedRoleSet()
+	roleAllowed := func(role string) bool {
+		if len(allowedRoles) == 0 || a.orgChartManager.GetDefaultTemplate().GetPositionByRole(role) == nil {
+			return true
+		}
+		_, ok := allowedRoles[strings.ToLower(role)]
+		return ok
+	}
 
 	// Map existing agents to their roles (check in-memory first)
 	existingByRole := map[string]string{} // role -> agentID
@@ -1759,10 +2289,8 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 	// Fill positions from existing agents first
 	for i := range chart.Positions {
 		pos := &chart.Positions[i]
-		if len(allowedRoles) > 0 {
-			if _, ok := allowedRoles[strings.ToLower(pos.RoleName)]; !ok {
-				continue
-			}
+		if !roleAllowed(pos.RoleName) {
+			continue
 		}
 		if agentID, ok := existingByRole[pos.RoleName]; ok {
 			if !pos.HasAgent(agentID) && pos.CanAddAgent() {
@@ -1773,10 +2301,8 @@ func (a *Loom) ensureOrgChart(ctx context.Context, projectID string) error {
 
 	// Create agents for ALL positions that are still vacant (agents start paused without a provider)
 	for _, pos := range chart.Positions {
-		if len(allowedRoles) > 0 {
-			if _, ok := allowedRoles[strings.ToLower(pos.RoleName)]; !ok {
-				continue
-			}
+		if !roleAllowed(pos.RoleName) {
+			continue
 		}
 		if pos.IsFilled() {
 			continue
@@ -2604,6 +3130,32 @@ func (a *Loom) GetProviderModels(ctx context.Context, providerID string) ([]prov
 	return a.providerRegistry.GetModels(ctx, providerID)
 }
 
+// TestProvider runs the provider diagnostics and records the probe result as
+// the provider's latest heartbeat, so the outcome shows up in the provider
+// list as well as in the returned report.
+func (a *Loom) TestProvider(ctx context.Context, providerID string) (*provider.DiagnosticReport, error) {
+	registered, err := a.providerRegistry.Get(providerID)
+	if err != nil {
+		return nil, err
+	}
+	report := provider.Diagnose(ctx, registered)
+
+	latency := report.ProbeMs
+	if report.ProbeError != "" {
+		latency = -1
+	}
+	a.providerRegistry.UpdateHeartbeatLatency(providerID, latency)
+	if a.database != nil {
+		if dbProvider, err := a.database.GetProvider(providerID); err == nil && dbProvider != nil {
+			dbProvider.LastHeartbeatAt = report.StartedAt
+			dbProvider.LastHeartbeatLatencyMs = report.ProbeMs
+			dbProvider.LastHeartbeatError = report.ProbeError
+			_ = a.database.UpsertProvider(dbProvider)
+		}
+	}
+	return report, nil
+}
+
 // ReplResult represents a CEO REPL response.
 type ReplResult struct {
 	BeadID       string `json:"bead_id"`
@@ -2618,6 +3170,12 @@ type ReplResult struct {
 // RunReplQuery sends a high-priority query to the best provider.
 // All CEO queries automatically create P0 beads to preserve state.
 func (a *Loom) RunReplQuery(ctx context.Context, message string) (*ReplResult, error) {
+	return a.RunReplQueryStream(ctx, message, nil)
+}
+
+// RunReplQueryStream is RunReplQuery with the response passed to onDelta as
+// it is generated. Providers that can't stream deliver it in one piece.
+func (a *Loom) RunReplQueryStream(ctx context.Context, message string, onDelta func(delta string)) (*ReplResult, error) {
 	if strings.TrimSpace(message) == "" {
 		return nil, fmt.Errorf("message is required")
 	}
@@ -2700,7 +3258,15 @@ func (a *Loom) RunReplQuery(ctx context.Context, message string) (*ReplResult, e
 	}
 
 	queryStart := time.Now()
-	resp, err := regProvider.Protocol.CreateChatCompletion(ctx, req)
+	var resp *provider.ChatCompletionResponse
+	if sp, ok := regProvider.Protocol.(provider.StreamingProtocol); ok && onDelta != nil {
+		resp, err = provider.CollectStream(ctx, sp, req, onDelta)
+	} else {
+		resp, err = regProvider.Protocol.CreateChatCompletion(ctx, req)
+		if err == nil && onDelta != nil && len(resp.Choices) > 0 {
+			onDelta(resp.Choices[0].Message.Content)
+		}
+	}
 	latencyMs := time.Since(queryStart).Milliseconds()
 	if err != nil {
 		// Update bead with error if it was created
@@ -2832,7 +3398,7 @@ func (a *Loom) selectBestProviderForRepl() (*internalmodels.Provider, error) {
 func (a *Loom) buildLoomPersonaPrompt() string {
 	persona, err := a.personaManager.LoadPersona("loom")
 	if err != nil {
-		return fmt.Sprintf("You are Loom, the orchestration system. Respond to the CEO with clear guidance and actionable next steps.\n\n%s", actions.ActionPrompt)
+		return fmt.Sprintf("You are Loom, the orchestration system. Respond to the CEO with clear guidance and actionable next steps.\n\n%s", actions.CurrentActionPrompt())
 	}
 
 	focus := strings.Join(persona.FocusAreas, ", ")
@@ -2846,7 +3412,7 @@ func (a *Loom) buildLoomPersonaPrompt() string {
 		strings.TrimSpace(focus),
 		strings.TrimSpace(persona.DecisionMaking),
 		strings.TrimSpace(standards),
-		actions.ActionPrompt,
+		actions.CurrentActionPrompt(),
 	)
 }
 
@@ -2974,92 +3540,38 @@ func (a *Loom) CreateBead(title, description string, priority models.BeadPriorit
 	return bead, nil
 }
 
-// tryAutoApproveCodeFix evaluates a code fix proposal for auto-approval.
-// Low-risk fixes (single file, no security impact, small diff) are closed
-// immediately. Higher-risk fixes stay open for agent review.
+// tryAutoApproveCodeFix runs a code fix proposal through the auto-approval
+// policy and closes it as approved when one of the policy's rules allows
+// it; other proposals stay open for review. The policy's decision, and with
+// auto_fix.ensemble_review a second model's review, are recorded on the
+// bead, and each auto-approval in the audit log.
 func (a *Loom) tryAutoApproveCodeFix(bead *models.Bead) {
-	risk, reasons := assessFixRisk(bead.Description)
-	log.Printf("[AutoApproval] Bead %s risk=%s reasons=%v", bead.ID, risk, reasons)
+	decision := a.evaluateFixApproval(bead)
+	log.Printf("[AutoApproval] Bead %s risk=%s reasons=%v", bead.ID, decision.Risk, decision.RiskReasons)
+	a.recordFixApproval(bead, decision)
 
-	if risk != "low" {
-		log.Printf("[AutoApproval] Bead %s requires manual CEO review (risk=%s)", bead.ID, risk)
+	if a.needsEnsembleReview(decision.Risk) {
+		if review := a.ensembleReviewFix(bead, decision.Risk, decision.RiskReasons); review.Verdict != fixVerdictApprove {
+			log.Printf("[AutoApproval] Bead %s not auto-approved: ensemble review verdict %s", bead.ID, review.Verdict)
+			return
+		}
+	}
+
+	if !decision.Approved {
+		log.Printf("[AutoApproval] Bead %s requires manual CEO review (risk=%s): %s",
+			bead.ID, decision.Risk, strings.Join(decision.Rejections, "; "))
 		return
 	}
 
 	// Wait briefly so the bead is fully persisted before we close it
 	time.Sleep(2 * time.Second)
 
-	reason := fmt.Sprintf("Auto-approved (risk=%s): %s", risk, strings.Join(reasons, "; "))
+	reason := fmt.Sprintf("Auto-approved by rule %s (risk=%s): %s", decision.Rule, decision.Risk, strings.Join(decision.RiskReasons, "; "))
 	if err := a.CloseBead(bead.ID, reason); err != nil {
 		log.Printf("[AutoApproval] Failed to auto-approve bead %s: %v", bead.ID, err)
 		return
 	}
-	log.Printf("[AutoApproval] Auto-approved code fix proposal %s", bead.ID)
-}
-
-// assessFixRisk evaluates the risk level of a code fix proposal.
-// Returns risk level ("low", "medium", "high") and list of reasons.
-func assessFixRisk(description string) (string, []string) {
-	lower := strings.ToLower(description)
-	var reasons []string
-
-	// High-risk indicators
-	highRiskPatterns := []string{
-		"security", "authentication", "authorization", "password",
-		"encryption", "token", "secret", "credential",
-		"database migration", "schema change", "drop table",
-		"delete all", "rm -rf", "force push",
-	}
-	for _, p := range highRiskPatterns {
-		if strings.Contains(lower, p) {
-			return "high", []string{"contains security/destructive keyword: " + p}
-		}
-	}
-
-	// Medium-risk: multi-file changes, API changes, config changes
-	mediumRiskPatterns := []string{
-		"breaking change", "api change", "config change",
-		"multiple files", "architecture", "refactor",
-	}
-	for _, p := range mediumRiskPatterns {
-		if strings.Contains(lower, p) {
-			reasons = append(reasons, "medium-risk pattern: "+p)
-		}
-	}
-	if len(reasons) > 0 {
-		return "medium", reasons
-	}
-
-	// Check risk assessment section in the proposal
-	if strings.Contains(lower, "risk level: high") || strings.Contains(lower, "risk: high") {
-		return "high", []string{"proposal self-assessed as high risk"}
-	}
-	if strings.Contains(lower, "risk level: medium") || strings.Contains(lower, "risk: medium") {
-		return "medium", []string{"proposal self-assessed as medium risk"}
-	}
-
-	// Low-risk indicators
-	if strings.Contains(lower, "risk level: low") || strings.Contains(lower, "risk: low") {
-		reasons = append(reasons, "proposal self-assessed as low risk")
-	}
-
-	lowRiskPatterns := []string{
-		"typo", "comment", "formatting", "whitespace",
-		"variable rename", "css", "style", "cosmetic",
-		"undefined variable", "missing import",
-		"single file", "one file",
-	}
-	for _, p := range lowRiskPatterns {
-		if strings.Contains(lower, p) {
-			reasons = append(reasons, "low-risk pattern: "+p)
-		}
-	}
-
-	if len(reasons) == 0 {
-		reasons = append(reasons, "no high/medium risk indicators detected")
-	}
-
-	return "low", reasons
+	log.Printf("[AutoApproval] Auto-approved code fix proposal %s by rule %s", bead.ID, decision.Rule)
 }
 
 // CloseBead closes a bead with an optional reason
@@ -3136,6 +3648,22 @@ func (a *Loom) createApplyFixBead(approvalBead *models.Bead, closeReason string)
 		projectID = a.config.GetSelfProjectID()
 	}
 
+	// Risky fixes get a second model's review before they are applied,
+	// unless one was already had when the proposal was made.
+	assessed := approvalBead.Context
+	if latest, err := a.beadsManager.GetBead(approvalBead.ID); err == nil && latest != nil {
+		assessed = latest.Context
+	}
+	risk, reasons := assessed[fixRiskKey], strings.Split(assessed[fixRiskReasonsKey], "; ")
+	if risk == "" {
+		decision := a.evaluateFixApproval(approvalBead)
+		risk, reasons = decision.Risk, decision.RiskReasons
+	}
+	review := fixReviewFromContext(assessed)
+	if review == nil && a.needsEnsembleReview(risk) {
+		review = a.ensembleReviewFix(approvalBead, risk, reasons)
+	}
+
 	// Create apply-fix bead
 	title := fmt.Sprintf("[apply-fix] Apply approved patch from %s", approvalBead.ID)
 
@@ -3149,11 +3677,15 @@ func (a *Loom) createApplyFixBead(approvalBead *models.Bead, closeReason string)
 
 ### Instructions
 
-1. Read the approved fix proposal from bead %s
-2. Extract the patch or code changes from the proposal
-3. Apply the changes using write_file or apply_patch action
-4. Verify the fix (compile/test if applicable)
-5. Update cache versions if needed (for frontend changes)
+Loom applies the proposal's patch itself and runs the project's build and
+tests; the outcome is under "Patch Application" below.
+
+1. If the patch applied, review the changes in the working tree (git_diff)
+2. If it conflicted, had no patch, or the build or tests failed, make the
+   changes from the approved proposal in bead %s yourself
+3. Verify the fix (compile/test if applicable)
+4. Update cache versions if needed (for frontend changes)
+5. Commit the changes
 6. Close this bead and the original bug bead %s
 7. Add comment to bug bead: "Fixed by applying approved patch from %s"
 
@@ -3164,7 +3696,7 @@ func (a *Loom) createApplyFixBead(approvalBead *models.Bead, closeReason string)
 ### Important Notes
 
 - This fix has been reviewed and approved by the CEO
-- Apply the changes exactly as specified in the proposal
+- Keep to the changes specified in the proposal
 - Test thoroughly after applying
 - Report any issues or unexpected errors immediately
 - If hot-reload is enabled, verify the fix works after automatic browser refresh
@@ -3178,6 +3710,10 @@ func (a *Loom) createApplyFixBead(approvalBead *models.Bead, closeReason string)
 		approvalBead.ID,
 		approvalBead.Description,
 	)
+	description += fmt.Sprintf("\n### Risk Assessment\n\n**Heuristic Risk:** %s (%s)\n", risk, strings.Join(reasons, "; "))
+	if review != nil {
+		description += fmt.Sprintf("**Independent Review:** %s by %s (%s)\n\n%s\n", review.Verdict, review.Reviewer, review.Model, review.Assessment)
+	}
 
 	// Create the bead
 	bead, err := a.CreateBead(title, description, models.BeadPriority(1), "task", projectID)
@@ -3204,10 +3740,22 @@ func (a *Loom) createApplyFixBead(approvalBead *models.Bead, closeReason string)
 		updates["assigned_to"] = agentID
 	}
 
+	// A fix the reviewer didn't approve waits for a human to unblock it.
+	// Otherwise the bead waits while Loom applies the patch.
+	vetoed := review != nil && review.Verdict != fixVerdictApprove
+	updates["status"] = models.BeadStatusBlocked
+	ctx["blocked_reason"] = "applying the approved patch"
+	if vetoed {
+		ctx["blocked_reason"] = fmt.Sprintf("%s %s from %s", ensembleVetoReason, review.Verdict, review.Reviewer)
+	}
+
 	if err := a.beadsManager.UpdateBead(bead.ID, updates); err != nil {
 		log.Printf("[AutoFix] Failed to update apply-fix bead %s: %v", bead.ID, err)
 		// Don't fail - bead is created, just missing some metadata
 	}
+	if !vetoed {
+		go a.applyFixPatchInBackground(bead.ID)
+	}
 
 	log.Printf("[AutoFix] Created apply-fix bead %s for approved proposal %s (original bug: %s)",
 		bead.ID, approvalBead.ID, originalBugID)
@@ -3276,6 +3824,7 @@ func (a *Loom) CreateDecisionBead(question, parentBeadID, requesterID string, op
 				"decision_id":  decision.ID,
 				"question":     question,
 				"requester_id": requesterID,
+				"priority":     priority,
 			},
 		})
 	}
@@ -3292,6 +3841,9 @@ func (a *Loom) MakeDecision(decisionID, deciderID, decisionText, rationale strin
 			return fmt.Errorf("decider not found: %w", err)
 		}
 	}
+	if err := a.checkApprover(decisionID, deciderID); err != nil {
+		return err
+	}
 
 	// Make decision
 	if err := a.decisionManager.MakeDecision(decisionID, deciderID, decisionText, rationale); err != nil {
@@ -3319,6 +3871,7 @@ func (a *Loom) MakeDecision(decisionID, deciderID, decisionText, rationale strin
 	}
 
 	_ = a.applyCEODecisionToParent(decisionID)
+	a.applyWorkflowApproval(decisionID)
 
 	return nil
 }
@@ -3362,6 +3915,8 @@ func (a *Loom) EscalateBeadToCEO(beadID, reason, returnedTo string) (*models.Dec
 				"decision_id": decision.ID,
 				"bead_id":     beadID,
 				"reason":      reason,
+				"question":    question,
+				"priority":    models.BeadPriorityP0,
 			},
 		})
 	}
@@ -3488,6 +4043,14 @@ func (a *Loom) ClaimBead(beadID, agentID string) error {
 
 // UpdateBead updates a bead and publishes relevant events.
 func (a *Loom) UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error) {
+	if status, ok := updates["status"].(models.BeadStatus); ok {
+		if err := a.beadsManager.CheckTransition(beadID, status); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.checkMilestone([]string{beadID}, updates); err != nil {
+		return nil, err
+	}
 	if err := a.beadsManager.UpdateBead(beadID, updates); err != nil {
 		return nil, err
 	}
@@ -3496,24 +4059,85 @@ func (a *Loom) UpdateBead(beadID string, updates map[string]interface{}) (*model
 	if err != nil {
 		return nil, err
 	}
+	a.publishBeadUpdate(bead, updates)
+	return bead, nil
+}
 
-	if a.eventBus != nil {
-		if status, ok := updates["status"].(models.BeadStatus); ok {
-			_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadStatusChange, beadID, bead.ProjectID, map[string]interface{}{
-				"status": string(status),
-			})
-			if status == models.BeadStatusClosed {
-				_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadCompleted, beadID, bead.ProjectID, map[string]interface{}{})
-			}
+// UpdateBeads applies the same updates to several beads, all or nothing.
+// On failure the error is a *beads.BulkUpdateError naming the bead that
+// failed, and none of the beads are changed.
+func (a *Loom) UpdateBeads(beadIDs []string, updates map[string]interface{}) ([]*models.Bead, error) {
+	if err := a.checkMilestone(beadIDs, updates); err != nil {
+		return nil, err
+	}
+	if err := a.beadsManager.UpdateBeads(beadIDs, updates); err != nil {
+		return nil, err
+	}
+
+	updated := make([]*models.Bead, 0, len(beadIDs))
+	for _, id := range beadIDs {
+		bead, err := a.beadsManager.GetBead(id)
+		if err != nil {
+			return nil, err
 		}
-		if assignedTo, ok := updates["assigned_to"].(string); ok && assignedTo != "" {
-			_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadAssigned, beadID, bead.ProjectID, map[string]interface{}{
-				"assigned_to": assignedTo,
-			})
+		a.publishBeadUpdate(bead, updates)
+		updated = append(updated, bead)
+	}
+	return updated, nil
+}
+
+// checkMilestone refuses updates that put beads in a milestone that is
+// closed or does not include their project, including moving a bead to a
+// project its milestone does not include.
+func (a *Loom) checkMilestone(beadIDs []string, updates map[string]interface{}) error {
+	milestoneID, setMilestone := updates["milestone_id"].(string)
+	movedTo, moved := updates["project_id"].(string)
+	if (setMilestone && milestoneID == "") || (!setMilestone && !moved) {
+		return nil
+	}
+	for _, id := range beadIDs {
+		bead, err := a.beadsManager.GetBead(id)
+		if err != nil {
+			return err
+		}
+		target, projectID := milestoneID, bead.ProjectID
+		if !setMilestone {
+			target = bead.MilestoneID
+		}
+		if moved {
+			projectID = movedTo
+		}
+		if target == "" || (!setMilestone && projectID == bead.ProjectID) {
+			continue
+		}
+		if a.milestoneManager == nil {
+			return fmt.Errorf("%w: milestones require a database", milestone.ErrInvalidAssignment)
+		}
+		if err := a.milestoneManager.CheckAssign(target, projectID); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	return bead, nil
+// publishBeadUpdate announces the status and assignment changes in updates.
+func (a *Loom) publishBeadUpdate(bead *models.Bead, updates map[string]interface{}) {
+	if a.eventBus == nil {
+		return
+	}
+	if status, ok := updates["status"].(models.BeadStatus); ok {
+		_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadStatusChange, bead.ID, bead.ProjectID, map[string]interface{}{
+			"status": string(status),
+		})
+		if status == models.BeadStatusClosed {
+			_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadCompleted, bead.ID, bead.ProjectID, map[string]interface{}{})
+		}
+	}
+	if assignedTo, ok := updates["assigned_to"].(string); ok && assignedTo != "" {
+		_ = a.eventBus.PublishBeadEvent(eventbus.EventTypeBeadAssigned, bead.ID, bead.ProjectID, map[string]interface{}{
+			"assigned_to": assignedTo,
+		})
+	}
 }
 
 // GetReadyBeads returns beads that are ready to work on
@@ -3526,6 +4150,63 @@ func (a *Loom) GetWorkGraph(projectID string) (*models.WorkGraph, error) {
 	return a.beadsManager.GetWorkGraph(projectID)
 }
 
+// RelateBeads relates a bead to another (see beads.AddRelation). A bead
+// marked as a duplicate is closed, pointing at the original.
+func (a *Loom) RelateBeads(beadID, relation, targetID, actor string) error {
+	if err := a.beadsManager.AddRelation(beadID, relation, targetID, actor); err != nil {
+		return err
+	}
+	if relation != beads.RelationDuplicateOf {
+		return nil
+	}
+	bead, err := a.beadsManager.GetBead(beadID)
+	if err != nil || bead.Status == models.BeadStatusClosed {
+		return err
+	}
+	return a.CloseBead(beadID, "Duplicate of "+bead.DuplicateOf)
+}
+
+// UnrelateBeads removes a relation added with RelateBeads. Closed
+// duplicates stay closed.
+func (a *Loom) UnrelateBeads(beadID, relation, targetID, actor string) error {
+	return a.beadsManager.RemoveRelation(beadID, relation, targetID, actor)
+}
+
+// MoveBead moves a bead to another project under a new ID (see
+// beads.MoveBead), taking its records in the database along (see
+// database.MoveBeadRecords); when they can't be moved, neither is the
+// bead. Its agent worktree in the old project is cleaned up.
+func (a *Loom) MoveBead(beadID, projectID, actor string) (*models.Bead, error) {
+	if a.projectManager != nil {
+		if _, err := a.projectManager.GetProject(projectID); err != nil {
+			return nil, fmt.Errorf("%w: project %s not found", beads.ErrInvalidMove, projectID)
+		}
+	}
+	old, err := a.beadsManager.GetBead(beadID)
+	if err != nil {
+		return nil, err
+	}
+	oldProjectID := old.ProjectID
+
+	moved, err := a.beadsManager.MoveBead(beadID, projectID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if oldProjectID != "" {
+		wtManager := gitops.NewGitWorktreeManager(a.config.Git.ProjectKeyDir)
+		if err := wtManager.CleanupAgentWorktree(oldProjectID, beadID); err != nil {
+			log.Printf("[Loom] Worktree cleanup for moved bead %s failed (non-fatal): %v", beadID, err)
+		}
+	}
+	log.Printf("[Loom] Moved bead %s from %s to %s as %s", beadID, oldProjectID, projectID, moved.ID)
+	return moved, nil
+}
+
+// GetBeadRelations returns everything a bead is related to.
+func (a *Loom) GetBeadRelations(beadID string) (*beads.Relations, error) {
+	return a.beadsManager.GetRelations(beadID)
+}
+
 // GetFileLockManager returns the file lock manager
 func (a *Loom) GetFileLockManager() *FileLockManager {
 	return a.fileLockManager
@@ -3542,6 +4223,12 @@ func (a *Loom) StartMaintenanceLoop(ctx context.Context) {
 	defer ticker.Stop()
 
 	var lastFederationSync time.Time
+	var lastAuditPrune time.Time
+	var lastEventPrune time.Time
+	var lastLogPrune time.Time
+	var lastTrashPurge time.Time
+	var lastCostCheck time.Time
+	var lastMemoryIndex time.Time
 
 	for {
 		select {
@@ -3576,6 +4263,9 @@ func (a *Loom) StartMaintenanceLoop(ctx context.Context) {
 
 			// Refresh bead cache to pick up beads created externally
 			for _, p := range a.projectManager.ListProjects() {
+				if p.Status == models.ProjectStatusArchived {
+					continue
+				}
 				beadsRoot := a.beadsManager.GetProjectBeadsPath(p.ID)
 				if beadsRoot == "" {
 					continue
@@ -3585,6 +4275,98 @@ func (a *Loom) StartMaintenanceLoop(ctx context.Context) {
 				}
 			}
 
+			// Record SLA breaches and escalate where policies ask for it
+			if n, err := a.slaManager.Check(); err != nil {
+				log.Printf("[Maintenance] SLA check failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[Maintenance] Recorded %d SLA breach(es)", n)
+			}
+
+			// Raise beads past their due date, and escalate them if configured
+			if n, err := a.checkOverdueBeads(); err != nil {
+				log.Printf("[Maintenance] Overdue bead check failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[Maintenance] %d bead(s) became overdue", n)
+			}
+
+			// Advance workflows whose parallel branches or CI have finished
+			if a.workflowEngine != nil {
+				if n, err := a.workflowEngine.SyncBranches(); err != nil {
+					log.Printf("[Maintenance] Workflow branch sync failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] %d workflow branch(es) finished", n)
+				}
+				if n, err := a.workflowEngine.SyncCI(); err != nil {
+					log.Printf("[Maintenance] Workflow CI sync failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] %d workflow(s) moved on after CI", n)
+				}
+			}
+
+			// Apply the audit log retention policy hourly
+			if a.auditLog != nil && time.Since(lastAuditPrune) >= time.Hour {
+				if n, err := a.auditLog.Prune(); err != nil {
+					log.Printf("[Maintenance] Audit log pruning failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] Pruned %d audit log entries", n)
+				}
+				lastAuditPrune = time.Now()
+			}
+
+			// Apply the event store retention policy hourly
+			if a.eventStore != nil && time.Since(lastEventPrune) >= time.Hour {
+				if n, err := a.eventStore.Prune(); err != nil {
+					log.Printf("[Maintenance] Event store pruning failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] Pruned %d stored events", n)
+				}
+				lastEventPrune = time.Now()
+			}
+
+			// Apply the log retention policy hourly
+			if a.logManager != nil && time.Since(lastLogPrune) >= time.Hour {
+				if res, err := a.logManager.EnforceRetention(ctx); err != nil {
+					log.Printf("[Maintenance] Log pruning failed: %v", err)
+				} else if res.Deleted > 0 {
+					log.Printf("[Maintenance] Pruned %d log entries (%d archive(s))", res.Deleted, len(res.Archives))
+				}
+				lastLogPrune = time.Now()
+			}
+
+			// Purge beads that have been in the trash past the retention window
+			if time.Since(lastTrashPurge) >= time.Hour {
+				keep := a.config.Beads.TrashRetention
+				if keep == 0 {
+					keep = beads.DefaultTrashRetention
+				}
+				if keep > 0 {
+					if n := a.beadsManager.PurgeTrash(time.Now().Add(-keep)); n > 0 {
+						log.Printf("[Maintenance] Purged %d bead(s) from the trash", n)
+					}
+				}
+				lastTrashPurge = time.Now()
+			}
+
+			// Report token/cost spikes, such as an agent stuck in a loop
+			if a.costWatcher != nil && time.Since(lastCostCheck) >= 15*time.Minute {
+				if n, err := a.costWatcher.Check(ctx); err != nil {
+					log.Printf("[Maintenance] Cost anomaly check failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] Reported %d cost anomaly(ies)", n)
+				}
+				lastCostCheck = time.Now()
+			}
+
+			// Index closed beads, lessons and project files for retrieval
+			if a.retriever != nil && time.Since(lastMemoryIndex) >= a.memoryIndexInterval() {
+				if n, err := a.indexProjectMemory(ctx); err != nil {
+					log.Printf("[Maintenance] Memory indexing failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[Maintenance] Indexed %d memory document(s)", n)
+				}
+				lastMemoryIndex = time.Now()
+			}
+
 			// Periodic federation sync
 			if a.config.Beads.Federation.Enabled && a.config.Beads.Federation.SyncInterval > 0 {
 				if time.Since(lastFederationSync) >= a.config.Beads.Federation.SyncInterval {
@@ -3844,29 +4626,48 @@ func (a *Loom) StartTaskExecutor(ctx context.Context) {
 	if a.database != nil {
 		lp := dispatch.NewLessonsProvider(a.database)
 		if lp != nil {
+			lp.SetRetriever(a.retriever, a.config.Memory.TopK)
 			exec.SetLessonsProvider(lp)
 		}
 	}
 
+	if a.agentManager != nil {
+		exec.SetAgentSource(a.agentManager.ListAgentsByProject)
+	}
+	exec.SetFileLocker(a.fileLockManager)
+	if a.projectConfig != nil {
+		exec.SetProjectMaxLoopIterations(a.projectConfig.MaxLoopIterations)
+		exec.SetProjectLimits(a.projectExecutorLimits)
+	}
+	exec.SetLoopThresholds(a.loopThresholds)
+	if err := exec.SetLimits(a.executorLimits()); err != nil {
+		log.Printf("[Executor] Failed to apply executor limits: %v", err)
+	}
+	exec.SetMetrics(a.metrics)
+	if a.summarizer != nil {
+		exec.SetSummarizer(a.summarizer)
+	}
+	if a.analyticsLogger != nil {
+		exec.SetAnalyticsLogger(a.analyticsLogger)
+	}
+
+	exec.SetPaused(a.ServerMode().Maintenance())
 	a.taskExecutor = exec
 
 	// Start watcher + initial workers for all currently registered projects
+	// that are not archived
+	started := make(map[string]struct{})
 	for _, proj := range a.projectManager.ListProjects() {
-		if proj == nil || proj.ID == "" {
+		if proj == nil || proj.ID == "" || proj.Status == models.ProjectStatusArchived {
 			continue
 		}
 		exec.Start(ctx, proj.ID)
+		started[proj.ID] = struct{}{}
 	}
 
-	// Watch for newly registered projects
+	// Watch for newly registered (or unarchived) projects
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
-	started := make(map[string]struct{})
-	for _, proj := range a.projectManager.ListProjects() {
-		if proj != nil && proj.ID != "" {
-			started[proj.ID] = struct{}{}
-		}
-	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -3876,6 +4677,11 @@ func (a *Loom) StartTaskExecutor(ctx context.Context) {
 				if proj == nil || proj.ID == "" {
 					continue
 				}
+				if proj.Status == models.ProjectStatusArchived {
+					// ArchiveProject stopped its workers
+					delete(started, proj.ID)
+					continue
+				}
 				if _, ok := started[proj.ID]; !ok {
 					log.Printf("[TaskExecutor] Starting executor for new project %s", proj.ID)
 					exec.Start(ctx, proj.ID)
@@ -3886,6 +4692,23 @@ func (a *Loom) StartTaskExecutor(ctx context.Context) {
 	}
 }
 
+// DrainTaskExecutor stops the task executor claiming beads and waits up to
+// executor.shutdown_grace_period for those in flight, then checkpoints the
+// rest to resume on restart. Ending ctx cuts the wait short. Call it before
+// canceling the executor's context.
+func (a *Loom) DrainTaskExecutor(ctx context.Context) {
+	if a.taskExecutor == nil {
+		return
+	}
+	grace := a.config.Executor.ShutdownGracePeriod
+	if grace <= 0 {
+		grace = taskexecutor.DefaultShutdownGracePeriod
+	}
+	if ids := a.taskExecutor.Drain(ctx, grace); len(ids) > 0 {
+		log.Printf("[TaskExecutor] Checkpointed %d interrupted beads: %v", len(ids), ids)
+	}
+}
+
 // WakeProject signals the task executor that new work is available for projectID.
 // Safe to call if no executor is running (no-op in that case).
 func (a *Loom) WakeProject(projectID string) {
@@ -3919,6 +4742,16 @@ func (a *Loom) checkProviderHealthAndActivate(providerID string) {
 	})
 	if err != nil {
 		log.Printf("Provider %s health probe failed: %v", providerID, err)
+		if a.eventBus != nil {
+			_ = a.eventBus.Publish(&eventbus.Event{
+				Type:   eventbus.EventTypeProviderUnhealthy,
+				Source: "provider-manager",
+				Data: map[string]interface{}{
+					"provider_id": providerID,
+					"error":       err.Error(),
+				},
+			})
+		}
 		return
 	}
 
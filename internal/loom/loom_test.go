@@ -93,6 +93,7 @@ func testLoom(t *testing.T, opts ...func(*config.Config)) (*Loom, string) {
 		os.RemoveAll(tmpDir)
 		t.Fatalf("Failed to create loom: %v", err)
 	}
+	l.beadsManager.SetBeadsPath(tmpDir + "/.beads")
 	t.Cleanup(func() {
 		l.Shutdown()
 		os.RemoveAll(tmpDir)
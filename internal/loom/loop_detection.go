@@ -0,0 +1,16 @@
+package loom
+
+import "github.com/jordanhubbard/loom/internal/dispatch"
+
+// GetBeadLoopReport explains a bead's loop detection state: whether it was
+// flagged as stuck, by which detector and on what evidence, and the
+// detectors and thresholds that apply to its project.
+func (a *Loom) GetBeadLoopReport(beadID string) (*dispatch.LoopReport, error) {
+	bead, err := a.beadsManager.GetBead(beadID)
+	if err != nil {
+		return nil, err
+	}
+	ld := dispatch.NewLoopDetector()
+	ld.SetThresholdLookup(a.loopThresholds)
+	return ld.Report(bead), nil
+}
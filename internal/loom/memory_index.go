@@ -0,0 +1,129 @@
+package loom
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/memory"
+	"github.com/jordanhubbard/loom/pkg/config"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// defaultMemoryIndexInterval is how often new project history is indexed
+// for retrieval when the config doesn't say.
+const defaultMemoryIndexInterval = 10 * time.Minute
+
+// memoryIndexCursorKey is the config_kv key holding when the last indexing
+// sweep started; each sweep only indexes what changed since.
+const memoryIndexCursorKey = "memory:index:last_run"
+
+// defaultMemoryFiles are the project files indexed when memory.files is
+// not configured: the ones that usually spell out a project's conventions.
+var defaultMemoryFiles = []string{
+	"README.md",
+	"CONTRIBUTING.md",
+	"AGENTS.md",
+	"ARCHITECTURE.md",
+	"docs/ARCHITECTURE.md",
+}
+
+// maxMemoryFileBytes skips files too large to be worth excerpting.
+const maxMemoryFileBytes = 256 * 1024
+
+// newMemoryRetriever creates the retriever agent memory is drawn from,
+// embedding with the configured endpoint and falling back to the local
+// hashing embedder.
+func newMemoryRetriever(db *database.Database, cfg config.MemoryConfig) *memory.Retriever {
+	var embedder memory.Embedder = memory.NewHashEmbedder()
+	if cfg.EmbeddingEndpoint != "" {
+		embedder = memory.NewFallbackEmbedder(memory.NewProviderEmbedder(cfg.EmbeddingEndpoint, cfg.EmbeddingAPIKey, cfg.EmbeddingModel))
+	}
+	return memory.NewRetriever(db, embedder)
+}
+
+func (a *Loom) memoryIndexInterval() time.Duration {
+	if a.config.Memory.IndexInterval > 0 {
+		return a.config.Memory.IndexInterval
+	}
+	return defaultMemoryIndexInterval
+}
+
+// indexProjectMemory indexes the beads closed, lessons recorded and
+// project files changed since the previous sweep, and returns how many
+// documents it indexed. The cursor only advances when the whole sweep
+// succeeds, so a failed sweep is retried in full; indexing is idempotent.
+func (a *Loom) indexProjectMemory(ctx context.Context) (int, error) {
+	if a.retriever == nil || a.database == nil {
+		return 0, nil
+	}
+	started := time.Now().UTC()
+	var since time.Time
+	if raw, ok, err := a.database.GetConfigValue(memoryIndexCursorKey); err == nil && ok {
+		since, _ = time.Parse(time.RFC3339Nano, raw)
+	}
+
+	indexed := 0
+	closed, err := a.beadsManager.ListBeads(map[string]interface{}{"status": models.BeadStatusClosed})
+	if err != nil {
+		return indexed, err
+	}
+	for _, b := range closed {
+		if b.ClosedAt == nil || !b.ClosedAt.After(since) {
+			continue
+		}
+		var summary string
+		if conv, err := a.database.GetConversationContextByBeadID(b.ID); err == nil && conv != nil {
+			summary = conv.Metadata["summary"]
+		}
+		if err := a.retriever.Index(ctx, memory.BeadDocument(b, summary)); err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+
+	for cursor := since; ; {
+		lessons, err := a.database.ListLessonsSince(cursor, 500)
+		if err != nil {
+			return indexed, err
+		}
+		for _, l := range lessons {
+			if err := a.retriever.Index(ctx, memory.LessonDocument(l)); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+		if len(lessons) < 500 {
+			break
+		}
+		cursor = lessons[len(lessons)-1].CreatedAt
+	}
+
+	files := a.config.Memory.Files
+	if len(files) == 0 {
+		files = defaultMemoryFiles
+	}
+	for _, p := range a.projectManager.ListProjects() {
+		if p.Status == models.ProjectStatusArchived || p.WorkDir == "" {
+			continue
+		}
+		for _, rel := range files {
+			info, err := os.Stat(filepath.Join(p.WorkDir, rel))
+			if err != nil || info.IsDir() || info.Size() > maxMemoryFileBytes || !info.ModTime().After(since) {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(p.WorkDir, rel))
+			if err != nil {
+				continue
+			}
+			if err := a.retriever.IndexFile(ctx, p.ID, rel, string(content)); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+	}
+
+	return indexed, a.database.SetConfigValue(memoryIndexCursorKey, started.Format(time.RFC3339Nano))
+}
@@ -3,6 +3,7 @@ package loom
 import (
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/milestone"
 	"github.com/jordanhubbard/loom/internal/motivation"
 )
 
@@ -47,14 +48,52 @@ func (p *LoomStateProvider) GetBeadsByStatus(status string) ([]string, error) {
 	return result, nil
 }
 
-// GetMilestones returns milestones for a project
+// GetMilestones returns the dated milestones that include a project
 func (p *LoomStateProvider) GetMilestones(projectID string) ([]*motivation.Milestone, error) {
-	return nil, nil
+	return p.milestones(projectID, func(*milestone.Milestone) bool { return true })
 }
 
-// GetUpcomingMilestones returns milestones within the specified days
+// GetUpcomingMilestones returns open milestones due within the specified days
 func (p *LoomStateProvider) GetUpcomingMilestones(withinDays int) ([]*motivation.Milestone, error) {
-	return nil, nil
+	cutoff := time.Now().AddDate(0, 0, withinDays)
+	return p.milestones("", func(ms *milestone.Milestone) bool {
+		return ms.IsOpen() && ms.DueDate.Before(cutoff)
+	})
+}
+
+// milestones converts the milestones with a due date that keep accepts.
+func (p *LoomStateProvider) milestones(projectID string, keep func(*milestone.Milestone) bool) ([]*motivation.Milestone, error) {
+	mgr := p.loom.milestoneManager
+	if mgr == nil {
+		return nil, nil
+	}
+	list, err := mgr.List(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*motivation.Milestone
+	for _, ms := range list {
+		if ms.DueDate == nil || !keep(ms) {
+			continue
+		}
+		projectID := projectID
+		if projectID == "" {
+			projectID = ms.ProjectIDs[0]
+		}
+		result = append(result, &motivation.Milestone{
+			ID:          ms.ID,
+			ProjectID:   projectID,
+			Name:        ms.Name,
+			Description: ms.Description,
+			Type:        ms.Type,
+			Status:      ms.Status,
+			DueDate:     *ms.DueDate,
+			CompletedAt: ms.CompletedAt,
+			CreatedAt:   ms.CreatedAt,
+			UpdatedAt:   ms.UpdatedAt,
+		})
+	}
+	return result, nil
 }
 
 // GetIdleAgents returns IDs of agents that are currently idle
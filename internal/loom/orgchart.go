@@ -0,0 +1,136 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/orgchart"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// A project's org chart starts as a copy of the default template. Once it
+// is edited through the API its layout is saved to the database, restored
+// on start, and only added to by the default-position backfill; default
+// roles removed on purpose are not brought back.
+
+// GetProjectOrgChart returns a project's org chart, creating it if needed.
+func (a *Loom) GetProjectOrgChart(ctx context.Context, projectID string) (*models.OrgChart, error) {
+	if chart, err := a.orgChartManager.GetByProject(projectID); err == nil {
+		return chart, nil
+	}
+	if err := a.ensureOrgChart(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return a.orgChartManager.GetByProject(projectID)
+}
+
+// AddOrgChartPosition adds a position, such as a custom role, to a
+// project's org chart and staffs it if its persona exists.
+func (a *Loom) AddOrgChartPosition(ctx context.Context, projectID string, pos models.Position, actor string) (*models.OrgChart, error) {
+	if _, err := a.GetProjectOrgChart(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if err := a.orgChartManager.AddPosition(projectID, pos); err != nil {
+		return nil, err
+	}
+	if err := a.saveOrgChartLayout(projectID, actor); err != nil {
+		return nil, err
+	}
+	if err := a.ensureOrgChart(ctx, projectID); err != nil {
+		log.Printf("[OrgChart] Failed to staff new position %q in project %s: %v", pos.RoleName, projectID, err)
+	}
+	return a.orgChartManager.GetByProject(projectID)
+}
+
+// UpdateOrgChartPosition changes a position's persona, reporting line,
+// required flag or maximum number of agents.
+func (a *Loom) UpdateOrgChartPosition(ctx context.Context, projectID, positionID string, update orgchart.PositionUpdate, actor string) (*models.OrgChart, error) {
+	if _, err := a.GetProjectOrgChart(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := a.orgChartManager.UpdatePosition(projectID, positionID, update); err != nil {
+		return nil, err
+	}
+	if err := a.saveOrgChartLayout(projectID, actor); err != nil {
+		return nil, err
+	}
+	return a.orgChartManager.GetByProject(projectID)
+}
+
+// RemoveOrgChartPosition removes a position from a project's org chart.
+// Agents that held it stay in the project, unassigned.
+func (a *Loom) RemoveOrgChartPosition(ctx context.Context, projectID, positionID, actor string) (*models.OrgChart, error) {
+	if _, err := a.GetProjectOrgChart(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if err := a.orgChartManager.RemovePosition(projectID, positionID); err != nil {
+		return nil, err
+	}
+	if err := a.saveOrgChartLayout(projectID, actor); err != nil {
+		return nil, err
+	}
+	return a.orgChartManager.GetByProject(projectID)
+}
+
+// ResetOrgChart discards a project's org chart customizations and
+// rebuilds its chart from the default template.
+func (a *Loom) ResetOrgChart(ctx context.Context, projectID string) (*models.OrgChart, error) {
+	if _, err := a.projectManager.GetProject(projectID); err != nil {
+		return nil, err
+	}
+	if a.database != nil {
+		if err := a.database.DeleteOrgChartLayout(projectID); err != nil {
+			return nil, err
+		}
+	}
+	_ = a.orgChartManager.DeleteForProject(projectID)
+	if err := a.ensureOrgChart(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return a.orgChartManager.GetByProject(projectID)
+}
+
+// saveOrgChartLayout persists a project's customized org chart. Without a
+// database, customizations last until restart.
+func (a *Loom) saveOrgChartLayout(projectID, actor string) error {
+	if a.database == nil {
+		return nil
+	}
+	chart, err := a.orgChartManager.GetByProject(projectID)
+	if err != nil {
+		return err
+	}
+	err = a.database.SaveOrgChartLayout(&database.OrgChartLayout{
+		ProjectID:    projectID,
+		Positions:    chart.Positions,
+		RemovedRoles: chart.RemovedRoles,
+		UpdatedBy:    actor,
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("org chart changed but not saved: %w", err)
+	}
+	return nil
+}
+
+// loadOrgChartLayout restores a project's saved org chart layout, if it
+// has one.
+func (a *Loom) loadOrgChartLayout(projectID, projectName string) {
+	if a.database == nil {
+		return
+	}
+	layout, err := a.database.GetOrgChartLayout(projectID)
+	if err != nil {
+		log.Printf("[OrgChart] Failed to load saved org chart for project %s: %v", projectID, err)
+		return
+	}
+	if layout == nil {
+		return
+	}
+	if _, err := a.orgChartManager.LoadLayout(projectID, projectName, layout.Positions, layout.RemovedRoles); err != nil {
+		log.Printf("[OrgChart] Failed to restore org chart for project %s: %v", projectID, err)
+	}
+}
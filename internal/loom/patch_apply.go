@@ -0,0 +1,302 @@
+package loom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/approval"
+	"github.com/jordanhubbard/loom/internal/files"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Context keys recording the server-side application of an approved fix's
+// patch on its apply-fix bead.
+const (
+	patchStatusKey    = "patch_status"
+	patchStrategyKey  = "patch_strategy"
+	patchFilesKey     = "patch_files"
+	patchConflictsKey = "patch_conflicts"
+	patchBuildKey     = "patch_build"
+	patchTestsKey     = "patch_tests"
+	patchAppliedAtKey = "patch_applied_at"
+)
+
+// Patch application statuses.
+const (
+	// PatchStatusApplied: the patch applied and the build and tests passed,
+	// or had nothing to run.
+	PatchStatusApplied = "applied"
+	// PatchStatusFailed: the patch applied but the build or tests failed.
+	// The patch stays applied for the agent to fix.
+	PatchStatusFailed = "failed"
+	// PatchStatusConflict: the patch didn't apply, even as a three-way
+	// merge, and nothing changed.
+	PatchStatusConflict = "conflict"
+	// PatchStatusNoPatch: the proposal has no unified diff to apply.
+	PatchStatusNoPatch = "no_patch"
+	// PatchStatusError: the patch couldn't be tried, e.g. it touches a
+	// blocked file or the project has no work directory.
+	PatchStatusError = "error"
+)
+
+// Build and test check outcomes.
+const (
+	PatchCheckPassed  = "passed"
+	PatchCheckFailed  = "failed"
+	PatchCheckSkipped = "skipped"
+)
+
+// ErrFixNotApproved is returned when asked to apply the patch of a fix
+// that wasn't approved, or that the ensemble review vetoed.
+var ErrFixNotApproved = errors.New("fix not approved")
+
+// patchApplyTimeout bounds applying a patch and running the build and
+// tests after it.
+const patchApplyTimeout = 30 * time.Minute
+
+// patchReportOutputLimit caps the command output quoted in the report
+// appended to the bead's description.
+const patchReportOutputLimit = 2000
+
+// PatchCheck is the outcome of the build or test run after a patch.
+type PatchCheck struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// PatchApplication reports applying an approved fix's patch.
+type PatchApplication struct {
+	BeadID         string      `json:"bead_id"`
+	ApprovalBeadID string      `json:"approval_bead_id"`
+	Status         string      `json:"status"`
+	Strategy       string      `json:"strategy,omitempty"`
+	Files          []string    `json:"files,omitempty"`
+	Conflicts      []string    `json:"conflicts,omitempty"`
+	Output         string      `json:"output,omitempty"`
+	Build          *PatchCheck `json:"build,omitempty"`
+	Tests          *PatchCheck `json:"tests,omitempty"`
+	Error          string      `json:"error,omitempty"`
+	AppliedAt      time.Time   `json:"applied_at"`
+}
+
+// ApplyFixPatch applies the patch of an apply-fix bead's approved proposal
+// to the project, falling back to a three-way merge when the code has moved
+// on, then runs the project's build and tests. The outcome is recorded on
+// the bead and returned. The proposal must have been approved, and a bead
+// the ensemble review vetoed stays untouched until someone unblocks it;
+// otherwise, and for a bead that isn't an apply-fix bead, it returns an
+// error.
+func (a *Loom) ApplyFixPatch(ctx context.Context, beadID string) (*PatchApplication, error) {
+	bead, err := a.beadsManager.GetBead(beadID)
+	if err != nil {
+		return nil, fmt.Errorf("bead not found: %w", err)
+	}
+	approvalID := bead.Context["approval_bead_id"]
+	if approvalID == "" {
+		return nil, fmt.Errorf("bead %s is not an apply-fix bead", beadID)
+	}
+	approvalBead, err := a.beadsManager.GetBead(approvalID)
+	if err != nil {
+		return nil, fmt.Errorf("approval bead %s not found: %w", approvalID, err)
+	}
+	if !fixApproved(approvalBead) {
+		return nil, fmt.Errorf("%w: proposal %s is not approved", ErrFixNotApproved, approvalID)
+	}
+	if bead.Status == models.BeadStatusBlocked && strings.HasPrefix(bead.Context["blocked_reason"], ensembleVetoReason) {
+		return nil, fmt.Errorf("%w: %s", ErrFixNotApproved, bead.Context["blocked_reason"])
+	}
+
+	app := &PatchApplication{BeadID: beadID, ApprovalBeadID: approvalID, AppliedAt: time.Now().UTC()}
+	a.applyProposalPatch(ctx, bead, approval.ExtractPatch(approvalBead.Description), app)
+	a.recordPatchApplication(bead, app)
+	return app, nil
+}
+
+// fixApproved reports whether a code fix approval bead was closed as
+// approved, the same test that creates its apply-fix bead.
+func fixApproved(approvalBead *models.Bead) bool {
+	return approvalBead.Status == models.BeadStatusClosed &&
+		strings.Contains(strings.ToLower(approvalBead.Context["close_reason"]), "approve")
+}
+
+// applyProposalPatch applies patch to the bead's project and, once it has
+// applied, runs the build and then the tests.
+func (a *Loom) applyProposalPatch(ctx context.Context, bead *models.Bead, patch string, app *PatchApplication) {
+	if patch == "" {
+		app.Status = PatchStatusNoPatch
+		return
+	}
+	if a.fileManager == nil {
+		app.Status, app.Error = PatchStatusError, "file manager not configured"
+		return
+	}
+
+	res, err := a.fileManager.ApplyPatchWithFallback(ctx, bead.ProjectID, patch)
+	if res != nil {
+		app.Strategy, app.Files, app.Conflicts, app.Output = res.Strategy, res.Files, res.Conflicts, res.Output
+	}
+	if err != nil {
+		app.Status, app.Error = PatchStatusError, err.Error()
+		if errors.Is(err, files.ErrPatchConflict) {
+			app.Status = PatchStatusConflict
+		}
+		return
+	}
+
+	app.Status = PatchStatusApplied
+	if a.actionRouter == nil {
+		return
+	}
+	actx := actions.ActionContext{AgentID: "system", BeadID: bead.ID, ProjectID: bead.ProjectID}
+	app.Build = a.runPatchCheck(ctx, actions.ActionBuildProject, actx)
+	if app.Build.Status == PatchCheckFailed {
+		app.Status = PatchStatusFailed
+		return
+	}
+	app.Tests = a.runPatchCheck(ctx, actions.ActionRunTests, actx)
+	if app.Tests.Status == PatchCheckFailed {
+		app.Status = PatchStatusFailed
+	}
+}
+
+// runPatchCheck runs the project's build or tests through the action
+// router, so the project's configured commands apply.
+func (a *Loom) runPatchCheck(ctx context.Context, actionType string, actx actions.ActionContext) *PatchCheck {
+	results, err := a.actionRouter.Execute(ctx, &actions.ActionEnvelope{Actions: []actions.Action{{Type: actionType}}}, actx)
+	if err != nil {
+		return &PatchCheck{Status: PatchCheckFailed, Message: err.Error()}
+	}
+	if len(results) == 0 {
+		return &PatchCheck{Status: PatchCheckSkipped}
+	}
+	return patchCheckOutcome(results[0])
+}
+
+// patchCheckOutcome reads a build_project or run_tests result. A runner
+// that isn't configured skips the check rather than failing it.
+func patchCheckOutcome(res actions.Result) *PatchCheck {
+	check := &PatchCheck{Status: PatchCheckPassed, Message: res.Message}
+	if success, ok := res.Metadata["success"].(bool); ok {
+		if !success {
+			check.Status = PatchCheckFailed
+		}
+		return check
+	}
+	if res.Status == "error" {
+		check.Status = PatchCheckFailed
+		if res.Metadata == nil && strings.HasSuffix(res.Message, "not configured") {
+			check.Status = PatchCheckSkipped
+		}
+	}
+	return check
+}
+
+// recordPatchApplication records the outcome on the bead's context and
+// appends a report to its description.
+func (a *Loom) recordPatchApplication(bead *models.Bead, app *PatchApplication) {
+	ctx := map[string]string{
+		patchStatusKey:    app.Status,
+		patchStrategyKey:  app.Strategy,
+		patchFilesKey:     strings.Join(app.Files, ", "),
+		patchConflictsKey: strings.Join(app.Conflicts, ", "),
+		patchBuildKey:     "",
+		patchTestsKey:     "",
+		patchAppliedAtKey: app.AppliedAt.Format(time.RFC3339),
+	}
+	if app.Build != nil {
+		ctx[patchBuildKey] = app.Build.Status
+	}
+	if app.Tests != nil {
+		ctx[patchTestsKey] = app.Tests.Status
+	}
+
+	description := bead.Description
+	if latest, err := a.beadsManager.GetBead(bead.ID); err == nil && latest != nil {
+		description = latest.Description
+	}
+	if err := a.beadsManager.UpdateBead(bead.ID, map[string]interface{}{
+		"description": description + patchReport(app),
+		"context":     ctx,
+	}); err != nil {
+		log.Printf("[AutoFix] Failed to record patch application on %s: %v", bead.ID, err)
+	}
+}
+
+// patchReport renders the outcome as a section of the bead's description.
+func patchReport(app *PatchApplication) string {
+	var sb strings.Builder
+	sb.WriteString("\n### Patch Application\n\n")
+	fmt.Fprintf(&sb, "**Status:** %s\n", app.Status)
+	if app.Strategy != "" {
+		fmt.Fprintf(&sb, "**Strategy:** %s\n", app.Strategy)
+	}
+	if len(app.Files) > 0 {
+		fmt.Fprintf(&sb, "**Files:** %s\n", strings.Join(app.Files, ", "))
+	}
+	if len(app.Conflicts) > 0 {
+		fmt.Fprintf(&sb, "**Conflicts:** %s\n", strings.Join(app.Conflicts, ", "))
+	}
+	for _, c := range []struct {
+		name  string
+		check *PatchCheck
+	}{{"Build", app.Build}, {"Tests", app.Tests}} {
+		if c.check != nil {
+			fmt.Fprintf(&sb, "**%s:** %s\n", c.name, c.check.Status)
+		}
+	}
+	if app.Error != "" {
+		fmt.Fprintf(&sb, "**Error:** %s\n", app.Error)
+	}
+
+	switch app.Status {
+	case PatchStatusApplied:
+		sb.WriteString("\nThe patch is applied in the project's working tree. Review it, then commit it.\n")
+	case PatchStatusFailed:
+		sb.WriteString("\nThe patch is applied in the project's working tree, but the checks above failed. Fix them, then commit.\n")
+	default:
+		sb.WriteString("\nNothing was changed. Apply the proposal's changes by hand.\n")
+	}
+	for _, c := range []*PatchCheck{app.Build, app.Tests} {
+		if c != nil && c.Status == PatchCheckFailed && c.Message != "" {
+			fmt.Fprintf(&sb, "\n```\n%s\n```\n", truncateOutput(c.Message))
+		}
+	}
+	if app.Status == PatchStatusConflict && app.Output != "" {
+		fmt.Fprintf(&sb, "\n```\n%s\n```\n", truncateOutput(app.Output))
+	}
+	return sb.String()
+}
+
+func truncateOutput(s string) string {
+	if len(s) <= patchReportOutputLimit {
+		return s
+	}
+	return s[:patchReportOutputLimit] + "\n... (truncated)"
+}
+
+// applyFixPatchInBackground applies an apply-fix bead's patch, holding the
+// bead blocked meanwhile so no agent starts on it, then reopens it for the
+// agent to review and commit the result.
+func (a *Loom) applyFixPatchInBackground(beadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), patchApplyTimeout)
+	defer cancel()
+
+	app, err := a.ApplyFixPatch(ctx, beadID)
+	if err != nil {
+		log.Printf("[AutoFix] Failed to apply patch for %s: %v", beadID, err)
+	} else {
+		log.Printf("[AutoFix] Patch for %s: %s (strategy %q)", beadID, app.Status, app.Strategy)
+	}
+
+	if err := a.beadsManager.UpdateBead(beadID, map[string]interface{}{
+		"status":  models.BeadStatusOpen,
+		"context": map[string]string{"blocked_reason": ""},
+	}); err != nil {
+		log.Printf("[AutoFix] Failed to reopen %s after applying its patch: %v", beadID, err)
+	}
+}
@@ -0,0 +1,126 @@
+package loom
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/files"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type staticWorkDir string
+
+func (d staticWorkDir) GetProjectWorkDir(projectID string) string { return string(d) }
+
+func TestApplyProposalPatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("teh readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "README.md"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	a := &Loom{fileManager: files.NewManager(staticWorkDir(dir))}
+	bead := &models.Bead{ID: "loom-fix-1", ProjectID: "loom"}
+
+	patch := "--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-teh readme\n+the readme\n"
+	app := &PatchApplication{}
+	a.applyProposalPatch(context.Background(), bead, patch, app)
+	if app.Status != PatchStatusApplied || app.Strategy != files.StrategyApply || len(app.Files) != 1 {
+		t.Errorf("application = %+v", app)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "README.md")); string(data) != "the readme\n" {
+		t.Errorf("README.md = %q", data)
+	}
+
+	// The same patch no longer applies, and nothing changes.
+	app = &PatchApplication{}
+	a.applyProposalPatch(context.Background(), bead, patch, app)
+	if app.Status != PatchStatusConflict {
+		t.Errorf("reapplied: %+v", app)
+	}
+	if report := patchReport(app); !strings.Contains(report, "Nothing was changed") {
+		t.Errorf("report = %s", report)
+	}
+
+	app = &PatchApplication{}
+	a.applyProposalPatch(context.Background(), bead, "", app)
+	if app.Status != PatchStatusNoPatch {
+		t.Errorf("without a patch: %+v", app)
+	}
+}
+
+func TestApplyFixPatch_RequiresApproval(t *testing.T) {
+	bm := beads.NewManager("")
+	bm.SetBeadsPath(t.TempDir())
+	a := &Loom{beadsManager: bm}
+
+	proposal, _ := bm.CreateBead("Code fix approval for bd-1", "", models.BeadPriorityP1, "decision", "loom")
+	fix, _ := bm.CreateBead("[apply-fix] Apply approved patch", "", models.BeadPriorityP1, "task", "loom")
+	if err := bm.UpdateBead(fix.ID, map[string]interface{}{
+		"status":  models.BeadStatusBlocked,
+		"context": map[string]string{"approval_bead_id": proposal.ID, "blocked_reason": ensembleVetoReason + " reject from reviewer"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.ApplyFixPatch(context.Background(), fix.ID); !errors.Is(err, ErrFixNotApproved) {
+		t.Errorf("open proposal: error = %v, want ErrFixNotApproved", err)
+	}
+	if err := bm.UpdateBead(proposal.ID, map[string]interface{}{
+		"status":  models.BeadStatusClosed,
+		"context": map[string]string{"close_reason": "Approved by CEO"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ApplyFixPatch(context.Background(), fix.ID); !errors.Is(err, ErrFixNotApproved) {
+		t.Errorf("vetoed fix: error = %v, want ErrFixNotApproved", err)
+	}
+
+	// Once someone unblocks the vetoed fix, its patch may be applied.
+	if err := bm.UpdateBead(fix.ID, map[string]interface{}{"status": models.BeadStatusOpen}); err != nil {
+		t.Fatal(err)
+	}
+	app, err := a.ApplyFixPatch(context.Background(), fix.ID)
+	if err != nil {
+		t.Fatalf("ApplyFixPatch() error = %v", err)
+	}
+	if app.Status != PatchStatusNoPatch {
+		t.Errorf("status = %s, want %s", app.Status, PatchStatusNoPatch)
+	}
+}
+
+func TestPatchCheckOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		res  actions.Result
+		want string
+	}{
+		{"tests passed", actions.Result{Status: "executed", Metadata: map[string]interface{}{"success": true}}, PatchCheckPassed},
+		{"tests failed", actions.Result{Status: "executed", Metadata: map[string]interface{}{"success": false}}, PatchCheckFailed},
+		{"build passed", actions.Result{Status: "executed", Message: "build passed"}, PatchCheckPassed},
+		{"build failed", actions.Result{Status: "error", Message: "build failed (exit 2)"}, PatchCheckFailed},
+		{"no runner", actions.Result{Status: "error", Message: "test runner not configured"}, PatchCheckSkipped},
+	}
+	for _, tt := range tests {
+		if got := patchCheckOutcome(tt.res); got.Status != tt.want {
+			t.Errorf("%s: patchCheckOutcome() = %s, want %s", tt.name, got.Status, tt.want)
+		}
+	}
+}
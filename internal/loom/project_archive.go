@@ -0,0 +1,192 @@
+package loom
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// maxArchivedConversations bounds the conversation contexts one archive
+// holds; a project has one per bead it has worked, so this is generous.
+const maxArchivedConversations = 100000
+
+// ProjectArchiveData is the cold storage of an archived project, kept
+// gzip-compressed as JSON in the project_archives table.
+type ProjectArchiveData struct {
+	ProjectID     string                        `json:"project_id"`
+	ArchivedAt    time.Time                     `json:"archived_at"`
+	Beads         []*models.Bead                `json:"beads"`
+	Conversations []*models.ConversationContext `json:"conversations"`
+}
+
+// ArchiveProject freezes a project: it stops the project's task executor
+// workers and container, moves its beads and conversation history into
+// cold storage, and marks it archived so it drops out of default listings.
+// The beads themselves stay in the project's git repository; archiving
+// only evicts them from memory.
+func (a *Loom) ArchiveProject(ctx context.Context, projectID, actor string) (*database.ProjectArchive, error) {
+	if a.database == nil {
+		return nil, fmt.Errorf("archiving projects requires a database")
+	}
+	p, err := a.projectManager.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsPerpetual {
+		return nil, fmt.Errorf("cannot archive perpetual project: %s", p.Name)
+	}
+	if p.Status == models.ProjectStatusArchived {
+		return nil, fmt.Errorf("project already archived: %s", projectID)
+	}
+
+	beadList, err := a.beadsManager.ListBeads(map[string]interface{}{"project_id": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beads: %w", err)
+	}
+	conversations, err := a.database.ListConversationContextsByProject(projectID, maxArchivedConversations)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	data, err := packProjectArchive(&ProjectArchiveData{
+		ProjectID:     projectID,
+		ArchivedAt:    now,
+		Beads:         beadList,
+		Conversations: conversations,
+	})
+	if err != nil {
+		return nil, err
+	}
+	archive := &database.ProjectArchive{
+		ProjectID:         projectID,
+		PriorStatus:       string(p.Status),
+		ArchivedBy:        actor,
+		ArchivedAt:        now,
+		BeadCount:         len(beadList),
+		ConversationCount: len(conversations),
+		Data:              data,
+	}
+	if err := a.database.CreateProjectArchive(archive); err != nil {
+		return nil, err
+	}
+
+	// Mark the project archived before stopping its workers, so the task
+	// executor does not start them again.
+	if _, err := a.projectManager.ArchiveProject(projectID); err != nil {
+		return nil, err
+	}
+	a.PersistProject(projectID)
+
+	if a.taskExecutor != nil {
+		a.taskExecutor.StopProject(projectID)
+	}
+	if p.UseContainer && a.containerOrchestrator != nil {
+		if err := a.containerOrchestrator.StopProjectContainer(ctx, projectID); err != nil {
+			log.Printf("[Loom] Warning: failed to stop container for archived project %s: %v", projectID, err)
+		}
+	}
+	a.beadsManager.ClearProjectBeads(projectID)
+
+	log.Printf("[Loom] Archived project %s (%d beads, %d conversations, %d bytes)",
+		projectID, archive.BeadCount, archive.ConversationCount, len(data))
+	return archive, nil
+}
+
+// UnarchiveProject thaws an archived project: its conversation history is
+// restored, its beads are reloaded from git and it returns to the status it
+// had. The task executor picks the project up again on its next pass.
+func (a *Loom) UnarchiveProject(ctx context.Context, projectID string) error {
+	if a.database == nil {
+		return fmt.Errorf("archiving projects requires a database")
+	}
+	p, err := a.projectManager.GetProject(projectID)
+	if err != nil {
+		return err
+	}
+	archive, err := a.database.GetProjectArchive(projectID)
+	if err != nil {
+		return err
+	}
+	if archive == nil {
+		return fmt.Errorf("project is not archived: %s", projectID)
+	}
+	data, err := UnpackProjectArchive(archive.Data)
+	if err != nil {
+		return err
+	}
+
+	for _, conv := range data.Conversations {
+		if err := a.database.CreateConversationContext(conv); err != nil {
+			return fmt.Errorf("failed to restore conversation %s: %w", conv.SessionID, err)
+		}
+	}
+	if err := a.projectManager.UnarchiveProject(projectID, models.ProjectStatus(archive.PriorStatus)); err != nil {
+		return err
+	}
+	a.PersistProject(projectID)
+	if err := a.database.DeleteProjectArchive(projectID); err != nil {
+		return err
+	}
+
+	if _, err := a.ReloadProjectBeads(ctx, projectID); err != nil {
+		log.Printf("[Loom] Warning: failed to reload beads for unarchived project %s: %v", projectID, err)
+	}
+	if p.UseContainer && a.containerOrchestrator != nil {
+		projCopy := *p
+		go func() {
+			if err := a.containerOrchestrator.EnsureProjectContainer(context.Background(), &projCopy); err != nil {
+				log.Printf("[Loom] Warning: failed to start container for unarchived project %s: %v", projCopy.ID, err)
+			}
+		}()
+	}
+
+	log.Printf("[Loom] Unarchived project %s (%d conversations restored)", projectID, len(data.Conversations))
+	return nil
+}
+
+// GetProjectArchive returns an archived project's cold storage, or nil if
+// the project is not archived.
+func (a *Loom) GetProjectArchive(projectID string) (*database.ProjectArchive, error) {
+	if a.database == nil {
+		return nil, nil
+	}
+	return a.database.GetProjectArchive(projectID)
+}
+
+func packProjectArchive(data *ProjectArchiveData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to encode project archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress project archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnpackProjectArchive decompresses the data of a project archive.
+func UnpackProjectArchive(packed []byte) (*ProjectArchiveData, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(packed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress project archive: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress project archive: %w", err)
+	}
+	data := &ProjectArchiveData{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to decode project archive: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,34 @@
+package loom
+
+import (
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestProjectArchiveData_RoundTrip(t *testing.T) {
+	conv := models.NewConversationContext("session-1", "bead-1", "proj-1", 0)
+	conv.AddMessage("user", "hello", 1)
+	packed, err := packProjectArchive(&ProjectArchiveData{
+		ProjectID:     "proj-1",
+		Beads:         []*models.Bead{{ID: "bead-1", Title: "Old work", ProjectID: "proj-1"}},
+		Conversations: []*models.ConversationContext{conv},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := UnpackProjectArchive(packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Beads) != 1 || data.Beads[0].Title != "Old work" {
+		t.Errorf("beads = %+v", data.Beads)
+	}
+	if len(data.Conversations) != 1 || len(data.Conversations[0].Messages) != 1 {
+		t.Errorf("conversations = %+v", data.Conversations)
+	}
+	if _, err := UnpackProjectArchive([]byte("not gzip")); err == nil {
+		t.Error("unpacked garbage")
+	}
+}
@@ -0,0 +1,106 @@
+package loom
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/audit"
+	"github.com/jordanhubbard/loom/internal/dispatch"
+	"github.com/jordanhubbard/loom/internal/projectconfig"
+)
+
+// SetProjectConfig overrides one of a project's settings. A new bead prefix
+// applies to the next bead created; the other settings are read each time
+// they are used.
+func (a *Loom) SetProjectConfig(projectID, key, value, actor string) (*projectconfig.Setting, error) {
+	if a.projectConfig == nil {
+		return nil, fmt.Errorf("project config requires a database")
+	}
+	if _, err := a.projectManager.GetProject(projectID); err != nil {
+		return nil, err
+	}
+	setting, err := a.projectConfig.Set(projectID, key, value, actor)
+	if err != nil {
+		return nil, err
+	}
+	if key == projectconfig.KeyBeadPrefix {
+		a.beadsManager.SetProjectPrefix(projectID, setting.Value)
+	}
+	return setting, nil
+}
+
+// priorityAging returns how long a project's ready beads wait before their
+// priority is raised a level: the project's own setting, or the global one.
+func (a *Loom) priorityAging(projectID string) time.Duration {
+	if d, ok := a.projectConfig.PriorityAging(projectID); ok {
+		return d
+	}
+	return a.config.Dispatch.PriorityAging
+}
+
+// loopThresholds returns the loop detection thresholds for a project: each
+// of its own settings, or the global one.
+func (a *Loom) loopThresholds(projectID string) dispatch.LoopThresholds {
+	global := a.config.Dispatch.LoopDetection
+	t := dispatch.LoopThresholds{
+		RepeatedErrors:    global.RepeatedErrors,
+		RepeatedActions:   global.RepeatedActions,
+		NoProgressEdits:   global.NoProgressEdits,
+		BuildOscillations: global.BuildOscillations,
+		Disabled:          global.Disabled,
+	}
+	return t.Merge(dispatch.LoopThresholds{
+		RepeatedErrors:    a.projectConfig.LoopThreshold(projectID, projectconfig.KeyLoopRepeatedErrors),
+		RepeatedActions:   a.projectConfig.LoopThreshold(projectID, projectconfig.KeyLoopRepeatedActions),
+		NoProgressEdits:   a.projectConfig.LoopThreshold(projectID, projectconfig.KeyLoopNoProgressEdits),
+		BuildOscillations: a.projectConfig.LoopThreshold(projectID, projectconfig.KeyLoopBuildOscillations),
+		Disabled:          a.disabledLoopDetectors(projectID),
+	})
+}
+
+func (a *Loom) disabledLoopDetectors(projectID string) []string {
+	disabled, _ := a.projectConfig.DisabledLoopDetectors(projectID)
+	return disabled
+}
+
+// AuditOptions returns the self-audit checks to run on a project and their
+// coverage threshold: the project's own settings, or the global ones.
+func (a *Loom) AuditOptions(projectID string) audit.Options {
+	opts := audit.Options{CoverageThreshold: a.config.SelfAudit.CoverageThreshold}
+	if len(a.config.SelfAudit.Checks) > 0 {
+		opts.Checks = a.config.SelfAudit.Checks
+	}
+	if checks, ok := a.projectConfig.AuditChecks(projectID); ok {
+		opts.Checks = checks
+	}
+	if n, ok := a.projectConfig.AuditCoverageThreshold(projectID); ok {
+		opts.CoverageThreshold = n
+	}
+	return opts
+}
+
+// UnsetProjectConfig returns one of a project's settings to the global
+// default.
+func (a *Loom) UnsetProjectConfig(projectID, key string) error {
+	if a.projectConfig == nil {
+		return fmt.Errorf("project config requires a database")
+	}
+	p, err := a.projectManager.GetProject(projectID)
+	if err != nil {
+		return err
+	}
+	if err := a.projectConfig.Unset(projectID, key); err != nil {
+		return err
+	}
+	if key == projectconfig.KeyBeadPrefix {
+		// Back to the prefix Initialize gave the project.
+		a.beadsManager.SetProjectPrefix(projectID, "")
+		if beadsPath := a.beadsManager.GetProjectBeadsPath(projectID); beadsPath != "" {
+			_ = a.beadsManager.LoadProjectPrefixFromConfig(projectID, beadsPath)
+		}
+		if p.BeadPrefix != "" {
+			a.beadsManager.SetProjectPrefix(projectID, p.BeadPrefix)
+		}
+	}
+	return nil
+}
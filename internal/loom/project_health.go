@@ -0,0 +1,91 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/projecthealth"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ProjectHealth scores a project's health from its readiness, open P0
+// beads, SLA breaches, provider and container health, and recent error
+// rate. Signals loom has no data for are left out of the score.
+func (a *Loom) ProjectHealth(ctx context.Context, projectID string) (*projecthealth.Report, error) {
+	if _, err := a.projectManager.GetProject(projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %s", projectID)
+	}
+	now := time.Now()
+	in := projecthealth.Inputs{ProjectID: projectID}
+	in.Ready, in.ReadinessIssues = a.CheckProjectReadiness(ctx, projectID)
+
+	beads, err := a.beadsManager.ListBeads(map[string]interface{}{"project_id": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beads: %w", err)
+	}
+	for _, b := range beads {
+		if b.Priority == models.BeadPriorityP0 && b.Status != models.BeadStatusClosed {
+			in.OpenP0 = append(in.OpenP0, b.ID)
+		}
+	}
+
+	if a.slaManager != nil {
+		report, err := a.slaManager.Report(projectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build SLA report: %w", err)
+		}
+		in.SLA = &projecthealth.SLAState{
+			Tracked:        report.Summary.Tracked,
+			Breached:       report.Summary.Breached,
+			AtRisk:         report.Summary.AtRisk,
+			RecentBreaches: len(report.RecentBreaches),
+		}
+	}
+
+	if a.database != nil {
+		providers, err := a.database.ListProviders()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list providers: %w", err)
+		}
+		in.Providers = []projecthealth.ProviderState{}
+		for _, p := range providers {
+			in.Providers = append(in.Providers, projecthealth.ProviderState{
+				ID:      p.ID,
+				Healthy: p.Status == "healthy" || p.Status == "active",
+				Error:   p.LastHeartbeatError,
+			})
+		}
+	}
+
+	if a.containerOrchestrator != nil {
+		if info, err := a.containerOrchestrator.ContainerStatus(ctx, projectID); err == nil {
+			in.Container = &projecthealth.ContainerState{
+				Running:   info.Running,
+				Healthy:   info.Healthy,
+				Restarts:  info.Restarts,
+				LastError: info.LastError,
+			}
+		}
+	}
+
+	if a.analyticsLogger != nil {
+		logs, err := a.analyticsLogger.GetLogs(ctx, &analytics.LogFilter{
+			ProjectID: projectID,
+			StartTime: now.Add(-projecthealth.ErrorWindow),
+			EndTime:   now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request logs: %w", err)
+		}
+		for _, l := range logs {
+			in.Requests++
+			if l.ErrorMessage != "" || l.StatusCode >= 400 {
+				in.Errors++
+			}
+		}
+	}
+
+	return projecthealth.Build(in, now), nil
+}
@@ -0,0 +1,60 @@
+package loom
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+)
+
+// SetProviderPolicy installs and persists a provider routing policy for a
+// project, or the default policy when projectID is empty.
+func (a *Loom) SetProviderPolicy(projectID string, policy *provider.RoutingPolicy) error {
+	if err := a.providerRegistry.SetPolicy(projectID, policy); err != nil {
+		return err
+	}
+	if a.database == nil {
+		return nil
+	}
+	data, err := json.Marshal(a.providerRegistry.Policy(projectID))
+	if err != nil {
+		return fmt.Errorf("failed to encode provider policy: %w", err)
+	}
+	return a.database.UpsertProviderPolicy(projectID, string(data))
+}
+
+// DeleteProviderPolicy removes a project's routing policy (or the default
+// policy when projectID is empty).
+func (a *Loom) DeleteProviderPolicy(projectID string) error {
+	a.providerRegistry.RemovePolicy(projectID)
+	if a.database == nil {
+		return nil
+	}
+	return a.database.DeleteProviderPolicy(projectID)
+}
+
+// loadProviderPolicies restores persisted routing policies into the registry.
+func (a *Loom) loadProviderPolicies() {
+	if a.database == nil {
+		return
+	}
+	stored, err := a.database.ListProviderPolicies()
+	if err != nil {
+		log.Printf("[Loom] Failed to load provider policies: %v", err)
+		return
+	}
+	for projectID, data := range stored {
+		var policy provider.RoutingPolicy
+		if err := json.Unmarshal([]byte(data), &policy); err != nil {
+			log.Printf("[Loom] Ignoring invalid provider policy for %q: %v", projectID, err)
+			continue
+		}
+		if err := a.providerRegistry.SetPolicy(projectID, &policy); err != nil {
+			log.Printf("[Loom] Ignoring invalid provider policy for %q: %v", projectID, err)
+		}
+	}
+	if len(stored) > 0 {
+		log.Printf("[Loom] Loaded %d provider routing policies", len(stored))
+	}
+}
@@ -0,0 +1,124 @@
+package loom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/notifications"
+	"github.com/jordanhubbard/loom/internal/report"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// GenerateReport compiles a project's report for the period ending at end,
+// renders it, and stores it.
+func (a *Loom) GenerateReport(ctx context.Context, projectID string, period report.Period, end time.Time) (*report.Report, error) {
+	if a.database == nil {
+		return nil, fmt.Errorf("reports need a database")
+	}
+	proj, err := a.projectManager.GetProject(projectID)
+	if err != nil || proj == nil {
+		return nil, fmt.Errorf("project not found: %s", projectID)
+	}
+	beads, err := a.beadsManager.ListBeads(map[string]interface{}{"project_id": projectID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list beads: %w", err)
+	}
+	var logs []*analytics.RequestLog
+	if a.analyticsLogger != nil {
+		logs, err = a.analyticsLogger.GetLogs(ctx, &analytics.LogFilter{
+			ProjectID: projectID,
+			StartTime: end.Add(-period.Length()),
+			EndTime:   end,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request logs: %w", err)
+		}
+	}
+	var agents []*models.Agent
+	if a.agentManager != nil {
+		agents = a.agentManager.ListAgents()
+	}
+
+	r := report.Build(proj, period, end, beads, logs, agents)
+	r.ID = "report-" + uuid.New().String()[:8]
+	r.GeneratedAt = time.Now().UTC()
+	if err := r.Render(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	err = a.database.CreateReport(&database.Report{
+		ID:          r.ID,
+		ProjectID:   r.ProjectID,
+		Period:      string(r.Period),
+		PeriodStart: r.Start,
+		PeriodEnd:   r.End,
+		DataJSON:    string(data),
+		CreatedAt:   r.GeneratedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// HasReport reports whether a project's report for the period ending at
+// end was already generated.
+func (a *Loom) HasReport(projectID string, period report.Period, end time.Time) (bool, error) {
+	if a.database == nil {
+		return false, fmt.Errorf("reports need a database")
+	}
+	return a.database.HasReport(projectID, string(period), end)
+}
+
+// GetReport returns a stored report, or nil if there is none with the ID.
+func (a *Loom) GetReport(id string) (*report.Report, error) {
+	if a.database == nil {
+		return nil, fmt.Errorf("reports need a database")
+	}
+	stored, err := a.database.GetReport(id)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	r := &report.Report{}
+	if err := json.Unmarshal([]byte(stored.DataJSON), r); err != nil {
+		return nil, fmt.Errorf("failed to decode report %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// ListReports returns the stored reports of a project, newest first.
+func (a *Loom) ListReports(projectID string, limit int) ([]*database.Report, error) {
+	if a.database == nil {
+		return nil, fmt.Errorf("reports need a database")
+	}
+	return a.database.ListReports(projectID, limit)
+}
+
+// DistributeReport notifies the users subscribed to report.generated of a
+// new report. Those with email on get the report itself by email.
+func (a *Loom) DistributeReport(r *report.Report) error {
+	if a.notificationManager == nil {
+		return nil
+	}
+	_, err := a.notificationManager.NotifySubscribers(r.ProjectID, &notifications.Notification{
+		EventType: "report.generated",
+		Title:     r.Title(),
+		Message:   r.Headline(),
+		Link:      fmt.Sprintf("/api/v1/reports/%s?format=html", r.ID),
+		Priority:  notifications.PriorityLow,
+		Metadata: map[string]interface{}{
+			"report_id":                r.ID,
+			"project_id":               r.ProjectID,
+			notifications.EmailBodyKey: r.Markdown,
+		},
+	})
+	return err
+}
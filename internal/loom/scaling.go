@@ -0,0 +1,125 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/orgchart"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// RoleScale reports the agents filling a role after ScaleRole.
+type RoleScale struct {
+	ProjectID string          `json:"project_id"`
+	Role      string          `json:"role"`
+	Replicas  int             `json:"replicas"`
+	Agents    []*models.Agent `json:"agents"`
+}
+
+// ScaleRole sets how many agents fill a role in a project, and caps the
+// role's org chart position at that many. The task executor runs one bead
+// at a time per agent and shards the role's beads across them.
+//
+// Scaling up first resumes agents a previous scale-down took off the
+// position, then creates new ones from the position's persona with the
+// capabilities of the agents already there. Scaling down drains the most
+// recently added agents, so they finish their current bead before they stop,
+// and takes them off the position.
+func (a *Loom) ScaleRole(ctx context.Context, projectID, role string, replicas int, actor string) (*RoleScale, error) {
+	role = strings.TrimSpace(role)
+	if role == "" {
+		return nil, fmt.Errorf("%w: role is required", orgchart.ErrInvalidPosition)
+	}
+	if replicas < 1 {
+		return nil, fmt.Errorf("%w: replicas must be at least 1", orgchart.ErrInvalidPosition)
+	}
+	chart, err := a.GetProjectOrgChart(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	pos := chart.GetPositionByRole(role)
+	if pos == nil {
+		return nil, fmt.Errorf("position not found for role: %s", role)
+	}
+	positionID, personaPath := pos.ID, pos.PersonaPath
+	assigned := append([]string(nil), pos.AgentIDs...)
+
+	byID := make(map[string]*models.Agent)
+	var spare []*models.Agent
+	for _, ag := range a.agentManager.ListAgentsByProject(projectID) {
+		agentRole := ag.Role
+		if agentRole == "" {
+			agentRole = roleFromPersonaName(ag.PersonaName)
+		}
+		if agentRole != role {
+			continue
+		}
+		byID[ag.ID] = ag
+		if !pos.HasAgent(ag.ID) {
+			spare = append(spare, ag)
+		}
+	}
+	var members []*models.Agent
+	for _, id := range assigned {
+		if ag, ok := byID[id]; ok {
+			members = append(members, ag)
+		}
+	}
+
+	if _, err := a.orgChartManager.UpdatePosition(projectID, positionID, orgchart.PositionUpdate{MaxInstances: &replicas}); err != nil {
+		return nil, err
+	}
+
+	for len(members) > replicas {
+		ag := members[len(members)-1]
+		members = members[:len(members)-1]
+		if _, err := a.agentManager.DrainAgent(ag.ID); err != nil {
+			return nil, err
+		}
+		_ = a.orgChartManager.UnassignAgent(projectID, positionID, ag.ID)
+		log.Printf("[Scale] Drained agent %s (%s) in project %s", ag.ID, role, projectID)
+	}
+
+	for _, ag := range spare {
+		if len(members) >= replicas {
+			break
+		}
+		if _, err := a.agentManager.ResumeAgent(ag.ID); err != nil {
+			return nil, err
+		}
+		if err := a.orgChartManager.AssignAgent(projectID, positionID, ag.ID); err != nil {
+			return nil, err
+		}
+		members = append(members, ag)
+		log.Printf("[Scale] Resumed agent %s (%s) in project %s", ag.ID, role, projectID)
+	}
+
+	var capabilities []string
+	if len(members) > 0 {
+		capabilities = members[0].Capabilities
+	}
+	for n := len(byID) + 1; len(members) < replicas; n++ {
+		ag, err := a.CreateAgent(ctx, formatAgentName(role, fmt.Sprintf("Replica %d", n)), personaPath, projectID, role)
+		if err != nil {
+			return nil, err
+		}
+		if len(capabilities) > 0 {
+			_ = a.agentManager.UpdateAgentCapabilities(ag.ID, capabilities)
+		}
+		if err := a.orgChartManager.AssignAgent(projectID, positionID, ag.ID); err != nil {
+			return nil, err
+		}
+		members = append(members, ag)
+		log.Printf("[Scale] Created agent %s (%s) in project %s", ag.ID, role, projectID)
+	}
+
+	if err := a.saveOrgChartLayout(projectID, actor); err != nil {
+		return nil, err
+	}
+	if a.taskExecutor != nil {
+		a.taskExecutor.WakeProject(projectID)
+	}
+	return &RoleScale{ProjectID: projectID, Role: role, Replicas: replicas, Agents: members}, nil
+}
@@ -0,0 +1,86 @@
+package loom
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/orgchart"
+)
+
+func TestLoom_ScaleRole(t *testing.T) {
+	l, tmpDir := testLoom(t)
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+
+	project, err := l.CreateProject("scale-project", ".", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreateProject() error = %v", err)
+	}
+	position := func() []string {
+		chart, err := l.GetProjectOrgChart(ctx, project.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos := chart.GetPositionByRole("qa-engineer")
+		if pos == nil {
+			t.Fatal("qa-engineer position missing")
+		}
+		if pos.MaxInstances != len(pos.AgentIDs) {
+			t.Errorf("max_instances = %d, want %d", pos.MaxInstances, len(pos.AgentIDs))
+		}
+		return pos.AgentIDs
+	}
+
+	scale, err := l.ScaleRole(ctx, project.ID, "qa-engineer", 3, "test")
+	if err != nil {
+		t.Fatalf("ScaleRole(3) error = %v", err)
+	}
+	if len(scale.Agents) != 3 || len(position()) != 3 {
+		t.Fatalf("scaled to %d agents, position has %d; want 3", len(scale.Agents), len(position()))
+	}
+	for _, ag := range scale.Agents {
+		if ag.Role != "qa-engineer" || ag.Hold != "" {
+			t.Errorf("agent %s: role %q hold %q", ag.ID, ag.Role, ag.Hold)
+		}
+	}
+
+	scale, err = l.ScaleRole(ctx, project.ID, "qa-engineer", 1, "test")
+	if err != nil {
+		t.Fatalf("ScaleRole(1) error = %v", err)
+	}
+	if len(scale.Agents) != 1 || len(position()) != 1 {
+		t.Fatalf("scaled down to %d agents, want 1", len(scale.Agents))
+	}
+	held := 0
+	for _, ag := range l.agentManager.ListAgentsByProject(project.ID) {
+		if ag.Role == "qa-engineer" && ag.Hold != "" {
+			held++
+		}
+	}
+	if held != 2 {
+		t.Errorf("held qa agents = %d, want 2", held)
+	}
+
+	// Scaling back up reuses the drained agents before creating any.
+	if _, err := l.ScaleRole(ctx, project.ID, "qa-engineer", 2, "test"); err != nil {
+		t.Fatalf("ScaleRole(2) error = %v", err)
+	}
+	total := 0
+	for _, ag := range l.agentManager.ListAgentsByProject(project.ID) {
+		if ag.Role == "qa-engineer" {
+			total++
+		}
+	}
+	if total != 3 || len(position()) != 2 {
+		t.Errorf("qa agents = %d (want 3), on position = %d (want 2)", total, len(position()))
+	}
+
+	if _, err := l.ScaleRole(ctx, project.ID, "qa-engineer", 0, "test"); !errors.Is(err, orgchart.ErrInvalidPosition) {
+		t.Errorf("ScaleRole(0) error = %v, want ErrInvalidPosition", err)
+	}
+	if _, err := l.ScaleRole(ctx, project.ID, "no-such-role", 2, "test"); err == nil {
+		t.Error("ScaleRole() on a missing role should fail")
+	}
+}
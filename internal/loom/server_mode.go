@@ -0,0 +1,91 @@
+package loom
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Server modes. In maintenance the API rejects every mutating request
+// and the task executor stops claiming beads, for upgrades, backup
+// windows or incident response.
+const (
+	ModeNormal      = "normal"
+	ModeMaintenance = "maintenance"
+)
+
+// serverModeKey is the config_kv key holding the server mode, so
+// maintenance outlasts the restart of an upgrade.
+const serverModeKey = "server:mode"
+
+// ServerMode is the mode the server is in, and who put it there.
+type ServerMode struct {
+	Mode   string    `json:"mode"`
+	Reason string    `json:"reason,omitempty"`
+	SetBy  string    `json:"set_by,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+}
+
+// Maintenance reports whether the server is in maintenance mode.
+func (m ServerMode) Maintenance() bool {
+	return m.Mode == ModeMaintenance
+}
+
+// ServerMode returns the server's mode, loading it from the database the
+// first time.
+func (a *Loom) ServerMode() ServerMode {
+	a.serverModeMu.Lock()
+	defer a.serverModeMu.Unlock()
+	if a.serverMode != nil {
+		return *a.serverMode
+	}
+	mode := ServerMode{Mode: ModeNormal}
+	if a.database != nil {
+		if raw, ok, err := a.database.GetConfigValue(serverModeKey); err == nil && ok {
+			var stored ServerMode
+			if err := json.Unmarshal([]byte(raw), &stored); err == nil && validServerMode(stored.Mode) {
+				mode = stored
+			}
+		}
+	}
+	a.serverMode = &mode
+	return mode
+}
+
+// SetServerMode switches the server to mode, pausing or resuming the task
+// executor and the remote agents' work to match. The mode is saved, so it outlasts a restart.
+func (a *Loom) SetServerMode(mode, reason, actor string) (ServerMode, error) {
+	if !validServerMode(mode) {
+		return ServerMode{}, fmt.Errorf("invalid mode %q: must be %q or %q", mode, ModeNormal, ModeMaintenance)
+	}
+	m := ServerMode{Mode: mode, SetBy: actor, Since: time.Now().UTC()}
+	if mode == ModeMaintenance {
+		m.Reason = reason
+	}
+
+	a.serverModeMu.Lock()
+	defer a.serverModeMu.Unlock()
+	if a.database != nil {
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return ServerMode{}, err
+		}
+		if err := a.database.SetConfigValue(serverModeKey, string(raw)); err != nil {
+			return ServerMode{}, err
+		}
+	}
+	a.serverMode = &m
+	if a.taskExecutor != nil {
+		a.taskExecutor.SetPaused(m.Maintenance())
+	}
+	if a.remoteAgents != nil {
+		a.remoteAgents.SetPaused(m.Maintenance())
+	}
+	log.Printf("[Loom] Server mode set to %s by %s", m.Mode, actor)
+	return m, nil
+}
+
+func validServerMode(mode string) bool {
+	return mode == ModeNormal || mode == ModeMaintenance
+}
@@ -0,0 +1,146 @@
+package loom
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/internal/notifications"
+	"github.com/jordanhubbard/loom/internal/workflow"
+)
+
+// Decision context keys linking a decision to the workflow approval gate it
+// decides.
+const (
+	approvalExecKey      = "workflow_exec_id"
+	approvalNodeKey      = "workflow_node"
+	approvalApproversKey = "approvers"
+)
+
+// ErrNotApprover is returned when someone who is not one of its approvers
+// tries to decide a workflow approval gate.
+var ErrNotApprover = errors.New("not an approver")
+
+// workflowApprovalGates asks people to decide human approval gates of
+// workflows, through decision beads.
+type workflowApprovalGates struct {
+	loom *Loom
+}
+
+// RequestApproval opens a decision on the workflow's bead and tells the
+// approvers about it. The decision is announced on the event bus, so it
+// also reaches webhooks and the Slack and OpenClaw bridges.
+func (g *workflowApprovalGates) RequestApproval(exec *workflow.WorkflowExecution, node *workflow.WorkflowNode, approvers []string) (string, error) {
+	a := g.loom
+	bead, err := a.beadsManager.GetBead(exec.BeadID)
+	if err != nil {
+		return "", err
+	}
+
+	question := fmt.Sprintf("Approval required at %q of the workflow for bead %s (%s).", node.NodeKey, bead.ID, bead.Title)
+	if node.Instructions != "" {
+		question += "\n\n" + strings.TrimSpace(node.Instructions)
+	}
+	question += "\n\nChoose: approve | deny"
+	d, err := a.decisionManager.CreateDecision(question, bead.ID, "system", []string{"approve", "deny"}, "", bead.Priority, bead.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if d.Context == nil {
+		d.Context = make(map[string]string)
+	}
+	d.Context[approvalExecKey] = exec.ID
+	d.Context[approvalNodeKey] = node.NodeKey
+	d.Context[approvalApproversKey] = strings.Join(approvers, ",")
+
+	if a.eventBus != nil {
+		_ = a.eventBus.Publish(&eventbus.Event{
+			Type:      eventbus.EventTypeDecisionCreated,
+			Source:    "workflow-approval",
+			ProjectID: bead.ProjectID,
+			Data: map[string]interface{}{
+				"decision_id": d.ID,
+				"bead_id":     bead.ID,
+				"node_key":    node.NodeKey,
+				"approvers":   approvers,
+				"question":    question,
+				"priority":    bead.Priority,
+			},
+		})
+	}
+	g.notify(d.ID, bead.Title, node.NodeKey, approvers)
+	return d.ID, nil
+}
+
+// notify sends each approver with a Loom account an in-app notification.
+// A wildcard approver list notifies nobody directly.
+func (g *workflowApprovalGates) notify(decisionID, title, nodeKey string, approvers []string) {
+	a := g.loom
+	if a.notificationManager == nil || a.database == nil {
+		return
+	}
+	for _, name := range approvers {
+		if name == "*" {
+			return
+		}
+	}
+	users, err := a.database.ListUsers()
+	if err != nil {
+		log.Printf("[Workflow] Warning: cannot notify approvers of %s: %v", decisionID, err)
+		return
+	}
+	for _, u := range users {
+		if !workflow.MayApprove(approvers, u.Username) {
+			continue
+		}
+		err := a.notificationManager.NotifyUser(&notifications.Notification{
+			UserID:    u.ID,
+			EventType: "decision.created",
+			Title:     "Approval requested",
+			Message:   fmt.Sprintf("%s is waiting for your approval at %s", title, nodeKey),
+			Link:      fmt.Sprintf("/decisions/%s", decisionID),
+			Priority:  notifications.PriorityHigh,
+		})
+		if err != nil {
+			log.Printf("[Workflow] Warning: failed to notify %s of %s: %v", u.Username, decisionID, err)
+		}
+	}
+}
+
+// checkApprover refuses a decider who may not decide a workflow approval
+// gate. Gates are decided by people, named by username, never by agents.
+func (a *Loom) checkApprover(decisionID, deciderID string) error {
+	d, err := a.decisionManager.GetDecision(decisionID)
+	if err != nil || d.Context[approvalExecKey] == "" {
+		return nil
+	}
+	if !strings.HasPrefix(deciderID, "user-") {
+		return fmt.Errorf("%w: workflow approval gates are decided by users, not %s", ErrNotApprover, deciderID)
+	}
+	approvers := strings.Split(d.Context[approvalApproversKey], ",")
+	if !workflow.MayApprove(approvers, strings.TrimPrefix(deciderID, "user-")) {
+		return fmt.Errorf("%w: %s may not decide this gate (approvers: %s)", ErrNotApprover, deciderID, d.Context[approvalApproversKey])
+	}
+	return nil
+}
+
+// applyWorkflowApproval advances the workflow waiting on a decided approval
+// gate: along its approved edge if the decision approves, its rejected edge
+// otherwise.
+func (a *Loom) applyWorkflowApproval(decisionID string) {
+	d, err := a.decisionManager.GetDecision(decisionID)
+	if err != nil || d.Context[approvalExecKey] == "" || a.workflowEngine == nil {
+		return
+	}
+	approved := false
+	switch strings.ToLower(strings.TrimSpace(d.Decision)) {
+	case "approve", "approved", "yes", "lgtm":
+		approved = true
+	}
+	approver := strings.TrimPrefix(d.DeciderID, "user-")
+	if err := a.workflowEngine.ResolveApproval(d.Context[approvalExecKey], d.Context[approvalNodeKey], approved, approver, d.Rationale); err != nil {
+		log.Printf("[Workflow] Warning: decision %s did not advance its workflow: %v", decisionID, err)
+	}
+}
@@ -0,0 +1,46 @@
+package loom
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/workflow"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestWorkflowApprovalGate_OnlyApproversDecide(t *testing.T) {
+	a, tmp := newTestLoom(t)
+	defer os.RemoveAll(tmp)
+
+	bead, err := a.GetBeadsManager().CreateBead("Ship it", "", models.BeadPriorityP2, "task", "loom")
+	if err != nil {
+		t.Fatalf("failed to create bead: %v", err)
+	}
+	gates := &workflowApprovalGates{loom: a}
+	exec := &workflow.WorkflowExecution{ID: "wfex-1", BeadID: bead.ID}
+	node := &workflow.WorkflowNode{NodeKey: "release", NodeType: workflow.NodeTypeApproval, Instructions: "Check the changelog."}
+
+	decisionID, err := gates.RequestApproval(exec, node, []string{"alice"})
+	if err != nil {
+		t.Fatalf("failed to request approval: %v", err)
+	}
+	d, err := a.GetDecisionManager().GetDecision(decisionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Parent != bead.ID || !strings.Contains(d.Question, "Check the changelog.") || d.Context[approvalExecKey] != "wfex-1" {
+		t.Errorf("decision = %+v", d)
+	}
+
+	if err := a.MakeDecision(decisionID, "user-bob", "approve", "ok"); !errors.Is(err, ErrNotApprover) {
+		t.Errorf("bob deciding: err = %v, want ErrNotApprover", err)
+	}
+	if err := a.MakeDecision(decisionID, "user-alice", "approve", "ok"); err != nil {
+		t.Fatalf("alice deciding: %v", err)
+	}
+	if d.DeciderID != "user-alice" || d.Status != models.BeadStatusClosed {
+		t.Errorf("decided by %q, status %s", d.DeciderID, d.Status)
+	}
+}
@@ -0,0 +1,69 @@
+package loom
+
+import (
+	"fmt"
+
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/workflow"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// BranchOutcomeKey is the bead context key a branch bead is closed with to
+// report that its branch failed. A branch bead closed without it succeeded.
+const BranchOutcomeKey = "branch_outcome"
+
+// workflowBranchBeads runs the branches of parallel workflow nodes as child
+// beads of the bead the workflow belongs to.
+type workflowBranchBeads struct {
+	beads *beads.Manager
+}
+
+func (w *workflowBranchBeads) CreateBranchBead(exec *workflow.WorkflowExecution, node *workflow.WorkflowNode) (string, error) {
+	parent, err := w.beads.GetBead(exec.BeadID)
+	if err != nil {
+		return "", err
+	}
+
+	description := node.Instructions
+	if description != "" {
+		description += "\n\n"
+	}
+	description += fmt.Sprintf("Branch %q of the workflow for %s: %s\n\n%s", node.NodeKey, parent.ID, parent.Title, parent.Description)
+	child, err := w.beads.CreateBead(fmt.Sprintf("%s [%s]", parent.Title, node.NodeKey), description, parent.Priority, "task", parent.ProjectID)
+	if err != nil {
+		return "", err
+	}
+
+	persona := node.PersonaHint
+	if persona == "" {
+		persona = node.RoleRequired
+	}
+	updates := map[string]interface{}{
+		"parent": parent.ID,
+		"tags":   []string{"workflow-branch"},
+		"context": map[string]string{
+			"workflow_parent_exec": exec.ID,
+			"workflow_branch":      node.NodeKey,
+			"requires_persona":     persona,
+		},
+	}
+	if err := w.beads.UpdateBead(child.ID, updates); err != nil {
+		return "", err
+	}
+	return child.ID, nil
+}
+
+func (w *workflowBranchBeads) BranchOutcome(beadID string) workflow.BranchStatus {
+	bead, err := w.beads.GetBead(beadID)
+	if err != nil {
+		// Deleted: the branch will never finish.
+		return workflow.BranchStatusFailed
+	}
+	if bead.Status != models.BeadStatusClosed {
+		return workflow.BranchStatusRunning
+	}
+	if bead.Context[BranchOutcomeKey] == "failure" {
+		return workflow.BranchStatusFailed
+	}
+	return workflow.BranchStatusSucceeded
+}
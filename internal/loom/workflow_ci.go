@@ -0,0 +1,95 @@
+package loom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/ci"
+	"github.com/jordanhubbard/loom/internal/workflow"
+)
+
+// workflowCIChecks reads the CI of workflow beads' branches for the ci
+// nodes waiting on them.
+type workflowCIChecks struct {
+	loom *Loom
+}
+
+func (c *workflowCIChecks) CIStatus(exec *workflow.WorkflowExecution, required []string) (*workflow.CIResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	s, err := c.loom.ciStatus(ctx, exec.BeadID, required)
+	if err != nil {
+		return nil, err
+	}
+	return &workflow.CIResult{
+		State:   workflow.CIState(s.State),
+		Branch:  s.Branch,
+		Summary: s.Summary,
+		URL:     s.URL,
+	}, nil
+}
+
+// BeadCIStatus reports the CI checks on the branch a bead's agent pushed.
+// While the bead's workflow waits at a ci node, only the checks the node
+// requires decide the state.
+func (a *Loom) BeadCIStatus(ctx context.Context, beadID string) (*ci.Status, error) {
+	var required []string
+	if a.workflowEngine != nil {
+		exec, err := a.workflowEngine.GetDatabase().GetWorkflowExecutionByBeadID(beadID)
+		if err == nil && exec != nil && exec.Status == workflow.ExecutionStatusAwaitingCI {
+			if node, err := a.workflowEngine.GetCurrentNode(exec.ID); err == nil && node != nil {
+				required = workflow.RequiredChecks(node)
+			}
+		}
+	}
+	return a.ciStatus(ctx, beadID, required)
+}
+
+// ciStatus reads the checks on a bead's branch: the branch its pull request
+// was opened from, or the bead/<id> branch agents commit to.
+func (a *Loom) ciStatus(ctx context.Context, beadID string, required []string) (*ci.Status, error) {
+	bead, err := a.beadsManager.GetBead(beadID)
+	if err != nil {
+		return nil, err
+	}
+	branch := bead.Context["pr_branch"]
+	if branch == "" {
+		branch = "bead/" + bead.ID
+	}
+	provider, err := a.ciProvider(bead.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return ci.Get(ctx, provider, branch, required)
+}
+
+// ciProvider returns where a project's CI checks are read from: its Jenkins
+// job when its ci_provider setting is jenkins, its GitHub repository
+// otherwise.
+func (a *Loom) ciProvider(projectID string) (ci.Provider, error) {
+	proj, err := a.projectManager.GetProject(projectID)
+	if err != nil || proj == nil {
+		return nil, fmt.Errorf("project not found: %s", projectID)
+	}
+
+	if a.projectConfig.CIProvider(projectID) == "jenkins" {
+		jenkins := a.config.CI.Jenkins
+		job := a.projectConfig.JenkinsJob(projectID)
+		if jenkins.URL == "" || job == "" {
+			return nil, fmt.Errorf("project %s builds on Jenkins but ci.jenkins.url or its jenkins_job setting is not set", projectID)
+		}
+		token := jenkins.APIToken
+		if token == "" {
+			token = os.Getenv("JENKINS_API_TOKEN")
+		}
+		return ci.NewJenkins(jenkins.URL, job, jenkins.User, token), nil
+	}
+
+	workDir := proj.WorkDir
+	if workDir == "" {
+		workDir = fmt.Sprintf("data/projects/%s/main", projectID)
+	}
+	return ci.NewGitHub(workDir, proj.Context["github_token"]), nil
+}
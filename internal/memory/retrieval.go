@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Retrieval over project history. Closed-bead summaries, lessons and
+// excerpts of key project files are embedded into a document store; when a
+// bead is dispatched, the documents closest to the task are injected into
+// the agent's prompt, so agents stop relearning a project's conventions on
+// every bead.
+
+// DocumentKind classifies an indexed document by where it came from.
+type DocumentKind string
+
+const (
+	DocKindBead   DocumentKind = "bead"   // summary of a closed bead
+	DocKindLesson DocumentKind = "lesson" // a recorded lesson
+	DocKindFile   DocumentKind = "file"   // excerpt of a project file
+)
+
+const (
+	// DefaultTopK is how many documents are retrieved when the caller
+	// doesn't say.
+	DefaultTopK = 5
+	// fileChunkChars is the size excerpts of project files are cut to.
+	fileChunkChars = 1500
+	// maxQueryChars caps the text embedded as a query; task contexts can
+	// be long and embedding endpoints have input limits.
+	maxQueryChars = 4000
+)
+
+// Document is a piece of project history that can be retrieved.
+type Document struct {
+	ID        string       `json:"id"`
+	ProjectID string       `json:"project_id"`
+	Kind      DocumentKind `json:"kind"`
+	SourceID  string       `json:"source_id"` // bead ID, lesson ID or file path
+	Title     string       `json:"title"`
+	Content   string       `json:"content"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Score     float32      `json:"score,omitempty"` // similarity to the query, set by searches
+}
+
+// DocumentStore persists documents with their embeddings and searches them
+// by similarity. The concrete implementation lives in
+// internal/database/memory_documents.go.
+type DocumentStore interface {
+	UpsertDocument(ctx context.Context, doc *Document, embedding []float32) error
+	DeleteDocuments(ctx context.Context, projectID string, kind DocumentKind, sourceID string) error
+	SearchDocuments(ctx context.Context, projectID string, embedding []float32, topK int) ([]*Document, error)
+}
+
+// Retriever indexes project history and retrieves what is relevant to a task.
+type Retriever struct {
+	store    DocumentStore
+	embedder Embedder
+}
+
+// NewRetriever creates a Retriever backed by store. It returns nil without
+// a store, and uses the hash embedder when embedder is nil.
+func NewRetriever(store DocumentStore, embedder Embedder) *Retriever {
+	if store == nil {
+		return nil
+	}
+	if embedder == nil {
+		embedder = NewHashEmbedder()
+	}
+	return &Retriever{store: store, embedder: embedder}
+}
+
+// Index embeds and stores docs, replacing any stored under the same IDs.
+func (r *Retriever) Index(ctx context.Context, docs ...*Document) error {
+	if r == nil || len(docs) == 0 {
+		return nil
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Title + "\n" + doc.Content
+	}
+	embeddings, err := r.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed documents: %w", err)
+	}
+	if len(embeddings) != len(docs) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(docs), len(embeddings))
+	}
+	for i, doc := range docs {
+		if doc.UpdatedAt.IsZero() {
+			doc.UpdatedAt = time.Now().UTC()
+		}
+		if err := r.store.UpsertDocument(ctx, doc, embeddings[i]); err != nil {
+			return fmt.Errorf("store document %s: %w", doc.ID, err)
+		}
+	}
+	return nil
+}
+
+// IndexFile replaces the stored excerpts of a project file with excerpts
+// of content. Empty content just removes them.
+func (r *Retriever) IndexFile(ctx context.Context, projectID, path, content string) error {
+	if r == nil {
+		return nil
+	}
+	if err := r.store.DeleteDocuments(ctx, projectID, DocKindFile, path); err != nil {
+		return err
+	}
+	var docs []*Document
+	for i, chunk := range ChunkText(content, fileChunkChars) {
+		docs = append(docs, &Document{
+			ID:        fmt.Sprintf("file:%s:%s#%d", projectID, path, i),
+			ProjectID: projectID,
+			Kind:      DocKindFile,
+			SourceID:  path,
+			Title:     path,
+			Content:   chunk,
+		})
+	}
+	return r.Index(ctx, docs...)
+}
+
+// Retrieve returns up to topK of the project's documents most similar to
+// query, best first.
+func (r *Retriever) Retrieve(ctx context.Context, projectID, query string, topK int) ([]*Document, error) {
+	if r == nil || projectID == "" || strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+	if len(query) > maxQueryChars {
+		query = query[:maxQueryChars]
+	}
+	embeddings, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(embeddings) == 0 || len(embeddings[0]) == 0 {
+		return nil, nil
+	}
+	return r.store.SearchDocuments(ctx, projectID, embeddings[0], topK)
+}
+
+// LessonDocument returns the document a lesson is indexed as.
+func LessonDocument(l *models.Lesson) *Document {
+	return &Document{
+		ID:        "lesson:" + l.ID,
+		ProjectID: l.ProjectID,
+		Kind:      DocKindLesson,
+		SourceID:  l.ID,
+		Title:     l.Category + ": " + l.Title,
+		Content:   l.Detail,
+		UpdatedAt: l.CreatedAt,
+	}
+}
+
+// BeadDocument returns the document a closed bead is indexed as: what was
+// asked, how it was closed, and the summary of the agent's work on it, if
+// there is one.
+func BeadDocument(b *models.Bead, workSummary string) *Document {
+	var sb strings.Builder
+	if desc := strings.TrimSpace(b.Description); desc != "" {
+		sb.WriteString(desc)
+	}
+	if reason := strings.TrimSpace(b.Context["close_reason"]); reason != "" {
+		sb.WriteString("\n\nOutcome: " + reason)
+	}
+	if s := strings.TrimSpace(workSummary); s != "" {
+		sb.WriteString("\n\nWork done: " + s)
+	}
+	updated := b.UpdatedAt
+	if b.ClosedAt != nil {
+		updated = *b.ClosedAt
+	}
+	return &Document{
+		ID:        "bead:" + b.ID,
+		ProjectID: b.ProjectID,
+		Kind:      DocKindBead,
+		SourceID:  b.ID,
+		Title:     b.Title,
+		Content:   strings.TrimSpace(sb.String()),
+		UpdatedAt: updated,
+	}
+}
+
+// FormatDocuments renders retrieved documents as markdown for a system
+// prompt, stopping before maxChars (0 means no limit).
+func FormatDocuments(docs []*Document, maxChars int) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("The following is relevant history from earlier work on this project.\n")
+	sb.WriteString("Follow the conventions it shows and avoid repeating its mistakes:\n\n")
+	written := 0
+	for _, doc := range docs {
+		entry := fmt.Sprintf("### %s: %s\n%s\n\n", strings.ToUpper(string(doc.Kind)), doc.Title, strings.TrimSpace(doc.Content))
+		if maxChars > 0 && sb.Len()+len(entry) > maxChars {
+			break
+		}
+		sb.WriteString(entry)
+		written++
+	}
+	if written == 0 {
+		return ""
+	}
+	return sb.String()
+}
+
+// ChunkText splits text into pieces of at most size characters, breaking
+// at paragraph boundaries where it can.
+func ChunkText(text string, size int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		cur.Reset()
+	}
+	for _, para := range strings.Split(text, "\n\n") {
+		for len(para) > size {
+			flush()
+			chunks = append(chunks, strings.TrimSpace(para[:size]))
+			para = para[size:]
+		}
+		if cur.Len() > 0 && cur.Len()+len(para)+2 > size {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(para)
+	}
+	flush()
+	return chunks
+}
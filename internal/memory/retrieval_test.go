@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestChunkText(t *testing.T) {
+	if got := ChunkText("  ", 10); got != nil {
+		t.Errorf("ChunkText(blank) = %q, want nil", got)
+	}
+	got := ChunkText("aaaa\n\nbbbb\n\ncccccccccccccccc", 10)
+	want := []string{"aaaa\n\nbbbb", "cccccccccc", "cccccc"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ChunkText = %q, want %q", got, want)
+	}
+}
+
+func TestBeadDocument(t *testing.T) {
+	closed := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	b := &models.Bead{
+		ID: "b-1", ProjectID: "p1", Title: "Add retries", Description: "Retry uploads.",
+		Context: map[string]string{"close_reason": "done, merged"}, ClosedAt: &closed,
+	}
+	doc := BeadDocument(b, "Wrapped Put in backoff.")
+	if doc.ID != "bead:b-1" || doc.Kind != DocKindBead || !doc.UpdatedAt.Equal(closed) {
+		t.Errorf("doc = %+v", doc)
+	}
+	for _, want := range []string{"Retry uploads.", "Outcome: done, merged", "Work done: Wrapped Put in backoff."} {
+		if !strings.Contains(doc.Content, want) {
+			t.Errorf("content %q missing %q", doc.Content, want)
+		}
+	}
+}
+
+func TestFormatDocuments(t *testing.T) {
+	if FormatDocuments(nil, 0) != "" {
+		t.Error("no documents should format to nothing")
+	}
+	docs := []*Document{
+		{Kind: DocKindLesson, Title: "build", Content: "use make"},
+		{Kind: DocKindFile, Title: "README.md", Content: strings.Repeat("x", 500)},
+	}
+	out := FormatDocuments(docs, 300)
+	if !strings.Contains(out, "### LESSON: build") || strings.Contains(out, "README.md") {
+		t.Errorf("FormatDocuments = %q, want the lesson only", out)
+	}
+}
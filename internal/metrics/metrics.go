@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -36,6 +37,21 @@ type Metrics struct {
 	WorkflowDuration   *prometheus.HistogramVec
 	WorkflowErrors     *prometheus.CounterVec
 
+	// Dispatch and execution metrics
+	DispatchDecisions      *prometheus.CounterVec
+	TaskExecutorRuns       *prometheus.CounterVec
+	TaskExecutorIterations *prometheus.CounterVec
+	ActionDuration         *prometheus.HistogramVec
+
+	// Container metrics
+	ContainerHealthy  *prometheus.GaugeVec
+	ContainerRestarts *prometheus.CounterVec
+
+	// Budget metrics
+	BudgetTokensUsed  *prometheus.GaugeVec
+	BudgetCostUsed    *prometheus.GaugeVec
+	BudgetUtilization *prometheus.GaugeVec
+
 	// System metrics
 	DatabaseConnections prometheus.Gauge
 	CacheHits           prometheus.Counter
@@ -43,6 +59,7 @@ type Metrics struct {
 	EventsPublished     *prometheus.CounterVec
 	HTTPRequestsTotal   *prometheus.CounterVec
 	HTTPRequestDuration *prometheus.HistogramVec
+	APIRateLimited      *prometheus.CounterVec
 }
 
 var (
@@ -199,6 +216,76 @@ func NewMetrics() *Metrics {
 				[]string{"workflow_type", "error_type"},
 			),
 
+			// Dispatch and execution metrics
+			DispatchDecisions: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "loom_dispatch_decisions_total",
+					Help: "Total number of dispatcher passes by outcome",
+				},
+				[]string{"project_id", "decision"},
+			),
+			TaskExecutorRuns: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "loom_task_executor_runs_total",
+					Help: "Total number of beads run by the task executor, by terminal reason",
+				},
+				[]string{"project_id", "result"},
+			),
+			TaskExecutorIterations: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "loom_task_executor_iterations_total",
+					Help: "Total number of action loop iterations run by the task executor",
+				},
+				[]string{"project_id"},
+			),
+			ActionDuration: promauto.NewHistogramVec(
+				prometheus.HistogramOpts{
+					Name:    "loom_action_duration_seconds",
+					Help:    "Agent action execution duration in seconds",
+					Buckets: prometheus.ExponentialBuckets(0.01, 3, 10), // 10ms to 197s
+				},
+				[]string{"project_id", "action_type", "status"},
+			),
+
+			// Container metrics
+			ContainerHealthy: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "loom_container_healthy",
+					Help: "Project container health as last probed (1 for healthy, 0 for unhealthy)",
+				},
+				[]string{"project_id"},
+			),
+			ContainerRestarts: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "loom_container_restarts_total",
+					Help: "Total number of project container restarts by the health monitor",
+				},
+				[]string{"project_id"},
+			),
+
+			// Budget metrics
+			BudgetTokensUsed: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "loom_budget_tokens_used",
+					Help: "Tokens consumed in the current budget period",
+				},
+				[]string{"scope", "scope_id", "period"},
+			),
+			BudgetCostUsed: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "loom_budget_cost_used_usd",
+					Help: "Cost in USD consumed in the current budget period",
+				},
+				[]string{"scope", "scope_id", "period"},
+			),
+			BudgetUtilization: promauto.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Name: "loom_budget_utilization_ratio",
+					Help: "Fraction of the budget's tightest limit consumed in the current period",
+				},
+				[]string{"scope", "scope_id", "period"},
+			),
+
 			// System metrics
 			DatabaseConnections: promauto.NewGauge(
 				prometheus.GaugeOpts{
@@ -240,6 +327,13 @@ func NewMetrics() *Metrics {
 				},
 				[]string{"method", "path"},
 			),
+			APIRateLimited: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "loom_api_rate_limited_total",
+					Help: "Total number of API requests rejected by a rate limit",
+				},
+				[]string{"limit", "route_group"},
+			),
 		}
 	})
 
@@ -264,6 +358,51 @@ func (m *Metrics) RecordBeadTransition(projectID, fromStatus, toStatus string) {
 	m.BeadTransitions.WithLabelValues(projectID, fromStatus, toStatus).Inc()
 }
 
+// RecordDispatchDecision records the outcome of a dispatcher pass
+func (m *Metrics) RecordDispatchDecision(projectID, decision string) {
+	m.DispatchDecisions.WithLabelValues(projectID, decision).Inc()
+}
+
+// RecordTaskRun records a bead run by the task executor and the action
+// loop iterations it took
+func (m *Metrics) RecordTaskRun(projectID, result string, iterations int) {
+	m.TaskExecutorRuns.WithLabelValues(projectID, result).Inc()
+	if iterations > 0 {
+		m.TaskExecutorIterations.WithLabelValues(projectID).Add(float64(iterations))
+	}
+}
+
+// ObserveAction records the execution of an agent action
+func (m *Metrics) ObserveAction(projectID, actionType, status string, duration time.Duration) {
+	m.ActionDuration.WithLabelValues(projectID, actionType, status).Observe(duration.Seconds())
+}
+
+// RecordContainerProbe records a project container health probe and
+// whether it led to a restart
+func (m *Metrics) RecordContainerProbe(projectID string, healthy, restarted bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.ContainerHealthy.WithLabelValues(projectID).Set(value)
+	if restarted {
+		m.ContainerRestarts.WithLabelValues(projectID).Inc()
+	}
+}
+
+// RecordBudgetUsage records a budget's consumption in its current period
+func (m *Metrics) RecordBudgetUsage(scope, scopeID, period string, tokens int64, costUSD, utilization float64) {
+	m.BudgetTokensUsed.WithLabelValues(scope, scopeID, period).Set(float64(tokens))
+	m.BudgetCostUsed.WithLabelValues(scope, scopeID, period).Set(costUSD)
+	m.BudgetUtilization.WithLabelValues(scope, scopeID, period).Set(utilization)
+}
+
+// RecordRateLimited records an API request rejected by a rate limit:
+// "ip", "token" or "route_group"
+func (m *Metrics) RecordRateLimited(limit, routeGroup string) {
+	m.APIRateLimited.WithLabelValues(limit, routeGroup).Inc()
+}
+
 // RecordHTTPRequest records an HTTP request
 func (m *Metrics) RecordHTTPRequest(method, path, status string, duration float64) {
 	m.HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
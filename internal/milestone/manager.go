@@ -0,0 +1,418 @@
+// Package milestone groups beads of one or more projects under a named
+// milestone (a release, a sprint, an epic), tracks how many of them are
+// done, and can hold off closing the milestone until every bead in it is
+// closed. Beads join a milestone through their milestone_id.
+package milestone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/motivation"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+var (
+	// ErrNotFound is returned when a milestone ID does not exist.
+	ErrNotFound = errors.New("milestone not found")
+	// ErrBeadsOpen is returned when closing a milestone that requires its
+	// beads to be closed first.
+	ErrBeadsOpen = errors.New("milestone has beads that are not closed")
+	// ErrInvalidAssignment is returned when a bead cannot join a milestone.
+	ErrInvalidAssignment = errors.New("invalid milestone")
+)
+
+// BeadSource lists the beads of a milestone and detaches them when it is
+// deleted. *beads.Manager satisfies it.
+type BeadSource interface {
+	ListBeads(filters map[string]interface{}) ([]*models.Bead, error)
+	UpdateBead(id string, updates map[string]interface{}) error
+}
+
+// Milestone is a group of beads across one or more projects.
+type Milestone struct {
+	ID                 string                     `json:"id"`
+	Name               string                     `json:"name"`
+	Description        string                     `json:"description,omitempty"`
+	ProjectIDs         []string                   `json:"project_ids"`
+	Type               motivation.MilestoneType   `json:"type"`
+	Status             motivation.MilestoneStatus `json:"status"`
+	DueDate            *time.Time                 `json:"due_date,omitempty"`
+	CompletedAt        *time.Time                 `json:"completed_at,omitempty"`
+	CloseRequiresBeads bool                       `json:"close_requires_beads"`
+	CreatedBy          string                     `json:"created_by,omitempty"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	UpdatedAt          time.Time                  `json:"updated_at"`
+	Progress           Progress                   `json:"progress"`
+}
+
+// Progress counts a milestone's beads by status.
+type Progress struct {
+	Total           int     `json:"total"`
+	Open            int     `json:"open"`
+	InProgress      int     `json:"in_progress"`
+	Blocked         int     `json:"blocked"`
+	Closed          int     `json:"closed"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+// IsOpen reports whether beads can still join the milestone.
+func (m *Milestone) IsOpen() bool {
+	return m.Status != motivation.MilestoneStatusComplete && m.Status != motivation.MilestoneStatusCancelled
+}
+
+// CreateRequest is the body accepted when creating a milestone. DueDate is
+// RFC 3339 or YYYY-MM-DD.
+type CreateRequest struct {
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	ProjectIDs         []string `json:"project_ids"`
+	Type               string   `json:"type"`
+	DueDate            string   `json:"due_date"`
+	CloseRequiresBeads bool     `json:"close_requires_beads"`
+}
+
+// UpdateRequest is the body accepted when updating a milestone. Fields
+// left out are unchanged; an empty due_date clears it.
+type UpdateRequest struct {
+	Name               *string   `json:"name"`
+	Description        *string   `json:"description"`
+	ProjectIDs         *[]string `json:"project_ids"`
+	Type               *string   `json:"type"`
+	DueDate            *string   `json:"due_date"`
+	CloseRequiresBeads *bool     `json:"close_requires_beads"`
+}
+
+// Manager persists milestones and computes their progress.
+type Manager struct {
+	db    *database.Database
+	beads BeadSource
+	now   func() time.Time
+}
+
+// NewManager creates a milestone manager. Returns nil when db or beads is
+// nil.
+func NewManager(db *database.Database, beads BeadSource) *Manager {
+	if db == nil || beads == nil {
+		return nil
+	}
+	return &Manager{db: db, beads: beads, now: time.Now}
+}
+
+// Create adds a milestone.
+func (m *Manager) Create(req CreateRequest, createdBy string) (*Milestone, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	projectIDs := cleanProjectIDs(req.ProjectIDs)
+	if len(projectIDs) == 0 {
+		return nil, fmt.Errorf("at least one project is required")
+	}
+	kind, err := parseType(req.Type)
+	if err != nil {
+		return nil, err
+	}
+	due, err := parseDueDate(req.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now().UTC()
+	row := &database.Milestone{
+		ID:                 "ms-" + uuid.New().String()[:8],
+		ProjectIDs:         projectIDs,
+		Name:               name,
+		Description:        req.Description,
+		Type:               string(kind),
+		Status:             string(motivation.MilestoneStatusPlanned),
+		DueDate:            due,
+		CloseRequiresBeads: req.CloseRequiresBeads,
+		CreatedBy:          createdBy,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := m.db.CreateMilestone(row); err != nil {
+		return nil, err
+	}
+	return toMilestone(row), nil
+}
+
+// Get returns a milestone with its progress.
+func (m *Manager) Get(id string) (*Milestone, error) {
+	row, err := m.load(id)
+	if err != nil {
+		return nil, err
+	}
+	ms := toMilestone(row)
+	beads, err := m.Beads(id)
+	if err != nil {
+		return nil, err
+	}
+	ms.Progress = progressOf(beads)
+	return ms, nil
+}
+
+// List returns the milestones that include a project, or all milestones
+// for an empty projectID, with their progress.
+func (m *Manager) List(projectID string) ([]*Milestone, error) {
+	rows, err := m.db.ListMilestones(projectID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := m.beads.ListBeads(map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	byMilestone := make(map[string][]*models.Bead)
+	for _, b := range all {
+		if b.MilestoneID != "" {
+			byMilestone[b.MilestoneID] = append(byMilestone[b.MilestoneID], b)
+		}
+	}
+	out := make([]*Milestone, 0, len(rows))
+	for _, row := range rows {
+		ms := toMilestone(row)
+		ms.Progress = progressOf(byMilestone[row.ID])
+		out = append(out, ms)
+	}
+	return out, nil
+}
+
+// Beads returns the beads in a milestone.
+func (m *Manager) Beads(id string) ([]*models.Bead, error) {
+	return m.beads.ListBeads(map[string]interface{}{"milestone_id": id})
+}
+
+// Update changes a milestone's fields. Removing a project that still has
+// beads in the milestone is refused.
+func (m *Manager) Update(id string, req UpdateRequest) (*Milestone, error) {
+	row, err := m.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("name must not be empty")
+		}
+		row.Name = name
+	}
+	if req.Description != nil {
+		row.Description = *req.Description
+	}
+	if req.Type != nil {
+		kind, err := parseType(*req.Type)
+		if err != nil {
+			return nil, err
+		}
+		row.Type = string(kind)
+	}
+	if req.DueDate != nil {
+		if row.DueDate, err = parseDueDate(*req.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.CloseRequiresBeads != nil {
+		row.CloseRequiresBeads = *req.CloseRequiresBeads
+	}
+	if req.ProjectIDs != nil {
+		projectIDs := cleanProjectIDs(*req.ProjectIDs)
+		if len(projectIDs) == 0 {
+			return nil, fmt.Errorf("at least one project is required")
+		}
+		beads, err := m.Beads(id)
+		if err != nil {
+			return nil, err
+		}
+		kept := &database.Milestone{ProjectIDs: projectIDs}
+		for _, b := range beads {
+			if !kept.HasProject(b.ProjectID) {
+				return nil, fmt.Errorf("project %s still has beads in the milestone, e.g. %s", b.ProjectID, b.ID)
+			}
+		}
+		row.ProjectIDs = projectIDs
+	}
+	row.UpdatedAt = m.now().UTC()
+	if err := m.db.UpdateMilestone(row); err != nil {
+		return nil, err
+	}
+	return m.Get(id)
+}
+
+// Close marks a milestone complete. A milestone that requires its beads to
+// be closed first is refused with ErrBeadsOpen while any are not.
+func (m *Manager) Close(id string) (*Milestone, error) {
+	row, err := m.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if row.CloseRequiresBeads {
+		beads, err := m.Beads(id)
+		if err != nil {
+			return nil, err
+		}
+		if p := progressOf(beads); p.Closed < p.Total {
+			return nil, fmt.Errorf("%w: %d of %d", ErrBeadsOpen, p.Total-p.Closed, p.Total)
+		}
+	}
+	now := m.now().UTC()
+	row.Status = string(motivation.MilestoneStatusComplete)
+	row.CompletedAt = &now
+	row.UpdatedAt = now
+	if err := m.db.UpdateMilestone(row); err != nil {
+		return nil, err
+	}
+	return m.Get(id)
+}
+
+// Reopen marks a closed milestone as planned again.
+func (m *Manager) Reopen(id string) (*Milestone, error) {
+	row, err := m.load(id)
+	if err != nil {
+		return nil, err
+	}
+	row.Status = string(motivation.MilestoneStatusPlanned)
+	row.CompletedAt = nil
+	row.UpdatedAt = m.now().UTC()
+	if err := m.db.UpdateMilestone(row); err != nil {
+		return nil, err
+	}
+	return m.Get(id)
+}
+
+// Delete removes a milestone and takes its beads out of it.
+func (m *Manager) Delete(id string) error {
+	if _, err := m.load(id); err != nil {
+		return err
+	}
+	beads, err := m.Beads(id)
+	if err != nil {
+		return err
+	}
+	for _, b := range beads {
+		if err := m.beads.UpdateBead(b.ID, map[string]interface{}{"milestone_id": ""}); err != nil {
+			return fmt.Errorf("failed to detach bead %s: %w", b.ID, err)
+		}
+	}
+	return m.db.DeleteMilestone(id)
+}
+
+// CheckAssign returns an ErrInvalidAssignment error unless a bead of the
+// project can join the milestone: it must exist, be open, and include the
+// project.
+func (m *Manager) CheckAssign(id, projectID string) error {
+	row, err := m.db.GetMilestone(id)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return fmt.Errorf("%w: no milestone %s", ErrInvalidAssignment, id)
+	}
+	if !toMilestone(row).IsOpen() {
+		return fmt.Errorf("%w: milestone %s is %s", ErrInvalidAssignment, id, row.Status)
+	}
+	if !row.HasProject(projectID) {
+		return fmt.Errorf("%w: milestone %s does not include project %s", ErrInvalidAssignment, id, projectID)
+	}
+	return nil
+}
+
+func (m *Manager) load(id string) (*database.Milestone, error) {
+	row, err := m.db.GetMilestone(id)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return row, nil
+}
+
+func toMilestone(row *database.Milestone) *Milestone {
+	return &Milestone{
+		ID:                 row.ID,
+		Name:               row.Name,
+		Description:        row.Description,
+		ProjectIDs:         row.ProjectIDs,
+		Type:               motivation.MilestoneType(row.Type),
+		Status:             motivation.MilestoneStatus(row.Status),
+		DueDate:            row.DueDate,
+		CompletedAt:        row.CompletedAt,
+		CloseRequiresBeads: row.CloseRequiresBeads,
+		CreatedBy:          row.CreatedBy,
+		CreatedAt:          row.CreatedAt,
+		UpdatedAt:          row.UpdatedAt,
+	}
+}
+
+func progressOf(beads []*models.Bead) Progress {
+	var p Progress
+	for _, b := range beads {
+		if b == nil || b.DeletedAt != nil {
+			continue
+		}
+		p.Total++
+		switch b.Status {
+		case models.BeadStatusClosed:
+			p.Closed++
+		case models.BeadStatusInProgress:
+			p.InProgress++
+		case models.BeadStatusBlocked:
+			p.Blocked++
+		default:
+			p.Open++
+		}
+	}
+	if p.Total > 0 {
+		p.PercentComplete = float64(p.Closed) / float64(p.Total) * 100
+	}
+	return p
+}
+
+// cleanProjectIDs trims project IDs and drops empty and repeated ones.
+func cleanProjectIDs(ids []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func parseType(s string) (motivation.MilestoneType, error) {
+	switch t := motivation.MilestoneType(strings.TrimSpace(s)); t {
+	case "":
+		return motivation.MilestoneTypeCustom, nil
+	case motivation.MilestoneTypeRelease, motivation.MilestoneTypeSprintEnd, motivation.MilestoneTypeQuarterReview,
+		motivation.MilestoneTypeAnnualReview, motivation.MilestoneTypeCustom:
+		return t, nil
+	default:
+		return "", fmt.Errorf("invalid type %q: must be release, sprint_end, quarterly_review, annual_review, or custom", s)
+	}
+}
+
+// parseDueDate accepts RFC 3339 or YYYY-MM-DD (midnight UTC); empty means
+// no due date.
+func parseDueDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if t, err = time.Parse("2006-01-02", s); err != nil {
+			return nil, fmt.Errorf("invalid due_date %q: use YYYY-MM-DD or RFC 3339", s)
+		}
+	}
+	t = t.UTC()
+	return &t, nil
+}
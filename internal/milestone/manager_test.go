@@ -0,0 +1,176 @@
+package milestone
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/motivation"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type fakeBeads struct {
+	beads []*models.Bead
+}
+
+func (f *fakeBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	var out []*models.Bead
+	for _, b := range f.beads {
+		if id, ok := filters["milestone_id"].(string); ok && b.MilestoneID != id {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func (f *fakeBeads) UpdateBead(id string, updates map[string]interface{}) error {
+	for _, b := range f.beads {
+		if b.ID == id {
+			if ms, ok := updates["milestone_id"].(string); ok {
+				b.MilestoneID = ms
+			}
+			return nil
+		}
+	}
+	return errors.New("bead not found")
+}
+
+func newTestManager(t *testing.T, beads *fakeBeads) *Manager {
+	t.Helper()
+	db := dbtest.New(t)
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if err := db.UpsertProject(&models.Project{ID: id, Name: id, GitRepo: ".", Branch: "main", BeadsPath: ".beads"}); err != nil {
+			t.Fatalf("UpsertProject failed: %v", err)
+		}
+	}
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	m := NewManager(db, beads)
+	m.now = func() time.Time { return clock }
+	return m
+}
+
+func TestManager_CreateValidates(t *testing.T) {
+	m := newTestManager(t, &fakeBeads{})
+
+	bad := []CreateRequest{
+		{ProjectIDs: []string{"p1"}},
+		{Name: "v2"},
+		{Name: "v2", ProjectIDs: []string{" "}},
+		{Name: "v2", ProjectIDs: []string{"p1"}, Type: "someday"},
+		{Name: "v2", ProjectIDs: []string{"p1"}, DueDate: "next week"},
+	}
+	for _, req := range bad {
+		if _, err := m.Create(req, "admin"); err == nil {
+			t.Errorf("Create(%+v): expected error", req)
+		}
+	}
+
+	ms, err := m.Create(CreateRequest{Name: " v2 ", ProjectIDs: []string{"p1", "p2", "p1"}, DueDate: "2026-12-01"}, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if ms.Name != "v2" || len(ms.ProjectIDs) != 2 || ms.Type != motivation.MilestoneTypeCustom ||
+		ms.Status != motivation.MilestoneStatusPlanned || ms.DueDate == nil || ms.DueDate.Day() != 1 {
+		t.Fatalf("unexpected milestone: %+v", ms)
+	}
+	if _, err := m.Get("ms-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_ProgressAndClose(t *testing.T) {
+	beads := &fakeBeads{}
+	m := newTestManager(t, beads)
+	ms, err := m.Create(CreateRequest{Name: "v2", ProjectIDs: []string{"p1", "p2"}, CloseRequiresBeads: true}, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	beads.beads = []*models.Bead{
+		{ID: "b1", ProjectID: "p1", MilestoneID: ms.ID, Status: models.BeadStatusClosed},
+		{ID: "b2", ProjectID: "p2", MilestoneID: ms.ID, Status: models.BeadStatusInProgress},
+		{ID: "b3", ProjectID: "p1", MilestoneID: ms.ID, Status: models.BeadStatusOpen},
+		{ID: "b4", ProjectID: "p1", Status: models.BeadStatusOpen},
+	}
+
+	got, err := m.Get(ms.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	want := Progress{Total: 3, Open: 1, InProgress: 1, Closed: 1, PercentComplete: float64(1) / 3 * 100}
+	if got.Progress != want {
+		t.Errorf("progress = %+v, want %+v", got.Progress, want)
+	}
+	list, err := m.List("p2")
+	if err != nil || len(list) != 1 || list[0].Progress.Total != 3 {
+		t.Errorf("List(p2) = %+v, %v", list, err)
+	}
+	if list, _ := m.List("p3"); len(list) != 0 {
+		t.Errorf("List(p3) = %+v, want none", list)
+	}
+
+	if _, err := m.Close(ms.ID); !errors.Is(err, ErrBeadsOpen) {
+		t.Fatalf("Close with open beads: got %v, want ErrBeadsOpen", err)
+	}
+	beads.beads[1].Status = models.BeadStatusClosed
+	beads.beads[2].Status = models.BeadStatusClosed
+	closed, err := m.Close(ms.ID)
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if closed.Status != motivation.MilestoneStatusComplete || closed.CompletedAt == nil || closed.Progress.PercentComplete != 100 {
+		t.Errorf("closed milestone = %+v", closed)
+	}
+	if err := m.CheckAssign(ms.ID, "p1"); !errors.Is(err, ErrInvalidAssignment) {
+		t.Errorf("CheckAssign on closed milestone: got %v", err)
+	}
+
+	reopened, err := m.Reopen(ms.ID)
+	if err != nil || reopened.Status != motivation.MilestoneStatusPlanned || reopened.CompletedAt != nil {
+		t.Fatalf("Reopen = %+v, %v", reopened, err)
+	}
+}
+
+func TestManager_AssignUpdateAndDelete(t *testing.T) {
+	beads := &fakeBeads{}
+	m := newTestManager(t, beads)
+	ms, err := m.Create(CreateRequest{Name: "Auth", ProjectIDs: []string{"p1", "p2"}}, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if err := m.CheckAssign(ms.ID, "p2"); err != nil {
+		t.Errorf("CheckAssign(p2) failed: %v", err)
+	}
+	for _, tc := range []struct{ id, project string }{{ms.ID, "p3"}, {"ms-missing", "p1"}} {
+		if err := m.CheckAssign(tc.id, tc.project); !errors.Is(err, ErrInvalidAssignment) {
+			t.Errorf("CheckAssign(%s, %s): got %v, want ErrInvalidAssignment", tc.id, tc.project, err)
+		}
+	}
+
+	beads.beads = []*models.Bead{{ID: "b1", ProjectID: "p2", MilestoneID: ms.ID}}
+	onlyP1 := []string{"p1"}
+	if _, err := m.Update(ms.ID, UpdateRequest{ProjectIDs: &onlyP1}); err == nil {
+		t.Error("Update dropping a project with beads: expected error")
+	}
+	withP3 := []string{"p2", "p3"}
+	due := ""
+	updated, err := m.Update(ms.ID, UpdateRequest{ProjectIDs: &withP3, DueDate: &due})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(updated.ProjectIDs) != 2 || updated.ProjectIDs[1] != "p3" || updated.DueDate != nil {
+		t.Errorf("updated milestone = %+v", updated)
+	}
+
+	if err := m.Delete(ms.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if beads.beads[0].MilestoneID != "" {
+		t.Errorf("bead still in deleted milestone %q", beads.beads[0].MilestoneID)
+	}
+	if err := m.Delete(ms.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete twice: got %v, want ErrNotFound", err)
+	}
+}
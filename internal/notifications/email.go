@@ -0,0 +1,325 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// Email statuses of a notification
+const (
+	EmailQueued  = "queued"
+	EmailSent    = "sent"
+	EmailSkipped = "skipped" // the user has no email address or turned email off
+)
+
+// Mailer sends a plain-text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPMailer sends email through an SMTP server, upgrading the connection
+// to TLS when the server offers STARTTLS.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer returns a mailer for the server at host:port, 587 when port
+// is 0. An empty username sends without authenticating.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	if port == 0 {
+		port = 587
+	}
+	m := &SMTPMailer{addr: net.JoinHostPort(host, strconv.Itoa(port)), from: from}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+func (s *SMTPMailer) Send(to, subject, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{to}, []byte(msg.String()))
+}
+
+// emailChannel is the configured email delivery: where to send, the base
+// URL links are made absolute with, and a kick that sends right away.
+type emailChannel struct {
+	mailer  Mailer
+	baseURL string
+	kick    chan struct{}
+}
+
+// SetMailer turns on the email channel. Notifications of users who enabled
+// email are sent through mailer, one by one or in hourly or daily digests
+// depending on their digest mode. Relative links are prefixed with baseURL.
+func (m *Manager) SetMailer(mailer Mailer, baseURL string) {
+	ch := &emailChannel{mailer: mailer, baseURL: strings.TrimRight(baseURL, "/"), kick: make(chan struct{}, 1)}
+	m.emailMu.Lock()
+	m.email = ch
+	m.emailMu.Unlock()
+	go m.runEmail(ch)
+}
+
+func (m *Manager) emailChannel() *emailChannel {
+	m.emailMu.RLock()
+	defer m.emailMu.RUnlock()
+	return m.email
+}
+
+// runEmail sends queued emails every minute, and as soon as a realtime
+// notification is queued.
+func (m *Manager) runEmail(ch *emailChannel) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ch.kick:
+		}
+		if _, err := m.SendQueuedEmails(time.Now()); err != nil {
+			log.Printf("Failed to send notification emails: %v", err)
+		}
+	}
+}
+
+// queueEmail marks a notification to be emailed when the email channel is
+// on and the user wants its event type emailed.
+func (m *Manager) queueEmail(notification *Notification, prefs *NotificationPreferences) bool {
+	if m.emailChannel() == nil || !prefs.EnableEmail || !m.isEventSubscribed(notification.EventType, prefs.EmailEvents) {
+		return false
+	}
+	notification.EmailStatus = EmailQueued
+	return true
+}
+
+// kickEmail sends a queued notification right away for users who get
+// their email as it happens.
+func (m *Manager) kickEmail(prefs *NotificationPreferences) {
+	ch := m.emailChannel()
+	if ch == nil || digestPeriod(prefs.DigestMode) > 0 {
+		return
+	}
+	select {
+	case ch.kick <- struct{}{}:
+	default:
+	}
+}
+
+// digestPeriod is how long a user's emails are collected before they are
+// sent together.
+func digestPeriod(mode string) time.Duration {
+	switch mode {
+	case DigestHourly:
+		return time.Hour
+	case DigestDaily:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// SendQueuedEmails emails each user the notifications queued for them: a
+// single notification on its own, several as a digest. A user's digest goes
+// out once the oldest notification in it is older than their digest
+// period. Emails that fail to send stay queued for the next run. It
+// returns the number of emails sent.
+func (m *Manager) SendQueuedEmails(now time.Time) (int, error) {
+	ch := m.emailChannel()
+	if ch == nil {
+		return 0, nil
+	}
+	queued, err := m.db.ListQueuedNotificationEmails()
+	if err != nil || len(queued) == 0 {
+		return 0, err
+	}
+	users, err := m.db.ListUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	addresses := make(map[string]string, len(users))
+	names := make(map[string]string, len(users))
+	for _, u := range users {
+		addresses[u.ID] = u.Email
+		names[u.ID] = u.Username
+	}
+
+	sent := 0
+	for len(queued) > 0 {
+		n := 1
+		for n < len(queued) && queued[n].UserID == queued[0].UserID {
+			n++
+		}
+		group := queued[:n]
+		queued = queued[n:]
+
+		userID := group[0].UserID
+		prefs, err := m.GetPreferences(userID)
+		if err != nil {
+			log.Printf("Failed to get preferences for user %s: %v", userID, err)
+			continue
+		}
+		ids := make([]string, len(group))
+		for i, dbNotif := range group {
+			ids[i] = dbNotif.ID
+		}
+		if !prefs.EnableEmail || addresses[userID] == "" {
+			if err := m.db.SetNotificationEmailStatus(ids, EmailSkipped); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if now.Sub(group[0].CreatedAt) < digestPeriod(prefs.DigestMode) {
+			continue
+		}
+
+		subject, body, err := renderEmail(names[userID], ch.baseURL, group)
+		if err != nil {
+			return sent, err
+		}
+		if err := ch.mailer.Send(addresses[userID], subject, body); err != nil {
+			log.Printf("Failed to email notifications to user %s: %v", userID, err)
+			continue
+		}
+		if err := m.db.SetNotificationEmailStatus(ids, EmailSent); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// EmailBodyKey is the notification metadata key of a longer text that is
+// emailed in place of the message when the notification is sent on its own.
+const EmailBodyKey = "email_body"
+
+// emailItem is one notification as the email templates see it.
+type emailItem struct {
+	Title    string
+	Message  string
+	Body     string
+	Priority string
+	Link     string
+	Time     time.Time
+}
+
+// Email templates. Escalations, SLA breaches, decision requests and
+// reports have their own; other notifications use the default, and several
+// at once go out as a digest.
+var emailTemplates = template.Must(template.New("email").Parse(`
+{{- define "escalation.subject"}}[Loom] Escalated: {{.Item.Title}}{{end}}
+{{- define "escalation.body"}}Hi {{.User}},
+
+A bead was escalated and is waiting for your decision.
+
+{{.Item.Message}}
+{{if .Item.Link}}
+Decide at {{.Item.Link}}
+{{end}}{{end}}
+{{- define "sla_breach.subject"}}[Loom] SLA breached{{end}}
+{{- define "sla_breach.body"}}Hi {{.User}},
+
+A bead missed its SLA.
+
+{{.Item.Message}}
+{{if .Item.Link}}
+See {{.Item.Link}}
+{{end}}{{end}}
+{{- define "decision.subject"}}[Loom] {{.Item.Title}}{{end}}
+{{- define "decision.body"}}Hi {{.User}},
+
+{{.Item.Message}}
+{{if .Item.Link}}
+Decide at {{.Item.Link}}
+{{end}}{{end}}
+{{- define "report.subject"}}[Loom] {{.Item.Title}}{{end}}
+{{- define "report.body"}}{{or .Item.Body .Item.Message}}
+{{if .Item.Link}}
+Read it online at {{.Item.Link}}
+{{end}}{{end}}
+{{- define "default.subject"}}[Loom] {{.Item.Title}}{{end}}
+{{- define "default.body"}}Hi {{.User}},
+
+{{.Item.Message}}
+{{if .Item.Link}}
+{{.Item.Link}}
+{{end}}{{end}}
+{{- define "digest.subject"}}[Loom] {{len .Items}} notifications{{end}}
+{{- define "digest.body"}}Hi {{.User}},
+
+Here is what happened since your last digest.
+{{range .Items}}
+* {{.Title}} ({{.Priority}}, {{.Time.Format "Jan 2 15:04 MST"}})
+  {{.Message}}
+{{- if .Link}}
+  {{.Link}}
+{{- end}}
+{{end}}{{end}}
+`))
+
+// emailKind picks the template of a notification.
+func emailKind(eventType string, metadata map[string]interface{}) string {
+	if escalation, _ := metadata["escalation"].(bool); escalation {
+		return "escalation"
+	}
+	switch eventType {
+	case "bead.sla_breach":
+		return "sla_breach"
+	case "decision.created":
+		return "decision"
+	case "report.generated":
+		return "report"
+	default:
+		return "default"
+	}
+}
+
+// renderEmail renders the email for a user's queued notifications.
+func renderEmail(user, baseURL string, group []*database.Notification) (subject, body string, err error) {
+	items := make([]emailItem, len(group))
+	metadata := make([]map[string]interface{}, len(group))
+	for i, n := range group {
+		link := n.Link
+		if strings.HasPrefix(link, "/") {
+			link = baseURL + link
+		}
+		if n.MetadataJSON != "" {
+			_ = json.Unmarshal([]byte(n.MetadataJSON), &metadata[i])
+		}
+		body, _ := metadata[i][EmailBodyKey].(string)
+		items[i] = emailItem{Title: n.Title, Message: n.Message, Body: body, Priority: n.Priority, Link: link, Time: n.CreatedAt}
+	}
+
+	kind := "digest"
+	if len(group) == 1 {
+		kind = emailKind(group[0].EventType, metadata[0])
+	}
+	data := map[string]interface{}{"User": user, "Item": items[0], "Items": items}
+
+	var s, b strings.Builder
+	if err := emailTemplates.ExecuteTemplate(&s, kind+".subject", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := emailTemplates.ExecuteTemplate(&b, kind+".body", data); err != nil {
+		return "", "", fmt.Errorf("failed to render email body: %w", err)
+	}
+	return s.String(), b.String(), nil
+}
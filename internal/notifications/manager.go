@@ -18,6 +18,8 @@ type Manager struct {
 	activityMgr   *activity.Manager
 	subscribers   map[string]map[string]chan *Notification // userID -> subscriberID -> channel
 	subscribersMu sync.RWMutex
+	email         *emailChannel // nil until SetMailer
+	emailMu       sync.RWMutex
 }
 
 // NewManager creates a new notification manager
@@ -67,9 +69,16 @@ func (m *Manager) ProcessActivity(activity *activity.Activity) error {
 			continue
 		}
 
-		// Check if in-app notifications are enabled
+		// Check which channels the user wants the notification on.
+		// Email-only notifications are kept archived, out of the in-app feed.
+		emailed := m.queueEmail(notification, prefs)
 		if !prefs.EnableInApp {
-			continue
+			if !emailed {
+				continue
+			}
+			now := time.Now()
+			notification.Status = StatusArchived
+			notification.ArchivedAt = &now
 		}
 
 		// Create notification
@@ -79,7 +88,12 @@ func (m *Manager) ProcessActivity(activity *activity.Activity) error {
 		}
 
 		// Broadcast to user's SSE streams
-		m.broadcastToUser(user.ID, notification)
+		if prefs.EnableInApp {
+			m.broadcastToUser(user.ID, notification)
+		}
+		if emailed {
+			m.kickEmail(prefs)
+		}
 	}
 
 	return nil
@@ -129,10 +143,17 @@ func (m *Manager) ShouldNotify(activity *activity.Activity, userID string) (bool
 		Priority:   priority,
 		CreatedAt:  time.Now(),
 	}
+	if activity.EventType == "decision.created" && activity.Source == escalationSource {
+		notification.Metadata = map[string]interface{}{"escalation": true}
+	}
 
 	return true, notification
 }
 
+// escalationSource is the event source of decisions that escalate a bead to
+// a human.
+const escalationSource = "ceo-escalation"
+
 // formatNotification formats a notification based on activity and user
 func (m *Manager) formatNotification(activity *activity.Activity, userID string) (title, message, link string) {
 	// Check for direct assignment
@@ -146,6 +167,18 @@ func (m *Manager) formatNotification(activity *activity.Activity, userID string)
 		return "", "", ""
 	}
 
+	// Check for beads escalated to a human decision
+	if activity.EventType == "decision.created" && activity.Source == escalationSource {
+		title = "Bead Escalated"
+		if reason, ok := activity.Metadata["reason"].(string); ok && reason != "" {
+			message = fmt.Sprintf("Bead %s was escalated: %s", activity.BeadID, reason)
+		} else {
+			message = fmt.Sprintf("Bead %s was escalated", activity.BeadID)
+		}
+		link = fmt.Sprintf("/decisions/%s", activity.ResourceID)
+		return
+	}
+
 	// Check for decision requiring user input
 	if activity.EventType == "decision.created" {
 		if deciderID, ok := activity.Metadata["decider_id"].(string); ok && deciderID == userID {
@@ -157,6 +190,30 @@ func (m *Manager) formatNotification(activity *activity.Activity, userID string)
 		return "", "", ""
 	}
 
+	// Check for mentions in bead comments
+	if activity.EventType == "mention.created" {
+		if mentionedID, ok := activity.Metadata["mentioned_id"].(string); ok && mentionedID == userID {
+			author, _ := activity.Metadata["author_username"].(string)
+			title = "You Were Mentioned"
+			message = fmt.Sprintf("%s mentioned you on bead %s", author, activity.ResourceID)
+			link = fmt.Sprintf("/beads/%s", activity.ResourceID)
+			return
+		}
+		return "", "", ""
+	}
+
+	// Check for replies on bead threads the user has commented on
+	if activity.EventType == "comment.created" {
+		if m.isCommentParticipant(activity, userID) {
+			author, _ := activity.Metadata["author_username"].(string)
+			title = "New Comment"
+			message = fmt.Sprintf("%s commented on bead %s", author, activity.ResourceID)
+			link = fmt.Sprintf("/beads/%s", activity.ResourceID)
+			return
+		}
+		return "", "", ""
+	}
+
 	// Check for critical priority beads
 	if activity.EventType == "bead.created" {
 		if priority, ok := activity.Metadata["priority"].(string); ok && priority == "P0" {
@@ -167,6 +224,30 @@ func (m *Manager) formatNotification(activity *activity.Activity, userID string)
 		}
 	}
 
+	// Check for missed SLA deadlines
+	if activity.EventType == "bead.sla_breach" {
+		title = "SLA Breached"
+		if reason, ok := activity.Metadata["reason"].(string); ok {
+			message = reason
+		} else {
+			message = fmt.Sprintf("Bead %s missed its SLA", activity.ResourceID)
+		}
+		link = fmt.Sprintf("/beads/%s", activity.ResourceID)
+		return
+	}
+
+	// Check for token/cost spikes
+	if activity.EventType == "cost.anomaly" {
+		title = "Cost Anomaly"
+		if reason, ok := activity.Metadata["reason"].(string); ok {
+			message = reason
+		} else {
+			message = fmt.Sprintf("Unusual usage by %s %s", activity.ResourceType, activity.ResourceID)
+		}
+		link = "/analytics"
+		return
+	}
+
 	// Check for system errors
 	if activity.EventType == "provider.deleted" || activity.EventType == "workflow.failed" {
 		title = "System Alert"
@@ -178,6 +259,40 @@ func (m *Manager) formatNotification(activity *activity.Activity, userID string)
 	return "", "", ""
 }
 
+// isCommentParticipant reports whether userID has previously commented on the
+// bead a new comment was left on. The comment's author and anyone it mentions
+// are excluded; mentioned users get a mention.created notification instead.
+func (m *Manager) isCommentParticipant(activity *activity.Activity, userID string) bool {
+	if activity.ActorID == userID || activity.BeadID == "" {
+		return false
+	}
+	mentioned := make(map[string]bool)
+	switch mentions := activity.Metadata["mentions"].(type) {
+	case []string:
+		for _, username := range mentions {
+			mentioned[username] = true
+		}
+	case []interface{}:
+		for _, v := range mentions {
+			if username, ok := v.(string); ok {
+				mentioned[username] = true
+			}
+		}
+	}
+
+	comments, err := m.db.GetCommentsByBeadID(activity.BeadID)
+	if err != nil {
+		return false
+	}
+	commentID, _ := activity.Metadata["comment_id"].(string)
+	for _, c := range comments {
+		if c.AuthorID == userID && c.ID != commentID && !c.Deleted {
+			return !mentioned[c.AuthorUsername]
+		}
+	}
+	return false
+}
+
 // determinePriority determines notification priority based on activity
 func (m *Manager) determinePriority(activity *activity.Activity) string {
 	// Check metadata for explicit priority
@@ -196,11 +311,11 @@ func (m *Manager) determinePriority(activity *activity.Activity) string {
 
 	// Determine priority based on event type
 	switch activity.EventType {
-	case "bead.assigned", "decision.created":
+	case "bead.assigned", "decision.created", "mention.created", "cost.anomaly":
 		return PriorityHigh
-	case "workflow.failed", "provider.deleted":
+	case "workflow.failed", "provider.deleted", "bead.sla_breach":
 		return PriorityCritical
-	case "bead.created", "agent.spawned":
+	case "bead.created", "agent.spawned", "comment.created":
 		return PriorityNormal
 	default:
 		return PriorityLow
@@ -289,6 +404,7 @@ func (m *Manager) CreateNotification(notification *Notification) error {
 		Status:       notification.Status,
 		Priority:     notification.Priority,
 		MetadataJSON: metadataJSON,
+		EmailStatus:  notification.EmailStatus,
 		CreatedAt:    notification.CreatedAt,
 		ReadAt:       notification.ReadAt,
 		ArchivedAt:   notification.ArchivedAt,
@@ -297,6 +413,64 @@ func (m *Manager) CreateNotification(notification *Notification) error {
 	return m.db.CreateNotification(dbNotification)
 }
 
+// NotifyUser delivers a notification addressed to one user directly, without
+// going through the activity feed or the user's subscription preferences.
+// It is emailed too when the user has email on for its event type.
+func (m *Manager) NotifyUser(notification *Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.Status == "" {
+		notification.Status = StatusUnread
+	}
+	if notification.Priority == "" {
+		notification.Priority = PriorityNormal
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	prefs, err := m.GetPreferences(notification.UserID)
+	emailed := err == nil && m.queueEmail(notification, prefs)
+	if err := m.CreateNotification(notification); err != nil {
+		return err
+	}
+	m.broadcastToUser(notification.UserID, notification)
+	if emailed {
+		m.kickEmail(prefs)
+	}
+	return nil
+}
+
+// NotifySubscribers delivers a copy of a notification to every user
+// subscribed to its event type whose project filters allow projectID. It
+// returns the number of users notified.
+func (m *Manager) NotifySubscribers(projectID string, notification *Notification) (int, error) {
+	users, err := m.db.ListUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	notified := 0
+	for _, user := range users {
+		prefs, err := m.GetPreferences(user.ID)
+		if err != nil {
+			log.Printf("Failed to get preferences for user %s: %v", user.ID, err)
+			continue
+		}
+		if !m.isEventSubscribed(notification.EventType, prefs.SubscribedEvents) || !m.isEventSubscribed(projectID, prefs.ProjectFilters) {
+			continue
+		}
+		n := *notification
+		n.ID = ""
+		n.UserID = user.ID
+		if err := m.NotifyUser(&n); err != nil {
+			log.Printf("Failed to notify user %s: %v", user.ID, err)
+			continue
+		}
+		notified++
+	}
+	return notified, nil
+}
+
 // GetNotifications retrieves notifications for a user
 func (m *Manager) GetNotifications(userID string, status string, limit, offset int) ([]*Notification, error) {
 	dbNotifications, err := m.db.ListNotifications(userID, status, limit, offset)
@@ -378,6 +552,13 @@ func (m *Manager) GetPreferences(userID string) (*NotificationPreferences, error
 		}
 	}
 
+	if dbPrefs.EmailEventsJSON != "" {
+		var events []string
+		if err := json.Unmarshal([]byte(dbPrefs.EmailEventsJSON), &events); err == nil {
+			prefs.EmailEvents = events
+		}
+	}
+
 	if dbPrefs.ProjectFiltersJSON != "" {
 		var projects []string
 		if err := json.Unmarshal([]byte(dbPrefs.ProjectFiltersJSON), &projects); err == nil {
@@ -413,7 +594,7 @@ func (m *Manager) createDefaultPreferences(userID string) (*NotificationPreferen
 // UpdatePreferences updates notification preferences
 func (m *Manager) UpdatePreferences(prefs *NotificationPreferences) error {
 	// Convert to DB format
-	var subscribedEventsJSON, projectFiltersJSON string
+	var subscribedEventsJSON, emailEventsJSON, projectFiltersJSON string
 
 	if len(prefs.SubscribedEvents) > 0 {
 		data, err := json.Marshal(prefs.SubscribedEvents)
@@ -423,6 +604,14 @@ func (m *Manager) UpdatePreferences(prefs *NotificationPreferences) error {
 		subscribedEventsJSON = string(data)
 	}
 
+	if len(prefs.EmailEvents) > 0 {
+		data, err := json.Marshal(prefs.EmailEvents)
+		if err != nil {
+			return fmt.Errorf("failed to marshal email events: %w", err)
+		}
+		emailEventsJSON = string(data)
+	}
+
 	if len(prefs.ProjectFilters) > 0 {
 		data, err := json.Marshal(prefs.ProjectFilters)
 		if err != nil {
@@ -440,6 +629,7 @@ func (m *Manager) UpdatePreferences(prefs *NotificationPreferences) error {
 		EnableEmail:          prefs.EnableEmail,
 		EnableWebhook:        prefs.EnableWebhook,
 		SubscribedEventsJSON: subscribedEventsJSON,
+		EmailEventsJSON:      emailEventsJSON,
 		DigestMode:           prefs.DigestMode,
 		QuietHoursStart:      prefs.QuietHoursStart,
 		QuietHoursEnd:        prefs.QuietHoursEnd,
@@ -19,6 +19,9 @@ type Notification struct {
 	CreatedAt  time.Time              `json:"created_at"`
 	ReadAt     *time.Time             `json:"read_at,omitempty"`
 	ArchivedAt *time.Time             `json:"archived_at,omitempty"`
+
+	// EmailStatus is queued while the notification waits to be emailed
+	EmailStatus string `json:"-"`
 }
 
 // NotificationPreferences represents user notification preferences
@@ -29,6 +32,7 @@ type NotificationPreferences struct {
 	EnableEmail      bool      `json:"enable_email"`
 	EnableWebhook    bool      `json:"enable_webhook"`
 	SubscribedEvents []string  `json:"subscribed_events"`
+	EmailEvents      []string  `json:"email_events,omitempty"` // Event types emailed; empty emails all subscribed events
 	DigestMode       string    `json:"digest_mode"`
 	QuietHoursStart  string    `json:"quiet_hours_start,omitempty"`
 	QuietHoursEnd    string    `json:"quiet_hours_end,omitempty"`
@@ -52,7 +56,8 @@ const (
 	StatusArchived = "archived"
 )
 
-// Digest modes
+// Digest modes. They decide how often notification emails are sent:
+// one by one as they happen, or collected into an hourly or daily digest.
 const (
 	DigestRealtime = "realtime"
 	DigestHourly   = "hourly"
@@ -0,0 +1,422 @@
+// Package objectstore keeps objects in a local directory or an
+// S3-compatible bucket. Log archives, scheduled exports and backups go
+// through it.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// ErrNotFound is returned by Get for a key with no object.
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps objects.
+type Store interface {
+	// Put stores data under key, a slash-separated path, and returns
+	// where it went.
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Get returns the object stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the keys that start with prefix, sorted.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes the object under key; a missing object is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the store cfg describes, or nil when cfg has no target.
+func New(cfg config.ObjectStoreConfig) (Store, error) {
+	switch strings.ToLower(cfg.Target) {
+	case "", "none":
+		return nil, nil
+	case "file":
+		if cfg.Dir == "" {
+			return nil, errors.New("file store: dir is required")
+		}
+		return &FileStore{Dir: cfg.Dir}, nil
+	case "s3":
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown object store target %q: want file or s3", cfg.Target)
+	}
+}
+
+// FileStore keeps objects in a local directory. Key path segments become
+// subdirectories.
+type FileStore struct {
+	Dir string
+}
+
+// Put implements Store.
+func (s *FileStore) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create object dir: %w", err)
+	}
+	// Write to a temporary name first so a crash never leaves a truncated
+	// object behind.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	return path, nil
+}
+
+// path is where the object under key lives. Keys ending in .tmp are
+// reserved for objects being written.
+func (s *FileStore) path(key string) (string, error) {
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) || strings.HasSuffix(rel, ".tmp") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.Dir, rel), nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return data, err
+}
+
+// List implements Store.
+func (s *FileStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == s.Dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// S3Store writes objects to an S3 bucket (or an S3-compatible store such
+// as MinIO). Requests are signed with the credentials in
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN.
+type S3Store struct {
+	bucket   string
+	prefix   string
+	region   string
+	endpoint string
+	// pathStyle puts the bucket in the path instead of the host name,
+	// which custom endpoints need.
+	pathStyle bool
+	creds     s3Credentials
+	client    *http.Client
+	now       func() time.Time
+}
+
+type s3Credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// NewS3Store creates an S3 store. The region defaults to AWS_REGION, then
+// AWS_DEFAULT_REGION.
+func NewS3Store(cfg config.ObjectStoreConfig) (*S3Store, error) {
+	s := &S3Store{
+		bucket: cfg.Bucket,
+		prefix: cfg.Prefix,
+		region: cfg.Region,
+		creds: s3Credentials{
+			accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		client: &http.Client{Timeout: 60 * time.Second},
+		now:    time.Now,
+	}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_REGION")
+	}
+	if s.region == "" {
+		s.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if s.bucket == "" {
+		return nil, errors.New("s3 store: bucket is required")
+	}
+	if s.region == "" {
+		return nil, errors.New("s3 store: region is required (region or AWS_REGION)")
+	}
+	if s.creds.accessKeyID == "" || s.creds.secretAccessKey == "" {
+		return nil, errors.New("s3 store: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	if cfg.Endpoint != "" {
+		s.endpoint = strings.TrimRight(cfg.Endpoint, "/")
+		s.pathStyle = true
+	} else {
+		s.endpoint = "https://" + s.bucket + ".s3." + s.region + ".amazonaws.com"
+	}
+	return s, nil
+}
+
+// do sends a signed request for the object at key, a key already carrying
+// the store's prefix, or for the bucket when key is empty.
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, data []byte, contentType string) (*http.Response, error) {
+	path := "/" + escapeKey(key)
+	if s.pathStyle {
+		path = "/" + s.bucket + path
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = canonicalQuery(query)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", sha256Hex(data))
+	s.sign(req, data)
+	return s.client.Do(req)
+}
+
+// s3Error describes a failed S3 response.
+func s3Error(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	return fmt.Errorf("s3 %s failed with status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// Put implements Store.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	key = s.prefix + key
+	resp, err := s.do(ctx, http.MethodPut, key, nil, data, contentType)
+	if err != nil {
+		return "", fmt.Errorf("s3 upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", s3Error("upload", resp)
+	}
+	return "s3://" + s.bucket + "/" + key, nil
+}
+
+// Get implements Store.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.prefix+key, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("s3 download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return nil, s3Error("download", resp)
+}
+
+// List implements Store. It pages through ListObjectsV2.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("s3 list failed: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error("list", resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list failed: %w", err)
+		}
+		for _, c := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(c.Key, s.prefix))
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Delete implements Store.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.prefix+key, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("s3 delete failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete", resp)
+	}
+	return nil
+}
+
+// canonicalQuery encodes query the way SigV4 canonical requests expect:
+// sorted by name, with everything but unreserved characters escaped.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, v := range query[name] {
+			parts = append(parts, escapeQuery(name)+"="+escapeQuery(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapeQuery(s string) string {
+	return strings.ReplaceAll(escapeKey(s), "/", "%2F")
+}
+
+// escapeKey percent-encodes everything in key but unreserved characters
+// and slashes, the way SigV4 canonical URIs expect, so the signed path and
+// the sent path agree.
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sign adds AWS Signature Version 4 headers to req. Every header already
+// set on req is signed.
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	amzDate := s.now().UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.creds.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.creds.accessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
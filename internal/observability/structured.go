@@ -1,13 +1,14 @@
 package observability
 
 import (
-	"encoding/json"
-	"log"
-	"time"
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
 )
 
 func Info(event string, fields map[string]interface{}) {
-	logEvent("info", event, fields)
+	logEvent(slog.LevelInfo, event, fields)
 }
 
 func Error(event string, fields map[string]interface{}, err error) {
@@ -15,30 +16,25 @@ func Error(event string, fields map[string]interface{}, err error) {
 	if err != nil {
 		payload["error"] = err.Error()
 	}
-	logEvent("error", event, payload)
+	logEvent(slog.LevelError, event, payload)
 }
 
-func logEvent(level, event string, fields map[string]interface{}) {
-	payload := cloneFields(fields)
-	payload["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
-	payload["level"] = level
-	payload["event"] = event
-	raw, err := json.Marshal(payload)
-	if err != nil {
-		fallback := map[string]interface{}{
-			"ts":    time.Now().UTC().Format(time.RFC3339Nano),
-			"level": "error",
-			"event": "log.marshal_failed",
-			"error": err.Error(),
-		}
-		if fields != nil {
-			fallback["event_payload"] = fields
-		}
-		fallbackRaw, _ := json.Marshal(fallback)
-		log.Print(string(fallbackRaw))
-		return
+// logEvent logs event through the default slog logger. The part of the
+// event name before the first dot ("dispatch" in "dispatch.claim") is the
+// log source, so per-subsystem levels apply to these events too.
+func logEvent(level slog.Level, event string, fields map[string]interface{}) {
+	source, _, _ := strings.Cut(event, ".")
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]any, 0, 2*len(keys)+2)
+	args = append(args, "source", source)
+	for _, k := range keys {
+		args = append(args, k, fields[k])
 	}
-	log.Print(string(raw))
+	slog.Default().Log(context.Background(), level, event, args...)
 }
 
 func cloneFields(fields map[string]interface{}) map[string]interface{} {
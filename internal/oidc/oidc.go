@@ -0,0 +1,497 @@
+// Package oidc signs users in with an OpenID Connect provider such as Okta,
+// Azure AD, or Google, using the authorization code flow with PKCE.
+//
+// The provider is configured from its discovery document. ID tokens are
+// verified against the provider's published keys, and the user's groups
+// are mapped to a Loom role.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// loginTimeout is how long a user has to complete sign-in at the provider.
+const loginTimeout = 10 * time.Minute
+
+// keyRefreshInterval limits how often the provider's keys are refetched
+// when an ID token names a key that isn't known.
+const keyRefreshInterval = time.Minute
+
+// roleRank orders roles from most to least privileged, for users whose
+// groups map to several.
+var roleRank = []string{"admin", "operator", "user", "viewer", "service"}
+
+// ErrNotAllowed is returned when a user authenticated with the provider
+// but may not sign in to Loom.
+var ErrNotAllowed = errors.New("not allowed to sign in")
+
+// Identity is a user the provider authenticated.
+type Identity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	Email    string
+	Name     string
+	Groups   []string
+	Role     string
+	// Redirect is where the user asked to go after signing in.
+	Redirect string
+}
+
+// ExternalID identifies the user at the provider. Unlike the username or
+// email, the issuer and subject never change or get reassigned, so users
+// are matched on it.
+func (id *Identity) ExternalID() string {
+	return id.Issuer + "#" + id.Subject
+}
+
+// discovery is the part of the provider's discovery document used here.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// pendingLogin is a sign-in started with AuthCodeURL and not yet completed.
+type pendingLogin struct {
+	verifier string
+	nonce    string
+	redirect string
+	expires  time.Time
+}
+
+// Provider is an OpenID Connect provider.
+type Provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	disc    *discovery
+	keys    map[string]interface{} // key ID -> *rsa.PublicKey or *ecdsa.PublicKey
+	keysAt  time.Time
+	pending map[string]pendingLogin // state -> login
+}
+
+// New returns a provider for cfg. The discovery document is fetched on
+// first use, so a provider that is down at startup doesn't stop Loom.
+func New(cfg config.OIDCConfig, client *http.Client) (*Provider, error) {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc: issuer_url, client_id and redirect_url are required")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{
+		cfg:     cfg,
+		client:  client,
+		now:     time.Now,
+		pending: make(map[string]pendingLogin),
+	}, nil
+}
+
+// Name is the provider's display name.
+func (p *Provider) Name() string {
+	if p.cfg.Name != "" {
+		return p.cfg.Name
+	}
+	return "SSO"
+}
+
+// AuthCodeURL starts a sign-in and returns the provider URL to send the
+// user to, and the sign-in's state, which the caller binds to the browser
+// so that only it can complete the sign-in. redirect is where the user
+// goes once signed in.
+func (p *Provider) AuthCodeURL(ctx context.Context, redirect string) (string, string, error) {
+	disc, err := p.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	state, nonce, verifier := randomString(), randomString(), randomString()
+	challenge := sha256.Sum256([]byte(verifier))
+
+	p.mu.Lock()
+	now := p.now()
+	for s, login := range p.pending {
+		if now.After(login.expires) {
+			delete(p.pending, s)
+		}
+	}
+	p.pending[state] = pendingLogin{verifier: verifier, nonce: nonce, redirect: redirect, expires: now.Add(loginTimeout)}
+	p.mu.Unlock()
+
+	scopes := append([]string{"openid"}, p.cfg.Scopes...)
+	if len(p.cfg.Scopes) == 0 {
+		scopes = append(scopes, "profile", "email")
+	}
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(disc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return disc.AuthorizationEndpoint + sep + q.Encode(), state, nil
+}
+
+// Exchange completes a sign-in: it redeems the code the provider sent back
+// with state, verifies the ID token, and maps the user to a role. It
+// returns ErrNotAllowed, wrapped, for users who may not sign in.
+func (p *Provider) Exchange(ctx context.Context, code, state string) (*Identity, error) {
+	p.mu.Lock()
+	login, ok := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+	if !ok || p.now().After(login.expires) {
+		return nil, fmt.Errorf("oidc: unknown or expired sign-in; start again")
+	}
+
+	disc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {login.verifier},
+	}
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, disc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil && tokens.Error == "" {
+		return nil, fmt.Errorf("oidc: token exchange failed: %w", err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("oidc: token exchange failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("oidc: provider returned no ID token")
+	}
+
+	claims, err := p.verify(ctx, disc, tokens.IDToken, login.nonce)
+	if err != nil {
+		return nil, err
+	}
+	id, err := p.identity(claims)
+	if err != nil {
+		return nil, err
+	}
+	id.Redirect = login.redirect
+	return id, nil
+}
+
+// verify checks the ID token's signature, issuer, audience, expiry and
+// nonce and returns its claims.
+func (p *Provider) verify(ctx context.Context, disc *discovery, raw, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, disc, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}),
+		jwt.WithIssuer(disc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid ID token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("oidc: invalid ID token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// identity maps verified claims to a user and role.
+func (p *Provider) identity(claims jwt.MapClaims) (*Identity, error) {
+	str := func(name string) string {
+		v, _ := claims[name].(string)
+		return v
+	}
+	id := &Identity{Issuer: str("iss"), Subject: str("sub"), Email: str("email"), Name: str("name")}
+	if id.Subject == "" {
+		return nil, fmt.Errorf("oidc: ID token has no subject")
+	}
+
+	usernameClaim := p.cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	id.Username = str(usernameClaim)
+	if id.Username == "" {
+		id.Username = id.Email
+	}
+	if id.Username == "" {
+		id.Username = id.Subject
+	}
+
+	if len(p.cfg.AllowedDomains) > 0 {
+		verified, _ := claims["email_verified"].(bool)
+		_, domain, _ := strings.Cut(id.Email, "@")
+		if !verified || !containsFold(p.cfg.AllowedDomains, domain) {
+			return nil, fmt.Errorf("oidc: %s: email domain %q: %w", id.Username, domain, ErrNotAllowed)
+		}
+	}
+
+	groupsClaim := p.cfg.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+	switch v := claims[groupsClaim].(type) {
+	case []interface{}:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		id.Groups = []string{v}
+	}
+
+	id.Role = p.Role(id.Groups)
+	if id.Role == "" {
+		return nil, fmt.Errorf("oidc: %s is in no group mapped to a role: %w", id.Username, ErrNotAllowed)
+	}
+	return id, nil
+}
+
+// Role returns the most privileged role groups map to, or the default
+// role when none of them is mapped.
+func (p *Provider) Role(groups []string) string {
+	best := -1
+	role := ""
+	for _, g := range groups {
+		mapped, ok := p.cfg.RoleMapping[g]
+		if !ok {
+			continue
+		}
+		rank := len(roleRank)
+		for i, r := range roleRank {
+			if r == mapped {
+				rank = i
+				break
+			}
+		}
+		if best == -1 || rank < best {
+			best, role = rank, mapped
+		}
+	}
+	if role == "" {
+		return p.cfg.DefaultRole
+	}
+	return role
+}
+
+// discover returns the provider's discovery document, fetching it once.
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	disc := p.disc
+	p.mu.Unlock()
+	if disc != nil {
+		return disc, nil
+	}
+
+	u := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	disc = &discovery{}
+	if err := p.doJSON(req, disc); err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	if disc.AuthorizationEndpoint == "" || disc.TokenEndpoint == "" || disc.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: discovery document at %s is incomplete", u)
+	}
+	if strings.TrimSuffix(disc.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("oidc: discovery document names issuer %q, want %q", disc.Issuer, p.cfg.IssuerURL)
+	}
+
+	p.mu.Lock()
+	p.disc = disc
+	p.mu.Unlock()
+	return disc, nil
+}
+
+// key returns the provider's public key with ID kid, refetching the key
+// set when kid isn't known.
+func (p *Provider) key(ctx context.Context, disc *discovery, kid string) (interface{}, error) {
+	p.mu.Lock()
+	key, ok := p.lookupKey(kid)
+	stale := p.now().Sub(p.keysAt) >= keyRefreshInterval
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, disc.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("fetch signing keys: %w", err)
+	}
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys, p.keysAt = keys, p.now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds kid among the known keys. A token without a key ID may
+// use the only key there is. The caller holds p.mu.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	decodeErr := json.NewDecoder(resp.Body).Decode(v)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+	return decodeErr
+}
+
+// jwk is a JSON Web Key as published in a provider's key set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// testIdP is an identity provider that signs in whoever it is told to.
+type testIdP struct {
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+
+	// Set from the authorization request.
+	nonce, challenge string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.srv.URL,
+			"authorization_endpoint": idp.srv.URL + "/authorize",
+			"token_endpoint":         idp.srv.URL + "/token",
+			"jwks_uri":               idp.srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   idp.srv.URL,
+			"aud":   "loom",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "k1"
+		signed, err := tok.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+// login runs a sign-in through the provider and returns the result.
+func (idp *testIdP) login(t *testing.T, p *Provider, code string) (*Identity, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, state, err := p.AuthCodeURL(ctx, "/beads")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "loom" {
+		t.Errorf("authorization request %s lacks PKCE or client ID", authURL)
+	}
+	idp.nonce, idp.challenge = q.Get("nonce"), q.Get("code_challenge")
+	if q.Get("state") != state {
+		t.Errorf("authorization request state = %q, want %q", q.Get("state"), state)
+	}
+	return p.Exchange(ctx, code, state)
+}
+
+func testConfig(issuer string) config.OIDCConfig {
+	return config.OIDCConfig{
+		Enabled:     true,
+		IssuerURL:   issuer,
+		ClientID:    "loom",
+		RedirectURL: "https://loom.example.com/api/v1/auth/oidc/callback",
+		RoleMapping: map[string]string{"loom-admins": "admin", "engineers": "user"},
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newTestIdP(t)
+	p, err := New(testConfig(idp.srv.URL), idp.srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.claims = jwt.MapClaims{
+		"sub":                "00u1",
+		"preferred_username": "ada",
+		"email":              "ada@example.com",
+		"groups":             []string{"engineers", "loom-admins"},
+	}
+
+	id, err := idp.login(t, p, "good-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if id.Username != "ada" || id.Email != "ada@example.com" || id.Role != "admin" || id.Redirect != "/beads" ||
+		id.ExternalID() != idp.srv.URL+"#00u1" {
+		t.Errorf("identity = %+v", id)
+	}
+
+	if _, err := idp.login(t, p, "bad-code"); err == nil {
+		t.Error("bad authorization code accepted")
+	}
+	if _, err := p.Exchange(context.Background(), "good-code", "never-issued"); err == nil {
+		t.Error("unknown state accepted")
+	}
+}
+
+func TestExchange_Refused(t *testing.T) {
+	idp := newTestIdP(t)
+	cfg := testConfig(idp.srv.URL)
+	cfg.AllowedDomains = []string{"example.com"}
+	p, err := New(cfg, idp.srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp.claims = jwt.MapClaims{"sub": "1", "email": "eve@example.org", "email_verified": true, "groups": "engineers"}
+	if _, err := idp.login(t, p, "good-code"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("other domain: err = %v, want ErrNotAllowed", err)
+	}
+
+	idp.claims = jwt.MapClaims{"sub": "2", "email": "bob@example.com", "email_verified": true, "groups": "sales"}
+	if _, err := idp.login(t, p, "good-code"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("unmapped group without default role: err = %v, want ErrNotAllowed", err)
+	}
+
+	idp.claims = jwt.MapClaims{"sub": "3", "email": "cy@example.com", "email_verified": true, "groups": "engineers"}
+	id, err := idp.login(t, p, "good-code")
+	if err != nil {
+		t.Fatalf("allowed user refused: %v", err)
+	}
+	// Without a preferred_username the email is the username.
+	if id.Username != "cy@example.com" || id.Role != "user" {
+		t.Errorf("identity = %+v", id)
+	}
+}
+
+func TestRole(t *testing.T) {
+	p := &Provider{cfg: config.OIDCConfig{
+		RoleMapping: map[string]string{"ops": "operator", "staff": "viewer", "admins": "admin"},
+		DefaultRole: "viewer",
+	}}
+	tests := []struct {
+		groups []string
+		want   string
+	}{
+		{[]string{"staff", "ops"}, "operator"},
+		{[]string{"admins", "ops"}, "admin"},
+		{[]string{"unknown"}, "viewer"},
+		{nil, "viewer"},
+	}
+	for _, tt := range tests {
+		if got := p.Role(tt.groups); got != tt.want {
+			t.Errorf("Role(%v) = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+}
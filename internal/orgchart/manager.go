@@ -1,13 +1,29 @@
 package orgchart
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/jordanhubbard/loom/pkg/models"
 )
 
+// ErrInvalidPosition is returned for a position that can't be added or an
+// update that can't be applied, such as a reporting line to a position that
+// doesn't exist or one that would form a cycle.
+var ErrInvalidPosition = errors.New("invalid position")
+
+// PositionUpdate changes some of a position's settings. Nil fields are left
+// as they are; an empty ReportsTo makes the position report to no one.
+type PositionUpdate struct {
+	PersonaPath  *string `json:"persona_path,omitempty"`
+	Required     *bool   `json:"required,omitempty"`
+	MaxInstances *int    `json:"max_instances,omitempty"`
+	ReportsTo    *string `json:"reports_to,omitempty"`
+}
+
 // Manager manages org charts for projects
 type Manager struct {
 	charts   map[string]*models.OrgChart // key: project ID or template ID
@@ -231,7 +247,8 @@ func (m *Manager) GetPositionsForAgent(projectID, agentID string) []models.Posit
 	return positions
 }
 
-// AddPosition adds a new position to a project's org chart
+// AddPosition adds a new position to a project's org chart. The ID
+// defaults to "pos-<role>" and the persona to "default/<role>".
 func (m *Manager) AddPosition(projectID string, position models.Position) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -241,6 +258,22 @@ func (m *Manager) AddPosition(projectID string, position models.Position) error
 		return fmt.Errorf("org chart not found for project: %s", projectID)
 	}
 
+	if position.RoleName == "" {
+		return fmt.Errorf("%w: role name is required", ErrInvalidPosition)
+	}
+	if position.ID == "" {
+		position.ID = "pos-" + position.RoleName
+	}
+	if position.PersonaPath == "" {
+		position.PersonaPath = "default/" + position.RoleName
+	}
+	if position.MaxInstances < 0 {
+		return fmt.Errorf("%w: max instances cannot be negative", ErrInvalidPosition)
+	}
+	if position.ReportsTo != "" && chart.GetPositionByID(position.ReportsTo) == nil {
+		return fmt.Errorf("%w: reports to unknown position %s", ErrInvalidPosition, position.ReportsTo)
+	}
+
 	// Check for duplicate ID or role name
 	for _, p := range chart.Positions {
 		if p.ID == position.ID {
@@ -254,13 +287,82 @@ func (m *Manager) AddPosition(projectID string, position models.Position) error
 	if position.AgentIDs == nil {
 		position.AgentIDs = []string{}
 	}
+	if position.CreatedAt.IsZero() {
+		position.CreatedAt = time.Now()
+	}
 
 	chart.Positions = append(chart.Positions, position)
+	chart.RemovedRoles = removeString(chart.RemovedRoles, position.RoleName)
+	chart.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdatePosition applies update to a position in a project's org chart and
+// returns the updated position.
+func (m *Manager) UpdatePosition(projectID, positionID string, update PositionUpdate) (*models.Position, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	chart, ok := m.charts[projectID]
+	if !ok {
+		return nil, fmt.Errorf("org chart not found for project: %s", projectID)
+	}
+	pos := chart.GetPositionByID(positionID)
+	if pos == nil {
+		return nil, fmt.Errorf("position not found: %s", positionID)
+	}
+
+	if update.MaxInstances != nil && *update.MaxInstances < 0 {
+		return nil, fmt.Errorf("%w: max instances cannot be negative", ErrInvalidPosition)
+	}
+	if update.PersonaPath != nil && strings.TrimSpace(*update.PersonaPath) == "" {
+		return nil, fmt.Errorf("%w: persona path cannot be empty", ErrInvalidPosition)
+	}
+	if update.ReportsTo != nil && *update.ReportsTo != "" {
+		if err := checkReportingLine(chart, positionID, *update.ReportsTo); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.PersonaPath != nil {
+		pos.PersonaPath = *update.PersonaPath
+	}
+	if update.Required != nil {
+		pos.Required = *update.Required
+	}
+	if update.MaxInstances != nil {
+		pos.MaxInstances = *update.MaxInstances
+	}
+	if update.ReportsTo != nil {
+		pos.ReportsTo = *update.ReportsTo
+	}
 	chart.UpdatedAt = time.Now()
+	return pos, nil
+}
+
+// checkReportingLine returns an error unless positionID can report to
+// managerID: the manager must exist and must not already report, directly
+// or not, to positionID.
+func checkReportingLine(chart *models.OrgChart, positionID, managerID string) error {
+	for id, hops := managerID, 0; id != ""; hops++ {
+		if id == positionID {
+			return fmt.Errorf("%w: %s reporting to %s would form a cycle", ErrInvalidPosition, positionID, managerID)
+		}
+		p := chart.GetPositionByID(id)
+		if p == nil {
+			return fmt.Errorf("%w: reports to unknown position %s", ErrInvalidPosition, id)
+		}
+		if hops > len(chart.Positions) {
+			break // an existing cycle elsewhere; don't loop forever
+		}
+		id = p.ReportsTo
+	}
 	return nil
 }
 
-// RemovePosition removes a position from a project's org chart
+// RemovePosition removes a position from a project's org chart. Positions
+// that reported to it report to its manager instead. Removing a default
+// role records it in the chart's RemovedRoles so it isn't backfilled.
 func (m *Manager) RemovePosition(projectID, positionID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -273,6 +375,14 @@ func (m *Manager) RemovePosition(projectID, positionID string) error {
 	for i, p := range chart.Positions {
 		if p.ID == positionID {
 			chart.Positions = append(chart.Positions[:i], chart.Positions[i+1:]...)
+			for j := range chart.Positions {
+				if chart.Positions[j].ReportsTo == positionID {
+					chart.Positions[j].ReportsTo = p.ReportsTo
+				}
+			}
+			if m.template.GetPositionByRole(p.RoleName) != nil {
+				chart.RemovedRoles = append(removeString(chart.RemovedRoles, p.RoleName), p.RoleName)
+			}
 			chart.UpdatedAt = time.Now()
 			return nil
 		}
@@ -281,6 +391,46 @@ func (m *Manager) RemovePosition(projectID, positionID string) error {
 	return fmt.Errorf("position not found: %s", positionID)
 }
 
+// LoadLayout replaces a project's org chart with the given positions and
+// removed default roles, e.g. a customized chart restored from the
+// database. Agent assignments start empty.
+func (m *Manager) LoadLayout(projectID, projectName string, positions []models.Position, removedRoles []string) (*models.OrgChart, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cloned := make([]models.Position, len(positions))
+	for i, p := range positions {
+		p.AgentIDs = []string{}
+		cloned[i] = p
+	}
+	now := time.Now()
+	chart := &models.OrgChart{
+		ID:           fmt.Sprintf("orgchart-%s", projectID),
+		ProjectID:    projectID,
+		Name:         fmt.Sprintf("%s Org Chart", projectName),
+		Positions:    cloned,
+		ParentID:     m.template.ID,
+		RemovedRoles: append([]string(nil), removedRoles...),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	m.charts[projectID] = chart
+	return chart, nil
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // DeleteForProject removes the org chart for a project
 func (m *Manager) DeleteForProject(projectID string) error {
 	m.mu.Lock()
@@ -1,6 +1,7 @@
 package orgchart
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/jordanhubbard/loom/pkg/models"
@@ -335,3 +336,98 @@ func TestPositionStatus(t *testing.T) {
 		t.Errorf("Expected 'filled', got '%s'", pos.Status())
 	}
 }
+
+func TestAddPositionDefaultsAndValidation(t *testing.T) {
+	m := NewManager()
+	if _, err := m.CreateForProject("proj-123", "Test"); err != nil {
+		t.Fatalf("CreateForProject failed: %v", err)
+	}
+
+	if err := m.AddPosition("proj-123", models.Position{RoleName: "security-auditor", ReportsTo: "pos-em"}); err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	chart, _ := m.GetByProject("proj-123")
+	pos := chart.GetPositionByRole("security-auditor")
+	if pos == nil || pos.ID != "pos-security-auditor" || pos.PersonaPath != "default/security-auditor" {
+		t.Errorf("position = %+v, want defaulted ID and persona", pos)
+	}
+
+	for _, bad := range []models.Position{
+		{},
+		{RoleName: "x", ReportsTo: "pos-nope"},
+		{RoleName: "y", MaxInstances: -1},
+	} {
+		if err := m.AddPosition("proj-123", bad); !errors.Is(err, ErrInvalidPosition) {
+			t.Errorf("AddPosition(%+v) = %v, want ErrInvalidPosition", bad, err)
+		}
+	}
+}
+
+func TestUpdatePosition(t *testing.T) {
+	m := NewManager()
+	if _, err := m.CreateForProject("proj-123", "Test"); err != nil {
+		t.Fatalf("CreateForProject failed: %v", err)
+	}
+
+	maxAgents := 3
+	manager := "pos-pm"
+	pos, err := m.UpdatePosition("proj-123", "pos-qa", PositionUpdate{MaxInstances: &maxAgents, ReportsTo: &manager})
+	if err != nil {
+		t.Fatalf("UpdatePosition failed: %v", err)
+	}
+	if pos.MaxInstances != 3 || pos.ReportsTo != "pos-pm" || pos.PersonaPath != "default/qa-engineer" {
+		t.Errorf("position = %+v", pos)
+	}
+
+	// The CEO can't report to someone who reports to the CEO.
+	qa := "pos-qa"
+	if _, err := m.UpdatePosition("proj-123", "pos-ceo", PositionUpdate{ReportsTo: &qa}); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("cyclic update = %v, want ErrInvalidPosition", err)
+	}
+	if _, err := m.UpdatePosition("proj-123", "pos-nope", PositionUpdate{}); err == nil {
+		t.Error("updating an unknown position should fail")
+	}
+}
+
+func TestRemovePositionReparentsAndRemembersRole(t *testing.T) {
+	m := NewManager()
+	if _, err := m.CreateForProject("proj-123", "Test"); err != nil {
+		t.Fatalf("CreateForProject failed: %v", err)
+	}
+
+	if err := m.RemovePosition("proj-123", "pos-pm"); err != nil {
+		t.Fatalf("RemovePosition failed: %v", err)
+	}
+	chart, _ := m.GetByProject("proj-123")
+	if got := chart.GetPositionByID("pos-docs").ReportsTo; got != "pos-ceo" {
+		t.Errorf("docs reports to %q, want the removed position's manager pos-ceo", got)
+	}
+	if len(chart.RemovedRoles) != 1 || chart.RemovedRoles[0] != "product-manager" {
+		t.Errorf("removed roles = %v, want [product-manager]", chart.RemovedRoles)
+	}
+
+	// Adding the role back forgets the removal.
+	if err := m.AddPosition("proj-123", models.Position{RoleName: "product-manager"}); err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	if len(chart.RemovedRoles) != 0 {
+		t.Errorf("removed roles = %v, want none", chart.RemovedRoles)
+	}
+}
+
+func TestLoadLayout(t *testing.T) {
+	m := NewManager()
+	positions := []models.Position{
+		{ID: "pos-lead", RoleName: "lead", PersonaPath: "custom/lead", AgentIDs: []string{"stale"}},
+	}
+	chart, err := m.LoadLayout("proj-123", "Test", positions, []string{"cfo"})
+	if err != nil {
+		t.Fatalf("LoadLayout failed: %v", err)
+	}
+	if len(chart.Positions) != 1 || len(chart.Positions[0].AgentIDs) != 0 {
+		t.Errorf("positions = %+v, want the layout without agents", chart.Positions)
+	}
+	if again, _ := m.CreateForProject("proj-123", "Test"); again != chart {
+		t.Error("CreateForProject should return the loaded chart")
+	}
+}
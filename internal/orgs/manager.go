@@ -0,0 +1,362 @@
+// Package orgs lets several groups share one Loom server. Projects,
+// providers, agents, and budgets belong to an organization, users are
+// members of organizations, and every API request acts in one of them.
+// Teams split an organization further: a member limited to a team only
+// sees the organization's projects (and their agents) that belong to the
+// team or to no team.
+//
+// Isolation is enforced at the database query layer through
+// database.OrgScope; this package manages the organizations and decides
+// which one a request acts in.
+package orgs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// ErrNotFound is returned when an organization, team, or member does not
+// exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrNotMember is returned by Resolve when a user asks to act in an
+// organization they don't belong to.
+var ErrNotMember = errors.New("not a member of the organization")
+
+// DefaultOrgID is the organization existing resources belong to.
+const DefaultOrgID = database.DefaultOrgID
+
+// Member roles.
+const (
+	RoleAdmin  = "admin"  // manages the organization's teams and members
+	RoleMember = "member" // uses the organization's resources
+)
+
+// ServerAdminRole is the auth role that may act in, and manage, every
+// organization.
+const ServerAdminRole = "admin"
+
+// Resource types that belong to an organization.
+var ResourceTypes = []string{
+	database.OrgResourceProjects,
+	database.OrgResourceProviders,
+	database.OrgResourceAgents,
+	database.OrgResourceBudgets,
+}
+
+var orgIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// Organization is a tenant.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Team is a group of an organization's members.
+type Team struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Member is a user's membership in an organization. A member with a
+// TeamID only sees that team's projects.
+type Member struct {
+	OrgID     string    `json:"org_id"`
+	UserID    string    `json:"user_id"`
+	TeamID    string    `json:"team_id,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Manager manages organizations, teams, and memberships.
+type Manager struct {
+	db  *database.Database
+	now func() time.Time
+}
+
+// NewManager creates an organization manager. It returns nil without a
+// database.
+func NewManager(db *database.Database) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db, now: time.Now}
+}
+
+// CreateOrg creates an organization. IDs are lowercase letters, digits,
+// and dashes. The creator, if given, becomes its first admin.
+func (m *Manager) CreateOrg(id, name, createdBy string) (*Organization, error) {
+	if !orgIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid organization id %q: use 2-63 lowercase letters, digits, and dashes", id)
+	}
+	if name == "" {
+		name = id
+	}
+	existing, err := m.db.GetOrganization(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("organization %s already exists", id)
+	}
+
+	now := m.now().UTC()
+	o := &database.Organization{ID: id, Name: name, CreatedBy: createdBy, CreatedAt: now}
+	if err := m.db.CreateOrganization(o); err != nil {
+		return nil, err
+	}
+	if createdBy != "" {
+		if err := m.db.UpsertOrgMember(&database.OrgMember{OrgID: id, UserID: createdBy, Role: RoleAdmin, CreatedAt: now}); err != nil {
+			return nil, err
+		}
+	}
+	return toOrganization(o), nil
+}
+
+// GetOrg returns an organization.
+func (m *Manager) GetOrg(id string) (*Organization, error) {
+	o, err := m.db.GetOrganization(id)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return nil, fmt.Errorf("organization %s: %w", id, ErrNotFound)
+	}
+	return toOrganization(o), nil
+}
+
+// ListOrgs returns every organization, or with a userID, the ones the user
+// belongs to.
+func (m *Manager) ListOrgs(userID string) ([]*Organization, error) {
+	rows, err := m.db.ListOrganizations(userID)
+	if err != nil {
+		return nil, err
+	}
+	orgs := make([]*Organization, 0, len(rows))
+	for _, o := range rows {
+		orgs = append(orgs, toOrganization(o))
+	}
+	return orgs, nil
+}
+
+// DeleteOrg deletes an empty organization. The default organization can't
+// be deleted.
+func (m *Manager) DeleteOrg(id string) error {
+	if id == DefaultOrgID {
+		return errors.New("the default organization can't be deleted")
+	}
+	if _, err := m.GetOrg(id); err != nil {
+		return err
+	}
+	return m.db.DeleteOrganization(id)
+}
+
+// CreateTeam adds a team to an organization.
+func (m *Manager) CreateTeam(orgID, name string) (*Team, error) {
+	if name == "" {
+		return nil, errors.New("team name is required")
+	}
+	if _, err := m.GetOrg(orgID); err != nil {
+		return nil, err
+	}
+	t := &database.Team{
+		ID:        "team-" + uuid.New().String()[:8],
+		OrgID:     orgID,
+		Name:      name,
+		CreatedAt: m.now().UTC(),
+	}
+	if err := m.db.CreateTeam(t); err != nil {
+		return nil, err
+	}
+	return toTeam(t), nil
+}
+
+// ListTeams returns an organization's teams.
+func (m *Manager) ListTeams(orgID string) ([]*Team, error) {
+	rows, err := m.db.ListTeams(orgID)
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]*Team, 0, len(rows))
+	for _, t := range rows {
+		teams = append(teams, toTeam(t))
+	}
+	return teams, nil
+}
+
+// DeleteTeam deletes a team. Its members and projects stay in the
+// organization without a team.
+func (m *Manager) DeleteTeam(orgID, teamID string) error {
+	if err := m.requireTeam(orgID, teamID); err != nil {
+		return err
+	}
+	return m.db.DeleteTeam(orgID, teamID)
+}
+
+// SetMember adds a user to an organization, or changes their team and
+// role. Role defaults to member.
+func (m *Manager) SetMember(orgID, userID, teamID, role string) (*Member, error) {
+	if userID == "" {
+		return nil, errors.New("user_id is required")
+	}
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleAdmin && role != RoleMember {
+		return nil, fmt.Errorf("invalid member role %q (use %s or %s)", role, RoleAdmin, RoleMember)
+	}
+	if _, err := m.GetOrg(orgID); err != nil {
+		return nil, err
+	}
+	if teamID != "" {
+		if err := m.requireTeam(orgID, teamID); err != nil {
+			return nil, err
+		}
+	}
+	member := &database.OrgMember{OrgID: orgID, UserID: userID, TeamID: teamID, Role: role, CreatedAt: m.now().UTC()}
+	if existing, err := m.db.GetOrgMember(orgID, userID); err != nil {
+		return nil, err
+	} else if existing != nil {
+		member.CreatedAt = existing.CreatedAt
+	}
+	if err := m.db.UpsertOrgMember(member); err != nil {
+		return nil, err
+	}
+	return toMember(member), nil
+}
+
+// GetMember returns a user's membership, or ErrNotFound.
+func (m *Manager) GetMember(orgID, userID string) (*Member, error) {
+	member, err := m.db.GetOrgMember(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("member %s of %s: %w", userID, orgID, ErrNotFound)
+	}
+	return toMember(member), nil
+}
+
+// ListMembers returns an organization's members.
+func (m *Manager) ListMembers(orgID string) ([]*Member, error) {
+	rows, err := m.db.ListOrgMembers(orgID, "")
+	if err != nil {
+		return nil, err
+	}
+	members := make([]*Member, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, toMember(row))
+	}
+	return members, nil
+}
+
+// RemoveMember removes a user from an organization.
+func (m *Manager) RemoveMember(orgID, userID string) error {
+	if _, err := m.GetMember(orgID, userID); err != nil {
+		return err
+	}
+	return m.db.DeleteOrgMember(orgID, userID)
+}
+
+// Assign moves a resource to an organization and, for projects, one of
+// its teams.
+func (m *Manager) Assign(orgID, resourceType, resourceID, teamID string) error {
+	if _, err := m.GetOrg(orgID); err != nil {
+		return err
+	}
+	if teamID != "" {
+		if resourceType != database.OrgResourceProjects {
+			return fmt.Errorf("only projects can be assigned to a team")
+		}
+		if err := m.requireTeam(orgID, teamID); err != nil {
+			return err
+		}
+	}
+	return m.db.SetResourceOrg(resourceType, resourceID, orgID, teamID)
+}
+
+// Resolve decides which organization, and team, a user's request acts in.
+// requested is the organization the caller asked for, or "" for their
+// default. Server admins may act in any organization and default to the
+// default organization. Users without any membership act in the default
+// organization, as they did before organizations existed; other users
+// default to the first organization they belong to.
+func (m *Manager) Resolve(userID, role, requested string) (orgID, teamID string, err error) {
+	if role == ServerAdminRole {
+		if requested == "" {
+			return DefaultOrgID, "", nil
+		}
+		if _, err := m.GetOrg(requested); err != nil {
+			return "", "", err
+		}
+		return requested, "", nil
+	}
+
+	memberships, err := m.db.ListOrgMembers("", userID)
+	if err != nil {
+		return "", "", err
+	}
+	if len(memberships) == 0 {
+		if requested == "" || requested == DefaultOrgID {
+			return DefaultOrgID, "", nil
+		}
+		return "", "", fmt.Errorf("%w %s", ErrNotMember, requested)
+	}
+	if requested == "" {
+		return memberships[0].OrgID, memberships[0].TeamID, nil
+	}
+	for _, member := range memberships {
+		if member.OrgID == requested {
+			return member.OrgID, member.TeamID, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w %s", ErrNotMember, requested)
+}
+
+// CanAdmin reports whether a user may manage an organization's teams and
+// members: server admins and the organization's admins can.
+func (m *Manager) CanAdmin(orgID, userID, role string) bool {
+	if role == ServerAdminRole {
+		return true
+	}
+	member, err := m.db.GetOrgMember(orgID, userID)
+	return err == nil && member != nil && member.Role == RoleAdmin
+}
+
+// Scope returns the database scope requests in an organization are checked
+// against.
+func (m *Manager) Scope(orgID, teamID string) *database.OrgScope {
+	return m.db.Scope(orgID, teamID)
+}
+
+func (m *Manager) requireTeam(orgID, teamID string) error {
+	t, err := m.db.GetTeam(orgID, teamID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("team %s of %s: %w", teamID, orgID, ErrNotFound)
+	}
+	return nil
+}
+
+func toOrganization(o *database.Organization) *Organization {
+	return &Organization{ID: o.ID, Name: o.Name, CreatedBy: o.CreatedBy, CreatedAt: o.CreatedAt}
+}
+
+func toTeam(t *database.Team) *Team {
+	return &Team{ID: t.ID, OrgID: t.OrgID, Name: t.Name, CreatedAt: t.CreatedAt}
+}
+
+func toMember(m *database.OrgMember) *Member {
+	return &Member{OrgID: m.OrgID, UserID: m.UserID, TeamID: m.TeamID, Role: m.Role, CreatedAt: m.CreatedAt}
+}
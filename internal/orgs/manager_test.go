@@ -0,0 +1,218 @@
+package orgs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func newTestManager(t *testing.T) (*Manager, *database.Database) {
+	t.Helper()
+	db := dbtest.New(t)
+	m := NewManager(db)
+	m.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return m, db
+}
+
+func TestCreateOrg(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	if _, err := m.GetOrg(DefaultOrgID); err != nil {
+		t.Fatalf("default organization should exist: %v", err)
+	}
+	org, err := m.CreateOrg("acme", "Acme", "user-alice")
+	if err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	if org.Name != "Acme" || org.CreatedBy != "user-alice" {
+		t.Errorf("org = %+v", org)
+	}
+	member, err := m.GetMember("acme", "user-alice")
+	if err != nil || member.Role != RoleAdmin {
+		t.Errorf("creator membership = %+v, %v; want admin", member, err)
+	}
+	if _, err := m.CreateOrg("acme", "", ""); err == nil {
+		t.Error("duplicate organization should fail")
+	}
+	for _, id := range []string{"", "A", "Acme Corp", "-acme"} {
+		if _, err := m.CreateOrg(id, "", ""); err == nil {
+			t.Errorf("CreateOrg(%q) should fail", id)
+		}
+	}
+
+	mine, err := m.ListOrgs("user-alice")
+	if err != nil || len(mine) != 1 || mine[0].ID != "acme" {
+		t.Errorf("ListOrgs(user-alice) = %+v, %v", mine, err)
+	}
+	all, _ := m.ListOrgs("")
+	if len(all) != 2 {
+		t.Errorf("ListOrgs() returned %d organizations, want 2", len(all))
+	}
+
+	if err := m.DeleteOrg(DefaultOrgID); err == nil {
+		t.Error("deleting the default organization should fail")
+	}
+	if err := m.DeleteOrg("acme"); err != nil {
+		t.Fatalf("DeleteOrg: %v", err)
+	}
+	if _, err := m.GetOrg("acme"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetOrg after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTeamsAndMembers(t *testing.T) {
+	m, _ := newTestManager(t)
+	if _, err := m.CreateOrg("acme", "Acme", ""); err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	team, err := m.CreateTeam("acme", "platform")
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	if _, err := m.CreateTeam("missing", "platform"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CreateTeam in a missing org = %v, want ErrNotFound", err)
+	}
+
+	if _, err := m.SetMember("acme", "user-bob", team.ID, ""); err != nil {
+		t.Fatalf("SetMember: %v", err)
+	}
+	if _, err := m.SetMember("acme", "user-bob", "team-nope", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetMember with a missing team = %v, want ErrNotFound", err)
+	}
+	if _, err := m.SetMember("acme", "user-bob", "", "owner"); err == nil {
+		t.Error("SetMember with an invalid role should fail")
+	}
+	members, _ := m.ListMembers("acme")
+	if len(members) != 1 || members[0].Role != RoleMember || members[0].TeamID != team.ID {
+		t.Errorf("members = %+v", members)
+	}
+
+	if err := m.DeleteTeam("acme", team.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	member, _ := m.GetMember("acme", "user-bob")
+	if member == nil || member.TeamID != "" {
+		t.Errorf("member after team delete = %+v, want no team", member)
+	}
+	if err := m.RemoveMember("acme", "user-bob"); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if err := m.RemoveMember("acme", "user-bob"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second RemoveMember = %v, want ErrNotFound", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	m, _ := newTestManager(t)
+	if _, err := m.CreateOrg("acme", "Acme", ""); err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	if _, err := m.CreateOrg("globex", "Globex", ""); err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	team, _ := m.CreateTeam("acme", "platform")
+	if _, err := m.SetMember("acme", "user-bob", team.ID, RoleMember); err != nil {
+		t.Fatalf("SetMember: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		userID    string
+		role      string
+		requested string
+		wantOrg   string
+		wantTeam  string
+		wantErr   error
+	}{
+		{"admin default", "admin", ServerAdminRole, "", DefaultOrgID, "", nil},
+		{"admin any org", "admin", ServerAdminRole, "globex", "globex", "", nil},
+		{"admin missing org", "admin", ServerAdminRole, "nope", "", "", ErrNotFound},
+		{"no memberships", "user-carol", "user", "", DefaultOrgID, "", nil},
+		{"no memberships other org", "user-carol", "user", "acme", "", "", ErrNotMember},
+		{"member default", "user-bob", "user", "", "acme", team.ID, nil},
+		{"member requested", "user-bob", "user", "acme", "acme", team.ID, nil},
+		{"member other org", "user-bob", "user", "globex", "", "", ErrNotMember},
+		{"member default org", "user-bob", "user", DefaultOrgID, "", "", ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID, teamID, err := m.Resolve(tt.userID, tt.role, tt.requested)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if orgID != tt.wantOrg || teamID != tt.wantTeam {
+				t.Errorf("Resolve = %q, %q; want %q, %q", orgID, teamID, tt.wantOrg, tt.wantTeam)
+			}
+		})
+	}
+
+	if !m.CanAdmin("acme", "admin", ServerAdminRole) || m.CanAdmin("acme", "user-bob", "user") {
+		t.Error("only server admins and org admins can administer an organization")
+	}
+}
+
+func TestScope(t *testing.T) {
+	m, db := newTestManager(t)
+	if _, err := m.CreateOrg("acme", "Acme", ""); err != nil {
+		t.Fatalf("CreateOrg: %v", err)
+	}
+	platform, _ := m.CreateTeam("acme", "platform")
+	web, _ := m.CreateTeam("acme", "web")
+
+	for _, p := range []*models.Project{
+		{ID: "proj-default", Name: "Default"},
+		{ID: "proj-acme", Name: "Acme"},
+		{ID: "proj-platform", Name: "Platform"},
+		{ID: "proj-web", Name: "Web"},
+	} {
+		if err := db.UpsertProject(p); err != nil {
+			t.Fatalf("UpsertProject: %v", err)
+		}
+	}
+	for id, teamID := range map[string]string{"proj-acme": "", "proj-platform": platform.ID, "proj-web": web.ID} {
+		if err := m.Assign("acme", database.OrgResourceProjects, id, teamID); err != nil {
+			t.Fatalf("Assign(%s): %v", id, err)
+		}
+	}
+	if err := m.Assign("acme", database.OrgResourceProviders, "prov-1", platform.ID); err == nil {
+		t.Error("assigning a provider to a team should fail")
+	}
+
+	acme := m.Scope("acme", "")
+	if err := acme.Check(database.OrgResourceProjects, "proj-web"); err != nil {
+		t.Errorf("org-wide scope should see team projects: %v", err)
+	}
+	if err := acme.Check(database.OrgResourceProjects, "proj-default"); !errors.Is(err, database.ErrCrossOrg) {
+		t.Errorf("Check(other org) = %v, want ErrCrossOrg", err)
+	}
+	if err := m.Scope(DefaultOrgID, "").Check(database.OrgResourceProjects, "proj-missing"); err != nil {
+		t.Errorf("resources outside the database belong to the default org: %v", err)
+	}
+
+	ids := []string{"proj-default", "proj-acme", "proj-platform", "proj-web"}
+	visible, err := m.Scope("acme", platform.ID).Visible(database.OrgResourceProjects, ids)
+	if err != nil {
+		t.Fatalf("Visible: %v", err)
+	}
+	if len(visible) != 2 || !visible["proj-acme"] || !visible["proj-platform"] {
+		t.Errorf("platform team sees %v, want proj-acme and proj-platform", visible)
+	}
+	visible, _ = m.Scope(DefaultOrgID, "").Visible(database.OrgResourceProjects, ids)
+	if len(visible) != 1 || !visible["proj-default"] {
+		t.Errorf("default org sees %v, want proj-default", visible)
+	}
+
+	if err := m.DeleteOrg("acme"); err == nil {
+		t.Error("deleting an organization that still owns projects should fail")
+	}
+}
@@ -346,6 +346,50 @@ func (m *Manager) ReopenProject(projectID, authorID, comment string) error {
 	return nil
 }
 
+// ArchiveProject marks a project archived and returns the status it had,
+// so that UnarchiveProject can restore it.
+func (m *Manager) ArchiveProject(projectID string) (models.ProjectStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	project, ok := m.projects[projectID]
+	if !ok {
+		return "", fmt.Errorf("project not found: %s", projectID)
+	}
+	if project.IsPerpetual {
+		return "", fmt.Errorf("cannot archive perpetual project: %s", project.Name)
+	}
+	if project.Status == models.ProjectStatusArchived {
+		return "", fmt.Errorf("project already archived: %s", projectID)
+	}
+
+	prior := project.Status
+	project.Status = models.ProjectStatusArchived
+	project.UpdatedAt = time.Now()
+	return prior, nil
+}
+
+// UnarchiveProject returns an archived project to status.
+func (m *Manager) UnarchiveProject(projectID string, status models.ProjectStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	project, ok := m.projects[projectID]
+	if !ok {
+		return fmt.Errorf("project not found: %s", projectID)
+	}
+	if project.Status != models.ProjectStatusArchived {
+		return fmt.Errorf("project is not archived: %s", projectID)
+	}
+
+	if status == "" || status == models.ProjectStatusArchived {
+		status = models.ProjectStatusOpen
+	}
+	project.Status = status
+	project.UpdatedAt = time.Now()
+	return nil
+}
+
 // AddComment adds a comment to a project
 func (m *Manager) AddComment(projectID, authorID, comment string) (*models.ProjectComment, error) {
 	m.mu.Lock()
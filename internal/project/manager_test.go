@@ -244,6 +244,39 @@ func TestReopenNotClosed(t *testing.T) {
 	}
 }
 
+func TestArchiveProject(t *testing.T) {
+	manager, project := createTestProject(t, "Test Project")
+	if err := manager.CloseProject(project.ID, "agent-1", ""); err != nil {
+		t.Fatalf("CloseProject failed: %v", err)
+	}
+
+	prior, err := manager.ArchiveProject(project.ID)
+	if err != nil {
+		t.Fatalf("ArchiveProject failed: %v", err)
+	}
+	if prior != models.ProjectStatusClosed || project.Status != models.ProjectStatusArchived {
+		t.Errorf("prior %s, status %s; want closed, archived", prior, project.Status)
+	}
+	if _, err := manager.ArchiveProject(project.ID); err == nil {
+		t.Error("Expected error when archiving already archived project")
+	}
+
+	if err := manager.UnarchiveProject(project.ID, prior); err != nil {
+		t.Fatalf("UnarchiveProject failed: %v", err)
+	}
+	if project.Status != models.ProjectStatusClosed {
+		t.Errorf("status after unarchive = %s, want closed", project.Status)
+	}
+	if err := manager.UnarchiveProject(project.ID, prior); err == nil {
+		t.Error("Expected error when unarchiving project that isn't archived")
+	}
+
+	project.IsPerpetual = true
+	if _, err := manager.ArchiveProject(project.ID); err == nil {
+		t.Error("Expected error when archiving perpetual project")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // UpdateProject tests
 // ---------------------------------------------------------------------------
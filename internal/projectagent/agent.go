@@ -14,7 +14,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jordanhubbard/loom/internal/agentpki"
 	"github.com/jordanhubbard/loom/internal/messagebus"
+	"github.com/jordanhubbard/loom/internal/sandbox"
 	"github.com/jordanhubbard/loom/internal/swarm"
 	"github.com/jordanhubbard/loom/pkg/messages"
 )
@@ -39,6 +41,12 @@ type Config struct {
 	PersonaPath       string // Path to persona instructions file
 	ActionLoopEnabled bool   // Whether to use multi-turn action loop
 	MaxLoopIterations int    // Max action loop iterations (default: 20)
+
+	// Client certificate for mTLS to the control plane (optional). The
+	// files are re-read when rotated.
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
 }
 
 // Agent is a full-featured agent service that runs inside a project container.
@@ -51,6 +59,7 @@ type Agent struct {
 	messageBus   *messagebus.NatsMessageBus
 	swarmMgr     *swarm.Manager // announces this agent to the control plane via NATS swarm
 	resultStore  sync.Map       // taskID -> *TaskResult, for /results/{taskID} polling
+	sandbox      *sandbox.Sandbox
 
 	role                string // cached from config
 	personaInstructions string
@@ -107,7 +116,8 @@ func New(config Config) (*Agent, error) {
 	}
 
 	agent := &Agent{
-		config: config,
+		config:  config,
+		sandbox: sandbox.New(nil),
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -115,6 +125,17 @@ func New(config Config) (*Agent, error) {
 		role:         config.Role,
 	}
 
+	if config.TLSCertFile != "" {
+		tlsConfig, err := agentpki.ClientTLSConfig(config.TLSCertFile, config.TLSKeyFile, config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		agent.httpClient.Transport = transport
+		log.Printf("Using client certificate %s for the control plane", config.TLSCertFile)
+	}
+
 	// Load persona instructions from file if specified
 	if config.PersonaPath != "" {
 		data, err := readFileContent(config.PersonaPath)
@@ -304,6 +325,12 @@ func (a *Agent) handleExec(w http.ResponseWriter, r *http.Request) {
 		Command    string `json:"command"`
 		WorkingDir string `json:"working_dir"`
 		Timeout    int    `json:"timeout"`
+		// Limits, when sent, runs the command in the sandbox with these
+		// resource limits.
+		Limits *sandbox.Limits `json:"limits"`
+		// Env is added to the command's environment, e.g. the secrets
+		// granted to the project.
+		Env map[string]string `json:"env"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
@@ -329,13 +356,32 @@ func (a *Agent) handleExec(w http.ResponseWriter, r *http.Request) {
 
 	cmd := exec.CommandContext(ctx, "bash", "-c", req.Command)
 	cmd.Dir = workDir
+	if len(req.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range req.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	startTime := time.Now()
-	runErr := cmd.Run()
+	var runErr error
+	var report *sandbox.Report
+	if req.Limits != nil {
+		limits := *req.Limits
+		limits.TimeoutSeconds = timeout
+		var rep sandbox.Report
+		rep, runErr = a.sandbox.Run(ctx, cmd, limits)
+		report = &rep
+		if rep.Exceeded != "" {
+			stderr.WriteString("\n[loom] " + rep.String() + "\n")
+		}
+	} else {
+		runErr = cmd.Run()
+	}
 	durationMs := time.Since(startTime).Milliseconds()
 
 	exitCode := 0
@@ -356,6 +402,7 @@ func (a *Agent) handleExec(w http.ResponseWriter, r *http.Request) {
 		"exit_code":   exitCode,
 		"duration_ms": durationMs,
 		"success":     exitCode == 0,
+		"sandbox":     report,
 	})
 }
 
@@ -216,6 +216,53 @@ func TestHandleExec_FailedCommand(t *testing.T) {
 	}
 }
 
+func TestHandleExec_Sandboxed(t *testing.T) {
+	agent := newTestAgent(t)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"command": "echo hello",
+		"timeout": 5,
+		"limits":  map[string]interface{}{"max_file_size_mb": 64},
+	})
+	req := httptest.NewRequest("POST", "/exec", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	agent.handleExec(w, req)
+
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp["success"] != true {
+		t.Errorf("expected success, got %v", resp)
+	}
+	report, ok := resp["sandbox"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a sandbox report, got %v", resp["sandbox"])
+	}
+	if report["exceeded"] != nil {
+		t.Errorf("expected no limit exceeded, got %v", report["exceeded"])
+	}
+}
+
+func TestHandleExec_Env(t *testing.T) {
+	agent := newTestAgent(t)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"command": "echo $API_TOKEN",
+		"timeout": 5,
+		"env":     map[string]string{"API_TOKEN": "tok-123"},
+	})
+	req := httptest.NewRequest("POST", "/exec", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	agent.handleExec(w, req)
+
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp["stdout"] != "tok-123\n" {
+		t.Errorf("expected the env var in stdout, got %v", resp["stdout"])
+	}
+}
+
 func TestRegisterHandlers(t *testing.T) {
 	agent := newTestAgent(t)
 	mux := http.NewServeMux()
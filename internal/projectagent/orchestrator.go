@@ -39,6 +39,11 @@ type OrchestratorConfig struct {
 	// Action loop settings (applied to all roles)
 	ActionLoopEnabled bool
 	MaxLoopIterations int
+
+	// Client certificate for mTLS to the control plane (optional)
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
 }
 
 // InContainerOrchestrator manages multiple role-based agents within a single project container.
@@ -95,6 +100,9 @@ func (o *InContainerOrchestrator) Start(ctx context.Context) error {
 			PersonaPath:       personaPath,
 			ActionLoopEnabled: o.cfg.ActionLoopEnabled,
 			MaxLoopIterations: o.cfg.MaxLoopIterations,
+			TLSCertFile:       o.cfg.TLSCertFile,
+			TLSKeyFile:        o.cfg.TLSKeyFile,
+			TLSCAFile:         o.cfg.TLSCAFile,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create agent for role %s: %w", role, err)
@@ -0,0 +1,387 @@
+package projectagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/jordanhubbard/loom/pkg/messages"
+)
+
+// errRevoked is returned when the control plane rejects the agent's
+// credential; reconnecting will not help.
+var errRevoked = errors.New("credential rejected by the control plane; the agent was revoked or must enroll again")
+
+// Reconnect backoff of a remote agent.
+const (
+	remoteBackoffBase = time.Second
+	remoteBackoffMax  = time.Minute
+)
+
+// RemoteConfig configures remote mode, in which the agent runs outside the
+// control plane's network (a laptop, an on-prem GPU box), enrolls with a
+// one-time token and pulls beads of its project over an outbound
+// WebSocket instead of being sent tasks.
+type RemoteConfig struct {
+	EnrollToken    string   // enrollment token; only needed until a credential is stored
+	CredentialFile string   // where the credential is kept between runs
+	Name           string   // how the agent is listed; defaults to the host name
+	Roles          []string // personas whose beads the agent takes
+	Capabilities   []string // when set, only beads tagged with one of these are taken
+}
+
+// remoteClient is the state of a remote agent across reconnects.
+type remoteClient struct {
+	a          *Agent
+	rc         RemoteConfig
+	credential string
+
+	mu        sync.Mutex
+	conn      *websocket.Conn         // current connection, nil between connections
+	busy      bool                    // a task is running
+	requested bool                    // a work request is unanswered
+	pending   *messages.ResultMessage // result not yet delivered
+}
+
+// RunRemote runs the agent in remote mode until ctx is cancelled or its
+// credential is rejected. It enrolls first if no credential is stored.
+func (a *Agent) RunRemote(ctx context.Context, rc RemoteConfig) error {
+	if a.config.ProviderEndpoint == "" {
+		return fmt.Errorf("remote mode needs an LLM provider (PROVIDER_ENDPOINT)")
+	}
+	c := &remoteClient{a: a, rc: rc}
+
+	credential, err := c.loadCredential()
+	if err != nil {
+		return err
+	}
+	if credential == "" {
+		if credential, err = c.enroll(ctx); err != nil {
+			return err
+		}
+	}
+	c.credential = credential
+
+	backoff := remoteBackoffBase
+	for {
+		started := time.Now()
+		err := c.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errRevoked) {
+			return err
+		}
+		if time.Since(started) > remoteBackoffMax {
+			backoff = remoteBackoffBase
+		}
+		log.Printf("[Remote] Connection to control plane lost: %v; reconnecting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > remoteBackoffMax {
+			backoff = remoteBackoffMax
+		}
+	}
+}
+
+// loadCredential reads the stored credential, or returns "" if there is
+// none.
+func (c *remoteClient) loadCredential() (string, error) {
+	if c.rc.CredentialFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(c.rc.CredentialFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read credential: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// enroll trades the enrollment token for a credential and stores it.
+func (c *remoteClient) enroll(ctx context.Context) (string, error) {
+	if c.rc.EnrollToken == "" {
+		return "", fmt.Errorf("no stored credential and no enrollment token")
+	}
+	name := c.rc.Name
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"token":        c.rc.EnrollToken,
+		"name":         name,
+		"project_ids":  []string{c.a.config.ProjectID},
+		"roles":        c.rc.Roles,
+		"capabilities": c.rc.Capabilities,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(c.a.config.ControlPlaneURL, "/") + "/api/v1/remote-agents/enroll"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("enrollment failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Agent struct {
+			ID string `json:"id"`
+		} `json:"agent"`
+		Credential string `json:"credential"`
+		Error      string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode != http.StatusCreated || out.Credential == "" {
+		return "", fmt.Errorf("enrollment failed with status %d: %s", resp.StatusCode, out.Error)
+	}
+
+	if c.rc.CredentialFile != "" {
+		if err := os.MkdirAll(filepath.Dir(c.rc.CredentialFile), 0o700); err != nil {
+			return "", fmt.Errorf("failed to store credential: %w", err)
+		}
+		if err := os.WriteFile(c.rc.CredentialFile, []byte(out.Credential+"\n"), 0o600); err != nil {
+			return "", fmt.Errorf("failed to store credential: %w", err)
+		}
+	} else {
+		log.Printf("[Remote] Warning: no credential file set; the agent must enroll again after a restart")
+	}
+	log.Printf("[Remote] Enrolled as %s", out.Agent.ID)
+	return out.Credential, nil
+}
+
+// connectURL returns the WebSocket URL of the control plane.
+func connectURL(controlPlaneURL string) string {
+	u := strings.TrimSuffix(controlPlaneURL, "/") + "/api/v1/remote-agents/connect"
+	switch {
+	case strings.HasPrefix(u, "https://"):
+		return "wss://" + strings.TrimPrefix(u, "https://")
+	case strings.HasPrefix(u, "http://"):
+		return "ws://" + strings.TrimPrefix(u, "http://")
+	}
+	return u
+}
+
+// session runs one connection: it sends heartbeats, asks for work when
+// idle and delivers results, until the connection drops.
+func (c *remoteClient) session(ctx context.Context) error {
+	header := http.Header{"Authorization": []string{"Bearer " + c.credential}}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, connectURL(c.a.config.ControlPlaneURL), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return errRevoked
+		}
+		return err
+	}
+	defer conn.Close()
+
+	sessCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-sessCtx.Done()
+		conn.Close()
+	}()
+
+	var welcome messages.RemoteMessage
+	if err := conn.ReadJSON(&welcome); err != nil {
+		return err
+	}
+	if welcome.Type != messages.RemoteWelcome {
+		return fmt.Errorf("unexpected %s instead of welcome: %s", welcome.Type, welcome.Error)
+	}
+	log.Printf("[Remote] Connected to control plane as %s", welcome.AgentID)
+
+	c.mu.Lock()
+	c.conn = conn
+	c.requested = false
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+	}()
+
+	interval := time.Duration(welcome.HeartbeatSeconds) * time.Second
+	if interval <= 0 {
+		interval = c.a.config.HeartbeatInterval
+	}
+	go c.heartbeats(sessCtx, interval)
+
+	c.flushPending()
+	c.requestWork()
+
+	for {
+		var msg messages.RemoteMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		switch msg.Type {
+		case messages.RemoteTask:
+			c.startTask(ctx, msg.Task)
+		case messages.RemoteNoWork:
+			c.mu.Lock()
+			c.requested = false
+			c.mu.Unlock()
+			retry := time.Duration(msg.RetryAfterSeconds) * time.Second
+			if retry <= 0 {
+				retry = 30 * time.Second
+			}
+			time.AfterFunc(retry, c.requestWork)
+		case messages.RemoteAck:
+			log.Printf("[Remote] Result for bead %s recorded", msg.BeadID)
+		case messages.RemoteError:
+			log.Printf("[Remote] Control plane rejected a message (bead %s): %s", msg.BeadID, msg.Error)
+		}
+	}
+}
+
+// send writes a message on the current connection.
+func (c *remoteClient) send(msg *messages.RemoteMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendLocked(msg)
+}
+
+func (c *remoteClient) sendLocked(msg *messages.RemoteMessage) error {
+	if c.conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.conn.WriteJSON(msg)
+}
+
+func (c *remoteClient) heartbeats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			hb := messages.NewRemoteMessage(messages.RemoteHeartbeat)
+			hb.Status = "idle"
+			if c.busy {
+				hb.Status = "busy"
+			}
+			err := c.sendLocked(hb)
+			c.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// requestWork asks for a task unless one is running or already asked for.
+func (c *remoteClient) requestWork() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.busy || c.requested || c.conn == nil {
+		return
+	}
+	if err := c.sendLocked(messages.NewRemoteMessage(messages.RemoteRequestWork)); err == nil {
+		c.requested = true
+	}
+}
+
+// flushPending delivers a result a dropped connection held back.
+func (c *remoteClient) flushPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		return
+	}
+	msg := messages.NewRemoteMessage(messages.RemoteResult)
+	msg.BeadID = c.pending.BeadID
+	msg.Result = c.pending
+	if err := c.sendLocked(msg); err == nil {
+		c.pending = nil
+	}
+}
+
+// startTask runs a task in the background and reports its result. The
+// task outlives the connection it arrived on; if that drops, the result is
+// delivered after reconnecting.
+func (c *remoteClient) startTask(ctx context.Context, task *messages.TaskMessage) {
+	c.mu.Lock()
+	c.requested = false
+	if task == nil || c.busy {
+		c.mu.Unlock()
+		return
+	}
+	c.busy = true
+	c.mu.Unlock()
+
+	go func() {
+		log.Printf("[Remote] Working on bead %s: %s", task.BeadID, task.TaskData.Title)
+		result := c.a.runRemoteTask(ctx, task)
+
+		c.mu.Lock()
+		c.busy = false
+		c.pending = result
+		c.mu.Unlock()
+		c.flushPending()
+		c.requestWork()
+	}()
+}
+
+// runRemoteTask works on a task with the action loop.
+func (a *Agent) runRemoteTask(ctx context.Context, task *messages.TaskMessage) *messages.ResultMessage {
+	start := time.Now()
+	taskCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	title := task.TaskData.Title
+	if title == "" {
+		title = "Task from bead " + task.BeadID
+	}
+	output, err := a.RunActionLoop(taskCtx, title, task.TaskData.Description, ActionLoopConfig{
+		MaxIterations:       a.config.MaxLoopIterations,
+		ProviderEndpoint:    a.config.ProviderEndpoint,
+		ProviderModel:       a.config.ProviderModel,
+		ProviderAPIKey:      a.config.ProviderAPIKey,
+		PersonaInstructions: a.personaInstructions,
+		MemoryContext:       task.TaskData.MemoryContext,
+	})
+
+	duration := time.Since(start)
+	if err != nil {
+		log.Printf("[Remote] Bead %s failed: %v", task.BeadID, err)
+		return messages.TaskFailed(task.ProjectID, task.BeadID, task.AssignedTo, messages.ResultData{
+			Status:   "failure",
+			Output:   output,
+			Error:    err.Error(),
+			Duration: duration.Milliseconds(),
+		}, task.CorrelationID)
+	}
+	log.Printf("[Remote] Bead %s completed in %s", task.BeadID, duration)
+	return messages.TaskCompleted(task.ProjectID, task.BeadID, task.AssignedTo, messages.ResultData{
+		Status:   "success",
+		Output:   output,
+		Duration: duration.Milliseconds(),
+	}, task.CorrelationID)
+}
@@ -0,0 +1,145 @@
+package projectagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/jordanhubbard/loom/pkg/messages"
+)
+
+func TestConnectURL(t *testing.T) {
+	cases := map[string]string{
+		"http://loom:8080":      "ws://loom:8080/api/v1/remote-agents/connect",
+		"https://loom.example/": "wss://loom.example/api/v1/remote-agents/connect",
+		"ws://loom:8080":        "ws://loom:8080/api/v1/remote-agents/connect",
+	}
+	for in, want := range cases {
+		if got := connectURL(in); got != want {
+			t.Errorf("connectURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRunRemote_RequiresProvider(t *testing.T) {
+	a, _ := New(Config{ProjectID: "proj-1", ControlPlaneURL: "http://localhost:8080"})
+	if err := a.RunRemote(context.Background(), RemoteConfig{EnrollToken: "tok"}); err == nil {
+		t.Error("expected an error without a provider")
+	}
+}
+
+// fakeControlPlane enrolls one agent and, on its connection, answers the
+// first work request with no_work.
+func fakeControlPlane(requests chan<- string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/remote-agents/enroll", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["token"] != "good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid or expired enrollment token"})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"agent":      map[string]string{"id": "ra-1"},
+			"credential": "ra-1.secret",
+		})
+	})
+	mux.HandleFunc("/api/v1/remote-agents/connect", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ra-1.secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		welcome := messages.NewRemoteMessage(messages.RemoteWelcome)
+		welcome.AgentID = "ra-1"
+		welcome.HeartbeatSeconds = 60
+		_ = conn.WriteJSON(welcome)
+		for {
+			var msg messages.RemoteMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			requests <- msg.Type
+			if msg.Type == messages.RemoteRequestWork {
+				reply := messages.NewRemoteMessage(messages.RemoteNoWork)
+				reply.RetryAfterSeconds = 3600
+				_ = conn.WriteJSON(reply)
+			}
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRunRemote_EnrollsAndRequestsWork(t *testing.T) {
+	requests := make(chan string, 10)
+	srv := fakeControlPlane(requests)
+	defer srv.Close()
+
+	credFile := filepath.Join(t.TempDir(), "remote", "credential")
+	a, _ := New(Config{ProjectID: "proj-1", ControlPlaneURL: srv.URL, ProviderEndpoint: "http://llm.invalid/v1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.RunRemote(ctx, RemoteConfig{EnrollToken: "good-token", CredentialFile: credFile}) }()
+
+	select {
+	case got := <-requests:
+		if got != messages.RemoteRequestWork {
+			t.Errorf("first message = %s, want %s", got, messages.RemoteRequestWork)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent never asked for work")
+	}
+	cancel()
+	<-done
+
+	data, err := os.ReadFile(credFile)
+	if err != nil || strings.TrimSpace(string(data)) != "ra-1.secret" {
+		t.Errorf("stored credential = %q (%v), want ra-1.secret", data, err)
+	}
+	if info, err := os.Stat(credFile); err == nil && info.Mode().Perm() != 0o600 {
+		t.Errorf("credential file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRunRemote_RejectedCredentialStops(t *testing.T) {
+	srv := fakeControlPlane(make(chan string, 10))
+	defer srv.Close()
+
+	credFile := filepath.Join(t.TempDir(), "credential")
+	if err := os.WriteFile(credFile, []byte("ra-1.revoked\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := New(Config{ProjectID: "proj-1", ControlPlaneURL: srv.URL, ProviderEndpoint: "http://llm.invalid/v1"})
+	err := a.RunRemote(context.Background(), RemoteConfig{CredentialFile: credFile})
+	if !errors.Is(err, errRevoked) {
+		t.Errorf("err = %v, want errRevoked", err)
+	}
+}
+
+func TestRunRemote_BadEnrollToken(t *testing.T) {
+	srv := fakeControlPlane(make(chan string, 10))
+	defer srv.Close()
+
+	a, _ := New(Config{ProjectID: "proj-1", ControlPlaneURL: srv.URL, ProviderEndpoint: "http://llm.invalid/v1"})
+	err := a.RunRemote(context.Background(), RemoteConfig{EnrollToken: "bad-token"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("err = %v, want an enrollment failure with status 401", err)
+	}
+}
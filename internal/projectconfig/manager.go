@@ -0,0 +1,562 @@
+// Package projectconfig holds per-project overrides of settings that are
+// otherwise global or only set in config.yaml: how many iterations an
+// agent's action loop may run, whether failed readiness checks block
+// dispatch, the bead ID prefix, the commands the build, test and lint
+// actions run, the resource limits of the project's container, and the
+// task executor's concurrency limits, how quickly waiting beads gain
+// priority, the loop detectors' thresholds, and the highest risk of code
+// fix it auto-approves. A project that sets nothing gets the global
+// behaviour.
+package projectconfig
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+)
+
+// ErrInvalid is returned for an unknown key or a value the key does not
+// accept.
+var ErrInvalid = errors.New("invalid project config")
+
+// Setting keys.
+const (
+	KeyMaxLoopIterations = "max_loop_iterations"
+	KeyReadinessMode     = "readiness_mode"
+	KeyBeadPrefix        = "bead_prefix"
+	KeyBuildCommand      = "build_command"
+	KeyTestCommand       = "test_command"
+	KeyLintCommand       = "lint_command"
+	KeyContainerCPUs     = "container_cpus"
+	KeyContainerMemory   = "container_memory"
+
+	// Task executor limits.
+	KeyMaxConcurrentBeads = "max_concurrent_beads"
+	KeyMaxLLMCalls        = "max_llm_calls"
+	KeyClaimInterval      = "claim_interval"
+
+	// Dispatch ordering.
+	KeyPriorityAging = "priority_aging"
+
+	// Loop detection.
+	KeyLoopRepeatedErrors    = "loop_repeated_errors"
+	KeyLoopRepeatedActions   = "loop_repeated_actions"
+	KeyLoopNoProgressEdits   = "loop_no_progress_edits"
+	KeyLoopBuildOscillations = "loop_build_oscillations"
+	KeyLoopDisabledDetectors = "loop_disabled_detectors"
+
+	// Self-audit.
+	KeyAuditChecks            = "audit_checks"
+	KeyAuditCoverageThreshold = "audit_coverage_threshold"
+
+	// Test-failure triage.
+	KeyFlakyTests       = "flaky_tests"
+	KeyFlakyTestRetries = "flaky_test_retries"
+
+	// CI checks of agents' branches.
+	KeyCIProvider = "ci_provider"
+	KeyJenkinsJob = "jenkins_job"
+
+	// Code fix auto-approval.
+	KeyAutoApproveMaxRisk = "auto_approve_max_risk"
+)
+
+// maxLoopIterationsLimit caps max_loop_iterations.
+const maxLoopIterationsLimit = 1000
+
+var beadPrefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,15}$`)
+
+// memoryPattern matches Docker memory sizes such as 512m or 4g.
+var memoryPattern = regexp.MustCompile(`^[1-9][0-9]*[kmg]$`)
+
+// maxContainerCPUs caps container_cpus.
+const maxContainerCPUs = 256
+
+// maxExecutorLimit caps max_concurrent_beads and max_llm_calls.
+const maxExecutorLimit = 100
+
+// claim_interval bounds.
+const (
+	minClaimInterval = time.Second
+	maxClaimInterval = 10 * time.Minute
+)
+
+// maxPriorityAging caps priority_aging.
+const maxPriorityAging = 30 * 24 * time.Hour
+
+// maxLoopThreshold caps the loop detection thresholds.
+const maxLoopThreshold = 50
+
+var detectorNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var auditCheckPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// maxFlakyTestRetries caps flaky_test_retries.
+const maxFlakyTestRetries = 5
+
+// validators check a value and return it normalized.
+var validators = map[string]func(string) (string, error){
+	KeyMaxLoopIterations: func(v string) (string, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLoopIterationsLimit {
+			return "", fmt.Errorf("must be an integer from 1 to %d", maxLoopIterationsLimit)
+		}
+		return strconv.Itoa(n), nil
+	},
+	KeyReadinessMode: func(v string) (string, error) {
+		v = strings.ToLower(v)
+		if v != "block" && v != "warn" {
+			return "", fmt.Errorf("must be block or warn")
+		}
+		return v, nil
+	},
+	KeyBeadPrefix: func(v string) (string, error) {
+		if !beadPrefixPattern.MatchString(v) {
+			return "", fmt.Errorf("must be a letter followed by up to 15 letters, digits, _ or -")
+		}
+		return v, nil
+	},
+	KeyContainerCPUs: func(v string) (string, error) {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0.01 || n > maxContainerCPUs {
+			return "", fmt.Errorf("must be a number of CPUs from 0.01 to %d", maxContainerCPUs)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	},
+	KeyContainerMemory: func(v string) (string, error) {
+		v = strings.ToLower(v)
+		if !memoryPattern.MatchString(v) {
+			return "", fmt.Errorf("must be a size such as 512m or 4g")
+		}
+		return v, nil
+	},
+	KeyMaxConcurrentBeads: executorLimit,
+	KeyMaxLLMCalls:        executorLimit,
+	KeyClaimInterval: func(v string) (string, error) {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minClaimInterval || d > maxClaimInterval {
+			return "", fmt.Errorf("must be a duration from %s to %s", minClaimInterval, maxClaimInterval)
+		}
+		return d.String(), nil
+	},
+	KeyPriorityAging: func(v string) (string, error) {
+		if strings.EqualFold(v, "off") {
+			return "0s", nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || (d != 0 && (d < time.Minute || d > maxPriorityAging)) {
+			return "", fmt.Errorf("must be off or a duration from 1m to %s", maxPriorityAging)
+		}
+		return d.String(), nil
+	},
+	KeyBuildCommand: nonEmpty,
+	KeyTestCommand:  nonEmpty,
+	KeyLintCommand:  nonEmpty,
+
+	KeyLoopRepeatedErrors:    loopThreshold,
+	KeyLoopRepeatedActions:   loopThreshold,
+	KeyLoopNoProgressEdits:   loopThreshold,
+	KeyLoopBuildOscillations: loopThreshold,
+	KeyLoopDisabledDetectors: func(v string) (string, error) {
+		if strings.EqualFold(v, "none") {
+			return "none", nil
+		}
+		var names []string
+		for _, name := range strings.Split(strings.ToLower(v), ",") {
+			name = strings.TrimSpace(name)
+			if !detectorNamePattern.MatchString(name) {
+				return "", fmt.Errorf("must be none or a comma-separated list of detector names")
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ","), nil
+	},
+
+	KeyAuditChecks: func(v string) (string, error) {
+		if strings.EqualFold(v, "none") {
+			return "none", nil
+		}
+		var names []string
+		for _, name := range strings.Split(strings.ToLower(v), ",") {
+			name = strings.TrimSpace(name)
+			if !auditCheckPattern.MatchString(name) {
+				return "", fmt.Errorf("must be none or a comma-separated list of check names")
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ","), nil
+	},
+	KeyAuditCoverageThreshold: func(v string) (string, error) {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil || n < 0 || n > 100 {
+			return "", fmt.Errorf("must be a percentage from 0 to 100")
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	},
+
+	KeyFlakyTests: func(v string) (string, error) {
+		var patterns []string
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				return "", fmt.Errorf("must be a comma-separated list of test name patterns")
+			}
+			patterns = append(patterns, p)
+		}
+		return strings.Join(patterns, ","), nil
+	},
+	KeyFlakyTestRetries: func(v string) (string, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxFlakyTestRetries {
+			return "", fmt.Errorf("must be an integer from 0 to %d", maxFlakyTestRetries)
+		}
+		return strconv.Itoa(n), nil
+	},
+	KeyCIProvider: func(v string) (string, error) {
+		v = strings.ToLower(v)
+		if v != "github" && v != "jenkins" {
+			return "", fmt.Errorf("must be github or jenkins")
+		}
+		return v, nil
+	},
+	KeyAutoApproveMaxRisk: func(v string) (string, error) {
+		v = strings.ToLower(v)
+		if v != "none" && v != "low" && v != "medium" && v != "high" {
+			return "", fmt.Errorf("must be none, low, medium or high")
+		}
+		return v, nil
+	},
+	KeyJenkinsJob: func(v string) (string, error) {
+		v = strings.Trim(v, "/")
+		if v == "" || strings.ContainsAny(v, " ?#") {
+			return "", fmt.Errorf("must be a Jenkins job path such as team/loom")
+		}
+		return v, nil
+	},
+}
+
+func loopThreshold(v string) (string, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxLoopThreshold {
+		return "", fmt.Errorf("must be an integer from 1 to %d", maxLoopThreshold)
+	}
+	return strconv.Itoa(n), nil
+}
+
+func executorLimit(v string) (string, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxExecutorLimit {
+		return "", fmt.Errorf("must be an integer from 1 to %d", maxExecutorLimit)
+	}
+	return strconv.Itoa(n), nil
+}
+
+func nonEmpty(v string) (string, error) {
+	if v == "" {
+		return "", fmt.Errorf("must not be empty")
+	}
+	return v, nil
+}
+
+// Keys returns the setting keys, sorted.
+func Keys() []string {
+	keys := make([]string, 0, len(validators))
+	for k := range validators {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Setting is one override of one project.
+type Setting struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Manager stores project settings. Lookups are cached per project, since
+// the dispatcher and task executor make them for every bead.
+type Manager struct {
+	db  *database.Database
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]map[string]string // project ID -> key -> value
+}
+
+// NewManager creates a project config manager. It returns nil without a
+// database; the lookups of a nil Manager return the global defaults.
+func NewManager(db *database.Database) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db, now: time.Now, cache: make(map[string]map[string]string)}
+}
+
+// Validate checks a value for key and returns it normalized.
+func Validate(key, value string) (string, error) {
+	validate, ok := validators[key]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown key %q (known: %s)", ErrInvalid, key, strings.Join(Keys(), ", "))
+	}
+	value, err := validate(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %v", ErrInvalid, key, err)
+	}
+	return value, nil
+}
+
+// Set validates and stores one of a project's settings.
+func (m *Manager) Set(projectID, key, value, updatedBy string) (*Setting, error) {
+	value, err := Validate(key, value)
+	if err != nil {
+		return nil, err
+	}
+	e := &database.ProjectConfigEntry{
+		ProjectID: projectID,
+		Key:       key,
+		Value:     value,
+		UpdatedBy: updatedBy,
+		UpdatedAt: m.now().UTC(),
+	}
+	if err := m.db.UpsertProjectConfig(e); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	delete(m.cache, projectID)
+	m.mu.Unlock()
+	return &Setting{Key: e.Key, Value: e.Value, UpdatedBy: e.UpdatedBy, UpdatedAt: e.UpdatedAt}, nil
+}
+
+// Unset removes one of a project's settings, returning it to the global
+// default.
+func (m *Manager) Unset(projectID, key string) error {
+	if _, ok := validators[key]; !ok {
+		return fmt.Errorf("%w: unknown key %q", ErrInvalid, key)
+	}
+	if err := m.db.DeleteProjectConfig(projectID, key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	delete(m.cache, projectID)
+	m.mu.Unlock()
+	return nil
+}
+
+// List returns the settings a project overrides, ordered by key.
+func (m *Manager) List(projectID string) ([]*Setting, error) {
+	rows, err := m.db.ListProjectConfig(projectID)
+	if err != nil {
+		return nil, err
+	}
+	settings := make([]*Setting, 0, len(rows))
+	for _, e := range rows {
+		settings = append(settings, &Setting{Key: e.Key, Value: e.Value, UpdatedBy: e.UpdatedBy, UpdatedAt: e.UpdatedAt})
+	}
+	return settings, nil
+}
+
+// Value returns a project's setting for key, or "" when the project does
+// not override it.
+func (m *Manager) Value(projectID, key string) string {
+	if m == nil || projectID == "" {
+		return ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values, ok := m.cache[projectID]
+	if !ok {
+		rows, err := m.db.ListProjectConfig(projectID)
+		if err != nil {
+			log.Printf("[ProjectConfig] Warning: failed to load config for %s: %v", projectID, err)
+			return ""
+		}
+		values = make(map[string]string, len(rows))
+		for _, e := range rows {
+			values[e.Key] = e.Value
+		}
+		m.cache[projectID] = values
+	}
+	return values[key]
+}
+
+// MaxLoopIterations returns how many iterations an agent's action loop may
+// run on the project's beads, or fallback.
+func (m *Manager) MaxLoopIterations(projectID string, fallback int) int {
+	if n, err := strconv.Atoi(m.Value(projectID, KeyMaxLoopIterations)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
+
+// ReadinessMode returns the project's readiness mode, block or warn, or ""
+// when it uses the global mode.
+func (m *Manager) ReadinessMode(projectID string) string {
+	return m.Value(projectID, KeyReadinessMode)
+}
+
+// BeadPrefix returns the project's bead ID prefix, or "".
+func (m *Manager) BeadPrefix(projectID string) string {
+	return m.Value(projectID, KeyBeadPrefix)
+}
+
+// BuildCommand returns the command the build action runs for the project,
+// or "" to detect one.
+func (m *Manager) BuildCommand(projectID string) string {
+	return m.Value(projectID, KeyBuildCommand)
+}
+
+// TestCommand returns the command the test action runs for the project,
+// or "" to use the test runner.
+func (m *Manager) TestCommand(projectID string) string {
+	return m.Value(projectID, KeyTestCommand)
+}
+
+// LintCommand returns the command the lint action runs for the project,
+// or "" to use the linter.
+func (m *Manager) LintCommand(projectID string) string {
+	return m.Value(projectID, KeyLintCommand)
+}
+
+// ContainerCPUs returns how many CPUs the project's container may use, or
+// "" for no limit.
+func (m *Manager) ContainerCPUs(projectID string) string {
+	return m.Value(projectID, KeyContainerCPUs)
+}
+
+// ContainerMemory returns how much memory the project's container may use,
+// or "" for no limit.
+func (m *Manager) ContainerMemory(projectID string) string {
+	return m.Value(projectID, KeyContainerMemory)
+}
+
+// MaxConcurrentBeads returns how many of the project's beads the task
+// executor works on at once, or 0 for the global limit.
+func (m *Manager) MaxConcurrentBeads(projectID string) int {
+	n, _ := strconv.Atoi(m.Value(projectID, KeyMaxConcurrentBeads))
+	return n
+}
+
+// MaxLLMCalls returns how many model calls the project may have in flight,
+// or 0 when only the global limit applies.
+func (m *Manager) MaxLLMCalls(projectID string) int {
+	n, _ := strconv.Atoi(m.Value(projectID, KeyMaxLLMCalls))
+	return n
+}
+
+// ClaimInterval returns how often the project's idle workers look for a
+// bead to claim, or 0 for the global interval.
+func (m *Manager) ClaimInterval(projectID string) time.Duration {
+	d, _ := time.ParseDuration(m.Value(projectID, KeyClaimInterval))
+	return d
+}
+
+// PriorityAging returns how long the project's ready beads wait before
+// their priority is raised a level, 0 when the project turns aging off, and
+// false when it uses the global setting.
+func (m *Manager) PriorityAging(projectID string) (time.Duration, bool) {
+	d, err := time.ParseDuration(m.Value(projectID, KeyPriorityAging))
+	if err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// LoopThreshold returns the project's loop detection threshold for key,
+// one of the KeyLoop counts, or 0 when it uses the global threshold.
+func (m *Manager) LoopThreshold(projectID, key string) int {
+	n, _ := strconv.Atoi(m.Value(projectID, key))
+	return n
+}
+
+// DisabledLoopDetectors returns the loop detectors the project turns off,
+// empty for none, and false when it uses the global list.
+func (m *Manager) DisabledLoopDetectors(projectID string) ([]string, bool) {
+	v := m.Value(projectID, KeyLoopDisabledDetectors)
+	switch v {
+	case "":
+		return nil, false
+	case "none":
+		return []string{}, true
+	}
+	return strings.Split(v, ","), true
+}
+
+// AuditChecks returns the self-audit checks the project runs, empty for
+// none, and false when it uses the global list.
+func (m *Manager) AuditChecks(projectID string) ([]string, bool) {
+	v := m.Value(projectID, KeyAuditChecks)
+	switch v {
+	case "":
+		return nil, false
+	case "none":
+		return []string{}, true
+	}
+	return strings.Split(v, ","), true
+}
+
+// AuditCoverageThreshold returns the project's self-audit coverage
+// threshold in percent, 0 when the project turns the coverage check off,
+// and false when it uses the global threshold.
+func (m *Manager) AuditCoverageThreshold(projectID string) (float64, bool) {
+	n, err := strconv.ParseFloat(m.Value(projectID, KeyAuditCoverageThreshold), 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// FlakyTestPatterns returns the patterns naming the project's known flaky
+// tests.
+func (m *Manager) FlakyTestPatterns(projectID string) []string {
+	v := m.Value(projectID, KeyFlakyTests)
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// FlakyTestRetries returns how often the project retries a test run whose
+// failures are all known flaky tests, and false when it uses the default.
+func (m *Manager) FlakyTestRetries(projectID string) (int, bool) {
+	n, err := strconv.Atoi(m.Value(projectID, KeyFlakyTestRetries))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// CIProvider returns where the project's CI checks are read from: github
+// (the default) or jenkins.
+func (m *Manager) CIProvider(projectID string) string {
+	if v := m.Value(projectID, KeyCIProvider); v != "" {
+		return v
+	}
+	return "github"
+}
+
+// JenkinsJob returns the path of the project's Jenkins multibranch job, or
+// "" when it has none.
+func (m *Manager) JenkinsJob(projectID string) string {
+	return m.Value(projectID, KeyJenkinsJob)
+}
+
+// AutoApproveMaxRisk returns the highest risk of code fix the project
+// auto-approves, none, low, medium or high, or "" when only the
+// auto-approval rules decide.
+func (m *Manager) AutoApproveMaxRisk(projectID string) string {
+	return m.Value(projectID, KeyAutoApproveMaxRisk)
+}
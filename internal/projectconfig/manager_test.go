@@ -0,0 +1,195 @@
+package projectconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	return NewManager(dbtest.New(t))
+}
+
+func TestManager_SetValidates(t *testing.T) {
+	m := newTestManager(t)
+
+	bad := []struct{ key, value string }{
+		{"max_workers", "3"},
+		{KeyMaxLoopIterations, "0"},
+		{KeyMaxLoopIterations, "lots"},
+		{KeyReadinessMode, "strict"},
+		{KeyBeadPrefix, "9lives"},
+		{KeyBuildCommand, "  "},
+		{KeyContainerCPUs, "0"},
+		{KeyContainerCPUs, "many"},
+		{KeyContainerMemory, "4"},
+		{KeyContainerMemory, "4gb"},
+		{KeyMaxConcurrentBeads, "0"},
+		{KeyMaxLLMCalls, "101"},
+		{KeyClaimInterval, "5"},
+		{KeyClaimInterval, "1h"},
+		{KeyPriorityAging, "30s"},
+		{KeyPriorityAging, "never"},
+		{KeyLoopRepeatedActions, "0"},
+		{KeyLoopBuildOscillations, "51"},
+		{KeyLoopDisabledDetectors, "build oscillation"},
+		{KeyAuditChecks, "vet;lint"},
+		{KeyAuditCoverageThreshold, "101"},
+		{KeyFlakyTests, "TestA,,TestB"},
+		{KeyFlakyTestRetries, "6"},
+		{KeyCIProvider, "travis"},
+		{KeyJenkinsJob, "/"},
+		{KeyAutoApproveMaxRisk, "severe"},
+	}
+	for _, tc := range bad {
+		if _, err := m.Set("proj-1", tc.key, tc.value, "admin"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Set(%s=%q): err = %v, want ErrInvalid", tc.key, tc.value, err)
+		}
+	}
+
+	s, err := m.Set("proj-1", KeyReadinessMode, " BLOCK ", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Value != "block" || s.UpdatedBy != "admin" {
+		t.Errorf("setting = %+v, want normalized block by admin", s)
+	}
+
+	if s, err := m.Set("proj-1", KeyContainerMemory, "4G", "admin"); err != nil || s.Value != "4g" {
+		t.Errorf("Set(container_memory=4G) = %+v, %v, want 4g", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyContainerCPUs, "1.50", "admin"); err != nil || s.Value != "1.5" {
+		t.Errorf("Set(container_cpus=1.50) = %+v, %v, want 1.5", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyClaimInterval, "90s", "admin"); err != nil || s.Value != "1m30s" {
+		t.Errorf("Set(claim_interval=90s) = %+v, %v, want 1m30s", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyPriorityAging, "Off", "admin"); err != nil || s.Value != "0s" {
+		t.Errorf("Set(priority_aging=Off) = %+v, %v, want 0s", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyLoopDisabledDetectors, "Build_Oscillation, no_progress_edits", "admin"); err != nil || s.Value != "build_oscillation,no_progress_edits" {
+		t.Errorf("Set(loop_disabled_detectors) = %+v, %v", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyAuditCoverageThreshold, "62.50%", "admin"); err != nil || s.Value != "62.5" {
+		t.Errorf("Set(audit_coverage_threshold=62.50%%) = %+v, %v, want 62.5", s, err)
+	}
+	if s, err := m.Set("proj-1", KeyAutoApproveMaxRisk, "Medium", "admin"); err != nil || m.AutoApproveMaxRisk("proj-1") != "medium" {
+		t.Errorf("Set(auto_approve_max_risk=Medium) = %+v, %v, want medium", s, err)
+	}
+}
+
+func TestManager_Lookups(t *testing.T) {
+	m := newTestManager(t)
+
+	if got := m.MaxLoopIterations("proj-1", 100); got != 100 {
+		t.Errorf("MaxLoopIterations before set = %d, want fallback 100", got)
+	}
+	if _, err := m.Set("proj-1", KeyMaxLoopIterations, "25", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Set("proj-1", KeyTestCommand, "make check", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MaxLoopIterations("proj-1", 100); got != 25 {
+		t.Errorf("MaxLoopIterations = %d, want 25", got)
+	}
+	if got := m.TestCommand("proj-1"); got != "make check" {
+		t.Errorf("TestCommand = %q", got)
+	}
+	if got := m.MaxLoopIterations("proj-2", 100); got != 100 {
+		t.Errorf("other project MaxLoopIterations = %d, want 100", got)
+	}
+	if got := m.MaxConcurrentBeads("proj-3"); got != 0 {
+		t.Errorf("MaxConcurrentBeads before set = %d, want 0", got)
+	}
+	if _, err := m.Set("proj-3", KeyMaxConcurrentBeads, "8", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Set("proj-3", KeyClaimInterval, "2s", ""); err != nil {
+		t.Fatal(err)
+	}
+	if m.MaxConcurrentBeads("proj-3") != 8 || m.ClaimInterval("proj-3") != 2*time.Second || m.MaxLLMCalls("proj-3") != 0 {
+		t.Errorf("executor limits = %d, %s, %d; want 8, 2s, 0",
+			m.MaxConcurrentBeads("proj-3"), m.ClaimInterval("proj-3"), m.MaxLLMCalls("proj-3"))
+	}
+	if _, ok := m.PriorityAging("proj-3"); ok {
+		t.Error("PriorityAging before set should defer to the global setting")
+	}
+	if _, err := m.Set("proj-3", KeyPriorityAging, "24h", ""); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := m.PriorityAging("proj-3"); !ok || d != 24*time.Hour {
+		t.Errorf("PriorityAging = %s, %v; want 24h, true", d, ok)
+	}
+	if _, ok := m.DisabledLoopDetectors("proj-3"); ok {
+		t.Error("DisabledLoopDetectors before set should defer to the global list")
+	}
+	if _, err := m.Set("proj-3", KeyLoopRepeatedActions, "6", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Set("proj-3", KeyLoopDisabledDetectors, "none", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := m.LoopThreshold("proj-3", KeyLoopRepeatedActions); n != 6 || m.LoopThreshold("proj-3", KeyLoopRepeatedErrors) != 0 {
+		t.Errorf("LoopThreshold = %d, want 6 and 0 for unset keys", n)
+	}
+	if d, ok := m.DisabledLoopDetectors("proj-3"); !ok || d == nil || len(d) != 0 {
+		t.Errorf("DisabledLoopDetectors = %v, %v; want none, true", d, ok)
+	}
+	if _, ok := m.AuditChecks("proj-3"); ok {
+		t.Error("AuditChecks before set should defer to the global list")
+	}
+	if _, err := m.Set("proj-3", KeyAuditChecks, "Vet, govulncheck", ""); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := m.AuditChecks("proj-3"); !ok || len(c) != 2 || c[0] != "vet" || c[1] != "govulncheck" {
+		t.Errorf("AuditChecks = %v, %v; want [vet govulncheck], true", c, ok)
+	}
+	if _, err := m.Set("proj-3", KeyAuditCoverageThreshold, "0", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := m.AuditCoverageThreshold("proj-3"); !ok || n != 0 {
+		t.Errorf("AuditCoverageThreshold = %v, %v; want 0, true", n, ok)
+	}
+	if _, err := m.Set("proj-3", KeyFlakyTests, "TestNetwork*, pkg/cache.TestEvict", ""); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.FlakyTestPatterns("proj-3"); len(p) != 2 || p[1] != "pkg/cache.TestEvict" {
+		t.Errorf("FlakyTestPatterns = %v", p)
+	}
+	if _, ok := m.FlakyTestRetries("proj-3"); ok {
+		t.Error("FlakyTestRetries before set should defer to the default")
+	}
+	if got := m.CIProvider("proj-3"); got != "github" {
+		t.Errorf("CIProvider before set = %q, want github", got)
+	}
+	if _, err := m.Set("proj-3", KeyJenkinsJob, "/team/loom/", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.JenkinsJob("proj-3"); got != "team/loom" {
+		t.Errorf("JenkinsJob = %q, want team/loom", got)
+	}
+
+	settings, err := m.List("proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 2 || settings[0].Key != KeyMaxLoopIterations {
+		t.Errorf("List = %+v", settings)
+	}
+
+	if err := m.Unset("proj-1", KeyMaxLoopIterations); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.MaxLoopIterations("proj-1", 100); got != 100 {
+		t.Errorf("MaxLoopIterations after unset = %d, want 100", got)
+	}
+
+	var nilManager *Manager
+	if nilManager.BuildCommand("proj-1") != "" || nilManager.MaxLoopIterations("proj-1", 7) != 7 {
+		t.Error("nil manager does not fall back to defaults")
+	}
+}
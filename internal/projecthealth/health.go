@@ -0,0 +1,274 @@
+// Package projecthealth scores how healthy a project is from the signals
+// loom already tracks: dispatch readiness, open P0 beads, SLA breaches,
+// provider and container health, and the recent error rate of its model
+// requests. Each signal is a component scored 0-100; the project's score
+// is their weighted average.
+package projecthealth
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Status summarizes a score.
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"   // 80 and up
+	StatusDegraded  Status = "degraded"  // 50 to 79
+	StatusUnhealthy Status = "unhealthy" // below 50
+	// StatusUnknown marks a component loom has no data for. It doesn't
+	// count towards the project's score.
+	StatusUnknown Status = "unknown"
+)
+
+// statusOf maps a score to its status.
+func statusOf(score int) Status {
+	switch {
+	case score >= 80:
+		return StatusHealthy
+	case score >= 50:
+		return StatusDegraded
+	default:
+		return StatusUnhealthy
+	}
+}
+
+// Component names.
+const (
+	ComponentReadiness = "readiness"
+	ComponentP0        = "p0_beads"
+	ComponentSLA       = "sla"
+	ComponentProviders = "providers"
+	ComponentContainer = "container"
+	ComponentErrors    = "error_rate"
+)
+
+// weights says how much each component counts towards the project score.
+var weights = map[string]int{
+	ComponentReadiness: 20,
+	ComponentP0:        20,
+	ComponentSLA:       20,
+	ComponentProviders: 15,
+	ComponentContainer: 10,
+	ComponentErrors:    15,
+}
+
+// ErrorWindow is how far back the error rate looks.
+const ErrorWindow = 24 * time.Hour
+
+// Component is one signal of a project's health.
+type Component struct {
+	Name    string   `json:"name"`
+	Score   int      `json:"score"`
+	Weight  int      `json:"weight"`
+	Status  Status   `json:"status"`
+	Summary string   `json:"summary"`
+	Issues  []string `json:"issues,omitempty"`
+}
+
+// Report is a project's health score with its breakdown.
+type Report struct {
+	ProjectID   string      `json:"project_id"`
+	Score       int         `json:"score"`
+	Status      Status      `json:"status"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Components  []Component `json:"components"`
+}
+
+// SLAState counts a project's open beads by SLA state. Nil when SLAs are
+// not available.
+type SLAState struct {
+	Tracked        int
+	Breached       int
+	AtRisk         int
+	RecentBreaches int // Recorded over the past week
+}
+
+// ProviderState is a registered model provider.
+type ProviderState struct {
+	ID      string
+	Healthy bool
+	Error   string
+}
+
+// ContainerState is the project's container. Nil when the project doesn't
+// run in one.
+type ContainerState struct {
+	Running   bool
+	Healthy   bool
+	Restarts  int
+	LastError string
+}
+
+// Inputs are the signals a report is computed from.
+type Inputs struct {
+	ProjectID       string
+	Ready           bool
+	ReadinessIssues []string
+	OpenP0          []string // IDs of open P0 beads
+	SLA             *SLAState
+	// Providers is nil when loom can't tell which providers exist.
+	Providers []ProviderState
+	Container *ContainerState
+	// Requests and Errors count the project's model requests over the
+	// ErrorWindow and how many of them failed.
+	Requests int
+	Errors   int
+}
+
+// Build scores a project from its inputs.
+func Build(in Inputs, now time.Time) *Report {
+	r := &Report{ProjectID: in.ProjectID, GeneratedAt: now.UTC()}
+	r.Components = []Component{
+		readiness(in),
+		p0Beads(in),
+		slaComponent(in),
+		providers(in),
+		container(in),
+		errorRate(in),
+	}
+
+	var total, weight int
+	for i := range r.Components {
+		c := &r.Components[i]
+		c.Weight = weights[c.Name]
+		if c.Status == StatusUnknown {
+			continue
+		}
+		c.Score = max(0, min(100, c.Score))
+		c.Status = statusOf(c.Score)
+		total += c.Score * c.Weight
+		weight += c.Weight
+	}
+	if weight == 0 {
+		r.Status = StatusUnknown
+		return r
+	}
+	r.Score = int(math.Round(float64(total) / float64(weight)))
+	r.Status = statusOf(r.Score)
+	return r
+}
+
+func readiness(in Inputs) Component {
+	c := Component{Name: ComponentReadiness, Score: 100, Summary: "ready for dispatch"}
+	if !in.Ready {
+		c.Score = 0
+		c.Summary = "not ready for dispatch"
+		c.Issues = in.ReadinessIssues
+	}
+	return c
+}
+
+// p0Beads loses 40 points per open P0 bead.
+func p0Beads(in Inputs) Component {
+	n := len(in.OpenP0)
+	c := Component{Name: ComponentP0, Score: 100 - 40*n, Summary: plural(n, "open P0 bead")}
+	c.Issues = in.OpenP0
+	return c
+}
+
+// slaComponent loses points in proportion to the tracked beads that
+// breached their SLA, and half as much for those at risk.
+func slaComponent(in Inputs) Component {
+	c := Component{Name: ComponentSLA}
+	if in.SLA == nil {
+		c.Status = StatusUnknown
+		c.Summary = "SLAs not available"
+		return c
+	}
+	s := in.SLA
+	c.Score = 100
+	if s.Tracked == 0 {
+		c.Summary = "no open beads under an SLA"
+	} else {
+		c.Score = 100 - (100*s.Breached+50*s.AtRisk)/s.Tracked
+		c.Summary = fmt.Sprintf("%d of %d beads breached, %d at risk", s.Breached, s.Tracked, s.AtRisk)
+	}
+	if s.RecentBreaches > 0 {
+		c.Issues = []string{plural(s.RecentBreaches, "breach") + " in the past week"}
+	}
+	return c
+}
+
+// providers scores the share of registered providers that are healthy.
+func providers(in Inputs) Component {
+	c := Component{Name: ComponentProviders}
+	if in.Providers == nil {
+		c.Status = StatusUnknown
+		c.Summary = "providers not available"
+		return c
+	}
+	if len(in.Providers) == 0 {
+		c.Summary = "no providers registered"
+		return c
+	}
+	healthy := 0
+	for _, p := range in.Providers {
+		if p.Healthy {
+			healthy++
+			continue
+		}
+		issue := p.ID + " is unhealthy"
+		if p.Error != "" {
+			issue += ": " + p.Error
+		}
+		c.Issues = append(c.Issues, issue)
+	}
+	c.Score = 100 * healthy / len(in.Providers)
+	c.Summary = fmt.Sprintf("%d of %d providers healthy", healthy, len(in.Providers))
+	return c
+}
+
+func container(in Inputs) Component {
+	c := Component{Name: ComponentContainer}
+	ct := in.Container
+	switch {
+	case ct == nil:
+		c.Status = StatusUnknown
+		c.Summary = "no project container"
+		return c
+	case !ct.Running:
+		c.Summary = "container not running"
+	case !ct.Healthy:
+		c.Score = 40
+		c.Summary = "container failing health checks"
+	default:
+		c.Score = 100
+		c.Summary = "container healthy"
+	}
+	if ct.Restarts > 0 {
+		c.Summary += fmt.Sprintf(", restarted %s", plural(ct.Restarts, "time"))
+	}
+	if ct.LastError != "" {
+		c.Issues = []string{ct.LastError}
+	}
+	return c
+}
+
+// errorRate loses two points per percent of failed requests, so half the
+// requests failing scores 0.
+func errorRate(in Inputs) Component {
+	c := Component{Name: ComponentErrors, Score: 100}
+	window := fmt.Sprintf("%dh", int(ErrorWindow.Hours()))
+	if in.Requests == 0 {
+		c.Summary = "no requests in the last " + window
+		return c
+	}
+	rate := float64(in.Errors) / float64(in.Requests)
+	c.Score = int(math.Round(100 - 200*rate))
+	c.Summary = fmt.Sprintf("%.1f%% of %d requests failed in the last %s", 100*rate, in.Requests, window)
+	return c
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "ch") {
+		return fmt.Sprintf("%d %ses", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
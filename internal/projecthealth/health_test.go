@@ -0,0 +1,83 @@
+package projecthealth
+
+import (
+	"testing"
+	"time"
+)
+
+func component(r *Report, name string) Component {
+	for _, c := range r.Components {
+		if c.Name == name {
+			return c
+		}
+	}
+	return Component{}
+}
+
+func TestBuild_Healthy(t *testing.T) {
+	r := Build(Inputs{
+		ProjectID: "p1",
+		Ready:     true,
+		SLA:       &SLAState{Tracked: 4},
+		Providers: []ProviderState{{ID: "a", Healthy: true}},
+		Container: &ContainerState{Running: true, Healthy: true},
+		Requests:  200,
+		Errors:    2,
+	}, time.Now())
+
+	if r.Status != StatusHealthy || r.Score != 100 {
+		t.Errorf("score = %d %s, want 100 healthy", r.Score, r.Status)
+	}
+	if c := component(r, ComponentErrors); c.Score != 98 || c.Weight != 15 {
+		t.Errorf("error_rate = %+v", c)
+	}
+}
+
+func TestBuild_Unhealthy(t *testing.T) {
+	r := Build(Inputs{
+		ProjectID:       "p1",
+		ReadinessIssues: []string{"git remote access failed"},
+		OpenP0:          []string{"bd-1", "bd-2"},
+		SLA:             &SLAState{Tracked: 4, Breached: 1, AtRisk: 2, RecentBreaches: 3},
+		Providers:       []ProviderState{{ID: "a", Healthy: true}, {ID: "b", Error: "timeout"}},
+		Container:       &ContainerState{Running: true, Restarts: 2},
+		Requests:        10,
+		Errors:          5,
+	}, time.Now())
+
+	want := map[string]int{
+		ComponentReadiness: 0,
+		ComponentP0:        20,
+		ComponentSLA:       50,
+		ComponentProviders: 50,
+		ComponentContainer: 40,
+		ComponentErrors:    0,
+	}
+	for name, score := range want {
+		if c := component(r, name); c.Score != score {
+			t.Errorf("%s score = %d, want %d (%+v)", name, c.Score, score, c)
+		}
+	}
+	// (0*20 + 20*20 + 50*20 + 50*15 + 40*10 + 0*15) / 100
+	if r.Score != 26 || r.Status != StatusUnhealthy {
+		t.Errorf("score = %d %s, want 26 unhealthy", r.Score, r.Status)
+	}
+	if c := component(r, ComponentProviders); len(c.Issues) != 1 || c.Issues[0] != "b is unhealthy: timeout" {
+		t.Errorf("provider issues = %v", c.Issues)
+	}
+}
+
+// TestBuild_Unknown tests that components without data don't count.
+func TestBuild_Unknown(t *testing.T) {
+	r := Build(Inputs{ProjectID: "p1", Ready: true, OpenP0: []string{"bd-1"}}, time.Now())
+
+	for _, name := range []string{ComponentSLA, ComponentProviders, ComponentContainer} {
+		if c := component(r, name); c.Status != StatusUnknown {
+			t.Errorf("%s status = %s, want unknown", name, c.Status)
+		}
+	}
+	// (100*20 + 60*20 + 100*15) / 55
+	if r.Score != 85 {
+		t.Errorf("score = %d, want 85", r.Score)
+	}
+}
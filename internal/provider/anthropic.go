@@ -0,0 +1,332 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAnthropicEndpoint = "https://api.anthropic.com/v1"
+	anthropicVersion         = "2023-06-01"
+
+	// anthropicDefaultMaxTokens is used when a request leaves MaxTokens
+	// unset, since the Messages API requires it.
+	anthropicDefaultMaxTokens = 8192
+)
+
+// AnthropicProvider implements StreamingProtocol for the Anthropic Messages API
+type AnthropicProvider struct {
+	endpoint        string
+	apiKey          string
+	client          *http.Client
+	streamingClient *http.Client
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API.
+// The endpoint defaults to the public API; "/v1" is added if missing.
+func NewAnthropicProvider(endpoint, apiKey string) *AnthropicProvider {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultAnthropicEndpoint
+	} else if !strings.HasSuffix(endpoint, "/v1") {
+		endpoint += "/v1"
+	}
+	client, streaming := newHTTPClients()
+	return &AnthropicProvider{
+		endpoint:        endpoint,
+		apiKey:          apiKey,
+		client:          client,
+		streamingClient: streaming,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice  map[string]string  `json:"tool_choice,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+func (p *AnthropicProvider) buildRequest(req *ChatCompletionRequest, stream bool) *anthropicRequest {
+	system, messages := splitSystemMessages(req.Messages)
+	ar := &anthropicRequest{
+		Model:     req.Model,
+		System:    system,
+		MaxTokens: req.MaxTokens,
+		Stream:    stream,
+	}
+	if ar.MaxTokens <= 0 {
+		ar.MaxTokens = anthropicDefaultMaxTokens
+	}
+	if req.Temperature != 0 {
+		t := req.Temperature
+		ar.Temperature = &t
+	}
+	for _, m := range alternateTurns(messages) {
+		ar.Messages = append(ar.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		ar.Tools = []anthropicTool{{
+			Name:        jsonToolName,
+			Description: "Respond with a single JSON object.",
+			InputSchema: map[string]interface{}{"type": "object"},
+		}}
+		ar.ToolChoice = map[string]string{"type": "tool", "name": jsonToolName}
+	}
+	return ar
+}
+
+func (p *AnthropicProvider) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	if p.apiKey != "" {
+		httpReq.Header.Set("x-api-key", p.apiKey)
+	}
+	return httpReq, nil
+}
+
+// CreateChatCompletion sends a chat completion request
+func (p *AnthropicProvider) CreateChatCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, http.MethodPost, "/messages", p.buildRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, respBody)
+	}
+
+	var ar anthropicResponse
+	if err := json.Unmarshal(respBody, &ar); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range ar.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			content.WriteString(toolCallContent(block.Name, block.Input))
+		}
+	}
+	return newChatCompletionResponse(ar.ID, ar.Model, content.String(), anthropicFinishReason(ar.StopReason),
+		ar.Usage.InputTokens, ar.Usage.OutputTokens), nil
+}
+
+// CreateChatCompletionStream sends a streaming chat completion request. Text
+// and tool input arrive as content deltas; usage is sent on the final chunk.
+func (p *AnthropicProvider) CreateChatCompletionStream(ctx context.Context, req *ChatCompletionRequest, handler StreamHandler) error {
+	httpReq, err := p.newRequest(ctx, http.MethodPost, "/messages", p.buildRequest(req, true))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.streamingClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, body)
+	}
+
+	var (
+		id, model string
+		usage     anthropicUsage
+		toolName  string
+		toolInput strings.Builder
+	)
+	return readSSE(resp.Body, func(event, data string) error {
+		var ev struct {
+			Type    string `json:"type"`
+			Message struct {
+				ID    string         `json:"id"`
+				Model string         `json:"model"`
+				Usage anthropicUsage `json:"usage"`
+			} `json:"message"`
+			ContentBlock anthropicContentBlock `json:"content_block"`
+			Delta        struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				PartialJSON string `json:"partial_json"`
+				StopReason  string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage anthropicUsage `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			id, model = ev.Message.ID, ev.Message.Model
+			usage.InputTokens = ev.Message.Usage.InputTokens
+		case "content_block_start":
+			toolName = ""
+			toolInput.Reset()
+			if ev.ContentBlock.Type == "tool_use" {
+				toolName = ev.ContentBlock.Name
+			}
+		case "content_block_delta":
+			text := ev.Delta.Text
+			if ev.Delta.Type == "input_json_delta" {
+				text = ev.Delta.PartialJSON
+				if toolName != jsonToolName {
+					// Other tools are mapped once their input is complete.
+					toolInput.WriteString(text)
+					return nil
+				}
+			}
+			if text != "" {
+				return handler(newStreamChunk(id, model, text, ""))
+			}
+		case "content_block_stop":
+			if toolName != "" && toolName != jsonToolName {
+				return handler(newStreamChunk(id, model, toolCallContent(toolName, json.RawMessage(toolInput.String())), ""))
+			}
+		case "message_delta":
+			usage.OutputTokens = ev.Usage.OutputTokens
+			chunk := newStreamChunk(id, model, "", anthropicFinishReason(ev.Delta.StopReason))
+			chunk.Usage = &StreamUsage{
+				PromptTokens:     usage.InputTokens,
+				CompletionTokens: usage.OutputTokens,
+				TotalTokens:      usage.InputTokens + usage.OutputTokens,
+			}
+			return handler(chunk)
+		case "error":
+			return fmt.Errorf("stream error: %s: %s", ev.Error.Type, ev.Error.Message)
+		}
+		return nil
+	})
+}
+
+// GetModels lists available models
+func (p *AnthropicProvider) GetModels(ctx context.Context) ([]Model, error) {
+	var models []Model
+	afterID := ""
+	for {
+		path := "/models?limit=1000"
+		if afterID != "" {
+			path += "&after_id=" + url.QueryEscape(afterID)
+		}
+		httpReq, err := p.newRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.client.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, statusError(resp.StatusCode, respBody)
+		}
+
+		var page struct {
+			Data []struct {
+				ID        string    `json:"id"`
+				CreatedAt time.Time `json:"created_at"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		for _, m := range page.Data {
+			models = append(models, Model{ID: m.ID, Object: "model", Created: m.CreatedAt.Unix(), OwnedBy: "anthropic"})
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+// anthropicFinishReason maps a stop_reason onto the OpenAI finish_reason
+// values. Tool use counts as a normal stop because the call is returned as
+// message content.
+func anthropicFinishReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "max_tokens":
+		return "length"
+	default:
+		return "stop"
+	}
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicProvider_CreateChatCompletion(t *testing.T) {
+	var got anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{
+			"id": "msg_1", "model": "claude-test", "stop_reason": "tool_use",
+			"content": [{"type": "tool_use", "id": "tu_1", "name": "json_response", "input": {"action": "read", "path": "main.go"}}],
+			"usage": {"input_tokens": 12, "output_tokens": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewAnthropicProvider(server.URL, "test-key")
+	resp, err := p.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model: "claude-test",
+		Messages: []ChatMessage{
+			{Role: "system", Content: "You are an agent."},
+			{Role: "user", Content: "Start."},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	if got.System != "You are an agent." || len(got.Messages) != 1 || got.Messages[0].Role != "user" {
+		t.Errorf("unexpected request translation: %+v", got)
+	}
+	if got.MaxTokens != anthropicDefaultMaxTokens {
+		t.Errorf("expected default max_tokens, got %d", got.MaxTokens)
+	}
+	if len(got.Tools) != 1 || got.ToolChoice["name"] != jsonToolName {
+		t.Errorf("json_object should force the %s tool, got tools=%+v choice=%v", jsonToolName, got.Tools, got.ToolChoice)
+	}
+
+	if resp.Choices[0].Message.Content != `{"action": "read", "path": "main.go"}` {
+		t.Errorf("unexpected content: %s", resp.Choices[0].Message.Content)
+	}
+	if resp.Choices[0].Finish != "stop" || resp.Usage.TotalTokens != 17 {
+		t.Errorf("unexpected finish/usage: %q %+v", resp.Choices[0].Finish, resp.Usage)
+	}
+}
+
+func TestAnthropicProvider_ContextLengthError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 250000 tokens > 200000 maximum"}}`))
+	}))
+	defer server.Close()
+
+	_, err := NewAnthropicProvider(server.URL, "k").CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	})
+	var cle *ContextLengthError
+	if !errors.As(err, &cle) {
+		t.Fatalf("expected ContextLengthError, got %v", err)
+	}
+}
+
+func TestAnthropicProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`event: message_start` + "\n" + `data: {"type":"message_start","message":{"id":"msg_1","model":"claude-test","usage":{"input_tokens":10,"output_tokens":1}}}`,
+			`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" world"}}`,
+			`event: content_block_stop` + "\n" + `data: {"type":"content_block_stop","index":0}`,
+			`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":4}}`,
+			`event: message_stop` + "\n" + `data: {"type":"message_stop"}`,
+		}
+		_, _ = w.Write([]byte(strings.Join(events, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	resp, err := CollectStream(context.Background(), NewAnthropicProvider(server.URL, "k"), &ChatCompletionRequest{
+		Model:    "claude-test",
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hello world" || resp.Choices[0].Finish != "stop" {
+		t.Errorf("unexpected response: %+v", resp.Choices[0])
+	}
+	if resp.Usage.PromptTokens != 10 || resp.Usage.CompletionTokens != 4 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicProvider_GetModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after_id") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-a","created_at":"2025-01-01T00:00:00Z"}],"has_more":true,"last_id":"claude-a"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-b","created_at":"2025-02-01T00:00:00Z"}],"has_more":false}`))
+	}))
+	defer server.Close()
+
+	models, err := NewAnthropicProvider(server.URL+"/v1", "k").GetModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "claude-a" || models[1].ID != "claude-b" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+}
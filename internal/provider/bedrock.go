@@ -0,0 +1,396 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBedrockRegion = "us-east-1"
+	bedrockSigningName   = "bedrock"
+)
+
+// BedrockProvider implements StreamingProtocol for AWS Bedrock using the
+// Converse API, which works the same way for every Bedrock-hosted model.
+//
+// The provider endpoint is either a region name ("us-west-2") or a
+// bedrock-runtime URL, from which the region is taken. With no endpoint the
+// region comes from AWS_REGION or AWS_DEFAULT_REGION.
+//
+// The API key is either "ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN]",
+// used to SigV4-sign requests, or a Bedrock API key sent as a bearer token.
+// With no key, AWS_BEARER_TOKEN_BEDROCK or the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN variables are used.
+type BedrockProvider struct {
+	region          string
+	runtimeURL      string
+	controlURL      string
+	creds           awsCredentials
+	bearerToken     string
+	client          *http.Client
+	streamingClient *http.Client
+	now             func() time.Time
+}
+
+// NewBedrockProvider creates a provider for AWS Bedrock.
+func NewBedrockProvider(endpoint, apiKey string) *BedrockProvider {
+	endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	p := &BedrockProvider{now: time.Now}
+
+	if strings.Contains(endpoint, "://") {
+		p.runtimeURL = endpoint
+		p.region = bedrockRegionFromURL(endpoint)
+	} else {
+		p.region = endpoint
+	}
+	if p.region == "" {
+		p.region = os.Getenv("AWS_REGION")
+	}
+	if p.region == "" {
+		p.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if p.region == "" {
+		p.region = defaultBedrockRegion
+	}
+	if p.runtimeURL == "" {
+		p.runtimeURL = "https://bedrock-runtime." + p.region + ".amazonaws.com"
+	}
+	p.controlURL = "https://bedrock." + p.region + ".amazonaws.com"
+
+	switch parts := strings.SplitN(apiKey, ":", 3); {
+	case len(parts) >= 2:
+		p.creds = awsCredentials{AccessKeyID: parts[0], SecretAccessKey: parts[1]}
+		if len(parts) == 3 {
+			p.creds.SessionToken = parts[2]
+		}
+	case apiKey != "":
+		p.bearerToken = apiKey
+	case os.Getenv("AWS_BEARER_TOKEN_BEDROCK") != "":
+		p.bearerToken = os.Getenv("AWS_BEARER_TOKEN_BEDROCK")
+	default:
+		p.creds = awsCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+
+	p.client, p.streamingClient = newHTTPClients()
+	return p
+}
+
+// bedrockRegionFromURL returns the host label following "bedrock-runtime"
+// or "bedrock", e.g. us-east-1 for bedrock-runtime.us-east-1.amazonaws.com.
+func bedrockRegionFromURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	for i := 0; i+1 < len(labels); i++ {
+		if labels[i] == "bedrock-runtime" || labels[i] == "bedrock" {
+			return labels[i+1]
+		}
+	}
+	return ""
+}
+
+type bedrockContentBlock struct {
+	Text    string `json:"text,omitempty"`
+	ToolUse *struct {
+		ToolUseID string          `json:"toolUseId,omitempty"`
+		Name      string          `json:"name"`
+		Input     json.RawMessage `json:"input,omitempty"`
+	} `json:"toolUse,omitempty"`
+}
+
+type bedrockMessage struct {
+	Role    string                `json:"role"`
+	Content []bedrockContentBlock `json:"content"`
+}
+
+type bedrockRequest struct {
+	Messages        []bedrockMessage       `json:"messages"`
+	System          []bedrockContentBlock  `json:"system,omitempty"`
+	InferenceConfig map[string]interface{} `json:"inferenceConfig,omitempty"`
+	ToolConfig      map[string]interface{} `json:"toolConfig,omitempty"`
+}
+
+type bedrockUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+}
+
+func (p *BedrockProvider) buildRequest(req *ChatCompletionRequest) *bedrockRequest {
+	system, messages := splitSystemMessages(req.Messages)
+	br := &bedrockRequest{}
+	if system != "" {
+		br.System = []bedrockContentBlock{{Text: system}}
+	}
+	for _, m := range alternateTurns(messages) {
+		br.Messages = append(br.Messages, bedrockMessage{Role: m.Role, Content: []bedrockContentBlock{{Text: m.Content}}})
+	}
+
+	cfg := map[string]interface{}{}
+	if req.MaxTokens > 0 {
+		cfg["maxTokens"] = req.MaxTokens
+	}
+	if req.Temperature != 0 {
+		cfg["temperature"] = req.Temperature
+	}
+	if len(cfg) > 0 {
+		br.InferenceConfig = cfg
+	}
+
+	// Forcing a specific tool is only supported for Anthropic models; other
+	// models rely on the prompt asking for JSON.
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" && strings.Contains(req.Model, "anthropic.") {
+		br.ToolConfig = map[string]interface{}{
+			"tools": []interface{}{map[string]interface{}{
+				"toolSpec": map[string]interface{}{
+					"name":        jsonToolName,
+					"description": "Respond with a single JSON object.",
+					"inputSchema": map[string]interface{}{"json": map[string]interface{}{"type": "object"}},
+				},
+			}},
+			"toolChoice": map[string]interface{}{"tool": map[string]string{"name": jsonToolName}},
+		}
+	}
+	return br
+}
+
+// do sends a request to Bedrock, authenticating with the bearer token or a
+// SigV4 signature.
+func (p *BedrockProvider) do(ctx context.Context, client *http.Client, method, rawURL, escapedPath string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bedrock endpoint: %w", err)
+	}
+	if escapedPath != "" {
+		u.RawPath = u.EscapedPath() + escapedPath
+		u.Path, _ = url.PathUnescape(u.RawPath)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	switch {
+	case p.bearerToken != "":
+		httpReq.Header.Set("Authorization", "Bearer "+p.bearerToken)
+	case p.creds.AccessKeyID != "":
+		signV4(httpReq, body, p.creds, p.region, bedrockSigningName, p.now())
+	default:
+		return nil, fmt.Errorf("no AWS credentials configured for bedrock")
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// bedrockModelPath returns the escaped runtime path for a model operation.
+// Model IDs contain ':' which must be escaped for signing to match.
+func bedrockModelPath(model, operation string) string {
+	return "/model/" + awsURIEncode(model, true) + "/" + operation
+}
+
+// CreateChatCompletion sends a chat completion request
+func (p *BedrockProvider) CreateChatCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	body, err := json.Marshal(p.buildRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := p.do(ctx, p.client, http.MethodPost, p.runtimeURL, bedrockModelPath(req.Model, "converse"), body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, respBody)
+	}
+
+	var br struct {
+		Output struct {
+			Message bedrockMessage `json:"message"`
+		} `json:"output"`
+		StopReason string       `json:"stopReason"`
+		Usage      bedrockUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &br); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range br.Output.Message.Content {
+		if block.ToolUse != nil {
+			content.WriteString(toolCallContent(block.ToolUse.Name, block.ToolUse.Input))
+			continue
+		}
+		content.WriteString(block.Text)
+	}
+	return newChatCompletionResponse(resp.Header.Get("x-amzn-RequestId"), req.Model, content.String(),
+		bedrockFinishReason(br.StopReason), br.Usage.InputTokens, br.Usage.OutputTokens), nil
+}
+
+// CreateChatCompletionStream sends a streaming chat completion request via
+// ConverseStream, whose response uses the AWS event-stream encoding.
+func (p *BedrockProvider) CreateChatCompletionStream(ctx context.Context, req *ChatCompletionRequest, handler StreamHandler) error {
+	body, err := json.Marshal(p.buildRequest(req))
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	resp, err := p.do(ctx, p.streamingClient, http.MethodPost, p.runtimeURL, bedrockModelPath(req.Model, "converse-stream"), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, respBody)
+	}
+
+	id := resp.Header.Get("x-amzn-RequestId")
+	toolName := ""
+	var toolInput strings.Builder
+	for {
+		msg, err := readEventMessage(resp.Body)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Headers[":message-type"] == "exception" || msg.Headers[":message-type"] == "error" {
+			return fmt.Errorf("stream error: %s: %s", msg.Headers[":exception-type"]+msg.Headers[":error-code"], string(msg.Payload))
+		}
+
+		var ev struct {
+			Start struct {
+				ToolUse *struct {
+					Name string `json:"name"`
+				} `json:"toolUse"`
+			} `json:"start"`
+			Delta struct {
+				Text    string `json:"text"`
+				ToolUse *struct {
+					Input string `json:"input"`
+				} `json:"toolUse"`
+			} `json:"delta"`
+			StopReason string       `json:"stopReason"`
+			Usage      bedrockUsage `json:"usage"`
+		}
+		if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		var chunk *StreamChunk
+		switch msg.Headers[":event-type"] {
+		case "contentBlockStart":
+			toolName = ""
+			toolInput.Reset()
+			if ev.Start.ToolUse != nil {
+				toolName = ev.Start.ToolUse.Name
+			}
+		case "contentBlockDelta":
+			switch {
+			case ev.Delta.ToolUse != nil && toolName == jsonToolName:
+				chunk = newStreamChunk(id, req.Model, ev.Delta.ToolUse.Input, "")
+			case ev.Delta.ToolUse != nil:
+				// Other tools are mapped once their input is complete.
+				toolInput.WriteString(ev.Delta.ToolUse.Input)
+			case ev.Delta.Text != "":
+				chunk = newStreamChunk(id, req.Model, ev.Delta.Text, "")
+			}
+		case "contentBlockStop":
+			if toolName != "" && toolName != jsonToolName {
+				chunk = newStreamChunk(id, req.Model, toolCallContent(toolName, json.RawMessage(toolInput.String())), "")
+			}
+		case "messageStop":
+			chunk = newStreamChunk(id, req.Model, "", bedrockFinishReason(ev.StopReason))
+		case "metadata":
+			chunk = newStreamChunk(id, req.Model, "", "")
+			chunk.Usage = &StreamUsage{
+				PromptTokens:     ev.Usage.InputTokens,
+				CompletionTokens: ev.Usage.OutputTokens,
+				TotalTokens:      ev.Usage.InputTokens + ev.Usage.OutputTokens,
+			}
+		}
+		if chunk != nil {
+			if err := handler(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetModels lists the text-output foundation models available in the
+// region via ListFoundationModels.
+func (p *BedrockProvider) GetModels(ctx context.Context) ([]Model, error) {
+	resp, err := p.do(ctx, p.client, http.MethodGet, p.controlURL+"/foundation-models?byOutputModality=TEXT", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, respBody)
+	}
+
+	var page struct {
+		ModelSummaries []struct {
+			ModelID      string `json:"modelId"`
+			ProviderName string `json:"providerName"`
+		} `json:"modelSummaries"`
+	}
+	if err := json.Unmarshal(respBody, &page); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	models := make([]Model, 0, len(page.ModelSummaries))
+	for _, m := range page.ModelSummaries {
+		models = append(models, Model{ID: m.ModelID, Object: "model", OwnedBy: m.ProviderName})
+	}
+	return models, nil
+}
+
+// bedrockFinishReason maps a Converse stopReason onto the OpenAI values.
+func bedrockFinishReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "max_tokens":
+		return "length"
+	case "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// encodeEventMessage builds an AWS event-stream message with string headers.
+func encodeEventMessage(headers map[string]string, payload string) []byte {
+	var hb bytes.Buffer
+	for name, value := range headers {
+		hb.WriteByte(byte(len(name)))
+		hb.WriteString(name)
+		hb.WriteByte(7)
+		_ = binary.Write(&hb, binary.BigEndian, uint16(len(value)))
+		hb.WriteString(value)
+	}
+	total := 12 + hb.Len() + len(payload) + 4
+
+	var msg bytes.Buffer
+	_ = binary.Write(&msg, binary.BigEndian, uint32(total))
+	_ = binary.Write(&msg, binary.BigEndian, uint32(hb.Len()))
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(hb.Bytes())
+	msg.WriteString(payload)
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestBedrockRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	cases := []struct {
+		endpoint string
+		want     string
+	}{
+		{"us-west-2", "us-west-2"},
+		{"https://bedrock-runtime.ap-south-1.amazonaws.com", "ap-south-1"},
+		{"https://vpce-123.bedrock-runtime.us-east-2.vpce.amazonaws.com", "us-east-2"},
+		{"", "eu-west-1"},
+	}
+	for _, tc := range cases {
+		if got := NewBedrockProvider(tc.endpoint, "k").region; got != tc.want {
+			t.Errorf("NewBedrockProvider(%q).region = %q, want %q", tc.endpoint, got, tc.want)
+		}
+	}
+}
+
+func TestBedrockProvider_CreateChatCompletion(t *testing.T) {
+	var got bedrockRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/model/anthropic.claude-v2%3A1/converse" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-west-2/bedrock/aws4_request") {
+			t.Errorf("request not SigV4-signed for bedrock: %s", auth)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("x-amzn-RequestId", "req-1")
+		_, _ = w.Write([]byte(`{
+			"output": {"message": {"role": "assistant", "content": [{"toolUse": {"toolUseId": "t1", "name": "json_response", "input": {"action": "done"}}}]}},
+			"stopReason": "tool_use",
+			"usage": {"inputTokens": 20, "outputTokens": 6, "totalTokens": 26}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewBedrockProvider("us-west-2", "AKID:secret")
+	p.runtimeURL = server.URL
+	resp, err := p.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:          "anthropic.claude-v2:1",
+		Messages:       []ChatMessage{{Role: "system", Content: "sys"}, {Role: "user", Content: "go"}},
+		MaxTokens:      512,
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if len(got.System) != 1 || got.System[0].Text != "sys" || got.InferenceConfig["maxTokens"] != float64(512) {
+		t.Errorf("unexpected request translation: %+v", got)
+	}
+	if got.ToolConfig == nil {
+		t.Error("json_object on an Anthropic model should force the JSON tool")
+	}
+	if resp.ID != "req-1" || resp.Choices[0].Message.Content != `{"action": "done"}` || resp.Usage.TotalTokens != 26 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestBedrockProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer api-key" {
+			t.Errorf("expected bearer auth, got %q", r.Header.Get("Authorization"))
+		}
+		event := func(eventType, payload string) []byte {
+			return encodeEventMessage(map[string]string{":message-type": "event", ":event-type": eventType}, payload)
+		}
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		_, _ = w.Write(event("messageStart", `{"role":"assistant"}`))
+		_, _ = w.Write(event("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"Hi "}}`))
+		_, _ = w.Write(event("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"there"}}`))
+		_, _ = w.Write(event("contentBlockStop", `{"contentBlockIndex":0}`))
+		_, _ = w.Write(event("messageStop", `{"stopReason":"max_tokens"}`))
+		_, _ = w.Write(event("metadata", `{"usage":{"inputTokens":3,"outputTokens":2,"totalTokens":5}}`))
+	}))
+	defer server.Close()
+
+	p := NewBedrockProvider(server.URL, "api-key")
+	resp, err := CollectStream(context.Background(), p, &ChatCompletionRequest{
+		Model:    "meta.llama3-70b-instruct-v1:0",
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hi there" || resp.Choices[0].Finish != "length" {
+		t.Errorf("unexpected response: %+v", resp.Choices[0])
+	}
+	if resp.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestBedrockProvider_StreamException(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(encodeEventMessage(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, `{"message":"slow down"}`))
+	}))
+	defer server.Close()
+
+	err := NewBedrockProvider(server.URL, "k").CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "m",
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	}, func(*StreamChunk) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "throttlingException") {
+		t.Fatalf("expected throttling error, got %v", err)
+	}
+}
+
+func TestBedrockProvider_GetModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/foundation-models" || r.URL.Query().Get("byOutputModality") != "TEXT" {
+			t.Errorf("unexpected url %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"modelSummaries":[
+			{"modelId":"anthropic.claude-3-5-sonnet-20240620-v1:0","providerName":"Anthropic"},
+			{"modelId":"meta.llama3-70b-instruct-v1:0","providerName":"Meta"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := NewBedrockProvider("us-east-1", "AKID:secret")
+	p.controlURL = server.URL
+	models, err := p.GetModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetModels failed: %v", err)
+	}
+	if len(models) != 2 || models[1].ID != "meta.llama3-70b-instruct-v1:0" || models[1].OwnedBy != "Meta" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+}
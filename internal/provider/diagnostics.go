@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Diagnostic check statuses.
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// maxReportedModels caps the model list included in a diagnostic report.
+const maxReportedModels = 50
+
+// DiagnosticCheck is the outcome of one step of a provider test.
+type DiagnosticCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+}
+
+// DiagnosticReport is the result of testing a provider end to end.
+type DiagnosticReport struct {
+	ProviderID string            `json:"provider_id"`
+	Type       string            `json:"type"`
+	Endpoint   string            `json:"endpoint"`
+	Model      string            `json:"model"`
+	Passed     bool              `json:"passed"`
+	Checks     []DiagnosticCheck `json:"checks"`
+	Models     []string          `json:"models,omitempty"`
+	ModelCount int               `json:"model_count"`
+	ProbeMs    int64             `json:"probe_latency_ms"`
+	ProbeError string            `json:"probe_error,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs int64             `json:"duration_ms"`
+}
+
+// Diagnose runs the chat-completion health probe against a provider, lists
+// its models, and works out whether its API key is accepted and allowed to
+// do both. A report passes when no check fails; warnings (e.g. a key that can
+// chat but not list models) don't fail it.
+func Diagnose(ctx context.Context, rp *RegisteredProvider) *DiagnosticReport {
+	cfg := rp.Config
+	report := &DiagnosticReport{
+		ProviderID: cfg.ID,
+		Type:       cfg.Type,
+		Endpoint:   cfg.Endpoint,
+		Model:      cfg.SelectedModel,
+		StartedAt:  time.Now(),
+	}
+	if report.Model == "" {
+		report.Model = cfg.Model
+	}
+
+	// Chat completion probe — the same request the health check sends.
+	start := time.Now()
+	_, chatErr := rp.Protocol.CreateChatCompletion(ctx, &ChatCompletionRequest{
+		Model:     report.Model,
+		Messages:  []ChatMessage{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+	report.ProbeMs = time.Since(start).Milliseconds()
+	probe := DiagnosticCheck{Name: "chat_completion", LatencyMs: report.ProbeMs}
+	if chatErr != nil {
+		report.ProbeError = chatErr.Error()
+		probe.Status, probe.Message = CheckFail, chatErr.Error()
+	} else {
+		probe.Status, probe.Message = CheckPass, fmt.Sprintf("model %s answered in %dms", report.Model, report.ProbeMs)
+	}
+	report.Checks = append(report.Checks, probe)
+
+	// Model listing.
+	start = time.Now()
+	models, modelsErr := rp.Protocol.GetModels(ctx)
+	listing := DiagnosticCheck{Name: "list_models", LatencyMs: time.Since(start).Milliseconds()}
+	switch {
+	case modelsErr != nil:
+		// Some proxies restrict /models to a different key scope; that only
+		// matters if chat failed too.
+		listing.Status, listing.Message = CheckWarn, modelsErr.Error()
+		if chatErr != nil {
+			listing.Status = CheckFail
+		}
+	case len(models) == 0:
+		listing.Status, listing.Message = CheckWarn, "provider returned no models"
+	default:
+		listing.Status, listing.Message = CheckPass, fmt.Sprintf("%d models available", len(models))
+	}
+	report.ModelCount = len(models)
+	for i, m := range models {
+		if i == maxReportedModels {
+			break
+		}
+		report.Models = append(report.Models, m.ID)
+	}
+	report.Checks = append(report.Checks, listing)
+
+	// Configured model availability.
+	available := DiagnosticCheck{Name: "model_available"}
+	switch {
+	case report.Model == "":
+		available.Status, available.Message = CheckWarn, "no model configured"
+	case len(models) == 0:
+		available.Status, available.Message = CheckSkip, "model list unavailable"
+	case hasModel(models, report.Model):
+		available.Status, available.Message = CheckPass, fmt.Sprintf("%s is listed", report.Model)
+	case chatErr == nil:
+		// Aliases and routing proxies answer for models they don't list.
+		available.Status, available.Message = CheckWarn, fmt.Sprintf("%s is not listed but answered the probe", report.Model)
+	default:
+		available.Status, available.Message = CheckFail, fmt.Sprintf("%s is not offered by this provider", report.Model)
+	}
+	report.Checks = append(report.Checks, available)
+
+	report.Checks = append(report.Checks, keyScopeCheck(cfg, chatErr, modelsErr))
+
+	report.Passed = true
+	for _, c := range report.Checks {
+		if c.Status == CheckFail {
+			report.Passed = false
+		}
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// keyScopeCheck infers from the probe results whether the API key is valid
+// and permitted to chat and list models.
+func keyScopeCheck(cfg *ProviderConfig, chatErr, modelsErr error) DiagnosticCheck {
+	check := DiagnosticCheck{Name: "api_key_scope"}
+	chatStatus, modelsStatus := errorStatusCode(chatErr), errorStatusCode(modelsErr)
+
+	switch {
+	case chatStatus == 401 || (chatErr != nil && modelsStatus == 401):
+		check.Status, check.Message = CheckFail, "API key was rejected (HTTP 401)"
+	case chatStatus == 403:
+		check.Status, check.Message = CheckFail, "API key is not permitted to create chat completions (HTTP 403)"
+	case chatErr == nil && modelsStatus == 403:
+		check.Status, check.Message = CheckWarn, "API key can chat but is not permitted to list models (HTTP 403)"
+	case chatErr == nil && modelsErr == nil:
+		if cfg.APIKey == "" {
+			check.Status, check.Message = CheckPass, "no API key configured; provider accepts anonymous requests"
+		} else {
+			check.Status, check.Message = CheckPass, "API key can chat and list models"
+		}
+	case chatErr == nil:
+		check.Status, check.Message = CheckPass, "API key can chat"
+	default:
+		check.Status, check.Message = CheckSkip, "could not determine key scope: provider did not answer"
+	}
+	return check
+}
+
+var statusCodeRe = regexp.MustCompile(`status code (\d{3})`)
+
+// errorStatusCode extracts the HTTP status from a protocol error, or 0.
+func errorStatusCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var cle *ContextLengthError
+	if errors.As(err, &cle) {
+		return cle.StatusCode
+	}
+	if m := statusCodeRe.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+func hasModel(models []Model, id string) bool {
+	for _, m := range models {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
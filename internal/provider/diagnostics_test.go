@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func diagnosticServer(chatStatus, modelsStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chat/completions":
+			w.WriteHeader(chatStatus)
+			_, _ = w.Write([]byte(`{"id":"1","choices":[{"message":{"role":"assistant","content":"pong"}}]}`))
+		case "/models":
+			w.WriteHeader(modelsStatus)
+			_, _ = w.Write([]byte(`{"data":[{"id":"model-a"},{"id":"model-b"}]}`))
+		}
+	}))
+}
+
+func checkStatus(t *testing.T, report *DiagnosticReport, name string) string {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	t.Fatalf("check %s missing from report", name)
+	return ""
+}
+
+func TestDiagnose(t *testing.T) {
+	cases := []struct {
+		name         string
+		chatStatus   int
+		modelsStatus int
+		model        string
+		wantPassed   bool
+		want         map[string]string
+	}{
+		{"healthy", 200, 200, "model-a", true, map[string]string{
+			"chat_completion": CheckPass, "list_models": CheckPass, "model_available": CheckPass, "api_key_scope": CheckPass,
+		}},
+		{"key rejected", 401, 401, "model-a", false, map[string]string{
+			"chat_completion": CheckFail, "list_models": CheckFail, "api_key_scope": CheckFail,
+		}},
+		{"models scope missing", 200, 403, "model-a", true, map[string]string{
+			"chat_completion": CheckPass, "list_models": CheckWarn, "model_available": CheckSkip, "api_key_scope": CheckWarn,
+		}},
+		{"unlisted alias", 200, 200, "alias", true, map[string]string{
+			"model_available": CheckWarn,
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := diagnosticServer(tc.chatStatus, tc.modelsStatus)
+			defer server.Close()
+
+			rp := &RegisteredProvider{
+				Config:   &ProviderConfig{ID: "p1", Type: "openai", Endpoint: server.URL, APIKey: "k", Model: tc.model},
+				Protocol: NewOpenAIProvider(server.URL, "k"),
+			}
+			report := Diagnose(context.Background(), rp)
+			if report.Passed != tc.wantPassed {
+				t.Errorf("passed = %v, want %v: %+v", report.Passed, tc.wantPassed, report.Checks)
+			}
+			for name, want := range tc.want {
+				if got := checkStatus(t, report, name); got != want {
+					t.Errorf("%s = %s, want %s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorStatusCode(t *testing.T) {
+	if got := errorStatusCode(statusError(403, []byte("forbidden"))); got != 403 {
+		t.Errorf("got %d, want 403", got)
+	}
+	if got := errorStatusCode(&ContextLengthError{StatusCode: 400}); got != 400 {
+		t.Errorf("got %d, want 400", got)
+	}
+	if got := errorStatusCode(nil); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// awsEventMessage is one message of the AWS binary event-stream encoding
+// used by Bedrock's streaming APIs.
+type awsEventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// maxEventMessageSize bounds a single event-stream message.
+const maxEventMessageSize = 16 << 20
+
+// readEventMessage reads the next message from r. It returns io.EOF at a
+// clean end of stream.
+func readEventMessage(r io.Reader) (*awsEventMessage, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(r, prelude[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("truncated event-stream prelude")
+		}
+		return nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, fmt.Errorf("event-stream prelude checksum mismatch")
+	}
+	if totalLen < 16+headersLen || totalLen > maxEventMessageSize {
+		return nil, fmt.Errorf("invalid event-stream message length %d", totalLen)
+	}
+
+	rest := make([]byte, totalLen-12)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("truncated event-stream message: %w", err)
+	}
+	crc := crc32.NewIEEE()
+	crc.Write(prelude[:])
+	crc.Write(rest[:len(rest)-4])
+	if crc.Sum32() != binary.BigEndian.Uint32(rest[len(rest)-4:]) {
+		return nil, fmt.Errorf("event-stream message checksum mismatch")
+	}
+
+	headers, err := parseEventHeaders(rest[:headersLen])
+	if err != nil {
+		return nil, err
+	}
+	return &awsEventMessage{Headers: headers, Payload: rest[headersLen : len(rest)-4]}, nil
+}
+
+// parseEventHeaders decodes event-stream headers. Only string values are
+// kept; other types are skipped.
+func parseEventHeaders(b []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, fmt.Errorf("truncated event-stream header")
+		}
+		name := string(b[1 : 1+nameLen])
+		valueType := b[1+nameLen]
+		b = b[2+nameLen:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true/false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // int16
+			size = 2
+		case 4: // int32
+			size = 4
+		case 5, 8: // int64, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // bytes, string
+			if len(b) < 2 {
+				return nil, fmt.Errorf("truncated event-stream header %q", name)
+			}
+			n := int(binary.BigEndian.Uint16(b))
+			if len(b) < 2+n {
+				return nil, fmt.Errorf("truncated event-stream header %q", name)
+			}
+			if valueType == 7 {
+				headers[name] = string(b[2 : 2+n])
+			}
+			b = b[2+n:]
+			continue
+		default:
+			return nil, fmt.Errorf("unknown event-stream header type %d", valueType)
+		}
+		if len(b) < size {
+			return nil, fmt.Errorf("truncated event-stream header %q", name)
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultGeminiEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+
+// GeminiProvider implements StreamingProtocol for the Google Gemini API
+type GeminiProvider struct {
+	endpoint        string
+	apiKey          string
+	client          *http.Client
+	streamingClient *http.Client
+}
+
+// NewGeminiProvider creates a provider for the Gemini generateContent API.
+// The endpoint defaults to the public v1beta API.
+func NewGeminiProvider(endpoint, apiKey string) *GeminiProvider {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultGeminiEndpoint
+	}
+	client, streaming := newHTTPClients()
+	return &GeminiProvider{
+		endpoint:        endpoint,
+		apiKey:          apiKey,
+		client:          client,
+		streamingClient: streaming,
+	}
+}
+
+type geminiPart struct {
+	Text         string `json:"text,omitempty"`
+	FunctionCall *struct {
+		Name string          `json:"name"`
+		Args json.RawMessage `json:"args,omitempty"`
+	} `json:"functionCall,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens  int      `json:"maxOutputTokens,omitempty"`
+	ResponseMIMEType string   `json:"responseMimeType,omitempty"`
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+	ResponseID   string `json:"responseId"`
+}
+
+// text returns the first candidate's text, with function calls mapped onto
+// the actions JSON format.
+func (r *geminiResponse) text() (content, finish string) {
+	if len(r.Candidates) == 0 {
+		return "", ""
+	}
+	var b strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		if part.FunctionCall != nil {
+			b.WriteString(toolCallContent(part.FunctionCall.Name, part.FunctionCall.Args))
+			continue
+		}
+		b.WriteString(part.Text)
+	}
+	return b.String(), geminiFinishReason(r.Candidates[0].FinishReason)
+}
+
+func (p *GeminiProvider) buildRequest(req *ChatCompletionRequest) *geminiRequest {
+	system, messages := splitSystemMessages(req.Messages)
+	gr := &geminiRequest{}
+	if system != "" {
+		gr.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: system}}}
+	}
+	for _, m := range alternateTurns(messages) {
+		role := m.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		gr.Contents = append(gr.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+
+	cfg := &geminiGenerationConfig{MaxOutputTokens: req.MaxTokens}
+	if req.Temperature != 0 {
+		t := req.Temperature
+		cfg.Temperature = &t
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == "json_object" {
+		cfg.ResponseMIMEType = "application/json"
+	}
+	if *cfg != (geminiGenerationConfig{}) {
+		gr.GenerationConfig = cfg
+	}
+	return gr
+}
+
+func (p *GeminiProvider) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("x-goog-api-key", p.apiKey)
+	}
+	return httpReq, nil
+}
+
+// geminiModelPath returns the path for a model method, accepting model IDs
+// with or without the "models/" prefix.
+func geminiModelPath(model, method string) string {
+	return "/models/" + url.PathEscape(strings.TrimPrefix(model, "models/")) + ":" + method
+}
+
+// CreateChatCompletion sends a chat completion request
+func (p *GeminiProvider) CreateChatCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	httpReq, err := p.newRequest(ctx, http.MethodPost, geminiModelPath(req.Model, "generateContent"), p.buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, respBody)
+	}
+
+	var gr geminiResponse
+	if err := json.Unmarshal(respBody, &gr); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(gr.Candidates) == 0 {
+		return nil, fmt.Errorf("gemini returned no candidates: %s", string(respBody))
+	}
+
+	content, finish := gr.text()
+	promptTokens, completionTokens := 0, 0
+	if gr.UsageMetadata != nil {
+		promptTokens, completionTokens = gr.UsageMetadata.PromptTokenCount, gr.UsageMetadata.CandidatesTokenCount
+	}
+	model := gr.ModelVersion
+	if model == "" {
+		model = req.Model
+	}
+	return newChatCompletionResponse(gr.ResponseID, model, content, finish, promptTokens, completionTokens), nil
+}
+
+// CreateChatCompletionStream sends a streaming chat completion request. Each
+// server-sent event is a partial response; the latest usage is attached to
+// every chunk that reports it.
+func (p *GeminiProvider) CreateChatCompletionStream(ctx context.Context, req *ChatCompletionRequest, handler StreamHandler) error {
+	httpReq, err := p.newRequest(ctx, http.MethodPost, geminiModelPath(req.Model, "streamGenerateContent")+"?alt=sse", p.buildRequest(req))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.streamingClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, body)
+	}
+
+	return readSSE(resp.Body, func(event, data string) error {
+		var gr geminiResponse
+		if err := json.Unmarshal([]byte(data), &gr); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		model := gr.ModelVersion
+		if model == "" {
+			model = req.Model
+		}
+		content, finish := gr.text()
+		chunk := newStreamChunk(gr.ResponseID, model, content, finish)
+		if u := gr.UsageMetadata; u != nil {
+			chunk.Usage = &StreamUsage{
+				PromptTokens:     u.PromptTokenCount,
+				CompletionTokens: u.CandidatesTokenCount,
+				TotalTokens:      u.PromptTokenCount + u.CandidatesTokenCount,
+			}
+		}
+		return handler(chunk)
+	})
+}
+
+// GetModels lists the models that support generateContent. MaxModelLen is
+// the model's input token limit.
+func (p *GeminiProvider) GetModels(ctx context.Context) ([]Model, error) {
+	var models []Model
+	pageToken := ""
+	for {
+		path := "/models?pageSize=1000"
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		httpReq, err := p.newRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.client.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, statusError(resp.StatusCode, respBody)
+		}
+
+		var page struct {
+			Models []struct {
+				Name                       string   `json:"name"`
+				InputTokenLimit            int      `json:"inputTokenLimit"`
+				SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+			} `json:"models"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		for _, m := range page.Models {
+			if !containsString(m.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			models = append(models, Model{
+				ID:          strings.TrimPrefix(m.Name, "models/"),
+				Object:      "model",
+				OwnedBy:     "google",
+				MaxModelLen: m.InputTokenLimit,
+			})
+		}
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// geminiFinishReason maps a Gemini finishReason onto the OpenAI values.
+func geminiFinishReason(reason string) string {
+	switch reason {
+	case "", "FINISH_REASON_UNSPECIFIED":
+		return ""
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiProvider_CreateChatCompletion(t *testing.T) {
+	var got geminiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("missing api key header")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{
+			"candidates": [{"content": {"role": "model", "parts": [{"functionCall": {"name": "search", "args": {"query": "TODO"}}}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 8, "candidatesTokenCount": 3},
+			"modelVersion": "gemini-test-001"
+		}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewGeminiProvider(server.URL, "test-key").CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model: "models/gemini-test",
+		Messages: []ChatMessage{
+			{Role: "system", Content: "Be terse."},
+			{Role: "user", Content: "Find TODOs."},
+			{Role: "assistant", Content: "Looking."},
+			{Role: "user", Content: "Go on."},
+		},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	if got.SystemInstruction == nil || got.SystemInstruction.Parts[0].Text != "Be terse." {
+		t.Errorf("system prompt not sent as systemInstruction: %+v", got.SystemInstruction)
+	}
+	if len(got.Contents) != 3 || got.Contents[1].Role != "model" {
+		t.Errorf("unexpected contents: %+v", got.Contents)
+	}
+	if got.GenerationConfig == nil || got.GenerationConfig.ResponseMIMEType != "application/json" {
+		t.Errorf("json_object should set responseMimeType: %+v", got.GenerationConfig)
+	}
+
+	var action map[string]interface{}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &action); err != nil {
+		t.Fatalf("content is not JSON: %s", resp.Choices[0].Message.Content)
+	}
+	if action["action"] != "search" || action["query"] != "TODO" {
+		t.Errorf("function call not mapped to an action: %v", action)
+	}
+	if resp.Model != "gemini-test-001" || resp.Usage.TotalTokens != 11 {
+		t.Errorf("unexpected model/usage: %s %+v", resp.Model, resp.Usage)
+	}
+}
+
+func TestGeminiProvider_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("unexpected url %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}`,
+			`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2}}`,
+		}
+		_, _ = w.Write([]byte(strings.Join(events, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	resp, err := CollectStream(context.Background(), NewGeminiProvider(server.URL, "k"), &ChatCompletionRequest{
+		Model:    "gemini-test",
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hello" || resp.Choices[0].Finish != "stop" {
+		t.Errorf("unexpected response: %+v", resp.Choices[0])
+	}
+	if resp.Usage.TotalTokens != 6 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestGeminiProvider_GetModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[
+			{"name":"models/gemini-pro","inputTokenLimit":1048576,"supportedGenerationMethods":["generateContent","countTokens"]},
+			{"name":"models/text-embedding","inputTokenLimit":2048,"supportedGenerationMethods":["embedContent"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewGeminiProvider(server.URL, "k").GetModels(context.Background())
+	if err != nil {
+		t.Fatalf("GetModels failed: %v", err)
+	}
+	if len(models) != 1 || models[0].ID != "gemini-pro" || models[0].MaxModelLen != 1048576 {
+		t.Errorf("unexpected models: %+v", models)
+	}
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Helpers shared by the native (non-OpenAI) protocol implementations. Each
+// native protocol translates to and from the OpenAI-shaped request and
+// response types so the rest of loom never sees the difference.
+
+// jsonToolName is the tool native providers are made to call when a request
+// asks for response_format json_object. The tool's input is the JSON object,
+// which is how Anthropic recommends getting structured output.
+const jsonToolName = "json_response"
+
+// newHTTPClients returns the request and streaming clients used by the
+// native protocols, configured like OpenAIProvider's.
+func newHTTPClients() (client, streaming *http.Client) {
+	client = &http.Client{Timeout: 5 * time.Minute}
+	streaming = &http.Client{
+		Timeout: 0,
+		Transport: &http.Transport{
+			ResponseHeaderTimeout: 2 * time.Minute,
+			IdleConnTimeout:       10 * time.Minute,
+		},
+	}
+	return client, streaming
+}
+
+// statusError converts a non-200 response into an error, recognising
+// context-window overflows the same way the OpenAI protocol does.
+func statusError(statusCode int, body []byte) error {
+	bodyStr := string(body)
+	if statusCode == http.StatusBadRequest && isContextLengthError(bodyStr) {
+		return &ContextLengthError{StatusCode: statusCode, Body: bodyStr}
+	}
+	return fmt.Errorf("unexpected status code %d: %s", statusCode, bodyStr)
+}
+
+// splitSystemMessages separates system messages, which native APIs take as a
+// top-level field, from the conversation.
+func splitSystemMessages(messages []ChatMessage) (string, []ChatMessage) {
+	var system []string
+	rest := make([]ChatMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			if m.Content != "" {
+				system = append(system, m.Content)
+			}
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return strings.Join(system, "\n\n"), rest
+}
+
+// alternateTurns coerces a conversation into the strict user/assistant
+// alternation native APIs require: unknown roles become user, empty messages
+// are dropped, consecutive messages from the same side are merged, and a
+// conversation that opens with the assistant gets a user turn in front.
+func alternateTurns(messages []ChatMessage) []ChatMessage {
+	var turns []ChatMessage
+	for _, m := range messages {
+		if m.Content == "" {
+			continue
+		}
+		role := m.Role
+		if role != "assistant" {
+			role = "user"
+		}
+		if n := len(turns); n > 0 && turns[n-1].Role == role {
+			turns[n-1].Content += "\n\n" + m.Content
+			continue
+		}
+		turns = append(turns, ChatMessage{Role: role, Content: m.Content})
+	}
+	if len(turns) == 0 || turns[0].Role != "user" {
+		turns = append([]ChatMessage{{Role: "user", Content: "Continue."}}, turns...)
+	}
+	return turns
+}
+
+// toolCallContent maps a native tool call onto the actions JSON format. The
+// forced json_response tool already carries the object; any other tool
+// becomes {"action": name, ...arguments}.
+func toolCallContent(name string, input json.RawMessage) string {
+	if name == jsonToolName {
+		return string(input)
+	}
+	args := map[string]interface{}{}
+	if len(input) > 0 {
+		_ = json.Unmarshal(input, &args)
+	}
+	args["action"] = name
+	data, _ := json.Marshal(args)
+	return string(data)
+}
+
+// newChatCompletionResponse builds a single-choice response.
+func newChatCompletionResponse(id, model, content, finish string, promptTokens, completionTokens int) *ChatCompletionResponse {
+	resp := &ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+	}
+	resp.Choices = append(resp.Choices, struct {
+		Index   int         `json:"index"`
+		Message ChatMessage `json:"message"`
+		Finish  string      `json:"finish_reason"`
+	}{
+		Message: ChatMessage{Role: "assistant", Content: content},
+		Finish:  finish,
+	})
+	resp.Usage.PromptTokens = promptTokens
+	resp.Usage.CompletionTokens = completionTokens
+	resp.Usage.TotalTokens = promptTokens + completionTokens
+	return resp
+}
+
+// newStreamChunk builds a single-choice stream chunk.
+func newStreamChunk(id, model, content, finish string) *StreamChunk {
+	chunk := &StreamChunk{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   model,
+	}
+	chunk.Choices = make([]struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role    string `json:"role,omitempty"`
+			Content string `json:"content,omitempty"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason,omitempty"`
+	}, 1)
+	chunk.Choices[0].Delta.Content = content
+	chunk.Choices[0].FinishReason = finish
+	return chunk
+}
+
+// readSSE calls fn with the event name and data of each server-sent event.
+func readSSE(body io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		return fn(event, strings.Join(data, "\n"))
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream read error: %w", err)
+	}
+	return dispatch()
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAlternateTurns(t *testing.T) {
+	got := alternateTurns([]ChatMessage{
+		{Role: "assistant", Content: "earlier reply"},
+		{Role: "user", Content: "a"},
+		{Role: "tool", Content: "b"},
+		{Role: "user", Content: ""},
+		{Role: "assistant", Content: "c"},
+	})
+	want := []ChatMessage{
+		{Role: "user", Content: "Continue."},
+		{Role: "assistant", Content: "earlier reply"},
+		{Role: "user", Content: "a\n\nb"},
+		{Role: "assistant", Content: "c"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d turns, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestToolCallContent(t *testing.T) {
+	if got := toolCallContent(jsonToolName, json.RawMessage(`{"action":"read","path":"a.go"}`)); got != `{"action":"read","path":"a.go"}` {
+		t.Errorf("json tool input should pass through, got %s", got)
+	}
+
+	var action map[string]interface{}
+	if err := json.Unmarshal([]byte(toolCallContent("search", json.RawMessage(`{"query":"TODO"}`))), &action); err != nil {
+		t.Fatal(err)
+	}
+	if action["action"] != "search" || action["query"] != "TODO" {
+		t.Errorf("unexpected mapped action: %v", action)
+	}
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/cache"
+)
+
+type promptCacheKey struct{}
+
+// WithPromptCache marks completions made with ctx as idempotent: the same
+// prompt to the same model may be answered from the prompt cache instead
+// of the provider. Only steps whose answer doesn't depend on when they are
+// asked should opt in.
+func WithPromptCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, promptCacheKey{}, true)
+}
+
+func promptCacheAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(promptCacheKey{}).(bool)
+	return allowed
+}
+
+// PromptCache caches chat completions by provider, model, and prompt.
+type PromptCache struct {
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewPromptCache creates a prompt cache holding at most maxSize responses
+// for ttl each.
+func NewPromptCache(ttl time.Duration, maxSize int) *PromptCache {
+	return &PromptCache{
+		cache: cache.New(&cache.Config{
+			Enabled:       true,
+			DefaultTTL:    ttl,
+			MaxSize:       maxSize,
+			CleanupPeriod: 5 * time.Minute,
+		}),
+		ttl: ttl,
+	}
+}
+
+// promptKey hashes everything about req that shapes the response: the
+// model, the system and conversation messages, and the sampling settings.
+func promptKey(providerID string, req *ChatCompletionRequest) (string, error) {
+	return cache.GenerateKey(providerID, req.Model, struct {
+		Messages       []ChatMessage   `json:"messages"`
+		Temperature    float64         `json:"temperature"`
+		MaxTokens      int             `json:"max_tokens"`
+		ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	}{req.Messages, req.Temperature, req.MaxTokens, req.ResponseFormat})
+}
+
+// get returns the cached response to req, if any.
+func (p *PromptCache) get(ctx context.Context, providerID string, req *ChatCompletionRequest) (*ChatCompletionResponse, bool) {
+	key, err := promptKey(providerID, req)
+	if err != nil {
+		return nil, false
+	}
+	entry, ok := p.cache.Get(ctx, key)
+	if !ok {
+		return nil, false
+	}
+	// Responses are stored as JSON so every cache backend returns them the
+	// same way, and callers get their own copy.
+	raw, _ := entry.Response.(string)
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// put caches resp as the response to req.
+func (p *PromptCache) put(ctx context.Context, providerID string, req *ChatCompletionRequest, resp *ChatCompletionResponse) {
+	key, err := promptKey(providerID, req)
+	if err != nil {
+		return
+	}
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	_ = p.cache.Set(ctx, key, string(raw), p.ttl, map[string]interface{}{
+		"provider_id":  providerID,
+		"model_name":   req.Model,
+		"total_tokens": resp.Usage.TotalTokens,
+	})
+}
+
+// Stats returns the cache's hit and miss counts and the tokens it saved.
+func (p *PromptCache) Stats(ctx context.Context) *cache.Stats {
+	return p.cache.GetStats(ctx)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingProtocol struct {
+	Protocol
+	calls int
+}
+
+func (c *countingProtocol) CreateChatCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	c.calls++
+	resp, err := c.Protocol.CreateChatCompletion(ctx, req)
+	if resp != nil {
+		resp.Usage.TotalTokens = 40
+	}
+	return resp, err
+}
+
+func TestRegistrySendChatCompletion_PromptCache(t *testing.T) {
+	r := NewRegistry()
+	_ = r.Upsert(&ProviderConfig{ID: "mock1", Type: "mock", Model: "mock-model", Status: "healthy"})
+	rp, _ := r.Get("mock1")
+	counter := &countingProtocol{Protocol: rp.Protocol}
+	rp.Protocol = counter
+	r.SetPromptCache(NewPromptCache(time.Hour, 10))
+
+	ask := func(ctx context.Context, prompt string) string {
+		t.Helper()
+		resp, err := r.SendChatCompletion(ctx, "mock1", &ChatCompletionRequest{
+			Messages: []ChatMessage{{Role: "system", Content: "be brief"}, {Role: "user", Content: prompt}},
+		})
+		if err != nil {
+			t.Fatalf("SendChatCompletion: %v", err)
+		}
+		return resp.Choices[0].Message.Content
+	}
+
+	cached := WithPromptCache(context.Background())
+	first := ask(cached, "explain lint error E501")
+	if got := ask(cached, "explain lint error E501"); got != first || counter.calls != 1 {
+		t.Errorf("repeat answered %q after %d provider calls; want %q from the cache", got, counter.calls, first)
+	}
+	ask(cached, "explain lint error W291")
+	if counter.calls != 2 {
+		t.Errorf("different prompt made %d provider calls, want 2", counter.calls)
+	}
+	// Calls that didn't opt in always go to the provider.
+	ask(context.Background(), "explain lint error E501")
+	if counter.calls != 3 {
+		t.Errorf("uncached call made %d provider calls, want 3", counter.calls)
+	}
+
+	stats := r.PromptCache().Stats(context.Background())
+	if stats.Hits != 1 || stats.Misses != 2 || stats.TokensSaved != 40 {
+		t.Errorf("stats = %+v, want 1 hit, 2 misses, 40 tokens saved", stats)
+	}
+}
@@ -82,9 +82,17 @@ type ChatCompletionRequest struct {
 	Temperature    float64         `json:"temperature,omitempty"`
 	MaxTokens      int             `json:"max_tokens,omitempty"`
 	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
 	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 }
 
+// StreamOptions configures a streaming request. IncludeUsage asks the server
+// to send token usage in a final chunk so streamed calls can be accounted
+// for like non-streaming ones.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
 // ChatCompletionResponse represents a chat completion response
 type ChatCompletionResponse struct {
 	ID      string `json:"id"`
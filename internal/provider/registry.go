@@ -32,16 +32,21 @@ type Registry struct {
 	mu              sync.RWMutex
 	providers       map[string]*RegisteredProvider
 	metricsCallback MetricsCallback
+	router          *router
+	promptCache     *PromptCache
 }
 
 type RegisteredProvider struct {
 	Config   *ProviderConfig
 	Protocol Protocol
+
+	registry *Registry // receives ReportResult outcomes
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
 		providers: make(map[string]*RegisteredProvider),
+		router:    newRouter(),
 	}
 }
 
@@ -89,6 +94,7 @@ func (r *Registry) Register(config *ProviderConfig) error {
 	r.providers[config.ID] = &RegisteredProvider{
 		Config:   config,
 		Protocol: protocol,
+		registry: r,
 	}
 	return nil
 }
@@ -114,17 +120,30 @@ func (r *Registry) Upsert(config *ProviderConfig) error {
 		return nil
 	}
 
-	r.providers[config.ID] = &RegisteredProvider{Config: config, Protocol: protocol}
+	r.providers[config.ID] = &RegisteredProvider{Config: config, Protocol: protocol, registry: r}
 	return nil
 }
 
 func createProtocol(config *ProviderConfig) Protocol {
 	switch config.Type {
-	case "openai", "anthropic", "local", "custom", "vllm", "ollama", "tokenhub":
+	case "openai", "local", "custom", "vllm", "ollama", "tokenhub":
 		if config.APIKey == "" {
 			log.Printf("[Registry] Warning: API key is missing for provider %s", config.ID)
 		}
 		return NewOpenAIProvider(config.Endpoint, config.APIKey)
+	case "anthropic":
+		if config.APIKey == "" {
+			log.Printf("[Registry] Warning: API key is missing for provider %s", config.ID)
+		}
+		return NewAnthropicProvider(config.Endpoint, config.APIKey)
+	case "gemini":
+		if config.APIKey == "" {
+			log.Printf("[Registry] Warning: API key is missing for provider %s", config.ID)
+		}
+		return NewGeminiProvider(config.Endpoint, config.APIKey)
+	case "bedrock":
+		// Credentials may come from the environment, so a missing key is fine.
+		return NewBedrockProvider(config.Endpoint, config.APIKey)
 	case "mock":
 		return NewMockProvider()
 	default:
@@ -141,6 +160,7 @@ func (r *Registry) Unregister(providerID string) error {
 	}
 
 	delete(r.providers, providerID)
+	r.router.forget(providerID)
 	return nil
 }
 
@@ -209,20 +229,42 @@ func (r *Registry) SendChatCompletionStream(ctx context.Context, providerID stri
 		return fmt.Errorf("provider %s does not support streaming", providerID)
 	}
 
-	err = streamProvider.CreateChatCompletionStream(ctx, req, handler)
+	var totalTokens int64
+	err = streamProvider.CreateChatCompletionStream(ctx, req, func(chunk *StreamChunk) error {
+		if chunk.Usage != nil {
+			totalTokens = int64(chunk.Usage.TotalTokens)
+		}
+		return handler(chunk)
+	})
 
 	latencyMs := time.Since(start).Milliseconds()
+	r.router.record(providerID, err == nil)
 	if r.metricsCallback != nil {
 		errorCount := int64(0)
 		if err != nil {
 			errorCount = 1
 		}
-		r.metricsCallback(providerID, err == nil, latencyMs, 0, errorCount)
+		r.metricsCallback(providerID, err == nil, latencyMs, totalTokens, errorCount)
 	}
 
 	return err
 }
 
+// SetPromptCache sets the cache that answers completions marked with
+// WithPromptCache. A nil cache turns prompt caching off.
+func (r *Registry) SetPromptCache(pc *PromptCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.promptCache = pc
+}
+
+// PromptCache returns the prompt cache, or nil when there is none.
+func (r *Registry) PromptCache() *PromptCache {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.promptCache
+}
+
 func (r *Registry) SendChatCompletion(ctx context.Context, providerID string, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
 	startTime := time.Now()
 
@@ -238,6 +280,13 @@ func (r *Registry) SendChatCompletion(ctx context.Context, providerID string, re
 		req.Model = provider.Config.Model
 	}
 
+	pc := r.PromptCache()
+	if pc == nil || req.Stream || !promptCacheAllowed(ctx) {
+		pc = nil
+	} else if resp, ok := pc.get(ctx, providerID, req); ok {
+		return resp, nil
+	}
+
 	resp, err := provider.Protocol.CreateChatCompletion(ctx, req)
 
 	// If model not found (404), rediscover and retry once.
@@ -264,6 +313,9 @@ func (r *Registry) SendChatCompletion(ctx context.Context, providerID string, re
 	if resp != nil {
 		totalTokens = int64(resp.Usage.TotalTokens)
 	}
+	if pc != nil && success {
+		pc.put(ctx, providerID, req, resp)
+	}
 
 	r.RecordRequestMetrics(providerID, latencyMs, success)
 
@@ -291,6 +343,8 @@ func (r *Registry) GetModels(ctx context.Context, providerID string) ([]Model, e
 }
 
 func (r *Registry) RecordRequestMetrics(providerID string, latencyMs int64, success bool) {
+	r.router.record(providerID, success)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -79,7 +79,7 @@ func TestRegistryUpsert_UnsupportedType(t *testing.T) {
 }
 
 func TestRegistryUpsert_AllTypes(t *testing.T) {
-	types := []string{"openai", "anthropic", "local", "custom", "vllm", "ollama", "mock"}
+	types := []string{"openai", "anthropic", "gemini", "bedrock", "local", "custom", "vllm", "ollama", "mock"}
 	r := NewRegistry()
 	for _, tp := range types {
 		err := r.Upsert(&ProviderConfig{
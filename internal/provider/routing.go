@@ -0,0 +1,419 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RoutingStrategy selects how a policy orders healthy providers.
+type RoutingStrategy string
+
+const (
+	// StrategyFirstHealthy uses healthy providers in ID order. It is the
+	// behaviour when no policy is configured.
+	StrategyFirstHealthy RoutingStrategy = "first_healthy"
+	// StrategyPriority prefers providers with the lowest Priority value and
+	// fails over to the next one when a provider is unhealthy or tripped.
+	StrategyPriority RoutingStrategy = "priority"
+	// StrategyWeightedRoundRobin spreads work across providers in proportion
+	// to their Weight, failing over by priority when none are available.
+	StrategyWeightedRoundRobin RoutingStrategy = "weighted_round_robin"
+)
+
+// Circuit breaker defaults.
+const (
+	DefaultFailureThreshold = 5
+	DefaultCircuitCooldown  = 60 * time.Second
+)
+
+// ProviderRoute is one provider's place in a routing policy.
+type ProviderRoute struct {
+	ProviderID string `json:"provider_id"`
+	Priority   int    `json:"priority"`         // lower is preferred
+	Weight     int    `json:"weight,omitempty"` // weighted_round_robin share; defaults to 1
+}
+
+// RoutingPolicy controls which provider work is routed to. Providers that
+// are healthy but not listed are kept as a last-resort failover.
+type RoutingPolicy struct {
+	Strategy  RoutingStrategy `json:"strategy"`
+	Providers []ProviderRoute `json:"providers,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at,omitempty"`
+}
+
+// Validate checks the strategy and provider list.
+func (p *RoutingPolicy) Validate() error {
+	switch p.Strategy {
+	case StrategyFirstHealthy, StrategyPriority, StrategyWeightedRoundRobin:
+	case "":
+		return fmt.Errorf("strategy is required")
+	default:
+		return fmt.Errorf("unknown routing strategy %q (want priority, weighted_round_robin, or first_healthy)", p.Strategy)
+	}
+	seen := make(map[string]bool, len(p.Providers))
+	for _, route := range p.Providers {
+		if route.ProviderID == "" {
+			return fmt.Errorf("provider_id is required for every route")
+		}
+		if seen[route.ProviderID] {
+			return fmt.Errorf("provider %s listed more than once", route.ProviderID)
+		}
+		seen[route.ProviderID] = true
+		if route.Weight < 0 {
+			return fmt.Errorf("weight for %s must not be negative", route.ProviderID)
+		}
+	}
+	return nil
+}
+
+func (p *RoutingPolicy) clone() *RoutingPolicy {
+	c := *p
+	c.Providers = append([]ProviderRoute(nil), p.Providers...)
+	return &c
+}
+
+// CircuitState is the state of a provider's circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitStatus reports one provider's circuit breaker.
+type CircuitStatus struct {
+	ProviderID          string       `json:"provider_id"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"`
+}
+
+type circuit struct {
+	failures int
+	openedAt time.Time
+}
+
+// router holds routing policies and circuit breakers. It has its own lock so
+// that recording request outcomes never contends with the registry lock.
+type router struct {
+	mu               sync.Mutex
+	defaultPolicy    *RoutingPolicy
+	projectPolicies  map[string]*RoutingPolicy
+	wrrCurrent       map[string]map[string]int // policy key -> provider -> smooth WRR weight
+	circuits         map[string]*circuit
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+}
+
+func newRouter() *router {
+	return &router{
+		projectPolicies:  make(map[string]*RoutingPolicy),
+		wrrCurrent:       make(map[string]map[string]int),
+		circuits:         make(map[string]*circuit),
+		failureThreshold: DefaultFailureThreshold,
+		cooldown:         DefaultCircuitCooldown,
+		now:              time.Now,
+	}
+}
+
+// SetPolicy installs a routing policy for a project, or the default policy
+// when projectID is empty.
+func (r *Registry) SetPolicy(projectID string, policy *RoutingPolicy) error {
+	if policy == nil {
+		return fmt.Errorf("policy is required")
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	p := policy.clone()
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = time.Now().UTC()
+	}
+
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if projectID == "" {
+		rt.defaultPolicy = p
+	} else {
+		rt.projectPolicies[projectID] = p
+	}
+	delete(rt.wrrCurrent, projectID)
+	return nil
+}
+
+// RemovePolicy removes a project's policy (or the default policy when
+// projectID is empty) so that routing falls back to the next level.
+func (r *Registry) RemovePolicy(projectID string) {
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if projectID == "" {
+		rt.defaultPolicy = nil
+	} else {
+		delete(rt.projectPolicies, projectID)
+	}
+	delete(rt.wrrCurrent, projectID)
+}
+
+// Policy returns the effective policy for a project: its own policy, else
+// the default policy, else first_healthy.
+func (r *Registry) Policy(projectID string) *RoutingPolicy {
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	_, p := rt.effectivePolicy(projectID)
+	return p.clone()
+}
+
+// Policies returns the default policy (nil if unset) and per-project
+// overrides.
+func (r *Registry) Policies() (*RoutingPolicy, map[string]*RoutingPolicy) {
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	var def *RoutingPolicy
+	if rt.defaultPolicy != nil {
+		def = rt.defaultPolicy.clone()
+	}
+	projects := make(map[string]*RoutingPolicy, len(rt.projectPolicies))
+	for id, p := range rt.projectPolicies {
+		projects[id] = p.clone()
+	}
+	return def, projects
+}
+
+// SetCircuitBreaker configures how many consecutive failures open a
+// provider's circuit and how long it stays open before a trial request.
+func (r *Registry) SetCircuitBreaker(failureThreshold int, cooldown time.Duration) {
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if failureThreshold > 0 {
+		rt.failureThreshold = failureThreshold
+	}
+	if cooldown > 0 {
+		rt.cooldown = cooldown
+	}
+}
+
+// CircuitStatuses reports the circuit breaker state of every registered
+// provider, sorted by provider ID.
+func (r *Registry) CircuitStatuses() []CircuitStatus {
+	providers := r.List()
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	statuses := make([]CircuitStatus, 0, len(providers))
+	for _, p := range providers {
+		if p == nil || p.Config == nil {
+			continue
+		}
+		id := p.Config.ID
+		st := CircuitStatus{ProviderID: id, State: rt.stateLocked(id)}
+		if c := rt.circuits[id]; c != nil {
+			st.ConsecutiveFailures = c.failures
+			if !c.openedAt.IsZero() {
+				opened := c.openedAt
+				retry := opened.Add(rt.cooldown)
+				st.OpenedAt, st.RetryAt = &opened, &retry
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ProviderID < statuses[j].ProviderID })
+	return statuses
+}
+
+// Available reports whether a provider is healthy and its circuit is not open.
+func (r *Registry) Available(providerID string) bool {
+	if !r.IsActive(providerID) {
+		return false
+	}
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.stateLocked(providerID) != CircuitOpen
+}
+
+// SelectProvider returns the provider a project's work should be routed to,
+// or nil if no provider is available.
+func (r *Registry) SelectProvider(projectID string) *RegisteredProvider {
+	candidates := r.Candidates(projectID)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// Candidates returns the available providers for a project in the order
+// they should be tried: the policy's choice first, then failovers. Providers
+// whose circuit is open are left out.
+func (r *Registry) Candidates(projectID string) []*RegisteredProvider {
+	active := r.ListActive()
+	rt := r.router
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	byID := make(map[string]*RegisteredProvider, len(active))
+	for _, p := range active {
+		if rt.stateLocked(p.Config.ID) != CircuitOpen {
+			byID[p.Config.ID] = p
+		}
+	}
+	if len(byID) == 0 {
+		return nil
+	}
+
+	key, policy := rt.effectivePolicy(projectID)
+	ordered := rt.orderLocked(key, policy, byID)
+
+	result := make([]*RegisteredProvider, 0, len(ordered))
+	for _, id := range ordered {
+		result = append(result, byID[id])
+	}
+	return result
+}
+
+// ReportResult records the outcome of a request made directly through the
+// provider's Protocol, feeding the registry's circuit breaker.
+func (p *RegisteredProvider) ReportResult(err error) {
+	if p == nil || p.registry == nil || p.Config == nil {
+		return
+	}
+	p.registry.router.record(p.Config.ID, err == nil)
+}
+
+func (rt *router) effectivePolicy(projectID string) (string, *RoutingPolicy) {
+	if p, ok := rt.projectPolicies[projectID]; ok && projectID != "" {
+		return projectID, p
+	}
+	if rt.defaultPolicy != nil {
+		return "", rt.defaultPolicy
+	}
+	return "", &RoutingPolicy{Strategy: StrategyFirstHealthy}
+}
+
+// orderLocked returns provider IDs from available in routing order.
+func (rt *router) orderLocked(key string, policy *RoutingPolicy, available map[string]*RegisteredProvider) []string {
+	routes := make(map[string]ProviderRoute, len(policy.Providers))
+	for _, route := range policy.Providers {
+		routes[route.ProviderID] = route
+	}
+
+	ids := make([]string, 0, len(available))
+	for id := range available {
+		ids = append(ids, id)
+	}
+	// Listed providers by priority, then unlisted ones; ties by ID.
+	sort.Slice(ids, func(i, j int) bool {
+		ri, iListed := routes[ids[i]]
+		rj, jListed := routes[ids[j]]
+		if iListed != jListed {
+			return iListed
+		}
+		if policy.Strategy != StrategyFirstHealthy && ri.Priority != rj.Priority {
+			return ri.Priority < rj.Priority
+		}
+		return ids[i] < ids[j]
+	})
+
+	if policy.Strategy != StrategyWeightedRoundRobin {
+		return ids
+	}
+
+	// Smooth weighted round-robin (as in nginx): every candidate gains its
+	// weight, the largest is picked and loses the total.
+	weight := func(id string) int {
+		if len(policy.Providers) == 0 {
+			return 1
+		}
+		route, ok := routes[id]
+		if !ok {
+			return 0
+		}
+		if route.Weight == 0 {
+			return 1
+		}
+		return route.Weight
+	}
+	current := rt.wrrCurrent[key]
+	if current == nil {
+		current = make(map[string]int)
+		rt.wrrCurrent[key] = current
+	}
+	total, best := 0, ""
+	for _, id := range ids {
+		w := weight(id)
+		if w == 0 {
+			continue
+		}
+		current[id] += w
+		total += w
+		if best == "" || current[id] > current[best] {
+			best = id
+		}
+	}
+	if best == "" {
+		return ids
+	}
+	current[best] -= total
+
+	ordered := make([]string, 0, len(ids))
+	ordered = append(ordered, best)
+	for _, id := range ids {
+		if id != best {
+			ordered = append(ordered, id)
+		}
+	}
+	return ordered
+}
+
+// stateLocked derives a provider's circuit state. An open circuit becomes
+// half-open once the cooldown has elapsed, allowing a trial request.
+func (rt *router) stateLocked(providerID string) CircuitState {
+	c := rt.circuits[providerID]
+	if c == nil || c.openedAt.IsZero() {
+		return CircuitClosed
+	}
+	if rt.now().Sub(c.openedAt) >= rt.cooldown {
+		return CircuitHalfOpen
+	}
+	return CircuitOpen
+}
+
+// record updates a provider's circuit with a request outcome.
+func (rt *router) record(providerID string, success bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	c := rt.circuits[providerID]
+	if c == nil {
+		c = &circuit{}
+		rt.circuits[providerID] = c
+	}
+	if success {
+		c.failures = 0
+		c.openedAt = time.Time{}
+		return
+	}
+
+	state := rt.stateLocked(providerID)
+	c.failures++
+	if state == CircuitHalfOpen || (state == CircuitClosed && c.failures >= rt.failureThreshold) {
+		c.openedAt = rt.now()
+	}
+}
+
+// forget drops circuit state for a provider that is no longer registered.
+func (rt *router) forget(providerID string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	delete(rt.circuits, providerID)
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newRoutingRegistry(t *testing.T, ids ...string) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	for _, id := range ids {
+		if err := r.Upsert(&ProviderConfig{ID: id, Name: id, Type: "mock", Status: "healthy"}); err != nil {
+			t.Fatalf("Upsert %s: %v", id, err)
+		}
+	}
+	return r
+}
+
+func candidateIDs(r *Registry, projectID string) []string {
+	var ids []string
+	for _, p := range r.Candidates(projectID) {
+		ids = append(ids, p.Config.ID)
+	}
+	return ids
+}
+
+func TestRouting_DefaultIsFirstHealthy(t *testing.T) {
+	r := newRoutingRegistry(t, "c", "a", "b")
+	if got := r.SelectProvider("proj"); got == nil || got.Config.ID != "a" {
+		t.Fatalf("expected a, got %v", got)
+	}
+	if p := r.Policy("proj"); p.Strategy != StrategyFirstHealthy {
+		t.Errorf("expected first_healthy fallback, got %s", p.Strategy)
+	}
+}
+
+func TestRouting_PriorityFailover(t *testing.T) {
+	r := newRoutingRegistry(t, "primary", "secondary", "spare")
+	err := r.SetPolicy("", &RoutingPolicy{
+		Strategy: StrategyPriority,
+		Providers: []ProviderRoute{
+			{ProviderID: "secondary", Priority: 1},
+			{ProviderID: "primary", Priority: 0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+
+	got := candidateIDs(r, "proj")
+	want := []string{"primary", "secondary", "spare"}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("candidates = %v, want %v", got, want)
+		}
+	}
+
+	// An unhealthy primary fails over to the next priority.
+	p, _ := r.Get("primary")
+	p.Config.Status = "unhealthy"
+	if sel := r.SelectProvider("proj"); sel.Config.ID != "secondary" {
+		t.Errorf("expected failover to secondary, got %s", sel.Config.ID)
+	}
+}
+
+func TestRouting_ProjectPolicyOverridesDefault(t *testing.T) {
+	r := newRoutingRegistry(t, "a", "b")
+	_ = r.SetPolicy("", &RoutingPolicy{Strategy: StrategyPriority, Providers: []ProviderRoute{{ProviderID: "a"}}})
+	_ = r.SetPolicy("proj-b", &RoutingPolicy{Strategy: StrategyPriority, Providers: []ProviderRoute{{ProviderID: "b"}}})
+
+	if sel := r.SelectProvider("proj-a"); sel.Config.ID != "a" {
+		t.Errorf("proj-a: expected default policy to pick a, got %s", sel.Config.ID)
+	}
+	if sel := r.SelectProvider("proj-b"); sel.Config.ID != "b" {
+		t.Errorf("proj-b: expected project policy to pick b, got %s", sel.Config.ID)
+	}
+
+	r.RemovePolicy("proj-b")
+	if sel := r.SelectProvider("proj-b"); sel.Config.ID != "a" {
+		t.Errorf("proj-b after removal: expected a, got %s", sel.Config.ID)
+	}
+}
+
+func TestRouting_WeightedRoundRobin(t *testing.T) {
+	r := newRoutingRegistry(t, "big", "small")
+	_ = r.SetPolicy("", &RoutingPolicy{
+		Strategy: StrategyWeightedRoundRobin,
+		Providers: []ProviderRoute{
+			{ProviderID: "big", Weight: 3},
+			{ProviderID: "small", Weight: 1},
+		},
+	})
+
+	counts := map[string]int{}
+	for i := 0; i < 40; i++ {
+		counts[r.SelectProvider("proj").Config.ID]++
+	}
+	if counts["big"] != 30 || counts["small"] != 10 {
+		t.Errorf("expected 30/10 split, got %v", counts)
+	}
+}
+
+func TestRouting_CircuitBreaker(t *testing.T) {
+	r := newRoutingRegistry(t, "a", "b")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.router.now = func() time.Time { return now }
+	r.SetCircuitBreaker(2, time.Minute)
+
+	a, _ := r.Get("a")
+	a.ReportResult(errors.New("boom"))
+	if !r.Available("a") {
+		t.Fatal("circuit should stay closed below the threshold")
+	}
+	a.ReportResult(errors.New("boom"))
+	if r.Available("a") {
+		t.Fatal("circuit should open at the threshold")
+	}
+	if sel := r.SelectProvider("proj"); sel.Config.ID != "b" {
+		t.Errorf("expected failover to b while a is open, got %s", sel.Config.ID)
+	}
+
+	// After the cooldown a trial request is allowed; a failure reopens it.
+	now = now.Add(time.Minute)
+	if st := r.CircuitStatuses()[0]; st.ProviderID != "a" || st.State != CircuitHalfOpen {
+		t.Fatalf("expected a half-open, got %+v", st)
+	}
+	a.ReportResult(errors.New("still down"))
+	if r.Available("a") {
+		t.Fatal("failed trial should reopen the circuit")
+	}
+
+	// A successful trial closes it.
+	now = now.Add(time.Minute)
+	a.ReportResult(nil)
+	if st := r.CircuitStatuses()[0]; st.State != CircuitClosed || st.ConsecutiveFailures != 0 {
+		t.Errorf("expected closed circuit after success, got %+v", st)
+	}
+}
+
+func TestRoutingPolicy_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  RoutingPolicy
+		wantErr bool
+	}{
+		{"valid", RoutingPolicy{Strategy: StrategyPriority, Providers: []ProviderRoute{{ProviderID: "a"}}}, false},
+		{"missing strategy", RoutingPolicy{}, true},
+		{"unknown strategy", RoutingPolicy{Strategy: "random"}, true},
+		{"duplicate provider", RoutingPolicy{Strategy: StrategyPriority, Providers: []ProviderRoute{{ProviderID: "a"}, {ProviderID: "a"}}}, true},
+		{"negative weight", RoutingPolicy{Strategy: StrategyWeightedRoundRobin, Providers: []ProviderRoute{{ProviderID: "a", Weight: -1}}}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.policy.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
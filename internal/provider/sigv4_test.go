@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4_Vanilla checks the "get-vanilla" case from the AWS SigV4 test suite.
+func TestSignV4_Vanilla(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n  %s\nwant\n  %s", got, want)
+	}
+	if req.Header.Get("X-Amz-Date") != "20150830T123600Z" {
+		t.Errorf("unexpected X-Amz-Date %q", req.Header.Get("X-Amz-Date"))
+	}
+}
+
+func TestSignV4_SessionToken(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/a%3A0/converse", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	signV4(req, []byte("{}"), awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "tok"},
+		"us-east-1", "bedrock", time.Now())
+
+	if req.Header.Get("X-Amz-Security-Token") != "tok" {
+		t.Error("session token header not set")
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers: %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestAWSURIEncode(t *testing.T) {
+	if got := awsURIEncode("/model/anthropic.claude-v2:1/converse", false); got != "/model/anthropic.claude-v2%3A1/converse" {
+		t.Errorf("got %s", got)
+	}
+	if got := awsURIEncode("a b/c~", true); got != "a%20b%2Fc~" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -24,6 +24,16 @@ type StreamChunk struct {
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason,omitempty"`
 	} `json:"choices"`
+	// Usage is only set on the final chunk, and only when the server honours
+	// stream_options.include_usage.
+	Usage *StreamUsage `json:"usage,omitempty"`
+}
+
+// StreamUsage is the token usage reported at the end of a stream
+type StreamUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // StreamHandler handles streaming responses
@@ -31,8 +41,11 @@ type StreamHandler func(chunk *StreamChunk) error
 
 // CreateChatCompletionStream sends a streaming chat completion request
 func (p *OpenAIProvider) CreateChatCompletionStream(ctx context.Context, req *ChatCompletionRequest, handler StreamHandler) error {
-	// Ensure stream is enabled
+	// Ensure stream is enabled and ask for usage so tokens can be accounted
 	req.Stream = true
+	if req.StreamOptions == nil {
+		req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
 
 	url := fmt.Sprintf("%s/chat/completions", p.endpoint)
 
@@ -152,3 +165,67 @@ func (p *OpenAIProvider) readStreamingResponse(ctx context.Context, reader io.Re
 
 	return nil
 }
+
+// CollectStream runs a streaming completion, passing each content delta to
+// onDelta as it arrives, and assembles the chunks into a regular response.
+// When the server does not report usage, tokens are estimated at roughly
+// four characters per token so that analytics still sees the call.
+func CollectStream(ctx context.Context, sp StreamingProtocol, req *ChatCompletionRequest, onDelta func(delta string)) (*ChatCompletionResponse, error) {
+	streamReq := *req
+	var (
+		content strings.Builder
+		role    = "assistant"
+		finish  string
+		usage   *StreamUsage
+		resp    = &ChatCompletionResponse{Object: "chat.completion"}
+	)
+	err := sp.CreateChatCompletionStream(ctx, &streamReq, func(chunk *StreamChunk) error {
+		if resp.ID == "" {
+			resp.ID, resp.Created, resp.Model = chunk.ID, chunk.Created, chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Role != "" {
+			role = delta.Role
+		}
+		if chunk.Choices[0].FinishReason != "" {
+			finish = chunk.Choices[0].FinishReason
+		}
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			if onDelta != nil {
+				onDelta(delta.Content)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Choices = append(resp.Choices, struct {
+		Index   int         `json:"index"`
+		Message ChatMessage `json:"message"`
+		Finish  string      `json:"finish_reason"`
+	}{
+		Message: ChatMessage{Role: role, Content: content.String()},
+		Finish:  finish,
+	})
+	if usage != nil {
+		resp.Usage.PromptTokens = usage.PromptTokens
+		resp.Usage.CompletionTokens = usage.CompletionTokens
+		resp.Usage.TotalTokens = usage.TotalTokens
+	} else {
+		for _, m := range req.Messages {
+			resp.Usage.PromptTokens += len(m.Content) / 4
+		}
+		resp.Usage.CompletionTokens = content.Len() / 4
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	}
+	return resp, nil
+}
@@ -136,3 +136,63 @@ func TestStreamingContextCancellation(t *testing.T) {
 		t.Errorf("Expected 1 chunk before cancellation, got %d", chunkCount)
 	}
 }
+
+func TestCollectStream(t *testing.T) {
+	cases := []struct {
+		name      string
+		usage     string
+		wantTotal int
+	}{
+		{"server usage", `data: {"id":"1","object":"chat.completion.chunk","created":1234,"model":"test","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3,"total_tokens":10}}`, 10},
+		{"estimated usage", "", (len("Hello there") + len("Hello world!")) / 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				chunks := []string{
+					`data: {"id":"1","object":"chat.completion.chunk","created":1234,"model":"test","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+					`data: {"id":"1","object":"chat.completion.chunk","created":1234,"model":"test","choices":[{"index":0,"delta":{"content":" world!"},"finish_reason":"stop"}]}`,
+				}
+				if tc.usage != "" {
+					chunks = append(chunks, tc.usage)
+				}
+				chunks = append(chunks, `data: [DONE]`)
+				for _, chunk := range chunks {
+					_, _ = w.Write([]byte(chunk + "\n\n"))
+				}
+			}))
+			defer server.Close()
+
+			req := &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []ChatMessage{{Role: "user", Content: "Hello there"}},
+			}
+			var deltas []string
+			resp, err := CollectStream(context.Background(), NewOpenAIProvider(server.URL, "test-key"), req, func(d string) {
+				deltas = append(deltas, d)
+			})
+			if err != nil {
+				t.Fatalf("CollectStream failed: %v", err)
+			}
+			if strings.Join(deltas, "") != "Hello world!" {
+				t.Errorf("unexpected deltas: %q", deltas)
+			}
+			if resp.ID != "1" || len(resp.Choices) != 1 {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			if resp.Choices[0].Message.Content != "Hello world!" || resp.Choices[0].Message.Role != "assistant" {
+				t.Errorf("unexpected message: %+v", resp.Choices[0].Message)
+			}
+			if resp.Choices[0].Finish != "stop" {
+				t.Errorf("expected finish_reason stop, got %q", resp.Choices[0].Finish)
+			}
+			if resp.Usage.TotalTokens != tc.wantTotal {
+				t.Errorf("expected %d total tokens, got %d", tc.wantTotal, resp.Usage.TotalTokens)
+			}
+			if req.Stream || req.StreamOptions != nil {
+				t.Error("CollectStream should not modify the caller's request")
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ type Activities struct {
 	dispatcher *dispatch.Dispatcher
 	beadsMgr   *beads.Manager
 	agentMgr   *agent.WorkerManager
+	rootCause  Completer
 }
 
 // New creates a new Activities instance.
@@ -35,8 +36,9 @@ func New(db *database.Database, d *dispatch.Dispatcher, b *beads.Manager, a *age
 }
 
 // Beat executes one Ralph Loop beat.
-// Each beat: resets stuck agents, resolves stuck beads, then auto-recovers
-// provider-blocked beads every 10 beats (~100s).
+// Each beat: resets stuck agents, resolves stuck beads, analyzes why newly
+// blocked beads keep failing, then auto-recovers provider-blocked beads
+// every 10 beats (~100s).
 func (a *Activities) Beat(ctx context.Context, beatCount int) error {
 	start := time.Now()
 	log.Printf("[Ralph] Beat %d: starting (dispatcher=%v agentMgr=%v beadsMgr=%v)", beatCount, a.dispatcher != nil, a.agentMgr != nil, a.beadsMgr != nil)
@@ -57,6 +59,12 @@ func (a *Activities) Beat(ctx context.Context, beatCount int) error {
 	// no-op to avoid double-dispatch.
 	dispatched := 0
 
+	// Phase 3b: Summarize blocked beads' errors into a root cause.
+	analyzed := a.analyzeBlockedBeads(ctx)
+	if analyzed > 0 {
+		log.Printf("[Ralph] Beat %d: analyzed the root cause of %d blocked bead(s)", beatCount, analyzed)
+	}
+
 	// Phase 4: Auto-recover beads blocked due to transient provider failures.
 	recovered := 0
 	if beatCount%10 == 0 {
@@ -132,9 +140,14 @@ func (a *Activities) autoRecoverProviderBlockedBeads() int {
 			"dispatch_count":       "0",
 			"loop_detected":        "",
 			"loop_detected_reason": "",
+			"loop_detected_by":     "",
 			"error_history":        "",
+			"action_history":       "",
 			"redispatch_requested": "true",
 		}
+		for _, k := range rootCauseKeys {
+			ctxReset[k] = ""
+		}
 		updates := map[string]interface{}{
 			"status":      models.BeadStatusOpen,
 			"assigned_to": "",
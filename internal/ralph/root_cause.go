@@ -0,0 +1,230 @@
+package ralph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Bead context keys holding the root-cause analysis of a blocked bead.
+const (
+	RootCauseKey            = "root_cause"
+	RootCauseRemediationKey = "root_cause_remediation"
+	RootCauseAtKey          = "root_cause_at"
+	// rootCauseForKey records which error history was analyzed, so a bead
+	// is analyzed again only once it fails differently.
+	rootCauseForKey = "root_cause_for"
+)
+
+// rootCauseKeys are cleared when a bead gets a fresh start.
+var rootCauseKeys = []string{RootCauseKey, RootCauseRemediationKey, RootCauseAtKey, rootCauseForKey}
+
+// rootCausePerBeat caps the analyses one beat makes.
+const rootCausePerBeat = 2
+
+// Limits on how much of a bead's error history is sent to the model.
+const (
+	maxErrorChars   = 2000
+	maxHistoryChars = 12000
+)
+
+const rootCausePrompt = `You diagnose why an autonomous coding agent keeps failing on a task.
+You are given the task and the errors of its recent runs, oldest first.
+Reply with JSON only, in this form:
+{"hypothesis": "<the most likely root cause, in one to three sentences>",
+ "remediation": ["<a concrete step an operator can take>", ...]}
+Name the failing component (provider, credentials, build, tests, repository, task description) when you can.
+Give at most four remediation steps, most useful first.`
+
+// Completer sends a system prompt and a user prompt to a model and returns
+// its reply.
+type Completer func(ctx context.Context, system, prompt string) (string, error)
+
+// NewRegistryCompleter returns a Completer that uses the given provider and
+// model, typically a cheap one. An empty model uses the provider's.
+func NewRegistryCompleter(registry *provider.Registry, providerID, model string) Completer {
+	return func(ctx context.Context, system, prompt string) (string, error) {
+		resp, err := registry.SendChatCompletion(ctx, providerID, &provider.ChatCompletionRequest{
+			Model: model,
+			Messages: []provider.ChatMessage{
+				{Role: "system", Content: system},
+				{Role: "user", Content: prompt},
+			},
+			Temperature: 0,
+			MaxTokens:   1024,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+			return "", fmt.Errorf("model returned no reply")
+		}
+		return resp.Choices[0].Message.Content, nil
+	}
+}
+
+// SetRootCauseAnalyzer sets the model that summarizes blocked beads' error
+// histories. Without one, blocked beads are not analyzed.
+func (a *Activities) SetRootCauseAnalyzer(c Completer) {
+	a.rootCause = c
+}
+
+// RootCause is a model's reading of why a bead keeps failing.
+type RootCause struct {
+	Hypothesis  string   `json:"hypothesis"`
+	Remediation []string `json:"remediation"`
+}
+
+// errorRecord is an entry of a bead's error_history.
+type errorRecord struct {
+	Timestamp string `json:"timestamp"`
+	Error     string `json:"error"`
+	Dispatch  int    `json:"dispatch"`
+}
+
+// analyzeBlockedBeads summarizes the error history of beads blocked after
+// repeated errors into a root-cause hypothesis and remediation steps, and
+// stores them in the bead's context. Each error history is analyzed once.
+func (a *Activities) analyzeBlockedBeads(ctx context.Context) int {
+	if a.beadsMgr == nil || a.rootCause == nil {
+		return 0
+	}
+	blocked, err := a.beadsMgr.ListBeads(map[string]interface{}{"status": models.BeadStatusBlocked})
+	if err != nil {
+		return 0
+	}
+
+	analyzed := 0
+	for _, b := range blocked {
+		if analyzed >= rootCausePerBeat {
+			break
+		}
+		if b == nil || b.Context == nil {
+			continue
+		}
+		if b.Context["ralph_blocked_reason"] == "" && b.Context["loop_detected"] != "true" {
+			continue
+		}
+		raw := b.Context["error_history"]
+		var history []errorRecord
+		if raw == "" || json.Unmarshal([]byte(raw), &history) != nil || len(history) < 2 {
+			continue
+		}
+		sum := sha256.Sum256([]byte(raw))
+		digest := hex.EncodeToString(sum[:8])
+		if b.Context[rootCauseForKey] == digest {
+			continue
+		}
+
+		cause, err := a.analyzeRootCause(ctx, b, history)
+		if err != nil {
+			log.Printf("[Ralph] Root-cause analysis of %s failed: %v", b.ID, err)
+			// Don't retry the same history every beat.
+			_ = a.beadsMgr.UpdateBead(b.ID, map[string]interface{}{
+				"context": map[string]string{rootCauseForKey: digest},
+			})
+			continue
+		}
+		if err := a.beadsMgr.UpdateBead(b.ID, map[string]interface{}{
+			"context": map[string]string{
+				RootCauseKey:            cause.Hypothesis,
+				RootCauseRemediationKey: strings.Join(cause.Remediation, "\n"),
+				RootCauseAtKey:          time.Now().UTC().Format(time.RFC3339),
+				rootCauseForKey:         digest,
+			},
+		}); err != nil {
+			log.Printf("[Ralph] Failed to store root cause of %s: %v", b.ID, err)
+			continue
+		}
+		log.Printf("[Ralph] Root cause of blocked bead %s: %s", b.ID, cause.Hypothesis)
+		analyzed++
+	}
+	return analyzed
+}
+
+// analyzeRootCause asks the model why the bead keeps failing.
+func (a *Activities) analyzeRootCause(ctx context.Context, b *models.Bead, history []errorRecord) (*RootCause, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	reply, err := a.rootCause(ctx, rootCausePrompt, rootCauseInput(b, history))
+	if err != nil {
+		return nil, err
+	}
+	return parseRootCause(reply), nil
+}
+
+// rootCauseInput describes the bead and its errors for the model. Runs of
+// the same error are collapsed, long errors are cut, and the oldest errors
+// are dropped once the history grows too long.
+func rootCauseInput(b *models.Bead, history []errorRecord) string {
+	var errs []string
+	for i := 0; i < len(history); {
+		j := i + 1
+		for j < len(history) && history[j].Error == history[i].Error {
+			j++
+		}
+		msg := strings.TrimSpace(history[i].Error)
+		if len(msg) > maxErrorChars {
+			msg = msg[:maxErrorChars] + " [...]"
+		}
+		entry := fmt.Sprintf("Dispatch %d:\n%s", history[i].Dispatch, msg)
+		if n := j - i; n > 1 {
+			entry = fmt.Sprintf("Dispatches %d-%d (same error %d times):\n%s", history[i].Dispatch, history[j-1].Dispatch, n, msg)
+		}
+		errs = append(errs, entry)
+		i = j
+	}
+	total := 0
+	start := len(errs)
+	for start > 0 && total+len(errs[start-1]) <= maxHistoryChars {
+		start--
+		total += len(errs[start])
+	}
+	if start == len(errs) {
+		start = len(errs) - 1
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Task: %s (%s)\n", b.Title, b.Type)
+	if desc := strings.TrimSpace(b.Description); desc != "" {
+		if len(desc) > 1000 {
+			desc = desc[:1000] + " [...]"
+		}
+		fmt.Fprintf(&sb, "Description: %s\n", desc)
+	}
+	if reason := b.Context["ralph_blocked_reason"]; reason != "" {
+		fmt.Fprintf(&sb, "Blocked because: %s\n", reason)
+	} else if reason := b.Context["loop_detected_reason"]; reason != "" {
+		fmt.Fprintf(&sb, "Blocked because: %s\n", reason)
+	}
+	if start > 0 {
+		fmt.Fprintf(&sb, "\n(%d older error groups omitted)\n", start)
+	}
+	sb.WriteString("\nErrors:\n\n")
+	sb.WriteString(strings.Join(errs[start:], "\n\n"))
+	return sb.String()
+}
+
+// parseRootCause reads the model's JSON reply. A reply that isn't JSON is
+// kept whole as the hypothesis.
+func parseRootCause(reply string) *RootCause {
+	text := strings.TrimSpace(reply)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	if i, j := strings.Index(text, "{"), strings.LastIndex(text, "}"); i >= 0 && j > i {
+		var cause RootCause
+		if err := json.Unmarshal([]byte(text[i:j+1]), &cause); err == nil && cause.Hypothesis != "" {
+			return &cause
+		}
+	}
+	return &RootCause{Hypothesis: strings.TrimSpace(reply)}
+}
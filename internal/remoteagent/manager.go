@@ -0,0 +1,612 @@
+// Package remoteagent lets agents the control plane cannot reach, such as
+// developer laptops and on-prem GPU boxes, do work for it. An admin issues
+// a one-time enrollment token; the agent trades it for a credential,
+// connects outbound, and pulls ready beads of the projects it was enrolled
+// for. Beads held by an agent that stops sending heartbeats are released
+// so other agents can take them.
+package remoteagent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/agent"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/messages"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+var (
+	// ErrInvalidToken is returned for an unknown, used or expired
+	// enrollment token.
+	ErrInvalidToken = errors.New("invalid or expired enrollment token")
+	// ErrUnauthorized is returned for a bad or revoked credential.
+	ErrUnauthorized = errors.New("invalid remote agent credential")
+	// ErrForbidden is returned when an agent asks for more than its token
+	// grants.
+	ErrForbidden = errors.New("not granted by the enrollment token")
+	// ErrNotAssigned is returned for a result on a bead the agent does not
+	// hold.
+	ErrNotAssigned = errors.New("bead is not assigned to this agent")
+	// ErrNotFound is returned for an unknown remote agent.
+	ErrNotFound = errors.New("remote agent not found")
+)
+
+const (
+	// HeartbeatInterval is how often agents are asked to send heartbeats.
+	HeartbeatInterval = 15 * time.Second
+	// IdleRetryInterval is how long an agent that found no work waits
+	// before asking again.
+	IdleRetryInterval = 30 * time.Second
+	// LivenessTimeout is how long an agent may go without a heartbeat
+	// before it is considered offline and its beads are released.
+	LivenessTimeout = 90 * time.Second
+	// DefaultTokenTTL and MaxTokenTTL bound how long an enrollment token
+	// may be used.
+	DefaultTokenTTL = 24 * time.Hour
+	MaxTokenTTL     = 30 * 24 * time.Hour
+	// assigneePrefix marks beads held by remote agents.
+	assigneePrefix = "remote-"
+	// maxOutputBytes bounds the output recorded on a bead.
+	maxOutputBytes = 4000
+)
+
+// BeadStore is the part of the bead manager remote agents work through.
+type BeadStore interface {
+	GetReadyBeads(projectID string) ([]*models.Bead, error)
+	ClaimBead(beadID, agentID string) error
+	GetBead(id string) (*models.Bead, error)
+	UpdateBead(id string, updates map[string]interface{}) error
+	ListBeads(filters map[string]interface{}) ([]*models.Bead, error)
+}
+
+// Token is an enrollment token. The token itself is only known when it is
+// created.
+type Token struct {
+	ID         string    `json:"id"`
+	Token      string    `json:"token,omitempty"`
+	ProjectIDs []string  `json:"project_ids"`
+	Roles      []string  `json:"roles,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// EnrollRequest is what an agent sends to enroll. Empty project IDs and
+// roles take everything the token grants.
+type EnrollRequest struct {
+	Token        string   `json:"token"`
+	Name         string   `json:"name"`
+	ProjectIDs   []string `json:"project_ids,omitempty"`
+	Roles        []string `json:"roles,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// Agent describes a remote agent and its liveness.
+type Agent struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	ProjectIDs   []string   `json:"project_ids"`
+	Roles        []string   `json:"roles,omitempty"`
+	Capabilities []string   `json:"capabilities,omitempty"`
+	EnrolledAt   time.Time  `json:"enrolled_at"`
+	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
+	Status       string     `json:"status"` // "idle", "busy", "offline" or "revoked"
+	Connected    bool       `json:"connected"`
+	Beads        []string   `json:"beads,omitempty"` // beads the agent holds
+}
+
+// session is the live state of a connected agent.
+type session struct {
+	lastSeen  time.Time
+	status    string
+	connected bool
+	kick      func() // closes the agent's connection
+}
+
+// Manager enrolls remote agents, hands them work and watches their
+// liveness.
+type Manager struct {
+	db    *database.Database
+	beads BeadStore
+	now   func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session // agent ID -> live state
+	// paused is set by SetPaused, while the server is in maintenance.
+	paused bool
+}
+
+// NewManager creates a remote agent manager. It returns nil without a
+// database.
+func NewManager(db *database.Database, beads BeadStore) *Manager {
+	if db == nil {
+		return nil
+	}
+	return &Manager{db: db, beads: beads, now: time.Now, sessions: make(map[string]*session)}
+}
+
+// SetPaused stops or resumes handing out work. While paused, NextTask
+// claims nothing; agents finish and report the beads they hold.
+func (m *Manager) SetPaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+// Assignee is the assigned_to value of beads a remote agent holds.
+func Assignee(agentID string) string {
+	return assigneePrefix + agentID
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashSecret(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken issues a one-time enrollment token for agents serving
+// projectIDs, optionally only in roles. ttl of zero means DefaultTokenTTL.
+func (m *Manager) CreateToken(projectIDs, roles []string, ttl time.Duration, createdBy string) (*Token, error) {
+	projectIDs = cleanList(projectIDs, false)
+	if len(projectIDs) == 0 {
+		return nil, fmt.Errorf("at least one project is required")
+	}
+	if ttl == 0 {
+		ttl = DefaultTokenTTL
+	}
+	if ttl < 0 || ttl > MaxTokenTTL {
+		return nil, fmt.Errorf("ttl must be between 0 and %s", MaxTokenTTL)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	t := &database.RemoteAgentToken{
+		ID:         "rat-" + id,
+		TokenHash:  hashSecret(secret),
+		ProjectIDs: projectIDs,
+		Roles:      cleanList(roles, true),
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := m.db.CreateRemoteAgentToken(t); err != nil {
+		return nil, err
+	}
+	return &Token{ID: t.ID, Token: secret, ProjectIDs: t.ProjectIDs, Roles: t.Roles, ExpiresAt: t.ExpiresAt}, nil
+}
+
+// Enroll trades an enrollment token for a remote agent and its
+// credential. The credential is only returned here.
+func (m *Manager) Enroll(req EnrollRequest) (*Agent, string, error) {
+	id, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+	id = "ra-" + id
+
+	now := m.now().UTC()
+	token, err := m.db.ConsumeRemoteAgentToken(hashSecret(strings.TrimSpace(req.Token)), id, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if token == nil {
+		return nil, "", ErrInvalidToken
+	}
+
+	projectIDs, err := narrow(cleanList(req.ProjectIDs, false), token.ProjectIDs, "project")
+	if err != nil {
+		return nil, "", err
+	}
+	roles, err := narrow(cleanList(req.Roles, true), token.Roles, "role")
+	if err != nil {
+		return nil, "", err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = id
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	ra := &database.RemoteAgent{
+		ID:             id,
+		Name:           name,
+		ProjectIDs:     projectIDs,
+		Roles:          roles,
+		Capabilities:   agent.NormalizeCapabilities(req.Capabilities),
+		CredentialHash: hashSecret(secret),
+		EnrolledAt:     now,
+	}
+	if err := m.db.CreateRemoteAgent(ra); err != nil {
+		return nil, "", err
+	}
+	log.Printf("[RemoteAgent] Enrolled %s (%s) for projects %v with token %s", id, name, projectIDs, token.ID)
+	return m.describe(ra), id + "." + secret, nil
+}
+
+// narrow checks that requested is within granted and returns what the
+// agent gets: requested, or everything granted if it asked for nothing.
+// An empty grant allows anything.
+func narrow(requested, granted []string, what string) ([]string, error) {
+	if len(requested) == 0 {
+		return granted, nil
+	}
+	if len(granted) == 0 {
+		return requested, nil
+	}
+	allowed := make(map[string]bool, len(granted))
+	for _, g := range granted {
+		allowed[g] = true
+	}
+	for _, r := range requested {
+		if !allowed[r] {
+			return nil, fmt.Errorf("%w: %s %s", ErrForbidden, what, r)
+		}
+	}
+	return requested, nil
+}
+
+// cleanList trims and de-duplicates a list, lower-casing it if fold is set.
+func cleanList(in []string, fold bool) []string {
+	var out []string
+	seen := make(map[string]bool, len(in))
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if fold {
+			s = strings.ToLower(s)
+		}
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+// Authenticate returns the agent a credential belongs to.
+func (m *Manager) Authenticate(credential string) (*database.RemoteAgent, error) {
+	id, secret, ok := strings.Cut(strings.TrimSpace(credential), ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrUnauthorized
+	}
+	ra, err := m.db.GetRemoteAgent(id)
+	if err != nil {
+		return nil, err
+	}
+	if ra == nil || ra.RevokedAt != nil ||
+		subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(ra.CredentialHash)) != 1 {
+		return nil, ErrUnauthorized
+	}
+	return ra, nil
+}
+
+// Connect records that an agent connected. kick closes its connection; it
+// is called if the agent is revoked or connects again.
+func (m *Manager) Connect(agentID string, kick func()) {
+	m.mu.Lock()
+	if s := m.sessions[agentID]; s != nil && s.kick != nil {
+		go s.kick()
+	}
+	m.sessions[agentID] = &session{lastSeen: m.now(), status: "idle", connected: true, kick: kick}
+	m.mu.Unlock()
+	_ = m.db.TouchRemoteAgent(agentID, m.now().UTC())
+	log.Printf("[RemoteAgent] %s connected", agentID)
+}
+
+// Disconnect records that an agent's connection closed. Its beads stay
+// with it until LivenessTimeout passes, so it can reconnect and finish.
+func (m *Manager) Disconnect(agentID string) {
+	m.mu.Lock()
+	if s := m.sessions[agentID]; s != nil {
+		s.connected = false
+		s.kick = nil
+	}
+	m.mu.Unlock()
+	log.Printf("[RemoteAgent] %s disconnected", agentID)
+}
+
+// Heartbeat records that an agent is alive, with its status.
+func (m *Manager) Heartbeat(agentID, status string) {
+	now := m.now()
+	m.mu.Lock()
+	s := m.sessions[agentID]
+	if s == nil {
+		s = &session{}
+		m.sessions[agentID] = s
+	}
+	s.lastSeen = now
+	if status != "" {
+		s.status = status
+	}
+	m.mu.Unlock()
+	_ = m.db.TouchRemoteAgent(agentID, now.UTC())
+}
+
+// online reports whether an agent was heard from within LivenessTimeout.
+func (m *Manager) online(agentID string) (*session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[agentID]
+	return s, s != nil && m.now().Sub(s.lastSeen) < LivenessTimeout
+}
+
+// NextTask claims the most urgent ready bead the agent may work on and
+// returns it as a task, or nil if there is none or the manager is paused.
+// Decision beads, beads routed to a persona the agent does not list among
+// its roles and, for an agent with capabilities, beads without a matching
+// tag are left for others.
+func (m *Manager) NextTask(ra *database.RemoteAgent) (*messages.TaskMessage, error) {
+	m.Heartbeat(ra.ID, "")
+	m.mu.Lock()
+	paused := m.paused
+	m.mu.Unlock()
+	if paused {
+		return nil, nil
+	}
+
+	var candidates []*models.Bead
+	for _, projectID := range ra.ProjectIDs {
+		ready, err := m.beads.GetReadyBeads(projectID)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range ready {
+			if b != nil && eligible(ra, b) {
+				candidates = append(candidates, b)
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority < candidates[j].Priority
+		}
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+
+	for _, b := range candidates {
+		if err := m.beads.ClaimBead(b.ID, Assignee(ra.ID)); err != nil {
+			continue // someone else got it first
+		}
+		m.mu.Lock()
+		if s := m.sessions[ra.ID]; s != nil {
+			s.status = "busy"
+		}
+		m.mu.Unlock()
+		log.Printf("[RemoteAgent] %s claimed bead %s (%s)", ra.ID, b.ID, b.Title)
+
+		ctx := make(map[string]interface{}, len(b.Context)+1)
+		for k, v := range b.Context {
+			ctx[k] = v
+		}
+		if len(b.Tags) > 0 {
+			ctx["tags"] = b.Tags
+		}
+		return messages.TaskAssigned(b.ProjectID, b.ID, Assignee(ra.ID), messages.TaskData{
+			Title:       b.Title,
+			Description: b.Description,
+			Priority:    int(b.Priority),
+			Type:        b.Type,
+			Context:     ctx,
+		}, fmt.Sprintf("remote-%s-%d", b.ID, m.now().UnixNano())), nil
+	}
+	return nil, nil
+}
+
+func eligible(ra *database.RemoteAgent, b *models.Bead) bool {
+	if b.Status != models.BeadStatusOpen || b.AssignedTo != "" || b.Type == "decision" {
+		return false
+	}
+	if persona := b.Context["requires_persona"]; persona != "" && !contains(ra.Roles, strings.ToLower(persona)) {
+		return false
+	}
+	if len(ra.Capabilities) > 0 {
+		return agent.CapabilityScore(&models.Agent{Capabilities: ra.Capabilities}, b.Tags) > 0
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete records the result of a task: a successful bead is closed, a
+// failed one is reopened for another attempt, with the error in its
+// context.
+func (m *Manager) Complete(agentID string, result *messages.ResultMessage) error {
+	m.Heartbeat(agentID, "idle")
+
+	b, err := m.beads.GetBead(result.BeadID)
+	if err != nil {
+		return err
+	}
+	if b == nil || b.AssignedTo != Assignee(agentID) || b.Status != models.BeadStatusInProgress {
+		return ErrNotAssigned
+	}
+
+	updates := map[string]interface{}{"assigned_to": ""}
+	ctx := map[string]string{"remote_agent": agentID}
+	if result.Result.Status == "success" {
+		updates["status"] = models.BeadStatusClosed
+		ctx["remote_output"] = truncate(result.Result.Output)
+	} else {
+		updates["status"] = models.BeadStatusOpen
+		errMsg := result.Result.Error
+		if errMsg == "" {
+			errMsg = "remote agent reported failure"
+		}
+		ctx["remote_error"] = truncate(errMsg)
+	}
+	updates["context"] = ctx
+	if err := m.beads.UpdateBead(b.ID, updates); err != nil {
+		return err
+	}
+	log.Printf("[RemoteAgent] %s finished bead %s: %s", agentID, b.ID, result.Result.Status)
+	return nil
+}
+
+func truncate(s string) string {
+	if len(s) <= maxOutputBytes {
+		return s
+	}
+	return s[:maxOutputBytes] + "\n... (truncated)"
+}
+
+// heldBeads returns the beads an agent holds.
+func (m *Manager) heldBeads(agentID string) []*models.Bead {
+	held, err := m.beads.ListBeads(map[string]interface{}{
+		"assigned_to": Assignee(agentID),
+		"status":      models.BeadStatusInProgress,
+	})
+	if err != nil {
+		log.Printf("[RemoteAgent] Failed to list beads of %s: %v", agentID, err)
+		return nil
+	}
+	return held
+}
+
+// releaseBeads returns the beads an agent holds to the ready queue.
+func (m *Manager) releaseBeads(agentID, reason string) int {
+	released := 0
+	for _, b := range m.heldBeads(agentID) {
+		if err := m.beads.UpdateBead(b.ID, map[string]interface{}{
+			"status":      models.BeadStatusOpen,
+			"assigned_to": "",
+			"context":     map[string]string{"remote_error": reason},
+		}); err != nil {
+			log.Printf("[RemoteAgent] Failed to release bead %s of %s: %v", b.ID, agentID, err)
+			continue
+		}
+		released++
+	}
+	if released > 0 {
+		log.Printf("[RemoteAgent] Released %d beads of %s: %s", released, agentID, reason)
+	}
+	return released
+}
+
+// List describes all remote agents, revoked ones included.
+func (m *Manager) List() ([]*Agent, error) {
+	rows, err := m.db.ListRemoteAgents()
+	if err != nil {
+		return nil, err
+	}
+	agents := make([]*Agent, 0, len(rows))
+	for _, ra := range rows {
+		agents = append(agents, m.describe(ra))
+	}
+	return agents, nil
+}
+
+func (m *Manager) describe(ra *database.RemoteAgent) *Agent {
+	a := &Agent{
+		ID:           ra.ID,
+		Name:         ra.Name,
+		ProjectIDs:   ra.ProjectIDs,
+		Roles:        ra.Roles,
+		Capabilities: ra.Capabilities,
+		EnrolledAt:   ra.EnrolledAt,
+		LastSeenAt:   ra.LastSeenAt,
+		Status:       "offline",
+	}
+	if ra.RevokedAt != nil {
+		a.Status = "revoked"
+		return a
+	}
+	if s, ok := m.online(ra.ID); ok {
+		m.mu.Lock()
+		a.Status = s.status
+		a.Connected = s.connected
+		m.mu.Unlock()
+	}
+	for _, b := range m.heldBeads(ra.ID) {
+		a.Beads = append(a.Beads, b.ID)
+	}
+	return a
+}
+
+// Revoke revokes an agent's credential, closes its connection and
+// releases its beads.
+func (m *Manager) Revoke(agentID string) error {
+	ra, err := m.db.GetRemoteAgent(agentID)
+	if err != nil {
+		return err
+	}
+	if ra == nil {
+		return ErrNotFound
+	}
+	if err := m.db.RevokeRemoteAgent(agentID, m.now().UTC()); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	s := m.sessions[agentID]
+	delete(m.sessions, agentID)
+	m.mu.Unlock()
+	if s != nil && s.kick != nil {
+		s.kick()
+	}
+	m.releaseBeads(agentID, "remote agent revoked")
+	log.Printf("[RemoteAgent] Revoked %s", agentID)
+	return nil
+}
+
+// ReleaseStale releases the beads of agents not heard from within
+// LivenessTimeout.
+func (m *Manager) ReleaseStale() {
+	rows, err := m.db.ListRemoteAgents()
+	if err != nil {
+		log.Printf("[RemoteAgent] Failed to list remote agents: %v", err)
+		return
+	}
+	for _, ra := range rows {
+		if _, ok := m.online(ra.ID); ok {
+			continue
+		}
+		m.releaseBeads(ra.ID, "remote agent stopped sending heartbeats")
+	}
+}
+
+// StartReaper releases the beads of silent agents at interval until ctx is
+// cancelled.
+func (m *Manager) StartReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.ReleaseStale()
+		}
+	}
+}
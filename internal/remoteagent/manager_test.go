@@ -0,0 +1,332 @@
+package remoteagent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/messages"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// fakeBeads is an in-memory BeadStore.
+type fakeBeads struct {
+	mu    sync.Mutex
+	beads map[string]*models.Bead
+}
+
+func (f *fakeBeads) add(b *models.Bead) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b.Status == "" {
+		b.Status = models.BeadStatusOpen
+	}
+	if b.Context == nil {
+		b.Context = map[string]string{}
+	}
+	f.beads[b.ID] = b
+}
+
+func (f *fakeBeads) GetReadyBeads(projectID string) ([]*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ready []*models.Bead
+	for _, b := range f.beads {
+		if b.ProjectID == projectID && (b.Status == models.BeadStatusOpen || b.Status == models.BeadStatusInProgress) {
+			ready = append(ready, b)
+		}
+	}
+	return ready, nil
+}
+
+func (f *fakeBeads) ClaimBead(beadID, agentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b := f.beads[beadID]
+	if b == nil || (b.AssignedTo != "" && b.AssignedTo != agentID) {
+		return fmt.Errorf("cannot claim %s", beadID)
+	}
+	b.AssignedTo = agentID
+	b.Status = models.BeadStatusInProgress
+	return nil
+}
+
+func (f *fakeBeads) GetBead(id string) (*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b := f.beads[id]; b != nil {
+		return b, nil
+	}
+	return nil, fmt.Errorf("bead not found %s", id)
+}
+
+func (f *fakeBeads) UpdateBead(id string, updates map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b := f.beads[id]
+	if b == nil {
+		return fmt.Errorf("bead not found %s", id)
+	}
+	if s, ok := updates["status"].(models.BeadStatus); ok {
+		b.Status = s
+	}
+	if a, ok := updates["assigned_to"].(string); ok {
+		b.AssignedTo = a
+	}
+	if ctx, ok := updates["context"].(map[string]string); ok {
+		for k, v := range ctx {
+			b.Context[k] = v
+		}
+	}
+	return nil
+}
+
+func (f *fakeBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*models.Bead
+	for _, b := range f.beads {
+		if a, ok := filters["assigned_to"].(string); ok && b.AssignedTo != a {
+			continue
+		}
+		if s, ok := filters["status"].(models.BeadStatus); ok && b.Status != s {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeBeads) {
+	t.Helper()
+	db := dbtest.New(t)
+	beads := &fakeBeads{beads: make(map[string]*models.Bead)}
+	return NewManager(db, beads), beads
+}
+
+func enroll(t *testing.T, m *Manager, projects, roles []string, req EnrollRequest) (*database.RemoteAgent, string) {
+	t.Helper()
+	tok, err := m.CreateToken(projects, roles, 0, "admin")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	req.Token = tok.Token
+	_, cred, err := m.Enroll(req)
+	if err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	ra, err := m.Authenticate(cred)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	return ra, cred
+}
+
+func TestManager_TokenIsSingleUse(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	if _, err := m.CreateToken(nil, nil, 0, "admin"); err == nil {
+		t.Error("CreateToken without projects succeeded")
+	}
+	if _, err := m.CreateToken([]string{"p1"}, nil, MaxTokenTTL+time.Hour, "admin"); err == nil {
+		t.Error("CreateToken with too long a ttl succeeded")
+	}
+
+	tok, err := m.CreateToken([]string{"p1"}, nil, 0, "admin")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if _, _, err := m.Enroll(EnrollRequest{Token: tok.Token, Name: "laptop"}); err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	if _, _, err := m.Enroll(EnrollRequest{Token: tok.Token}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("second Enroll: err = %v, want ErrInvalidToken", err)
+	}
+	if _, _, err := m.Enroll(EnrollRequest{Token: "nope"}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Enroll with unknown token: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestManager_TokenExpires(t *testing.T) {
+	m, _ := newTestManager(t)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	tok, err := m.CreateToken([]string{"p1"}, nil, time.Hour, "admin")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	m.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, _, err := m.Enroll(EnrollRequest{Token: tok.Token}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Enroll with expired token: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestManager_EnrollNarrowsGrant(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	tok, _ := m.CreateToken([]string{"p1", "p2"}, []string{"qa-engineer"}, 0, "admin")
+	if _, _, err := m.Enroll(EnrollRequest{Token: tok.Token, ProjectIDs: []string{"p3"}}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Enroll for ungranted project: err = %v, want ErrForbidden", err)
+	}
+
+	tok, _ = m.CreateToken([]string{"p1", "p2"}, []string{"qa-engineer"}, 0, "admin")
+	if _, _, err := m.Enroll(EnrollRequest{Token: tok.Token, Roles: []string{"web-designer"}}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Enroll for ungranted role: err = %v, want ErrForbidden", err)
+	}
+
+	ra, _ := enroll(t, m, []string{"p1", "p2"}, []string{"qa-engineer"},
+		EnrollRequest{ProjectIDs: []string{"p2"}, Capabilities: []string{" GPU ", "gpu"}})
+	if strings.Join(ra.ProjectIDs, ",") != "p2" || strings.Join(ra.Roles, ",") != "qa-engineer" {
+		t.Errorf("agent has projects %v roles %v, want [p2] [qa-engineer]", ra.ProjectIDs, ra.Roles)
+	}
+	if strings.Join(ra.Capabilities, ",") != "gpu" {
+		t.Errorf("capabilities = %v, want [gpu]", ra.Capabilities)
+	}
+}
+
+func TestManager_Authenticate(t *testing.T) {
+	m, _ := newTestManager(t)
+	ra, cred := enroll(t, m, []string{"p1"}, nil, EnrollRequest{})
+
+	for _, bad := range []string{"", "nodot", ra.ID + ".wrong", "ra-missing.secret"} {
+		if _, err := m.Authenticate(bad); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Authenticate(%q): err = %v, want ErrUnauthorized", bad, err)
+		}
+	}
+
+	kicked := false
+	m.Connect(ra.ID, func() { kicked = true })
+	if err := m.Revoke(ra.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if !kicked {
+		t.Error("Revoke did not close the connection")
+	}
+	if _, err := m.Authenticate(cred); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authenticate after revoke: err = %v, want ErrUnauthorized", err)
+	}
+	if err := m.Revoke("ra-missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revoke unknown agent: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_NextTask(t *testing.T) {
+	m, beads := newTestManager(t)
+	ra, _ := enroll(t, m, []string{"p1"}, nil, EnrollRequest{Roles: []string{"qa-engineer"}, Capabilities: []string{"gpu"}})
+
+	old := time.Now().Add(-time.Hour)
+	beads.add(&models.Bead{ID: "b-other-project", ProjectID: "p2", Tags: []string{"gpu"}})
+	beads.add(&models.Bead{ID: "b-untagged", ProjectID: "p1", Priority: models.BeadPriorityP0})
+	beads.add(&models.Bead{ID: "b-decision", ProjectID: "p1", Type: "decision", Tags: []string{"gpu"}})
+	beads.add(&models.Bead{ID: "b-persona", ProjectID: "p1", Tags: []string{"gpu"}, Context: map[string]string{"requires_persona": "web-designer"}})
+	beads.add(&models.Bead{ID: "b-held", ProjectID: "p1", Tags: []string{"gpu"}, AssignedTo: "agent-1", Status: models.BeadStatusInProgress})
+	beads.add(&models.Bead{ID: "b-low", ProjectID: "p1", Tags: []string{"gpu"}, Priority: models.BeadPriorityP2, CreatedAt: old})
+	beads.add(&models.Bead{ID: "b-high", ProjectID: "p1", Tags: []string{"GPU"}, Priority: models.BeadPriorityP1})
+
+	for _, want := range []string{"b-high", "b-low", ""} {
+		task, err := m.NextTask(ra)
+		if err != nil {
+			t.Fatalf("NextTask failed: %v", err)
+		}
+		got := ""
+		if task != nil {
+			got = task.BeadID
+			if task.AssignedTo != Assignee(ra.ID) || beads.beads[got].AssignedTo != Assignee(ra.ID) {
+				t.Errorf("bead %s not assigned to %s", got, Assignee(ra.ID))
+			}
+		}
+		if got != want {
+			t.Errorf("NextTask = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestManager_NextTask_Paused(t *testing.T) {
+	m, beads := newTestManager(t)
+	ra, _ := enroll(t, m, []string{"p1"}, nil, EnrollRequest{})
+	beads.add(&models.Bead{ID: "b-1", ProjectID: "p1"})
+
+	m.SetPaused(true)
+	if task, err := m.NextTask(ra); err != nil || task != nil {
+		t.Fatalf("paused: NextTask = %v, %v, want no work", task, err)
+	}
+	if beads.beads["b-1"].AssignedTo != "" {
+		t.Error("bead claimed while paused")
+	}
+
+	m.SetPaused(false)
+	if task, err := m.NextTask(ra); err != nil || task == nil || task.BeadID != "b-1" {
+		t.Fatalf("resumed: NextTask = %v, %v, want b-1", task, err)
+	}
+}
+
+func TestManager_Complete(t *testing.T) {
+	m, beads := newTestManager(t)
+	ra, _ := enroll(t, m, []string{"p1"}, nil, EnrollRequest{})
+	other, _ := enroll(t, m, []string{"p1"}, nil, EnrollRequest{})
+	beads.add(&models.Bead{ID: "b1", ProjectID: "p1"})
+	beads.add(&models.Bead{ID: "b2", ProjectID: "p1", Priority: models.BeadPriorityP3})
+
+	if _, err := m.NextTask(ra); err != nil {
+		t.Fatalf("NextTask failed: %v", err)
+	}
+	fail := messages.TaskFailed("p1", "b1", ra.ID, messages.ResultData{Status: "failure", Error: "no GPU"}, "c1")
+	if err := m.Complete(other.ID, fail); !errors.Is(err, ErrNotAssigned) {
+		t.Errorf("Complete by another agent: err = %v, want ErrNotAssigned", err)
+	}
+	if err := m.Complete(ra.ID, fail); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if b := beads.beads["b1"]; b.Status != models.BeadStatusOpen || b.AssignedTo != "" || b.Context["remote_error"] != "no GPU" {
+		t.Errorf("failed bead = %s/%q/%q, want reopened with the error", b.Status, b.AssignedTo, b.Context["remote_error"])
+	}
+
+	if _, err := m.NextTask(ra); err != nil {
+		t.Fatalf("NextTask failed: %v", err)
+	}
+	ok := messages.TaskCompleted("p1", "b1", ra.ID, messages.ResultData{Status: "success", Output: "done"}, "c2")
+	if err := m.Complete(ra.ID, ok); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if b := beads.beads["b1"]; b.Status != models.BeadStatusClosed || b.Context["remote_output"] != "done" {
+		t.Errorf("completed bead = %s/%q, want closed with the output", b.Status, b.Context["remote_output"])
+	}
+}
+
+func TestManager_ReleaseStale(t *testing.T) {
+	m, beads := newTestManager(t)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	ra, _ := enroll(t, m, []string{"p1"}, nil, EnrollRequest{})
+	beads.add(&models.Bead{ID: "b1", ProjectID: "p1"})
+
+	m.Connect(ra.ID, nil)
+	if _, err := m.NextTask(ra); err != nil {
+		t.Fatalf("NextTask failed: %v", err)
+	}
+
+	m.ReleaseStale()
+	if beads.beads["b1"].AssignedTo != Assignee(ra.ID) {
+		t.Fatal("bead of a live agent was released")
+	}
+	agents, _ := m.List()
+	if len(agents) != 1 || agents[0].Status != "busy" || len(agents[0].Beads) != 1 {
+		t.Errorf("List = %+v, want one busy agent holding b1", agents)
+	}
+
+	now = now.Add(LivenessTimeout)
+	m.ReleaseStale()
+	if b := beads.beads["b1"]; b.AssignedTo != "" || b.Status != models.BeadStatusOpen {
+		t.Errorf("bead of a silent agent = %s/%q, want released", b.Status, b.AssignedTo)
+	}
+	agents, _ = m.List()
+	if agents[0].Status != "offline" {
+		t.Errorf("status = %s, want offline", agents[0].Status)
+	}
+}
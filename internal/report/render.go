@@ -0,0 +1,95 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+var funcs = map[string]interface{}{
+	"usd":   func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"rate":  func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"cell":  func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+	"date":  func(r *Report) string { return r.Start.Format("Jan 2") + " – " + r.End.Add(-1).Format("Jan 2, 2006") },
+	"title": func(r *Report) string { return r.Title() },
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(`# {{title .}}
+
+{{date .}} (UTC)
+
+## Beads
+
+- Opened: {{.BeadsOpened}}
+- Closed: {{.BeadsClosed}}
+- Still open: {{.BeadsOpen}}
+- Velocity: {{rate .Velocity}} closed per day ({{rate .PreviousVelocity}} the {{.Period}} before)
+
+## Spend
+
+{{usd .Spend.CostUSD}} on {{.Spend.Requests}} requests, {{.Spend.Tokens}} tokens.
+{{if .TopErrors}}
+## Top Errors
+
+| Count | Error |
+|---|---|
+{{range .TopErrors}}| {{.Count}} | {{cell .Message}} |
+{{end}}{{end}}{{if .Agents}}
+## Agent Activity
+
+| Agent | Tasks | Failed | Beads closed | Spend |
+|---|---|---|---|---|
+{{range .Agents}}| {{cell (or .Name .AgentID)}} | {{.Tasks}} | {{.Failed}} | {{.BeadsClosed}} | {{usd .CostUSD}} |
+{{end}}{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{title .}}</title></head>
+<body>
+<h1>{{title .}}</h1>
+<p>{{date .}} (UTC)</p>
+<h2>Beads</h2>
+<ul>
+<li>Opened: {{.BeadsOpened}}</li>
+<li>Closed: {{.BeadsClosed}}</li>
+<li>Still open: {{.BeadsOpen}}</li>
+<li>Velocity: {{rate .Velocity}} closed per day ({{rate .PreviousVelocity}} the {{.Period}} before)</li>
+</ul>
+<h2>Spend</h2>
+<p>{{usd .Spend.CostUSD}} on {{.Spend.Requests}} requests, {{.Spend.Tokens}} tokens.</p>
+{{- if .TopErrors}}
+<h2>Top Errors</h2>
+<table>
+<tr><th>Count</th><th>Error</th></tr>
+{{- range .TopErrors}}
+<tr><td>{{.Count}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Agents}}
+<h2>Agent Activity</h2>
+<table>
+<tr><th>Agent</th><th>Tasks</th><th>Failed</th><th>Beads closed</th><th>Spend</th></tr>
+{{- range .Agents}}
+<tr><td>{{or .Name .AgentID}}</td><td>{{.Tasks}}</td><td>{{.Failed}}</td><td>{{.BeadsClosed}}</td><td>{{usd .CostUSD}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// Render fills in the report's Markdown and HTML.
+func (r *Report) Render() error {
+	var md, html strings.Builder
+	if err := markdownTemplate.Execute(&md, r); err != nil {
+		return fmt.Errorf("failed to render report markdown: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, r); err != nil {
+		return fmt.Errorf("failed to render report HTML: %w", err)
+	}
+	r.Markdown = md.String()
+	r.HTML = html.String()
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package report compiles per-project digests: beads opened and closed,
+// velocity, spend, top errors, and agent activity over a day or a week.
+// Reports are rendered as markdown and HTML so they can be stored, read in
+// the UI, and sent out on the notification channels.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Period is the span a report covers.
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+)
+
+// ParsePeriod accepts day or week, and daily or weekly.
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "day", "daily":
+		return PeriodDay, nil
+	case "week", "weekly":
+		return PeriodWeek, nil
+	default:
+		return "", fmt.Errorf("invalid period %q: must be day or week", s)
+	}
+}
+
+// Length is the duration of the period.
+func (p Period) Length() time.Duration {
+	if p == PeriodWeek {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LastEnd is the end of the last complete period before t: midnight UTC
+// for days, Monday midnight UTC for weeks.
+func (p Period) LastEnd(t time.Time) time.Time {
+	t = t.UTC()
+	end := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if p == PeriodWeek {
+		end = end.AddDate(0, 0, -((int(end.Weekday()) + 6) % 7))
+	}
+	return end
+}
+
+// maxErrors is how many of the most frequent errors a report lists.
+const maxErrors = 5
+
+// Report is a project's digest for one period.
+type Report struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"project_id"`
+	ProjectName string    `json:"project_name,omitempty"`
+	Period      Period    `json:"period"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	BeadsOpened int `json:"beads_opened"`
+	BeadsClosed int `json:"beads_closed"`
+	BeadsOpen   int `json:"beads_open"` // Not closed at the end of the period
+	// Velocity is beads closed per day, against the period before.
+	Velocity         float64 `json:"velocity"`
+	PreviousVelocity float64 `json:"previous_velocity"`
+
+	Spend     Spend           `json:"spend"`
+	TopErrors []ErrorCount    `json:"top_errors,omitempty"`
+	Agents    []AgentActivity `json:"agents,omitempty"`
+
+	Markdown string `json:"markdown,omitempty"`
+	HTML     string `json:"html,omitempty"`
+}
+
+// Spend is what a project's model calls cost in a period.
+type Spend struct {
+	CostUSD  float64 `json:"cost_usd"`
+	Tokens   int64   `json:"tokens"`
+	Requests int     `json:"requests"`
+}
+
+// ErrorCount is an error message and how often it was logged.
+type ErrorCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// AgentActivity is what one agent did in a period.
+type AgentActivity struct {
+	AgentID     string  `json:"agent_id"`
+	Name        string  `json:"name,omitempty"`
+	Tasks       int     `json:"tasks"`
+	Failed      int     `json:"failed"`
+	BeadsClosed int     `json:"beads_closed"`
+	CostUSD     float64 `json:"cost_usd"`
+}
+
+// Build compiles the report of a project for the period ending at end.
+// beads include those closed in the period before, so the velocity can be
+// compared; logs are the period's request logs and agents are loom's.
+func Build(project *models.Project, period Period, end time.Time, beads []*models.Bead, logs []*analytics.RequestLog, agents []*models.Agent) *Report {
+	start := end.Add(-period.Length())
+	r := &Report{
+		ProjectID: project.ID,
+		Period:    period,
+		Start:     start,
+		End:       end,
+	}
+	if project.Name != project.ID {
+		r.ProjectName = project.Name
+	}
+
+	days := period.Length().Hours() / 24
+	previousStart := start.Add(-period.Length())
+	var previousClosed int
+	var windowBeads []*models.Bead
+	for _, b := range beads {
+		if b == nil || b.ProjectID != project.ID || !b.CreatedAt.Before(end) {
+			continue
+		}
+		closed := b.Status == models.BeadStatusClosed && b.ClosedAt != nil
+		closedByEnd := closed && b.ClosedAt.Before(end)
+		if !b.CreatedAt.Before(start) {
+			r.BeadsOpened++
+		}
+		switch {
+		case !closedByEnd:
+			r.BeadsOpen++
+		case !b.ClosedAt.Before(start):
+			r.BeadsClosed++
+			windowBeads = append(windowBeads, b)
+		case !b.ClosedAt.Before(previousStart):
+			previousClosed++
+		}
+		if !closed {
+			windowBeads = append(windowBeads, b)
+		}
+	}
+	r.Velocity = float64(r.BeadsClosed) / days
+	r.PreviousVelocity = float64(previousClosed) / days
+
+	errors := make(map[string]int)
+	var windowLogs []*analytics.RequestLog
+	for _, l := range logs {
+		if l == nil || l.Timestamp.Before(start) || !l.Timestamp.Before(end) || logProject(l) != project.ID {
+			continue
+		}
+		windowLogs = append(windowLogs, l)
+		r.Spend.CostUSD += l.CostUSD
+		r.Spend.Tokens += l.TotalTokens
+		r.Spend.Requests++
+		if msg := errorLine(l.ErrorMessage); msg != "" {
+			errors[msg]++
+		}
+	}
+	for msg, n := range errors {
+		r.TopErrors = append(r.TopErrors, ErrorCount{Message: msg, Count: n})
+	}
+	sort.Slice(r.TopErrors, func(i, j int) bool {
+		if r.TopErrors[i].Count != r.TopErrors[j].Count {
+			return r.TopErrors[i].Count > r.TopErrors[j].Count
+		}
+		return r.TopErrors[i].Message < r.TopErrors[j].Message
+	})
+	if len(r.TopErrors) > maxErrors {
+		r.TopErrors = r.TopErrors[:maxErrors]
+	}
+
+	board := analytics.BuildAgentScoreboard(windowLogs, windowBeads, agents, project.ID, start, end)
+	for _, s := range board.Agents {
+		if s.Tasks == 0 && s.BeadsClosed == 0 {
+			continue
+		}
+		r.Agents = append(r.Agents, AgentActivity{
+			AgentID:     s.AgentID,
+			Name:        s.AgentName,
+			Tasks:       s.Tasks,
+			Failed:      s.TasksFailed,
+			BeadsClosed: s.BeadsClosed,
+			CostUSD:     s.CostUSD,
+		})
+	}
+	sort.SliceStable(r.Agents, func(i, j int) bool {
+		if r.Agents[i].BeadsClosed != r.Agents[j].BeadsClosed {
+			return r.Agents[i].BeadsClosed > r.Agents[j].BeadsClosed
+		}
+		return r.Agents[i].Tasks > r.Agents[j].Tasks
+	})
+	return r
+}
+
+// logProject is the project a request log was made for.
+func logProject(l *analytics.RequestLog) string {
+	if l.ProjectID != "" {
+		return l.ProjectID
+	}
+	return l.Metadata["project_id"]
+}
+
+// errorLine shortens an error message to its first line, so repeats of
+// the same error with different details count together.
+func errorLine(msg string) string {
+	msg, _, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+	if len(msg) > 160 {
+		msg = msg[:160] + "…"
+	}
+	return msg
+}
+
+// Headline sums the report up in one line.
+func (r *Report) Headline() string {
+	return fmt.Sprintf("%d opened, %d closed, $%.2f spent", r.BeadsOpened, r.BeadsClosed, r.Spend.CostUSD)
+}
+
+// Title names the report, e.g. "Weekly report for loom, week of Mar 2, 2026".
+func (r *Report) Title() string {
+	name := r.ProjectName
+	if name == "" {
+		name = r.ProjectID
+	}
+	if r.Period == PeriodWeek {
+		return fmt.Sprintf("Weekly report for %s, week of %s", name, r.Start.Format("Jan 2, 2006"))
+	}
+	return fmt.Sprintf("Daily report for %s, %s", name, r.Start.Format("Jan 2, 2006"))
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/analytics"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func TestPeriods(t *testing.T) {
+	for _, s := range []string{"day", "Daily", " week ", "weekly"} {
+		if _, err := ParsePeriod(s); err != nil {
+			t.Errorf("ParsePeriod(%q): %v", s, err)
+		}
+	}
+	if _, err := ParsePeriod("month"); err == nil {
+		t.Error("ParsePeriod(month) succeeded")
+	}
+
+	// Thursday afternoon, Mar 5 2026
+	now := time.Date(2026, 3, 5, 15, 30, 0, 0, time.UTC)
+	if got, want := PeriodDay.LastEnd(now), time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("day LastEnd = %v, want %v", got, want)
+	}
+	if got, want := PeriodWeek.LastEnd(now), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("week LastEnd = %v, want %v", got, want)
+	}
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	if got := PeriodWeek.LastEnd(monday); !got.Equal(monday) {
+		t.Errorf("week LastEnd at Monday midnight = %v", got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return end.Add(-d) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	day := 24 * time.Hour
+
+	project := &models.Project{ID: "proj-1", Name: "Loom"}
+	beads := []*models.Bead{
+		// Opened and closed this week
+		{ID: "b1", ProjectID: "proj-1", Status: models.BeadStatusClosed, CreatedAt: at(3 * day), ClosedAt: ptr(at(day)), Context: map[string]string{"agent_id": "agent-1"}},
+		// Opened last week, closed this week
+		{ID: "b2", ProjectID: "proj-1", Status: models.BeadStatusClosed, CreatedAt: at(10 * day), ClosedAt: ptr(at(2 * day)), Context: map[string]string{"agent_id": "agent-1"}},
+		// Closed last week
+		{ID: "b3", ProjectID: "proj-1", Status: models.BeadStatusClosed, CreatedAt: at(12 * day), ClosedAt: ptr(at(9 * day))},
+		// Opened this week, still open
+		{ID: "b4", ProjectID: "proj-1", Status: models.BeadStatusOpen, CreatedAt: at(day)},
+		// After the period, and another project's
+		{ID: "b5", ProjectID: "proj-1", Status: models.BeadStatusOpen, CreatedAt: end.Add(time.Hour)},
+		{ID: "b6", ProjectID: "proj-2", Status: models.BeadStatusOpen, CreatedAt: at(day)},
+	}
+	meta := map[string]string{"agent_id": "agent-1", "project_id": "proj-1"}
+	logs := []*analytics.RequestLog{
+		{Timestamp: at(day), Metadata: meta, StatusCode: 200, TotalTokens: 1000, CostUSD: 1.5},
+		{Timestamp: at(2 * day), Metadata: meta, StatusCode: 500, TotalTokens: 10, CostUSD: 0.25, ErrorMessage: "provider timeout\nretry 3"},
+		{Timestamp: at(3 * day), Metadata: meta, StatusCode: 500, ErrorMessage: "provider timeout\nretry 1"},
+		{Timestamp: at(3 * day), Metadata: meta, StatusCode: 400, ErrorMessage: "bad request"},
+		// Outside the week, and another project's
+		{Timestamp: at(8 * day), Metadata: meta, StatusCode: 200, CostUSD: 100},
+		{Timestamp: at(day), Metadata: map[string]string{"project_id": "proj-2"}, CostUSD: 100},
+	}
+	agents := []*models.Agent{{ID: "agent-1", Name: "Engineer", ProjectID: "proj-1"}, {ID: "agent-2", Name: "Idle", ProjectID: "proj-1"}}
+
+	r := Build(project, PeriodWeek, end, beads, logs, agents)
+	if r.BeadsOpened != 2 || r.BeadsClosed != 2 || r.BeadsOpen != 1 {
+		t.Errorf("opened/closed/open = %d/%d/%d, want 2/2/1", r.BeadsOpened, r.BeadsClosed, r.BeadsOpen)
+	}
+	if r.Velocity != 2.0/7 || r.PreviousVelocity != 1.0/7 {
+		t.Errorf("velocity = %v against %v", r.Velocity, r.PreviousVelocity)
+	}
+	if r.Spend.CostUSD != 1.75 || r.Spend.Tokens != 1010 || r.Spend.Requests != 4 {
+		t.Errorf("spend = %+v", r.Spend)
+	}
+	if len(r.TopErrors) != 2 || r.TopErrors[0] != (ErrorCount{"provider timeout", 2}) {
+		t.Errorf("top errors = %+v", r.TopErrors)
+	}
+	if len(r.Agents) != 1 || r.Agents[0].Name != "Engineer" || r.Agents[0].BeadsClosed != 2 || r.Agents[0].Tasks != 4 || r.Agents[0].Failed != 3 {
+		t.Errorf("agents = %+v", r.Agents)
+	}
+	if r.Title() != "Weekly report for Loom, week of Feb 23, 2026" {
+		t.Errorf("title = %q", r.Title())
+	}
+
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Weekly report for Loom", "provider timeout", "Engineer", "$1.75"} {
+		if !strings.Contains(r.Markdown, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, r.Markdown)
+		}
+		if !strings.Contains(r.HTML, want) {
+			t.Errorf("HTML lacks %q", want)
+		}
+	}
+}
+
+// fakeGenerator records the reports it was asked for.
+type fakeGenerator struct {
+	stored      map[string]bool
+	failing     string
+	distributed []string
+}
+
+func (f *fakeGenerator) key(pid string, period Period, end time.Time) string {
+	return fmt.Sprintf("%s/%s/%s", pid, period, end.Format(time.RFC3339))
+}
+
+func (f *fakeGenerator) ListProjectIDs() []string { return []string{"a", "b"} }
+
+func (f *fakeGenerator) HasReport(pid string, period Period, end time.Time) (bool, error) {
+	return f.stored[f.key(pid, period, end)], nil
+}
+
+func (f *fakeGenerator) GenerateReport(ctx context.Context, pid string, period Period, end time.Time) (*Report, error) {
+	if pid == f.failing {
+		return nil, fmt.Errorf("boom")
+	}
+	f.stored[f.key(pid, period, end)] = true
+	return &Report{ID: f.key(pid, period, end), ProjectID: pid, Period: period, End: end}, nil
+}
+
+func (f *fakeGenerator) DistributeReport(r *Report) error {
+	f.distributed = append(f.distributed, r.ID)
+	return nil
+}
+
+func TestRunnerSweep(t *testing.T) {
+	gen := &fakeGenerator{stored: map[string]bool{}, failing: "b"}
+	runner := NewRunner(gen, []Period{PeriodDay, PeriodWeek}, nil)
+	now := time.Date(2026, 3, 5, 15, 0, 0, 0, time.UTC)
+
+	if n := runner.Sweep(context.Background(), now); n != 2 {
+		t.Fatalf("first sweep generated %d, want 2", n)
+	}
+	want := "a/day/2026-03-05T00:00:00Z,a/week/2026-03-02T00:00:00Z"
+	if got := strings.Join(gen.distributed, ","); got != want {
+		t.Errorf("distributed %s, want %s", got, want)
+	}
+	if n := runner.Sweep(context.Background(), now.Add(time.Hour)); n != 0 {
+		t.Errorf("second sweep in the same day generated %d", n)
+	}
+	if n := runner.Sweep(context.Background(), now.Add(24*time.Hour)); n != 1 {
+		t.Errorf("next day's sweep generated %d, want 1", n)
+	}
+
+	only := NewRunner(gen, []Period{PeriodDay}, []string{"c"})
+	if n := only.Sweep(context.Background(), now); n != 1 || !gen.stored["c/day/2026-03-05T00:00:00Z"] {
+		t.Errorf("sweep limited to c generated %d", n)
+	}
+}
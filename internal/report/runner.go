@@ -0,0 +1,105 @@
+package report
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Generator compiles, stores and sends out project reports.
+type Generator interface {
+	ListProjectIDs() []string
+	// HasReport reports whether the project's report for the period
+	// ending at end was already generated.
+	HasReport(projectID string, period Period, end time.Time) (bool, error)
+	// GenerateReport compiles and stores the project's report for the
+	// period ending at end.
+	GenerateReport(ctx context.Context, projectID string, period Period, end time.Time) (*Report, error)
+	// DistributeReport sends a report out on the notification channels.
+	DistributeReport(r *Report) error
+}
+
+// checkInterval is how often the runner looks for reports that are due.
+const checkInterval = time.Hour
+
+// Runner generates each project's daily and weekly reports once their
+// period is over: daily reports after midnight UTC, weekly reports after
+// Monday midnight UTC. A report missed while loom was down is generated
+// when it starts again.
+type Runner struct {
+	gen      Generator
+	periods  []Period
+	projects []string
+	stopCh   chan struct{}
+}
+
+// NewRunner creates a runner for the given periods. projects limits it to
+// those project IDs; empty covers all.
+func NewRunner(gen Generator, periods []Period, projects []string) *Runner {
+	return &Runner{
+		gen:      gen,
+		periods:  periods,
+		projects: projects,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start generates the reports that are due, then checks again every hour
+// until the context is cancelled or Stop is called.
+func (r *Runner) Start(ctx context.Context) {
+	log.Printf("[Reports] Starting with periods %v", r.periods)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		r.Sweep(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop signals the runner to exit its loop.
+func (r *Runner) Stop() {
+	close(r.stopCh)
+}
+
+// Sweep generates and sends out the reports due at now that have not
+// been generated yet, and returns how many it generated.
+func (r *Runner) Sweep(ctx context.Context, now time.Time) int {
+	projectIDs := r.projects
+	if len(projectIDs) == 0 {
+		projectIDs = r.gen.ListProjectIDs()
+	}
+	generated := 0
+	for _, period := range r.periods {
+		end := period.LastEnd(now)
+		for _, pid := range projectIDs {
+			if ctx.Err() != nil {
+				return generated
+			}
+			done, err := r.gen.HasReport(pid, period, end)
+			if err != nil {
+				log.Printf("[Reports] project %s: %v", pid, err)
+				continue
+			}
+			if done {
+				continue
+			}
+			rep, err := r.gen.GenerateReport(ctx, pid, period, end)
+			if err != nil {
+				log.Printf("[Reports] project %s: %s report failed: %v", pid, period, err)
+				continue
+			}
+			generated++
+			if err := r.gen.DistributeReport(rep); err != nil {
+				log.Printf("[Reports] project %s: failed to send out report %s: %v", pid, rep.ID, err)
+			}
+		}
+	}
+	return generated
+}
@@ -0,0 +1,255 @@
+// Package sandbox runs agent commands under CPU, memory, process, file-size,
+// and wall-time limits so a runaway build or test cannot exhaust the host.
+//
+// On Linux each command is placed in its own cgroup v2 group when loom can
+// manage cgroups, and otherwise gets per-process resource limits (rlimits).
+// Elsewhere only the timeout is enforced.
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// Enforcement modes.
+const (
+	ModeAuto   = "auto"   // cgroups when available, rlimits otherwise
+	ModeCgroup = "cgroup" // cgroups only; fall back to rlimits with a warning
+	ModeRlimit = "rlimit" // rlimits only
+	ModeOff    = "off"    // timeout only
+)
+
+// Reasons a command was stopped, reported in Report.Exceeded.
+const (
+	ExceededTimeout   = "timeout"
+	ExceededMemory    = "memory"
+	ExceededCPU       = "cpu"
+	ExceededProcesses = "processes"
+	ExceededFileSize  = "file_size"
+)
+
+// DefaultProfile is the profile used when neither the configuration nor the
+// project names one.
+const DefaultProfile = "standard"
+
+// defaultTimeoutSeconds applies when neither the request nor the profile
+// sets a timeout.
+const defaultTimeoutSeconds = 300
+
+// Limits caps the resources one command may use. Zero means no limit.
+type Limits struct {
+	TimeoutSeconds int     `json:"timeout_seconds,omitempty"`
+	CPUs           float64 `json:"cpus,omitempty"`
+	MemoryMB       int64   `json:"memory_mb,omitempty"`
+	MaxProcesses   int64   `json:"max_processes,omitempty"`
+	MaxFileSizeMB  int64   `json:"max_file_size_mb,omitempty"`
+}
+
+// resourceLimited reports whether any limit other than the timeout is set.
+func (l Limits) resourceLimited() bool {
+	return l.CPUs > 0 || l.MemoryMB > 0 || l.MaxProcesses > 0 || l.MaxFileSizeMB > 0
+}
+
+// Timeout returns the timeout in seconds for a command that asked for
+// requested seconds: the request, capped at the profile's timeout, or the
+// profile's timeout when nothing was requested.
+func (l Limits) Timeout(requested int) int {
+	switch {
+	case requested <= 0 && l.TimeoutSeconds > 0:
+		return l.TimeoutSeconds
+	case requested <= 0:
+		return defaultTimeoutSeconds
+	case l.TimeoutSeconds > 0 && requested > l.TimeoutSeconds:
+		return l.TimeoutSeconds
+	}
+	return requested
+}
+
+// builtinProfiles are available without configuration. Configured profiles
+// with the same name replace them.
+var builtinProfiles = map[string]Limits{
+	"small":     {TimeoutSeconds: 300, CPUs: 1, MemoryMB: 1024, MaxProcesses: 128, MaxFileSizeMB: 512},
+	"standard":  {TimeoutSeconds: 600, CPUs: 2, MemoryMB: 4096, MaxProcesses: 512, MaxFileSizeMB: 2048},
+	"large":     {TimeoutSeconds: 1800, CPUs: 4, MemoryMB: 8192, MaxProcesses: 2048, MaxFileSizeMB: 8192},
+	"unlimited": {},
+}
+
+// Project context keys that select a profile or override single limits.
+const (
+	ContextProfile       = "sandbox_profile"
+	ContextTimeout       = "sandbox_timeout_seconds"
+	ContextCPUs          = "sandbox_cpus"
+	ContextMemoryMB      = "sandbox_memory_mb"
+	ContextMaxProcesses  = "sandbox_max_processes"
+	ContextMaxFileSizeMB = "sandbox_max_file_size_mb"
+)
+
+// Report describes how a command was sandboxed and whether it hit a limit.
+type Report struct {
+	Profile  string `json:"profile,omitempty"`
+	Mode     string `json:"mode"`
+	Exceeded string `json:"exceeded,omitempty"`
+}
+
+// Sandbox resolves limits for projects and runs commands under them.
+type Sandbox struct {
+	mode           string
+	defaultProfile string
+	cgroupRoot     string
+	profiles       map[string]Limits
+
+	once       sync.Once
+	cgroupsErr error // why cgroups can't be used, set once by detect
+}
+
+// New creates a sandbox from configuration. A nil config uses the built-in
+// profiles, the "standard" default, and automatic enforcement.
+func New(cfg *config.SandboxConfig) *Sandbox {
+	s := &Sandbox{
+		mode:           ModeAuto,
+		defaultProfile: DefaultProfile,
+		cgroupRoot:     "/sys/fs/cgroup/loom",
+		profiles:       make(map[string]Limits, len(builtinProfiles)),
+	}
+	for name, l := range builtinProfiles {
+		s.profiles[name] = l
+	}
+	if cfg == nil {
+		return s
+	}
+	if cfg.Mode != "" {
+		s.mode = cfg.Mode
+	}
+	if cfg.DefaultProfile != "" {
+		s.defaultProfile = cfg.DefaultProfile
+	}
+	if cfg.CgroupRoot != "" {
+		s.cgroupRoot = cfg.CgroupRoot
+	}
+	for name, p := range cfg.Profiles {
+		s.profiles[name] = Limits{
+			TimeoutSeconds: p.TimeoutSeconds,
+			CPUs:           p.CPUs,
+			MemoryMB:       p.MemoryMB,
+			MaxProcesses:   p.MaxProcesses,
+			MaxFileSizeMB:  p.MaxFileSizeMB,
+		}
+	}
+	if _, ok := s.profiles[s.defaultProfile]; !ok {
+		log.Printf("[Sandbox] Default profile %q is not defined, using %q", s.defaultProfile, DefaultProfile)
+		s.defaultProfile = DefaultProfile
+	}
+	return s
+}
+
+// Profiles returns the profile names in sorted order.
+func (s *Sandbox) Profiles() []string {
+	names := make([]string, 0, len(s.profiles))
+	for name := range s.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns a named profile's limits.
+func (s *Sandbox) Profile(name string) (Limits, bool) {
+	l, ok := s.profiles[name]
+	return l, ok
+}
+
+// Resolve returns the profile name and limits for a project from its
+// context: sandbox_profile picks the profile, and the sandbox_* limit keys
+// override single limits on top of it. Unknown profiles and malformed
+// values are logged and ignored.
+func (s *Sandbox) Resolve(projectContext map[string]string) (string, Limits) {
+	name := s.defaultProfile
+	if p := strings.TrimSpace(projectContext[ContextProfile]); p != "" {
+		if _, ok := s.profiles[p]; ok {
+			name = p
+		} else {
+			log.Printf("[Sandbox] Unknown profile %q, using %q", p, name)
+		}
+	}
+	limits := s.profiles[name]
+
+	limits.TimeoutSeconds = int(contextInt(projectContext, ContextTimeout, int64(limits.TimeoutSeconds)))
+	limits.MemoryMB = contextInt(projectContext, ContextMemoryMB, limits.MemoryMB)
+	limits.MaxProcesses = contextInt(projectContext, ContextMaxProcesses, limits.MaxProcesses)
+	limits.MaxFileSizeMB = contextInt(projectContext, ContextMaxFileSizeMB, limits.MaxFileSizeMB)
+	if v := strings.TrimSpace(projectContext[ContextCPUs]); v != "" {
+		if cpus, err := strconv.ParseFloat(v, 64); err == nil && cpus >= 0 {
+			limits.CPUs = cpus
+		} else {
+			log.Printf("[Sandbox] Ignoring %s=%q", ContextCPUs, v)
+		}
+	}
+	return name, limits
+}
+
+func contextInt(projectContext map[string]string, key string, fallback int64) int64 {
+	v := strings.TrimSpace(projectContext[key])
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("[Sandbox] Ignoring %s=%q", key, v)
+		return fallback
+	}
+	return n
+}
+
+// Run runs cmd under limits and waits for it. cmd must have been created
+// with exec.CommandContext(ctx, ...); when ctx ends the whole process tree
+// is killed, not just the direct child. The returned error is cmd's.
+func (s *Sandbox) Run(ctx context.Context, cmd *exec.Cmd, limits Limits) (Report, error) {
+	report := Report{Mode: ModeOff}
+	finish := func(*Report, bool) {}
+	if s.mode != ModeOff && limits.resourceLimited() {
+		var err error
+		finish, err = s.prepare(cmd, limits, &report)
+		if err != nil {
+			return report, fmt.Errorf("sandbox: %w", err)
+		}
+	} else {
+		killProcessGroup(cmd)
+	}
+
+	if err := cmd.Start(); err != nil {
+		finish(&report, true)
+		return report, err
+	}
+	s.started(cmd, limits, &report)
+	err := cmd.Wait()
+	finish(&report, err != nil)
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		report.Exceeded = ExceededTimeout
+	} else if report.Exceeded == "" && err != nil {
+		report.Exceeded = signalExceeded(cmd)
+	}
+	return report, err
+}
+
+// String describes the report for command output, e.g. "killed: memory
+// limit exceeded (profile standard, cgroup)".
+func (r Report) String() string {
+	if r.Exceeded == "" {
+		return ""
+	}
+	where := r.Mode
+	if r.Profile != "" {
+		where = "profile " + r.Profile + ", " + r.Mode
+	}
+	return fmt.Sprintf("killed: %s limit exceeded (%s)", strings.ReplaceAll(r.Exceeded, "_", " "), where)
+}
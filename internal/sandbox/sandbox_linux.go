@@ -0,0 +1,213 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var cgroupSeq atomic.Int64
+
+// killProcessGroup runs cmd in its own process group and kills the whole
+// group when its context ends, so children of a shell or make don't outlive
+// the timeout.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 5 * time.Second
+}
+
+// detect decides once whether commands can be put in cgroups: cgroup v2
+// must be mounted and loom must be able to create the root group and
+// delegate the cpu, memory, and pids controllers to it.
+func (s *Sandbox) detect() {
+	if s.mode == ModeRlimit {
+		s.cgroupsErr = errors.New("rlimit mode configured")
+		return
+	}
+	defer func() {
+		if s.cgroupsErr != nil {
+			log.Printf("[Sandbox] cgroups unavailable, using rlimits: %v", s.cgroupsErr)
+		} else {
+			log.Printf("[Sandbox] Using cgroup limits under %s", s.cgroupRoot)
+		}
+	}()
+	var fs unix.Statfs_t
+	if err := unix.Statfs(filepath.Dir(s.cgroupRoot), &fs); err != nil || fs.Type != unix.CGROUP2_SUPER_MAGIC {
+		s.cgroupsErr = fmt.Errorf("%s is not on a cgroup v2 mount", s.cgroupRoot)
+		return
+	}
+	if err := os.MkdirAll(s.cgroupRoot, 0o755); err != nil {
+		s.cgroupsErr = err
+		return
+	}
+	// The parent may already delegate these; if it can't, enabling them on
+	// the root group below fails and says so.
+	_ = os.WriteFile(filepath.Join(filepath.Dir(s.cgroupRoot), "cgroup.subtree_control"), []byte("+cpu +memory +pids"), 0)
+	if err := os.WriteFile(filepath.Join(s.cgroupRoot, "cgroup.subtree_control"), []byte("+cpu +memory +pids"), 0); err != nil {
+		s.cgroupsErr = fmt.Errorf("enable controllers in %s: %w", s.cgroupRoot, err)
+	}
+}
+
+// prepare sets cmd up to start inside a new cgroup carrying limits, or
+// notes that rlimits will be applied once it has started. The returned
+// function reads the cgroup's events into report and removes the group.
+func (s *Sandbox) prepare(cmd *exec.Cmd, limits Limits, report *Report) (func(*Report, bool), error) {
+	killProcessGroup(cmd)
+	report.Mode = ModeRlimit
+
+	if limits.CPUs <= 0 && limits.MemoryMB <= 0 && limits.MaxProcesses <= 0 {
+		return func(*Report, bool) {}, nil
+	}
+	s.once.Do(s.detect)
+	if s.cgroupsErr != nil {
+		return func(*Report, bool) {}, nil
+	}
+
+	dir := filepath.Join(s.cgroupRoot, fmt.Sprintf("cmd-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		log.Printf("[Sandbox] Failed to create cgroup, using rlimits: %v", err)
+		return func(*Report, bool) {}, nil
+	}
+	if err := writeCgroupLimits(dir, limits); err != nil {
+		_ = os.Remove(dir)
+		log.Printf("[Sandbox] Failed to set cgroup limits, using rlimits: %v", err)
+		return func(*Report, bool) {}, nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		_ = os.Remove(dir)
+		return nil, err
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(f.Fd())
+	report.Mode = ModeCgroup
+
+	return func(report *Report, failed bool) {
+		f.Close()
+		if cgroupEvent(dir, "memory.events", "oom_kill") > 0 {
+			report.Exceeded = ExceededMemory
+		} else if failed && cgroupEvent(dir, "pids.events", "max") > 0 {
+			report.Exceeded = ExceededProcesses
+		}
+		removeCgroup(dir)
+	}, nil
+}
+
+func writeCgroupLimits(dir string, limits Limits) error {
+	files := map[string]string{}
+	if limits.CPUs > 0 {
+		const period = 100000
+		files["cpu.max"] = fmt.Sprintf("%d %d", int64(math.Ceil(limits.CPUs*period)), period)
+	}
+	if limits.MemoryMB > 0 {
+		files["memory.max"] = strconv.FormatInt(limits.MemoryMB<<20, 10)
+		files["memory.swap.max"] = "0"
+	}
+	if limits.MaxProcesses > 0 {
+		files["pids.max"] = strconv.FormatInt(limits.MaxProcesses, 10)
+	}
+	for name, value := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0); err != nil {
+			// Swap accounting is often disabled; memory.max still applies.
+			if name == "memory.swap.max" {
+				continue
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// cgroupEvent reads a counter from a cgroup events file such as
+// memory.events.
+func cgroupEvent(dir, file, key string) int64 {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, value, ok := strings.Cut(line, " "); ok && name == key {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// removeCgroup kills anything left in a command's cgroup and removes it.
+func removeCgroup(dir string) {
+	_ = os.WriteFile(filepath.Join(dir, "cgroup.kill"), []byte("1"), 0)
+	for i := 0; i < 10; i++ {
+		if err := os.Remove(dir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	log.Printf("[Sandbox] Failed to remove cgroup %s", dir)
+}
+
+// started applies the rlimits for a command that has just started. File
+// size is always capped this way since cgroups can't; memory and CPU time
+// are only capped here when the command isn't in a cgroup. RLIMIT_NPROC
+// counts every process of the user, not just the command's, so process
+// limits are left to cgroups.
+func (s *Sandbox) started(cmd *exec.Cmd, limits Limits, report *Report) {
+	if report.Mode == ModeOff {
+		return
+	}
+	pid := cmd.Process.Pid
+	set := func(resource int, value uint64) {
+		rl := unix.Rlimit{Cur: value, Max: value}
+		if err := unix.Prlimit(pid, resource, &rl, nil); err != nil {
+			log.Printf("[Sandbox] Failed to set rlimit %d on pid %d: %v", resource, pid, err)
+		}
+	}
+	if limits.MaxFileSizeMB > 0 {
+		set(unix.RLIMIT_FSIZE, uint64(limits.MaxFileSizeMB)<<20)
+	}
+	if report.Mode != ModeRlimit {
+		return
+	}
+	if limits.MemoryMB > 0 {
+		set(unix.RLIMIT_DATA, uint64(limits.MemoryMB)<<20)
+	}
+	if limits.CPUs > 0 && limits.TimeoutSeconds > 0 {
+		set(unix.RLIMIT_CPU, uint64(math.Ceil(limits.CPUs*float64(limits.TimeoutSeconds))))
+	}
+}
+
+// signalExceeded maps the signals the kernel sends on rlimit overruns to
+// the limit that was hit.
+func signalExceeded(cmd *exec.Cmd) string {
+	if cmd.ProcessState == nil {
+		return ""
+	}
+	ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return ""
+	}
+	switch ws.Signal() {
+	case syscall.SIGXCPU:
+		return ExceededCPU
+	case syscall.SIGXFSZ:
+		return ExceededFileSize
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"log"
+	"os/exec"
+	"sync"
+)
+
+var warnOnce sync.Once
+
+// killProcessGroup is a no-op here; the context kills the direct child.
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// prepare only warns: resource limits need Linux, so just the timeout is
+// enforced.
+func (s *Sandbox) prepare(cmd *exec.Cmd, limits Limits, report *Report) (func(*Report, bool), error) {
+	warnOnce.Do(func() {
+		log.Printf("[Sandbox] Resource limits are only enforced on Linux; commands get the timeout only")
+	})
+	return func(*Report, bool) {}, nil
+}
+
+func (s *Sandbox) started(cmd *exec.Cmd, limits Limits, report *Report) {}
+
+func signalExceeded(cmd *exec.Cmd) string { return "" }
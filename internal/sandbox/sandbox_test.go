@@ -0,0 +1,142 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+func TestResolve(t *testing.T) {
+	sb := New(&config.SandboxConfig{
+		DefaultProfile: "small",
+		Profiles: map[string]config.SandboxProfile{
+			"ci": {TimeoutSeconds: 900, CPUs: 8, MemoryMB: 16384},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		context map[string]string
+		profile string
+		want    Limits
+	}{
+		{"default", nil, "small", builtinProfiles["small"]},
+		{"named", map[string]string{ContextProfile: "ci"}, "ci", Limits{TimeoutSeconds: 900, CPUs: 8, MemoryMB: 16384}},
+		{"unknown falls back", map[string]string{ContextProfile: "huge"}, "small", builtinProfiles["small"]},
+		{
+			"overrides",
+			map[string]string{ContextProfile: "large", ContextMemoryMB: "2048", ContextCPUs: "0.5", ContextMaxProcesses: "64"},
+			"large",
+			Limits{TimeoutSeconds: 1800, CPUs: 0.5, MemoryMB: 2048, MaxProcesses: 64, MaxFileSizeMB: 8192},
+		},
+		{"bad override ignored", map[string]string{ContextMemoryMB: "lots", ContextTimeout: "-5"}, "small", builtinProfiles["small"]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, limits := sb.Resolve(tt.context)
+			if profile != tt.profile {
+				t.Errorf("profile = %q, want %q", profile, tt.profile)
+			}
+			if limits != tt.want {
+				t.Errorf("limits = %+v, want %+v", limits, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_UnknownDefaultProfile(t *testing.T) {
+	sb := New(&config.SandboxConfig{DefaultProfile: "missing"})
+	if profile, _ := sb.Resolve(nil); profile != DefaultProfile {
+		t.Errorf("profile = %q, want %q", profile, DefaultProfile)
+	}
+}
+
+func TestLimitsTimeout(t *testing.T) {
+	tests := []struct {
+		limits    Limits
+		requested int
+		want      int
+	}{
+		{Limits{}, 0, defaultTimeoutSeconds},
+		{Limits{}, 3600, 3600},
+		{Limits{TimeoutSeconds: 600}, 0, 600},
+		{Limits{TimeoutSeconds: 600}, 60, 60},
+		{Limits{TimeoutSeconds: 600}, 3600, 600},
+	}
+	for _, tt := range tests {
+		if got := tt.limits.Timeout(tt.requested); got != tt.want {
+			t.Errorf("%+v.Timeout(%d) = %d, want %d", tt.limits, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	sb := New(&config.SandboxConfig{Mode: ModeRlimit})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The background sleep would keep the pipes open past the timeout if
+	// only the shell were killed.
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", "sleep 30 & sleep 30")
+	var out strings.Builder
+	cmd.Stdout = &out
+
+	start := time.Now()
+	report, err := sb.Run(ctx, cmd, Limits{TimeoutSeconds: 1, MaxFileSizeMB: 1})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if report.Exceeded != ExceededTimeout {
+		t.Errorf("Exceeded = %q, want %q", report.Exceeded, ExceededTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Run took %v; the process group was not killed", elapsed)
+	}
+}
+
+func TestRun_FileSizeLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only enforced on Linux")
+	}
+	sb := New(&config.SandboxConfig{Mode: ModeRlimit})
+	out := filepath.Join(t.TempDir(), "big")
+	cmd := exec.CommandContext(context.Background(), "/bin/sh", "-c", "sleep 0.2; exec head -c 2097152 /dev/zero > "+out)
+
+	report, err := sb.Run(context.Background(), cmd, Limits{MaxFileSizeMB: 1})
+	if err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if report.Mode != ModeRlimit {
+		t.Errorf("Mode = %q, want %q", report.Mode, ModeRlimit)
+	}
+	if report.Exceeded != ExceededFileSize {
+		t.Errorf("Exceeded = %q, want %q", report.Exceeded, ExceededFileSize)
+	}
+	if !strings.Contains(report.String(), "file size limit exceeded") {
+		t.Errorf("String() = %q", report.String())
+	}
+}
+
+func TestRun_NoLimits(t *testing.T) {
+	sb := New(nil)
+	cmd := exec.CommandContext(context.Background(), "/bin/sh", "-c", "echo ok")
+	var out strings.Builder
+	cmd.Stdout = &out
+
+	report, err := sb.Run(context.Background(), cmd, Limits{TimeoutSeconds: 5})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Mode != ModeOff || report.Exceeded != "" {
+		t.Errorf("report = %+v, want mode off and nothing exceeded", report)
+	}
+	if out.String() != "ok\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,308 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ErrNotFound is returned when a schedule ID does not exist.
+var ErrNotFound = errors.New("bead schedule not found")
+
+// defaultCheckInterval is how often due schedules are looked for. Cron has
+// minute resolution, so anything shorter than a minute fires on time.
+const defaultCheckInterval = 30 * time.Second
+
+// BeadSource creates and inspects the beads a schedule materializes.
+// *loom.Loom satisfies it.
+type BeadSource interface {
+	CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error)
+	GetBead(beadID string) (*models.Bead, error)
+	UpdateBead(beadID string, updates map[string]interface{}) (*models.Bead, error)
+}
+
+// Schedule is a recurring bead definition.
+type Schedule struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	ProjectID   string     `json:"project_id"`
+	Cron        string     `json:"cron"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Priority    int        `json:"priority"`
+	Type        string     `json:"type"`
+	Enabled     bool       `json:"enabled"`
+	NextFireAt  *time.Time `json:"next_fire_at,omitempty"`
+	LastFiredAt *time.Time `json:"last_fired_at,omitempty"`
+	LastBeadID  string     `json:"last_bead_id,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateRequest is the body accepted when defining a schedule.
+type CreateRequest struct {
+	Name        string `json:"name"`
+	ProjectID   string `json:"project_id"`
+	Cron        string `json:"cron"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    *int   `json:"priority,omitempty"`
+	Type        string `json:"type"`
+}
+
+// Manager persists bead schedules and materializes them into concrete beads
+// when they fire. A schedule whose previous bead is still open is skipped
+// for that firing rather than piling up duplicates.
+type Manager struct {
+	db       *database.Database
+	beads    BeadSource
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex // serializes firing
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager creates a schedule manager. Returns nil when db is nil, since
+// schedules are only kept in the database.
+func NewManager(db *database.Database, beads BeadSource) *Manager {
+	if db == nil || beads == nil {
+		return nil
+	}
+	return &Manager{
+		db:       db,
+		beads:    beads,
+		interval: defaultCheckInterval,
+		now:      time.Now,
+	}
+}
+
+// Start begins checking for due schedules in the background until ctx is
+// cancelled or Close is called.
+func (m *Manager) Start(ctx context.Context) {
+	if m == nil || m.done != nil {
+		return
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.FireDue()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the background loop and waits for it to exit.
+func (m *Manager) Close() {
+	if m == nil || m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+}
+
+// Create validates and stores a new schedule.
+func (m *Manager) Create(req CreateRequest, createdBy string) (*Schedule, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if req.ProjectID == "" {
+		return nil, fmt.Errorf("project_id is required")
+	}
+	cron, err := ParseCron(req.Cron)
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", req.Cron)
+	}
+
+	priority := int(models.BeadPriorityP2)
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
+	if priority < int(models.BeadPriorityP0) || priority > int(models.BeadPriorityP3) {
+		return nil, fmt.Errorf("priority must be between 0 and 3")
+	}
+	if req.Type == "" {
+		req.Type = "task"
+	}
+	if req.Name == "" {
+		req.Name = req.Title
+	}
+
+	s := &database.BeadSchedule{
+		ID:          "sched-" + uuid.New().String()[:8],
+		Name:        req.Name,
+		ProjectID:   req.ProjectID,
+		CronExpr:    cron.String(),
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    priority,
+		BeadType:    req.Type,
+		Enabled:     true,
+		NextFireAt:  &next,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.db.CreateBeadSchedule(s); err != nil {
+		return nil, err
+	}
+	return toSchedule(s), nil
+}
+
+// Get returns a schedule by ID.
+func (m *Manager) Get(id string) (*Schedule, error) {
+	s, err := m.db.GetBeadSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return toSchedule(s), nil
+}
+
+// List returns schedules, optionally filtered by project.
+func (m *Manager) List(projectID string) ([]*Schedule, error) {
+	rows, err := m.db.ListBeadSchedules(projectID)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]*Schedule, 0, len(rows))
+	for _, s := range rows {
+		schedules = append(schedules, toSchedule(s))
+	}
+	return schedules, nil
+}
+
+// Delete removes a schedule. Beads it already created are left alone.
+func (m *Manager) Delete(id string) error {
+	if _, err := m.Get(id); err != nil {
+		return err
+	}
+	return m.db.DeleteBeadSchedule(id)
+}
+
+// FireDue materializes every schedule whose fire time has passed and
+// returns the number of beads created. Missed firings (e.g. while loom was
+// down) collapse into a single bead.
+func (m *Manager) FireDue() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now().UTC()
+	due, err := m.db.ListDueBeadSchedules(now)
+	if err != nil {
+		log.Printf("[Scheduler] Failed to list due schedules: %v", err)
+		return 0
+	}
+
+	created := 0
+	for _, s := range due {
+		if m.fire(s, now) {
+			created++
+		}
+	}
+	return created
+}
+
+// fire creates the bead for one due schedule unless its previous bead is
+// still open, then advances the schedule to its next fire time.
+func (m *Manager) fire(s *database.BeadSchedule, now time.Time) bool {
+	cron, err := ParseCron(s.CronExpr)
+	if err != nil {
+		log.Printf("[Scheduler] Disabling schedule %s: %v", s.ID, err)
+		s.Enabled = false
+		s.UpdatedAt = now
+		_ = m.db.UpdateBeadSchedule(s)
+		return false
+	}
+
+	created := false
+	if open := m.openInstance(s.LastBeadID); open != "" {
+		log.Printf("[Scheduler] Skipping %s (%s): previous bead %s is still %s", s.ID, s.Name, s.LastBeadID, open)
+	} else {
+		bead, err := m.beads.CreateBead(s.Title, s.Description, models.BeadPriority(s.Priority), s.BeadType, s.ProjectID)
+		if err != nil {
+			// Leave next_fire_at alone so the next check retries.
+			log.Printf("[Scheduler] Failed to create bead for %s (%s): %v", s.ID, s.Name, err)
+			return false
+		}
+		_, _ = m.beads.UpdateBead(bead.ID, map[string]interface{}{
+			"context": map[string]string{
+				"schedule_id":       s.ID,
+				"schedule_fired_at": now.Format(time.RFC3339),
+			},
+		})
+		log.Printf("[Scheduler] Schedule %s (%s) created bead %s", s.ID, s.Name, bead.ID)
+		s.LastBeadID = bead.ID
+		s.LastFiredAt = &now
+		created = true
+	}
+
+	if next := cron.Next(now); next.IsZero() {
+		s.NextFireAt = nil
+	} else {
+		s.NextFireAt = &next
+	}
+	s.UpdatedAt = now
+	if err := m.db.UpdateBeadSchedule(s); err != nil {
+		log.Printf("[Scheduler] Failed to update schedule %s: %v", s.ID, err)
+	}
+	return created
+}
+
+// openInstance returns the status of the bead if it exists and is not yet
+// closed, or "" otherwise.
+func (m *Manager) openInstance(beadID string) string {
+	if beadID == "" {
+		return ""
+	}
+	bead, err := m.beads.GetBead(beadID)
+	if err != nil || bead == nil || bead.Status == models.BeadStatusClosed {
+		return ""
+	}
+	return string(bead.Status)
+}
+
+func toSchedule(s *database.BeadSchedule) *Schedule {
+	return &Schedule{
+		ID:          s.ID,
+		Name:        s.Name,
+		ProjectID:   s.ProjectID,
+		Cron:        s.CronExpr,
+		Title:       s.Title,
+		Description: s.Description,
+		Priority:    s.Priority,
+		Type:        s.BeadType,
+		Enabled:     s.Enabled,
+		NextFireAt:  s.NextFireAt,
+		LastFiredAt: s.LastFiredAt,
+		LastBeadID:  s.LastBeadID,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+	}
+}
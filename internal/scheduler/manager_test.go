@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type fakeBeads struct {
+	beads map[string]*models.Bead
+}
+
+func (f *fakeBeads) CreateBead(title, description string, priority models.BeadPriority, beadType, projectID string) (*models.Bead, error) {
+	b := &models.Bead{
+		ID:        fmt.Sprintf("bd-%d", len(f.beads)+1),
+		Title:     title,
+		Priority:  priority,
+		Type:      beadType,
+		ProjectID: projectID,
+		Status:    models.BeadStatusOpen,
+	}
+	f.beads[b.ID] = b
+	return b, nil
+}
+
+func (f *fakeBeads) GetBead(id string) (*models.Bead, error) {
+	b, ok := f.beads[id]
+	if !ok {
+		return nil, fmt.Errorf("bead not found: %s", id)
+	}
+	return b, nil
+}
+
+func (f *fakeBeads) UpdateBead(id string, updates map[string]interface{}) (*models.Bead, error) {
+	return f.GetBead(id)
+}
+
+func TestManager_FireDueDedupsOpenInstances(t *testing.T) {
+	db := dbtest.New(t)
+
+	beads := &fakeBeads{beads: map[string]*models.Bead{}}
+	m := NewManager(db, beads)
+	clock := time.Date(2026, 10, 16, 23, 50, 0, 0, time.UTC)
+	m.now = func() time.Time { return clock }
+
+	if _, err := m.Create(CreateRequest{ProjectID: "loom", Cron: "not cron", Title: "x"}, ""); err == nil {
+		t.Error("expected error for invalid cron expression")
+	}
+
+	s, err := m.Create(CreateRequest{
+		ProjectID: "loom",
+		Cron:      "@daily",
+		Title:     "Nightly dependency audit",
+	}, "admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if s.NextFireAt == nil || !s.NextFireAt.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextFireAt = %v", s.NextFireAt)
+	}
+
+	if n := m.FireDue(); n != 0 {
+		t.Fatalf("fired %d before due", n)
+	}
+
+	// First night: creates a bead.
+	clock = time.Date(2026, 10, 17, 0, 0, 30, 0, time.UTC)
+	if n := m.FireDue(); n != 1 {
+		t.Fatalf("fired %d, want 1", n)
+	}
+	s, _ = m.Get(s.ID)
+	if s.LastBeadID != "bd-1" || !s.NextFireAt.Equal(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("after first fire: %+v", s)
+	}
+
+	// Second night: bd-1 is still open, so nothing new is created.
+	clock = time.Date(2026, 10, 18, 0, 1, 0, 0, time.UTC)
+	if n := m.FireDue(); n != 0 {
+		t.Fatalf("fired %d while previous instance open, want 0", n)
+	}
+	s, _ = m.Get(s.ID)
+	if !s.NextFireAt.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("skipped firing should still advance, next = %v", s.NextFireAt)
+	}
+
+	// Third night: bd-1 closed, so a fresh instance is created.
+	beads.beads["bd-1"].Status = models.BeadStatusClosed
+	clock = time.Date(2026, 10, 19, 0, 0, 5, 0, time.UTC)
+	if n := m.FireDue(); n != 1 {
+		t.Fatalf("fired %d after previous closed, want 1", n)
+	}
+	s, _ = m.Get(s.ID)
+	if s.LastBeadID != "bd-2" {
+		t.Errorf("LastBeadID = %q, want bd-2", s.LastBeadID)
+	}
+
+	if err := m.Delete(s.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := m.Get(s.ID); err == nil {
+		t.Error("expected ErrNotFound after delete")
+	}
+}
@@ -0,0 +1,600 @@
+// Package sla holds per-project service-level policies for beads: how soon
+// a bead of a given priority must be started and closed. The maintenance
+// loop calls Check to record breaches, publish events, and optionally
+// escalate breached beads to the CEO.
+package sla
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// ErrNotFound is returned when a policy ID does not exist.
+var ErrNotFound = errors.New("SLA policy not found")
+
+// Breach kinds.
+const (
+	KindResponse   = "response"   // not moved to in_progress in time
+	KindResolution = "resolution" // not closed in time
+)
+
+// Bead SLA states, in order of urgency.
+const (
+	StateBreached = "breached"
+	StateAtRisk   = "at_risk"
+	StateOnTrack  = "on_track"
+	StateMet      = "met"
+)
+
+// atRiskFraction is the share of a window left below which a bead is at risk.
+const atRiskFraction = 0.2
+
+// recentlyClosed bounds how long after closing a bead is still checked, so
+// that one closed late between two maintenance ticks is still recorded.
+const recentlyClosed = time.Hour
+
+// reportBreachWindow is how far back a report lists recorded breaches.
+const reportBreachWindow = 7 * 24 * time.Hour
+
+// BeadSource supplies the beads policies are evaluated against.
+// *beads.Manager satisfies it.
+type BeadSource interface {
+	ListBeads(filters map[string]interface{}) ([]*models.Bead, error)
+}
+
+// Escalator raises a breached bead to the CEO. *loom.Loom satisfies it.
+type Escalator interface {
+	EscalateBeadToCEO(beadID, reason, returnedTo string) (*models.DecisionBead, error)
+}
+
+// Policy sets deadlines for beads of one priority, measured from creation.
+// An empty ProjectID applies to every project without its own policy for
+// that priority. Either deadline may be empty.
+type Policy struct {
+	ID            string    `json:"id"`
+	ProjectID     string    `json:"project_id,omitempty"`
+	Priority      int       `json:"priority"`
+	RespondWithin string    `json:"respond_within,omitempty"`
+	ResolveWithin string    `json:"resolve_within,omitempty"`
+	AutoEscalate  bool      `json:"auto_escalate"`
+	CreatedBy     string    `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SetRequest is the body accepted when setting a policy. Durations use Go
+// syntax, e.g. "30m" or "24h".
+type SetRequest struct {
+	ProjectID     string `json:"project_id"`
+	Priority      *int   `json:"priority"`
+	RespondWithin string `json:"respond_within"`
+	ResolveWithin string `json:"resolve_within"`
+	AutoEscalate  bool   `json:"auto_escalate"`
+}
+
+// BeadStatus is a bead's standing against the policy that applies to it.
+// Remaining times are negative once a deadline has passed and are omitted
+// once it has been met.
+type BeadStatus struct {
+	PolicyID                string     `json:"policy_id"`
+	State                   string     `json:"state"`
+	RespondBy               *time.Time `json:"respond_by,omitempty"`
+	RespondedAt             *time.Time `json:"responded_at,omitempty"`
+	RespondRemainingSeconds *int64     `json:"respond_remaining_seconds,omitempty"`
+	ResponseBreached        bool       `json:"response_breached,omitempty"`
+	ResolveBy               *time.Time `json:"resolve_by,omitempty"`
+	ResolveRemainingSeconds *int64     `json:"resolve_remaining_seconds,omitempty"`
+	ResolutionBreached      bool       `json:"resolution_breached,omitempty"`
+}
+
+// Breach is a recorded missed deadline.
+type Breach struct {
+	BeadID       string    `json:"bead_id"`
+	Kind         string    `json:"kind"`
+	PolicyID     string    `json:"policy_id"`
+	ProjectID    string    `json:"project_id,omitempty"`
+	DueAt        time.Time `json:"due_at"`
+	BreachedAt   time.Time `json:"breached_at"`
+	EscalationID string    `json:"escalation_id,omitempty"`
+}
+
+// ReportEntry is one open bead in a report.
+type ReportEntry struct {
+	BeadID     string      `json:"bead_id"`
+	Title      string      `json:"title"`
+	ProjectID  string      `json:"project_id"`
+	Priority   int         `json:"priority"`
+	Status     string      `json:"status"`
+	AssignedTo string      `json:"assigned_to,omitempty"`
+	SLA        *BeadStatus `json:"sla"`
+}
+
+// ReportSummary counts open beads by SLA state.
+type ReportSummary struct {
+	Tracked  int `json:"tracked"`
+	Breached int `json:"breached"`
+	AtRisk   int `json:"at_risk"`
+	OnTrack  int `json:"on_track"`
+}
+
+// Report lists open beads covered by a policy, most urgent first, and the
+// breaches recorded over the past week.
+type Report struct {
+	ProjectID      string         `json:"project_id,omitempty"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+	Summary        ReportSummary  `json:"summary"`
+	Beads          []*ReportEntry `json:"beads"`
+	RecentBreaches []*Breach      `json:"recent_breaches"`
+}
+
+// Manager persists SLA policies and evaluates beads against them.
+type Manager struct {
+	db        *database.Database
+	beads     BeadSource
+	escalator Escalator
+	eventBus  *eventbus.EventBus
+	now       func() time.Time
+}
+
+// NewManager creates an SLA manager. Returns nil when db or beads is nil.
+// escalator and eventBus are optional.
+func NewManager(db *database.Database, beads BeadSource, escalator Escalator, eventBus *eventbus.EventBus) *Manager {
+	if db == nil || beads == nil {
+		return nil
+	}
+	return &Manager{
+		db:        db,
+		beads:     beads,
+		escalator: escalator,
+		eventBus:  eventBus,
+		now:       time.Now,
+	}
+}
+
+// Set creates the policy for a project and priority, or replaces the
+// deadlines of the existing one.
+func (m *Manager) Set(req SetRequest, createdBy string) (*Policy, error) {
+	if req.Priority == nil {
+		return nil, fmt.Errorf("priority is required")
+	}
+	priority := *req.Priority
+	if priority < int(models.BeadPriorityP0) || priority > int(models.BeadPriorityP3) {
+		return nil, fmt.Errorf("priority must be between 0 and 3")
+	}
+	respond, err := parseWithin("respond_within", req.RespondWithin)
+	if err != nil {
+		return nil, err
+	}
+	resolve, err := parseWithin("resolve_within", req.ResolveWithin)
+	if err != nil {
+		return nil, err
+	}
+	if respond == 0 && resolve == 0 {
+		return nil, fmt.Errorf("respond_within or resolve_within is required")
+	}
+	if respond > 0 && resolve > 0 && resolve < respond {
+		return nil, fmt.Errorf("resolve_within must not be shorter than respond_within")
+	}
+	projectID := strings.TrimSpace(req.ProjectID)
+
+	now := m.now().UTC()
+	existing, err := m.db.FindSLAPolicy(projectID, priority)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		existing.RespondSeconds = int64(respond / time.Second)
+		existing.ResolveSeconds = int64(resolve / time.Second)
+		existing.AutoEscalate = req.AutoEscalate
+		existing.UpdatedAt = now
+		if err := m.db.UpdateSLAPolicy(existing); err != nil {
+			return nil, err
+		}
+		return toPolicy(existing), nil
+	}
+
+	p := &database.SLAPolicy{
+		ID:             "sla-" + uuid.New().String()[:8],
+		ProjectID:      projectID,
+		Priority:       priority,
+		RespondSeconds: int64(respond / time.Second),
+		ResolveSeconds: int64(resolve / time.Second),
+		AutoEscalate:   req.AutoEscalate,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := m.db.CreateSLAPolicy(p); err != nil {
+		return nil, err
+	}
+	return toPolicy(p), nil
+}
+
+// List returns every policy.
+func (m *Manager) List() ([]*Policy, error) {
+	rows, err := m.db.ListSLAPolicies()
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*Policy, 0, len(rows))
+	for _, p := range rows {
+		policies = append(policies, toPolicy(p))
+	}
+	return policies, nil
+}
+
+// Delete removes a policy.
+func (m *Manager) Delete(id string) error {
+	p, err := m.db.GetSLAPolicy(id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return m.db.DeleteSLAPolicy(id)
+}
+
+// Status returns a bead's SLA standing, or nil if no policy covers it.
+func (m *Manager) Status(b *models.Bead) (*BeadStatus, error) {
+	statuses, err := m.Statuses([]*models.Bead{b})
+	if err != nil {
+		return nil, err
+	}
+	return statuses[b.ID], nil
+}
+
+// Statuses returns the SLA standing of each bead covered by a policy, keyed
+// by bead ID.
+func (m *Manager) Statuses(beads []*models.Bead) (map[string]*BeadStatus, error) {
+	ps, err := m.loadPolicies()
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	out := make(map[string]*BeadStatus)
+	for _, b := range beads {
+		if p := ps.lookup(b); p != nil {
+			out[b.ID] = evaluate(p, b, now)
+		}
+	}
+	return out, nil
+}
+
+// Check evaluates open and recently closed beads, records breaches not seen
+// before, publishes a bead.sla_breach event for each, and escalates the bead
+// to the CEO when its policy asks for it. It returns the number of new
+// breaches.
+func (m *Manager) Check() (int, error) {
+	if m == nil {
+		return 0, nil
+	}
+	ps, err := m.loadPolicies()
+	if err != nil || len(ps) == 0 {
+		return 0, err
+	}
+	beads, err := m.beads.ListBeads(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	now := m.now()
+	recorded := 0
+	for _, b := range beads {
+		if b.Status == models.BeadStatusClosed && (b.ClosedAt == nil || now.Sub(*b.ClosedAt) > recentlyClosed) {
+			continue
+		}
+		p := ps.lookup(b)
+		if p == nil {
+			continue
+		}
+		st := evaluate(p, b, now)
+		if st.ResponseBreached && m.recordBreach(p, b, KindResponse, *st.RespondBy, now) {
+			recorded++
+		}
+		if st.ResolutionBreached && m.recordBreach(p, b, KindResolution, *st.ResolveBy, now) {
+			recorded++
+		}
+	}
+	return recorded, nil
+}
+
+// recordBreach stores a breach and reports whether it was new.
+func (m *Manager) recordBreach(p *database.SLAPolicy, b *models.Bead, kind string, due, now time.Time) bool {
+	breach := &database.SLABreach{
+		BeadID:     b.ID,
+		Kind:       kind,
+		PolicyID:   p.ID,
+		ProjectID:  b.ProjectID,
+		DueAt:      due.UTC(),
+		BreachedAt: now.UTC(),
+	}
+	created, err := m.db.CreateSLABreach(breach)
+	if err != nil {
+		log.Printf("[SLA] Failed to record %s breach for %s: %v", kind, b.ID, err)
+		return false
+	}
+	if !created {
+		return false
+	}
+
+	reason := breachReason(p, b, kind)
+	log.Printf("[SLA] %s", reason)
+
+	if m.eventBus != nil {
+		_ = m.eventBus.PublishBeadEvent(eventbus.EventTypeBeadSLABreach, b.ID, b.ProjectID, map[string]interface{}{
+			"kind":      kind,
+			"policy_id": p.ID,
+			"priority":  int(b.Priority),
+			"due_at":    due.UTC().Format(time.RFC3339),
+			"title":     b.Title,
+			"reason":    reason,
+		})
+	}
+
+	if p.AutoEscalate && m.escalator != nil && b.Status != models.BeadStatusClosed && b.Context["escalated_to_ceo_decision_id"] == "" {
+		decision, err := m.escalator.EscalateBeadToCEO(b.ID, reason, "")
+		if err != nil {
+			log.Printf("[SLA] Failed to escalate %s: %v", b.ID, err)
+		} else if decision != nil {
+			if err := m.db.SetSLABreachEscalation(b.ID, kind, decision.ID); err != nil {
+				log.Printf("[SLA] %v", err)
+			}
+		}
+	}
+	return true
+}
+
+// Report builds an SLA report, optionally limited to one project.
+func (m *Manager) Report(projectID string) (*Report, error) {
+	ps, err := m.loadPolicies()
+	if err != nil {
+		return nil, err
+	}
+	filters := map[string]interface{}{}
+	if projectID != "" {
+		filters["project_id"] = projectID
+	}
+	beads, err := m.beads.ListBeads(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	report := &Report{
+		ProjectID:      projectID,
+		GeneratedAt:    now.UTC(),
+		Beads:          []*ReportEntry{},
+		RecentBreaches: []*Breach{},
+	}
+	for _, b := range beads {
+		if b.Status == models.BeadStatusClosed {
+			continue
+		}
+		p := ps.lookup(b)
+		if p == nil {
+			continue
+		}
+		st := evaluate(p, b, now)
+		report.Beads = append(report.Beads, &ReportEntry{
+			BeadID:     b.ID,
+			Title:      b.Title,
+			ProjectID:  b.ProjectID,
+			Priority:   int(b.Priority),
+			Status:     string(b.Status),
+			AssignedTo: b.AssignedTo,
+			SLA:        st,
+		})
+		report.Summary.Tracked++
+		switch st.State {
+		case StateBreached:
+			report.Summary.Breached++
+		case StateAtRisk:
+			report.Summary.AtRisk++
+		default:
+			report.Summary.OnTrack++
+		}
+	}
+	sort.SliceStable(report.Beads, func(i, j int) bool {
+		a, b := report.Beads[i].SLA, report.Beads[j].SLA
+		if ra, rb := stateRank(a.State), stateRank(b.State); ra != rb {
+			return ra < rb
+		}
+		return nextDue(a) < nextDue(b)
+	})
+
+	breaches, err := m.db.ListSLABreaches(projectID, now.Add(-reportBreachWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, br := range breaches {
+		report.RecentBreaches = append(report.RecentBreaches, &Breach{
+			BeadID:       br.BeadID,
+			Kind:         br.Kind,
+			PolicyID:     br.PolicyID,
+			ProjectID:    br.ProjectID,
+			DueAt:        br.DueAt,
+			BreachedAt:   br.BreachedAt,
+			EscalationID: br.EscalationID,
+		})
+	}
+	return report, nil
+}
+
+// policySet indexes policies by project and priority.
+type policySet map[string]map[int]*database.SLAPolicy
+
+func (m *Manager) loadPolicies() (policySet, error) {
+	rows, err := m.db.ListSLAPolicies()
+	if err != nil {
+		return nil, err
+	}
+	ps := make(policySet)
+	for _, p := range rows {
+		if ps[p.ProjectID] == nil {
+			ps[p.ProjectID] = make(map[int]*database.SLAPolicy)
+		}
+		ps[p.ProjectID][p.Priority] = p
+	}
+	return ps, nil
+}
+
+// lookup returns the policy covering a bead: the project's own policy for
+// its priority, else the global one. Decision beads are never covered.
+func (ps policySet) lookup(b *models.Bead) *database.SLAPolicy {
+	if b == nil || b.Type == "decision" {
+		return nil
+	}
+	if p := ps[b.ProjectID][int(b.Priority)]; p != nil {
+		return p
+	}
+	return ps[""][int(b.Priority)]
+}
+
+// evaluate measures a bead against a policy. A bead has responded once it
+// is started; beads loaded without a start time count as responded once they
+// leave open, at an unknown time that is never treated as late.
+func evaluate(p *database.SLAPolicy, b *models.Bead, now time.Time) *BeadStatus {
+	st := &BeadStatus{PolicyID: p.ID}
+	atRisk := false
+	pending := false
+
+	if p.RespondSeconds > 0 {
+		window := time.Duration(p.RespondSeconds) * time.Second
+		due := b.CreatedAt.Add(window)
+		st.RespondBy = &due
+		respondedAt := b.StartedAt
+		if respondedAt == nil && b.Status == models.BeadStatusClosed {
+			respondedAt = b.ClosedAt
+		}
+		switch {
+		case respondedAt != nil:
+			st.RespondedAt = respondedAt
+			st.ResponseBreached = respondedAt.After(due)
+		case b.Status != models.BeadStatusOpen:
+			// Started, but when is unknown.
+		default:
+			pending = true
+			remaining := due.Sub(now)
+			secs := int64(remaining / time.Second)
+			st.RespondRemainingSeconds = &secs
+			st.ResponseBreached = remaining < 0
+			atRisk = atRisk || remaining < time.Duration(float64(window)*atRiskFraction)
+		}
+	}
+
+	if p.ResolveSeconds > 0 {
+		window := time.Duration(p.ResolveSeconds) * time.Second
+		due := b.CreatedAt.Add(window)
+		st.ResolveBy = &due
+		if b.Status == models.BeadStatusClosed {
+			st.ResolutionBreached = b.ClosedAt != nil && b.ClosedAt.After(due)
+		} else {
+			pending = true
+			remaining := due.Sub(now)
+			secs := int64(remaining / time.Second)
+			st.ResolveRemainingSeconds = &secs
+			st.ResolutionBreached = remaining < 0
+			atRisk = atRisk || remaining < time.Duration(float64(window)*atRiskFraction)
+		}
+	}
+
+	switch {
+	case st.ResponseBreached || st.ResolutionBreached:
+		st.State = StateBreached
+	case !pending:
+		st.State = StateMet
+	case atRisk:
+		st.State = StateAtRisk
+	default:
+		st.State = StateOnTrack
+	}
+	return st
+}
+
+func stateRank(state string) int {
+	switch state {
+	case StateBreached:
+		return 0
+	case StateAtRisk:
+		return 1
+	case StateOnTrack:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// nextDue returns the seconds until a status's nearest pending deadline.
+func nextDue(st *BeadStatus) int64 {
+	next := int64(1<<62 - 1)
+	for _, r := range []*int64{st.RespondRemainingSeconds, st.ResolveRemainingSeconds} {
+		if r != nil && *r < next {
+			next = *r
+		}
+	}
+	return next
+}
+
+func breachReason(p *database.SLAPolicy, b *models.Bead, kind string) string {
+	if kind == KindResponse {
+		return fmt.Sprintf("SLA breach: P%d bead %s was not started within %s", b.Priority, b.ID,
+			formatWithin(time.Duration(p.RespondSeconds)*time.Second))
+	}
+	return fmt.Sprintf("SLA breach: P%d bead %s was not closed within %s", b.Priority, b.ID,
+		formatWithin(time.Duration(p.ResolveSeconds)*time.Second))
+}
+
+func parseWithin(field, s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("%s must be at least 1m", field)
+	}
+	return d, nil
+}
+
+// formatWithin renders a duration without trailing zero units, e.g. "24h"
+// rather than "24h0m0s".
+func formatWithin(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func toPolicy(p *database.SLAPolicy) *Policy {
+	return &Policy{
+		ID:            p.ID,
+		ProjectID:     p.ProjectID,
+		Priority:      p.Priority,
+		RespondWithin: formatWithin(time.Duration(p.RespondSeconds) * time.Second),
+		ResolveWithin: formatWithin(time.Duration(p.ResolveSeconds) * time.Second),
+		AutoEscalate:  p.AutoEscalate,
+		CreatedBy:     p.CreatedBy,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
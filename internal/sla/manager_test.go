@@ -0,0 +1,180 @@
+package sla
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+type fakeBeads struct {
+	beads []*models.Bead
+}
+
+func (f *fakeBeads) ListBeads(filters map[string]interface{}) ([]*models.Bead, error) {
+	var out []*models.Bead
+	for _, b := range f.beads {
+		if pid, ok := filters["project_id"].(string); ok && b.ProjectID != pid {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+type fakeEscalator struct {
+	escalated []string
+}
+
+func (f *fakeEscalator) EscalateBeadToCEO(beadID, reason, returnedTo string) (*models.DecisionBead, error) {
+	f.escalated = append(f.escalated, beadID)
+	return &models.DecisionBead{Bead: &models.Bead{ID: "dec-" + beadID}}, nil
+}
+
+func newTestManager(t *testing.T, beads *fakeBeads, esc *fakeEscalator, clock *time.Time) *Manager {
+	t.Helper()
+	db := dbtest.New(t)
+	m := NewManager(db, beads, esc, nil)
+	m.now = func() time.Time { return *clock }
+	return m
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestManager_SetValidatesAndUpserts(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(t, &fakeBeads{}, nil, &clock)
+
+	bad := []SetRequest{
+		{RespondWithin: "30m"},
+		{Priority: intPtr(4), RespondWithin: "30m"},
+		{Priority: intPtr(0)},
+		{Priority: intPtr(0), RespondWithin: "soon"},
+		{Priority: intPtr(0), RespondWithin: "10s"},
+		{Priority: intPtr(0), RespondWithin: "2h", ResolveWithin: "1h"},
+	}
+	for _, req := range bad {
+		if _, err := m.Set(req, "admin"); err == nil {
+			t.Errorf("Set(%+v): expected error", req)
+		}
+	}
+
+	p, err := m.Set(SetRequest{Priority: intPtr(0), RespondWithin: "30m", ResolveWithin: "24h"}, "admin")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if p.RespondWithin != "30m" || p.ResolveWithin != "24h" || p.ProjectID != "" {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+
+	updated, err := m.Set(SetRequest{Priority: intPtr(0), ResolveWithin: "1h30m", AutoEscalate: true}, "admin")
+	if err != nil {
+		t.Fatalf("Set update failed: %v", err)
+	}
+	if updated.ID != p.ID || updated.RespondWithin != "" || updated.ResolveWithin != "1h30m" || !updated.AutoEscalate {
+		t.Fatalf("expected in-place update, got %+v", updated)
+	}
+
+	list, _ := m.List()
+	if len(list) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(list))
+	}
+	if err := m.Delete(p.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := m.Delete(p.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManager_StatusStates(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	started := clock.Add(-20 * time.Minute)
+	beads := &fakeBeads{beads: []*models.Bead{
+		{ID: "fresh", ProjectID: "loom", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-5 * time.Minute)},
+		{ID: "close-call", ProjectID: "loom", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-28 * time.Minute)},
+		{ID: "late", ProjectID: "loom", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Hour)},
+		{ID: "started", ProjectID: "loom", Priority: 0, Status: models.BeadStatusInProgress, CreatedAt: clock.Add(-time.Hour), StartedAt: &started},
+		{ID: "other-project", ProjectID: "web", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Hour)},
+		{ID: "uncovered", ProjectID: "loom", Priority: 3, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Hour)},
+		{ID: "decision", ProjectID: "loom", Priority: 0, Type: "decision", Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Hour)},
+	}}
+	m := newTestManager(t, beads, nil, &clock)
+
+	if _, err := m.Set(SetRequest{Priority: intPtr(0), RespondWithin: "2h"}, "admin"); err != nil {
+		t.Fatalf("Set global failed: %v", err)
+	}
+	if _, err := m.Set(SetRequest{ProjectID: "loom", Priority: intPtr(0), RespondWithin: "30m", ResolveWithin: "24h"}, "admin"); err != nil {
+		t.Fatalf("Set project failed: %v", err)
+	}
+
+	statuses, err := m.Statuses(beads.beads)
+	if err != nil {
+		t.Fatalf("Statuses failed: %v", err)
+	}
+	want := map[string]string{
+		"fresh":         StateOnTrack,
+		"close-call":    StateAtRisk,
+		"late":          StateBreached,
+		"started":       StateBreached, // started 20m late
+		"other-project": StateOnTrack,  // global 2h policy
+	}
+	for id, state := range want {
+		if st := statuses[id]; st == nil || st.State != state {
+			t.Errorf("%s: expected %s, got %+v", id, state, st)
+		}
+	}
+	for _, id := range []string{"uncovered", "decision"} {
+		if st := statuses[id]; st != nil {
+			t.Errorf("%s: expected no SLA, got %+v", id, st)
+		}
+	}
+	if st := statuses["late"]; st.RespondRemainingSeconds == nil || *st.RespondRemainingSeconds != -30*60 {
+		t.Errorf("late: unexpected remaining %+v", st)
+	}
+	if st := statuses["started"]; st.RespondRemainingSeconds != nil || st.ResolveRemainingSeconds == nil {
+		t.Errorf("started: expected only resolve remaining, got %+v", st)
+	}
+}
+
+func TestManager_CheckRecordsAndEscalatesOnce(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	beads := &fakeBeads{beads: []*models.Bead{
+		{ID: "late", ProjectID: "loom", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Hour)},
+		{ID: "ok", ProjectID: "loom", Priority: 0, Status: models.BeadStatusOpen, CreatedAt: clock.Add(-time.Minute)},
+	}}
+	esc := &fakeEscalator{}
+	m := newTestManager(t, beads, esc, &clock)
+	if _, err := m.Set(SetRequest{Priority: intPtr(0), RespondWithin: "30m", AutoEscalate: true}, "admin"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	n, err := m.Check()
+	if err != nil || n != 1 {
+		t.Fatalf("Check = %d, %v; want 1 breach", n, err)
+	}
+	if len(esc.escalated) != 1 || esc.escalated[0] != "late" {
+		t.Fatalf("expected late to be escalated, got %v", esc.escalated)
+	}
+
+	clock = clock.Add(time.Minute)
+	if n, _ := m.Check(); n != 0 {
+		t.Fatalf("second Check recorded %d breaches, want 0", n)
+	}
+	if len(esc.escalated) != 1 {
+		t.Fatalf("escalated again: %v", esc.escalated)
+	}
+
+	report, err := m.Report("loom")
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.Summary.Tracked != 2 || report.Summary.Breached != 1 || report.Beads[0].BeadID != "late" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.RecentBreaches) != 1 || report.RecentBreaches[0].EscalationID != "dec-late" {
+		t.Fatalf("unexpected breaches: %+v", report.RecentBreaches)
+	}
+}
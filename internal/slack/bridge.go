@@ -0,0 +1,206 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+// Bridge subscribes to the EventBus and posts decision beads and CEO
+// escalations to Slack with approve/deny buttons. When a decision is
+// resolved (from Slack or anywhere else) the original message is updated
+// and its buttons removed.
+type Bridge struct {
+	client     *Client
+	eventBus   *eventbus.EventBus
+	subscriber *eventbus.Subscriber
+	cancel     context.CancelFunc
+	done       chan struct{}
+
+	mu       sync.Mutex
+	messages map[string]postedMessage // decision ID -> posted message
+}
+
+type postedMessage struct {
+	channel string
+	ts      string
+	text    string
+}
+
+// NewBridge creates a new Slack bridge. Returns nil if the client is nil
+// (integration disabled) or the event bus is nil.
+func NewBridge(client *Client, eb *eventbus.EventBus) *Bridge {
+	if client == nil || eb == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &Bridge{
+		client:   client,
+		eventBus: eb,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		messages: make(map[string]postedMessage),
+	}
+
+	b.subscriber = eb.Subscribe("slack-bridge", func(e *eventbus.Event) bool {
+		return e.Type == eventbus.EventTypeDecisionCreated ||
+			e.Type == eventbus.EventTypeDecisionResolved
+	})
+
+	go func() {
+		defer close(b.done)
+		b.run(ctx)
+	}()
+	return b
+}
+
+// Close unsubscribes from the event bus and stops the bridge goroutine.
+// Blocks until the goroutine has exited. Safe to call multiple times.
+func (b *Bridge) Close() {
+	if b == nil {
+		return
+	}
+	b.cancel()
+	if b.eventBus != nil {
+		b.eventBus.Unsubscribe("slack-bridge")
+	}
+	<-b.done
+}
+
+// run processes events from the subscription channel.
+func (b *Bridge) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-b.subscriber.Channel:
+			if !ok {
+				return
+			}
+			b.handleEvent(ctx, event)
+		}
+	}
+}
+
+func (b *Bridge) handleEvent(ctx context.Context, event *eventbus.Event) {
+	if event == nil {
+		return
+	}
+	data := event.Data
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	decisionID, _ := data["decision_id"].(string)
+	if decisionID == "" {
+		return
+	}
+
+	switch event.Type {
+	case eventbus.EventTypeDecisionCreated:
+		msg := formatDecision(event, decisionID)
+		channel, ts, err := b.client.PostMessage(ctx, msg)
+		if err != nil {
+			log.Printf("[Slack] Failed to post decision %s: %v", decisionID, err)
+			return
+		}
+		b.mu.Lock()
+		b.messages[decisionID] = postedMessage{channel: channel, ts: ts, text: msg.Text}
+		b.mu.Unlock()
+		log.Printf("[Slack] Posted decision %s to %s", decisionID, channel)
+
+	case eventbus.EventTypeDecisionResolved:
+		b.mu.Lock()
+		posted, ok := b.messages[decisionID]
+		delete(b.messages, decisionID)
+		b.mu.Unlock()
+		if !ok {
+			return
+		}
+		decision, _ := data["decision"].(string)
+		decider, _ := data["decider_id"].(string)
+		outcome := fmt.Sprintf("Decided *%s* by %s", decision, strings.TrimPrefix(decider, "user-slack-"))
+		err := b.client.UpdateMessage(ctx, &Message{
+			Channel: posted.channel,
+			TS:      posted.ts,
+			Text:    posted.text + "\n" + outcome,
+			Blocks: []Block{
+				sectionBlock(posted.text),
+				contextBlock(outcome),
+			},
+		})
+		if err != nil {
+			log.Printf("[Slack] Failed to update decision %s: %v", decisionID, err)
+		}
+	}
+}
+
+// formatDecision builds the interactive message for a new decision bead.
+func formatDecision(event *eventbus.Event, decisionID string) *Message {
+	data := event.Data
+	question, _ := data["question"].(string)
+	reason, _ := data["reason"].(string)
+	beadID, _ := data["bead_id"].(string)
+
+	var sb strings.Builder
+	if event.Source == "ceo-escalation" {
+		sb.WriteString(":rotating_light: *CEO escalation*")
+	} else {
+		sb.WriteString(":ballot_box_with_check: *Decision required*")
+	}
+	if p, ok := data["priority"]; ok {
+		fmt.Fprintf(&sb, " (P%v)", p)
+	}
+	sb.WriteString("\n")
+	if event.ProjectID != "" {
+		fmt.Fprintf(&sb, "*Project:* %s\n", event.ProjectID)
+	}
+	if beadID != "" {
+		fmt.Fprintf(&sb, "*Bead:* %s\n", beadID)
+	}
+	if question != "" {
+		fmt.Fprintf(&sb, "*Question:* %s\n", question)
+	} else if reason != "" {
+		fmt.Fprintf(&sb, "*Reason:* %s\n", reason)
+	}
+	fmt.Fprintf(&sb, "*Decision:* %s", decisionID)
+	text := sb.String()
+
+	return &Message{
+		Text: text,
+		Blocks: []Block{
+			sectionBlock(text),
+			{
+				"type":     "actions",
+				"block_id": "loom_decision:" + decisionID,
+				"elements": []Block{
+					button(ActionApprove, "Approve", "primary", decisionID),
+					button(ActionDeny, "Deny", "danger", decisionID),
+				},
+			},
+		},
+	}
+}
+
+func sectionBlock(text string) Block {
+	return Block{"type": "section", "text": Block{"type": "mrkdwn", "text": text}}
+}
+
+func contextBlock(text string) Block {
+	return Block{"type": "context", "elements": []Block{{"type": "mrkdwn", "text": text}}}
+}
+
+func button(actionID, label, style, value string) Block {
+	return Block{
+		"type":      "button",
+		"action_id": actionID,
+		"text":      Block{"type": "plain_text", "text": label},
+		"style":     style,
+		"value":     value,
+	}
+}
@@ -0,0 +1,126 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/eventbus"
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+type apiCall struct {
+	method string
+	msg    Message
+}
+
+func newTestSlack(t *testing.T) (*Client, chan apiCall) {
+	t.Helper()
+	calls := make(chan apiCall, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			json.NewEncoder(w).Encode(apiResponse{OK: false, Error: "invalid_auth"})
+			return
+		}
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		calls <- apiCall{method: strings.TrimPrefix(r.URL.Path, "/"), msg: msg}
+		json.NewEncoder(w).Encode(apiResponse{OK: true, Channel: "C123", TS: "1760616000.000100"})
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewClient(&config.SlackConfig{
+		Enabled:  true,
+		BotToken: "xoxb-test",
+		Channel:  "#loom-decisions",
+		APIURL:   srv.URL,
+	}), calls
+}
+
+func waitCall(t *testing.T, calls chan apiCall) apiCall {
+	t.Helper()
+	select {
+	case c := <-calls:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Slack API call")
+		return apiCall{}
+	}
+}
+
+func TestNewClient_Disabled(t *testing.T) {
+	if NewClient(&config.SlackConfig{}) != nil {
+		t.Error("expected nil client when disabled")
+	}
+	if NewClient(&config.SlackConfig{Enabled: true}) != nil {
+		t.Error("expected nil client without a bot token")
+	}
+	if NewBridge(nil, eventbus.NewEventBus()) != nil {
+		t.Error("expected nil bridge when client is nil")
+	}
+}
+
+func TestBridge_PostsAndResolvesDecision(t *testing.T) {
+	client, calls := newTestSlack(t)
+	eb := eventbus.NewEventBus()
+	defer eb.Close()
+
+	b := NewBridge(client, eb)
+	defer b.Close()
+
+	_ = eb.Publish(&eventbus.Event{
+		Type:      eventbus.EventTypeDecisionCreated,
+		Source:    "ceo-escalation",
+		ProjectID: "loom",
+		Data: map[string]interface{}{
+			"decision_id": "bd-dec-1",
+			"bead_id":     "loom-042",
+			"question":    "Ship the release?",
+			"priority":    0,
+		},
+	})
+
+	posted := waitCall(t, calls)
+	if posted.method != "chat.postMessage" {
+		t.Fatalf("method = %q, want chat.postMessage", posted.method)
+	}
+	if posted.msg.Channel != "#loom-decisions" {
+		t.Errorf("channel = %q", posted.msg.Channel)
+	}
+	if !strings.Contains(posted.msg.Text, "CEO escalation") || !strings.Contains(posted.msg.Text, "Ship the release?") {
+		t.Errorf("text = %q", posted.msg.Text)
+	}
+	raw, _ := json.Marshal(posted.msg.Blocks)
+	for _, want := range []string{ActionApprove, ActionDeny, `"value":"bd-dec-1"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("blocks missing %s: %s", want, raw)
+		}
+	}
+
+	_ = eb.Publish(&eventbus.Event{
+		Type: eventbus.EventTypeDecisionResolved,
+		Data: map[string]interface{}{
+			"decision_id": "bd-dec-1",
+			"decision":    "approve",
+			"decider_id":  "user-slack-U123",
+		},
+	})
+
+	updated := waitCall(t, calls)
+	if updated.method != "chat.update" {
+		t.Fatalf("method = %q, want chat.update", updated.method)
+	}
+	if updated.msg.Channel != "C123" || updated.msg.TS != "1760616000.000100" {
+		t.Errorf("update target = %s/%s", updated.msg.Channel, updated.msg.TS)
+	}
+	raw, _ = json.Marshal(updated.msg.Blocks)
+	if strings.Contains(string(raw), ActionApprove) {
+		t.Error("buttons should be removed once resolved")
+	}
+	if !strings.Contains(updated.msg.Text, "Decided *approve* by U123") {
+		t.Errorf("text = %q", updated.msg.Text)
+	}
+}
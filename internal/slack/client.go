@@ -0,0 +1,106 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jordanhubbard/loom/pkg/config"
+)
+
+// Client posts messages through the Slack Web API using a bot token.
+type Client struct {
+	apiURL     string
+	botToken   string
+	channel    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Slack client. Returns nil if the integration is not
+// enabled or has no bot token, allowing callers to treat a nil *Client as
+// "disabled".
+func NewClient(cfg *config.SlackConfig) *Client {
+	if cfg == nil || !cfg.Enabled || cfg.BotToken == "" {
+		return nil
+	}
+
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = "https://slack.com/api"
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Client{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		botToken:   cfg.BotToken,
+		channel:    cfg.Channel,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// PostMessage posts a message and returns the channel ID and timestamp that
+// identify it for later updates.
+func (c *Client) PostMessage(ctx context.Context, msg *Message) (channel, ts string, err error) {
+	if msg.Channel == "" {
+		msg.Channel = c.channel
+	}
+	resp, err := c.call(ctx, "chat.postMessage", msg)
+	if err != nil {
+		return "", "", err
+	}
+	return resp.Channel, resp.TS, nil
+}
+
+// UpdateMessage replaces the content of a previously posted message.
+func (c *Client) UpdateMessage(ctx context.Context, msg *Message) error {
+	_, err := c.call(ctx, "chat.update", msg)
+	return err
+}
+
+// call POSTs a JSON body to a Web API method. Slack reports most failures
+// with HTTP 200 and ok=false, so both are checked.
+func (c *Client) call(ctx context.Context, method string, payload interface{}) (*apiResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("slack: marshal %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("slack: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack: %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("slack: read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("slack: %s: unexpected status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+
+	var apiResp apiResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("slack: decode response: %w", err)
+	}
+	if !apiResp.OK {
+		return &apiResp, fmt.Errorf("slack: %s: %s", method, apiResp.Error)
+	}
+	return &apiResp, nil
+}
@@ -0,0 +1,51 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRequestAge bounds how old a signed request may be, to prevent replays.
+const maxRequestAge = 5 * time.Minute
+
+// VerifySignature checks a request against Slack's v0 signing scheme:
+// X-Slack-Signature must equal "v0=" + hex(HMAC-SHA256(secret,
+// "v0:<X-Slack-Request-Timestamp>:<body>")), and the timestamp must be
+// within five minutes of now.
+func VerifySignature(secret string, header http.Header, body []byte, now time.Time) error {
+	if secret == "" {
+		return errors.New("slack signing secret is not configured")
+	}
+
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	signature := header.Get("X-Slack-Signature")
+	if timestamp == "" || signature == "" {
+		return errors.New("missing Slack signature headers")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid Slack request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return errors.New("stale Slack request timestamp")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("invalid Slack signature")
+	}
+	return nil
+}
+
+// Sign computes the X-Slack-Signature value for a timestamp and body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
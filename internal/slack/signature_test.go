@@ -0,0 +1,43 @@
+package slack
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := "8f742231b10e8888abcd99yyyzzz85a5"
+	body := []byte("payload=%7B%22type%22%3A%22block_actions%22%7D")
+	now := time.Unix(1760616000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	header := func(ts, sig string) http.Header {
+		h := http.Header{}
+		h.Set("X-Slack-Request-Timestamp", ts)
+		h.Set("X-Slack-Signature", sig)
+		return h
+	}
+
+	if err := VerifySignature(secret, header(ts, Sign(secret, ts, body)), body, now); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := VerifySignature(secret, header(ts, Sign("other", ts, body)), body, now); err == nil {
+		t.Error("expected error for wrong secret")
+	}
+	if err := VerifySignature(secret, header(ts, Sign(secret, ts, body)), append(body, 'x'), now); err == nil {
+		t.Error("expected error for tampered body")
+	}
+
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	if err := VerifySignature(secret, header(stale, Sign(secret, stale, body)), body, now); err == nil {
+		t.Error("expected error for stale timestamp")
+	}
+	if err := VerifySignature(secret, http.Header{}, body, now); err == nil {
+		t.Error("expected error for missing headers")
+	}
+	if err := VerifySignature("", header(ts, Sign("", ts, body)), body, now); err == nil {
+		t.Error("expected error when no secret is configured")
+	}
+}
@@ -0,0 +1,49 @@
+package slack
+
+// Action IDs attached to the interactive buttons on decision messages. The
+// button value carries the decision ID.
+const (
+	ActionApprove = "loom_decision_approve"
+	ActionDeny    = "loom_decision_deny"
+)
+
+// Block is a Slack Block Kit block. Blocks are built as plain maps since loom
+// only needs a handful of section, context, and actions blocks.
+type Block map[string]interface{}
+
+// Message is the payload for chat.postMessage and chat.update.
+type Message struct {
+	Channel string  `json:"channel"`
+	TS      string  `json:"ts,omitempty"` // set for chat.update
+	Text    string  `json:"text"`         // fallback for notifications
+	Blocks  []Block `json:"blocks,omitempty"`
+}
+
+// apiResponse is the common envelope returned by Slack Web API methods.
+type apiResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	TS      string `json:"ts,omitempty"`
+}
+
+// InteractionPayload is the JSON carried in the "payload" form field that
+// Slack POSTs when a user clicks a button.
+type InteractionPayload struct {
+	Type string `json:"type"` // "block_actions"
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS string `json:"ts"`
+	} `json:"message"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
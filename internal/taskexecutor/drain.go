@@ -0,0 +1,141 @@
+package taskexecutor
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/worker"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+const (
+	// DefaultShutdownGracePeriod is how long Drain waits for beads in
+	// flight before interrupting them, unless configured otherwise.
+	DefaultShutdownGracePeriod = 30 * time.Second
+	// checkpointTimeout bounds how long Drain waits for interrupted beads
+	// to be checkpointed.
+	checkpointTimeout = 10 * time.Second
+)
+
+// Bead context keys marking a bead interrupted by a shutdown. The next run
+// of the bead resumes from its saved conversation and clears them.
+const (
+	InterruptedAtKey         = "interrupted_at"
+	InterruptedIterationsKey = "interrupted_iterations"
+)
+
+// Drain prepares the executor for shutdown. Workers stop claiming beads
+// and the beads in flight get up to grace to finish. Those still running
+// after that, or once ctx is done, are interrupted: their conversation is
+// saved and they are reopened marked to resume on restart. Drain returns
+// the IDs of the interrupted beads; the executor can't be started again.
+func (e *Executor) Drain(ctx context.Context, grace time.Duration) []string {
+	e.mu.Lock()
+	e.draining = true
+	inFlight := len(e.running)
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.inFlight.Wait()
+		close(done)
+	}()
+
+	logger().Info("draining", "beads_in_flight", inFlight, "grace", grace)
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+		logger().Info("drained")
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	e.mu.Lock()
+	var interrupted []string
+	for id := range e.running {
+		e.interrupted[id] = true
+		interrupted = append(interrupted, id)
+	}
+	for _, state := range e.projectStates {
+		if state.cancel != nil {
+			state.cancel()
+		}
+	}
+	e.mu.Unlock()
+	sort.Strings(interrupted)
+	logger().Warn("interrupting beads still in flight", "bead_ids", interrupted)
+
+	select {
+	case <-done:
+	case <-time.After(checkpointTimeout):
+		logger().Error("timed out checkpointing interrupted beads")
+	}
+	return interrupted
+}
+
+// isDraining reports whether Drain was called.
+func (e *Executor) isDraining() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.draining
+}
+
+// beginBead counts a claimed bead as in flight, unless the executor is
+// draining.
+func (e *Executor) beginBead() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.draining {
+		return false
+	}
+	e.inFlight.Add(1)
+	return true
+}
+
+// wasInterrupted reports whether Drain interrupted beadID.
+func (e *Executor) wasInterrupted(beadID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.interrupted[beadID]
+}
+
+// checkpointBead records how far an interrupted bead got and reopens it
+// to resume from its saved conversation. Unlike a failed run, it counts
+// as neither a dispatch nor an error.
+func (e *Executor) checkpointBead(bead *models.Bead, agentID string, result *worker.LoopResult) {
+	iterations := 0
+	ctxUpdate := map[string]string{
+		InterruptedAtKey: time.Now().UTC().Format(time.RFC3339),
+	}
+	if result != nil {
+		iterations = result.Iterations
+		fresh, err := e.beadManager.GetBead(bead.ID)
+		if err != nil || fresh == nil {
+			fresh = bead
+		}
+		probe := &models.Bead{ID: fresh.ID, ProjectID: fresh.ProjectID, Context: make(map[string]string, len(fresh.Context))}
+		for k, v := range fresh.Context {
+			probe.Context[k] = v
+		}
+		if err := e.loopDetector().RecordActionLog(probe, agentID, result.ActionLog); err != nil {
+			logger().Warn("failed to record action history", "project_id", bead.ProjectID, "bead_id", bead.ID, "error", err)
+		}
+		ctxUpdate["action_history"] = probe.Context["action_history"]
+		ctxUpdate["progress_metrics"] = probe.Context["progress_metrics"]
+	}
+	ctxUpdate[InterruptedIterationsKey] = strconv.Itoa(iterations)
+
+	if err := e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
+		"status":      models.BeadStatusOpen,
+		"assigned_to": "",
+		"context":     ctxUpdate,
+	}); err != nil {
+		logger().Error("failed to checkpoint interrupted bead", "project_id", bead.ProjectID, "bead_id", bead.ID, "error", err)
+		return
+	}
+	logger().Info("checkpointed interrupted bead", "project_id", bead.ProjectID, "bead_id", bead.ID, "iterations", iterations)
+}
@@ -0,0 +1,167 @@
+package taskexecutor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// blockingProtocol is a provider whose completions never finish: each call
+// is announced on started and then waits for its context to be done.
+type blockingProtocol struct {
+	started chan struct{}
+}
+
+func (p *blockingProtocol) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	select {
+	case p.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *blockingProtocol) GetModels(ctx context.Context) ([]provider.Model, error) {
+	return nil, nil
+}
+
+// newDrainTestExecutor returns an executor whose only provider is fake,
+// working on bm's beads.
+func newDrainTestExecutor(t *testing.T, bm *beads.Manager, fake provider.Protocol) *Executor {
+	t.Helper()
+	reg := provider.NewRegistry()
+	if err := reg.Upsert(&provider.ProviderConfig{ID: "fake", Type: "mock", Model: "fake-model", Status: "healthy"}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := reg.Get("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Protocol = fake
+	return New(reg, bm, nil, nil, nil)
+}
+
+func newDrainTestBeads(t *testing.T) *beads.Manager {
+	t.Helper()
+	bm := beads.NewManager("")
+	bm.SetBeadsPath(t.TempDir())
+	return bm
+}
+
+// startWorker runs a worker for the project under a context the executor
+// can cancel, as StartProject does, and returns a channel closed once the
+// worker has exited.
+func startWorker(e *Executor, projectID string) <-chan struct{} {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.mu.Lock()
+	state := e.getOrCreateState(projectID)
+	state.ctx, state.cancel = ctx, cancel
+	state.activeWorkers++
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.workerLoop(ctx, projectID, state)
+	}()
+	return done
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestWorkerLoop_NoClaimsWhileDraining(t *testing.T) {
+	bm := newDrainTestBeads(t)
+	bead, _ := bm.CreateBead("Fix the parser", "", models.BeadPriorityP2, "task", "p")
+	e := newDrainTestExecutor(t, bm, &blockingProtocol{started: make(chan struct{}, 1)})
+
+	if interrupted := e.Drain(context.Background(), time.Second); interrupted != nil {
+		t.Fatalf("Drain() with nothing in flight interrupted %v", interrupted)
+	}
+	waitFor(t, startWorker(e, "p"), "the worker to exit")
+
+	got, _ := bm.GetBead(bead.ID)
+	if got.Status != models.BeadStatusOpen || got.AssignedTo != "" {
+		t.Errorf("bead is %s, assigned to %q; want it left open and unclaimed", got.Status, got.AssignedTo)
+	}
+}
+
+func TestWorkerLoop_HandsBackBeadClaimedWhileDraining(t *testing.T) {
+	bm := newDrainTestBeads(t)
+	bead, _ := bm.CreateBead("Fix the parser", "", models.BeadPriorityP2, "task", "p")
+	_ = bm.UpdateBead(bead.ID, map[string]interface{}{"context": map[string]string{RoleContextKey: "coder"}})
+	e := newDrainTestExecutor(t, bm, &blockingProtocol{started: make(chan struct{}, 1)})
+
+	// Draining starts while the worker is picking the bead's agent, after
+	// it last checked but before the bead counts as in flight.
+	e.SetAgentSource(func(projectID string) []*models.Agent {
+		e.mu.Lock()
+		e.draining = true
+		e.mu.Unlock()
+		return nil
+	})
+	waitFor(t, startWorker(e, "p"), "the worker to exit")
+
+	got, _ := bm.GetBead(bead.ID)
+	if got.Status != models.BeadStatusOpen || got.AssignedTo != "" {
+		t.Errorf("bead is %s, assigned to %q; want it handed back", got.Status, got.AssignedTo)
+	}
+	e.mu.Lock()
+	running := len(e.running)
+	e.mu.Unlock()
+	if running != 0 {
+		t.Errorf("%d beads still marked running", running)
+	}
+	if interrupted := e.Drain(context.Background(), time.Second); interrupted != nil {
+		t.Errorf("Drain() interrupted %v; the handed back bead never started", interrupted)
+	}
+}
+
+func TestDrain_InterruptsAndResumes(t *testing.T) {
+	bm := newDrainTestBeads(t)
+	bead, _ := bm.CreateBead("Fix the parser", "", models.BeadPriorityP2, "task", "p")
+	fake := &blockingProtocol{started: make(chan struct{}, 1)}
+	e := newDrainTestExecutor(t, bm, fake)
+
+	done := startWorker(e, "p")
+	waitFor(t, fake.started, "the bead to start")
+
+	interrupted := e.Drain(context.Background(), 10*time.Millisecond)
+	if len(interrupted) != 1 || interrupted[0] != bead.ID {
+		t.Fatalf("Drain() interrupted %v, want [%s]", interrupted, bead.ID)
+	}
+	waitFor(t, done, "the worker to exit")
+
+	got, _ := bm.GetBead(bead.ID)
+	if got.Status != models.BeadStatusOpen || got.AssignedTo != "" {
+		t.Errorf("interrupted bead is %s, assigned to %q; want it reopened", got.Status, got.AssignedTo)
+	}
+	if got.Context[InterruptedAtKey] == "" || got.Context[InterruptedIterationsKey] != "0" {
+		t.Errorf("interrupted bead context = %v, want %s set and %s = 0", got.Context, InterruptedAtKey, InterruptedIterationsKey)
+	}
+	if got.Context["dispatch_count"] != "" || got.Context["error_history"] != "" {
+		t.Errorf("interruption counted as a failed run: %v", got.Context)
+	}
+
+	// After a restart, the next run of the bead clears the markers.
+	fake = &blockingProtocol{started: make(chan struct{}, 1)}
+	e = newDrainTestExecutor(t, bm, fake)
+	done = startWorker(e, "p")
+	waitFor(t, fake.started, "the bead to resume")
+	got, _ = bm.GetBead(bead.ID)
+	if got.Context[InterruptedAtKey] != "" || got.Context[InterruptedIterationsKey] != "" {
+		t.Errorf("resumed bead context = %v, want the interruption markers cleared", got.Context)
+	}
+	e.Drain(context.Background(), 0)
+	waitFor(t, done, "the worker to exit")
+}
@@ -6,7 +6,7 @@ package taskexecutor
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,20 +19,41 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/analytics"
 	"github.com/jordanhubbard/loom/internal/beads"
+	"github.com/jordanhubbard/loom/internal/comments"
 	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/internal/dispatch"
+	"github.com/jordanhubbard/loom/internal/logging"
+	"github.com/jordanhubbard/loom/internal/metrics"
 	"github.com/jordanhubbard/loom/internal/project"
 	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/internal/worker"
 	"github.com/jordanhubbard/loom/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// logger returns the task executor's logger. It is looked up on each use so
+// it follows the default logger installed at startup.
+func logger() *slog.Logger {
+	return logging.For("taskexecutor")
+}
+
 const (
+	// defaultNumWorkers: beads a project works on at once, unless the
+	// limits say otherwise.
 	defaultNumWorkers = 5
-	// maxIdleRounds: after this many consecutive nil-claim rounds (each 5s),
-	// a worker goroutine exits. 36 × 5s = 3 minutes of idleness.
-	maxIdleRounds = 36
+	// defaultMaxLoopIterations: the action loop limit for projects that
+	// don't set max_loop_iterations.
+	defaultMaxLoopIterations = 100
+	// defaultClaimInterval: how long an idle worker waits between attempts
+	// to claim a bead.
+	defaultClaimInterval = 5 * time.Second
+	// maxIdleTime: a worker goroutine that has found nothing to claim for
+	// this long exits.
+	maxIdleTime = 3 * time.Minute
 	// watcherInterval: how often the watcher checks for new work when idle.
 	watcherInterval = 30 * time.Second
 	// gitFetchInterval: how often the watcher does a git fetch to detect
@@ -45,7 +66,9 @@ const (
 	// providerErrorBackoff: how long a worker pauses after a provider error
 	// (502, 429, context canceled) before claiming the next bead. Prevents
 	// hot-spin loops that exhaust the tokenhub rate limit (60 RPS / IP).
-	providerErrorBackoff  = 3 * time.Second
+	providerErrorBackoff = 3 * time.Second
+	// maxConcurrentRequests: model calls in flight across all projects,
+	// unless the limits say otherwise.
 	maxConcurrentRequests = 3
 )
 
@@ -55,6 +78,12 @@ type projectState struct {
 	watcherRunning bool
 	// wakeCh is sent on to immediately unblock a sleeping watcher.
 	wakeCh chan struct{}
+	// ctx is the context the project's watcher and workers run under, and
+	// cancel stops them all (see StopProject).
+	ctx    context.Context
+	cancel context.CancelFunc
+	// llmCalls caps the project's own model calls in flight.
+	llmCalls *limiter
 }
 
 // Executor is the direct bead execution engine.
@@ -65,10 +94,26 @@ type Executor struct {
 	projectManager   *project.Manager
 	db               *database.Database
 	lessonsProvider  worker.LessonsProvider
-	numWorkers       int
+	summarizer       worker.Summarizer
+	agentSource      func(projectID string) []*models.Agent
+	maxLoopIter      func(projectID string, fallback int) int
+	loopThresholds   func(projectID string) dispatch.LoopThresholds
+	metrics          *metrics.Metrics
+	analyticsLogger  *analytics.Logger
+	limits           Limits
+	projectLimits    func(projectID string) Limits
+	fileLocker       FileLocker
 	projectStates    map[string]*projectState
-	semaphore        chan struct{}
-	mu               sync.Mutex
+	running          map[string]*runningBead // by bead ID
+	llmCalls         *limiter                // model calls in flight, all projects
+	// draining is set by Drain; inFlight counts the beads being executed
+	// and interrupted holds those Drain cut short.
+	draining    bool
+	inFlight    sync.WaitGroup
+	interrupted map[string]bool
+	// paused is set by SetPaused, while the server is in maintenance.
+	paused bool
+	mu     sync.Mutex
 }
 
 // New creates an Executor.
@@ -85,9 +130,11 @@ func New(
 		actionRouter:     actionRouter,
 		projectManager:   projectManager,
 		db:               db,
-		numWorkers:       defaultNumWorkers,
+		limits:           DefaultLimits(),
 		projectStates:    make(map[string]*projectState),
-		semaphore:        make(chan struct{}, maxConcurrentRequests),
+		running:          make(map[string]*runningBead),
+		llmCalls:         newLimiter(maxConcurrentRequests),
+		interrupted:      make(map[string]bool),
 	}
 }
 
@@ -98,43 +145,126 @@ func (e *Executor) SetLessonsProvider(lp worker.LessonsProvider) {
 	e.lessonsProvider = lp
 }
 
-// SetNumWorkers sets the number of concurrent worker goroutines per project.
-func (e *Executor) SetNumWorkers(n int) {
+// SetSummarizer sets what summarizes long conversations nearing the
+// context window.
+func (e *Executor) SetSummarizer(s worker.Summarizer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.summarizer = s
+}
+
+// SetAgentSource wires in the project's registered agents. When a bead names
+// a role, or its tags match an agent's capabilities, the worker runs it as one
+// of those agents (see reserve); otherwise the persona is chosen from the tags
+// alone.
+func (e *Executor) SetAgentSource(fn func(projectID string) []*models.Agent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.agentSource = fn
+}
+
+// SetProjectMaxLoopIterations sets the lookup of projects' own action loop
+// limits. It is given the default limit to fall back on.
+func (e *Executor) SetProjectMaxLoopIterations(lookup func(projectID string, fallback int) int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.maxLoopIter = lookup
+}
+
+// SetLoopThresholds sets the lookup of projects' loop detection thresholds.
+func (e *Executor) SetLoopThresholds(lookup func(projectID string) dispatch.LoopThresholds) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.loopThresholds = lookup
+}
+
+// loopDetector returns a loop detector using the projects' thresholds.
+func (e *Executor) loopDetector() *dispatch.LoopDetector {
+	e.mu.Lock()
+	lookup := e.loopThresholds
+	e.mu.Unlock()
+	ld := dispatch.NewLoopDetector()
+	ld.SetThresholdLookup(lookup)
+	return ld
+}
+
+// SetMetrics sets where bead runs and their loop iterations are counted.
+func (e *Executor) SetMetrics(m *metrics.Metrics) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.metrics = m
+}
+
+// SetAnalyticsLogger sets where the token usage of each model call is
+// logged, attributed to the bead and the actions it produced.
+func (e *Executor) SetAnalyticsLogger(l *analytics.Logger) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.numWorkers = n
+	e.analyticsLogger = l
+}
+
+// recordRun counts a bead run that ended with result.
+func (e *Executor) recordRun(projectID, result string, iterations int) {
+	e.mu.Lock()
+	m := e.metrics
+	e.mu.Unlock()
+	if m != nil {
+		m.RecordTaskRun(projectID, result, iterations)
+	}
 }
 
 // Start ensures the watcher is running and spawns workers for projectID.
 // Safe to call multiple times; spawns workers only when none are active.
+// Does nothing once the executor is draining.
 func (e *Executor) Start(ctx context.Context, projectID string) {
+	n := e.ProjectLimits(projectID).MaxConcurrentBeads
 	e.mu.Lock()
+	if e.draining {
+		e.mu.Unlock()
+		return
+	}
 	state := e.getOrCreateState(projectID)
-	n := e.numWorkers
 
 	// Start the long-lived watcher if not already running
 	if !state.watcherRunning {
 		state.watcherRunning = true
+		state.ctx, state.cancel = context.WithCancel(ctx)
 		e.mu.Unlock()
-		go e.watcherLoop(ctx, projectID)
+		go e.watcherLoop(state.ctx, projectID)
 	} else {
 		e.mu.Unlock()
 	}
+	ctx = state.ctx
 
-	// Spawn workers up to numWorkers
+	// Spawn workers up to the project's bead limit
 	e.mu.Lock()
 	toSpawn := n - state.activeWorkers
 	state.activeWorkers += toSpawn
 	e.mu.Unlock()
 
 	if toSpawn > 0 {
-		log.Printf("[TaskExecutor] Spawning %d worker(s) for project %s", toSpawn, projectID)
+		logger().Info("spawning workers", "project_id", projectID, "count", toSpawn)
 		for i := 0; i < toSpawn; i++ {
-			go e.workerLoop(ctx, projectID)
+			go e.workerLoop(ctx, projectID, state)
 		}
 	}
 }
 
+// StopProject stops the watcher and workers of projectID. Workers part way
+// through a bead see their context canceled and reset it. Start brings the
+// project back.
+func (e *Executor) StopProject(projectID string) {
+	e.mu.Lock()
+	state, ok := e.projectStates[projectID]
+	delete(e.projectStates, projectID)
+	e.mu.Unlock()
+
+	if ok && state.cancel != nil {
+		state.cancel()
+		logger().Info("stopped project", "project_id", projectID)
+	}
+}
+
 // WakeProject signals that new work may be available, spawning workers if idle.
 func (e *Executor) WakeProject(projectID string) {
 	e.mu.Lock()
@@ -156,28 +286,31 @@ func (e *Executor) getOrCreateState(projectID string) *projectState {
 		return s
 	}
 	s := &projectState{
-		wakeCh: make(chan struct{}, 1),
+		wakeCh:   make(chan struct{}, 1),
+		llmCalls: newLimiter(0),
 	}
 	e.projectStates[projectID] = s
 	return s
 }
 
-// workerLoop claims and executes beads. Exits after maxIdleRounds of no work.
-func (e *Executor) workerLoop(ctx context.Context, projectID string) {
+// workerLoop claims and executes beads. Exits after maxIdleTime of no work,
+// or when the project's bead limit is lowered below its running workers.
+func (e *Executor) workerLoop(ctx context.Context, projectID string, state *projectState) {
 	workerID := fmt.Sprintf("exec-%s-%s", projectID, uuid.New().String()[:8])
-	log.Printf("[TaskExecutor] Worker %s started for project %s", workerID, projectID)
+	logger().Info("worker started", "project_id", projectID, "agent_id", workerID)
 
-	idleRounds := 0
+	idleSince := time.Now()
+	counted := true
 	defer func() {
 		e.mu.Lock()
-		if s, ok := e.projectStates[projectID]; ok {
-			s.activeWorkers--
-			if s.activeWorkers < 0 {
-				s.activeWorkers = 0
-			}
+		if counted {
+			state.activeWorkers--
+		}
+		if state.activeWorkers < 0 {
+			state.activeWorkers = 0
 		}
 		e.mu.Unlock()
-		log.Printf("[TaskExecutor] Worker %s exiting (idle=%d)", workerID, idleRounds)
+		logger().Info("worker exiting", "project_id", projectID, "agent_id", workerID)
 	}()
 
 	for {
@@ -186,35 +319,52 @@ func (e *Executor) workerLoop(ctx context.Context, projectID string) {
 			return
 		default:
 		}
+		if e.isDraining() || e.Paused() {
+			return
+		}
 
-		// Acquire semaphore slot for concurrency limiting
-		select {
-		case <-ctx.Done():
+		limits := e.ProjectLimits(projectID)
+		e.mu.Lock()
+		if state.activeWorkers > limits.MaxConcurrentBeads {
+			state.activeWorkers--
+			counted = false
+			e.mu.Unlock()
+			logger().Info("worker over the project's bead limit", "project_id", projectID, "agent_id", workerID,
+				"max_concurrent_beads", limits.MaxConcurrentBeads)
 			return
-		case e.semaphore <- struct{}{}:
 		}
+		e.mu.Unlock()
 
-		bead := e.claimNextBead(ctx, projectID, workerID)
+		bead, instance := e.claimNextBead(ctx, projectID, workerID)
 		if bead == nil {
-			<-e.semaphore // Release semaphore slot
-			idleRounds++
-			if idleRounds >= maxIdleRounds {
-				log.Printf("[TaskExecutor] Worker %s idle for %ds, going to sleep",
-					workerID, idleRounds*5)
+			if time.Since(idleSince) >= maxIdleTime {
+				logger().Info("worker idle, going to sleep", "project_id", projectID, "agent_id", workerID,
+					"idle_seconds", int(time.Since(idleSince).Seconds()))
 				return
 			}
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(5 * time.Second):
+			case <-time.After(limits.ClaimInterval):
 			}
 			continue
 		}
 
-		idleRounds = 0
-		log.Printf("[TaskExecutor] Worker %s claimed bead %s (%s)", workerID, bead.ID, bead.Title)
-		if needsBackoff := e.executeBead(ctx, bead, workerID); needsBackoff {
-			<-e.semaphore // Release semaphore slot
+		if !e.beginBead() {
+			// Claimed as the executor began draining: hand it back unstarted.
+			_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
+				"status":      models.BeadStatusOpen,
+				"assigned_to": "",
+			})
+			e.release(bead.ID, workerID)
+			return
+		}
+		logger().Info("worker claimed bead", "project_id", projectID, "agent_id", workerID, "bead_id", bead.ID, "title", bead.Title)
+		needsBackoff := e.executeBead(ctx, bead, workerID, instance)
+		e.release(bead.ID, workerID)
+		e.inFlight.Done()
+		idleSince = time.Now()
+		if needsBackoff {
 			// Provider error (502, 429, context canceled): pause before
 			// claiming the next bead to avoid hammering tokenhub rate limits.
 			select {
@@ -222,8 +372,6 @@ func (e *Executor) workerLoop(ctx context.Context, projectID string) {
 				return
 			case <-time.After(providerErrorBackoff):
 			}
-		} else {
-			<-e.semaphore // Release semaphore slot
 		}
 	}
 }
@@ -231,8 +379,8 @@ func (e *Executor) workerLoop(ctx context.Context, projectID string) {
 // watcherLoop runs forever for a project. It wakes workers when new beads arrive,
 // either from the API (via WakeProject) or from a periodic git fetch.
 func (e *Executor) watcherLoop(ctx context.Context, projectID string) {
-	log.Printf("[TaskExecutor] Watcher started for project %s", projectID)
-	defer log.Printf("[TaskExecutor] Watcher stopped for project %s", projectID)
+	logger().Info("watcher started", "project_id", projectID)
+	defer logger().Info("watcher stopped", "project_id", projectID)
 
 	ticker := time.NewTicker(watcherInterval)
 	defer ticker.Stop()
@@ -272,21 +420,21 @@ func (e *Executor) maybeSpawnWorkers(ctx context.Context, projectID string) {
 		return
 	}
 
+	n := e.ProjectLimits(projectID).MaxConcurrentBeads
 	e.mu.Lock()
 	state := e.getOrCreateState(projectID)
-	n := e.numWorkers
 	toSpawn := n - state.activeWorkers
-	if toSpawn <= 0 {
+	if toSpawn <= 0 || e.draining || e.paused {
 		e.mu.Unlock()
 		return
 	}
 	state.activeWorkers += toSpawn
 	e.mu.Unlock()
 
-	log.Printf("[TaskExecutor] Waking %d worker(s) for project %s (%d ready beads)",
-		toSpawn, projectID, len(readyBeads))
+	logger().Info("waking workers", "project_id", projectID, "count", toSpawn,
+		"ready_beads", len(readyBeads))
 	for i := 0; i < toSpawn; i++ {
-		go e.workerLoop(ctx, projectID)
+		go e.workerLoop(ctx, projectID, state)
 	}
 }
 
@@ -330,14 +478,14 @@ func (e *Executor) fetchAndReloadBeads(ctx context.Context, projectID string) {
 	resetCtx, cancel2 := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel2()
 	if err := runShell(resetCtx, fmt.Sprintf("cd %q && git reset --hard FETCH_HEAD 2>/dev/null", worktreeRoot)); err != nil {
-		log.Printf("[TaskExecutor] git reset failed for project %s: %v", projectID, err)
+		logger().Error("git reset failed", "project_id", projectID, "error", err)
 		return
 	}
 
-	log.Printf("[TaskExecutor] New beads detected for project %s, reloading", projectID)
+	logger().Info("new beads detected, reloading", "project_id", projectID)
 	e.beadManager.ClearProjectBeads(projectID)
 	if err := e.beadManager.LoadBeadsFromGit(ctx, projectID, beadsPath); err != nil {
-		log.Printf("[TaskExecutor] reload failed for project %s: %v", projectID, err)
+		logger().Error("reload failed", "project_id", projectID, "error", err)
 	}
 }
 
@@ -347,13 +495,16 @@ func runShell(ctx context.Context, cmd string) error {
 	return c.Run()
 }
 
-// claimNextBead returns the next available bead for the project, or nil.
-func (e *Executor) claimNextBead(ctx context.Context, projectID, workerID string) *models.Bead {
+// claimNextBead returns the next available bead for the project and the
+// agent instance to run it as, or a nil bead. Beads are tried most urgent
+// first by effective priority. The worker must release the bead when done
+// with it.
+func (e *Executor) claimNextBead(ctx context.Context, projectID, workerID string) (*models.Bead, *models.Agent) {
 	_ = ctx // reserved for future use
 	readyBeads, err := e.beadManager.GetReadyBeads(projectID)
 	if err != nil {
-		log.Printf("[TaskExecutor] GetReadyBeads(%s) error: %v", projectID, err)
-		return nil
+		logger().Error("listing ready beads failed", "project_id", projectID, "error", err)
+		return nil, nil
 	}
 
 	for _, b := range readyBeads {
@@ -370,8 +521,8 @@ func (e *Executor) claimNextBead(ctx context.Context, projectID, workerID string
 		// the executor is gone and we reset it back to open so it can be reclaimed.
 		if b.Status == models.BeadStatusInProgress && b.AssignedTo != "" {
 			if strings.HasPrefix(b.AssignedTo, "exec-") && time.Since(b.UpdatedAt) > zombieBeadThreshold {
-				log.Printf("[TaskExecutor] Reclaiming zombie bead %s (stale executor %s, age %v)",
-					b.ID, b.AssignedTo, time.Since(b.UpdatedAt).Round(time.Second))
+				logger().Warn("reclaiming zombie bead", "project_id", projectID, "bead_id", b.ID,
+					"stale_executor", b.AssignedTo, "age", time.Since(b.UpdatedAt).Round(time.Second))
 				_ = e.beadManager.UpdateBead(b.ID, map[string]interface{}{
 					"status":      models.BeadStatusOpen,
 					"assigned_to": "",
@@ -389,22 +540,42 @@ func (e *Executor) claimNextBead(ctx context.Context, projectID, workerID string
 			})
 			b.AssignedTo = ""
 		}
+		instance, ok := e.reserve(b, workerID)
+		if !ok {
+			continue
+		}
 		// Try to claim; another worker goroutine may win the race
 		if err := e.beadManager.ClaimBead(b.ID, workerID); err != nil {
+			e.release(b.ID, workerID)
 			continue
 		}
-		return b
+		return b, instance
 	}
-	return nil
+	return nil, nil
 }
 
 // executeBead runs the full action loop for a claimed bead.
 // executeBead runs a bead through the worker loop. Returns true if the worker
 // should back off before claiming the next bead (provider errors, rate limits).
-func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID string) (needsBackoff bool) {
+func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID string, instance *models.Agent) (needsBackoff bool) {
+	// One trace per bead execution: LLM calls and actions below become
+	// child spans of this one.
+	ctx, span := telemetry.Tracer.Start(ctx, "taskexecutor.executeBead")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("bead_id", bead.ID),
+		attribute.String("project_id", bead.ProjectID),
+		attribute.String("worker_id", workerID),
+	)
+	if traceID := telemetry.TraceID(ctx); traceID != "" {
+		_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
+			"context": map[string]string{"trace_id": traceID},
+		})
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("[TaskExecutor] PANIC for bead %s: %v", bead.ID, r)
+			logger().ErrorContext(ctx, "panic while executing bead", "project_id", bead.ProjectID, "bead_id", bead.ID, "panic", fmt.Sprint(r))
 			_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
 				"status":      models.BeadStatusOpen,
 				"assigned_to": "",
@@ -412,16 +583,17 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 		}
 	}()
 
-	providers := e.providerRegistry.ListActive()
-	if len(providers) == 0 {
-		log.Printf("[TaskExecutor] No active providers, releasing bead %s", bead.ID)
+	prov := e.providerRegistry.SelectProvider(bead.ProjectID)
+	if prov == nil {
+		logger().WarnContext(ctx, "no available providers, releasing bead", "project_id", bead.ProjectID, "bead_id", bead.ID)
+		e.recordRun(bead.ProjectID, "no_provider", 0)
+		span.SetStatus(codes.Error, "no provider available")
 		_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
 			"status":      models.BeadStatusOpen,
 			"assigned_to": "",
 		})
 		return true // no providers = back off
 	}
-	prov := providers[0]
 
 	personaName := personaForBead(bead)
 	agent := &models.Agent{
@@ -436,11 +608,25 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 			Character: personas[personaName],
 		},
 	}
+	if instance != nil {
+		logger().InfoContext(ctx, "bead runs as project agent", "project_id", bead.ProjectID, "bead_id", bead.ID,
+			"agent", instance.Name, "role", instance.Role, "persona", instance.PersonaName)
+		agent.Name = instance.Name
+		agent.Role = instance.Role
+		agent.PersonaName = instance.PersonaName
+		agent.Persona = instance.Persona
+		agent.Capabilities = instance.Capabilities
+	}
+
+	span.SetAttributes(attribute.String("provider_id", prov.Config.ID))
 
 	w := worker.NewWorker(workerID, agent, prov)
 	if e.db != nil {
 		w.SetDatabase(e.db)
 	}
+	w.SetCallGate(func(ctx context.Context) (func(), error) {
+		return e.acquireLLMCall(ctx, bead.ProjectID)
+	})
 
 	var proj *models.Project
 	if e.projectManager != nil {
@@ -450,13 +636,28 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 	task := &worker.Task{
 		ID:          fmt.Sprintf("task-%s-%d", bead.ID, time.Now().UnixNano()),
 		Description: buildBeadDescription(bead),
-		Context:     buildBeadContext(bead, proj),
+		Context:     buildBeadContext(bead, proj) + comments.PromptSection(e.db, bead.ID),
 		BeadID:      bead.ID,
 		ProjectID:   bead.ProjectID,
 	}
+	if bead.Context[InterruptedAtKey] != "" {
+		logger().InfoContext(ctx, "resuming interrupted bead", "project_id", bead.ProjectID, "bead_id", bead.ID,
+			"interrupted_at", bead.Context[InterruptedAtKey])
+		_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
+			"context": map[string]string{InterruptedAtKey: "", InterruptedIterationsKey: ""},
+		})
+	}
+
+	maxIterations := defaultMaxLoopIterations
+	e.mu.Lock()
+	lookup := e.maxLoopIter
+	e.mu.Unlock()
+	if lookup != nil {
+		maxIterations = lookup(bead.ProjectID, maxIterations)
+	}
 
 	loopConfig := &worker.LoopConfig{
-		MaxIterations: 100,
+		MaxIterations: maxIterations,
 		Router:        e.actionRouter,
 		ActionContext: actions.ActionContext{
 			AgentID:   workerID,
@@ -464,6 +665,7 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 			ProjectID: bead.ProjectID,
 		},
 		LessonsProvider: e.lessonsProvider,
+		Summarizer:      e.summarizer,
 		DB:              e.db,
 		TextMode:        !isFullModeCapable(prov),
 		OnProgress: func() {
@@ -473,15 +675,45 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 		},
 	}
 
+	e.mu.Lock()
+	al := e.analyticsLogger
+	e.mu.Unlock()
+	if al != nil {
+		loopConfig.OnUsage = func(u worker.CompletionUsage) {
+			_ = al.LogRequest(ctx, u.RequestLog("agent:"+agent.Name, prov.Config.ID, loopConfig.ActionContext))
+		}
+	}
+
 	result, err := w.ExecuteTaskWithLoop(ctx, task, loopConfig)
+	if err != nil && e.wasInterrupted(bead.ID) {
+		iterations := 0
+		if result != nil {
+			iterations = result.Iterations
+		}
+		e.recordRun(bead.ProjectID, "interrupted", iterations)
+		span.SetStatus(codes.Error, "interrupted by shutdown")
+		e.checkpointBead(bead, workerID, result)
+		return false
+	}
 	if err != nil {
-		log.Printf("[TaskExecutor] ExecuteTaskWithLoop error for bead %s: %v", bead.ID, err)
+		logger().ErrorContext(ctx, "bead execution failed", "project_id", bead.ProjectID, "bead_id", bead.ID, "agent_id", workerID, "error", err)
+		e.recordRun(bead.ProjectID, "error", 0)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		e.handleBeadError(bead, err)
 		return true // provider error — caller should back off
 	}
 
-	log.Printf("[TaskExecutor] Bead %s finished: %s (%d iterations)",
-		bead.ID, result.TerminalReason, result.Iterations)
+	logger().InfoContext(ctx, "bead finished", "project_id", bead.ProjectID, "bead_id", bead.ID, "agent_id", workerID,
+		"terminal_reason", result.TerminalReason, "iterations", result.Iterations)
+	e.recordRun(bead.ProjectID, result.TerminalReason, result.Iterations)
+	span.SetAttributes(
+		attribute.String("terminal_reason", result.TerminalReason),
+		attribute.Int("iterations", result.Iterations),
+	)
+	if result.TerminalReason != "completed" {
+		span.SetStatus(codes.Error, result.TerminalReason)
+	}
 
 	if result.TerminalReason == "completed" {
 		// done/close_bead action signals success — explicitly mark closed
@@ -498,23 +730,59 @@ func (e *Executor) executeBead(ctx context.Context, bead *models.Bead, workerID
 			if _, err := e.db.DB().Exec(
 				"DELETE FROM conversation_contexts WHERE bead_id = $1", bead.ID,
 			); err != nil {
-				log.Printf("[TaskExecutor] Failed to clear conversation for bead %s: %v", bead.ID, err)
+				logger().ErrorContext(ctx, "failed to clear conversation", "project_id", bead.ProjectID, "bead_id", bead.ID, "error", err)
 			} else {
-				log.Printf("[TaskExecutor] Cleared stale conversation history for bead %s (parse_failures)", bead.ID)
+				logger().InfoContext(ctx, "cleared stale conversation history after parse failures", "project_id", bead.ProjectID, "bead_id", bead.ID)
 			}
 		}
 		e.handleBeadError(bead, fmt.Errorf("parse_failures: model failed to produce valid actions after %d iterations", result.Iterations))
 		return true // back off before next attempt
 	} else {
 		// Any other non-successful terminal reason: reset to open for retry
-		_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
-			"status":      models.BeadStatusOpen,
-			"assigned_to": "",
-		})
+		e.reopenAfterLoop(bead, workerID, result)
 	}
 	return false
 }
 
+// reopenAfterLoop adds an unfinished action loop's actions to the bead's
+// history and reopens the bead for another attempt, or blocks it when the
+// loop detectors find it going round in circles.
+func (e *Executor) reopenAfterLoop(bead *models.Bead, agentID string, result *worker.LoopResult) {
+	fresh, err := e.beadManager.GetBead(bead.ID)
+	if err != nil || fresh == nil {
+		fresh = bead
+	}
+	// Work on a copy of the context: the detectors write to it.
+	probe := &models.Bead{ID: fresh.ID, ProjectID: fresh.ProjectID, Context: make(map[string]string, len(fresh.Context))}
+	for k, v := range fresh.Context {
+		probe.Context[k] = v
+	}
+	// The loop ran, so an earlier run's error is no reason to flag it now.
+	delete(probe.Context, "last_run_error")
+
+	ld := e.loopDetector()
+	if err := ld.RecordActionLog(probe, agentID, result.ActionLog); err != nil {
+		logger().Warn("failed to record action history", "project_id", bead.ProjectID, "bead_id", bead.ID, "error", err)
+	}
+	ctxUpdate := map[string]string{
+		"action_history":   probe.Context["action_history"],
+		"progress_metrics": probe.Context["progress_metrics"],
+	}
+	newStatus := models.BeadStatusOpen
+	if f := ld.Check(probe); f != nil {
+		for k, v := range f.Context() {
+			ctxUpdate[k] = v
+		}
+		newStatus = models.BeadStatusBlocked
+		logger().Warn("loop detected", "project_id", bead.ProjectID, "bead_id", bead.ID, "detector", f.Detector, "reason", f.Reason)
+	}
+	_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
+		"status":      newStatus,
+		"assigned_to": "",
+		"context":     ctxUpdate,
+	})
+}
+
 // handleBeadError records the error in bead context and detects dispatch loops.
 // Context-canceled errors (from loom shutdown) are silently reset.
 // Repeated provider/infra errors trigger loop detection and eventual blocking.
@@ -567,24 +835,24 @@ func (e *Executor) handleBeadError(bead *models.Bead, execErr error) {
 	fresh.Context["last_run_at"] = time.Now().UTC().Format(time.RFC3339)
 
 	// Run loop detection on the updated bead context.
-	ld := dispatch.NewLoopDetector()
-	isStuck, loopReason := ld.IsStuckInLoop(fresh)
+	finding := e.loopDetector().Check(fresh)
 
 	ctxUpdate := map[string]string{
 		"dispatch_count": fresh.Context["dispatch_count"],
 		"error_history":  fresh.Context["error_history"],
 		"last_run_error": fresh.Context["last_run_error"],
 		"last_run_at":    fresh.Context["last_run_at"],
-		"loop_detected":  fmt.Sprintf("%t", isStuck),
+		"loop_detected":  fmt.Sprintf("%t", finding != nil),
 	}
-	if isStuck {
-		ctxUpdate["loop_detected_reason"] = loopReason
-		ctxUpdate["loop_detected_at"] = time.Now().UTC().Format(time.RFC3339)
-		log.Printf("[TaskExecutor] Loop detected for bead %s: %s", bead.ID, loopReason)
+	if finding != nil {
+		for k, v := range finding.Context() {
+			ctxUpdate[k] = v
+		}
+		logger().Warn("loop detected", "project_id", bead.ProjectID, "bead_id", bead.ID, "detector", finding.Detector, "reason", finding.Reason)
 	}
 
 	newStatus := models.BeadStatusOpen
-	if isStuck {
+	if finding != nil {
 		newStatus = models.BeadStatusBlocked
 	}
 	_ = e.beadManager.UpdateBead(bead.ID, map[string]interface{}{
@@ -664,7 +932,9 @@ func buildBeadContext(bead *models.Bead, proj *models.Project) string {
 			// Skip internal executor fields from the prompt to reduce noise.
 			switch k {
 			case "dispatch_count", "error_history", "loop_detected",
-				"loop_detected_reason", "loop_detected_at", "ralph_blocked_reason":
+				"loop_detected_reason", "loop_detected_at", "ralph_blocked_reason",
+				"loop_detected_by", "loop_detected_evidence", "action_history", "progress_metrics",
+				InterruptedAtKey, InterruptedIterationsKey:
 				continue
 			}
 			sb.WriteString(fmt.Sprintf("- %s: %s\n", k, v))
@@ -0,0 +1,238 @@
+package taskexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// maxLimit caps MaxConcurrentBeads and MaxLLMCalls.
+	maxLimit = 100
+	// minClaimInterval and maxClaimInterval bound ClaimInterval.
+	minClaimInterval = time.Second
+	maxClaimInterval = 10 * time.Minute
+)
+
+// ErrInvalidLimits is returned for limits out of range.
+var ErrInvalidLimits = errors.New("invalid executor limits")
+
+// Limits are the executor's concurrency limits. As global limits, zero
+// fields keep the current value; as a project's limits, zero fields fall
+// back to the global ones.
+type Limits struct {
+	// MaxConcurrentBeads is how many beads a project works on at once, which
+	// is the number of worker goroutines it runs.
+	MaxConcurrentBeads int
+	// MaxLLMCalls is how many model calls may be in flight: across all
+	// projects for the global limit, within the project for a project's.
+	MaxLLMCalls int
+	// ClaimInterval is how often an idle worker looks for a bead to claim.
+	ClaimInterval time.Duration
+}
+
+// DefaultLimits are the limits used when nothing is configured.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxConcurrentBeads: defaultNumWorkers,
+		MaxLLMCalls:        maxConcurrentRequests,
+		ClaimInterval:      defaultClaimInterval,
+	}
+}
+
+// Validate checks that set fields are in range.
+func (l Limits) Validate() error {
+	if l.MaxConcurrentBeads < 0 || l.MaxConcurrentBeads > maxLimit {
+		return fmt.Errorf("%w: max_concurrent_beads must be from 1 to %d", ErrInvalidLimits, maxLimit)
+	}
+	if l.MaxLLMCalls < 0 || l.MaxLLMCalls > maxLimit {
+		return fmt.Errorf("%w: max_llm_calls must be from 1 to %d", ErrInvalidLimits, maxLimit)
+	}
+	if l.ClaimInterval != 0 && (l.ClaimInterval < minClaimInterval || l.ClaimInterval > maxClaimInterval) {
+		return fmt.Errorf("%w: claim_interval must be from %s to %s", ErrInvalidLimits, minClaimInterval, maxClaimInterval)
+	}
+	return nil
+}
+
+// Merge returns l with its zero fields taken from base.
+func (l Limits) Merge(base Limits) Limits {
+	if l.MaxConcurrentBeads == 0 {
+		l.MaxConcurrentBeads = base.MaxConcurrentBeads
+	}
+	if l.MaxLLMCalls == 0 {
+		l.MaxLLMCalls = base.MaxLLMCalls
+	}
+	if l.ClaimInterval == 0 {
+		l.ClaimInterval = base.ClaimInterval
+	}
+	return l
+}
+
+type limitsJSON struct {
+	MaxConcurrentBeads int    `json:"max_concurrent_beads,omitempty"`
+	MaxLLMCalls        int    `json:"max_llm_calls,omitempty"`
+	ClaimInterval      string `json:"claim_interval,omitempty"`
+}
+
+// MarshalJSON writes the claim interval as a duration string such as "5s".
+func (l Limits) MarshalJSON() ([]byte, error) {
+	j := limitsJSON{MaxConcurrentBeads: l.MaxConcurrentBeads, MaxLLMCalls: l.MaxLLMCalls}
+	if l.ClaimInterval > 0 {
+		j.ClaimInterval = l.ClaimInterval.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON reads what MarshalJSON writes.
+func (l *Limits) UnmarshalJSON(data []byte) error {
+	var j limitsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*l = Limits{MaxConcurrentBeads: j.MaxConcurrentBeads, MaxLLMCalls: j.MaxLLMCalls}
+	if j.ClaimInterval != "" {
+		d, err := time.ParseDuration(j.ClaimInterval)
+		if err != nil {
+			return fmt.Errorf("claim_interval: %w", err)
+		}
+		l.ClaimInterval = d
+	}
+	return nil
+}
+
+// SetLimits changes the global limits; zero fields are left as they are.
+// It takes effect without a restart: workers beyond a lowered bead limit
+// exit after their current bead, and projects with ready work get workers
+// up to a raised one.
+func (e *Executor) SetLimits(l Limits) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.limits = l.Merge(e.limits)
+	e.llmCalls.setLimit(e.limits.MaxLLMCalls)
+	var projects []string
+	for id, state := range e.projectStates {
+		if state.watcherRunning {
+			projects = append(projects, id)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, id := range projects {
+		e.WakeProject(id)
+	}
+	return nil
+}
+
+// Limits returns the global limits in effect.
+func (e *Executor) Limits() Limits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.limits
+}
+
+// SetProjectLimits sets the lookup of projects' own limits.
+func (e *Executor) SetProjectLimits(lookup func(projectID string) Limits) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.projectLimits = lookup
+}
+
+// ProjectLimits returns the limits in effect for a project: its own where
+// it sets them, the global ones otherwise. MaxLLMCalls is the project's own
+// cap, or 0 when only the global one applies.
+func (e *Executor) ProjectLimits(projectID string) Limits {
+	e.mu.Lock()
+	global, lookup := e.limits, e.projectLimits
+	e.mu.Unlock()
+	var own Limits
+	if lookup != nil {
+		own = lookup(projectID)
+	}
+	effective := own.Merge(global)
+	effective.MaxLLMCalls = own.MaxLLMCalls
+	return effective
+}
+
+// acquireLLMCall waits for a model call slot in the project and globally,
+// and returns the function that frees them.
+func (e *Executor) acquireLLMCall(ctx context.Context, projectID string) (func(), error) {
+	limit := e.ProjectLimits(projectID).MaxLLMCalls
+	e.mu.Lock()
+	state := e.getOrCreateState(projectID)
+	e.mu.Unlock()
+
+	state.llmCalls.setLimit(limit)
+	if err := state.llmCalls.acquire(ctx); err != nil {
+		return nil, err
+	}
+	if err := e.llmCalls.acquire(ctx); err != nil {
+		state.llmCalls.release()
+		return nil, err
+	}
+	return func() {
+		e.llmCalls.release()
+		state.llmCalls.release()
+	}, nil
+}
+
+// limiter is a counting semaphore whose size can change while it is in
+// use. A limit of 0 or less admits everyone.
+type limiter struct {
+	mu    sync.Mutex
+	limit int
+	inUse int
+	// freed is closed, and replaced, whenever a slot may have opened up.
+	freed chan struct{}
+}
+
+func newLimiter(limit int) *limiter {
+	return &limiter{limit: limit, freed: make(chan struct{})}
+}
+
+func (l *limiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.limit <= 0 || l.inUse < l.limit {
+			l.inUse++
+			l.mu.Unlock()
+			return nil
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inUse > 0 {
+		l.inUse--
+	}
+	l.wakeLocked()
+}
+
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit == l.limit {
+		return
+	}
+	l.limit = limit
+	l.wakeLocked()
+}
+
+func (l *limiter) wakeLocked() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
@@ -0,0 +1,199 @@
+package taskexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr bool
+	}{
+		{"zero keeps everything", Limits{}, false},
+		{"in range", Limits{MaxConcurrentBeads: 5, MaxLLMCalls: 10, ClaimInterval: 30 * time.Second}, false},
+		{"negative beads", Limits{MaxConcurrentBeads: -1}, true},
+		{"too many beads", Limits{MaxConcurrentBeads: maxLimit + 1}, true},
+		{"too many calls", Limits{MaxLLMCalls: maxLimit + 1}, true},
+		{"claim interval too short", Limits{ClaimInterval: time.Millisecond}, true},
+		{"claim interval too long", Limits{ClaimInterval: time.Hour}, true},
+	}
+	for _, tt := range tests {
+		err := tt.limits.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidLimits) {
+			t.Errorf("%s: error %v is not ErrInvalidLimits", tt.name, err)
+		}
+	}
+}
+
+func TestExecutor_ProjectLimits(t *testing.T) {
+	global := Limits{MaxConcurrentBeads: 4, MaxLLMCalls: 6, ClaimInterval: 5 * time.Second}
+	tests := []struct {
+		name string
+		own  Limits
+		want Limits
+	}{
+		{"no overrides", Limits{}, Limits{MaxConcurrentBeads: 4, ClaimInterval: 5 * time.Second}},
+		{"bead limit", Limits{MaxConcurrentBeads: 1}, Limits{MaxConcurrentBeads: 1, ClaimInterval: 5 * time.Second}},
+		{"own call cap", Limits{MaxLLMCalls: 2}, Limits{MaxConcurrentBeads: 4, MaxLLMCalls: 2, ClaimInterval: 5 * time.Second}},
+		{"everything", Limits{MaxConcurrentBeads: 8, MaxLLMCalls: 3, ClaimInterval: time.Minute}, Limits{MaxConcurrentBeads: 8, MaxLLMCalls: 3, ClaimInterval: time.Minute}},
+	}
+	for _, tt := range tests {
+		e := New(nil, nil, nil, nil, nil)
+		if err := e.SetLimits(global); err != nil {
+			t.Fatal(err)
+		}
+		e.SetProjectLimits(func(projectID string) Limits {
+			if projectID == "p" {
+				return tt.own
+			}
+			return Limits{}
+		})
+		if got := e.ProjectLimits("p"); got != tt.want {
+			t.Errorf("%s: ProjectLimits() = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if got := e.ProjectLimits("other"); got.MaxConcurrentBeads != 4 || got.MaxLLMCalls != 0 {
+			t.Errorf("%s: another project's limits = %+v, want the global ones", tt.name, got)
+		}
+	}
+}
+
+func TestExecutor_SetLimitsKeepsZeroFields(t *testing.T) {
+	e := New(nil, nil, nil, nil, nil)
+	if err := e.SetLimits(Limits{MaxLLMCalls: 7}); err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultLimits()
+	want.MaxLLMCalls = 7
+	if got := e.Limits(); got != want {
+		t.Errorf("Limits() = %+v, want %+v", got, want)
+	}
+	if err := e.SetLimits(Limits{MaxConcurrentBeads: -1}); err == nil {
+		t.Error("SetLimits() accepted a negative bead limit")
+	}
+	if got := e.Limits(); got != want {
+		t.Errorf("Limits() = %+v after a rejected change, want %+v", got, want)
+	}
+}
+
+func TestLimits_JSON(t *testing.T) {
+	in := Limits{MaxConcurrentBeads: 2, ClaimInterval: 90 * time.Second}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"max_concurrent_beads":2,"claim_interval":"1m30s"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+	var out Limits
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("Unmarshal() = %+v, %v; want %+v", out, err, in)
+	}
+	if err := json.Unmarshal([]byte(`{"claim_interval":"soon"}`), &out); err == nil {
+		t.Error("Unmarshal() accepted a bad claim interval")
+	}
+}
+
+// tryAcquire reports whether an LLM call slot is free right away.
+func tryAcquire(e *Executor, projectID string) (func(), bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release, err := e.acquireLLMCall(ctx, projectID)
+	return release, err == nil
+}
+
+func TestExecutor_AcquireLLMCall(t *testing.T) {
+	e := New(nil, nil, nil, nil, nil)
+	if err := e.SetLimits(Limits{MaxLLMCalls: 2}); err != nil {
+		t.Fatal(err)
+	}
+	e.SetProjectLimits(func(projectID string) Limits {
+		if projectID == "capped" {
+			return Limits{MaxLLMCalls: 1}
+		}
+		return Limits{}
+	})
+
+	// The project's own cap applies before the global one.
+	releaseCapped, ok := tryAcquire(e, "capped")
+	if !ok {
+		t.Fatal("first call of the capped project was refused")
+	}
+	if _, ok := tryAcquire(e, "capped"); ok {
+		t.Error("second call of the capped project was admitted past its cap of 1")
+	}
+
+	// The global cap counts every project's calls.
+	releaseOther, ok := tryAcquire(e, "other")
+	if !ok {
+		t.Fatal("call of another project was refused with a global slot free")
+	}
+	if _, ok := tryAcquire(e, "third"); ok {
+		t.Error("call admitted past the global cap of 2")
+	}
+
+	// Releasing a call frees both its project's slot and the global one,
+	// and wakes a caller already waiting.
+	waited := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		release, err := e.acquireLLMCall(ctx, "capped")
+		if err == nil {
+			release()
+		}
+		waited <- err
+	}()
+	releaseCapped()
+	if err := <-waited; err != nil {
+		t.Errorf("waiting call of the capped project: %v, want it admitted once a slot freed", err)
+	}
+	releaseOther()
+
+	// Raising the global cap takes effect without a restart.
+	if err := e.SetLimits(Limits{MaxLLMCalls: 3}); err != nil {
+		t.Fatal(err)
+	}
+	var releases []func()
+	for i := 0; i < 3; i++ {
+		release, ok := tryAcquire(e, "other")
+		if !ok {
+			t.Fatalf("call %d refused under the raised cap of 3", i+1)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := tryAcquire(e, "other"); ok {
+		t.Error("call admitted past the raised cap of 3")
+	}
+	for _, release := range releases {
+		release()
+	}
+}
+
+func TestLimiter_ZeroAdmitsEveryone(t *testing.T) {
+	l := newLimiter(0)
+	for i := 0; i < 5; i++ {
+		if err := l.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire %d: %v", i+1, err)
+		}
+	}
+	l.setLimit(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire over a lowered limit = %v, want context.Canceled", err)
+	}
+	for i := 0; i < 5; i++ {
+		l.release()
+	}
+	if err := l.acquire(ctx); err != nil {
+		t.Errorf("acquire after every release = %v, want a free slot", err)
+	}
+}
@@ -0,0 +1,35 @@
+package taskexecutor
+
+// SetPaused stops or resumes claiming beads. While paused, workers finish
+// the bead they hold and exit, and no new ones start; nothing in flight is
+// interrupted. Resuming wakes every project's watcher.
+func (e *Executor) SetPaused(paused bool) {
+	e.mu.Lock()
+	changed := e.paused != paused
+	e.paused = paused
+	var projects []string
+	if changed && !paused {
+		for id := range e.projectStates {
+			projects = append(projects, id)
+		}
+	}
+	e.mu.Unlock()
+	if !changed {
+		return
+	}
+	if paused {
+		logger().Info("paused claiming beads")
+		return
+	}
+	logger().Info("resumed claiming beads")
+	for _, id := range projects {
+		e.WakeProject(id)
+	}
+}
+
+// Paused reports whether claiming beads is paused.
+func (e *Executor) Paused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
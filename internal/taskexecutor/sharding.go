@@ -0,0 +1,193 @@
+package taskexecutor
+
+import (
+	"hash/fnv"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/agent"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Work sharding across agent instances. A role scaled to several agents runs
+// one bead at a time per agent. Each bead has a home agent among the
+// instances that can take it, picked by hashing the bead ID, so beads spread
+// evenly and a retried bead goes back to the agent that started it. When the
+// home agent is busy, the next idle instance steals the bead; when all are
+// busy it waits. Beads that list the files they will change are held back
+// while another running bead, or a file lock, covers one of those files.
+
+const (
+	// FilesContextKey is the bead context key holding the files a bead
+	// expects to change, separated by commas or newlines.
+	FilesContextKey = "files"
+	// RoleContextKey is the bead context key that routes a bead to the
+	// agents of one role, whatever its tags.
+	RoleContextKey = "role"
+)
+
+// FileLocker is where the executor takes locks on the files a running bead
+// changes. loom.FileLockManager implements it.
+type FileLocker interface {
+	AcquireLock(projectID, filePath, agentID, beadID string) (*models.FileLock, error)
+	ReleaseAgentLocks(agentID string) error
+}
+
+// runningBead is a claimed bead the executor is working on.
+type runningBead struct {
+	projectID string
+	agentID   string // the agent instance running it; "" for a tag-picked persona
+	files     []string
+}
+
+// SetFileLocker sets where the files of running beads are locked, so that
+// agents working through the API see them as taken.
+func (e *Executor) SetFileLocker(l FileLocker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fileLocker = l
+}
+
+// reserve picks the agent instance a bead runs as and marks the bead as
+// running, locking its files. It returns false when the bead must wait: it
+// is already running, its files are taken, or every instance that can take
+// it is busy. A nil agent means no registered agent matches the bead and it
+// runs under a persona chosen from its tags.
+func (e *Executor) reserve(bead *models.Bead, workerID string) (*models.Agent, bool) {
+	candidates := e.agentsForBead(bead)
+	files := beadFiles(bead)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.running[bead.ID]; ok {
+		return nil, false
+	}
+	busy := make(map[string]bool)
+	for _, rb := range e.running {
+		if rb.projectID != bead.ProjectID {
+			continue
+		}
+		if rb.agentID != "" {
+			busy[rb.agentID] = true
+		}
+		if overlaps(rb.files, files) {
+			return nil, false
+		}
+	}
+
+	var instance *models.Agent
+	if len(candidates) > 0 {
+		home := shardFor(bead.ID, len(candidates))
+		for i := range candidates {
+			if c := candidates[(home+i)%len(candidates)]; !busy[c.ID] {
+				instance = c
+				break
+			}
+		}
+		if instance == nil {
+			return nil, false
+		}
+	}
+
+	if e.fileLocker != nil {
+		for _, f := range files {
+			if _, err := e.fileLocker.AcquireLock(bead.ProjectID, f, workerID, bead.ID); err != nil {
+				_ = e.fileLocker.ReleaseAgentLocks(workerID)
+				logger().Debug("bead waiting on file lock", "project_id", bead.ProjectID, "bead_id", bead.ID, "file", f, "error", err)
+				return nil, false
+			}
+		}
+	}
+
+	rb := &runningBead{projectID: bead.ProjectID, files: files}
+	if instance != nil {
+		rb.agentID = instance.ID
+	}
+	e.running[bead.ID] = rb
+	return instance, true
+}
+
+// release undoes reserve once the worker is done with a bead.
+func (e *Executor) release(beadID, workerID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.running, beadID)
+	if e.fileLocker != nil {
+		_ = e.fileLocker.ReleaseAgentLocks(workerID)
+	}
+}
+
+// agentsForBead returns the project agents that can take a bead, sorted by
+// ID: the agents of the role named in its context, or else those whose
+// capabilities best match its tags. Paused and draining agents, and agents
+// without a loaded persona, are left out.
+func (e *Executor) agentsForBead(bead *models.Bead) []*models.Agent {
+	e.mu.Lock()
+	source := e.agentSource
+	e.mu.Unlock()
+	if source == nil {
+		return nil
+	}
+	role := strings.TrimSpace(bead.Context[RoleContextKey])
+	if role == "" && len(bead.Tags) == 0 {
+		return nil
+	}
+
+	var candidates []*models.Agent
+	for _, a := range source(bead.ProjectID) {
+		if a == nil || a.Persona == nil || a.Hold != "" {
+			continue
+		}
+		if role != "" && !strings.EqualFold(a.Role, role) {
+			continue
+		}
+		candidates = append(candidates, a)
+	}
+	if role == "" {
+		candidates = agent.BestCapabilityMatches(candidates, bead.Tags)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	return candidates
+}
+
+// shardFor maps a bead ID onto one of n instances.
+func shardFor(beadID string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(beadID))
+	return int(h.Sum32() % uint32(n))
+}
+
+// beadFiles returns the cleaned, de-duplicated paths in a bead's files
+// context.
+func beadFiles(bead *models.Bead) []string {
+	raw := bead.Context[FilesContextKey]
+	if raw == "" {
+		return nil
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, f := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		f = strings.TrimPrefix(path.Clean(f), "./")
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
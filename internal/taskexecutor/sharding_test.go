@@ -0,0 +1,187 @@
+package taskexecutor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// coders returns n agent instances of the coder role.
+func coders(n int) []*models.Agent {
+	agents := make([]*models.Agent, n)
+	for i := range agents {
+		agents[i] = &models.Agent{ID: fmt.Sprintf("coder-%d", i), Role: "coder", Persona: &models.Persona{Name: "coder"}}
+	}
+	return agents
+}
+
+func coderBead(id string, files string) *models.Bead {
+	ctx := map[string]string{RoleContextKey: "coder"}
+	if files != "" {
+		ctx[FilesContextKey] = files
+	}
+	return &models.Bead{ID: id, ProjectID: "p", Context: ctx}
+}
+
+func newShardingExecutor(agents *[]*models.Agent) *Executor {
+	e := New(nil, nil, nil, nil, nil)
+	e.SetAgentSource(func(projectID string) []*models.Agent { return *agents })
+	return e
+}
+
+func TestShardFor(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5} {
+		counts := make([]int, n)
+		for i := 0; i < 500; i++ {
+			id := fmt.Sprintf("bd-%d", i)
+			shard := shardFor(id, n)
+			if shard < 0 || shard >= n {
+				t.Fatalf("shardFor(%s, %d) = %d, out of range", id, n, shard)
+			}
+			if again := shardFor(id, n); again != shard {
+				t.Fatalf("shardFor(%s, %d) = %d, then %d", id, n, shard, again)
+			}
+			counts[shard]++
+		}
+		for i, c := range counts {
+			if c < 500/n/2 {
+				t.Errorf("n=%d: instance %d got %d of 500 beads, want an even spread: %v", n, i, c, counts)
+			}
+		}
+	}
+}
+
+func TestReserve_StableHomeAgent(t *testing.T) {
+	agents := coders(3)
+	e := newShardingExecutor(&agents)
+
+	for i := 0; i < 20; i++ {
+		bead := coderBead(fmt.Sprintf("bd-%d", i), "")
+		home := agents[shardFor(bead.ID, len(agents))]
+		for run := 0; run < 2; run++ {
+			got, ok := e.reserve(bead, "exec-1")
+			if !ok || got == nil || got.ID != home.ID {
+				t.Fatalf("%s run %d: reserved %v, %v; want its home agent %s", bead.ID, run+1, got, ok, home.ID)
+			}
+			e.release(bead.ID, "exec-1")
+		}
+	}
+}
+
+func TestReserve_RebalancesWhenInstancesChange(t *testing.T) {
+	agents := coders(2)
+	e := newShardingExecutor(&agents)
+
+	beadIDs := make([]string, 60)
+	for i := range beadIDs {
+		beadIDs[i] = fmt.Sprintf("bd-%d", i)
+	}
+	homes := func() map[string]string {
+		out := make(map[string]string)
+		for _, id := range beadIDs {
+			got, ok := e.reserve(coderBead(id, ""), "exec-1")
+			if !ok || got == nil {
+				t.Fatalf("%s: nothing reserved", id)
+			}
+			e.release(id, "exec-1")
+			out[id] = got.ID
+		}
+		return out
+	}
+	perAgent := func(homes map[string]string) map[string]int {
+		out := make(map[string]int)
+		for _, a := range homes {
+			out[a]++
+		}
+		return out
+	}
+
+	before := homes()
+	if again := homes(); !reflect.DeepEqual(before, again) {
+		t.Error("assignment changed without the instances changing")
+	}
+	if n := len(perAgent(before)); n != 2 {
+		t.Errorf("beads spread over %d of 2 instances", n)
+	}
+
+	// Scaling the role up spreads the beads over the new instance too, and
+	// the new assignment is as stable as the old one.
+	agents = coders(3)
+	scaled := homes()
+	if counts := perAgent(scaled); len(counts) != 3 || counts["coder-2"] == 0 {
+		t.Errorf("after scaling to 3, beads per instance = %v", counts)
+	}
+	if again := homes(); !reflect.DeepEqual(scaled, again) {
+		t.Error("assignment changed between runs after scaling")
+	}
+
+	// Scaling down moves the removed instance's beads to the others.
+	agents = coders(1)
+	for id, a := range homes() {
+		if a != "coder-0" {
+			t.Errorf("%s went to %s with only coder-0 left", id, a)
+		}
+	}
+}
+
+func TestReserve_StealsFromBusyHome(t *testing.T) {
+	agents := coders(2)
+	e := newShardingExecutor(&agents)
+
+	first := coderBead("bd-1", "")
+	home, ok := e.reserve(first, "exec-1")
+	if !ok {
+		t.Fatal("first bead not reserved")
+	}
+	// A second bead with the same home goes to the idle instance.
+	var second *models.Bead
+	for i := 2; second == nil; i++ {
+		if b := coderBead(fmt.Sprintf("bd-%d", i), ""); shardFor(b.ID, 2) == shardFor(first.ID, 2) {
+			second = b
+		}
+	}
+	stolen, ok := e.reserve(second, "exec-2")
+	if !ok || stolen == nil || stolen.ID == home.ID {
+		t.Fatalf("second bead reserved %v, %v; want the idle instance", stolen, ok)
+	}
+	// With both instances busy, a third bead waits.
+	if _, ok := e.reserve(coderBead("bd-x", ""), "exec-3"); ok {
+		t.Error("third bead reserved with every instance busy")
+	}
+	// A bead that is already running isn't reserved twice.
+	e.release(second.ID, "exec-2")
+	if _, ok := e.reserve(first, "exec-3"); ok {
+		t.Error("running bead reserved again")
+	}
+}
+
+func TestReserve_HoldsBackOverlappingFiles(t *testing.T) {
+	agents := coders(0)
+	e := newShardingExecutor(&agents)
+
+	if _, ok := e.reserve(coderBead("bd-1", "internal/a.go, ./internal/b.go"), "exec-1"); !ok {
+		t.Fatal("first bead not reserved")
+	}
+	if _, ok := e.reserve(coderBead("bd-2", "internal/b.go"), "exec-2"); ok {
+		t.Error("bead reserved while another running bead changes the same file")
+	}
+	if _, ok := e.reserve(coderBead("bd-3", "internal/c.go"), "exec-3"); !ok {
+		t.Error("bead with other files held back")
+	}
+	e.release("bd-1", "exec-1")
+	if _, ok := e.reserve(coderBead("bd-2", "internal/b.go"), "exec-2"); !ok {
+		t.Error("bead still held back once the other finished")
+	}
+}
+
+func TestBeadFiles(t *testing.T) {
+	bead := &models.Bead{Context: map[string]string{FilesContextKey: "a.go, ./b.go\nsub/../c.go,,a.go"}}
+	if got, want := beadFiles(bead), []string{"a.go", "b.go", "c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("beadFiles() = %v, want %v", got, want)
+	}
+	if got := beadFiles(&models.Bead{}); got != nil {
+		t.Errorf("beadFiles() without files = %v, want nil", got)
+	}
+}
@@ -166,3 +166,13 @@ func initMetrics() error {
 
 	return nil
 }
+
+// TraceID returns the ID of the trace ctx belongs to, or "" when ctx carries
+// no sampled span (for example when telemetry is not initialized).
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
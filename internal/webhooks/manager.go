@@ -0,0 +1,548 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhubbard/loom/internal/database"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+// EventBeadClosed is delivered when a bead's status changes to closed. It is
+// derived from bead.status_change rather than published on the event bus.
+const EventBeadClosed = "bead.closed"
+
+// EventWebhookTest is the event type sent by Manager.Test.
+const EventWebhookTest = "webhook.test"
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, hex encoded
+// and prefixed with "sha256=", when the webhook has a secret.
+const SignatureHeader = "X-Loom-Signature"
+
+// ErrNotFound is returned when a webhook ID does not exist.
+var ErrNotFound = errors.New("webhook not found")
+
+// Webhook is a registered outbound endpoint. The secret is write-only and
+// never returned.
+type Webhook struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	HasSecret  bool      `json:"has_secret"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Delivery is one attempt to deliver an event to a webhook.
+type Delivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateRequest registers a webhook. EventTypes lists event bus types
+// (e.g. "bead.created", "agent.stuck", "provider.unhealthy"), the derived
+// "bead.closed", or "*" for everything.
+type CreateRequest struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+// UpdateRequest changes the fields that are set.
+type UpdateRequest struct {
+	Name       *string  `json:"name,omitempty"`
+	URL        *string  `json:"url,omitempty"`
+	Secret     *string  `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+// Manager stores webhooks and delivers matching event bus events to them,
+// retrying failed deliveries with exponential backoff and recording every
+// attempt in the database.
+type Manager struct {
+	db          *database.Database
+	eventBus    *eventbus.EventBus
+	subscriber  *eventbus.Subscriber
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	done        chan struct{}
+
+	// Webhooks are read for every event, so the list is cached briefly and
+	// invalidated by local changes.
+	cacheMu  sync.Mutex
+	cache    []*database.Webhook
+	cachedAt time.Time
+}
+
+const webhookCacheTTL = 30 * time.Second
+
+// NewManager creates a webhook manager. Returns nil if the database is nil.
+// When eb is non-nil the manager subscribes to it and starts delivering.
+func NewManager(db *database.Database, eb *eventbus.EventBus) *Manager {
+	if db == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		db:          db,
+		eventBus:    eb,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: 4,
+		retryDelay:  2 * time.Second,
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+
+	if eb == nil {
+		close(m.done)
+		return m
+	}
+
+	// Transient events (streamed agent output) are far too chatty to POST.
+	m.subscriber = eb.Subscribe("webhooks-manager", func(e *eventbus.Event) bool { return !e.Transient })
+	go func() {
+		defer close(m.done)
+		m.run()
+	}()
+	return m
+}
+
+// Close stops delivering events and waits for in-flight deliveries.
+// Safe to call multiple times.
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+	m.cancel()
+	if m.eventBus != nil {
+		m.eventBus.Unsubscribe("webhooks-manager")
+	}
+	<-m.done
+	m.wg.Wait()
+}
+
+func (m *Manager) run() {
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case event, ok := <-m.subscriber.Channel:
+			if !ok {
+				return
+			}
+			m.dispatch(event)
+		}
+	}
+}
+
+// dispatch starts a delivery to every active webhook subscribed to event.
+func (m *Manager) dispatch(event *eventbus.Event) {
+	if event == nil {
+		return
+	}
+	names := eventNames(event)
+
+	hooks, err := m.activeWebhooks()
+	if err != nil {
+		log.Printf("[Webhooks] Failed to list webhooks: %v", err)
+		return
+	}
+	for _, hook := range hooks {
+		if !hook.Active {
+			continue
+		}
+		name, ok := matchEvent(hook.EventTypes, names)
+		if !ok {
+			continue
+		}
+		m.wg.Add(1)
+		go func(hook *database.Webhook, name string) {
+			defer m.wg.Done()
+			m.deliver(m.ctx, hook, name, event, m.maxAttempts)
+		}(hook, name)
+	}
+}
+
+func (m *Manager) activeWebhooks() ([]*database.Webhook, error) {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	if m.cache != nil && time.Since(m.cachedAt) < webhookCacheTTL {
+		return m.cache, nil
+	}
+	hooks, err := m.db.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	if hooks == nil {
+		hooks = []*database.Webhook{}
+	}
+	m.cache, m.cachedAt = hooks, time.Now()
+	return hooks, nil
+}
+
+func (m *Manager) invalidate() {
+	m.cacheMu.Lock()
+	m.cache = nil
+	m.cacheMu.Unlock()
+}
+
+// eventNames returns the names an event can be subscribed under: its own
+// type plus any derived names.
+func eventNames(event *eventbus.Event) []string {
+	names := []string{string(event.Type)}
+	if event.Type == eventbus.EventTypeBeadStatusChange {
+		if status, _ := event.Data["status"].(string); status == "closed" {
+			names = append(names, EventBeadClosed)
+		}
+	}
+	return names
+}
+
+// matchEvent returns the first of names the subscription covers. A derived
+// name is preferred so receivers see "bead.closed" rather than the generic
+// status change when they asked for it.
+func matchEvent(subscribed, names []string) (string, bool) {
+	for i := len(names) - 1; i >= 0; i-- {
+		for _, s := range subscribed {
+			if s == "*" || s == names[i] {
+				return names[i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// deliver POSTs the event to the webhook, retrying non-2xx responses and
+// transport errors up to attempts times. The final attempt is returned.
+func (m *Manager) deliver(ctx context.Context, hook *database.Webhook, name string, event *eventbus.Event, attempts int) *Delivery {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":         event.ID,
+		"type":       name,
+		"timestamp":  event.Timestamp,
+		"source":     event.Source,
+		"project_id": event.ProjectID,
+		"data":       event.Data,
+	})
+	if err != nil {
+		log.Printf("[Webhooks] Failed to encode %s for %s: %v", name, hook.ID, err)
+		return nil
+	}
+
+	var last *Delivery
+	for attempt := 1; attempt <= attempts; attempt++ {
+		last = m.attempt(ctx, hook, name, event.ID, body, attempt)
+		if err := m.db.CreateWebhookDelivery(&database.WebhookDelivery{
+			ID:         last.ID,
+			WebhookID:  last.WebhookID,
+			EventID:    last.EventID,
+			EventType:  last.EventType,
+			Attempt:    last.Attempt,
+			StatusCode: last.StatusCode,
+			Success:    last.Success,
+			Error:      last.Error,
+			DurationMs: last.DurationMs,
+			CreatedAt:  last.CreatedAt,
+		}); err != nil {
+			log.Printf("[Webhooks] %v", err)
+		}
+		if last.Success || attempt == attempts {
+			break
+		}
+
+		delay := m.retryDelay << (attempt - 1)
+		select {
+		case <-ctx.Done():
+			return last
+		case <-time.After(delay):
+		}
+	}
+	if !last.Success {
+		log.Printf("[Webhooks] Giving up on %s for webhook %s after %d attempts: %s", name, hook.ID, attempts, last.Error)
+	}
+	return last
+}
+
+func (m *Manager) attempt(ctx context.Context, hook *database.Webhook, name, eventID string, body []byte, attempt int) *Delivery {
+	d := &Delivery{
+		ID:        uuid.New().String(),
+		WebhookID: hook.ID,
+		EventID:   eventID,
+		EventType: name,
+		Attempt:   attempt,
+		CreatedAt: time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Loom-Webhooks/1.0")
+	req.Header.Set("X-Loom-Event", name)
+	req.Header.Set("X-Loom-Delivery", eventID)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	start := time.Now()
+	resp, err := m.client.Do(req)
+	d.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	d.StatusCode = resp.StatusCode
+	d.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !d.Success {
+		d.Error = resp.Status
+	}
+	return d
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret. Receivers should compute
+// the same value and compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Create registers a new webhook.
+func (m *Manager) Create(req CreateRequest, createdBy string) (*Webhook, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes := cleanEventTypes(req.EventTypes)
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("at least one event type is required")
+	}
+
+	now := time.Now()
+	hook := &database.Webhook{
+		ID:         "wh-" + uuid.New().String()[:8],
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: eventTypes,
+		Active:     req.Active == nil || *req.Active,
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := m.db.CreateWebhook(hook); err != nil {
+		return nil, err
+	}
+	m.invalidate()
+	return toWebhook(hook), nil
+}
+
+// Get returns a webhook by ID.
+func (m *Manager) Get(id string) (*Webhook, error) {
+	hook, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhook(hook), nil
+}
+
+// List returns all webhooks.
+func (m *Manager) List() ([]*Webhook, error) {
+	hooks, err := m.db.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Webhook, 0, len(hooks))
+	for _, h := range hooks {
+		result = append(result, toWebhook(h))
+	}
+	return result, nil
+}
+
+// Update applies the set fields of req to a webhook.
+func (m *Manager) Update(id string, req UpdateRequest) (*Webhook, error) {
+	hook, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, fmt.Errorf("name cannot be empty")
+		}
+		hook.Name = *req.Name
+	}
+	if req.URL != nil {
+		if err := validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+	if req.Secret != nil {
+		hook.Secret = *req.Secret
+	}
+	if req.EventTypes != nil {
+		eventTypes := cleanEventTypes(req.EventTypes)
+		if len(eventTypes) == 0 {
+			return nil, fmt.Errorf("at least one event type is required")
+		}
+		hook.EventTypes = eventTypes
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	hook.UpdatedAt = time.Now()
+
+	if err := m.db.UpdateWebhook(hook); err != nil {
+		return nil, err
+	}
+	m.invalidate()
+	return toWebhook(hook), nil
+}
+
+// Delete removes a webhook and its delivery history.
+func (m *Manager) Delete(id string) error {
+	if _, err := m.get(id); err != nil {
+		return err
+	}
+	if err := m.db.DeleteWebhook(id); err != nil {
+		return err
+	}
+	m.invalidate()
+	return nil
+}
+
+// Deliveries returns recent delivery attempts for a webhook, newest first.
+func (m *Manager) Deliveries(id string, limit int) ([]*Delivery, error) {
+	if _, err := m.get(id); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.ListWebhookDeliveries(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Delivery, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, &Delivery{
+			ID:         r.ID,
+			WebhookID:  r.WebhookID,
+			EventID:    r.EventID,
+			EventType:  r.EventType,
+			Attempt:    r.Attempt,
+			StatusCode: r.StatusCode,
+			Success:    r.Success,
+			Error:      r.Error,
+			DurationMs: r.DurationMs,
+			CreatedAt:  r.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// Test sends a single webhook.test event to the webhook, without retries,
+// and returns the recorded delivery.
+func (m *Manager) Test(ctx context.Context, id string) (*Delivery, error) {
+	hook, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	event := &eventbus.Event{
+		ID:        fmt.Sprintf("%s-%d", EventWebhookTest, time.Now().UnixNano()),
+		Type:      EventWebhookTest,
+		Timestamp: time.Now(),
+		Source:    "webhooks",
+		Data:      map[string]interface{}{"webhook_id": hook.ID},
+	}
+	return m.deliver(ctx, hook, EventWebhookTest, event, 1), nil
+}
+
+func (m *Manager) get(id string) (*database.Webhook, error) {
+	hook, err := m.db.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return hook, nil
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func cleanEventTypes(types []string) []string {
+	seen := make(map[string]bool, len(types))
+	var result []string
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+func toWebhook(h *database.Webhook) *Webhook {
+	eventTypes := h.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return &Webhook{
+		ID:         h.ID,
+		Name:       h.Name,
+		URL:        h.URL,
+		EventTypes: eventTypes,
+		Active:     h.Active,
+		HasSecret:  h.Secret != "",
+		CreatedBy:  h.CreatedBy,
+		CreatedAt:  h.CreatedAt,
+		UpdatedAt:  h.UpdatedAt,
+	}
+}
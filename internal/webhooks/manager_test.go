@@ -0,0 +1,148 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jordanhubbard/loom/internal/database/dbtest"
+	"github.com/jordanhubbard/loom/internal/eventbus"
+)
+
+func newTestManager(t *testing.T) (*Manager, *eventbus.EventBus) {
+	t.Helper()
+	db := dbtest.New(t)
+	eb := eventbus.NewEventBus()
+	m := NewManager(db, eb)
+	m.retryDelay = time.Millisecond
+	t.Cleanup(func() {
+		m.Close()
+		eb.Close()
+	})
+	return m, eb
+}
+
+type received struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+func TestManager_DeliversSignedEventsWithRetry(t *testing.T) {
+	m, eb := newTestManager(t)
+
+	var (
+		mu    sync.Mutex
+		calls []received
+	)
+	got := make(chan received, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec := received{event: r.Header.Get("X-Loom-Event"), signature: r.Header.Get(SignatureHeader), body: body}
+		mu.Lock()
+		calls = append(calls, rec)
+		first := len(calls) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		got <- rec
+	}))
+	defer srv.Close()
+
+	hook, err := m.Create(CreateRequest{
+		Name:       "ops",
+		URL:        srv.URL,
+		Secret:     "s3cret",
+		EventTypes: []string{EventBeadClosed, "provider.unhealthy"},
+	}, "user-admin")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !hook.HasSecret {
+		t.Error("expected HasSecret")
+	}
+
+	// Not subscribed: must not be delivered.
+	_ = eb.PublishBeadEvent(eventbus.EventTypeBeadStatusChange, "bd-1", "loom", map[string]interface{}{"status": "in_progress"})
+	_ = eb.PublishBeadEvent(eventbus.EventTypeBeadStatusChange, "bd-1", "loom", map[string]interface{}{"status": "closed"})
+
+	var rec received
+	select {
+	case rec = <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+
+	if rec.event != EventBeadClosed {
+		t.Errorf("X-Loom-Event = %q, want %q", rec.event, EventBeadClosed)
+	}
+	if rec.signature != Sign("s3cret", rec.body) {
+		t.Errorf("signature %q does not match body", rec.signature)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(rec.body, &payload); err != nil || payload["type"] != EventBeadClosed {
+		t.Errorf("payload = %s", rec.body)
+	}
+
+	m.wg.Wait()
+	deliveries, err := m.Deliveries(hook.ID, 10)
+	if err != nil {
+		t.Fatalf("Deliveries failed: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2 (one failure, one retry)", len(deliveries))
+	}
+	attempts := map[int]bool{}
+	for _, d := range deliveries {
+		attempts[d.Attempt] = d.Success
+	}
+	if attempts[1] || !attempts[2] {
+		t.Errorf("attempt outcomes = %v, want 1 failed and 2 succeeded", attempts)
+	}
+}
+
+func TestManager_CRUD(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	if _, err := m.Create(CreateRequest{Name: "bad", URL: "ftp://example.com", EventTypes: []string{"*"}}, ""); err == nil {
+		t.Error("expected error for non-http URL")
+	}
+	if _, err := m.Create(CreateRequest{Name: "none", URL: "http://example.com"}, ""); err == nil {
+		t.Error("expected error without event types")
+	}
+
+	hook, err := m.Create(CreateRequest{Name: "all", URL: "http://example.com/hook", EventTypes: []string{"*", "*"}}, "")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(hook.EventTypes) != 1 || !hook.Active {
+		t.Errorf("hook = %+v", hook)
+	}
+
+	inactive := false
+	updated, err := m.Update(hook.ID, UpdateRequest{Active: &inactive, EventTypes: []string{"agent.stuck"}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Active || updated.EventTypes[0] != "agent.stuck" {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	hooks, _ := m.List()
+	if len(hooks) != 1 {
+		t.Errorf("List returned %d hooks, want 1", len(hooks))
+	}
+
+	if err := m.Delete(hook.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := m.Get(hook.ID); err == nil {
+		t.Error("expected ErrNotFound after delete")
+	}
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+// Context window management for the action loop. Before each turn the loop
+// estimates the conversation's size; once it nears the model's context
+// window, the older turns are summarized and replaced by the summary, so
+// long-running beads keep going instead of failing mid-loop.
+const (
+	// SummarizeThreshold is the fraction of the context window at which
+	// older turns are summarized. It sits below TokenLimitHeadroom so
+	// summarizing comes before truncation.
+	SummarizeThreshold = 0.7
+	// keepRecentMessages is how many of the latest messages stay verbatim.
+	keepRecentMessages = 6
+	// maxTranscriptMessageChars caps each message in the transcript handed
+	// to the summarizer, so the summarizer's own context isn't overrun.
+	maxTranscriptMessageChars = 2000
+	// SummaryMetadataKey is the conversation context metadata key the
+	// latest summary is stored under.
+	SummaryMetadataKey = "summary"
+	summaryPrefix      = "[Summary of earlier work on this task]\n"
+)
+
+const summarizerPrompt = `You condense the history of an autonomous coding agent working on a task.
+Summarize the transcript below so the agent can continue without it. Keep:
+- files read, created, or changed, and why
+- commands run and whether they passed
+- decisions made and approaches that failed
+- what remains to be done
+Be specific (paths, names, errors) and brief: at most 300 words. Reply with the summary only.`
+
+// Summarizer condenses a transcript of earlier conversation into a summary.
+type Summarizer func(ctx context.Context, transcript string) (string, error)
+
+// NewRegistrySummarizer returns a Summarizer that uses the given provider
+// and model, typically a cheap one. An empty model uses the provider's.
+func NewRegistrySummarizer(registry *provider.Registry, providerID, model string) Summarizer {
+	return func(ctx context.Context, transcript string) (string, error) {
+		resp, err := registry.SendChatCompletion(ctx, providerID, summaryRequest(model, transcript))
+		return summaryFromResponse(resp, err)
+	}
+}
+
+// summarizeWithOwnProvider is the default Summarizer: the worker's own
+// provider and model.
+func (w *Worker) summarizeWithOwnProvider(ctx context.Context, transcript string) (string, error) {
+	resp, err := w.provider.Protocol.CreateChatCompletion(ctx, summaryRequest(w.provider.Config.Model, transcript))
+	return summaryFromResponse(resp, err)
+}
+
+func summaryRequest(model, transcript string) *provider.ChatCompletionRequest {
+	return &provider.ChatCompletionRequest{
+		Model: model,
+		Messages: []provider.ChatMessage{
+			{Role: "system", Content: summarizerPrompt},
+			{Role: "user", Content: transcript},
+		},
+		Temperature: 0,
+		MaxTokens:   1024,
+	}
+}
+
+func summaryFromResponse(resp *provider.ChatCompletionResponse, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("summarizer returned no summary")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// EstimateTokens roughly counts the tokens messages take up, at
+// CharToTokenRatio characters per token plus a few per message for roles
+// and separators.
+func EstimateTokens(messages []provider.ChatMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += len(msg.Content)/CharToTokenRatio + 4
+	}
+	return total
+}
+
+// compactMessages summarizes the older turns of messages once they take up
+// more than SummarizeThreshold of limit tokens. It keeps the system prompt
+// and the latest turns, starting at a user message, and puts the summary
+// between them. It returns the summary, or "" when nothing needed doing.
+func compactMessages(ctx context.Context, messages []provider.ChatMessage, limit int, summarize Summarizer) ([]provider.ChatMessage, string, error) {
+	if EstimateTokens(messages) <= int(float64(limit)*SummarizeThreshold) {
+		return messages, "", nil
+	}
+	start := len(messages) - keepRecentMessages
+	for start < len(messages) && messages[start].Role != "user" {
+		start++
+	}
+	// Only the system prompt precedes the recent turns: nothing to
+	// summarize, leave it to truncation.
+	if start <= 2 || start >= len(messages) {
+		return messages, "", nil
+	}
+
+	var transcript strings.Builder
+	for _, msg := range messages[1:start] {
+		content := msg.Content
+		if len(content) > maxTranscriptMessageChars {
+			content = content[:maxTranscriptMessageChars] + " [...]"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", strings.ToUpper(msg.Role), content)
+	}
+	summary, err := summarize(ctx, transcript.String())
+	if err != nil {
+		return messages, "", err
+	}
+
+	compacted := make([]provider.ChatMessage, 0, len(messages)-start+2)
+	compacted = append(compacted, messages[0], provider.ChatMessage{Role: "system", Content: summaryPrefix + summary})
+	compacted = append(compacted, messages[start:]...)
+	return compacted, summary, nil
+}
+
+// storeSummary replaces the conversation's history with the compacted
+// messages and records the summary, so a resumed session starts from it.
+func storeSummary(conversationCtx *models.ConversationContext, compacted []provider.ChatMessage, summary string) {
+	if conversationCtx == nil {
+		return
+	}
+	history := make([]models.ChatMessage, 0, len(compacted))
+	tokens := 0
+	for _, msg := range compacted {
+		n := len(msg.Content) / CharToTokenRatio
+		history = append(history, models.ChatMessage{Role: msg.Role, Content: msg.Content, TokenCount: n})
+		tokens += n
+	}
+	conversationCtx.Messages = history
+	conversationCtx.TokenCount = tokens
+	if conversationCtx.Metadata == nil {
+		conversationCtx.Metadata = make(map[string]string)
+	}
+	conversationCtx.Metadata[SummaryMetadataKey] = summary
+}
+
+// fitContext summarizes the loop's older turns when the conversation nears
+// the context window. If summarizing fails the messages are returned as
+// they are and truncation takes over.
+func (w *Worker) fitContext(ctx context.Context, messages []provider.ChatMessage, config *LoopConfig, conversationCtx *models.ConversationContext) []provider.ChatMessage {
+	summarize := config.Summarizer
+	if summarize == nil {
+		summarize = w.summarizeWithOwnProvider
+	}
+	compacted, summary, err := compactMessages(ctx, messages, w.getModelTokenLimit(), summarize)
+	if err != nil {
+		log.Printf("[ActionLoop] Summarizing conversation failed, falling back to truncation: %v", err)
+		return messages
+	}
+	if summary == "" {
+		return messages
+	}
+	log.Printf("[ActionLoop] Summarized %d older messages to stay within the context window", len(messages)-len(compacted)+1)
+	storeSummary(conversationCtx, compacted, summary)
+	return compacted
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/pkg/models"
+)
+
+func longConversation(turns int) []provider.ChatMessage {
+	messages := []provider.ChatMessage{{Role: "system", Content: "you are an agent"}}
+	for i := 0; i < turns; i++ {
+		messages = append(messages,
+			provider.ChatMessage{Role: "user", Content: strings.Repeat("u", 400)},
+			provider.ChatMessage{Role: "assistant", Content: strings.Repeat("a", 400)},
+		)
+	}
+	return messages
+}
+
+func TestCompactMessages(t *testing.T) {
+	messages := longConversation(10) // ~2000 tokens
+	var transcript string
+	summarize := func(_ context.Context, s string) (string, error) {
+		transcript = s
+		return "read main.go, tests pass", nil
+	}
+
+	// Well within the window: left alone.
+	if got, summary, err := compactMessages(context.Background(), messages, 100000, summarize); err != nil || summary != "" || len(got) != len(messages) {
+		t.Fatalf("small conversation compacted: %d messages, summary %q, err %v", len(got), summary, err)
+	}
+
+	got, summary, err := compactMessages(context.Background(), messages, 2000, summarize)
+	if err != nil || summary != "read main.go, tests pass" {
+		t.Fatalf("summary %q, err %v", summary, err)
+	}
+	if len(got) != 2+keepRecentMessages {
+		t.Fatalf("compacted to %d messages, want system + summary + %d recent", len(got), keepRecentMessages)
+	}
+	if got[0].Content != "you are an agent" || !strings.Contains(got[1].Content, summary) || got[2].Role != "user" {
+		t.Errorf("unexpected layout: %+v", got[:3])
+	}
+	if !strings.HasPrefix(transcript, "USER: ") || strings.Contains(transcript, "you are an agent") {
+		t.Errorf("transcript should hold the older turns only: %.60q", transcript)
+	}
+
+	// A failing summarizer leaves the messages for truncation to handle.
+	failing := func(context.Context, string) (string, error) { return "", errors.New("down") }
+	if got, _, err := compactMessages(context.Background(), messages, 2000, failing); err == nil || len(got) != len(messages) {
+		t.Errorf("failed summary: %d messages, err %v", len(got), err)
+	}
+}
+
+func TestFitContextStoresSummary(t *testing.T) {
+	rp := &provider.RegisteredProvider{
+		Config:   &provider.ProviderConfig{ID: "p1", Model: "m", ContextWindow: 2000},
+		Protocol: &sequenceMockProvider{responses: []string{"the story so far"}},
+	}
+	w := NewWorker("w1", &models.Agent{ID: "a1", Name: "Agent"}, rp)
+	conv := models.NewConversationContext("s1", "bd-1", "proj", 0)
+
+	got := w.fitContext(context.Background(), longConversation(10), &LoopConfig{}, conv)
+	if len(got) != 2+keepRecentMessages {
+		t.Fatalf("fitContext kept %d messages", len(got))
+	}
+	if conv.Metadata[SummaryMetadataKey] != "the story so far" || len(conv.Messages) != len(got) {
+		t.Errorf("conversation context not updated: summary %q, %d messages", conv.Metadata[SummaryMetadataKey], len(conv.Messages))
+	}
+}
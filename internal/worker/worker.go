@@ -4,19 +4,25 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/analytics"
 	"github.com/jordanhubbard/loom/internal/database"
 	"github.com/jordanhubbard/loom/internal/memory"
 	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Worker return w.statuspresents an agent worker that processes tasks
@@ -26,6 +32,7 @@ type Worker struct {
 	provider    *provider.RegisteredProvider
 	db          *database.Database
 	textMode    bool // Use simple text-based actions instead of JSON
+	callGate    CallGate
 	status      WorkerStatus
 	currentTask string
 	startedAt   time.Time
@@ -35,6 +42,10 @@ type Worker struct {
 	mu          sync.RWMutex
 }
 
+// CallGate is waited on before each model call. It returns the function
+// to call once the model has answered, or an error to abandon the call.
+type CallGate func(ctx context.Context) (release func(), err error)
+
 // WorkerStatus represents the status of a worker
 type WorkerStatus string
 
@@ -99,6 +110,14 @@ func (w *Worker) SetDatabase(db *database.Database) {
 	w.db = db
 }
 
+// SetCallGate sets what limits how many of the worker's model calls run at
+// once alongside other workers'.
+func (w *Worker) SetCallGate(gate CallGate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callGate = gate
+}
+
 // ExecuteTask executes a task using the agent's persona and provider
 // Supports multi-turn conversations when ConversationSession is provided or database is available
 func (w *Worker) ExecuteTask(ctx context.Context, task *Task) (*TaskResult, error) {
@@ -190,7 +209,7 @@ func (w *Worker) ExecuteTask(ctx context.Context, task *Task) (*TaskResult, erro
 	}
 
 	// Send request to provider (with automatic context-length retry)
-	resp, usedMessages, err := w.callWithContextRetry(ctx, req)
+	resp, usedMessages, err := w.callWithContextRetry(ctx, req, outputFunc(task, 1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completion: %w. Please check provider credentials and network connectivity.", err)
 	}
@@ -399,6 +418,69 @@ func truncateMessages(messages []provider.ChatMessage, fraction float64) []provi
 	return result
 }
 
+// outputFunc returns the partial-output callback for one turn of a task, or
+// nil when the task does not want partial output.
+func outputFunc(task *Task, turn int) func(string) {
+	if task == nil || task.OnOutput == nil {
+		return nil
+	}
+	return func(delta string) { task.OnOutput(turn, delta) }
+}
+
+// complete sends one completion request. When onOutput is set and the
+// provider can stream, the response is streamed and each content delta is
+// passed to onOutput as it arrives. The mock provider is never streamed: it
+// simulates network latency per chunk, which would make every turn crawl.
+//
+// Each request is recorded as an "llm.chat_completion" span with the model,
+// token usage and latency, so it shows up in the trace of the bead it runs for.
+func (w *Worker) complete(ctx context.Context, req *provider.ChatCompletionRequest, onOutput func(string)) (*provider.ChatCompletionResponse, error) {
+	ctx, span := telemetry.Tracer.Start(ctx, "llm.chat_completion")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("llm.model", req.Model),
+		attribute.Int("llm.messages", len(req.Messages)),
+	)
+	if w.provider.Config != nil {
+		span.SetAttributes(attribute.String("llm.provider", w.provider.Config.ID))
+	}
+
+	w.mu.RLock()
+	gate := w.callGate
+	w.mu.RUnlock()
+	if gate != nil {
+		release, err := gate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	started := time.Now()
+	resp, err := w.sendCompletion(ctx, req, onOutput)
+	span.SetAttributes(attribute.Int64("llm.latency_ms", time.Since(started).Milliseconds()))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Int("llm.prompt_tokens", resp.Usage.PromptTokens),
+		attribute.Int("llm.completion_tokens", resp.Usage.CompletionTokens),
+		attribute.Int("llm.total_tokens", resp.Usage.TotalTokens),
+	)
+	return resp, nil
+}
+
+func (w *Worker) sendCompletion(ctx context.Context, req *provider.ChatCompletionRequest, onOutput func(string)) (*provider.ChatCompletionResponse, error) {
+	if onOutput != nil && (w.provider.Config == nil || w.provider.Config.Type != "mock") {
+		if sp, ok := w.provider.Protocol.(provider.StreamingProtocol); ok {
+			return provider.CollectStream(ctx, sp, req, onOutput)
+		}
+	}
+	return w.provider.Protocol.CreateChatCompletion(ctx, req)
+}
+
 // callWithContextRetry calls CreateChatCompletion and retries with
 // progressively smaller message windows on ContextLengthError.
 // Returns the response and the final messages used (which may be truncated).
@@ -406,12 +488,12 @@ func isTemporaryError(err error) bool {
 	return strings.Contains(err.Error(), "502") || strings.Contains(err.Error(), "503")
 }
 
-func (w *Worker) callWithContextRetry(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, []provider.ChatMessage, error) {
+func (w *Worker) callWithContextRetry(ctx context.Context, req *provider.ChatCompletionRequest, onOutput func(string)) (*provider.ChatCompletionResponse, []provider.ChatMessage, error) {
 	// Attempt 1: use messages as-is
 	var resp *provider.ChatCompletionResponse
 	var err error
 	for retries := 0; retries < 3; retries++ {
-		resp, err = w.provider.Protocol.CreateChatCompletion(ctx, req)
+		resp, err = w.complete(ctx, req, onOutput)
 		if err == nil {
 			break
 		}
@@ -424,11 +506,17 @@ func (w *Worker) callWithContextRetry(ctx context.Context, req *provider.ChatCom
 		}
 	}
 	if err == nil {
+		w.provider.ReportResult(nil)
 		return resp, req.Messages, nil
 	}
 
 	var ctxErr *provider.ContextLengthError
 	if !errors.As(err, &ctxErr) {
+		// Feed the provider's circuit breaker; cancellations are not the
+		// provider's fault.
+		if ctx.Err() == nil {
+			w.provider.ReportResult(err)
+		}
 		return nil, req.Messages, err
 	}
 
@@ -445,7 +533,7 @@ func (w *Worker) callWithContextRetry(ctx context.Context, req *provider.ChatCom
 		retryReq := *req
 		retryReq.Messages = truncated
 
-		resp, err = w.provider.Protocol.CreateChatCompletion(ctx, &retryReq)
+		resp, err = w.complete(ctx, &retryReq, onOutput)
 		if err == nil {
 			return resp, truncated, nil
 		}
@@ -466,7 +554,7 @@ func (w *Worker) callWithContextRetry(ctx context.Context, req *provider.ChatCom
 
 			retryReq := *req
 			retryReq.Messages = minimal
-			resp, err = w.provider.Protocol.CreateChatCompletion(ctx, &retryReq)
+			resp, err = w.complete(ctx, &retryReq, onOutput)
 			if err == nil {
 				return resp, minimal, nil
 			}
@@ -495,7 +583,7 @@ func (w *Worker) buildSystemPrompt() string {
 	if w.textMode {
 		prompt = actions.SimpleJSONPrompt + "\n\n"
 	} else {
-		prompt = actions.ActionPrompt + "\n\n"
+		prompt = actions.CurrentActionPrompt() + "\n\n"
 	}
 
 	// 2. Brief persona role context
@@ -521,8 +609,13 @@ func (w *Worker) buildSystemPrompt() string {
 // responseFormat returns the ResponseFormat for LLM requests.
 // Local vLLM servers support response_format: json_object for constrained
 // decoding. Cloud/litellm proxies often choke on it, so we skip it when the
-// provider endpoint is not a local address.
+// provider endpoint is not a local address. The native Anthropic, Gemini, and
+// Bedrock protocols map it onto their own structured-output features.
 func (w *Worker) responseFormat() *provider.ResponseFormat {
+	switch w.provider.Config.Type {
+	case "anthropic", "gemini", "bedrock":
+		return &provider.ResponseFormat{Type: "json_object"}
+	}
 	ep := w.provider.Config.Endpoint
 	if strings.Contains(ep, "localhost") ||
 		strings.Contains(ep, "127.0.0.1") ||
@@ -570,6 +663,11 @@ type Task struct {
 	BeadID              string
 	ProjectID           string
 	ConversationSession *models.ConversationContext // Optional: enables multi-turn conversation
+	// OnOutput, if set, receives the model's output as it is generated: the
+	// turn number (1-based; action loops make one call per turn) and the
+	// next piece of text. Setting it makes the worker stream responses from
+	// providers that support streaming.
+	OnOutput func(turn int, delta string)
 }
 
 // TaskResult represents the result of task execution
@@ -619,13 +717,36 @@ type LoopConfig struct {
 	// OnProgress is called after each successful iteration so the caller can
 	// update heartbeat timestamps and prevent stuck-agent timeouts on long tasks.
 	OnProgress func()
+	// OnIteration, if set, receives each iteration's actions and results as
+	// soon as they are logged, for live views of what the agent is doing.
+	OnIteration func(entry ActionLogEntry)
+	// OnUsage, if set, receives the token usage of each model call, so it
+	// can be attributed to the bead and the actions it produced.
+	OnUsage func(usage CompletionUsage)
+	// Summarizer condenses older turns when the conversation nears the
+	// context window. Nil uses the worker's own provider and model.
+	Summarizer Summarizer
+}
+
+// CompletionUsage is the token usage of one model call in the action loop.
+// See RequestLog for turning it into an analytics record.
+type CompletionUsage struct {
+	Iteration        int
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	Latency          time.Duration
+	// ActionTypes are the actions the response asked for, in order. It is
+	// empty when the response couldn't be parsed.
+	ActionTypes []string
 }
 
 // LoopResult contains the result of a multi-turn action loop.
 type LoopResult struct {
 	*TaskResult
 	Iterations     int                    `json:"iterations"`
-	TerminalReason string                 `json:"terminal_reason"` // "completed", "max_iterations", "escalated", "error", "no_actions", "parse_failures", "progress_stagnant"
+	TerminalReason string                 `json:"terminal_reason"` // "completed", "max_iterations", "escalated", "error", "no_actions", "parse_failures", "progress_stagnant", "context_canceled"
 	ActionLog      []ActionLogEntry       `json:"action_log"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"` // For progress metrics and remediation analysis
 }
@@ -729,6 +850,12 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 	// Build system prompt with lessons
 	systemPrompt := w.buildEnhancedSystemPrompt(config.LessonsProvider, task.ProjectID, task.Context)
 
+	// A checkpoint left by a loop that never finished is resumed.
+	var resume *models.LoopState
+	if conversationCtx != nil && conversationCtx.LoopState != nil && conversationCtx.LoopState.Iteration < maxIter {
+		resume = conversationCtx.LoopState
+	}
+
 	if conversationCtx != nil {
 		if len(conversationCtx.Messages) == 0 {
 			conversationCtx.AddMessage("system", systemPrompt, len(systemPrompt)/4)
@@ -740,6 +867,9 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 		if task.Context != "" {
 			userPrompt = fmt.Sprintf("%s\n\nContext:\n%s", userPrompt, task.Context)
 		}
+		if resume != nil {
+			userPrompt += resumeNote(resume, maxIter)
+		}
 		messages = append(messages, provider.ChatMessage{Role: "user", Content: userPrompt})
 	} else {
 		userPrompt := task.Description
@@ -769,8 +899,69 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 	actionHashes := make(map[string]int)    // for inner loop detection
 	actionTypeCount := make(map[string]int) // for progress stagnation detection
 	treePaths := make(map[string]int)       // track repeated scope/tree calls per path
+	var filesChanged []string
+
+	start := 0
+	if resume != nil {
+		start = resume.Iteration
+		loopResult.TokensUsed = resume.TokensUsed
+		if len(resume.ActionLog) > 0 {
+			if err := json.Unmarshal(resume.ActionLog, &loopResult.ActionLog); err != nil {
+				log.Printf("[ActionLoop] Warning: Failed to restore action log: %v", err)
+			}
+		}
+		for _, entry := range loopResult.ActionLog {
+			allActions = append(allActions, entry.Results...)
+		}
+		for k, v := range resume.ActionHashes {
+			actionHashes[k] = v
+		}
+		for k, v := range resume.ActionTypeCount {
+			actionTypeCount[k] = v
+		}
+		filesChanged = resume.FilesChanged
+		log.Printf("[ActionLoop] Resuming task %s for bead %s at iteration %d/%d", task.ID, task.BeadID, start+1, maxIter)
+	}
 
-	for iteration := 0; iteration < maxIter; iteration++ {
+	// checkpoint saves the conversation with how far the loop got, in one
+	// write, before each iteration.
+	checkpoint := func(iteration int) {
+		if conversationCtx == nil || config.DB == nil {
+			return
+		}
+		logTail := loopResult.ActionLog
+		if len(logTail) > maxCheckpointedIterations {
+			logTail = logTail[len(logTail)-maxCheckpointedIterations:]
+		}
+		state := &models.LoopState{
+			Iteration:       iteration,
+			TokensUsed:      loopResult.TokensUsed,
+			FilesChanged:    filesChanged,
+			ActionHashes:    actionHashes,
+			ActionTypeCount: actionTypeCount,
+			UpdatedAt:       time.Now().UTC(),
+		}
+		if data, err := json.Marshal(logTail); err == nil {
+			state.ActionLog = data
+		}
+		conversationCtx.LoopState = state
+		persistConversation(config, conversationCtx)
+	}
+	// A loop that ends for good drops its checkpoint. One interrupted or
+	// cut short by an error keeps the last, to resume from on the next run.
+	defer func() {
+		if conversationCtx == nil {
+			return
+		}
+		switch loopResult.TerminalReason {
+		case "context_canceled", "error":
+		default:
+			conversationCtx.LoopState = nil
+		}
+		persistConversation(config, conversationCtx)
+	}()
+
+	for iteration := start; iteration < maxIter; iteration++ {
 		select {
 		case <-ctx.Done():
 			loopResult.TerminalReason = "context_canceled"
@@ -780,8 +971,13 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 			return loopResult, ctx.Err()
 		default:
 		}
+		if iteration > start {
+			checkpoint(iteration)
+		}
 
-		// Handle token limits
+		// Summarize older turns when nearing the context window, then
+		// truncate as a last resort.
+		messages = w.fitContext(ctx, messages, config, conversationCtx)
 		trimmedMessages := w.handleTokenLimits(messages)
 
 		// Cap max tokens: text mode produces a single compact JSON action,
@@ -800,7 +996,16 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 
 		log.Printf("[ActionLoop] Iteration %d/%d for task %s (messages: %d, textMode: %v)", iteration+1, maxIter, task.ID, len(trimmedMessages), config.TextMode)
 
-		resp, usedMsgs, err := w.callWithContextRetry(ctx, req)
+		callStart := time.Now()
+		resp, usedMsgs, err := w.callWithContextRetry(ctx, req, outputFunc(task, iteration+1))
+		if err != nil && ctx.Err() != nil {
+			// Interrupted mid-call: the last turn is the one to resume from.
+			loopResult.TerminalReason = "context_canceled"
+			loopResult.Iterations = iteration
+			loopResult.Actions = allActions
+			loopResult.CompletedAt = time.Now()
+			return loopResult, ctx.Err()
+		}
 		if err != nil {
 			loopResult.TerminalReason = "error"
 			loopResult.Iterations = iteration + 1
@@ -844,6 +1049,25 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 		} else {
 			env, parseErr = actions.DecodeLenient([]byte(llmResponse))
 		}
+		if config.OnUsage != nil {
+			usage := CompletionUsage{
+				Iteration:        iteration + 1,
+				Model:            resp.Model,
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+				TotalTokens:      resp.Usage.TotalTokens,
+				Latency:          time.Since(callStart),
+			}
+			if usage.Model == "" {
+				usage.Model = req.Model
+			}
+			if parseErr == nil {
+				for _, act := range env.Actions {
+					usage.ActionTypes = append(usage.ActionTypes, act.Type)
+				}
+			}
+			config.OnUsage(usage)
+		}
 		if parseErr != nil {
 			var validationErr *actions.ValidationError
 			if errors.As(parseErr, &validationErr) {
@@ -955,6 +1179,9 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 					switch act.Type {
 					case actions.ActionWriteFile, actions.ActionEditCode, actions.ActionApplyPatch:
 						needsCheckpoint = true
+						if act.Path != "" && !slices.Contains(filesChanged, act.Path) {
+							filesChanged = append(filesChanged, act.Path)
+						}
 					}
 				}
 			}
@@ -988,12 +1215,16 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 		}
 
 		// Log the iteration
-		loopResult.ActionLog = append(loopResult.ActionLog, ActionLogEntry{
+		entry := ActionLogEntry{
 			Iteration: iteration + 1,
 			Actions:   env.Actions,
 			Results:   results,
 			Timestamp: time.Now(),
-		})
+		}
+		loopResult.ActionLog = append(loopResult.ActionLog, entry)
+		if config.OnIteration != nil {
+			config.OnIteration(entry)
+		}
 
 		// Notify caller that progress was made so heartbeat timestamps can be updated.
 		if config.OnProgress != nil {
@@ -1107,12 +1338,6 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 			conversationCtx.AddMessage("user", feedback, len(feedback)/4)
 		}
 
-		// Persist conversation context periodically
-		if conversationCtx != nil && config.DB != nil && (iteration%3 == 2 || iteration == maxIter-1) {
-			if err := config.DB.UpdateConversationContext(conversationCtx); err != nil {
-				log.Printf("[ActionLoop] Warning: Failed to persist conversation: %v", err)
-			}
-		}
 	}
 
 	// If we exhausted iterations without terminal condition
@@ -1132,14 +1357,32 @@ func (w *Worker) ExecuteTaskWithLoop(ctx context.Context, task *Task, config *Lo
 		}
 	}
 
-	// Final persist
-	if conversationCtx != nil && config.DB != nil {
-		if err := config.DB.UpdateConversationContext(conversationCtx); err != nil {
-			log.Printf("[ActionLoop] Warning: Failed to persist final conversation: %v", err)
-		}
+	return loopResult, nil
+}
+
+// maxCheckpointedIterations caps the action log kept in a loop checkpoint.
+const maxCheckpointedIterations = 20
+
+// resumeNote tells the model its loop is picking up where a previous run
+// stopped.
+func resumeNote(state *models.LoopState, maxIter int) string {
+	note := fmt.Sprintf("\n\n## Resuming\n\nYour previous run on this bead stopped after iteration %d of %d "+
+		"when loom or its container restarted. The conversation above is where you left off. "+
+		"Continue from there; do not start over.\n", state.Iteration, maxIter)
+	if len(state.FilesChanged) > 0 {
+		note += "Files you have changed so far: " + strings.Join(state.FilesChanged, ", ") + "\n"
 	}
+	return note
+}
 
-	return loopResult, nil
+// persistConversation saves the loop's conversation, if it keeps one.
+func persistConversation(config *LoopConfig, conversationCtx *models.ConversationContext) {
+	if conversationCtx == nil || config.DB == nil {
+		return
+	}
+	if err := config.DB.UpdateConversationContext(conversationCtx); err != nil {
+		log.Printf("[ActionLoop] Warning: Failed to persist conversation: %v", err)
+	}
 }
 
 // buildEnhancedSystemPrompt builds the system prompt with ReAct operating model first,
@@ -1310,3 +1553,34 @@ func hashActions(acts []actions.Action) string {
 	h := sha256.Sum256([]byte(sb.String()))
 	return hex.EncodeToString(h[:8])
 }
+
+// RequestLog returns the analytics record of the call, attributed to the
+// agent, bead, and project in actx and to the first action the response
+// asked for. All of the response's actions are listed in the metadata.
+func (u CompletionUsage) RequestLog(userID, providerID string, actx actions.ActionContext) *analytics.RequestLog {
+	rl := &analytics.RequestLog{
+		UserID:           userID,
+		Method:           "POST",
+		Path:             analytics.CompletionPath,
+		ProviderID:       providerID,
+		ModelName:        u.Model,
+		PromptTokens:     int64(u.PromptTokens),
+		CompletionTokens: int64(u.CompletionTokens),
+		TotalTokens:      int64(u.TotalTokens),
+		LatencyMs:        u.Latency.Milliseconds(),
+		StatusCode:       200,
+		BeadID:           actx.BeadID,
+		AgentID:          actx.AgentID,
+		ProjectID:        actx.ProjectID,
+		Metadata: map[string]string{
+			"iteration": fmt.Sprintf("%d", u.Iteration),
+		},
+	}
+	if len(u.ActionTypes) > 0 {
+		rl.ActionType = u.ActionTypes[0]
+		rl.Metadata["actions"] = strings.Join(u.ActionTypes, ",")
+	} else {
+		rl.ActionType = "unparsed"
+	}
+	return rl
+}
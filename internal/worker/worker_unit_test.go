@@ -2,13 +2,18 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jordanhubbard/loom/internal/actions"
+	"github.com/jordanhubbard/loom/internal/analytics"
 	"github.com/jordanhubbard/loom/internal/provider"
+	"github.com/jordanhubbard/loom/internal/telemetry"
 	"github.com/jordanhubbard/loom/pkg/models"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func makeTestWorker(persona *models.Persona) *Worker {
@@ -684,6 +689,57 @@ func TestWorker_ExecuteTaskWithLoop_DoneAction(t *testing.T) {
 	}
 }
 
+func TestWorker_ExecuteTaskWithLoop_Traced(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevTracer := telemetry.Tracer
+	telemetry.Tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	defer func() { telemetry.Tracer = prevTracer }()
+
+	mock := &sequenceMockProvider{
+		responses: []string{`{"action": "done", "reason": "task completed"}`},
+	}
+	rp := &provider.RegisteredProvider{
+		Config:   &provider.ProviderConfig{ID: "p1", Name: "P", Model: "m"},
+		Protocol: mock,
+	}
+	w := NewWorker("w1", &models.Agent{ID: "a1", Name: "Agent"}, rp)
+	_ = w.Start()
+
+	ctx, root := telemetry.Tracer.Start(context.Background(), "bead")
+	task := &Task{ID: "t1", Description: "do something"}
+	config := &LoopConfig{
+		MaxIterations: 5,
+		Router:        &actions.Router{},
+		ActionContext: actions.ActionContext{ProjectID: "p1", BeadID: "b1"},
+		TextMode:      true,
+	}
+	if _, err := w.ExecuteTaskWithLoop(ctx, task, config); err != nil {
+		t.Fatalf("ExecuteTaskWithLoop error = %v", err)
+	}
+	root.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	for _, name := range []string{"llm.chat_completion", "action.done"} {
+		s, ok := spans[name]
+		if !ok {
+			t.Fatalf("no %s span recorded; got %v", name, spans)
+		}
+		if s.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("%s span is not a child of the bead span", name)
+		}
+	}
+	attrs := map[string]int64{}
+	for _, kv := range spans["llm.chat_completion"].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInt64()
+	}
+	if attrs["llm.prompt_tokens"] != 50 || attrs["llm.completion_tokens"] != 20 {
+		t.Errorf("token attributes = %v, want 50 prompt and 20 completion", attrs)
+	}
+}
+
 func TestWorker_ExecuteTaskWithLoop_ParseFailure(t *testing.T) {
 	mock := &sequenceMockProvider{
 		responses: []string{
@@ -882,3 +938,73 @@ func TestWorker_ExecuteTaskWithLoop_DefaultMaxIterations(t *testing.T) {
 		t.Errorf("TerminalReason = %q, want completed", result.TerminalReason)
 	}
 }
+
+func TestWorker_ExecuteTaskWithLoop_OnUsage(t *testing.T) {
+	mock := &sequenceMockProvider{
+		responses: []string{`{"action": "done", "reason": "done"}`},
+	}
+	rp := &provider.RegisteredProvider{
+		Config:   &provider.ProviderConfig{ID: "p1", Name: "P", Model: "m"},
+		Protocol: mock,
+	}
+	w := NewWorker("w1", &models.Agent{ID: "a1", Name: "Agent"}, rp)
+	_ = w.Start()
+
+	var usages []CompletionUsage
+	config := &LoopConfig{
+		Router:        &actions.Router{},
+		TextMode:      true,
+		ActionContext: actions.ActionContext{AgentID: "a1", BeadID: "bd-1", ProjectID: "proj"},
+		OnUsage:       func(u CompletionUsage) { usages = append(usages, u) },
+	}
+	if _, err := w.ExecuteTaskWithLoop(context.Background(), &Task{ID: "t1", Description: "do something"}, config); err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if len(usages) != 1 || usages[0].Iteration != 1 || strings.Join(usages[0].ActionTypes, ",") != "done" {
+		t.Fatalf("usages = %+v, want one call that asked for done", usages)
+	}
+
+	rl := usages[0].RequestLog("agent:Agent", "p1", config.ActionContext)
+	if rl.BeadID != "bd-1" || rl.AgentID != "a1" || rl.ProjectID != "proj" || rl.ActionType != "done" || rl.Path != analytics.CompletionPath {
+		t.Errorf("request log = %+v", rl)
+	}
+}
+
+func TestCompleteWaitsOnCallGate(t *testing.T) {
+	rp := &provider.RegisteredProvider{
+		Config:   &provider.ProviderConfig{ID: "p1", Model: "m"},
+		Protocol: &sequenceMockProvider{responses: []string{"ok"}},
+	}
+	w := NewWorker("w1", &models.Agent{ID: "a1", Name: "Agent"}, rp)
+	req := &provider.ChatCompletionRequest{Model: "m", Messages: []provider.ChatMessage{{Role: "user", Content: "hi"}}}
+
+	acquired, released := 0, 0
+	w.SetCallGate(func(context.Context) (func(), error) {
+		acquired++
+		return func() { released++ }, nil
+	})
+	if _, err := w.complete(context.Background(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if acquired != 1 || released != 1 {
+		t.Errorf("gate acquired %d, released %d; want 1 each", acquired, released)
+	}
+
+	// A gate that gives up abandons the call.
+	w.SetCallGate(func(ctx context.Context) (func(), error) { return nil, context.Canceled })
+	if _, err := w.complete(context.Background(), req, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("complete() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestResumeNote(t *testing.T) {
+	note := resumeNote(&models.LoopState{Iteration: 7, FilesChanged: []string{"a.go", "b.go"}}, 100)
+	for _, want := range []string{"## Resuming", "iteration 7 of 100", "a.go, b.go"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("resumeNote() = %q, want it to contain %q", note, want)
+		}
+	}
+	if strings.Contains(resumeNote(&models.LoopState{Iteration: 1}, 10), "Files you have changed") {
+		t.Error("resumeNote() lists files when none changed")
+	}
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Human approval gates: an approval node that lists approvers is not worked
+// by an agent. When an execution reaches it, a decision is opened for the
+// approvers and the execution waits at the node, awaiting approval, until
+// one of them approves or rejects it. The execution then advances along the
+// node's approved or rejected edge, with the approver as the agent in its
+// history.
+
+// ApprovalGates opens the decisions that human approval gates wait on.
+type ApprovalGates interface {
+	// RequestApproval opens a decision on node, a gate of exec's bead,
+	// notifies approvers, and returns the decision's ID.
+	RequestApproval(exec *WorkflowExecution, node *WorkflowNode, approvers []string) (string, error)
+}
+
+// SetApprovalGates sets what opens the decisions of human approval gates.
+// Without it, approval nodes are worked by agents whether or not they list
+// approvers.
+func (e *Engine) SetApprovalGates(g ApprovalGates) {
+	e.approvalGates = g
+}
+
+// Approvers returns the usernames that may decide an approval node, or nil
+// when the node is not a human approval gate.
+func Approvers(node *WorkflowNode) []string {
+	if node.NodeType != NodeTypeApproval {
+		return nil
+	}
+	var approvers []string
+	for _, name := range strings.Split(node.Metadata[ApproversKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			approvers = append(approvers, name)
+		}
+	}
+	return approvers
+}
+
+// MayApprove reports whether user is one of approvers.
+func MayApprove(approvers []string, user string) bool {
+	for _, name := range approvers {
+		if name == "*" || name == user {
+			return true
+		}
+	}
+	return false
+}
+
+// awaitApproval moves an execution to a human approval gate and opens the
+// decision it waits on.
+func (e *Engine) awaitApproval(exec *WorkflowExecution, gate *WorkflowNode, approvers []string) error {
+	exec.CurrentNodeKey = gate.NodeKey
+	exec.Status = ExecutionStatusAwaitingApproval
+	exec.NodeAttemptCount = 0
+	exec.LastNodeAt = time.Now()
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+
+	decisionID, err := e.approvalGates.RequestApproval(exec, gate, approvers)
+	if err != nil {
+		return e.escalateWorkflow(exec, fmt.Sprintf("Could not request approval at %s: %v", gate.NodeKey, err))
+	}
+	e.recordHistory(exec, HistoryApprovalRequested, "system", map[string]string{
+		"decision_id": decisionID,
+		"approvers":   strings.Join(approvers, ","),
+	})
+	e.updateBeadContext(exec, map[string]string{
+		"workflow_node":        gate.NodeKey,
+		"workflow_status":      string(ExecutionStatusAwaitingApproval),
+		"cycle_count":          fmt.Sprintf("%d", exec.CycleCount),
+		"required_role":        gate.RoleRequired,
+		"approval_decision_id": decisionID,
+		"redispatch_requested": "false",
+	})
+
+	log.Printf("[Workflow] Bead %s awaiting approval at %s from %s (decision %s)",
+		exec.BeadID, gate.NodeKey, strings.Join(approvers, ", "), decisionID)
+	return nil
+}
+
+// ResolveApproval settles the approval gate an execution is waiting at:
+// it advances along the gate's approved edge if approved, its rejected edge
+// otherwise, recording approver as the agent.
+func (e *Engine) ResolveApproval(executionID, nodeKey string, approved bool, approver, rationale string) error {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return err
+	}
+	if exec.Status != ExecutionStatusAwaitingApproval || exec.CurrentNodeKey != nodeKey {
+		return fmt.Errorf("workflow execution %s is not awaiting approval at %s", exec.ID, nodeKey)
+	}
+
+	exec.Status = ExecutionStatusActive
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+	condition := EdgeConditionRejected
+	resultData := map[string]string{"rejected_by": approver}
+	if approved {
+		condition = EdgeConditionApproved
+		resultData = map[string]string{"approved_by": approver}
+	}
+	if rationale != "" {
+		resultData["rationale"] = rationale
+	}
+
+	log.Printf("[Workflow] %s %s node %s for bead %s", approver, condition, nodeKey, exec.BeadID)
+	return e.AdvanceWorkflow(exec.ID, condition, approver, resultData)
+}
@@ -0,0 +1,205 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeApprovalGates numbers the decisions it opens and remembers who was
+// asked.
+type fakeApprovalGates struct {
+	requested []string // node keys
+	approvers [][]string
+	err       error
+}
+
+func (f *fakeApprovalGates) RequestApproval(exec *WorkflowExecution, node *WorkflowNode, approvers []string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.requested = append(f.requested, node.NodeKey)
+	f.approvers = append(f.approvers, approvers)
+	return fmt.Sprintf("dec-%d", len(f.requested)), nil
+}
+
+// newApprovalEngine is newControlsEngine with review made a human approval
+// gate for alice and bob.
+func newApprovalEngine(t *testing.T) (*Engine, *mockDatabase, *mockBeadManager, *fakeApprovalGates) {
+	t.Helper()
+	db := newMockDatabase()
+	beads := newMockBeadManager()
+	wf, err := NewWorkflowFromDefinition(&WorkflowDefinition{
+		ID: "wf-a", Name: "Approval", WorkflowType: "bug",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "investigate", NodeType: "task", RoleRequired: "QA", MaxAttempts: 3},
+			{NodeKey: "review", NodeType: "approval", RoleRequired: "PM", Metadata: map[string]string{ApproversKey: "alice, bob"}},
+			{NodeKey: "fix", NodeType: "task", MaxAttempts: 3},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "investigate", Condition: "success"},
+			{FromNodeKey: "investigate", ToNodeKey: "review", Condition: "success"},
+			{FromNodeKey: "review", ToNodeKey: "fix", Condition: "approved"},
+			{FromNodeKey: "review", ToNodeKey: "investigate", Condition: "rejected"},
+			{FromNodeKey: "fix", Condition: "success"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.workflows[wf.ID] = wf
+
+	gates := &fakeApprovalGates{}
+	engine := NewEngine(db, beads)
+	engine.SetApprovalGates(gates)
+	exec, err := engine.StartWorkflow("bead-1", wf.ID, "proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.ID = "exec-1"
+	db.executions["exec-1"] = exec
+	for _, step := range []EdgeCondition{EdgeConditionSuccess, EdgeConditionSuccess} {
+		if err := engine.AdvanceWorkflow("exec-1", step, "agent-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine, db, beads, gates
+}
+
+func TestApprovalGate_Await(t *testing.T) {
+	engine, db, beads, gates := newApprovalEngine(t)
+	exec := engine.mustExec(t, "exec-1")
+
+	if exec.Status != ExecutionStatusAwaitingApproval || exec.CurrentNodeKey != "review" {
+		t.Fatalf("status %s at %q, want awaiting_approval at review", exec.Status, exec.CurrentNodeKey)
+	}
+	if len(gates.requested) != 1 || strings.Join(gates.approvers[0], ",") != "alice,bob" {
+		t.Fatalf("requested %v from %v", gates.requested, gates.approvers)
+	}
+	ctx := beadContext(beads, "bead-1")
+	if ctx["workflow_status"] != "awaiting_approval" || ctx["approval_decision_id"] != "dec-1" || ctx["redispatch_requested"] != "false" {
+		t.Errorf("bead context = %v", ctx)
+	}
+	history := db.history["exec-1"]
+	last := history[len(history)-1]
+	if last.Condition != HistoryApprovalRequested || last.NodeKey != "review" || !strings.Contains(last.ResultData, "dec-1") {
+		t.Errorf("last history = %+v", last)
+	}
+
+	err := engine.AdvanceWorkflow("exec-1", EdgeConditionApproved, "agent-1", nil)
+	if err == nil || !strings.Contains(err.Error(), "awaiting approval") {
+		t.Errorf("agent advance: err = %v, want awaiting approval", err)
+	}
+	if engine.IsNodeReady(exec) {
+		t.Error("execution awaiting approval is ready for an agent")
+	}
+}
+
+func TestApprovalGate_Resolve(t *testing.T) {
+	engine, db, _, _ := newApprovalEngine(t)
+
+	if err := engine.ResolveApproval("exec-1", "fix", true, "alice", ""); err == nil {
+		t.Error("resolved a gate the execution is not at")
+	}
+	if err := engine.ResolveApproval("exec-1", "review", true, "alice", "looks right"); err != nil {
+		t.Fatal(err)
+	}
+	exec := engine.mustExec(t, "exec-1")
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "fix" {
+		t.Fatalf("after approval: status %s at %q, want active at fix", exec.Status, exec.CurrentNodeKey)
+	}
+	history := db.history["exec-1"]
+	last := history[len(history)-1]
+	if last.AgentID != "alice" || last.Condition != EdgeConditionApproved || !strings.Contains(last.ResultData, "looks right") {
+		t.Errorf("last history = %+v", last)
+	}
+	if err := engine.ResolveApproval("exec-1", "review", false, "bob", ""); err == nil {
+		t.Error("resolved the gate twice")
+	}
+
+	// Rolling back to the gate asks its approvers again.
+	exec, err := engine.RollbackExecution("exec-1", "review", "operator", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.Status != ExecutionStatusAwaitingApproval || exec.CurrentNodeKey != "review" {
+		t.Errorf("after rollback: status %s at %q, want awaiting_approval at review", exec.Status, exec.CurrentNodeKey)
+	}
+}
+
+func TestApprovalGate_RejectAndReturn(t *testing.T) {
+	engine, _, _, gates := newApprovalEngine(t)
+
+	if err := engine.ResolveApproval("exec-1", "review", false, "bob", "not yet"); err != nil {
+		t.Fatal(err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.CurrentNodeKey != "investigate" || exec.Status != ExecutionStatusActive {
+		t.Fatalf("after rejection: status %s at %q, want active at investigate", exec.Status, exec.CurrentNodeKey)
+	}
+
+	// Coming back to the gate asks again.
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionSuccess, "agent-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(gates.requested) != 2 {
+		t.Fatalf("requested %d approvals, want 2", len(gates.requested))
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusAwaitingApproval {
+		t.Errorf("back at the gate: status %s, want awaiting_approval", exec.Status)
+	}
+}
+
+func TestApprovalGate_RequestFails(t *testing.T) {
+	engine, _, _, _ := newApprovalEngine(t)
+	engine.SetApprovalGates(&fakeApprovalGates{err: fmt.Errorf("no decisions")})
+
+	// Back to investigate, then forward to the gate with nobody to ask.
+	if err := engine.ResolveApproval("exec-1", "review", false, "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionSuccess, "agent-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusEscalated {
+		t.Errorf("status %s, want escalated", exec.Status)
+	}
+}
+
+func TestApprovalGate_WithoutGates(t *testing.T) {
+	// With no ApprovalGates set, the gate is worked like any approval node.
+	engine, _, _, _ := newApprovalEngine(t)
+	engine.SetApprovalGates(nil)
+
+	if err := engine.ResolveApproval("exec-1", "review", false, "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionSuccess, "agent-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "review" {
+		t.Errorf("status %s at %q, want active at review", exec.Status, exec.CurrentNodeKey)
+	}
+}
+
+func TestApprovers(t *testing.T) {
+	gate := &WorkflowNode{NodeType: NodeTypeApproval, Metadata: map[string]string{ApproversKey: " alice,, bob "}}
+	if got := strings.Join(Approvers(gate), ","); got != "alice,bob" {
+		t.Errorf("Approvers = %q", got)
+	}
+	task := &WorkflowNode{NodeType: NodeTypeTask, Metadata: map[string]string{ApproversKey: "alice"}}
+	if Approvers(task) != nil {
+		t.Error("a task node has approvers")
+	}
+	if !MayApprove([]string{"alice", "bob"}, "bob") || MayApprove([]string{"alice"}, "bob") || !MayApprove([]string{"*"}, "bob") {
+		t.Error("MayApprove")
+	}
+
+	def := &WorkflowDefinition{
+		ID: "wf-x", Name: "X", WorkflowType: "bug",
+		Nodes: []WorkflowNodeDefinition{{NodeKey: "a", NodeType: "task", Metadata: map[string]string{ApproversKey: "alice"}}},
+		Edges: []WorkflowEdgeDefinition{{ToNodeKey: "a", Condition: "success"}, {FromNodeKey: "a", Condition: "success"}},
+	}
+	if _, err := NewWorkflowFromDefinition(def); err == nil || !strings.Contains(err.Error(), "not an approval node") {
+		t.Errorf("approvers on a task node: err = %v", err)
+	}
+}
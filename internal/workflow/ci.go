@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CI gates: a ci node is not worked by an agent. When an execution reaches
+// it, the execution waits at the node, awaiting CI, while the checks run on
+// the branch the bead's agent pushed. SyncCI polls the checks and advances
+// the execution along the node's ci_passed edge once they have all passed,
+// or its ci_failed edge as soon as one fails. A ci node with a timeout
+// leaves by its timeout edge when the checks take too long.
+
+// CIChecksKey is the ci node metadata key listing, comma-separated, the
+// checks the node waits for. Unset means every check on the branch.
+const CIChecksKey = "ci_checks"
+
+// CIState is the combined state of the CI checks a ci node waits for.
+type CIState string
+
+const (
+	CIStatePending CIState = "pending" // Checks still running, or none reported yet
+	CIStatePassed  CIState = "passed"  // Every check passed
+	CIStateFailed  CIState = "failed"  // At least one check failed
+)
+
+// CIResult is what a ci node's checks came to.
+type CIResult struct {
+	State   CIState
+	Branch  string // branch the checks ran on
+	Summary string // which checks passed or failed
+	URL     string // where to read the failing check, if any
+}
+
+// CIChecks reports on the CI checks of the branches workflow beads push.
+type CIChecks interface {
+	// CIStatus reports the combined state of the checks on the branch of
+	// exec's bead. When required is not empty, only those checks count.
+	CIStatus(exec *WorkflowExecution, required []string) (*CIResult, error)
+}
+
+// SetCIChecks sets what reports the CI checks ci nodes wait for. Without
+// it, executions cannot pass a ci node.
+func (e *Engine) SetCIChecks(c CIChecks) {
+	e.ciChecks = c
+}
+
+// RequiredChecks returns the checks a ci node waits for, or nil for all of
+// them.
+func RequiredChecks(node *WorkflowNode) []string {
+	var checks []string
+	for _, name := range strings.Split(node.Metadata[CIChecksKey], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			checks = append(checks, name)
+		}
+	}
+	return checks
+}
+
+// checkCINodes checks that ci_passed and ci_failed edges leave only ci
+// nodes, and that a ci node leaves by a ci_passed edge and otherwise only by
+// ci_failed and timeout edges.
+func checkCINodes(def *WorkflowDefinition) error {
+	types := make(map[string]NodeType, len(def.Nodes))
+	for _, n := range def.Nodes {
+		types[n.NodeKey] = NodeType(n.NodeType)
+	}
+	passes := make(map[string]bool)
+	for _, e := range def.Edges {
+		cond := EdgeCondition(e.Condition)
+		isCIEdge := cond == EdgeConditionCIPassed || cond == EdgeConditionCIFailed
+		if types[e.FromNodeKey] != NodeTypeCI {
+			if isCIEdge {
+				return fmt.Errorf("edge %s -> %s: %s edges may only leave ci nodes", e.FromNodeKey, e.ToNodeKey, cond)
+			}
+			continue
+		}
+		if !isCIEdge && cond != EdgeConditionTimeout {
+			return fmt.Errorf("edge %s -> %s: ci nodes leave only by ci_passed, ci_failed, and timeout edges", e.FromNodeKey, e.ToNodeKey)
+		}
+		if cond == EdgeConditionCIPassed {
+			passes[e.FromNodeKey] = true
+		}
+	}
+	for _, n := range def.Nodes {
+		if NodeType(n.NodeType) == NodeTypeCI && !passes[n.NodeKey] {
+			return fmt.Errorf("ci node %s has no ci_passed edge", n.NodeKey)
+		}
+	}
+	return nil
+}
+
+// awaitCI moves an execution to a ci node, where it waits for the checks on
+// its bead's branch.
+func (e *Engine) awaitCI(exec *WorkflowExecution, node *WorkflowNode) error {
+	if e.ciChecks == nil {
+		return fmt.Errorf("cannot run ci node %s: no CI checks configured", node.NodeKey)
+	}
+	exec.CurrentNodeKey = node.NodeKey
+	exec.Status = ExecutionStatusAwaitingCI
+	exec.NodeAttemptCount = 0
+	exec.LastNodeAt = time.Now()
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+	e.updateBeadContext(exec, map[string]string{
+		"workflow_node":        node.NodeKey,
+		"workflow_status":      string(ExecutionStatusAwaitingCI),
+		"cycle_count":          fmt.Sprintf("%d", exec.CycleCount),
+		"ci_state":             string(CIStatePending),
+		"ci_summary":           "",
+		"redispatch_requested": "false",
+	})
+
+	log.Printf("[Workflow] Bead %s waiting for CI at %s", exec.BeadID, node.NodeKey)
+	return nil
+}
+
+// SyncCI checks the CI of every execution waiting at a ci node and advances
+// those whose checks have finished or whose node has timed out. It returns
+// how many executions moved on.
+func (e *Engine) SyncCI() (int, error) {
+	if e.ciChecks == nil {
+		return 0, nil
+	}
+	waiting, err := e.db.ListWorkflowExecutionsByStatus(ExecutionStatusAwaitingCI)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list executions waiting for CI: %w", err)
+	}
+
+	advanced := 0
+	for _, exec := range waiting {
+		moved, err := e.checkCI(exec)
+		if err != nil {
+			log.Printf("[Workflow] Warning: CI check for bead %s failed: %v", exec.BeadID, err)
+		}
+		if moved {
+			advanced++
+		}
+	}
+	return advanced, nil
+}
+
+// checkCI advances an execution waiting at a ci node once its checks have
+// decided, or once the node has timed out.
+func (e *Engine) checkCI(exec *WorkflowExecution) (bool, error) {
+	node, err := e.GetCurrentNode(exec.ID)
+	if err != nil || node == nil {
+		return false, fmt.Errorf("ci node not found: %s", exec.CurrentNodeKey)
+	}
+
+	res, err := e.ciChecks.CIStatus(exec, RequiredChecks(node))
+	if err != nil {
+		res = &CIResult{State: CIStatePending}
+		log.Printf("[Workflow] Warning: could not read CI for bead %s: %v", exec.BeadID, err)
+	}
+
+	var condition EdgeCondition
+	resultData := map[string]string{}
+	switch res.State {
+	case CIStatePassed:
+		condition = EdgeConditionCIPassed
+	case CIStateFailed:
+		condition = EdgeConditionCIFailed
+	default:
+		if node.TimeoutMinutes <= 0 || time.Since(exec.LastNodeAt) <= time.Duration(node.TimeoutMinutes)*time.Minute {
+			return false, nil
+		}
+		condition = EdgeConditionTimeout
+		resultData["timeout_reason"] = fmt.Sprintf("CI did not finish within %d minutes", node.TimeoutMinutes)
+	}
+	for k, v := range map[string]string{"ci_branch": res.Branch, "ci_summary": res.Summary, "ci_url": res.URL} {
+		if v != "" {
+			resultData[k] = v
+		}
+	}
+
+	exec.Status = ExecutionStatusActive
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return false, fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+	e.updateBeadContext(exec, map[string]string{
+		"ci_state":   string(res.State),
+		"ci_summary": res.Summary,
+	})
+	if _, err := e.GetNextNode(exec, condition); err != nil {
+		reason := fmt.Sprintf("CI at %s came to %s and the node has no %s edge", node.NodeKey, res.State, condition)
+		if res.Summary != "" {
+			reason += ": " + res.Summary
+		}
+		return true, e.escalateWorkflow(exec, reason)
+	}
+
+	log.Printf("[Workflow] CI at %s for bead %s: %s", node.NodeKey, exec.BeadID, condition)
+	return true, e.AdvanceWorkflow(exec.ID, condition, "ci", resultData)
+}
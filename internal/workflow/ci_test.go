@@ -0,0 +1,183 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCIChecks reports the same result for every branch and remembers the
+// required checks it was asked about.
+type fakeCIChecks struct {
+	result   CIResult
+	required []string
+}
+
+func (f *fakeCIChecks) CIStatus(exec *WorkflowExecution, required []string) (*CIResult, error) {
+	f.required = required
+	res := f.result
+	return &res, nil
+}
+
+// newCIEngine runs bead-1 through fix to a ci node that leads to review
+// when CI passes and back to fix when it fails.
+func newCIEngine(t *testing.T) (*Engine, *mockDatabase, *mockBeadManager, *fakeCIChecks) {
+	t.Helper()
+	db := newMockDatabase()
+	beads := newMockBeadManager()
+	wf, err := NewWorkflowFromDefinition(&WorkflowDefinition{
+		ID: "wf-ci", Name: "CI", WorkflowType: "bug",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "fix", NodeType: "task", MaxAttempts: 3},
+			{NodeKey: "wait_for_ci", NodeType: "ci", TimeoutMinutes: 30, Metadata: map[string]string{CIChecksKey: "build, test"}},
+			{NodeKey: "review", NodeType: "task"},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "fix", Condition: "success"},
+			{FromNodeKey: "fix", ToNodeKey: "wait_for_ci", Condition: "success"},
+			{FromNodeKey: "wait_for_ci", ToNodeKey: "review", Condition: "ci_passed"},
+			{FromNodeKey: "wait_for_ci", ToNodeKey: "fix", Condition: "ci_failed"},
+			{FromNodeKey: "review", Condition: "success"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.workflows[wf.ID] = wf
+
+	checks := &fakeCIChecks{result: CIResult{State: CIStatePending, Branch: "bead/bead-1"}}
+	engine := NewEngine(db, beads)
+	engine.SetCIChecks(checks)
+	exec, err := engine.StartWorkflow("bead-1", wf.ID, "proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(db.executions, exec.ID)
+	exec.ID = "exec-1"
+	db.executions["exec-1"] = exec
+	for _, step := range []EdgeCondition{EdgeConditionSuccess, EdgeConditionSuccess} {
+		if err := engine.AdvanceWorkflow("exec-1", step, "agent-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine, db, beads, checks
+}
+
+func TestCIGate_WaitsForChecks(t *testing.T) {
+	engine, db, beads, checks := newCIEngine(t)
+	exec := engine.mustExec(t, "exec-1")
+	if exec.Status != ExecutionStatusAwaitingCI || exec.CurrentNodeKey != "wait_for_ci" {
+		t.Fatalf("status %s at %q, want awaiting_ci at wait_for_ci", exec.Status, exec.CurrentNodeKey)
+	}
+	ctx := beadContext(beads, "bead-1")
+	if ctx["workflow_status"] != "awaiting_ci" || ctx["ci_state"] != "pending" || ctx["redispatch_requested"] != "false" {
+		t.Errorf("bead context = %v", ctx)
+	}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionCIPassed, "agent-1", nil); err == nil || !strings.Contains(err.Error(), "waiting for CI") {
+		t.Errorf("agent advance: err = %v, want waiting for CI", err)
+	}
+
+	if n, err := engine.SyncCI(); err != nil || n != 0 {
+		t.Fatalf("SyncCI while pending = %d, %v", n, err)
+	}
+	if strings.Join(checks.required, ",") != "build,test" {
+		t.Errorf("required checks = %v", checks.required)
+	}
+
+	checks.result = CIResult{State: CIStatePassed, Branch: "bead/bead-1", Summary: "2 passed"}
+	if n, err := engine.SyncCI(); err != nil || n != 1 {
+		t.Fatalf("SyncCI after passing = %d, %v", n, err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "review" {
+		t.Errorf("after CI passed: status %s at %q, want active at review", exec.Status, exec.CurrentNodeKey)
+	}
+	history := db.history["exec-1"]
+	if last := history[len(history)-1]; last.Condition != EdgeConditionCIPassed || !strings.Contains(last.ResultData, "2 passed") {
+		t.Errorf("last history = %+v", last)
+	}
+}
+
+func TestCIGate_FailureAndTimeout(t *testing.T) {
+	engine, db, _, checks := newCIEngine(t)
+
+	checks.result = CIResult{State: CIStateFailed, Summary: "1 failed (test)", URL: "https://ci/run/1"}
+	if _, err := engine.SyncCI(); err != nil {
+		t.Fatal(err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "fix" {
+		t.Fatalf("after CI failed: status %s at %q, want active at fix", exec.Status, exec.CurrentNodeKey)
+	}
+	history := db.history["exec-1"]
+	last := history[len(history)-1]
+	if last.Condition != EdgeConditionCIFailed || last.AgentID != "ci" || !strings.Contains(last.ResultData, "https://ci/run/1") {
+		t.Errorf("last history = %+v", last)
+	}
+
+	// Back at the gate, CI that never finishes times out. With no timeout
+	// edge, the workflow escalates.
+	checks.result = CIResult{State: CIStatePending}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionSuccess, "agent-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	engine.mustExec(t, "exec-1").LastNodeAt = time.Now().Add(-time.Hour)
+	if _, err := engine.SyncCI(); err != nil {
+		t.Fatal(err)
+	}
+	if exec := engine.mustExec(t, "exec-1"); exec.Status != ExecutionStatusEscalated {
+		t.Errorf("after timeout: status %s, want escalated", exec.Status)
+	}
+}
+
+func TestCIGate_SkipAndRollback(t *testing.T) {
+	engine, _, _, _ := newCIEngine(t)
+
+	exec, err := engine.SkipNode("exec-1", "", "operator", "checked by hand")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "review" {
+		t.Fatalf("after skip: status %s at %q, want active at review", exec.Status, exec.CurrentNodeKey)
+	}
+	if exec, err = engine.RollbackExecution("exec-1", "wait_for_ci", "operator", ""); err != nil {
+		t.Fatal(err)
+	}
+	if exec.Status != ExecutionStatusAwaitingCI {
+		t.Errorf("after rollback: status %s, want awaiting_ci", exec.Status)
+	}
+
+	engine.SetCIChecks(nil)
+	if _, err := engine.RollbackExecution("exec-1", "fix", "operator", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionSuccess, "agent-1", nil); err == nil {
+		t.Error("reached a ci node with no CI checks configured")
+	}
+}
+
+func TestCheckCINodes(t *testing.T) {
+	base := func(edges ...WorkflowEdgeDefinition) *WorkflowDefinition {
+		return &WorkflowDefinition{
+			ID: "wf-x", Name: "X", WorkflowType: "bug",
+			Nodes: []WorkflowNodeDefinition{{NodeKey: "a", NodeType: "task"}, {NodeKey: "ci", NodeType: "ci"}},
+			Edges: append([]WorkflowEdgeDefinition{
+				{ToNodeKey: "a", Condition: "success"},
+				{FromNodeKey: "a", ToNodeKey: "ci", Condition: "success"},
+			}, edges...),
+		}
+	}
+	for _, tc := range []struct {
+		name  string
+		def   *WorkflowDefinition
+		error string
+	}{
+		{"valid", base(WorkflowEdgeDefinition{FromNodeKey: "ci", Condition: "ci_passed"}, WorkflowEdgeDefinition{FromNodeKey: "ci", ToNodeKey: "a", Condition: "ci_failed"}), ""},
+		{"no ci_passed", base(WorkflowEdgeDefinition{FromNodeKey: "ci", Condition: "timeout"}), "no ci_passed edge"},
+		{"success edge", base(WorkflowEdgeDefinition{FromNodeKey: "ci", Condition: "success"}), "ci nodes leave only by"},
+		{"ci edge from a task", base(WorkflowEdgeDefinition{FromNodeKey: "ci", Condition: "ci_passed"}, WorkflowEdgeDefinition{FromNodeKey: "a", Condition: "ci_failed"}), "may only leave ci nodes"},
+	} {
+		_, err := NewWorkflowFromDefinition(tc.def)
+		if tc.error == "" && err != nil || tc.error != "" && (err == nil || !strings.Contains(err.Error(), tc.error)) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.error)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operator controls for an execution in flight: pause and resume it, force
+// it past a stuck node, or roll it back to a node it has already been
+// through. Each action is recorded in the execution's history with the
+// operator as its agent.
+
+// PauseExecution holds an active execution where it is. A paused execution
+// is neither dispatched nor advanced, and its node does not time out.
+func (e *Engine) PauseExecution(executionID, actor, reason string) (*WorkflowExecution, error) {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	if exec.Status != ExecutionStatusActive {
+		return nil, fmt.Errorf("cannot pause a workflow execution that is %s", exec.Status)
+	}
+
+	exec.Status = ExecutionStatusPaused
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return nil, fmt.Errorf("failed to pause workflow execution: %w", err)
+	}
+	var data map[string]string
+	if reason != "" {
+		data = map[string]string{"reason": reason}
+	}
+	e.recordHistory(exec, HistoryPaused, actor, data)
+	e.updateBeadContext(exec, map[string]string{
+		"workflow_status":      string(ExecutionStatusPaused),
+		"redispatch_requested": "false",
+	})
+
+	log.Printf("[Workflow] Paused execution %s for bead %s at node %q", exec.ID, exec.BeadID, exec.CurrentNodeKey)
+	return exec, nil
+}
+
+// ResumeExecution reactivates a paused execution. The node's timeout starts
+// over.
+func (e *Engine) ResumeExecution(executionID, actor string) (*WorkflowExecution, error) {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	if exec.Status != ExecutionStatusPaused {
+		return nil, fmt.Errorf("cannot resume a workflow execution that is %s", exec.Status)
+	}
+
+	exec.Status = ExecutionStatusActive
+	exec.LastNodeAt = time.Now()
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return nil, fmt.Errorf("failed to resume workflow execution: %w", err)
+	}
+	e.recordHistory(exec, HistoryResumed, actor, nil)
+
+	context := map[string]string{"workflow_status": string(ExecutionStatusActive)}
+	if node, err := e.GetCurrentNode(exec.ID); err == nil && node != nil {
+		context["redispatch_requested"] = shouldRedispatch(exec, node)
+	}
+	e.updateBeadContext(exec, context)
+
+	log.Printf("[Workflow] Resumed execution %s for bead %s at node %q", exec.ID, exec.BeadID, exec.CurrentNodeKey)
+	return exec, nil
+}
+
+// SkipNode forces an execution past its current node along the edge for
+// condition, as if the node had finished that way. An empty condition means
+// approved on approval nodes, ci_passed on ci nodes, and success elsewhere.
+// Paused, escalated, blocked, and awaiting executions are reactivated first,
+// so a stuck execution can be moved on; a join stops waiting for its
+// remaining branches.
+func (e *Engine) SkipNode(executionID string, condition EdgeCondition, actor, reason string) (*WorkflowExecution, error) {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	if exec.Status == ExecutionStatusCompleted {
+		return nil, fmt.Errorf("workflow execution already %s", exec.Status)
+	}
+	if condition == "" {
+		condition = EdgeConditionSuccess
+		if node, err := e.GetCurrentNode(exec.ID); err == nil && node != nil {
+			switch node.NodeType {
+			case NodeTypeApproval:
+				condition = EdgeConditionApproved
+			case NodeTypeCI:
+				condition = EdgeConditionCIPassed
+			}
+		}
+	}
+	if _, err := e.GetNextNode(exec, condition); err != nil {
+		return nil, err
+	}
+
+	if exec.Status == ExecutionStatusBlocked {
+		e.cancelBranches(exec)
+	}
+	if exec.Status != ExecutionStatusActive {
+		exec.Status = ExecutionStatusActive
+		exec.EscalatedAt = nil
+		if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+			return nil, fmt.Errorf("failed to reactivate workflow execution: %w", err)
+		}
+	}
+	from := exec.CurrentNodeKey
+	resultData := map[string]string{"skipped_by": actor}
+	if reason != "" {
+		resultData["skip_reason"] = reason
+	}
+	if err := e.AdvanceWorkflow(exec.ID, condition, actor, resultData); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Workflow] %s skipped node %q of execution %s (%s)", actor, from, exec.ID, condition)
+	return e.db.GetWorkflowExecution(exec.ID)
+}
+
+// RollbackExecution moves an execution back to a node it has already been
+// through, restoring the state it had there: the cycle count it reached,
+// a fresh attempt count and timeout, and the bead's workflow context.
+// Completed, escalated, and blocked executions become active again. Parallel
+// and join nodes cannot be rolled back to; roll back to the node before the
+// parallel node to run its branches again. Rolling back to a human approval
+// gate asks its approvers again, and rolling back to a ci node waits for CI
+// again.
+func (e *Engine) RollbackExecution(executionID, nodeKey, actor, reason string) (*WorkflowExecution, error) {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return nil, err
+	}
+	if nodeKey == "" {
+		return nil, fmt.Errorf("a node to roll back to is required")
+	}
+	if nodeKey == exec.CurrentNodeKey && exec.Status != ExecutionStatusCompleted {
+		return nil, fmt.Errorf("workflow execution is already at node %s", nodeKey)
+	}
+
+	wf, err := e.db.GetWorkflow(exec.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	var target *WorkflowNode
+	for i := range wf.Nodes {
+		if wf.Nodes[i].NodeKey == nodeKey {
+			target = &wf.Nodes[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("node %s is not in workflow %s", nodeKey, wf.ID)
+	}
+	if target.NodeType == NodeTypeParallel || target.NodeType == NodeTypeJoin {
+		return nil, fmt.Errorf("cannot roll back to %s node %s", target.NodeType, nodeKey)
+	}
+
+	history, err := e.db.ListWorkflowHistory(exec.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow history: %w", err)
+	}
+	var last *WorkflowExecutionHistory
+	for _, h := range history {
+		if h.NodeKey == nodeKey {
+			last = h
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("execution %s has not been through node %s", exec.ID, nodeKey)
+	}
+
+	if exec.Status == ExecutionStatusBlocked {
+		e.cancelBranches(exec)
+	}
+	left := *exec
+	exec.CurrentNodeKey = nodeKey
+	exec.Status = ExecutionStatusActive
+	exec.CycleCount = last.CycleCount
+	exec.NodeAttemptCount = 0
+	exec.CompletedAt = nil
+	exec.EscalatedAt = nil
+	exec.LastNodeAt = time.Now()
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return nil, fmt.Errorf("failed to roll back workflow execution: %w", err)
+	}
+
+	// Like a transition, the rollback is recorded at the node it leaves.
+	data := map[string]string{"to_node": nodeKey}
+	if reason != "" {
+		data["reason"] = reason
+	}
+	e.recordHistory(&left, HistoryRolledBack, actor, data)
+	e.updateBeadContext(exec, map[string]string{
+		"workflow_node":        nodeKey,
+		"workflow_status":      string(ExecutionStatusActive),
+		"cycle_count":          fmt.Sprintf("%d", exec.CycleCount),
+		"required_role":        target.RoleRequired,
+		"redispatch_requested": shouldRedispatch(exec, target),
+		"needs_ceo_review":     "false",
+		"escalation_reason":    "",
+	})
+	if approvers := Approvers(target); len(approvers) > 0 && e.approvalGates != nil {
+		if err := e.awaitApproval(exec, target, approvers); err != nil {
+			return nil, err
+		}
+	} else if target.NodeType == NodeTypeCI {
+		if err := e.awaitCI(exec, target); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[Workflow] %s rolled back execution %s for bead %s from %q to %q", actor, exec.ID, exec.BeadID, left.CurrentNodeKey, nodeKey)
+	return exec, nil
+}
+
+// recordHistory adds an entry made outside AdvanceWorkflow, such as an
+// operator action, to an execution's history.
+func (e *Engine) recordHistory(exec *WorkflowExecution, action EdgeCondition, actor string, data map[string]string) {
+	resultJSON := ""
+	if len(data) > 0 {
+		if b, err := json.Marshal(data); err == nil {
+			resultJSON = string(b)
+		}
+	}
+	history := &WorkflowExecutionHistory{
+		ID:            fmt.Sprintf("wfhist-%s", uuid.New().String()[:8]),
+		ExecutionID:   exec.ID,
+		NodeKey:       exec.CurrentNodeKey,
+		AgentID:       actor,
+		Condition:     action,
+		ResultData:    resultJSON,
+		AttemptNumber: exec.NodeAttemptCount,
+		CycleCount:    exec.CycleCount,
+		CreatedAt:     time.Now(),
+	}
+	if err := e.db.InsertWorkflowHistory(history); err != nil {
+		log.Printf("[Workflow] Warning: failed to insert history: %v", err)
+	}
+}
+
+func (e *Engine) updateBeadContext(exec *WorkflowExecution, context map[string]string) {
+	if e.beads == nil {
+		return
+	}
+	if err := e.beads.UpdateBead(exec.BeadID, map[string]interface{}{"context": context}); err != nil {
+		log.Printf("[Workflow] Warning: failed to update bead context: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+// newControlsEngine returns an engine with a three-node workflow
+// (investigate -> review -> fix -> end, review rejecting back to
+// investigate) and an execution for bead-1 at review.
+func newControlsEngine(t *testing.T) (*Engine, *mockDatabase, *mockBeadManager) {
+	t.Helper()
+	db := newMockDatabase()
+	beads := newMockBeadManager()
+	wf, err := NewWorkflowFromDefinition(&WorkflowDefinition{
+		ID: "wf-c", Name: "Controls", WorkflowType: "bug",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "investigate", NodeType: "task", RoleRequired: "QA", MaxAttempts: 3},
+			{NodeKey: "review", NodeType: "approval", RoleRequired: "PM"},
+			{NodeKey: "fix", NodeType: "task", MaxAttempts: 3},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "investigate", Condition: "success"},
+			{FromNodeKey: "investigate", ToNodeKey: "review", Condition: "success"},
+			{FromNodeKey: "review", ToNodeKey: "fix", Condition: "approved"},
+			{FromNodeKey: "review", ToNodeKey: "investigate", Condition: "rejected"},
+			{FromNodeKey: "fix", Condition: "success"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.workflows[wf.ID] = wf
+
+	engine := NewEngine(db, beads)
+	exec, err := engine.StartWorkflow("bead-1", wf.ID, "proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.ID = "exec-1"
+	db.executions["exec-1"] = exec
+	for _, step := range []EdgeCondition{EdgeConditionSuccess, EdgeConditionSuccess} {
+		if err := engine.AdvanceWorkflow("exec-1", step, "agent-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if exec.CurrentNodeKey != "review" {
+		t.Fatalf("setup: execution at %q, want review", exec.CurrentNodeKey)
+	}
+	return engine, db, beads
+}
+
+func beadContext(beads *mockBeadManager, beadID string) map[string]string {
+	ctx, _ := beads.beads[beadID]["context"].(map[string]string)
+	return ctx
+}
+
+func TestPauseResumeExecution(t *testing.T) {
+	engine, db, beads := newControlsEngine(t)
+
+	exec, err := engine.PauseExecution("exec-1", "alice", "waiting on legal")
+	if err != nil {
+		t.Fatalf("PauseExecution: %v", err)
+	}
+	if exec.Status != ExecutionStatusPaused || beadContext(beads, "bead-1")["workflow_status"] != "paused" {
+		t.Errorf("status = %s, bead context = %v", exec.Status, beadContext(beads, "bead-1"))
+	}
+	if err := engine.AdvanceWorkflow("exec-1", EdgeConditionApproved, "agent-1", nil); err == nil || !strings.Contains(err.Error(), "paused") {
+		t.Errorf("AdvanceWorkflow on paused execution: err = %v", err)
+	}
+	if _, err := engine.PauseExecution("exec-1", "alice", ""); err == nil {
+		t.Error("expected error pausing a paused execution")
+	}
+
+	exec, err = engine.ResumeExecution("exec-1", "alice")
+	if err != nil {
+		t.Fatalf("ResumeExecution: %v", err)
+	}
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "review" {
+		t.Errorf("after resume: status %s at %q", exec.Status, exec.CurrentNodeKey)
+	}
+	if _, err := engine.ResumeExecution("exec-1", "alice"); err == nil {
+		t.Error("expected error resuming an active execution")
+	}
+
+	var actions []EdgeCondition
+	for _, h := range db.history["exec-1"] {
+		if h.AgentID == "alice" {
+			actions = append(actions, h.Condition)
+		}
+	}
+	if len(actions) != 2 || actions[0] != HistoryPaused || actions[1] != HistoryResumed {
+		t.Errorf("operator history = %v", actions)
+	}
+}
+
+func TestSkipNode(t *testing.T) {
+	engine, _, _ := newControlsEngine(t)
+
+	// An escalated execution is stuck until someone moves it on.
+	if err := engine.escalateWorkflow(engine.mustExec(t, "exec-1"), "stuck"); err != nil {
+		t.Fatal(err)
+	}
+	exec, err := engine.SkipNode("exec-1", "", "alice", "approved offline")
+	if err != nil {
+		t.Fatalf("SkipNode: %v", err)
+	}
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "fix" || exec.EscalatedAt != nil {
+		t.Errorf("after skip: status %s at %q", exec.Status, exec.CurrentNodeKey)
+	}
+
+	if _, err := engine.SkipNode("exec-1", EdgeConditionRejected, "alice", ""); err == nil {
+		t.Error("expected error skipping along an edge that does not exist")
+	}
+	exec, err = engine.SkipNode("exec-1", "", "alice", "")
+	if err != nil || exec.Status != ExecutionStatusCompleted {
+		t.Fatalf("skipping the last node: status %v, err %v", exec, err)
+	}
+	if _, err := engine.SkipNode("exec-1", "", "alice", ""); err == nil {
+		t.Error("expected error skipping a completed execution")
+	}
+}
+
+func TestRollbackExecution(t *testing.T) {
+	engine, db, beads := newControlsEngine(t)
+
+	// review -> investigate -> review is one cycle.
+	for _, step := range []EdgeCondition{EdgeConditionRejected, EdgeConditionSuccess} {
+		if err := engine.AdvanceWorkflow("exec-1", step, "agent-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exec := engine.mustExec(t, "exec-1")
+	if exec.CycleCount == 0 {
+		t.Fatal("setup: expected a cycle")
+	}
+	exec.NodeAttemptCount = 2
+
+	if _, err := engine.RollbackExecution("exec-1", "fix", "alice", ""); err == nil {
+		t.Error("expected error rolling back to a node not yet visited")
+	}
+	if _, err := engine.RollbackExecution("exec-1", "nope", "alice", ""); err == nil {
+		t.Error("expected error rolling back to an unknown node")
+	}
+
+	exec, err := engine.RollbackExecution("exec-1", "investigate", "alice", "bad analysis")
+	if err != nil {
+		t.Fatalf("RollbackExecution: %v", err)
+	}
+	if exec.CurrentNodeKey != "investigate" || exec.NodeAttemptCount != 0 || exec.Status != ExecutionStatusActive {
+		t.Errorf("after rollback: at %q, attempts %d, status %s", exec.CurrentNodeKey, exec.NodeAttemptCount, exec.Status)
+	}
+	// The most recent pass through investigate came after the first cycle.
+	if exec.CycleCount != 1 {
+		t.Errorf("CycleCount = %d, want 1", exec.CycleCount)
+	}
+	ctx := beadContext(beads, "bead-1")
+	if ctx["workflow_node"] != "investigate" || ctx["required_role"] != "QA" || ctx["redispatch_requested"] != "true" {
+		t.Errorf("bead context = %v", ctx)
+	}
+	history := db.history["exec-1"]
+	last := history[len(history)-1]
+	if last.Condition != HistoryRolledBack || last.NodeKey != "review" || !strings.Contains(last.ResultData, "investigate") {
+		t.Errorf("last history entry = %+v", last)
+	}
+}
+
+func (e *Engine) mustExec(t *testing.T, id string) *WorkflowExecution {
+	t.Helper()
+	exec, err := e.db.GetWorkflowExecution(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exec
+}
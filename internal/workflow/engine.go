@@ -19,10 +19,14 @@ type Database interface {
 	UpsertWorkflowEdge(edge *WorkflowEdge) error
 	UpsertWorkflowExecution(exec *WorkflowExecution) error
 	GetWorkflowExecution(id string) (*WorkflowExecution, error)
+	ListWorkflowExecutionsByStatus(status ExecutionStatus) ([]*WorkflowExecution, error)
 	GetWorkflowExecutionByBeadID(beadID string) (*WorkflowExecution, error)
 	InsertWorkflowHistory(history *WorkflowExecutionHistory) error
 	ListWorkflowHistory(executionID string) ([]*WorkflowExecutionHistory, error)
 	DeleteWorkflowExecutionByBeadID(beadID string) error
+	UpsertWorkflowBranch(branch *WorkflowBranch) error
+	ListWorkflowBranches(executionID string) ([]*WorkflowBranch, error)
+	ListRunningWorkflowBranches() ([]*WorkflowBranch, error)
 }
 
 // BeadManager interface for bead operations
@@ -37,8 +41,11 @@ type BeadCreator interface {
 
 // Engine manages workflow execution
 type Engine struct {
-	db    Database
-	beads BeadManager
+	db            Database
+	beads         BeadManager
+	branchBeads   BranchBeads
+	approvalGates ApprovalGates
+	ciChecks      CIChecks
 }
 
 // NewEngine creates a new workflow engine
@@ -152,7 +159,7 @@ func (e *Engine) GetNextNode(execution *WorkflowExecution, condition EdgeConditi
 
 	if targetNodeKey == "" {
 		// No matching edge - check if this is workflow end
-		if condition == EdgeConditionSuccess && execution.CurrentNodeKey != "" {
+		if (condition == EdgeConditionSuccess || condition == EdgeConditionCIPassed) && execution.CurrentNodeKey != "" {
 			// Look for workflow end transition (ToNodeKey empty)
 			for _, edge := range wf.Edges {
 				if edge.FromNodeKey == execution.CurrentNodeKey && edge.ToNodeKey == "" {
@@ -207,10 +214,22 @@ func (e *Engine) AdvanceWorkflow(executionID string, condition EdgeCondition, ag
 		return fmt.Errorf("failed to get execution: %w", err)
 	}
 
-	// Check if already completed or escalated
+	// Check if already completed or escalated, or held by an operator
 	if exec.Status == ExecutionStatusCompleted || exec.Status == ExecutionStatusEscalated {
 		return fmt.Errorf("workflow execution already %s", exec.Status)
 	}
+	if exec.Status == ExecutionStatusPaused {
+		return fmt.Errorf("workflow execution is paused")
+	}
+	if exec.Status == ExecutionStatusBlocked {
+		return fmt.Errorf("workflow execution is waiting for parallel branches at %s", exec.CurrentNodeKey)
+	}
+	if exec.Status == ExecutionStatusAwaitingApproval {
+		return fmt.Errorf("workflow execution is awaiting approval at %s", exec.CurrentNodeKey)
+	}
+	if exec.Status == ExecutionStatusAwaitingCI {
+		return fmt.Errorf("workflow execution is waiting for CI at %s", exec.CurrentNodeKey)
+	}
 
 	// Record history
 	resultJSON := ""
@@ -227,6 +246,7 @@ func (e *Engine) AdvanceWorkflow(executionID string, condition EdgeCondition, ag
 		Condition:     condition,
 		ResultData:    resultJSON,
 		AttemptNumber: exec.NodeAttemptCount,
+		CycleCount:    exec.CycleCount,
 		CreatedAt:     time.Now(),
 	}
 	if err := e.db.InsertWorkflowHistory(history); err != nil {
@@ -282,6 +302,22 @@ func (e *Engine) AdvanceWorkflow(executionID string, condition EdgeCondition, ag
 		return e.escalateWorkflow(exec, fmt.Sprintf("Exceeded max cycles (3): workflow has cycled %d times", exec.CycleCount))
 	}
 
+	// A parallel node is not worked on itself: its branches run as child
+	// beads while the execution waits at their join.
+	if nextNode.NodeType == NodeTypeParallel {
+		return e.fanOut(exec, nextNode)
+	}
+
+	// A human approval gate waits for one of its approvers to decide.
+	if approvers := Approvers(nextNode); len(approvers) > 0 && e.approvalGates != nil {
+		return e.awaitApproval(exec, nextNode, approvers)
+	}
+
+	// A ci node waits for the checks on the bead's branch.
+	if nextNode.NodeType == NodeTypeCI {
+		return e.awaitCI(exec, nextNode)
+	}
+
 	// Move to next node
 	exec.CurrentNodeKey = nextNode.NodeKey
 	exec.NodeAttemptCount = 0 // Reset attempt count for new node
@@ -264,6 +264,7 @@ type mockDatabase struct {
 	executions     map[string]*WorkflowExecution
 	history        map[string][]*WorkflowExecutionHistory
 	beadExecutions map[string]*WorkflowExecution
+	branches       []*WorkflowBranch
 }
 
 func newMockDatabase() *mockDatabase {
@@ -332,6 +333,16 @@ func (m *mockDatabase) GetWorkflowExecutionByBeadID(beadID string) (*WorkflowExe
 	return exec, nil
 }
 
+func (m *mockDatabase) ListWorkflowExecutionsByStatus(status ExecutionStatus) ([]*WorkflowExecution, error) {
+	var result []*WorkflowExecution
+	for _, exec := range m.executions {
+		if exec.Status == status {
+			result = append(result, exec)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockDatabase) InsertWorkflowHistory(history *WorkflowExecutionHistory) error {
 	m.history[history.ExecutionID] = append(m.history[history.ExecutionID], history)
 	return nil
@@ -350,6 +361,37 @@ func (m *mockDatabase) DeleteWorkflowExecutionByBeadID(beadID string) error {
 	return nil
 }
 
+func (m *mockDatabase) UpsertWorkflowBranch(branch *WorkflowBranch) error {
+	for i, b := range m.branches {
+		if b.ExecutionID == branch.ExecutionID && b.NodeKey == branch.NodeKey {
+			m.branches[i] = branch
+			return nil
+		}
+	}
+	m.branches = append(m.branches, branch)
+	return nil
+}
+
+func (m *mockDatabase) ListWorkflowBranches(executionID string) ([]*WorkflowBranch, error) {
+	var result []*WorkflowBranch
+	for _, b := range m.branches {
+		if b.ExecutionID == executionID {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDatabase) ListRunningWorkflowBranches() ([]*WorkflowBranch, error) {
+	var result []*WorkflowBranch
+	for _, b := range m.branches {
+		if b.Status == BranchStatusRunning {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
 type mockBeadManager struct {
 	beads map[string]map[string]interface{}
 }
@@ -0,0 +1,198 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Graph formats accepted by RenderGraph.
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+)
+
+// Keys of the start and end pseudo-nodes in a rendered graph. Edges with an
+// empty from_node_key leave start; edges with an empty to_node_key enter end.
+const (
+	graphStart = "__start__"
+	graphEnd   = "__end__"
+)
+
+// GraphPosition marks where an execution stands in a rendered graph.
+type GraphPosition struct {
+	NodeKey  string          // current node; empty before the first node or after the last
+	Status   ExecutionStatus // the execution's status
+	Branches []string        // branch nodes still running while blocked at a join
+}
+
+// graphKeys are the nodes a position highlights, with the start and end of
+// the workflow as their pseudo-nodes.
+func (p *GraphPosition) graphKeys() map[string]bool {
+	switch {
+	case p == nil:
+		return nil
+	case p.NodeKey != "":
+		keys := map[string]bool{p.NodeKey: true}
+		for _, key := range p.Branches {
+			keys[key] = true
+		}
+		return keys
+	case p.Status == ExecutionStatusCompleted:
+		return map[string]bool{graphEnd: true}
+	default:
+		return map[string]bool{graphStart: true}
+	}
+}
+
+// RenderGraph renders a workflow's nodes and edges as Graphviz DOT or a
+// Mermaid flowchart. Edges are labelled with their condition. When pos is
+// set, the node the execution stands at is highlighted, along with any
+// branches it is waiting for.
+func RenderGraph(wf *Workflow, format string, pos *GraphPosition) (string, error) {
+	switch format {
+	case GraphFormatDOT:
+		return renderDOT(wf, pos.graphKeys()), nil
+	case GraphFormatMermaid:
+		return renderMermaid(wf, pos.graphKeys()), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (want %s or %s)", format, GraphFormatDOT, GraphFormatMermaid)
+	}
+}
+
+func graphEdgeEnds(e WorkflowEdge) (from, to string) {
+	from, to = e.FromNodeKey, e.ToNodeKey
+	if from == "" {
+		from = graphStart
+	}
+	if to == "" {
+		to = graphEnd
+	}
+	return from, to
+}
+
+func nodeLabel(n WorkflowNode) string {
+	if n.RoleRequired == "" {
+		return n.NodeKey
+	}
+	return n.NodeKey + "\n" + n.RoleRequired
+}
+
+func renderDOT(wf *Workflow, current map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(wf.ID))
+	fmt.Fprintf(&b, "  label=%s;\n", dotQuote(wf.Name))
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	pseudo := func(key, label string) {
+		attrs := fmt.Sprintf("label=%s, shape=circle", dotQuote(label))
+		if current[key] {
+			attrs += dotCurrentAttrs
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(key), attrs)
+	}
+	pseudo(graphStart, "start")
+	for _, n := range wf.Nodes {
+		shape, ok := dotShapes[n.NodeType]
+		if !ok {
+			shape = dotShapes[NodeTypeTask]
+		}
+		attrs := fmt.Sprintf("label=%s, shape=%s", dotQuote(nodeLabel(n)), shape)
+		if current[n.NodeKey] {
+			attrs += dotCurrentAttrs
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.NodeKey), attrs)
+	}
+	pseudo(graphEnd, "end")
+
+	for _, e := range wf.Edges {
+		from, to := graphEdgeEnds(e)
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(from), dotQuote(to), dotQuote(string(e.Condition)))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+const dotCurrentAttrs = `, style="filled,bold", fillcolor="#fde68a", penwidth=3`
+
+var dotShapes = map[NodeType]string{
+	NodeTypeTask:     "box",
+	NodeTypeApproval: "diamond",
+	NodeTypeCommit:   "cylinder",
+	NodeTypeVerify:   "hexagon",
+	NodeTypeParallel: "invtrapezium",
+	NodeTypeJoin:     "trapezium",
+	NodeTypeCI:       "octagon",
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func renderMermaid(wf *Workflow, current map[string]bool) string {
+	ids := make(map[string]string)
+	id := func(key string) string {
+		if v, ok := ids[key]; ok {
+			return v
+		}
+		v := fmt.Sprintf("n%d_%s", len(ids), mermaidUnsafe.ReplaceAllString(key, "_"))
+		ids[key] = v
+		return v
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	fmt.Fprintf(&b, "  %s((start))\n", id(graphStart))
+	for _, n := range wf.Nodes {
+		shape, ok := mermaidShapes[n.NodeType]
+		if !ok {
+			shape = mermaidShapes[NodeTypeTask]
+		}
+		fmt.Fprintf(&b, "  %s%s%s%s\n", id(n.NodeKey), shape[0], mermaidQuote(nodeLabel(n)), shape[1])
+	}
+	fmt.Fprintf(&b, "  %s((end))\n", id(graphEnd))
+
+	for _, e := range wf.Edges {
+		from, to := graphEdgeEnds(e)
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", id(from), mermaidQuote(string(e.Condition)), id(to))
+	}
+
+	var highlighted []string
+	for key := range ids {
+		if current[key] {
+			highlighted = append(highlighted, ids[key])
+		}
+	}
+	if len(highlighted) > 0 {
+		sort.Strings(highlighted)
+		b.WriteString("  classDef current fill:#fde68a,stroke:#d97706,stroke-width:3px\n")
+		fmt.Fprintf(&b, "  class %s current\n", strings.Join(highlighted, ","))
+	}
+	return b.String()
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+var mermaidShapes = map[NodeType][2]string{
+	NodeTypeTask:     {"[", "]"},
+	NodeTypeApproval: {"{", "}"},
+	NodeTypeCommit:   {"[(", ")]"},
+	NodeTypeVerify:   {"{{", "}}"},
+	NodeTypeParallel: {"[/", "\\]"},
+	NodeTypeJoin:     {"[\\", "/]"},
+	NodeTypeCI:       {"([", "])"},
+}
+
+// mermaidQuote quotes a label, escaping the characters Mermaid would read
+// as syntax.
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "\n", "<br/>")
+	return `"` + s + `"`
+}
@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func graphTestWorkflow(t *testing.T) *Workflow {
+	t.Helper()
+	wf, err := NewWorkflowFromDefinition(&WorkflowDefinition{
+		ID: "wf-g", Name: `Review "fast"`, WorkflowType: "custom",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "fix-it", NodeType: "task", RoleRequired: "Engineer"},
+			{NodeKey: "review", NodeType: "approval"},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "fix-it", Condition: "success"},
+			{FromNodeKey: "fix-it", ToNodeKey: "review", Condition: "success"},
+			{FromNodeKey: "review", ToNodeKey: "fix-it", Condition: "rejected"},
+			{FromNodeKey: "review", Condition: "approved"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wf
+}
+
+func TestRenderGraph_DOT(t *testing.T) {
+	out, err := RenderGraph(graphTestWorkflow(t), GraphFormatDOT, &GraphPosition{NodeKey: "review", Status: ExecutionStatusActive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`digraph "wf-g" {`,
+		`label="Review \"fast\"";`,
+		`"fix-it" [label="fix-it\nEngineer", shape=box];`,
+		`"review" [label="review", shape=diamond, style="filled,bold"`,
+		`"__start__" -> "fix-it" [label="success"];`,
+		`"review" -> "__end__" [label="approved"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderGraph_Mermaid(t *testing.T) {
+	out, err := RenderGraph(graphTestWorkflow(t), GraphFormatMermaid, &GraphPosition{Status: ExecutionStatusCompleted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"flowchart TD\n",
+		`n1_fix_it["fix-it<br/>Engineer"]`,
+		`n2_review{"review"}`,
+		`n0___start__ -->|"success"| n1_fix_it`,
+		`n2_review -->|"approved"| n3___end__`,
+		"class n3___end__ current",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _ = RenderGraph(graphTestWorkflow(t), GraphFormatMermaid, nil)
+	if strings.Contains(out, "classDef current") {
+		t.Errorf("no position should highlight nothing:\n%s", out)
+	}
+	if _, err := RenderGraph(graphTestWorkflow(t), "svg", nil); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRenderGraph_ParallelBranches(t *testing.T) {
+	wf, err := NewWorkflowFromDefinition(parallelDefinition(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := &GraphPosition{NodeKey: "merge", Status: ExecutionStatusBlocked, Branches: []string{"docs", "qa"}}
+	out, err := RenderGraph(wf, GraphFormatMermaid, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`n2_fan[/"fan"\]`,
+		`n6_merge[\"merge"/]`,
+		`n2_fan -->|"branch"| n3_code`,
+		"class n4_docs,n5_qa,n6_merge current",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package workflow
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,33 +16,34 @@ import (
 
 // WorkflowDefinition represents a workflow definition from YAML
 type WorkflowDefinition struct {
-	ID           string                   `yaml:"id"`
-	Name         string                   `yaml:"name"`
-	Description  string                   `yaml:"description"`
-	WorkflowType string                   `yaml:"workflow_type"`
-	IsDefault    bool                     `yaml:"is_default"`
-	Nodes        []WorkflowNodeDefinition `yaml:"nodes"`
-	Edges        []WorkflowEdgeDefinition `yaml:"edges"`
+	ID           string                   `yaml:"id" json:"id"`
+	Name         string                   `yaml:"name" json:"name"`
+	Description  string                   `yaml:"description" json:"description"`
+	WorkflowType string                   `yaml:"workflow_type" json:"workflow_type"`
+	IsDefault    bool                     `yaml:"is_default" json:"is_default"`
+	ProjectID    string                   `yaml:"project_id,omitempty" json:"project_id,omitempty"`
+	Nodes        []WorkflowNodeDefinition `yaml:"nodes" json:"nodes"`
+	Edges        []WorkflowEdgeDefinition `yaml:"edges" json:"edges"`
 }
 
 // WorkflowNodeDefinition represents a node definition from YAML
 type WorkflowNodeDefinition struct {
-	NodeKey        string            `yaml:"node_key"`
-	NodeType       string            `yaml:"node_type"`
-	RoleRequired   string            `yaml:"role_required"`
-	PersonaHint    string            `yaml:"persona_hint"`
-	MaxAttempts    int               `yaml:"max_attempts"`
-	TimeoutMinutes int               `yaml:"timeout_minutes"`
-	Instructions   string            `yaml:"instructions"`
-	Metadata       map[string]string `yaml:"metadata,omitempty"`
+	NodeKey        string            `yaml:"node_key" json:"node_key"`
+	NodeType       string            `yaml:"node_type" json:"node_type"`
+	RoleRequired   string            `yaml:"role_required" json:"role_required"`
+	PersonaHint    string            `yaml:"persona_hint" json:"persona_hint"`
+	MaxAttempts    int               `yaml:"max_attempts" json:"max_attempts"`
+	TimeoutMinutes int               `yaml:"timeout_minutes" json:"timeout_minutes"`
+	Instructions   string            `yaml:"instructions" json:"instructions"`
+	Metadata       map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
 }
 
 // WorkflowEdgeDefinition represents an edge definition from YAML
 type WorkflowEdgeDefinition struct {
-	FromNodeKey string `yaml:"from_node_key"`
-	ToNodeKey   string `yaml:"to_node_key"`
-	Condition   string `yaml:"condition"`
-	Priority    int    `yaml:"priority"`
+	FromNodeKey string `yaml:"from_node_key" json:"from_node_key"`
+	ToNodeKey   string `yaml:"to_node_key" json:"to_node_key"`
+	Condition   string `yaml:"condition" json:"condition"`
+	Priority    int    `yaml:"priority" json:"priority"`
 }
 
 // LoadWorkflowFromFile loads a workflow definition from a YAML file
@@ -84,6 +88,216 @@ func LoadDefaultWorkflows(dir string) ([]*Workflow, error) {
 	return workflows, nil
 }
 
+// ParseWorkflowDefinition parses a workflow definition from YAML or JSON,
+// in the same shape as the files under workflows/defaults.
+func ParseWorkflowDefinition(data []byte) (*WorkflowDefinition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var def WorkflowDefinition
+	if err := dec.Decode(&def); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("workflow definition is empty")
+		}
+		return nil, fmt.Errorf("failed to parse workflow definition: %w", err)
+	}
+	return &def, nil
+}
+
+// NewWorkflowFromDefinition validates a definition and converts it to a
+// Workflow. Node keys must be unique and edges may only reference declared
+// nodes; an empty from_node_key marks the entry edge and an empty
+// to_node_key the end of the workflow. The end must be reachable from every
+// node the entry leads to.
+func NewWorkflowFromDefinition(def *WorkflowDefinition) (*Workflow, error) {
+	if def == nil {
+		return nil, fmt.Errorf("workflow definition cannot be nil")
+	}
+	if def.ID == "" || def.Name == "" || def.WorkflowType == "" {
+		return nil, fmt.Errorf("id, name, and workflow_type are required")
+	}
+	keys := make(map[string]bool, len(def.Nodes))
+	for _, n := range def.Nodes {
+		if n.NodeKey == "" {
+			return nil, fmt.Errorf("workflow %s: node_key is required", def.ID)
+		}
+		if keys[n.NodeKey] {
+			return nil, fmt.Errorf("workflow %s: duplicate node_key %q", def.ID, n.NodeKey)
+		}
+		if !validNodeTypes[NodeType(n.NodeType)] {
+			return nil, fmt.Errorf("workflow %s: node %s has unknown node_type %q", def.ID, n.NodeKey, n.NodeType)
+		}
+		if _, ok := n.Metadata[ApproversKey]; ok && NodeType(n.NodeType) != NodeTypeApproval {
+			return nil, fmt.Errorf("workflow %s: node %s lists %s but is not an approval node", def.ID, n.NodeKey, ApproversKey)
+		}
+		keys[n.NodeKey] = true
+	}
+	for _, e := range def.Edges {
+		for _, key := range []string{e.FromNodeKey, e.ToNodeKey} {
+			if key != "" && !keys[key] {
+				return nil, fmt.Errorf("workflow %s: edge references unknown node %q", def.ID, key)
+			}
+		}
+		if e.Condition == "" {
+			return nil, fmt.Errorf("workflow %s: edge %s -> %s has no condition", def.ID, e.FromNodeKey, e.ToNodeKey)
+		}
+		if !validEdgeConditions[EdgeCondition(e.Condition)] {
+			return nil, fmt.Errorf("workflow %s: edge %s -> %s has unknown condition %q", def.ID, e.FromNodeKey, e.ToNodeKey, e.Condition)
+		}
+		if e.FromNodeKey == "" && e.ToNodeKey == "" {
+			return nil, fmt.Errorf("workflow %s: an edge must have a from_node_key or a to_node_key", def.ID)
+		}
+	}
+	if err := checkParallelNodes(def); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", def.ID, err)
+	}
+	if err := checkCINodes(def); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", def.ID, err)
+	}
+	if err := checkWorkflowGraph(def); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", def.ID, err)
+	}
+	return convertDefinitionToWorkflow(def), nil
+}
+
+var validNodeTypes = map[NodeType]bool{
+	NodeTypeTask: true, NodeTypeApproval: true, NodeTypeCommit: true, NodeTypeVerify: true,
+	NodeTypeParallel: true, NodeTypeJoin: true, NodeTypeCI: true,
+}
+
+var validEdgeConditions = map[EdgeCondition]bool{
+	EdgeConditionSuccess: true, EdgeConditionFailure: true, EdgeConditionApproved: true,
+	EdgeConditionRejected: true, EdgeConditionTimeout: true, EdgeConditionEscalated: true,
+	EdgeConditionBranch: true, EdgeConditionCIPassed: true, EdgeConditionCIFailed: true,
+}
+
+// checkParallelNodes checks the shape parallel execution relies on. A
+// parallel node leaves only by branch edges, and only parallel nodes have
+// them. Each branch node is a task, commit, or verify node entered only by
+// its branch edge and left only by a success edge to a join, the same join
+// for every branch of a parallel node. A join belongs to one parallel node
+// and is entered only from its branch nodes, and its join_count, if set, is
+// a positive number no larger than its branches.
+func checkParallelNodes(def *WorkflowDefinition) error {
+	types := make(map[string]NodeType, len(def.Nodes))
+	for _, n := range def.Nodes {
+		types[n.NodeKey] = NodeType(n.NodeType)
+	}
+	out := make(map[string][]WorkflowEdgeDefinition)
+	in := make(map[string][]WorkflowEdgeDefinition)
+	for _, e := range def.Edges {
+		out[e.FromNodeKey] = append(out[e.FromNodeKey], e)
+		in[e.ToNodeKey] = append(in[e.ToNodeKey], e)
+		isBranch := EdgeCondition(e.Condition) == EdgeConditionBranch
+		if isBranch != (types[e.FromNodeKey] == NodeTypeParallel) {
+			return fmt.Errorf("edge %s -> %s: branch edges must leave, and are the only edges leaving, parallel nodes", e.FromNodeKey, e.ToNodeKey)
+		}
+	}
+
+	joinOwner := make(map[string]string)
+	joinBranches := make(map[string]int)
+	for _, n := range def.Nodes {
+		if NodeType(n.NodeType) != NodeTypeParallel {
+			continue
+		}
+		if len(out[n.NodeKey]) == 0 {
+			return fmt.Errorf("parallel node %s has no branch edges", n.NodeKey)
+		}
+		join := ""
+		for _, branch := range out[n.NodeKey] {
+			key := branch.ToNodeKey
+			switch types[key] {
+			case NodeTypeTask, NodeTypeCommit, NodeTypeVerify:
+			default:
+				return fmt.Errorf("branch %s of parallel node %s must be a task, commit, or verify node", key, n.NodeKey)
+			}
+			if len(in[key]) != 1 {
+				return fmt.Errorf("branch node %s must be entered only from parallel node %s", key, n.NodeKey)
+			}
+			edges := out[key]
+			if len(edges) != 1 || EdgeCondition(edges[0].Condition) != EdgeConditionSuccess || types[edges[0].ToNodeKey] != NodeTypeJoin {
+				return fmt.Errorf("branch node %s must have exactly one edge, a success edge to a join node", key)
+			}
+			if join != "" && edges[0].ToNodeKey != join {
+				return fmt.Errorf("branches of parallel node %s lead to different joins (%s and %s)", n.NodeKey, join, edges[0].ToNodeKey)
+			}
+			join = edges[0].ToNodeKey
+		}
+		if owner, ok := joinOwner[join]; ok {
+			return fmt.Errorf("join node %s is shared by parallel nodes %s and %s", join, owner, n.NodeKey)
+		}
+		joinOwner[join] = n.NodeKey
+		joinBranches[join] = len(out[n.NodeKey])
+	}
+
+	for _, n := range def.Nodes {
+		if NodeType(n.NodeType) != NodeTypeJoin {
+			continue
+		}
+		for _, e := range in[n.NodeKey] {
+			if e.FromNodeKey == "" || len(in[e.FromNodeKey]) != 1 || types[in[e.FromNodeKey][0].FromNodeKey] != NodeTypeParallel {
+				return fmt.Errorf("join node %s may only be entered from branch nodes", n.NodeKey)
+			}
+		}
+		if v, ok := n.Metadata[JoinCountKey]; ok {
+			count, err := strconv.Atoi(v)
+			if err != nil || count < 1 || count > joinBranches[n.NodeKey] {
+				return fmt.Errorf("join node %s: %s must be between 1 and its %d branches", n.NodeKey, JoinCountKey, joinBranches[n.NodeKey])
+			}
+		}
+	}
+	return nil
+}
+
+// checkWorkflowGraph checks that the workflow has an entry edge, that the
+// end can be reached from every node the entry leads to, so no execution is
+// stranded in a dead end, and that no edge leaves a node that can never be
+// reached. Nodes without edges may be kept unrouted for later use. The
+// start and end of the workflow are both the empty key.
+func checkWorkflowGraph(def *WorkflowDefinition) error {
+	forward := make(map[string][]string)
+	backward := make(map[string][]string)
+	for _, e := range def.Edges {
+		forward[e.FromNodeKey] = append(forward[e.FromNodeKey], e.ToNodeKey)
+		backward[e.ToNodeKey] = append(backward[e.ToNodeKey], e.FromNodeKey)
+	}
+	if len(forward[""]) == 0 {
+		return fmt.Errorf("no entry edge (an edge with an empty from_node_key)")
+	}
+	if len(backward[""]) == 0 {
+		return fmt.Errorf("no edge ends the workflow (an edge with an empty to_node_key)")
+	}
+
+	fromStart := reachable(forward)
+	toEnd := reachable(backward)
+	for _, e := range def.Edges {
+		if !fromStart[e.FromNodeKey] {
+			return fmt.Errorf("edge from %s is orphaned: node %s cannot be reached from the entry edge", e.FromNodeKey, e.FromNodeKey)
+		}
+	}
+	for _, n := range def.Nodes {
+		if fromStart[n.NodeKey] && !toEnd[n.NodeKey] {
+			return fmt.Errorf("node %s has no path to the end of the workflow", n.NodeKey)
+		}
+	}
+	return nil
+}
+
+// reachable returns the keys reachable from the empty key along next.
+func reachable(next map[string][]string) map[string]bool {
+	seen := map[string]bool{"": true}
+	queue := []string{""}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, to := range next[key] {
+			if !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return seen
+}
+
 // convertDefinitionToWorkflow converts a YAML definition to a Workflow model
 func convertDefinitionToWorkflow(def *WorkflowDefinition) *Workflow {
 	now := time.Now()
@@ -93,7 +307,7 @@ func convertDefinitionToWorkflow(def *WorkflowDefinition) *Workflow {
 		Description:  def.Description,
 		WorkflowType: def.WorkflowType,
 		IsDefault:    def.IsDefault,
-		ProjectID:    "", // Empty for global defaults
+		ProjectID:    def.ProjectID, // Empty for global defaults
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
@@ -136,6 +350,45 @@ func convertDefinitionToWorkflow(def *WorkflowDefinition) *Workflow {
 	return wf
 }
 
+// DefinitionFromWorkflow converts a Workflow back to the definition it was
+// made from, in the shape ParseWorkflowDefinition accepts.
+func DefinitionFromWorkflow(wf *Workflow) *WorkflowDefinition {
+	def := &WorkflowDefinition{
+		ID:           wf.ID,
+		Name:         wf.Name,
+		Description:  wf.Description,
+		WorkflowType: wf.WorkflowType,
+		IsDefault:    wf.IsDefault,
+		ProjectID:    wf.ProjectID,
+		Nodes:        []WorkflowNodeDefinition{},
+		Edges:        []WorkflowEdgeDefinition{},
+	}
+	for _, n := range wf.Nodes {
+		nodeDef := WorkflowNodeDefinition{
+			NodeKey:        n.NodeKey,
+			NodeType:       string(n.NodeType),
+			RoleRequired:   n.RoleRequired,
+			PersonaHint:    n.PersonaHint,
+			MaxAttempts:    n.MaxAttempts,
+			TimeoutMinutes: n.TimeoutMinutes,
+			Instructions:   n.Instructions,
+		}
+		if len(n.Metadata) > 0 {
+			nodeDef.Metadata = n.Metadata
+		}
+		def.Nodes = append(def.Nodes, nodeDef)
+	}
+	for _, e := range wf.Edges {
+		def.Edges = append(def.Edges, WorkflowEdgeDefinition{
+			FromNodeKey: e.FromNodeKey,
+			ToNodeKey:   e.ToNodeKey,
+			Condition:   string(e.Condition),
+			Priority:    e.Priority,
+		})
+	}
+	return def
+}
+
 // InstallDefaultWorkflows loads and installs default workflows into the database
 func InstallDefaultWorkflows(db Database, workflowsDir string) error {
 	workflows, err := LoadDefaultWorkflows(workflowsDir)
@@ -144,6 +397,12 @@ func InstallDefaultWorkflows(db Database, workflowsDir string) error {
 	}
 
 	for _, wf := range workflows {
+		// A default replaced through the API is left as it was saved.
+		if existing, err := db.GetWorkflow(wf.ID); err == nil && existing.Version > 1 {
+			log.Printf("[Workflow] Keeping %s at version %d saved through the API", wf.ID, existing.Version)
+			continue
+		}
+
 		// Insert workflow
 		if err := db.UpsertWorkflow(wf); err != nil {
 			log.Printf("[Workflow] Warning: failed to upsert workflow %s: %v", wf.ID, err)
@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWorkflowFromDefinition(t *testing.T) {
+	def := &WorkflowDefinition{
+		ID:           "wf-team-bug",
+		Name:         "Team Bug Workflow",
+		WorkflowType: "bug",
+		ProjectID:    "proj-1",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "investigate", NodeType: "task"},
+			{NodeKey: "review", NodeType: "approval"},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "investigate", Condition: "success"},
+			{FromNodeKey: "investigate", ToNodeKey: "review", Condition: "success"},
+			{FromNodeKey: "review", Condition: "approved"},
+		},
+	}
+	wf, err := NewWorkflowFromDefinition(def)
+	if err != nil {
+		t.Fatalf("NewWorkflowFromDefinition: %v", err)
+	}
+	if wf.ProjectID != "proj-1" {
+		t.Errorf("ProjectID = %q, want proj-1", wf.ProjectID)
+	}
+	if len(wf.Nodes) != 2 || len(wf.Edges) != 3 {
+		t.Fatalf("got %d nodes and %d edges, want 2 and 3", len(wf.Nodes), len(wf.Edges))
+	}
+	for _, n := range wf.Nodes {
+		if n.WorkflowID != def.ID || n.Metadata == nil {
+			t.Errorf("node %s: WorkflowID=%q Metadata=%v", n.NodeKey, n.WorkflowID, n.Metadata)
+		}
+	}
+}
+
+func TestNewWorkflowFromDefinition_Invalid(t *testing.T) {
+	valid := func() *WorkflowDefinition {
+		return &WorkflowDefinition{
+			ID: "wf-x", Name: "X", WorkflowType: "custom",
+			Nodes: []WorkflowNodeDefinition{{NodeKey: "a", NodeType: "task"}},
+			Edges: []WorkflowEdgeDefinition{
+				{ToNodeKey: "a", Condition: "success"},
+				{FromNodeKey: "a", Condition: "success"},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		mutate func(*WorkflowDefinition)
+		want   string
+	}{
+		{"missing id", func(d *WorkflowDefinition) { d.ID = "" }, "required"},
+		{"missing type", func(d *WorkflowDefinition) { d.WorkflowType = "" }, "required"},
+		{"empty node key", func(d *WorkflowDefinition) { d.Nodes[0].NodeKey = "" }, "node_key is required"},
+		{"duplicate node", func(d *WorkflowDefinition) { d.Nodes = append(d.Nodes, d.Nodes[0]) }, "duplicate node_key"},
+		{"unknown edge node", func(d *WorkflowDefinition) { d.Edges[0].ToNodeKey = "b" }, "unknown node"},
+		{"edge without condition", func(d *WorkflowDefinition) { d.Edges[0].Condition = "" }, "no condition"},
+		{"unknown condition", func(d *WorkflowDefinition) { d.Edges[0].Condition = "done" }, "unknown condition"},
+		{"unknown node type", func(d *WorkflowDefinition) { d.Nodes[0].NodeType = "review" }, "unknown node_type"},
+		{"no entry", func(d *WorkflowDefinition) { d.Edges = d.Edges[1:] }, "no entry edge"},
+		{"no end", func(d *WorkflowDefinition) { d.Edges = d.Edges[:1] }, "no edge ends"},
+		{"unreachable node", func(d *WorkflowDefinition) {
+			d.Nodes = append(d.Nodes, WorkflowNodeDefinition{NodeKey: "b", NodeType: "task"})
+			d.Edges = append(d.Edges, WorkflowEdgeDefinition{FromNodeKey: "b", Condition: "success"})
+		}, "edge from b is orphaned"},
+		{"dead end", func(d *WorkflowDefinition) {
+			d.Nodes = append(d.Nodes, WorkflowNodeDefinition{NodeKey: "b", NodeType: "task"})
+			d.Edges = append(d.Edges, WorkflowEdgeDefinition{FromNodeKey: "a", ToNodeKey: "b", Condition: "failure"})
+		}, "node b has no path to the end"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := valid()
+			tt.mutate(def)
+			_, err := NewWorkflowFromDefinition(def)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := NewWorkflowFromDefinition(nil); err == nil {
+		t.Error("expected error for nil definition")
+	}
+}
+
+func TestParseWorkflowDefinition(t *testing.T) {
+	def, err := ParseWorkflowDefinition([]byte(`{"id": "wf-x", "name": "X", "workflow_type": "custom"}`))
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if def.ID != "wf-x" || def.WorkflowType != "custom" {
+		t.Errorf("got %+v", def)
+	}
+
+	if _, err := ParseWorkflowDefinition([]byte("id: [wf-x")); err == nil {
+		t.Error("expected error for malformed YAML")
+	}
+	if _, err := ParseWorkflowDefinition(nil); err == nil {
+		t.Error("expected error for empty definition")
+	}
+}
+
+func TestDefaultWorkflowsAreValid(t *testing.T) {
+	files, err := filepath.Glob("../../workflows/defaults/*.yaml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no default workflows found: %v", err)
+	}
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		def, err := ParseWorkflowDefinition(data)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if _, err := NewWorkflowFromDefinition(def); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
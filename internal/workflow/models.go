@@ -12,6 +12,9 @@ const (
 	NodeTypeApproval NodeType = "approval" // Requires approval to proceed
 	NodeTypeCommit   NodeType = "commit"   // Git commit/push operation
 	NodeTypeVerify   NodeType = "verify"   // Verification/testing node
+	NodeTypeParallel NodeType = "parallel" // Fans out a child bead per branch edge
+	NodeTypeJoin     NodeType = "join"     // Waits for the branches of a parallel node
+	NodeTypeCI       NodeType = "ci"       // Waits for CI on the bead's branch
 )
 
 // EdgeCondition represents conditions for workflow transitions
@@ -24,6 +27,19 @@ const (
 	EdgeConditionRejected  EdgeCondition = "rejected"  // Approval rejected
 	EdgeConditionTimeout   EdgeCondition = "timeout"   // Node timed out
 	EdgeConditionEscalated EdgeCondition = "escalated" // Escalated to higher authority
+	EdgeConditionBranch    EdgeCondition = "branch"    // Parallel node to one of its branches
+	EdgeConditionCIPassed  EdgeCondition = "ci_passed" // Every CI check on the branch passed
+	EdgeConditionCIFailed  EdgeCondition = "ci_failed" // A CI check on the branch failed
+)
+
+// Conditions recorded in an execution's history for operator actions. They
+// are not valid on edges.
+const (
+	HistoryPaused     EdgeCondition = "paused"      // Execution paused
+	HistoryResumed    EdgeCondition = "resumed"     // Paused execution resumed
+	HistoryRolledBack EdgeCondition = "rolled_back" // Execution moved back to an earlier node
+
+	HistoryApprovalRequested EdgeCondition = "approval_requested" // Approval gate opened a decision for its approvers
 )
 
 // ExecutionStatus represents the status of a workflow execution
@@ -35,8 +51,31 @@ const (
 	ExecutionStatusCompleted ExecutionStatus = "completed" // Successfully finished
 	ExecutionStatusFailed    ExecutionStatus = "failed"    // Failed permanently
 	ExecutionStatusEscalated ExecutionStatus = "escalated" // Escalated to CEO
+	ExecutionStatusPaused    ExecutionStatus = "paused"    // Held by an operator; not dispatched or advanced
+
+	ExecutionStatusAwaitingApproval ExecutionStatus = "awaiting_approval" // At an approval gate until a person decides
+	ExecutionStatusAwaitingCI       ExecutionStatus = "awaiting_ci"       // At a ci node until the branch's checks finish
 )
 
+// BranchStatus is the state of one branch of a parallel node.
+type BranchStatus string
+
+const (
+	BranchStatusRunning   BranchStatus = "running"   // Child bead not finished yet
+	BranchStatusSucceeded BranchStatus = "succeeded" // Child bead closed
+	BranchStatusFailed    BranchStatus = "failed"    // Child bead closed as failed, or deleted
+	BranchStatusCancelled BranchStatus = "cancelled" // Join no longer waits for it
+)
+
+// JoinCountKey is the join node metadata key holding how many branches must
+// succeed before the join advances. Unset means all of them.
+const JoinCountKey = "join_count"
+
+// ApproversKey is the approval node metadata key listing, comma-separated,
+// the usernames that may decide the node. It makes the node a human approval
+// gate; "*" lets any user decide.
+const ApproversKey = "approvers"
+
 // Workflow represents a workflow definition
 type Workflow struct {
 	ID           string         `json:"id"`
@@ -45,12 +84,22 @@ type Workflow struct {
 	WorkflowType string         `json:"workflow_type"` // "bug", "feature", "ui", "custom"
 	IsDefault    bool           `json:"is_default"`    // Is this a default workflow?
 	ProjectID    string         `json:"project_id"`    // Empty for global defaults
+	Version      int            `json:"version"`       // Bumped each time the definition is saved through the API
 	Nodes        []WorkflowNode `json:"nodes"`
 	Edges        []WorkflowEdge `json:"edges"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 }
 
+// WorkflowVersion is a workflow's definition as saved at one version.
+type WorkflowVersion struct {
+	WorkflowID string             `json:"workflow_id"`
+	Version    int                `json:"version"`
+	SavedBy    string             `json:"saved_by,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Definition WorkflowDefinition `json:"definition"`
+}
+
 // WorkflowNode represents a node in the workflow
 type WorkflowNode struct {
 	ID             string            `json:"id"`
@@ -93,6 +142,18 @@ type WorkflowExecution struct {
 	LastNodeAt       time.Time       `json:"last_node_at"` // Last time node was updated
 }
 
+// WorkflowBranch tracks the child bead running one branch of a parallel
+// node for an execution. The execution waits, blocked, at JoinNodeKey.
+type WorkflowBranch struct {
+	ExecutionID string       `json:"execution_id"`
+	NodeKey     string       `json:"node_key"`      // Branch node the child bead runs
+	JoinNodeKey string       `json:"join_node_key"` // Join node the branch leads to
+	BeadID      string       `json:"bead_id"`       // Child bead
+	Status      BranchStatus `json:"status"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
 // WorkflowExecutionHistory represents an audit trail of workflow state changes
 type WorkflowExecutionHistory struct {
 	ID            string        `json:"id"`
@@ -102,5 +163,6 @@ type WorkflowExecutionHistory struct {
 	Condition     EdgeCondition `json:"condition"`      // Condition that was satisfied
 	ResultData    string        `json:"result_data"`    // JSON-encoded result data
 	AttemptNumber int           `json:"attempt_number"` // Which attempt was this?
+	CycleCount    int           `json:"cycle_count"`    // Execution's cycle count at the node
 	CreatedAt     time.Time     `json:"created_at"`
 }
@@ -0,0 +1,276 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Parallel execution: when an execution reaches a parallel node, each of the
+// node's branch edges leads to a branch node that runs as a child bead of
+// the execution's bead, all at once. Every branch node has a single success
+// edge to the same join node, where the execution waits, blocked, until
+// enough branches have finished for the join to decide: success once
+// join_count of them (all by default) have succeeded, failure once that can
+// no longer happen.
+
+// BranchBeads creates the child beads that run the branches of parallel
+// nodes and reports how they finished.
+type BranchBeads interface {
+	// CreateBranchBead creates a bead for node, a branch of exec's bead,
+	// and returns its ID.
+	CreateBranchBead(exec *WorkflowExecution, node *WorkflowNode) (string, error)
+	// BranchOutcome reports BranchStatusRunning until the bead is finished,
+	// then BranchStatusSucceeded or BranchStatusFailed.
+	BranchOutcome(beadID string) BranchStatus
+}
+
+// SetBranchBeads sets what runs the branches of parallel nodes. Without it,
+// executions cannot pass a parallel node.
+func (e *Engine) SetBranchBeads(b BranchBeads) {
+	e.branchBeads = b
+}
+
+// fanOut starts a child bead for each branch of a parallel node and leaves
+// the execution blocked at the branches' join.
+func (e *Engine) fanOut(exec *WorkflowExecution, parallel *WorkflowNode) error {
+	if e.branchBeads == nil {
+		return fmt.Errorf("cannot run parallel node %s: no branch bead creator configured", parallel.NodeKey)
+	}
+	wf, err := e.db.GetWorkflow(exec.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+	branches := branchNodes(wf, parallel.NodeKey)
+	if len(branches) == 0 {
+		return fmt.Errorf("parallel node %s has no branches", parallel.NodeKey)
+	}
+	joinKey := joinNodeKey(wf, branches[0].NodeKey)
+	if joinKey == "" {
+		return fmt.Errorf("branch %s of parallel node %s does not lead to a join", branches[0].NodeKey, parallel.NodeKey)
+	}
+
+	spawned := make(map[string]string, len(branches))
+	for i := range branches {
+		node := &branches[i]
+		beadID, err := e.branchBeads.CreateBranchBead(exec, node)
+		if err != nil {
+			return fmt.Errorf("failed to create bead for branch %s: %w", node.NodeKey, err)
+		}
+		branch := &WorkflowBranch{
+			ExecutionID: exec.ID,
+			NodeKey:     node.NodeKey,
+			JoinNodeKey: joinKey,
+			BeadID:      beadID,
+			Status:      BranchStatusRunning,
+		}
+		if err := e.db.UpsertWorkflowBranch(branch); err != nil {
+			return fmt.Errorf("failed to record branch %s: %w", node.NodeKey, err)
+		}
+		spawned[node.NodeKey] = beadID
+	}
+
+	at := *exec
+	at.CurrentNodeKey = parallel.NodeKey
+	at.NodeAttemptCount = 0
+	e.recordHistory(&at, EdgeConditionBranch, "system", spawned)
+
+	exec.CurrentNodeKey = joinKey
+	exec.Status = ExecutionStatusBlocked
+	exec.NodeAttemptCount = 0
+	exec.LastNodeAt = time.Now()
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+	e.updateBeadContext(exec, map[string]string{
+		"workflow_node":        joinKey,
+		"workflow_status":      string(ExecutionStatusBlocked),
+		"cycle_count":          fmt.Sprintf("%d", exec.CycleCount),
+		"redispatch_requested": "false",
+	})
+
+	log.Printf("[Workflow] Bead %s fanned out %d branches at %s; waiting at %s",
+		exec.BeadID, len(branches), parallel.NodeKey, joinKey)
+	return nil
+}
+
+// SyncBranches checks the child beads of every running branch and advances
+// the executions whose joins are now decided. It returns how many branches
+// finished.
+func (e *Engine) SyncBranches() (int, error) {
+	if e.branchBeads == nil {
+		return 0, nil
+	}
+	running, err := e.db.ListRunningWorkflowBranches()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list running branches: %w", err)
+	}
+
+	finished := 0
+	var executions []string
+	seen := make(map[string]bool)
+	for _, b := range running {
+		status := e.branchBeads.BranchOutcome(b.BeadID)
+		if status == BranchStatusRunning {
+			continue
+		}
+		b.Status = status
+		if err := e.db.UpsertWorkflowBranch(b); err != nil {
+			log.Printf("[Workflow] Warning: failed to update branch %s of %s: %v", b.NodeKey, b.ExecutionID, err)
+			continue
+		}
+		finished++
+		if !seen[b.ExecutionID] {
+			seen[b.ExecutionID] = true
+			executions = append(executions, b.ExecutionID)
+		}
+	}
+
+	for _, id := range executions {
+		if err := e.checkJoin(id); err != nil {
+			log.Printf("[Workflow] Warning: failed to join branches of %s: %v", id, err)
+		}
+	}
+	return finished, nil
+}
+
+// checkJoin advances an execution blocked at a join once its branches have
+// decided it.
+func (e *Engine) checkJoin(executionID string) error {
+	exec, err := e.db.GetWorkflowExecution(executionID)
+	if err != nil {
+		return err
+	}
+	if exec.Status != ExecutionStatusBlocked {
+		return nil // moved on already, e.g. by an operator
+	}
+	wf, err := e.db.GetWorkflow(exec.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+	var join *WorkflowNode
+	for i := range wf.Nodes {
+		if wf.Nodes[i].NodeKey == exec.CurrentNodeKey {
+			join = &wf.Nodes[i]
+		}
+	}
+	if join == nil {
+		return fmt.Errorf("join node not found: %s", exec.CurrentNodeKey)
+	}
+
+	branches, err := e.JoinBranches(exec)
+	if err != nil {
+		return err
+	}
+	succeeded, failed := 0, 0
+	for _, b := range branches {
+		switch b.Status {
+		case BranchStatusSucceeded:
+			succeeded++
+		case BranchStatusFailed:
+			failed++
+		}
+	}
+	required := JoinRequired(join, len(branches))
+
+	var condition EdgeCondition
+	switch {
+	case succeeded >= required:
+		condition = EdgeConditionSuccess
+	case len(branches)-failed < required:
+		condition = EdgeConditionFailure
+	default:
+		return nil
+	}
+
+	e.cancelBranches(exec)
+	exec.Status = ExecutionStatusActive
+	if err := e.db.UpsertWorkflowExecution(exec); err != nil {
+		return fmt.Errorf("failed to update workflow execution: %w", err)
+	}
+	resultData := map[string]string{
+		"branches_succeeded": strconv.Itoa(succeeded),
+		"branches_failed":    strconv.Itoa(failed),
+		"branches_required":  strconv.Itoa(required),
+	}
+	if condition == EdgeConditionFailure {
+		if _, err := e.GetNextNode(exec, condition); err != nil {
+			return e.escalateWorkflow(exec, fmt.Sprintf("Only %d of %d branches joining at %s can still succeed; %d required",
+				len(branches)-failed, len(branches), join.NodeKey, required))
+		}
+	}
+	log.Printf("[Workflow] Join %s for bead %s decided %s (%d succeeded, %d failed, %d required)",
+		join.NodeKey, exec.BeadID, condition, succeeded, failed, required)
+	return e.AdvanceWorkflow(exec.ID, condition, "system", resultData)
+}
+
+// JoinBranches returns the branches of the join an execution is at,
+// whatever their status.
+func (e *Engine) JoinBranches(exec *WorkflowExecution) ([]*WorkflowBranch, error) {
+	all, err := e.db.ListWorkflowBranches(exec.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	var branches []*WorkflowBranch
+	for _, b := range all {
+		if b.JoinNodeKey == exec.CurrentNodeKey {
+			branches = append(branches, b)
+		}
+	}
+	return branches, nil
+}
+
+// cancelBranches stops waiting for the branches of the join an execution
+// is at. Their child beads are left as they are.
+func (e *Engine) cancelBranches(exec *WorkflowExecution) {
+	branches, err := e.JoinBranches(exec)
+	if err != nil {
+		log.Printf("[Workflow] Warning: %v", err)
+		return
+	}
+	for _, b := range branches {
+		if b.Status != BranchStatusRunning {
+			continue
+		}
+		b.Status = BranchStatusCancelled
+		if err := e.db.UpsertWorkflowBranch(b); err != nil {
+			log.Printf("[Workflow] Warning: failed to cancel branch %s of %s: %v", b.NodeKey, exec.ID, err)
+		}
+	}
+}
+
+// JoinRequired returns how many of total branches must succeed for join to
+// advance: its join_count metadata, or all of them.
+func JoinRequired(join *WorkflowNode, total int) int {
+	if n, err := strconv.Atoi(join.Metadata[JoinCountKey]); err == nil && n > 0 && n < total {
+		return n
+	}
+	return total
+}
+
+// branchNodes returns the nodes the branch edges of a parallel node lead to.
+func branchNodes(wf *Workflow, parallelKey string) []WorkflowNode {
+	var nodes []WorkflowNode
+	for _, edge := range wf.Edges {
+		if edge.FromNodeKey != parallelKey || edge.Condition != EdgeConditionBranch {
+			continue
+		}
+		for _, node := range wf.Nodes {
+			if node.NodeKey == edge.ToNodeKey {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
+// joinNodeKey returns the join a branch node leads to.
+func joinNodeKey(wf *Workflow, branchKey string) string {
+	for _, edge := range wf.Edges {
+		if edge.FromNodeKey == branchKey && edge.Condition == EdgeConditionSuccess {
+			return edge.ToNodeKey
+		}
+	}
+	return ""
+}
@@ -0,0 +1,231 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// parallelDefinition fans out from plan to code, docs, and qa, which join
+// at merge; merge goes on to ship on success and back to plan on failure.
+func parallelDefinition(joinCount string) *WorkflowDefinition {
+	join := WorkflowNodeDefinition{NodeKey: "merge", NodeType: "join"}
+	if joinCount != "" {
+		join.Metadata = map[string]string{JoinCountKey: joinCount}
+	}
+	return &WorkflowDefinition{
+		ID: "wf-par", Name: "Parallel", WorkflowType: "feature",
+		Nodes: []WorkflowNodeDefinition{
+			{NodeKey: "plan", NodeType: "task", RoleRequired: "PM"},
+			{NodeKey: "fan", NodeType: "parallel"},
+			{NodeKey: "code", NodeType: "task", RoleRequired: "Engineer"},
+			{NodeKey: "docs", NodeType: "task", RoleRequired: "Writer"},
+			{NodeKey: "qa", NodeType: "verify", RoleRequired: "QA"},
+			join,
+			{NodeKey: "ship", NodeType: "commit"},
+		},
+		Edges: []WorkflowEdgeDefinition{
+			{ToNodeKey: "plan", Condition: "success"},
+			{FromNodeKey: "plan", ToNodeKey: "fan", Condition: "success"},
+			{FromNodeKey: "fan", ToNodeKey: "code", Condition: "branch"},
+			{FromNodeKey: "fan", ToNodeKey: "docs", Condition: "branch"},
+			{FromNodeKey: "fan", ToNodeKey: "qa", Condition: "branch"},
+			{FromNodeKey: "code", ToNodeKey: "merge", Condition: "success"},
+			{FromNodeKey: "docs", ToNodeKey: "merge", Condition: "success"},
+			{FromNodeKey: "qa", ToNodeKey: "merge", Condition: "success"},
+			{FromNodeKey: "merge", ToNodeKey: "ship", Condition: "success"},
+			{FromNodeKey: "merge", ToNodeKey: "plan", Condition: "failure"},
+			{FromNodeKey: "ship", Condition: "success"},
+		},
+	}
+}
+
+func TestParallelDefinition_Invalid(t *testing.T) {
+	if _, err := NewWorkflowFromDefinition(parallelDefinition("2")); err != nil {
+		t.Fatalf("valid parallel workflow rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*WorkflowDefinition)
+		want   string
+	}{
+		{"branch edge from task", func(d *WorkflowDefinition) { d.Edges[1].Condition = "branch" }, "branch edges"},
+		{"success edge from parallel", func(d *WorkflowDefinition) { d.Edges[2].Condition = "success" }, "branch edges"},
+		{"approval branch", func(d *WorkflowDefinition) { d.Nodes[3].NodeType = "approval" }, "task, commit, or verify"},
+		{"branch skips the join", func(d *WorkflowDefinition) { d.Edges[6].ToNodeKey = "ship" }, "success edge to a join"},
+		{"branch entered twice", func(d *WorkflowDefinition) {
+			d.Edges = append(d.Edges, WorkflowEdgeDefinition{FromNodeKey: "ship", ToNodeKey: "code", Condition: "failure"})
+		}, "entered only from parallel"},
+		{"join from a task", func(d *WorkflowDefinition) { d.Edges[1].ToNodeKey = "merge" }, "only be entered from branch"},
+		{"join count too high", func(d *WorkflowDefinition) { d.Nodes[5].Metadata = map[string]string{JoinCountKey: "4"} }, "between 1 and its 3"},
+		{"join count not a number", func(d *WorkflowDefinition) { d.Nodes[5].Metadata = map[string]string{JoinCountKey: "most"} }, JoinCountKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := parallelDefinition("")
+			tt.mutate(def)
+			_, err := NewWorkflowFromDefinition(def)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// fakeBranchBeads creates numbered child beads whose outcomes the test sets.
+type fakeBranchBeads struct {
+	created  map[string]string // branch node -> bead ID
+	outcomes map[string]BranchStatus
+}
+
+func (f *fakeBranchBeads) CreateBranchBead(exec *WorkflowExecution, node *WorkflowNode) (string, error) {
+	id := fmt.Sprintf("%s-%s", exec.BeadID, node.NodeKey)
+	f.created[node.NodeKey] = id
+	return id, nil
+}
+
+func (f *fakeBranchBeads) BranchOutcome(beadID string) BranchStatus {
+	if s, ok := f.outcomes[beadID]; ok {
+		return s
+	}
+	return BranchStatusRunning
+}
+
+// newParallelEngine starts an execution for bead-1 and advances it past
+// plan, so it is blocked at merge with its three branches running.
+func newParallelEngine(t *testing.T, joinCount string) (*Engine, *mockDatabase, *mockBeadManager, *fakeBranchBeads) {
+	t.Helper()
+	wf, err := NewWorkflowFromDefinition(parallelDefinition(joinCount))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newMockDatabase()
+	db.workflows[wf.ID] = wf
+	beads := newMockBeadManager()
+	branches := &fakeBranchBeads{created: map[string]string{}, outcomes: map[string]BranchStatus{}}
+	engine := NewEngine(db, beads)
+	engine.SetBranchBeads(branches)
+
+	exec, err := engine.StartWorkflow("bead-1", wf.ID, "proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := engine.AdvanceWorkflow(exec.ID, EdgeConditionSuccess, "agent-1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine, db, beads, branches
+}
+
+func TestFanOut(t *testing.T) {
+	engine, db, beads, branches := newParallelEngine(t, "")
+	exec := db.beadExecutions["bead-1"]
+
+	if exec.Status != ExecutionStatusBlocked || exec.CurrentNodeKey != "merge" {
+		t.Fatalf("after fan-out: status %s at %q, want blocked at merge", exec.Status, exec.CurrentNodeKey)
+	}
+	if len(branches.created) != 3 || len(db.branches) != 3 {
+		t.Fatalf("created %v, recorded %d branches", branches.created, len(db.branches))
+	}
+	if ctx := beadContext(beads, "bead-1"); ctx["workflow_status"] != "blocked" || ctx["redispatch_requested"] != "false" {
+		t.Errorf("bead context = %v", ctx)
+	}
+	history := db.history[exec.ID]
+	if last := history[len(history)-1]; last.NodeKey != "fan" || last.Condition != EdgeConditionBranch {
+		t.Errorf("last history entry = %+v, want the fan-out", last)
+	}
+	if err := engine.AdvanceWorkflow(exec.ID, EdgeConditionSuccess, "agent-1", nil); err == nil {
+		t.Error("expected a blocked execution to refuse to advance")
+	}
+
+	// Without a way to create child beads, a parallel node is an error.
+	plain := NewEngine(db, beads)
+	exec2, _ := plain.StartWorkflow("bead-2", "wf-par", "proj-1")
+	_ = plain.AdvanceWorkflow(exec2.ID, EdgeConditionSuccess, "agent-1", nil)
+	if err := plain.AdvanceWorkflow(exec2.ID, EdgeConditionSuccess, "agent-1", nil); err == nil {
+		t.Error("expected an error reaching a parallel node with no branch bead creator")
+	}
+}
+
+func TestJoin_All(t *testing.T) {
+	engine, db, _, branches := newParallelEngine(t, "")
+	exec := db.beadExecutions["bead-1"]
+
+	branches.outcomes["bead-1-code"] = BranchStatusSucceeded
+	branches.outcomes["bead-1-docs"] = BranchStatusSucceeded
+	if n, err := engine.SyncBranches(); err != nil || n != 2 {
+		t.Fatalf("SyncBranches = %d, %v; want 2", n, err)
+	}
+	if exec.Status != ExecutionStatusBlocked {
+		t.Fatalf("joined with a branch still running: status %s", exec.Status)
+	}
+
+	branches.outcomes["bead-1-qa"] = BranchStatusSucceeded
+	if n, _ := engine.SyncBranches(); n != 1 {
+		t.Fatalf("SyncBranches = %d, want 1", n)
+	}
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "ship" {
+		t.Errorf("after join: status %s at %q, want active at ship", exec.Status, exec.CurrentNodeKey)
+	}
+}
+
+func TestJoin_Quorum(t *testing.T) {
+	engine, db, _, branches := newParallelEngine(t, "2")
+	exec := db.beadExecutions["bead-1"]
+
+	branches.outcomes["bead-1-code"] = BranchStatusSucceeded
+	branches.outcomes["bead-1-qa"] = BranchStatusSucceeded
+	if _, err := engine.SyncBranches(); err != nil {
+		t.Fatal(err)
+	}
+	if exec.CurrentNodeKey != "ship" {
+		t.Fatalf("2 of 3 with join_count 2: at %q, want ship", exec.CurrentNodeKey)
+	}
+	for _, b := range db.branches {
+		if b.NodeKey == "docs" && b.Status != BranchStatusCancelled {
+			t.Errorf("leftover branch status = %s, want cancelled", b.Status)
+		}
+	}
+}
+
+func TestJoin_Failure(t *testing.T) {
+	engine, db, _, branches := newParallelEngine(t, "2")
+	exec := db.beadExecutions["bead-1"]
+
+	branches.outcomes["bead-1-code"] = BranchStatusFailed
+	if _, err := engine.SyncBranches(); err != nil {
+		t.Fatal(err)
+	}
+	if exec.Status != ExecutionStatusBlocked {
+		t.Fatalf("gave up while 2 of 3 could still succeed: status %s", exec.Status)
+	}
+	branches.outcomes["bead-1-docs"] = BranchStatusFailed
+	if _, err := engine.SyncBranches(); err != nil {
+		t.Fatal(err)
+	}
+	if exec.Status != ExecutionStatusActive || exec.CurrentNodeKey != "plan" {
+		t.Errorf("after failed join: status %s at %q, want active at plan", exec.Status, exec.CurrentNodeKey)
+	}
+}
+
+func TestSkipNode_Join(t *testing.T) {
+	engine, db, _, _ := newParallelEngine(t, "")
+	exec := db.beadExecutions["bead-1"]
+
+	if _, err := engine.SkipNode(exec.ID, "", "alice", "merged by hand"); err != nil {
+		t.Fatalf("SkipNode: %v", err)
+	}
+	if exec.CurrentNodeKey != "ship" {
+		t.Errorf("at %q, want ship", exec.CurrentNodeKey)
+	}
+	for _, b := range db.branches {
+		if b.Status != BranchStatusCancelled {
+			t.Errorf("branch %s status = %s, want cancelled", b.NodeKey, b.Status)
+		}
+	}
+	if _, err := engine.RollbackExecution(exec.ID, "fan", "alice", ""); err == nil {
+		t.Error("expected error rolling back to a parallel node")
+	}
+}
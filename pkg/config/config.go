@@ -29,24 +29,43 @@ type Provider struct {
 // and JSON-based configuration (for user-specific config using LoadConfig).
 type Config struct {
 	// YAML/File-based configuration fields
-	Server        ServerConfig    `yaml:"server" json:"server,omitempty"`
-	Database      DatabaseConfig  `yaml:"database" json:"database,omitempty"`
-	Beads         BeadsConfig     `yaml:"beads" json:"beads,omitempty"`
-	Agents        AgentsConfig    `yaml:"agents" json:"agents,omitempty"`
-	Security      SecurityConfig  `yaml:"security" json:"security,omitempty"`
-	Cache         CacheConfig     `yaml:"cache" json:"cache,omitempty"`
-	Readiness     ReadinessConfig `yaml:"readiness" json:"readiness,omitempty"`
-	Dispatch      DispatchConfig  `yaml:"dispatch" json:"dispatch,omitempty"`
-	Git           GitConfig       `yaml:"git" json:"git,omitempty"`
-	Models        ModelsConfig    `yaml:"models" json:"models,omitempty"`
-	Projects      []ProjectConfig `yaml:"projects" json:"projects,omitempty"`
-	SelfProjectID string          `yaml:"self_project_id" json:"self_project_id,omitempty"`
-	WebUI         WebUIConfig     `yaml:"web_ui" json:"web_ui,omitempty"`
-	Temporal      TemporalConfig  `yaml:"temporal" json:"temporal,omitempty"`
-	HotReload     HotReloadConfig `yaml:"hot_reload" json:"hot_reload,omitempty"`
-	OpenClaw      OpenClawConfig  `yaml:"openclaw" json:"openclaw,omitempty"`
-	PDA           PDAConfig       `yaml:"pda" json:"pda,omitempty"`
-	Swarm         SwarmConfig     `yaml:"swarm" json:"swarm,omitempty"`
+	Server        ServerConfig     `yaml:"server" json:"server,omitempty"`
+	Database      DatabaseConfig   `yaml:"database" json:"database,omitempty"`
+	Beads         BeadsConfig      `yaml:"beads" json:"beads,omitempty"`
+	Agents        AgentsConfig     `yaml:"agents" json:"agents,omitempty"`
+	Security      SecurityConfig   `yaml:"security" json:"security,omitempty"`
+	Cache         CacheConfig      `yaml:"cache" json:"cache,omitempty"`
+	Readiness     ReadinessConfig  `yaml:"readiness" json:"readiness,omitempty"`
+	Dispatch      DispatchConfig   `yaml:"dispatch" json:"dispatch,omitempty"`
+	Git           GitConfig        `yaml:"git" json:"git,omitempty"`
+	Models        ModelsConfig     `yaml:"models" json:"models,omitempty"`
+	Projects      []ProjectConfig  `yaml:"projects" json:"projects,omitempty"`
+	SelfProjectID string           `yaml:"self_project_id" json:"self_project_id,omitempty"`
+	WebUI         WebUIConfig      `yaml:"web_ui" json:"web_ui,omitempty"`
+	Temporal      TemporalConfig   `yaml:"temporal" json:"temporal,omitempty"`
+	HotReload     HotReloadConfig  `yaml:"hot_reload" json:"hot_reload,omitempty"`
+	OpenClaw      OpenClawConfig   `yaml:"openclaw" json:"openclaw,omitempty"`
+	Slack         SlackConfig      `yaml:"slack" json:"slack,omitempty"`
+	PDA           PDAConfig        `yaml:"pda" json:"pda,omitempty"`
+	Swarm         SwarmConfig      `yaml:"swarm" json:"swarm,omitempty"`
+	Sandbox       SandboxConfig    `yaml:"sandbox" json:"sandbox,omitempty"`
+	Containers    ContainersConfig `yaml:"containers" json:"containers,omitempty"`
+	KeyStore      KeyStoreConfig   `yaml:"key_store" json:"key_store,omitempty"`
+	Audit         AuditConfig      `yaml:"audit" json:"audit,omitempty"`
+	SelfAudit     SelfAuditConfig  `yaml:"self_audit" json:"self_audit,omitempty"`
+	DepBot        DepBotConfig     `yaml:"dependency_bot" json:"dependency_bot,omitempty"`
+	CI            CIConfig         `yaml:"ci" json:"ci,omitempty"`
+	Reports       ReportsConfig    `yaml:"reports" json:"reports,omitempty"`
+	Events        EventsConfig     `yaml:"events" json:"events,omitempty"`
+	Logging       LoggingConfig    `yaml:"logging" json:"logging,omitempty"`
+	Exports       []ExportConfig   `yaml:"exports" json:"exports,omitempty"`
+	Backup        BackupConfig     `yaml:"backup" json:"backup,omitempty"`
+	Memory        MemoryConfig     `yaml:"memory" json:"memory,omitempty"`
+	Executor      ExecutorConfig   `yaml:"executor" json:"executor,omitempty"`
+	AutoFix       AutoFixConfig    `yaml:"auto_fix" json:"auto_fix,omitempty"`
+
+	// Channels notifications are delivered on besides the in-app feed
+	Notifications NotificationsConfig `yaml:"notifications" json:"notifications,omitempty"`
 
 	// Debug instrumentation level: "off" | "standard" | "extreme"
 	// See docs/DEBUG.md for full documentation.
@@ -83,12 +102,37 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// BasePath serves loom under a path prefix, e.g. "/loom" behind a
+	// reverse proxy. Requests without the prefix are still served, for
+	// proxies that strip it and for health probes.
+	BasePath string `yaml:"base_path"`
+	// TrustedProxies are the addresses or CIDR ranges of the reverse
+	// proxies whose X-Forwarded-For and X-Forwarded-Proto are believed.
+	TrustedProxies []string   `yaml:"trusted_proxies"`
+	CORS           CORSConfig `yaml:"cors"`
 }
 
-// DatabaseConfig configures the PostgreSQL database connection
+// CORSConfig configures cross-origin requests to the API. An origin may
+// use a wildcard subdomain, e.g. "https://*.example.com"; "*" allows any.
+type CORSConfig struct {
+	// AllowedOrigins replaces security.allowed_origins when set.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// AllowedHeaders are allowed on top of Content-Type, Authorization,
+	// X-API-Key and X-Loom-Org.
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"` // how long browsers may cache a preflight
+}
+
+// DatabaseConfig configures the database connection
 type DatabaseConfig struct {
-	Type string `yaml:"type"` // "postgres" (only supported value)
+	Type string `yaml:"type"` // "postgres" or "sqlite"
 	DSN  string `yaml:"dsn"`  // PostgreSQL DSN (optional; env vars used if empty)
+	Path string `yaml:"path"` // SQLite database file (default ./data/loom.db)
+	// ManualMigrations leaves schema migrations for an operator to review
+	// and apply with `loomctl admin migrate up` instead of running them at
+	// startup.
+	ManualMigrations bool `yaml:"manual_migrations"`
 }
 
 // BeadsConfig configures beads integration
@@ -101,6 +145,21 @@ type BeadsConfig struct {
 	BeadsBranch    string                `yaml:"beads_branch"`     // Global default for beads branch
 	UseGitStorage  bool                  `yaml:"use_git_storage"`  // Enable git-centric storage (default: true)
 	Federation     BeadsFederationConfig `yaml:"federation"`
+	Attachments    AttachmentsConfig     `yaml:"attachments"`
+	// TrashRetention is how long deleted beads can be restored before they
+	// are purged (default 30 days). A negative value keeps them forever.
+	TrashRetention time.Duration `yaml:"trash_retention"`
+	// EscalateOverdue hands beads that pass their due date to the CEO for a
+	// decision, besides raising their priority.
+	EscalateOverdue bool `yaml:"escalate_overdue"`
+}
+
+// AttachmentsConfig configures storage for files attached to beads
+type AttachmentsConfig struct {
+	Storage      string   `yaml:"storage"`        // "disk" (default) or "postgres" (large objects)
+	Dir          string   `yaml:"dir"`            // Directory for disk storage (default ./data/attachments)
+	MaxSizeBytes int64    `yaml:"max_size_bytes"` // Per-file limit (default 10 MiB)
+	AllowedTypes []string `yaml:"allowed_types"`  // Accepted content types; "image/*" style wildcards allowed
 }
 
 // BeadsFederationConfig configures peer-to-peer federation
@@ -129,6 +188,11 @@ type AgentsConfig struct {
 	FileLockTimeout    time.Duration `yaml:"file_lock_timeout"`
 	CorpProfile        string        `yaml:"corp_profile" json:"corp_profile,omitempty"`
 	AllowedRoles       []string      `yaml:"allowed_roles" json:"allowed_roles,omitempty"`
+	// SummaryProvider and SummaryModel pick the (cheap) model that
+	// summarizes long conversations nearing the context window. Unset, an
+	// agent summarizes with its own provider.
+	SummaryProvider string `yaml:"summary_provider" json:"summary_provider,omitempty"`
+	SummaryModel    string `yaml:"summary_model" json:"summary_model,omitempty"`
 }
 
 // ReadinessConfig controls readiness gating behavior
@@ -140,6 +204,30 @@ type ReadinessConfig struct {
 type DispatchConfig struct {
 	MaxHops         int  `yaml:"max_hops" json:"max_hops,omitempty"`
 	UseNATSDispatch bool `yaml:"use_nats_dispatch" json:"use_nats_dispatch,omitempty"`
+	// PriorityAging raises a ready bead's priority one level for every
+	// interval it waits, so low-priority work can't starve behind a steady
+	// stream of urgent beads. 0 disables aging.
+	PriorityAging time.Duration `yaml:"priority_aging" json:"priority_aging,omitempty"`
+	// LoopDetection tunes the detectors that flag beads stuck in a loop.
+	LoopDetection LoopDetectionConfig `yaml:"loop_detection" json:"loop_detection,omitempty"`
+}
+
+// LoopDetectionConfig sets the loop detectors' thresholds. A zero count
+// uses the built-in default.
+type LoopDetectionConfig struct {
+	// RepeatedErrors is how many identical run errors flag a bead.
+	RepeatedErrors int `yaml:"repeated_errors" json:"repeated_errors,omitempty"`
+	// RepeatedActions is how many identical actions in a row, without
+	// progress, flag a bead.
+	RepeatedActions int `yaml:"repeated_actions" json:"repeated_actions,omitempty"`
+	// NoProgressEdits is how many edits that repeat or undo an earlier
+	// edit flag a bead.
+	NoProgressEdits int `yaml:"no_progress_edits" json:"no_progress_edits,omitempty"`
+	// BuildOscillations is how many pass/fail flips of a build or test
+	// flag a bead that ends failing.
+	BuildOscillations int `yaml:"build_oscillations" json:"build_oscillations,omitempty"`
+	// Disabled names detectors that are not run.
+	Disabled []string `yaml:"disabled" json:"disabled,omitempty"`
 }
 
 // PDAConfig configures the Plan/Document/Act orchestrator
@@ -165,6 +253,9 @@ type GitConfig struct {
 // ModelsConfig configures model preferences for provider negotiation
 type ModelsConfig struct {
 	PreferredModels []PreferredModel `yaml:"preferred_models" json:"preferred_models,omitempty"`
+	// Pricing is the USD cost per million tokens of each model, by model
+	// name. Analytics prices model calls with it.
+	Pricing map[string]float64 `yaml:"pricing" json:"pricing,omitempty"`
 }
 
 // PreferredModel represents a model preference for negotiation with providers.
@@ -179,14 +270,325 @@ type PreferredModel struct {
 
 // SecurityConfig configures authentication and authorization
 type SecurityConfig struct {
-	EnableAuth     bool     `yaml:"enable_auth"`
-	PKIEnabled     bool     `yaml:"pki_enabled"`
-	CAFile         string   `yaml:"ca_file"`
-	RequireHTTPS   bool     `yaml:"require_https"`
-	AllowedOrigins []string `yaml:"allowed_origins"` // CORS
-	APIKeys        []string `yaml:"api_keys,omitempty"`
-	JWTSecret      string   `yaml:"jwt_secret" json:"jwt_secret,omitempty"`
-	WebhookSecret  string   `yaml:"webhook_secret" json:"webhook_secret,omitempty"` // GitHub webhook secret
+	EnableAuth     bool            `yaml:"enable_auth"`
+	PKIEnabled     bool            `yaml:"pki_enabled"`
+	CAFile         string          `yaml:"ca_file"`
+	RequireHTTPS   bool            `yaml:"require_https"`   // redirect plain HTTP, as the client or a trusted proxy saw it
+	AllowedOrigins []string        `yaml:"allowed_origins"` // CORS
+	APIKeys        []string        `yaml:"api_keys,omitempty"`
+	JWTSecret      string          `yaml:"jwt_secret" json:"jwt_secret,omitempty"`
+	WebhookSecret  string          `yaml:"webhook_secret" json:"webhook_secret,omitempty"`   // GitHub webhook secret
+	GitHookSecret  string          `yaml:"git_hook_secret" json:"git_hook_secret,omitempty"` // Secret of the git push/PR hook
+	RateLimits     RateLimitConfig `yaml:"rate_limits" json:"rate_limits,omitempty"`
+	AgentMTLS      AgentMTLSConfig `yaml:"agent_mtls" json:"agent_mtls,omitempty"`
+	OIDC           OIDCConfig      `yaml:"oidc" json:"oidc,omitempty"`
+}
+
+// OIDCConfig configures single sign-on with an OpenID Connect provider
+// such as Okta, Azure AD, or Google, alongside the password login. Users
+// are created on their first sign-in with the role their groups map to.
+type OIDCConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Name is shown on the sign-in button, e.g. "Okta". Default "SSO".
+	Name string `yaml:"name" json:"name,omitempty"`
+	// IssuerURL is where the provider's discovery document is found, under
+	// /.well-known/openid-configuration.
+	IssuerURL    string `yaml:"issuer_url" json:"issuer_url"`
+	ClientID     string `yaml:"client_id" json:"client_id"`
+	ClientSecret string `yaml:"client_secret" json:"client_secret,omitempty"`
+	// RedirectURL is this server's callback, registered with the provider:
+	// https://<host>[/<base path>]/api/v1/auth/oidc/callback.
+	RedirectURL string `yaml:"redirect_url" json:"redirect_url"`
+	// Scopes requested besides openid. Default profile and email.
+	Scopes []string `yaml:"scopes" json:"scopes,omitempty"`
+	// UsernameClaim names the claim users are known by. Default
+	// preferred_username, falling back to email and then sub.
+	UsernameClaim string `yaml:"username_claim" json:"username_claim,omitempty"`
+	// GroupsClaim names the claim listing the user's groups. Default groups.
+	GroupsClaim string `yaml:"groups_claim" json:"groups_claim,omitempty"`
+	// RoleMapping maps groups to roles. A user in several mapped groups
+	// gets the most privileged of their roles.
+	RoleMapping map[string]string `yaml:"role_mapping" json:"role_mapping,omitempty"`
+	// DefaultRole is given to users none of whose groups are mapped. Empty
+	// refuses them.
+	DefaultRole string `yaml:"default_role" json:"default_role,omitempty"`
+	// AllowedDomains, if set, limits sign-in to verified email addresses
+	// in these domains.
+	AllowedDomains []string `yaml:"allowed_domains" json:"allowed_domains,omitempty"`
+}
+
+// AgentMTLSConfig configures mutual TLS between project agent containers
+// and the control plane. The control plane issues each container a client
+// certificate naming its project and only accepts project agent calls made
+// with a matching certificate.
+type AgentMTLSConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Port is the TLS listener agents connect to. Default 8443.
+	Port int `yaml:"port" json:"port,omitempty"`
+	// CADir holds the CA certificate and key, created on first start.
+	// Default /app/data/pki.
+	CADir string `yaml:"ca_dir" json:"ca_dir,omitempty"`
+	// CertTTL is how long issued certificates live. They are re-issued
+	// once two thirds of that has passed. Default 24h.
+	CertTTL time.Duration `yaml:"cert_ttl" json:"cert_ttl,omitempty"`
+	// ServerNames are the host names and addresses the control plane's
+	// certificate is issued for. Default loom and localhost.
+	ServerNames []string `yaml:"server_names" json:"server_names,omitempty"`
+}
+
+// RateLimitConfig configures API rate limits. Requests over a limit get
+// 429 with Retry-After.
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// PerToken limits each API token across every route. Sessions and API
+	// keys are limited per user.
+	PerToken RateLimit `yaml:"per_token" json:"per_token,omitempty"`
+	// PerIP limits each client address, signed in or not.
+	PerIP RateLimit `yaml:"per_ip" json:"per_ip,omitempty"`
+	// RouteGroups limits each caller within a route group, keyed by the
+	// group's RBAC resource, e.g. "beads" or "providers".
+	RouteGroups map[string]RateLimit `yaml:"route_groups" json:"route_groups,omitempty"`
+	// AdminBypass exempts callers with the admin role from every limit.
+	AdminBypass bool `yaml:"admin_bypass" json:"admin_bypass,omitempty"`
+}
+
+// RateLimit is a token bucket: Rate requests per second on average, in
+// bursts of up to Burst. A zero Rate is unlimited.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" json:"burst,omitempty"` // default: Rate, rounded up
+}
+
+// AuditConfig configures the audit log of mutating API requests
+type AuditConfig struct {
+	// Retention is how long entries are kept (default 90 days). A negative
+	// value keeps them forever.
+	Retention time.Duration `yaml:"retention" json:"retention,omitempty"`
+	// ResourceRetention overrides Retention per resource type, e.g.
+	// "providers" or "secrets".
+	ResourceRetention map[string]time.Duration `yaml:"resource_retention" json:"resource_retention,omitempty"`
+}
+
+// SelfAuditConfig configures the periodic self-audit, which runs checks over
+// a project's tree and files a bead for each new finding.
+type SelfAuditConfig struct {
+	// Interval between audits. 0 leaves self-audit off unless
+	// SELF_AUDIT_INTERVAL_MINUTES is set.
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty"`
+	// Checks names the checks to run: build, test, lint, vet, staticcheck,
+	// govulncheck and coverage. Empty runs build, test and lint.
+	Checks []string `yaml:"checks" json:"checks,omitempty"`
+	// CoverageThreshold is the average statement coverage, in percent,
+	// below which the coverage check files a bead.
+	CoverageThreshold float64 `yaml:"coverage_threshold" json:"coverage_threshold,omitempty"`
+	// Projects lists further project IDs to audit in their work
+	// directories, besides loom's own project.
+	Projects []string `yaml:"projects" json:"projects,omitempty"`
+}
+
+// DepBotConfig configures the dependency bot, which scans projects' go.mod,
+// package.json and requirements.txt for updates and files beads for them.
+type DepBotConfig struct {
+	// Interval between scans. 0 (default) leaves the bot off.
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty"`
+	// Projects limits the scan to these project IDs; empty scans all.
+	Projects []string `yaml:"projects" json:"projects,omitempty"`
+	// BranchLevels are the update levels (major, minor, patch) for which
+	// the bot applies the updates itself and opens a pull request.
+	BranchLevels []string `yaml:"branch_levels" json:"branch_levels,omitempty"`
+}
+
+// ReportsConfig schedules project digests: beads opened and closed,
+// velocity, spend, top errors and agent activity, sent out on the
+// notification channels when each period ends.
+type ReportsConfig struct {
+	// Periods to report on: day, week, or both. Empty (default) leaves
+	// scheduled reports off.
+	Periods []string `yaml:"periods" json:"periods,omitempty"`
+	// Projects limits the reports to these project IDs; empty covers all.
+	Projects []string `yaml:"projects" json:"projects,omitempty"`
+}
+
+// CIConfig configures how the CI checks on agents' branches are read for
+// workflow ci nodes. Projects read them from GitHub unless their
+// ci_provider setting is jenkins.
+type CIConfig struct {
+	Jenkins JenkinsConfig `yaml:"jenkins" json:"jenkins,omitempty"`
+}
+
+// JenkinsConfig locates the Jenkins server projects with ci_provider
+// jenkins build on. Each project names its multibranch job with
+// jenkins_job.
+type JenkinsConfig struct {
+	URL      string `yaml:"url" json:"url,omitempty"`
+	User     string `yaml:"user" json:"user,omitempty"`
+	APIToken string `yaml:"api_token" json:"-"` // Defaults to JENKINS_API_TOKEN
+}
+
+// EventsConfig configures the durable event store
+type EventsConfig struct {
+	// Retention is how long stored events are kept (default 30 days). A
+	// negative value keeps them forever.
+	Retention time.Duration `yaml:"retention" json:"retention,omitempty"`
+}
+
+// MemoryConfig configures agent memory retrieval: closed beads, lessons
+// and excerpts of key project files are embedded, and the ones most
+// relevant to a bead are added to the agent's prompt. It is off by default.
+type MemoryConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// EmbeddingEndpoint is an OpenAI-compatible base URL serving
+	// /v1/embeddings. Without one, a local hashing embedder is used.
+	EmbeddingEndpoint string `yaml:"embedding_endpoint" json:"embedding_endpoint,omitempty"`
+	EmbeddingAPIKey   string `yaml:"embedding_api_key" json:"-"`
+	EmbeddingModel    string `yaml:"embedding_model" json:"embedding_model,omitempty"`
+	// TopK is how many memories are added to a prompt (default 5).
+	TopK int `yaml:"top_k" json:"top_k,omitempty"`
+	// IndexInterval is how often new history is indexed (default 10m).
+	IndexInterval time.Duration `yaml:"index_interval" json:"index_interval,omitempty"`
+	// Files are the project files, relative to the repository root, whose
+	// excerpts are indexed. Defaults to README, CONTRIBUTING, AGENTS and
+	// ARCHITECTURE docs.
+	Files []string `yaml:"files" json:"files,omitempty"`
+}
+
+// ExecutorConfig sets the task executor's concurrency limits. Zero values
+// use the defaults; all three can be changed at runtime through the API,
+// and per project through project config.
+type ExecutorConfig struct {
+	// MaxConcurrentBeads is how many beads each project works on at once
+	// (default 5).
+	MaxConcurrentBeads int `yaml:"max_concurrent_beads" json:"max_concurrent_beads,omitempty"`
+	// MaxLLMCalls is how many model calls may be in flight across all
+	// projects (default 3).
+	MaxLLMCalls int `yaml:"max_llm_calls" json:"max_llm_calls,omitempty"`
+	// ClaimInterval is how often an idle worker looks for a bead to claim
+	// (default 5s).
+	ClaimInterval time.Duration `yaml:"claim_interval" json:"claim_interval,omitempty"`
+	// ShutdownGracePeriod is how long a shutdown waits for beads in flight
+	// to finish before checkpointing them to resume on restart (default 30s).
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period" json:"shutdown_grace_period,omitempty"`
+}
+
+// AutoFixConfig configures how code fix proposals are approved and applied.
+type AutoFixConfig struct {
+	Approval       AutoApprovalConfig   `yaml:"approval" json:"approval,omitempty"`
+	EnsembleReview EnsembleReviewConfig `yaml:"ensemble_review" json:"ensemble_review,omitempty"`
+}
+
+// AutoApprovalConfig is the policy deciding which code fix proposals are
+// approved without waiting for a person. Each proposal's patch is rated
+// low, medium or high risk, and the first rule it satisfies approves it.
+type AutoApprovalConfig struct {
+	// HighRiskPaths are globs of paths a fix touching is high risk. The
+	// defaults cover migrations, SQL, auth code, keys and secrets, CI
+	// workflows and container builds.
+	HighRiskPaths []string `yaml:"high_risk_paths" json:"high_risk_paths,omitempty"`
+	// LowRiskMaxFiles and LowRiskMaxLines bound a low-risk patch (default
+	// 1 file and 50 changed lines); larger ones are medium risk.
+	LowRiskMaxFiles int `yaml:"low_risk_max_files" json:"low_risk_max_files,omitempty"`
+	LowRiskMaxLines int `yaml:"low_risk_max_lines" json:"low_risk_max_lines,omitempty"`
+	// Rules approve proposals. Without any, low-risk proposals are
+	// approved.
+	Rules []AutoApprovalRule `yaml:"rules" json:"rules,omitempty"`
+}
+
+// AutoApprovalRule approves the proposals meeting all of its conditions.
+type AutoApprovalRule struct {
+	Name string `yaml:"name" json:"name"`
+	// Projects limits the rule to these project IDs; empty means all.
+	Projects []string `yaml:"projects" json:"projects,omitempty"`
+	// MaxRisk is the highest risk approved: low (default), medium or high.
+	MaxRisk string `yaml:"max_risk" json:"max_risk,omitempty"`
+	// MaxDiffLines and MaxFiles cap the patch's size; 0 is no limit.
+	MaxDiffLines int `yaml:"max_diff_lines" json:"max_diff_lines,omitempty"`
+	MaxFiles     int `yaml:"max_files" json:"max_files,omitempty"`
+	// AllowedPaths are globs every changed path must match.
+	AllowedPaths []string `yaml:"allowed_paths" json:"allowed_paths,omitempty"`
+	// ForbiddenPaths are globs no changed path may match.
+	ForbiddenPaths []string `yaml:"forbidden_paths" json:"forbidden_paths,omitempty"`
+	// RequireTestsPassing approves only fixes whose agent's last test run
+	// passed.
+	RequireTestsPassing bool `yaml:"require_tests_passing" json:"require_tests_passing,omitempty"`
+}
+
+// EnsembleReviewConfig sends risky code fix proposals to a second model for
+// an independent review before their apply-fix bead runs. An apply-fix
+// bead whose patch the reviewer rejects is created blocked. It is off by
+// default.
+type EnsembleReviewConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Provider is the ID of the reviewing provider. By default it is an
+	// active provider other than the proposing agent's.
+	Provider string `yaml:"provider" json:"provider,omitempty"`
+	// Model overrides the reviewing provider's model.
+	Model string `yaml:"model" json:"model,omitempty"`
+	// RiskLevels are the assessed risk levels reviewed (default high).
+	RiskLevels []string `yaml:"risk_levels" json:"risk_levels,omitempty"`
+	// Timeout bounds each review (default 2m).
+	Timeout time.Duration `yaml:"timeout" json:"timeout,omitempty"`
+}
+
+// LoggingConfig configures log levels and output
+type LoggingConfig struct {
+	// Level is the minimum level logged: debug, info (default), warn or error.
+	Level string `yaml:"level" json:"level,omitempty"`
+	// Subsystems overrides Level per subsystem, e.g. "dispatcher: debug".
+	Subsystems map[string]string `yaml:"subsystems" json:"subsystems,omitempty"`
+	// Output is where log lines are written as JSON: stderr (default),
+	// stdout, or none to only keep them in the log store.
+	Output string `yaml:"output" json:"output,omitempty"`
+	// Retention is how long stored log entries are kept (default 30 days).
+	// A negative value keeps them forever.
+	Retention time.Duration `yaml:"retention" json:"retention,omitempty"`
+	// MaxEntries caps the number of stored entries; the oldest are pruned
+	// first. 0 means no cap.
+	MaxEntries int `yaml:"max_entries" json:"max_entries,omitempty"`
+	// Archive saves entries somewhere else, as gzipped JSON lines, before
+	// they are pruned. Without a target they are discarded. The file target
+	// defaults to ./data/log-archive.
+	Archive ObjectStoreConfig `yaml:"archive" json:"archive,omitempty"`
+}
+
+// ObjectStoreConfig configures where objects such as log archives and
+// exports are written: a local directory or an S3-compatible bucket.
+type ObjectStoreConfig struct {
+	Target   string `yaml:"target" json:"target,omitempty"`     // "file" or "s3"
+	Dir      string `yaml:"dir" json:"dir,omitempty"`           // file: directory
+	Bucket   string `yaml:"bucket" json:"bucket,omitempty"`     // s3: bucket name
+	Prefix   string `yaml:"prefix" json:"prefix,omitempty"`     // s3: key prefix, e.g. "loom/logs/"
+	Region   string `yaml:"region" json:"region,omitempty"`     // s3: defaults to AWS_REGION
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"` // s3: overrides the regional endpoint, e.g. for MinIO
+}
+
+// ExportConfig configures a scheduled export of operational data to object
+// storage for long-term reporting.
+type ExportConfig struct {
+	// Name identifies the export; it keys the export's progress, so
+	// renaming an export starts it over.
+	Name string `yaml:"name" json:"name"`
+	// Datasets lists what is exported: analytics (LLM request logs), logs,
+	// and beads (a full snapshot every run).
+	Datasets []string `yaml:"datasets" json:"datasets"`
+	// Format is the object format. Only jsonl (gzipped JSON lines, the
+	// default) is supported.
+	Format string `yaml:"format" json:"format,omitempty"`
+	// Interval is how often the export runs (default 24h).
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty"`
+	// Destination is where exported objects are written.
+	Destination ObjectStoreConfig `yaml:"destination" json:"destination"`
+}
+
+// BackupConfig configures scheduled backups of the database and each
+// project's beads worktree. Backups are off until a destination is set.
+type BackupConfig struct {
+	// Interval is how often a backup is taken (default 24h).
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty"`
+	// Retain is how many backups are kept (default 7); older ones are
+	// deleted after each new backup.
+	Retain int `yaml:"retain" json:"retain,omitempty"`
+	// Destination is where backups are written and restored from.
+	Destination ObjectStoreConfig `yaml:"destination" json:"destination"`
 }
 
 // TemporalConfig configures Temporal workflow engine
@@ -209,6 +611,16 @@ type CacheConfig struct {
 	MaxMemoryMB   int           `yaml:"max_memory_mb" json:"max_memory_mb"`
 	CleanupPeriod time.Duration `yaml:"cleanup_period" json:"cleanup_period"`
 	RedisURL      string        `yaml:"redis_url" json:"redis_url,omitempty"` // Redis connection URL
+	// Prompts caches LLM responses to identical prompts for the steps
+	// that are safe to answer from a cache.
+	Prompts PromptCacheConfig `yaml:"prompts" json:"prompts"`
+}
+
+// PromptCacheConfig configures the LLM prompt cache. It is off by default.
+type PromptCacheConfig struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"`
+	TTL     time.Duration `yaml:"ttl" json:"ttl"`           // Default 1h
+	MaxSize int           `yaml:"max_size" json:"max_size"` // Max cached responses, default 1000
 }
 
 // ProjectConfig represents a project configuration
@@ -261,6 +673,94 @@ type OpenClawConfig struct {
 	EscalationsOnly  bool          `yaml:"escalations_only" json:"escalations_only"` // Only send P0/CEO-escalated decisions
 }
 
+// SlackConfig configures the Slack app used to post escalations and decision
+// beads with interactive approve/deny buttons. The app's Interactivity
+// Request URL must point at /api/v1/webhooks/slack.
+type SlackConfig struct {
+	Enabled       bool          `yaml:"enabled" json:"enabled"`
+	BotToken      string        `yaml:"bot_token" json:"bot_token,omitempty"`           // xoxb- token with chat:write
+	SigningSecret string        `yaml:"signing_secret" json:"signing_secret,omitempty"` // Verifies inbound interaction requests
+	Channel       string        `yaml:"channel" json:"channel,omitempty"`               // Channel ID or name to post to
+	APIURL        string        `yaml:"api_url" json:"api_url,omitempty"`               // Override for testing; defaults to https://slack.com/api
+	Timeout       time.Duration `yaml:"timeout" json:"timeout,omitempty"`
+}
+
+// NotificationsConfig configures the channels notifications are delivered
+// on besides the in-app feed.
+type NotificationsConfig struct {
+	Email EmailConfig `yaml:"email" json:"email,omitempty"`
+}
+
+// EmailConfig configures the SMTP server notification emails are sent
+// through. Users opt in with enable_email in their notification
+// preferences.
+type EmailConfig struct {
+	Enabled  bool   `yaml:"enabled" json:"enabled"`
+	SMTPHost string `yaml:"smtp_host" json:"smtp_host,omitempty"`
+	SMTPPort int    `yaml:"smtp_port" json:"smtp_port,omitempty"` // Defaults to 587
+	Username string `yaml:"username" json:"username,omitempty"`
+	Password string `yaml:"password" json:"-"` // Defaults to SMTP_PASSWORD
+	From     string `yaml:"from" json:"from,omitempty"`
+	BaseURL  string `yaml:"base_url" json:"base_url,omitempty"` // Prefixed to the links in emails, e.g. https://loom.example.com
+}
+
+// SandboxConfig limits the resources of the commands agents run. A project
+// picks a profile with the sandbox_profile context key; the built-in
+// profiles are small, standard, large, and unlimited.
+type SandboxConfig struct {
+	Mode           string                    `yaml:"mode" json:"mode,omitempty"`                       // "auto" (default), "cgroup", "rlimit", or "off"
+	DefaultProfile string                    `yaml:"default_profile" json:"default_profile,omitempty"` // Defaults to "standard"
+	CgroupRoot     string                    `yaml:"cgroup_root" json:"cgroup_root,omitempty"`         // Defaults to /sys/fs/cgroup/loom
+	Profiles       map[string]SandboxProfile `yaml:"profiles" json:"profiles,omitempty"`               // Added to, or replacing, the built-in profiles
+}
+
+// ContainersConfig selects the container engine that runs project
+// containers. Docker and Podman, rootful or rootless, are driven through
+// their CLIs; any engine serving the Docker API can be reached with the
+// docker runtime and its socket.
+type ContainersConfig struct {
+	Runtime string `yaml:"runtime" json:"runtime,omitempty"` // "auto" (default), "docker", or "podman"
+	Socket  string `yaml:"socket" json:"socket,omitempty"`   // API socket, e.g. /run/user/1000/podman/podman.sock; defaults to the runtime's own
+}
+
+// SandboxProfile is a named set of per-command limits. Zero means no limit.
+type SandboxProfile struct {
+	TimeoutSeconds int     `yaml:"timeout_seconds" json:"timeout_seconds,omitempty"`
+	CPUs           float64 `yaml:"cpus" json:"cpus,omitempty"`
+	MemoryMB       int64   `yaml:"memory_mb" json:"memory_mb,omitempty"`
+	MaxProcesses   int64   `yaml:"max_processes" json:"max_processes,omitempty"`
+	MaxFileSizeMB  int64   `yaml:"max_file_size_mb" json:"max_file_size_mb,omitempty"`
+}
+
+// KeyStoreConfig selects what protects the key store (data/keys/.keys.json).
+// The local backend encrypts it with LOOM_PASSWORD; vault and aws-kms keep
+// its data key in an external key service instead. Switching from local
+// migrates the existing store on the next start.
+type KeyStoreConfig struct {
+	Backend          string              `yaml:"backend" json:"backend,omitempty"`                     // "local" (default), "vault", or "aws-kms"
+	RotationInterval time.Duration       `yaml:"rotation_interval" json:"rotation_interval,omitempty"` // Re-encrypt under a new data key this often (0 = never)
+	Vault            VaultKeyStoreConfig `yaml:"vault" json:"vault,omitempty"`
+	KMS              KMSKeyStoreConfig   `yaml:"kms" json:"kms,omitempty"`
+}
+
+// VaultKeyStoreConfig configures the Vault transit key that wraps the key
+// store's data key.
+type VaultKeyStoreConfig struct {
+	Address   string `yaml:"address" json:"address,omitempty"`     // Defaults to VAULT_ADDR
+	Token     string `yaml:"token" json:"-"`                       // Defaults to VAULT_TOKEN
+	Namespace string `yaml:"namespace" json:"namespace,omitempty"` // Defaults to VAULT_NAMESPACE
+	Mount     string `yaml:"mount" json:"mount,omitempty"`         // Transit mount path, defaults to "transit"
+	KeyName   string `yaml:"key_name" json:"key_name,omitempty"`   // Defaults to "loom"
+}
+
+// KMSKeyStoreConfig configures the AWS KMS key that wraps the key store's
+// data key. Credentials come from the standard AWS_* environment variables.
+type KMSKeyStoreConfig struct {
+	KeyID    string `yaml:"key_id" json:"key_id,omitempty"`     // Key ID, ARN, or alias/name
+	Region   string `yaml:"region" json:"region,omitempty"`     // Defaults to AWS_REGION
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"` // Overrides the regional endpoint, e.g. for a VPC endpoint
+}
+
 // LoadConfigFromFile loads configuration from a YAML file at the specified path.
 // This is typically used for loading system-wide or project-specific configuration.
 func LoadConfigFromFile(path string) (*Config, error) {
@@ -276,10 +776,31 @@ func LoadConfigFromFile(path string) (*Config, error) {
 	if err := yaml.Unmarshal([]byte(expanded), &config); err != nil {
 		return nil, err
 	}
+	if err := config.validateCORS(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
+// validateCORS refuses credentials for any origin: every site could then
+// make requests with the user's cookies and read the responses.
+func (c *Config) validateCORS() error {
+	if !c.Server.CORS.AllowCredentials {
+		return nil
+	}
+	origins := c.Server.CORS.AllowedOrigins
+	if len(origins) == 0 {
+		origins = c.Security.AllowedOrigins
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			return fmt.Errorf("server.cors.allow_credentials can't be used with allowed origin \"*\"; list the origins instead")
+		}
+	}
+	return nil
+}
+
 // LoadConfig loads user-specific configuration from the default JSON config file.
 // This is typically used for loading user preferences and provider settings.
 // The config file is stored at ~/.loom.json
@@ -381,6 +902,11 @@ func DefaultConfig() *Config {
 			RetryDelay:      2 * time.Second,
 			EscalationsOnly: true,
 		},
+		Slack: SlackConfig{
+			Enabled: false,
+			APIURL:  "https://slack.com/api",
+			Timeout: 10 * time.Second,
+		},
 	}
 }
 
@@ -191,6 +191,28 @@ func TestLoadConfigFromFile_InvalidYAML(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFromFile_CORSCredentialsWithAnyOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"any origin with credentials", "server:\n  cors:\n    allowed_origins: [\"*\"]\n    allow_credentials: true\n", true},
+		{"security origins with credentials", "security:\n  allowed_origins: [\"*\"]\nserver:\n  cors:\n    allow_credentials: true\n", true},
+		{"listed origins with credentials", "security:\n  allowed_origins: [\"*\"]\nserver:\n  cors:\n    allowed_origins: [\"https://app.example.com\"]\n    allow_credentials: true\n", false},
+		{"any origin without credentials", "server:\n  cors:\n    allowed_origins: [\"*\"]\n", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfigFromFile(path); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
 func TestPDAConfig_Fields(t *testing.T) {
 	cfg := PDAConfig{
 		Enabled:         true,
@@ -245,6 +267,16 @@ func TestDefaultConfig_OpenClaw(t *testing.T) {
 	}
 }
 
+func TestDefaultConfig_Slack(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Slack.Enabled {
+		t.Error("Slack should be disabled by default")
+	}
+	if cfg.Slack.APIURL != "https://slack.com/api" {
+		t.Errorf("got api url %q", cfg.Slack.APIURL)
+	}
+}
+
 func TestDefaultConfig_Security(t *testing.T) {
 	cfg := DefaultConfig()
 	if !cfg.Security.EnableAuth {
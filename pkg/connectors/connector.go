@@ -70,6 +70,35 @@ type Connector interface {
 	Close() error
 }
 
+// ActionProvider is implemented by connectors that give agents new actions.
+// Loom registers the actions with the action router at startup; agents see
+// them in their action prompt and call them with a "params" object that is
+// validated against the action's Schema.
+type ActionProvider interface {
+	Actions() []ActionSpec
+}
+
+// ActionSpec describes an agent action provided by a connector.
+type ActionSpec struct {
+	Type        string
+	Description string
+	// Schema is a JSON Schema for the action's params (type, properties,
+	// required, enum, items, and additionalProperties are supported).
+	Schema map[string]interface{}
+	// ReadOnly marks actions that only read, for dry-run plans.
+	ReadOnly bool
+	// Execute runs the action and returns a message for the agent plus any
+	// structured output.
+	Execute func(ctx context.Context, params map[string]interface{}, scope ActionScope) (string, map[string]interface{}, error)
+}
+
+// ActionScope identifies who is running a connector action, and for what.
+type ActionScope struct {
+	AgentID   string
+	BeadID    string
+	ProjectID string
+}
+
 // Config holds the configuration for a connector
 type Config struct {
 	ID          string         `json:"id" yaml:"id"`
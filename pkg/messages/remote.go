@@ -0,0 +1,37 @@
+package messages
+
+import "time"
+
+// Remote agent protocol message types. A remote agent opens a WebSocket to
+// the control plane and exchanges RemoteMessages on it: it sends
+// heartbeats, asks for work and reports results; the control plane
+// answers with a task, or with no_work when nothing is ready.
+const (
+	RemoteWelcome     = "remote.welcome"      // control plane: connection accepted
+	RemoteHeartbeat   = "remote.heartbeat"    // agent: still alive, with its status
+	RemoteRequestWork = "remote.request_work" // agent: idle, send a task
+	RemoteTask        = "remote.task"         // control plane: a claimed bead to work on
+	RemoteNoWork      = "remote.no_work"      // control plane: nothing ready, ask again later
+	RemoteResult      = "remote.result"       // agent: outcome of a task
+	RemoteAck         = "remote.ack"          // control plane: result recorded
+	RemoteError       = "remote.error"        // control plane: the last message was rejected
+)
+
+// RemoteMessage is one message of the remote agent protocol.
+type RemoteMessage struct {
+	Type              string         `json:"type"`
+	AgentID           string         `json:"agent_id,omitempty"`
+	Status            string         `json:"status,omitempty"` // heartbeat: "idle" or "busy"
+	BeadID            string         `json:"bead_id,omitempty"`
+	Task              *TaskMessage   `json:"task,omitempty"`
+	Result            *ResultMessage `json:"result,omitempty"`
+	HeartbeatSeconds  int            `json:"heartbeat_seconds,omitempty"`   // welcome: how often to send heartbeats
+	RetryAfterSeconds int            `json:"retry_after_seconds,omitempty"` // no_work: when to ask again
+	Error             string         `json:"error,omitempty"`
+	Timestamp         time.Time      `json:"timestamp"`
+}
+
+// NewRemoteMessage creates a remote agent protocol message of a type.
+func NewRemoteMessage(msgType string) *RemoteMessage {
+	return &RemoteMessage{Type: msgType, Timestamp: time.Now()}
+}
@@ -0,0 +1,30 @@
+package messages
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRemoteMessageRoundTrip(t *testing.T) {
+	msg := NewRemoteMessage(RemoteResult)
+	msg.BeadID = "bd-1"
+	msg.Result = TaskCompleted("proj-1", "bd-1", "ra-1", ResultData{Status: "success", Output: "done"}, "c-1")
+	if msg.Timestamp.IsZero() {
+		t.Error("timestamp not set")
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got RemoteMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Type != RemoteResult || got.BeadID != "bd-1" {
+		t.Errorf("got %+v", got)
+	}
+	if got.Result == nil || got.Result.Result.Output != "done" || got.Task != nil {
+		t.Errorf("got result %+v, task %+v", got.Result, got.Task)
+	}
+}
@@ -25,11 +25,29 @@ type ConversationContext struct {
 	ExpiresAt  time.Time         `json:"expires_at" db:"expires_at"`
 	TokenCount int               `json:"token_count" db:"token_count"` // Cumulative token usage
 	Metadata   map[string]string `json:"metadata" db:"metadata"`       // Stored as JSON in SQLite
+	// LoopState is where the action loop working on the bead got to; nil
+	// when no loop is under way.
+	LoopState *LoopState `json:"loop_state,omitempty" db:"loop_state"`
 
 	// Entity versioning
 	EntityMetadata `json:"entity_metadata,omitempty"`
 }
 
+// LoopState checkpoints an action loop. It is saved with the conversation
+// before every iteration, so a loop cut short by a crash or restart
+// resumes at the iteration it was on rather than from the start.
+type LoopState struct {
+	Iteration  int `json:"iteration"` // Iterations completed
+	TokensUsed int `json:"tokens_used"`
+	// ActionLog holds the most recent iterations' actions and results, as
+	// the worker logs them.
+	ActionLog       json.RawMessage `json:"action_log,omitempty"`
+	FilesChanged    []string        `json:"files_changed,omitempty"`
+	ActionHashes    map[string]int  `json:"action_hashes,omitempty"`
+	ActionTypeCount map[string]int  `json:"action_type_count,omitempty"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
 // ChatMessage represents a single message in the conversation history.
 // This extends the basic provider.ChatMessage with additional fields needed
 // for conversation tracking.
@@ -153,6 +171,29 @@ func (c *ConversationContext) SetMetadataFromJSON(data []byte) error {
 	return json.Unmarshal(data, &c.Metadata)
 }
 
+// LoopStateJSON returns the loop state as JSON for database storage, or
+// nil when there is none.
+func (c *ConversationContext) LoopStateJSON() (interface{}, error) {
+	if c.LoopState == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(c.LoopState)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// SetLoopStateFromJSON parses JSON bytes into the loop state.
+func (c *ConversationContext) SetLoopStateFromJSON(data []byte) error {
+	if len(data) == 0 || string(data) == "null" {
+		c.LoopState = nil
+		return nil
+	}
+	c.LoopState = &LoopState{}
+	return json.Unmarshal(data, c.LoopState)
+}
+
 // VersionedEntity interface implementation
 
 // GetEntityType returns the entity type for conversation contexts
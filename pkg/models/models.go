@@ -54,20 +54,33 @@ func (p *Persona) GetID() string                      { return p.Name }
 type Agent struct {
 	EntityMetadata `json:",inline"`
 
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Role        string    `json:"role,omitempty"`
-	PersonaName string    `json:"persona_name"`
-	Persona     *Persona  `json:"persona,omitempty"`
-	ProviderID  string    `json:"provider_id,omitempty"`
-	Status      string    `json:"status"` // "paused", "idle", "working", "deciding", "blocked"
-	CurrentBead string    `json:"current_bead,omitempty"`
-	ProjectID   string    `json:"project_id"`
-	PositionID  string    `json:"position_id,omitempty"` // Link to org chart position
-	StartedAt   time.Time `json:"started_at"`
-	LastActive  time.Time `json:"last_active"`
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Role         string    `json:"role,omitempty"`
+	PersonaName  string    `json:"persona_name"`
+	Persona      *Persona  `json:"persona,omitempty"`
+	ProviderID   string    `json:"provider_id,omitempty"`
+	Status       string    `json:"status"` // "paused", "idle", "working", "deciding", "blocked"
+	CurrentBead  string    `json:"current_bead,omitempty"`
+	ProjectID    string    `json:"project_id"`
+	PositionID   string    `json:"position_id,omitempty"`  // Link to org chart position
+	Capabilities []string  `json:"capabilities,omitempty"` // Skill tags matched against bead tags at dispatch
+	Hold         string    `json:"hold,omitempty"`         // AgentHoldPaused or AgentHoldDraining; held agents get no new work
+	StartedAt    time.Time `json:"started_at"`
+	LastActive   time.Time `json:"last_active"`
 }
 
+// Agent hold states, set by an operator. Unlike the "paused" status, which
+// only means an agent is waiting for a provider, a hold is never lifted
+// automatically.
+const (
+	// AgentHoldPaused means the agent takes no new work until resumed.
+	AgentHoldPaused = "paused"
+	// AgentHoldDraining means the agent is finishing its current bead and
+	// will then be paused.
+	AgentHoldDraining = "draining"
+)
+
 // VersionedEntity interface implementation for Agent
 func (a *Agent) GetEntityType() EntityType          { return EntityTypeAgent }
 func (a *Agent) GetSchemaVersion() SchemaVersion    { return a.EntityMetadata.SchemaVersion }
@@ -82,6 +95,7 @@ const (
 	ProjectStatusOpen     ProjectStatus = "open"
 	ProjectStatusClosed   ProjectStatus = "closed"
 	ProjectStatusReopened ProjectStatus = "reopened"
+	ProjectStatusArchived ProjectStatus = "archived"
 )
 
 // ProjectComment represents a comment on a project's state
@@ -228,6 +242,7 @@ type Bead struct {
 	Children    []string          `json:"children,omitempty"`    // Child bead IDs
 	Tags        []string          `json:"tags,omitempty"`
 	Context     map[string]string `json:"context,omitempty"`
+	DuplicateOf string            `json:"duplicate_of,omitempty"` // Bead ID this one duplicates
 
 	// Deadline tracking (motivation system)
 	DueDate       *time.Time `json:"due_date,omitempty"`       // When this bead should be completed
@@ -236,7 +251,12 @@ type Bead struct {
 
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"` // First moved to in_progress; used for SLA response time
 	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+
+	// Soft deletion: a deleted bead sits in the trash until restored or purged
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
 }
 
 // VersionedEntity interface implementation for Bead
@@ -246,6 +266,28 @@ func (b *Bead) SetSchemaVersion(v SchemaVersion)   { b.EntityMetadata.SchemaVers
 func (b *Bead) GetEntityMetadata() *EntityMetadata { return &b.EntityMetadata }
 func (b *Bead) GetID() string                      { return b.ID }
 
+// IsOverdue reports whether the bead is still open past its due date.
+func (b *Bead) IsOverdue(now time.Time) bool {
+	return b.DueDate != nil && b.Status != BeadStatusClosed && now.After(*b.DueDate)
+}
+
+// EffectivePriority is the bead's priority raised one level for every
+// interval it has waited since it was created, up to P0. An interval of 0
+// or less leaves the priority as it is.
+func (b *Bead) EffectivePriority(interval time.Duration, now time.Time) BeadPriority {
+	if interval <= 0 || b.Priority <= BeadPriorityP0 {
+		return b.Priority
+	}
+	raised := BeadPriority(now.Sub(b.CreatedAt) / interval)
+	if raised <= 0 {
+		return b.Priority
+	}
+	if raised >= b.Priority {
+		return BeadPriorityP0
+	}
+	return b.Priority - raised
+}
+
 // DecisionBead represents a specific decision point that needs resolution
 type DecisionBead struct {
 	*Bead
@@ -274,13 +316,25 @@ type WorkGraph struct {
 	Beads     map[string]*Bead `json:"beads"`
 	Edges     []Edge           `json:"edges"`
 	UpdatedAt time.Time        `json:"updated_at"`
+	// Rollups summarizes the descendants of each bead that has children.
+	Rollups map[string]ChildRollup `json:"rollups,omitempty"`
 }
 
 // Edge represents a directed edge in the work graph
 type Edge struct {
 	From         string `json:"from"`
 	To           string `json:"to"`
-	Relationship string `json:"relationship"` // "blocks", "parent", "related"
+	Relationship string `json:"relationship"` // "blocks", "parent", "related", "duplicate"
+}
+
+// ChildRollup counts a bead's children, and their children, by status.
+type ChildRollup struct {
+	Total           int     `json:"total"`
+	Open            int     `json:"open"`
+	InProgress      int     `json:"in_progress"`
+	Blocked         int     `json:"blocked"`
+	Closed          int     `json:"closed"`
+	PercentComplete float64 `json:"percent_complete"`
 }
 
 // AutonomyLevel defines agent decision-making authority
@@ -14,6 +14,10 @@ type OrgChart struct {
 	ParentID   string     `json:"parent_id"`   // For inherited org charts (sub-projects)
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// RemovedRoles are default roles deliberately removed from this chart;
+	// backfilling new default positions skips them.
+	RemovedRoles []string `json:"removed_roles,omitempty"`
 }
 
 // VersionedEntity interface implementation for OrgChart
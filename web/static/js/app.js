@@ -3,6 +3,11 @@ const API_BASE = '/api/v1';
 const REFRESH_INTERVAL = 5000; // 5 seconds
 
 const AUTH_TOKEN_KEY = 'loom.authToken';
+// Single sign-on returns to the page with the session token in the fragment.
+if (window.location.hash.startsWith('#token=')) {
+    localStorage.setItem(AUTH_TOKEN_KEY, decodeURIComponent(window.location.hash.slice('#token='.length)));
+    history.replaceState(null, '', window.location.pathname + window.location.search);
+}
 let authToken = localStorage.getItem(AUTH_TOKEN_KEY) || '';
 let authCheckInFlight = null;
 let loginInFlight = null;
@@ -96,7 +101,9 @@ let state = {
     decisions: [],
     systemStatus: null,
     users: [],
-    apiKeys: []
+    apiKeys: [],
+    boards: {},
+    health: {}
 };
 window.state = state;
 
@@ -259,6 +266,7 @@ function initUI() {
     beadProject?.addEventListener('change', (e) => {
         uiState.bead.project = e.target.value;
         render();
+        loadProjectBoard(e.target.value);
     });
     beadAssigned?.addEventListener('input', (e) => {
         uiState.bead.assigned = e.target.value || '';
@@ -297,13 +305,12 @@ function initUI() {
         render();
     });
 
-    initKanbanDnD();
-
 
     const projectSelect = document.getElementById('project-view-select');
     projectSelect?.addEventListener('change', (e) => {
         uiState.project.selectedId = e.target.value || '';
         render();
+        loadProjectHealth(uiState.project.selectedId);
     });
 
     // Legacy REPL (backward compat)
@@ -420,6 +427,8 @@ async function loadAll() {
             loadMotivations().catch(err => { console.error('[Loom] Failed to load motivations:', err); })
         ]);
         await loadCeoBeads().catch(err => { console.error('[Loom] Failed to load CEO beads:', err); state.ceoBeads = []; });
+        await loadProjectBoard(uiState.bead.project, { render: false });
+        await loadProjectHealth(uiState.project.selectedId || state.projects?.[0]?.id, { render: false });
         console.log('[Loom] Data loaded successfully:', {
             beads: state.beads?.length || 0,
             projects: state.projects?.length || 0,
@@ -559,6 +568,24 @@ async function ensureAuth(forcePrompt = false) {
 async function showLoginModal() {
     if (loginInFlight) return loginInFlight;
     loginInFlight = (async () => {
+        let sso = null;
+        try {
+            sso = await apiCall('/auth/oidc/config', { skipAuth: true, skipAutoFile: true, suppressToast: true });
+        } catch (e) {
+            // Servers without single sign-on only offer the password login.
+        }
+        if (sso?.enabled) {
+            const choice = await formModal({
+                title: 'Sign in',
+                submitText: `Sign in with ${sso.name}`,
+                cancelText: 'Use password'
+            });
+            if (choice) {
+                const redirect = window.location.pathname + window.location.search;
+                window.location.href = `${window.LOOM_BASE_PATH || ''}${API_BASE}/auth/oidc/login?redirect=${encodeURIComponent(redirect)}`;
+                return new Promise(() => {});
+            }
+        }
         let loggedIn = false;
         while (!loggedIn) {
             const values = await formModal({
@@ -618,50 +645,80 @@ function scheduleReload(kind, delayMs = 150) {
     }, delayMs);
 }
 
+// startEventStream follows the event socket and reloads what each event
+// touches. On reconnect it passes the cursor of the last message seen so the
+// server replays events missed while disconnected; a "gap" message means
+// some could not be replayed, so everything is reloaded.
 function startEventStream() {
-    if (typeof EventSource === 'undefined') return;
+    if (typeof WebSocket === 'undefined') return;
 
-    try {
-        const es = new EventSource(`${API_BASE}/events/stream`);
+    const map = {
+        'bead.created': ['beads', 'status'],
+        'bead.assigned': ['beads', 'agents', 'status'],
+        'bead.status_change': ['beads', 'status'],
+        'bead.completed': ['beads', 'status'],
+        'agent.spawned': ['agents', 'projects', 'status'],
+        'agent.status_change': ['agents', 'status'],
+        'agent.heartbeat': ['agents', 'status'],
+        'agent.completed': ['agents', 'status'],
+        'decision.created': ['decisions'],
+        'decision.resolved': ['decisions'],
+        'project.created': ['projects'],
+        'project.updated': ['projects'],
+        'project.deleted': ['projects'],
+        'config.updated': ['projects', 'agents', 'status']
+    };
+    const allKinds = ['beads', 'agents', 'projects', 'decisions', 'status'];
 
-        es.addEventListener('connected', () => {
-            eventStreamConnected = true;
-        });
+    let cursor = null;
+    let retryMs = 1000;
 
-        const map = {
-            'bead.created': ['beads', 'status'],
-            'bead.assigned': ['beads', 'agents', 'status'],
-            'bead.status_change': ['beads', 'status'],
-            'bead.completed': ['beads', 'status'],
-            'agent.spawned': ['agents', 'projects', 'status'],
-            'agent.status_change': ['agents', 'status'],
-            'agent.heartbeat': ['agents', 'status'],
-            'agent.completed': ['agents', 'status'],
-            'decision.created': ['decisions'],
-            'decision.resolved': ['decisions'],
-            'project.created': ['projects'],
-            'project.updated': ['projects'],
-            'project.deleted': ['projects'],
-            'config.updated': ['projects', 'agents', 'status']
-        };
+    const connect = () => {
+        const proto = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+        // WebSockets can't carry an Authorization header; send the token
+        // as a query parameter instead.
+        const params = new URLSearchParams();
+        if (cursor !== null) params.set('cursor', cursor);
+        if (AUTH_ENABLED && authToken) params.set('token', authToken);
+        let url = `${proto}//${window.location.host}${API_BASE}/events/ws`;
+        if (params.toString()) url += `?${params}`;
 
-        for (const [eventName, kinds] of Object.entries(map)) {
-            es.addEventListener(eventName, () => {
-                for (const k of kinds) scheduleReload(k);
-            });
+        let ws;
+        try {
+            ws = new WebSocket(url);
+        } catch {
+            eventStreamConnected = false;
+            return;
         }
 
-        es.onerror = () => {
-            eventStreamConnected = false;
+        ws.onmessage = (e) => {
+            let msg;
             try {
-                es.close();
+                msg = JSON.parse(e.data);
             } catch {
-                // ignore
+                return;
+            }
+            if (msg.cursor) cursor = msg.cursor;
+
+            if (msg.kind === 'ready') {
+                eventStreamConnected = true;
+                retryMs = 1000;
+            } else if (msg.kind === 'gap') {
+                for (const k of allKinds) scheduleReload(k);
+            } else if (msg.kind === 'event' && msg.event) {
+                for (const k of map[msg.event.type] || []) scheduleReload(k);
             }
         };
-    } catch {
-        eventStreamConnected = false;
-    }
+
+        ws.onclose = () => {
+            // Polling takes over until the socket is back.
+            eventStreamConnected = false;
+            window.setTimeout(connect, retryMs);
+            retryMs = Math.min(retryMs * 2, 30000);
+        };
+    };
+
+    connect();
 }
 
 function showToast(message, type = 'info', timeoutMs = 4500) {
@@ -692,6 +749,39 @@ async function loadBeads() {
     state.beads = await apiCall('/beads');
 }
 
+// loadProjectBoard fetches a project's board columns and WIP counts. The
+// "all" view has no board and falls back to the default columns.
+async function loadProjectBoard(projectId, { render: rerender = true } = {}) {
+    if (!projectId || projectId === 'all') return;
+    try {
+        state.boards[projectId] = await apiCall(`/projects/${encodeURIComponent(projectId)}/board?limit=0`, { suppressToast: true, skipAutoFile: true });
+    } catch (error) {
+        delete state.boards[projectId];
+    }
+    if (rerender) render();
+}
+
+// loadProjectHealth fetches a project's health score for the project viewer.
+async function loadProjectHealth(projectId, { render: rerender = true } = {}) {
+    if (!projectId) return;
+    try {
+        state.health[projectId] = await apiCall(`/projects/${encodeURIComponent(projectId)}/health`, { suppressToast: true, skipAutoFile: true });
+    } catch (error) {
+        delete state.health[projectId];
+    }
+    if (rerender) render();
+}
+
+// renderHealthBadge shows a health score, with each component's score and
+// summary in its tooltip.
+function renderHealthBadge(health) {
+    if (!health || health.status === 'unknown') return '';
+    const breakdown = (health.components || [])
+        .map((c) => `${c.name}: ${c.status === 'unknown' ? '-' : c.score} (${c.summary})`)
+        .join('\n');
+    return `<span class="badge health-${escapeHtml(health.status)}" style="margin-right: 0.5rem;" title="${escapeHtml(breakdown).replace(/"/g, '&quot;')}">health ${health.score}</span>`;
+}
+
 async function loadCeoBeads() {
     const ceoIds = getCeoAgentIds();
     if (ceoIds.length === 0) {
@@ -816,6 +906,7 @@ function renderProjectViewer() {
         <div class="project-header" style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 1rem; padding-bottom: 0.75rem; border-bottom: 1px solid var(--border-color);">
             <div>
                 <span class="badge ${statusClass}" style="margin-right: 0.5rem;">${escapeHtml(project.status || 'open')}</span>
+                ${renderHealthBadge(state.health[project.id])}
                 <span class="small" style="color: var(--text-muted);">${escapeHtml(project.git_repo || '')} @ ${escapeHtml(project.branch || 'main')}</span>
             </div>
             <div style="display: flex; gap: 0.5rem;">
@@ -1440,36 +1531,61 @@ function renderKanban() {
         projectSelect.value = currentVal;
     }
 
+    const container = document.getElementById('kanban-board');
+    if (!container) return;
+
+    const projectId = uiState.bead.project || 'all';
+    const board = projectId !== 'all' ? state.boards[projectId] : null;
+    const columns = board?.columns || defaultKanbanColumns;
+
+    // Rebuild the column skeleton only when the layout changes so drag
+    // listeners aren't piled onto the same elements on every render.
+    const layoutKey = JSON.stringify(columns.map((c) => [c.name, c.statuses]));
+    if (container.dataset.layout !== layoutKey) {
+        container.innerHTML = columns.map((col, i) => `
+            <div class="kanban-column kanban-dropzone" data-status="${escapeHtml(col.statuses[0] || '')}">
+                <h3>${escapeHtml(col.name)} <span class="kanban-wip" id="kanban-wip-${i}"></span></h3>
+                <div id="kanban-col-${i}" class="bead-list"></div>
+            </div>`).join('');
+        container.style.setProperty('--kanban-columns', columns.length);
+        container.dataset.layout = layoutKey;
+        initKanbanDnD();
+    }
+
     const filtered = getFilteredBeads();
-    const openBeads = filtered.filter((b) => b.status === 'open');
-    const blockedBeads = filtered.filter((b) => b.status === 'blocked');
-    const inProgressBeads = filtered.filter((b) => b.status === 'in_progress');
-    const closedBeads = filtered.filter((b) => b.status === 'closed');
-
-    const openEl = document.getElementById('open-beads');
-    const blockedEl = document.getElementById('blocked-beads');
-    const ipEl = document.getElementById('in-progress-beads');
-    const closedEl = document.getElementById('closed-beads');
-    if (!openEl || !blockedEl || !ipEl || !closedEl) return;
-
-    openEl.innerHTML =
-        openBeads.length > 0
-            ? openBeads.map(renderBeadCard).join('')
-            : renderEmptyState('No open beads', 'Create a bead via the API or bd CLI, then it will show up here.');
-    blockedEl.innerHTML =
-        blockedBeads.length > 0
-            ? blockedBeads.map(renderBeadCard).join('')
-            : renderEmptyState('No blocked beads', 'Blocked beads will appear here when work is waiting on dependencies or intervention.');
-    ipEl.innerHTML =
-        inProgressBeads.length > 0
-            ? inProgressBeads.map(renderBeadCard).join('')
-            : renderEmptyState('Nothing in progress', 'Claim a bead to move it into progress.');
-    closedEl.innerHTML =
-        closedBeads.length > 0
-            ? closedBeads.map(renderBeadCard).join('')
-            : renderEmptyState('No closed beads yet', 'Completed beads will appear here.');
+    columns.forEach((col, i) => {
+        const beads = filtered.filter((b) => col.statuses.includes(b.status));
+        const listEl = document.getElementById(`kanban-col-${i}`);
+        if (listEl) {
+            const empty = kanbanEmptyStates[col.statuses[0]] || ['Nothing here', 'Beads in this column will appear here.'];
+            listEl.innerHTML = beads.length > 0 ? beads.map(renderBeadCard).join('') : renderEmptyState(empty[0], empty[1]);
+        }
+
+        const wipEl = document.getElementById(`kanban-wip-${i}`);
+        if (wipEl) {
+            const count = col.count ?? beads.length;
+            wipEl.textContent = col.wip_limit ? `${count}/${col.wip_limit}` : '';
+            wipEl.title = col.wip_limit ? `WIP limit ${col.wip_limit}` : '';
+            wipEl.classList.toggle('at-limit', !!col.at_limit && !col.over_limit);
+            wipEl.classList.toggle('over-limit', !!col.over_limit);
+        }
+    });
 }
 
+const defaultKanbanColumns = [
+    { name: 'Open', statuses: ['open'] },
+    { name: 'Blocked', statuses: ['blocked'] },
+    { name: 'In Progress', statuses: ['in_progress'] },
+    { name: 'Closed', statuses: ['closed'] }
+];
+
+const kanbanEmptyStates = {
+    open: ['No open beads', 'Create a bead via the API or bd CLI, then it will show up here.'],
+    blocked: ['No blocked beads', 'Blocked beads will appear here when work is waiting on dependencies or intervention.'],
+    in_progress: ['Nothing in progress', 'Claim a bead to move it into progress.'],
+    closed: ['No closed beads yet', 'Completed beads will appear here.']
+};
+
 function initKanbanDnD() {
     const dropzones = document.querySelectorAll('.kanban-dropzone');
     dropzones.forEach((zone) => {
@@ -1516,6 +1632,7 @@ async function handleKanbanDrop(event) {
     } catch (error) {
         // Error already handled
     }
+    loadProjectBoard(bead.project_id);
 }
 
 async function sendStreamingTest() {
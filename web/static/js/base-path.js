@@ -0,0 +1,45 @@
+// Base path support for Loom
+// When Loom is served under a path prefix (server.base_path), the server
+// sets window.LOOM_BASE_PATH and loads this script before any other. It
+// prefixes the root-relative API and static URLs the UI's scripts use.
+
+(function () {
+    const base = window.LOOM_BASE_PATH || '';
+    if (!base) {
+        return;
+    }
+
+    const prefixed = /^\/(api|static|ws)\//;
+    const withBase = (url) => (typeof url === 'string' && prefixed.test(url) ? base + url : url);
+
+    const origFetch = window.fetch;
+    window.fetch = function (input, init) {
+        return origFetch.call(this, withBase(input), init);
+    };
+
+    const OrigEventSource = window.EventSource;
+    if (OrigEventSource) {
+        window.EventSource = function (url, config) {
+            return new OrigEventSource(withBase(url), config);
+        };
+        window.EventSource.prototype = OrigEventSource.prototype;
+    }
+
+    const OrigWebSocket = window.WebSocket;
+    if (OrigWebSocket) {
+        window.WebSocket = function (url, protocols) {
+            const u = new URL(url, window.location.href);
+            if (u.host === window.location.host && !u.pathname.startsWith(base + '/')) {
+                u.pathname = withBase(u.pathname);
+            }
+            return new OrigWebSocket(u.toString(), protocols);
+        };
+        window.WebSocket.prototype = OrigWebSocket.prototype;
+        Object.assign(window.WebSocket, {
+            CONNECTING: OrigWebSocket.CONNECTING,
+            OPEN: OrigWebSocket.OPEN,
+            CLOSING: OrigWebSocket.CLOSING,
+            CLOSED: OrigWebSocket.CLOSED,
+        });
+    }
+})();
@@ -14,10 +14,11 @@
  * Clicking a node shows action details + notes + result in the side panel.
  */
 
-/* global cytoscape, apiCall, escapeHtml, state, uiState, LoomCharts, formatAgentDisplayName */
+/* global cytoscape, apiCall, escapeHtml, state, uiState, LoomCharts, formatAgentDisplayName, API_BASE */
 
 let convCy = null;
 let convData = null; // {conversations: [...], selected: conversation object}
+let convStream = null; // {sessionId, source} for the live output pane
 
 const CONV_COLORS = {
     system: '#2563eb',
@@ -117,12 +118,56 @@ async function loadConversationsList() {
     }
 }
 
+// ── Live Output ─────────────────────────────────────────────────────
+
+// Stream the selected conversation's model output into the live pane as
+// it is generated. One block per turn; text is appended as it arrives.
+function watchConversationOutput(sessionId) {
+    if (convStream && convStream.sessionId === sessionId) return;
+    if (convStream) convStream.source.close();
+    convStream = null;
+
+    var pane = document.getElementById('live-updates');
+    var counter = document.getElementById('iteration-counter');
+    if (pane) pane.innerHTML = '';
+    if (counter) counter.textContent = '';
+    if (!pane || !sessionId || typeof EventSource === 'undefined') return;
+
+    var source = new EventSource(API_BASE + '/conversations/' + encodeURIComponent(sessionId) + '/stream');
+    convStream = { sessionId: sessionId, source: source };
+
+    source.addEventListener('output', function (e) {
+        var data;
+        try { data = JSON.parse(e.data); } catch { return; }
+        var turn = String(data.turn || 0);
+        var block = pane.querySelector('div[data-turn="' + turn + '"]');
+        if (!block) {
+            block = document.createElement('div');
+            block.dataset.turn = turn;
+            block.style.whiteSpace = 'pre-wrap';
+            pane.appendChild(block);
+        }
+        block.textContent += data.delta || '';
+        pane.scrollTop = pane.scrollHeight;
+        if (counter) counter.textContent = 'Turn ' + turn;
+    });
+    source.onerror = function () {
+        // EventSource reconnects on its own; give up only if the server
+        // rejected the stream (e.g. the session was deleted).
+        if (source.readyState === EventSource.CLOSED && convStream && convStream.source === source) {
+            convStream = null;
+        }
+    };
+}
+
 // ── Graph Rendering ─────────────────────────────────────────────────
 
 function renderConversationGraph(conversation) {
     var graphEl = document.getElementById('conv-graph');
     if (!graphEl || !conversation || !conversation.messages) return;
 
+    watchConversationOutput(conversation.session_id);
+
     // Parse messages into nodes + edges
     var nodes = [];
     var edges = [];
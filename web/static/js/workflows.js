@@ -265,6 +265,10 @@ function getNodeShape(nodeType) {
             return ['[[', ']]'];  // Subroutine
         case 'verify':
             return ['[/', '/]'];  // Parallelogram
+        case 'parallel':
+            return ['[/', '\\]'];  // Trapezoid
+        case 'join':
+            return ['[\\', '/]'];  // Inverted trapezoid
         default:
             return ['[', ']'];  // Rectangle
     }
@@ -408,6 +412,16 @@ function renderBeadWorkflow(data) {
                 </div>
             ` : ''}
 
+            ${data.branches && data.branches.length > 0 ? `
+                <div style="margin-top:20px;">
+                    <h4>Parallel Branches</h4>
+                    ${data.branches.map(b => `
+                        <p><strong>${b.node_key}</strong> &rarr; ${b.join_node_key}: bead ${b.bead_id}
+                            <span class="execution-status ${b.status}">${b.status}</span></p>
+                    `).join('')}
+                </div>
+            ` : ''}
+
             ${data.history && data.history.length > 0 ? `
                 <div class="history-timeline">
                     <h4>Execution History</h4>